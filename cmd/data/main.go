@@ -8,14 +8,17 @@ import (
 	"syscall"
 
 	"github.com/quidditch/quidditch/pkg/common/config"
+	"github.com/quidditch/quidditch/pkg/common/logging"
+	"github.com/quidditch/quidditch/pkg/common/tracing"
 	"github.com/quidditch/quidditch/pkg/data"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
 
 var (
-	cfgFile string
-	logger  *zap.Logger
+	cfgFile  string
+	logger   *zap.Logger
+	logLevel zap.AtomicLevel
 )
 
 func main() {
@@ -40,7 +43,7 @@ func init() {
 
 func initConfig() {
 	var err error
-	logger, err = zap.NewProduction()
+	logger, logLevel, err = logging.New()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
 		os.Exit(1)
@@ -57,6 +60,9 @@ func run(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		logger.Fatal("Failed to load configuration", zap.Error(err))
 	}
+	if err := logging.SetLevel(logLevel, cfg.LogLevel); err != nil {
+		logger.Fatal("Invalid log level", zap.Error(err))
+	}
 
 	logger.Info("Starting Quidditch Data Node",
 		zap.String("node_id", cfg.NodeID),
@@ -68,11 +74,23 @@ func run(cmd *cobra.Command, args []string) error {
 		zap.Bool("simd_enabled", cfg.SIMDEnabled),
 	)
 
+	// Set up tracing before any gRPC client or server is created
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing, "quidditch-data", cfg.NodeID)
+	if err != nil {
+		logger.Fatal("Failed to initialize tracing", zap.Error(err))
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Warn("Failed to flush traces", zap.Error(err))
+		}
+	}()
+
 	// Create data node
 	dataNode, err := data.NewDataNode(cfg, logger)
 	if err != nil {
 		logger.Fatal("Failed to create data node", zap.Error(err))
 	}
+	dataNode.SetLogLevel(logLevel)
 
 	// Start data node
 	if err := dataNode.Start(ctx); err != nil {
@@ -30,8 +30,10 @@ extern "C" {
     /// Check if a document has a specific field
     fn has_field(ctx_id: i64, field_ptr: *const u8, field_len: i32) -> i32;
 
-    /// Get a string field value from the document
-    /// Returns the length of the value written, or -1 on error
+    /// Get a string field value from the document. `value_len_ptr` holds
+    /// the buffer size on entry and the value's length on return.
+    /// Returns 0 on success, 1 if the field is missing, or 3 if the buffer
+    /// is too small, with the required size stored at `value_len_ptr`
     fn get_field_string(
         ctx_id: i64,
         field_ptr: *const u8,
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// AuthConfig configures authentication and authorization of REST API requests
+type AuthConfig struct {
+	Enabled bool     // Reject requests without valid credentials
+	APIKeys []APIKey // Accepted API keys / bearer tokens
+
+	// Roles maps role names to the index permissions they grant. When empty,
+	// every authenticated caller may perform any action.
+	Roles map[string]RoleConfig
+}
+
+// APIKey maps a secret key to the identity it authenticates
+type APIKey struct {
+	Name  string   `mapstructure:"name"`  // Identity of the caller (e.g. "ingest-service")
+	Key   string   `mapstructure:"key"`   // Secret sent as a bearer token or API key
+	Roles []string `mapstructure:"roles"` // Roles granted to the caller
+}
+
+// RoleConfig lists the index permissions granted by a role
+type RoleConfig struct {
+	Indices []IndexPermission `mapstructure:"indices"`
+}
+
+// IndexPermission grants actions (read, write, admin) on indices matching any
+// of the name patterns (e.g. "logs-*")
+type IndexPermission struct {
+	Names   []string `mapstructure:"names"`
+	Actions []string `mapstructure:"actions"`
+}
+
+// loadAuthConfig reads the "auth" section of a node config
+func loadAuthConfig(v *viper.Viper) (AuthConfig, error) {
+	cfg := AuthConfig{
+		Enabled: v.GetBool("auth.enabled"),
+	}
+
+	if err := v.UnmarshalKey("auth.api_keys", &cfg.APIKeys); err != nil {
+		return AuthConfig{}, fmt.Errorf("invalid auth.api_keys: %w", err)
+	}
+	if err := v.UnmarshalKey("auth.roles", &cfg.Roles); err != nil {
+		return AuthConfig{}, fmt.Errorf("invalid auth.roles: %w", err)
+	}
+
+	return cfg, nil
+}
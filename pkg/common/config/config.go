@@ -18,6 +18,16 @@ type MasterConfig struct {
 	Peers       []string
 	LogLevel    string
 	MetricsPort int
+
+	// RebalanceInterval is how often the leader evens out shards across data
+	// nodes. Rebalancing is off unless it is positive.
+	RebalanceInterval time.Duration
+
+	// TLS secures the gRPC API (optional)
+	TLS TLSConfig
+
+	// Tracing exports OpenTelemetry spans (disabled by default)
+	Tracing TracingConfig
 }
 
 // CoordinationConfig holds configuration for coordination nodes
@@ -34,20 +44,81 @@ type CoordinationConfig struct {
 	MetricsPort    int
 	MaxConcurrent  int
 	RequestTimeout time.Duration
+
+	// DataDir holds state that must survive restarts, such as uploaded UDFs
+	// (empty keeps it in memory only)
+	DataDir string
+
+	// DisableCompression turns off gzip request/response compression on the REST API
+	DisableCompression bool
+
+	// REST server limits (zero uses the coordination node defaults)
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+
+	// ShutdownTimeout bounds how long Stop waits for in-flight requests and
+	// background work to drain
+	ShutdownTimeout time.Duration
+
+	// TLS secures the REST API and connections to master and data nodes (optional)
+	TLS TLSConfig
+
+	// Auth configures REST API authentication (disabled by default)
+	Auth AuthConfig
+
+	// RateLimit throttles REST API requests per client (disabled by default)
+	RateLimit RateLimitConfig
+
+	// Tracing exports OpenTelemetry spans (disabled by default)
+	Tracing TracingConfig
+
+	// AllowUnboundedRegexp permits regexp queries starting with .* or .+,
+	// which scan every term of the field (rejected by default)
+	AllowUnboundedRegexp bool
+
+	// Bulk request limits and concurrency (zero uses the bulk package defaults)
+	MaxBulkBodyBytes  int64
+	MaxBulkOperations int
+	BulkConcurrency   int
+
+	// PathRepo lists the directories fs snapshot repositories may be
+	// registered under (path.repo). Without any, fs repositories are rejected.
+	PathRepo []string
 }
 
 // DataNodeConfig holds configuration for data nodes (Diagon)
 type DataNodeConfig struct {
-	NodeID       string
-	BindAddr     string
-	GRPCPort     int
-	DataDir      string
-	MasterAddr   string
-	StorageTier  string // hot, warm, cold, frozen
-	MaxShards    int
-	LogLevel     string
-	MetricsPort  int
-	SIMDEnabled  bool
+	NodeID      string
+	BindAddr    string
+	GRPCPort    int
+	DataDir     string
+	MasterAddr  string
+	StorageTier string // hot, warm, cold, frozen
+	MaxShards   int
+	LogLevel    string
+	MetricsPort int
+	SIMDEnabled bool
+
+	// Zone is the rack or availability zone of the node. The master never
+	// places two copies of a shard on nodes in the same zone (optional)
+	Zone string
+
+	// TLS secures the gRPC API and the connection to the master (optional)
+	TLS TLSConfig
+
+	// FieldDataBreakerLimit caps the estimated memory of in-flight
+	// aggregations in bytes (0 disables the breaker)
+	FieldDataBreakerLimit int64
+
+	// Tracing exports OpenTelemetry spans (disabled by default)
+	Tracing TracingConfig
+
+	// RAMBufferSizeMB is the indexing RAM buffer of each shard whose index
+	// does not set index.buffer_size_mb
+	RAMBufferSizeMB float64
 }
 
 // LoadMasterConfig loads master node configuration from file
@@ -62,6 +133,8 @@ func LoadMasterConfig(cfgFile string) (*MasterConfig, error) {
 	v.SetDefault("data_dir", "/var/lib/quidditch/master")
 	v.SetDefault("log_level", "info")
 	v.SetDefault("metrics_port", 9400)
+	v.SetDefault("rebalance_interval", "0s")
+	setTracingDefaults(v)
 
 	// Load config file
 	if cfgFile != "" {
@@ -93,6 +166,10 @@ func LoadMasterConfig(cfgFile string) (*MasterConfig, error) {
 		Peers:       v.GetStringSlice("peers"),
 		LogLevel:    v.GetString("log_level"),
 		MetricsPort: v.GetInt("metrics_port"),
+		TLS:         loadTLSConfig(v),
+		Tracing:     loadTracingConfig(v),
+
+		RebalanceInterval: v.GetDuration("rebalance_interval"),
 	}
 
 	return cfg, nil
@@ -109,12 +186,28 @@ func LoadCoordinationConfig(cfgFile string) (*CoordinationConfig, error) {
 	v.SetDefault("grpc_port", 9302)
 	v.SetDefault("master_addr", "localhost:9301")
 	v.SetDefault("calcite_addr", "localhost:50051")
-	v.SetDefault("python_enabled", true)
+	v.SetDefault("python_enabled", false)
 	v.SetDefault("python_path", "/usr/lib/python3.11")
 	v.SetDefault("log_level", "info")
 	v.SetDefault("metrics_port", 9401)
 	v.SetDefault("max_concurrent", 1000)
 	v.SetDefault("request_timeout", "30s")
+	v.SetDefault("disable_compression", false)
+	v.SetDefault("read_timeout", "60s")
+	v.SetDefault("read_header_timeout", "10s")
+	v.SetDefault("write_timeout", "60s")
+	v.SetDefault("idle_timeout", "120s")
+	v.SetDefault("max_header_bytes", 1<<20)
+	v.SetDefault("shutdown_timeout", "30s")
+	v.SetDefault("auth.enabled", false)
+	v.SetDefault("rate_limit.enabled", false)
+	v.SetDefault("rate_limit.requests_per_second", 100)
+	v.SetDefault("rate_limit.burst", 200)
+	v.SetDefault("rate_limit.max_in_flight_searches", 10)
+	v.SetDefault("rate_limit.key_by", "ip")
+	v.SetDefault("allow_unbounded_regexp", false)
+	v.SetDefault("data_dir", "/var/lib/quidditch/coordination")
+	setTracingDefaults(v)
 
 	// Load config file
 	if cfgFile != "" {
@@ -150,8 +243,32 @@ func LoadCoordinationConfig(cfgFile string) (*CoordinationConfig, error) {
 		MetricsPort:    v.GetInt("metrics_port"),
 		MaxConcurrent:  v.GetInt("max_concurrent"),
 		RequestTimeout: v.GetDuration("request_timeout"),
+		DataDir:        v.GetString("data_dir"),
+
+		DisableCompression: v.GetBool("disable_compression"),
+		ReadTimeout:        v.GetDuration("read_timeout"),
+		ReadHeaderTimeout:  v.GetDuration("read_header_timeout"),
+		WriteTimeout:       v.GetDuration("write_timeout"),
+		IdleTimeout:        v.GetDuration("idle_timeout"),
+		MaxHeaderBytes:     v.GetInt("max_header_bytes"),
+		ShutdownTimeout:    v.GetDuration("shutdown_timeout"),
+		TLS:                loadTLSConfig(v),
+		RateLimit:          loadRateLimitConfig(v),
+		Tracing:            loadTracingConfig(v),
+
+		AllowUnboundedRegexp: v.GetBool("allow_unbounded_regexp"),
+		MaxBulkBodyBytes:     v.GetInt64("max_bulk_body_bytes"),
+		MaxBulkOperations:    v.GetInt("max_bulk_operations"),
+		BulkConcurrency:      v.GetInt("bulk_concurrency"),
+		PathRepo:             v.GetStringSlice("path.repo"),
 	}
 
+	authCfg, err := loadAuthConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Auth = authCfg
+
 	return cfg, nil
 }
 
@@ -170,6 +287,9 @@ func LoadDataNodeConfig(cfgFile string) (*DataNodeConfig, error) {
 	v.SetDefault("log_level", "info")
 	v.SetDefault("metrics_port", 9402)
 	v.SetDefault("simd_enabled", true)
+	v.SetDefault("breaker.fielddata_limit", "512mb")
+	v.SetDefault("ram_buffer_size_mb", 64)
+	setTracingDefaults(v)
 
 	// Load config file
 	if cfgFile != "" {
@@ -203,6 +323,12 @@ func LoadDataNodeConfig(cfgFile string) (*DataNodeConfig, error) {
 		LogLevel:    v.GetString("log_level"),
 		MetricsPort: v.GetInt("metrics_port"),
 		SIMDEnabled: v.GetBool("simd_enabled"),
+		Zone:        v.GetString("zone"),
+		TLS:         loadTLSConfig(v),
+		Tracing:     loadTracingConfig(v),
+
+		FieldDataBreakerLimit: int64(v.GetSizeInBytes("breaker.fielddata_limit")),
+		RAMBufferSizeMB:       v.GetFloat64("ram_buffer_size_mb"),
 	}
 
 	return cfg, nil
@@ -0,0 +1,23 @@
+package config
+
+import "github.com/spf13/viper"
+
+// RateLimitConfig configures per-client throttling of REST API requests
+type RateLimitConfig struct {
+	Enabled             bool
+	RequestsPerSecond   float64 // Sustained request rate per client (0 = unlimited)
+	Burst               int     // Requests a client may make at once before being throttled
+	MaxInFlightSearches int     // Concurrent search requests per client (0 = unlimited)
+	KeyBy               string  // Client identity: "ip" or "api_key" (falls back to ip when unauthenticated)
+}
+
+// loadRateLimitConfig reads the "rate_limit" section of a node config
+func loadRateLimitConfig(v *viper.Viper) RateLimitConfig {
+	return RateLimitConfig{
+		Enabled:             v.GetBool("rate_limit.enabled"),
+		RequestsPerSecond:   v.GetFloat64("rate_limit.requests_per_second"),
+		Burst:               v.GetInt("rate_limit.burst"),
+		MaxInFlightSearches: v.GetInt("rate_limit.max_in_flight_searches"),
+		KeyBy:               v.GetString("rate_limit.key_by"),
+	}
+}
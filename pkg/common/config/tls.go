@@ -0,0 +1,108 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// TLSConfig holds the certificate paths a node uses for TLS. The same
+// certificate serves incoming connections and, for mutual TLS, identifies the
+// node to the servers it dials.
+type TLSConfig struct {
+	CertFile   string // PEM certificate presented by this node
+	KeyFile    string // PEM private key for CertFile
+	CAFile     string // PEM CA bundle used to verify peers
+	ClientAuth bool   // Require and verify client certificates (mutual TLS)
+}
+
+// Enabled returns true if a certificate and key are configured
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
+
+// ServerConfig builds the tls.Config for accepting connections
+func (c TLSConfig) ServerConfig() (*tls.Config, error) {
+	if !c.Enabled() {
+		return nil, fmt.Errorf("tls cert_file and key_file are required")
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tls key pair: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if c.ClientAuth {
+		if c.CAFile == "" {
+			return nil, fmt.Errorf("tls ca_file is required for client authentication")
+		}
+		pool, err := loadCertPool(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// ClientConfig builds the tls.Config for dialing other nodes. The CA bundle,
+// if set, replaces the system roots; the node certificate, if set, is
+// presented for mutual TLS.
+func (c TLSConfig) ClientConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if c.CAFile != "" {
+		pool, err := loadCertPool(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.Enabled() {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls key pair: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// loadCertPool reads a PEM CA bundle
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tls ca_file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in tls ca_file %s", caFile)
+	}
+
+	return pool, nil
+}
+
+// loadTLSConfig reads the "tls" section of a node config
+func loadTLSConfig(v *viper.Viper) TLSConfig {
+	return TLSConfig{
+		CertFile:   v.GetString("tls.cert_file"),
+		KeyFile:    v.GetString("tls.key_file"),
+		CAFile:     v.GetString("tls.ca_file"),
+		ClientAuth: v.GetBool("tls.client_auth"),
+	}
+}
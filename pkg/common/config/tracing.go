@@ -0,0 +1,29 @@
+package config
+
+import "github.com/spf13/viper"
+
+// TracingConfig configures OpenTelemetry trace export
+type TracingConfig struct {
+	Enabled     bool
+	Endpoint    string  // OTLP gRPC collector address (host:port)
+	Insecure    bool    // Send spans without TLS
+	SampleRatio float64 // Fraction of new traces to record (0 to 1)
+}
+
+// setTracingDefaults sets the defaults of the "tracing" section
+func setTracingDefaults(v *viper.Viper) {
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.endpoint", "localhost:4317")
+	v.SetDefault("tracing.insecure", true)
+	v.SetDefault("tracing.sample_ratio", 1.0)
+}
+
+// loadTracingConfig reads the "tracing" section of a node config
+func loadTracingConfig(v *viper.Viper) TracingConfig {
+	return TracingConfig{
+		Enabled:     v.GetBool("tracing.enabled"),
+		Endpoint:    v.GetString("tracing.endpoint"),
+		Insecure:    v.GetBool("tracing.insecure"),
+		SampleRatio: v.GetFloat64("tracing.sample_ratio"),
+	}
+}
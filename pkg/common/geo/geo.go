@@ -0,0 +1,188 @@
+// Package geo provides geo_point parsing and distance helpers shared by the
+// query parser on coordination nodes and the indexing/search path on data
+// nodes.
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EarthRadiusMeters is the mean Earth radius used for distance calculations
+const EarthRadiusMeters = 6371008.8
+
+// Point is a latitude/longitude pair in degrees
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// ParsePoint parses a geo_point value in any of the accepted forms:
+// {"lat": 40.7, "lon": -74.0}, "40.7,-74.0" or [-74.0, 40.7] (GeoJSON order)
+func ParsePoint(value interface{}) (Point, error) {
+	var p Point
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		lat, latOK := v["lat"].(float64)
+		lon, lonOK := v["lon"].(float64)
+		if !latOK || !lonOK {
+			return p, fmt.Errorf("geo_point object must have numeric [lat] and [lon]")
+		}
+		p = Point{Lat: lat, Lon: lon}
+	case string:
+		parts := strings.Split(v, ",")
+		if len(parts) != 2 {
+			return p, fmt.Errorf("geo_point string must be \"lat,lon\", got [%s]", v)
+		}
+		lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		if err != nil {
+			return p, fmt.Errorf("invalid geo_point latitude [%s]", parts[0])
+		}
+		lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil {
+			return p, fmt.Errorf("invalid geo_point longitude [%s]", parts[1])
+		}
+		p = Point{Lat: lat, Lon: lon}
+	case []interface{}:
+		if len(v) != 2 {
+			return p, fmt.Errorf("geo_point array must be [lon, lat]")
+		}
+		lon, lonOK := v[0].(float64)
+		lat, latOK := v[1].(float64)
+		if !latOK || !lonOK {
+			return p, fmt.Errorf("geo_point array must be numeric [lon, lat]")
+		}
+		p = Point{Lat: lat, Lon: lon}
+	default:
+		return p, fmt.Errorf("unsupported geo_point value type %T", value)
+	}
+
+	if p.Lat < -90 || p.Lat > 90 {
+		return p, fmt.Errorf("latitude [%v] must be between -90 and 90", p.Lat)
+	}
+	if p.Lon < -180 || p.Lon > 180 {
+		return p, fmt.Errorf("longitude [%v] must be between -180 and 180", p.Lon)
+	}
+	return p, nil
+}
+
+// IsPointObject reports whether value is a {"lat": .., "lon": ..} object,
+// which is how geo_point fields are detected without an explicit mapping
+func IsPointObject(value interface{}) bool {
+	m, ok := value.(map[string]interface{})
+	if !ok || len(m) != 2 {
+		return false
+	}
+	_, err := ParsePoint(m)
+	return err == nil
+}
+
+// distanceUnits maps distance unit suffixes to meters, ordered so longer
+// suffixes are matched first ("nmi" before "mi", "km" before "m")
+var distanceUnits = []struct {
+	suffix string
+	meters float64
+}{
+	{"nmi", 1852},
+	{"km", 1000},
+	{"mi", 1609.344},
+	{"yd", 0.9144},
+	{"ft", 0.3048},
+	{"cm", 0.01},
+	{"mm", 0.001},
+	{"in", 0.0254},
+	{"m", 1},
+}
+
+// ParseDistance parses a distance such as "10km", "500m" or "2mi" into
+// meters. Plain numbers are taken as meters.
+func ParseDistance(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		if v < 0 {
+			return 0, fmt.Errorf("distance must be non-negative, got [%v]", v)
+		}
+		return v, nil
+	case string:
+		s := strings.TrimSpace(strings.ToLower(v))
+		unit := 1.0
+		for _, u := range distanceUnits {
+			if strings.HasSuffix(s, u.suffix) {
+				s, unit = strings.TrimSuffix(s, u.suffix), u.meters
+				break
+			}
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid distance [%s]", v)
+		}
+		return n * unit, nil
+	default:
+		return 0, fmt.Errorf("unsupported distance value type %T", value)
+	}
+}
+
+// Distance returns the great-circle (haversine) distance between two points
+// in meters
+func Distance(a, b Point) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BoundingBox returns a box containing every point within meters of origin.
+// It is used to narrow candidates with range queries before the exact
+// distance check. wrapsLon is set when the box crosses the antimeridian or
+// reaches a pole, in which case the longitude bounds cover everything.
+func BoundingBox(origin Point, meters float64) (minLat, maxLat, minLon, maxLon float64, wrapsLon bool) {
+	deltaLat := meters / EarthRadiusMeters * 180 / math.Pi
+	minLat = math.Max(-90, origin.Lat-deltaLat)
+	maxLat = math.Min(90, origin.Lat+deltaLat)
+
+	if minLat == -90 || maxLat == 90 {
+		return minLat, maxLat, -180, 180, true
+	}
+
+	// The widest longitude span is at the latitude farthest from the equator
+	maxAbsLat := math.Max(math.Abs(minLat), math.Abs(maxLat)) * math.Pi / 180
+	deltaLon := deltaLat / math.Cos(maxAbsLat)
+	minLon = origin.Lon - deltaLon
+	maxLon = origin.Lon + deltaLon
+	if minLon < -180 || maxLon > 180 {
+		return minLat, maxLat, -180, 180, true
+	}
+	return minLat, maxLat, minLon, maxLon, false
+}
+
+// InnerBox returns a box every point of which is within meters of origin,
+// the counterpart of BoundingBox for excluding candidates: a document in the
+// box is certainly within the distance. Its half-sides are half the distance,
+// measured where a degree of longitude is widest. Boxes that would reach a
+// pole or the antimeridian shrink to the origin itself.
+func InnerBox(origin Point, meters float64) (minLat, maxLat, minLon, maxLon float64) {
+	deltaLat := meters / 2 / EarthRadiusMeters * 180 / math.Pi
+	minLat, maxLat = origin.Lat-deltaLat, origin.Lat+deltaLat
+	if minLat <= -90 || maxLat >= 90 {
+		return origin.Lat, origin.Lat, origin.Lon, origin.Lon
+	}
+
+	// The narrowest longitude span is at the latitude nearest the equator
+	minAbsLat := 0.0
+	if minLat > 0 || maxLat < 0 {
+		minAbsLat = math.Min(math.Abs(minLat), math.Abs(maxLat)) * math.Pi / 180
+	}
+	deltaLon := deltaLat / math.Cos(minAbsLat)
+	minLon, maxLon = origin.Lon-deltaLon, origin.Lon+deltaLon
+	if minLon < -180 || maxLon > 180 {
+		return origin.Lat, origin.Lat, origin.Lon, origin.Lon
+	}
+	return minLat, maxLat, minLon, maxLon
+}
@@ -0,0 +1,146 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParsePoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    Point
+		wantErr bool
+	}{
+		{name: "object", value: map[string]interface{}{"lat": 40.7, "lon": -74.0}, want: Point{Lat: 40.7, Lon: -74.0}},
+		{name: "string", value: "40.7, -74.0", want: Point{Lat: 40.7, Lon: -74.0}},
+		{name: "array_lon_lat", value: []interface{}{-74.0, 40.7}, want: Point{Lat: 40.7, Lon: -74.0}},
+		{name: "missing_lon", value: map[string]interface{}{"lat": 40.7}, wantErr: true},
+		{name: "bad_string", value: "somewhere", wantErr: true},
+		{name: "lat_out_of_range", value: "91,0", wantErr: true},
+		{name: "lon_out_of_range", value: "0,181", wantErr: true},
+		{name: "unsupported", value: 42.0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePoint(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParsePoint(%v) expected error", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePoint(%v) error = %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePoint(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDistance(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		want    float64
+		wantErr bool
+	}{
+		{value: "10km", want: 10000},
+		{value: "500m", want: 500},
+		{value: "2mi", want: 3218.688},
+		{value: "1nmi", want: 1852},
+		{value: "1.5 KM", want: 1500},
+		{value: "250", want: 250},
+		{value: 75.0, want: 75},
+		{value: "far", wantErr: true},
+		{value: "-1km", wantErr: true},
+		{value: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDistance(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseDistance(%v) expected error", tt.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseDistance(%v) error = %v", tt.value, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("ParseDistance(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	london := Point{Lat: 51.5074, Lon: -0.1278}
+	paris := Point{Lat: 48.8566, Lon: 2.3522}
+
+	// London to Paris is roughly 344km
+	if d := Distance(london, paris); math.Abs(d-343_900) > 2_000 {
+		t.Errorf("Distance(london, paris) = %v, want ~343.9km", d)
+	}
+	if d := Distance(london, london); d != 0 {
+		t.Errorf("Distance to self = %v, want 0", d)
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	origin := Point{Lat: 48.8566, Lon: 2.3522}
+	minLat, maxLat, minLon, maxLon, wraps := BoundingBox(origin, 50_000)
+	if wraps {
+		t.Fatalf("BoundingBox around Paris should not wrap")
+	}
+
+	// Points on the circle must fall inside the box
+	for _, p := range []Point{
+		{Lat: origin.Lat + 0.449, Lon: origin.Lon},
+		{Lat: origin.Lat, Lon: origin.Lon + 0.68},
+		{Lat: origin.Lat, Lon: origin.Lon - 0.68},
+	} {
+		if Distance(origin, p) > 50_000 {
+			t.Fatalf("test point %v is outside the radius", p)
+		}
+		if p.Lat < minLat || p.Lat > maxLat || p.Lon < minLon || p.Lon > maxLon {
+			t.Errorf("point %v outside box [%v,%v]x[%v,%v]", p, minLat, maxLat, minLon, maxLon)
+		}
+	}
+
+	// Boxes reaching a pole or the antimeridian cover every longitude
+	if _, _, _, _, wraps := BoundingBox(Point{Lat: 89.9, Lon: 0}, 50_000); !wraps {
+		t.Errorf("BoundingBox near the pole should wrap")
+	}
+	if _, _, _, _, wraps := BoundingBox(Point{Lat: 0, Lon: 179.9}, 50_000); !wraps {
+		t.Errorf("BoundingBox across the antimeridian should wrap")
+	}
+}
+
+func TestInnerBox(t *testing.T) {
+	for _, origin := range []Point{{Lat: 48.8566, Lon: 2.3522}, {Lat: -0.1, Lon: 30}, {Lat: 70, Lon: -20}} {
+		minLat, maxLat, minLon, maxLon := InnerBox(origin, 50_000)
+		if minLat >= maxLat || minLon >= maxLon {
+			t.Fatalf("InnerBox around %v is empty", origin)
+		}
+
+		// Every corner of the box is within the distance
+		for _, corner := range []Point{
+			{Lat: minLat, Lon: minLon}, {Lat: minLat, Lon: maxLon},
+			{Lat: maxLat, Lon: minLon}, {Lat: maxLat, Lon: maxLon},
+		} {
+			if d := Distance(origin, corner); d > 50_000 {
+				t.Errorf("corner %v of the box around %v is %.0fm away", corner, origin, d)
+			}
+		}
+	}
+
+	// Boxes reaching a pole or the antimeridian shrink to the origin
+	origin := Point{Lat: 0, Lon: 179.9}
+	if minLat, maxLat, minLon, maxLon := InnerBox(origin, 50_000); minLat != 0 || maxLat != 0 || minLon != 179.9 || maxLon != 179.9 {
+		t.Errorf("InnerBox across the antimeridian should be the origin, got [%v,%v]x[%v,%v]", minLat, maxLat, minLon, maxLon)
+	}
+}
@@ -0,0 +1,75 @@
+// Package logging builds the loggers nodes log through, at a level that can
+// be changed while the node runs
+package logging
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SettingLevel is the cluster setting for the level every node logs at,
+// overriding its configured level until the setting is reset
+const SettingLevel = "logger.level"
+
+// New builds a production logger and the level it logs at, starting at
+// info. Setting the level changes what the logger writes from then on.
+func New() (*zap.Logger, zap.AtomicLevel, error) {
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	cfg := zap.NewProductionConfig()
+	cfg.Level = level
+	logger, err := cfg.Build()
+	if err != nil {
+		return nil, level, err
+	}
+	return logger, level, nil
+}
+
+// ParseLevel parses a level name, ignoring case. Empty is info. trace is
+// accepted as debug and warning as warn, so Elasticsearch-style names work.
+func ParseLevel(name string) (zapcore.Level, error) {
+	switch name = strings.ToLower(strings.TrimSpace(name)); name {
+	case "trace":
+		return zapcore.DebugLevel, nil
+	case "warning":
+		return zapcore.WarnLevel, nil
+	}
+
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return level, fmt.Errorf("unknown log level [%s], expected one of trace, debug, info, warn or error", name)
+	}
+	return level, nil
+}
+
+// SetLevel sets level to the level named name
+func SetLevel(level zap.AtomicLevel, name string) error {
+	parsed, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	level.SetLevel(parsed)
+	return nil
+}
+
+// ApplySetting sets level from the logger.level setting in settings, or
+// back to configured when it is unset, logging a change to logger. An
+// invalid setting leaves the level as it was.
+func ApplySetting(level zap.AtomicLevel, settings map[string]string, configured string, logger *zap.Logger) {
+	name, ok := settings[SettingLevel]
+	if !ok {
+		name = configured
+	}
+	previous := level.Level()
+	if err := SetLevel(level, name); err != nil {
+		logger.Warn("Ignoring invalid log level", zap.String("level", name), zap.Error(err))
+		return
+	}
+	if current := level.Level(); current != previous {
+		logger.Info("Changed log level",
+			zap.Stringer("from", previous),
+			zap.Stringer("to", current))
+	}
+}
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]zapcore.Level{
+		"":        zapcore.InfoLevel,
+		"info":    zapcore.InfoLevel,
+		"DEBUG":   zapcore.DebugLevel,
+		"trace":   zapcore.DebugLevel,
+		"Warning": zapcore.WarnLevel,
+		"warn":    zapcore.WarnLevel,
+		" error ": zapcore.ErrorLevel,
+	} {
+		level, err := ParseLevel(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, level, name)
+	}
+
+	_, err := ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestSetLevel(t *testing.T) {
+	logger, level, err := New()
+	require.NoError(t, err)
+	defer logger.Sync()
+
+	assert.False(t, logger.Core().Enabled(zapcore.DebugLevel))
+	require.NoError(t, SetLevel(level, "debug"))
+	assert.True(t, logger.Core().Enabled(zapcore.DebugLevel))
+
+	// An unknown level leaves the level unchanged
+	assert.Error(t, SetLevel(level, "loud"))
+	assert.Equal(t, zap.DebugLevel, level.Level())
+}
+
+func TestApplySetting(t *testing.T) {
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	logger := zap.NewNop()
+
+	ApplySetting(level, map[string]string{SettingLevel: "debug"}, "info", logger)
+	assert.Equal(t, zapcore.DebugLevel, level.Level())
+
+	// An invalid setting is ignored
+	ApplySetting(level, map[string]string{SettingLevel: "loud"}, "info", logger)
+	assert.Equal(t, zapcore.DebugLevel, level.Level())
+
+	// Unset returns to the configured level
+	ApplySetting(level, map[string]string{}, "warn", logger)
+	assert.Equal(t, zapcore.WarnLevel, level.Level())
+}
@@ -59,13 +59,29 @@ type MetricsCollector struct {
 	RaftTerm            prometheus.Gauge
 	RaftCommitIndex     prometheus.Gauge
 	RaftAppliedIndex    prometheus.Gauge
+
+	// Data node client metrics (for coordination nodes)
+	DataNodeClientHealthy *prometheus.GaugeVec
+
+	// Rate limiter metrics (for coordination nodes)
+	RateLimitInFlightSearches prometheus.Gauge
+	RateLimitTrackedClients   prometheus.Gauge
+	RateLimitRejections       *prometheus.CounterVec
 }
 
-// NewMetricsCollector creates a new metrics collector for a component
+// NewMetricsCollector creates a new metrics collector for a component,
+// registered with the default Prometheus registry
 func NewMetricsCollector(component string) *MetricsCollector {
+	return NewMetricsCollectorWithRegistry(component, prometheus.DefaultRegisterer)
+}
+
+// NewMetricsCollectorWithRegistry creates a new metrics collector for a
+// component, registered with reg
+func NewMetricsCollectorWithRegistry(component string, reg prometheus.Registerer) *MetricsCollector {
+	factory := promauto.With(reg)
 	return &MetricsCollector{
 		// HTTP metrics
-		HTTPRequestsTotal: promauto.NewCounterVec(
+		HTTPRequestsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -74,7 +90,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 			},
 			[]string{"method", "path", "status"},
 		),
-		HTTPRequestDuration: promauto.NewHistogramVec(
+		HTTPRequestDuration: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -84,7 +100,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 			},
 			[]string{"method", "path"},
 		),
-		HTTPRequestSize: promauto.NewHistogramVec(
+		HTTPRequestSize: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -94,7 +110,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 			},
 			[]string{"method", "path"},
 		),
-		HTTPResponseSize: promauto.NewHistogramVec(
+		HTTPResponseSize: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -106,7 +122,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 		),
 
 		// Query metrics
-		QueryTotal: promauto.NewCounterVec(
+		QueryTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -115,7 +131,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 			},
 			[]string{"index", "query_type", "status"},
 		),
-		QueryDuration: promauto.NewHistogramVec(
+		QueryDuration: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -125,7 +141,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 			},
 			[]string{"index", "query_type"},
 		),
-		QueryComplexity: promauto.NewHistogramVec(
+		QueryComplexity: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -135,7 +151,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 			},
 			[]string{"index"},
 		),
-		QueryCacheHits: promauto.NewCounter(
+		QueryCacheHits: factory.NewCounter(
 			prometheus.CounterOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -143,7 +159,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 				Help:      "Total number of query cache hits",
 			},
 		),
-		QueryCacheMisses: promauto.NewCounter(
+		QueryCacheMisses: factory.NewCounter(
 			prometheus.CounterOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -151,7 +167,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 				Help:      "Total number of query cache misses",
 			},
 		),
-		QueryShardCount: promauto.NewHistogramVec(
+		QueryShardCount: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -163,7 +179,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 		),
 
 		// Bulk operation metrics
-		BulkOperationsTotal: promauto.NewCounterVec(
+		BulkOperationsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -172,7 +188,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 			},
 			[]string{"operation", "status"},
 		),
-		BulkOperationsDuration: promauto.NewHistogram(
+		BulkOperationsDuration: factory.NewHistogram(
 			prometheus.HistogramOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -181,7 +197,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 				Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
 			},
 		),
-		BulkOperationsPerRequest: promauto.NewHistogramVec(
+		BulkOperationsPerRequest: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -193,7 +209,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 		),
 
 		// Document operation metrics
-		DocumentsIndexed: promauto.NewCounterVec(
+		DocumentsIndexed: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -202,7 +218,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 			},
 			[]string{"index", "status"},
 		),
-		DocumentsDeleted: promauto.NewCounterVec(
+		DocumentsDeleted: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -211,7 +227,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 			},
 			[]string{"index", "status"},
 		),
-		DocumentsRetrieved: promauto.NewCounterVec(
+		DocumentsRetrieved: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -222,7 +238,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 		),
 
 		// Cluster metrics
-		ClusterNodes: promauto.NewGaugeVec(
+		ClusterNodes: factory.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -231,7 +247,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 			},
 			[]string{"node_type", "status"},
 		),
-		ClusterShards: promauto.NewGaugeVec(
+		ClusterShards: factory.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -240,7 +256,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 			},
 			[]string{"index", "state"},
 		),
-		ClusterDocuments: promauto.NewGauge(
+		ClusterDocuments: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -248,7 +264,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 				Help:      "Total number of documents in the cluster",
 			},
 		),
-		ClusterIndices: promauto.NewGauge(
+		ClusterIndices: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -258,7 +274,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 		),
 
 		// Shard metrics
-		ShardOperations: promauto.NewCounterVec(
+		ShardOperations: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -267,7 +283,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 			},
 			[]string{"operation", "status"},
 		),
-		ShardSize: promauto.NewGaugeVec(
+		ShardSize: factory.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -276,7 +292,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 			},
 			[]string{"index", "shard_id"},
 		),
-		ShardDocuments: promauto.NewGaugeVec(
+		ShardDocuments: factory.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -287,7 +303,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 		),
 
 		// gRPC metrics
-		GRPCRequestsTotal: promauto.NewCounterVec(
+		GRPCRequestsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -296,7 +312,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 			},
 			[]string{"method", "status"},
 		),
-		GRPCRequestDuration: promauto.NewHistogramVec(
+		GRPCRequestDuration: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -308,7 +324,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 		),
 
 		// Raft metrics
-		RaftLeader: promauto.NewGauge(
+		RaftLeader: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -316,7 +332,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 				Help:      "Whether this node is the Raft leader (1=leader, 0=follower)",
 			},
 		),
-		RaftTerm: promauto.NewGauge(
+		RaftTerm: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -324,7 +340,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 				Help:      "Current Raft term",
 			},
 		),
-		RaftCommitIndex: promauto.NewGauge(
+		RaftCommitIndex: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -332,7 +348,7 @@ func NewMetricsCollector(component string) *MetricsCollector {
 				Help:      "Current Raft commit index",
 			},
 		),
-		RaftAppliedIndex: promauto.NewGauge(
+		RaftAppliedIndex: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: Namespace,
 				Subsystem: component,
@@ -340,6 +356,44 @@ func NewMetricsCollector(component string) *MetricsCollector {
 				Help:      "Current Raft applied index",
 			},
 		),
+
+		// Data node client metrics
+		DataNodeClientHealthy: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: component,
+				Name:      "data_node_client_healthy",
+				Help:      "Whether the last health probe of a data node succeeded (1) or failed (0)",
+			},
+			[]string{"node_id"},
+		),
+
+		// Rate limiter metrics
+		RateLimitInFlightSearches: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: component,
+				Name:      "rate_limit_in_flight_searches",
+				Help:      "Search requests currently executing under the per-client concurrency limit",
+			},
+		),
+		RateLimitTrackedClients: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: component,
+				Name:      "rate_limit_tracked_clients",
+				Help:      "Clients currently tracked by the rate limiter",
+			},
+		),
+		RateLimitRejections: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: component,
+				Name:      "rate_limit_rejections_total",
+				Help:      "Requests rejected with 429 by the rate limiter",
+			},
+			[]string{"reason"}, // rate, concurrency
+		),
 	}
 }
 
@@ -381,6 +435,22 @@ func (m *MetricsCollector) RecordBulkOperation(operation, status string, duratio
 	m.BulkOperationsPerRequest.WithLabelValues(hasErrorsStr).Observe(float64(operationCount))
 }
 
+// RecordDataNodeHealth records the result of a data node's last health
+// probe
+func (m *MetricsCollector) RecordDataNodeHealth(nodeID string, healthy bool) {
+	value := 0.0
+	if healthy {
+		value = 1
+	}
+	m.DataNodeClientHealthy.WithLabelValues(nodeID).Set(value)
+}
+
+// RemoveDataNode drops the metrics of a data node the coordinator no longer
+// talks to
+func (m *MetricsCollector) RemoveDataNode(nodeID string) {
+	m.DataNodeClientHealthy.DeleteLabelValues(nodeID)
+}
+
 // statusClass converts HTTP status code to status class (2xx, 3xx, 4xx, 5xx)
 func statusClass(status int) string {
 	class := status / 100
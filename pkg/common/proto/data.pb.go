@@ -75,7 +75,7 @@ func (x ShardInfo_ShardState) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ShardInfo_ShardState.Descriptor instead.
 func (ShardInfo_ShardState) EnumDescriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{5, 0}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{7, 0}
 }
 
 type CreateShardRequest struct {
@@ -294,6 +294,111 @@ func (x *DeleteShardResponse) GetAcknowledged() bool {
 	return false
 }
 
+// UpdateShardSettingsRequest applies an index's settings to its open shards
+type UpdateShardSettingsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IndexName     string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	Settings      map[string]string      `protobuf:"bytes,2,rep,name=settings,proto3" json:"settings,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // The index settings, as sent with CreateShardRequest
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateShardSettingsRequest) Reset() {
+	*x = UpdateShardSettingsRequest{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateShardSettingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateShardSettingsRequest) ProtoMessage() {}
+
+func (x *UpdateShardSettingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateShardSettingsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateShardSettingsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpdateShardSettingsRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *UpdateShardSettingsRequest) GetSettings() map[string]string {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+type UpdateShardSettingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	ShardsUpdated int32                  `protobuf:"varint,2,opt,name=shards_updated,json=shardsUpdated,proto3" json:"shards_updated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateShardSettingsResponse) Reset() {
+	*x = UpdateShardSettingsResponse{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateShardSettingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateShardSettingsResponse) ProtoMessage() {}
+
+func (x *UpdateShardSettingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateShardSettingsResponse.ProtoReflect.Descriptor instead.
+func (*UpdateShardSettingsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateShardSettingsResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+func (x *UpdateShardSettingsResponse) GetShardsUpdated() int32 {
+	if x != nil {
+		return x.ShardsUpdated
+	}
+	return 0
+}
+
 type GetShardInfoRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	IndexName     string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
@@ -304,7 +409,7 @@ type GetShardInfoRequest struct {
 
 func (x *GetShardInfoRequest) Reset() {
 	*x = GetShardInfoRequest{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[4]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -316,7 +421,7 @@ func (x *GetShardInfoRequest) String() string {
 func (*GetShardInfoRequest) ProtoMessage() {}
 
 func (x *GetShardInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[4]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -329,7 +434,7 @@ func (x *GetShardInfoRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetShardInfoRequest.ProtoReflect.Descriptor instead.
 func (*GetShardInfoRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{4}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *GetShardInfoRequest) GetIndexName() string {
@@ -362,7 +467,7 @@ type ShardInfo struct {
 
 func (x *ShardInfo) Reset() {
 	*x = ShardInfo{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[5]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -374,7 +479,7 @@ func (x *ShardInfo) String() string {
 func (*ShardInfo) ProtoMessage() {}
 
 func (x *ShardInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[5]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -387,7 +492,7 @@ func (x *ShardInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ShardInfo.ProtoReflect.Descriptor instead.
 func (*ShardInfo) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{5}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ShardInfo) GetIndexName() string {
@@ -456,7 +561,7 @@ type RefreshShardRequest struct {
 
 func (x *RefreshShardRequest) Reset() {
 	*x = RefreshShardRequest{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[6]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -468,7 +573,7 @@ func (x *RefreshShardRequest) String() string {
 func (*RefreshShardRequest) ProtoMessage() {}
 
 func (x *RefreshShardRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[6]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -481,7 +586,7 @@ func (x *RefreshShardRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RefreshShardRequest.ProtoReflect.Descriptor instead.
 func (*RefreshShardRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{6}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *RefreshShardRequest) GetIndexName() string {
@@ -507,7 +612,7 @@ type RefreshShardResponse struct {
 
 func (x *RefreshShardResponse) Reset() {
 	*x = RefreshShardResponse{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[7]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -519,7 +624,7 @@ func (x *RefreshShardResponse) String() string {
 func (*RefreshShardResponse) ProtoMessage() {}
 
 func (x *RefreshShardResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[7]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -532,7 +637,7 @@ func (x *RefreshShardResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RefreshShardResponse.ProtoReflect.Descriptor instead.
 func (*RefreshShardResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{7}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *RefreshShardResponse) GetAcknowledged() bool {
@@ -552,7 +657,7 @@ type FlushShardRequest struct {
 
 func (x *FlushShardRequest) Reset() {
 	*x = FlushShardRequest{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[8]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -564,7 +669,7 @@ func (x *FlushShardRequest) String() string {
 func (*FlushShardRequest) ProtoMessage() {}
 
 func (x *FlushShardRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[8]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -577,45 +682,838 @@ func (x *FlushShardRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FlushShardRequest.ProtoReflect.Descriptor instead.
 func (*FlushShardRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{8}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *FlushShardRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *FlushShardRequest) GetShardId() int32 {
+	if x != nil {
+		return x.ShardId
+	}
+	return 0
+}
+
+type FlushShardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlushShardResponse) Reset() {
+	*x = FlushShardResponse{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlushShardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlushShardResponse) ProtoMessage() {}
+
+func (x *FlushShardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlushShardResponse.ProtoReflect.Descriptor instead.
+func (*FlushShardResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FlushShardResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+// ForceMergeRequest merges the segments of a shard, reclaiming the space of
+// deleted documents
+type ForceMergeRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	IndexName      string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	ShardId        int32                  `protobuf:"varint,2,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
+	MaxNumSegments int32                  `protobuf:"varint,3,opt,name=max_num_segments,json=maxNumSegments,proto3" json:"max_num_segments,omitempty"` // 0 lets the merge policy decide
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ForceMergeRequest) Reset() {
+	*x = ForceMergeRequest{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForceMergeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForceMergeRequest) ProtoMessage() {}
+
+func (x *ForceMergeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForceMergeRequest.ProtoReflect.Descriptor instead.
+func (*ForceMergeRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ForceMergeRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *ForceMergeRequest) GetShardId() int32 {
+	if x != nil {
+		return x.ShardId
+	}
+	return 0
+}
+
+func (x *ForceMergeRequest) GetMaxNumSegments() int32 {
+	if x != nil {
+		return x.MaxNumSegments
+	}
+	return 0
+}
+
+type ForceMergeResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged      bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	DocsDeletedBefore int64                  `protobuf:"varint,2,opt,name=docs_deleted_before,json=docsDeletedBefore,proto3" json:"docs_deleted_before,omitempty"`
+	DocsDeletedAfter  int64                  `protobuf:"varint,3,opt,name=docs_deleted_after,json=docsDeletedAfter,proto3" json:"docs_deleted_after,omitempty"`
+	SizeBytesBefore   int64                  `protobuf:"varint,4,opt,name=size_bytes_before,json=sizeBytesBefore,proto3" json:"size_bytes_before,omitempty"`
+	SizeBytesAfter    int64                  `protobuf:"varint,5,opt,name=size_bytes_after,json=sizeBytesAfter,proto3" json:"size_bytes_after,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ForceMergeResponse) Reset() {
+	*x = ForceMergeResponse{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForceMergeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForceMergeResponse) ProtoMessage() {}
+
+func (x *ForceMergeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForceMergeResponse.ProtoReflect.Descriptor instead.
+func (*ForceMergeResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ForceMergeResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+func (x *ForceMergeResponse) GetDocsDeletedBefore() int64 {
+	if x != nil {
+		return x.DocsDeletedBefore
+	}
+	return 0
+}
+
+func (x *ForceMergeResponse) GetDocsDeletedAfter() int64 {
+	if x != nil {
+		return x.DocsDeletedAfter
+	}
+	return 0
+}
+
+func (x *ForceMergeResponse) GetSizeBytesBefore() int64 {
+	if x != nil {
+		return x.SizeBytesBefore
+	}
+	return 0
+}
+
+func (x *ForceMergeResponse) GetSizeBytesAfter() int64 {
+	if x != nil {
+		return x.SizeBytesAfter
+	}
+	return 0
+}
+
+// SnapshotFile is a committed segment file of a shard, stored in a snapshot
+// repository as a blob named by its content hash
+type SnapshotFile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Blob          string                 `protobuf:"bytes,2,opt,name=blob,proto3" json:"blob,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	Reused        bool                   `protobuf:"varint,4,opt,name=reused,proto3" json:"reused,omitempty"` // The blob was already in the repository
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotFile) Reset() {
+	*x = SnapshotFile{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotFile) ProtoMessage() {}
+
+func (x *SnapshotFile) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotFile.ProtoReflect.Descriptor instead.
+func (*SnapshotFile) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SnapshotFile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SnapshotFile) GetBlob() string {
+	if x != nil {
+		return x.Blob
+	}
+	return ""
+}
+
+func (x *SnapshotFile) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *SnapshotFile) GetReused() bool {
+	if x != nil {
+		return x.Reused
+	}
+	return false
+}
+
+// SnapshotShardRequest commits a shard and copies its segment files into a
+// filesystem snapshot repository, skipping files the repository already holds
+type SnapshotShardRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	IndexName          string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	ShardId            int32                  `protobuf:"varint,2,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
+	RepositoryLocation string                 `protobuf:"bytes,3,opt,name=repository_location,json=repositoryLocation,proto3" json:"repository_location,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *SnapshotShardRequest) Reset() {
+	*x = SnapshotShardRequest{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotShardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotShardRequest) ProtoMessage() {}
+
+func (x *SnapshotShardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotShardRequest.ProtoReflect.Descriptor instead.
+func (*SnapshotShardRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SnapshotShardRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *SnapshotShardRequest) GetShardId() int32 {
+	if x != nil {
+		return x.ShardId
+	}
+	return 0
+}
+
+func (x *SnapshotShardRequest) GetRepositoryLocation() string {
+	if x != nil {
+		return x.RepositoryLocation
+	}
+	return ""
+}
+
+type SnapshotShardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*SnapshotFile        `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotShardResponse) Reset() {
+	*x = SnapshotShardResponse{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotShardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotShardResponse) ProtoMessage() {}
+
+func (x *SnapshotShardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotShardResponse.ProtoReflect.Descriptor instead.
+func (*SnapshotShardResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SnapshotShardResponse) GetFiles() []*SnapshotFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+// RestoreShardRequest replaces the contents of a shard with segment files
+// from a snapshot repository
+type RestoreShardRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	IndexName          string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	ShardId            int32                  `protobuf:"varint,2,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
+	RepositoryLocation string                 `protobuf:"bytes,3,opt,name=repository_location,json=repositoryLocation,proto3" json:"repository_location,omitempty"`
+	Files              []*SnapshotFile        `protobuf:"bytes,4,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *RestoreShardRequest) Reset() {
+	*x = RestoreShardRequest{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreShardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreShardRequest) ProtoMessage() {}
+
+func (x *RestoreShardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreShardRequest.ProtoReflect.Descriptor instead.
+func (*RestoreShardRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RestoreShardRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *RestoreShardRequest) GetShardId() int32 {
+	if x != nil {
+		return x.ShardId
+	}
+	return 0
+}
+
+func (x *RestoreShardRequest) GetRepositoryLocation() string {
+	if x != nil {
+		return x.RepositoryLocation
+	}
+	return ""
+}
+
+func (x *RestoreShardRequest) GetFiles() []*SnapshotFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type RestoreShardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	DocCount      int64                  `protobuf:"varint,2,opt,name=doc_count,json=docCount,proto3" json:"doc_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreShardResponse) Reset() {
+	*x = RestoreShardResponse{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreShardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreShardResponse) ProtoMessage() {}
+
+func (x *RestoreShardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreShardResponse.ProtoReflect.Descriptor instead.
+func (*RestoreShardResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RestoreShardResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+func (x *RestoreShardResponse) GetDocCount() int64 {
+	if x != nil {
+		return x.DocCount
+	}
+	return 0
+}
+
+// ScanShardRequest pages through the documents of a shard in index order, so
+// the shard can be copied to another node when it is relocated
+type ScanShardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IndexName     string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	ShardId       int32                  `protobuf:"varint,2,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
+	From          int32                  `protobuf:"varint,3,opt,name=from,proto3" json:"from,omitempty"`
+	Size          int32                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanShardRequest) Reset() {
+	*x = ScanShardRequest{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanShardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanShardRequest) ProtoMessage() {}
+
+func (x *ScanShardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanShardRequest.ProtoReflect.Descriptor instead.
+func (*ScanShardRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ScanShardRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *ScanShardRequest) GetShardId() int32 {
+	if x != nil {
+		return x.ShardId
+	}
+	return 0
+}
+
+func (x *ScanShardRequest) GetFrom() int32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *ScanShardRequest) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type ScanShardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Documents     []*BulkIndexItem       `protobuf:"bytes,1,rep,name=documents,proto3" json:"documents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanShardResponse) Reset() {
+	*x = ScanShardResponse{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanShardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanShardResponse) ProtoMessage() {}
+
+func (x *ScanShardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanShardResponse.ProtoReflect.Descriptor instead.
+func (*ScanShardResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ScanShardResponse) GetDocuments() []*BulkIndexItem {
+	if x != nil {
+		return x.Documents
+	}
+	return nil
+}
+
+type IndexDocumentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IndexName     string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	ShardId       int32                  `protobuf:"varint,2,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
+	DocId         string                 `protobuf:"bytes,3,opt,name=doc_id,json=docId,proto3" json:"doc_id,omitempty"`
+	Document      *structpb.Struct       `protobuf:"bytes,4,opt,name=document,proto3" json:"document,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndexDocumentRequest) Reset() {
+	*x = IndexDocumentRequest{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexDocumentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexDocumentRequest) ProtoMessage() {}
+
+func (x *IndexDocumentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexDocumentRequest.ProtoReflect.Descriptor instead.
+func (*IndexDocumentRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *IndexDocumentRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *IndexDocumentRequest) GetShardId() int32 {
+	if x != nil {
+		return x.ShardId
+	}
+	return 0
+}
+
+func (x *IndexDocumentRequest) GetDocId() string {
+	if x != nil {
+		return x.DocId
+	}
+	return ""
+}
+
+func (x *IndexDocumentRequest) GetDocument() *structpb.Struct {
+	if x != nil {
+		return x.Document
+	}
+	return nil
+}
+
+type IndexDocumentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	DocId         string                 `protobuf:"bytes,2,opt,name=doc_id,json=docId,proto3" json:"doc_id,omitempty"`
+	Version       int64                  `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	Found         bool                   `protobuf:"varint,4,opt,name=found,proto3" json:"found,omitempty"` // Whether the document existed before it was indexed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndexDocumentResponse) Reset() {
+	*x = IndexDocumentResponse{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexDocumentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexDocumentResponse) ProtoMessage() {}
+
+func (x *IndexDocumentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexDocumentResponse.ProtoReflect.Descriptor instead.
+func (*IndexDocumentResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *IndexDocumentResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+func (x *IndexDocumentResponse) GetDocId() string {
+	if x != nil {
+		return x.DocId
+	}
+	return ""
+}
+
+func (x *IndexDocumentResponse) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *IndexDocumentResponse) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+type GetDocumentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IndexName     string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	ShardId       int32                  `protobuf:"varint,2,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
+	DocId         string                 `protobuf:"bytes,3,opt,name=doc_id,json=docId,proto3" json:"doc_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDocumentRequest) Reset() {
+	*x = GetDocumentRequest{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDocumentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDocumentRequest) ProtoMessage() {}
+
+func (x *GetDocumentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDocumentRequest.ProtoReflect.Descriptor instead.
+func (*GetDocumentRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *FlushShardRequest) GetIndexName() string {
+func (x *GetDocumentRequest) GetIndexName() string {
 	if x != nil {
 		return x.IndexName
 	}
 	return ""
 }
 
-func (x *FlushShardRequest) GetShardId() int32 {
+func (x *GetDocumentRequest) GetShardId() int32 {
 	if x != nil {
 		return x.ShardId
 	}
 	return 0
 }
 
-type FlushShardResponse struct {
+func (x *GetDocumentRequest) GetDocId() string {
+	if x != nil {
+		return x.DocId
+	}
+	return ""
+}
+
+type GetDocumentResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	Found         bool                   `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
+	DocId         string                 `protobuf:"bytes,2,opt,name=doc_id,json=docId,proto3" json:"doc_id,omitempty"`
+	Document      *structpb.Struct       `protobuf:"bytes,3,opt,name=document,proto3" json:"document,omitempty"`
+	Version       int64                  `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FlushShardResponse) Reset() {
-	*x = FlushShardResponse{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[9]
+func (x *GetDocumentResponse) Reset() {
+	*x = GetDocumentResponse{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FlushShardResponse) String() string {
+func (x *GetDocumentResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FlushShardResponse) ProtoMessage() {}
+func (*GetDocumentResponse) ProtoMessage() {}
 
-func (x *FlushShardResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[9]
+func (x *GetDocumentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -626,43 +1524,66 @@ func (x *FlushShardResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FlushShardResponse.ProtoReflect.Descriptor instead.
-func (*FlushShardResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use GetDocumentResponse.ProtoReflect.Descriptor instead.
+func (*GetDocumentResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *FlushShardResponse) GetAcknowledged() bool {
+func (x *GetDocumentResponse) GetFound() bool {
 	if x != nil {
-		return x.Acknowledged
+		return x.Found
 	}
 	return false
 }
 
-type IndexDocumentRequest struct {
+func (x *GetDocumentResponse) GetDocId() string {
+	if x != nil {
+		return x.DocId
+	}
+	return ""
+}
+
+func (x *GetDocumentResponse) GetDocument() *structpb.Struct {
+	if x != nil {
+		return x.Document
+	}
+	return nil
+}
+
+func (x *GetDocumentResponse) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+// TermVectorsRequest asks for the term vectors of a document's fields,
+// computed from its stored source
+type TermVectorsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	IndexName     string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
 	ShardId       int32                  `protobuf:"varint,2,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
 	DocId         string                 `protobuf:"bytes,3,opt,name=doc_id,json=docId,proto3" json:"doc_id,omitempty"`
-	Document      *structpb.Struct       `protobuf:"bytes,4,opt,name=document,proto3" json:"document,omitempty"`
+	Fields        []string               `protobuf:"bytes,4,rep,name=fields,proto3" json:"fields,omitempty"` // Fields to return, all string fields if empty
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *IndexDocumentRequest) Reset() {
-	*x = IndexDocumentRequest{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[10]
+func (x *TermVectorsRequest) Reset() {
+	*x = TermVectorsRequest{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *IndexDocumentRequest) String() string {
+func (x *TermVectorsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*IndexDocumentRequest) ProtoMessage() {}
+func (*TermVectorsRequest) ProtoMessage() {}
 
-func (x *IndexDocumentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[10]
+func (x *TermVectorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -673,63 +1594,63 @@ func (x *IndexDocumentRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use IndexDocumentRequest.ProtoReflect.Descriptor instead.
-func (*IndexDocumentRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use TermVectorsRequest.ProtoReflect.Descriptor instead.
+func (*TermVectorsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *IndexDocumentRequest) GetIndexName() string {
+func (x *TermVectorsRequest) GetIndexName() string {
 	if x != nil {
 		return x.IndexName
 	}
 	return ""
 }
 
-func (x *IndexDocumentRequest) GetShardId() int32 {
+func (x *TermVectorsRequest) GetShardId() int32 {
 	if x != nil {
 		return x.ShardId
 	}
 	return 0
 }
 
-func (x *IndexDocumentRequest) GetDocId() string {
+func (x *TermVectorsRequest) GetDocId() string {
 	if x != nil {
 		return x.DocId
 	}
 	return ""
 }
 
-func (x *IndexDocumentRequest) GetDocument() *structpb.Struct {
+func (x *TermVectorsRequest) GetFields() []string {
 	if x != nil {
-		return x.Document
+		return x.Fields
 	}
 	return nil
 }
 
-type IndexDocumentResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
-	DocId         string                 `protobuf:"bytes,2,opt,name=doc_id,json=docId,proto3" json:"doc_id,omitempty"`
-	Version       int64                  `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+type TermVectorsResponse struct {
+	state         protoimpl.MessageState      `protogen:"open.v1"`
+	Found         bool                        `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
+	DocId         string                      `protobuf:"bytes,2,opt,name=doc_id,json=docId,proto3" json:"doc_id,omitempty"`
+	TermVectors   map[string]*FieldTermVector `protobuf:"bytes,3,rep,name=term_vectors,json=termVectors,proto3" json:"term_vectors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // By field path
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *IndexDocumentResponse) Reset() {
-	*x = IndexDocumentResponse{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[11]
+func (x *TermVectorsResponse) Reset() {
+	*x = TermVectorsResponse{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *IndexDocumentResponse) String() string {
+func (x *TermVectorsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*IndexDocumentResponse) ProtoMessage() {}
+func (*TermVectorsResponse) ProtoMessage() {}
 
-func (x *IndexDocumentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[11]
+func (x *TermVectorsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -740,56 +1661,54 @@ func (x *IndexDocumentResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use IndexDocumentResponse.ProtoReflect.Descriptor instead.
-func (*IndexDocumentResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use TermVectorsResponse.ProtoReflect.Descriptor instead.
+func (*TermVectorsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *IndexDocumentResponse) GetAcknowledged() bool {
+func (x *TermVectorsResponse) GetFound() bool {
 	if x != nil {
-		return x.Acknowledged
+		return x.Found
 	}
 	return false
 }
 
-func (x *IndexDocumentResponse) GetDocId() string {
+func (x *TermVectorsResponse) GetDocId() string {
 	if x != nil {
 		return x.DocId
 	}
 	return ""
 }
 
-func (x *IndexDocumentResponse) GetVersion() int64 {
+func (x *TermVectorsResponse) GetTermVectors() map[string]*FieldTermVector {
 	if x != nil {
-		return x.Version
+		return x.TermVectors
 	}
-	return 0
+	return nil
 }
 
-type GetDocumentRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	IndexName     string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
-	ShardId       int32                  `protobuf:"varint,2,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
-	DocId         string                 `protobuf:"bytes,3,opt,name=doc_id,json=docId,proto3" json:"doc_id,omitempty"`
+type FieldTermVector struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Terms         map[string]*TermVectorTerm `protobuf:"bytes,1,rep,name=terms,proto3" json:"terms,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetDocumentRequest) Reset() {
-	*x = GetDocumentRequest{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[12]
+func (x *FieldTermVector) Reset() {
+	*x = FieldTermVector{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetDocumentRequest) String() string {
+func (x *FieldTermVector) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetDocumentRequest) ProtoMessage() {}
+func (*FieldTermVector) ProtoMessage() {}
 
-func (x *GetDocumentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[12]
+func (x *FieldTermVector) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -800,57 +1719,94 @@ func (x *GetDocumentRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetDocumentRequest.ProtoReflect.Descriptor instead.
-func (*GetDocumentRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use FieldTermVector.ProtoReflect.Descriptor instead.
+func (*FieldTermVector) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *GetDocumentRequest) GetIndexName() string {
+func (x *FieldTermVector) GetTerms() map[string]*TermVectorTerm {
 	if x != nil {
-		return x.IndexName
+		return x.Terms
 	}
-	return ""
+	return nil
 }
 
-func (x *GetDocumentRequest) GetShardId() int32 {
+type TermVectorTerm struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TermFreq      int32                  `protobuf:"varint,1,opt,name=term_freq,json=termFreq,proto3" json:"term_freq,omitempty"`
+	Tokens        []*TermVectorToken     `protobuf:"bytes,2,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TermVectorTerm) Reset() {
+	*x = TermVectorTerm{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TermVectorTerm) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TermVectorTerm) ProtoMessage() {}
+
+func (x *TermVectorTerm) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[28]
 	if x != nil {
-		return x.ShardId
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TermVectorTerm.ProtoReflect.Descriptor instead.
+func (*TermVectorTerm) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *TermVectorTerm) GetTermFreq() int32 {
+	if x != nil {
+		return x.TermFreq
 	}
 	return 0
 }
 
-func (x *GetDocumentRequest) GetDocId() string {
+func (x *TermVectorTerm) GetTokens() []*TermVectorToken {
 	if x != nil {
-		return x.DocId
+		return x.Tokens
 	}
-	return ""
+	return nil
 }
 
-type GetDocumentResponse struct {
+type TermVectorToken struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Found         bool                   `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
-	DocId         string                 `protobuf:"bytes,2,opt,name=doc_id,json=docId,proto3" json:"doc_id,omitempty"`
-	Document      *structpb.Struct       `protobuf:"bytes,3,opt,name=document,proto3" json:"document,omitempty"`
-	Version       int64                  `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	Position      int32                  `protobuf:"varint,1,opt,name=position,proto3" json:"position,omitempty"`
+	StartOffset   int32                  `protobuf:"varint,2,opt,name=start_offset,json=startOffset,proto3" json:"start_offset,omitempty"`
+	EndOffset     int32                  `protobuf:"varint,3,opt,name=end_offset,json=endOffset,proto3" json:"end_offset,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetDocumentResponse) Reset() {
-	*x = GetDocumentResponse{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[13]
+func (x *TermVectorToken) Reset() {
+	*x = TermVectorToken{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetDocumentResponse) String() string {
+func (x *TermVectorToken) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetDocumentResponse) ProtoMessage() {}
+func (*TermVectorToken) ProtoMessage() {}
 
-func (x *GetDocumentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[13]
+func (x *TermVectorToken) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -861,35 +1817,28 @@ func (x *GetDocumentResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetDocumentResponse.ProtoReflect.Descriptor instead.
-func (*GetDocumentResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{13}
-}
-
-func (x *GetDocumentResponse) GetFound() bool {
-	if x != nil {
-		return x.Found
-	}
-	return false
+// Deprecated: Use TermVectorToken.ProtoReflect.Descriptor instead.
+func (*TermVectorToken) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *GetDocumentResponse) GetDocId() string {
+func (x *TermVectorToken) GetPosition() int32 {
 	if x != nil {
-		return x.DocId
+		return x.Position
 	}
-	return ""
+	return 0
 }
 
-func (x *GetDocumentResponse) GetDocument() *structpb.Struct {
+func (x *TermVectorToken) GetStartOffset() int32 {
 	if x != nil {
-		return x.Document
+		return x.StartOffset
 	}
-	return nil
+	return 0
 }
 
-func (x *GetDocumentResponse) GetVersion() int64 {
+func (x *TermVectorToken) GetEndOffset() int32 {
 	if x != nil {
-		return x.Version
+		return x.EndOffset
 	}
 	return 0
 }
@@ -905,7 +1854,7 @@ type DeleteDocumentRequest struct {
 
 func (x *DeleteDocumentRequest) Reset() {
 	*x = DeleteDocumentRequest{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[14]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -917,7 +1866,7 @@ func (x *DeleteDocumentRequest) String() string {
 func (*DeleteDocumentRequest) ProtoMessage() {}
 
 func (x *DeleteDocumentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[14]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -930,7 +1879,7 @@ func (x *DeleteDocumentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteDocumentRequest.ProtoReflect.Descriptor instead.
 func (*DeleteDocumentRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{14}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *DeleteDocumentRequest) GetIndexName() string {
@@ -964,7 +1913,7 @@ type DeleteDocumentResponse struct {
 
 func (x *DeleteDocumentResponse) Reset() {
 	*x = DeleteDocumentResponse{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[15]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -976,7 +1925,7 @@ func (x *DeleteDocumentResponse) String() string {
 func (*DeleteDocumentResponse) ProtoMessage() {}
 
 func (x *DeleteDocumentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[15]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -989,7 +1938,7 @@ func (x *DeleteDocumentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteDocumentResponse.ProtoReflect.Descriptor instead.
 func (*DeleteDocumentResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{15}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *DeleteDocumentResponse) GetAcknowledged() bool {
@@ -1017,7 +1966,7 @@ type BulkIndexRequest struct {
 
 func (x *BulkIndexRequest) Reset() {
 	*x = BulkIndexRequest{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[16]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1029,7 +1978,7 @@ func (x *BulkIndexRequest) String() string {
 func (*BulkIndexRequest) ProtoMessage() {}
 
 func (x *BulkIndexRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[16]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1042,7 +1991,7 @@ func (x *BulkIndexRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BulkIndexRequest.ProtoReflect.Descriptor instead.
 func (*BulkIndexRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{16}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *BulkIndexRequest) GetIndexName() string {
@@ -1076,7 +2025,7 @@ type BulkIndexItem struct {
 
 func (x *BulkIndexItem) Reset() {
 	*x = BulkIndexItem{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[17]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1088,7 +2037,7 @@ func (x *BulkIndexItem) String() string {
 func (*BulkIndexItem) ProtoMessage() {}
 
 func (x *BulkIndexItem) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[17]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1101,7 +2050,7 @@ func (x *BulkIndexItem) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BulkIndexItem.ProtoReflect.Descriptor instead.
 func (*BulkIndexItem) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{17}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *BulkIndexItem) GetDocId() string {
@@ -1129,7 +2078,7 @@ type BulkIndexResponse struct {
 
 func (x *BulkIndexResponse) Reset() {
 	*x = BulkIndexResponse{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[18]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1141,7 +2090,7 @@ func (x *BulkIndexResponse) String() string {
 func (*BulkIndexResponse) ProtoMessage() {}
 
 func (x *BulkIndexResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[18]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1154,7 +2103,7 @@ func (x *BulkIndexResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BulkIndexResponse.ProtoReflect.Descriptor instead.
 func (*BulkIndexResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{18}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *BulkIndexResponse) GetHasErrors() bool {
@@ -1189,7 +2138,7 @@ type BulkIndexItemResponse struct {
 
 func (x *BulkIndexItemResponse) Reset() {
 	*x = BulkIndexItemResponse{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[19]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1201,7 +2150,7 @@ func (x *BulkIndexItemResponse) String() string {
 func (*BulkIndexItemResponse) ProtoMessage() {}
 
 func (x *BulkIndexItemResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[19]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1214,7 +2163,7 @@ func (x *BulkIndexItemResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BulkIndexItemResponse.ProtoReflect.Descriptor instead.
 func (*BulkIndexItemResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{19}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *BulkIndexItemResponse) GetAcknowledged() bool {
@@ -1248,13 +2197,17 @@ type SearchRequest struct {
 	Sort             []string               `protobuf:"bytes,6,rep,name=sort,proto3" json:"sort,omitempty"`
 	TrackTotalHits   bool                   `protobuf:"varint,7,opt,name=track_total_hits,json=trackTotalHits,proto3" json:"track_total_hits,omitempty"`
 	FilterExpression []byte                 `protobuf:"bytes,8,opt,name=filter_expression,json=filterExpression,proto3" json:"filter_expression,omitempty"` // Serialized expression tree for native C++ evaluation
+	TerminateAfter   int64                  `protobuf:"varint,9,opt,name=terminate_after,json=terminateAfter,proto3" json:"terminate_after,omitempty"`      // Stop collecting after this many matching documents (0 = no limit)
+	MinScore         float64                `protobuf:"fixed64,10,opt,name=min_score,json=minScore,proto3" json:"min_score,omitempty"`                      // Drop hits scoring below this threshold (0 = keep all)
+	CollapseField    string                 `protobuf:"bytes,11,opt,name=collapse_field,json=collapseField,proto3" json:"collapse_field,omitempty"`         // Keep only the best hits per distinct value of this field
+	CollapseSize     int32                  `protobuf:"varint,12,opt,name=collapse_size,json=collapseSize,proto3" json:"collapse_size,omitempty"`           // Hits kept per collapse value (0 = 1)
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
 
 func (x *SearchRequest) Reset() {
 	*x = SearchRequest{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[20]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1266,7 +2219,7 @@ func (x *SearchRequest) String() string {
 func (*SearchRequest) ProtoMessage() {}
 
 func (x *SearchRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[20]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1279,7 +2232,7 @@ func (x *SearchRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
 func (*SearchRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{20}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *SearchRequest) GetIndexName() string {
@@ -1335,23 +2288,52 @@ func (x *SearchRequest) GetFilterExpression() []byte {
 	if x != nil {
 		return x.FilterExpression
 	}
-	return nil
+	return nil
+}
+
+func (x *SearchRequest) GetTerminateAfter() int64 {
+	if x != nil {
+		return x.TerminateAfter
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetMinScore() float64 {
+	if x != nil {
+		return x.MinScore
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetCollapseField() string {
+	if x != nil {
+		return x.CollapseField
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetCollapseSize() int32 {
+	if x != nil {
+		return x.CollapseSize
+	}
+	return 0
 }
 
 type SearchResponse struct {
-	state         protoimpl.MessageState        `protogen:"open.v1"`
-	TookMillis    int64                         `protobuf:"varint,1,opt,name=took_millis,json=tookMillis,proto3" json:"took_millis,omitempty"`
-	TimedOut      bool                          `protobuf:"varint,2,opt,name=timed_out,json=timedOut,proto3" json:"timed_out,omitempty"`
-	Shards        *ShardSearchStats             `protobuf:"bytes,3,opt,name=shards,proto3" json:"shards,omitempty"`
-	Hits          *SearchHits                   `protobuf:"bytes,4,opt,name=hits,proto3" json:"hits,omitempty"`
-	Aggregations  map[string]*AggregationResult `protobuf:"bytes,5,rep,name=aggregations,proto3" json:"aggregations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState        `protogen:"open.v1"`
+	TookMillis      int64                         `protobuf:"varint,1,opt,name=took_millis,json=tookMillis,proto3" json:"took_millis,omitempty"`
+	TimedOut        bool                          `protobuf:"varint,2,opt,name=timed_out,json=timedOut,proto3" json:"timed_out,omitempty"`
+	Shards          *ShardSearchStats             `protobuf:"bytes,3,opt,name=shards,proto3" json:"shards,omitempty"`
+	Hits            *SearchHits                   `protobuf:"bytes,4,opt,name=hits,proto3" json:"hits,omitempty"`
+	Aggregations    map[string]*AggregationResult `protobuf:"bytes,5,rep,name=aggregations,proto3" json:"aggregations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	TerminatedEarly bool                          `protobuf:"varint,6,opt,name=terminated_early,json=terminatedEarly,proto3" json:"terminated_early,omitempty"` // Collection stopped at terminate_after
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *SearchResponse) Reset() {
 	*x = SearchResponse{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[21]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1363,7 +2345,7 @@ func (x *SearchResponse) String() string {
 func (*SearchResponse) ProtoMessage() {}
 
 func (x *SearchResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[21]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1376,7 +2358,7 @@ func (x *SearchResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
 func (*SearchResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{21}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *SearchResponse) GetTookMillis() int64 {
@@ -1414,6 +2396,13 @@ func (x *SearchResponse) GetAggregations() map[string]*AggregationResult {
 	return nil
 }
 
+func (x *SearchResponse) GetTerminatedEarly() bool {
+	if x != nil {
+		return x.TerminatedEarly
+	}
+	return false
+}
+
 type ShardSearchStats struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Total         int32                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
@@ -1425,7 +2414,7 @@ type ShardSearchStats struct {
 
 func (x *ShardSearchStats) Reset() {
 	*x = ShardSearchStats{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[22]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1437,7 +2426,7 @@ func (x *ShardSearchStats) String() string {
 func (*ShardSearchStats) ProtoMessage() {}
 
 func (x *ShardSearchStats) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[22]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1450,7 +2439,7 @@ func (x *ShardSearchStats) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ShardSearchStats.ProtoReflect.Descriptor instead.
 func (*ShardSearchStats) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{22}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *ShardSearchStats) GetTotal() int32 {
@@ -1485,7 +2474,7 @@ type SearchHits struct {
 
 func (x *SearchHits) Reset() {
 	*x = SearchHits{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[23]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1497,7 +2486,7 @@ func (x *SearchHits) String() string {
 func (*SearchHits) ProtoMessage() {}
 
 func (x *SearchHits) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[23]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1510,7 +2499,7 @@ func (x *SearchHits) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SearchHits.ProtoReflect.Descriptor instead.
 func (*SearchHits) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{23}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{39}
 }
 
 func (x *SearchHits) GetTotal() *TotalHits {
@@ -1544,7 +2533,7 @@ type TotalHits struct {
 
 func (x *TotalHits) Reset() {
 	*x = TotalHits{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[24]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1556,7 +2545,7 @@ func (x *TotalHits) String() string {
 func (*TotalHits) ProtoMessage() {}
 
 func (x *TotalHits) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[24]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1569,7 +2558,7 @@ func (x *TotalHits) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TotalHits.ProtoReflect.Descriptor instead.
 func (*TotalHits) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{24}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *TotalHits) GetValue() int64 {
@@ -1587,18 +2576,19 @@ func (x *TotalHits) GetRelation() string {
 }
 
 type SearchHit struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
-	Source        *structpb.Struct       `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
-	Sort          []float64              `protobuf:"fixed64,4,rep,packed,name=sort,proto3" json:"sort,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Score          float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	Source         *structpb.Struct       `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	Sort           []float64              `protobuf:"fixed64,4,rep,packed,name=sort,proto3" json:"sort,omitempty"`
+	MatchedQueries []string               `protobuf:"bytes,5,rep,name=matched_queries,json=matchedQueries,proto3" json:"matched_queries,omitempty"` // names of the named clauses the hit matched
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *SearchHit) Reset() {
 	*x = SearchHit{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[25]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1610,7 +2600,7 @@ func (x *SearchHit) String() string {
 func (*SearchHit) ProtoMessage() {}
 
 func (x *SearchHit) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[25]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1623,7 +2613,7 @@ func (x *SearchHit) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SearchHit.ProtoReflect.Descriptor instead.
 func (*SearchHit) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{25}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *SearchHit) GetId() string {
@@ -1654,10 +2644,17 @@ func (x *SearchHit) GetSort() []float64 {
 	return nil
 }
 
+func (x *SearchHit) GetMatchedQueries() []string {
+	if x != nil {
+		return x.MatchedQueries
+	}
+	return nil
+}
+
 type AggregationResult struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	Type  string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // terms, stats, histogram, date_histogram, percentiles, cardinality, extended_stats
-	// Terms aggregation
+	Type  string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // terms, stats, histogram, date_histogram, percentiles, cardinality, extended_stats, avg, min, max, sum, value_count, range, filters
+	// Terms aggregation, Range aggregation, Filters aggregation
 	Buckets []*AggregationBucket `protobuf:"bytes,2,rep,name=buckets,proto3" json:"buckets,omitempty"`
 	// Stats/Extended Stats aggregation
 	Count                   int64   `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
@@ -1680,7 +2677,7 @@ type AggregationResult struct {
 
 func (x *AggregationResult) Reset() {
 	*x = AggregationResult{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[26]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1692,7 +2689,7 @@ func (x *AggregationResult) String() string {
 func (*AggregationResult) ProtoMessage() {}
 
 func (x *AggregationResult) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[26]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1705,7 +2702,7 @@ func (x *AggregationResult) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AggregationResult.ProtoReflect.Descriptor instead.
 func (*AggregationResult) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{26}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{42}
 }
 
 func (x *AggregationResult) GetType() string {
@@ -1808,17 +2805,20 @@ func (x *AggregationResult) GetValue() int64 {
 
 type AggregationBucket struct {
 	state           protoimpl.MessageState        `protogen:"open.v1"`
-	Key             string                        `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`                                   // For terms, date histogram key_as_string
+	Key             string                        `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`                                   // For terms, date histogram key_as_string, range
 	NumericKey      float64                       `protobuf:"fixed64,2,opt,name=numeric_key,json=numericKey,proto3" json:"numeric_key,omitempty"` // For histogram, date histogram timestamp
 	DocCount        int64                         `protobuf:"varint,3,opt,name=doc_count,json=docCount,proto3" json:"doc_count,omitempty"`
 	SubAggregations map[string]*AggregationResult `protobuf:"bytes,4,rep,name=sub_aggregations,json=subAggregations,proto3" json:"sub_aggregations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // For nested aggregations
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	// Range aggregation fields
+	From          *float64 `protobuf:"fixed64,5,opt,name=from,proto3,oneof" json:"from,omitempty"` // Lower bound for range (omitted if unbounded)
+	To            *float64 `protobuf:"fixed64,6,opt,name=to,proto3,oneof" json:"to,omitempty"`     // Upper bound for range (omitted if unbounded)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *AggregationBucket) Reset() {
 	*x = AggregationBucket{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[27]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1830,7 +2830,7 @@ func (x *AggregationBucket) String() string {
 func (*AggregationBucket) ProtoMessage() {}
 
 func (x *AggregationBucket) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[27]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1843,7 +2843,7 @@ func (x *AggregationBucket) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AggregationBucket.ProtoReflect.Descriptor instead.
 func (*AggregationBucket) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{27}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *AggregationBucket) GetKey() string {
@@ -1874,6 +2874,20 @@ func (x *AggregationBucket) GetSubAggregations() map[string]*AggregationResult {
 	return nil
 }
 
+func (x *AggregationBucket) GetFrom() float64 {
+	if x != nil && x.From != nil {
+		return *x.From
+	}
+	return 0
+}
+
+func (x *AggregationBucket) GetTo() float64 {
+	if x != nil && x.To != nil {
+		return *x.To
+	}
+	return 0
+}
+
 type CountRequest struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	IndexName        string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
@@ -1886,7 +2900,7 @@ type CountRequest struct {
 
 func (x *CountRequest) Reset() {
 	*x = CountRequest{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[28]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1895,10 +2909,270 @@ func (x *CountRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CountRequest) ProtoMessage() {}
+func (*CountRequest) ProtoMessage() {}
+
+func (x *CountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountRequest.ProtoReflect.Descriptor instead.
+func (*CountRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *CountRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *CountRequest) GetShardId() int32 {
+	if x != nil {
+		return x.ShardId
+	}
+	return 0
+}
+
+func (x *CountRequest) GetQuery() []byte {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *CountRequest) GetFilterExpression() []byte {
+	if x != nil {
+		return x.FilterExpression
+	}
+	return nil
+}
+
+type CountResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int64                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountResponse) Reset() {
+	*x = CountResponse{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountResponse) ProtoMessage() {}
+
+func (x *CountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountResponse.ProtoReflect.Descriptor instead.
+func (*CountResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *CountResponse) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type SuggestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IndexName     string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	ShardId       int32                  `protobuf:"varint,2,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
+	Suggest       []byte                 `protobuf:"bytes,3,opt,name=suggest,proto3" json:"suggest,omitempty"` // Serialized suggest block of the search request
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestRequest) Reset() {
+	*x = SuggestRequest{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestRequest) ProtoMessage() {}
+
+func (x *SuggestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuggestRequest.ProtoReflect.Descriptor instead.
+func (*SuggestRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *SuggestRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *SuggestRequest) GetShardId() int32 {
+	if x != nil {
+		return x.ShardId
+	}
+	return 0
+}
+
+func (x *SuggestRequest) GetSuggest() []byte {
+	if x != nil {
+		return x.Suggest
+	}
+	return nil
+}
+
+type SuggestResponse struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Suggestions   map[string]*SuggestEntries `protobuf:"bytes,1,rep,name=suggestions,proto3" json:"suggestions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // suggester name -> entries
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestResponse) Reset() {
+	*x = SuggestResponse{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestResponse) ProtoMessage() {}
+
+func (x *SuggestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuggestResponse.ProtoReflect.Descriptor instead.
+func (*SuggestResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *SuggestResponse) GetSuggestions() map[string]*SuggestEntries {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+type SuggestEntries struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*SuggestEntry        `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestEntries) Reset() {
+	*x = SuggestEntries{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestEntries) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestEntries) ProtoMessage() {}
+
+func (x *SuggestEntries) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuggestEntries.ProtoReflect.Descriptor instead.
+func (*SuggestEntries) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *SuggestEntries) GetEntries() []*SuggestEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type SuggestEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Offset        int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Length        int32                  `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	Options       []*SuggestOption       `protobuf:"bytes,4,rep,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestEntry) Reset() {
+	*x = SuggestEntry{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestEntry) ProtoMessage() {}
 
-func (x *CountRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[28]
+func (x *SuggestEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[49]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1909,61 +3183,65 @@ func (x *CountRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CountRequest.ProtoReflect.Descriptor instead.
-func (*CountRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{28}
+// Deprecated: Use SuggestEntry.ProtoReflect.Descriptor instead.
+func (*SuggestEntry) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{49}
 }
 
-func (x *CountRequest) GetIndexName() string {
+func (x *SuggestEntry) GetText() string {
 	if x != nil {
-		return x.IndexName
+		return x.Text
 	}
 	return ""
 }
 
-func (x *CountRequest) GetShardId() int32 {
+func (x *SuggestEntry) GetOffset() int32 {
 	if x != nil {
-		return x.ShardId
+		return x.Offset
 	}
 	return 0
 }
 
-func (x *CountRequest) GetQuery() []byte {
+func (x *SuggestEntry) GetLength() int32 {
 	if x != nil {
-		return x.Query
+		return x.Length
 	}
-	return nil
+	return 0
 }
 
-func (x *CountRequest) GetFilterExpression() []byte {
+func (x *SuggestEntry) GetOptions() []*SuggestOption {
 	if x != nil {
-		return x.FilterExpression
+		return x.Options
 	}
 	return nil
 }
 
-type CountResponse struct {
+type SuggestOption struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Count         int64                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	Freq          int64                  `protobuf:"varint,3,opt,name=freq,proto3" json:"freq,omitempty"`               // Term suggester: documents holding the term
+	DocId         string                 `protobuf:"bytes,4,opt,name=doc_id,json=docId,proto3" json:"doc_id,omitempty"` // Completion suggester: document of the input
+	Source        *structpb.Struct       `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CountResponse) Reset() {
-	*x = CountResponse{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[29]
+func (x *SuggestOption) Reset() {
+	*x = SuggestOption{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CountResponse) String() string {
+func (x *SuggestOption) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CountResponse) ProtoMessage() {}
+func (*SuggestOption) ProtoMessage() {}
 
-func (x *CountResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[29]
+func (x *SuggestOption) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1974,18 +3252,46 @@ func (x *CountResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CountResponse.ProtoReflect.Descriptor instead.
-func (*CountResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{29}
+// Deprecated: Use SuggestOption.ProtoReflect.Descriptor instead.
+func (*SuggestOption) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{50}
 }
 
-func (x *CountResponse) GetCount() int64 {
+func (x *SuggestOption) GetText() string {
 	if x != nil {
-		return x.Count
+		return x.Text
+	}
+	return ""
+}
+
+func (x *SuggestOption) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SuggestOption) GetFreq() int64 {
+	if x != nil {
+		return x.Freq
 	}
 	return 0
 }
 
+func (x *SuggestOption) GetDocId() string {
+	if x != nil {
+		return x.DocId
+	}
+	return ""
+}
+
+func (x *SuggestOption) GetSource() *structpb.Struct {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
 type GetShardStatsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	IndexName     string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
@@ -1996,7 +3302,7 @@ type GetShardStatsRequest struct {
 
 func (x *GetShardStatsRequest) Reset() {
 	*x = GetShardStatsRequest{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[30]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[51]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2008,7 +3314,7 @@ func (x *GetShardStatsRequest) String() string {
 func (*GetShardStatsRequest) ProtoMessage() {}
 
 func (x *GetShardStatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[30]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[51]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2021,7 +3327,7 @@ func (x *GetShardStatsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetShardStatsRequest.ProtoReflect.Descriptor instead.
 func (*GetShardStatsRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{30}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{51}
 }
 
 func (x *GetShardStatsRequest) GetIndexName() string {
@@ -2050,13 +3356,17 @@ type ShardStats struct {
 	SearchQueriesTimeMillis int64                  `protobuf:"varint,8,opt,name=search_queries_time_millis,json=searchQueriesTimeMillis,proto3" json:"search_queries_time_millis,omitempty"`
 	IndexingTotal           int64                  `protobuf:"varint,9,opt,name=indexing_total,json=indexingTotal,proto3" json:"indexing_total,omitempty"`
 	IndexingTimeMillis      int64                  `protobuf:"varint,10,opt,name=indexing_time_millis,json=indexingTimeMillis,proto3" json:"indexing_time_millis,omitempty"`
+	RefreshTotal            int64                  `protobuf:"varint,11,opt,name=refresh_total,json=refreshTotal,proto3" json:"refresh_total,omitempty"`
+	RefreshTimeMillis       int64                  `protobuf:"varint,12,opt,name=refresh_time_millis,json=refreshTimeMillis,proto3" json:"refresh_time_millis,omitempty"`
+	MergeTotal              int64                  `protobuf:"varint,13,opt,name=merge_total,json=mergeTotal,proto3" json:"merge_total,omitempty"`
+	MergeTimeMillis         int64                  `protobuf:"varint,14,opt,name=merge_time_millis,json=mergeTimeMillis,proto3" json:"merge_time_millis,omitempty"`
 	unknownFields           protoimpl.UnknownFields
 	sizeCache               protoimpl.SizeCache
 }
 
 func (x *ShardStats) Reset() {
 	*x = ShardStats{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[31]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2068,7 +3378,7 @@ func (x *ShardStats) String() string {
 func (*ShardStats) ProtoMessage() {}
 
 func (x *ShardStats) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[31]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2081,7 +3391,7 @@ func (x *ShardStats) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ShardStats.ProtoReflect.Descriptor instead.
 func (*ShardStats) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{31}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{52}
 }
 
 func (x *ShardStats) GetIndexName() string {
@@ -2154,6 +3464,224 @@ func (x *ShardStats) GetIndexingTimeMillis() int64 {
 	return 0
 }
 
+func (x *ShardStats) GetRefreshTotal() int64 {
+	if x != nil {
+		return x.RefreshTotal
+	}
+	return 0
+}
+
+func (x *ShardStats) GetRefreshTimeMillis() int64 {
+	if x != nil {
+		return x.RefreshTimeMillis
+	}
+	return 0
+}
+
+func (x *ShardStats) GetMergeTotal() int64 {
+	if x != nil {
+		return x.MergeTotal
+	}
+	return 0
+}
+
+func (x *ShardStats) GetMergeTimeMillis() int64 {
+	if x != nil {
+		return x.MergeTimeMillis
+	}
+	return 0
+}
+
+// GetSegmentStatsRequest lists the segments of a shard's index
+type GetSegmentStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IndexName     string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	ShardId       int32                  `protobuf:"varint,2,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSegmentStatsRequest) Reset() {
+	*x = GetSegmentStatsRequest{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSegmentStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSegmentStatsRequest) ProtoMessage() {}
+
+func (x *GetSegmentStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSegmentStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetSegmentStatsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *GetSegmentStatsRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *GetSegmentStatsRequest) GetShardId() int32 {
+	if x != nil {
+		return x.ShardId
+	}
+	return 0
+}
+
+// SegmentStats describes one segment of a shard's index
+type SegmentStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	NumDocs       int64                  `protobuf:"varint,2,opt,name=num_docs,json=numDocs,proto3" json:"num_docs,omitempty"`
+	DeletedDocs   int64                  `protobuf:"varint,3,opt,name=deleted_docs,json=deletedDocs,proto3" json:"deleted_docs,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,4,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SegmentStats) Reset() {
+	*x = SegmentStats{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SegmentStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SegmentStats) ProtoMessage() {}
+
+func (x *SegmentStats) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SegmentStats.ProtoReflect.Descriptor instead.
+func (*SegmentStats) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *SegmentStats) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SegmentStats) GetNumDocs() int64 {
+	if x != nil {
+		return x.NumDocs
+	}
+	return 0
+}
+
+func (x *SegmentStats) GetDeletedDocs() int64 {
+	if x != nil {
+		return x.DeletedDocs
+	}
+	return 0
+}
+
+func (x *SegmentStats) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+type GetSegmentStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IndexName     string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	ShardId       int32                  `protobuf:"varint,2,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
+	IsPrimary     bool                   `protobuf:"varint,3,opt,name=is_primary,json=isPrimary,proto3" json:"is_primary,omitempty"`
+	Segments      []*SegmentStats        `protobuf:"bytes,4,rep,name=segments,proto3" json:"segments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSegmentStatsResponse) Reset() {
+	*x = GetSegmentStatsResponse{}
+	mi := &file_pkg_common_proto_data_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSegmentStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSegmentStatsResponse) ProtoMessage() {}
+
+func (x *GetSegmentStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_data_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSegmentStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetSegmentStatsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *GetSegmentStatsResponse) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *GetSegmentStatsResponse) GetShardId() int32 {
+	if x != nil {
+		return x.ShardId
+	}
+	return 0
+}
+
+func (x *GetSegmentStatsResponse) GetIsPrimary() bool {
+	if x != nil {
+		return x.IsPrimary
+	}
+	return false
+}
+
+func (x *GetSegmentStatsResponse) GetSegments() []*SegmentStats {
+	if x != nil {
+		return x.Segments
+	}
+	return nil
+}
+
 type GetNodeStatsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	IncludeShards bool                   `protobuf:"varint,1,opt,name=include_shards,json=includeShards,proto3" json:"include_shards,omitempty"`
@@ -2163,7 +3691,7 @@ type GetNodeStatsRequest struct {
 
 func (x *GetNodeStatsRequest) Reset() {
 	*x = GetNodeStatsRequest{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[32]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2175,7 +3703,7 @@ func (x *GetNodeStatsRequest) String() string {
 func (*GetNodeStatsRequest) ProtoMessage() {}
 
 func (x *GetNodeStatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[32]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2188,7 +3716,7 @@ func (x *GetNodeStatsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetNodeStatsRequest.ProtoReflect.Descriptor instead.
 func (*GetNodeStatsRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{32}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{56}
 }
 
 func (x *GetNodeStatsRequest) GetIncludeShards() bool {
@@ -2209,13 +3737,14 @@ type DataNodeStats struct {
 	DiskUsagePercent   float64                `protobuf:"fixed64,7,opt,name=disk_usage_percent,json=diskUsagePercent,proto3" json:"disk_usage_percent,omitempty"`
 	UptimeSeconds      int64                  `protobuf:"varint,8,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
 	Shards             []*ShardStats          `protobuf:"bytes,9,rep,name=shards,proto3" json:"shards,omitempty"`
+	DiskTotalBytes     int64                  `protobuf:"varint,10,opt,name=disk_total_bytes,json=diskTotalBytes,proto3" json:"disk_total_bytes,omitempty"`
 	unknownFields      protoimpl.UnknownFields
 	sizeCache          protoimpl.SizeCache
 }
 
 func (x *DataNodeStats) Reset() {
 	*x = DataNodeStats{}
-	mi := &file_pkg_common_proto_data_proto_msgTypes[33]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[57]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2227,7 +3756,7 @@ func (x *DataNodeStats) String() string {
 func (*DataNodeStats) ProtoMessage() {}
 
 func (x *DataNodeStats) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_data_proto_msgTypes[33]
+	mi := &file_pkg_common_proto_data_proto_msgTypes[57]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2240,7 +3769,7 @@ func (x *DataNodeStats) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DataNodeStats.ProtoReflect.Descriptor instead.
 func (*DataNodeStats) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{33}
+	return file_pkg_common_proto_data_proto_rawDescGZIP(), []int{57}
 }
 
 func (x *DataNodeStats) GetNodeId() string {
@@ -2306,6 +3835,13 @@ func (x *DataNodeStats) GetShards() []*ShardStats {
 	return nil
 }
 
+func (x *DataNodeStats) GetDiskTotalBytes() int64 {
+	if x != nil {
+		return x.DiskTotalBytes
+	}
+	return 0
+}
+
 var File_pkg_common_proto_data_proto protoreflect.FileDescriptor
 
 const file_pkg_common_proto_data_proto_rawDesc = "" +
@@ -2329,7 +3865,17 @@ const file_pkg_common_proto_data_proto_rawDesc = "" +
 	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
 	"\bshard_id\x18\x02 \x01(\x05R\ashardId\"9\n" +
 	"\x13DeleteShardResponse\x12\"\n" +
-	"\facknowledged\x18\x01 \x01(\bR\facknowledged\"O\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\"\xce\x01\n" +
+	"\x1aUpdateShardSettingsRequest\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12T\n" +
+	"\bsettings\x18\x02 \x03(\v28.quidditch.data.UpdateShardSettingsRequest.SettingsEntryR\bsettings\x1a;\n" +
+	"\rSettingsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"h\n" +
+	"\x1bUpdateShardSettingsResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12%\n" +
+	"\x0eshards_updated\x18\x02 \x01(\x05R\rshardsUpdated\"O\n" +
 	"\x13GetShardInfoRequest\x12\x1d\n" +
 	"\n" +
 	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
@@ -2366,17 +3912,59 @@ const file_pkg_common_proto_data_proto_rawDesc = "" +
 	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
 	"\bshard_id\x18\x02 \x01(\x05R\ashardId\"8\n" +
 	"\x12FlushShardResponse\x12\"\n" +
-	"\facknowledged\x18\x01 \x01(\bR\facknowledged\"\x9c\x01\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\"w\n" +
+	"\x11ForceMergeRequest\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
+	"\bshard_id\x18\x02 \x01(\x05R\ashardId\x12(\n" +
+	"\x10max_num_segments\x18\x03 \x01(\x05R\x0emaxNumSegments\"\xec\x01\n" +
+	"\x12ForceMergeResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12.\n" +
+	"\x13docs_deleted_before\x18\x02 \x01(\x03R\x11docsDeletedBefore\x12,\n" +
+	"\x12docs_deleted_after\x18\x03 \x01(\x03R\x10docsDeletedAfter\x12*\n" +
+	"\x11size_bytes_before\x18\x04 \x01(\x03R\x0fsizeBytesBefore\x12(\n" +
+	"\x10size_bytes_after\x18\x05 \x01(\x03R\x0esizeBytesAfter\"m\n" +
+	"\fSnapshotFile\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04blob\x18\x02 \x01(\tR\x04blob\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x03 \x01(\x03R\tsizeBytes\x12\x16\n" +
+	"\x06reused\x18\x04 \x01(\bR\x06reused\"\x81\x01\n" +
+	"\x14SnapshotShardRequest\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
+	"\bshard_id\x18\x02 \x01(\x05R\ashardId\x12/\n" +
+	"\x13repository_location\x18\x03 \x01(\tR\x12repositoryLocation\"K\n" +
+	"\x15SnapshotShardResponse\x122\n" +
+	"\x05files\x18\x01 \x03(\v2\x1c.quidditch.data.SnapshotFileR\x05files\"\xb4\x01\n" +
+	"\x13RestoreShardRequest\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
+	"\bshard_id\x18\x02 \x01(\x05R\ashardId\x12/\n" +
+	"\x13repository_location\x18\x03 \x01(\tR\x12repositoryLocation\x122\n" +
+	"\x05files\x18\x04 \x03(\v2\x1c.quidditch.data.SnapshotFileR\x05files\"W\n" +
+	"\x14RestoreShardResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12\x1b\n" +
+	"\tdoc_count\x18\x02 \x01(\x03R\bdocCount\"t\n" +
+	"\x10ScanShardRequest\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
+	"\bshard_id\x18\x02 \x01(\x05R\ashardId\x12\x12\n" +
+	"\x04from\x18\x03 \x01(\x05R\x04from\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x05R\x04size\"P\n" +
+	"\x11ScanShardResponse\x12;\n" +
+	"\tdocuments\x18\x01 \x03(\v2\x1d.quidditch.data.BulkIndexItemR\tdocuments\"\x9c\x01\n" +
 	"\x14IndexDocumentRequest\x12\x1d\n" +
 	"\n" +
 	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
 	"\bshard_id\x18\x02 \x01(\x05R\ashardId\x12\x15\n" +
 	"\x06doc_id\x18\x03 \x01(\tR\x05docId\x123\n" +
-	"\bdocument\x18\x04 \x01(\v2\x17.google.protobuf.StructR\bdocument\"l\n" +
+	"\bdocument\x18\x04 \x01(\v2\x17.google.protobuf.StructR\bdocument\"\x82\x01\n" +
 	"\x15IndexDocumentResponse\x12\"\n" +
 	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12\x15\n" +
 	"\x06doc_id\x18\x02 \x01(\tR\x05docId\x12\x18\n" +
-	"\aversion\x18\x03 \x01(\x03R\aversion\"e\n" +
+	"\aversion\x18\x03 \x01(\x03R\aversion\x12\x14\n" +
+	"\x05found\x18\x04 \x01(\bR\x05found\"e\n" +
 	"\x12GetDocumentRequest\x12\x1d\n" +
 	"\n" +
 	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
@@ -2386,7 +3974,34 @@ const file_pkg_common_proto_data_proto_rawDesc = "" +
 	"\x05found\x18\x01 \x01(\bR\x05found\x12\x15\n" +
 	"\x06doc_id\x18\x02 \x01(\tR\x05docId\x123\n" +
 	"\bdocument\x18\x03 \x01(\v2\x17.google.protobuf.StructR\bdocument\x12\x18\n" +
-	"\aversion\x18\x04 \x01(\x03R\aversion\"h\n" +
+	"\aversion\x18\x04 \x01(\x03R\aversion\"}\n" +
+	"\x12TermVectorsRequest\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
+	"\bshard_id\x18\x02 \x01(\x05R\ashardId\x12\x15\n" +
+	"\x06doc_id\x18\x03 \x01(\tR\x05docId\x12\x16\n" +
+	"\x06fields\x18\x04 \x03(\tR\x06fields\"\xfc\x01\n" +
+	"\x13TermVectorsResponse\x12\x14\n" +
+	"\x05found\x18\x01 \x01(\bR\x05found\x12\x15\n" +
+	"\x06doc_id\x18\x02 \x01(\tR\x05docId\x12W\n" +
+	"\fterm_vectors\x18\x03 \x03(\v24.quidditch.data.TermVectorsResponse.TermVectorsEntryR\vtermVectors\x1a_\n" +
+	"\x10TermVectorsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x125\n" +
+	"\x05value\x18\x02 \x01(\v2\x1f.quidditch.data.FieldTermVectorR\x05value:\x028\x01\"\xad\x01\n" +
+	"\x0fFieldTermVector\x12@\n" +
+	"\x05terms\x18\x01 \x03(\v2*.quidditch.data.FieldTermVector.TermsEntryR\x05terms\x1aX\n" +
+	"\n" +
+	"TermsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x124\n" +
+	"\x05value\x18\x02 \x01(\v2\x1e.quidditch.data.TermVectorTermR\x05value:\x028\x01\"f\n" +
+	"\x0eTermVectorTerm\x12\x1b\n" +
+	"\tterm_freq\x18\x01 \x01(\x05R\btermFreq\x127\n" +
+	"\x06tokens\x18\x02 \x03(\v2\x1f.quidditch.data.TermVectorTokenR\x06tokens\"o\n" +
+	"\x0fTermVectorToken\x12\x1a\n" +
+	"\bposition\x18\x01 \x01(\x05R\bposition\x12!\n" +
+	"\fstart_offset\x18\x02 \x01(\x05R\vstartOffset\x12\x1d\n" +
+	"\n" +
+	"end_offset\x18\x03 \x01(\x05R\tendOffset\"h\n" +
 	"\x15DeleteDocumentRequest\x12\x1d\n" +
 	"\n" +
 	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
@@ -2412,7 +4027,7 @@ const file_pkg_common_proto_data_proto_rawDesc = "" +
 	"\x15BulkIndexItemResponse\x12\"\n" +
 	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12\x15\n" +
 	"\x06doc_id\x18\x02 \x01(\tR\x05docId\x12\x14\n" +
-	"\x05error\x18\x03 \x01(\tR\x05error\"\xf2\x01\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\x84\x03\n" +
 	"\rSearchRequest\x12\x1d\n" +
 	"\n" +
 	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
@@ -2422,14 +4037,20 @@ const file_pkg_common_proto_data_proto_rawDesc = "" +
 	"\x04size\x18\x05 \x01(\x05R\x04size\x12\x12\n" +
 	"\x04sort\x18\x06 \x03(\tR\x04sort\x12(\n" +
 	"\x10track_total_hits\x18\a \x01(\bR\x0etrackTotalHits\x12+\n" +
-	"\x11filter_expression\x18\b \x01(\fR\x10filterExpression\"\xf2\x02\n" +
+	"\x11filter_expression\x18\b \x01(\fR\x10filterExpression\x12'\n" +
+	"\x0fterminate_after\x18\t \x01(\x03R\x0eterminateAfter\x12\x1b\n" +
+	"\tmin_score\x18\n" +
+	" \x01(\x01R\bminScore\x12%\n" +
+	"\x0ecollapse_field\x18\v \x01(\tR\rcollapseField\x12#\n" +
+	"\rcollapse_size\x18\f \x01(\x05R\fcollapseSize\"\x9d\x03\n" +
 	"\x0eSearchResponse\x12\x1f\n" +
 	"\vtook_millis\x18\x01 \x01(\x03R\n" +
 	"tookMillis\x12\x1b\n" +
 	"\ttimed_out\x18\x02 \x01(\bR\btimedOut\x128\n" +
 	"\x06shards\x18\x03 \x01(\v2 .quidditch.data.ShardSearchStatsR\x06shards\x12.\n" +
 	"\x04hits\x18\x04 \x01(\v2\x1a.quidditch.data.SearchHitsR\x04hits\x12T\n" +
-	"\faggregations\x18\x05 \x03(\v20.quidditch.data.SearchResponse.AggregationsEntryR\faggregations\x1ab\n" +
+	"\faggregations\x18\x05 \x03(\v20.quidditch.data.SearchResponse.AggregationsEntryR\faggregations\x12)\n" +
+	"\x10terminated_early\x18\x06 \x01(\bR\x0fterminatedEarly\x1ab\n" +
 	"\x11AggregationsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x127\n" +
 	"\x05value\x18\x02 \x01(\v2!.quidditch.data.AggregationResultR\x05value:\x028\x01\"`\n" +
@@ -2446,12 +4067,13 @@ const file_pkg_common_proto_data_proto_rawDesc = "" +
 	"\x04hits\x18\x03 \x03(\v2\x19.quidditch.data.SearchHitR\x04hits\"=\n" +
 	"\tTotalHits\x12\x14\n" +
 	"\x05value\x18\x01 \x01(\x03R\x05value\x12\x1a\n" +
-	"\brelation\x18\x02 \x01(\tR\brelation\"v\n" +
+	"\brelation\x18\x02 \x01(\tR\brelation\"\x9f\x01\n" +
 	"\tSearchHit\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
 	"\x05score\x18\x02 \x01(\x01R\x05score\x12/\n" +
 	"\x06source\x18\x03 \x01(\v2\x17.google.protobuf.StructR\x06source\x12\x12\n" +
-	"\x04sort\x18\x04 \x03(\x01R\x04sort\"\xbb\x04\n" +
+	"\x04sort\x18\x04 \x03(\x01R\x04sort\x12'\n" +
+	"\x0fmatched_queries\x18\x05 \x03(\tR\x0ematchedQueries\"\xbb\x04\n" +
 	"\x11AggregationResult\x12\x12\n" +
 	"\x04type\x18\x01 \x01(\tR\x04type\x12;\n" +
 	"\abuckets\x18\x02 \x03(\v2!.quidditch.data.AggregationBucketR\abuckets\x12\x14\n" +
@@ -2470,16 +4092,20 @@ const file_pkg_common_proto_data_proto_rawDesc = "" +
 	"\x05value\x18\x0e \x01(\x03R\x05value\x1a9\n" +
 	"\vValuesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\xad\x02\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\xeb\x02\n" +
 	"\x11AggregationBucket\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1f\n" +
 	"\vnumeric_key\x18\x02 \x01(\x01R\n" +
 	"numericKey\x12\x1b\n" +
 	"\tdoc_count\x18\x03 \x01(\x03R\bdocCount\x12a\n" +
-	"\x10sub_aggregations\x18\x04 \x03(\v26.quidditch.data.AggregationBucket.SubAggregationsEntryR\x0fsubAggregations\x1ae\n" +
+	"\x10sub_aggregations\x18\x04 \x03(\v26.quidditch.data.AggregationBucket.SubAggregationsEntryR\x0fsubAggregations\x12\x17\n" +
+	"\x04from\x18\x05 \x01(\x01H\x00R\x04from\x88\x01\x01\x12\x13\n" +
+	"\x02to\x18\x06 \x01(\x01H\x01R\x02to\x88\x01\x01\x1ae\n" +
 	"\x14SubAggregationsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x127\n" +
-	"\x05value\x18\x02 \x01(\v2!.quidditch.data.AggregationResultR\x05value:\x028\x01\"\x8b\x01\n" +
+	"\x05value\x18\x02 \x01(\v2!.quidditch.data.AggregationResultR\x05value:\x028\x01B\a\n" +
+	"\x05_fromB\x05\n" +
+	"\x03_to\"\x8b\x01\n" +
 	"\fCountRequest\x12\x1d\n" +
 	"\n" +
 	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
@@ -2487,11 +4113,34 @@ const file_pkg_common_proto_data_proto_rawDesc = "" +
 	"\x05query\x18\x03 \x01(\fR\x05query\x12+\n" +
 	"\x11filter_expression\x18\x04 \x01(\fR\x10filterExpression\"%\n" +
 	"\rCountResponse\x12\x14\n" +
-	"\x05count\x18\x01 \x01(\x03R\x05count\"P\n" +
+	"\x05count\x18\x01 \x01(\x03R\x05count\"d\n" +
+	"\x0eSuggestRequest\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
+	"\bshard_id\x18\x02 \x01(\x05R\ashardId\x12\x18\n" +
+	"\asuggest\x18\x03 \x01(\fR\asuggest\"\xc5\x01\n" +
+	"\x0fSuggestResponse\x12R\n" +
+	"\vsuggestions\x18\x01 \x03(\v20.quidditch.data.SuggestResponse.SuggestionsEntryR\vsuggestions\x1a^\n" +
+	"\x10SuggestionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x124\n" +
+	"\x05value\x18\x02 \x01(\v2\x1e.quidditch.data.SuggestEntriesR\x05value:\x028\x01\"H\n" +
+	"\x0eSuggestEntries\x126\n" +
+	"\aentries\x18\x01 \x03(\v2\x1c.quidditch.data.SuggestEntryR\aentries\"\x8b\x01\n" +
+	"\fSuggestEntry\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x05R\x06offset\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x05R\x06length\x127\n" +
+	"\aoptions\x18\x04 \x03(\v2\x1d.quidditch.data.SuggestOptionR\aoptions\"\x95\x01\n" +
+	"\rSuggestOption\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\x12\x12\n" +
+	"\x04freq\x18\x03 \x01(\x03R\x04freq\x12\x15\n" +
+	"\x06doc_id\x18\x04 \x01(\tR\x05docId\x12/\n" +
+	"\x06source\x18\x05 \x01(\v2\x17.google.protobuf.StructR\x06source\"P\n" +
 	"\x14GetShardStatsRequest\x12\x1d\n" +
 	"\n" +
 	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
-	"\bshard_id\x18\x02 \x01(\x05R\ashardId\"\x8e\x03\n" +
+	"\bshard_id\x18\x02 \x01(\x05R\ashardId\"\xb0\x04\n" +
 	"\n" +
 	"ShardStats\x12\x1d\n" +
 	"\n" +
@@ -2508,9 +4157,31 @@ const file_pkg_common_proto_data_proto_rawDesc = "" +
 	"\x1asearch_queries_time_millis\x18\b \x01(\x03R\x17searchQueriesTimeMillis\x12%\n" +
 	"\x0eindexing_total\x18\t \x01(\x03R\rindexingTotal\x120\n" +
 	"\x14indexing_time_millis\x18\n" +
-	" \x01(\x03R\x12indexingTimeMillis\"<\n" +
+	" \x01(\x03R\x12indexingTimeMillis\x12#\n" +
+	"\rrefresh_total\x18\v \x01(\x03R\frefreshTotal\x12.\n" +
+	"\x13refresh_time_millis\x18\f \x01(\x03R\x11refreshTimeMillis\x12\x1f\n" +
+	"\vmerge_total\x18\r \x01(\x03R\n" +
+	"mergeTotal\x12*\n" +
+	"\x11merge_time_millis\x18\x0e \x01(\x03R\x0fmergeTimeMillis\"R\n" +
+	"\x16GetSegmentStatsRequest\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
+	"\bshard_id\x18\x02 \x01(\x05R\ashardId\"\x7f\n" +
+	"\fSegmentStats\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x19\n" +
+	"\bnum_docs\x18\x02 \x01(\x03R\anumDocs\x12!\n" +
+	"\fdeleted_docs\x18\x03 \x01(\x03R\vdeletedDocs\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x04 \x01(\x03R\tsizeBytes\"\xac\x01\n" +
+	"\x17GetSegmentStatsResponse\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
+	"\bshard_id\x18\x02 \x01(\x05R\ashardId\x12\x1d\n" +
+	"\n" +
+	"is_primary\x18\x03 \x01(\bR\tisPrimary\x128\n" +
+	"\bsegments\x18\x04 \x03(\v2\x1c.quidditch.data.SegmentStatsR\bsegments\"<\n" +
 	"\x13GetNodeStatsRequest\x12%\n" +
-	"\x0einclude_shards\x18\x01 \x01(\bR\rincludeShards\"\xfb\x02\n" +
+	"\x0einclude_shards\x18\x01 \x01(\bR\rincludeShards\"\xa5\x03\n" +
 	"\rDataNodeStats\x12\x17\n" +
 	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12!\n" +
 	"\ftotal_shards\x18\x02 \x01(\x05R\vtotalShards\x12\x1d\n" +
@@ -2521,21 +4192,32 @@ const file_pkg_common_proto_data_proto_rawDesc = "" +
 	"\x14memory_usage_percent\x18\x06 \x01(\x01R\x12memoryUsagePercent\x12,\n" +
 	"\x12disk_usage_percent\x18\a \x01(\x01R\x10diskUsagePercent\x12%\n" +
 	"\x0euptime_seconds\x18\b \x01(\x03R\ruptimeSeconds\x122\n" +
-	"\x06shards\x18\t \x03(\v2\x1a.quidditch.data.ShardStatsR\x06shards2\xdc\b\n" +
+	"\x06shards\x18\t \x03(\v2\x1a.quidditch.data.ShardStatsR\x06shards\x12(\n" +
+	"\x10disk_total_bytes\x18\n" +
+	" \x01(\x03R\x0ediskTotalBytes2\xb4\x0e\n" +
 	"\vDataService\x12V\n" +
 	"\vCreateShard\x12\".quidditch.data.CreateShardRequest\x1a#.quidditch.data.CreateShardResponse\x12V\n" +
 	"\vDeleteShard\x12\".quidditch.data.DeleteShardRequest\x1a#.quidditch.data.DeleteShardResponse\x12N\n" +
-	"\fGetShardInfo\x12#.quidditch.data.GetShardInfoRequest\x1a\x19.quidditch.data.ShardInfo\x12Y\n" +
+	"\fGetShardInfo\x12#.quidditch.data.GetShardInfoRequest\x1a\x19.quidditch.data.ShardInfo\x12n\n" +
+	"\x13UpdateShardSettings\x12*.quidditch.data.UpdateShardSettingsRequest\x1a+.quidditch.data.UpdateShardSettingsResponse\x12Y\n" +
 	"\fRefreshShard\x12#.quidditch.data.RefreshShardRequest\x1a$.quidditch.data.RefreshShardResponse\x12S\n" +
 	"\n" +
-	"FlushShard\x12!.quidditch.data.FlushShardRequest\x1a\".quidditch.data.FlushShardResponse\x12\\\n" +
+	"FlushShard\x12!.quidditch.data.FlushShardRequest\x1a\".quidditch.data.FlushShardResponse\x12S\n" +
+	"\n" +
+	"ForceMerge\x12!.quidditch.data.ForceMergeRequest\x1a\".quidditch.data.ForceMergeResponse\x12\\\n" +
+	"\rSnapshotShard\x12$.quidditch.data.SnapshotShardRequest\x1a%.quidditch.data.SnapshotShardResponse\x12Y\n" +
+	"\fRestoreShard\x12#.quidditch.data.RestoreShardRequest\x1a$.quidditch.data.RestoreShardResponse\x12P\n" +
+	"\tScanShard\x12 .quidditch.data.ScanShardRequest\x1a!.quidditch.data.ScanShardResponse\x12\\\n" +
 	"\rIndexDocument\x12$.quidditch.data.IndexDocumentRequest\x1a%.quidditch.data.IndexDocumentResponse\x12V\n" +
-	"\vGetDocument\x12\".quidditch.data.GetDocumentRequest\x1a#.quidditch.data.GetDocumentResponse\x12_\n" +
+	"\vGetDocument\x12\".quidditch.data.GetDocumentRequest\x1a#.quidditch.data.GetDocumentResponse\x12V\n" +
+	"\vTermVectors\x12\".quidditch.data.TermVectorsRequest\x1a#.quidditch.data.TermVectorsResponse\x12_\n" +
 	"\x0eDeleteDocument\x12%.quidditch.data.DeleteDocumentRequest\x1a&.quidditch.data.DeleteDocumentResponse\x12P\n" +
 	"\tBulkIndex\x12 .quidditch.data.BulkIndexRequest\x1a!.quidditch.data.BulkIndexResponse\x12G\n" +
 	"\x06Search\x12\x1d.quidditch.data.SearchRequest\x1a\x1e.quidditch.data.SearchResponse\x12D\n" +
-	"\x05Count\x12\x1c.quidditch.data.CountRequest\x1a\x1d.quidditch.data.CountResponse\x12Q\n" +
-	"\rGetShardStats\x12$.quidditch.data.GetShardStatsRequest\x1a\x1a.quidditch.data.ShardStats\x12R\n" +
+	"\x05Count\x12\x1c.quidditch.data.CountRequest\x1a\x1d.quidditch.data.CountResponse\x12J\n" +
+	"\aSuggest\x12\x1e.quidditch.data.SuggestRequest\x1a\x1f.quidditch.data.SuggestResponse\x12Q\n" +
+	"\rGetShardStats\x12$.quidditch.data.GetShardStatsRequest\x1a\x1a.quidditch.data.ShardStats\x12b\n" +
+	"\x0fGetSegmentStats\x12&.quidditch.data.GetSegmentStatsRequest\x1a'.quidditch.data.GetSegmentStatsResponse\x12R\n" +
 	"\fGetNodeStats\x12#.quidditch.data.GetNodeStatsRequest\x1a\x1d.quidditch.data.DataNodeStatsB1Z/github.com/quidditch/quidditch/pkg/common/protob\x06proto3"
 
 var (
@@ -2551,103 +4233,162 @@ func file_pkg_common_proto_data_proto_rawDescGZIP() []byte {
 }
 
 var file_pkg_common_proto_data_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_pkg_common_proto_data_proto_msgTypes = make([]protoimpl.MessageInfo, 38)
+var file_pkg_common_proto_data_proto_msgTypes = make([]protoimpl.MessageInfo, 66)
 var file_pkg_common_proto_data_proto_goTypes = []any{
-	(ShardInfo_ShardState)(0),      // 0: quidditch.data.ShardInfo.ShardState
-	(*CreateShardRequest)(nil),     // 1: quidditch.data.CreateShardRequest
-	(*CreateShardResponse)(nil),    // 2: quidditch.data.CreateShardResponse
-	(*DeleteShardRequest)(nil),     // 3: quidditch.data.DeleteShardRequest
-	(*DeleteShardResponse)(nil),    // 4: quidditch.data.DeleteShardResponse
-	(*GetShardInfoRequest)(nil),    // 5: quidditch.data.GetShardInfoRequest
-	(*ShardInfo)(nil),              // 6: quidditch.data.ShardInfo
-	(*RefreshShardRequest)(nil),    // 7: quidditch.data.RefreshShardRequest
-	(*RefreshShardResponse)(nil),   // 8: quidditch.data.RefreshShardResponse
-	(*FlushShardRequest)(nil),      // 9: quidditch.data.FlushShardRequest
-	(*FlushShardResponse)(nil),     // 10: quidditch.data.FlushShardResponse
-	(*IndexDocumentRequest)(nil),   // 11: quidditch.data.IndexDocumentRequest
-	(*IndexDocumentResponse)(nil),  // 12: quidditch.data.IndexDocumentResponse
-	(*GetDocumentRequest)(nil),     // 13: quidditch.data.GetDocumentRequest
-	(*GetDocumentResponse)(nil),    // 14: quidditch.data.GetDocumentResponse
-	(*DeleteDocumentRequest)(nil),  // 15: quidditch.data.DeleteDocumentRequest
-	(*DeleteDocumentResponse)(nil), // 16: quidditch.data.DeleteDocumentResponse
-	(*BulkIndexRequest)(nil),       // 17: quidditch.data.BulkIndexRequest
-	(*BulkIndexItem)(nil),          // 18: quidditch.data.BulkIndexItem
-	(*BulkIndexResponse)(nil),      // 19: quidditch.data.BulkIndexResponse
-	(*BulkIndexItemResponse)(nil),  // 20: quidditch.data.BulkIndexItemResponse
-	(*SearchRequest)(nil),          // 21: quidditch.data.SearchRequest
-	(*SearchResponse)(nil),         // 22: quidditch.data.SearchResponse
-	(*ShardSearchStats)(nil),       // 23: quidditch.data.ShardSearchStats
-	(*SearchHits)(nil),             // 24: quidditch.data.SearchHits
-	(*TotalHits)(nil),              // 25: quidditch.data.TotalHits
-	(*SearchHit)(nil),              // 26: quidditch.data.SearchHit
-	(*AggregationResult)(nil),      // 27: quidditch.data.AggregationResult
-	(*AggregationBucket)(nil),      // 28: quidditch.data.AggregationBucket
-	(*CountRequest)(nil),           // 29: quidditch.data.CountRequest
-	(*CountResponse)(nil),          // 30: quidditch.data.CountResponse
-	(*GetShardStatsRequest)(nil),   // 31: quidditch.data.GetShardStatsRequest
-	(*ShardStats)(nil),             // 32: quidditch.data.ShardStats
-	(*GetNodeStatsRequest)(nil),    // 33: quidditch.data.GetNodeStatsRequest
-	(*DataNodeStats)(nil),          // 34: quidditch.data.DataNodeStats
-	nil,                            // 35: quidditch.data.CreateShardRequest.SettingsEntry
-	nil,                            // 36: quidditch.data.SearchResponse.AggregationsEntry
-	nil,                            // 37: quidditch.data.AggregationResult.ValuesEntry
-	nil,                            // 38: quidditch.data.AggregationBucket.SubAggregationsEntry
-	(*timestamppb.Timestamp)(nil),  // 39: google.protobuf.Timestamp
-	(*structpb.Struct)(nil),        // 40: google.protobuf.Struct
+	(ShardInfo_ShardState)(0),           // 0: quidditch.data.ShardInfo.ShardState
+	(*CreateShardRequest)(nil),          // 1: quidditch.data.CreateShardRequest
+	(*CreateShardResponse)(nil),         // 2: quidditch.data.CreateShardResponse
+	(*DeleteShardRequest)(nil),          // 3: quidditch.data.DeleteShardRequest
+	(*DeleteShardResponse)(nil),         // 4: quidditch.data.DeleteShardResponse
+	(*UpdateShardSettingsRequest)(nil),  // 5: quidditch.data.UpdateShardSettingsRequest
+	(*UpdateShardSettingsResponse)(nil), // 6: quidditch.data.UpdateShardSettingsResponse
+	(*GetShardInfoRequest)(nil),         // 7: quidditch.data.GetShardInfoRequest
+	(*ShardInfo)(nil),                   // 8: quidditch.data.ShardInfo
+	(*RefreshShardRequest)(nil),         // 9: quidditch.data.RefreshShardRequest
+	(*RefreshShardResponse)(nil),        // 10: quidditch.data.RefreshShardResponse
+	(*FlushShardRequest)(nil),           // 11: quidditch.data.FlushShardRequest
+	(*FlushShardResponse)(nil),          // 12: quidditch.data.FlushShardResponse
+	(*ForceMergeRequest)(nil),           // 13: quidditch.data.ForceMergeRequest
+	(*ForceMergeResponse)(nil),          // 14: quidditch.data.ForceMergeResponse
+	(*SnapshotFile)(nil),                // 15: quidditch.data.SnapshotFile
+	(*SnapshotShardRequest)(nil),        // 16: quidditch.data.SnapshotShardRequest
+	(*SnapshotShardResponse)(nil),       // 17: quidditch.data.SnapshotShardResponse
+	(*RestoreShardRequest)(nil),         // 18: quidditch.data.RestoreShardRequest
+	(*RestoreShardResponse)(nil),        // 19: quidditch.data.RestoreShardResponse
+	(*ScanShardRequest)(nil),            // 20: quidditch.data.ScanShardRequest
+	(*ScanShardResponse)(nil),           // 21: quidditch.data.ScanShardResponse
+	(*IndexDocumentRequest)(nil),        // 22: quidditch.data.IndexDocumentRequest
+	(*IndexDocumentResponse)(nil),       // 23: quidditch.data.IndexDocumentResponse
+	(*GetDocumentRequest)(nil),          // 24: quidditch.data.GetDocumentRequest
+	(*GetDocumentResponse)(nil),         // 25: quidditch.data.GetDocumentResponse
+	(*TermVectorsRequest)(nil),          // 26: quidditch.data.TermVectorsRequest
+	(*TermVectorsResponse)(nil),         // 27: quidditch.data.TermVectorsResponse
+	(*FieldTermVector)(nil),             // 28: quidditch.data.FieldTermVector
+	(*TermVectorTerm)(nil),              // 29: quidditch.data.TermVectorTerm
+	(*TermVectorToken)(nil),             // 30: quidditch.data.TermVectorToken
+	(*DeleteDocumentRequest)(nil),       // 31: quidditch.data.DeleteDocumentRequest
+	(*DeleteDocumentResponse)(nil),      // 32: quidditch.data.DeleteDocumentResponse
+	(*BulkIndexRequest)(nil),            // 33: quidditch.data.BulkIndexRequest
+	(*BulkIndexItem)(nil),               // 34: quidditch.data.BulkIndexItem
+	(*BulkIndexResponse)(nil),           // 35: quidditch.data.BulkIndexResponse
+	(*BulkIndexItemResponse)(nil),       // 36: quidditch.data.BulkIndexItemResponse
+	(*SearchRequest)(nil),               // 37: quidditch.data.SearchRequest
+	(*SearchResponse)(nil),              // 38: quidditch.data.SearchResponse
+	(*ShardSearchStats)(nil),            // 39: quidditch.data.ShardSearchStats
+	(*SearchHits)(nil),                  // 40: quidditch.data.SearchHits
+	(*TotalHits)(nil),                   // 41: quidditch.data.TotalHits
+	(*SearchHit)(nil),                   // 42: quidditch.data.SearchHit
+	(*AggregationResult)(nil),           // 43: quidditch.data.AggregationResult
+	(*AggregationBucket)(nil),           // 44: quidditch.data.AggregationBucket
+	(*CountRequest)(nil),                // 45: quidditch.data.CountRequest
+	(*CountResponse)(nil),               // 46: quidditch.data.CountResponse
+	(*SuggestRequest)(nil),              // 47: quidditch.data.SuggestRequest
+	(*SuggestResponse)(nil),             // 48: quidditch.data.SuggestResponse
+	(*SuggestEntries)(nil),              // 49: quidditch.data.SuggestEntries
+	(*SuggestEntry)(nil),                // 50: quidditch.data.SuggestEntry
+	(*SuggestOption)(nil),               // 51: quidditch.data.SuggestOption
+	(*GetShardStatsRequest)(nil),        // 52: quidditch.data.GetShardStatsRequest
+	(*ShardStats)(nil),                  // 53: quidditch.data.ShardStats
+	(*GetSegmentStatsRequest)(nil),      // 54: quidditch.data.GetSegmentStatsRequest
+	(*SegmentStats)(nil),                // 55: quidditch.data.SegmentStats
+	(*GetSegmentStatsResponse)(nil),     // 56: quidditch.data.GetSegmentStatsResponse
+	(*GetNodeStatsRequest)(nil),         // 57: quidditch.data.GetNodeStatsRequest
+	(*DataNodeStats)(nil),               // 58: quidditch.data.DataNodeStats
+	nil,                                 // 59: quidditch.data.CreateShardRequest.SettingsEntry
+	nil,                                 // 60: quidditch.data.UpdateShardSettingsRequest.SettingsEntry
+	nil,                                 // 61: quidditch.data.TermVectorsResponse.TermVectorsEntry
+	nil,                                 // 62: quidditch.data.FieldTermVector.TermsEntry
+	nil,                                 // 63: quidditch.data.SearchResponse.AggregationsEntry
+	nil,                                 // 64: quidditch.data.AggregationResult.ValuesEntry
+	nil,                                 // 65: quidditch.data.AggregationBucket.SubAggregationsEntry
+	nil,                                 // 66: quidditch.data.SuggestResponse.SuggestionsEntry
+	(*timestamppb.Timestamp)(nil),       // 67: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),             // 68: google.protobuf.Struct
 }
 var file_pkg_common_proto_data_proto_depIdxs = []int32{
-	35, // 0: quidditch.data.CreateShardRequest.settings:type_name -> quidditch.data.CreateShardRequest.SettingsEntry
-	0,  // 1: quidditch.data.ShardInfo.state:type_name -> quidditch.data.ShardInfo.ShardState
-	39, // 2: quidditch.data.ShardInfo.created_at:type_name -> google.protobuf.Timestamp
-	39, // 3: quidditch.data.ShardInfo.last_updated:type_name -> google.protobuf.Timestamp
-	40, // 4: quidditch.data.IndexDocumentRequest.document:type_name -> google.protobuf.Struct
-	40, // 5: quidditch.data.GetDocumentResponse.document:type_name -> google.protobuf.Struct
-	18, // 6: quidditch.data.BulkIndexRequest.items:type_name -> quidditch.data.BulkIndexItem
-	40, // 7: quidditch.data.BulkIndexItem.document:type_name -> google.protobuf.Struct
-	20, // 8: quidditch.data.BulkIndexResponse.items:type_name -> quidditch.data.BulkIndexItemResponse
-	23, // 9: quidditch.data.SearchResponse.shards:type_name -> quidditch.data.ShardSearchStats
-	24, // 10: quidditch.data.SearchResponse.hits:type_name -> quidditch.data.SearchHits
-	36, // 11: quidditch.data.SearchResponse.aggregations:type_name -> quidditch.data.SearchResponse.AggregationsEntry
-	25, // 12: quidditch.data.SearchHits.total:type_name -> quidditch.data.TotalHits
-	26, // 13: quidditch.data.SearchHits.hits:type_name -> quidditch.data.SearchHit
-	40, // 14: quidditch.data.SearchHit.source:type_name -> google.protobuf.Struct
-	28, // 15: quidditch.data.AggregationResult.buckets:type_name -> quidditch.data.AggregationBucket
-	37, // 16: quidditch.data.AggregationResult.values:type_name -> quidditch.data.AggregationResult.ValuesEntry
-	38, // 17: quidditch.data.AggregationBucket.sub_aggregations:type_name -> quidditch.data.AggregationBucket.SubAggregationsEntry
-	32, // 18: quidditch.data.DataNodeStats.shards:type_name -> quidditch.data.ShardStats
-	27, // 19: quidditch.data.SearchResponse.AggregationsEntry.value:type_name -> quidditch.data.AggregationResult
-	27, // 20: quidditch.data.AggregationBucket.SubAggregationsEntry.value:type_name -> quidditch.data.AggregationResult
-	1,  // 21: quidditch.data.DataService.CreateShard:input_type -> quidditch.data.CreateShardRequest
-	3,  // 22: quidditch.data.DataService.DeleteShard:input_type -> quidditch.data.DeleteShardRequest
-	5,  // 23: quidditch.data.DataService.GetShardInfo:input_type -> quidditch.data.GetShardInfoRequest
-	7,  // 24: quidditch.data.DataService.RefreshShard:input_type -> quidditch.data.RefreshShardRequest
-	9,  // 25: quidditch.data.DataService.FlushShard:input_type -> quidditch.data.FlushShardRequest
-	11, // 26: quidditch.data.DataService.IndexDocument:input_type -> quidditch.data.IndexDocumentRequest
-	13, // 27: quidditch.data.DataService.GetDocument:input_type -> quidditch.data.GetDocumentRequest
-	15, // 28: quidditch.data.DataService.DeleteDocument:input_type -> quidditch.data.DeleteDocumentRequest
-	17, // 29: quidditch.data.DataService.BulkIndex:input_type -> quidditch.data.BulkIndexRequest
-	21, // 30: quidditch.data.DataService.Search:input_type -> quidditch.data.SearchRequest
-	29, // 31: quidditch.data.DataService.Count:input_type -> quidditch.data.CountRequest
-	31, // 32: quidditch.data.DataService.GetShardStats:input_type -> quidditch.data.GetShardStatsRequest
-	33, // 33: quidditch.data.DataService.GetNodeStats:input_type -> quidditch.data.GetNodeStatsRequest
-	2,  // 34: quidditch.data.DataService.CreateShard:output_type -> quidditch.data.CreateShardResponse
-	4,  // 35: quidditch.data.DataService.DeleteShard:output_type -> quidditch.data.DeleteShardResponse
-	6,  // 36: quidditch.data.DataService.GetShardInfo:output_type -> quidditch.data.ShardInfo
-	8,  // 37: quidditch.data.DataService.RefreshShard:output_type -> quidditch.data.RefreshShardResponse
-	10, // 38: quidditch.data.DataService.FlushShard:output_type -> quidditch.data.FlushShardResponse
-	12, // 39: quidditch.data.DataService.IndexDocument:output_type -> quidditch.data.IndexDocumentResponse
-	14, // 40: quidditch.data.DataService.GetDocument:output_type -> quidditch.data.GetDocumentResponse
-	16, // 41: quidditch.data.DataService.DeleteDocument:output_type -> quidditch.data.DeleteDocumentResponse
-	19, // 42: quidditch.data.DataService.BulkIndex:output_type -> quidditch.data.BulkIndexResponse
-	22, // 43: quidditch.data.DataService.Search:output_type -> quidditch.data.SearchResponse
-	30, // 44: quidditch.data.DataService.Count:output_type -> quidditch.data.CountResponse
-	32, // 45: quidditch.data.DataService.GetShardStats:output_type -> quidditch.data.ShardStats
-	34, // 46: quidditch.data.DataService.GetNodeStats:output_type -> quidditch.data.DataNodeStats
-	34, // [34:47] is the sub-list for method output_type
-	21, // [21:34] is the sub-list for method input_type
-	21, // [21:21] is the sub-list for extension type_name
-	21, // [21:21] is the sub-list for extension extendee
-	0,  // [0:21] is the sub-list for field type_name
+	59, // 0: quidditch.data.CreateShardRequest.settings:type_name -> quidditch.data.CreateShardRequest.SettingsEntry
+	60, // 1: quidditch.data.UpdateShardSettingsRequest.settings:type_name -> quidditch.data.UpdateShardSettingsRequest.SettingsEntry
+	0,  // 2: quidditch.data.ShardInfo.state:type_name -> quidditch.data.ShardInfo.ShardState
+	67, // 3: quidditch.data.ShardInfo.created_at:type_name -> google.protobuf.Timestamp
+	67, // 4: quidditch.data.ShardInfo.last_updated:type_name -> google.protobuf.Timestamp
+	15, // 5: quidditch.data.SnapshotShardResponse.files:type_name -> quidditch.data.SnapshotFile
+	15, // 6: quidditch.data.RestoreShardRequest.files:type_name -> quidditch.data.SnapshotFile
+	34, // 7: quidditch.data.ScanShardResponse.documents:type_name -> quidditch.data.BulkIndexItem
+	68, // 8: quidditch.data.IndexDocumentRequest.document:type_name -> google.protobuf.Struct
+	68, // 9: quidditch.data.GetDocumentResponse.document:type_name -> google.protobuf.Struct
+	61, // 10: quidditch.data.TermVectorsResponse.term_vectors:type_name -> quidditch.data.TermVectorsResponse.TermVectorsEntry
+	62, // 11: quidditch.data.FieldTermVector.terms:type_name -> quidditch.data.FieldTermVector.TermsEntry
+	30, // 12: quidditch.data.TermVectorTerm.tokens:type_name -> quidditch.data.TermVectorToken
+	34, // 13: quidditch.data.BulkIndexRequest.items:type_name -> quidditch.data.BulkIndexItem
+	68, // 14: quidditch.data.BulkIndexItem.document:type_name -> google.protobuf.Struct
+	36, // 15: quidditch.data.BulkIndexResponse.items:type_name -> quidditch.data.BulkIndexItemResponse
+	39, // 16: quidditch.data.SearchResponse.shards:type_name -> quidditch.data.ShardSearchStats
+	40, // 17: quidditch.data.SearchResponse.hits:type_name -> quidditch.data.SearchHits
+	63, // 18: quidditch.data.SearchResponse.aggregations:type_name -> quidditch.data.SearchResponse.AggregationsEntry
+	41, // 19: quidditch.data.SearchHits.total:type_name -> quidditch.data.TotalHits
+	42, // 20: quidditch.data.SearchHits.hits:type_name -> quidditch.data.SearchHit
+	68, // 21: quidditch.data.SearchHit.source:type_name -> google.protobuf.Struct
+	44, // 22: quidditch.data.AggregationResult.buckets:type_name -> quidditch.data.AggregationBucket
+	64, // 23: quidditch.data.AggregationResult.values:type_name -> quidditch.data.AggregationResult.ValuesEntry
+	65, // 24: quidditch.data.AggregationBucket.sub_aggregations:type_name -> quidditch.data.AggregationBucket.SubAggregationsEntry
+	66, // 25: quidditch.data.SuggestResponse.suggestions:type_name -> quidditch.data.SuggestResponse.SuggestionsEntry
+	50, // 26: quidditch.data.SuggestEntries.entries:type_name -> quidditch.data.SuggestEntry
+	51, // 27: quidditch.data.SuggestEntry.options:type_name -> quidditch.data.SuggestOption
+	68, // 28: quidditch.data.SuggestOption.source:type_name -> google.protobuf.Struct
+	55, // 29: quidditch.data.GetSegmentStatsResponse.segments:type_name -> quidditch.data.SegmentStats
+	53, // 30: quidditch.data.DataNodeStats.shards:type_name -> quidditch.data.ShardStats
+	28, // 31: quidditch.data.TermVectorsResponse.TermVectorsEntry.value:type_name -> quidditch.data.FieldTermVector
+	29, // 32: quidditch.data.FieldTermVector.TermsEntry.value:type_name -> quidditch.data.TermVectorTerm
+	43, // 33: quidditch.data.SearchResponse.AggregationsEntry.value:type_name -> quidditch.data.AggregationResult
+	43, // 34: quidditch.data.AggregationBucket.SubAggregationsEntry.value:type_name -> quidditch.data.AggregationResult
+	49, // 35: quidditch.data.SuggestResponse.SuggestionsEntry.value:type_name -> quidditch.data.SuggestEntries
+	1,  // 36: quidditch.data.DataService.CreateShard:input_type -> quidditch.data.CreateShardRequest
+	3,  // 37: quidditch.data.DataService.DeleteShard:input_type -> quidditch.data.DeleteShardRequest
+	7,  // 38: quidditch.data.DataService.GetShardInfo:input_type -> quidditch.data.GetShardInfoRequest
+	5,  // 39: quidditch.data.DataService.UpdateShardSettings:input_type -> quidditch.data.UpdateShardSettingsRequest
+	9,  // 40: quidditch.data.DataService.RefreshShard:input_type -> quidditch.data.RefreshShardRequest
+	11, // 41: quidditch.data.DataService.FlushShard:input_type -> quidditch.data.FlushShardRequest
+	13, // 42: quidditch.data.DataService.ForceMerge:input_type -> quidditch.data.ForceMergeRequest
+	16, // 43: quidditch.data.DataService.SnapshotShard:input_type -> quidditch.data.SnapshotShardRequest
+	18, // 44: quidditch.data.DataService.RestoreShard:input_type -> quidditch.data.RestoreShardRequest
+	20, // 45: quidditch.data.DataService.ScanShard:input_type -> quidditch.data.ScanShardRequest
+	22, // 46: quidditch.data.DataService.IndexDocument:input_type -> quidditch.data.IndexDocumentRequest
+	24, // 47: quidditch.data.DataService.GetDocument:input_type -> quidditch.data.GetDocumentRequest
+	26, // 48: quidditch.data.DataService.TermVectors:input_type -> quidditch.data.TermVectorsRequest
+	31, // 49: quidditch.data.DataService.DeleteDocument:input_type -> quidditch.data.DeleteDocumentRequest
+	33, // 50: quidditch.data.DataService.BulkIndex:input_type -> quidditch.data.BulkIndexRequest
+	37, // 51: quidditch.data.DataService.Search:input_type -> quidditch.data.SearchRequest
+	45, // 52: quidditch.data.DataService.Count:input_type -> quidditch.data.CountRequest
+	47, // 53: quidditch.data.DataService.Suggest:input_type -> quidditch.data.SuggestRequest
+	52, // 54: quidditch.data.DataService.GetShardStats:input_type -> quidditch.data.GetShardStatsRequest
+	54, // 55: quidditch.data.DataService.GetSegmentStats:input_type -> quidditch.data.GetSegmentStatsRequest
+	57, // 56: quidditch.data.DataService.GetNodeStats:input_type -> quidditch.data.GetNodeStatsRequest
+	2,  // 57: quidditch.data.DataService.CreateShard:output_type -> quidditch.data.CreateShardResponse
+	4,  // 58: quidditch.data.DataService.DeleteShard:output_type -> quidditch.data.DeleteShardResponse
+	8,  // 59: quidditch.data.DataService.GetShardInfo:output_type -> quidditch.data.ShardInfo
+	6,  // 60: quidditch.data.DataService.UpdateShardSettings:output_type -> quidditch.data.UpdateShardSettingsResponse
+	10, // 61: quidditch.data.DataService.RefreshShard:output_type -> quidditch.data.RefreshShardResponse
+	12, // 62: quidditch.data.DataService.FlushShard:output_type -> quidditch.data.FlushShardResponse
+	14, // 63: quidditch.data.DataService.ForceMerge:output_type -> quidditch.data.ForceMergeResponse
+	17, // 64: quidditch.data.DataService.SnapshotShard:output_type -> quidditch.data.SnapshotShardResponse
+	19, // 65: quidditch.data.DataService.RestoreShard:output_type -> quidditch.data.RestoreShardResponse
+	21, // 66: quidditch.data.DataService.ScanShard:output_type -> quidditch.data.ScanShardResponse
+	23, // 67: quidditch.data.DataService.IndexDocument:output_type -> quidditch.data.IndexDocumentResponse
+	25, // 68: quidditch.data.DataService.GetDocument:output_type -> quidditch.data.GetDocumentResponse
+	27, // 69: quidditch.data.DataService.TermVectors:output_type -> quidditch.data.TermVectorsResponse
+	32, // 70: quidditch.data.DataService.DeleteDocument:output_type -> quidditch.data.DeleteDocumentResponse
+	35, // 71: quidditch.data.DataService.BulkIndex:output_type -> quidditch.data.BulkIndexResponse
+	38, // 72: quidditch.data.DataService.Search:output_type -> quidditch.data.SearchResponse
+	46, // 73: quidditch.data.DataService.Count:output_type -> quidditch.data.CountResponse
+	48, // 74: quidditch.data.DataService.Suggest:output_type -> quidditch.data.SuggestResponse
+	53, // 75: quidditch.data.DataService.GetShardStats:output_type -> quidditch.data.ShardStats
+	56, // 76: quidditch.data.DataService.GetSegmentStats:output_type -> quidditch.data.GetSegmentStatsResponse
+	58, // 77: quidditch.data.DataService.GetNodeStats:output_type -> quidditch.data.DataNodeStats
+	57, // [57:78] is the sub-list for method output_type
+	36, // [36:57] is the sub-list for method input_type
+	36, // [36:36] is the sub-list for extension type_name
+	36, // [36:36] is the sub-list for extension extendee
+	0,  // [0:36] is the sub-list for field type_name
 }
 
 func init() { file_pkg_common_proto_data_proto_init() }
@@ -2655,13 +4396,14 @@ func file_pkg_common_proto_data_proto_init() {
 	if File_pkg_common_proto_data_proto != nil {
 		return
 	}
+	file_pkg_common_proto_data_proto_msgTypes[43].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_common_proto_data_proto_rawDesc), len(file_pkg_common_proto_data_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   38,
+			NumMessages:   66,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
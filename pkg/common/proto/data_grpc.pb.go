@@ -19,19 +19,27 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	DataService_CreateShard_FullMethodName    = "/quidditch.data.DataService/CreateShard"
-	DataService_DeleteShard_FullMethodName    = "/quidditch.data.DataService/DeleteShard"
-	DataService_GetShardInfo_FullMethodName   = "/quidditch.data.DataService/GetShardInfo"
-	DataService_RefreshShard_FullMethodName   = "/quidditch.data.DataService/RefreshShard"
-	DataService_FlushShard_FullMethodName     = "/quidditch.data.DataService/FlushShard"
-	DataService_IndexDocument_FullMethodName  = "/quidditch.data.DataService/IndexDocument"
-	DataService_GetDocument_FullMethodName    = "/quidditch.data.DataService/GetDocument"
-	DataService_DeleteDocument_FullMethodName = "/quidditch.data.DataService/DeleteDocument"
-	DataService_BulkIndex_FullMethodName      = "/quidditch.data.DataService/BulkIndex"
-	DataService_Search_FullMethodName         = "/quidditch.data.DataService/Search"
-	DataService_Count_FullMethodName          = "/quidditch.data.DataService/Count"
-	DataService_GetShardStats_FullMethodName  = "/quidditch.data.DataService/GetShardStats"
-	DataService_GetNodeStats_FullMethodName   = "/quidditch.data.DataService/GetNodeStats"
+	DataService_CreateShard_FullMethodName         = "/quidditch.data.DataService/CreateShard"
+	DataService_DeleteShard_FullMethodName         = "/quidditch.data.DataService/DeleteShard"
+	DataService_GetShardInfo_FullMethodName        = "/quidditch.data.DataService/GetShardInfo"
+	DataService_UpdateShardSettings_FullMethodName = "/quidditch.data.DataService/UpdateShardSettings"
+	DataService_RefreshShard_FullMethodName        = "/quidditch.data.DataService/RefreshShard"
+	DataService_FlushShard_FullMethodName          = "/quidditch.data.DataService/FlushShard"
+	DataService_ForceMerge_FullMethodName          = "/quidditch.data.DataService/ForceMerge"
+	DataService_SnapshotShard_FullMethodName       = "/quidditch.data.DataService/SnapshotShard"
+	DataService_RestoreShard_FullMethodName        = "/quidditch.data.DataService/RestoreShard"
+	DataService_ScanShard_FullMethodName           = "/quidditch.data.DataService/ScanShard"
+	DataService_IndexDocument_FullMethodName       = "/quidditch.data.DataService/IndexDocument"
+	DataService_GetDocument_FullMethodName         = "/quidditch.data.DataService/GetDocument"
+	DataService_TermVectors_FullMethodName         = "/quidditch.data.DataService/TermVectors"
+	DataService_DeleteDocument_FullMethodName      = "/quidditch.data.DataService/DeleteDocument"
+	DataService_BulkIndex_FullMethodName           = "/quidditch.data.DataService/BulkIndex"
+	DataService_Search_FullMethodName              = "/quidditch.data.DataService/Search"
+	DataService_Count_FullMethodName               = "/quidditch.data.DataService/Count"
+	DataService_Suggest_FullMethodName             = "/quidditch.data.DataService/Suggest"
+	DataService_GetShardStats_FullMethodName       = "/quidditch.data.DataService/GetShardStats"
+	DataService_GetSegmentStats_FullMethodName     = "/quidditch.data.DataService/GetSegmentStats"
+	DataService_GetNodeStats_FullMethodName        = "/quidditch.data.DataService/GetNodeStats"
 )
 
 // DataServiceClient is the client API for DataService service.
@@ -44,18 +52,26 @@ type DataServiceClient interface {
 	CreateShard(ctx context.Context, in *CreateShardRequest, opts ...grpc.CallOption) (*CreateShardResponse, error)
 	DeleteShard(ctx context.Context, in *DeleteShardRequest, opts ...grpc.CallOption) (*DeleteShardResponse, error)
 	GetShardInfo(ctx context.Context, in *GetShardInfoRequest, opts ...grpc.CallOption) (*ShardInfo, error)
+	UpdateShardSettings(ctx context.Context, in *UpdateShardSettingsRequest, opts ...grpc.CallOption) (*UpdateShardSettingsResponse, error)
 	RefreshShard(ctx context.Context, in *RefreshShardRequest, opts ...grpc.CallOption) (*RefreshShardResponse, error)
 	FlushShard(ctx context.Context, in *FlushShardRequest, opts ...grpc.CallOption) (*FlushShardResponse, error)
+	ForceMerge(ctx context.Context, in *ForceMergeRequest, opts ...grpc.CallOption) (*ForceMergeResponse, error)
+	SnapshotShard(ctx context.Context, in *SnapshotShardRequest, opts ...grpc.CallOption) (*SnapshotShardResponse, error)
+	RestoreShard(ctx context.Context, in *RestoreShardRequest, opts ...grpc.CallOption) (*RestoreShardResponse, error)
+	ScanShard(ctx context.Context, in *ScanShardRequest, opts ...grpc.CallOption) (*ScanShardResponse, error)
 	// Document operations
 	IndexDocument(ctx context.Context, in *IndexDocumentRequest, opts ...grpc.CallOption) (*IndexDocumentResponse, error)
 	GetDocument(ctx context.Context, in *GetDocumentRequest, opts ...grpc.CallOption) (*GetDocumentResponse, error)
+	TermVectors(ctx context.Context, in *TermVectorsRequest, opts ...grpc.CallOption) (*TermVectorsResponse, error)
 	DeleteDocument(ctx context.Context, in *DeleteDocumentRequest, opts ...grpc.CallOption) (*DeleteDocumentResponse, error)
 	BulkIndex(ctx context.Context, in *BulkIndexRequest, opts ...grpc.CallOption) (*BulkIndexResponse, error)
 	// Search operations
 	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
 	Count(ctx context.Context, in *CountRequest, opts ...grpc.CallOption) (*CountResponse, error)
+	Suggest(ctx context.Context, in *SuggestRequest, opts ...grpc.CallOption) (*SuggestResponse, error)
 	// Statistics and health
 	GetShardStats(ctx context.Context, in *GetShardStatsRequest, opts ...grpc.CallOption) (*ShardStats, error)
+	GetSegmentStats(ctx context.Context, in *GetSegmentStatsRequest, opts ...grpc.CallOption) (*GetSegmentStatsResponse, error)
 	GetNodeStats(ctx context.Context, in *GetNodeStatsRequest, opts ...grpc.CallOption) (*DataNodeStats, error)
 }
 
@@ -97,6 +113,16 @@ func (c *dataServiceClient) GetShardInfo(ctx context.Context, in *GetShardInfoRe
 	return out, nil
 }
 
+func (c *dataServiceClient) UpdateShardSettings(ctx context.Context, in *UpdateShardSettingsRequest, opts ...grpc.CallOption) (*UpdateShardSettingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateShardSettingsResponse)
+	err := c.cc.Invoke(ctx, DataService_UpdateShardSettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *dataServiceClient) RefreshShard(ctx context.Context, in *RefreshShardRequest, opts ...grpc.CallOption) (*RefreshShardResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(RefreshShardResponse)
@@ -117,6 +143,46 @@ func (c *dataServiceClient) FlushShard(ctx context.Context, in *FlushShardReques
 	return out, nil
 }
 
+func (c *dataServiceClient) ForceMerge(ctx context.Context, in *ForceMergeRequest, opts ...grpc.CallOption) (*ForceMergeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ForceMergeResponse)
+	err := c.cc.Invoke(ctx, DataService_ForceMerge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataServiceClient) SnapshotShard(ctx context.Context, in *SnapshotShardRequest, opts ...grpc.CallOption) (*SnapshotShardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SnapshotShardResponse)
+	err := c.cc.Invoke(ctx, DataService_SnapshotShard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataServiceClient) RestoreShard(ctx context.Context, in *RestoreShardRequest, opts ...grpc.CallOption) (*RestoreShardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreShardResponse)
+	err := c.cc.Invoke(ctx, DataService_RestoreShard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataServiceClient) ScanShard(ctx context.Context, in *ScanShardRequest, opts ...grpc.CallOption) (*ScanShardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScanShardResponse)
+	err := c.cc.Invoke(ctx, DataService_ScanShard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *dataServiceClient) IndexDocument(ctx context.Context, in *IndexDocumentRequest, opts ...grpc.CallOption) (*IndexDocumentResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(IndexDocumentResponse)
@@ -137,6 +203,16 @@ func (c *dataServiceClient) GetDocument(ctx context.Context, in *GetDocumentRequ
 	return out, nil
 }
 
+func (c *dataServiceClient) TermVectors(ctx context.Context, in *TermVectorsRequest, opts ...grpc.CallOption) (*TermVectorsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TermVectorsResponse)
+	err := c.cc.Invoke(ctx, DataService_TermVectors_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *dataServiceClient) DeleteDocument(ctx context.Context, in *DeleteDocumentRequest, opts ...grpc.CallOption) (*DeleteDocumentResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DeleteDocumentResponse)
@@ -177,6 +253,16 @@ func (c *dataServiceClient) Count(ctx context.Context, in *CountRequest, opts ..
 	return out, nil
 }
 
+func (c *dataServiceClient) Suggest(ctx context.Context, in *SuggestRequest, opts ...grpc.CallOption) (*SuggestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SuggestResponse)
+	err := c.cc.Invoke(ctx, DataService_Suggest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *dataServiceClient) GetShardStats(ctx context.Context, in *GetShardStatsRequest, opts ...grpc.CallOption) (*ShardStats, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ShardStats)
@@ -187,6 +273,16 @@ func (c *dataServiceClient) GetShardStats(ctx context.Context, in *GetShardStats
 	return out, nil
 }
 
+func (c *dataServiceClient) GetSegmentStats(ctx context.Context, in *GetSegmentStatsRequest, opts ...grpc.CallOption) (*GetSegmentStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSegmentStatsResponse)
+	err := c.cc.Invoke(ctx, DataService_GetSegmentStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *dataServiceClient) GetNodeStats(ctx context.Context, in *GetNodeStatsRequest, opts ...grpc.CallOption) (*DataNodeStats, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DataNodeStats)
@@ -207,18 +303,26 @@ type DataServiceServer interface {
 	CreateShard(context.Context, *CreateShardRequest) (*CreateShardResponse, error)
 	DeleteShard(context.Context, *DeleteShardRequest) (*DeleteShardResponse, error)
 	GetShardInfo(context.Context, *GetShardInfoRequest) (*ShardInfo, error)
+	UpdateShardSettings(context.Context, *UpdateShardSettingsRequest) (*UpdateShardSettingsResponse, error)
 	RefreshShard(context.Context, *RefreshShardRequest) (*RefreshShardResponse, error)
 	FlushShard(context.Context, *FlushShardRequest) (*FlushShardResponse, error)
+	ForceMerge(context.Context, *ForceMergeRequest) (*ForceMergeResponse, error)
+	SnapshotShard(context.Context, *SnapshotShardRequest) (*SnapshotShardResponse, error)
+	RestoreShard(context.Context, *RestoreShardRequest) (*RestoreShardResponse, error)
+	ScanShard(context.Context, *ScanShardRequest) (*ScanShardResponse, error)
 	// Document operations
 	IndexDocument(context.Context, *IndexDocumentRequest) (*IndexDocumentResponse, error)
 	GetDocument(context.Context, *GetDocumentRequest) (*GetDocumentResponse, error)
+	TermVectors(context.Context, *TermVectorsRequest) (*TermVectorsResponse, error)
 	DeleteDocument(context.Context, *DeleteDocumentRequest) (*DeleteDocumentResponse, error)
 	BulkIndex(context.Context, *BulkIndexRequest) (*BulkIndexResponse, error)
 	// Search operations
 	Search(context.Context, *SearchRequest) (*SearchResponse, error)
 	Count(context.Context, *CountRequest) (*CountResponse, error)
+	Suggest(context.Context, *SuggestRequest) (*SuggestResponse, error)
 	// Statistics and health
 	GetShardStats(context.Context, *GetShardStatsRequest) (*ShardStats, error)
+	GetSegmentStats(context.Context, *GetSegmentStatsRequest) (*GetSegmentStatsResponse, error)
 	GetNodeStats(context.Context, *GetNodeStatsRequest) (*DataNodeStats, error)
 	mustEmbedUnimplementedDataServiceServer()
 }
@@ -239,18 +343,36 @@ func (UnimplementedDataServiceServer) DeleteShard(context.Context, *DeleteShardR
 func (UnimplementedDataServiceServer) GetShardInfo(context.Context, *GetShardInfoRequest) (*ShardInfo, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetShardInfo not implemented")
 }
+func (UnimplementedDataServiceServer) UpdateShardSettings(context.Context, *UpdateShardSettingsRequest) (*UpdateShardSettingsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateShardSettings not implemented")
+}
 func (UnimplementedDataServiceServer) RefreshShard(context.Context, *RefreshShardRequest) (*RefreshShardResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method RefreshShard not implemented")
 }
 func (UnimplementedDataServiceServer) FlushShard(context.Context, *FlushShardRequest) (*FlushShardResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method FlushShard not implemented")
 }
+func (UnimplementedDataServiceServer) ForceMerge(context.Context, *ForceMergeRequest) (*ForceMergeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ForceMerge not implemented")
+}
+func (UnimplementedDataServiceServer) SnapshotShard(context.Context, *SnapshotShardRequest) (*SnapshotShardResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SnapshotShard not implemented")
+}
+func (UnimplementedDataServiceServer) RestoreShard(context.Context, *RestoreShardRequest) (*RestoreShardResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RestoreShard not implemented")
+}
+func (UnimplementedDataServiceServer) ScanShard(context.Context, *ScanShardRequest) (*ScanShardResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ScanShard not implemented")
+}
 func (UnimplementedDataServiceServer) IndexDocument(context.Context, *IndexDocumentRequest) (*IndexDocumentResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method IndexDocument not implemented")
 }
 func (UnimplementedDataServiceServer) GetDocument(context.Context, *GetDocumentRequest) (*GetDocumentResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetDocument not implemented")
 }
+func (UnimplementedDataServiceServer) TermVectors(context.Context, *TermVectorsRequest) (*TermVectorsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method TermVectors not implemented")
+}
 func (UnimplementedDataServiceServer) DeleteDocument(context.Context, *DeleteDocumentRequest) (*DeleteDocumentResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method DeleteDocument not implemented")
 }
@@ -263,9 +385,15 @@ func (UnimplementedDataServiceServer) Search(context.Context, *SearchRequest) (*
 func (UnimplementedDataServiceServer) Count(context.Context, *CountRequest) (*CountResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Count not implemented")
 }
+func (UnimplementedDataServiceServer) Suggest(context.Context, *SuggestRequest) (*SuggestResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Suggest not implemented")
+}
 func (UnimplementedDataServiceServer) GetShardStats(context.Context, *GetShardStatsRequest) (*ShardStats, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetShardStats not implemented")
 }
+func (UnimplementedDataServiceServer) GetSegmentStats(context.Context, *GetSegmentStatsRequest) (*GetSegmentStatsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSegmentStats not implemented")
+}
 func (UnimplementedDataServiceServer) GetNodeStats(context.Context, *GetNodeStatsRequest) (*DataNodeStats, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetNodeStats not implemented")
 }
@@ -344,6 +472,24 @@ func _DataService_GetShardInfo_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DataService_UpdateShardSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateShardSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataServiceServer).UpdateShardSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataService_UpdateShardSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataServiceServer).UpdateShardSettings(ctx, req.(*UpdateShardSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DataService_RefreshShard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(RefreshShardRequest)
 	if err := dec(in); err != nil {
@@ -380,6 +526,78 @@ func _DataService_FlushShard_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DataService_ForceMerge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForceMergeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataServiceServer).ForceMerge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataService_ForceMerge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataServiceServer).ForceMerge(ctx, req.(*ForceMergeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataService_SnapshotShard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotShardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataServiceServer).SnapshotShard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataService_SnapshotShard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataServiceServer).SnapshotShard(ctx, req.(*SnapshotShardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataService_RestoreShard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreShardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataServiceServer).RestoreShard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataService_RestoreShard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataServiceServer).RestoreShard(ctx, req.(*RestoreShardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataService_ScanShard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScanShardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataServiceServer).ScanShard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataService_ScanShard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataServiceServer).ScanShard(ctx, req.(*ScanShardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DataService_IndexDocument_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(IndexDocumentRequest)
 	if err := dec(in); err != nil {
@@ -416,6 +634,24 @@ func _DataService_GetDocument_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DataService_TermVectors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TermVectorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataServiceServer).TermVectors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataService_TermVectors_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataServiceServer).TermVectors(ctx, req.(*TermVectorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DataService_DeleteDocument_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteDocumentRequest)
 	if err := dec(in); err != nil {
@@ -488,6 +724,24 @@ func _DataService_Count_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DataService_Suggest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuggestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataServiceServer).Suggest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataService_Suggest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataServiceServer).Suggest(ctx, req.(*SuggestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DataService_GetShardStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetShardStatsRequest)
 	if err := dec(in); err != nil {
@@ -506,6 +760,24 @@ func _DataService_GetShardStats_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DataService_GetSegmentStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSegmentStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataServiceServer).GetSegmentStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataService_GetSegmentStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataServiceServer).GetSegmentStats(ctx, req.(*GetSegmentStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DataService_GetNodeStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetNodeStatsRequest)
 	if err := dec(in); err != nil {
@@ -543,6 +815,10 @@ var DataService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetShardInfo",
 			Handler:    _DataService_GetShardInfo_Handler,
 		},
+		{
+			MethodName: "UpdateShardSettings",
+			Handler:    _DataService_UpdateShardSettings_Handler,
+		},
 		{
 			MethodName: "RefreshShard",
 			Handler:    _DataService_RefreshShard_Handler,
@@ -551,6 +827,22 @@ var DataService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "FlushShard",
 			Handler:    _DataService_FlushShard_Handler,
 		},
+		{
+			MethodName: "ForceMerge",
+			Handler:    _DataService_ForceMerge_Handler,
+		},
+		{
+			MethodName: "SnapshotShard",
+			Handler:    _DataService_SnapshotShard_Handler,
+		},
+		{
+			MethodName: "RestoreShard",
+			Handler:    _DataService_RestoreShard_Handler,
+		},
+		{
+			MethodName: "ScanShard",
+			Handler:    _DataService_ScanShard_Handler,
+		},
 		{
 			MethodName: "IndexDocument",
 			Handler:    _DataService_IndexDocument_Handler,
@@ -559,6 +851,10 @@ var DataService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetDocument",
 			Handler:    _DataService_GetDocument_Handler,
 		},
+		{
+			MethodName: "TermVectors",
+			Handler:    _DataService_TermVectors_Handler,
+		},
 		{
 			MethodName: "DeleteDocument",
 			Handler:    _DataService_DeleteDocument_Handler,
@@ -575,10 +871,18 @@ var DataService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Count",
 			Handler:    _DataService_Count_Handler,
 		},
+		{
+			MethodName: "Suggest",
+			Handler:    _DataService_Suggest_Handler,
+		},
 		{
 			MethodName: "GetShardStats",
 			Handler:    _DataService_GetShardStats_Handler,
 		},
+		{
+			MethodName: "GetSegmentStats",
+			Handler:    _DataService_GetSegmentStats_Handler,
+		},
 		{
 			MethodName: "GetNodeStats",
 			Handler:    _DataService_GetNodeStats_Handler,
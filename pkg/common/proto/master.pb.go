@@ -297,7 +297,7 @@ func (x IndexMetadata_IndexState) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use IndexMetadata_IndexState.Descriptor instead.
 func (IndexMetadata_IndexState) EnumDescriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{12, 0}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{14, 0}
 }
 
 type ShardAllocation_ShardState int32
@@ -352,7 +352,7 @@ func (x ShardAllocation_ShardState) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ShardAllocation_ShardState.Descriptor instead.
 func (ShardAllocation_ShardState) EnumDescriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{25, 0}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{27, 0}
 }
 
 // Cluster State
@@ -1021,6 +1021,150 @@ func (x *IndexMetadataResponse) GetMetadata() *IndexMetadata {
 	return nil
 }
 
+type PutMappingRequest struct {
+	state          protoimpl.MessageState   `protogen:"open.v1"`
+	IndexName      string                   `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	Mappings       map[string]*FieldMapping `protobuf:"bytes,2,rep,name=mappings,proto3" json:"mappings,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // The index's whole mapping, with the new fields merged in
+	Analysis       string                   `protobuf:"bytes,3,opt,name=analysis,proto3" json:"analysis,omitempty"`                                                                           // index.analysis rebuilt for the merged mapping
+	NumericFields  string                   `protobuf:"bytes,4,opt,name=numeric_fields,json=numericFields,proto3" json:"numeric_fields,omitempty"`                                            // index.mapping.numeric_fields rebuilt for the merged mapping
+	Version        int64                    `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`                                                                            // Metadata version the mapping was merged from, 0 to skip the check
+	DateFields     string                   `protobuf:"bytes,6,opt,name=date_fields,json=dateFields,proto3" json:"date_fields,omitempty"`                                                     // index.mapping.date_fields rebuilt for the merged mapping
+	GeoPointFields string                   `protobuf:"bytes,7,opt,name=geo_point_fields,json=geoPointFields,proto3" json:"geo_point_fields,omitempty"`                                       // index.mapping.geo_point_fields rebuilt for the merged mapping
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PutMappingRequest) Reset() {
+	*x = PutMappingRequest{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutMappingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutMappingRequest) ProtoMessage() {}
+
+func (x *PutMappingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutMappingRequest.ProtoReflect.Descriptor instead.
+func (*PutMappingRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PutMappingRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *PutMappingRequest) GetMappings() map[string]*FieldMapping {
+	if x != nil {
+		return x.Mappings
+	}
+	return nil
+}
+
+func (x *PutMappingRequest) GetAnalysis() string {
+	if x != nil {
+		return x.Analysis
+	}
+	return ""
+}
+
+func (x *PutMappingRequest) GetNumericFields() string {
+	if x != nil {
+		return x.NumericFields
+	}
+	return ""
+}
+
+func (x *PutMappingRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *PutMappingRequest) GetDateFields() string {
+	if x != nil {
+		return x.DateFields
+	}
+	return ""
+}
+
+func (x *PutMappingRequest) GetGeoPointFields() string {
+	if x != nil {
+		return x.GeoPointFields
+	}
+	return ""
+}
+
+type PutMappingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	Version       int64                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutMappingResponse) Reset() {
+	*x = PutMappingResponse{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutMappingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutMappingResponse) ProtoMessage() {}
+
+func (x *PutMappingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutMappingResponse.ProtoReflect.Descriptor instead.
+func (*PutMappingResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PutMappingResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+func (x *PutMappingResponse) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 type IndexMetadata struct {
 	state         protoimpl.MessageState   `protogen:"open.v1"`
 	IndexName     string                   `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
@@ -1037,7 +1181,7 @@ type IndexMetadata struct {
 
 func (x *IndexMetadata) Reset() {
 	*x = IndexMetadata{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[12]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1049,7 +1193,7 @@ func (x *IndexMetadata) String() string {
 func (*IndexMetadata) ProtoMessage() {}
 
 func (x *IndexMetadata) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[12]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1062,7 +1206,7 @@ func (x *IndexMetadata) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use IndexMetadata.ProtoReflect.Descriptor instead.
 func (*IndexMetadata) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{12}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *IndexMetadata) GetIndexName() string {
@@ -1122,19 +1266,30 @@ func (x *IndexMetadata) GetCreatedAt() *timestamppb.Timestamp {
 }
 
 type IndexSettings struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	NumberOfShards   int32                  `protobuf:"varint,1,opt,name=number_of_shards,json=numberOfShards,proto3" json:"number_of_shards,omitempty"`
-	NumberOfReplicas int32                  `protobuf:"varint,2,opt,name=number_of_replicas,json=numberOfReplicas,proto3" json:"number_of_replicas,omitempty"`
-	RefreshInterval  string                 `protobuf:"bytes,3,opt,name=refresh_interval,json=refreshInterval,proto3" json:"refresh_interval,omitempty"`
-	Compression      *CompressionSettings   `protobuf:"bytes,4,opt,name=compression,proto3" json:"compression,omitempty"`
-	Tiering          *TieringSettings       `protobuf:"bytes,5,opt,name=tiering,proto3" json:"tiering,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	NumberOfShards       int32                  `protobuf:"varint,1,opt,name=number_of_shards,json=numberOfShards,proto3" json:"number_of_shards,omitempty"`
+	NumberOfReplicas     int32                  `protobuf:"varint,2,opt,name=number_of_replicas,json=numberOfReplicas,proto3" json:"number_of_replicas,omitempty"`
+	RefreshInterval      string                 `protobuf:"bytes,3,opt,name=refresh_interval,json=refreshInterval,proto3" json:"refresh_interval,omitempty"`
+	Compression          *CompressionSettings   `protobuf:"bytes,4,opt,name=compression,proto3" json:"compression,omitempty"`
+	Tiering              *TieringSettings       `protobuf:"bytes,5,opt,name=tiering,proto3" json:"tiering,omitempty"`
+	StoreType            string                 `protobuf:"bytes,6,opt,name=store_type,json=storeType,proto3" json:"store_type,omitempty"`                                        // mmapfs, niofs, hybridfs
+	BufferSizeMb         float64                `protobuf:"fixed64,7,opt,name=buffer_size_mb,json=bufferSizeMb,proto3" json:"buffer_size_mb,omitempty"`                           // Indexing RAM buffer per shard, 0 for the data node default
+	Analysis             string                 `protobuf:"bytes,8,opt,name=analysis,proto3" json:"analysis,omitempty"`                                                           // JSON analyzer settings: custom analyzers and per-field analyzers
+	MaxFieldValueLength  int64                  `protobuf:"varint,9,opt,name=max_field_value_length,json=maxFieldValueLength,proto3" json:"max_field_value_length,omitempty"`     // Longest string value a field may hold, in bytes, 0 for the data node default
+	MaxDocumentSizeBytes int64                  `protobuf:"varint,10,opt,name=max_document_size_bytes,json=maxDocumentSizeBytes,proto3" json:"max_document_size_bytes,omitempty"` // Largest document a shard indexes, 0 for the data node default
+	NumericFields        string                 `protobuf:"bytes,11,opt,name=numeric_fields,json=numericFields,proto3" json:"numeric_fields,omitempty"`                           // JSON numeric field mappings: type and ignore_malformed per field
+	BlocksWrite          bool                   `protobuf:"varint,12,opt,name=blocks_write,json=blocksWrite,proto3" json:"blocks_write,omitempty"`                                // Reject document writes to the index, as index.blocks.write
+	DateFields           string                 `protobuf:"bytes,13,opt,name=date_fields,json=dateFields,proto3" json:"date_fields,omitempty"`                                    // JSON date field mappings: the formats of each field
+	GeoPointFields       string                 `protobuf:"bytes,14,opt,name=geo_point_fields,json=geoPointFields,proto3" json:"geo_point_fields,omitempty"`                      // JSON list of the fields mapped as geo_point
+	MaxResultWindow      int32                  `protobuf:"varint,15,opt,name=max_result_window,json=maxResultWindow,proto3" json:"max_result_window,omitempty"`                  // Largest from+size a search may request, 0 for the default
+	SlowlogThreshold     string                 `protobuf:"bytes,16,opt,name=slowlog_threshold,json=slowlogThreshold,proto3" json:"slowlog_threshold,omitempty"`                  // Search duration past which searches are slow logged, such as "1s"; empty when off
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *IndexSettings) Reset() {
 	*x = IndexSettings{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[13]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1146,7 +1301,7 @@ func (x *IndexSettings) String() string {
 func (*IndexSettings) ProtoMessage() {}
 
 func (x *IndexSettings) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[13]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1159,7 +1314,7 @@ func (x *IndexSettings) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use IndexSettings.ProtoReflect.Descriptor instead.
 func (*IndexSettings) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{13}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *IndexSettings) GetNumberOfShards() int32 {
@@ -1197,6 +1352,83 @@ func (x *IndexSettings) GetTiering() *TieringSettings {
 	return nil
 }
 
+func (x *IndexSettings) GetStoreType() string {
+	if x != nil {
+		return x.StoreType
+	}
+	return ""
+}
+
+func (x *IndexSettings) GetBufferSizeMb() float64 {
+	if x != nil {
+		return x.BufferSizeMb
+	}
+	return 0
+}
+
+func (x *IndexSettings) GetAnalysis() string {
+	if x != nil {
+		return x.Analysis
+	}
+	return ""
+}
+
+func (x *IndexSettings) GetMaxFieldValueLength() int64 {
+	if x != nil {
+		return x.MaxFieldValueLength
+	}
+	return 0
+}
+
+func (x *IndexSettings) GetMaxDocumentSizeBytes() int64 {
+	if x != nil {
+		return x.MaxDocumentSizeBytes
+	}
+	return 0
+}
+
+func (x *IndexSettings) GetNumericFields() string {
+	if x != nil {
+		return x.NumericFields
+	}
+	return ""
+}
+
+func (x *IndexSettings) GetBlocksWrite() bool {
+	if x != nil {
+		return x.BlocksWrite
+	}
+	return false
+}
+
+func (x *IndexSettings) GetDateFields() string {
+	if x != nil {
+		return x.DateFields
+	}
+	return ""
+}
+
+func (x *IndexSettings) GetGeoPointFields() string {
+	if x != nil {
+		return x.GeoPointFields
+	}
+	return ""
+}
+
+func (x *IndexSettings) GetMaxResultWindow() int32 {
+	if x != nil {
+		return x.MaxResultWindow
+	}
+	return 0
+}
+
+func (x *IndexSettings) GetSlowlogThreshold() string {
+	if x != nil {
+		return x.SlowlogThreshold
+	}
+	return ""
+}
+
 type CompressionSettings struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Codec         string                 `protobuf:"bytes,1,opt,name=codec,proto3" json:"codec,omitempty"` // lz4, zstd, deflate
@@ -1207,7 +1439,7 @@ type CompressionSettings struct {
 
 func (x *CompressionSettings) Reset() {
 	*x = CompressionSettings{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[14]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1219,7 +1451,7 @@ func (x *CompressionSettings) String() string {
 func (*CompressionSettings) ProtoMessage() {}
 
 func (x *CompressionSettings) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[14]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1232,7 +1464,7 @@ func (x *CompressionSettings) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CompressionSettings.ProtoReflect.Descriptor instead.
 func (*CompressionSettings) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{14}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *CompressionSettings) GetCodec() string {
@@ -1259,7 +1491,7 @@ type TieringSettings struct {
 
 func (x *TieringSettings) Reset() {
 	*x = TieringSettings{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[15]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1271,7 +1503,7 @@ func (x *TieringSettings) String() string {
 func (*TieringSettings) ProtoMessage() {}
 
 func (x *TieringSettings) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[15]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1284,7 +1516,7 @@ func (x *TieringSettings) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TieringSettings.ProtoReflect.Descriptor instead.
 func (*TieringSettings) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{15}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *TieringSettings) GetDefaultTier() string {
@@ -1302,19 +1534,21 @@ func (x *TieringSettings) GetTierRules() map[string]string {
 }
 
 type FieldMapping struct {
-	state         protoimpl.MessageState   `protogen:"open.v1"`
-	Type          string                   `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // text, keyword, long, double, date, boolean, etc.
-	Index         bool                     `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
-	Store         bool                     `protobuf:"varint,3,opt,name=store,proto3" json:"store,omitempty"`
-	Analyzer      string                   `protobuf:"bytes,4,opt,name=analyzer,proto3" json:"analyzer,omitempty"`
-	Properties    map[string]*FieldMapping `protobuf:"bytes,5,rep,name=properties,proto3" json:"properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState   `protogen:"open.v1"`
+	Type            string                   `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // text, keyword, long, double, date, boolean, etc.
+	Index           bool                     `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	Store           bool                     `protobuf:"varint,3,opt,name=store,proto3" json:"store,omitempty"`
+	Analyzer        string                   `protobuf:"bytes,4,opt,name=analyzer,proto3" json:"analyzer,omitempty"`
+	Properties      map[string]*FieldMapping `protobuf:"bytes,5,rep,name=properties,proto3" json:"properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	IgnoreMalformed bool                     `protobuf:"varint,6,opt,name=ignore_malformed,json=ignoreMalformed,proto3" json:"ignore_malformed,omitempty"` // Skip values that don't parse as the field's type instead of rejecting the document
+	Format          string                   `protobuf:"bytes,7,opt,name=format,proto3" json:"format,omitempty"`                                           // Date formats of a date field, separated by ||
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *FieldMapping) Reset() {
 	*x = FieldMapping{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[16]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1326,7 +1560,7 @@ func (x *FieldMapping) String() string {
 func (*FieldMapping) ProtoMessage() {}
 
 func (x *FieldMapping) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[16]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1339,7 +1573,7 @@ func (x *FieldMapping) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FieldMapping.ProtoReflect.Descriptor instead.
 func (*FieldMapping) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{16}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *FieldMapping) GetType() string {
@@ -1377,6 +1611,20 @@ func (x *FieldMapping) GetProperties() map[string]*FieldMapping {
 	return nil
 }
 
+func (x *FieldMapping) GetIgnoreMalformed() bool {
+	if x != nil {
+		return x.IgnoreMalformed
+	}
+	return false
+}
+
+func (x *FieldMapping) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
 // Shard Allocation
 type AllocateShardRequest struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
@@ -1390,7 +1638,7 @@ type AllocateShardRequest struct {
 
 func (x *AllocateShardRequest) Reset() {
 	*x = AllocateShardRequest{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[17]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1402,7 +1650,7 @@ func (x *AllocateShardRequest) String() string {
 func (*AllocateShardRequest) ProtoMessage() {}
 
 func (x *AllocateShardRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[17]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1415,7 +1663,7 @@ func (x *AllocateShardRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AllocateShardRequest.ProtoReflect.Descriptor instead.
 func (*AllocateShardRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{17}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *AllocateShardRequest) GetIndexName() string {
@@ -1457,7 +1705,7 @@ type AllocateShardResponse struct {
 
 func (x *AllocateShardResponse) Reset() {
 	*x = AllocateShardResponse{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[18]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1469,7 +1717,7 @@ func (x *AllocateShardResponse) String() string {
 func (*AllocateShardResponse) ProtoMessage() {}
 
 func (x *AllocateShardResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[18]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1482,7 +1730,7 @@ func (x *AllocateShardResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AllocateShardResponse.ProtoReflect.Descriptor instead.
 func (*AllocateShardResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{18}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *AllocateShardResponse) GetAcknowledged() bool {
@@ -1516,7 +1764,7 @@ type RebalanceShardsRequest struct {
 
 func (x *RebalanceShardsRequest) Reset() {
 	*x = RebalanceShardsRequest{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[19]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1528,7 +1776,7 @@ func (x *RebalanceShardsRequest) String() string {
 func (*RebalanceShardsRequest) ProtoMessage() {}
 
 func (x *RebalanceShardsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[19]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1541,7 +1789,7 @@ func (x *RebalanceShardsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RebalanceShardsRequest.ProtoReflect.Descriptor instead.
 func (*RebalanceShardsRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{19}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *RebalanceShardsRequest) GetIndexNames() []string {
@@ -1567,7 +1815,7 @@ type RebalanceShardsResponse struct {
 
 func (x *RebalanceShardsResponse) Reset() {
 	*x = RebalanceShardsResponse{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[20]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1579,7 +1827,7 @@ func (x *RebalanceShardsResponse) String() string {
 func (*RebalanceShardsResponse) ProtoMessage() {}
 
 func (x *RebalanceShardsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[20]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1592,7 +1840,7 @@ func (x *RebalanceShardsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RebalanceShardsResponse.ProtoReflect.Descriptor instead.
 func (*RebalanceShardsResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{20}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *RebalanceShardsResponse) GetRelocations() []*ShardRelocation {
@@ -1614,7 +1862,7 @@ type ShardRelocation struct {
 
 func (x *ShardRelocation) Reset() {
 	*x = ShardRelocation{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[21]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1626,7 +1874,7 @@ func (x *ShardRelocation) String() string {
 func (*ShardRelocation) ProtoMessage() {}
 
 func (x *ShardRelocation) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[21]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1639,7 +1887,7 @@ func (x *ShardRelocation) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ShardRelocation.ProtoReflect.Descriptor instead.
 func (*ShardRelocation) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{21}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *ShardRelocation) GetIndexName() string {
@@ -1681,7 +1929,7 @@ type RoutingTable struct {
 
 func (x *RoutingTable) Reset() {
 	*x = RoutingTable{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[22]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1693,7 +1941,7 @@ func (x *RoutingTable) String() string {
 func (*RoutingTable) ProtoMessage() {}
 
 func (x *RoutingTable) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[22]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1706,7 +1954,7 @@ func (x *RoutingTable) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RoutingTable.ProtoReflect.Descriptor instead.
 func (*RoutingTable) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{22}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *RoutingTable) GetVersion() int64 {
@@ -1733,7 +1981,7 @@ type IndexRoutingTable struct {
 
 func (x *IndexRoutingTable) Reset() {
 	*x = IndexRoutingTable{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[23]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1745,7 +1993,7 @@ func (x *IndexRoutingTable) String() string {
 func (*IndexRoutingTable) ProtoMessage() {}
 
 func (x *IndexRoutingTable) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[23]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1758,7 +2006,7 @@ func (x *IndexRoutingTable) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use IndexRoutingTable.ProtoReflect.Descriptor instead.
 func (*IndexRoutingTable) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{23}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *IndexRoutingTable) GetIndexName() string {
@@ -1780,13 +2028,14 @@ type ShardRouting struct {
 	ShardId       int32                  `protobuf:"varint,1,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
 	IsPrimary     bool                   `protobuf:"varint,2,opt,name=is_primary,json=isPrimary,proto3" json:"is_primary,omitempty"`
 	Allocation    *ShardAllocation       `protobuf:"bytes,3,opt,name=allocation,proto3" json:"allocation,omitempty"`
+	Replicas      []*ShardAllocation     `protobuf:"bytes,4,rep,name=replicas,proto3" json:"replicas,omitempty"` // Replica copies of the shard
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ShardRouting) Reset() {
 	*x = ShardRouting{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[24]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1798,7 +2047,7 @@ func (x *ShardRouting) String() string {
 func (*ShardRouting) ProtoMessage() {}
 
 func (x *ShardRouting) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[24]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1811,7 +2060,7 @@ func (x *ShardRouting) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ShardRouting.ProtoReflect.Descriptor instead.
 func (*ShardRouting) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{24}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *ShardRouting) GetShardId() int32 {
@@ -1835,18 +2084,26 @@ func (x *ShardRouting) GetAllocation() *ShardAllocation {
 	return nil
 }
 
+func (x *ShardRouting) GetReplicas() []*ShardAllocation {
+	if x != nil {
+		return x.Replicas
+	}
+	return nil
+}
+
 type ShardAllocation struct {
-	state         protoimpl.MessageState     `protogen:"open.v1"`
-	NodeId        string                     `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
-	State         ShardAllocation_ShardState `protobuf:"varint,2,opt,name=state,proto3,enum=quidditch.master.ShardAllocation_ShardState" json:"state,omitempty"`
-	AllocatedAt   *timestamppb.Timestamp     `protobuf:"bytes,3,opt,name=allocated_at,json=allocatedAt,proto3" json:"allocated_at,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState     `protogen:"open.v1"`
+	NodeId           string                     `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	State            ShardAllocation_ShardState `protobuf:"varint,2,opt,name=state,proto3,enum=quidditch.master.ShardAllocation_ShardState" json:"state,omitempty"`
+	AllocatedAt      *timestamppb.Timestamp     `protobuf:"bytes,3,opt,name=allocated_at,json=allocatedAt,proto3" json:"allocated_at,omitempty"`
+	RelocatingNodeId string                     `protobuf:"bytes,4,opt,name=relocating_node_id,json=relocatingNodeId,proto3" json:"relocating_node_id,omitempty"` // Target node of a relocating shard
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *ShardAllocation) Reset() {
 	*x = ShardAllocation{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[25]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1858,7 +2115,7 @@ func (x *ShardAllocation) String() string {
 func (*ShardAllocation) ProtoMessage() {}
 
 func (x *ShardAllocation) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[25]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1871,7 +2128,7 @@ func (x *ShardAllocation) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ShardAllocation.ProtoReflect.Descriptor instead.
 func (*ShardAllocation) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{25}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *ShardAllocation) GetNodeId() string {
@@ -1895,6 +2152,13 @@ func (x *ShardAllocation) GetAllocatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *ShardAllocation) GetRelocatingNodeId() string {
+	if x != nil {
+		return x.RelocatingNodeId
+	}
+	return ""
+}
+
 // Node Management
 type RegisterNodeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1909,7 +2173,7 @@ type RegisterNodeRequest struct {
 
 func (x *RegisterNodeRequest) Reset() {
 	*x = RegisterNodeRequest{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[26]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1921,7 +2185,7 @@ func (x *RegisterNodeRequest) String() string {
 func (*RegisterNodeRequest) ProtoMessage() {}
 
 func (x *RegisterNodeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[26]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1934,7 +2198,7 @@ func (x *RegisterNodeRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RegisterNodeRequest.ProtoReflect.Descriptor instead.
 func (*RegisterNodeRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{26}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *RegisterNodeRequest) GetNodeId() string {
@@ -1982,7 +2246,7 @@ type RegisterNodeResponse struct {
 
 func (x *RegisterNodeResponse) Reset() {
 	*x = RegisterNodeResponse{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[27]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1994,7 +2258,7 @@ func (x *RegisterNodeResponse) String() string {
 func (*RegisterNodeResponse) ProtoMessage() {}
 
 func (x *RegisterNodeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[27]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2007,7 +2271,7 @@ func (x *RegisterNodeResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RegisterNodeResponse.ProtoReflect.Descriptor instead.
 func (*RegisterNodeResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{27}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *RegisterNodeResponse) GetAcknowledged() bool {
@@ -2033,7 +2297,7 @@ type UnregisterNodeRequest struct {
 
 func (x *UnregisterNodeRequest) Reset() {
 	*x = UnregisterNodeRequest{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[28]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2045,7 +2309,7 @@ func (x *UnregisterNodeRequest) String() string {
 func (*UnregisterNodeRequest) ProtoMessage() {}
 
 func (x *UnregisterNodeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[28]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2058,7 +2322,7 @@ func (x *UnregisterNodeRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UnregisterNodeRequest.ProtoReflect.Descriptor instead.
 func (*UnregisterNodeRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{28}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *UnregisterNodeRequest) GetNodeId() string {
@@ -2077,7 +2341,7 @@ type UnregisterNodeResponse struct {
 
 func (x *UnregisterNodeResponse) Reset() {
 	*x = UnregisterNodeResponse{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[29]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2089,7 +2353,7 @@ func (x *UnregisterNodeResponse) String() string {
 func (*UnregisterNodeResponse) ProtoMessage() {}
 
 func (x *UnregisterNodeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[29]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2102,7 +2366,7 @@ func (x *UnregisterNodeResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UnregisterNodeResponse.ProtoReflect.Descriptor instead.
 func (*UnregisterNodeResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{29}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *UnregisterNodeResponse) GetAcknowledged() bool {
@@ -2122,7 +2386,7 @@ type NodeHeartbeatRequest struct {
 
 func (x *NodeHeartbeatRequest) Reset() {
 	*x = NodeHeartbeatRequest{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[30]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2134,7 +2398,7 @@ func (x *NodeHeartbeatRequest) String() string {
 func (*NodeHeartbeatRequest) ProtoMessage() {}
 
 func (x *NodeHeartbeatRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[30]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2147,7 +2411,7 @@ func (x *NodeHeartbeatRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeHeartbeatRequest.ProtoReflect.Descriptor instead.
 func (*NodeHeartbeatRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{30}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *NodeHeartbeatRequest) GetNodeId() string {
@@ -2174,7 +2438,7 @@ type NodeHeartbeatResponse struct {
 
 func (x *NodeHeartbeatResponse) Reset() {
 	*x = NodeHeartbeatResponse{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[31]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2186,7 +2450,7 @@ func (x *NodeHeartbeatResponse) String() string {
 func (*NodeHeartbeatResponse) ProtoMessage() {}
 
 func (x *NodeHeartbeatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[31]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2199,7 +2463,7 @@ func (x *NodeHeartbeatResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeHeartbeatResponse.ProtoReflect.Descriptor instead.
 func (*NodeHeartbeatResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{31}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *NodeHeartbeatResponse) GetAcknowledged() bool {
@@ -2233,7 +2497,7 @@ type NodeInfo struct {
 
 func (x *NodeInfo) Reset() {
 	*x = NodeInfo{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[32]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2245,7 +2509,7 @@ func (x *NodeInfo) String() string {
 func (*NodeInfo) ProtoMessage() {}
 
 func (x *NodeInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[32]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2258,7 +2522,7 @@ func (x *NodeInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeInfo.ProtoReflect.Descriptor instead.
 func (*NodeInfo) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{32}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *NodeInfo) GetNodeId() string {
@@ -2337,7 +2601,7 @@ type NodeAttributes struct {
 
 func (x *NodeAttributes) Reset() {
 	*x = NodeAttributes{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[33]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2349,7 +2613,7 @@ func (x *NodeAttributes) String() string {
 func (*NodeAttributes) ProtoMessage() {}
 
 func (x *NodeAttributes) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[33]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2362,7 +2626,7 @@ func (x *NodeAttributes) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeAttributes.ProtoReflect.Descriptor instead.
 func (*NodeAttributes) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{33}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *NodeAttributes) GetStorageTier() string {
@@ -2416,7 +2680,7 @@ type NodeStats struct {
 
 func (x *NodeStats) Reset() {
 	*x = NodeStats{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[34]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2428,7 +2692,7 @@ func (x *NodeStats) String() string {
 func (*NodeStats) ProtoMessage() {}
 
 func (x *NodeStats) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[34]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2441,7 +2705,7 @@ func (x *NodeStats) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeStats.ProtoReflect.Descriptor instead.
 func (*NodeStats) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{34}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *NodeStats) GetTotalShards() int64 {
@@ -2512,7 +2776,7 @@ type MasterNode struct {
 
 func (x *MasterNode) Reset() {
 	*x = MasterNode{}
-	mi := &file_pkg_common_proto_master_proto_msgTypes[35]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2524,7 +2788,7 @@ func (x *MasterNode) String() string {
 func (*MasterNode) ProtoMessage() {}
 
 func (x *MasterNode) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_common_proto_master_proto_msgTypes[35]
+	mi := &file_pkg_common_proto_master_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2537,7 +2801,7 @@ func (x *MasterNode) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MasterNode.ProtoReflect.Descriptor instead.
 func (*MasterNode) Descriptor() ([]byte, []int) {
-	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{35}
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *MasterNode) GetNodeId() string {
@@ -2568,191 +2832,1966 @@ func (x *MasterNode) GetTerm() int64 {
 	return 0
 }
 
-var File_pkg_common_proto_master_proto protoreflect.FileDescriptor
+// Stored Scripts
+type PutStoredScriptRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Lang          string                 `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`     // "mustache"
+	Source        string                 `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"` // template source
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_pkg_common_proto_master_proto_rawDesc = "" +
-	"\n" +
-	"\x1dpkg/common/proto/master.proto\x12\x10quidditch.master\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8f\x01\n" +
-	"\x16GetClusterStateRequest\x12'\n" +
-	"\x0finclude_routing\x18\x01 \x01(\bR\x0eincludeRouting\x12#\n" +
-	"\rinclude_nodes\x18\x02 \x01(\bR\fincludeNodes\x12'\n" +
-	"\x0finclude_indices\x18\x03 \x01(\bR\x0eincludeIndices\"\xa0\x03\n" +
-	"\x14ClusterStateResponse\x12\x18\n" +
-	"\aversion\x18\x01 \x01(\x03R\aversion\x12!\n" +
-	"\fcluster_name\x18\x02 \x01(\tR\vclusterName\x12!\n" +
-	"\fcluster_uuid\x18\x03 \x01(\tR\vclusterUuid\x127\n" +
-	"\x06status\x18\x04 \x01(\x0e2\x1f.quidditch.master.ClusterStatusR\x06status\x129\n" +
-	"\aindices\x18\x05 \x03(\v2\x1f.quidditch.master.IndexMetadataR\aindices\x12C\n" +
-	"\rrouting_table\x18\x06 \x01(\v2\x1e.quidditch.master.RoutingTableR\froutingTable\x120\n" +
-	"\x05nodes\x18\a \x03(\v2\x1a.quidditch.master.NodeInfoR\x05nodes\x12=\n" +
-	"\vmaster_node\x18\b \x01(\v2\x1c.quidditch.master.MasterNodeR\n" +
-	"masterNode\"=\n" +
-	"\x18WatchClusterStateRequest\x12!\n" +
-	"\ffrom_version\x18\x01 \x01(\x03R\vfromVersion\"\xe2\x02\n" +
-	"\x11ClusterStateEvent\x12\x18\n" +
-	"\aversion\x18\x01 \x01(\x03R\aversion\x12A\n" +
-	"\x04type\x18\x02 \x01(\x0e2-.quidditch.master.ClusterStateEvent.EventTypeR\x04type\x12\x18\n" +
-	"\apayload\x18\x03 \x01(\fR\apayload\"\xd5\x01\n" +
-	"\tEventType\x12\x16\n" +
-	"\x12EVENT_TYPE_UNKNOWN\x10\x00\x12\x1c\n" +
-	"\x18EVENT_TYPE_INDEX_CREATED\x10\x01\x12\x1c\n" +
-	"\x18EVENT_TYPE_INDEX_DELETED\x10\x02\x12\x1e\n" +
-	"\x1aEVENT_TYPE_SHARD_ALLOCATED\x10\x03\x12\x1e\n" +
-	"\x1aEVENT_TYPE_SHARD_RELOCATED\x10\x04\x12\x1a\n" +
-	"\x16EVENT_TYPE_NODE_JOINED\x10\x05\x12\x18\n" +
-	"\x14EVENT_TYPE_NODE_LEFT\x10\x06\"\xa6\x03\n" +
-	"\x12CreateIndexRequest\x12\x1d\n" +
-	"\n" +
-	"index_name\x18\x01 \x01(\tR\tindexName\x12;\n" +
-	"\bsettings\x18\x02 \x01(\v2\x1f.quidditch.master.IndexSettingsR\bsettings\x12N\n" +
-	"\bmappings\x18\x03 \x03(\v22.quidditch.master.CreateIndexRequest.MappingsEntryR\bmappings\x12K\n" +
-	"\aaliases\x18\x04 \x03(\v21.quidditch.master.CreateIndexRequest.AliasesEntryR\aaliases\x1a[\n" +
-	"\rMappingsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x124\n" +
-	"\x05value\x18\x02 \x01(\v2\x1e.quidditch.master.FieldMappingR\x05value:\x028\x01\x1a:\n" +
-	"\fAliasesEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"r\n" +
-	"\x13CreateIndexResponse\x12\"\n" +
-	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12\x1d\n" +
-	"\n" +
-	"index_name\x18\x02 \x01(\tR\tindexName\x12\x18\n" +
-	"\aversion\x18\x03 \x01(\x03R\aversion\"3\n" +
-	"\x12DeleteIndexRequest\x12\x1d\n" +
-	"\n" +
-	"index_name\x18\x01 \x01(\tR\tindexName\"9\n" +
-	"\x13DeleteIndexResponse\x12\"\n" +
-	"\facknowledged\x18\x01 \x01(\bR\facknowledged\"x\n" +
-	"\x1aUpdateIndexSettingsRequest\x12\x1d\n" +
-	"\n" +
-	"index_name\x18\x01 \x01(\tR\tindexName\x12;\n" +
-	"\bsettings\x18\x02 \x01(\v2\x1f.quidditch.master.IndexSettingsR\bsettings\"A\n" +
-	"\x1bUpdateIndexSettingsResponse\x12\"\n" +
-	"\facknowledged\x18\x01 \x01(\bR\facknowledged\"8\n" +
-	"\x17GetIndexMetadataRequest\x12\x1d\n" +
-	"\n" +
-	"index_name\x18\x01 \x01(\tR\tindexName\"T\n" +
-	"\x15IndexMetadataResponse\x12;\n" +
-	"\bmetadata\x18\x01 \x01(\v2\x1f.quidditch.master.IndexMetadataR\bmetadata\"\xd7\x05\n" +
-	"\rIndexMetadata\x12\x1d\n" +
-	"\n" +
-	"index_name\x18\x01 \x01(\tR\tindexName\x12\x1d\n" +
-	"\n" +
-	"index_uuid\x18\x02 \x01(\tR\tindexUuid\x12\x18\n" +
-	"\aversion\x18\x03 \x01(\x03R\aversion\x12;\n" +
-	"\bsettings\x18\x04 \x01(\v2\x1f.quidditch.master.IndexSettingsR\bsettings\x12I\n" +
-	"\bmappings\x18\x05 \x03(\v2-.quidditch.master.IndexMetadata.MappingsEntryR\bmappings\x12F\n" +
-	"\aaliases\x18\x06 \x03(\v2,.quidditch.master.IndexMetadata.AliasesEntryR\aaliases\x12@\n" +
-	"\x05state\x18\a \x01(\x0e2*.quidditch.master.IndexMetadata.IndexStateR\x05state\x129\n" +
-	"\n" +
-	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x1a[\n" +
-	"\rMappingsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x124\n" +
-	"\x05value\x18\x02 \x01(\v2\x1e.quidditch.master.FieldMappingR\x05value:\x028\x01\x1a:\n" +
-	"\fAliasesEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x87\x01\n" +
-	"\n" +
-	"IndexState\x12\x17\n" +
-	"\x13INDEX_STATE_UNKNOWN\x10\x00\x12\x18\n" +
-	"\x14INDEX_STATE_CREATING\x10\x01\x12\x14\n" +
-	"\x10INDEX_STATE_OPEN\x10\x02\x12\x16\n" +
-	"\x12INDEX_STATE_CLOSED\x10\x03\x12\x18\n" +
-	"\x14INDEX_STATE_DELETING\x10\x04\"\x98\x02\n" +
-	"\rIndexSettings\x12(\n" +
-	"\x10number_of_shards\x18\x01 \x01(\x05R\x0enumberOfShards\x12,\n" +
-	"\x12number_of_replicas\x18\x02 \x01(\x05R\x10numberOfReplicas\x12)\n" +
-	"\x10refresh_interval\x18\x03 \x01(\tR\x0frefreshInterval\x12G\n" +
-	"\vcompression\x18\x04 \x01(\v2%.quidditch.master.CompressionSettingsR\vcompression\x12;\n" +
-	"\atiering\x18\x05 \x01(\v2!.quidditch.master.TieringSettingsR\atiering\"A\n" +
-	"\x13CompressionSettings\x12\x14\n" +
-	"\x05codec\x18\x01 \x01(\tR\x05codec\x12\x14\n" +
-	"\x05level\x18\x02 \x01(\x05R\x05level\"\xc3\x01\n" +
-	"\x0fTieringSettings\x12!\n" +
-	"\fdefault_tier\x18\x01 \x01(\tR\vdefaultTier\x12O\n" +
-	"\n" +
-	"tier_rules\x18\x02 \x03(\v20.quidditch.master.TieringSettings.TierRulesEntryR\ttierRules\x1a<\n" +
-	"\x0eTierRulesEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x99\x02\n" +
-	"\fFieldMapping\x12\x12\n" +
-	"\x04type\x18\x01 \x01(\tR\x04type\x12\x14\n" +
-	"\x05index\x18\x02 \x01(\bR\x05index\x12\x14\n" +
-	"\x05store\x18\x03 \x01(\bR\x05store\x12\x1a\n" +
-	"\banalyzer\x18\x04 \x01(\tR\banalyzer\x12N\n" +
-	"\n" +
-	"properties\x18\x05 \x03(\v2..quidditch.master.FieldMapping.PropertiesEntryR\n" +
-	"properties\x1a]\n" +
-	"\x0fPropertiesEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x124\n" +
-	"\x05value\x18\x02 \x01(\v2\x1e.quidditch.master.FieldMappingR\x05value:\x028\x01\"\x9b\x01\n" +
-	"\x14AllocateShardRequest\x12\x1d\n" +
-	"\n" +
-	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
-	"\bshard_id\x18\x02 \x01(\x05R\ashardId\x12\x1d\n" +
-	"\n" +
-	"is_primary\x18\x03 \x01(\bR\tisPrimary\x12*\n" +
-	"\x11preferred_node_id\x18\x04 \x01(\tR\x0fpreferredNodeId\"\x97\x01\n" +
-	"\x15AllocateShardResponse\x12\"\n" +
-	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12\x17\n" +
-	"\anode_id\x18\x02 \x01(\tR\x06nodeId\x12A\n" +
-	"\n" +
-	"allocation\x18\x03 \x01(\v2!.quidditch.master.ShardAllocationR\n" +
-	"allocation\"R\n" +
-	"\x16RebalanceShardsRequest\x12\x1f\n" +
-	"\vindex_names\x18\x01 \x03(\tR\n" +
-	"indexNames\x12\x17\n" +
-	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"^\n" +
-	"\x17RebalanceShardsResponse\x12C\n" +
-	"\vrelocations\x18\x01 \x03(\v2!.quidditch.master.ShardRelocationR\vrelocations\"\x81\x01\n" +
-	"\x0fShardRelocation\x12\x1d\n" +
-	"\n" +
-	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
-	"\bshard_id\x18\x02 \x01(\x05R\ashardId\x12\x1b\n" +
-	"\tfrom_node\x18\x03 \x01(\tR\bfromNode\x12\x17\n" +
-	"\ato_node\x18\x04 \x01(\tR\x06toNode\"\xd0\x01\n" +
-	"\fRoutingTable\x12\x18\n" +
-	"\aversion\x18\x01 \x01(\x03R\aversion\x12E\n" +
-	"\aindices\x18\x02 \x03(\v2+.quidditch.master.RoutingTable.IndicesEntryR\aindices\x1a_\n" +
-	"\fIndicesEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x129\n" +
-	"\x05value\x18\x02 \x01(\v2#.quidditch.master.IndexRoutingTableR\x05value:\x028\x01\"\xd6\x01\n" +
-	"\x11IndexRoutingTable\x12\x1d\n" +
-	"\n" +
-	"index_name\x18\x01 \x01(\tR\tindexName\x12G\n" +
-	"\x06shards\x18\x02 \x03(\v2/.quidditch.master.IndexRoutingTable.ShardsEntryR\x06shards\x1aY\n" +
-	"\vShardsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\x05R\x03key\x124\n" +
-	"\x05value\x18\x02 \x01(\v2\x1e.quidditch.master.ShardRoutingR\x05value:\x028\x01\"\x8b\x01\n" +
-	"\fShardRouting\x12\x19\n" +
-	"\bshard_id\x18\x01 \x01(\x05R\ashardId\x12\x1d\n" +
-	"\n" +
-	"is_primary\x18\x02 \x01(\bR\tisPrimary\x12A\n" +
-	"\n" +
-	"allocation\x18\x03 \x01(\v2!.quidditch.master.ShardAllocationR\n" +
-	"allocation\"\xc4\x02\n" +
-	"\x0fShardAllocation\x12\x17\n" +
-	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12B\n" +
-	"\x05state\x18\x02 \x01(\x0e2,.quidditch.master.ShardAllocation.ShardStateR\x05state\x12=\n" +
-	"\fallocated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vallocatedAt\"\x94\x01\n" +
-	"\n" +
-	"ShardState\x12\x17\n" +
-	"\x13SHARD_STATE_UNKNOWN\x10\x00\x12\x1c\n" +
-	"\x18SHARD_STATE_INITIALIZING\x10\x01\x12\x17\n" +
-	"\x13SHARD_STATE_STARTED\x10\x02\x12\x1a\n" +
-	"\x16SHARD_STATE_RELOCATING\x10\x03\x12\x1a\n" +
-	"\x16SHARD_STATE_UNASSIGNED\x10\x04\"\xe3\x01\n" +
-	"\x13RegisterNodeRequest\x12\x17\n" +
-	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x127\n" +
-	"\tnode_type\x18\x02 \x01(\x0e2\x1a.quidditch.master.NodeTypeR\bnodeType\x12\x1b\n" +
-	"\tbind_addr\x18\x03 \x01(\tR\bbindAddr\x12\x1b\n" +
-	"\tgrpc_port\x18\x04 \x01(\x05R\bgrpcPort\x12@\n" +
-	"\n" +
-	"attributes\x18\x05 \x01(\v2 .quidditch.master.NodeAttributesR\n" +
-	"attributes\"c\n" +
-	"\x14RegisterNodeResponse\x12\"\n" +
-	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12'\n" +
-	"\x0fcluster_version\x18\x02 \x01(\x03R\x0eclusterVersion\"0\n" +
+func (x *PutStoredScriptRequest) Reset() {
+	*x = PutStoredScriptRequest{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutStoredScriptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutStoredScriptRequest) ProtoMessage() {}
+
+func (x *PutStoredScriptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutStoredScriptRequest.ProtoReflect.Descriptor instead.
+func (*PutStoredScriptRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *PutStoredScriptRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PutStoredScriptRequest) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *PutStoredScriptRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+type PutStoredScriptResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutStoredScriptResponse) Reset() {
+	*x = PutStoredScriptResponse{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutStoredScriptResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutStoredScriptResponse) ProtoMessage() {}
+
+func (x *PutStoredScriptResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutStoredScriptResponse.ProtoReflect.Descriptor instead.
+func (*PutStoredScriptResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *PutStoredScriptResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+type GetStoredScriptRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStoredScriptRequest) Reset() {
+	*x = GetStoredScriptRequest{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStoredScriptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStoredScriptRequest) ProtoMessage() {}
+
+func (x *GetStoredScriptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStoredScriptRequest.ProtoReflect.Descriptor instead.
+func (*GetStoredScriptRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *GetStoredScriptRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetStoredScriptResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Lang          string                 `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`
+	Source        string                 `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStoredScriptResponse) Reset() {
+	*x = GetStoredScriptResponse{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStoredScriptResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStoredScriptResponse) ProtoMessage() {}
+
+func (x *GetStoredScriptResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStoredScriptResponse.ProtoReflect.Descriptor instead.
+func (*GetStoredScriptResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *GetStoredScriptResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetStoredScriptResponse) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *GetStoredScriptResponse) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+// Index Templates
+type IndexTemplate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	IndexPatterns []string               `protobuf:"bytes,2,rep,name=index_patterns,json=indexPatterns,proto3" json:"index_patterns,omitempty"` // Wildcard patterns such as "logs-*"
+	Priority      int64                  `protobuf:"varint,3,opt,name=priority,proto3" json:"priority,omitempty"`                               // The highest priority matching template applies
+	Template      string                 `protobuf:"bytes,4,opt,name=template,proto3" json:"template,omitempty"`                                // JSON {"settings": ..., "mappings": ...}
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndexTemplate) Reset() {
+	*x = IndexTemplate{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexTemplate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexTemplate) ProtoMessage() {}
+
+func (x *IndexTemplate) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexTemplate.ProtoReflect.Descriptor instead.
+func (*IndexTemplate) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *IndexTemplate) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *IndexTemplate) GetIndexPatterns() []string {
+	if x != nil {
+		return x.IndexPatterns
+	}
+	return nil
+}
+
+func (x *IndexTemplate) GetPriority() int64 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *IndexTemplate) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+type PutIndexTemplateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Template      *IndexTemplate         `protobuf:"bytes,1,opt,name=template,proto3" json:"template,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutIndexTemplateRequest) Reset() {
+	*x = PutIndexTemplateRequest{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutIndexTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutIndexTemplateRequest) ProtoMessage() {}
+
+func (x *PutIndexTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutIndexTemplateRequest.ProtoReflect.Descriptor instead.
+func (*PutIndexTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *PutIndexTemplateRequest) GetTemplate() *IndexTemplate {
+	if x != nil {
+		return x.Template
+	}
+	return nil
+}
+
+type PutIndexTemplateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutIndexTemplateResponse) Reset() {
+	*x = PutIndexTemplateResponse{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutIndexTemplateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutIndexTemplateResponse) ProtoMessage() {}
+
+func (x *PutIndexTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutIndexTemplateResponse.ProtoReflect.Descriptor instead.
+func (*PutIndexTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *PutIndexTemplateResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+type GetIndexTemplatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // Empty for every template
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetIndexTemplatesRequest) Reset() {
+	*x = GetIndexTemplatesRequest{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetIndexTemplatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetIndexTemplatesRequest) ProtoMessage() {}
+
+func (x *GetIndexTemplatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetIndexTemplatesRequest.ProtoReflect.Descriptor instead.
+func (*GetIndexTemplatesRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *GetIndexTemplatesRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type GetIndexTemplatesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Templates     []*IndexTemplate       `protobuf:"bytes,1,rep,name=templates,proto3" json:"templates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetIndexTemplatesResponse) Reset() {
+	*x = GetIndexTemplatesResponse{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetIndexTemplatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetIndexTemplatesResponse) ProtoMessage() {}
+
+func (x *GetIndexTemplatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetIndexTemplatesResponse.ProtoReflect.Descriptor instead.
+func (*GetIndexTemplatesResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *GetIndexTemplatesResponse) GetTemplates() []*IndexTemplate {
+	if x != nil {
+		return x.Templates
+	}
+	return nil
+}
+
+type DeleteIndexTemplateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteIndexTemplateRequest) Reset() {
+	*x = DeleteIndexTemplateRequest{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteIndexTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteIndexTemplateRequest) ProtoMessage() {}
+
+func (x *DeleteIndexTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteIndexTemplateRequest.ProtoReflect.Descriptor instead.
+func (*DeleteIndexTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *DeleteIndexTemplateRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeleteIndexTemplateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteIndexTemplateResponse) Reset() {
+	*x = DeleteIndexTemplateResponse{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteIndexTemplateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteIndexTemplateResponse) ProtoMessage() {}
+
+func (x *DeleteIndexTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteIndexTemplateResponse.ProtoReflect.Descriptor instead.
+func (*DeleteIndexTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *DeleteIndexTemplateResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+// Snapshot Repositories
+type SnapshotRepository struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`         // Only "fs" is supported
+	Location      string                 `protobuf:"bytes,3,opt,name=location,proto3" json:"location,omitempty"` // Directory shared by every node
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotRepository) Reset() {
+	*x = SnapshotRepository{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotRepository) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotRepository) ProtoMessage() {}
+
+func (x *SnapshotRepository) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotRepository.ProtoReflect.Descriptor instead.
+func (*SnapshotRepository) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *SnapshotRepository) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SnapshotRepository) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SnapshotRepository) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+type PutSnapshotRepositoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Repository    *SnapshotRepository    `protobuf:"bytes,1,opt,name=repository,proto3" json:"repository,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutSnapshotRepositoryRequest) Reset() {
+	*x = PutSnapshotRepositoryRequest{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutSnapshotRepositoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutSnapshotRepositoryRequest) ProtoMessage() {}
+
+func (x *PutSnapshotRepositoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutSnapshotRepositoryRequest.ProtoReflect.Descriptor instead.
+func (*PutSnapshotRepositoryRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *PutSnapshotRepositoryRequest) GetRepository() *SnapshotRepository {
+	if x != nil {
+		return x.Repository
+	}
+	return nil
+}
+
+type PutSnapshotRepositoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutSnapshotRepositoryResponse) Reset() {
+	*x = PutSnapshotRepositoryResponse{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutSnapshotRepositoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutSnapshotRepositoryResponse) ProtoMessage() {}
+
+func (x *PutSnapshotRepositoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutSnapshotRepositoryResponse.ProtoReflect.Descriptor instead.
+func (*PutSnapshotRepositoryResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *PutSnapshotRepositoryResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+type GetSnapshotRepositoriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // Empty for every repository
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSnapshotRepositoriesRequest) Reset() {
+	*x = GetSnapshotRepositoriesRequest{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSnapshotRepositoriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSnapshotRepositoriesRequest) ProtoMessage() {}
+
+func (x *GetSnapshotRepositoriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSnapshotRepositoriesRequest.ProtoReflect.Descriptor instead.
+func (*GetSnapshotRepositoriesRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *GetSnapshotRepositoriesRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type GetSnapshotRepositoriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Repositories  []*SnapshotRepository  `protobuf:"bytes,1,rep,name=repositories,proto3" json:"repositories,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSnapshotRepositoriesResponse) Reset() {
+	*x = GetSnapshotRepositoriesResponse{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSnapshotRepositoriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSnapshotRepositoriesResponse) ProtoMessage() {}
+
+func (x *GetSnapshotRepositoriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSnapshotRepositoriesResponse.ProtoReflect.Descriptor instead.
+func (*GetSnapshotRepositoriesResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *GetSnapshotRepositoriesResponse) GetRepositories() []*SnapshotRepository {
+	if x != nil {
+		return x.Repositories
+	}
+	return nil
+}
+
+// Cluster Settings
+type UpdateClusterSettingsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Settings      map[string]string      `protobuf:"bytes,1,rep,name=settings,proto3" json:"settings,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Dotted setting name -> value
+	ResetSettings []string               `protobuf:"bytes,2,rep,name=reset_settings,json=resetSettings,proto3" json:"reset_settings,omitempty"`                                            // Settings reset to their defaults
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateClusterSettingsRequest) Reset() {
+	*x = UpdateClusterSettingsRequest{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateClusterSettingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateClusterSettingsRequest) ProtoMessage() {}
+
+func (x *UpdateClusterSettingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateClusterSettingsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateClusterSettingsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *UpdateClusterSettingsRequest) GetSettings() map[string]string {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+func (x *UpdateClusterSettingsRequest) GetResetSettings() []string {
+	if x != nil {
+		return x.ResetSettings
+	}
+	return nil
+}
+
+type UpdateClusterSettingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	Settings      map[string]string      `protobuf:"bytes,2,rep,name=settings,proto3" json:"settings,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Settings after the update
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateClusterSettingsResponse) Reset() {
+	*x = UpdateClusterSettingsResponse{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateClusterSettingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateClusterSettingsResponse) ProtoMessage() {}
+
+func (x *UpdateClusterSettingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateClusterSettingsResponse.ProtoReflect.Descriptor instead.
+func (*UpdateClusterSettingsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *UpdateClusterSettingsResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+func (x *UpdateClusterSettingsResponse) GetSettings() map[string]string {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+type GetClusterSettingsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetClusterSettingsRequest) Reset() {
+	*x = GetClusterSettingsRequest{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetClusterSettingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetClusterSettingsRequest) ProtoMessage() {}
+
+func (x *GetClusterSettingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetClusterSettingsRequest.ProtoReflect.Descriptor instead.
+func (*GetClusterSettingsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{56}
+}
+
+type GetClusterSettingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Settings      map[string]string      `protobuf:"bytes,1,rep,name=settings,proto3" json:"settings,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetClusterSettingsResponse) Reset() {
+	*x = GetClusterSettingsResponse{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetClusterSettingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetClusterSettingsResponse) ProtoMessage() {}
+
+func (x *GetClusterSettingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetClusterSettingsResponse.ProtoReflect.Descriptor instead.
+func (*GetClusterSettingsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *GetClusterSettingsResponse) GetSettings() map[string]string {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+type ExplainAllocationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IndexName     string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"` // empty explains the first unassigned shard
+	ShardId       int32                  `protobuf:"varint,2,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
+	Primary       bool                   `protobuf:"varint,3,opt,name=primary,proto3" json:"primary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExplainAllocationRequest) Reset() {
+	*x = ExplainAllocationRequest{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainAllocationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainAllocationRequest) ProtoMessage() {}
+
+func (x *ExplainAllocationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainAllocationRequest.ProtoReflect.Descriptor instead.
+func (*ExplainAllocationRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *ExplainAllocationRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *ExplainAllocationRequest) GetShardId() int32 {
+	if x != nil {
+		return x.ShardId
+	}
+	return 0
+}
+
+func (x *ExplainAllocationRequest) GetPrimary() bool {
+	if x != nil {
+		return x.Primary
+	}
+	return false
+}
+
+type ExplainAllocationResponse struct {
+	state                   protoimpl.MessageState    `protogen:"open.v1"`
+	IndexName               string                    `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	ShardId                 int32                     `protobuf:"varint,2,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
+	Primary                 bool                      `protobuf:"varint,3,opt,name=primary,proto3" json:"primary,omitempty"`
+	CurrentState            string                    `protobuf:"bytes,4,opt,name=current_state,json=currentState,proto3" json:"current_state,omitempty"`
+	CurrentNode             string                    `protobuf:"bytes,5,opt,name=current_node,json=currentNode,proto3" json:"current_node,omitempty"`
+	UnassignedReason        string                    `protobuf:"bytes,6,opt,name=unassigned_reason,json=unassignedReason,proto3" json:"unassigned_reason,omitempty"`
+	UnassignedAt            *timestamppb.Timestamp    `protobuf:"bytes,7,opt,name=unassigned_at,json=unassignedAt,proto3" json:"unassigned_at,omitempty"`
+	CanAllocate             string                    `protobuf:"bytes,8,opt,name=can_allocate,json=canAllocate,proto3" json:"can_allocate,omitempty"` // yes, no (unassigned shards)
+	AllocateExplanation     string                    `protobuf:"bytes,9,opt,name=allocate_explanation,json=allocateExplanation,proto3" json:"allocate_explanation,omitempty"`
+	CanRemain               string                    `protobuf:"bytes,10,opt,name=can_remain,json=canRemain,proto3" json:"can_remain,omitempty"` // yes, no (assigned shards)
+	NodeAllocationDecisions []*NodeAllocationDecision `protobuf:"bytes,11,rep,name=node_allocation_decisions,json=nodeAllocationDecisions,proto3" json:"node_allocation_decisions,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *ExplainAllocationResponse) Reset() {
+	*x = ExplainAllocationResponse{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainAllocationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainAllocationResponse) ProtoMessage() {}
+
+func (x *ExplainAllocationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainAllocationResponse.ProtoReflect.Descriptor instead.
+func (*ExplainAllocationResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *ExplainAllocationResponse) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *ExplainAllocationResponse) GetShardId() int32 {
+	if x != nil {
+		return x.ShardId
+	}
+	return 0
+}
+
+func (x *ExplainAllocationResponse) GetPrimary() bool {
+	if x != nil {
+		return x.Primary
+	}
+	return false
+}
+
+func (x *ExplainAllocationResponse) GetCurrentState() string {
+	if x != nil {
+		return x.CurrentState
+	}
+	return ""
+}
+
+func (x *ExplainAllocationResponse) GetCurrentNode() string {
+	if x != nil {
+		return x.CurrentNode
+	}
+	return ""
+}
+
+func (x *ExplainAllocationResponse) GetUnassignedReason() string {
+	if x != nil {
+		return x.UnassignedReason
+	}
+	return ""
+}
+
+func (x *ExplainAllocationResponse) GetUnassignedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UnassignedAt
+	}
+	return nil
+}
+
+func (x *ExplainAllocationResponse) GetCanAllocate() string {
+	if x != nil {
+		return x.CanAllocate
+	}
+	return ""
+}
+
+func (x *ExplainAllocationResponse) GetAllocateExplanation() string {
+	if x != nil {
+		return x.AllocateExplanation
+	}
+	return ""
+}
+
+func (x *ExplainAllocationResponse) GetCanRemain() string {
+	if x != nil {
+		return x.CanRemain
+	}
+	return ""
+}
+
+func (x *ExplainAllocationResponse) GetNodeAllocationDecisions() []*NodeAllocationDecision {
+	if x != nil {
+		return x.NodeAllocationDecisions
+	}
+	return nil
+}
+
+type NodeAllocationDecision struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	NodeId        string                     `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Zone          string                     `protobuf:"bytes,2,opt,name=zone,proto3" json:"zone,omitempty"`
+	Allowed       bool                       `protobuf:"varint,3,opt,name=allowed,proto3" json:"allowed,omitempty"`
+	Deciders      []*AllocationDeciderResult `protobuf:"bytes,4,rep,name=deciders,proto3" json:"deciders,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeAllocationDecision) Reset() {
+	*x = NodeAllocationDecision{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeAllocationDecision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeAllocationDecision) ProtoMessage() {}
+
+func (x *NodeAllocationDecision) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeAllocationDecision.ProtoReflect.Descriptor instead.
+func (*NodeAllocationDecision) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *NodeAllocationDecision) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *NodeAllocationDecision) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *NodeAllocationDecision) GetAllowed() bool {
+	if x != nil {
+		return x.Allowed
+	}
+	return false
+}
+
+func (x *NodeAllocationDecision) GetDeciders() []*AllocationDeciderResult {
+	if x != nil {
+		return x.Deciders
+	}
+	return nil
+}
+
+type AllocationDeciderResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Decider       string                 `protobuf:"bytes,1,opt,name=decider,proto3" json:"decider,omitempty"`
+	Allowed       bool                   `protobuf:"varint,2,opt,name=allowed,proto3" json:"allowed,omitempty"`
+	Explanation   string                 `protobuf:"bytes,3,opt,name=explanation,proto3" json:"explanation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AllocationDeciderResult) Reset() {
+	*x = AllocationDeciderResult{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AllocationDeciderResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllocationDeciderResult) ProtoMessage() {}
+
+func (x *AllocationDeciderResult) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllocationDeciderResult.ProtoReflect.Descriptor instead.
+func (*AllocationDeciderResult) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *AllocationDeciderResult) GetDecider() string {
+	if x != nil {
+		return x.Decider
+	}
+	return ""
+}
+
+func (x *AllocationDeciderResult) GetAllowed() bool {
+	if x != nil {
+		return x.Allowed
+	}
+	return false
+}
+
+func (x *AllocationDeciderResult) GetExplanation() string {
+	if x != nil {
+		return x.Explanation
+	}
+	return ""
+}
+
+// Pipelines
+type StoredPipeline struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Definition    string                 `protobuf:"bytes,2,opt,name=definition,proto3" json:"definition,omitempty"` // JSON pipeline definition
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StoredPipeline) Reset() {
+	*x = StoredPipeline{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoredPipeline) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredPipeline) ProtoMessage() {}
+
+func (x *StoredPipeline) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredPipeline.ProtoReflect.Descriptor instead.
+func (*StoredPipeline) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *StoredPipeline) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *StoredPipeline) GetDefinition() string {
+	if x != nil {
+		return x.Definition
+	}
+	return ""
+}
+
+type PutPipelineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pipeline      *StoredPipeline        `protobuf:"bytes,1,opt,name=pipeline,proto3" json:"pipeline,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutPipelineRequest) Reset() {
+	*x = PutPipelineRequest{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutPipelineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutPipelineRequest) ProtoMessage() {}
+
+func (x *PutPipelineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutPipelineRequest.ProtoReflect.Descriptor instead.
+func (*PutPipelineRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *PutPipelineRequest) GetPipeline() *StoredPipeline {
+	if x != nil {
+		return x.Pipeline
+	}
+	return nil
+}
+
+type PutPipelineResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutPipelineResponse) Reset() {
+	*x = PutPipelineResponse{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutPipelineResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutPipelineResponse) ProtoMessage() {}
+
+func (x *PutPipelineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutPipelineResponse.ProtoReflect.Descriptor instead.
+func (*PutPipelineResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *PutPipelineResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+type DeletePipelineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePipelineRequest) Reset() {
+	*x = DeletePipelineRequest{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePipelineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePipelineRequest) ProtoMessage() {}
+
+func (x *DeletePipelineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePipelineRequest.ProtoReflect.Descriptor instead.
+func (*DeletePipelineRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *DeletePipelineRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeletePipelineResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePipelineResponse) Reset() {
+	*x = DeletePipelineResponse{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePipelineResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePipelineResponse) ProtoMessage() {}
+
+func (x *DeletePipelineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePipelineResponse.ProtoReflect.Descriptor instead.
+func (*DeletePipelineResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *DeletePipelineResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+type IndexPipeline struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IndexName     string                 `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`         // query, document, result
+	Pipeline      string                 `protobuf:"bytes,3,opt,name=pipeline,proto3" json:"pipeline,omitempty"` // Empty removes the association
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndexPipeline) Reset() {
+	*x = IndexPipeline{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexPipeline) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexPipeline) ProtoMessage() {}
+
+func (x *IndexPipeline) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexPipeline.ProtoReflect.Descriptor instead.
+func (*IndexPipeline) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *IndexPipeline) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *IndexPipeline) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *IndexPipeline) GetPipeline() string {
+	if x != nil {
+		return x.Pipeline
+	}
+	return ""
+}
+
+type GetPipelinesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPipelinesRequest) Reset() {
+	*x = GetPipelinesRequest{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPipelinesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPipelinesRequest) ProtoMessage() {}
+
+func (x *GetPipelinesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPipelinesRequest.ProtoReflect.Descriptor instead.
+func (*GetPipelinesRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{68}
+}
+
+type GetPipelinesResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Pipelines      []*StoredPipeline      `protobuf:"bytes,1,rep,name=pipelines,proto3" json:"pipelines,omitempty"`
+	IndexPipelines []*IndexPipeline       `protobuf:"bytes,2,rep,name=index_pipelines,json=indexPipelines,proto3" json:"index_pipelines,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetPipelinesResponse) Reset() {
+	*x = GetPipelinesResponse{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPipelinesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPipelinesResponse) ProtoMessage() {}
+
+func (x *GetPipelinesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPipelinesResponse.ProtoReflect.Descriptor instead.
+func (*GetPipelinesResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *GetPipelinesResponse) GetPipelines() []*StoredPipeline {
+	if x != nil {
+		return x.Pipelines
+	}
+	return nil
+}
+
+func (x *GetPipelinesResponse) GetIndexPipelines() []*IndexPipeline {
+	if x != nil {
+		return x.IndexPipelines
+	}
+	return nil
+}
+
+type SetIndexPipelineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Association   *IndexPipeline         `protobuf:"bytes,1,opt,name=association,proto3" json:"association,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetIndexPipelineRequest) Reset() {
+	*x = SetIndexPipelineRequest{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetIndexPipelineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetIndexPipelineRequest) ProtoMessage() {}
+
+func (x *SetIndexPipelineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetIndexPipelineRequest.ProtoReflect.Descriptor instead.
+func (*SetIndexPipelineRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *SetIndexPipelineRequest) GetAssociation() *IndexPipeline {
+	if x != nil {
+		return x.Association
+	}
+	return nil
+}
+
+type SetIndexPipelineResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetIndexPipelineResponse) Reset() {
+	*x = SetIndexPipelineResponse{}
+	mi := &file_pkg_common_proto_master_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetIndexPipelineResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetIndexPipelineResponse) ProtoMessage() {}
+
+func (x *SetIndexPipelineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_common_proto_master_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetIndexPipelineResponse.ProtoReflect.Descriptor instead.
+func (*SetIndexPipelineResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_common_proto_master_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *SetIndexPipelineResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+var File_pkg_common_proto_master_proto protoreflect.FileDescriptor
+
+const file_pkg_common_proto_master_proto_rawDesc = "" +
+	"\n" +
+	"\x1dpkg/common/proto/master.proto\x12\x10quidditch.master\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8f\x01\n" +
+	"\x16GetClusterStateRequest\x12'\n" +
+	"\x0finclude_routing\x18\x01 \x01(\bR\x0eincludeRouting\x12#\n" +
+	"\rinclude_nodes\x18\x02 \x01(\bR\fincludeNodes\x12'\n" +
+	"\x0finclude_indices\x18\x03 \x01(\bR\x0eincludeIndices\"\xa0\x03\n" +
+	"\x14ClusterStateResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x03R\aversion\x12!\n" +
+	"\fcluster_name\x18\x02 \x01(\tR\vclusterName\x12!\n" +
+	"\fcluster_uuid\x18\x03 \x01(\tR\vclusterUuid\x127\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x1f.quidditch.master.ClusterStatusR\x06status\x129\n" +
+	"\aindices\x18\x05 \x03(\v2\x1f.quidditch.master.IndexMetadataR\aindices\x12C\n" +
+	"\rrouting_table\x18\x06 \x01(\v2\x1e.quidditch.master.RoutingTableR\froutingTable\x120\n" +
+	"\x05nodes\x18\a \x03(\v2\x1a.quidditch.master.NodeInfoR\x05nodes\x12=\n" +
+	"\vmaster_node\x18\b \x01(\v2\x1c.quidditch.master.MasterNodeR\n" +
+	"masterNode\"=\n" +
+	"\x18WatchClusterStateRequest\x12!\n" +
+	"\ffrom_version\x18\x01 \x01(\x03R\vfromVersion\"\xe2\x02\n" +
+	"\x11ClusterStateEvent\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x03R\aversion\x12A\n" +
+	"\x04type\x18\x02 \x01(\x0e2-.quidditch.master.ClusterStateEvent.EventTypeR\x04type\x12\x18\n" +
+	"\apayload\x18\x03 \x01(\fR\apayload\"\xd5\x01\n" +
+	"\tEventType\x12\x16\n" +
+	"\x12EVENT_TYPE_UNKNOWN\x10\x00\x12\x1c\n" +
+	"\x18EVENT_TYPE_INDEX_CREATED\x10\x01\x12\x1c\n" +
+	"\x18EVENT_TYPE_INDEX_DELETED\x10\x02\x12\x1e\n" +
+	"\x1aEVENT_TYPE_SHARD_ALLOCATED\x10\x03\x12\x1e\n" +
+	"\x1aEVENT_TYPE_SHARD_RELOCATED\x10\x04\x12\x1a\n" +
+	"\x16EVENT_TYPE_NODE_JOINED\x10\x05\x12\x18\n" +
+	"\x14EVENT_TYPE_NODE_LEFT\x10\x06\"\xa6\x03\n" +
+	"\x12CreateIndexRequest\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12;\n" +
+	"\bsettings\x18\x02 \x01(\v2\x1f.quidditch.master.IndexSettingsR\bsettings\x12N\n" +
+	"\bmappings\x18\x03 \x03(\v22.quidditch.master.CreateIndexRequest.MappingsEntryR\bmappings\x12K\n" +
+	"\aaliases\x18\x04 \x03(\v21.quidditch.master.CreateIndexRequest.AliasesEntryR\aaliases\x1a[\n" +
+	"\rMappingsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x124\n" +
+	"\x05value\x18\x02 \x01(\v2\x1e.quidditch.master.FieldMappingR\x05value:\x028\x01\x1a:\n" +
+	"\fAliasesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"r\n" +
+	"\x13CreateIndexResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x02 \x01(\tR\tindexName\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x03R\aversion\"3\n" +
+	"\x12DeleteIndexRequest\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\"9\n" +
+	"\x13DeleteIndexResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\"x\n" +
+	"\x1aUpdateIndexSettingsRequest\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12;\n" +
+	"\bsettings\x18\x02 \x01(\v2\x1f.quidditch.master.IndexSettingsR\bsettings\"A\n" +
+	"\x1bUpdateIndexSettingsResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\"8\n" +
+	"\x17GetIndexMetadataRequest\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\"T\n" +
+	"\x15IndexMetadataResponse\x12;\n" +
+	"\bmetadata\x18\x01 \x01(\v2\x1f.quidditch.master.IndexMetadataR\bmetadata\"\x86\x03\n" +
+	"\x11PutMappingRequest\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12M\n" +
+	"\bmappings\x18\x02 \x03(\v21.quidditch.master.PutMappingRequest.MappingsEntryR\bmappings\x12\x1a\n" +
+	"\banalysis\x18\x03 \x01(\tR\banalysis\x12%\n" +
+	"\x0enumeric_fields\x18\x04 \x01(\tR\rnumericFields\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x03R\aversion\x12\x1f\n" +
+	"\vdate_fields\x18\x06 \x01(\tR\n" +
+	"dateFields\x12(\n" +
+	"\x10geo_point_fields\x18\a \x01(\tR\x0egeoPointFields\x1a[\n" +
+	"\rMappingsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x124\n" +
+	"\x05value\x18\x02 \x01(\v2\x1e.quidditch.master.FieldMappingR\x05value:\x028\x01\"R\n" +
+	"\x12PutMappingResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversion\"\xd7\x05\n" +
+	"\rIndexMetadata\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12\x1d\n" +
+	"\n" +
+	"index_uuid\x18\x02 \x01(\tR\tindexUuid\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x03R\aversion\x12;\n" +
+	"\bsettings\x18\x04 \x01(\v2\x1f.quidditch.master.IndexSettingsR\bsettings\x12I\n" +
+	"\bmappings\x18\x05 \x03(\v2-.quidditch.master.IndexMetadata.MappingsEntryR\bmappings\x12F\n" +
+	"\aaliases\x18\x06 \x03(\v2,.quidditch.master.IndexMetadata.AliasesEntryR\aaliases\x12@\n" +
+	"\x05state\x18\a \x01(\x0e2*.quidditch.master.IndexMetadata.IndexStateR\x05state\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x1a[\n" +
+	"\rMappingsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x124\n" +
+	"\x05value\x18\x02 \x01(\v2\x1e.quidditch.master.FieldMappingR\x05value:\x028\x01\x1a:\n" +
+	"\fAliasesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x87\x01\n" +
+	"\n" +
+	"IndexState\x12\x17\n" +
+	"\x13INDEX_STATE_UNKNOWN\x10\x00\x12\x18\n" +
+	"\x14INDEX_STATE_CREATING\x10\x01\x12\x14\n" +
+	"\x10INDEX_STATE_OPEN\x10\x02\x12\x16\n" +
+	"\x12INDEX_STATE_CLOSED\x10\x03\x12\x18\n" +
+	"\x14INDEX_STATE_DELETING\x10\x04\"\xd3\x05\n" +
+	"\rIndexSettings\x12(\n" +
+	"\x10number_of_shards\x18\x01 \x01(\x05R\x0enumberOfShards\x12,\n" +
+	"\x12number_of_replicas\x18\x02 \x01(\x05R\x10numberOfReplicas\x12)\n" +
+	"\x10refresh_interval\x18\x03 \x01(\tR\x0frefreshInterval\x12G\n" +
+	"\vcompression\x18\x04 \x01(\v2%.quidditch.master.CompressionSettingsR\vcompression\x12;\n" +
+	"\atiering\x18\x05 \x01(\v2!.quidditch.master.TieringSettingsR\atiering\x12\x1d\n" +
+	"\n" +
+	"store_type\x18\x06 \x01(\tR\tstoreType\x12$\n" +
+	"\x0ebuffer_size_mb\x18\a \x01(\x01R\fbufferSizeMb\x12\x1a\n" +
+	"\banalysis\x18\b \x01(\tR\banalysis\x123\n" +
+	"\x16max_field_value_length\x18\t \x01(\x03R\x13maxFieldValueLength\x125\n" +
+	"\x17max_document_size_bytes\x18\n" +
+	" \x01(\x03R\x14maxDocumentSizeBytes\x12%\n" +
+	"\x0enumeric_fields\x18\v \x01(\tR\rnumericFields\x12!\n" +
+	"\fblocks_write\x18\f \x01(\bR\vblocksWrite\x12\x1f\n" +
+	"\vdate_fields\x18\r \x01(\tR\n" +
+	"dateFields\x12(\n" +
+	"\x10geo_point_fields\x18\x0e \x01(\tR\x0egeoPointFields\x12*\n" +
+	"\x11max_result_window\x18\x0f \x01(\x05R\x0fmaxResultWindow\x12+\n" +
+	"\x11slowlog_threshold\x18\x10 \x01(\tR\x10slowlogThreshold\"A\n" +
+	"\x13CompressionSettings\x12\x14\n" +
+	"\x05codec\x18\x01 \x01(\tR\x05codec\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\x05R\x05level\"\xc3\x01\n" +
+	"\x0fTieringSettings\x12!\n" +
+	"\fdefault_tier\x18\x01 \x01(\tR\vdefaultTier\x12O\n" +
+	"\n" +
+	"tier_rules\x18\x02 \x03(\v20.quidditch.master.TieringSettings.TierRulesEntryR\ttierRules\x1a<\n" +
+	"\x0eTierRulesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xdc\x02\n" +
+	"\fFieldMapping\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\bR\x05index\x12\x14\n" +
+	"\x05store\x18\x03 \x01(\bR\x05store\x12\x1a\n" +
+	"\banalyzer\x18\x04 \x01(\tR\banalyzer\x12N\n" +
+	"\n" +
+	"properties\x18\x05 \x03(\v2..quidditch.master.FieldMapping.PropertiesEntryR\n" +
+	"properties\x12)\n" +
+	"\x10ignore_malformed\x18\x06 \x01(\bR\x0fignoreMalformed\x12\x16\n" +
+	"\x06format\x18\a \x01(\tR\x06format\x1a]\n" +
+	"\x0fPropertiesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x124\n" +
+	"\x05value\x18\x02 \x01(\v2\x1e.quidditch.master.FieldMappingR\x05value:\x028\x01\"\x9b\x01\n" +
+	"\x14AllocateShardRequest\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
+	"\bshard_id\x18\x02 \x01(\x05R\ashardId\x12\x1d\n" +
+	"\n" +
+	"is_primary\x18\x03 \x01(\bR\tisPrimary\x12*\n" +
+	"\x11preferred_node_id\x18\x04 \x01(\tR\x0fpreferredNodeId\"\x97\x01\n" +
+	"\x15AllocateShardResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12\x17\n" +
+	"\anode_id\x18\x02 \x01(\tR\x06nodeId\x12A\n" +
+	"\n" +
+	"allocation\x18\x03 \x01(\v2!.quidditch.master.ShardAllocationR\n" +
+	"allocation\"R\n" +
+	"\x16RebalanceShardsRequest\x12\x1f\n" +
+	"\vindex_names\x18\x01 \x03(\tR\n" +
+	"indexNames\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"^\n" +
+	"\x17RebalanceShardsResponse\x12C\n" +
+	"\vrelocations\x18\x01 \x03(\v2!.quidditch.master.ShardRelocationR\vrelocations\"\x81\x01\n" +
+	"\x0fShardRelocation\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
+	"\bshard_id\x18\x02 \x01(\x05R\ashardId\x12\x1b\n" +
+	"\tfrom_node\x18\x03 \x01(\tR\bfromNode\x12\x17\n" +
+	"\ato_node\x18\x04 \x01(\tR\x06toNode\"\xd0\x01\n" +
+	"\fRoutingTable\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x03R\aversion\x12E\n" +
+	"\aindices\x18\x02 \x03(\v2+.quidditch.master.RoutingTable.IndicesEntryR\aindices\x1a_\n" +
+	"\fIndicesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x129\n" +
+	"\x05value\x18\x02 \x01(\v2#.quidditch.master.IndexRoutingTableR\x05value:\x028\x01\"\xd6\x01\n" +
+	"\x11IndexRoutingTable\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12G\n" +
+	"\x06shards\x18\x02 \x03(\v2/.quidditch.master.IndexRoutingTable.ShardsEntryR\x06shards\x1aY\n" +
+	"\vShardsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x05R\x03key\x124\n" +
+	"\x05value\x18\x02 \x01(\v2\x1e.quidditch.master.ShardRoutingR\x05value:\x028\x01\"\xca\x01\n" +
+	"\fShardRouting\x12\x19\n" +
+	"\bshard_id\x18\x01 \x01(\x05R\ashardId\x12\x1d\n" +
+	"\n" +
+	"is_primary\x18\x02 \x01(\bR\tisPrimary\x12A\n" +
+	"\n" +
+	"allocation\x18\x03 \x01(\v2!.quidditch.master.ShardAllocationR\n" +
+	"allocation\x12=\n" +
+	"\breplicas\x18\x04 \x03(\v2!.quidditch.master.ShardAllocationR\breplicas\"\xf2\x02\n" +
+	"\x0fShardAllocation\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12B\n" +
+	"\x05state\x18\x02 \x01(\x0e2,.quidditch.master.ShardAllocation.ShardStateR\x05state\x12=\n" +
+	"\fallocated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vallocatedAt\x12,\n" +
+	"\x12relocating_node_id\x18\x04 \x01(\tR\x10relocatingNodeId\"\x94\x01\n" +
+	"\n" +
+	"ShardState\x12\x17\n" +
+	"\x13SHARD_STATE_UNKNOWN\x10\x00\x12\x1c\n" +
+	"\x18SHARD_STATE_INITIALIZING\x10\x01\x12\x17\n" +
+	"\x13SHARD_STATE_STARTED\x10\x02\x12\x1a\n" +
+	"\x16SHARD_STATE_RELOCATING\x10\x03\x12\x1a\n" +
+	"\x16SHARD_STATE_UNASSIGNED\x10\x04\"\xe3\x01\n" +
+	"\x13RegisterNodeRequest\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x127\n" +
+	"\tnode_type\x18\x02 \x01(\x0e2\x1a.quidditch.master.NodeTypeR\bnodeType\x12\x1b\n" +
+	"\tbind_addr\x18\x03 \x01(\tR\bbindAddr\x12\x1b\n" +
+	"\tgrpc_port\x18\x04 \x01(\x05R\bgrpcPort\x12@\n" +
+	"\n" +
+	"attributes\x18\x05 \x01(\v2 .quidditch.master.NodeAttributesR\n" +
+	"attributes\"c\n" +
+	"\x14RegisterNodeResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12'\n" +
+	"\x0fcluster_version\x18\x02 \x01(\x03R\x0eclusterVersion\"0\n" +
 	"\x15UnregisterNodeRequest\x12\x17\n" +
 	"\anode_id\x18\x01 \x01(\tR\x06nodeId\"<\n" +
 	"\x16UnregisterNodeResponse\x12\"\n" +
@@ -2801,7 +4840,123 @@ const file_pkg_common_proto_master_proto_rawDesc = "" +
 	"\tnode_name\x18\x02 \x01(\tR\bnodeName\x129\n" +
 	"\n" +
 	"elected_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\telectedAt\x12\x12\n" +
-	"\x04term\x18\x04 \x01(\x03R\x04term*x\n" +
+	"\x04term\x18\x04 \x01(\x03R\x04term\"T\n" +
+	"\x16PutStoredScriptRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04lang\x18\x02 \x01(\tR\x04lang\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\"=\n" +
+	"\x17PutStoredScriptResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\"(\n" +
+	"\x16GetStoredScriptRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"U\n" +
+	"\x17GetStoredScriptResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04lang\x18\x02 \x01(\tR\x04lang\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\"\x82\x01\n" +
+	"\rIndexTemplate\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12%\n" +
+	"\x0eindex_patterns\x18\x02 \x03(\tR\rindexPatterns\x12\x1a\n" +
+	"\bpriority\x18\x03 \x01(\x03R\bpriority\x12\x1a\n" +
+	"\btemplate\x18\x04 \x01(\tR\btemplate\"V\n" +
+	"\x17PutIndexTemplateRequest\x12;\n" +
+	"\btemplate\x18\x01 \x01(\v2\x1f.quidditch.master.IndexTemplateR\btemplate\">\n" +
+	"\x18PutIndexTemplateResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\".\n" +
+	"\x18GetIndexTemplatesRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"Z\n" +
+	"\x19GetIndexTemplatesResponse\x12=\n" +
+	"\ttemplates\x18\x01 \x03(\v2\x1f.quidditch.master.IndexTemplateR\ttemplates\"0\n" +
+	"\x1aDeleteIndexTemplateRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"A\n" +
+	"\x1bDeleteIndexTemplateResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\"X\n" +
+	"\x12SnapshotRepository\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1a\n" +
+	"\blocation\x18\x03 \x01(\tR\blocation\"d\n" +
+	"\x1cPutSnapshotRepositoryRequest\x12D\n" +
+	"\n" +
+	"repository\x18\x01 \x01(\v2$.quidditch.master.SnapshotRepositoryR\n" +
+	"repository\"C\n" +
+	"\x1dPutSnapshotRepositoryResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\"4\n" +
+	"\x1eGetSnapshotRepositoriesRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"k\n" +
+	"\x1fGetSnapshotRepositoriesResponse\x12H\n" +
+	"\frepositories\x18\x01 \x03(\v2$.quidditch.master.SnapshotRepositoryR\frepositories\"\xdc\x01\n" +
+	"\x1cUpdateClusterSettingsRequest\x12X\n" +
+	"\bsettings\x18\x01 \x03(\v2<.quidditch.master.UpdateClusterSettingsRequest.SettingsEntryR\bsettings\x12%\n" +
+	"\x0ereset_settings\x18\x02 \x03(\tR\rresetSettings\x1a;\n" +
+	"\rSettingsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xdb\x01\n" +
+	"\x1dUpdateClusterSettingsResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12Y\n" +
+	"\bsettings\x18\x02 \x03(\v2=.quidditch.master.UpdateClusterSettingsResponse.SettingsEntryR\bsettings\x1a;\n" +
+	"\rSettingsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x1b\n" +
+	"\x19GetClusterSettingsRequest\"\xb1\x01\n" +
+	"\x1aGetClusterSettingsResponse\x12V\n" +
+	"\bsettings\x18\x01 \x03(\v2:.quidditch.master.GetClusterSettingsResponse.SettingsEntryR\bsettings\x1a;\n" +
+	"\rSettingsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"n\n" +
+	"\x18ExplainAllocationRequest\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
+	"\bshard_id\x18\x02 \x01(\x05R\ashardId\x12\x18\n" +
+	"\aprimary\x18\x03 \x01(\bR\aprimary\"\x80\x04\n" +
+	"\x19ExplainAllocationResponse\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12\x19\n" +
+	"\bshard_id\x18\x02 \x01(\x05R\ashardId\x12\x18\n" +
+	"\aprimary\x18\x03 \x01(\bR\aprimary\x12#\n" +
+	"\rcurrent_state\x18\x04 \x01(\tR\fcurrentState\x12!\n" +
+	"\fcurrent_node\x18\x05 \x01(\tR\vcurrentNode\x12+\n" +
+	"\x11unassigned_reason\x18\x06 \x01(\tR\x10unassignedReason\x12?\n" +
+	"\runassigned_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\funassignedAt\x12!\n" +
+	"\fcan_allocate\x18\b \x01(\tR\vcanAllocate\x121\n" +
+	"\x14allocate_explanation\x18\t \x01(\tR\x13allocateExplanation\x12\x1d\n" +
+	"\n" +
+	"can_remain\x18\n" +
+	" \x01(\tR\tcanRemain\x12d\n" +
+	"\x19node_allocation_decisions\x18\v \x03(\v2(.quidditch.master.NodeAllocationDecisionR\x17nodeAllocationDecisions\"\xa6\x01\n" +
+	"\x16NodeAllocationDecision\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x12\n" +
+	"\x04zone\x18\x02 \x01(\tR\x04zone\x12\x18\n" +
+	"\aallowed\x18\x03 \x01(\bR\aallowed\x12E\n" +
+	"\bdeciders\x18\x04 \x03(\v2).quidditch.master.AllocationDeciderResultR\bdeciders\"o\n" +
+	"\x17AllocationDeciderResult\x12\x18\n" +
+	"\adecider\x18\x01 \x01(\tR\adecider\x12\x18\n" +
+	"\aallowed\x18\x02 \x01(\bR\aallowed\x12 \n" +
+	"\vexplanation\x18\x03 \x01(\tR\vexplanation\"D\n" +
+	"\x0eStoredPipeline\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1e\n" +
+	"\n" +
+	"definition\x18\x02 \x01(\tR\n" +
+	"definition\"R\n" +
+	"\x12PutPipelineRequest\x12<\n" +
+	"\bpipeline\x18\x01 \x01(\v2 .quidditch.master.StoredPipelineR\bpipeline\"9\n" +
+	"\x13PutPipelineResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\"+\n" +
+	"\x15DeletePipelineRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"<\n" +
+	"\x16DeletePipelineResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\"^\n" +
+	"\rIndexPipeline\x12\x1d\n" +
+	"\n" +
+	"index_name\x18\x01 \x01(\tR\tindexName\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1a\n" +
+	"\bpipeline\x18\x03 \x01(\tR\bpipeline\"\x15\n" +
+	"\x13GetPipelinesRequest\"\xa0\x01\n" +
+	"\x14GetPipelinesResponse\x12>\n" +
+	"\tpipelines\x18\x01 \x03(\v2 .quidditch.master.StoredPipelineR\tpipelines\x12H\n" +
+	"\x0findex_pipelines\x18\x02 \x03(\v2\x1f.quidditch.master.IndexPipelineR\x0eindexPipelines\"\\\n" +
+	"\x17SetIndexPipelineRequest\x12A\n" +
+	"\vassociation\x18\x01 \x01(\v2\x1f.quidditch.master.IndexPipelineR\vassociation\">\n" +
+	"\x18SetIndexPipelineResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged*x\n" +
 	"\rClusterStatus\x12\x1a\n" +
 	"\x16CLUSTER_STATUS_UNKNOWN\x10\x00\x12\x18\n" +
 	"\x14CLUSTER_STATUS_GREEN\x10\x01\x12\x19\n" +
@@ -2819,19 +4974,35 @@ const file_pkg_common_proto_master_proto_rawDesc = "" +
 	"\x13NODE_STATUS_HEALTHY\x10\x01\x12\x18\n" +
 	"\x14NODE_STATUS_DEGRADED\x10\x02\x12\x19\n" +
 	"\x15NODE_STATUS_UNHEALTHY\x10\x03\x12\x17\n" +
-	"\x13NODE_STATUS_OFFLINE\x10\x042\xe0\b\n" +
+	"\x13NODE_STATUS_OFFLINE\x10\x042\xb4\x15\n" +
 	"\rMasterService\x12c\n" +
 	"\x0fGetClusterState\x12(.quidditch.master.GetClusterStateRequest\x1a&.quidditch.master.ClusterStateResponse\x12f\n" +
 	"\x11WatchClusterState\x12*.quidditch.master.WatchClusterStateRequest\x1a#.quidditch.master.ClusterStateEvent0\x01\x12Z\n" +
 	"\vCreateIndex\x12$.quidditch.master.CreateIndexRequest\x1a%.quidditch.master.CreateIndexResponse\x12Z\n" +
 	"\vDeleteIndex\x12$.quidditch.master.DeleteIndexRequest\x1a%.quidditch.master.DeleteIndexResponse\x12r\n" +
 	"\x13UpdateIndexSettings\x12,.quidditch.master.UpdateIndexSettingsRequest\x1a-.quidditch.master.UpdateIndexSettingsResponse\x12f\n" +
-	"\x10GetIndexMetadata\x12).quidditch.master.GetIndexMetadataRequest\x1a'.quidditch.master.IndexMetadataResponse\x12`\n" +
+	"\x10GetIndexMetadata\x12).quidditch.master.GetIndexMetadataRequest\x1a'.quidditch.master.IndexMetadataResponse\x12W\n" +
+	"\n" +
+	"PutMapping\x12#.quidditch.master.PutMappingRequest\x1a$.quidditch.master.PutMappingResponse\x12`\n" +
 	"\rAllocateShard\x12&.quidditch.master.AllocateShardRequest\x1a'.quidditch.master.AllocateShardResponse\x12f\n" +
 	"\x0fRebalanceShards\x12(.quidditch.master.RebalanceShardsRequest\x1a).quidditch.master.RebalanceShardsResponse\x12]\n" +
 	"\fRegisterNode\x12%.quidditch.master.RegisterNodeRequest\x1a&.quidditch.master.RegisterNodeResponse\x12c\n" +
 	"\x0eUnregisterNode\x12'.quidditch.master.UnregisterNodeRequest\x1a(.quidditch.master.UnregisterNodeResponse\x12`\n" +
-	"\rNodeHeartbeat\x12&.quidditch.master.NodeHeartbeatRequest\x1a'.quidditch.master.NodeHeartbeatResponseB1Z/github.com/quidditch/quidditch/pkg/common/protob\x06proto3"
+	"\rNodeHeartbeat\x12&.quidditch.master.NodeHeartbeatRequest\x1a'.quidditch.master.NodeHeartbeatResponse\x12f\n" +
+	"\x0fPutStoredScript\x12(.quidditch.master.PutStoredScriptRequest\x1a).quidditch.master.PutStoredScriptResponse\x12f\n" +
+	"\x0fGetStoredScript\x12(.quidditch.master.GetStoredScriptRequest\x1a).quidditch.master.GetStoredScriptResponse\x12i\n" +
+	"\x10PutIndexTemplate\x12).quidditch.master.PutIndexTemplateRequest\x1a*.quidditch.master.PutIndexTemplateResponse\x12l\n" +
+	"\x11GetIndexTemplates\x12*.quidditch.master.GetIndexTemplatesRequest\x1a+.quidditch.master.GetIndexTemplatesResponse\x12r\n" +
+	"\x13DeleteIndexTemplate\x12,.quidditch.master.DeleteIndexTemplateRequest\x1a-.quidditch.master.DeleteIndexTemplateResponse\x12x\n" +
+	"\x15PutSnapshotRepository\x12..quidditch.master.PutSnapshotRepositoryRequest\x1a/.quidditch.master.PutSnapshotRepositoryResponse\x12~\n" +
+	"\x17GetSnapshotRepositories\x120.quidditch.master.GetSnapshotRepositoriesRequest\x1a1.quidditch.master.GetSnapshotRepositoriesResponse\x12x\n" +
+	"\x15UpdateClusterSettings\x12..quidditch.master.UpdateClusterSettingsRequest\x1a/.quidditch.master.UpdateClusterSettingsResponse\x12o\n" +
+	"\x12GetClusterSettings\x12+.quidditch.master.GetClusterSettingsRequest\x1a,.quidditch.master.GetClusterSettingsResponse\x12l\n" +
+	"\x11ExplainAllocation\x12*.quidditch.master.ExplainAllocationRequest\x1a+.quidditch.master.ExplainAllocationResponse\x12Z\n" +
+	"\vPutPipeline\x12$.quidditch.master.PutPipelineRequest\x1a%.quidditch.master.PutPipelineResponse\x12c\n" +
+	"\x0eDeletePipeline\x12'.quidditch.master.DeletePipelineRequest\x1a(.quidditch.master.DeletePipelineResponse\x12]\n" +
+	"\fGetPipelines\x12%.quidditch.master.GetPipelinesRequest\x1a&.quidditch.master.GetPipelinesResponse\x12i\n" +
+	"\x10SetIndexPipeline\x12).quidditch.master.SetIndexPipelineRequest\x1a*.quidditch.master.SetIndexPipelineResponseB1Z/github.com/quidditch/quidditch/pkg/common/protob\x06proto3"
 
 var (
 	file_pkg_common_proto_master_proto_rawDescOnce sync.Once
@@ -2846,131 +5017,218 @@ func file_pkg_common_proto_master_proto_rawDescGZIP() []byte {
 }
 
 var file_pkg_common_proto_master_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
-var file_pkg_common_proto_master_proto_msgTypes = make([]protoimpl.MessageInfo, 45)
+var file_pkg_common_proto_master_proto_msgTypes = make([]protoimpl.MessageInfo, 85)
 var file_pkg_common_proto_master_proto_goTypes = []any{
-	(ClusterStatus)(0),                  // 0: quidditch.master.ClusterStatus
-	(NodeType)(0),                       // 1: quidditch.master.NodeType
-	(NodeStatus)(0),                     // 2: quidditch.master.NodeStatus
-	(ClusterStateEvent_EventType)(0),    // 3: quidditch.master.ClusterStateEvent.EventType
-	(IndexMetadata_IndexState)(0),       // 4: quidditch.master.IndexMetadata.IndexState
-	(ShardAllocation_ShardState)(0),     // 5: quidditch.master.ShardAllocation.ShardState
-	(*GetClusterStateRequest)(nil),      // 6: quidditch.master.GetClusterStateRequest
-	(*ClusterStateResponse)(nil),        // 7: quidditch.master.ClusterStateResponse
-	(*WatchClusterStateRequest)(nil),    // 8: quidditch.master.WatchClusterStateRequest
-	(*ClusterStateEvent)(nil),           // 9: quidditch.master.ClusterStateEvent
-	(*CreateIndexRequest)(nil),          // 10: quidditch.master.CreateIndexRequest
-	(*CreateIndexResponse)(nil),         // 11: quidditch.master.CreateIndexResponse
-	(*DeleteIndexRequest)(nil),          // 12: quidditch.master.DeleteIndexRequest
-	(*DeleteIndexResponse)(nil),         // 13: quidditch.master.DeleteIndexResponse
-	(*UpdateIndexSettingsRequest)(nil),  // 14: quidditch.master.UpdateIndexSettingsRequest
-	(*UpdateIndexSettingsResponse)(nil), // 15: quidditch.master.UpdateIndexSettingsResponse
-	(*GetIndexMetadataRequest)(nil),     // 16: quidditch.master.GetIndexMetadataRequest
-	(*IndexMetadataResponse)(nil),       // 17: quidditch.master.IndexMetadataResponse
-	(*IndexMetadata)(nil),               // 18: quidditch.master.IndexMetadata
-	(*IndexSettings)(nil),               // 19: quidditch.master.IndexSettings
-	(*CompressionSettings)(nil),         // 20: quidditch.master.CompressionSettings
-	(*TieringSettings)(nil),             // 21: quidditch.master.TieringSettings
-	(*FieldMapping)(nil),                // 22: quidditch.master.FieldMapping
-	(*AllocateShardRequest)(nil),        // 23: quidditch.master.AllocateShardRequest
-	(*AllocateShardResponse)(nil),       // 24: quidditch.master.AllocateShardResponse
-	(*RebalanceShardsRequest)(nil),      // 25: quidditch.master.RebalanceShardsRequest
-	(*RebalanceShardsResponse)(nil),     // 26: quidditch.master.RebalanceShardsResponse
-	(*ShardRelocation)(nil),             // 27: quidditch.master.ShardRelocation
-	(*RoutingTable)(nil),                // 28: quidditch.master.RoutingTable
-	(*IndexRoutingTable)(nil),           // 29: quidditch.master.IndexRoutingTable
-	(*ShardRouting)(nil),                // 30: quidditch.master.ShardRouting
-	(*ShardAllocation)(nil),             // 31: quidditch.master.ShardAllocation
-	(*RegisterNodeRequest)(nil),         // 32: quidditch.master.RegisterNodeRequest
-	(*RegisterNodeResponse)(nil),        // 33: quidditch.master.RegisterNodeResponse
-	(*UnregisterNodeRequest)(nil),       // 34: quidditch.master.UnregisterNodeRequest
-	(*UnregisterNodeResponse)(nil),      // 35: quidditch.master.UnregisterNodeResponse
-	(*NodeHeartbeatRequest)(nil),        // 36: quidditch.master.NodeHeartbeatRequest
-	(*NodeHeartbeatResponse)(nil),       // 37: quidditch.master.NodeHeartbeatResponse
-	(*NodeInfo)(nil),                    // 38: quidditch.master.NodeInfo
-	(*NodeAttributes)(nil),              // 39: quidditch.master.NodeAttributes
-	(*NodeStats)(nil),                   // 40: quidditch.master.NodeStats
-	(*MasterNode)(nil),                  // 41: quidditch.master.MasterNode
-	nil,                                 // 42: quidditch.master.CreateIndexRequest.MappingsEntry
-	nil,                                 // 43: quidditch.master.CreateIndexRequest.AliasesEntry
-	nil,                                 // 44: quidditch.master.IndexMetadata.MappingsEntry
-	nil,                                 // 45: quidditch.master.IndexMetadata.AliasesEntry
-	nil,                                 // 46: quidditch.master.TieringSettings.TierRulesEntry
-	nil,                                 // 47: quidditch.master.FieldMapping.PropertiesEntry
-	nil,                                 // 48: quidditch.master.RoutingTable.IndicesEntry
-	nil,                                 // 49: quidditch.master.IndexRoutingTable.ShardsEntry
-	nil,                                 // 50: quidditch.master.NodeAttributes.LabelsEntry
-	(*timestamppb.Timestamp)(nil),       // 51: google.protobuf.Timestamp
+	(ClusterStatus)(0),                      // 0: quidditch.master.ClusterStatus
+	(NodeType)(0),                           // 1: quidditch.master.NodeType
+	(NodeStatus)(0),                         // 2: quidditch.master.NodeStatus
+	(ClusterStateEvent_EventType)(0),        // 3: quidditch.master.ClusterStateEvent.EventType
+	(IndexMetadata_IndexState)(0),           // 4: quidditch.master.IndexMetadata.IndexState
+	(ShardAllocation_ShardState)(0),         // 5: quidditch.master.ShardAllocation.ShardState
+	(*GetClusterStateRequest)(nil),          // 6: quidditch.master.GetClusterStateRequest
+	(*ClusterStateResponse)(nil),            // 7: quidditch.master.ClusterStateResponse
+	(*WatchClusterStateRequest)(nil),        // 8: quidditch.master.WatchClusterStateRequest
+	(*ClusterStateEvent)(nil),               // 9: quidditch.master.ClusterStateEvent
+	(*CreateIndexRequest)(nil),              // 10: quidditch.master.CreateIndexRequest
+	(*CreateIndexResponse)(nil),             // 11: quidditch.master.CreateIndexResponse
+	(*DeleteIndexRequest)(nil),              // 12: quidditch.master.DeleteIndexRequest
+	(*DeleteIndexResponse)(nil),             // 13: quidditch.master.DeleteIndexResponse
+	(*UpdateIndexSettingsRequest)(nil),      // 14: quidditch.master.UpdateIndexSettingsRequest
+	(*UpdateIndexSettingsResponse)(nil),     // 15: quidditch.master.UpdateIndexSettingsResponse
+	(*GetIndexMetadataRequest)(nil),         // 16: quidditch.master.GetIndexMetadataRequest
+	(*IndexMetadataResponse)(nil),           // 17: quidditch.master.IndexMetadataResponse
+	(*PutMappingRequest)(nil),               // 18: quidditch.master.PutMappingRequest
+	(*PutMappingResponse)(nil),              // 19: quidditch.master.PutMappingResponse
+	(*IndexMetadata)(nil),                   // 20: quidditch.master.IndexMetadata
+	(*IndexSettings)(nil),                   // 21: quidditch.master.IndexSettings
+	(*CompressionSettings)(nil),             // 22: quidditch.master.CompressionSettings
+	(*TieringSettings)(nil),                 // 23: quidditch.master.TieringSettings
+	(*FieldMapping)(nil),                    // 24: quidditch.master.FieldMapping
+	(*AllocateShardRequest)(nil),            // 25: quidditch.master.AllocateShardRequest
+	(*AllocateShardResponse)(nil),           // 26: quidditch.master.AllocateShardResponse
+	(*RebalanceShardsRequest)(nil),          // 27: quidditch.master.RebalanceShardsRequest
+	(*RebalanceShardsResponse)(nil),         // 28: quidditch.master.RebalanceShardsResponse
+	(*ShardRelocation)(nil),                 // 29: quidditch.master.ShardRelocation
+	(*RoutingTable)(nil),                    // 30: quidditch.master.RoutingTable
+	(*IndexRoutingTable)(nil),               // 31: quidditch.master.IndexRoutingTable
+	(*ShardRouting)(nil),                    // 32: quidditch.master.ShardRouting
+	(*ShardAllocation)(nil),                 // 33: quidditch.master.ShardAllocation
+	(*RegisterNodeRequest)(nil),             // 34: quidditch.master.RegisterNodeRequest
+	(*RegisterNodeResponse)(nil),            // 35: quidditch.master.RegisterNodeResponse
+	(*UnregisterNodeRequest)(nil),           // 36: quidditch.master.UnregisterNodeRequest
+	(*UnregisterNodeResponse)(nil),          // 37: quidditch.master.UnregisterNodeResponse
+	(*NodeHeartbeatRequest)(nil),            // 38: quidditch.master.NodeHeartbeatRequest
+	(*NodeHeartbeatResponse)(nil),           // 39: quidditch.master.NodeHeartbeatResponse
+	(*NodeInfo)(nil),                        // 40: quidditch.master.NodeInfo
+	(*NodeAttributes)(nil),                  // 41: quidditch.master.NodeAttributes
+	(*NodeStats)(nil),                       // 42: quidditch.master.NodeStats
+	(*MasterNode)(nil),                      // 43: quidditch.master.MasterNode
+	(*PutStoredScriptRequest)(nil),          // 44: quidditch.master.PutStoredScriptRequest
+	(*PutStoredScriptResponse)(nil),         // 45: quidditch.master.PutStoredScriptResponse
+	(*GetStoredScriptRequest)(nil),          // 46: quidditch.master.GetStoredScriptRequest
+	(*GetStoredScriptResponse)(nil),         // 47: quidditch.master.GetStoredScriptResponse
+	(*IndexTemplate)(nil),                   // 48: quidditch.master.IndexTemplate
+	(*PutIndexTemplateRequest)(nil),         // 49: quidditch.master.PutIndexTemplateRequest
+	(*PutIndexTemplateResponse)(nil),        // 50: quidditch.master.PutIndexTemplateResponse
+	(*GetIndexTemplatesRequest)(nil),        // 51: quidditch.master.GetIndexTemplatesRequest
+	(*GetIndexTemplatesResponse)(nil),       // 52: quidditch.master.GetIndexTemplatesResponse
+	(*DeleteIndexTemplateRequest)(nil),      // 53: quidditch.master.DeleteIndexTemplateRequest
+	(*DeleteIndexTemplateResponse)(nil),     // 54: quidditch.master.DeleteIndexTemplateResponse
+	(*SnapshotRepository)(nil),              // 55: quidditch.master.SnapshotRepository
+	(*PutSnapshotRepositoryRequest)(nil),    // 56: quidditch.master.PutSnapshotRepositoryRequest
+	(*PutSnapshotRepositoryResponse)(nil),   // 57: quidditch.master.PutSnapshotRepositoryResponse
+	(*GetSnapshotRepositoriesRequest)(nil),  // 58: quidditch.master.GetSnapshotRepositoriesRequest
+	(*GetSnapshotRepositoriesResponse)(nil), // 59: quidditch.master.GetSnapshotRepositoriesResponse
+	(*UpdateClusterSettingsRequest)(nil),    // 60: quidditch.master.UpdateClusterSettingsRequest
+	(*UpdateClusterSettingsResponse)(nil),   // 61: quidditch.master.UpdateClusterSettingsResponse
+	(*GetClusterSettingsRequest)(nil),       // 62: quidditch.master.GetClusterSettingsRequest
+	(*GetClusterSettingsResponse)(nil),      // 63: quidditch.master.GetClusterSettingsResponse
+	(*ExplainAllocationRequest)(nil),        // 64: quidditch.master.ExplainAllocationRequest
+	(*ExplainAllocationResponse)(nil),       // 65: quidditch.master.ExplainAllocationResponse
+	(*NodeAllocationDecision)(nil),          // 66: quidditch.master.NodeAllocationDecision
+	(*AllocationDeciderResult)(nil),         // 67: quidditch.master.AllocationDeciderResult
+	(*StoredPipeline)(nil),                  // 68: quidditch.master.StoredPipeline
+	(*PutPipelineRequest)(nil),              // 69: quidditch.master.PutPipelineRequest
+	(*PutPipelineResponse)(nil),             // 70: quidditch.master.PutPipelineResponse
+	(*DeletePipelineRequest)(nil),           // 71: quidditch.master.DeletePipelineRequest
+	(*DeletePipelineResponse)(nil),          // 72: quidditch.master.DeletePipelineResponse
+	(*IndexPipeline)(nil),                   // 73: quidditch.master.IndexPipeline
+	(*GetPipelinesRequest)(nil),             // 74: quidditch.master.GetPipelinesRequest
+	(*GetPipelinesResponse)(nil),            // 75: quidditch.master.GetPipelinesResponse
+	(*SetIndexPipelineRequest)(nil),         // 76: quidditch.master.SetIndexPipelineRequest
+	(*SetIndexPipelineResponse)(nil),        // 77: quidditch.master.SetIndexPipelineResponse
+	nil,                                     // 78: quidditch.master.CreateIndexRequest.MappingsEntry
+	nil,                                     // 79: quidditch.master.CreateIndexRequest.AliasesEntry
+	nil,                                     // 80: quidditch.master.PutMappingRequest.MappingsEntry
+	nil,                                     // 81: quidditch.master.IndexMetadata.MappingsEntry
+	nil,                                     // 82: quidditch.master.IndexMetadata.AliasesEntry
+	nil,                                     // 83: quidditch.master.TieringSettings.TierRulesEntry
+	nil,                                     // 84: quidditch.master.FieldMapping.PropertiesEntry
+	nil,                                     // 85: quidditch.master.RoutingTable.IndicesEntry
+	nil,                                     // 86: quidditch.master.IndexRoutingTable.ShardsEntry
+	nil,                                     // 87: quidditch.master.NodeAttributes.LabelsEntry
+	nil,                                     // 88: quidditch.master.UpdateClusterSettingsRequest.SettingsEntry
+	nil,                                     // 89: quidditch.master.UpdateClusterSettingsResponse.SettingsEntry
+	nil,                                     // 90: quidditch.master.GetClusterSettingsResponse.SettingsEntry
+	(*timestamppb.Timestamp)(nil),           // 91: google.protobuf.Timestamp
 }
 var file_pkg_common_proto_master_proto_depIdxs = []int32{
 	0,  // 0: quidditch.master.ClusterStateResponse.status:type_name -> quidditch.master.ClusterStatus
-	18, // 1: quidditch.master.ClusterStateResponse.indices:type_name -> quidditch.master.IndexMetadata
-	28, // 2: quidditch.master.ClusterStateResponse.routing_table:type_name -> quidditch.master.RoutingTable
-	38, // 3: quidditch.master.ClusterStateResponse.nodes:type_name -> quidditch.master.NodeInfo
-	41, // 4: quidditch.master.ClusterStateResponse.master_node:type_name -> quidditch.master.MasterNode
+	20, // 1: quidditch.master.ClusterStateResponse.indices:type_name -> quidditch.master.IndexMetadata
+	30, // 2: quidditch.master.ClusterStateResponse.routing_table:type_name -> quidditch.master.RoutingTable
+	40, // 3: quidditch.master.ClusterStateResponse.nodes:type_name -> quidditch.master.NodeInfo
+	43, // 4: quidditch.master.ClusterStateResponse.master_node:type_name -> quidditch.master.MasterNode
 	3,  // 5: quidditch.master.ClusterStateEvent.type:type_name -> quidditch.master.ClusterStateEvent.EventType
-	19, // 6: quidditch.master.CreateIndexRequest.settings:type_name -> quidditch.master.IndexSettings
-	42, // 7: quidditch.master.CreateIndexRequest.mappings:type_name -> quidditch.master.CreateIndexRequest.MappingsEntry
-	43, // 8: quidditch.master.CreateIndexRequest.aliases:type_name -> quidditch.master.CreateIndexRequest.AliasesEntry
-	19, // 9: quidditch.master.UpdateIndexSettingsRequest.settings:type_name -> quidditch.master.IndexSettings
-	18, // 10: quidditch.master.IndexMetadataResponse.metadata:type_name -> quidditch.master.IndexMetadata
-	19, // 11: quidditch.master.IndexMetadata.settings:type_name -> quidditch.master.IndexSettings
-	44, // 12: quidditch.master.IndexMetadata.mappings:type_name -> quidditch.master.IndexMetadata.MappingsEntry
-	45, // 13: quidditch.master.IndexMetadata.aliases:type_name -> quidditch.master.IndexMetadata.AliasesEntry
-	4,  // 14: quidditch.master.IndexMetadata.state:type_name -> quidditch.master.IndexMetadata.IndexState
-	51, // 15: quidditch.master.IndexMetadata.created_at:type_name -> google.protobuf.Timestamp
-	20, // 16: quidditch.master.IndexSettings.compression:type_name -> quidditch.master.CompressionSettings
-	21, // 17: quidditch.master.IndexSettings.tiering:type_name -> quidditch.master.TieringSettings
-	46, // 18: quidditch.master.TieringSettings.tier_rules:type_name -> quidditch.master.TieringSettings.TierRulesEntry
-	47, // 19: quidditch.master.FieldMapping.properties:type_name -> quidditch.master.FieldMapping.PropertiesEntry
-	31, // 20: quidditch.master.AllocateShardResponse.allocation:type_name -> quidditch.master.ShardAllocation
-	27, // 21: quidditch.master.RebalanceShardsResponse.relocations:type_name -> quidditch.master.ShardRelocation
-	48, // 22: quidditch.master.RoutingTable.indices:type_name -> quidditch.master.RoutingTable.IndicesEntry
-	49, // 23: quidditch.master.IndexRoutingTable.shards:type_name -> quidditch.master.IndexRoutingTable.ShardsEntry
-	31, // 24: quidditch.master.ShardRouting.allocation:type_name -> quidditch.master.ShardAllocation
-	5,  // 25: quidditch.master.ShardAllocation.state:type_name -> quidditch.master.ShardAllocation.ShardState
-	51, // 26: quidditch.master.ShardAllocation.allocated_at:type_name -> google.protobuf.Timestamp
-	1,  // 27: quidditch.master.RegisterNodeRequest.node_type:type_name -> quidditch.master.NodeType
-	39, // 28: quidditch.master.RegisterNodeRequest.attributes:type_name -> quidditch.master.NodeAttributes
-	40, // 29: quidditch.master.NodeHeartbeatRequest.stats:type_name -> quidditch.master.NodeStats
-	1,  // 30: quidditch.master.NodeInfo.node_type:type_name -> quidditch.master.NodeType
-	39, // 31: quidditch.master.NodeInfo.attributes:type_name -> quidditch.master.NodeAttributes
-	2,  // 32: quidditch.master.NodeInfo.status:type_name -> quidditch.master.NodeStatus
-	51, // 33: quidditch.master.NodeInfo.joined_at:type_name -> google.protobuf.Timestamp
-	51, // 34: quidditch.master.NodeInfo.last_seen:type_name -> google.protobuf.Timestamp
-	50, // 35: quidditch.master.NodeAttributes.labels:type_name -> quidditch.master.NodeAttributes.LabelsEntry
-	51, // 36: quidditch.master.MasterNode.elected_at:type_name -> google.protobuf.Timestamp
-	22, // 37: quidditch.master.CreateIndexRequest.MappingsEntry.value:type_name -> quidditch.master.FieldMapping
-	22, // 38: quidditch.master.IndexMetadata.MappingsEntry.value:type_name -> quidditch.master.FieldMapping
-	22, // 39: quidditch.master.FieldMapping.PropertiesEntry.value:type_name -> quidditch.master.FieldMapping
-	29, // 40: quidditch.master.RoutingTable.IndicesEntry.value:type_name -> quidditch.master.IndexRoutingTable
-	30, // 41: quidditch.master.IndexRoutingTable.ShardsEntry.value:type_name -> quidditch.master.ShardRouting
-	6,  // 42: quidditch.master.MasterService.GetClusterState:input_type -> quidditch.master.GetClusterStateRequest
-	8,  // 43: quidditch.master.MasterService.WatchClusterState:input_type -> quidditch.master.WatchClusterStateRequest
-	10, // 44: quidditch.master.MasterService.CreateIndex:input_type -> quidditch.master.CreateIndexRequest
-	12, // 45: quidditch.master.MasterService.DeleteIndex:input_type -> quidditch.master.DeleteIndexRequest
-	14, // 46: quidditch.master.MasterService.UpdateIndexSettings:input_type -> quidditch.master.UpdateIndexSettingsRequest
-	16, // 47: quidditch.master.MasterService.GetIndexMetadata:input_type -> quidditch.master.GetIndexMetadataRequest
-	23, // 48: quidditch.master.MasterService.AllocateShard:input_type -> quidditch.master.AllocateShardRequest
-	25, // 49: quidditch.master.MasterService.RebalanceShards:input_type -> quidditch.master.RebalanceShardsRequest
-	32, // 50: quidditch.master.MasterService.RegisterNode:input_type -> quidditch.master.RegisterNodeRequest
-	34, // 51: quidditch.master.MasterService.UnregisterNode:input_type -> quidditch.master.UnregisterNodeRequest
-	36, // 52: quidditch.master.MasterService.NodeHeartbeat:input_type -> quidditch.master.NodeHeartbeatRequest
-	7,  // 53: quidditch.master.MasterService.GetClusterState:output_type -> quidditch.master.ClusterStateResponse
-	9,  // 54: quidditch.master.MasterService.WatchClusterState:output_type -> quidditch.master.ClusterStateEvent
-	11, // 55: quidditch.master.MasterService.CreateIndex:output_type -> quidditch.master.CreateIndexResponse
-	13, // 56: quidditch.master.MasterService.DeleteIndex:output_type -> quidditch.master.DeleteIndexResponse
-	15, // 57: quidditch.master.MasterService.UpdateIndexSettings:output_type -> quidditch.master.UpdateIndexSettingsResponse
-	17, // 58: quidditch.master.MasterService.GetIndexMetadata:output_type -> quidditch.master.IndexMetadataResponse
-	24, // 59: quidditch.master.MasterService.AllocateShard:output_type -> quidditch.master.AllocateShardResponse
-	26, // 60: quidditch.master.MasterService.RebalanceShards:output_type -> quidditch.master.RebalanceShardsResponse
-	33, // 61: quidditch.master.MasterService.RegisterNode:output_type -> quidditch.master.RegisterNodeResponse
-	35, // 62: quidditch.master.MasterService.UnregisterNode:output_type -> quidditch.master.UnregisterNodeResponse
-	37, // 63: quidditch.master.MasterService.NodeHeartbeat:output_type -> quidditch.master.NodeHeartbeatResponse
-	53, // [53:64] is the sub-list for method output_type
-	42, // [42:53] is the sub-list for method input_type
-	42, // [42:42] is the sub-list for extension type_name
-	42, // [42:42] is the sub-list for extension extendee
-	0,  // [0:42] is the sub-list for field type_name
+	21, // 6: quidditch.master.CreateIndexRequest.settings:type_name -> quidditch.master.IndexSettings
+	78, // 7: quidditch.master.CreateIndexRequest.mappings:type_name -> quidditch.master.CreateIndexRequest.MappingsEntry
+	79, // 8: quidditch.master.CreateIndexRequest.aliases:type_name -> quidditch.master.CreateIndexRequest.AliasesEntry
+	21, // 9: quidditch.master.UpdateIndexSettingsRequest.settings:type_name -> quidditch.master.IndexSettings
+	20, // 10: quidditch.master.IndexMetadataResponse.metadata:type_name -> quidditch.master.IndexMetadata
+	80, // 11: quidditch.master.PutMappingRequest.mappings:type_name -> quidditch.master.PutMappingRequest.MappingsEntry
+	21, // 12: quidditch.master.IndexMetadata.settings:type_name -> quidditch.master.IndexSettings
+	81, // 13: quidditch.master.IndexMetadata.mappings:type_name -> quidditch.master.IndexMetadata.MappingsEntry
+	82, // 14: quidditch.master.IndexMetadata.aliases:type_name -> quidditch.master.IndexMetadata.AliasesEntry
+	4,  // 15: quidditch.master.IndexMetadata.state:type_name -> quidditch.master.IndexMetadata.IndexState
+	91, // 16: quidditch.master.IndexMetadata.created_at:type_name -> google.protobuf.Timestamp
+	22, // 17: quidditch.master.IndexSettings.compression:type_name -> quidditch.master.CompressionSettings
+	23, // 18: quidditch.master.IndexSettings.tiering:type_name -> quidditch.master.TieringSettings
+	83, // 19: quidditch.master.TieringSettings.tier_rules:type_name -> quidditch.master.TieringSettings.TierRulesEntry
+	84, // 20: quidditch.master.FieldMapping.properties:type_name -> quidditch.master.FieldMapping.PropertiesEntry
+	33, // 21: quidditch.master.AllocateShardResponse.allocation:type_name -> quidditch.master.ShardAllocation
+	29, // 22: quidditch.master.RebalanceShardsResponse.relocations:type_name -> quidditch.master.ShardRelocation
+	85, // 23: quidditch.master.RoutingTable.indices:type_name -> quidditch.master.RoutingTable.IndicesEntry
+	86, // 24: quidditch.master.IndexRoutingTable.shards:type_name -> quidditch.master.IndexRoutingTable.ShardsEntry
+	33, // 25: quidditch.master.ShardRouting.allocation:type_name -> quidditch.master.ShardAllocation
+	33, // 26: quidditch.master.ShardRouting.replicas:type_name -> quidditch.master.ShardAllocation
+	5,  // 27: quidditch.master.ShardAllocation.state:type_name -> quidditch.master.ShardAllocation.ShardState
+	91, // 28: quidditch.master.ShardAllocation.allocated_at:type_name -> google.protobuf.Timestamp
+	1,  // 29: quidditch.master.RegisterNodeRequest.node_type:type_name -> quidditch.master.NodeType
+	41, // 30: quidditch.master.RegisterNodeRequest.attributes:type_name -> quidditch.master.NodeAttributes
+	42, // 31: quidditch.master.NodeHeartbeatRequest.stats:type_name -> quidditch.master.NodeStats
+	1,  // 32: quidditch.master.NodeInfo.node_type:type_name -> quidditch.master.NodeType
+	41, // 33: quidditch.master.NodeInfo.attributes:type_name -> quidditch.master.NodeAttributes
+	2,  // 34: quidditch.master.NodeInfo.status:type_name -> quidditch.master.NodeStatus
+	91, // 35: quidditch.master.NodeInfo.joined_at:type_name -> google.protobuf.Timestamp
+	91, // 36: quidditch.master.NodeInfo.last_seen:type_name -> google.protobuf.Timestamp
+	87, // 37: quidditch.master.NodeAttributes.labels:type_name -> quidditch.master.NodeAttributes.LabelsEntry
+	91, // 38: quidditch.master.MasterNode.elected_at:type_name -> google.protobuf.Timestamp
+	48, // 39: quidditch.master.PutIndexTemplateRequest.template:type_name -> quidditch.master.IndexTemplate
+	48, // 40: quidditch.master.GetIndexTemplatesResponse.templates:type_name -> quidditch.master.IndexTemplate
+	55, // 41: quidditch.master.PutSnapshotRepositoryRequest.repository:type_name -> quidditch.master.SnapshotRepository
+	55, // 42: quidditch.master.GetSnapshotRepositoriesResponse.repositories:type_name -> quidditch.master.SnapshotRepository
+	88, // 43: quidditch.master.UpdateClusterSettingsRequest.settings:type_name -> quidditch.master.UpdateClusterSettingsRequest.SettingsEntry
+	89, // 44: quidditch.master.UpdateClusterSettingsResponse.settings:type_name -> quidditch.master.UpdateClusterSettingsResponse.SettingsEntry
+	90, // 45: quidditch.master.GetClusterSettingsResponse.settings:type_name -> quidditch.master.GetClusterSettingsResponse.SettingsEntry
+	91, // 46: quidditch.master.ExplainAllocationResponse.unassigned_at:type_name -> google.protobuf.Timestamp
+	66, // 47: quidditch.master.ExplainAllocationResponse.node_allocation_decisions:type_name -> quidditch.master.NodeAllocationDecision
+	67, // 48: quidditch.master.NodeAllocationDecision.deciders:type_name -> quidditch.master.AllocationDeciderResult
+	68, // 49: quidditch.master.PutPipelineRequest.pipeline:type_name -> quidditch.master.StoredPipeline
+	68, // 50: quidditch.master.GetPipelinesResponse.pipelines:type_name -> quidditch.master.StoredPipeline
+	73, // 51: quidditch.master.GetPipelinesResponse.index_pipelines:type_name -> quidditch.master.IndexPipeline
+	73, // 52: quidditch.master.SetIndexPipelineRequest.association:type_name -> quidditch.master.IndexPipeline
+	24, // 53: quidditch.master.CreateIndexRequest.MappingsEntry.value:type_name -> quidditch.master.FieldMapping
+	24, // 54: quidditch.master.PutMappingRequest.MappingsEntry.value:type_name -> quidditch.master.FieldMapping
+	24, // 55: quidditch.master.IndexMetadata.MappingsEntry.value:type_name -> quidditch.master.FieldMapping
+	24, // 56: quidditch.master.FieldMapping.PropertiesEntry.value:type_name -> quidditch.master.FieldMapping
+	31, // 57: quidditch.master.RoutingTable.IndicesEntry.value:type_name -> quidditch.master.IndexRoutingTable
+	32, // 58: quidditch.master.IndexRoutingTable.ShardsEntry.value:type_name -> quidditch.master.ShardRouting
+	6,  // 59: quidditch.master.MasterService.GetClusterState:input_type -> quidditch.master.GetClusterStateRequest
+	8,  // 60: quidditch.master.MasterService.WatchClusterState:input_type -> quidditch.master.WatchClusterStateRequest
+	10, // 61: quidditch.master.MasterService.CreateIndex:input_type -> quidditch.master.CreateIndexRequest
+	12, // 62: quidditch.master.MasterService.DeleteIndex:input_type -> quidditch.master.DeleteIndexRequest
+	14, // 63: quidditch.master.MasterService.UpdateIndexSettings:input_type -> quidditch.master.UpdateIndexSettingsRequest
+	16, // 64: quidditch.master.MasterService.GetIndexMetadata:input_type -> quidditch.master.GetIndexMetadataRequest
+	18, // 65: quidditch.master.MasterService.PutMapping:input_type -> quidditch.master.PutMappingRequest
+	25, // 66: quidditch.master.MasterService.AllocateShard:input_type -> quidditch.master.AllocateShardRequest
+	27, // 67: quidditch.master.MasterService.RebalanceShards:input_type -> quidditch.master.RebalanceShardsRequest
+	34, // 68: quidditch.master.MasterService.RegisterNode:input_type -> quidditch.master.RegisterNodeRequest
+	36, // 69: quidditch.master.MasterService.UnregisterNode:input_type -> quidditch.master.UnregisterNodeRequest
+	38, // 70: quidditch.master.MasterService.NodeHeartbeat:input_type -> quidditch.master.NodeHeartbeatRequest
+	44, // 71: quidditch.master.MasterService.PutStoredScript:input_type -> quidditch.master.PutStoredScriptRequest
+	46, // 72: quidditch.master.MasterService.GetStoredScript:input_type -> quidditch.master.GetStoredScriptRequest
+	49, // 73: quidditch.master.MasterService.PutIndexTemplate:input_type -> quidditch.master.PutIndexTemplateRequest
+	51, // 74: quidditch.master.MasterService.GetIndexTemplates:input_type -> quidditch.master.GetIndexTemplatesRequest
+	53, // 75: quidditch.master.MasterService.DeleteIndexTemplate:input_type -> quidditch.master.DeleteIndexTemplateRequest
+	56, // 76: quidditch.master.MasterService.PutSnapshotRepository:input_type -> quidditch.master.PutSnapshotRepositoryRequest
+	58, // 77: quidditch.master.MasterService.GetSnapshotRepositories:input_type -> quidditch.master.GetSnapshotRepositoriesRequest
+	60, // 78: quidditch.master.MasterService.UpdateClusterSettings:input_type -> quidditch.master.UpdateClusterSettingsRequest
+	62, // 79: quidditch.master.MasterService.GetClusterSettings:input_type -> quidditch.master.GetClusterSettingsRequest
+	64, // 80: quidditch.master.MasterService.ExplainAllocation:input_type -> quidditch.master.ExplainAllocationRequest
+	69, // 81: quidditch.master.MasterService.PutPipeline:input_type -> quidditch.master.PutPipelineRequest
+	71, // 82: quidditch.master.MasterService.DeletePipeline:input_type -> quidditch.master.DeletePipelineRequest
+	74, // 83: quidditch.master.MasterService.GetPipelines:input_type -> quidditch.master.GetPipelinesRequest
+	76, // 84: quidditch.master.MasterService.SetIndexPipeline:input_type -> quidditch.master.SetIndexPipelineRequest
+	7,  // 85: quidditch.master.MasterService.GetClusterState:output_type -> quidditch.master.ClusterStateResponse
+	9,  // 86: quidditch.master.MasterService.WatchClusterState:output_type -> quidditch.master.ClusterStateEvent
+	11, // 87: quidditch.master.MasterService.CreateIndex:output_type -> quidditch.master.CreateIndexResponse
+	13, // 88: quidditch.master.MasterService.DeleteIndex:output_type -> quidditch.master.DeleteIndexResponse
+	15, // 89: quidditch.master.MasterService.UpdateIndexSettings:output_type -> quidditch.master.UpdateIndexSettingsResponse
+	17, // 90: quidditch.master.MasterService.GetIndexMetadata:output_type -> quidditch.master.IndexMetadataResponse
+	19, // 91: quidditch.master.MasterService.PutMapping:output_type -> quidditch.master.PutMappingResponse
+	26, // 92: quidditch.master.MasterService.AllocateShard:output_type -> quidditch.master.AllocateShardResponse
+	28, // 93: quidditch.master.MasterService.RebalanceShards:output_type -> quidditch.master.RebalanceShardsResponse
+	35, // 94: quidditch.master.MasterService.RegisterNode:output_type -> quidditch.master.RegisterNodeResponse
+	37, // 95: quidditch.master.MasterService.UnregisterNode:output_type -> quidditch.master.UnregisterNodeResponse
+	39, // 96: quidditch.master.MasterService.NodeHeartbeat:output_type -> quidditch.master.NodeHeartbeatResponse
+	45, // 97: quidditch.master.MasterService.PutStoredScript:output_type -> quidditch.master.PutStoredScriptResponse
+	47, // 98: quidditch.master.MasterService.GetStoredScript:output_type -> quidditch.master.GetStoredScriptResponse
+	50, // 99: quidditch.master.MasterService.PutIndexTemplate:output_type -> quidditch.master.PutIndexTemplateResponse
+	52, // 100: quidditch.master.MasterService.GetIndexTemplates:output_type -> quidditch.master.GetIndexTemplatesResponse
+	54, // 101: quidditch.master.MasterService.DeleteIndexTemplate:output_type -> quidditch.master.DeleteIndexTemplateResponse
+	57, // 102: quidditch.master.MasterService.PutSnapshotRepository:output_type -> quidditch.master.PutSnapshotRepositoryResponse
+	59, // 103: quidditch.master.MasterService.GetSnapshotRepositories:output_type -> quidditch.master.GetSnapshotRepositoriesResponse
+	61, // 104: quidditch.master.MasterService.UpdateClusterSettings:output_type -> quidditch.master.UpdateClusterSettingsResponse
+	63, // 105: quidditch.master.MasterService.GetClusterSettings:output_type -> quidditch.master.GetClusterSettingsResponse
+	65, // 106: quidditch.master.MasterService.ExplainAllocation:output_type -> quidditch.master.ExplainAllocationResponse
+	70, // 107: quidditch.master.MasterService.PutPipeline:output_type -> quidditch.master.PutPipelineResponse
+	72, // 108: quidditch.master.MasterService.DeletePipeline:output_type -> quidditch.master.DeletePipelineResponse
+	75, // 109: quidditch.master.MasterService.GetPipelines:output_type -> quidditch.master.GetPipelinesResponse
+	77, // 110: quidditch.master.MasterService.SetIndexPipeline:output_type -> quidditch.master.SetIndexPipelineResponse
+	85, // [85:111] is the sub-list for method output_type
+	59, // [59:85] is the sub-list for method input_type
+	59, // [59:59] is the sub-list for extension type_name
+	59, // [59:59] is the sub-list for extension extendee
+	0,  // [0:59] is the sub-list for field type_name
 }
 
 func init() { file_pkg_common_proto_master_proto_init() }
@@ -2984,7 +5242,7 @@ func file_pkg_common_proto_master_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_common_proto_master_proto_rawDesc), len(file_pkg_common_proto_master_proto_rawDesc)),
 			NumEnums:      6,
-			NumMessages:   45,
+			NumMessages:   85,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
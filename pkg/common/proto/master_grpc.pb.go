@@ -19,17 +19,32 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	MasterService_GetClusterState_FullMethodName     = "/quidditch.master.MasterService/GetClusterState"
-	MasterService_WatchClusterState_FullMethodName   = "/quidditch.master.MasterService/WatchClusterState"
-	MasterService_CreateIndex_FullMethodName         = "/quidditch.master.MasterService/CreateIndex"
-	MasterService_DeleteIndex_FullMethodName         = "/quidditch.master.MasterService/DeleteIndex"
-	MasterService_UpdateIndexSettings_FullMethodName = "/quidditch.master.MasterService/UpdateIndexSettings"
-	MasterService_GetIndexMetadata_FullMethodName    = "/quidditch.master.MasterService/GetIndexMetadata"
-	MasterService_AllocateShard_FullMethodName       = "/quidditch.master.MasterService/AllocateShard"
-	MasterService_RebalanceShards_FullMethodName     = "/quidditch.master.MasterService/RebalanceShards"
-	MasterService_RegisterNode_FullMethodName        = "/quidditch.master.MasterService/RegisterNode"
-	MasterService_UnregisterNode_FullMethodName      = "/quidditch.master.MasterService/UnregisterNode"
-	MasterService_NodeHeartbeat_FullMethodName       = "/quidditch.master.MasterService/NodeHeartbeat"
+	MasterService_GetClusterState_FullMethodName         = "/quidditch.master.MasterService/GetClusterState"
+	MasterService_WatchClusterState_FullMethodName       = "/quidditch.master.MasterService/WatchClusterState"
+	MasterService_CreateIndex_FullMethodName             = "/quidditch.master.MasterService/CreateIndex"
+	MasterService_DeleteIndex_FullMethodName             = "/quidditch.master.MasterService/DeleteIndex"
+	MasterService_UpdateIndexSettings_FullMethodName     = "/quidditch.master.MasterService/UpdateIndexSettings"
+	MasterService_GetIndexMetadata_FullMethodName        = "/quidditch.master.MasterService/GetIndexMetadata"
+	MasterService_PutMapping_FullMethodName              = "/quidditch.master.MasterService/PutMapping"
+	MasterService_AllocateShard_FullMethodName           = "/quidditch.master.MasterService/AllocateShard"
+	MasterService_RebalanceShards_FullMethodName         = "/quidditch.master.MasterService/RebalanceShards"
+	MasterService_RegisterNode_FullMethodName            = "/quidditch.master.MasterService/RegisterNode"
+	MasterService_UnregisterNode_FullMethodName          = "/quidditch.master.MasterService/UnregisterNode"
+	MasterService_NodeHeartbeat_FullMethodName           = "/quidditch.master.MasterService/NodeHeartbeat"
+	MasterService_PutStoredScript_FullMethodName         = "/quidditch.master.MasterService/PutStoredScript"
+	MasterService_GetStoredScript_FullMethodName         = "/quidditch.master.MasterService/GetStoredScript"
+	MasterService_PutIndexTemplate_FullMethodName        = "/quidditch.master.MasterService/PutIndexTemplate"
+	MasterService_GetIndexTemplates_FullMethodName       = "/quidditch.master.MasterService/GetIndexTemplates"
+	MasterService_DeleteIndexTemplate_FullMethodName     = "/quidditch.master.MasterService/DeleteIndexTemplate"
+	MasterService_PutSnapshotRepository_FullMethodName   = "/quidditch.master.MasterService/PutSnapshotRepository"
+	MasterService_GetSnapshotRepositories_FullMethodName = "/quidditch.master.MasterService/GetSnapshotRepositories"
+	MasterService_UpdateClusterSettings_FullMethodName   = "/quidditch.master.MasterService/UpdateClusterSettings"
+	MasterService_GetClusterSettings_FullMethodName      = "/quidditch.master.MasterService/GetClusterSettings"
+	MasterService_ExplainAllocation_FullMethodName       = "/quidditch.master.MasterService/ExplainAllocation"
+	MasterService_PutPipeline_FullMethodName             = "/quidditch.master.MasterService/PutPipeline"
+	MasterService_DeletePipeline_FullMethodName          = "/quidditch.master.MasterService/DeletePipeline"
+	MasterService_GetPipelines_FullMethodName            = "/quidditch.master.MasterService/GetPipelines"
+	MasterService_SetIndexPipeline_FullMethodName        = "/quidditch.master.MasterService/SetIndexPipeline"
 )
 
 // MasterServiceClient is the client API for MasterService service.
@@ -46,6 +61,7 @@ type MasterServiceClient interface {
 	DeleteIndex(ctx context.Context, in *DeleteIndexRequest, opts ...grpc.CallOption) (*DeleteIndexResponse, error)
 	UpdateIndexSettings(ctx context.Context, in *UpdateIndexSettingsRequest, opts ...grpc.CallOption) (*UpdateIndexSettingsResponse, error)
 	GetIndexMetadata(ctx context.Context, in *GetIndexMetadataRequest, opts ...grpc.CallOption) (*IndexMetadataResponse, error)
+	PutMapping(ctx context.Context, in *PutMappingRequest, opts ...grpc.CallOption) (*PutMappingResponse, error)
 	// Shard allocation
 	AllocateShard(ctx context.Context, in *AllocateShardRequest, opts ...grpc.CallOption) (*AllocateShardResponse, error)
 	RebalanceShards(ctx context.Context, in *RebalanceShardsRequest, opts ...grpc.CallOption) (*RebalanceShardsResponse, error)
@@ -53,6 +69,21 @@ type MasterServiceClient interface {
 	RegisterNode(ctx context.Context, in *RegisterNodeRequest, opts ...grpc.CallOption) (*RegisterNodeResponse, error)
 	UnregisterNode(ctx context.Context, in *UnregisterNodeRequest, opts ...grpc.CallOption) (*UnregisterNodeResponse, error)
 	NodeHeartbeat(ctx context.Context, in *NodeHeartbeatRequest, opts ...grpc.CallOption) (*NodeHeartbeatResponse, error)
+	// Stored scripts (search templates)
+	PutStoredScript(ctx context.Context, in *PutStoredScriptRequest, opts ...grpc.CallOption) (*PutStoredScriptResponse, error)
+	GetStoredScript(ctx context.Context, in *GetStoredScriptRequest, opts ...grpc.CallOption) (*GetStoredScriptResponse, error)
+	PutIndexTemplate(ctx context.Context, in *PutIndexTemplateRequest, opts ...grpc.CallOption) (*PutIndexTemplateResponse, error)
+	GetIndexTemplates(ctx context.Context, in *GetIndexTemplatesRequest, opts ...grpc.CallOption) (*GetIndexTemplatesResponse, error)
+	DeleteIndexTemplate(ctx context.Context, in *DeleteIndexTemplateRequest, opts ...grpc.CallOption) (*DeleteIndexTemplateResponse, error)
+	PutSnapshotRepository(ctx context.Context, in *PutSnapshotRepositoryRequest, opts ...grpc.CallOption) (*PutSnapshotRepositoryResponse, error)
+	GetSnapshotRepositories(ctx context.Context, in *GetSnapshotRepositoriesRequest, opts ...grpc.CallOption) (*GetSnapshotRepositoriesResponse, error)
+	UpdateClusterSettings(ctx context.Context, in *UpdateClusterSettingsRequest, opts ...grpc.CallOption) (*UpdateClusterSettingsResponse, error)
+	GetClusterSettings(ctx context.Context, in *GetClusterSettingsRequest, opts ...grpc.CallOption) (*GetClusterSettingsResponse, error)
+	ExplainAllocation(ctx context.Context, in *ExplainAllocationRequest, opts ...grpc.CallOption) (*ExplainAllocationResponse, error)
+	PutPipeline(ctx context.Context, in *PutPipelineRequest, opts ...grpc.CallOption) (*PutPipelineResponse, error)
+	DeletePipeline(ctx context.Context, in *DeletePipelineRequest, opts ...grpc.CallOption) (*DeletePipelineResponse, error)
+	GetPipelines(ctx context.Context, in *GetPipelinesRequest, opts ...grpc.CallOption) (*GetPipelinesResponse, error)
+	SetIndexPipeline(ctx context.Context, in *SetIndexPipelineRequest, opts ...grpc.CallOption) (*SetIndexPipelineResponse, error)
 }
 
 type masterServiceClient struct {
@@ -132,6 +163,16 @@ func (c *masterServiceClient) GetIndexMetadata(ctx context.Context, in *GetIndex
 	return out, nil
 }
 
+func (c *masterServiceClient) PutMapping(ctx context.Context, in *PutMappingRequest, opts ...grpc.CallOption) (*PutMappingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PutMappingResponse)
+	err := c.cc.Invoke(ctx, MasterService_PutMapping_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *masterServiceClient) AllocateShard(ctx context.Context, in *AllocateShardRequest, opts ...grpc.CallOption) (*AllocateShardResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(AllocateShardResponse)
@@ -182,6 +223,146 @@ func (c *masterServiceClient) NodeHeartbeat(ctx context.Context, in *NodeHeartbe
 	return out, nil
 }
 
+func (c *masterServiceClient) PutStoredScript(ctx context.Context, in *PutStoredScriptRequest, opts ...grpc.CallOption) (*PutStoredScriptResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PutStoredScriptResponse)
+	err := c.cc.Invoke(ctx, MasterService_PutStoredScript_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterServiceClient) GetStoredScript(ctx context.Context, in *GetStoredScriptRequest, opts ...grpc.CallOption) (*GetStoredScriptResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStoredScriptResponse)
+	err := c.cc.Invoke(ctx, MasterService_GetStoredScript_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterServiceClient) PutIndexTemplate(ctx context.Context, in *PutIndexTemplateRequest, opts ...grpc.CallOption) (*PutIndexTemplateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PutIndexTemplateResponse)
+	err := c.cc.Invoke(ctx, MasterService_PutIndexTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterServiceClient) GetIndexTemplates(ctx context.Context, in *GetIndexTemplatesRequest, opts ...grpc.CallOption) (*GetIndexTemplatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetIndexTemplatesResponse)
+	err := c.cc.Invoke(ctx, MasterService_GetIndexTemplates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterServiceClient) DeleteIndexTemplate(ctx context.Context, in *DeleteIndexTemplateRequest, opts ...grpc.CallOption) (*DeleteIndexTemplateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteIndexTemplateResponse)
+	err := c.cc.Invoke(ctx, MasterService_DeleteIndexTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterServiceClient) PutSnapshotRepository(ctx context.Context, in *PutSnapshotRepositoryRequest, opts ...grpc.CallOption) (*PutSnapshotRepositoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PutSnapshotRepositoryResponse)
+	err := c.cc.Invoke(ctx, MasterService_PutSnapshotRepository_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterServiceClient) GetSnapshotRepositories(ctx context.Context, in *GetSnapshotRepositoriesRequest, opts ...grpc.CallOption) (*GetSnapshotRepositoriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSnapshotRepositoriesResponse)
+	err := c.cc.Invoke(ctx, MasterService_GetSnapshotRepositories_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterServiceClient) UpdateClusterSettings(ctx context.Context, in *UpdateClusterSettingsRequest, opts ...grpc.CallOption) (*UpdateClusterSettingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateClusterSettingsResponse)
+	err := c.cc.Invoke(ctx, MasterService_UpdateClusterSettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterServiceClient) GetClusterSettings(ctx context.Context, in *GetClusterSettingsRequest, opts ...grpc.CallOption) (*GetClusterSettingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetClusterSettingsResponse)
+	err := c.cc.Invoke(ctx, MasterService_GetClusterSettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterServiceClient) ExplainAllocation(ctx context.Context, in *ExplainAllocationRequest, opts ...grpc.CallOption) (*ExplainAllocationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExplainAllocationResponse)
+	err := c.cc.Invoke(ctx, MasterService_ExplainAllocation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterServiceClient) PutPipeline(ctx context.Context, in *PutPipelineRequest, opts ...grpc.CallOption) (*PutPipelineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PutPipelineResponse)
+	err := c.cc.Invoke(ctx, MasterService_PutPipeline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterServiceClient) DeletePipeline(ctx context.Context, in *DeletePipelineRequest, opts ...grpc.CallOption) (*DeletePipelineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeletePipelineResponse)
+	err := c.cc.Invoke(ctx, MasterService_DeletePipeline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterServiceClient) GetPipelines(ctx context.Context, in *GetPipelinesRequest, opts ...grpc.CallOption) (*GetPipelinesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPipelinesResponse)
+	err := c.cc.Invoke(ctx, MasterService_GetPipelines_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterServiceClient) SetIndexPipeline(ctx context.Context, in *SetIndexPipelineRequest, opts ...grpc.CallOption) (*SetIndexPipelineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetIndexPipelineResponse)
+	err := c.cc.Invoke(ctx, MasterService_SetIndexPipeline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MasterServiceServer is the server API for MasterService service.
 // All implementations must embed UnimplementedMasterServiceServer
 // for forward compatibility.
@@ -196,6 +377,7 @@ type MasterServiceServer interface {
 	DeleteIndex(context.Context, *DeleteIndexRequest) (*DeleteIndexResponse, error)
 	UpdateIndexSettings(context.Context, *UpdateIndexSettingsRequest) (*UpdateIndexSettingsResponse, error)
 	GetIndexMetadata(context.Context, *GetIndexMetadataRequest) (*IndexMetadataResponse, error)
+	PutMapping(context.Context, *PutMappingRequest) (*PutMappingResponse, error)
 	// Shard allocation
 	AllocateShard(context.Context, *AllocateShardRequest) (*AllocateShardResponse, error)
 	RebalanceShards(context.Context, *RebalanceShardsRequest) (*RebalanceShardsResponse, error)
@@ -203,6 +385,21 @@ type MasterServiceServer interface {
 	RegisterNode(context.Context, *RegisterNodeRequest) (*RegisterNodeResponse, error)
 	UnregisterNode(context.Context, *UnregisterNodeRequest) (*UnregisterNodeResponse, error)
 	NodeHeartbeat(context.Context, *NodeHeartbeatRequest) (*NodeHeartbeatResponse, error)
+	// Stored scripts (search templates)
+	PutStoredScript(context.Context, *PutStoredScriptRequest) (*PutStoredScriptResponse, error)
+	GetStoredScript(context.Context, *GetStoredScriptRequest) (*GetStoredScriptResponse, error)
+	PutIndexTemplate(context.Context, *PutIndexTemplateRequest) (*PutIndexTemplateResponse, error)
+	GetIndexTemplates(context.Context, *GetIndexTemplatesRequest) (*GetIndexTemplatesResponse, error)
+	DeleteIndexTemplate(context.Context, *DeleteIndexTemplateRequest) (*DeleteIndexTemplateResponse, error)
+	PutSnapshotRepository(context.Context, *PutSnapshotRepositoryRequest) (*PutSnapshotRepositoryResponse, error)
+	GetSnapshotRepositories(context.Context, *GetSnapshotRepositoriesRequest) (*GetSnapshotRepositoriesResponse, error)
+	UpdateClusterSettings(context.Context, *UpdateClusterSettingsRequest) (*UpdateClusterSettingsResponse, error)
+	GetClusterSettings(context.Context, *GetClusterSettingsRequest) (*GetClusterSettingsResponse, error)
+	ExplainAllocation(context.Context, *ExplainAllocationRequest) (*ExplainAllocationResponse, error)
+	PutPipeline(context.Context, *PutPipelineRequest) (*PutPipelineResponse, error)
+	DeletePipeline(context.Context, *DeletePipelineRequest) (*DeletePipelineResponse, error)
+	GetPipelines(context.Context, *GetPipelinesRequest) (*GetPipelinesResponse, error)
+	SetIndexPipeline(context.Context, *SetIndexPipelineRequest) (*SetIndexPipelineResponse, error)
 	mustEmbedUnimplementedMasterServiceServer()
 }
 
@@ -231,6 +428,9 @@ func (UnimplementedMasterServiceServer) UpdateIndexSettings(context.Context, *Up
 func (UnimplementedMasterServiceServer) GetIndexMetadata(context.Context, *GetIndexMetadataRequest) (*IndexMetadataResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetIndexMetadata not implemented")
 }
+func (UnimplementedMasterServiceServer) PutMapping(context.Context, *PutMappingRequest) (*PutMappingResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PutMapping not implemented")
+}
 func (UnimplementedMasterServiceServer) AllocateShard(context.Context, *AllocateShardRequest) (*AllocateShardResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method AllocateShard not implemented")
 }
@@ -246,6 +446,48 @@ func (UnimplementedMasterServiceServer) UnregisterNode(context.Context, *Unregis
 func (UnimplementedMasterServiceServer) NodeHeartbeat(context.Context, *NodeHeartbeatRequest) (*NodeHeartbeatResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method NodeHeartbeat not implemented")
 }
+func (UnimplementedMasterServiceServer) PutStoredScript(context.Context, *PutStoredScriptRequest) (*PutStoredScriptResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PutStoredScript not implemented")
+}
+func (UnimplementedMasterServiceServer) GetStoredScript(context.Context, *GetStoredScriptRequest) (*GetStoredScriptResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetStoredScript not implemented")
+}
+func (UnimplementedMasterServiceServer) PutIndexTemplate(context.Context, *PutIndexTemplateRequest) (*PutIndexTemplateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PutIndexTemplate not implemented")
+}
+func (UnimplementedMasterServiceServer) GetIndexTemplates(context.Context, *GetIndexTemplatesRequest) (*GetIndexTemplatesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetIndexTemplates not implemented")
+}
+func (UnimplementedMasterServiceServer) DeleteIndexTemplate(context.Context, *DeleteIndexTemplateRequest) (*DeleteIndexTemplateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteIndexTemplate not implemented")
+}
+func (UnimplementedMasterServiceServer) PutSnapshotRepository(context.Context, *PutSnapshotRepositoryRequest) (*PutSnapshotRepositoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PutSnapshotRepository not implemented")
+}
+func (UnimplementedMasterServiceServer) GetSnapshotRepositories(context.Context, *GetSnapshotRepositoriesRequest) (*GetSnapshotRepositoriesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSnapshotRepositories not implemented")
+}
+func (UnimplementedMasterServiceServer) UpdateClusterSettings(context.Context, *UpdateClusterSettingsRequest) (*UpdateClusterSettingsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateClusterSettings not implemented")
+}
+func (UnimplementedMasterServiceServer) GetClusterSettings(context.Context, *GetClusterSettingsRequest) (*GetClusterSettingsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetClusterSettings not implemented")
+}
+func (UnimplementedMasterServiceServer) ExplainAllocation(context.Context, *ExplainAllocationRequest) (*ExplainAllocationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ExplainAllocation not implemented")
+}
+func (UnimplementedMasterServiceServer) PutPipeline(context.Context, *PutPipelineRequest) (*PutPipelineResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PutPipeline not implemented")
+}
+func (UnimplementedMasterServiceServer) DeletePipeline(context.Context, *DeletePipelineRequest) (*DeletePipelineResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeletePipeline not implemented")
+}
+func (UnimplementedMasterServiceServer) GetPipelines(context.Context, *GetPipelinesRequest) (*GetPipelinesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetPipelines not implemented")
+}
+func (UnimplementedMasterServiceServer) SetIndexPipeline(context.Context, *SetIndexPipelineRequest) (*SetIndexPipelineResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetIndexPipeline not implemented")
+}
 func (UnimplementedMasterServiceServer) mustEmbedUnimplementedMasterServiceServer() {}
 func (UnimplementedMasterServiceServer) testEmbeddedByValue()                       {}
 
@@ -368,6 +610,24 @@ func _MasterService_GetIndexMetadata_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MasterService_PutMapping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutMappingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServiceServer).PutMapping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MasterService_PutMapping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServiceServer).PutMapping(ctx, req.(*PutMappingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MasterService_AllocateShard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AllocateShardRequest)
 	if err := dec(in); err != nil {
@@ -458,6 +718,258 @@ func _MasterService_NodeHeartbeat_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MasterService_PutStoredScript_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutStoredScriptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServiceServer).PutStoredScript(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MasterService_PutStoredScript_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServiceServer).PutStoredScript(ctx, req.(*PutStoredScriptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterService_GetStoredScript_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStoredScriptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServiceServer).GetStoredScript(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MasterService_GetStoredScript_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServiceServer).GetStoredScript(ctx, req.(*GetStoredScriptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterService_PutIndexTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutIndexTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServiceServer).PutIndexTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MasterService_PutIndexTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServiceServer).PutIndexTemplate(ctx, req.(*PutIndexTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterService_GetIndexTemplates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetIndexTemplatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServiceServer).GetIndexTemplates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MasterService_GetIndexTemplates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServiceServer).GetIndexTemplates(ctx, req.(*GetIndexTemplatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterService_DeleteIndexTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteIndexTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServiceServer).DeleteIndexTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MasterService_DeleteIndexTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServiceServer).DeleteIndexTemplate(ctx, req.(*DeleteIndexTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterService_PutSnapshotRepository_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutSnapshotRepositoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServiceServer).PutSnapshotRepository(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MasterService_PutSnapshotRepository_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServiceServer).PutSnapshotRepository(ctx, req.(*PutSnapshotRepositoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterService_GetSnapshotRepositories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSnapshotRepositoriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServiceServer).GetSnapshotRepositories(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MasterService_GetSnapshotRepositories_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServiceServer).GetSnapshotRepositories(ctx, req.(*GetSnapshotRepositoriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterService_UpdateClusterSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateClusterSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServiceServer).UpdateClusterSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MasterService_UpdateClusterSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServiceServer).UpdateClusterSettings(ctx, req.(*UpdateClusterSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterService_GetClusterSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClusterSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServiceServer).GetClusterSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MasterService_GetClusterSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServiceServer).GetClusterSettings(ctx, req.(*GetClusterSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterService_ExplainAllocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExplainAllocationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServiceServer).ExplainAllocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MasterService_ExplainAllocation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServiceServer).ExplainAllocation(ctx, req.(*ExplainAllocationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterService_PutPipeline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutPipelineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServiceServer).PutPipeline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MasterService_PutPipeline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServiceServer).PutPipeline(ctx, req.(*PutPipelineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterService_DeletePipeline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePipelineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServiceServer).DeletePipeline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MasterService_DeletePipeline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServiceServer).DeletePipeline(ctx, req.(*DeletePipelineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterService_GetPipelines_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPipelinesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServiceServer).GetPipelines(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MasterService_GetPipelines_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServiceServer).GetPipelines(ctx, req.(*GetPipelinesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterService_SetIndexPipeline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetIndexPipelineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServiceServer).SetIndexPipeline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MasterService_SetIndexPipeline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServiceServer).SetIndexPipeline(ctx, req.(*SetIndexPipelineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MasterService_ServiceDesc is the grpc.ServiceDesc for MasterService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -485,6 +997,10 @@ var MasterService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetIndexMetadata",
 			Handler:    _MasterService_GetIndexMetadata_Handler,
 		},
+		{
+			MethodName: "PutMapping",
+			Handler:    _MasterService_PutMapping_Handler,
+		},
 		{
 			MethodName: "AllocateShard",
 			Handler:    _MasterService_AllocateShard_Handler,
@@ -505,6 +1021,62 @@ var MasterService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "NodeHeartbeat",
 			Handler:    _MasterService_NodeHeartbeat_Handler,
 		},
+		{
+			MethodName: "PutStoredScript",
+			Handler:    _MasterService_PutStoredScript_Handler,
+		},
+		{
+			MethodName: "GetStoredScript",
+			Handler:    _MasterService_GetStoredScript_Handler,
+		},
+		{
+			MethodName: "PutIndexTemplate",
+			Handler:    _MasterService_PutIndexTemplate_Handler,
+		},
+		{
+			MethodName: "GetIndexTemplates",
+			Handler:    _MasterService_GetIndexTemplates_Handler,
+		},
+		{
+			MethodName: "DeleteIndexTemplate",
+			Handler:    _MasterService_DeleteIndexTemplate_Handler,
+		},
+		{
+			MethodName: "PutSnapshotRepository",
+			Handler:    _MasterService_PutSnapshotRepository_Handler,
+		},
+		{
+			MethodName: "GetSnapshotRepositories",
+			Handler:    _MasterService_GetSnapshotRepositories_Handler,
+		},
+		{
+			MethodName: "UpdateClusterSettings",
+			Handler:    _MasterService_UpdateClusterSettings_Handler,
+		},
+		{
+			MethodName: "GetClusterSettings",
+			Handler:    _MasterService_GetClusterSettings_Handler,
+		},
+		{
+			MethodName: "ExplainAllocation",
+			Handler:    _MasterService_ExplainAllocation_Handler,
+		},
+		{
+			MethodName: "PutPipeline",
+			Handler:    _MasterService_PutPipeline_Handler,
+		},
+		{
+			MethodName: "DeletePipeline",
+			Handler:    _MasterService_DeletePipeline_Handler,
+		},
+		{
+			MethodName: "GetPipelines",
+			Handler:    _MasterService_GetPipelines_Handler,
+		},
+		{
+			MethodName: "SetIndexPipeline",
+			Handler:    _MasterService_SetIndexPipeline_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -0,0 +1,100 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Header is the HTTP header carrying the request id
+const Header = "X-Request-ID"
+
+// metadataKey is the gRPC metadata key carrying the request id
+const metadataKey = "x-request-id"
+
+// maxLength is the longest request id accepted from a client
+const maxLength = 128
+
+// valid reports whether a request id sent by a client is safe to log and
+// echo back: non-empty, at most maxLength bytes, and made of letters,
+// digits and -_.:
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		switch c := id[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-' || c == '_' || c == '.' || c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the request id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request id in ctx, or "" if there is none
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Logger returns logger with the request id in ctx added as a field
+func Logger(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	if id := FromContext(ctx); id != "" {
+		return logger.With(zap.String("request_id", id))
+	}
+	return logger
+}
+
+// Middleware creates a Gin middleware that takes the request id from the
+// X-Request-ID header (or generates one when it is missing or invalid),
+// stores it in the request context and returns it in the response header
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(Header)
+		if !valid(id) {
+			id = uuid.New().String()
+		}
+
+		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), id))
+		c.Header(Header, id)
+
+		c.Next()
+	}
+}
+
+// ClientOption forwards the request id in the call context to the server as
+// gRPC metadata
+func ClientOption() grpc.DialOption {
+	return grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if id := FromContext(ctx); id != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, metadataKey, id)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	})
+}
+
+// ServerOption stores the request id sent by the client in the handler
+// context, dropping one that isn't valid
+func ServerOption() grpc.ServerOption {
+	return grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if ids := md.Get(metadataKey); len(ids) > 0 && valid(ids[0]) {
+				ctx = NewContext(ctx, ids[0])
+			}
+		}
+		return handler(ctx, req)
+	})
+}
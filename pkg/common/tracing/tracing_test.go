@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quidditch/quidditch/pkg/common/config"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestRecorder installs a tracer provider that records spans in memory
+func newTestRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	_, err := Init(context.Background(), config.TracingConfig{}, "test", "node-1")
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		provider.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+// statsService records the span context seen by the server
+type statsService struct {
+	pb.UnimplementedDataServiceServer
+	seen chan trace.SpanContext
+}
+
+func (s *statsService) GetNodeStats(ctx context.Context, req *pb.GetNodeStatsRequest) (*pb.DataNodeStats, error) {
+	s.seen <- trace.SpanContextFromContext(ctx)
+	return &pb.DataNodeStats{NodeId: "data-1"}, nil
+}
+
+func TestGRPCPropagation(t *testing.T) {
+	recorder := newTestRecorder(t)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(ServerOption())
+	service := &statsService{seen: make(chan trace.SpanContext, 1)}
+	pb.RegisterDataServiceServer(server, service)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		ClientOption(),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, parent := StartSpan(context.Background(), "search")
+	_, err = pb.NewDataServiceClient(conn).GetNodeStats(ctx, &pb.GetNodeStatsRequest{})
+	require.NoError(t, err)
+	parent.End()
+
+	// The server continues the caller's trace
+	serverCtx := <-service.seen
+	assert.Equal(t, parent.SpanContext().TraceID(), serverCtx.TraceID())
+
+	// search -> client span -> server span form one connected trace
+	spans := recorder.Ended()
+	byKind := make(map[trace.SpanKind]sdktrace.ReadOnlySpan)
+	for _, span := range spans {
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+		byKind[span.SpanKind()] = span
+	}
+	require.Contains(t, byKind, trace.SpanKindClient)
+	require.Contains(t, byKind, trace.SpanKindServer)
+	assert.Equal(t, parent.SpanContext().SpanID(), byKind[trace.SpanKindClient].Parent().SpanID())
+	assert.Equal(t, byKind[trace.SpanKindClient].SpanContext().SpanID(), byKind[trace.SpanKindServer].Parent().SpanID())
+}
+
+func TestMiddlewarePropagation(t *testing.T) {
+	recorder := newTestRecorder(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware())
+
+	var handlerSpan trace.SpanContext
+	router.GET("/:index/_search", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/products/_search", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, traceID, handlerSpan.TraceID().String())
+	assert.Contains(t, w.Header().Get("traceparent"), traceID)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /:index/_search", spans[0].Name())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+}
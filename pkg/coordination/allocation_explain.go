@@ -0,0 +1,142 @@
+package coordination
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// allocationExplainRequest is the body of an _cluster/allocation/explain
+// request. An empty body explains the first unassigned shard.
+type allocationExplainRequest struct {
+	Index   string `json:"index"`
+	Shard   *int32 `json:"shard"`
+	Primary bool   `json:"primary"`
+}
+
+// handleAllocationExplain explains why a shard copy is allocated where it is
+// or why it is unassigned, listing the deciders that rejected each node
+func (c *CoordinationNode) handleAllocationExplain(ctx *gin.Context) {
+	badRequest := func(reason string) {
+		respondError(ctx, http.StatusBadRequest, "illegal_argument_exception", reason)
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		badRequest(fmt.Sprintf("Failed to read request body: %v", err))
+		return
+	}
+
+	var req allocationExplainRequest
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			badRequest(fmt.Sprintf("Failed to parse allocation explain request: %v", err))
+			return
+		}
+	}
+	if (req.Index == "") != (req.Shard == nil) {
+		badRequest("the [index] and [shard] parameters must be given together, or neither to explain the first unassigned shard")
+		return
+	}
+	var shardID int32
+	if req.Shard != nil {
+		shardID = *req.Shard
+	}
+	includeYes := ctx.Query("include_yes_decisions") == "true"
+
+	explanation, err := c.masterClient.ExplainAllocation(ctx.Request.Context(), req.Index, shardID, req.Primary)
+	if err != nil {
+		var rejected interface{ GRPCStatus() *status.Status }
+		if errors.As(err, &rejected) && rejected.GRPCStatus().Code() == codes.NotFound {
+			notFound := indexNotFoundError(req.Index)
+			notFound.Reason = rejected.GRPCStatus().Message()
+			respondAPIError(ctx, notFound)
+			return
+		}
+		c.logger.Error("Failed to explain allocation", zap.Error(err))
+		respondErrorFrom(ctx, err, "allocation_explain_exception", "Failed to explain allocation")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, allocationExplanationResponse(explanation, includeYes))
+}
+
+// allocationExplanationResponse renders an allocation explanation. Deciders
+// that allowed a node are left out unless includeYes is set.
+func allocationExplanationResponse(explanation *pb.ExplainAllocationResponse, includeYes bool) gin.H {
+	resp := gin.H{
+		"index":         explanation.IndexName,
+		"shard":         explanation.ShardId,
+		"primary":       explanation.Primary,
+		"current_state": explanation.CurrentState,
+	}
+
+	if explanation.CurrentNode != "" {
+		resp["current_node"] = gin.H{
+			"id":   explanation.CurrentNode,
+			"name": explanation.CurrentNode,
+		}
+		resp["can_remain_on_current_node"] = explanation.CanRemain
+	} else {
+		unassignedInfo := gin.H{
+			"reason":                 explanation.UnassignedReason,
+			"last_allocation_status": explanation.CanAllocate,
+		}
+		if explanation.UnassignedAt != nil {
+			unassignedInfo["at"] = explanation.UnassignedAt.AsTime().UTC().Format(time.RFC3339)
+		}
+		resp["unassigned_info"] = unassignedInfo
+		resp["can_allocate"] = explanation.CanAllocate
+		resp["allocate_explanation"] = explanation.AllocateExplanation
+	}
+
+	nodeDecisions := make([]gin.H, 0, len(explanation.NodeAllocationDecisions))
+	for _, node := range explanation.NodeAllocationDecisions {
+		decision := "no"
+		if node.Allowed {
+			decision = "yes"
+		}
+
+		deciders := make([]gin.H, 0, len(node.Deciders))
+		for _, decider := range node.Deciders {
+			if decider.Allowed && !includeYes {
+				continue
+			}
+			deciderDecision := "NO"
+			if decider.Allowed {
+				deciderDecision = "YES"
+			}
+			deciders = append(deciders, gin.H{
+				"decider":     decider.Decider,
+				"decision":    deciderDecision,
+				"explanation": decider.Explanation,
+			})
+		}
+
+		nodeDecision := gin.H{
+			"node_id":       node.NodeId,
+			"node_name":     node.NodeId,
+			"node_decision": decision,
+		}
+		if node.Zone != "" {
+			nodeDecision["node_attributes"] = gin.H{"zone": node.Zone}
+		}
+		if len(deciders) > 0 {
+			nodeDecision["deciders"] = deciders
+		}
+		nodeDecisions = append(nodeDecisions, nodeDecision)
+	}
+	resp["node_allocation_decisions"] = nodeDecisions
+
+	return resp
+}
@@ -0,0 +1,109 @@
+package coordination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// explainMasterServer is a master whose only unassigned shard is a replica
+// blocked by the same_shard decider
+type explainMasterServer struct {
+	pb.UnimplementedMasterServiceServer
+
+	lastRequest *pb.ExplainAllocationRequest
+}
+
+func (m *explainMasterServer) ExplainAllocation(ctx context.Context, req *pb.ExplainAllocationRequest) (*pb.ExplainAllocationResponse, error) {
+	m.lastRequest = req
+	if req.IndexName != "" && req.IndexName != "products" {
+		return nil, status.Errorf(codes.NotFound, "no such index [%s]", req.IndexName)
+	}
+	return &pb.ExplainAllocationResponse{
+		IndexName:           "products",
+		ShardId:             0,
+		CurrentState:        "unassigned",
+		UnassignedReason:    "INDEX_CREATED",
+		CanAllocate:         "no",
+		AllocateExplanation: "cannot allocate because allocation is not permitted to any of the nodes",
+		NodeAllocationDecisions: []*pb.NodeAllocationDecision{
+			{
+				NodeId: "node-1",
+				Deciders: []*pb.AllocationDeciderResult{
+					{Decider: "enable", Allowed: true, Explanation: "allocation is allowed"},
+					{Decider: "same_shard", Explanation: "a copy of this shard is already allocated to this node"},
+				},
+			},
+		},
+	}, nil
+}
+
+func TestAllocationExplain(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &explainMasterServer{}
+	server := grpc.NewServer()
+	pb.RegisterMasterServiceServer(server, master)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	node := newTestCoordinationNode(t)
+	node.masterClient = NewMasterClient(lis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	defer node.masterClient.Disconnect()
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	// An empty request explains the first unassigned shard
+	w := serve(http.MethodGet, "/_cluster/allocation/explain", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "", master.lastRequest.IndexName)
+
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "unassigned", resp["current_state"])
+	assert.Equal(t, "no", resp["can_allocate"])
+	assert.Equal(t, "INDEX_CREATED", resp["unassigned_info"].(map[string]interface{})["reason"])
+
+	nodes := resp["node_allocation_decisions"].([]interface{})
+	require.Len(t, nodes, 1)
+	nodeDecision := nodes[0].(map[string]interface{})
+	assert.Equal(t, "no", nodeDecision["node_decision"])
+	deciders := nodeDecision["deciders"].([]interface{})
+	require.Len(t, deciders, 1, "only blocking deciders are listed by default")
+	assert.Equal(t, "same_shard", deciders[0].(map[string]interface{})["decider"])
+	assert.Equal(t, "NO", deciders[0].(map[string]interface{})["decision"])
+
+	// A specific shard, with every decider listed
+	w = serve(http.MethodPost, "/_cluster/allocation/explain?include_yes_decisions=true", `{"index":"products","shard":0,"primary":false}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "products", master.lastRequest.IndexName)
+	assert.False(t, master.lastRequest.Primary)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	nodeDecision = resp["node_allocation_decisions"].([]interface{})[0].(map[string]interface{})
+	assert.Len(t, nodeDecision["deciders"], 2)
+
+	w = serve(http.MethodPost, "/_cluster/allocation/explain", `{"index":"missing","shard":0}`)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+
+	w = serve(http.MethodPost, "/_cluster/allocation/explain", `{"index":"products"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
@@ -0,0 +1,304 @@
+package coordination
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/data/analysis"
+)
+
+// indexAnalysis is the value of the index.analysis setting data nodes build
+// each shard's analyzers from: the custom analyzers and token filters the
+// index settings define and the analyzers the mapping selects per field,
+// keyed by the field's dot path
+type indexAnalysis struct {
+	FieldAnalyzers  map[string]string                    `json:"field_analyzers,omitempty"`
+	CustomAnalyzers map[string]analysis.Definition       `json:"custom_analyzers,omitempty"`
+	CustomFilters   map[string]analysis.FilterDefinition `json:"custom_filters,omitempty"`
+}
+
+// parseCustomAnalyzers parses the custom analyzers of an analysis settings
+// object:
+//
+//	{"analyzer": {"folded": {"type": "custom", "tokenizer": "standard", "filter": ["lowercase", "asciifolding"]}}}
+func parseCustomAnalyzers(settings map[string]interface{}) (map[string]analysis.Definition, error) {
+	analyzers, ok := settings["analyzer"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	definitions := make(map[string]analysis.Definition, len(analyzers))
+	for name, value := range analyzers {
+		body, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("analyzer [%s] must be an object", name)
+		}
+		if analyzerType, ok := body["type"]; ok && analyzerType != "custom" {
+			return nil, fmt.Errorf("analyzer [%s] has unsupported type [%v], expected custom", name, analyzerType)
+		}
+
+		var definition analysis.Definition
+		definition.Tokenizer, _ = body["tokenizer"].(string)
+		switch filters := body["filter"].(type) {
+		case nil:
+		case string:
+			definition.Filters = []string{filters}
+		case []interface{}:
+			for _, filter := range filters {
+				filterName, ok := filter.(string)
+				if !ok {
+					return nil, fmt.Errorf("analyzer [%s] filters must be names", name)
+				}
+				definition.Filters = append(definition.Filters, filterName)
+			}
+		default:
+			return nil, fmt.Errorf("analyzer [%s] filter must be a name or a list of names", name)
+		}
+		definitions[name] = definition
+	}
+	return definitions, nil
+}
+
+// parseCustomFilters parses the custom token filters of an analysis
+// settings object:
+//
+//	{"filter": {"product_synonyms": {"type": "synonym", "synonyms": ["laptop, notebook"]}}}
+//	{"filter": {"light_stemmer": {"type": "stemmer", "language": "minimal_english"}}}
+func parseCustomFilters(settings map[string]interface{}) (map[string]analysis.FilterDefinition, error) {
+	filters, ok := settings["filter"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	definitions := make(map[string]analysis.FilterDefinition, len(filters))
+	for name, value := range filters {
+		body, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("filter [%s] must be an object", name)
+		}
+
+		var definition analysis.FilterDefinition
+		definition.Type, _ = body["type"].(string)
+		// Stemmers accept the language as name too
+		definition.Language, _ = body["language"].(string)
+		if name, ok := body["name"].(string); ok && definition.Language == "" {
+			definition.Language = name
+		}
+		switch synonyms := body["synonyms"].(type) {
+		case nil:
+		case []interface{}:
+			for _, synonym := range synonyms {
+				rule, ok := synonym.(string)
+				if !ok {
+					return nil, fmt.Errorf("filter [%s] synonyms must be strings", name)
+				}
+				definition.Synonyms = append(definition.Synonyms, rule)
+			}
+		default:
+			return nil, fmt.Errorf("filter [%s] synonyms must be a list of rules", name)
+		}
+		definitions[name] = definition
+	}
+	return definitions, nil
+}
+
+// parseMappings parses the properties of a mappings object into field
+// mappings. Object fields hold their own properties.
+func parseMappings(mappings map[string]interface{}) (map[string]*pb.FieldMapping, error) {
+	properties, ok := mappings["properties"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	fields := make(map[string]*pb.FieldMapping, len(properties))
+	for name, value := range properties {
+		body, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("mapping of field [%s] must be an object", name)
+		}
+
+		field := &pb.FieldMapping{Index: true}
+		field.Type, _ = body["type"].(string)
+		field.Analyzer, _ = body["analyzer"].(string)
+		if index, ok := body["index"].(bool); ok {
+			field.Index = index
+		}
+		field.Store, _ = body["store"].(bool)
+		field.IgnoreMalformed, _ = body["ignore_malformed"].(bool)
+		field.Format, _ = body["format"].(string)
+		if field.Analyzer != "" && field.Type != "text" && field.Type != "" {
+			return nil, fmt.Errorf("field [%s] of type [%s] does not support an analyzer", name, field.Type)
+		}
+		if field.IgnoreMalformed && !numericFieldTypes[field.Type] {
+			return nil, fmt.Errorf("field [%s] of type [%s] does not support [ignore_malformed]", name, field.Type)
+		}
+		if field.Format != "" && field.Type != "date" {
+			return nil, fmt.Errorf("field [%s] of type [%s] does not support [format]", name, field.Type)
+		}
+
+		subfields, err := parseMappings(body)
+		if err != nil {
+			return nil, err
+		}
+		field.Properties = subfields
+		fields[name] = field
+	}
+	return fields, nil
+}
+
+// collectFieldAnalyzers adds the analyzers field mappings select to
+// analyzers, keyed by dot path. Keyword fields are indexed whole.
+func collectFieldAnalyzers(fields map[string]*pb.FieldMapping, prefix string, analyzers map[string]string) {
+	for name, field := range fields {
+		path := prefix + name
+		switch {
+		case field.Analyzer != "":
+			analyzers[path] = field.Analyzer
+		case field.Type == "keyword":
+			analyzers[path] = "keyword"
+		}
+		collectFieldAnalyzers(field.Properties, path+".", analyzers)
+	}
+}
+
+// numericFieldTypes are the field types data nodes coerce values to
+var numericFieldTypes = map[string]bool{
+	"long": true, "integer": true, "short": true, "byte": true,
+	"double": true, "float": true, "half_float": true,
+}
+
+// numericField is a field's entry in the index.mapping.numeric_fields
+// setting
+type numericField struct {
+	Type            string `json:"type"`
+	IgnoreMalformed bool   `json:"ignore_malformed,omitempty"`
+}
+
+// collectNumericFields adds the fields mapped as numeric to fields, keyed
+// by dot path
+func collectNumericFields(mappings map[string]*pb.FieldMapping, prefix string, fields map[string]numericField) {
+	for name, field := range mappings {
+		path := prefix + name
+		if numericFieldTypes[field.Type] {
+			fields[path] = numericField{Type: field.Type, IgnoreMalformed: field.IgnoreMalformed}
+		}
+		collectNumericFields(field.Properties, path+".", fields)
+	}
+}
+
+// indexNumericFieldsSetting builds the index.mapping.numeric_fields setting
+// from an index's field mappings. It is empty when no field is numeric.
+func indexNumericFieldsSetting(mappings map[string]*pb.FieldMapping) (string, error) {
+	fields := make(map[string]numericField)
+	collectNumericFields(mappings, "", fields)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// dateField is a field's entry in the index.mapping.date_fields setting.
+// No formats selects the data node's defaults.
+type dateField struct {
+	Formats []string `json:"formats,omitempty"`
+}
+
+// collectDateFields adds the fields mapped as dates to fields, keyed by dot
+// path
+func collectDateFields(mappings map[string]*pb.FieldMapping, prefix string, fields map[string]dateField) {
+	for name, field := range mappings {
+		path := prefix + name
+		if field.Type == "date" {
+			var formats []string
+			if field.Format != "" {
+				formats = strings.Split(field.Format, "||")
+			}
+			fields[path] = dateField{Formats: formats}
+		}
+		collectDateFields(field.Properties, path+".", fields)
+	}
+}
+
+// indexDateFieldsSetting builds the index.mapping.date_fields setting from
+// an index's field mappings. It is empty when no field is a date.
+func indexDateFieldsSetting(mappings map[string]*pb.FieldMapping) (string, error) {
+	fields := make(map[string]dateField)
+	collectDateFields(mappings, "", fields)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// collectGeoPointFields adds the dot paths of the fields mapped as
+// geo_point to fields
+func collectGeoPointFields(mappings map[string]*pb.FieldMapping, prefix string, fields *[]string) {
+	for name, field := range mappings {
+		path := prefix + name
+		if field.Type == "geo_point" {
+			*fields = append(*fields, path)
+		}
+		collectGeoPointFields(field.Properties, path+".", fields)
+	}
+}
+
+// indexGeoPointFieldsSetting builds the index.mapping.geo_point_fields
+// setting from an index's field mappings. It is empty when no field is a
+// geo_point.
+func indexGeoPointFieldsSetting(mappings map[string]*pb.FieldMapping) (string, error) {
+	var fields []string
+	collectGeoPointFields(mappings, "", &fields)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	sort.Strings(fields)
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// indexAnalysisSetting builds the index.analysis setting from an index's
+// custom analyzers and token filters and its field mappings, checking that
+// every analyzer the mappings select exists. It is empty when none of them
+// configures analysis.
+func indexAnalysisSetting(custom map[string]analysis.Definition, filters map[string]analysis.FilterDefinition, mappings map[string]*pb.FieldMapping) (string, error) {
+	registry, err := analysis.NewRegistry(custom, filters)
+	if err != nil {
+		return "", err
+	}
+
+	fieldAnalyzers := make(map[string]string)
+	collectFieldAnalyzers(mappings, "", fieldAnalyzers)
+	fields := make([]string, 0, len(fieldAnalyzers))
+	for field := range fieldAnalyzers {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		if _, ok := registry.Get(fieldAnalyzers[field]); !ok {
+			return "", fmt.Errorf("analyzer [%s] of field [%s] not found", fieldAnalyzers[field], field)
+		}
+	}
+
+	if len(custom) == 0 && len(filters) == 0 && len(fieldAnalyzers) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(indexAnalysis{FieldAnalyzers: fieldAnalyzers, CustomAnalyzers: custom, CustomFilters: filters})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,248 @@
+package coordination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/data/analysis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+func TestIndexAnalysisSetting(t *testing.T) {
+	mappings, err := parseMappings(map[string]interface{}{
+		"properties": map[string]interface{}{
+			"title": map[string]interface{}{"type": "text", "analyzer": "folded"},
+			"body":  map[string]interface{}{"type": "text"},
+			"sku":   map[string]interface{}{"type": "keyword"},
+			"author": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"name": map[string]interface{}{"type": "text", "analyzer": "whitespace"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "text", mappings["title"].Type)
+	assert.True(t, mappings["title"].Index)
+	assert.Equal(t, "whitespace", mappings["author"].Properties["name"].Analyzer)
+
+	custom, err := parseCustomAnalyzers(map[string]interface{}{
+		"analyzer": map[string]interface{}{
+			"folded": map[string]interface{}{
+				"type":      "custom",
+				"tokenizer": "standard",
+				"filter":    []interface{}{"lowercase", "asciifolding"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	setting, err := indexAnalysisSetting(custom, nil, mappings)
+	require.NoError(t, err)
+	var parsed indexAnalysis
+	require.NoError(t, json.Unmarshal([]byte(setting), &parsed))
+	assert.Equal(t, map[string]string{
+		"title":       "folded",
+		"sku":         "keyword",
+		"author.name": "whitespace",
+	}, parsed.FieldAnalyzers)
+	assert.Equal(t, analysis.Definition{Tokenizer: "standard", Filters: []string{"lowercase", "asciifolding"}}, parsed.CustomAnalyzers["folded"])
+
+	// Nothing to configure leaves the setting unset
+	setting, err = indexAnalysisSetting(nil, nil, map[string]*pb.FieldMapping{"body": {Type: "text"}})
+	require.NoError(t, err)
+	assert.Empty(t, setting)
+}
+
+func TestIndexAnalysisSettingErrors(t *testing.T) {
+	_, err := parseMappings(map[string]interface{}{
+		"properties": map[string]interface{}{"count": map[string]interface{}{"type": "long", "analyzer": "standard"}},
+	})
+	assert.ErrorContains(t, err, "does not support an analyzer")
+
+	_, err = parseCustomAnalyzers(map[string]interface{}{
+		"analyzer": map[string]interface{}{"snowball": map[string]interface{}{"type": "snowball"}},
+	})
+	assert.ErrorContains(t, err, "unsupported type [snowball]")
+
+	_, err = indexAnalysisSetting(nil, nil, map[string]*pb.FieldMapping{"title": {Type: "text", Analyzer: "missing"}})
+	assert.ErrorContains(t, err, "analyzer [missing] of field [title] not found")
+
+	_, err = indexAnalysisSetting(map[string]analysis.Definition{"broken": {Tokenizer: "ngram"}}, nil, nil)
+	assert.ErrorContains(t, err, "unknown tokenizer [ngram]")
+
+	_, err = parseCustomFilters(map[string]interface{}{
+		"filter": map[string]interface{}{"synonyms": map[string]interface{}{"type": "synonym", "synonyms": "tv, telly"}},
+	})
+	assert.ErrorContains(t, err, "synonyms must be a list of rules")
+
+	_, err = indexAnalysisSetting(nil, map[string]analysis.FilterDefinition{"stems": {Type: "stemmer", Language: "klingon"}}, nil)
+	assert.ErrorContains(t, err, "unknown stemmer language [klingon]")
+
+	_, err = indexAnalysisSetting(nil, map[string]analysis.FilterDefinition{"synonyms": {Type: "synonym", Synonyms: []string{"a => b => c"}}}, nil)
+	assert.ErrorContains(t, err, "more than one =>")
+}
+
+func TestIndexNumericFieldsSetting(t *testing.T) {
+	mappings, err := parseMappings(map[string]interface{}{
+		"properties": map[string]interface{}{
+			"title": map[string]interface{}{"type": "text"},
+			"price": map[string]interface{}{"type": "double", "ignore_malformed": true},
+			"stats": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"views": map[string]interface{}{"type": "long"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, mappings["price"].IgnoreMalformed)
+
+	setting, err := indexNumericFieldsSetting(mappings)
+	require.NoError(t, err)
+	var parsed map[string]numericField
+	require.NoError(t, json.Unmarshal([]byte(setting), &parsed))
+	assert.Equal(t, map[string]numericField{
+		"price":       {Type: "double", IgnoreMalformed: true},
+		"stats.views": {Type: "long"},
+	}, parsed)
+
+	// No numeric field leaves the setting unset
+	setting, err = indexNumericFieldsSetting(map[string]*pb.FieldMapping{"title": {Type: "text"}})
+	require.NoError(t, err)
+	assert.Empty(t, setting)
+
+	_, err = parseMappings(map[string]interface{}{
+		"properties": map[string]interface{}{"title": map[string]interface{}{"type": "text", "ignore_malformed": true}},
+	})
+	assert.ErrorContains(t, err, "field [title] of type [text] does not support [ignore_malformed]")
+}
+
+func TestIndexDateFieldsSetting(t *testing.T) {
+	mappings, err := parseMappings(map[string]interface{}{
+		"properties": map[string]interface{}{
+			"title":      map[string]interface{}{"type": "text"},
+			"created_at": map[string]interface{}{"type": "date", "format": "yyyy-MM-dd HH:mm:ss||epoch_millis"},
+			"audit": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"updated_at": map[string]interface{}{"type": "date"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	setting, err := indexDateFieldsSetting(mappings)
+	require.NoError(t, err)
+	var parsed map[string]dateField
+	require.NoError(t, json.Unmarshal([]byte(setting), &parsed))
+	assert.Equal(t, map[string]dateField{
+		"created_at":       {Formats: []string{"yyyy-MM-dd HH:mm:ss", "epoch_millis"}},
+		"audit.updated_at": {},
+	}, parsed)
+
+	// No date field leaves the setting unset
+	setting, err = indexDateFieldsSetting(map[string]*pb.FieldMapping{"title": {Type: "text"}})
+	require.NoError(t, err)
+	assert.Empty(t, setting)
+
+	_, err = parseMappings(map[string]interface{}{
+		"properties": map[string]interface{}{"title": map[string]interface{}{"type": "text", "format": "yyyy"}},
+	})
+	assert.ErrorContains(t, err, "field [title] of type [text] does not support [format]")
+}
+
+func TestIndexGeoPointFieldsSetting(t *testing.T) {
+	mappings, err := parseMappings(map[string]interface{}{
+		"properties": map[string]interface{}{
+			"title":    map[string]interface{}{"type": "text"},
+			"location": map[string]interface{}{"type": "geo_point"},
+			"venue": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"entrance": map[string]interface{}{"type": "geo_point"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	setting, err := indexGeoPointFieldsSetting(mappings)
+	require.NoError(t, err)
+	assert.JSONEq(t, `["location", "venue.entrance"]`, setting)
+
+	// No geo_point field leaves the setting unset
+	setting, err = indexGeoPointFieldsSetting(map[string]*pb.FieldMapping{"title": {Type: "text"}})
+	require.NoError(t, err)
+	assert.Empty(t, setting)
+}
+
+func TestCreateIndexWithAnalysis(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &templateMasterServer{
+		resizeMasterServer: resizeMasterServer{indices: map[string]*pb.IndexMetadata{}},
+		templates:          map[string]*pb.IndexTemplate{},
+	}
+	server := grpc.NewServer()
+	pb.RegisterMasterServiceServer(server, master)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	node := newTestCoordinationNode(t)
+	node.masterClient = NewMasterClient(lis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	defer node.masterClient.Disconnect()
+
+	serve := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/products", `{
+		"settings": {"analysis": {"analyzer": {"folded": {"tokenizer": "standard", "filter": ["lowercase", "asciifolding"]}}}},
+		"mappings": {"properties": {"title": {"type": "text", "analyzer": "folded"}, "tags": {"type": "text", "analyzer": "lowercase"}}}
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	created := master.indices["products"]
+	assert.Equal(t, "folded", created.Mappings["title"].Analyzer)
+	var parsed indexAnalysis
+	require.NoError(t, json.Unmarshal([]byte(created.Settings.Analysis), &parsed))
+	assert.Equal(t, map[string]string{"title": "folded", "tags": "lowercase"}, parsed.FieldAnalyzers)
+	assert.Contains(t, parsed.CustomAnalyzers, "folded")
+
+	// Synonym filters travel with the custom analyzers chaining them
+	w = serve("/devices", `{
+		"settings": {"analysis": {
+			"filter": {
+				"device_synonyms": {"type": "synonym", "synonyms": ["laptop, notebook"]},
+				"light_stemmer": {"type": "stemmer", "name": "minimal_english"}
+			},
+			"analyzer": {"devices": {"tokenizer": "standard", "filter": ["lowercase", "device_synonyms", "light_stemmer"]}}
+		}},
+		"mappings": {"properties": {"title": {"type": "text", "analyzer": "devices"}}}
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	parsed = indexAnalysis{}
+	require.NoError(t, json.Unmarshal([]byte(master.indices["devices"].Settings.Analysis), &parsed))
+	assert.Equal(t, analysis.FilterDefinition{Type: "synonym", Synonyms: []string{"laptop, notebook"}}, parsed.CustomFilters["device_synonyms"])
+	assert.Equal(t, analysis.FilterDefinition{Type: "stemmer", Language: "minimal_english"}, parsed.CustomFilters["light_stemmer"])
+
+	// An analyzer that isn't defined is rejected before the index exists
+	w = serve("/broken", `{"mappings": {"properties": {"title": {"type": "text", "analyzer": "folded"}}}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "analyzer [folded] of field [title] not found")
+	assert.NotContains(t, master.indices, "broken")
+}
@@ -0,0 +1,152 @@
+package coordination
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quidditch/quidditch/pkg/data/analysis"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// analyzeRequest is the body of an _analyze request. The text is analyzed
+// by the named analyzer, by the analyzer a field of the index maps, or by
+// a tokenizer and filter chain given inline, in that order; the standard
+// analyzer is the default.
+type analyzeRequest struct {
+	Analyzer  string          `json:"analyzer"`
+	Field     string          `json:"field"`
+	Tokenizer string          `json:"tokenizer"`
+	Filter    []string        `json:"filter"`
+	Text      json.RawMessage `json:"text"`
+}
+
+// analyzeTexts returns the texts of an _analyze request, given as a string
+// or a list of strings
+func analyzeTexts(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("[text] is required")
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return []string{text}, nil
+	}
+	var texts []string
+	if err := json.Unmarshal(raw, &texts); err != nil {
+		return nil, fmt.Errorf("[text] must be a string or a list of strings")
+	}
+	return texts, nil
+}
+
+// analyzeTokens analyzes texts as the values of one field: positions and
+// offsets continue from one text to the next
+func analyzeTokens(analyzer *analysis.Analyzer, texts []string) []analysis.Token {
+	tokens := []analysis.Token{}
+	position, offset := 0, 0
+	for _, text := range texts {
+		last := -1
+		for _, token := range analyzer.Analyze(text) {
+			token.Position += position
+			token.StartOffset += offset
+			token.EndOffset += offset
+			last = token.Position
+			tokens = append(tokens, token)
+		}
+		if last >= 0 {
+			position = last + 1
+		}
+		offset += len([]rune(text)) + 1
+	}
+	return tokens
+}
+
+// handleAnalyze analyzes text and returns the tokens it produces with their
+// positions and offsets, to debug why a query does or doesn't match. With
+// an index in the path, the index's custom analyzers and field analyzers
+// are available too.
+func (c *CoordinationNode) handleAnalyze(ctx *gin.Context) {
+	indexName := ctx.Param("index")
+
+	badRequest := func(reason string) {
+		respondError(ctx, http.StatusBadRequest, "illegal_argument_exception", reason)
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		badRequest(fmt.Sprintf("Failed to read request body: %v", err))
+		return
+	}
+	var req analyzeRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		badRequest(fmt.Sprintf("Failed to parse analyze request: %v", err))
+		return
+	}
+	texts, err := analyzeTexts(req.Text)
+	if err != nil {
+		badRequest(err.Error())
+		return
+	}
+
+	// The index's analysis settings, if analyzing in an index
+	var settings indexAnalysis
+	if indexName != "" {
+		metadata, err := c.masterClient.GetIndexMetadata(ctx.Request.Context(), indexName)
+		if status.Code(err) == codes.NotFound {
+			respondAPIError(ctx, indexNotFoundError(indexName))
+			return
+		}
+		if err != nil {
+			c.logger.Error("Failed to get index metadata", zap.String("index", indexName), zap.Error(err))
+			respondErrorFrom(ctx, err, "analysis_exception", "Failed to get index metadata")
+			return
+		}
+		if value := metadata.GetMetadata().GetSettings().GetAnalysis(); value != "" {
+			if err := json.Unmarshal([]byte(value), &settings); err != nil {
+				respondErrorFrom(ctx, err, "analysis_exception", "Failed to read index analysis settings")
+				return
+			}
+		}
+	}
+	registry, err := analysis.NewRegistry(settings.CustomAnalyzers, settings.CustomFilters)
+	if err != nil {
+		respondErrorFrom(ctx, err, "analysis_exception", "Failed to build index analyzers")
+		return
+	}
+
+	var analyzer *analysis.Analyzer
+	switch {
+	case req.Analyzer != "":
+		var ok bool
+		if analyzer, ok = registry.Get(req.Analyzer); !ok {
+			badRequest(fmt.Sprintf("failed to find analyzer [%s]", req.Analyzer))
+			return
+		}
+	case req.Field != "":
+		if indexName == "" {
+			badRequest("analyzing a [field] requires an index")
+			return
+		}
+		name, ok := settings.FieldAnalyzers[req.Field]
+		if !ok {
+			name = analysis.DefaultAnalyzer
+		}
+		if analyzer, ok = registry.Get(name); !ok {
+			badRequest(fmt.Sprintf("analyzer [%s] of field [%s] is not available to _analyze", name, req.Field))
+			return
+		}
+	case req.Tokenizer != "":
+		analyzer, err = registry.Build("_analyze", analysis.Definition{Tokenizer: req.Tokenizer, Filters: req.Filter})
+		if err != nil {
+			badRequest(err.Error())
+			return
+		}
+	default:
+		analyzer, _ = registry.Get(analysis.DefaultAnalyzer)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"tokens": analyzeTokens(analyzer, texts)})
+}
@@ -0,0 +1,134 @@
+package coordination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/data/analysis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+func TestHandleAnalyze(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &resizeMasterServer{indices: map[string]*pb.IndexMetadata{
+		"products": {
+			IndexName: "products",
+			Settings: &pb.IndexSettings{
+				NumberOfShards: 1,
+				Analysis: `{
+					"field_analyzers": {"sku": "keyword", "title": "devices"},
+					"custom_analyzers": {"devices": {"tokenizer": "standard", "filters": ["lowercase", "device_synonyms"]}},
+					"custom_filters": {"device_synonyms": {"type": "synonym", "synonyms": ["laptop, notebook"]}}
+				}`,
+			},
+		},
+	}}
+	server := grpc.NewServer()
+	pb.RegisterMasterServiceServer(server, master)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	node := newTestCoordinationNode(t)
+	node.masterClient = NewMasterClient(lis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	defer node.masterClient.Disconnect()
+
+	analyze := func(path, body string) (*httptest.ResponseRecorder, []analysis.Token) {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+
+		var resp struct {
+			Tokens []analysis.Token `json:"tokens"`
+		}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w, resp.Tokens
+	}
+
+	t.Run("standard analyzer", func(t *testing.T) {
+		w, tokens := analyze("/_analyze", `{"analyzer": "standard", "text": "Quick, Brown fox"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, []analysis.Token{
+			{Text: "quick", Position: 0, StartOffset: 0, EndOffset: 5, Type: analysis.TokenTypeAlphanum},
+			{Text: "brown", Position: 1, StartOffset: 7, EndOffset: 12, Type: analysis.TokenTypeAlphanum},
+			{Text: "fox", Position: 2, StartOffset: 13, EndOffset: 16, Type: analysis.TokenTypeAlphanum},
+		}, tokens)
+	})
+
+	t.Run("keyword analyzer", func(t *testing.T) {
+		w, tokens := analyze("/_analyze", `{"analyzer": "keyword", "text": "Quick, Brown fox"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, []analysis.Token{
+			{Text: "Quick, Brown fox", Position: 0, StartOffset: 0, EndOffset: 16, Type: analysis.TokenTypeWord},
+		}, tokens)
+	})
+
+	t.Run("texts continue positions and offsets", func(t *testing.T) {
+		w, tokens := analyze("/_analyze", `{"text": ["one two", "three"]}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Len(t, tokens, 3)
+		assert.Equal(t, analysis.Token{Text: "three", Position: 2, StartOffset: 8, EndOffset: 13, Type: analysis.TokenTypeAlphanum}, tokens[2])
+	})
+
+	t.Run("inline chain", func(t *testing.T) {
+		w, tokens := analyze("/_analyze", `{"tokenizer": "whitespace", "filter": ["uppercase"], "text": "shout it"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Len(t, tokens, 2)
+		assert.Equal(t, "SHOUT", tokens[0].Text)
+	})
+
+	t.Run("field of an index", func(t *testing.T) {
+		w, tokens := analyze("/products/_analyze", `{"field": "title", "text": "Laptop bag"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var texts []string
+		for _, token := range tokens {
+			texts = append(texts, token.Text)
+		}
+		assert.Equal(t, []string{"laptop", "notebook", "bag"}, texts)
+		assert.Equal(t, 0, tokens[1].Position)
+
+		w, tokens = analyze("/products/_analyze", `{"field": "sku", "text": "AB-12 x"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Len(t, tokens, 1)
+		assert.Equal(t, "AB-12 x", tokens[0].Text)
+
+		// The index's custom analyzers can be named too
+		w, tokens = analyze("/products/_analyze", `{"analyzer": "devices", "text": "notebook"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Len(t, tokens, 2)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		w, _ := analyze("/_analyze", `{"analyzer": "devices", "text": "x"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "failed to find analyzer [devices]")
+
+		w, _ = analyze("/_analyze", `{"analyzer": "standard"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "[text] is required")
+
+		w, _ = analyze("/_analyze", `{"field": "title", "text": "x"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w, _ = analyze("/_analyze", `{"tokenizer": "ngram", "text": "x"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "unknown tokenizer [ngram]")
+
+		w, _ = analyze("/missing/_analyze", `{"text": "x"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "index_not_found_exception")
+	})
+}
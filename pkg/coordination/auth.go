@@ -0,0 +1,143 @@
+package coordination
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quidditch/quidditch/pkg/common/config"
+	"go.uber.org/zap"
+)
+
+// identityContextKey is the gin context key holding the authenticated Identity
+const identityContextKey = "quidditch.identity"
+
+// authAllowlist lists paths that never require credentials
+var authAllowlist = map[string]bool{
+	"/_health": true,
+	"/metrics": true,
+}
+
+// Identity is the authenticated caller of a request
+type Identity struct {
+	Name  string
+	Roles []string
+}
+
+// Authenticator validates request credentials
+type Authenticator interface {
+	// Authenticate returns the identity owning token, or false if the token
+	// isn't recognized
+	Authenticate(token string) (*Identity, bool)
+}
+
+// APIKeyStore authenticates static API keys. Keys are held as SHA-256
+// digests so lookups don't compare the secrets themselves.
+type APIKeyStore struct {
+	keys map[[sha256.Size]byte]*Identity
+}
+
+// NewAPIKeyStore creates a store from configured API keys
+func NewAPIKeyStore(apiKeys []config.APIKey) (*APIKeyStore, error) {
+	store := &APIKeyStore{
+		keys: make(map[[sha256.Size]byte]*Identity, len(apiKeys)),
+	}
+
+	for i, apiKey := range apiKeys {
+		if apiKey.Key == "" {
+			return nil, fmt.Errorf("api key %d has no key", i)
+		}
+		if apiKey.Name == "" {
+			return nil, fmt.Errorf("api key %d has no name", i)
+		}
+		digest := sha256.Sum256([]byte(apiKey.Key))
+		if _, exists := store.keys[digest]; exists {
+			return nil, fmt.Errorf("api key '%s' duplicates another key", apiKey.Name)
+		}
+		store.keys[digest] = &Identity{Name: apiKey.Name, Roles: apiKey.Roles}
+	}
+
+	return store, nil
+}
+
+// Authenticate implements Authenticator
+func (s *APIKeyStore) Authenticate(token string) (*Identity, bool) {
+	identity, ok := s.keys[sha256.Sum256([]byte(token))]
+	return identity, ok
+}
+
+// newAuthenticator builds the configured authenticator, or nil when
+// authentication is disabled
+func newAuthenticator(cfg config.AuthConfig) (Authenticator, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if len(cfg.APIKeys) == 0 {
+		return nil, fmt.Errorf("auth is enabled but no api keys are configured")
+	}
+	return NewAPIKeyStore(cfg.APIKeys)
+}
+
+// authMiddleware rejects requests without valid credentials. The token is
+// read from "Authorization: Bearer <token>", "Authorization: ApiKey <key>"
+// or the "X-API-Key" header.
+func authMiddleware(authenticator Authenticator, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authAllowlist[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		token := requestToken(c.Request)
+		if token == "" {
+			abortUnauthorized(c, "missing authentication credentials for REST request")
+			return
+		}
+
+		identity, ok := authenticator.Authenticate(token)
+		if !ok {
+			logger.Warn("Rejected request with invalid credentials",
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.String("client_ip", c.ClientIP()))
+			abortUnauthorized(c, "unable to authenticate with provided credentials")
+			return
+		}
+
+		c.Set(identityContextKey, identity)
+		c.Next()
+	}
+}
+
+// requestToken extracts the credentials sent with a request
+func requestToken(req *http.Request) string {
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		scheme, token, found := strings.Cut(auth, " ")
+		if !found {
+			return ""
+		}
+		if strings.EqualFold(scheme, "Bearer") || strings.EqualFold(scheme, "ApiKey") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	return strings.TrimSpace(req.Header.Get("X-API-Key"))
+}
+
+// abortUnauthorized responds with 401 and an OpenSearch-style security error
+func abortUnauthorized(c *gin.Context, reason string) {
+	c.Header("WWW-Authenticate", `Bearer realm="quidditch"`)
+	respondError(c, http.StatusUnauthorized, "security_exception", reason)
+}
+
+// identityFromContext returns the authenticated caller, if any
+func identityFromContext(c *gin.Context) (*Identity, bool) {
+	value, ok := c.Get(identityContextKey)
+	if !ok {
+		return nil, false
+	}
+	identity, ok := value.(*Identity)
+	return identity, ok
+}
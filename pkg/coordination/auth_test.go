@@ -0,0 +1,153 @@
+package coordination
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quidditch/quidditch/pkg/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newAuthTestRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	authenticator, err := newAuthenticator(config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKey{
+			{Name: "search-app", Key: "secret-token"},
+			{Name: "ingest-app", Key: "ingest-token"},
+		},
+	})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(authMiddleware(authenticator, zap.NewNop()))
+
+	ok := func(c *gin.Context) {
+		response := gin.H{"status": "ok"}
+		if value, exists := c.Get(identityContextKey); exists {
+			response["identity"] = value.(*Identity).Name
+		}
+		c.JSON(http.StatusOK, response)
+	}
+	router.GET("/_health", ok)
+	router.GET("/metrics", ok)
+	router.POST("/:index/_search", ok)
+
+	return router
+}
+
+func TestAuthMiddleware_Authorized(t *testing.T) {
+	router := newAuthTestRouter(t)
+
+	tests := []struct {
+		name     string
+		header   string
+		value    string
+		identity string
+	}{
+		{"BearerToken", "Authorization", "Bearer secret-token", "search-app"},
+		{"ApiKeyScheme", "Authorization", "ApiKey ingest-token", "ingest-app"},
+		{"LowercaseScheme", "Authorization", "bearer secret-token", "search-app"},
+		{"APIKeyHeader", "X-API-Key", "ingest-token", "ingest-app"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/products/_search", nil)
+			req.Header.Set(tt.header, tt.value)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.identity, response["identity"])
+		})
+	}
+}
+
+func TestAuthMiddleware_Unauthorized(t *testing.T) {
+	router := newAuthTestRouter(t)
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		reason string
+	}{
+		{"NoCredentials", "", "", "missing authentication credentials"},
+		{"WrongToken", "Authorization", "Bearer wrong-token", "unable to authenticate"},
+		{"UnsupportedScheme", "Authorization", "Basic c2VjcmV0LXRva2Vu", "missing authentication credentials"},
+		{"EmptyBearer", "Authorization", "Bearer ", "missing authentication credentials"},
+		{"WrongAPIKey", "X-API-Key", "nope", "unable to authenticate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/products/_search", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			errObj := response["error"].(map[string]interface{})
+			assert.Equal(t, "security_exception", errObj["type"])
+			assert.Contains(t, errObj["reason"], tt.reason)
+		})
+	}
+}
+
+func TestAuthMiddleware_Allowlist(t *testing.T) {
+	router := newAuthTestRouter(t)
+
+	for _, path := range []string{"/_health", "/metrics"} {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		authenticator, err := newAuthenticator(config.AuthConfig{
+			APIKeys: []config.APIKey{{Name: "app", Key: "token"}},
+		})
+		require.NoError(t, err)
+		assert.Nil(t, authenticator)
+	})
+
+	t.Run("EnabledWithoutKeys", func(t *testing.T) {
+		_, err := newAuthenticator(config.AuthConfig{Enabled: true})
+		assert.Error(t, err)
+	})
+
+	t.Run("InvalidKeys", func(t *testing.T) {
+		_, err := NewAPIKeyStore([]config.APIKey{{Name: "app"}})
+		assert.Error(t, err)
+
+		_, err = NewAPIKeyStore([]config.APIKey{{Key: "token"}})
+		assert.Error(t, err)
+
+		_, err = NewAPIKeyStore([]config.APIKey{
+			{Name: "a", Key: "token"},
+			{Name: "b", Key: "token"},
+		})
+		assert.Error(t, err)
+	})
+}
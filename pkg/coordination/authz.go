@@ -0,0 +1,227 @@
+package coordination
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quidditch/quidditch/pkg/common/config"
+	"go.uber.org/zap"
+)
+
+// Action is an operation class checked by the authorizer
+type Action string
+
+const (
+	ActionRead  Action = "read"  // Search, count and get documents or index metadata
+	ActionWrite Action = "write" // Index, update and delete documents
+	ActionAdmin Action = "admin" // Create, delete and configure indices; implies read and write
+)
+
+// indexGrant allows a set of actions on indices matching any pattern
+type indexGrant struct {
+	patterns []string
+	actions  map[Action]bool
+}
+
+// Authorizer checks whether an identity may perform an action on an index,
+// based on a static role map
+type Authorizer struct {
+	roles map[string][]indexGrant
+}
+
+// NewAuthorizer creates an authorizer from role definitions. Every role
+// granted to an API key must be defined.
+func NewAuthorizer(roles map[string]config.RoleConfig, apiKeys []config.APIKey) (*Authorizer, error) {
+	authorizer := &Authorizer{
+		roles: make(map[string][]indexGrant, len(roles)),
+	}
+
+	for roleName, role := range roles {
+		grants := make([]indexGrant, 0, len(role.Indices))
+		for i, perm := range role.Indices {
+			if len(perm.Names) == 0 {
+				return nil, fmt.Errorf("role '%s' index permission %d has no index names", roleName, i)
+			}
+			for _, pattern := range perm.Names {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return nil, fmt.Errorf("role '%s' has invalid index pattern '%s'", roleName, pattern)
+				}
+			}
+
+			grant := indexGrant{
+				patterns: perm.Names,
+				actions:  make(map[Action]bool, len(perm.Actions)),
+			}
+			for _, name := range perm.Actions {
+				action := Action(strings.ToLower(name))
+				switch action {
+				case ActionRead, ActionWrite:
+					grant.actions[action] = true
+				case ActionAdmin:
+					grant.actions[ActionRead] = true
+					grant.actions[ActionWrite] = true
+					grant.actions[ActionAdmin] = true
+				default:
+					return nil, fmt.Errorf("role '%s' has unknown action '%s' (expected read, write or admin)", roleName, name)
+				}
+			}
+			grants = append(grants, grant)
+		}
+		authorizer.roles[roleName] = grants
+	}
+
+	for _, apiKey := range apiKeys {
+		for _, roleName := range apiKey.Roles {
+			if _, ok := authorizer.roles[roleName]; !ok {
+				return nil, fmt.Errorf("api key '%s' references undefined role '%s'", apiKey.Name, roleName)
+			}
+		}
+	}
+
+	return authorizer, nil
+}
+
+// newAuthorizer builds the configured authorizer, or nil when no roles are
+// defined (every authenticated caller is then allowed everything)
+func newAuthorizer(cfg config.AuthConfig) (*Authorizer, error) {
+	if !cfg.Enabled || len(cfg.Roles) == 0 {
+		return nil, nil
+	}
+	return NewAuthorizer(cfg.Roles, cfg.APIKeys)
+}
+
+// Allowed returns true if identity may perform action on index. index may be
+// a comma-separated list; every entry must be allowed. An empty index, "_all"
+// or "*" addresses all indices and needs a grant on "*".
+func (a *Authorizer) Allowed(identity *Identity, action Action, index string) bool {
+	if identity == nil {
+		return false
+	}
+
+	for _, name := range splitIndexNames(index) {
+		if !a.allowedOne(identity, action, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowedOne checks a single index name or pattern
+func (a *Authorizer) allowedOne(identity *Identity, action Action, index string) bool {
+	for _, roleName := range identity.Roles {
+		for _, grant := range a.roles[roleName] {
+			if !grant.actions[action] {
+				continue
+			}
+			for _, pattern := range grant.patterns {
+				if patternCovers(pattern, index) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// splitIndexNames splits a comma-separated index expression, mapping the
+// all-indices forms to "*"
+func splitIndexNames(index string) []string {
+	if index == "" || index == "_all" {
+		return []string{"*"}
+	}
+
+	parts := strings.Split(index, ",")
+	names := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" || part == "_all" {
+			part = "*"
+		}
+		names = append(names, part)
+	}
+	return names
+}
+
+// patternCovers reports whether every index matched by index is also matched
+// by pattern. Concrete names are matched directly; a wildcard request is only
+// covered by an identical pattern or a broader "prefix*" pattern.
+func patternCovers(pattern, index string) bool {
+	if !strings.ContainsAny(index, "*?[") {
+		matched, _ := path.Match(pattern, index)
+		return matched
+	}
+
+	if pattern == index {
+		return true
+	}
+	prefix := strings.TrimSuffix(pattern, "*")
+	return len(prefix) == len(pattern)-1 &&
+		!strings.ContainsAny(prefix, "*?[") &&
+		strings.HasPrefix(index, prefix)
+}
+
+// authorize returns a route middleware that checks the caller may perform
+// action on the ":index" path parameter. It is a no-op when authorization
+// isn't configured.
+func (c *CoordinationNode) authorize(action Action) gin.HandlerFunc {
+	return c.authorizeParam(action, "index")
+}
+
+// authorizeParam is authorize for the index named by another path
+// parameter, such as the target index of a resize
+func (c *CoordinationNode) authorizeParam(action Action, param string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if c.authorizer == nil {
+			ctx.Next()
+			return
+		}
+
+		index := ctx.Param(param)
+		identity, _ := identityFromContext(ctx)
+		if !c.authorizer.Allowed(identity, action, index) {
+			c.logger.Warn("Denied unauthorized request",
+				zap.String("user", identityName(identity)),
+				zap.String("action", string(action)),
+				zap.String("index", index),
+				zap.String("path", ctx.Request.URL.Path))
+			abortForbidden(ctx, identity, action, index)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// abortForbidden responds with 403 and an OpenSearch-style security error
+func abortForbidden(ctx *gin.Context, identity *Identity, action Action, index string) {
+	respondAPIError(ctx, &APIError{
+		Status: http.StatusForbidden,
+		Type:   "security_exception",
+		Reason: forbiddenReason(identity, action, index),
+		Metadata: gin.H{
+			"user":   identityName(identity),
+			"action": string(action),
+			"index":  index,
+		},
+	})
+}
+
+// forbiddenReason describes a denied action
+func forbiddenReason(identity *Identity, action Action, index string) string {
+	if index == "" {
+		index = "_all"
+	}
+	return fmt.Sprintf("action [%s] is unauthorized for user [%s] on index [%s]",
+		action, identityName(identity), index)
+}
+
+// identityName returns the caller name for error messages
+func identityName(identity *Identity) string {
+	if identity == nil {
+		return "<anonymous>"
+	}
+	return identity.Name
+}
@@ -0,0 +1,279 @@
+package coordination
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/quidditch/quidditch/pkg/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func authzTestConfig() config.AuthConfig {
+	return config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKey{
+			{Name: "analyst", Key: "reader-token", Roles: []string{"reader"}},
+			{Name: "ingest", Key: "writer-token", Roles: []string{"writer"}},
+			{Name: "ops", Key: "admin-token", Roles: []string{"superuser"}},
+			{Name: "logs-ops", Key: "logs-admin-token", Roles: []string{"logs-admin"}},
+		},
+		Roles: map[string]config.RoleConfig{
+			"reader": {Indices: []config.IndexPermission{
+				{Names: []string{"products", "logs-*"}, Actions: []string{"read"}},
+			}},
+			"writer": {Indices: []config.IndexPermission{
+				{Names: []string{"logs-*"}, Actions: []string{"write"}},
+			}},
+			"superuser": {Indices: []config.IndexPermission{
+				{Names: []string{"*"}, Actions: []string{"admin"}},
+			}},
+			"logs-admin": {Indices: []config.IndexPermission{
+				{Names: []string{"logs-*"}, Actions: []string{"admin"}},
+			}},
+		},
+	}
+}
+
+// withTestAuth enables authentication and authorization with
+// authzTestConfig on a test node
+func withTestAuth(t *testing.T) func(*CoordinationNode) {
+	return func(node *CoordinationNode) {
+		authCfg := authzTestConfig()
+		authenticator, err := newAuthenticator(authCfg)
+		require.NoError(t, err)
+		authorizer, err := newAuthorizer(authCfg)
+		require.NoError(t, err)
+
+		node.cfg.Auth = authCfg
+		node.authenticator = authenticator
+		node.authorizer = authorizer
+	}
+}
+
+func TestAuthorizer_Allowed(t *testing.T) {
+	authCfg := authzTestConfig()
+	authorizer, err := NewAuthorizer(authCfg.Roles, authCfg.APIKeys)
+	require.NoError(t, err)
+
+	reader := &Identity{Name: "analyst", Roles: []string{"reader"}}
+	writer := &Identity{Name: "ingest", Roles: []string{"writer"}}
+	admin := &Identity{Name: "ops", Roles: []string{"superuser"}}
+
+	tests := []struct {
+		name     string
+		identity *Identity
+		action   Action
+		index    string
+		allowed  bool
+	}{
+		{"ReaderReadsExact", reader, ActionRead, "products", true},
+		{"ReaderReadsPattern", reader, ActionRead, "logs-2026", true},
+		{"ReaderCannotWrite", reader, ActionWrite, "products", false},
+		{"ReaderCannotAdmin", reader, ActionAdmin, "products", false},
+		{"ReaderOtherIndex", reader, ActionRead, "orders", false},
+		{"ReaderIndexList", reader, ActionRead, "products,logs-1", true},
+		{"ReaderPartialIndexList", reader, ActionRead, "products,orders", false},
+		{"ReaderNarrowerWildcard", reader, ActionRead, "logs-2026-*", true},
+		{"ReaderBroaderWildcard", reader, ActionRead, "log*", false},
+		{"ReaderAllIndices", reader, ActionRead, "", false},
+		{"WriterWrites", writer, ActionWrite, "logs-1", true},
+		{"WriterCannotRead", writer, ActionRead, "logs-1", false},
+		{"AdminImpliesRead", admin, ActionRead, "anything", true},
+		{"AdminImpliesWrite", admin, ActionWrite, "anything", true},
+		{"AdminAllIndices", admin, ActionAdmin, "_all", true},
+		{"Anonymous", nil, ActionRead, "products", false},
+		{"UnknownRole", &Identity{Name: "x", Roles: []string{"missing"}}, ActionRead, "products", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, authorizer.Allowed(tt.identity, tt.action, tt.index))
+		})
+	}
+}
+
+func TestNewAuthorizer_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		roles   map[string]config.RoleConfig
+		apiKeys []config.APIKey
+		errMsg  string
+	}{
+		{
+			name: "UnknownAction",
+			roles: map[string]config.RoleConfig{"r": {Indices: []config.IndexPermission{
+				{Names: []string{"*"}, Actions: []string{"delete"}},
+			}}},
+			errMsg: "unknown action",
+		},
+		{
+			name: "NoIndexNames",
+			roles: map[string]config.RoleConfig{"r": {Indices: []config.IndexPermission{
+				{Actions: []string{"read"}},
+			}}},
+			errMsg: "no index names",
+		},
+		{
+			name: "InvalidPattern",
+			roles: map[string]config.RoleConfig{"r": {Indices: []config.IndexPermission{
+				{Names: []string{"logs-["}, Actions: []string{"read"}},
+			}}},
+			errMsg: "invalid index pattern",
+		},
+		{
+			name:    "UndefinedRole",
+			roles:   map[string]config.RoleConfig{"r": {}},
+			apiKeys: []config.APIKey{{Name: "app", Key: "k", Roles: []string{"other"}}},
+			errMsg:  "undefined role",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAuthorizer(tt.roles, tt.apiKeys)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+
+	// Without roles, authorization is off
+	authorizer, err := newAuthorizer(config.AuthConfig{Enabled: true})
+	require.NoError(t, err)
+	assert.Nil(t, authorizer)
+}
+
+func TestAuthorization_ReadOnlyUserCannotIndex(t *testing.T) {
+	node := newTestCoordinationNode(t, withTestAuth(t))
+
+	serve := func(method, path, token string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("IndexDocumentDenied", func(t *testing.T) {
+		w := serve(http.MethodPut, "/products/_doc/1", "reader-token", []byte(`{"title":"laptop"}`))
+		require.Equal(t, http.StatusForbidden, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		errObj := response["error"].(map[string]interface{})
+		assert.Equal(t, "security_exception", errObj["type"])
+		assert.Equal(t, "analyst", errObj["user"])
+		assert.Equal(t, "write", errObj["action"])
+		assert.Equal(t, "products", errObj["index"])
+		assert.Contains(t, errObj["reason"], "action [write] is unauthorized for user [analyst] on index [products]")
+	})
+
+	t.Run("CreateIndexDenied", func(t *testing.T) {
+		w := serve(http.MethodPut, "/products", "reader-token", []byte(`{}`))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("ResizeTargetDenied", func(t *testing.T) {
+		// Admin on the source doesn't allow creating any target index
+		for _, path := range []string{"/logs-1/_split/products", "/logs-1/_shrink/products"} {
+			w := serve(http.MethodPost, path, "logs-admin-token", []byte(`{}`))
+			require.Equal(t, http.StatusForbidden, w.Code, path)
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			errObj := response["error"].(map[string]interface{})
+			assert.Equal(t, "admin", errObj["action"])
+			assert.Equal(t, "products", errObj["index"])
+		}
+
+		// A target the caller administers gets past authorization
+		w := serve(http.MethodPost, "/logs-1/_split/logs-2", "logs-admin-token", []byte(`{}`))
+		assert.NotEqual(t, http.StatusForbidden, w.Code, w.Body.String())
+	})
+
+	t.Run("SearchAllowed", func(t *testing.T) {
+		w := serve(http.MethodPost, "/products/_search", "reader-token", []byte(`{"query":{"match_all":{}}}`))
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("SearchOtherIndexDenied", func(t *testing.T) {
+		w := serve(http.MethodPost, "/orders/_search", "reader-token", []byte(`{"query":{"match_all":{}}}`))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("SearchAllIndicesDenied", func(t *testing.T) {
+		w := serve(http.MethodPost, "/_search", "reader-token", []byte(`{"query":{"match_all":{}}}`))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("BulkItemsDenied", func(t *testing.T) {
+		body := []byte(`{"index":{"_index":"products","_id":"1"}}
+{"title":"laptop"}
+{"delete":{"_index":"logs-1","_id":"2"}}
+`)
+		w := serve(http.MethodPost, "/_bulk", "reader-token", body)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Errors bool                                `json:"errors"`
+			Items  []map[string]map[string]interface{} `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Items, 2)
+		assert.True(t, response.Errors)
+		assert.EqualValues(t, http.StatusForbidden, response.Items[0]["index"]["status"])
+		assert.EqualValues(t, http.StatusForbidden, response.Items[1]["delete"]["status"])
+	})
+
+	t.Run("WriterBulkItemChecked", func(t *testing.T) {
+		body := []byte(`{"index":{"_index":"products","_id":"1"}}
+{"title":"laptop"}
+`)
+		w := serve(http.MethodPost, "/_bulk", "writer-token", body)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "action [write] is unauthorized for user [ingest] on index [products]")
+	})
+
+	t.Run("MultiGetItemsDenied", func(t *testing.T) {
+		body := []byte(`{"docs":[{"_index":"orders","_id":"1"},{"_index":"logs-*","_id":"2"}]}`)
+		w := serve(http.MethodPost, "/_mget", "writer-token", body)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response struct {
+			Docs []map[string]interface{} `json:"docs"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Docs, 2)
+		for _, doc := range response.Docs {
+			errObj := doc["error"].(map[string]interface{})
+			assert.Equal(t, "security_exception", errObj["type"])
+		}
+		assert.Equal(t, "orders", response.Docs[0]["_index"])
+		assert.Contains(t, response.Docs[1]["error"].(map[string]interface{})["reason"],
+			"action [read] is unauthorized for user [ingest] on index [logs-*]")
+	})
+
+	t.Run("PipelineManagementDenied", func(t *testing.T) {
+		w := serve(http.MethodGet, "/api/v1/pipelines", "reader-token", nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = serve(http.MethodDelete, "/api/v1/pipelines/enrich", "writer-token", nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = serve(http.MethodGet, "/api/v1/pipelines", "admin-token", nil)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("IndexHealthDenied", func(t *testing.T) {
+		w := serve(http.MethodGet, "/_cluster/health/orders", "reader-token", nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("AdminAllowed", func(t *testing.T) {
+		w := serve(http.MethodPost, "/_search", "admin-token", []byte(`{"query":{"match_all":{}}}`))
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+}
@@ -0,0 +1,133 @@
+package coordination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// settingAutoCreateIndex is the cluster setting controlling whether a write
+// to a missing index creates it. Unset, indices are created.
+const settingAutoCreateIndex = "action.auto_create_index"
+
+// autoCreateTimeout bounds how long a write waits for the primaries of an
+// index it created to start
+const autoCreateTimeout = 30 * time.Second
+
+// errAutoCreateDenied is returned for a write to a missing index that
+// action.auto_create_index does not allow to be created
+var errAutoCreateDenied = errors.New("index auto-creation denied")
+
+// autoCreateRule allows or denies the creation of indices matching pattern
+type autoCreateRule struct {
+	pattern string
+	allow   bool
+}
+
+// parseAutoCreateIndex parses an action.auto_create_index value: true,
+// false, or a comma-separated list of index patterns, each prefixed with +
+// to allow or - to deny. A pattern without a prefix allows.
+func parseAutoCreateIndex(value string) ([]autoCreateRule, error) {
+	switch value {
+	case "", "true":
+		return []autoCreateRule{{pattern: "*", allow: true}}, nil
+	case "false":
+		return nil, nil
+	}
+
+	var rules []autoCreateRule
+	for _, pattern := range strings.Split(value, ",") {
+		rule := autoCreateRule{pattern: strings.TrimSpace(pattern), allow: true}
+		if strings.HasPrefix(rule.pattern, "+") {
+			rule.pattern = rule.pattern[1:]
+		} else if strings.HasPrefix(rule.pattern, "-") {
+			rule.pattern = rule.pattern[1:]
+			rule.allow = false
+		}
+		if rule.pattern == "" {
+			return nil, fmt.Errorf("empty index pattern in [%s]", value)
+		}
+		if _, err := path.Match(rule.pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid index pattern [%s]", rule.pattern)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// autoCreateAllowed reports whether rules allow indexName to be created. The
+// first matching pattern decides, and an index matching none is denied.
+func autoCreateAllowed(rules []autoCreateRule, indexName string) bool {
+	for _, rule := range rules {
+		if matched, _ := path.Match(rule.pattern, indexName); matched {
+			return rule.allow
+		}
+	}
+	return false
+}
+
+// ensureIndexForWrite creates indexName, from its matching index template if
+// any, when it does not exist and action.auto_create_index allows it, and
+// waits for its primaries to start. It returns an error wrapping
+// errAutoCreateDenied when the index is missing and may not be created.
+func (c *CoordinationNode) ensureIndexForWrite(ctx context.Context, indexName string) error {
+	if c.masterClient == nil {
+		return nil
+	}
+
+	_, err := c.masterClient.GetIndexMetadata(ctx, indexName)
+	if status.Code(err) != codes.NotFound {
+		// Other failures surface when the write is routed
+		return nil
+	}
+
+	settings, err := c.masterClient.GetClusterSettings(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster settings: %w", err)
+	}
+	value := settings[settingAutoCreateIndex]
+	rules, err := parseAutoCreateIndex(value)
+	if err != nil {
+		return fmt.Errorf("invalid [%s] setting: %w", settingAutoCreateIndex, err)
+	}
+	if !autoCreateAllowed(rules, indexName) {
+		return fmt.Errorf("%w: no such index [%s] and [%s] ([%s]) doesn't allow creating it",
+			errAutoCreateDenied, indexName, settingAutoCreateIndex, value)
+	}
+
+	if _, err := c.createIndex(ctx, indexName, nil); err != nil {
+		// A concurrent write may have created the index first
+		if _, getErr := c.masterClient.GetIndexMetadata(ctx, indexName); getErr != nil {
+			return fmt.Errorf("failed to auto-create index [%s]: %w", indexName, err)
+		}
+	} else {
+		c.logger.Info("Auto-created index on first write", zap.String("index", indexName))
+	}
+
+	metadata, err := c.masterClient.GetIndexMetadata(ctx, indexName)
+	if err != nil {
+		return fmt.Errorf("failed to get index metadata: %w", err)
+	}
+	numShards := metadata.GetMetadata().GetSettings().GetNumberOfShards()
+	if err := c.waitForPrimaries(ctx, indexName, numShards, autoCreateTimeout); err != nil {
+		return fmt.Errorf("auto-created index [%s] did not start: %w", indexName, err)
+	}
+	return nil
+}
+
+// autoCreateErrorStatus returns the HTTP status and error type for a failed
+// ensureIndexForWrite
+func autoCreateErrorStatus(err error) (int, string) {
+	if errors.Is(err, errAutoCreateDenied) {
+		return http.StatusBadRequest, "index_not_found_exception"
+	}
+	return http.StatusInternalServerError, "index_failed_exception"
+}
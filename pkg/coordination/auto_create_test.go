@@ -0,0 +1,194 @@
+package coordination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/coordination/pipeline"
+	"github.com/quidditch/quidditch/pkg/coordination/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// autoCreateMasterServer is a master with index templates and cluster
+// settings
+type autoCreateMasterServer struct {
+	templateMasterServer
+
+	settingsMu sync.Mutex
+	settings   map[string]string
+}
+
+func (m *autoCreateMasterServer) GetClusterSettings(ctx context.Context, req *pb.GetClusterSettingsRequest) (*pb.GetClusterSettingsResponse, error) {
+	m.settingsMu.Lock()
+	defer m.settingsMu.Unlock()
+	settings := make(map[string]string, len(m.settings))
+	for k, v := range m.settings {
+		settings[k] = v
+	}
+	return &pb.GetClusterSettingsResponse{Settings: settings}, nil
+}
+
+func (m *autoCreateMasterServer) setAutoCreateIndex(value string) {
+	m.settingsMu.Lock()
+	defer m.settingsMu.Unlock()
+	m.settings[settingAutoCreateIndex] = value
+}
+
+func (m *autoCreateMasterServer) hasIndex(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, exists := m.indices[name]
+	return exists
+}
+
+func TestParseAutoCreateIndex(t *testing.T) {
+	for _, tc := range []struct {
+		value   string
+		index   string
+		allowed bool
+	}{
+		{"", "logs-1", true},
+		{"true", "anything", true},
+		{"false", "logs-1", false},
+		{"+logs-*,-secret-*", "logs-1", true},
+		{"+logs-*,-secret-*", "secret-1", false},
+		{"+logs-*,-secret-*", "other", false},
+		{"-logs-private*, logs-*", "logs-private-1", false},
+		{"-logs-private*, logs-*", "logs-public-1", true},
+		{"-secret-*,*", "other", true},
+	} {
+		rules, err := parseAutoCreateIndex(tc.value)
+		require.NoError(t, err, tc.value)
+		assert.Equal(t, tc.allowed, autoCreateAllowed(rules, tc.index), "%q allows %s", tc.value, tc.index)
+	}
+
+	for _, value := range []string{"logs-*,", "+", "logs-[", " - "} {
+		_, err := parseAutoCreateIndex(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestAutoCreateIndexOnWrite(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &autoCreateMasterServer{
+		templateMasterServer: templateMasterServer{
+			resizeMasterServer: resizeMasterServer{indices: map[string]*pb.IndexMetadata{}},
+			templates:          map[string]*pb.IndexTemplate{},
+		},
+		settings: map[string]string{},
+	}
+	server := grpc.NewServer()
+	pb.RegisterMasterServiceServer(server, master)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	store := &shardedStore{shards: make(map[string]map[int32]map[string]map[string]interface{})}
+	node := newTestCoordinationNode(t)
+	node.masterClient = NewMasterClient(lis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	defer node.masterClient.Disconnect()
+	node.docRouter = router.NewDocumentRouter(node.masterClient, map[string]router.DataNodeClient{"node1": store}, zap.NewNop())
+	node.pipelineRegistry = pipeline.NewRegistry(zap.NewNop())
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		return w
+	}
+	stored := func(index string) int {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		n := 0
+		for _, docs := range store.shards[index] {
+			n += len(docs)
+		}
+		return n
+	}
+
+	w := serve(http.MethodPut, "/_index_template/logs", `{
+		"index_patterns": ["logs-*"],
+		"template": {"settings": {"index": {"number_of_shards": 2}}}
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// Unset, the first write creates the index from its template
+	w = serve(http.MethodPut, "/logs-1/_doc/1", `{"message": "hello"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	require.True(t, master.hasIndex("logs-1"))
+	assert.Equal(t, int32(2), master.indices["logs-1"].Settings.NumberOfShards)
+	assert.Equal(t, 1, stored("logs-1"))
+
+	// Allowed and denied patterns
+	master.setAutoCreateIndex("+logs-*,-secret-*")
+
+	w = serve(http.MethodPut, "/logs-2/_doc/1", `{"message": "hello"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.True(t, master.hasIndex("logs-2"))
+
+	for _, index := range []string{"secret-1", "other"} {
+		w = serve(http.MethodPut, "/"+index+"/_doc/1", `{"message": "hello"}`)
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		var resp struct {
+			Error map[string]interface{} `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "index_not_found_exception", resp.Error["type"])
+		assert.Contains(t, resp.Error["reason"], settingAutoCreateIndex)
+		assert.False(t, master.hasIndex(index))
+	}
+
+	// Bulk writes create allowed indices and fail items for denied ones
+	bulkBody := `{"index": {"_index": "logs-3", "_id": "1"}}
+{"message": "a"}
+{"index": {"_index": "secret-2", "_id": "1"}}
+{"message": "b"}
+{"index": {"_index": "logs-3", "_id": "2"}}
+{"message": "c"}
+`
+	w = serve(http.MethodPost, "/_bulk", bulkBody)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var bulkResp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Index  string `json:"_index"`
+			Status int    `json:"status"`
+			Error  *struct {
+				Type string `json:"type"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bulkResp))
+	assert.True(t, bulkResp.Errors)
+	require.Len(t, bulkResp.Items, 3)
+	assert.Equal(t, http.StatusCreated, bulkResp.Items[0]["index"].Status)
+	assert.Equal(t, http.StatusBadRequest, bulkResp.Items[1]["index"].Status)
+	require.NotNil(t, bulkResp.Items[1]["index"].Error)
+	assert.Equal(t, "index_not_found_exception", bulkResp.Items[1]["index"].Error.Type)
+	assert.Equal(t, http.StatusCreated, bulkResp.Items[2]["index"].Status)
+	assert.Equal(t, 2, stored("logs-3"))
+	assert.False(t, master.hasIndex("secret-2"))
+
+	// Disabled, even matching indices are not created
+	master.setAutoCreateIndex("false")
+	w = serve(http.MethodPut, "/logs-4/_doc/1", `{"message": "hello"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	assert.False(t, master.hasIndex("logs-4"))
+
+	// Existing indices are written to regardless
+	w = serve(http.MethodPut, "/logs-1/_doc/2", `{"message": "again"}`)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, 2, stored("logs-1"))
+}
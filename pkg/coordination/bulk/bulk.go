@@ -4,10 +4,37 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 )
 
+// Default bulk request limits
+const (
+	DefaultMaxBodyBytes  = 100 << 20 // 100MB
+	DefaultMaxOperations = 10000
+
+	// DefaultConcurrency is the number of bulk operations executed in parallel
+	DefaultConcurrency = 10
+)
+
+var (
+	// ErrBodyTooLarge is returned for a bulk body over Limits.MaxBodyBytes
+	ErrBodyTooLarge = errors.New("bulk request body too large")
+
+	// ErrTooManyOperations is returned for a bulk request with more than
+	// Limits.MaxOperations operations
+	ErrTooManyOperations = errors.New("too many operations in bulk request")
+)
+
+// Limits bounds the size of a bulk request; zero fields are unlimited
+type Limits struct {
+	MaxBodyBytes  int64
+	MaxOperations int
+}
+
 // OperationType represents the type of bulk operation
 type OperationType string
 
@@ -25,6 +52,11 @@ type BulkOperation struct {
 	ID        string
 	Document  map[string]interface{} // For index, create, update
 	UpdateDoc map[string]interface{} // For update operations (the "doc" field)
+
+	// Upsert is indexed by an update when the document does not exist, and
+	// DocAsUpsert indexes UpdateDoc instead
+	Upsert      map[string]interface{}
+	DocAsUpsert bool
 }
 
 // BulkRequest represents a parsed bulk request
@@ -69,15 +101,28 @@ type BulkResponse struct {
 // { "field": "value" }
 // { "delete": { "_index": "test", "_id": "2" } }
 func ParseBulkRequest(body []byte) (*BulkRequest, error) {
+	return ParseBulkRequestWithLimits(body, Limits{})
+}
+
+// ParseBulkRequestWithLimits parses a bulk request, rejecting bodies and
+// operation counts over the limits with ErrBodyTooLarge and
+// ErrTooManyOperations
+func ParseBulkRequestWithLimits(body []byte, limits Limits) (*BulkRequest, error) {
 	if len(body) == 0 {
 		return nil, fmt.Errorf("empty bulk request")
 	}
+	if limits.MaxBodyBytes > 0 && int64(len(body)) > limits.MaxBodyBytes {
+		return nil, fmt.Errorf("%w: the body exceeds the limit of %d bytes", ErrBodyTooLarge, limits.MaxBodyBytes)
+	}
 
 	req := &BulkRequest{
 		Operations: make([]*BulkOperation, 0),
 	}
 
 	scanner := bufio.NewScanner(bytes.NewReader(body))
+	// A line can be as long as the body; the default 64KB cap fails large
+	// documents with an unhelpful "token too long"
+	scanner.Buffer(make([]byte, 0, 64*1024), len(body)+1)
 	lineNum := 0
 
 	for scanner.Scan() {
@@ -97,22 +142,28 @@ func ParseBulkRequest(body []byte) (*BulkRequest, error) {
 
 		// Determine operation type
 		var opType OperationType
-		var meta map[string]interface{}
+		var metaValue interface{}
 
 		if indexMeta, ok := actionMap["index"]; ok {
 			opType = OperationIndex
-			meta = indexMeta.(map[string]interface{})
+			metaValue = indexMeta
 		} else if createMeta, ok := actionMap["create"]; ok {
 			opType = OperationCreate
-			meta = createMeta.(map[string]interface{})
+			metaValue = createMeta
 		} else if updateMeta, ok := actionMap["update"]; ok {
 			opType = OperationUpdate
-			meta = updateMeta.(map[string]interface{})
+			metaValue = updateMeta
 		} else if deleteMeta, ok := actionMap["delete"]; ok {
 			opType = OperationDelete
-			meta = deleteMeta.(map[string]interface{})
+			metaValue = deleteMeta
 		} else {
-			return nil, fmt.Errorf("unknown bulk operation on line %d", lineNum)
+			return nil, fmt.Errorf("unknown bulk operation [%s] on line %d, expected an index, create, update or delete action",
+				actionNames(actionMap), lineNum)
+		}
+
+		meta, ok := metaValue.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("malformed %s action on line %d: the action metadata must be an object", opType, lineNum)
 		}
 
 		// Extract index and ID
@@ -129,10 +180,18 @@ func ParseBulkRequest(body []byte) (*BulkRequest, error) {
 			ID:    id,
 		}
 
+		if limits.MaxOperations > 0 && len(req.Operations) == limits.MaxOperations {
+			return nil, fmt.Errorf("%w: the %s action on line %d exceeds the limit of %d operations",
+				ErrTooManyOperations, opType, lineNum, limits.MaxOperations)
+		}
+
 		// For operations that require a document body, read the next line
 		if opType == OperationIndex || opType == OperationCreate || opType == OperationUpdate {
 			if !scanner.Scan() {
-				return nil, fmt.Errorf("missing document body for %s operation on line %d", opType, lineNum)
+				if err := scanner.Err(); err != nil {
+					return nil, fmt.Errorf("error reading document for %s operation on line %d: %w", opType, lineNum, err)
+				}
+				return nil, fmt.Errorf("missing document body for %s operation on line %d: the action line must be followed by a document line", opType, lineNum)
 			}
 
 			lineNum++
@@ -148,11 +207,24 @@ func ParseBulkRequest(body []byte) (*BulkRequest, error) {
 			}
 
 			if opType == OperationUpdate {
-				// For update operations, extract the "doc" field
-				if doc, ok := document["doc"].(map[string]interface{}); ok {
-					op.UpdateDoc = doc
-				} else {
+				// For update operations, extract the "doc", "upsert" and
+				// "doc_as_upsert" fields; a line with neither doc nor upsert is
+				// the partial document itself
+				_, hasDoc := document["doc"]
+				_, hasUpsert := document["upsert"]
+				if !hasDoc && !hasUpsert {
 					op.UpdateDoc = document
+				} else {
+					var ok bool
+					if op.UpdateDoc, ok = document["doc"].(map[string]interface{}); hasDoc && !ok {
+						return nil, fmt.Errorf("malformed update on line %d: [doc] must be an object", lineNum)
+					}
+					if op.Upsert, ok = document["upsert"].(map[string]interface{}); hasUpsert && !ok {
+						return nil, fmt.Errorf("malformed update on line %d: [upsert] must be an object", lineNum)
+					}
+					if op.DocAsUpsert, ok = document["doc_as_upsert"].(bool); document["doc_as_upsert"] != nil && !ok {
+						return nil, fmt.Errorf("malformed update on line %d: [doc_as_upsert] must be a boolean", lineNum)
+					}
 				}
 			} else {
 				op.Document = document
@@ -163,7 +235,7 @@ func ParseBulkRequest(body []byte) (*BulkRequest, error) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading bulk request: %w", err)
+		return nil, fmt.Errorf("error reading bulk request after line %d: %w", lineNum, err)
 	}
 
 	if len(req.Operations) == 0 {
@@ -173,6 +245,16 @@ func ParseBulkRequest(body []byte) (*BulkRequest, error) {
 	return req, nil
 }
 
+// actionNames returns the sorted keys of an action line, for error messages
+func actionNames(actionMap map[string]interface{}) string {
+	names := make([]string, 0, len(actionMap))
+	for name := range actionMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
 // NewBulkResponse creates a new bulk response
 func NewBulkResponse() *BulkResponse {
 	return &BulkResponse{
@@ -1,6 +1,7 @@
 package bulk
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -278,3 +279,92 @@ func TestParseBulkRequest_UpdateWithoutDoc(t *testing.T) {
 	assert.NotNil(t, op.UpdateDoc)
 	assert.Equal(t, "value", op.UpdateDoc["field"])
 }
+
+func TestParseBulkRequest_UpdateUpsert(t *testing.T) {
+	body := []byte(`{"update":{"_index":"test","_id":"1"}}
+{"doc":{"field":"value"},"doc_as_upsert":true}
+{"update":{"_index":"test","_id":"2"}}
+{"doc":{"field":"value"},"upsert":{"field":"initial"}}
+`)
+
+	req, err := ParseBulkRequest(body)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(req.Operations))
+
+	op := req.Operations[0]
+	assert.Equal(t, "value", op.UpdateDoc["field"])
+	assert.True(t, op.DocAsUpsert)
+	assert.Nil(t, op.Upsert)
+
+	op = req.Operations[1]
+	assert.Equal(t, "value", op.UpdateDoc["field"])
+	assert.False(t, op.DocAsUpsert)
+	assert.Equal(t, "initial", op.Upsert["field"])
+
+	// A doc that is not an object names the offending line
+	_, err = ParseBulkRequest([]byte(`{"update":{"_index":"test","_id":"1"}}
+{"doc":"value"}
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestParseBulkRequest_BodyTooLarge(t *testing.T) {
+	body := []byte(`{"index":{"_index":"test","_id":"1"}}
+{"field":"value"}
+`)
+
+	_, err := ParseBulkRequestWithLimits(body, Limits{MaxBodyBytes: 16})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+	assert.Contains(t, err.Error(), "limit of 16 bytes")
+
+	_, err = ParseBulkRequestWithLimits(body, Limits{MaxBodyBytes: int64(len(body))})
+	assert.NoError(t, err)
+}
+
+func TestParseBulkRequest_TooManyOperations(t *testing.T) {
+	body := []byte(`{"delete":{"_index":"test","_id":"1"}}
+{"delete":{"_index":"test","_id":"2"}}
+{"index":{"_index":"test","_id":"3"}}
+{"field":"value"}
+`)
+
+	_, err := ParseBulkRequestWithLimits(body, Limits{MaxOperations: 2})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrTooManyOperations)
+	assert.Contains(t, err.Error(), "line 3")
+}
+
+func TestParseBulkRequest_DanglingActionLine(t *testing.T) {
+	body := []byte(`{"delete":{"_index":"test","_id":"1"}}
+{"index":{"_index":"test","_id":"2"}}
+{"field":"value"}
+{"create":{"_index":"test","_id":"3"}}`)
+
+	_, err := ParseBulkRequest(body)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing document body for create operation on line 4")
+}
+
+func TestParseBulkRequest_MalformedAction(t *testing.T) {
+	body := []byte(`{"index":"test"}
+{"field":"value"}
+`)
+
+	_, err := ParseBulkRequest(body)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "malformed index action on line 1")
+}
+
+func TestParseBulkRequest_LargeDocument(t *testing.T) {
+	// Lines longer than bufio.Scanner's default 64KB token limit
+	value := strings.Repeat("x", 100*1024)
+	body := []byte(`{"index":{"_index":"test","_id":"1"}}
+{"field":"` + value + `"}
+`)
+
+	req, err := ParseBulkRequest(body)
+	require.NoError(t, err)
+	assert.Equal(t, value, req.Operations[0].Document["field"])
+}
@@ -0,0 +1,416 @@
+package coordination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/coordination/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHandleBulk_Limits(t *testing.T) {
+	node := newTestCoordinationNode(t)
+	node.cfg.MaxBulkBodyBytes = 1024
+	node.cfg.MaxBulkOperations = 2
+
+	bulkRequest := func(body string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodPost, "/_bulk", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), w.Body.String())
+		return w.Code, response
+	}
+
+	// An oversized body is rejected before it is parsed
+	code, response := bulkRequest(`{"index":{"_index":"products","_id":"1"}}
+{"title":"` + strings.Repeat("x", 2048) + `"}
+`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+	errObj := response["error"].(map[string]interface{})
+	assert.Equal(t, "request_entity_too_large_exception", errObj["type"])
+	assert.Contains(t, errObj["reason"], "limit of 1024 bytes")
+
+	code, response = bulkRequest(`{"delete":{"_index":"products","_id":"1"}}
+{"delete":{"_index":"products","_id":"2"}}
+{"delete":{"_index":"products","_id":"3"}}
+`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	errObj = response["error"].(map[string]interface{})
+	assert.Equal(t, "illegal_argument_exception", errObj["type"])
+	assert.Contains(t, errObj["reason"], "limit of 2 operations")
+
+	// A trailing action without its document names the offending line
+	code, response = bulkRequest(`{"delete":{"_index":"products","_id":"1"}}
+{"index":{"_index":"products","_id":"2"}}
+`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	errObj = response["error"].(map[string]interface{})
+	assert.Contains(t, errObj["reason"], "missing document body for index operation on line 2")
+}
+
+// slowCreateIndex is a memoryIndex that takes longer to write documents
+// marked as created, so an unordered bulk would let a later update of the
+// same id land first
+type slowCreateIndex struct {
+	*memoryIndex
+}
+
+func (s *slowCreateIndex) IndexDocument(ctx context.Context, indexName string, shardID int32, docID string, document map[string]interface{}) (*pb.IndexDocumentResponse, error) {
+	if document["stage"] == "created" {
+		time.Sleep(20 * time.Millisecond)
+	}
+	return s.memoryIndex.IndexDocument(ctx, indexName, shardID, docID, document)
+}
+
+func TestHandleBulk_SameIDOrdering(t *testing.T) {
+	node := newTestCoordinationNode(t)
+	node.cfg.BulkConcurrency = 4
+	index := &slowCreateIndex{memoryIndex: &memoryIndex{
+		docs:      map[string]map[string]interface{}{},
+		conflicts: map[string]bool{},
+	}}
+	node.docRouter = router.NewDocumentRouter(&mockMasterClient{},
+		map[string]router.DataNodeClient{"node1": index}, zap.NewNop())
+
+	var body strings.Builder
+	for i := 0; i < 8; i++ {
+		fmt.Fprintf(&body, `{"create":{"_index":"products","_id":"%d"}}`+"\n", i)
+		body.WriteString(`{"stage":"created"}` + "\n")
+		fmt.Fprintf(&body, `{"update":{"_index":"products","_id":"%d"}}`+"\n", i)
+		body.WriteString(`{"doc":{"stage":"updated"}}` + "\n")
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/_bulk", strings.NewReader(body.String()))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	w := httptest.NewRecorder()
+	node.ginRouter.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// Every document ends in the state of its last operation
+	require.Len(t, index.docs, 8)
+	for id, doc := range index.docs {
+		assert.Equal(t, "updated", doc["stage"], "document %s", id)
+	}
+}
+
+func TestHandleBulk_PartialUpdateAndUpsert(t *testing.T) {
+	node := newTestCoordinationNode(t)
+	index := &memoryIndex{
+		docs: map[string]map[string]interface{}{
+			"1": {"title": "laptop", "price": 999.0, "specs": map[string]interface{}{"ram": "8GB", "cpu": "i5"}},
+		},
+		conflicts: map[string]bool{},
+	}
+	node.docRouter = router.NewDocumentRouter(&mockMasterClient{},
+		map[string]router.DataNodeClient{"node1": index}, zap.NewNop())
+
+	body := `{"update":{"_index":"products","_id":"1"}}
+{"doc":{"price":899.0,"specs":{"ram":"16GB"}}}
+{"update":{"_index":"products","_id":"1"}}
+{"doc":{"title":"laptop"}}
+{"update":{"_index":"products","_id":"2"}}
+{"doc":{"title":"phone"},"doc_as_upsert":true}
+{"update":{"_index":"products","_id":"3"}}
+{"doc":{"title":"tablet"},"upsert":{"title":"tablet","stock":1}}
+{"update":{"_index":"products","_id":"4"}}
+{"doc":{"title":"watch"}}
+`
+	req := httptest.NewRequest(http.MethodPost, "/_bulk", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	w := httptest.NewRecorder()
+	node.ginRouter.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Errors bool                                `json:"errors"`
+		Items  []map[string]map[string]interface{} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Items, 5)
+	results := make([]interface{}, len(response.Items))
+	for i, item := range response.Items {
+		results[i] = item["update"]["result"]
+	}
+	assert.Equal(t, []interface{}{"updated", "noop", "created", "created", nil}, results)
+	assert.True(t, response.Errors)
+	missing := response.Items[4]["update"]
+	assert.Equal(t, float64(http.StatusNotFound), missing["status"])
+	assert.Equal(t, "document_missing_exception", missing["error"].(map[string]interface{})["type"])
+
+	// The partial document is merged into the existing source
+	assert.Equal(t, map[string]interface{}{
+		"title": "laptop",
+		"price": 899.0,
+		"specs": map[string]interface{}{"ram": "16GB", "cpu": "i5"},
+	}, index.docs["1"])
+	assert.Equal(t, map[string]interface{}{"title": "phone"}, index.docs["2"])
+	assert.Equal(t, map[string]interface{}{"title": "tablet", "stock": 1.0}, index.docs["3"])
+	assert.NotContains(t, index.docs, "4")
+}
+
+func TestHandleBulk_IndexCreatedThenUpdated(t *testing.T) {
+	node := newTestCoordinationNode(t)
+	index := &memoryIndex{
+		docs:      map[string]map[string]interface{}{},
+		conflicts: map[string]bool{},
+	}
+	node.docRouter = router.NewDocumentRouter(&mockMasterClient{},
+		map[string]router.DataNodeClient{"node1": index}, zap.NewNop())
+
+	body := `{"index":{"_index":"products","_id":"1"}}
+{"title":"laptop"}
+{"index":{"_index":"products","_id":"1"}}
+{"title":"laptop pro"}
+`
+	req := httptest.NewRequest(http.MethodPost, "/_bulk", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	w := httptest.NewRecorder()
+	node.ginRouter.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Items []map[string]map[string]interface{} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Items, 2)
+
+	first, second := response.Items[0]["index"], response.Items[1]["index"]
+	assert.Equal(t, "created", first["result"])
+	assert.Equal(t, float64(http.StatusCreated), first["status"])
+	assert.Equal(t, "updated", second["result"])
+	assert.Equal(t, float64(http.StatusOK), second["status"])
+	assert.Equal(t, "laptop pro", index.docs["1"]["title"])
+}
+
+func TestHandleBulk_ReplaceUnsupported(t *testing.T) {
+	node := newTestCoordinationNode(t)
+	index := &memoryIndex{
+		docs:               map[string]map[string]interface{}{"1": {"title": "laptop", "price": 999.0}},
+		conflicts:          map[string]bool{},
+		replaceUnsupported: true,
+	}
+	node.docRouter = router.NewDocumentRouter(&mockMasterClient{},
+		map[string]router.DataNodeClient{"node1": index}, zap.NewNop())
+
+	// Writes that would replace the existing document are reported as
+	// unsupported rather than updated; new documents are still created
+	body := `{"index":{"_index":"products","_id":"1"}}
+{"title":"laptop pro"}
+{"update":{"_index":"products","_id":"1"}}
+{"doc":{"price":899.0}}
+{"update":{"_index":"products","_id":"1"}}
+{"doc":{"price":999.0}}
+{"index":{"_index":"products","_id":"2"}}
+{"title":"phone"}
+`
+	req := httptest.NewRequest(http.MethodPost, "/_bulk", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	w := httptest.NewRecorder()
+	node.ginRouter.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Items []map[string]map[string]interface{} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Items, 4)
+	for _, item := range []map[string]interface{}{response.Items[0]["index"], response.Items[1]["update"]} {
+		assert.Equal(t, float64(http.StatusNotImplemented), item["status"])
+		assert.Equal(t, "unsupported_operation_exception", item["error"].(map[string]interface{})["type"])
+	}
+	assert.Equal(t, "noop", response.Items[2]["update"]["result"])
+	assert.Equal(t, "created", response.Items[3]["index"]["result"])
+	assert.Equal(t, map[string]interface{}{"title": "laptop", "price": 999.0}, index.docs["1"])
+
+	// The document APIs report the same
+	for _, write := range []struct{ method, path, body string }{
+		{http.MethodPut, "/products/_doc/1", `{"title":"laptop pro"}`},
+		{http.MethodPost, "/products/_update/1", `{"doc":{"price":899.0}}`},
+	} {
+		req = httptest.NewRequest(write.method, write.path, strings.NewReader(write.body))
+		req.Header.Set("Content-Type", "application/json")
+		w = httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotImplemented, w.Code, w.Body.String())
+	}
+	assert.Equal(t, map[string]interface{}{"title": "laptop", "price": 999.0}, index.docs["1"])
+}
+
+// limitedIndex is a memoryIndex that rejects oversized documents the way a
+// data node enforcing its index's document limits does
+type limitedIndex struct {
+	*memoryIndex
+}
+
+func (l *limitedIndex) IndexDocument(ctx context.Context, indexName string, shardID int32, docID string, document map[string]interface{}) (*pb.IndexDocumentResponse, error) {
+	if value, _ := document["body"].(string); len(value) > 16 {
+		return nil, status.Errorf(codes.InvalidArgument, "document [%s] has a value of %d bytes in field [body], longer than the limit of [16] set by [index.mapping.max_field_value_length]", docID, len(value))
+	}
+	if len(document) > 4 {
+		return nil, status.Errorf(codes.OutOfRange, "document [%s] is larger than the limit of [64] bytes set by [index.mapping.max_document_size_bytes]", docID)
+	}
+	return l.memoryIndex.IndexDocument(ctx, indexName, shardID, docID, document)
+}
+
+func TestIndexDocument_OversizedRejected(t *testing.T) {
+	node := newTestCoordinationNode(t)
+	index := &limitedIndex{memoryIndex: &memoryIndex{
+		docs:      map[string]map[string]interface{}{},
+		conflicts: map[string]bool{},
+	}}
+	node.docRouter = router.NewDocumentRouter(&mockMasterClient{},
+		map[string]router.DataNodeClient{"node1": index}, zap.NewNop())
+
+	put := func(path, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodPut, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	w, response := put("/articles/_doc/1", `{"body": "`+strings.Repeat("x", 17)+`"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	errObj := response["error"].(map[string]interface{})
+	assert.Equal(t, "illegal_argument_exception", errObj["type"])
+	assert.Contains(t, errObj["reason"], "index.mapping.max_field_value_length")
+
+	w, response = put("/articles/_doc/2", `{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	errObj = response["error"].(map[string]interface{})
+	assert.Equal(t, "document_too_large_exception", errObj["type"])
+	assert.Contains(t, errObj["reason"], "index.mapping.max_document_size_bytes")
+
+	// Bulk items keep the status their document was rejected with
+	body := `{"index":{"_index":"articles","_id":"3"}}
+{"body":"fits"}
+{"index":{"_index":"articles","_id":"4"}}
+{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5}
+`
+	req := httptest.NewRequest(http.MethodPost, "/_bulk", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	bulkW := httptest.NewRecorder()
+	node.ginRouter.ServeHTTP(bulkW, req)
+	require.Equal(t, http.StatusOK, bulkW.Code, bulkW.Body.String())
+
+	var bulkResponse struct {
+		Errors bool                                `json:"errors"`
+		Items  []map[string]map[string]interface{} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(bulkW.Body.Bytes(), &bulkResponse))
+	require.Len(t, bulkResponse.Items, 2)
+	assert.True(t, bulkResponse.Errors)
+	assert.Equal(t, float64(http.StatusCreated), bulkResponse.Items[0]["index"]["status"])
+	rejected := bulkResponse.Items[1]["index"]
+	assert.Equal(t, float64(http.StatusRequestEntityTooLarge), rejected["status"])
+	assert.Equal(t, "document_too_large_exception", rejected["error"].(map[string]interface{})["type"])
+
+	assert.Len(t, index.docs, 1)
+}
+
+func TestHandleBulk_RelocatingShardRejectsWrites(t *testing.T) {
+	node := newTestCoordinationNode(t)
+	index := &memoryIndex{
+		docs:      map[string]map[string]interface{}{"1": {"title": "laptop"}},
+		conflicts: map[string]bool{},
+	}
+	master := &mockMasterClient{shardRouting: map[int32]*pb.ShardRouting{
+		0: {
+			ShardId:   0,
+			IsPrimary: true,
+			Allocation: &pb.ShardAllocation{
+				NodeId: "node1",
+				State:  pb.ShardAllocation_SHARD_STATE_RELOCATING,
+			},
+		},
+	}}
+	node.docRouter = router.NewDocumentRouter(master,
+		map[string]router.DataNodeClient{"node1": index}, zap.NewNop())
+
+	body := `{"index":{"_index":"products","_id":"2"}}
+{"title":"phone"}
+`
+	req := httptest.NewRequest(http.MethodPost, "/_bulk", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	w := httptest.NewRecorder()
+	node.ginRouter.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// The write is rejected rather than sent to the relocation source
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	item := response["items"].([]interface{})[0].(map[string]interface{})["index"].(map[string]interface{})
+	assert.Equal(t, float64(http.StatusServiceUnavailable), item["status"])
+	assert.Equal(t, "unavailable_shards_exception", item["error"].(map[string]interface{})["type"])
+	assert.NotContains(t, index.docs, "2")
+
+	// Reads keep being served by the source
+	req = httptest.NewRequest(http.MethodGet, "/products/_doc/1", nil)
+	w = httptest.NewRecorder()
+	node.ginRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestHandleBulk_ReplicatesWritesToStartedReplicas(t *testing.T) {
+	node := newTestCoordinationNode(t)
+	primary := &memoryIndex{docs: map[string]map[string]interface{}{}, conflicts: map[string]bool{}}
+	replica := &memoryIndex{docs: map[string]map[string]interface{}{}, conflicts: map[string]bool{}}
+	master := &mockMasterClient{shardRouting: map[int32]*pb.ShardRouting{
+		0: {
+			ShardId:    0,
+			IsPrimary:  true,
+			Allocation: &pb.ShardAllocation{NodeId: "node1", State: pb.ShardAllocation_SHARD_STATE_STARTED},
+			Replicas: []*pb.ShardAllocation{
+				{NodeId: "node2", State: pb.ShardAllocation_SHARD_STATE_STARTED},
+			},
+		},
+	}}
+	node.docRouter = router.NewDocumentRouter(master,
+		map[string]router.DataNodeClient{"node1": primary, "node2": replica}, zap.NewNop())
+
+	bulk := func(id string) map[string]interface{} {
+		body := `{"index":{"_index":"products","_id":"` + id + `"}}
+{"title":"phone"}
+`
+		req := httptest.NewRequest(http.MethodPost, "/_bulk", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response["items"].([]interface{})[0].(map[string]interface{})["index"].(map[string]interface{})
+	}
+
+	// A started replica takes each write along with the primary, so it can
+	// serve reads
+	item := bulk("1")
+	assert.Equal(t, float64(http.StatusCreated), item["status"])
+	assert.Contains(t, primary.docs, "1")
+	assert.Contains(t, replica.docs, "1")
+
+	// While a replica recovers from the primary writes are rejected, so the
+	// recovery doesn't miss them
+	master.shardRouting[0].Replicas[0].State = pb.ShardAllocation_SHARD_STATE_INITIALIZING
+	item = bulk("2")
+	assert.Equal(t, float64(http.StatusServiceUnavailable), item["status"])
+	assert.NotContains(t, primary.docs, "2")
+	assert.NotContains(t, replica.docs, "2")
+}
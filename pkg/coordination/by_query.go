@@ -0,0 +1,203 @@
+package coordination
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultScrollSize is the number of matching documents fetched per search
+// page by the _delete_by_query and _update_by_query APIs
+const defaultScrollSize = 1000
+
+// byQueryRequest holds the parameters shared by the by-query APIs
+type byQueryRequest struct {
+	Query      json.RawMessage
+	Conflicts  string // "abort" or "proceed"
+	ScrollSize int
+}
+
+// byQueryFailure describes a document a by-query request could not process
+type byQueryFailure struct {
+	ID     string
+	Type   string
+	Reason string
+}
+
+// byQueryResult counts the outcome of a by-query request
+type byQueryResult struct {
+	Total            int
+	Batches          int
+	VersionConflicts int
+	Failures         []byQueryFailure
+	Aborted          bool
+	Cancelled        bool // Stopped by cancelling the request's task
+}
+
+// parseByQueryRequest reads the query and the conflicts and scroll_size
+// parameters of a by-query request. Without a query all documents match.
+// On error it writes the error response and returns false.
+func parseByQueryRequest(ctx *gin.Context, requireQuery bool) (*byQueryRequest, bool) {
+	badRequest := func(errorType, reason string) (*byQueryRequest, bool) {
+		respondError(ctx, http.StatusBadRequest, errorType, reason)
+		return nil, false
+	}
+
+	req := &byQueryRequest{
+		Conflicts:  ctx.DefaultQuery("conflicts", "abort"),
+		ScrollSize: defaultScrollSize,
+	}
+	if req.Conflicts != "abort" && req.Conflicts != "proceed" {
+		return badRequest("illegal_argument_exception",
+			fmt.Sprintf("conflicts may only be \"proceed\" or \"abort\" but was [%s]", req.Conflicts))
+	}
+
+	if value := ctx.Query("scroll_size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			return badRequest("illegal_argument_exception",
+				fmt.Sprintf("scroll_size must be a positive integer but was [%s]", value))
+		}
+		req.ScrollSize = size
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return badRequest("parse_exception", fmt.Sprintf("Failed to read request body: %v", err))
+	}
+
+	if len(body) > 0 {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return badRequest("parsing_exception", fmt.Sprintf("Failed to parse request body: %v", err))
+		}
+		req.Query = fields["query"]
+	}
+	if len(req.Query) == 0 {
+		if requireQuery {
+			return badRequest("parsing_exception", "request body must contain a query")
+		}
+		req.Query = json.RawMessage(`{"match_all": {}}`)
+	}
+
+	return req, true
+}
+
+// scrollMatches pages through the documents matching query, calling fn with
+// each page of hits not seen on an earlier page. Paging stops when fn
+// returns false.
+func (c *CoordinationNode) scrollMatches(ctx context.Context, indexName string, query json.RawMessage, scrollSize int, fn func(hits []*SearchHit) bool) error {
+	seen := make(map[string]bool)
+
+	for from := 0; ; from += scrollSize {
+		page, err := json.Marshal(map[string]interface{}{
+			"query": query,
+			"from":  from,
+			"size":  scrollSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to build search request: %w", err)
+		}
+
+		// Pages go past max_result_window, as a client's scroll would
+		result, err := c.queryService.executeSearch(ctx, indexName, page, false)
+		if err != nil {
+			return err
+		}
+
+		var hits []*SearchHit
+		for _, hit := range result.Hits {
+			if !seen[hit.ID] {
+				seen[hit.ID] = true
+				hits = append(hits, hit)
+			}
+		}
+		if len(hits) > 0 && !fn(hits) {
+			return nil
+		}
+
+		if len(result.Hits) < scrollSize || int64(from+len(result.Hits)) >= result.TotalHits {
+			return nil
+		}
+	}
+}
+
+// recordFailure counts a failed document operation and reports whether the
+// request should continue. Version conflicts, reported by data nodes as
+// Aborted, are skipped when conflicts=proceed; any other failure aborts.
+func (r *byQueryResult) recordFailure(req *byQueryRequest, docID, failureType string, err error) bool {
+	if status.Code(err) == codes.Aborted {
+		r.VersionConflicts++
+		if req.Conflicts == "proceed" {
+			return true
+		}
+		failureType = "version_conflict_engine_exception"
+	}
+	r.Failures = append(r.Failures, byQueryFailure{ID: docID, Type: failureType, Reason: err.Error()})
+	r.Aborted = true
+	return false
+}
+
+// statusCode returns the HTTP status of a by-query response
+func (r *byQueryResult) statusCode() int {
+	if r.Aborted && r.VersionConflicts > 0 {
+		return http.StatusConflict
+	}
+	return http.StatusOK
+}
+
+// response builds the JSON response body shared by the by-query APIs
+func (r *byQueryResult) response(indexName string, tookMillis int64) gin.H {
+	failures := make([]gin.H, 0, len(r.Failures))
+	for _, failure := range r.Failures {
+		failures = append(failures, gin.H{
+			"index": indexName,
+			"id":    failure.ID,
+			"cause": gin.H{
+				"type":   failure.Type,
+				"reason": failure.Reason,
+			},
+		})
+	}
+
+	response := gin.H{
+		"took":              tookMillis,
+		"timed_out":         false,
+		"total":             r.Total,
+		"batches":           r.Batches,
+		"version_conflicts": r.VersionConflicts,
+		"failures":          failures,
+	}
+	if r.Cancelled {
+		response["canceled"] = "by user request"
+	}
+	return response
+}
+
+// progress returns the task status of a by-query request, with the number
+// of documents processed so far under doneKey
+func (r *byQueryResult) progress(doneKey string, done int) gin.H {
+	return gin.H{
+		"total":             r.Total,
+		doneKey:             done,
+		"batches":           r.Batches,
+		"version_conflicts": r.VersionConflicts,
+	}
+}
+
+// byQuerySearchError returns the response for a failed by-query search
+func byQuerySearchError(err error) (int, gin.H) {
+	apiErr := &APIError{
+		Status: http.StatusInternalServerError,
+		Type:   "search_exception",
+		Reason: fmt.Sprintf("By query search failed: %v", err),
+	}
+	return apiErr.Status, apiErr.envelope()
+}
@@ -5,12 +5,13 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
-	"github.com/quidditch/quidditch/pkg/coordination/parser"
-	"github.com/quidditch/quidditch/pkg/coordination/planner"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/quidditch/quidditch/pkg/coordination/parser"
+	"github.com/quidditch/quidditch/pkg/coordination/planner"
 )
 
 // Prometheus metrics for query cache
@@ -296,21 +297,27 @@ func (qc *QueryCache) Stats() map[CacheType]CacheStats {
 func (qc *QueryCache) generateLogicalPlanKey(indexName string, searchReq *parser.SearchRequest, shardIDs []int32) string {
 	// Create a normalized representation of the search request
 	keyData := struct {
-		Index        string
-		Query        interface{}
-		Aggregations interface{}
-		Size         int
-		From         int
-		Sort         interface{}
-		ShardIDs     []int32
+		Index          string
+		Query          interface{}
+		Aggregations   interface{}
+		Size           int
+		From           int
+		Sort           interface{}
+		Collapse       interface{}
+		TerminateAfter int
+		MinScore       float64
+		ShardIDs       []int32
 	}{
-		Index:        indexName,
-		Query:        normalizeQuery(searchReq.ParsedQuery),
-		Aggregations: searchReq.Aggregations, // Use raw aggregations map
-		Size:         searchReq.Size,
-		From:         searchReq.From,
-		Sort:         searchReq.Sort, // Use raw sort slice
-		ShardIDs:     shardIDs,
+		Index:          indexName,
+		Query:          normalizeQuery(searchReq.ParsedQuery),
+		Aggregations:   searchReq.Aggregations, // Use raw aggregations map
+		Size:           searchReq.Size,
+		From:           searchReq.From,
+		Sort:           searchReq.Sort, // Use raw sort slice
+		Collapse:       searchReq.Collapse,
+		TerminateAfter: searchReq.TerminateAfter,
+		MinScore:       searchReq.MinScore,
+		ShardIDs:       shardIDs,
 	}
 
 	// Serialize to JSON for consistent hashing
@@ -327,21 +334,46 @@ func (qc *QueryCache) generateLogicalPlanKey(indexName string, searchReq *parser
 
 // generatePhysicalPlanKey creates a cache key for a physical plan
 func (qc *QueryCache) generatePhysicalPlanKey(indexName string, logicalPlan planner.LogicalPlan) string {
-	// Use the logical plan's string representation as part of the key
-	planStr := logicalPlan.String()
-	keyStr := fmt.Sprintf("%s:%s", indexName, planStr)
+	// Each node only describes itself, so the key spells out the whole tree
+	keyStr := fmt.Sprintf("%s:%s", indexName, planTreeString(logicalPlan))
 
 	// Hash the key
 	hash := sha256.Sum256([]byte(keyStr))
 	return "physical:" + hex.EncodeToString(hash[:])
 }
 
+// planTreeString describes plan and, nested under it, each of its children
+func planTreeString(plan planner.LogicalPlan) string {
+	desc := plan.String()
+	if children := plan.Children(); len(children) > 0 {
+		parts := make([]string, 0, len(children))
+		for _, child := range children {
+			if child == nil {
+				continue
+			}
+			parts = append(parts, planTreeString(child))
+		}
+		desc += "[" + strings.Join(parts, ", ") + "]"
+	}
+	return desc
+}
+
 // normalizeQuery normalizes a query for consistent caching
 func normalizeQuery(query parser.Query) interface{} {
 	if query == nil {
 		return nil
 	}
 
+	normalized := normalizeQueryFields(query)
+	// Named clauses report matched_queries, so the name is part of the key
+	if named, ok := query.(parser.NamedQuery); ok && named.QueryName() != "" {
+		normalized["_name"] = named.QueryName()
+	}
+	return normalized
+}
+
+// normalizeQueryFields returns the type-specific fields of a normalized query
+func normalizeQueryFields(query parser.Query) map[string]interface{} {
 	// Return a map representation that can be consistently serialized
 	// This is a simplified normalization - in production, you'd want more sophisticated handling
 	switch q := query.(type) {
@@ -351,6 +383,11 @@ func normalizeQuery(query parser.Query) interface{} {
 			"field": q.Field,
 			"value": q.Value,
 		}
+	case *parser.IDsQuery:
+		return map[string]interface{}{
+			"type":   "ids",
+			"values": q.Values,
+		}
 	case *parser.MatchQuery:
 		return map[string]interface{}{
 			"type":  "match",
@@ -368,12 +405,12 @@ func normalizeQuery(query parser.Query) interface{} {
 		}
 	case *parser.BoolQuery:
 		return map[string]interface{}{
-			"type":               "bool",
-			"must":               normalizeQueryList(q.Must),
-			"should":             normalizeQueryList(q.Should),
-			"must_not":           normalizeQueryList(q.MustNot),
-			"filter":             normalizeQueryList(q.Filter),
-			"minimum_should_match": q.MinimumShouldMatch,
+			"type":                 "bool",
+			"must":                 normalizeQueryList(q.Must),
+			"should":               normalizeQueryList(q.Should),
+			"must_not":             normalizeQueryList(q.MustNot),
+			"filter":               normalizeQueryList(q.Filter),
+			"minimum_should_match": q.RequiredShouldClauses(),
 		}
 	case *parser.MatchAllQuery:
 		return map[string]interface{}{
@@ -391,6 +428,12 @@ func normalizeQuery(query parser.Query) interface{} {
 			"field": q.Field,
 			"value": q.Value,
 		}
+	case *parser.RegexpQuery:
+		return map[string]interface{}{
+			"type":  "regexp",
+			"field": q.Field,
+			"value": q.Value,
+		}
 	case *parser.FuzzyQuery:
 		return map[string]interface{}{
 			"type":      "fuzzy",
@@ -403,6 +446,25 @@ func normalizeQuery(query parser.Query) interface{} {
 			"type":  "exists",
 			"field": q.Field,
 		}
+	case *parser.GeoDistanceQuery:
+		return map[string]interface{}{
+			"type":            "geo_distance",
+			"field":           q.Field,
+			"lat":             q.Origin.Lat,
+			"lon":             q.Origin.Lon,
+			"distance":        q.Distance,
+			"render_distance": q.RenderDistance,
+		}
+	case *parser.QueryStringQuery:
+		return map[string]interface{}{
+			"type":  "query_string",
+			"query": normalizeQuery(q.Parsed),
+		}
+	case *parser.SimpleQueryStringQuery:
+		return map[string]interface{}{
+			"type":  "simple_query_string",
+			"query": normalizeQuery(q.Parsed),
+		}
 	default:
 		// Fallback: use string representation
 		return map[string]interface{}{
@@ -122,6 +122,76 @@ func TestQueryCache_LogicalPlan_DifferentQueries(t *testing.T) {
 	assert.NotNil(t, plan2)
 }
 
+func TestQueryCache_LogicalPlan_TerminateAfter(t *testing.T) {
+	cache := NewQueryCache(DefaultQueryCacheConfig())
+
+	query := &parser.TermQuery{Field: "status", Value: "active"}
+	searchReq := &parser.SearchRequest{ParsedQuery: query, Size: 10}
+	limitedReq := &parser.SearchRequest{ParsedQuery: query, Size: 10, TerminateAfter: 5}
+
+	indexName := "products"
+	shardIDs := []int32{0}
+	cache.PutLogicalPlan(indexName, searchReq, shardIDs, &planner.LogicalScan{IndexName: indexName, Shards: shardIDs})
+
+	// A request with terminate_after doesn't reuse the unlimited plan
+	_, found := cache.GetLogicalPlan(indexName, limitedReq, shardIDs)
+	assert.False(t, found)
+
+	// Nor does its physical plan
+	unlimited := &planner.LogicalScan{IndexName: indexName, Shards: shardIDs}
+	limited := &planner.LogicalScan{IndexName: indexName, Shards: shardIDs, TerminateAfter: 5}
+	cache.PutPhysicalPlan(indexName, unlimited, &planner.PhysicalScan{IndexName: indexName})
+	_, found = cache.GetPhysicalPlan(indexName, limited)
+	assert.False(t, found)
+}
+
+func TestQueryCache_LogicalPlan_MinScore(t *testing.T) {
+	cache := NewQueryCache(DefaultQueryCacheConfig())
+
+	query := &parser.TermQuery{Field: "status", Value: "active"}
+	searchReq := &parser.SearchRequest{ParsedQuery: query, Size: 10, MinScore: 0.5}
+	strictReq := &parser.SearchRequest{ParsedQuery: query, Size: 10, MinScore: 2}
+
+	indexName := "products"
+	shardIDs := []int32{0}
+	cache.PutLogicalPlan(indexName, searchReq, shardIDs, &planner.LogicalScan{IndexName: indexName, Shards: shardIDs, MinScore: 0.5})
+
+	// A different min_score doesn't reuse the cached plan
+	_, found := cache.GetLogicalPlan(indexName, strictReq, shardIDs)
+	assert.False(t, found)
+	_, found = cache.GetLogicalPlan(indexName, searchReq, shardIDs)
+	assert.True(t, found)
+
+	// Nor does its physical plan
+	cache.PutPhysicalPlan(indexName, &planner.LogicalScan{IndexName: indexName, Shards: shardIDs, MinScore: 0.5}, &planner.PhysicalScan{IndexName: indexName})
+	_, found = cache.GetPhysicalPlan(indexName, &planner.LogicalScan{IndexName: indexName, Shards: shardIDs, MinScore: 2})
+	assert.False(t, found)
+}
+
+func TestQueryCache_PhysicalPlan_KeyCoversWholeTree(t *testing.T) {
+	cache := NewQueryCache(DefaultQueryCacheConfig())
+
+	indexName := "products"
+	page := func(filter *planner.Expression) planner.LogicalPlan {
+		return &planner.LogicalLimit{Limit: 10, Child: &planner.LogicalScan{IndexName: indexName, Filter: filter}}
+	}
+	boolOf := func(value string) *planner.Expression {
+		return &planner.Expression{Type: planner.ExprTypeBool, Children: []*planner.Expression{
+			{Type: planner.ExprTypeMatch, Field: "title", Value: value},
+		}}
+	}
+	cache.PutPhysicalPlan(indexName, page(boolOf("laptop")), &planner.PhysicalScan{IndexName: indexName})
+
+	// The same page over another query, or over a bool with other clauses,
+	// doesn't reuse the cached plan
+	_, found := cache.GetPhysicalPlan(indexName, page(&planner.Expression{Type: planner.ExprTypeMatch, Field: "title", Value: "desk"}))
+	assert.False(t, found)
+	_, found = cache.GetPhysicalPlan(indexName, page(boolOf("desk")))
+	assert.False(t, found)
+	_, found = cache.GetPhysicalPlan(indexName, page(boolOf("laptop")))
+	assert.True(t, found)
+}
+
 func TestQueryCache_LogicalPlan_SameQueryDifferentIndices(t *testing.T) {
 	cache := NewQueryCache(DefaultQueryCacheConfig())
 
@@ -0,0 +1,182 @@
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"go.uber.org/zap"
+)
+
+// catIndicesColumns are the columns of /_cat/indices, in order
+var catIndicesColumns = []string{"health", "status", "index", "uuid", "pri", "rep", "docs.count", "docs.deleted", "store.size", "pri.store.size"}
+
+// handleCatIndices lists indices with their health, document counts and
+// on-disk size, as the data nodes measure it from each shard's files. The
+// sizes cover every started copy; pri.store.size only the primaries. With
+// format=json the rows are objects, otherwise aligned text with a header
+// row when v is set. Sizes are in bytes with bytes=b, otherwise
+// human-readable.
+func (c *CoordinationNode) handleCatIndices(ctx *gin.Context) {
+	state, err := c.masterClient.GetClusterState(ctx.Request.Context(), true, false, true)
+	if err != nil {
+		respondErrorFrom(ctx, err, "cat_exception", "Failed to get cluster state")
+		return
+	}
+
+	var indices []*pb.IndexMetadata
+	if indexName := ctx.Param("index"); indexName != "" {
+		for _, index := range state.Indices {
+			if index.IndexName == indexName {
+				indices = append(indices, index)
+			}
+		}
+		if len(indices) == 0 {
+			respondAPIError(ctx, indexNotFoundError(indexName))
+			return
+		}
+	} else {
+		indices = state.Indices
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i].IndexName < indices[j].IndexName })
+
+	rawBytes := ctx.Query("bytes") == "b"
+	formatSize := func(size int64) string {
+		if rawBytes {
+			return strconv.FormatInt(size, 10)
+		}
+		return formatByteSize(size)
+	}
+
+	rows := make([]map[string]string, len(indices))
+	for i, index := range indices {
+		routing := state.GetRoutingTable().GetIndices()[index.IndexName]
+		var tally shardTally
+		tally.addIndex(routing)
+
+		var docs, deleted, storeSize, primarySize int64
+		copies := startedShardCopies(routing.GetShards())
+		for j, stats := range c.shardCopyStats(ctx.Request.Context(), index.IndexName, copies) {
+			if stats == nil {
+				continue
+			}
+			storeSize += stats.SizeBytes
+			if copies[j].IsPrimary {
+				docs += stats.DocsCount
+				deleted += stats.DocsDeleted
+				primarySize += stats.SizeBytes
+			}
+		}
+
+		status := "open"
+		if index.State == pb.IndexMetadata_INDEX_STATE_CLOSED {
+			status = "close"
+		}
+		rows[i] = map[string]string{
+			"health":         tally.status(),
+			"status":         status,
+			"index":          index.IndexName,
+			"uuid":           index.IndexUuid,
+			"pri":            strconv.Itoa(int(index.GetSettings().GetNumberOfShards())),
+			"rep":            strconv.Itoa(int(index.GetSettings().GetNumberOfReplicas())),
+			"docs.count":     strconv.FormatInt(docs, 10),
+			"docs.deleted":   strconv.FormatInt(deleted, 10),
+			"store.size":     formatSize(storeSize),
+			"pri.store.size": formatSize(primarySize),
+		}
+	}
+
+	if ctx.Query("format") == "json" {
+		ctx.JSON(http.StatusOK, rows)
+		return
+	}
+	_, verbose := ctx.GetQuery("v")
+	ctx.String(http.StatusOK, formatCatTable(catIndicesColumns, rows, verbose))
+}
+
+// shardCopyStats asks the data nodes for the stats of shard copies, in the
+// order given. A copy whose node can't be reached has nil stats.
+func (c *CoordinationNode) shardCopyStats(ctx context.Context, indexName string, copies []shardCopy) []*pb.ShardStats {
+	stats := make([]*pb.ShardStats, len(copies))
+	var wg sync.WaitGroup
+	for i, shard := range copies {
+		c.dataClientsMu.RLock()
+		client, exists := c.dataClients[shard.NodeID]
+		c.dataClientsMu.RUnlock()
+		if !exists {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, shard shardCopy) {
+			defer wg.Done()
+			resp, err := client.GetShardStats(ctx, indexName, shard.ShardID)
+			if err != nil {
+				c.logger.Warn("Failed to get shard stats",
+					zap.String("index", indexName),
+					zap.Int32("shard_id", shard.ShardID),
+					zap.String("node_id", shard.NodeID),
+					zap.Error(err))
+				return
+			}
+			stats[i] = resp
+		}(i, shard)
+	}
+	wg.Wait()
+	return stats
+}
+
+// formatCatTable renders rows as whitespace-aligned text columns, with a
+// header row if verbose
+func formatCatTable(columns []string, rows []map[string]string, verbose bool) string {
+	widths := make([]int, len(columns))
+	if verbose {
+		for i, column := range columns {
+			widths[i] = len(column)
+		}
+	}
+	for _, row := range rows {
+		for i, column := range columns {
+			widths[i] = max(widths[i], len(row[column]))
+		}
+	}
+
+	var b strings.Builder
+	writeRow := func(value func(i int) string) {
+		cells := make([]string, len(columns))
+		for i := range columns {
+			cells[i] = fmt.Sprintf("%-*s", widths[i], value(i))
+		}
+		b.WriteString(strings.TrimRight(strings.Join(cells, " "), " "))
+		b.WriteString("\n")
+	}
+	if verbose {
+		writeRow(func(i int) string { return columns[i] })
+	}
+	for _, row := range rows {
+		writeRow(func(i int) string { return row[columns[i]] })
+	}
+	return b.String()
+}
+
+// formatByteSize renders a size in the largest unit it reaches, as in
+// "512b", "3.4kb" or "1.2gb"
+func formatByteSize(size int64) string {
+	units := []string{"b", "kb", "mb", "gb", "tb", "pb"}
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d%s", size, units[0])
+	}
+	return strconv.FormatFloat(value, 'f', 1, 64) + units[unit]
+}
@@ -0,0 +1,112 @@
+package coordination
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+func TestCatIndices(t *testing.T) {
+	// A master holding a 2-shard index on node1
+	masterLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &resizeMasterServer{indices: map[string]*pb.IndexMetadata{
+		"logs": {IndexName: "logs", IndexUuid: "logs-uuid", Settings: &pb.IndexSettings{NumberOfShards: 2}},
+	}}
+	masterServer := grpc.NewServer()
+	pb.RegisterMasterServiceServer(masterServer, master)
+	go masterServer.Serve(masterLis)
+	defer masterServer.Stop()
+
+	dataLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	data := &segmentDataServer{segments: map[int32][]*pb.SegmentStats{}}
+	dataServer := grpc.NewServer()
+	pb.RegisterDataServiceServer(dataServer, data)
+	go dataServer.Serve(dataLis)
+	defer dataServer.Stop()
+
+	node := newTestCoordinationNode(t)
+	node.masterClient = NewMasterClient(masterLis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	defer node.masterClient.Disconnect()
+
+	dataClient := NewDataNodeClient("node1", dataLis.Addr().String(), zap.NewNop())
+	require.NoError(t, dataClient.Connect(context.Background()))
+	defer dataClient.Disconnect()
+	node.dataClients["node1"] = dataClient
+
+	catIndices := func() map[string]string {
+		req := httptest.NewRequest(http.MethodGet, "/_cat/indices/logs?format=json&bytes=b", nil)
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var rows []map[string]string
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rows))
+		require.Len(t, rows, 1)
+		return rows[0]
+	}
+	storeSize := func(row map[string]string) int64 {
+		size, err := strconv.ParseInt(row["store.size"], 10, 64)
+		require.NoError(t, err)
+		return size
+	}
+
+	data.index(0, 10)
+	data.index(1, 10)
+	row := catIndices()
+	assert.Equal(t, "green", row["health"])
+	assert.Equal(t, "logs-uuid", row["uuid"])
+	assert.Equal(t, "2", row["pri"])
+	assert.Equal(t, "20", row["docs.count"])
+	initial := storeSize(row)
+	assert.Equal(t, int64(2*segmentOverheadBytes+20*segmentDocBytes), initial)
+	assert.Equal(t, row["store.size"], row["pri.store.size"])
+
+	// Indexing grows the store
+	data.index(0, 10)
+	grown := storeSize(catIndices())
+	assert.Greater(t, grown, initial)
+
+	// Merging the shard's segments shrinks the store and keeps every document
+	w, _ := postJSON(node.ginRouter, "/logs/_forcemerge?max_num_segments=1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	row = catIndices()
+	assert.Equal(t, grown-segmentOverheadBytes, storeSize(row))
+	assert.Equal(t, "0", row["docs.deleted"])
+	assert.Equal(t, "30", row["docs.count"])
+
+	// The text format aligns columns under a header row
+	req := httptest.NewRequest(http.MethodGet, "/_cat/indices?v", nil)
+	w = httptest.NewRecorder()
+	node.ginRouter.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "health status index uuid"), lines[0])
+	assert.Equal(t, []string{"green", "open", "logs", "logs-uuid", "2", "0", "30", "0", "4.9kb", "4.9kb"}, strings.Fields(lines[1]))
+
+	req = httptest.NewRequest(http.MethodGet, "/_cat/indices/missing", nil)
+	w = httptest.NewRecorder()
+	node.ginRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
+
+func TestFormatByteSize(t *testing.T) {
+	assert.Equal(t, "0b", formatByteSize(0))
+	assert.Equal(t, "1023b", formatByteSize(1023))
+	assert.Equal(t, "1.0kb", formatByteSize(1024))
+	assert.Equal(t, "1.5mb", formatByteSize(3<<19))
+	assert.Equal(t, "2.0gb", formatByteSize(2<<30))
+}
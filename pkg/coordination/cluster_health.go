@@ -0,0 +1,382 @@
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"go.uber.org/zap"
+)
+
+// defaultClusterHealthTimeout bounds how long a health request waits for
+// the requested status
+const defaultClusterHealthTimeout = 30 * time.Second
+
+// clusterHealthPollInterval is how often a waiting health request re-reads
+// the cluster state when the master can't be watched for shard changes
+const clusterHealthPollInterval = 100 * time.Millisecond
+
+// healthStatusRank orders health colors from best to worst
+var healthStatusRank = map[string]int{"green": 0, "yellow": 1, "red": 2}
+
+// shardTally counts the shard copies of a set of indices by state.
+// Relocating copies keep serving from their source node, so they count as
+// active as well as relocating.
+type shardTally struct {
+	activePrimaryShards int32
+	activeShards        int32
+	relocatingShards    int32
+	initializingShards  int32
+	unassignedShards    int32
+
+	// Copies that aren't serving, which make the indices red for primaries
+	// and yellow for replicas
+	inactivePrimaries int32
+	inactiveReplicas  int32
+}
+
+// addCopy counts one copy of a shard
+func (t *shardTally) addCopy(allocation *pb.ShardAllocation, isPrimary bool) {
+	if allocation == nil {
+		return
+	}
+	switch allocation.State {
+	case pb.ShardAllocation_SHARD_STATE_STARTED, pb.ShardAllocation_SHARD_STATE_RELOCATING:
+		t.activeShards++
+		if isPrimary {
+			t.activePrimaryShards++
+		}
+		if allocation.State == pb.ShardAllocation_SHARD_STATE_RELOCATING {
+			t.relocatingShards++
+		}
+		return
+	case pb.ShardAllocation_SHARD_STATE_INITIALIZING:
+		t.initializingShards++
+	case pb.ShardAllocation_SHARD_STATE_UNASSIGNED:
+		t.unassignedShards++
+	}
+	if isPrimary {
+		t.inactivePrimaries++
+	} else {
+		t.inactiveReplicas++
+	}
+}
+
+// addIndex counts every copy of an index's shards
+func (t *shardTally) addIndex(index *pb.IndexRoutingTable) {
+	for _, shard := range index.GetShards() {
+		t.addCopy(shard.Allocation, shard.IsPrimary)
+		for _, replica := range shard.Replicas {
+			t.addCopy(replica, false)
+		}
+	}
+}
+
+// status returns the health color of the tallied indices
+func (t *shardTally) status() string {
+	switch {
+	case t.inactivePrimaries > 0:
+		return "red"
+	case t.inactiveReplicas > 0:
+		return "yellow"
+	default:
+		return "green"
+	}
+}
+
+// activeShardsPercent returns the share of shard copies that are active
+func (t *shardTally) activeShardsPercent() float64 {
+	total := t.activeShards + t.initializingShards + t.unassignedShards
+	if total == 0 {
+		return 100.0
+	}
+	return float64(t.activeShards) / float64(total) * 100
+}
+
+// healthCondition is what a health request waits for
+type healthCondition struct {
+	// status is the worst acceptable color, or empty for any
+	status string
+
+	// activeShards is the number of active shard copies to wait for, or
+	// zero for any. allShards waits for every copy to be active instead.
+	activeShards int32
+	allShards    bool
+
+	// noRelocatingShards waits for no shard copy to be relocating
+	noRelocatingShards bool
+}
+
+// waitsForShards reports whether the condition waits for shard counts
+func (c healthCondition) waitsForShards() bool {
+	return c.activeShards > 0 || c.allShards || c.noRelocatingShards
+}
+
+// waits reports whether the condition asks to wait for anything
+func (c healthCondition) waits() bool {
+	return c.status != "" || c.waitsForShards()
+}
+
+// met reports whether health satisfies the condition. Shard counts are
+// never met while a requested index doesn't exist.
+func (c healthCondition) met(health *clusterHealth) bool {
+	if c.status != "" && healthStatusRank[health.status] > healthStatusRank[c.status] {
+		return false
+	}
+	if !c.waitsForShards() {
+		return true
+	}
+	if len(health.missing) > 0 {
+		return false
+	}
+	t := health.tally
+	if c.noRelocatingShards && t.relocatingShards > 0 {
+		return false
+	}
+	if c.allShards {
+		return t.initializingShards == 0 && t.unassignedShards == 0
+	}
+	return t.activeShards >= c.activeShards
+}
+
+// clusterHealth is the health of the cluster, or of some of its indices
+type clusterHealth struct {
+	state  *pb.ClusterStateResponse
+	status string
+	tally  shardTally
+
+	// missing lists requested indices that don't exist
+	missing []string
+}
+
+// computeClusterHealth tallies the shards of indices, or of every index
+// when none are named. The cluster's health is the master's; the health of
+// named indices is computed from their shards, and is red while any of them
+// doesn't exist.
+func computeClusterHealth(state *pb.ClusterStateResponse, indices []string) *clusterHealth {
+	health := &clusterHealth{state: state}
+	routing := state.GetRoutingTable().GetIndices()
+
+	if len(indices) == 0 {
+		for _, index := range routing {
+			health.tally.addIndex(index)
+		}
+		switch state.Status {
+		case pb.ClusterStatus_CLUSTER_STATUS_YELLOW:
+			health.status = "yellow"
+		case pb.ClusterStatus_CLUSTER_STATUS_RED:
+			health.status = "red"
+		default:
+			health.status = "green"
+		}
+		return health
+	}
+
+	for _, name := range indices {
+		index, exists := routing[name]
+		if !exists {
+			health.missing = append(health.missing, name)
+			continue
+		}
+		health.tally.addIndex(index)
+	}
+	health.status = health.tally.status()
+	if len(health.missing) > 0 {
+		health.status = "red"
+	}
+	return health
+}
+
+// response renders the health as a _cluster/health response
+func (h *clusterHealth) response(timedOut bool) gin.H {
+	clusterName := "quidditch-cluster"
+	if h.state.ClusterName != "" {
+		clusterName = h.state.ClusterName
+	}
+
+	numNodes := int32(len(h.state.Nodes))
+	numDataNodes := int32(0)
+	for _, node := range h.state.Nodes {
+		if node.NodeType == pb.NodeType_NODE_TYPE_DATA {
+			numDataNodes++
+		}
+	}
+
+	return gin.H{
+		"cluster_name":                     clusterName,
+		"status":                           h.status,
+		"timed_out":                        timedOut,
+		"number_of_nodes":                  numNodes,
+		"number_of_data_nodes":             numDataNodes,
+		"active_primary_shards":            h.tally.activePrimaryShards,
+		"active_shards":                    h.tally.activeShards,
+		"relocating_shards":                h.tally.relocatingShards,
+		"initializing_shards":              h.tally.initializingShards,
+		"unassigned_shards":                h.tally.unassignedShards,
+		"delayed_unassigned_shards":        0, // The master never delays allocating unassigned shards
+		"number_of_pending_tasks":          0,
+		"number_of_in_flight_fetch":        0,
+		"task_max_waiting_in_queue_millis": 0,
+		"active_shards_percent_as_number":  h.tally.activeShardsPercent(),
+	}
+}
+
+// handleClusterHealth reports the health of the cluster, or of the
+// comma-separated indices in the path. With wait_for_status it waits, up to
+// timeout, until the health is at least that good, and with
+// wait_for_active_shards until that many shard copies, or all of them, are
+// active, and with wait_for_no_relocating_shards until no copy is
+// relocating, answering 408 with timed_out set if it never gets there.
+func (c *CoordinationNode) handleClusterHealth(ctx *gin.Context) {
+	var indices []string
+	if param := ctx.Param("index"); param != "" && param != "_all" && param != "*" {
+		indices = strings.Split(param, ",")
+	}
+
+	badRequest := func(reason string) {
+		respondError(ctx, http.StatusBadRequest, "illegal_argument_exception", reason)
+	}
+
+	var condition healthCondition
+	condition.status = ctx.Query("wait_for_status")
+	if _, valid := healthStatusRank[condition.status]; condition.status != "" && !valid {
+		badRequest(fmt.Sprintf("unknown wait_for_status [%s], expected green, yellow or red", condition.status))
+		return
+	}
+	switch value := ctx.Query("wait_for_active_shards"); value {
+	case "":
+	case "all":
+		condition.allShards = true
+	default:
+		count, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || count < 0 {
+			badRequest(fmt.Sprintf("failed to parse wait_for_active_shards [%s], expected a number or all", value))
+			return
+		}
+		condition.activeShards = int32(count)
+	}
+	if value := ctx.Query("wait_for_no_relocating_shards"); value != "" {
+		wait, err := strconv.ParseBool(value)
+		if err != nil {
+			badRequest(fmt.Sprintf("failed to parse wait_for_no_relocating_shards [%s], expected true or false", value))
+			return
+		}
+		condition.noRelocatingShards = wait
+	}
+	timeout := defaultClusterHealthTimeout
+	if value := ctx.Query("timeout"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			badRequest(fmt.Sprintf("failed to parse timeout [%s]", value))
+			return
+		}
+		timeout = parsed
+	}
+
+	health, timedOut, err := c.waitForClusterHealth(ctx.Request.Context(), indices, condition, timeout)
+	if err != nil {
+		c.logger.Error("Failed to get cluster health", zap.Error(err))
+		respondError(ctx, http.StatusInternalServerError, "cluster_health_exception", fmt.Sprintf("Failed to get cluster health: %v", err))
+		return
+	}
+
+	if len(health.missing) > 0 && !condition.waits() {
+		respondAPIError(ctx, indexNotFoundError(health.missing[0]))
+		return
+	}
+
+	httpStatus := http.StatusOK
+	if timedOut {
+		httpStatus = http.StatusRequestTimeout
+	}
+	ctx.JSON(httpStatus, health.response(timedOut))
+}
+
+// waitForClusterHealth returns the health of indices once it meets
+// condition, or the last health seen and timedOut once timeout elapses. A
+// condition that doesn't wait returns the current health. The health is
+// re-read whenever the master reports a shard change, or polled if the
+// master can't be watched.
+func (c *CoordinationNode) waitForClusterHealth(ctx context.Context, indices []string, condition healthCondition, timeout time.Duration) (*clusterHealth, bool, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	var changes <-chan struct{}
+	var poll <-chan time.Time
+	if condition.waits() {
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		// Watch before the first read so no change goes unnoticed
+		changes = c.watchShardChanges(watchCtx)
+	}
+
+	var last *clusterHealth
+	for {
+		state, err := c.masterClient.GetClusterHealth(ctx)
+		if err != nil && last == nil {
+			return nil, false, err
+		}
+		if err == nil {
+			last = computeClusterHealth(state, indices)
+			if condition.met(last) {
+				return last, false, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return last, true, nil
+		case <-deadline.C:
+			return last, true, nil
+		case _, open := <-changes:
+			if !open {
+				// The watch ended; fall back to polling
+				changes = nil
+				ticker := time.NewTicker(clusterHealthPollInterval)
+				defer ticker.Stop()
+				poll = ticker.C
+			}
+		case <-poll:
+		}
+	}
+}
+
+// watchShardChanges watches the master for shard routing changes until ctx
+// is done, signalling each on the returned channel. Changes arriving before
+// the last was received are coalesced. The channel is closed when the
+// watch ends, including when it can't be opened.
+func (c *CoordinationNode) watchShardChanges(ctx context.Context) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		defer close(changes)
+
+		stream, err := c.masterClient.WatchClusterState(ctx, 0)
+		if err != nil {
+			return
+		}
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				if ctx.Err() == nil {
+					c.logger.Debug("Shard change watch ended", zap.Error(err))
+				}
+				return
+			}
+			if event.Type != pb.ClusterStateEvent_EVENT_TYPE_SHARD_ALLOCATED {
+				continue
+			}
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return changes
+}
@@ -0,0 +1,154 @@
+package coordination
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quidditch/quidditch/pkg/common/logging"
+	"go.uber.org/zap"
+)
+
+// settingLoggerLevel is the cluster setting for the level nodes log at.
+// Unset, they log at their configured level.
+const settingLoggerLevel = logging.SettingLevel
+
+// clusterSettingsRequest is the body of a PUT /_cluster/settings request.
+// Transient settings are stored like persistent ones.
+type clusterSettingsRequest struct {
+	Persistent map[string]interface{} `json:"persistent"`
+	Transient  map[string]interface{} `json:"transient"`
+}
+
+// flattenSettings flattens nested settings objects into dotted setting
+// names. Null values are collected in reset.
+func flattenSettings(prefix string, settings map[string]interface{}, values map[string]string, reset *[]string) error {
+	for key, value := range settings {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case nil:
+			*reset = append(*reset, name)
+		case map[string]interface{}:
+			if err := flattenSettings(name, v, values, reset); err != nil {
+				return err
+			}
+		case string:
+			values[name] = v
+		case bool:
+			values[name] = strconv.FormatBool(v)
+		case float64:
+			values[name] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return fmt.Errorf("setting [%s] must be a string, number, boolean or null", name)
+		}
+	}
+	return nil
+}
+
+// handleClusterSettings updates cluster settings in the master metadata.
+// A logger.level change takes effect on this node at once and on other
+// coordination nodes at their next poll of the master.
+func (c *CoordinationNode) handleClusterSettings(ctx *gin.Context) {
+	badRequest := func(reason string) {
+		respondError(ctx, http.StatusBadRequest, "illegal_argument_exception", reason)
+	}
+
+	var req clusterSettingsRequest
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+		badRequest(fmt.Sprintf("Failed to parse cluster settings: %v", err))
+		return
+	}
+
+	values := make(map[string]string)
+	var reset []string
+	for _, section := range []map[string]interface{}{req.Persistent, req.Transient} {
+		if err := flattenSettings("", section, values, &reset); err != nil {
+			badRequest(err.Error())
+			return
+		}
+	}
+
+	settings, err := c.masterClient.UpdateClusterSettings(ctx.Request.Context(), values, reset)
+	if err != nil {
+		c.logger.Error("Failed to update cluster settings", zap.Error(err))
+		respondErrorFrom(ctx, err, "cluster_settings_exception", "Failed to update cluster settings")
+		return
+	}
+	c.applyLogLevel(settings)
+
+	// Echo the applied settings in the section they were given in
+	applied := func(section map[string]interface{}) gin.H {
+		sectionValues := make(map[string]string)
+		var sectionReset []string
+		_ = flattenSettings("", section, sectionValues, &sectionReset)
+		result := gin.H{}
+		for name, value := range sectionValues {
+			result[name] = value
+		}
+		return result
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"acknowledged": true,
+		"persistent":   applied(req.Persistent),
+		"transient":    applied(req.Transient),
+	})
+}
+
+// handleGetClusterSettings returns the cluster settings
+func (c *CoordinationNode) handleGetClusterSettings(ctx *gin.Context) {
+	settings, err := c.masterClient.GetClusterSettings(ctx.Request.Context())
+	if err != nil {
+		c.logger.Error("Failed to get cluster settings", zap.Error(err))
+		respondError(ctx, http.StatusInternalServerError, "cluster_settings_exception", fmt.Sprintf("Failed to get cluster settings: %v", err))
+		return
+	}
+
+	persistent := gin.H{}
+	for name, value := range settings {
+		persistent[name] = value
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"persistent": persistent,
+		"transient":  gin.H{},
+	})
+}
+
+// SetLogLevel sets the level the node's logger logs at, letting the
+// logger.level cluster setting change it while the node runs
+func (c *CoordinationNode) SetLogLevel(level zap.AtomicLevel) {
+	c.logLevel = &level
+}
+
+// refreshLogLevel applies the logger.level cluster setting as the master
+// holds it, so a level set through another coordination node reaches this
+// one
+func (c *CoordinationNode) refreshLogLevel(ctx context.Context) {
+	if c.logLevel == nil {
+		return
+	}
+
+	settings, err := c.masterClient.GetClusterSettings(ctx)
+	if err != nil {
+		c.logger.Warn("Failed to get cluster settings", zap.Error(err))
+		return
+	}
+	c.applyLogLevel(settings)
+}
+
+// applyLogLevel sets the node's log level from the logger.level cluster
+// setting, or back to the configured level when the setting is unset
+func (c *CoordinationNode) applyLogLevel(settings map[string]string) {
+	if c.logLevel == nil {
+		return
+	}
+	logging.ApplySetting(*c.logLevel, settings, c.cfg.LogLevel, c.logger)
+}
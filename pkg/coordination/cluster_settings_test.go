@@ -0,0 +1,116 @@
+package coordination
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+)
+
+// settingsMasterServer is a master holding cluster settings
+type settingsMasterServer struct {
+	pb.UnimplementedMasterServiceServer
+
+	mu       sync.Mutex
+	settings map[string]string
+}
+
+func (m *settingsMasterServer) UpdateClusterSettings(ctx context.Context, req *pb.UpdateClusterSettingsRequest) (*pb.UpdateClusterSettingsResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, value := range req.Settings {
+		m.settings[key] = value
+	}
+	for _, key := range req.ResetSettings {
+		delete(m.settings, key)
+	}
+	return &pb.UpdateClusterSettingsResponse{Acknowledged: true, Settings: m.copySettings()}, nil
+}
+
+func (m *settingsMasterServer) GetClusterSettings(ctx context.Context, req *pb.GetClusterSettingsRequest) (*pb.GetClusterSettingsResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &pb.GetClusterSettingsResponse{Settings: m.copySettings()}, nil
+}
+
+func (m *settingsMasterServer) copySettings() map[string]string {
+	settings := make(map[string]string, len(m.settings))
+	for key, value := range m.settings {
+		settings[key] = value
+	}
+	return settings
+}
+
+func TestClusterSettingsLoggerLevel(t *testing.T) {
+	masterLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &settingsMasterServer{settings: map[string]string{}}
+	masterServer := grpc.NewServer()
+	pb.RegisterMasterServiceServer(masterServer, master)
+	go masterServer.Serve(masterLis)
+	defer masterServer.Stop()
+
+	node := newTestCoordinationNode(t)
+	node.cfg.LogLevel = "info"
+	node.masterClient = NewMasterClient(masterLis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	defer node.masterClient.Disconnect()
+
+	// The node logs through a core that drops entries below its level
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	core, logs := observer.New(level)
+	node.logger = zap.New(core)
+	node.SetLogLevel(level)
+
+	debugLogged := func() bool {
+		before := logs.FilterMessage("debug probe").Len()
+		node.logger.Debug("debug probe")
+		return logs.FilterMessage("debug probe").Len() > before
+	}
+	assert.False(t, debugLogged())
+
+	putSettings := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/_cluster/settings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	// Setting logger.level turns debug logs on without a restart
+	w := putSettings(`{"persistent": {"logger": {"level": "DEBUG"}}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, zapcore.DebugLevel, level.Level())
+	assert.True(t, debugLogged())
+	assert.Equal(t, "DEBUG", master.settings[settingLoggerLevel])
+
+	// Resetting it returns to the configured level
+	w = putSettings(`{"persistent": {"logger.level": null}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.False(t, debugLogged())
+
+	// A level set through another coordination node arrives on the next poll
+	master.mu.Lock()
+	master.settings[settingLoggerLevel] = "trace"
+	master.mu.Unlock()
+	node.refreshLogLevel(context.Background())
+	assert.True(t, debugLogged())
+
+	// Raising the level above info silences info logs too
+	w = putSettings(`{"transient": {"logger.level": "warn"}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.False(t, debugLogged())
+	node.logger.Info("info probe")
+	assert.Zero(t, logs.FilterMessage("info probe").Len())
+}
@@ -0,0 +1,147 @@
+package coordination
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// gzipWriterPool reuses gzip writers across responses
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(io.Discard)
+	},
+}
+
+// gzipResponseWriter compresses the response body. The gzip stream is only
+// started on the first write so bodiless responses (HEAD, 204, 304) stay empty.
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	writer *gzip.Writer
+}
+
+// Write compresses data into the response
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	if w.writer == nil {
+		header := w.ResponseWriter.Header()
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+
+		w.writer = gzipWriterPool.Get().(*gzip.Writer)
+		w.writer.Reset(w.ResponseWriter)
+	}
+	return w.writer.Write(data)
+}
+
+// WriteString compresses s into the response
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeader drops any precomputed Content-Length, which would describe the
+// uncompressed body
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	w.ResponseWriter.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Flush flushes compressed data to the client
+func (w *gzipResponseWriter) Flush() {
+	if w.writer != nil {
+		w.writer.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// close terminates the gzip stream, if one was started
+func (w *gzipResponseWriter) close() error {
+	if w.writer == nil {
+		return nil
+	}
+	err := w.writer.Close()
+	w.writer.Reset(io.Discard)
+	gzipWriterPool.Put(w.writer)
+	w.writer = nil
+	return err
+}
+
+// gzipMiddleware decompresses request bodies sent with Content-Encoding: gzip
+// and compresses responses for clients that send Accept-Encoding: gzip. A
+// body that decompresses to more than maxBodyBytes is rejected with a 413,
+// so a small compressed request can't expand without bound.
+func gzipMiddleware(logger *zap.Logger, maxBodyBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if headerHasToken(c.GetHeader("Content-Encoding"), "gzip") {
+			reader, err := gzip.NewReader(c.Request.Body)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, "parse_exception", fmt.Sprintf("Failed to decompress gzip request body: %v", err))
+				return
+			}
+			defer reader.Close()
+
+			// Read one byte past the limit so oversized bodies are
+			// detected without decompressing them whole
+			body, err := io.ReadAll(io.LimitReader(reader, maxBodyBytes+1))
+			if err != nil {
+				respondError(c, http.StatusBadRequest, "parse_exception", fmt.Sprintf("Failed to decompress gzip request body: %v", err))
+				return
+			}
+			if int64(len(body)) > maxBodyBytes {
+				respondError(c, http.StatusRequestEntityTooLarge, "request_entity_too_large_exception",
+					fmt.Sprintf("Decompressed request body exceeds the limit of %d bytes", maxBodyBytes))
+				return
+			}
+
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			c.Request.Header.Del("Content-Encoding")
+			c.Request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+			c.Request.ContentLength = int64(len(body))
+		}
+
+		if c.Request.Method == http.MethodHead || !headerHasToken(c.GetHeader("Accept-Encoding"), "gzip") {
+			c.Next()
+			return
+		}
+
+		// Handlers that negotiate compression themselves (e.g. /metrics)
+		// must not compress a second time
+		c.Request.Header.Del("Accept-Encoding")
+		c.Header("Vary", "Accept-Encoding")
+
+		writer := &gzipResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		defer func() {
+			if err := writer.close(); err != nil {
+				logger.Warn("Failed to finish gzip response", zap.Error(err))
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// headerHasToken reports whether a comma-separated header value lists token,
+// ignoring case and parameters such as ";q=0.8"
+func headerHasToken(value, token string) bool {
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if i := strings.Index(part, ";"); i >= 0 {
+			if strings.TrimSpace(part[i+1:]) == "q=0" {
+				continue
+			}
+			part = strings.TrimSpace(part[:i])
+		}
+		if strings.EqualFold(part, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package coordination
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestGzipMiddleware_BulkRequest(t *testing.T) {
+	node := newTestCoordinationNode(t, withCompression)
+
+	body := []byte(`{"index":{"_index":"products","_id":"1"}}
+{"title":"laptop"}
+{"delete":{"_index":"products","_id":"2"}}
+`)
+
+	req := httptest.NewRequest(http.MethodPost, "/_bulk", bytes.NewReader(gzipBytes(t, body)))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	node.ginRouter.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Items []map[string]map[string]interface{} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Items, 2)
+	assert.Equal(t, "1", response.Items[0]["index"]["_id"])
+	assert.Equal(t, "products", response.Items[0]["index"]["_index"])
+	assert.Equal(t, "2", response.Items[1]["delete"]["_id"])
+}
+
+func TestGzipMiddleware_InvalidGzipBody(t *testing.T) {
+	node := newTestCoordinationNode(t, withCompression)
+
+	req := httptest.NewRequest(http.MethodPost, "/_bulk", bytes.NewReader([]byte("not gzip")))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	node.ginRouter.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Failed to decompress gzip request body")
+}
+
+func TestGzipMiddleware_DecompressedBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(gzipMiddleware(zap.NewNop(), 1024))
+	engine.POST("/echo", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		c.Data(http.StatusOK, "text/plain", body)
+	})
+
+	post := func(body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader(gzipBytes(t, body)))
+		req.Header.Set("Content-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	// A body within the limit reaches the handler decompressed
+	w := post(bytes.Repeat([]byte("x"), 1024))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1024, w.Body.Len())
+
+	// One that expands past it is rejected, however small it compresses
+	w = post(bytes.Repeat([]byte("x"), 1<<20))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "request_entity_too_large_exception")
+	assert.Contains(t, w.Body.String(), "limit of 1024 bytes")
+}
+
+func TestGzipMiddleware_SearchResponse(t *testing.T) {
+	search := func(node *CoordinationNode, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/products/_search",
+			bytes.NewReader([]byte(`{"query": {"match": {"title": "laptop"}}}`)))
+		req.Header.Set("Content-Type", "application/json")
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Compressed", func(t *testing.T) {
+		node := newTestCoordinationNode(t, withCompression)
+
+		w := search(node, "deflate, gzip;q=0.9")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+
+		zr, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		data, err := io.ReadAll(zr)
+		require.NoError(t, err)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &response))
+		hits := response["hits"].(map[string]interface{})["hits"].([]interface{})
+		assert.Len(t, hits, 3)
+	})
+
+	t.Run("NotRequested", func(t *testing.T) {
+		node := newTestCoordinationNode(t, withCompression)
+
+		w := search(node, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.True(t, json.Valid(w.Body.Bytes()))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		node := newTestCoordinationNode(t)
+
+		w := search(node, "gzip")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.True(t, json.Valid(w.Body.Bytes()))
+	})
+}
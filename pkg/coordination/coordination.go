@@ -2,9 +2,16 @@ package coordination
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
+	"math/rand"
 	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -14,14 +21,21 @@ import (
 	"github.com/quidditch/quidditch/pkg/common/config"
 	"github.com/quidditch/quidditch/pkg/common/metrics"
 	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/common/requestid"
+	"github.com/quidditch/quidditch/pkg/common/tracing"
 	"github.com/quidditch/quidditch/pkg/coordination/bulk"
 	"github.com/quidditch/quidditch/pkg/coordination/executor"
 	"github.com/quidditch/quidditch/pkg/coordination/parser"
 	"github.com/quidditch/quidditch/pkg/coordination/pipeline"
+	"github.com/quidditch/quidditch/pkg/coordination/pipeline/stages"
 	"github.com/quidditch/quidditch/pkg/coordination/planner"
 	"github.com/quidditch/quidditch/pkg/coordination/router"
+	"github.com/quidditch/quidditch/pkg/data/suggest"
 	"github.com/quidditch/quidditch/pkg/wasm"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 // CoordinationNode represents a coordination node in the Quidditch cluster
@@ -40,6 +54,29 @@ type CoordinationNode struct {
 	dataClients   map[string]*DataNodeClient
 	dataClientsMu sync.RWMutex
 
+	// grpcTLS secures connections to master and data nodes (nil = plaintext)
+	grpcTLS *tls.Config
+
+	// authenticator validates REST API credentials (nil = auth disabled)
+	authenticator Authenticator
+
+	// authorizer enforces per-index permissions (nil = no role map configured)
+	authorizer *Authorizer
+
+	// rateLimiter throttles requests per client (nil = unlimited)
+	rateLimiter *RateLimiter
+
+	// tasks tracks long-running operations for the tasks API
+	tasks *taskManager
+
+	// logLevel is the level the node's logger logs at, which the
+	// logger.level cluster setting changes (nil = fixed level)
+	logLevel *zap.AtomicLevel
+
+	// workers tracks background goroutines that Stop must wait for
+	workers       sync.WaitGroup
+	stopDiscovery context.CancelFunc
+
 	// UDF Management
 	udfRuntime  *wasm.Runtime
 	udfRegistry *wasm.UDFRegistry
@@ -55,18 +92,49 @@ func NewCoordinationNode(cfg *config.CoordinationConfig, logger *zap.Logger) (*C
 		return nil, fmt.Errorf("logger is required")
 	}
 
+	// Build TLS credentials for master and data node connections
+	var grpcTLS *tls.Config
+	if cfg.TLS.Enabled() || cfg.TLS.CAFile != "" {
+		clientTLS, err := cfg.TLS.ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls config: %w", err)
+		}
+		grpcTLS = clientTLS
+	}
+
+	authenticator, err := newAuthenticator(cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth config: %w", err)
+	}
+
+	authorizer, err := newAuthorizer(cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth config: %w", err)
+	}
+
+	rateLimiter, err := newRateLimiter(cfg.RateLimit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate_limit config: %w", err)
+	}
+
 	// Set Gin to release mode for production
 	gin.SetMode(gin.ReleaseMode)
 	ginRouter := gin.New()
 	ginRouter.Use(gin.Recovery())
+	ginRouter.Use(tracing.Middleware())
+	ginRouter.Use(requestid.Middleware())
 	ginRouter.Use(ginLogger(logger))
 
 	// Create metrics collector
 	metricsCollector := metrics.NewMetricsCollector("coordination")
 	ginRouter.Use(metrics.HTTPMetricsMiddleware(metricsCollector))
+	if rateLimiter != nil {
+		rateLimiter.SetMetrics(metricsCollector)
+	}
 
 	// Create master client
 	masterClient := NewMasterClient(cfg.MasterAddr, logger)
+	masterClient.SetTLSConfig(grpcTLS)
 
 	// Create data clients map
 	dataClients := make(map[string]*DataNodeClient)
@@ -102,10 +170,20 @@ func NewCoordinationNode(cfg *config.CoordinationConfig, logger *zap.Logger) (*C
 	var udfRegistry *wasm.UDFRegistry
 	if wasmRuntime != nil {
 		registryConfig := &wasm.UDFRegistryConfig{
-			Runtime:         wasmRuntime,
-			DefaultPoolSize: 10,
-			EnableStats:     true,
-			Logger:          logger,
+			Runtime:          wasmRuntime,
+			DefaultPoolSize:  10,
+			EnableStats:      true,
+			StrictSignatures: true,
+			Logger:           logger,
+		}
+		// Persisted UDFs are restored before pipelines are created, so
+		// pipelines referencing them resolve after a restart
+		if cfg.DataDir != "" {
+			store, err := wasm.NewFileUDFStore(filepath.Join(cfg.DataDir, "udfs"))
+			if err != nil {
+				return nil, fmt.Errorf("failed to open UDF store: %w", err)
+			}
+			registryConfig.Store = store
 		}
 		udfRegistry, err = wasm.NewUDFRegistry(registryConfig)
 		if err != nil {
@@ -119,12 +197,28 @@ func NewCoordinationNode(cfg *config.CoordinationConfig, logger *zap.Logger) (*C
 	// Initialize Pipeline registry and executor
 	pipelineRegistry := pipeline.NewRegistry(logger)
 	pipelineExecutor := pipeline.NewExecutor(pipelineRegistry, logger)
+	var udfCaller stages.UDFCaller
+	if udfRegistry != nil {
+		// Reject pipelines that reference UDFs which aren't uploaded yet
+		pipelineRegistry.SetUDFResolver(udfRegistry)
+		udfCaller = udfRegistry
+	}
+	stageBuilder := stages.NewStageBuilder(udfCaller, logger)
+	if cfg.PythonEnabled {
+		// Inline scripts run as python subprocesses, so they are opt-in
+		stageBuilder.SetPythonRunner(stages.NewPythonRunner(nil, logger))
+	}
+	pipelineRegistry.SetStageFactory(stageBuilder)
 	logger.Info("Pipeline framework initialized successfully")
 
 	// Connect pipelines to query service
 	queryService.SetPipelineComponents(pipelineRegistry, pipelineExecutor)
 	logger.Info("Query service pipeline integration enabled")
 
+	queryService.SetAllowUnboundedRegexp(cfg.AllowUnboundedRegexp)
+	queryParser := parser.NewQueryParser()
+	queryParser.SetAllowUnboundedRegexp(cfg.AllowUnboundedRegexp)
+
 	node := &CoordinationNode{
 		cfg:              cfg,
 		logger:           logger,
@@ -134,9 +228,14 @@ func NewCoordinationNode(cfg *config.CoordinationConfig, logger *zap.Logger) (*C
 		queryPlanner:     queryPlanner,
 		queryService:     queryService,
 		docRouter:        docRouter,
-		queryParser:      parser.NewQueryParser(),
+		queryParser:      queryParser,
 		metrics:          metricsCollector,
 		dataClients:      dataClients,
+		grpcTLS:          grpcTLS,
+		authenticator:    authenticator,
+		authorizer:       authorizer,
+		rateLimiter:      rateLimiter,
+		tasks:            newTaskManager(cfg.NodeID),
 		udfRuntime:       wasmRuntime,
 		udfRegistry:      udfRegistry,
 		pipelineRegistry: pipelineRegistry,
@@ -160,6 +259,26 @@ func (c *CoordinationNode) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to connect to master: %w", err)
 	}
 
+	// Log at the level the cluster settings ask for, if any
+	c.refreshLogLevel(ctx)
+
+	// Persist pipelines in the master and restore the ones created before
+	// this node started. UDFs were restored from this node's data directory
+	// when the node was created, so pipelines referencing UDFs uploaded to
+	// another node don't resolve here; they are skipped and reported.
+	c.pipelineRegistry.SetStore(&masterPipelineStore{client: c.masterClient})
+	if err := c.pipelineRegistry.Restore(); err != nil {
+		c.logger.Warn("Failed to restore pipelines", zap.Error(err))
+	}
+	if unrestored := c.pipelineRegistry.Unrestored(); len(unrestored) > 0 {
+		names := make([]string, 0, len(unrestored))
+		for _, u := range unrestored {
+			names = append(names, u.Name)
+		}
+		c.logger.Warn("Pipelines not restored; upload their UDFs to this node and register them again",
+			zap.Strings("pipelines", names))
+	}
+
 	// Discover and register data nodes
 	if err := c.discoverDataNodes(ctx); err != nil {
 		c.logger.Warn("Failed to discover data nodes", zap.Error(err))
@@ -167,37 +286,149 @@ func (c *CoordinationNode) Start(ctx context.Context) error {
 	}
 
 	// Start continuous data node discovery in background
-	go c.continuousDataNodeDiscovery(ctx)
+	discoveryCtx, stopDiscovery := context.WithCancel(ctx)
+	c.stopDiscovery = stopDiscovery
+	c.goWorker(func() { c.continuousDataNodeDiscovery(discoveryCtx) })
 
 	// Start HTTP server
-	c.httpServer = &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", c.cfg.BindAddr, c.cfg.RESTPort),
-		Handler: c.ginRouter,
+	if err := c.startHTTPServer(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// startHTTPServer starts serving the REST API, over TLS when configured
+func (c *CoordinationNode) startHTTPServer() error {
+	c.httpServer = c.newHTTPServer()
+
+	scheme := "http"
+	if c.cfg.TLS.Enabled() {
+		tlsConfig, err := c.cfg.TLS.ServerConfig()
+		if err != nil {
+			return fmt.Errorf("invalid tls config: %w", err)
+		}
+		c.httpServer.TLSConfig = tlsConfig
+		scheme = "https"
 	}
 
+	server := c.httpServer
 	go func() {
-		if err := c.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if server.TLSConfig != nil {
+			// Certificates are already loaded into TLSConfig
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			c.logger.Error("HTTP server error", zap.Error(err))
 		}
 	}()
 
 	c.logger.Info("Coordination node started successfully",
-		zap.String("rest_api", fmt.Sprintf("http://%s:%d", c.cfg.BindAddr, c.cfg.RESTPort)))
+		zap.String("rest_api", fmt.Sprintf("%s://%s:%d", scheme, c.cfg.BindAddr, c.cfg.RESTPort)))
 
 	return nil
 }
 
-// Stop stops the coordination node
+// Default REST server limits, used when the config leaves them unset
+const (
+	defaultReadTimeout       = 60 * time.Second
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultWriteTimeout      = 60 * time.Second
+	defaultIdleTimeout       = 120 * time.Second
+	defaultMaxHeaderBytes    = 1 << 20 // 1MB
+)
+
+// newHTTPServer creates the REST server with timeouts so slow or idle
+// clients can't hold connections open indefinitely
+func (c *CoordinationNode) newHTTPServer() *http.Server {
+	durationOrDefault := func(value, fallback time.Duration) time.Duration {
+		if value > 0 {
+			return value
+		}
+		return fallback
+	}
+
+	maxHeaderBytes := c.cfg.MaxHeaderBytes
+	if maxHeaderBytes <= 0 {
+		maxHeaderBytes = defaultMaxHeaderBytes
+	}
+
+	return &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", c.cfg.BindAddr, c.cfg.RESTPort),
+		Handler:           c.ginRouter,
+		ReadTimeout:       durationOrDefault(c.cfg.ReadTimeout, defaultReadTimeout),
+		ReadHeaderTimeout: durationOrDefault(c.cfg.ReadHeaderTimeout, defaultReadHeaderTimeout),
+		WriteTimeout:      durationOrDefault(c.cfg.WriteTimeout, defaultWriteTimeout),
+		IdleTimeout:       durationOrDefault(c.cfg.IdleTimeout, defaultIdleTimeout),
+		MaxHeaderBytes:    maxHeaderBytes,
+	}
+}
+
+// defaultShutdownTimeout bounds draining when the config leaves it unset
+const defaultShutdownTimeout = 30 * time.Second
+
+// goWorker runs fn in a goroutine that Stop waits for
+func (c *CoordinationNode) goWorker(fn func()) {
+	c.workers.Add(1)
+	go func() {
+		defer c.workers.Done()
+		fn()
+	}()
+}
+
+// Stop stops the coordination node. New connections are refused right away;
+// in-flight requests and background work get up to the shutdown timeout to
+// finish before clients are closed.
 func (c *CoordinationNode) Stop(ctx context.Context) error {
 	c.logger.Info("Stopping coordination node")
 
-	// Stop HTTP server
+	timeout := c.cfg.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	drainCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Stop background discovery
+	if c.stopDiscovery != nil {
+		c.stopDiscovery()
+	}
+
+	// Stop HTTP server, waiting for in-flight requests
 	if c.httpServer != nil {
-		if err := c.httpServer.Shutdown(ctx); err != nil {
+		if err := c.httpServer.Shutdown(drainCtx); err != nil {
 			c.logger.Error("Failed to shutdown HTTP server", zap.Error(err))
 		}
 	}
 
+	// Wait for background goroutines
+	drained := make(chan struct{})
+	go func() {
+		c.workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-drainCtx.Done():
+		c.logger.Warn("Timed out waiting for background work to drain",
+			zap.Duration("timeout", timeout))
+	}
+
+	// Close data node connections
+	c.dataClientsMu.Lock()
+	for nodeID, client := range c.dataClients {
+		client.StopHealthChecks()
+		if err := client.Disconnect(); err != nil {
+			c.logger.Warn("Failed to disconnect from data node",
+				zap.String("node_id", nodeID),
+				zap.Error(err))
+		}
+	}
+	c.dataClientsMu.Unlock()
+
 	// Close WASM runtime
 	if c.udfRuntime != nil {
 		if err := c.udfRuntime.Close(); err != nil {
@@ -239,76 +470,148 @@ func (c *CoordinationNode) connectToMasterWithRetries(ctx context.Context) error
 
 // setupRoutes sets up all REST API routes
 func (c *CoordinationNode) setupRoutes() {
+	// Compression must wrap every route, so it goes in before any is registered
+	if !c.cfg.DisableCompression {
+		c.ginRouter.Use(gzipMiddleware(c.logger, c.bulkLimits().MaxBodyBytes))
+	}
+
+	// Authentication also guards every route except the allowlisted ones
+	if c.authenticator != nil {
+		c.ginRouter.Use(authMiddleware(c.authenticator, c.logger))
+	}
+
+	// Throttling comes after authentication so clients can be keyed by API key
+	if c.rateLimiter != nil {
+		c.ginRouter.Use(rateLimitMiddleware(c.rateLimiter, c.logger))
+	}
+
 	// Root endpoint
 	c.ginRouter.GET("/", c.handleRoot)
 
 	// Cluster APIs
 	c.ginRouter.GET("/_cluster/health", c.handleClusterHealth)
-	c.ginRouter.GET("/_cluster/health/:index", c.handleClusterHealth)
+	c.ginRouter.GET("/_cluster/health/:index", c.authorize(ActionRead), c.handleClusterHealth)
 	c.ginRouter.GET("/_cluster/state", c.handleClusterState)
 	c.ginRouter.GET("/_cluster/stats", c.handleClusterStats)
-	c.ginRouter.PUT("/_cluster/settings", c.handleClusterSettings)
+	c.ginRouter.PUT("/_cluster/settings", c.authorize(ActionAdmin), c.handleClusterSettings)
+	c.ginRouter.GET("/_cluster/settings", c.handleGetClusterSettings)
+	c.ginRouter.GET("/_cluster/allocation/explain", c.handleAllocationExplain)
+	c.ginRouter.POST("/_cluster/allocation/explain", c.handleAllocationExplain)
 
 	// Index Management APIs
-	c.ginRouter.PUT("/:index", c.handleCreateIndex)
-	c.ginRouter.DELETE("/:index", c.handleDeleteIndex)
-	c.ginRouter.GET("/:index", c.handleGetIndex)
-	c.ginRouter.HEAD("/:index", c.handleIndexExists)
-	c.ginRouter.POST("/:index/_open", c.handleOpenIndex)
-	c.ginRouter.POST("/:index/_close", c.handleCloseIndex)
-	c.ginRouter.POST("/:index/_refresh", c.handleRefreshIndex)
-	c.ginRouter.POST("/:index/_flush", c.handleFlushIndex)
+	c.ginRouter.PUT("/:index", c.authorize(ActionAdmin), c.handleCreateIndex)
+	c.ginRouter.DELETE("/:index", c.authorize(ActionAdmin), c.handleDeleteIndex)
+	c.ginRouter.GET("/:index", c.authorize(ActionRead), c.handleGetIndex)
+	c.ginRouter.HEAD("/:index", c.authorize(ActionRead), c.handleIndexExists)
+	c.ginRouter.POST("/:index/_open", c.authorize(ActionAdmin), c.handleOpenIndex)
+	c.ginRouter.POST("/:index/_close", c.authorize(ActionAdmin), c.handleCloseIndex)
+	c.ginRouter.POST("/:index/_refresh", c.authorize(ActionAdmin), c.handleRefreshIndex)
+	c.ginRouter.POST("/:index/_flush", c.authorize(ActionAdmin), c.handleFlushIndex)
+	c.ginRouter.POST("/:index/_forcemerge", c.authorize(ActionAdmin), c.handleForceMerge)
+	c.ginRouter.GET("/:index/_segments", c.authorize(ActionRead), c.handleGetSegments)
+	c.ginRouter.GET("/_stats", c.authorize(ActionRead), c.handleIndexStats)
+	c.ginRouter.GET("/:index/_stats", c.authorize(ActionRead), c.handleIndexStats)
+	c.ginRouter.POST("/:index/_tier/:tier", c.authorize(ActionAdmin), c.handleMigrateTier)
+	c.ginRouter.POST("/:index/_split/:target", c.authorize(ActionAdmin), c.authorizeParam(ActionAdmin, "target"), c.handleSplitIndex)
+	c.ginRouter.POST("/:index/_shrink/:target", c.authorize(ActionAdmin), c.authorizeParam(ActionAdmin, "target"), c.handleShrinkIndex)
 
 	// Mapping APIs
-	c.ginRouter.GET("/:index/_mapping", c.handleGetMapping)
-	c.ginRouter.PUT("/:index/_mapping", c.handlePutMapping)
+	c.ginRouter.GET("/:index/_mapping", c.authorize(ActionRead), c.handleGetMapping)
+	c.ginRouter.PUT("/:index/_mapping", c.authorize(ActionAdmin), c.handlePutMapping)
 
 	// Settings APIs
-	c.ginRouter.GET("/:index/_settings", c.handleGetSettings)
-	c.ginRouter.PUT("/:index/_settings", c.handlePutSettings)
+	c.ginRouter.GET("/:index/_settings", c.authorize(ActionRead), c.handleGetSettings)
+	c.ginRouter.PUT("/:index/_settings", c.authorize(ActionAdmin), c.handlePutSettings)
+
+	// Analyze APIs
+	c.ginRouter.GET("/_analyze", c.handleAnalyze)
+	c.ginRouter.POST("/_analyze", c.handleAnalyze)
+	c.ginRouter.GET("/:index/_analyze", c.authorize(ActionRead), c.handleAnalyze)
+	c.ginRouter.POST("/:index/_analyze", c.authorize(ActionRead), c.handleAnalyze)
 
 	// Document APIs
 	c.logger.Info("Registering document routes")
-	c.ginRouter.PUT("/:index/_doc/:id", c.handleIndexDocument)
+	c.ginRouter.PUT("/:index/_doc/:id", c.authorize(ActionWrite), c.handleIndexDocument)
 	c.logger.Info("Registered PUT /:index/_doc/:id route")
-	c.ginRouter.POST("/:index/_doc", c.handleIndexDocument)
-	c.ginRouter.GET("/:index/_doc/:id", c.handleGetDocument)
-	c.ginRouter.DELETE("/:index/_doc/:id", c.handleDeleteDocument)
-	c.ginRouter.POST("/:index/_update/:id", c.handleUpdateDocument)
+	c.ginRouter.POST("/:index/_doc", c.authorize(ActionWrite), c.handleIndexDocument)
+	c.ginRouter.GET("/:index/_doc/:id", c.authorize(ActionRead), c.handleGetDocument)
+	c.ginRouter.POST("/_mget", c.handleMultiGet)
+	c.ginRouter.POST("/:index/_mget", c.handleMultiGet)
+	c.ginRouter.DELETE("/:index/_doc/:id", c.authorize(ActionWrite), c.handleDeleteDocument)
+	c.ginRouter.POST("/:index/_update/:id", c.authorize(ActionWrite), c.handleUpdateDocument)
+	c.ginRouter.POST("/:index/_delete_by_query", c.authorize(ActionWrite), c.handleDeleteByQuery)
+	c.ginRouter.POST("/:index/_update_by_query", c.authorize(ActionWrite), c.handleUpdateByQuery)
+	c.ginRouter.GET("/:index/_termvectors/:id", c.authorize(ActionRead), c.handleTermVectors)
+	c.ginRouter.POST("/:index/_termvectors/:id", c.authorize(ActionRead), c.handleTermVectors)
+
+	// Tasks API
+	c.ginRouter.GET("/_tasks", c.authorize(ActionAdmin), c.handleListTasks)
+	c.ginRouter.GET("/_tasks/:id", c.authorize(ActionAdmin), c.handleGetTask)
+	c.ginRouter.POST("/_tasks/:id/_cancel", c.authorize(ActionAdmin), c.handleCancelTask)
 
 	// Bulk API
 	c.ginRouter.POST("/_bulk", c.handleBulk)
 	c.ginRouter.POST("/:index/_bulk", c.handleBulk)
 
 	// Search APIs
-	c.ginRouter.GET("/:index/_search", c.handleSearch)
-	c.ginRouter.POST("/:index/_search", c.handleSearch)
-	c.ginRouter.GET("/_search", c.handleSearch)
-	c.ginRouter.POST("/_search", c.handleSearch)
+	c.ginRouter.GET("/:index/_search", c.authorize(ActionRead), c.handleSearch)
+	c.ginRouter.POST("/:index/_search", c.authorize(ActionRead), c.handleSearch)
+	c.ginRouter.GET("/_search", c.authorize(ActionRead), c.handleSearch)
+	c.ginRouter.POST("/_search", c.authorize(ActionRead), c.handleSearch)
+
+	// Search template APIs
+	c.ginRouter.PUT("/_scripts/:id", c.authorize(ActionAdmin), c.handlePutStoredScript)
+	c.ginRouter.POST("/_scripts/:id", c.authorize(ActionAdmin), c.handlePutStoredScript)
+	c.ginRouter.GET("/_scripts/:id", c.authorize(ActionRead), c.handleGetStoredScript)
+	c.ginRouter.GET("/:index/_search/template", c.authorize(ActionRead), c.handleSearchTemplate)
+	c.ginRouter.POST("/:index/_search/template", c.authorize(ActionRead), c.handleSearchTemplate)
+	c.ginRouter.GET("/_search/template", c.authorize(ActionRead), c.handleSearchTemplate)
+	c.ginRouter.POST("/_search/template", c.authorize(ActionRead), c.handleSearchTemplate)
+
+	// Index template APIs
+	c.ginRouter.PUT("/_index_template/:name", c.authorize(ActionAdmin), c.handlePutIndexTemplate)
+	c.ginRouter.POST("/_index_template/:name", c.authorize(ActionAdmin), c.handlePutIndexTemplate)
+	c.ginRouter.GET("/_index_template", c.authorize(ActionRead), c.handleGetIndexTemplate)
+	c.ginRouter.GET("/_index_template/:name", c.authorize(ActionRead), c.handleGetIndexTemplate)
+	c.ginRouter.DELETE("/_index_template/:name", c.authorize(ActionAdmin), c.handleDeleteIndexTemplate)
+
+	// Snapshot APIs
+	c.ginRouter.PUT("/_snapshot/:repository", c.authorize(ActionAdmin), c.handlePutSnapshotRepository)
+	c.ginRouter.POST("/_snapshot/:repository", c.authorize(ActionAdmin), c.handlePutSnapshotRepository)
+	c.ginRouter.GET("/_snapshot/:repository", c.authorize(ActionAdmin), c.handleGetSnapshotRepository)
+	c.ginRouter.PUT("/_snapshot/:repository/:snapshot", c.authorize(ActionAdmin), c.handleCreateSnapshot)
+	c.ginRouter.GET("/_snapshot/:repository/:snapshot", c.authorize(ActionAdmin), c.handleGetSnapshot)
+	c.ginRouter.POST("/_snapshot/:repository/:snapshot/_restore", c.authorize(ActionAdmin), c.handleRestoreSnapshot)
 
 	// Multi-search API
-	c.ginRouter.POST("/_msearch", c.handleMultiSearch)
-	c.ginRouter.POST("/:index/_msearch", c.handleMultiSearch)
+	c.ginRouter.POST("/_msearch", c.authorize(ActionRead), c.handleMultiSearch)
+	c.ginRouter.POST("/:index/_msearch", c.authorize(ActionRead), c.handleMultiSearch)
 
 	// Count API
-	c.ginRouter.GET("/:index/_count", c.handleCount)
-	c.ginRouter.POST("/:index/_count", c.handleCount)
+	c.ginRouter.GET("/:index/_count", c.authorize(ActionRead), c.handleCount)
+	c.ginRouter.POST("/:index/_count", c.authorize(ActionRead), c.handleCount)
+
+	// Cat APIs
+	c.ginRouter.GET("/_cat/indices", c.authorize(ActionRead), c.handleCatIndices)
+	c.ginRouter.GET("/_cat/indices/:index", c.authorize(ActionRead), c.handleCatIndices)
 
 	// Nodes API
 	c.ginRouter.GET("/_nodes", c.handleNodes)
 	c.ginRouter.GET("/_nodes/stats", c.handleNodesStats)
 
+	// UDFs and pipelines apply to every index, so managing them takes
+	// admin on all of them
+	api := c.ginRouter.Group("/api/v1", c.authorize(ActionAdmin))
+
 	// UDF Management APIs
 	if c.udfRegistry != nil {
 		udfHandlers := NewUDFHandlers(c.udfRegistry, c.logger)
-		api := c.ginRouter.Group("/api/v1")
 		udfHandlers.RegisterRoutes(api)
 	}
 
 	// Pipeline Management APIs
 	if c.pipelineRegistry != nil {
 		pipelineHandlers := NewPipelineHandlers(c.pipelineRegistry, c.pipelineExecutor, c.logger)
-		api := c.ginRouter.Group("/api/v1")
 		pipelineHandlers.RegisterRoutes(api)
 	}
 
@@ -338,88 +641,6 @@ func (c *CoordinationNode) handleRoot(ctx *gin.Context) {
 	})
 }
 
-func (c *CoordinationNode) handleClusterHealth(ctx *gin.Context) {
-	// Get cluster state from master
-	state, err := c.masterClient.GetClusterHealth(ctx.Request.Context())
-	if err != nil {
-		c.logger.Error("Failed to get cluster health", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"type":   "cluster_health_exception",
-				"reason": fmt.Sprintf("Failed to get cluster health: %v", err),
-			},
-		})
-		return
-	}
-
-	// Convert cluster state to health response
-	status := "green"
-	switch state.Status {
-	case pb.ClusterStatus_CLUSTER_STATUS_GREEN:
-		status = "green"
-	case pb.ClusterStatus_CLUSTER_STATUS_YELLOW:
-		status = "yellow"
-	case pb.ClusterStatus_CLUSTER_STATUS_RED:
-		status = "red"
-	}
-
-	// Count shards from routing table
-	var activePrimaryShards, activeShards, relocatingShards, initializingShards, unassignedShards int32
-	if state.RoutingTable != nil && state.RoutingTable.Indices != nil {
-		for _, indexRouting := range state.RoutingTable.Indices {
-			for _, shard := range indexRouting.Shards {
-				if shard.Allocation == nil {
-					continue
-				}
-				switch shard.Allocation.State {
-				case pb.ShardAllocation_SHARD_STATE_STARTED:
-					activeShards++
-					if shard.IsPrimary {
-						activePrimaryShards++
-					}
-				case pb.ShardAllocation_SHARD_STATE_RELOCATING:
-					relocatingShards++
-				case pb.ShardAllocation_SHARD_STATE_INITIALIZING:
-					initializingShards++
-				case pb.ShardAllocation_SHARD_STATE_UNASSIGNED:
-					unassignedShards++
-				}
-			}
-		}
-	}
-
-	clusterName := "quidditch-cluster"
-	if state.ClusterName != "" {
-		clusterName = state.ClusterName
-	}
-
-	numNodes := int32(len(state.Nodes))
-	numDataNodes := int32(0)
-	for _, node := range state.Nodes {
-		if node.NodeType == pb.NodeType_NODE_TYPE_DATA {
-			numDataNodes++
-		}
-	}
-
-	ctx.JSON(http.StatusOK, gin.H{
-		"cluster_name":                     clusterName,
-		"status":                           status,
-		"timed_out":                        false,
-		"number_of_nodes":                  numNodes,
-		"number_of_data_nodes":             numDataNodes,
-		"active_primary_shards":            activePrimaryShards,
-		"active_shards":                    activeShards,
-		"relocating_shards":                relocatingShards,
-		"initializing_shards":              initializingShards,
-		"unassigned_shards":                unassignedShards,
-		"delayed_unassigned_shards":        0,
-		"number_of_pending_tasks":          0,
-		"number_of_in_flight_fetch":        0,
-		"task_max_waiting_in_queue_millis": 0,
-		"active_shards_percent_as_number":  100.0,
-	})
-}
-
 func (c *CoordinationNode) handleClusterState(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{
 		"cluster_name":  "quidditch-cluster",
@@ -441,14 +662,6 @@ func (c *CoordinationNode) handleClusterStats(ctx *gin.Context) {
 	})
 }
 
-func (c *CoordinationNode) handleClusterSettings(ctx *gin.Context) {
-	ctx.JSON(http.StatusOK, gin.H{
-		"acknowledged": true,
-		"persistent":   gin.H{},
-		"transient":    gin.H{},
-	})
-}
-
 func (c *CoordinationNode) handleCreateIndex(ctx *gin.Context) {
 	indexName := ctx.Param("index")
 
@@ -458,28 +671,79 @@ func (c *CoordinationNode) handleCreateIndex(ctx *gin.Context) {
 	var body map[string]interface{}
 	if err := ctx.ShouldBindJSON(&body); err != nil && err != io.EOF {
 		c.logger.Error("Failed to parse request body", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"type":   "parsing_exception",
-				"reason": fmt.Sprintf("Failed to parse request body: %v", err),
-			},
-		})
+		respondError(ctx, http.StatusBadRequest, "parsing_exception", fmt.Sprintf("Failed to parse request body: %v", err))
+		return
+	}
+
+	resp, err := c.createIndex(ctx.Request.Context(), indexName, body)
+	if err != nil {
+		c.logger.Error("Failed to create index", zap.String("index", indexName), zap.Error(err))
+		respondErrorFrom(ctx, err, "create_index_exception", "Failed to create index")
 		return
 	}
 
+	ctx.JSON(http.StatusOK, gin.H{
+		"acknowledged":        resp.Acknowledged,
+		"shards_acknowledged": true,
+		"index":               indexName,
+	})
+}
+
+// createIndex creates an index from a creation body of settings and
+// mappings, layered over the index template matching its name, and
+// associates the pipelines named in its settings
+func (c *CoordinationNode) createIndex(ctx context.Context, indexName string, body map[string]interface{}) (*pb.CreateIndexResponse, error) {
+	body, err := c.applyIndexTemplate(ctx, indexName, body)
+	if err != nil {
+		return nil, err
+	}
+
 	// Extract settings (with defaults)
 	numShards := int32(1)
 	numReplicas := int32(0)
+	var storeType string
+	var bufferSizeMB float64
+	var maxFieldValueLength, maxDocumentSize int64
+	var maxResultWindow int
 	var queryPipeline, documentPipeline, resultPipeline string
+	var analysisSettings map[string]interface{}
 
 	if settingsMap, ok := body["settings"].(map[string]interface{}); ok {
+		// Analysis is accepted with or without the index prefix
+		analysisSettings, _ = settingsMap["analysis"].(map[string]interface{})
 		if indexSettings, ok := settingsMap["index"].(map[string]interface{}); ok {
+			if value, ok := indexSettings["analysis"].(map[string]interface{}); ok {
+				analysisSettings = value
+			}
 			if shards, ok := indexSettings["number_of_shards"].(float64); ok {
 				numShards = int32(shards)
 			}
 			if replicas, ok := indexSettings["number_of_replicas"].(float64); ok {
 				numReplicas = int32(replicas)
 			}
+			if bufferSize, ok := indexSettings["buffer_size_mb"].(float64); ok {
+				bufferSizeMB = bufferSize
+			}
+			if value, ok := indexSettings["max_result_window"]; ok {
+				window, err := parseMaxResultWindow(value)
+				if err != nil {
+					return nil, status.Error(codes.InvalidArgument, err.Error())
+				}
+				maxResultWindow = window
+			}
+			if store, ok := indexSettings["store"].(map[string]interface{}); ok {
+				if value, ok := store["type"].(string); ok {
+					storeType = value
+				}
+			}
+			if mapping, ok := indexSettings["mapping"].(map[string]interface{}); ok {
+				if value, ok := mapping["max_field_value_length"].(float64); ok {
+					maxFieldValueLength = int64(value)
+				}
+				if value, ok := mapping["max_document_size_bytes"].(float64); ok {
+					maxDocumentSize = int64(value)
+				}
+			}
 
 			// Extract pipeline settings
 			if querySettings, ok := indexSettings["query"].(map[string]interface{}); ok {
@@ -502,24 +766,49 @@ func (c *CoordinationNode) handleCreateIndex(ctx *gin.Context) {
 
 	// Create index settings
 	settings := &pb.IndexSettings{
-		NumberOfShards:   numShards,
-		NumberOfReplicas: numReplicas,
+		NumberOfShards:       numShards,
+		NumberOfReplicas:     numReplicas,
+		StoreType:            storeType,
+		BufferSizeMb:         bufferSizeMB,
+		MaxFieldValueLength:  maxFieldValueLength,
+		MaxDocumentSizeBytes: maxDocumentSize,
+		MaxResultWindow:      int32(maxResultWindow),
 	}
 
-	// TODO: Parse mappings from body
-	var mappings map[string]*pb.FieldMapping
+	mappingsMap, _ := body["mappings"].(map[string]interface{})
+	mappings, err := parseMappings(mappingsMap)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	customAnalyzers, err := parseCustomAnalyzers(analysisSettings)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	customFilters, err := parseCustomFilters(analysisSettings)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	settings.Analysis, err = indexAnalysisSetting(customAnalyzers, customFilters, mappings)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	settings.NumericFields, err = indexNumericFieldsSetting(mappings)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	settings.DateFields, err = indexDateFieldsSetting(mappings)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	settings.GeoPointFields, err = indexGeoPointFieldsSetting(mappings)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 
 	// Call master to create index
-	resp, err := c.masterClient.CreateIndex(ctx.Request.Context(), indexName, settings, mappings)
+	resp, err := c.masterClient.CreateIndex(ctx, indexName, settings, mappings)
 	if err != nil {
-		c.logger.Error("Failed to create index", zap.String("index", indexName), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"type":   "create_index_exception",
-				"reason": fmt.Sprintf("Failed to create index: %v", err),
-			},
-		})
-		return
+		return nil, err
 	}
 
 	c.logger.Info("Successfully created index",
@@ -564,11 +853,7 @@ func (c *CoordinationNode) handleCreateIndex(ctx *gin.Context) {
 		}
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"acknowledged":        resp.Acknowledged,
-		"shards_acknowledged": true,
-		"index":               indexName,
-	})
+	return resp, nil
 }
 
 func (c *CoordinationNode) handleDeleteIndex(ctx *gin.Context) {
@@ -580,12 +865,7 @@ func (c *CoordinationNode) handleDeleteIndex(ctx *gin.Context) {
 	resp, err := c.masterClient.DeleteIndex(ctx.Request.Context(), indexName)
 	if err != nil {
 		c.logger.Error("Failed to delete index", zap.String("index", indexName), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"type":   "delete_index_exception",
-				"reason": fmt.Sprintf("Failed to delete index: %v", err),
-			},
-		})
+		respondError(ctx, http.StatusInternalServerError, "delete_index_exception", fmt.Sprintf("Failed to delete index: %v", err))
 		return
 	}
 
@@ -607,12 +887,7 @@ func (c *CoordinationNode) handleGetIndex(ctx *gin.Context) {
 	resp, err := c.masterClient.GetIndexMetadata(ctx.Request.Context(), indexName)
 	if err != nil {
 		c.logger.Error("Failed to get index metadata", zap.String("index", indexName), zap.Error(err))
-		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": gin.H{
-				"type":   "index_not_found_exception",
-				"reason": fmt.Sprintf("Index %s not found: %v", indexName, err),
-			},
-		})
+		respondError(ctx, http.StatusNotFound, "index_not_found_exception", fmt.Sprintf("Index %s not found: %v", indexName, err))
 		return
 	}
 
@@ -658,17 +933,6 @@ func (c *CoordinationNode) handleFlushIndex(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"_shards": gin.H{"total": 1, "successful": 1, "failed": 0}})
 }
 
-func (c *CoordinationNode) handleGetMapping(ctx *gin.Context) {
-	indexName := ctx.Param("index")
-	ctx.JSON(http.StatusOK, gin.H{
-		indexName: gin.H{"mappings": gin.H{}},
-	})
-}
-
-func (c *CoordinationNode) handlePutMapping(ctx *gin.Context) {
-	ctx.JSON(http.StatusOK, gin.H{"acknowledged": true})
-}
-
 func (c *CoordinationNode) handleGetSettings(ctx *gin.Context) {
 	indexName := ctx.Param("index")
 
@@ -677,6 +941,22 @@ func (c *CoordinationNode) handleGetSettings(ctx *gin.Context) {
 		"number_of_shards":   "1",
 		"number_of_replicas": "0",
 	}
+	if c.masterClient != nil {
+		if metadata, err := c.masterClient.GetIndexMetadata(ctx.Request.Context(), indexName); err == nil {
+			if settings := metadata.GetMetadata().GetSettings(); settings != nil {
+				indexSettings["number_of_shards"] = strconv.Itoa(int(settings.NumberOfShards))
+				indexSettings["number_of_replicas"] = strconv.Itoa(int(settings.NumberOfReplicas))
+				if settings.MaxResultWindow > 0 {
+					indexSettings["max_result_window"] = strconv.Itoa(int(settings.MaxResultWindow))
+				}
+				if threshold := settingsSlowlogThreshold(settings); threshold > 0 {
+					indexSettings["slowlog"] = gin.H{
+						"threshold": threshold.String(),
+					}
+				}
+			}
+		}
+	}
 
 	// Add pipeline settings if pipelines are associated
 	if queryPipeline, err := c.pipelineRegistry.GetPipelineForIndex(indexName, pipeline.PipelineTypeQuery); err == nil {
@@ -711,17 +991,69 @@ func (c *CoordinationNode) handlePutSettings(ctx *gin.Context) {
 	var body map[string]interface{}
 	if err := ctx.ShouldBindJSON(&body); err != nil {
 		c.logger.Error("Failed to parse request body", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"type":   "parsing_exception",
-				"reason": fmt.Sprintf("Failed to parse request body: %v", err),
-			},
-		})
+		respondError(ctx, http.StatusBadRequest, "parsing_exception", fmt.Sprintf("Failed to parse request body: %v", err))
 		return
 	}
 
 	// Extract pipeline settings
 	if settingsMap, ok := body["index"].(map[string]interface{}); ok {
+		// Validate the replica count, slow log threshold and result window
+		// before applying anything
+		replicasValue, hasReplicas := settingsMap["number_of_replicas"]
+		var replicas int32
+		if hasReplicas {
+			parsed, err := parseNumberOfReplicas(replicasValue)
+			if err != nil {
+				respondError(ctx, http.StatusBadRequest, "illegal_argument_exception", err.Error())
+				return
+			}
+			replicas = parsed
+		}
+
+		slowlogSettings, hasSlowlog := settingsMap["slowlog"].(map[string]interface{})
+		var slowlogThreshold time.Duration
+		if hasSlowlog {
+			if value, ok := slowlogSettings["threshold"]; ok {
+				threshold, err := parseSlowlogThreshold(value)
+				if err != nil {
+					respondError(ctx, http.StatusBadRequest, "illegal_argument_exception", err.Error())
+					return
+				}
+				slowlogThreshold = threshold
+			} else {
+				hasSlowlog = false
+			}
+		}
+
+		windowValue, hasWindow := settingsMap["max_result_window"]
+		var window int
+		if hasWindow {
+			parsed, err := parseMaxResultWindow(windowValue)
+			if err != nil {
+				respondError(ctx, http.StatusBadRequest, "illegal_argument_exception", err.Error())
+				return
+			}
+			window = parsed
+		}
+
+		// Update the replica count, which the master applies by adding or
+		// removing replica shards
+		if hasReplicas && !c.updateNumberOfReplicas(ctx, indexName, replicas) {
+			return
+		}
+
+		// Update the result window, which every coordination node reads
+		// from the index's settings on the master
+		if hasWindow && !c.updateMaxResultWindow(ctx, indexName, window) {
+			return
+		}
+
+		// Update the slow log threshold, which is read from the index's
+		// settings on the master the same way
+		if hasSlowlog && !c.updateSlowlogThreshold(ctx, indexName, slowlogThreshold) {
+			return
+		}
+
 		// Update query pipeline
 		if querySettings, ok := settingsMap["query"].(map[string]interface{}); ok {
 			if pipelineName, ok := querySettings["default_pipeline"].(string); ok {
@@ -730,12 +1062,7 @@ func (c *CoordinationNode) handlePutSettings(ctx *gin.Context) {
 						zap.String("index", indexName),
 						zap.String("pipeline", pipelineName),
 						zap.Error(err))
-					ctx.JSON(http.StatusBadRequest, gin.H{
-						"error": gin.H{
-							"type":   "pipeline_association_exception",
-							"reason": fmt.Sprintf("Failed to associate query pipeline: %v", err),
-						},
-					})
+					respondError(ctx, http.StatusBadRequest, "pipeline_association_exception", fmt.Sprintf("Failed to associate query pipeline: %v", err))
 					return
 				}
 				c.logger.Info("Updated query pipeline association",
@@ -752,12 +1079,7 @@ func (c *CoordinationNode) handlePutSettings(ctx *gin.Context) {
 						zap.String("index", indexName),
 						zap.String("pipeline", pipelineName),
 						zap.Error(err))
-					ctx.JSON(http.StatusBadRequest, gin.H{
-						"error": gin.H{
-							"type":   "pipeline_association_exception",
-							"reason": fmt.Sprintf("Failed to associate document pipeline: %v", err),
-						},
-					})
+					respondError(ctx, http.StatusBadRequest, "pipeline_association_exception", fmt.Sprintf("Failed to associate document pipeline: %v", err))
 					return
 				}
 				c.logger.Info("Updated document pipeline association",
@@ -774,12 +1096,7 @@ func (c *CoordinationNode) handlePutSettings(ctx *gin.Context) {
 						zap.String("index", indexName),
 						zap.String("pipeline", pipelineName),
 						zap.Error(err))
-					ctx.JSON(http.StatusBadRequest, gin.H{
-						"error": gin.H{
-							"type":   "pipeline_association_exception",
-							"reason": fmt.Sprintf("Failed to associate result pipeline: %v", err),
-						},
-					})
+					respondError(ctx, http.StatusBadRequest, "pipeline_association_exception", fmt.Sprintf("Failed to associate result pipeline: %v", err))
 					return
 				}
 				c.logger.Info("Updated result pipeline association",
@@ -805,11 +1122,19 @@ func (c *CoordinationNode) handleIndexDocument(ctx *gin.Context) {
 	// Parse document from request body
 	var document map[string]interface{}
 	if err := ctx.ShouldBindJSON(&document); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"type":   "parse_exception",
-				"reason": fmt.Sprintf("Failed to parse document: %v", err),
-			},
+		respondError(ctx, http.StatusBadRequest, "parse_exception", fmt.Sprintf("Failed to parse document: %v", err))
+		return
+	}
+
+	// Create the index on its first write, so its template's pipelines apply
+	if err := c.ensureIndexForWrite(ctx.Request.Context(), indexName); err != nil {
+		statusCode, errorType := autoCreateErrorStatus(err)
+		respondAPIError(ctx, &APIError{
+			Status:   statusCode,
+			Type:     errorType,
+			Reason:   err.Error(),
+			Metadata: gin.H{"index": indexName},
+			Err:      err,
 		})
 		return
 	}
@@ -839,19 +1164,14 @@ func (c *CoordinationNode) handleIndexDocument(ctx *gin.Context) {
 			zap.String("doc_id", docID),
 			zap.Error(err))
 
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"type":   "index_failed_exception",
-				"reason": fmt.Sprintf("Failed to index document: %v", err),
-			},
-		})
+		respondErrorFrom(ctx, err, "index_failed_exception", "Failed to index document")
 		return
 	}
 
 	// Return success response
 	result := "created"
 	statusCode := http.StatusCreated
-	if resp.Version > 1 {
+	if resp.Found {
 		result = "updated"
 		statusCode = http.StatusOK
 	}
@@ -887,12 +1207,7 @@ func (c *CoordinationNode) handleGetDocument(ctx *gin.Context) {
 			return
 		}
 
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"type":   "get_failed_exception",
-				"reason": fmt.Sprintf("Failed to get document: %v", err),
-			},
-		})
+		respondError(ctx, http.StatusInternalServerError, "get_failed_exception", fmt.Sprintf("Failed to get document: %v", err))
 		return
 	}
 
@@ -928,12 +1243,7 @@ func (c *CoordinationNode) handleDeleteDocument(ctx *gin.Context) {
 			return
 		}
 
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"type":   "delete_failed_exception",
-				"reason": fmt.Sprintf("Failed to delete document: %v", err),
-			},
-		})
+		respondError(ctx, http.StatusInternalServerError, "delete_failed_exception", fmt.Sprintf("Failed to delete document: %v", err))
 		return
 	}
 
@@ -960,6 +1270,12 @@ func (c *CoordinationNode) executeDocumentPipeline(ctx context.Context, indexNam
 		return nil, nil
 	}
 
+	return c.runDocumentPipeline(ctx, pipe, indexName, docID, document)
+}
+
+// runDocumentPipeline runs a document pipeline on a document and returns the
+// modified document
+func (c *CoordinationNode) runDocumentPipeline(ctx context.Context, pipe pipeline.Pipeline, indexName string, docID string, document map[string]interface{}) (map[string]interface{}, error) {
 	c.logger.Debug("Executing document pipeline",
 		zap.String("index", indexName),
 		zap.String("doc_id", docID),
@@ -1011,105 +1327,167 @@ func (c *CoordinationNode) handleUpdateDocument(ctx *gin.Context) {
 		Upsert         map[string]interface{} `json:"upsert"`
 	}
 	if err := ctx.ShouldBindJSON(&updateReq); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"type":   "parse_exception",
-				"reason": fmt.Sprintf("Failed to parse update request: %v", err),
-			},
-		})
+		respondError(ctx, http.StatusBadRequest, "parse_exception", fmt.Sprintf("Failed to parse update request: %v", err))
 		return
 	}
 
-	// For now, perform a full document replacement with the "doc" field
-	// TODO: Implement partial updates and scripted updates
-	document := updateReq.Doc
-	if document == nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"type":   "illegal_argument_exception",
-				"reason": "Update request must contain 'doc' field",
-			},
-		})
+	if updateReq.Doc == nil {
+		respondError(ctx, http.StatusBadRequest, "illegal_argument_exception", "Update request must contain 'doc' field")
 		return
 	}
 
-	// Route to appropriate data node
-	resp, err := c.docRouter.RouteIndexDocument(ctx.Request.Context(), indexName, docID, document)
+	// Merge the partial document into the existing one, or upsert it
+	result, version, err := c.updateDocument(ctx.Request.Context(), indexName, docID, documentUpdate{
+		Doc:         updateReq.Doc,
+		Upsert:      updateReq.Upsert,
+		DocAsUpsert: updateReq.DocAsUpsert,
+	})
+	if errors.Is(err, errDocumentMissing) {
+		respondError(ctx, http.StatusNotFound, "document_missing_exception", err.Error())
+		return
+	}
 	if err != nil {
 		c.logger.Error("Failed to update document",
 			zap.String("index", indexName),
 			zap.String("doc_id", docID),
 			zap.Error(err))
 
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"type":   "update_failed_exception",
-				"reason": fmt.Sprintf("Failed to update document: %v", err),
-			},
-		})
+		respondErrorFrom(ctx, err, "update_failed_exception", "Failed to update document")
 		return
 	}
 
 	// Return success response
-	ctx.JSON(http.StatusOK, gin.H{
+	statusCode := http.StatusOK
+	if result == "created" {
+		statusCode = http.StatusCreated
+	}
+	ctx.JSON(statusCode, gin.H{
 		"_index":   indexName,
 		"_id":      docID,
-		"_version": resp.Version,
-		"result":   "updated",
+		"_version": version,
+		"result":   result,
 		// TODO: Add shard information once proto is updated with Shards field
 	})
 }
 
+// bulkLimits returns the configured bulk request limits
+func (c *CoordinationNode) bulkLimits() bulk.Limits {
+	limits := bulk.Limits{
+		MaxBodyBytes:  bulk.DefaultMaxBodyBytes,
+		MaxOperations: bulk.DefaultMaxOperations,
+	}
+	if c.cfg != nil && c.cfg.MaxBulkBodyBytes > 0 {
+		limits.MaxBodyBytes = c.cfg.MaxBulkBodyBytes
+	}
+	if c.cfg != nil && c.cfg.MaxBulkOperations > 0 {
+		limits.MaxOperations = c.cfg.MaxBulkOperations
+	}
+	return limits
+}
+
+// bulkConcurrency returns the number of bulk operations executed in parallel
+func (c *CoordinationNode) bulkConcurrency() int {
+	if c.cfg != nil && c.cfg.BulkConcurrency > 0 {
+		return c.cfg.BulkConcurrency
+	}
+	return bulk.DefaultConcurrency
+}
+
+// bulkQueue returns the queue an operation runs on: operations on the same
+// index and id always share a queue, while operations with an
+// auto-generated id are spread round-robin
+func bulkQueue(op *bulk.BulkOperation, position, numQueues int) int {
+	if op.ID == "" {
+		return position % numQueues
+	}
+	h := fnv.New32a()
+	h.Write([]byte(op.Index))
+	h.Write([]byte{0})
+	h.Write([]byte(op.ID))
+	return int(h.Sum32() % uint32(numQueues))
+}
+
 func (c *CoordinationNode) handleBulk(ctx *gin.Context) {
 	startTime := time.Now()
+	limits := c.bulkLimits()
 
-	// Read request body
-	body, err := io.ReadAll(ctx.Request.Body)
+	// Read request body, one byte past the limit so oversized bodies are
+	// detected without buffering them whole
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, limits.MaxBodyBytes+1))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"type":   "parse_exception",
-				"reason": fmt.Sprintf("Failed to read request body: %v", err),
-			},
-		})
+		respondError(ctx, http.StatusBadRequest, "parse_exception", fmt.Sprintf("Failed to read request body: %v", err))
 		return
 	}
 
 	// Parse bulk request
-	bulkReq, err := bulk.ParseBulkRequest(body)
+	bulkReq, err := bulk.ParseBulkRequestWithLimits(body, limits)
 	if err != nil {
 		c.logger.Error("Failed to parse bulk request", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"type":   "parse_exception",
-				"reason": fmt.Sprintf("Failed to parse bulk request: %v", err),
-			},
-		})
+		statusCode := http.StatusBadRequest
+		errorType := "parse_exception"
+		if errors.Is(err, bulk.ErrBodyTooLarge) {
+			statusCode = http.StatusRequestEntityTooLarge
+			errorType = "request_entity_too_large_exception"
+		} else if errors.Is(err, bulk.ErrTooManyOperations) {
+			errorType = "illegal_argument_exception"
+		}
+		respondError(ctx, statusCode, errorType, fmt.Sprintf("Failed to parse bulk request: %v", err))
 		return
 	}
 
 	c.logger.Debug("Processing bulk request",
 		zap.Int("num_operations", len(bulkReq.Operations)))
 
-	// Process operations in parallel with limited concurrency
+	// Process operations in parallel on a fixed number of queues. All
+	// operations on a document share a queue and run in submission order,
+	// so a create followed by an update of the same id can't be reordered.
 	response := bulk.NewBulkResponse()
 	results := make([]*bulkOperationResult, len(bulkReq.Operations))
 	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, 10) // Limit concurrent operations to 10
+	queues := make([][]int, c.bulkConcurrency())
+	identity, _ := identityFromContext(ctx)
+	autoCreateErrors := make(map[string]error)
 
 	for i, op := range bulkReq.Operations {
+		// Each operation may target a different index, so permissions are
+		// checked per item rather than on the route
+		if c.authorizer != nil && !c.authorizer.Allowed(identity, ActionWrite, op.Index) {
+			results[i] = forbiddenBulkResult(op, identity)
+			continue
+		}
+
+		// Create each missing index once, before any writes to it
+		if op.Type == bulk.OperationIndex || op.Type == bulk.OperationCreate {
+			err, checked := autoCreateErrors[op.Index]
+			if !checked {
+				err = c.ensureIndexForWrite(ctx.Request.Context(), op.Index)
+				autoCreateErrors[op.Index] = err
+			}
+			if err != nil {
+				results[i] = autoCreateFailedBulkResult(op, err)
+				continue
+			}
+		}
+
+		q := bulkQueue(op, i, len(queues))
+		queues[q] = append(queues[q], i)
+	}
+
+	for _, queue := range queues {
+		if len(queue) == 0 {
+			continue
+		}
+
 		wg.Add(1)
-		go func(idx int, operation *bulk.BulkOperation) {
+		c.workers.Add(1)
+		go func(queue []int) {
+			defer c.workers.Done()
 			defer wg.Done()
 
-			// Acquire semaphore
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			// Execute operation
-			result := c.executeBulkOperation(ctx.Request.Context(), operation)
-			results[idx] = result
-		}(i, op)
+			for _, idx := range queue {
+				results[idx] = c.executeBulkOperation(ctx.Request.Context(), bulkReq.Operations[idx])
+			}
+		}(queue)
 	}
 
 	// Wait for all operations to complete
@@ -1135,6 +1513,38 @@ type bulkOperationResult struct {
 	itemResult *bulk.BulkItemResult
 }
 
+// forbiddenBulkResult rejects a bulk operation the caller may not perform
+func forbiddenBulkResult(op *bulk.BulkOperation, identity *Identity) *bulkOperationResult {
+	return &bulkOperationResult{
+		itemResult: &bulk.BulkItemResult{
+			Index:  op.Index,
+			ID:     op.ID,
+			Status: http.StatusForbidden,
+			Error: &bulk.BulkItemError{
+				Type:   "security_exception",
+				Reason: forbiddenReason(identity, ActionWrite, op.Index),
+			},
+		},
+	}
+}
+
+// autoCreateFailedBulkResult fails a bulk write to an index that is missing
+// and could not be auto-created
+func autoCreateFailedBulkResult(op *bulk.BulkOperation, err error) *bulkOperationResult {
+	statusCode, errorType := autoCreateErrorStatus(err)
+	return &bulkOperationResult{
+		itemResult: &bulk.BulkItemResult{
+			Index:  op.Index,
+			ID:     op.ID,
+			Status: statusCode,
+			Error: &bulk.BulkItemError{
+				Type:   errorType,
+				Reason: err.Error(),
+			},
+		},
+	}
+}
+
 // executeBulkOperation executes a single bulk operation
 func (c *CoordinationNode) executeBulkOperation(ctx context.Context, op *bulk.BulkOperation) *bulkOperationResult {
 	result := &bulkOperationResult{
@@ -1159,9 +1569,16 @@ func (c *CoordinationNode) executeBulkOperation(ctx context.Context, op *bulk.Bu
 				Type:   "index_failed_exception",
 				Reason: err.Error(),
 			}
+			// Documents the data node rejected, such as oversized ones,
+			// keep the status they were rejected with
+			if classified := classifyError(err); classified != nil {
+				result.itemResult.Status = classified.Status
+				result.itemResult.Error.Type = classified.Type
+				result.itemResult.Error.Reason = classified.Reason
+			}
 		} else {
 			result.itemResult.Status = http.StatusCreated
-			if op.Type == bulk.OperationIndex && resp.Version > 1 {
+			if resp.Found {
 				result.itemResult.Status = http.StatusOK
 				result.itemResult.Result = "updated"
 			} else {
@@ -1177,14 +1594,28 @@ func (c *CoordinationNode) executeBulkOperation(ctx context.Context, op *bulk.Bu
 		}
 
 	case bulk.OperationUpdate:
-		// Update document
-		document := op.UpdateDoc
-		if document == nil {
-			document = op.Document
+		// Update document with the same semantics as the _update API
+		if op.UpdateDoc == nil {
+			result.itemResult.Status = http.StatusBadRequest
+			result.itemResult.Error = &bulk.BulkItemError{
+				Type:   "action_request_validation_exception",
+				Reason: "Validation Failed: 1: doc is missing;",
+			}
+			break
 		}
 
-		resp, err := c.docRouter.RouteIndexDocument(ctx, op.Index, op.ID, document)
-		if err != nil {
+		updateResult, version, err := c.updateDocument(ctx, op.Index, op.ID, documentUpdate{
+			Doc:         op.UpdateDoc,
+			Upsert:      op.Upsert,
+			DocAsUpsert: op.DocAsUpsert,
+		})
+		if errors.Is(err, errDocumentMissing) {
+			result.itemResult.Status = http.StatusNotFound
+			result.itemResult.Error = &bulk.BulkItemError{
+				Type:   "document_missing_exception",
+				Reason: err.Error(),
+			}
+		} else if err != nil {
 			c.logger.Error("Bulk update operation failed",
 				zap.String("index", op.Index),
 				zap.String("doc_id", op.ID),
@@ -1195,10 +1626,20 @@ func (c *CoordinationNode) executeBulkOperation(ctx context.Context, op *bulk.Bu
 				Type:   "update_failed_exception",
 				Reason: err.Error(),
 			}
+			// Writes the data node can't perform keep the status it
+			// rejected them with
+			if classified := classifyError(err); classified != nil {
+				result.itemResult.Status = classified.Status
+				result.itemResult.Error.Type = classified.Type
+				result.itemResult.Error.Reason = classified.Reason
+			}
 		} else {
 			result.itemResult.Status = http.StatusOK
-			result.itemResult.Result = "updated"
-			result.itemResult.Version = resp.Version
+			if updateResult == "created" {
+				result.itemResult.Status = http.StatusCreated
+			}
+			result.itemResult.Result = updateResult
+			result.itemResult.Version = version
 			// TODO: Add shard information once proto is updated with Shards field
 			// result.itemResult.Shards = &bulk.BulkItemShards{
 			// 	Total:      1,
@@ -1267,38 +1708,23 @@ func (c *CoordinationNode) handleSearch(ctx *gin.Context) {
 	// Read request body
 	body, err := io.ReadAll(ctx.Request.Body)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"type":   "parse_exception",
-				"reason": fmt.Sprintf("Failed to read request body: %v", err),
-			},
-		})
+		respondError(ctx, http.StatusBadRequest, "parse_exception", fmt.Sprintf("Failed to read request body: %v", err))
 		return
 	}
 
+	c.search(ctx, indexName, body, startTime)
+}
+
+// search executes a search request body against an index and writes the
+// search response
+func (c *CoordinationNode) search(ctx *gin.Context, indexName string, body []byte, startTime time.Time) {
 	// Execute search using the complete planner pipeline
 	result, err := c.queryService.ExecuteSearch(ctx.Request.Context(), indexName, body)
 	if err != nil {
-		// Determine error type
-		errorType := "search_exception"
-		statusCode := http.StatusInternalServerError
-
-		// Check if it's a parsing/validation error
-		if strings.Contains(err.Error(), "parse") || strings.Contains(err.Error(), "validation") {
-			errorType = "parsing_exception"
-			statusCode = http.StatusBadRequest
-		}
-
 		c.logger.Error("Search failed",
 			zap.String("index", indexName),
 			zap.Error(err))
-
-		ctx.JSON(statusCode, gin.H{
-			"error": gin.H{
-				"type":   errorType,
-				"reason": err.Error(),
-			},
-		})
+		respondErrorFrom(ctx, err, "search_exception", "Search failed")
 		return
 	}
 
@@ -1323,22 +1749,34 @@ func (c *CoordinationNode) convertSearchResultToResponse(result *SearchResult) g
 	// Convert hits
 	hits := make([]gin.H, 0, len(result.Hits))
 	for _, hit := range result.Hits {
-		hits = append(hits, gin.H{
-			"_id":     hit.ID,
-			"_score":  hit.Score,
-			"_source": hit.Source,
-		})
+		hits = append(hits, searchHitToResponse(hit))
+	}
+
+	shards := gin.H{
+		"total":      result.Shards.Total,
+		"successful": result.Shards.Successful,
+		"skipped":    result.Shards.Skipped,
+		"failed":     result.Shards.Failed,
+	}
+	if len(result.Shards.Failures) > 0 {
+		failures := make([]gin.H, 0, len(result.Shards.Failures))
+		for _, failure := range result.Shards.Failures {
+			failures = append(failures, gin.H{
+				"index": failure.Index,
+				"shard": failure.Shard,
+				"reason": gin.H{
+					"type":   "search_exception",
+					"reason": failure.Reason,
+				},
+			})
+		}
+		shards["failures"] = failures
 	}
 
 	response := gin.H{
 		"took":      result.TookMillis,
-		"timed_out": false,
-		"_shards": gin.H{
-			"total":      result.Shards.Total,
-			"successful": result.Shards.Successful,
-			"skipped":    result.Shards.Skipped,
-			"failed":     result.Shards.Failed,
-		},
+		"timed_out": result.TimedOut,
+		"_shards":   shards,
 		"hits": gin.H{
 			"total": gin.H{
 				"value":    result.TotalHits,
@@ -1348,6 +1786,9 @@ func (c *CoordinationNode) convertSearchResultToResponse(result *SearchResult) g
 			"hits":      hits,
 		},
 	}
+	if result.TerminatedEarly {
+		response["terminated_early"] = true
+	}
 
 	// Add aggregations if present
 	if len(result.Aggregations) > 0 {
@@ -1358,9 +1799,78 @@ func (c *CoordinationNode) convertSearchResultToResponse(result *SearchResult) g
 		response["aggregations"] = aggregations
 	}
 
+	if result.Suggest != nil {
+		response["suggest"] = suggestToResponse(result.Suggest)
+	}
+
+	return response
+}
+
+// suggestToResponse renders the suggestions of each suggester. Completions
+// carry the document they came from; term suggestions carry the term's
+// document frequency.
+func suggestToResponse(suggestions map[string][]suggest.Entry) gin.H {
+	response := make(gin.H, len(suggestions))
+	for name, entries := range suggestions {
+		rendered := make([]gin.H, 0, len(entries))
+		for _, entry := range entries {
+			options := make([]gin.H, 0, len(entry.Options))
+			for _, option := range entry.Options {
+				if option.ID != "" {
+					options = append(options, gin.H{
+						"text":    option.Text,
+						"_id":     option.ID,
+						"_score":  option.Score,
+						"_source": option.Source,
+					})
+					continue
+				}
+				options = append(options, gin.H{
+					"text":  option.Text,
+					"score": option.Score,
+					"freq":  option.Freq,
+				})
+			}
+			rendered = append(rendered, gin.H{
+				"text":    entry.Text,
+				"offset":  entry.Offset,
+				"length":  entry.Length,
+				"options": options,
+			})
+		}
+		response[name] = rendered
+	}
 	return response
 }
 
+// searchHitToResponse renders a search hit, including its collapsed group's
+// inner hits
+func searchHitToResponse(hit *SearchHit) gin.H {
+	h := gin.H{
+		"_id":     hit.ID,
+		"_score":  hit.Score,
+		"_source": hit.Source,
+	}
+	if len(hit.Sort) > 0 {
+		h["sort"] = hit.Sort
+	}
+	if len(hit.MatchedQueries) > 0 {
+		h["matched_queries"] = hit.MatchedQueries
+	}
+	if len(hit.InnerHits) > 0 {
+		innerHits := make(gin.H, len(hit.InnerHits))
+		for name, group := range hit.InnerHits {
+			groupHits := make([]gin.H, 0, len(group))
+			for _, innerHit := range group {
+				groupHits = append(groupHits, searchHitToResponse(innerHit))
+			}
+			innerHits[name] = gin.H{"hits": gin.H{"hits": groupHits}}
+		}
+		h["inner_hits"] = innerHits
+	}
+	return h
+}
+
 // convertAggregationToResponse converts AggregationResult to response format
 func (c *CoordinationNode) convertAggregationToResponse(agg *AggregationResult) gin.H {
 	result := gin.H{}
@@ -1418,12 +1928,7 @@ func (c *CoordinationNode) handleCount(ctx *gin.Context) {
 	// Read request body (optional query)
 	body, err := io.ReadAll(ctx.Request.Body)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"type":   "parse_exception",
-				"reason": fmt.Sprintf("Failed to read request body: %v", err),
-			},
-		})
+		respondError(ctx, http.StatusBadRequest, "parse_exception", fmt.Sprintf("Failed to read request body: %v", err))
 		return
 	}
 
@@ -1442,18 +1947,34 @@ func (c *CoordinationNode) handleCount(ctx *gin.Context) {
 		c.logger.Error("Count execution failed",
 			zap.String("index", indexName),
 			zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"type":   "count_exception",
-				"reason": fmt.Sprintf("Count execution failed: %v", err),
-			},
-		})
+		respondError(ctx, http.StatusInternalServerError, "count_exception", fmt.Sprintf("Count execution failed: %v", err))
 		return
 	}
 
+	shards := gin.H{
+		"total":      count.Shards.Total,
+		"successful": count.Shards.Successful,
+		"skipped":    0,
+		"failed":     count.Shards.Failed,
+	}
+	if len(count.Shards.Failures) > 0 {
+		failures := make([]gin.H, 0, len(count.Shards.Failures))
+		for _, failure := range count.Shards.Failures {
+			failures = append(failures, gin.H{
+				"index": failure.Index,
+				"shard": failure.Shard,
+				"reason": gin.H{
+					"type":   "count_exception",
+					"reason": failure.Reason,
+				},
+			})
+		}
+		shards["failures"] = failures
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
-		"count":   count,
-		"_shards": gin.H{"total": 1, "successful": 1, "skipped": 0, "failed": 0},
+		"count":   count.Count,
+		"_shards": shards,
 	})
 }
 
@@ -1476,6 +1997,7 @@ func (c *CoordinationNode) handleNodesStats(ctx *gin.Context) {
 func (c *CoordinationNode) handleHealthCheck(ctx *gin.Context) {
 	// Check if master client is connected
 	healthy := true
+	status := "green"
 	checks := gin.H{
 		"master_connection": "ok",
 		"query_executor":    "ok",
@@ -1486,12 +2008,32 @@ func (c *CoordinationNode) handleHealthCheck(ctx *gin.Context) {
 	_, err := c.masterClient.GetClusterHealth(ctx.Request.Context())
 	if err != nil {
 		healthy = false
+		status = "yellow"
 		checks["master_connection"] = "failed"
 	}
 
-	status := "green"
-	if !healthy {
+	// Queries fan out to data nodes, so a node that reaches none of them
+	// can't serve any and should be routed away from
+	total, unreachable := c.probeDataNodes(ctx.Request.Context())
+	probed := min(total, healthCheckSampleSize)
+	switch {
+	case total == 0:
+		checks["query_executor"] = "no_data_nodes"
 		status = "yellow"
+	case len(unreachable) == probed:
+		checks["query_executor"] = "failed"
+		healthy = false
+		status = "red"
+	case len(unreachable) > 0:
+		checks["query_executor"] = "degraded"
+		if status == "green" {
+			status = "yellow"
+		}
+	}
+	checks["data_nodes"] = gin.H{
+		"total":       total,
+		"probed":      probed,
+		"unreachable": unreachable,
 	}
 
 	httpStatus := http.StatusOK
@@ -1505,6 +2047,49 @@ func (c *CoordinationNode) handleHealthCheck(ctx *gin.Context) {
 	})
 }
 
+// healthCheckSampleSize is the number of data nodes the health endpoint
+// probes, so its cost doesn't grow with the cluster
+const healthCheckSampleSize = 5
+
+// probeDataNodes pings a random sample of the registered data nodes in
+// parallel and returns how many are registered and the ids of the sampled
+// ones that didn't answer, sorted
+func (c *CoordinationNode) probeDataNodes(ctx context.Context) (int, []string) {
+	c.dataClientsMu.RLock()
+	clients := make([]*DataNodeClient, 0, len(c.dataClients))
+	for _, client := range c.dataClients {
+		clients = append(clients, client)
+	}
+	c.dataClientsMu.RUnlock()
+
+	rand.Shuffle(len(clients), func(i, j int) {
+		clients[i], clients[j] = clients[j], clients[i]
+	})
+	sample := clients[:min(len(clients), healthCheckSampleSize)]
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	unreachable := []string{}
+	for _, client := range sample {
+		wg.Add(1)
+		go func(client *DataNodeClient) {
+			defer wg.Done()
+			if err := client.Ping(ctx); err != nil {
+				c.logger.Warn("Data node failed health probe",
+					zap.String("node_id", client.NodeID()),
+					zap.Error(err))
+				mu.Lock()
+				unreachable = append(unreachable, client.NodeID())
+				mu.Unlock()
+			}
+		}(client)
+	}
+	wg.Wait()
+
+	sort.Strings(unreachable)
+	return len(clients), unreachable
+}
+
 // discoverDataNodes discovers data nodes from master and registers them with query executor
 func (c *CoordinationNode) discoverDataNodes(ctx context.Context) error {
 	c.logger.Info("Discovering data nodes from master")
@@ -1526,6 +2111,8 @@ func (c *CoordinationNode) discoverDataNodes(ctx context.Context) error {
 
 			// Create data node client
 			dataClient := NewDataNodeClient(node.NodeId, address, c.logger)
+			dataClient.SetTLSConfig(c.grpcTLS)
+			dataClient.SetMetrics(c.metrics)
 
 			// Store in coordination node
 			c.dataClientsMu.Lock()
@@ -1534,6 +2121,7 @@ func (c *CoordinationNode) discoverDataNodes(ctx context.Context) error {
 
 			// Register with query executor
 			c.queryExecutor.RegisterDataNode(dataClient)
+			dataClient.StartHealthChecks(dataNodeHealthCheckInterval)
 
 			// Add to interface map for document router
 			dataClientInterfaces[node.NodeId] = dataClient
@@ -1554,8 +2142,14 @@ func (c *CoordinationNode) discoverDataNodes(ctx context.Context) error {
 	return nil
 }
 
-// continuousDataNodeDiscovery periodically discovers new data nodes joining the cluster
+// continuousDataNodeDiscovery tracks data nodes joining and leaving the
+// cluster. Membership changes arrive through a cluster state watch; polling
+// the master every 30s remains as a fallback for missed events and masters
+// that don't support watches. The poll also picks up log level changes
+// made through other coordination nodes.
 func (c *CoordinationNode) continuousDataNodeDiscovery(ctx context.Context) {
+	c.goWorker(func() { c.watchDataNodes(ctx) })
+
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
@@ -1568,11 +2162,93 @@ func (c *CoordinationNode) continuousDataNodeDiscovery(ctx context.Context) {
 			return
 		case <-ticker.C:
 			c.refreshDataNodeClients(ctx)
+			c.refreshLogLevel(ctx)
+		}
+	}
+}
+
+// clusterStateWatchRetry is the delay before re-opening a broken cluster state watch
+const clusterStateWatchRetry = 5 * time.Second
+
+// dataNodeHealthCheckInterval is how often each data node client is probed
+const dataNodeHealthCheckInterval = 5 * time.Second
+
+// clusterStateEventStream is the receiving side of a cluster state watch
+type clusterStateEventStream interface {
+	Recv() (*pb.ClusterStateEvent, error)
+}
+
+// watchDataNodes keeps a cluster state watch open and applies membership
+// changes as they arrive, re-opening the watch when the stream breaks
+func (c *CoordinationNode) watchDataNodes(ctx context.Context) {
+	for {
+		stream, err := c.masterClient.WatchClusterState(ctx, 0)
+		if err == nil {
+			c.logger.Info("Watching cluster state for data node changes")
+			err = c.consumeClusterStateEvents(ctx, stream)
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		if status.Code(err) == codes.Unimplemented {
+			c.logger.Warn("Master does not support cluster state watches, relying on polling")
+			return
+		}
+		c.logger.Warn("Cluster state watch interrupted, retrying",
+			zap.Duration("retry_in", clusterStateWatchRetry),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(clusterStateWatchRetry):
+		}
+	}
+}
+
+// consumeClusterStateEvents applies events from stream until it fails
+func (c *CoordinationNode) consumeClusterStateEvents(ctx context.Context, stream clusterStateEventStream) error {
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return fmt.Errorf("cluster state watch closed by master")
 		}
+		if err != nil {
+			return err
+		}
+		c.applyClusterStateEvent(ctx, event)
 	}
 }
 
-// refreshDataNodeClients discovers new data nodes and registers them with the query executor
+// applyClusterStateEvent adds or removes the data node client named by a
+// membership event. Other event types are ignored.
+func (c *CoordinationNode) applyClusterStateEvent(ctx context.Context, event *pb.ClusterStateEvent) {
+	if event.Type != pb.ClusterStateEvent_EVENT_TYPE_NODE_JOINED &&
+		event.Type != pb.ClusterStateEvent_EVENT_TYPE_NODE_LEFT {
+		return
+	}
+
+	var node pb.NodeInfo
+	if err := proto.Unmarshal(event.Payload, &node); err != nil {
+		c.logger.Warn("Ignoring malformed cluster state event",
+			zap.String("type", event.Type.String()),
+			zap.Error(err))
+		return
+	}
+	if node.NodeType != pb.NodeType_NODE_TYPE_DATA {
+		return
+	}
+
+	if event.Type == pb.ClusterStateEvent_EVENT_TYPE_NODE_JOINED {
+		c.addDataNodeClient(ctx, &node)
+	} else {
+		c.removeDataNodeClient(node.NodeId)
+	}
+}
+
+// refreshDataNodeClients reconciles data node clients with the cluster state
+// held by the master
 func (c *CoordinationNode) refreshDataNodeClients(ctx context.Context) {
 	c.logger.Debug("Refreshing data node clients")
 
@@ -1583,67 +2259,145 @@ func (c *CoordinationNode) refreshDataNodeClients(ctx context.Context) {
 		return
 	}
 
-	// Track newly discovered nodes
-	newNodes := 0
+	c.reconcileDataNodeClients(ctx, state)
+}
 
+// reconcileDataNodeClients makes the data node clients match state: clients
+// are created for new data nodes, and clients of nodes absent from state are
+// closed and unregistered so queries are no longer routed to them
+func (c *CoordinationNode) reconcileDataNodeClients(ctx context.Context, state *pb.ClusterStateResponse) {
 	// Register any new data node clients
+	newNodes := 0
+	current := make(map[string]bool)
 	for _, node := range state.Nodes {
 		if node.NodeType != pb.NodeType_NODE_TYPE_DATA {
 			continue
 		}
+		current[node.NodeId] = true
 
-		nodeID := node.NodeId
-
-		// Check if client already exists
-		c.dataClientsMu.RLock()
-		_, exists := c.dataClients[nodeID]
-		c.dataClientsMu.RUnlock()
+		if c.addDataNodeClient(ctx, node) {
+			newNodes++
+		}
+	}
 
-		if exists {
-			continue // Already registered
+	// Drop clients of nodes that are no longer in the cluster
+	c.dataClientsMu.RLock()
+	var departed []string
+	for nodeID := range c.dataClients {
+		if !current[nodeID] {
+			departed = append(departed, nodeID)
 		}
+	}
+	c.dataClientsMu.RUnlock()
 
-		// Construct data node address
-		address := fmt.Sprintf("%s:%d", node.BindAddr, node.GrpcPort)
+	for _, nodeID := range departed {
+		c.removeDataNodeClient(nodeID)
+	}
 
-		// Create data node client
-		dataClient := NewDataNodeClient(nodeID, address, c.logger)
+	if newNodes > 0 || len(departed) > 0 {
+		c.logger.Info("Data node membership changed",
+			zap.Int("joined", newNodes),
+			zap.Int("left", len(departed)))
+	}
+}
 
-		// Connect to the new data node
-		if err := dataClient.Connect(ctx); err != nil {
-			c.logger.Error("Failed to connect to new data node",
-				zap.String("node_id", nodeID),
-				zap.String("address", address),
-				zap.Error(err))
-			continue
+// addDataNodeClient connects to a data node and registers it with the query
+// executor and document router. A node that rejoined on a new address has
+// its old client replaced. It returns false if the node already has a client
+// or can't be reached.
+func (c *CoordinationNode) addDataNodeClient(ctx context.Context, node *pb.NodeInfo) bool {
+	nodeID := node.NodeId
+
+	// Construct data node address
+	address := fmt.Sprintf("%s:%d", node.BindAddr, node.GrpcPort)
+
+	// Check if client already exists
+	c.dataClientsMu.RLock()
+	existing, exists := c.dataClients[nodeID]
+	c.dataClientsMu.RUnlock()
+
+	if exists {
+		if existing.Address() == address {
+			return false
 		}
+		c.logger.Info("Data node address changed",
+			zap.String("node_id", nodeID),
+			zap.String("old_address", existing.Address()),
+			zap.String("new_address", address))
+		c.removeDataNodeClient(nodeID)
+	}
+
+	// Create data node client
+	dataClient := NewDataNodeClient(nodeID, address, c.logger)
+	dataClient.SetTLSConfig(c.grpcTLS)
+	dataClient.SetMetrics(c.metrics)
+
+	// Connect to the new data node
+	if err := dataClient.Connect(ctx); err != nil {
+		c.logger.Error("Failed to connect to new data node",
+			zap.String("node_id", nodeID),
+			zap.String("address", address),
+			zap.Error(err))
+		return false
+	}
 
-		// Store in coordination node
-		c.dataClientsMu.Lock()
-		c.dataClients[nodeID] = dataClient
+	// Store in coordination node, unless the watch and the poller raced
+	// to add the same node
+	c.dataClientsMu.Lock()
+	if _, exists := c.dataClients[nodeID]; exists {
 		c.dataClientsMu.Unlock()
+		dataClient.Disconnect()
+		return false
+	}
+	c.dataClients[nodeID] = dataClient
+	c.dataClientsMu.Unlock()
 
-		// Register with query executor
-		c.queryExecutor.RegisterDataNode(dataClient)
+	// Register with query executor and document router
+	c.queryExecutor.RegisterDataNode(dataClient)
+	c.updateDocRouterClients()
+	dataClient.StartHealthChecks(dataNodeHealthCheckInterval)
 
-		// Update document router
-		c.dataClientsMu.RLock()
-		dataClientInterfaces := make(map[string]router.DataNodeClient)
-		for id, client := range c.dataClients {
-			dataClientInterfaces[id] = client
-		}
-		c.dataClientsMu.RUnlock()
-		c.docRouter.SetDataClients(dataClientInterfaces)
+	c.logger.Info("Registered new data node",
+		zap.String("node_id", nodeID),
+		zap.String("address", address))
+	return true
+}
+
+// removeDataNodeClient unregisters and disconnects the client of a data node
+// that left the cluster
+func (c *CoordinationNode) removeDataNodeClient(nodeID string) {
+	c.dataClientsMu.Lock()
+	dataClient, exists := c.dataClients[nodeID]
+	delete(c.dataClients, nodeID)
+	c.dataClientsMu.Unlock()
+
+	if !exists {
+		return
+	}
 
-		c.logger.Info("Registered new data node",
+	c.queryExecutor.UnregisterDataNode(nodeID)
+	c.updateDocRouterClients()
+
+	dataClient.StopHealthChecks()
+	if err := dataClient.Disconnect(); err != nil {
+		c.logger.Warn("Error disconnecting from removed data node",
 			zap.String("node_id", nodeID),
-			zap.String("address", address))
-		newNodes++
+			zap.Error(err))
 	}
 
-	if newNodes > 0 {
-		c.logger.Info("Discovered new data nodes", zap.Int("count", newNodes))
+	c.logger.Info("Removed data node", zap.String("node_id", nodeID))
+}
+
+// updateDocRouterClients hands the current data node clients to the document router
+func (c *CoordinationNode) updateDocRouterClients() {
+	c.dataClientsMu.RLock()
+	dataClientInterfaces := make(map[string]router.DataNodeClient, len(c.dataClients))
+	for id, client := range c.dataClients {
+		dataClientInterfaces[id] = client
 	}
+	c.dataClientsMu.RUnlock()
+
+	c.docRouter.SetDataClients(dataClientInterfaces)
 }
 
 // ginLogger creates a Gin middleware that logs requests using zap
@@ -1662,6 +2416,7 @@ func ginLogger(logger *zap.Logger) gin.HandlerFunc {
 			zap.Int("status", c.Writer.Status()),
 			zap.Duration("latency", time.Since(start)),
 			zap.String("client_ip", c.ClientIP()),
+			zap.String("request_id", requestid.FromContext(c.Request.Context())),
 		)
 	}
 }
@@ -0,0 +1,89 @@
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quidditch/quidditch/pkg/coordination/parser"
+)
+
+// splitIndices splits a comma-separated index list, dropping empty names
+func splitIndices(indexName string) []string {
+	var indices []string
+	for _, name := range strings.Split(indexName, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			indices = append(indices, name)
+		}
+	}
+	return indices
+}
+
+// searchIndices searches each index on its own and merges the hits by score,
+// after multiplying each index's scores by its indices_boost. Every index
+// returns its top from+size hits so the merged page is exact. Only
+// relevance-ordered hits can be merged this way, so sort, aggregations and
+// collapse are rejected.
+func (qs *QueryService) searchIndices(ctx context.Context, indices []string, searchReq *parser.SearchRequest, startTime time.Time) (*SearchResult, string, error) {
+	if len(searchReq.Sort) > 0 || len(searchReq.Aggregations) > 0 || len(searchReq.Aggs) > 0 || searchReq.Collapse != nil {
+		return nil, "", &APIError{
+			Status: http.StatusBadRequest,
+			Type:   "illegal_argument_exception",
+			Reason: "sort, aggregations and collapse are not supported when searching multiple indices",
+		}
+	}
+
+	indexReq := *searchReq
+	indexReq.From = 0
+	indexReq.Size = searchReq.From + searchReq.Size
+
+	merged := &SearchResult{
+		Aggregations: make(map[string]*AggregationResult),
+		Shards:       &ShardInfo{},
+	}
+	plans := make([]string, 0, len(indices))
+	for _, index := range indices {
+		result, plan, err := qs.searchIndex(ctx, index, &indexReq, startTime)
+		if err != nil {
+			return nil, "", fmt.Errorf("search on index %s failed: %w", index, err)
+		}
+		plans = append(plans, index+": "+plan)
+
+		boost := searchReq.IndexBoost(index)
+		for _, hit := range result.Hits {
+			hit.Score *= boost
+		}
+		if score := result.MaxScore * boost; score > merged.MaxScore {
+			merged.MaxScore = score
+		}
+
+		merged.Hits = append(merged.Hits, result.Hits...)
+		merged.TotalHits += result.TotalHits
+		merged.TimedOut = merged.TimedOut || result.TimedOut
+		merged.TerminatedEarly = merged.TerminatedEarly || result.TerminatedEarly
+		merged.Shards.Total += result.Shards.Total
+		merged.Shards.Successful += result.Shards.Successful
+		merged.Shards.Skipped += result.Shards.Skipped
+		merged.Shards.Failed += result.Shards.Failed
+		merged.Shards.Failures = append(merged.Shards.Failures, result.Shards.Failures...)
+	}
+
+	// Stable, so equal scores keep the order the indices were listed in
+	sort.SliceStable(merged.Hits, func(i, j int) bool {
+		return merged.Hits[i].Score > merged.Hits[j].Score
+	})
+	if searchReq.From >= len(merged.Hits) {
+		merged.Hits = nil
+	} else {
+		merged.Hits = merged.Hits[searchReq.From:]
+	}
+	if len(merged.Hits) > searchReq.Size {
+		merged.Hits = merged.Hits[:searchReq.Size]
+	}
+
+	merged.TookMillis = time.Since(startTime).Milliseconds()
+	return merged, strings.Join(plans, "; "), nil
+}
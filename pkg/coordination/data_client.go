@@ -2,26 +2,50 @@ package coordination
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/quidditch/quidditch/pkg/common/metrics"
 	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/common/requestid"
+	"github.com/quidditch/quidditch/pkg/common/tracing"
+	"github.com/quidditch/quidditch/pkg/coordination/executor"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
+// Health check timing
+const (
+	healthCheckTimeout   = 2 * time.Second
+	reconnectBaseBackoff = 500 * time.Millisecond
+	reconnectMaxBackoff  = 30 * time.Second
+)
+
 // DataNodeClient manages communication with a data node
 type DataNodeClient struct {
-	nodeID   string
-	address  string
-	logger   *zap.Logger
-	conn     *grpc.ClientConn
-	client   pb.DataServiceClient
-	mu       sync.RWMutex
+	nodeID    string
+	address   string
+	logger    *zap.Logger
+	conn      *grpc.ClientConn
+	client    pb.DataServiceClient
+	tlsConfig *tls.Config
+	mu        sync.RWMutex
 	connected bool
+
+	// unhealthy is set when the last health probe failed. It's kept outside
+	// mu so readers aren't blocked while a reconnect holds the lock.
+	unhealthy atomic.Bool
+
+	healthCancel context.CancelFunc
+	healthDone   chan struct{}
+
+	// metrics records health probe results (nil = not recorded). Like
+	// unhealthy, it's kept outside mu.
+	metrics atomic.Pointer[metrics.MetricsCollector]
 }
 
 // NewDataNodeClient creates a new data node client
@@ -33,6 +57,19 @@ func NewDataNodeClient(nodeID, address string, logger *zap.Logger) *DataNodeClie
 	}
 }
 
+// SetTLSConfig makes Connect dial the data node over TLS
+func (dc *DataNodeClient) SetTLSConfig(tlsConfig *tls.Config) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	dc.tlsConfig = tlsConfig
+}
+
+// SetMetrics makes the client record its health probe results in m
+func (dc *DataNodeClient) SetMetrics(m *metrics.MetricsCollector) {
+	dc.metrics.Store(m)
+}
+
 // Connect establishes connection to the data node
 func (dc *DataNodeClient) Connect(ctx context.Context) error {
 	dc.mu.Lock()
@@ -53,7 +90,9 @@ func (dc *DataNodeClient) Connect(ctx context.Context) error {
 	conn, err := grpc.DialContext(
 		dialCtx,
 		dc.address,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(transportCredentials(dc.tlsConfig)),
+		tracing.ClientOption(),
+		requestid.ClientOption(),
 		grpc.WithBlock(),
 	)
 	if err != nil {
@@ -96,6 +135,153 @@ func (dc *DataNodeClient) IsConnected() bool {
 	return dc.connected
 }
 
+// IsHealthy returns false if the last health probe failed. Clients are
+// considered healthy until probed.
+func (dc *DataNodeClient) IsHealthy() bool {
+	return !dc.unhealthy.Load()
+}
+
+// Ping checks that the data node answers a cheap GetNodeStats call
+func (dc *DataNodeClient) Ping(ctx context.Context) error {
+	dc.mu.RLock()
+	if !dc.connected {
+		dc.mu.RUnlock()
+		return fmt.Errorf("not connected to data node %s", dc.nodeID)
+	}
+	client := dc.client
+	dc.mu.RUnlock()
+
+	pingCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	if _, err := client.GetNodeStats(pingCtx, &pb.GetNodeStatsRequest{}); err != nil {
+		return fmt.Errorf("health check failed on node %s: %w", dc.nodeID, err)
+	}
+	return nil
+}
+
+// StartHealthChecks probes the data node every interval until
+// StopHealthChecks is called. When a probe fails the client is marked
+// unhealthy and reconnected, backing off exponentially while the node stays
+// unreachable.
+func (dc *DataNodeClient) StartHealthChecks(interval time.Duration) {
+	dc.mu.Lock()
+	if dc.healthCancel != nil {
+		dc.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	dc.healthCancel = cancel
+	dc.healthDone = done
+	dc.mu.Unlock()
+
+	dc.recordHealth(dc.IsHealthy())
+
+	go func() {
+		defer close(done)
+		dc.runHealthChecks(ctx, interval)
+	}()
+}
+
+// StopHealthChecks stops the health check loop and waits for it to exit
+func (dc *DataNodeClient) StopHealthChecks() {
+	dc.mu.Lock()
+	cancel, done := dc.healthCancel, dc.healthDone
+	dc.healthCancel = nil
+	dc.healthDone = nil
+	dc.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+
+	if m := dc.metrics.Load(); m != nil {
+		m.RemoveDataNode(dc.nodeID)
+	}
+}
+
+// runHealthChecks is the health check loop started by StartHealthChecks
+func (dc *DataNodeClient) runHealthChecks(ctx context.Context, interval time.Duration) {
+	wait := interval
+	backoff := reconnectBaseBackoff
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		err := dc.Ping(ctx)
+		if err != nil && ctx.Err() == nil {
+			dc.setHealthy(false, err)
+
+			// Re-dial the node; the probe decides whether it recovered
+			if err = dc.reconnect(ctx); err == nil {
+				err = dc.Ping(ctx)
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err == nil {
+			dc.setHealthy(true, nil)
+			wait = interval
+			backoff = reconnectBaseBackoff
+		} else {
+			dc.logger.Debug("Data node still unreachable",
+				zap.String("node_id", dc.nodeID),
+				zap.Duration("retry_in", backoff),
+				zap.Error(err))
+			wait = backoff
+			backoff = min(backoff*2, reconnectMaxBackoff)
+		}
+		timer.Reset(wait)
+	}
+}
+
+// reconnect closes the current connection and dials the node again
+func (dc *DataNodeClient) reconnect(ctx context.Context) error {
+	if err := dc.Disconnect(); err != nil {
+		dc.logger.Debug("Error closing connection before reconnect",
+			zap.String("node_id", dc.nodeID),
+			zap.Error(err))
+	}
+	return dc.Connect(ctx)
+}
+
+// recordHealth records a health probe result in the metrics, if set
+func (dc *DataNodeClient) recordHealth(healthy bool) {
+	if m := dc.metrics.Load(); m != nil {
+		m.RecordDataNodeHealth(dc.nodeID, healthy)
+	}
+}
+
+// setHealthy records a health probe result, logging transitions
+func (dc *DataNodeClient) setHealthy(healthy bool, cause error) {
+	changed := dc.unhealthy.Swap(!healthy) == healthy
+
+	dc.recordHealth(healthy)
+
+	if !changed {
+		return
+	}
+	if healthy {
+		dc.logger.Info("Data node recovered", zap.String("node_id", dc.nodeID))
+	} else {
+		dc.logger.Warn("Data node unhealthy, reconnecting",
+			zap.String("node_id", dc.nodeID),
+			zap.Error(cause))
+	}
+}
+
 // Search executes a search query on a specific shard
 func (dc *DataNodeClient) Search(ctx context.Context, indexName string, shardID int32, query []byte, filterExpression []byte) (*pb.SearchResponse, error) {
 	dc.mu.RLock()
@@ -106,11 +292,18 @@ func (dc *DataNodeClient) Search(ctx context.Context, indexName string, shardID
 	client := dc.client
 	dc.mu.RUnlock()
 
+	opts := executor.ShardSearchOptionsFromContext(ctx)
 	req := &pb.SearchRequest{
 		IndexName:        indexName,
 		ShardId:          shardID,
 		Query:            query,
 		FilterExpression: filterExpression,
+		Size:             opts.Size,
+		TerminateAfter:   opts.TerminateAfter,
+		MinScore:         opts.MinScore,
+		Sort:             opts.Sort,
+		CollapseField:    opts.CollapseField,
+		CollapseSize:     opts.CollapseSize,
 	}
 
 	resp, err := client.Search(ctx, req)
@@ -146,6 +339,31 @@ func (dc *DataNodeClient) Count(ctx context.Context, indexName string, shardID i
 	return resp, nil
 }
 
+// Suggest runs the suggesters of a serialized suggest block on a specific
+// shard
+func (dc *DataNodeClient) Suggest(ctx context.Context, indexName string, shardID int32, suggest []byte) (*pb.SuggestResponse, error) {
+	dc.mu.RLock()
+	if !dc.connected {
+		dc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to data node %s", dc.nodeID)
+	}
+	client := dc.client
+	dc.mu.RUnlock()
+
+	req := &pb.SuggestRequest{
+		IndexName: indexName,
+		ShardId:   shardID,
+		Suggest:   suggest,
+	}
+
+	resp, err := client.Suggest(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("suggest failed on node %s shard %d: %w", dc.nodeID, shardID, err)
+	}
+
+	return resp, nil
+}
+
 // IndexDocument indexes a document on a specific shard
 func (dc *DataNodeClient) IndexDocument(ctx context.Context, indexName string, shardID int32, docID string, document map[string]interface{}) (*pb.IndexDocumentResponse, error) {
 	dc.mu.RLock()
@@ -201,6 +419,31 @@ func (dc *DataNodeClient) GetDocument(ctx context.Context, indexName string, sha
 	return resp, nil
 }
 
+// TermVectors retrieves the term vectors of a document from a specific shard
+func (dc *DataNodeClient) TermVectors(ctx context.Context, indexName string, shardID int32, docID string, fields []string) (*pb.TermVectorsResponse, error) {
+	dc.mu.RLock()
+	if !dc.connected {
+		dc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to data node %s", dc.nodeID)
+	}
+	client := dc.client
+	dc.mu.RUnlock()
+
+	req := &pb.TermVectorsRequest{
+		IndexName: indexName,
+		ShardId:   shardID,
+		DocId:     docID,
+		Fields:    fields,
+	}
+
+	resp, err := client.TermVectors(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("term vectors failed on node %s shard %d: %w", dc.nodeID, shardID, err)
+	}
+
+	return resp, nil
+}
+
 // DeleteDocument deletes a document by ID from a specific shard
 func (dc *DataNodeClient) DeleteDocument(ctx context.Context, indexName string, shardID int32, docID string) (*pb.DeleteDocumentResponse, error) {
 	dc.mu.RLock()
@@ -248,6 +491,103 @@ func (dc *DataNodeClient) GetShardStats(ctx context.Context, indexName string, s
 	return resp, nil
 }
 
+// ForceMerge merges the segments of a shard on the data node
+func (dc *DataNodeClient) ForceMerge(ctx context.Context, indexName string, shardID int32, maxNumSegments int32) (*pb.ForceMergeResponse, error) {
+	dc.mu.RLock()
+	if !dc.connected {
+		dc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to data node %s", dc.nodeID)
+	}
+	client := dc.client
+	dc.mu.RUnlock()
+
+	req := &pb.ForceMergeRequest{
+		IndexName:      indexName,
+		ShardId:        shardID,
+		MaxNumSegments: maxNumSegments,
+	}
+
+	resp, err := client.ForceMerge(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("force merge failed on node %s shard %d: %w", dc.nodeID, shardID, err)
+	}
+
+	return resp, nil
+}
+
+// GetSegmentStats lists the segments of a shard on the data node
+func (dc *DataNodeClient) GetSegmentStats(ctx context.Context, indexName string, shardID int32) (*pb.GetSegmentStatsResponse, error) {
+	dc.mu.RLock()
+	if !dc.connected {
+		dc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to data node %s", dc.nodeID)
+	}
+	client := dc.client
+	dc.mu.RUnlock()
+
+	req := &pb.GetSegmentStatsRequest{
+		IndexName: indexName,
+		ShardId:   shardID,
+	}
+
+	resp, err := client.GetSegmentStats(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("get segment stats failed on node %s shard %d: %w", dc.nodeID, shardID, err)
+	}
+
+	return resp, nil
+}
+
+// SnapshotShard copies a shard's segment files into a snapshot repository
+func (dc *DataNodeClient) SnapshotShard(ctx context.Context, indexName string, shardID int32, repositoryLocation string) (*pb.SnapshotShardResponse, error) {
+	dc.mu.RLock()
+	if !dc.connected {
+		dc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to data node %s", dc.nodeID)
+	}
+	client := dc.client
+	dc.mu.RUnlock()
+
+	req := &pb.SnapshotShardRequest{
+		IndexName:          indexName,
+		ShardId:            shardID,
+		RepositoryLocation: repositoryLocation,
+	}
+
+	resp, err := client.SnapshotShard(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot failed on node %s shard %d: %w", dc.nodeID, shardID, err)
+	}
+
+	return resp, nil
+}
+
+// RestoreShard replaces a shard's contents with files from a snapshot
+// repository
+func (dc *DataNodeClient) RestoreShard(ctx context.Context, indexName string, shardID int32, repositoryLocation string, files []*pb.SnapshotFile) (*pb.RestoreShardResponse, error) {
+	dc.mu.RLock()
+	if !dc.connected {
+		dc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to data node %s", dc.nodeID)
+	}
+	client := dc.client
+	dc.mu.RUnlock()
+
+	req := &pb.RestoreShardRequest{
+		IndexName:          indexName,
+		ShardId:            shardID,
+		RepositoryLocation: repositoryLocation,
+		Files:              files,
+	}
+
+	resp, err := client.RestoreShard(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("restore failed on node %s shard %d: %w", dc.nodeID, shardID, err)
+	}
+
+	return resp, nil
+}
+
 // NodeID returns the node ID
 func (dc *DataNodeClient) NodeID() string {
 	return dc.nodeID
@@ -258,6 +598,7 @@ func (dc *DataNodeClient) Address() string {
 	return dc.address
 }
 
+
 // Helper function to convert map to protobuf Struct
 func convertMapToStruct(m map[string]interface{}) (*structpb.Struct, error) {
 	return structpb.NewStruct(m)
@@ -0,0 +1,89 @@
+package coordination
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/quidditch/quidditch/pkg/common/metrics"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// pingDataService answers the GetNodeStats health probe
+type pingDataService struct {
+	pb.UnimplementedDataServiceServer
+}
+
+func (s *pingDataService) GetNodeStats(ctx context.Context, req *pb.GetNodeStatsRequest) (*pb.DataNodeStats, error) {
+	return &pb.DataNodeStats{NodeId: "data-1"}, nil
+}
+
+// serveDataService starts a data service on address and returns a function
+// that stops it
+func serveDataService(t *testing.T, address string) func() {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", address)
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	pb.RegisterDataServiceServer(server, &pingDataService{})
+	go server.Serve(listener)
+
+	t.Cleanup(server.Stop)
+	return server.Stop
+}
+
+func TestDataNodeClient_HealthChecksReconnect(t *testing.T) {
+	address := net.JoinHostPort("127.0.0.1", strconv.Itoa(freeTestPort(t)))
+	stop := serveDataService(t, address)
+
+	m := metrics.NewMetricsCollectorWithRegistry("coordination", prometheus.NewRegistry())
+	client := NewDataNodeClient("health-test", address, zap.NewNop())
+	client.SetMetrics(m)
+	require.NoError(t, client.Connect(context.Background()))
+	client.StartHealthChecks(20 * time.Millisecond)
+	defer client.StopHealthChecks()
+
+	gauge := m.DataNodeClientHealthy.WithLabelValues("health-test")
+	require.NoError(t, client.Ping(context.Background()))
+	assert.True(t, client.IsHealthy())
+
+	// The data node goes away: the client is marked unhealthy
+	stop()
+	require.Eventually(t, func() bool {
+		return !client.IsHealthy()
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, float64(0), testutil.ToFloat64(gauge))
+
+	// The node comes back on the same address: the client reconnects
+	serveDataService(t, address)
+	require.Eventually(t, client.IsHealthy, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, client.IsConnected())
+	assert.Equal(t, float64(1), testutil.ToFloat64(gauge))
+	require.NoError(t, client.Ping(context.Background()))
+}
+
+func TestDataNodeClient_StopHealthChecks(t *testing.T) {
+	m := metrics.NewMetricsCollectorWithRegistry("coordination", prometheus.NewRegistry())
+	client := NewDataNodeClient("stop-test", "127.0.0.1:1", zap.NewNop())
+	client.SetMetrics(m)
+
+	// Stopping without starting is a no-op
+	client.StopHealthChecks()
+
+	client.StartHealthChecks(time.Hour)
+	assert.Equal(t, 1, testutil.CollectAndCount(m.DataNodeClientHealthy))
+	client.StopHealthChecks()
+
+	// The gauge series is removed with the health checks
+	assert.Equal(t, 0, testutil.CollectAndCount(m.DataNodeClientHealthy))
+	assert.True(t, client.IsHealthy(), "unprobed clients count as healthy")
+}
@@ -0,0 +1,96 @@
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// handleDeleteByQuery deletes every document matching the query in the
+// request body. Matching documents are collected page by page first, so
+// deletes do not shift later pages, then deleted one by one through the
+// document router. It runs as a task, in the background with
+// wait_for_completion=false. Data nodes whose storage engine can't delete
+// documents fail the request with a 501.
+func (c *CoordinationNode) handleDeleteByQuery(ctx *gin.Context) {
+	indexName := ctx.Param("index")
+
+	req, ok := parseByQueryRequest(ctx, true)
+	if !ok {
+		return
+	}
+
+	c.runTask(ctx, "indices:data/write/delete/byquery", fmt.Sprintf("delete-by-query [%s]", indexName), func(ctx context.Context, t *task) (int, gin.H) {
+		return c.deleteByQuery(ctx, t, indexName, req)
+	})
+}
+
+// deleteByQuery runs a delete by query request under its task
+func (c *CoordinationNode) deleteByQuery(ctx context.Context, t *task, indexName string, req *byQueryRequest) (int, gin.H) {
+	startTime := time.Now()
+
+	result := &byQueryResult{}
+	var docIDs []string
+	err := c.scrollMatches(ctx, indexName, req.Query, req.ScrollSize, func(hits []*SearchHit) bool {
+		result.Batches++
+		for _, hit := range hits {
+			docIDs = append(docIDs, hit.ID)
+		}
+		return true
+	})
+	if err != nil && ctx.Err() == nil {
+		c.logger.Error("Delete by query search failed",
+			zap.String("index", indexName),
+			zap.Error(err))
+		return byQuerySearchError(err)
+	}
+	result.Total = len(docIDs)
+
+	deleted := 0
+	for _, docID := range docIDs {
+		if ctx.Err() != nil {
+			break
+		}
+		resp, err := c.docRouter.RouteDeleteDocument(ctx, indexName, docID)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			// Every delete fails the same way on a storage engine that
+			// can't delete documents, so the request fails as a whole
+			if status.Code(err) == codes.Unimplemented {
+				c.logger.Warn("Delete by query is not supported by the data nodes",
+					zap.String("index", indexName),
+					zap.Error(err))
+				apiErr := classifyError(err)
+				return apiErr.Status, apiErr.envelope()
+			}
+			if !result.recordFailure(req, docID, "delete_failed_exception", err) {
+				break
+			}
+			continue
+		}
+		if resp.Found {
+			deleted++
+		}
+		t.setStatus(result.progress("deleted", deleted))
+	}
+	result.Cancelled = ctx.Err() != nil
+
+	c.logger.Info("Delete by query completed",
+		zap.String("index", indexName),
+		zap.Int("total", result.Total),
+		zap.Int("deleted", deleted),
+		zap.Int("version_conflicts", result.VersionConflicts),
+		zap.Int("failures", len(result.Failures)),
+		zap.Bool("cancelled", result.Cancelled))
+
+	response := result.response(indexName, time.Since(startTime).Milliseconds())
+	response["deleted"] = deleted
+	return result.statusCode(), response
+}
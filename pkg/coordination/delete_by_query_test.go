@@ -0,0 +1,309 @@
+package coordination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/coordination/executor"
+	"github.com/quidditch/quidditch/pkg/coordination/pipeline"
+	"github.com/quidditch/quidditch/pkg/coordination/router"
+	"github.com/quidditch/quidditch/pkg/data/analysis"
+	"github.com/quidditch/quidditch/pkg/data/suggest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// memoryIndex is an in-memory index serving both searches (as a query
+// executor) and document writes (as a data node client)
+type memoryIndex struct {
+	mu        sync.Mutex
+	docs      map[string]map[string]interface{}
+	conflicts map[string]bool // doc ids whose delete reports a version conflict
+
+	deleteUnsupported  bool // Deletes fail as on a storage engine without them
+	replaceUnsupported bool // Writes to existing ids fail as on an append-only engine
+}
+
+func (m *memoryIndex) ExecuteSearch(ctx context.Context, indexName string, query []byte, filterExpr []byte, from, size int) (*executor.SearchResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var q map[string]map[string]interface{}
+	if err := json.Unmarshal(query, &q); err != nil {
+		return nil, err
+	}
+
+	hits := []*executor.SearchHit{}
+	for id, doc := range m.docs {
+		if term, ok := q["term"]; ok {
+			match := true
+			for field, value := range term {
+				match = match && fmt.Sprint(doc[field]) == fmt.Sprint(value)
+			}
+			if !match {
+				continue
+			}
+		}
+		// Hits carry a copy of the source, as they would decoded from a
+		// data node's response
+		hits = append(hits, &executor.SearchHit{ID: id, Score: 1.0, Source: maps.Clone(doc)})
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].ID < hits[j].ID })
+
+	return &executor.SearchResult{TotalHits: int64(len(hits)), MaxScore: 1.0, Hits: hits}, nil
+}
+
+// ExecuteSuggest runs the suggesters over the documents with the standard
+// analyzer, as a data node does for fields without a mapped analyzer
+func (m *memoryIndex) ExecuteSuggest(ctx context.Context, indexName string, block map[string]interface{}) (map[string][]suggest.Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	suggesters, err := suggest.Parse(block)
+	if err != nil {
+		return nil, err
+	}
+	registry, err := analysis.NewRegistry(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	standard, _ := registry.Get(analysis.DefaultAnalyzer)
+	analyze := func(field, text string) ([]analysis.Token, error) {
+		return standard.Analyze(text), nil
+	}
+
+	docs := make([]suggest.Document, 0, len(m.docs))
+	for id, doc := range m.docs {
+		docs = append(docs, suggest.Document{ID: id, Source: doc})
+	}
+	results := make(map[string][]suggest.Entry, len(suggesters))
+	for _, suggester := range suggesters {
+		if results[suggester.Name], err = suggest.Run(suggester, docs, analyze); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func (m *memoryIndex) DeleteDocument(ctx context.Context, indexName string, shardID int32, docID string) (*pb.DeleteDocumentResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.deleteUnsupported {
+		return nil, status.Error(codes.Unimplemented, "document deletion is not supported by the storage engine")
+	}
+	if m.conflicts[docID] {
+		return nil, status.Error(codes.Aborted, "version conflict")
+	}
+	_, found := m.docs[docID]
+	delete(m.docs, docID)
+	return &pb.DeleteDocumentResponse{Acknowledged: true, Found: found}, nil
+}
+
+func (m *memoryIndex) IndexDocument(ctx context.Context, indexName string, shardID int32, docID string, document map[string]interface{}) (*pb.IndexDocumentResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.conflicts[docID] {
+		return nil, status.Error(codes.Aborted, "version conflict")
+	}
+	_, found := m.docs[docID]
+	if found && m.replaceUnsupported {
+		return nil, status.Error(codes.Unimplemented, "replacing an indexed document is not supported by the storage engine")
+	}
+	m.docs[docID] = document
+	return &pb.IndexDocumentResponse{Acknowledged: true, Version: 2, Found: found}, nil
+}
+
+func (m *memoryIndex) GetDocument(ctx context.Context, indexName string, shardID int32, docID string) (*pb.GetDocumentResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	doc, found := m.docs[docID]
+	if !found {
+		return &pb.GetDocumentResponse{Found: false}, nil
+	}
+	source, err := structpb.NewStruct(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetDocumentResponse{Found: true, Version: 1, Document: source}, nil
+}
+
+// TermVectors computes term vectors with the standard analyzer, as a data
+// node does for fields without a mapped analyzer
+func (m *memoryIndex) TermVectors(ctx context.Context, indexName string, shardID int32, docID string, fields []string) (*pb.TermVectorsResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	doc, found := m.docs[docID]
+	if !found {
+		return &pb.TermVectorsResponse{Found: false, DocId: docID}, nil
+	}
+	registry, err := analysis.NewRegistry(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	standard, _ := registry.Get(analysis.DefaultAnalyzer)
+
+	resp := &pb.TermVectorsResponse{Found: true, DocId: docID, TermVectors: map[string]*pb.FieldTermVector{}}
+	for field, value := range doc {
+		text, ok := value.(string)
+		if !ok || (len(fields) > 0 && !slices.Contains(fields, field)) {
+			continue
+		}
+		vector, err := analysis.NewTermVector([]string{text}, func(text string) ([]analysis.Token, error) {
+			return standard.Analyze(text), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		terms := map[string]*pb.TermVectorTerm{}
+		for term, occurrences := range vector {
+			tokens := make([]*pb.TermVectorToken, len(occurrences))
+			for i, occurrence := range occurrences {
+				tokens[i] = &pb.TermVectorToken{
+					Position:    int32(occurrence.Position),
+					StartOffset: int32(occurrence.StartOffset),
+					EndOffset:   int32(occurrence.EndOffset),
+				}
+			}
+			terms[term] = &pb.TermVectorTerm{TermFreq: int32(len(occurrences)), Tokens: tokens}
+		}
+		resp.TermVectors[field] = &pb.FieldTermVector{Terms: terms}
+	}
+	return resp, nil
+}
+
+func (m *memoryIndex) IsConnected() bool                 { return true }
+func (m *memoryIndex) Connect(ctx context.Context) error { return nil }
+func (m *memoryIndex) NodeID() string                    { return "node1" }
+
+func setupByQueryNode(index *memoryIndex) *CoordinationNode {
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+	masterClient := &mockMasterClient{}
+
+	node := &CoordinationNode{
+		logger:           logger,
+		ginRouter:        gin.New(),
+		queryService:     NewQueryService(index, masterClient, logger),
+		docRouter:        router.NewDocumentRouter(masterClient, map[string]router.DataNodeClient{"node1": index}, logger),
+		pipelineRegistry: pipeline.NewRegistry(logger),
+		tasks:            newTaskManager("node1"),
+	}
+	node.ginRouter.POST("/:index/_delete_by_query", node.handleDeleteByQuery)
+	node.ginRouter.POST("/:index/_update_by_query", node.handleUpdateByQuery)
+	node.ginRouter.GET("/:index/_doc/:id", node.handleGetDocument)
+	node.ginRouter.POST("/_mget", node.handleMultiGet)
+	node.ginRouter.POST("/:index/_mget", node.handleMultiGet)
+	node.ginRouter.GET("/:index/_termvectors/:id", node.handleTermVectors)
+	node.ginRouter.POST("/:index/_termvectors/:id", node.handleTermVectors)
+	return node
+}
+
+func newMemoryIndex() *memoryIndex {
+	return &memoryIndex{
+		docs: map[string]map[string]interface{}{
+			"1": {"category": "books"},
+			"2": {"category": "music"},
+			"3": {"category": "books"},
+			"4": {"category": "games"},
+		},
+		conflicts: map[string]bool{},
+	}
+}
+
+func postJSON(r *gin.Engine, path, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w, response
+}
+
+func TestDeleteByQuery_DeletesMatchingDocuments(t *testing.T) {
+	index := newMemoryIndex()
+	node := setupByQueryNode(index)
+
+	query := `{"query": {"term": {"category": "books"}}}`
+	w, response := postJSON(node.ginRouter, "/products/_delete_by_query", query)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, float64(2), response["total"])
+	assert.Equal(t, float64(2), response["deleted"])
+	assert.Equal(t, float64(0), response["version_conflicts"])
+	assert.Empty(t, response["failures"])
+
+	// The matching documents are gone, the others remain
+	result, err := node.queryService.ExecuteSearch(context.Background(), "products", []byte(query))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), result.TotalHits)
+
+	assert.Len(t, index.docs, 2)
+	assert.Contains(t, index.docs, "2")
+	assert.Contains(t, index.docs, "4")
+}
+
+func TestDeleteByQuery_VersionConflicts(t *testing.T) {
+	query := `{"query": {"match_all": {}}}`
+
+	// By default the first conflict aborts the request
+	index := newMemoryIndex()
+	index.conflicts["2"] = true
+	w, response := postJSON(setupByQueryNode(index).ginRouter, "/products/_delete_by_query", query)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, float64(1), response["deleted"])
+	assert.Equal(t, float64(1), response["version_conflicts"])
+	require.Len(t, response["failures"], 1)
+	assert.Len(t, index.docs, 3)
+
+	// conflicts=proceed counts the conflict and keeps going
+	index = newMemoryIndex()
+	index.conflicts["2"] = true
+	w, response = postJSON(setupByQueryNode(index).ginRouter, "/products/_delete_by_query?conflicts=proceed", query)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(3), response["deleted"])
+	assert.Equal(t, float64(1), response["version_conflicts"])
+	assert.Empty(t, response["failures"])
+	assert.Len(t, index.docs, 1)
+}
+
+func TestDeleteByQuery_DeleteUnsupported(t *testing.T) {
+	index := newMemoryIndex()
+	index.deleteUnsupported = true
+
+	w, response := postJSON(setupByQueryNode(index).ginRouter, "/products/_delete_by_query", `{"query": {"match_all": {}}}`)
+	assert.Equal(t, http.StatusNotImplemented, w.Code, w.Body.String())
+	errorObject, ok := response["error"].(map[string]interface{})
+	require.True(t, ok, w.Body.String())
+	assert.Equal(t, "unsupported_operation_exception", errorObject["type"])
+	assert.Len(t, index.docs, 4)
+}
+
+func TestDeleteByQuery_InvalidRequest(t *testing.T) {
+	r := setupByQueryNode(newMemoryIndex()).ginRouter
+
+	w, _ := postJSON(r, "/products/_delete_by_query", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, _ = postJSON(r, "/products/_delete_by_query?conflicts=ignore", `{"query": {"match_all": {}}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,211 @@
+package coordination
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/coordination/executor"
+	"github.com/quidditch/quidditch/pkg/coordination/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// mockClusterStateStream replays events, then reports the stream as closed
+type mockClusterStateStream struct {
+	events chan *pb.ClusterStateEvent
+}
+
+func (m *mockClusterStateStream) Recv() (*pb.ClusterStateEvent, error) {
+	event, ok := <-m.events
+	if !ok {
+		return nil, io.EOF
+	}
+	return event, nil
+}
+
+// newDiscoveryTestNode builds a node with an executor and router but no data nodes
+func newDiscoveryTestNode(t *testing.T) *CoordinationNode {
+	logger := zap.NewNop()
+	node := &CoordinationNode{
+		logger:        logger,
+		dataClients:   make(map[string]*DataNodeClient),
+		queryExecutor: executor.NewQueryExecutor(nil, logger),
+		docRouter:     router.NewDocumentRouter(nil, nil, logger),
+	}
+
+	t.Cleanup(func() {
+		node.dataClientsMu.RLock()
+		defer node.dataClientsMu.RUnlock()
+		for _, client := range node.dataClients {
+			client.StopHealthChecks()
+			client.Disconnect()
+		}
+	})
+	return node
+}
+
+// startTestDataNode serves an empty gRPC server that data node clients can dial
+func startTestDataNode(t *testing.T) (string, int32) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), int32(addr.Port)
+}
+
+func membershipEvent(t *testing.T, eventType pb.ClusterStateEvent_EventType, node *pb.NodeInfo) *pb.ClusterStateEvent {
+	t.Helper()
+
+	payload, err := proto.Marshal(node)
+	require.NoError(t, err)
+	return &pb.ClusterStateEvent{Type: eventType, Payload: payload}
+}
+
+func TestConsumeClusterStateEvents_JoinAndLeave(t *testing.T) {
+	node := newDiscoveryTestNode(t)
+	bindAddr, grpcPort := startTestDataNode(t)
+
+	dataNode := &pb.NodeInfo{
+		NodeId:   "data-1",
+		NodeType: pb.NodeType_NODE_TYPE_DATA,
+		BindAddr: bindAddr,
+		GrpcPort: grpcPort,
+	}
+	masterNode := &pb.NodeInfo{
+		NodeId:   "master-1",
+		NodeType: pb.NodeType_NODE_TYPE_MASTER,
+		BindAddr: bindAddr,
+		GrpcPort: grpcPort,
+	}
+
+	stream := &mockClusterStateStream{events: make(chan *pb.ClusterStateEvent, 4)}
+	stream.events <- membershipEvent(t, pb.ClusterStateEvent_EVENT_TYPE_NODE_JOINED, dataNode)
+	stream.events <- membershipEvent(t, pb.ClusterStateEvent_EVENT_TYPE_NODE_JOINED, masterNode)
+
+	done := make(chan error, 1)
+	go func() { done <- node.consumeClusterStateEvents(context.Background(), stream) }()
+
+	// The data node is connected and registered, the master node is ignored
+	require.Eventually(t, func() bool {
+		return node.queryExecutor.HasDataNodeClient("data-1")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	node.dataClientsMu.RLock()
+	client := node.dataClients["data-1"]
+	_, hasMaster := node.dataClients["master-1"]
+	node.dataClientsMu.RUnlock()
+	require.NotNil(t, client)
+	assert.True(t, client.IsConnected())
+	assert.False(t, hasMaster)
+
+	// The node leaves: its client is disconnected and unregistered
+	stream.events <- membershipEvent(t, pb.ClusterStateEvent_EVENT_TYPE_NODE_LEFT, dataNode)
+	close(stream.events)
+
+	err := <-done
+	require.Error(t, err, "a closed stream should be reported so the watch is re-opened")
+
+	assert.False(t, node.queryExecutor.HasDataNodeClient("data-1"))
+	assert.False(t, client.IsConnected())
+	node.dataClientsMu.RLock()
+	assert.Empty(t, node.dataClients)
+	node.dataClientsMu.RUnlock()
+}
+
+func TestApplyClusterStateEvent_IgnoresUnrelatedEvents(t *testing.T) {
+	node := newDiscoveryTestNode(t)
+
+	node.applyClusterStateEvent(context.Background(), &pb.ClusterStateEvent{
+		Type:    pb.ClusterStateEvent_EVENT_TYPE_INDEX_CREATED,
+		Payload: []byte("not a node"),
+	})
+	node.applyClusterStateEvent(context.Background(), &pb.ClusterStateEvent{
+		Type:    pb.ClusterStateEvent_EVENT_TYPE_NODE_JOINED,
+		Payload: []byte{0xff, 0xff},
+	})
+
+	assert.Empty(t, node.dataClients)
+}
+
+func TestReconcileDataNodeClients_RemovesStaleNodes(t *testing.T) {
+	node := newDiscoveryTestNode(t)
+	bindAddr, grpcPort := startTestDataNode(t)
+
+	dataNode := func(id string, port int32) *pb.NodeInfo {
+		return &pb.NodeInfo{
+			NodeId:   id,
+			NodeType: pb.NodeType_NODE_TYPE_DATA,
+			BindAddr: bindAddr,
+			GrpcPort: port,
+		}
+	}
+
+	ctx := context.Background()
+	node.reconcileDataNodeClients(ctx, &pb.ClusterStateResponse{
+		Nodes: []*pb.NodeInfo{dataNode("data-1", grpcPort), dataNode("data-2", grpcPort)},
+	})
+
+	node.dataClientsMu.RLock()
+	require.Len(t, node.dataClients, 2)
+	stale := node.dataClients["data-2"]
+	node.dataClientsMu.RUnlock()
+	require.True(t, stale.IsConnected())
+
+	// data-2 disappears from the cluster state
+	node.reconcileDataNodeClients(ctx, &pb.ClusterStateResponse{
+		Nodes: []*pb.NodeInfo{dataNode("data-1", grpcPort)},
+	})
+
+	assert.False(t, stale.IsConnected(), "stale client should be closed")
+	assert.False(t, node.queryExecutor.HasDataNodeClient("data-2"))
+	assert.True(t, node.queryExecutor.HasDataNodeClient("data-1"))
+	node.dataClientsMu.RLock()
+	_, exists := node.dataClients["data-2"]
+	assert.False(t, exists)
+	assert.Len(t, node.dataClients, 1)
+	node.dataClientsMu.RUnlock()
+}
+
+func TestReconcileDataNodeClients_ReplacesMovedNode(t *testing.T) {
+	node := newDiscoveryTestNode(t)
+	bindAddr, oldPort := startTestDataNode(t)
+	_, newPort := startTestDataNode(t)
+
+	state := func(port int32) *pb.ClusterStateResponse {
+		return &pb.ClusterStateResponse{Nodes: []*pb.NodeInfo{{
+			NodeId:   "data-1",
+			NodeType: pb.NodeType_NODE_TYPE_DATA,
+			BindAddr: bindAddr,
+			GrpcPort: port,
+		}}}
+	}
+
+	ctx := context.Background()
+	node.reconcileDataNodeClients(ctx, state(oldPort))
+	node.dataClientsMu.RLock()
+	oldClient := node.dataClients["data-1"]
+	node.dataClientsMu.RUnlock()
+
+	// The node restarted on another port
+	node.reconcileDataNodeClients(ctx, state(newPort))
+	node.dataClientsMu.RLock()
+	newClient := node.dataClients["data-1"]
+	node.dataClientsMu.RUnlock()
+
+	assert.False(t, oldClient.IsConnected())
+	require.NotNil(t, newClient)
+	assert.True(t, newClient.IsConnected())
+	assert.NotEqual(t, oldClient.Address(), newClient.Address())
+}
@@ -0,0 +1,213 @@
+package coordination
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quidditch/quidditch/pkg/coordination/router"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error responses share one envelope, in the OpenSearch format:
+//
+//	{"error": {"type": ..., "reason": ..., "root_cause": [...]}, "status": 400}
+//
+// Handlers answer with respondError when they know the status and type, and
+// with respondErrorFrom when the kind of error decides them.
+
+// APIError is an error reported to clients with its HTTP status and error
+// type
+type APIError struct {
+	Status int
+	Type   string
+	Reason string
+
+	// Metadata holds further fields of the error object, such as the index
+	// the error concerns
+	Metadata gin.H
+
+	// RootCause lists the failures behind the error, such as the shards
+	// that failed a search
+	RootCause []*APIError
+
+	// Err is the underlying error, if any
+	Err error
+}
+
+// Error implements error interface
+func (e *APIError) Error() string {
+	return e.Reason
+}
+
+// Unwrap returns the underlying error
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// body returns the error object of the envelope
+func (e *APIError) body() gin.H {
+	body := gin.H{
+		"type":   e.Type,
+		"reason": e.Reason,
+	}
+	for key, value := range e.Metadata {
+		body[key] = value
+	}
+	if len(e.RootCause) > 0 {
+		causes := make([]gin.H, len(e.RootCause))
+		for i, cause := range e.RootCause {
+			causes[i] = cause.body()
+		}
+		body["root_cause"] = causes
+	}
+	return body
+}
+
+// envelope returns the error response body
+func (e *APIError) envelope() gin.H {
+	return gin.H{
+		"error":  e.body(),
+		"status": e.Status,
+	}
+}
+
+// parsingError reports a request whose content can't be parsed or is
+// invalid
+func parsingError(err error) *APIError {
+	return &APIError{
+		Status: http.StatusBadRequest,
+		Type:   "parsing_exception",
+		Reason: err.Error(),
+		Err:    err,
+	}
+}
+
+// indexNotFoundError reports a request naming an index that doesn't exist
+func indexNotFoundError(indexName string) *APIError {
+	return &APIError{
+		Status:   http.StatusNotFound,
+		Type:     "index_not_found_exception",
+		Reason:   fmt.Sprintf("no such index [%s]", indexName),
+		Metadata: gin.H{"index": indexName},
+	}
+}
+
+// classifyError returns the status and type err is reported with, or nil
+// if its kind doesn't decide them. An APIError anywhere in the chain is
+// reported as it is, the query service's errors by their own kind, and a
+// gRPC status from the master or a data node by its code.
+func classifyError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var windowErr *ResultWindowTooLargeError
+	if errors.As(err, &windowErr) {
+		return &APIError{
+			Status:   http.StatusBadRequest,
+			Type:     "illegal_argument_exception",
+			Reason:   windowErr.Error(),
+			Metadata: gin.H{"index": windowErr.Index},
+			Err:      err,
+		}
+	}
+
+	var partialErr *PartialSearchResultsError
+	if errors.As(err, &partialErr) {
+		// Some shards failed and the request disallowed partial results
+		classified := &APIError{
+			Status: http.StatusInternalServerError,
+			Type:   "search_phase_execution_exception",
+			Reason: partialErr.Error(),
+			Err:    err,
+		}
+		for _, failure := range partialErr.Failures {
+			classified.RootCause = append(classified.RootCause, &APIError{
+				Type:     "search_exception",
+				Reason:   failure.Reason,
+				Metadata: gin.H{"index": failure.Index, "shard": failure.Shard},
+			})
+		}
+		return classified
+	}
+
+	if errors.Is(err, router.ErrIndexWriteBlocked) {
+		return &APIError{
+			Status: http.StatusForbidden,
+			Type:   "cluster_block_exception",
+			Reason: err.Error(),
+			Err:    err,
+		}
+	}
+
+	if errors.Is(err, router.ErrShardRelocating) || errors.Is(err, router.ErrReplicaRecovering) {
+		return &APIError{
+			Status: http.StatusServiceUnavailable,
+			Type:   "unavailable_shards_exception",
+			Reason: err.Error(),
+			Err:    err,
+		}
+	}
+
+	var outputErr *QueryPipelineOutputError
+	if errors.As(err, &outputErr) {
+		return parsingError(err)
+	}
+
+	var rejected interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &rejected) {
+		return nil
+	}
+	classified := &APIError{Reason: rejected.GRPCStatus().Message(), Err: err}
+	switch rejected.GRPCStatus().Code() {
+	case codes.InvalidArgument:
+		classified.Status, classified.Type = http.StatusBadRequest, "illegal_argument_exception"
+	case codes.NotFound:
+		classified.Status, classified.Type = http.StatusNotFound, "resource_not_found_exception"
+	case codes.AlreadyExists:
+		classified.Status, classified.Type = http.StatusBadRequest, "resource_already_exists_exception"
+	case codes.Aborted:
+		classified.Status, classified.Type = http.StatusConflict, "version_conflict_engine_exception"
+	case codes.ResourceExhausted:
+		// A data node circuit breaker rejected the request (e.g. an
+		// aggregation too large for the fielddata limit)
+		classified.Status, classified.Type = http.StatusTooManyRequests, "circuit_breaking_exception"
+	case codes.Unimplemented:
+		// A data node can't perform the operation, such as deleting a
+		// document, with its storage engine
+		classified.Status, classified.Type = http.StatusNotImplemented, "unsupported_operation_exception"
+	case codes.OutOfRange:
+		// A data node rejected a document larger than its index's
+		// index.mapping.max_document_size_bytes
+		classified.Status, classified.Type = http.StatusRequestEntityTooLarge, "document_too_large_exception"
+	default:
+		return nil
+	}
+	return classified
+}
+
+// respondError aborts the request with an error response
+func respondError(ctx *gin.Context, status int, errorType, reason string) {
+	respondAPIError(ctx, &APIError{Status: status, Type: errorType, Reason: reason})
+}
+
+// respondAPIError aborts the request with an error response for err
+func respondAPIError(ctx *gin.Context, err *APIError) {
+	ctx.AbortWithStatusJSON(err.Status, err.envelope())
+}
+
+// respondErrorFrom aborts the request with an error response for err,
+// classified by classifyError. Any other error is an internal error of
+// fallbackType, its reason saying what failed, as in "Failed to create
+// index: ...".
+func respondErrorFrom(ctx *gin.Context, err error, fallbackType, failed string) {
+	if classified := classifyError(err); classified != nil {
+		respondAPIError(ctx, classified)
+		return
+	}
+	respondError(ctx, http.StatusInternalServerError, fallbackType, fmt.Sprintf("%s: %v", failed, err))
+}
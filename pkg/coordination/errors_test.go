@@ -0,0 +1,140 @@
+package coordination
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// decodeErrorEnvelope checks a response has the error envelope shape and
+// returns its error object
+func decodeErrorEnvelope(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), w.Body.String())
+	assert.Equal(t, float64(w.Code), response["status"])
+
+	errorObject, ok := response["error"].(map[string]interface{})
+	require.True(t, ok, "error is not an object: %s", w.Body.String())
+	assert.NotEmpty(t, errorObject["type"])
+	assert.NotEmpty(t, errorObject["reason"])
+	return errorObject
+}
+
+func respondErrorFromRecorded(err error) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	respondErrorFrom(ctx, err, "test_exception", "Failed to test")
+	return w
+}
+
+func TestRespondErrorFrom(t *testing.T) {
+	t.Run("IndexNotFound", func(t *testing.T) {
+		w := respondErrorFromRecorded(fmt.Errorf("lookup: %w", indexNotFoundError("products")))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		errorObject := decodeErrorEnvelope(t, w)
+		assert.Equal(t, "index_not_found_exception", errorObject["type"])
+		assert.Equal(t, "no such index [products]", errorObject["reason"])
+		assert.Equal(t, "products", errorObject["index"])
+		assert.NotContains(t, errorObject, "root_cause")
+	})
+
+	t.Run("PartialSearchResults", func(t *testing.T) {
+		w := respondErrorFromRecorded(&PartialSearchResultsError{
+			Index: "products",
+			Total: 3,
+			Failures: []ShardFailure{
+				{Index: "products", Shard: 0, Reason: "node left"},
+				{Index: "products", Shard: 2, Reason: "timed out"},
+			},
+		})
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+		errorObject := decodeErrorEnvelope(t, w)
+		assert.Equal(t, "search_phase_execution_exception", errorObject["type"])
+		rootCause, ok := errorObject["root_cause"].([]interface{})
+		require.True(t, ok)
+		require.Len(t, rootCause, 2)
+		assert.Equal(t, map[string]interface{}{
+			"type":   "search_exception",
+			"reason": "timed out",
+			"index":  "products",
+			"shard":  float64(2),
+		}, rootCause[1])
+	})
+
+	t.Run("GRPCStatus", func(t *testing.T) {
+		for code, expected := range map[codes.Code]struct {
+			status    int
+			errorType string
+		}{
+			codes.InvalidArgument:   {http.StatusBadRequest, "illegal_argument_exception"},
+			codes.NotFound:          {http.StatusNotFound, "resource_not_found_exception"},
+			codes.AlreadyExists:     {http.StatusBadRequest, "resource_already_exists_exception"},
+			codes.Aborted:           {http.StatusConflict, "version_conflict_engine_exception"},
+			codes.ResourceExhausted: {http.StatusTooManyRequests, "circuit_breaking_exception"},
+			codes.OutOfRange:        {http.StatusRequestEntityTooLarge, "document_too_large_exception"},
+		} {
+			w := respondErrorFromRecorded(fmt.Errorf("master: %w", status.Error(code, "rejected by master")))
+			assert.Equal(t, expected.status, w.Code, "code %s", code)
+
+			errorObject := decodeErrorEnvelope(t, w)
+			assert.Equal(t, expected.errorType, errorObject["type"], "code %s", code)
+			assert.Equal(t, "rejected by master", errorObject["reason"], "code %s", code)
+		}
+	})
+
+	t.Run("Fallback", func(t *testing.T) {
+		for _, err := range []error{errors.New("disk full"), status.Error(codes.Unavailable, "disk full")} {
+			w := respondErrorFromRecorded(err)
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+			errorObject := decodeErrorEnvelope(t, w)
+			assert.Equal(t, "test_exception", errorObject["type"])
+			assert.Contains(t, errorObject["reason"], "Failed to test: ")
+			assert.Contains(t, errorObject["reason"], "disk full")
+		}
+	})
+}
+
+func TestErrorEnvelopeHTTP(t *testing.T) {
+	node := newTestCoordinationNode(t)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	// A malformed search body is a parsing error
+	w := do(http.MethodPost, "/products/_search", `{"query": `)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "parsing_exception", decodeErrorEnvelope(t, w)["type"])
+
+	// An invalid search is an illegal argument, naming the index
+	w = do(http.MethodPost, "/products/_search", `{"from": 10000, "size": 10}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	errorObject := decodeErrorEnvelope(t, w)
+	assert.Equal(t, "illegal_argument_exception", errorObject["type"])
+	assert.Equal(t, "products", errorObject["index"])
+
+	// Handlers that validate their own input answer the same way
+	w = do(http.MethodPut, "/products/_settings", `{"index": {"max_result_window": -1}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "illegal_argument_exception", decodeErrorEnvelope(t, w)["type"])
+}
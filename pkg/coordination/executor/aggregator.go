@@ -46,6 +46,9 @@ func (qe *QueryExecutor) aggregateSearchResults(responses []*pb.SearchResponse,
 					ID:     hit.Id,
 					Score:  hit.Score,
 					Source: sourceMap,
+					Sort:   hit.Sort,
+
+					MatchedQueries: hit.MatchedQueries,
 				})
 			}
 		}
@@ -2,14 +2,20 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/common/tracing"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Prometheus metrics for distributed query monitoring
@@ -78,7 +84,9 @@ var (
 type DataNodeClient interface {
 	Search(ctx context.Context, indexName string, shardID int32, query []byte, filterExpression []byte) (*pb.SearchResponse, error)
 	Count(ctx context.Context, indexName string, shardID int32, query []byte, filterExpression []byte) (*pb.CountResponse, error)
+	Suggest(ctx context.Context, indexName string, shardID int32, suggest []byte) (*pb.SuggestResponse, error)
 	IsConnected() bool
+	IsHealthy() bool
 	Connect(ctx context.Context) error
 	NodeID() string
 }
@@ -133,12 +141,6 @@ func (qe *QueryExecutor) HasDataNodeClient(nodeID string) bool {
 func (qe *QueryExecutor) ExecuteSearch(ctx context.Context, indexName string, query []byte, filterExpression []byte, from, size int) (*SearchResult, error) {
 	startTime := time.Now()
 
-	qe.logger.Info("==> ExecuteSearch ENTRY",
-		zap.String("index", indexName),
-		zap.Int("from", from),
-		zap.Int("size", size),
-		zap.String("query", string(query)))
-
 	// Get shard routing from master
 	routing, err := qe.masterClient.GetShardRouting(ctx, indexName)
 	if err != nil {
@@ -146,7 +148,7 @@ func (qe *QueryExecutor) ExecuteSearch(ctx context.Context, indexName string, qu
 		return nil, fmt.Errorf("failed to get shard routing: %w", err)
 	}
 
-	qe.logger.Info("Got shard routing",
+	qe.logger.Debug("Got shard routing",
 		zap.String("index", indexName),
 		zap.Int("num_shards", len(routing)))
 
@@ -169,135 +171,46 @@ func (qe *QueryExecutor) ExecuteSearch(ctx context.Context, indexName string, qu
 
 	resultsChan := make(chan shardResult, len(routing))
 	var wg sync.WaitGroup
+	var failures []ShardFailure
 
 	for shardID, shard := range routing {
-		qe.logger.Info("Processing shard",
+		qe.logger.Debug("Processing shard",
 			zap.String("index", indexName),
 			zap.Int32("shard_id", shardID),
-			zap.Bool("has_allocation", shard.Allocation != nil))
+			zap.Bool("has_allocation", shard.Allocation != nil),
+			zap.Int("replicas", len(shard.Replicas)))
 
-		// Only query primary or started replicas
-		if shard.Allocation == nil || shard.Allocation.State != pb.ShardAllocation_SHARD_STATE_STARTED {
-			qe.logger.Warn("Skipping shard - not started",
+		// Only query started copies, primary first
+		nodeIDs := shardCopies(shard)
+		if len(nodeIDs) == 0 {
+			qe.logger.Warn("Skipping shard - no started copy",
 				zap.String("index", indexName),
 				zap.Int32("shard_id", shardID),
-				zap.String("state", shard.Allocation.State.String()))
-			continue
-		}
-
-		nodeID := shard.Allocation.NodeId
-		if nodeID == "" {
-			qe.logger.Warn("Shard has no node assignment",
-				zap.String("index", indexName),
-				zap.Int32("shard_id", shardID))
+				zap.String("state", shard.GetAllocation().GetState().String()))
+			failures = append(failures, ShardFailure{
+				Index:  indexName,
+				Shard:  shardID,
+				Reason: fmt.Sprintf("no started copy of shard (state %s)", shard.GetAllocation().GetState()),
+			})
 			continue
 		}
 
-		qe.logger.Info("Querying shard",
-			zap.String("index", indexName),
-			zap.Int32("shard_id", shardID),
-			zap.String("node_id", nodeID))
-
 		wg.Add(1)
-		go func(sid int32, nid string) {
+		go func(sid int32, nodeIDs []string) {
 			defer wg.Done()
 
-			// Track per-shard query latency
-			shardStartTime := time.Now()
-			defer func() {
-				shardQueryLatency.WithLabelValues(
-					indexName,
-					fmt.Sprintf("%d", sid),
-					nid,
-				).Observe(time.Since(shardStartTime).Seconds())
-			}()
-
-			// Get data node client
-			qe.mu.RLock()
-			client, exists := qe.dataClients[nid]
-			qe.mu.RUnlock()
-
-			if !exists {
-				qe.logger.Error("Data node client not found",
-					zap.String("node_id", nid),
-					zap.Int32("shard_id", sid))
-				shardQueryFailures.WithLabelValues(
-					indexName,
-					fmt.Sprintf("%d", sid),
-					nid,
-					"client_not_found",
-				).Inc()
-				resultsChan <- shardResult{
-					shardID: sid,
-					err:     fmt.Errorf("data node %s not found", nid),
-				}
-				return
-			}
-
-			// Ensure client is connected
-			if !client.IsConnected() {
-				if err := client.Connect(ctx); err != nil {
-					qe.logger.Error("Failed to connect to data node",
-						zap.String("node_id", nid),
-						zap.Error(err))
-					shardQueryFailures.WithLabelValues(
-						indexName,
-						fmt.Sprintf("%d", sid),
-						nid,
-						"connection_failed",
-					).Inc()
-					resultsChan <- shardResult{
-						shardID: sid,
-						err:     fmt.Errorf("failed to connect to node %s: %w", nid, err),
-					}
-					return
-				}
-			}
-
-			// Execute search on shard
-			qe.logger.Info("DEBUG: About to call client.Search",
-				zap.Int32("shard_id", sid),
-				zap.String("node_id", nid),
-				zap.String("index", indexName),
-				zap.String("query", string(query)))
-
-			resp, err := client.Search(ctx, indexName, sid, query, filterExpression)
-
-			qe.logger.Info("DEBUG: client.Search returned",
-				zap.Int32("shard_id", sid),
-				zap.String("node_id", nid),
-				zap.Bool("has_response", resp != nil),
-				zap.Bool("has_error", err != nil))
-
-			if resp != nil {
-				var totalHits int64
-				var hitsCount int
-				if resp.Hits != nil {
-					if resp.Hits.Total != nil {
-						totalHits = resp.Hits.Total.Value
-					}
-					hitsCount = len(resp.Hits.Hits)
-				}
-				qe.logger.Info("DEBUG: Search response details",
-					zap.Int32("shard_id", sid),
-					zap.Int64("total_hits", totalHits),
-					zap.Int("hits_count", hitsCount))
-			}
-
-			if err != nil {
-				shardQueryFailures.WithLabelValues(
-					indexName,
-					fmt.Sprintf("%d", sid),
-					nid,
-					"search_failed",
-				).Inc()
-			}
+			var resp *pb.SearchResponse
+			err := qe.tryShardCopies(ctx, indexName, sid, nodeIDs, func(client DataNodeClient) error {
+				var err error
+				resp, err = qe.searchShardCopy(ctx, client, indexName, sid, query, filterExpression)
+				return err
+			})
 			resultsChan <- shardResult{
 				shardID:  sid,
 				response: resp,
 				err:      err,
 			}
-		}(shardID, nodeID)
+		}(shardID, nodeIDs)
 	}
 
 	// Wait for all shard searches to complete
@@ -309,6 +222,7 @@ func (qe *QueryExecutor) ExecuteSearch(ctx context.Context, indexName string, qu
 	// Collect results
 	var shardResponses []*pb.SearchResponse
 	var errors []error
+	var timedOut, terminatedEarly bool
 
 	for result := range resultsChan {
 		if result.err != nil {
@@ -316,15 +230,32 @@ func (qe *QueryExecutor) ExecuteSearch(ctx context.Context, indexName string, qu
 				zap.Int32("shard_id", result.shardID),
 				zap.Error(result.err))
 			errors = append(errors, result.err)
+			reason := result.err.Error()
+			if isDeadlineExceeded(result.err) {
+				timedOut = true
+				reason = "shard search timed out"
+			}
+			failures = append(failures, ShardFailure{
+				Index:  indexName,
+				Shard:  result.shardID,
+				Reason: reason,
+			})
 			continue
 		}
+		if result.response.TimedOut {
+			timedOut = true
+		}
+		if result.response.TerminatedEarly {
+			terminatedEarly = true
+		}
 		shardResponses = append(shardResponses, result.response)
 	}
 
-	// Check if we have any successful results
-	if len(shardResponses) == 0 {
+	// Check if we have any successful results. A search that ran out of time
+	// before any shard answered still reports an empty, timed out result.
+	if len(shardResponses) == 0 && !timedOut {
 		if len(errors) > 0 {
-			return nil, fmt.Errorf("all shard searches failed: %v", errors[0])
+			return nil, fmt.Errorf("all shard searches failed: %w", errors[0])
 		}
 		return nil, fmt.Errorf("no shards available for index %s", indexName)
 	}
@@ -332,6 +263,18 @@ func (qe *QueryExecutor) ExecuteSearch(ctx context.Context, indexName string, qu
 	// Aggregate results
 	aggregatedResult := qe.aggregateSearchResults(shardResponses, from, size)
 	aggregatedResult.TookMillis = time.Since(startTime).Milliseconds()
+	aggregatedResult.TimedOut = timedOut
+	aggregatedResult.TerminatedEarly = terminatedEarly
+
+	// Shards without a started copy count as failed along with shards whose
+	// every copy failed
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Shard < failures[j].Shard })
+	aggregatedResult.Shards = ShardStats{
+		Total:      len(routing),
+		Successful: len(shardResponses),
+		Failed:     len(routing) - len(shardResponses),
+		Failures:   failures,
+	}
 
 	// Record metrics
 	distributedSearchLatency.WithLabelValues(indexName).Observe(time.Since(startTime).Seconds())
@@ -347,69 +290,57 @@ func (qe *QueryExecutor) ExecuteSearch(ctx context.Context, indexName string, qu
 	return aggregatedResult, nil
 }
 
-// ExecuteCount executes a count query across all relevant shards
-func (qe *QueryExecutor) ExecuteCount(ctx context.Context, indexName string, query []byte, filterExpression []byte) (int64, error) {
+// ExecuteCount executes a count query across all relevant shards, summing
+// the per-shard counts
+func (qe *QueryExecutor) ExecuteCount(ctx context.Context, indexName string, query []byte, filterExpression []byte) (*CountResult, error) {
 	// Get shard routing from master
 	routing, err := qe.masterClient.GetShardRouting(ctx, indexName)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get shard routing: %w", err)
+		return nil, fmt.Errorf("failed to get shard routing: %w", err)
 	}
 
 	if len(routing) == 0 {
-		return 0, nil
+		return &CountResult{}, nil
 	}
 
 	// Execute count on all shards in parallel
 	type shardResult struct {
-		count int64
-		err   error
+		shardID int32
+		count   int64
+		err     error
 	}
 
 	resultsChan := make(chan shardResult, len(routing))
 	var wg sync.WaitGroup
+	var failures []ShardFailure
 
 	for shardID, shard := range routing {
-		// Only query primary or started replicas
-		if shard.Allocation == nil || shard.Allocation.State != pb.ShardAllocation_SHARD_STATE_STARTED {
-			continue
-		}
-
-		nodeID := shard.Allocation.NodeId
-		if nodeID == "" {
+		// Only query started copies, primary first
+		nodeIDs := shardCopies(shard)
+		if len(nodeIDs) == 0 {
+			failures = append(failures, ShardFailure{
+				Index:  indexName,
+				Shard:  shardID,
+				Reason: fmt.Sprintf("no started copy of shard (state %s)", shard.GetAllocation().GetState()),
+			})
 			continue
 		}
 
 		wg.Add(1)
-		go func(sid int32, nid string) {
+		go func(sid int32, nodeIDs []string) {
 			defer wg.Done()
 
-			// Get data node client
-			qe.mu.RLock()
-			client, exists := qe.dataClients[nid]
-			qe.mu.RUnlock()
-
-			if !exists {
-				resultsChan <- shardResult{err: fmt.Errorf("data node %s not found", nid)}
-				return
-			}
-
-			// Ensure client is connected
-			if !client.IsConnected() {
-				if err := client.Connect(ctx); err != nil {
-					resultsChan <- shardResult{err: err}
-					return
+			var count int64
+			err := qe.tryShardCopies(ctx, indexName, sid, nodeIDs, func(client DataNodeClient) error {
+				resp, err := client.Count(ctx, indexName, sid, query, filterExpression)
+				if err != nil {
+					return err
 				}
-			}
-
-			// Execute count on shard
-			resp, err := client.Count(ctx, indexName, sid, query, filterExpression)
-			if err != nil {
-				resultsChan <- shardResult{err: err}
-				return
-			}
-
-			resultsChan <- shardResult{count: resp.Count}
-		}(shardID, nodeID)
+				count = resp.Count
+				return nil
+			})
+			resultsChan <- shardResult{shardID: sid, count: count, err: err}
+		}(shardID, nodeIDs)
 	}
 
 	// Wait for all shard counts to complete
@@ -419,25 +350,225 @@ func (qe *QueryExecutor) ExecuteCount(ctx context.Context, indexName string, que
 	}()
 
 	// Sum up counts
-	var totalCount int64
-	for result := range resultsChan {
-		if result.err != nil {
-			qe.logger.Error("Shard count failed", zap.Error(result.err))
+	result := &CountResult{}
+	var errors []error
+	for shard := range resultsChan {
+		if shard.err != nil {
+			qe.logger.Error("Shard count failed",
+				zap.Int32("shard_id", shard.shardID),
+				zap.Error(shard.err))
+			errors = append(errors, shard.err)
+			failures = append(failures, ShardFailure{
+				Index:  indexName,
+				Shard:  shard.shardID,
+				Reason: shard.err.Error(),
+			})
 			continue
 		}
-		totalCount += result.count
+		result.Count += shard.count
+		result.Shards.Successful++
+	}
+
+	if result.Shards.Successful == 0 {
+		if len(errors) > 0 {
+			return nil, fmt.Errorf("all shard counts failed: %w", errors[0])
+		}
+		return nil, fmt.Errorf("no shards available for index %s", indexName)
+	}
+
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Shard < failures[j].Shard })
+	result.Shards.Total = len(routing)
+	result.Shards.Failed = len(routing) - result.Shards.Successful
+	result.Shards.Failures = failures
+
+	return result, nil
+}
+
+// shardCopies returns the nodes holding a started or relocating copy of
+// shard, primary first, in the order they should be tried
+func shardCopies(shard *pb.ShardRouting) []string {
+	var nodeIDs []string
+	add := func(allocation *pb.ShardAllocation) {
+		state := allocation.GetState()
+		active := state == pb.ShardAllocation_SHARD_STATE_STARTED || state == pb.ShardAllocation_SHARD_STATE_RELOCATING
+		if active && allocation.GetNodeId() != "" {
+			nodeIDs = append(nodeIDs, allocation.GetNodeId())
+		}
+	}
+
+	add(shard.GetAllocation())
+	for _, replica := range shard.GetReplicas() {
+		add(replica)
+	}
+	return nodeIDs
+}
+
+// tryShardCopies runs fn against each copy of a shard in turn until one
+// succeeds. It returns the last error if every copy failed.
+func (qe *QueryExecutor) tryShardCopies(ctx context.Context, indexName string, shardID int32, nodeIDs []string, fn func(client DataNodeClient) error) error {
+	var lastErr error
+	for i, nodeID := range nodeIDs {
+		client, err := qe.shardClient(ctx, indexName, shardID, nodeID)
+		if err == nil {
+			if err = fn(client); err == nil {
+				return nil
+			}
+		}
+		lastErr = err
+
+		if ctx.Err() != nil {
+			break
+		}
+		if i+1 < len(nodeIDs) {
+			qe.logger.Warn("Shard request failed, retrying on replica",
+				zap.String("index", indexName),
+				zap.Int32("shard_id", shardID),
+				zap.String("node_id", nodeID),
+				zap.String("replica_node_id", nodeIDs[i+1]),
+				zap.Error(err))
+		}
+	}
+	return lastErr
+}
+
+// isDeadlineExceeded reports whether err comes from the search deadline
+// expiring, either locally or on the data node
+func isDeadlineExceeded(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded
+}
+
+// shardClient returns a connected client for the node holding a shard copy
+func (qe *QueryExecutor) shardClient(ctx context.Context, indexName string, shardID int32, nodeID string) (DataNodeClient, error) {
+	qe.mu.RLock()
+	client, exists := qe.dataClients[nodeID]
+	qe.mu.RUnlock()
+
+	if !exists {
+		qe.logger.Error("Data node client not found",
+			zap.String("node_id", nodeID),
+			zap.Int32("shard_id", shardID))
+		shardQueryFailures.WithLabelValues(
+			indexName,
+			fmt.Sprintf("%d", shardID),
+			nodeID,
+			"client_not_found",
+		).Inc()
+		return nil, fmt.Errorf("data node %s not found", nodeID)
+	}
+
+	// Fail fast on nodes whose health probe failed rather than
+	// waiting for the request to time out
+	if !client.IsHealthy() {
+		shardQueryFailures.WithLabelValues(
+			indexName,
+			fmt.Sprintf("%d", shardID),
+			nodeID,
+			"node_unhealthy",
+		).Inc()
+		return nil, fmt.Errorf("data node %s is unhealthy", nodeID)
+	}
+
+	// Ensure client is connected
+	if !client.IsConnected() {
+		if err := client.Connect(ctx); err != nil {
+			qe.logger.Error("Failed to connect to data node",
+				zap.String("node_id", nodeID),
+				zap.Error(err))
+			shardQueryFailures.WithLabelValues(
+				indexName,
+				fmt.Sprintf("%d", shardID),
+				nodeID,
+				"connection_failed",
+			).Inc()
+			return nil, fmt.Errorf("failed to connect to node %s: %w", nodeID, err)
+		}
 	}
 
-	return totalCount, nil
+	return client, nil
+}
+
+// searchShardCopy searches one copy of a shard, recording latency and failures
+func (qe *QueryExecutor) searchShardCopy(ctx context.Context, client DataNodeClient, indexName string, shardID int32, query []byte, filterExpression []byte) (resp *pb.SearchResponse, err error) {
+	nodeID := client.NodeID()
+
+	ctx, span := tracing.StartSpan(ctx, "search.shard",
+		attribute.String("index", indexName),
+		attribute.Int("shard_id", int(shardID)),
+		attribute.String("node_id", nodeID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	// Track per-shard query latency
+	shardStartTime := time.Now()
+	defer func() {
+		shardQueryLatency.WithLabelValues(
+			indexName,
+			fmt.Sprintf("%d", shardID),
+			nodeID,
+		).Observe(time.Since(shardStartTime).Seconds())
+	}()
+
+	qe.logger.Debug("Querying shard",
+		zap.String("index", indexName),
+		zap.Int32("shard_id", shardID),
+		zap.String("node_id", nodeID))
+
+	resp, err = client.Search(ctx, indexName, shardID, query, filterExpression)
+	if err != nil {
+		shardQueryFailures.WithLabelValues(
+			indexName,
+			fmt.Sprintf("%d", shardID),
+			nodeID,
+			"search_failed",
+		).Inc()
+		return nil, err
+	}
+
+	if resp.Hits != nil {
+		var totalHits int64
+		if resp.Hits.Total != nil {
+			totalHits = resp.Hits.Total.Value
+		}
+		qe.logger.Debug("Shard search response",
+			zap.Int32("shard_id", shardID),
+			zap.String("node_id", nodeID),
+			zap.Int64("total_hits", totalHits),
+			zap.Int("hits_count", len(resp.Hits.Hits)))
+	}
+
+	return resp, nil
 }
 
 // SearchResult represents aggregated search results
 type SearchResult struct {
-	TookMillis   int64
-	TotalHits    int64
-	MaxScore     float64
-	Hits         []*SearchHit
-	Aggregations map[string]*AggregationResult
+	TookMillis      int64
+	TotalHits       int64
+	MaxScore        float64
+	Hits            []*SearchHit
+	Aggregations    map[string]*AggregationResult
+	Shards          ShardStats
+	TimedOut        bool // At least one shard ran out of time
+	TerminatedEarly bool // At least one shard stopped at terminate_after
+}
+
+// CountResult represents the aggregated result of a count across shards
+type CountResult struct {
+	Count  int64
+	Shards ShardStats
+}
+
+// ShardStats counts the shards a search was sent to
+type ShardStats struct {
+	Total      int
+	Successful int
+	Failed     int
+	Failures   []ShardFailure
+}
+
+// ShardFailure describes why a shard didn't contribute to a search
+type ShardFailure struct {
+	Index  string
+	Shard  int32
+	Reason string
 }
 
 // AggregationResult represents an aggregation result
@@ -476,4 +607,7 @@ type SearchHit struct {
 	ID     string
 	Score  float64
 	Source map[string]interface{}
+	Sort   []float64 // Script sort keys computed by the shard
+
+	MatchedQueries []string // Names of the named clauses the hit matched
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	pb "github.com/quidditch/quidditch/pkg/common/proto"
 	"github.com/stretchr/testify/assert"
@@ -15,7 +16,8 @@ import (
 // MockDataNodeClient is a mock implementation of DataNodeClient
 type MockDataNodeClient struct {
 	mock.Mock
-	nodeID string
+	nodeID    string
+	unhealthy bool
 }
 
 func (m *MockDataNodeClient) Search(ctx context.Context, indexName string, shardID int32, query []byte, filterExpression []byte) (*pb.SearchResponse, error) {
@@ -34,11 +36,23 @@ func (m *MockDataNodeClient) Count(ctx context.Context, indexName string, shardI
 	return args.Get(0).(*pb.CountResponse), args.Error(1)
 }
 
+func (m *MockDataNodeClient) Suggest(ctx context.Context, indexName string, shardID int32, suggest []byte) (*pb.SuggestResponse, error) {
+	args := m.Called(ctx, indexName, shardID, suggest)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.SuggestResponse), args.Error(1)
+}
+
 func (m *MockDataNodeClient) IsConnected() bool {
 	args := m.Called()
 	return args.Bool(0)
 }
 
+func (m *MockDataNodeClient) IsHealthy() bool {
+	return !m.unhealthy
+}
+
 func (m *MockDataNodeClient) Connect(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
@@ -109,7 +123,7 @@ func TestQueryExecutorSearchTwoShards(t *testing.T) {
 	// Setup mock data node clients
 	node1 := &MockDataNodeClient{nodeID: "node1"}
 	node1.On("IsConnected").Return(true)
-	node1.On("Search", ctx, "test-index", int32(0), mock.Anything, mock.Anything).Return(
+	node1.On("Search", mock.Anything, "test-index", int32(0), mock.Anything, mock.Anything).Return(
 		&pb.SearchResponse{
 			TookMillis: 10,
 			Hits: &pb.SearchHits{
@@ -126,7 +140,7 @@ func TestQueryExecutorSearchTwoShards(t *testing.T) {
 
 	node2 := &MockDataNodeClient{nodeID: "node2"}
 	node2.On("IsConnected").Return(true)
-	node2.On("Search", ctx, "test-index", int32(1), mock.Anything, mock.Anything).Return(
+	node2.On("Search", mock.Anything, "test-index", int32(1), mock.Anything, mock.Anything).Return(
 		&pb.SearchResponse{
 			TookMillis: 12,
 			Hits: &pb.SearchHits{
@@ -198,7 +212,7 @@ func TestQueryExecutorSearchWithPagination(t *testing.T) {
 	}
 
 	node1.On("IsConnected").Return(true)
-	node1.On("Search", ctx, "test-index", int32(0), mock.Anything, mock.Anything).Return(
+	node1.On("Search", mock.Anything, "test-index", int32(0), mock.Anything, mock.Anything).Return(
 		&pb.SearchResponse{
 			TookMillis: 5,
 			Hits: &pb.SearchHits{
@@ -251,7 +265,7 @@ func TestQueryExecutorPartialShardFailure(t *testing.T) {
 	// Setup mock data nodes
 	node1 := &MockDataNodeClient{nodeID: "node1"}
 	node1.On("IsConnected").Return(true)
-	node1.On("Search", ctx, "test-index", int32(0), mock.Anything, mock.Anything).Return(
+	node1.On("Search", mock.Anything, "test-index", int32(0), mock.Anything, mock.Anything).Return(
 		&pb.SearchResponse{
 			Hits: &pb.SearchHits{
 				Total: &pb.TotalHits{Value: 30, Relation: "eq"},
@@ -264,14 +278,14 @@ func TestQueryExecutorPartialShardFailure(t *testing.T) {
 	node2 := &MockDataNodeClient{nodeID: "node2"}
 	node2.On("IsConnected").Return(true)
 	// Node2 fails
-	node2.On("Search", ctx, "test-index", int32(1), mock.Anything, mock.Anything).Return(
+	node2.On("Search", mock.Anything, "test-index", int32(1), mock.Anything, mock.Anything).Return(
 		(*pb.SearchResponse)(nil),
 		errors.New("connection timeout"),
 	)
 
 	node3 := &MockDataNodeClient{nodeID: "node3"}
 	node3.On("IsConnected").Return(true)
-	node3.On("Search", ctx, "test-index", int32(2), mock.Anything, mock.Anything).Return(
+	node3.On("Search", mock.Anything, "test-index", int32(2), mock.Anything, mock.Anything).Return(
 		&pb.SearchResponse{
 			Hits: &pb.SearchHits{
 				Total: &pb.TotalHits{Value: 35, Relation: "eq"},
@@ -293,6 +307,124 @@ func TestQueryExecutorPartialShardFailure(t *testing.T) {
 	// Verify graceful degradation
 	require.NoError(t, err, "Search should succeed despite partial shard failure")
 	assert.Equal(t, int64(65), result.TotalHits) // 30 + 35 (node2 excluded)
+	assert.Equal(t, 3, result.Shards.Total)
+	assert.Equal(t, 2, result.Shards.Successful)
+	assert.Equal(t, 1, result.Shards.Failed)
+	require.Len(t, result.Shards.Failures, 1)
+	assert.Equal(t, "test-index", result.Shards.Failures[0].Index)
+	assert.Equal(t, int32(1), result.Shards.Failures[0].Shard)
+	assert.Contains(t, result.Shards.Failures[0].Reason, "connection timeout")
+
+	masterClient.AssertExpectations(t)
+	node1.AssertExpectations(t)
+	node2.AssertExpectations(t)
+	node3.AssertExpectations(t)
+}
+
+// TestQueryExecutorTerminatedEarly tests that a shard stopping at
+// terminate_after marks the aggregated result as terminated early
+func TestQueryExecutorTerminatedEarly(t *testing.T) {
+	logger := zap.NewNop()
+	ctx := WithShardSearchOptions(context.Background(), ShardSearchOptions{TerminateAfter: 5})
+
+	masterClient := new(MockMasterClient)
+	masterClient.On("GetShardRouting", ctx, "test-index").Return(
+		map[int32]*pb.ShardRouting{
+			0: {ShardId: 0, Allocation: &pb.ShardAllocation{NodeId: "node1", State: pb.ShardAllocation_SHARD_STATE_STARTED}},
+			1: {ShardId: 1, Allocation: &pb.ShardAllocation{NodeId: "node2", State: pb.ShardAllocation_SHARD_STATE_STARTED}},
+		},
+		nil,
+	)
+
+	// The options travel with the context to each shard request
+	withOptions := mock.MatchedBy(func(ctx context.Context) bool {
+		return ShardSearchOptionsFromContext(ctx).TerminateAfter == 5
+	})
+
+	node1 := &MockDataNodeClient{nodeID: "node1"}
+	node1.On("IsConnected").Return(true)
+	node1.On("Search", withOptions, "test-index", int32(0), mock.Anything, mock.Anything).Return(
+		&pb.SearchResponse{
+			TerminatedEarly: true,
+			Hits:            &pb.SearchHits{Total: &pb.TotalHits{Value: 5, Relation: "eq"}},
+		},
+		nil,
+	)
+
+	node2 := &MockDataNodeClient{nodeID: "node2"}
+	node2.On("IsConnected").Return(true)
+	node2.On("Search", withOptions, "test-index", int32(1), mock.Anything, mock.Anything).Return(
+		&pb.SearchResponse{
+			Hits: &pb.SearchHits{Total: &pb.TotalHits{Value: 3, Relation: "eq"}},
+		},
+		nil,
+	)
+
+	executor := NewQueryExecutor(masterClient, logger)
+	executor.RegisterDataNode(node1)
+	executor.RegisterDataNode(node2)
+
+	result, err := executor.ExecuteSearch(ctx, "test-index", []byte(`{"match_all": {}}`), nil, 0, 10)
+	require.NoError(t, err)
+	assert.True(t, result.TerminatedEarly)
+	assert.Equal(t, int64(8), result.TotalHits)
+
+	node1.AssertExpectations(t)
+	node2.AssertExpectations(t)
+}
+
+// TestQueryExecutorCountMultiShard tests that a count is summed across every
+// shard and that a failed or unassigned shard is reported in the shard stats
+func TestQueryExecutorCountMultiShard(t *testing.T) {
+	logger := zap.NewNop()
+	ctx := context.Background()
+
+	masterClient := new(MockMasterClient)
+	masterClient.On("GetShardRouting", ctx, "test-index").Return(
+		map[int32]*pb.ShardRouting{
+			0: {ShardId: 0, Allocation: &pb.ShardAllocation{NodeId: "node1", State: pb.ShardAllocation_SHARD_STATE_STARTED}},
+			1: {ShardId: 1, Allocation: &pb.ShardAllocation{NodeId: "node2", State: pb.ShardAllocation_SHARD_STATE_STARTED}},
+			2: {ShardId: 2, Allocation: &pb.ShardAllocation{NodeId: "node3", State: pb.ShardAllocation_SHARD_STATE_STARTED}},
+			3: {ShardId: 3, Allocation: &pb.ShardAllocation{NodeId: "node3", State: pb.ShardAllocation_SHARD_STATE_INITIALIZING}},
+		},
+		nil,
+	)
+
+	query := []byte(`{"term": {"status": "active"}}`)
+
+	node1 := &MockDataNodeClient{nodeID: "node1"}
+	node1.On("IsConnected").Return(true)
+	node1.On("Count", mock.Anything, "test-index", int32(0), query, mock.Anything).Return(
+		&pb.CountResponse{Count: 12}, nil,
+	)
+
+	node2 := &MockDataNodeClient{nodeID: "node2"}
+	node2.On("IsConnected").Return(true)
+	node2.On("Count", mock.Anything, "test-index", int32(1), query, mock.Anything).Return(
+		(*pb.CountResponse)(nil), errors.New("connection refused"),
+	)
+
+	node3 := &MockDataNodeClient{nodeID: "node3"}
+	node3.On("IsConnected").Return(true)
+	node3.On("Count", mock.Anything, "test-index", int32(2), query, mock.Anything).Return(
+		&pb.CountResponse{Count: 30}, nil,
+	)
+
+	executor := NewQueryExecutor(masterClient, logger)
+	executor.RegisterDataNode(node1)
+	executor.RegisterDataNode(node2)
+	executor.RegisterDataNode(node3)
+
+	result, err := executor.ExecuteCount(ctx, "test-index", query, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), result.Count)
+	assert.Equal(t, 4, result.Shards.Total)
+	assert.Equal(t, 2, result.Shards.Successful)
+	assert.Equal(t, 2, result.Shards.Failed)
+	require.Len(t, result.Shards.Failures, 2)
+	assert.Equal(t, int32(1), result.Shards.Failures[0].Shard)
+	assert.Contains(t, result.Shards.Failures[0].Reason, "connection refused")
+	assert.Equal(t, int32(3), result.Shards.Failures[1].Shard)
 
 	masterClient.AssertExpectations(t)
 	node1.AssertExpectations(t)
@@ -300,6 +432,293 @@ func TestQueryExecutorPartialShardFailure(t *testing.T) {
 	node3.AssertExpectations(t)
 }
 
+// TestQueryExecutorSuggestMergesShards tests that term suggestions from
+// every shard are merged, summing the frequencies of a shared correction
+func TestQueryExecutorSuggestMergesShards(t *testing.T) {
+	logger := zap.NewNop()
+	ctx := context.Background()
+
+	masterClient := new(MockMasterClient)
+	masterClient.On("GetShardRouting", ctx, "test-index").Return(
+		map[int32]*pb.ShardRouting{
+			0: {ShardId: 0, Allocation: &pb.ShardAllocation{NodeId: "node1", State: pb.ShardAllocation_SHARD_STATE_STARTED}},
+			1: {ShardId: 1, Allocation: &pb.ShardAllocation{NodeId: "node2", State: pb.ShardAllocation_SHARD_STATE_STARTED}},
+		},
+		nil,
+	)
+
+	shardSuggestions := func(options ...*pb.SuggestOption) *pb.SuggestResponse {
+		return &pb.SuggestResponse{Suggestions: map[string]*pb.SuggestEntries{
+			"spelling": {Entries: []*pb.SuggestEntry{{Text: "qiuck", Offset: 0, Length: 5, Options: options}}},
+		}}
+	}
+
+	node1 := &MockDataNodeClient{nodeID: "node1"}
+	node1.On("IsConnected").Return(true)
+	node1.On("Suggest", mock.Anything, "test-index", int32(0), mock.Anything).Return(
+		shardSuggestions(&pb.SuggestOption{Text: "quick", Score: 0.6, Freq: 2}), nil,
+	)
+
+	node2 := &MockDataNodeClient{nodeID: "node2"}
+	node2.On("IsConnected").Return(true)
+	node2.On("Suggest", mock.Anything, "test-index", int32(1), mock.Anything).Return(
+		shardSuggestions(&pb.SuggestOption{Text: "quack", Score: 0.6, Freq: 2}, &pb.SuggestOption{Text: "quick", Score: 0.6, Freq: 1}), nil,
+	)
+
+	executor := NewQueryExecutor(masterClient, logger)
+	executor.RegisterDataNode(node1)
+	executor.RegisterDataNode(node2)
+
+	results, err := executor.ExecuteSuggest(ctx, "test-index", map[string]interface{}{
+		"spelling": map[string]interface{}{"text": "qiuck", "term": map[string]interface{}{"field": "title"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results["spelling"], 1)
+
+	options := results["spelling"][0].Options
+	require.Len(t, options, 2)
+	assert.Equal(t, "quick", options[0].Text)
+	assert.Equal(t, int64(3), options[0].Freq)
+	assert.Equal(t, "quack", options[1].Text)
+
+	// An invalid suggest block is rejected before any shard is asked
+	_, err = executor.ExecuteSuggest(ctx, "test-index", map[string]interface{}{"spelling": map[string]interface{}{"text": "x"}})
+	assert.Error(t, err)
+
+	node1.AssertExpectations(t)
+	node2.AssertExpectations(t)
+}
+
+// TestQueryExecutorReplicaFallback tests that a shard whose primary fails is
+// served by a replica
+func TestQueryExecutorReplicaFallback(t *testing.T) {
+	logger := zap.NewNop()
+	ctx := context.Background()
+
+	started := func(nodeID string) *pb.ShardAllocation {
+		return &pb.ShardAllocation{NodeId: nodeID, State: pb.ShardAllocation_SHARD_STATE_STARTED}
+	}
+
+	masterClient := new(MockMasterClient)
+	masterClient.On("GetShardRouting", ctx, "test-index").Return(
+		map[int32]*pb.ShardRouting{
+			0: {ShardId: 0, IsPrimary: true, Allocation: started("node1"), Replicas: []*pb.ShardAllocation{started("node2")}},
+			1: {ShardId: 1, IsPrimary: true, Allocation: started("node2"), Replicas: []*pb.ShardAllocation{started("node1")}},
+		},
+		nil,
+	)
+
+	// node1 is down: both its primary and its replica copy fail
+	node1 := &MockDataNodeClient{nodeID: "node1"}
+	node1.On("IsConnected").Return(true)
+	node1.On("Search", mock.Anything, "test-index", mock.Anything, mock.Anything, mock.Anything).Return(
+		(*pb.SearchResponse)(nil),
+		errors.New("connection refused"),
+	)
+
+	node2 := &MockDataNodeClient{nodeID: "node2"}
+	node2.On("IsConnected").Return(true)
+	node2.On("Search", mock.Anything, "test-index", int32(0), mock.Anything, mock.Anything).Return(
+		&pb.SearchResponse{
+			Hits: &pb.SearchHits{
+				Total: &pb.TotalHits{Value: 30, Relation: "eq"},
+				Hits:  []*pb.SearchHit{{Id: "doc1", Score: 1.0}},
+			},
+		},
+		nil,
+	)
+	node2.On("Search", mock.Anything, "test-index", int32(1), mock.Anything, mock.Anything).Return(
+		&pb.SearchResponse{
+			Hits: &pb.SearchHits{
+				Total: &pb.TotalHits{Value: 20, Relation: "eq"},
+				Hits:  []*pb.SearchHit{{Id: "doc2", Score: 0.5}},
+			},
+		},
+		nil,
+	)
+
+	executor := NewQueryExecutor(masterClient, logger)
+	executor.RegisterDataNode(node1)
+	executor.RegisterDataNode(node2)
+
+	result, err := executor.ExecuteSearch(ctx, "test-index", []byte(`{"match_all": {}}`), nil, 0, 10)
+	require.NoError(t, err)
+
+	// Shard 0 fell back to its replica on node2; shard 1's primary served it
+	assert.Equal(t, int64(50), result.TotalHits)
+	assert.Len(t, result.Hits, 2)
+	assert.Equal(t, ShardStats{Total: 2, Successful: 2, Failed: 0}, result.Shards)
+	node1.AssertCalled(t, "Search", mock.Anything, "test-index", int32(0), mock.Anything, mock.Anything)
+	node1.AssertNotCalled(t, "Search", mock.Anything, "test-index", int32(1), mock.Anything, mock.Anything)
+
+	// Counts fall back the same way
+	node1.On("Count", ctx, "test-index", mock.Anything, mock.Anything, mock.Anything).Return(
+		(*pb.CountResponse)(nil),
+		errors.New("connection refused"),
+	)
+	node2.On("Count", ctx, "test-index", int32(0), mock.Anything, mock.Anything).Return(&pb.CountResponse{Count: 30}, nil)
+	node2.On("Count", ctx, "test-index", int32(1), mock.Anything, mock.Anything).Return(&pb.CountResponse{Count: 20}, nil)
+
+	count, err := executor.ExecuteCount(ctx, "test-index", []byte(`{"match_all": {}}`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), count.Count)
+	assert.Equal(t, ShardStats{Total: 2, Successful: 2, Failed: 0}, count.Shards)
+}
+
+// TestQueryExecutorAllCopiesFail tests that a shard is only failed once
+// every copy has failed
+func TestQueryExecutorAllCopiesFail(t *testing.T) {
+	logger := zap.NewNop()
+	ctx := context.Background()
+
+	started := func(nodeID string) *pb.ShardAllocation {
+		return &pb.ShardAllocation{NodeId: nodeID, State: pb.ShardAllocation_SHARD_STATE_STARTED}
+	}
+
+	masterClient := new(MockMasterClient)
+	masterClient.On("GetShardRouting", ctx, "test-index").Return(
+		map[int32]*pb.ShardRouting{
+			0: {ShardId: 0, IsPrimary: true, Allocation: started("node1"), Replicas: []*pb.ShardAllocation{started("node2")}},
+			1: {ShardId: 1, IsPrimary: true, Allocation: started("node3")},
+		},
+		nil,
+	)
+
+	failing := func(nodeID string) *MockDataNodeClient {
+		node := &MockDataNodeClient{nodeID: nodeID}
+		node.On("IsConnected").Return(true)
+		node.On("Search", mock.Anything, "test-index", int32(0), mock.Anything, mock.Anything).Return(
+			(*pb.SearchResponse)(nil),
+			errors.New("shard unavailable"),
+		)
+		return node
+	}
+	node1 := failing("node1")
+	node2 := failing("node2")
+
+	node3 := &MockDataNodeClient{nodeID: "node3"}
+	node3.On("IsConnected").Return(true)
+	node3.On("Search", mock.Anything, "test-index", int32(1), mock.Anything, mock.Anything).Return(
+		&pb.SearchResponse{
+			Hits: &pb.SearchHits{
+				Total: &pb.TotalHits{Value: 10, Relation: "eq"},
+				Hits:  []*pb.SearchHit{},
+			},
+		},
+		nil,
+	)
+
+	executor := NewQueryExecutor(masterClient, logger)
+	executor.RegisterDataNode(node1)
+	executor.RegisterDataNode(node2)
+	executor.RegisterDataNode(node3)
+
+	result, err := executor.ExecuteSearch(ctx, "test-index", []byte(`{"match_all": {}}`), nil, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), result.TotalHits)
+	assert.Equal(t, 1, result.Shards.Failed)
+	require.Len(t, result.Shards.Failures, 1)
+	assert.Equal(t, int32(0), result.Shards.Failures[0].Shard)
+	assert.Contains(t, result.Shards.Failures[0].Reason, "shard unavailable")
+
+	// Both copies of shard 0 were tried
+	node1.AssertExpectations(t)
+	node2.AssertExpectations(t)
+}
+
+// TestQueryExecutorSkipsUnhealthyNode tests that shards on unhealthy nodes
+// fail fast without a search request
+// TestQueryExecutorSearchTimeout tests that a slow shard flips timed_out
+func TestQueryExecutorSearchTimeout(t *testing.T) {
+	logger := zap.NewNop()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	masterClient := new(MockMasterClient)
+	masterClient.On("GetShardRouting", mock.Anything, "test-index").Return(
+		map[int32]*pb.ShardRouting{
+			0: {ShardId: 0, Allocation: &pb.ShardAllocation{NodeId: "node1", State: pb.ShardAllocation_SHARD_STATE_STARTED}},
+			1: {ShardId: 1, Allocation: &pb.ShardAllocation{NodeId: "node2", State: pb.ShardAllocation_SHARD_STATE_STARTED}},
+		},
+		nil,
+	)
+
+	fast := &MockDataNodeClient{nodeID: "node1"}
+	fast.On("IsConnected").Return(true)
+	fast.On("Search", mock.Anything, "test-index", int32(0), mock.Anything, mock.Anything).Return(
+		&pb.SearchResponse{
+			Hits: &pb.SearchHits{
+				Total: &pb.TotalHits{Value: 1, Relation: "eq"},
+				Hits:  []*pb.SearchHit{{Id: "doc1", Score: 1.0}},
+			},
+		},
+		nil,
+	)
+
+	// The slow shard only answers once the deadline has passed
+	slow := &MockDataNodeClient{nodeID: "node2"}
+	slow.On("IsConnected").Return(true)
+	slow.On("Search", mock.Anything, "test-index", int32(1), mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			<-args.Get(0).(context.Context).Done()
+		}).
+		Return((*pb.SearchResponse)(nil), context.DeadlineExceeded)
+
+	executor := NewQueryExecutor(masterClient, logger)
+	executor.RegisterDataNode(fast)
+	executor.RegisterDataNode(slow)
+
+	result, err := executor.ExecuteSearch(ctx, "test-index", []byte(`{"match_all": {}}`), nil, 0, 10)
+	require.NoError(t, err)
+	assert.True(t, result.TimedOut)
+	require.Len(t, result.Hits, 1)
+	assert.Equal(t, "doc1", result.Hits[0].ID)
+	assert.Equal(t, 1, result.Shards.Failed)
+	require.Len(t, result.Shards.Failures, 1)
+	assert.Equal(t, int32(1), result.Shards.Failures[0].Shard)
+	assert.Equal(t, "shard search timed out", result.Shards.Failures[0].Reason)
+}
+
+func TestQueryExecutorSkipsUnhealthyNode(t *testing.T) {
+	logger := zap.NewNop()
+	ctx := context.Background()
+
+	masterClient := new(MockMasterClient)
+	masterClient.On("GetShardRouting", ctx, "test-index").Return(
+		map[int32]*pb.ShardRouting{
+			0: {ShardId: 0, Allocation: &pb.ShardAllocation{NodeId: "node1", State: pb.ShardAllocation_SHARD_STATE_STARTED}},
+			1: {ShardId: 1, Allocation: &pb.ShardAllocation{NodeId: "node2", State: pb.ShardAllocation_SHARD_STATE_STARTED}},
+		},
+		nil,
+	)
+
+	node1 := &MockDataNodeClient{nodeID: "node1"}
+	node1.On("IsConnected").Return(true)
+	node1.On("Search", mock.Anything, "test-index", int32(0), mock.Anything, mock.Anything).Return(
+		&pb.SearchResponse{
+			Hits: &pb.SearchHits{
+				Total: &pb.TotalHits{Value: 30, Relation: "eq"},
+				Hits:  []*pb.SearchHit{},
+			},
+		},
+		nil,
+	)
+
+	// No expectations: an unhealthy node must not be searched
+	node2 := &MockDataNodeClient{nodeID: "node2", unhealthy: true}
+
+	executor := NewQueryExecutor(masterClient, logger)
+	executor.RegisterDataNode(node1)
+	executor.RegisterDataNode(node2)
+
+	result, err := executor.ExecuteSearch(ctx, "test-index", []byte(`{"match_all": {}}`), nil, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(30), result.TotalHits)
+
+	node1.AssertExpectations(t)
+	node2.AssertExpectations(t)
+}
+
 // TestQueryExecutorNoDataNodes tests behavior with no data nodes
 func TestQueryExecutorNoDataNodes(t *testing.T) {
 	logger := zap.NewNop()
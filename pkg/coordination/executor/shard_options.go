@@ -0,0 +1,28 @@
+package executor
+
+import "context"
+
+// ShardSearchOptions carries per-request settings that each shard applies
+// while collecting hits
+type ShardSearchOptions struct {
+	Size           int32    // Top hits each shard returns (0 = the shard default of 10)
+	TerminateAfter int64    // Stop collecting after this many matches per shard (0 = no limit)
+	MinScore       float64  // Drop matches scoring below this (0 = keep all)
+	Sort           []string // _script sort clauses (JSON) the shards compute sort keys for
+	CollapseField  string   // Keep only the best hits per distinct value of this field
+	CollapseSize   int32    // Hits kept per collapse value (0 = 1)
+}
+
+type shardSearchOptionsKey struct{}
+
+// WithShardSearchOptions attaches shard search options to a search context
+func WithShardSearchOptions(ctx context.Context, opts ShardSearchOptions) context.Context {
+	return context.WithValue(ctx, shardSearchOptionsKey{}, opts)
+}
+
+// ShardSearchOptionsFromContext returns the shard search options attached to
+// ctx, or the zero options when there are none
+func ShardSearchOptionsFromContext(ctx context.Context) ShardSearchOptions {
+	opts, _ := ctx.Value(shardSearchOptionsKey{}).(ShardSearchOptions)
+	return opts
+}
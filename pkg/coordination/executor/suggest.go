@@ -0,0 +1,121 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/data/suggest"
+	"go.uber.org/zap"
+)
+
+// ExecuteSuggest runs the suggesters of a search request's suggest block on
+// every shard of an index and merges their suggestions, keyed by suggester
+// name. Shards that fail are left out unless all of them do.
+func (qe *QueryExecutor) ExecuteSuggest(ctx context.Context, indexName string, block map[string]interface{}) (map[string][]suggest.Entry, error) {
+	suggesters, err := suggest.Parse(block)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize suggest block: %w", err)
+	}
+
+	routing, err := qe.masterClient.GetShardRouting(ctx, indexName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shard routing: %w", err)
+	}
+
+	type shardResult struct {
+		shardID int32
+		resp    *pb.SuggestResponse
+		err     error
+	}
+
+	resultsChan := make(chan shardResult, len(routing))
+	var wg sync.WaitGroup
+
+	for shardID, shard := range routing {
+		// Only query started copies, primary first
+		nodeIDs := shardCopies(shard)
+		if len(nodeIDs) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(sid int32, nodeIDs []string) {
+			defer wg.Done()
+
+			var resp *pb.SuggestResponse
+			err := qe.tryShardCopies(ctx, indexName, sid, nodeIDs, func(client DataNodeClient) error {
+				var err error
+				resp, err = client.Suggest(ctx, indexName, sid, data)
+				return err
+			})
+			resultsChan <- shardResult{shardID: sid, resp: resp, err: err}
+		}(shardID, nodeIDs)
+	}
+
+	go func() {
+		wg.Wait()
+		close(resultsChan)
+	}()
+
+	shardEntries := make(map[string][][]suggest.Entry, len(suggesters))
+	successful := 0
+	var firstErr error
+	for shard := range resultsChan {
+		if shard.err != nil {
+			qe.logger.Error("Shard suggest failed",
+				zap.Int32("shard_id", shard.shardID),
+				zap.Error(shard.err))
+			if firstErr == nil {
+				firstErr = shard.err
+			}
+			continue
+		}
+		successful++
+		for name, entries := range shard.resp.GetSuggestions() {
+			shardEntries[name] = append(shardEntries[name], suggestEntriesFromProto(entries))
+		}
+	}
+
+	if successful == 0 && firstErr != nil {
+		return nil, fmt.Errorf("all shard suggests failed: %w", firstErr)
+	}
+
+	results := make(map[string][]suggest.Entry, len(suggesters))
+	for _, suggester := range suggesters {
+		results[suggester.Name] = suggest.Merge(suggester, shardEntries[suggester.Name])
+	}
+	return results, nil
+}
+
+// suggestEntriesFromProto converts the suggestions of one suggester
+func suggestEntriesFromProto(entries *pb.SuggestEntries) []suggest.Entry {
+	converted := make([]suggest.Entry, len(entries.GetEntries()))
+	for i, entry := range entries.GetEntries() {
+		options := make([]suggest.Option, len(entry.GetOptions()))
+		for j, option := range entry.GetOptions() {
+			options[j] = suggest.Option{
+				Text:  option.GetText(),
+				Score: option.GetScore(),
+				Freq:  option.GetFreq(),
+				ID:    option.GetDocId(),
+			}
+			if option.GetSource() != nil {
+				options[j].Source = option.GetSource().AsMap()
+			}
+		}
+		converted[i] = suggest.Entry{
+			Text:    entry.GetText(),
+			Offset:  int(entry.GetOffset()),
+			Length:  int(entry.GetLength()),
+			Options: options,
+		}
+	}
+	return converted
+}
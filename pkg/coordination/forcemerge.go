@@ -0,0 +1,183 @@
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"go.uber.org/zap"
+)
+
+// shardCopy is a started copy of a shard held by a data node
+type shardCopy struct {
+	ShardID   int32
+	IsPrimary bool
+	NodeID    string
+}
+
+// startedShardCopies returns the started primary and replica copies in a
+// shard routing table, ordered by shard with the primary first
+func startedShardCopies(routing map[int32]*pb.ShardRouting) []shardCopy {
+	copies := make([]shardCopy, 0, len(routing))
+	for shardID, shard := range routing {
+		if shardCopyActive(shard.GetAllocation()) {
+			copies = append(copies, shardCopy{ShardID: shardID, IsPrimary: true, NodeID: shard.GetAllocation().GetNodeId()})
+		}
+		for _, replica := range shard.GetReplicas() {
+			if shardCopyActive(replica) {
+				copies = append(copies, shardCopy{ShardID: shardID, NodeID: replica.GetNodeId()})
+			}
+		}
+	}
+	sort.Slice(copies, func(i, j int) bool {
+		if copies[i].ShardID != copies[j].ShardID {
+			return copies[i].ShardID < copies[j].ShardID
+		}
+		if copies[i].IsPrimary != copies[j].IsPrimary {
+			return copies[i].IsPrimary
+		}
+		return copies[i].NodeID < copies[j].NodeID
+	})
+	return copies
+}
+
+// shardCopyActive reports whether a shard copy is assigned and serving
+func shardCopyActive(allocation *pb.ShardAllocation) bool {
+	if allocation.GetNodeId() == "" {
+		return false
+	}
+	state := allocation.GetState()
+	return state == pb.ShardAllocation_SHARD_STATE_STARTED || state == pb.ShardAllocation_SHARD_STATE_RELOCATING
+}
+
+// handleForceMerge merges the segments of every started copy of an index's
+// shards, reclaiming the space held by deleted documents, and reports the
+// outcome for each copy. It runs as a task, in the background with
+// wait_for_completion=false.
+func (c *CoordinationNode) handleForceMerge(ctx *gin.Context) {
+	indexName := ctx.Param("index")
+
+	var maxNumSegments int32
+	if value := ctx.Query("max_num_segments"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || parsed < 0 {
+			respondError(ctx, http.StatusBadRequest, "illegal_argument_exception", fmt.Sprintf("max_num_segments must be a non-negative integer but was [%s]", value))
+			return
+		}
+		maxNumSegments = int32(parsed)
+	}
+
+	routing, err := c.masterClient.GetShardRouting(ctx.Request.Context(), indexName)
+	if err != nil {
+		respondAPIError(ctx, indexNotFoundError(indexName))
+		return
+	}
+
+	c.runTask(ctx, "indices:admin/forcemerge", fmt.Sprintf("Force-merge indices [%s], maxSegments[%d]", indexName, maxNumSegments), func(ctx context.Context, t *task) (int, gin.H) {
+		return c.forceMerge(ctx, t, indexName, startedShardCopies(routing), maxNumSegments)
+	})
+}
+
+// forceMerge force merges shard copies under the force merge's task
+func (c *CoordinationNode) forceMerge(ctx context.Context, t *task, indexName string, copies []shardCopy, maxNumSegments int32) (int, gin.H) {
+	results := make([]gin.H, len(copies))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+	t.setStatus(gin.H{"total": len(copies), "completed": done})
+	for i, shard := range copies {
+		wg.Add(1)
+		go func(i int, shard shardCopy) {
+			defer wg.Done()
+			results[i] = c.forceMergeShardCopy(ctx, indexName, shard, maxNumSegments)
+
+			mu.Lock()
+			done++
+			t.setStatus(gin.H{"total": len(copies), "completed": done})
+			mu.Unlock()
+		}(i, shard)
+	}
+	wg.Wait()
+
+	successful := 0
+	failures := make([]gin.H, 0)
+	for i, result := range results {
+		if result["status"] == "success" {
+			successful++
+			continue
+		}
+		failures = append(failures, gin.H{
+			"index":   indexName,
+			"shard":   copies[i].ShardID,
+			"node":    copies[i].NodeID,
+			"primary": copies[i].IsPrimary,
+			"reason": gin.H{
+				"type":   "force_merge_exception",
+				"reason": result["error"],
+			},
+		})
+	}
+
+	shards := gin.H{
+		"total":      len(copies),
+		"successful": successful,
+		"failed":     len(failures),
+	}
+	if len(failures) > 0 {
+		shards["failures"] = failures
+	}
+
+	c.logger.Info("Force merged index",
+		zap.String("index", indexName),
+		zap.Int32("max_num_segments", maxNumSegments),
+		zap.Int("shards", len(copies)),
+		zap.Int("failed", len(failures)))
+
+	return http.StatusOK, gin.H{
+		"_shards": shards,
+		"shards":  results,
+	}
+}
+
+// forceMergeShardCopy force merges one shard copy on its data node
+func (c *CoordinationNode) forceMergeShardCopy(ctx context.Context, indexName string, shard shardCopy, maxNumSegments int32) gin.H {
+	result := gin.H{
+		"index":   indexName,
+		"shard":   shard.ShardID,
+		"primary": shard.IsPrimary,
+		"node":    shard.NodeID,
+	}
+
+	c.dataClientsMu.RLock()
+	client, exists := c.dataClients[shard.NodeID]
+	c.dataClientsMu.RUnlock()
+	if !exists {
+		result["status"] = "failed"
+		result["error"] = fmt.Sprintf("data node %s not found", shard.NodeID)
+		return result
+	}
+
+	resp, err := client.ForceMerge(ctx, indexName, shard.ShardID, maxNumSegments)
+	if err != nil {
+		c.logger.Warn("Failed to force merge shard",
+			zap.String("index", indexName),
+			zap.Int32("shard_id", shard.ShardID),
+			zap.String("node_id", shard.NodeID),
+			zap.Error(err))
+		result["status"] = "failed"
+		result["error"] = err.Error()
+		return result
+	}
+
+	result["status"] = "success"
+	result["docs_deleted_before"] = resp.DocsDeletedBefore
+	result["docs_deleted_after"] = resp.DocsDeletedAfter
+	result["size_in_bytes_before"] = resp.SizeBytesBefore
+	result["size_in_bytes_after"] = resp.SizeBytesAfter
+	return result
+}
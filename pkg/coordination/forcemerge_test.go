@@ -0,0 +1,165 @@
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// segmentDataServer is a data node whose shards write a segment per batch
+// of indexed documents. Like Diagon it can't delete documents, so a force
+// merge only reclaims the overhead of the segments it merges away.
+type segmentDataServer struct {
+	pb.UnimplementedDataServiceServer
+
+	mu       sync.Mutex
+	segments map[int32][]*pb.SegmentStats
+}
+
+const (
+	segmentDocBytes      = 100
+	segmentOverheadBytes = 1000
+)
+
+func (s *segmentDataServer) index(shardID int32, docs int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.segments[shardID] = append(s.segments[shardID], &pb.SegmentStats{
+		Name:      fmt.Sprintf("_%d", len(s.segments[shardID])),
+		NumDocs:   docs,
+		SizeBytes: segmentOverheadBytes + docs*segmentDocBytes,
+	})
+}
+
+func (s *segmentDataServer) ForceMerge(ctx context.Context, req *pb.ForceMergeRequest) (*pb.ForceMergeResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if req.IndexName != "logs" {
+		return nil, status.Errorf(codes.NotFound, "shard not found")
+	}
+
+	resp := &pb.ForceMergeResponse{Acknowledged: true}
+	var docs int64
+	for _, segment := range s.segments[req.ShardId] {
+		docs += segment.NumDocs
+		resp.SizeBytesBefore += segment.SizeBytes
+	}
+	merged := &pb.SegmentStats{Name: "_merged", NumDocs: docs, SizeBytes: segmentOverheadBytes + docs*segmentDocBytes}
+	s.segments[req.ShardId] = []*pb.SegmentStats{merged}
+	resp.SizeBytesAfter = merged.SizeBytes
+	return resp, nil
+}
+
+func (s *segmentDataServer) GetSegmentStats(ctx context.Context, req *pb.GetSegmentStatsRequest) (*pb.GetSegmentStatsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if req.IndexName != "logs" {
+		return nil, status.Errorf(codes.NotFound, "shard not found")
+	}
+
+	resp := &pb.GetSegmentStatsResponse{IndexName: req.IndexName, ShardId: req.ShardId, IsPrimary: true}
+	for _, segment := range s.segments[req.ShardId] {
+		resp.Segments = append(resp.Segments, proto.Clone(segment).(*pb.SegmentStats))
+	}
+	return resp, nil
+}
+
+func (s *segmentDataServer) GetShardStats(ctx context.Context, req *pb.GetShardStatsRequest) (*pb.ShardStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if req.IndexName != "logs" {
+		return nil, status.Errorf(codes.NotFound, "shard not found")
+	}
+
+	stats := &pb.ShardStats{IndexName: req.IndexName, ShardId: req.ShardId, IsPrimary: true}
+	for _, segment := range s.segments[req.ShardId] {
+		stats.DocsCount += segment.NumDocs
+		stats.SizeBytes += segment.SizeBytes
+	}
+	return stats, nil
+}
+
+func TestForceMerge(t *testing.T) {
+	// A master holding a 2-shard index on node1
+	masterLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &resizeMasterServer{indices: map[string]*pb.IndexMetadata{
+		"logs": {IndexName: "logs", Settings: &pb.IndexSettings{NumberOfShards: 2}},
+	}}
+	masterServer := grpc.NewServer()
+	pb.RegisterMasterServiceServer(masterServer, master)
+	go masterServer.Serve(masterLis)
+	defer masterServer.Stop()
+
+	dataLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	data := &segmentDataServer{segments: map[int32][]*pb.SegmentStats{}}
+	dataServer := grpc.NewServer()
+	pb.RegisterDataServiceServer(dataServer, data)
+	go dataServer.Serve(dataLis)
+	defer dataServer.Stop()
+
+	node := newTestCoordinationNode(t)
+	node.masterClient = NewMasterClient(masterLis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	defer node.masterClient.Disconnect()
+
+	dataClient := NewDataNodeClient("node1", dataLis.Addr().String(), zap.NewNop())
+	require.NoError(t, dataClient.Connect(context.Background()))
+	defer dataClient.Disconnect()
+	node.dataClients["node1"] = dataClient
+
+	// Index documents in batches, a segment each
+	data.index(0, 10)
+	data.index(0, 6)
+	data.index(1, 10)
+	data.index(1, 8)
+	data.index(1, 2)
+
+	w, resp := postJSON(node.ginRouter, "/logs/_forcemerge?max_num_segments=1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	shards := resp["_shards"].(map[string]interface{})
+	assert.Equal(t, float64(2), shards["total"])
+	assert.Equal(t, float64(2), shards["successful"])
+	assert.Equal(t, float64(0), shards["failed"])
+
+	results := resp["shards"].([]interface{})
+	require.Len(t, results, 2)
+	for i, segments := range []int{2, 3} {
+		result := results[i].(map[string]interface{})
+		assert.Equal(t, float64(i), result["shard"])
+		assert.Equal(t, "success", result["status"])
+		assert.Equal(t, float64(0), result["docs_deleted_before"], "shard %d", i)
+		assert.Equal(t, float64(0), result["docs_deleted_after"], "shard %d", i)
+		assert.Equal(t, float64((segments-1)*segmentOverheadBytes),
+			result["size_in_bytes_before"].(float64)-result["size_in_bytes_after"].(float64),
+			"shard %d should shrink by the segments merged away", i)
+	}
+
+	// A shard whose node is gone is reported as failed
+	delete(node.dataClients, "node1")
+	w, resp = postJSON(node.ginRouter, "/logs/_forcemerge", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	shards = resp["_shards"].(map[string]interface{})
+	assert.Equal(t, float64(2), shards["failed"])
+	assert.Len(t, shards["failures"], 2)
+
+	w, _ = postJSON(node.ginRouter, "/logs/_forcemerge?max_num_segments=-1", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	w, _ = postJSON(node.ginRouter, "/missing/_forcemerge", "")
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
@@ -0,0 +1,344 @@
+package coordination
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// healthMasterServer answers cluster state queries with a fixed state
+type healthMasterServer struct {
+	pb.UnimplementedMasterServiceServer
+
+	state *pb.ClusterStateResponse
+}
+
+func (m *healthMasterServer) GetClusterState(ctx context.Context, req *pb.GetClusterStateRequest) (*pb.ClusterStateResponse, error) {
+	if m.state != nil {
+		return m.state, nil
+	}
+	return &pb.ClusterStateResponse{Version: 1}, nil
+}
+
+// shardWatchMasterServer answers cluster state queries with a state that
+// can change, reporting each change to watchers as a shard event
+type shardWatchMasterServer struct {
+	pb.UnimplementedMasterServiceServer
+
+	mu       sync.Mutex
+	state    *pb.ClusterStateResponse
+	reads    int
+	watchers []chan struct{}
+}
+
+func (m *shardWatchMasterServer) GetClusterState(ctx context.Context, req *pb.GetClusterStateRequest) (*pb.ClusterStateResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reads++
+	return m.state, nil
+}
+
+func (m *shardWatchMasterServer) WatchClusterState(req *pb.WatchClusterStateRequest, stream pb.MasterService_WatchClusterStateServer) error {
+	changed := make(chan struct{}, 1)
+	m.mu.Lock()
+	m.watchers = append(m.watchers, changed)
+	m.mu.Unlock()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-changed:
+			if err := stream.Send(&pb.ClusterStateEvent{Type: pb.ClusterStateEvent_EVENT_TYPE_SHARD_ALLOCATED}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (m *shardWatchMasterServer) setState(state *pb.ClusterStateResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = state
+	for _, watcher := range m.watchers {
+		select {
+		case watcher <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (m *shardWatchMasterServer) readCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.reads
+}
+
+// newHealthTestNode returns a node whose master serves state
+func newHealthTestNode(t *testing.T, state *pb.ClusterStateResponse) *CoordinationNode {
+	return newMasterTestNode(t, &healthMasterServer{state: state})
+}
+
+// newMasterTestNode returns a node whose master is master
+func newMasterTestNode(t *testing.T, master pb.MasterServiceServer) *CoordinationNode {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	pb.RegisterMasterServiceServer(server, master)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	node := newTestCoordinationNode(t)
+	node.masterClient = NewMasterClient(lis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	t.Cleanup(func() { node.masterClient.Disconnect() })
+
+	return node
+}
+
+func TestHealthCheck_DataNodeReachability(t *testing.T) {
+	node := newHealthTestNode(t, nil)
+
+	address := net.JoinHostPort("127.0.0.1", strconv.Itoa(freeTestPort(t)))
+	serveDataService(t, address)
+	reachable := NewDataNodeClient("data-up", address, zap.NewNop())
+	require.NoError(t, reachable.Connect(context.Background()))
+	defer reachable.Disconnect()
+
+	// Never connected, so every probe fails
+	unreachable := NewDataNodeClient("data-down", "127.0.0.1:1", zap.NewNop())
+
+	checkHealth := func() (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_health", nil))
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return w.Code, resp
+	}
+
+	node.dataClients["data-up"] = reachable
+	code, resp := checkHealth()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "green", resp["status"])
+
+	// One of two data nodes is down: degraded but still serving
+	node.dataClients["data-down"] = unreachable
+	code, resp = checkHealth()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "yellow", resp["status"])
+	checks := resp["checks"].(map[string]interface{})
+	assert.Equal(t, "degraded", checks["query_executor"])
+	dataNodes := checks["data_nodes"].(map[string]interface{})
+	assert.Equal(t, float64(2), dataNodes["total"])
+	assert.Equal(t, []interface{}{"data-down"}, dataNodes["unreachable"])
+
+	// No reachable data node: the node can't serve queries
+	delete(node.dataClients, "data-up")
+	code, resp = checkHealth()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "red", resp["status"])
+	assert.Equal(t, "failed", resp["checks"].(map[string]interface{})["query_executor"])
+}
+
+// mixedShardState is a yellow cluster whose products index has an
+// unassigned replica and an initializing primary, and whose orders index
+// is relocating its only shard
+func mixedShardState() *pb.ClusterStateResponse {
+	copyIn := func(state pb.ShardAllocation_ShardState) *pb.ShardAllocation {
+		return &pb.ShardAllocation{NodeId: "data-1", State: state}
+	}
+	return &pb.ClusterStateResponse{
+		Status: pb.ClusterStatus_CLUSTER_STATUS_YELLOW,
+		RoutingTable: &pb.RoutingTable{
+			Indices: map[string]*pb.IndexRoutingTable{
+				"products": {
+					IndexName: "products",
+					Shards: map[int32]*pb.ShardRouting{
+						0: {
+							ShardId:    0,
+							IsPrimary:  true,
+							Allocation: copyIn(pb.ShardAllocation_SHARD_STATE_STARTED),
+							Replicas:   []*pb.ShardAllocation{copyIn(pb.ShardAllocation_SHARD_STATE_UNASSIGNED)},
+						},
+						1: {
+							ShardId:    1,
+							IsPrimary:  true,
+							Allocation: copyIn(pb.ShardAllocation_SHARD_STATE_INITIALIZING),
+							Replicas:   []*pb.ShardAllocation{copyIn(pb.ShardAllocation_SHARD_STATE_STARTED)},
+						},
+					},
+				},
+				"orders": {
+					IndexName: "orders",
+					Shards: map[int32]*pb.ShardRouting{
+						0: {
+							ShardId:    0,
+							IsPrimary:  true,
+							Allocation: copyIn(pb.ShardAllocation_SHARD_STATE_RELOCATING),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestClusterHealth_ActiveShardsPercent(t *testing.T) {
+	node := newHealthTestNode(t, mixedShardState())
+
+	w := httptest.NewRecorder()
+	node.ginRouter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_cluster/health", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "yellow", resp["status"])
+	// The relocating copy is active as well as relocating
+	assert.Equal(t, float64(3), resp["active_shards"])
+	assert.Equal(t, float64(2), resp["active_primary_shards"])
+	assert.Equal(t, float64(1), resp["initializing_shards"])
+	assert.Equal(t, float64(1), resp["unassigned_shards"])
+	assert.Equal(t, float64(1), resp["relocating_shards"])
+	assert.InDelta(t, 60.0, resp["active_shards_percent_as_number"], 0.001)
+}
+
+func TestClusterHealth_PerIndex(t *testing.T) {
+	node := newHealthTestNode(t, mixedShardState())
+
+	clusterHealth := func(path string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return w.Code, resp
+	}
+
+	code, cluster := clusterHealth("/_cluster/health")
+	require.Equal(t, http.StatusOK, code)
+	code, orders := clusterHealth("/_cluster/health/orders")
+	require.Equal(t, http.StatusOK, code)
+
+	// The orders index only has its relocating shard
+	assert.Equal(t, float64(3), cluster["active_shards"])
+	assert.Equal(t, float64(1), orders["active_shards"])
+	assert.Equal(t, float64(1), orders["active_primary_shards"])
+	assert.Equal(t, float64(1), orders["relocating_shards"])
+	assert.Equal(t, float64(0), orders["unassigned_shards"])
+	assert.Equal(t, "green", orders["status"], "relocating copies keep serving")
+
+	// An initializing primary makes products red
+	code, products := clusterHealth("/_cluster/health/products")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "red", products["status"])
+	assert.Equal(t, float64(2), products["active_shards"])
+	assert.Equal(t, float64(1), products["unassigned_shards"])
+
+	// A list of indices takes the worst color
+	code, both := clusterHealth("/_cluster/health/orders,products")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "red", both["status"])
+	assert.Equal(t, float64(1), both["relocating_shards"])
+	assert.Equal(t, float64(1), both["initializing_shards"])
+
+	code, _ = clusterHealth("/_cluster/health/missing")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// Waiting for a status the index doesn't reach times out
+	start := time.Now()
+	code, waited := clusterHealth("/_cluster/health/products?wait_for_status=yellow&timeout=200ms")
+	assert.Equal(t, http.StatusRequestTimeout, code)
+	assert.Equal(t, true, waited["timed_out"])
+	assert.Equal(t, "red", waited["status"])
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+
+	// A status already reached returns at once
+	code, waited = clusterHealth("/_cluster/health/orders?wait_for_status=green&timeout=30s")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, false, waited["timed_out"])
+
+	code, _ = clusterHealth("/_cluster/health?wait_for_status=blue")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestClusterHealth_WaitForActiveShards(t *testing.T) {
+	products := func(state pb.ShardAllocation_ShardState) *pb.ClusterStateResponse {
+		return &pb.ClusterStateResponse{
+			RoutingTable: &pb.RoutingTable{
+				Indices: map[string]*pb.IndexRoutingTable{
+					"products": {
+						IndexName: "products",
+						Shards: map[int32]*pb.ShardRouting{
+							0: {
+								ShardId:    0,
+								IsPrimary:  true,
+								Allocation: &pb.ShardAllocation{NodeId: "data-1", State: state},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	master := &shardWatchMasterServer{state: products(pb.ShardAllocation_SHARD_STATE_INITIALIZING)}
+	node := newMasterTestNode(t, master)
+
+	clusterHealth := func(path string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return w.Code, resp
+	}
+
+	code, _ := clusterHealth("/_cluster/health/products?wait_for_active_shards=many")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, waited := clusterHealth("/_cluster/health/products?wait_for_active_shards=1&timeout=100ms")
+	assert.Equal(t, http.StatusRequestTimeout, code)
+	assert.Equal(t, true, waited["timed_out"])
+
+	// The request blocks until the primary starts
+	const startAfter = 500 * time.Millisecond
+	readsBefore := master.readCount()
+	go func() {
+		time.Sleep(startAfter)
+		master.setState(products(pb.ShardAllocation_SHARD_STATE_STARTED))
+	}()
+
+	start := time.Now()
+	code, waited = clusterHealth("/_cluster/health/products?wait_for_active_shards=all&wait_for_status=green&timeout=10s")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, false, waited["timed_out"])
+	assert.Equal(t, "green", waited["status"])
+	assert.Equal(t, float64(1), waited["active_shards"])
+	assert.GreaterOrEqual(t, time.Since(start), startAfter)
+
+	// The state is re-read on the shard change rather than polled
+	assert.LessOrEqual(t, master.readCount()-readsBefore, 2)
+
+	// A relocating copy is active, but not done relocating
+	master.setState(products(pb.ShardAllocation_SHARD_STATE_RELOCATING))
+	code, waited = clusterHealth("/_cluster/health/products?wait_for_active_shards=all&timeout=10s")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, false, waited["timed_out"])
+	code, waited = clusterHealth("/_cluster/health/products?wait_for_no_relocating_shards=true&timeout=100ms")
+	assert.Equal(t, http.StatusRequestTimeout, code)
+	assert.Equal(t, float64(1), waited["relocating_shards"])
+
+	code, _ = clusterHealth("/_cluster/health/products?wait_for_no_relocating_shards=maybe")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,54 @@
+package coordination
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/quidditch/quidditch/pkg/common/config"
+	"github.com/quidditch/quidditch/pkg/common/metrics"
+	"github.com/quidditch/quidditch/pkg/coordination/parser"
+	"github.com/quidditch/quidditch/pkg/coordination/pipeline"
+	"github.com/quidditch/quidditch/pkg/coordination/router"
+	"go.uber.org/zap"
+)
+
+// newTestCoordinationNode returns a coordination node with its routes set
+// up, serving searches from canned shard results instead of a live cluster.
+// Response compression is disabled. Each configure function adjusts the
+// node before its routes are registered.
+func newTestCoordinationNode(t *testing.T, configure ...func(*CoordinationNode)) *CoordinationNode {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+
+	masterClient := NewMasterClient("127.0.0.1:8000", logger)
+	node := &CoordinationNode{
+		cfg: &config.CoordinationConfig{
+			NodeID:             "coord-test",
+			MasterAddr:         "127.0.0.1:8000",
+			DisableCompression: true,
+		},
+		logger:           logger,
+		ginRouter:        gin.New(),
+		masterClient:     masterClient,
+		queryService:     NewQueryService(&mockPipelineQueryExecutor{}, &mockPipelineMasterClient{}, logger),
+		docRouter:        router.NewDocumentRouter(masterClient, map[string]router.DataNodeClient{}, logger),
+		queryParser:      parser.NewQueryParser(),
+		metrics:          metrics.NewMetricsCollectorWithRegistry("coordination", prometheus.NewRegistry()),
+		dataClients:      make(map[string]*DataNodeClient),
+		pipelineRegistry: pipeline.NewRegistry(logger),
+		tasks:            newTaskManager("coord-test"),
+	}
+	for _, fn := range configure {
+		fn(node)
+	}
+	node.setupRoutes()
+
+	return node
+}
+
+// withCompression enables response compression on a test node
+func withCompression(node *CoordinationNode) {
+	node.cfg.DisableCompression = false
+}
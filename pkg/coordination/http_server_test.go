@@ -0,0 +1,86 @@
+package coordination
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quidditch/quidditch/pkg/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewHTTPServer_Defaults(t *testing.T) {
+	node := &CoordinationNode{
+		cfg:       &config.CoordinationConfig{BindAddr: "127.0.0.1", RESTPort: 9200},
+		logger:    zap.NewNop(),
+		ginRouter: gin.New(),
+	}
+
+	server := node.newHTTPServer()
+	assert.Equal(t, "127.0.0.1:9200", server.Addr)
+	assert.Equal(t, defaultReadTimeout, server.ReadTimeout)
+	assert.Equal(t, defaultReadHeaderTimeout, server.ReadHeaderTimeout)
+	assert.Equal(t, defaultWriteTimeout, server.WriteTimeout)
+	assert.Equal(t, defaultIdleTimeout, server.IdleTimeout)
+	assert.Equal(t, defaultMaxHeaderBytes, server.MaxHeaderBytes)
+
+	node.cfg.ReadHeaderTimeout = 2 * time.Second
+	node.cfg.MaxHeaderBytes = 4096
+	server = node.newHTTPServer()
+	assert.Equal(t, 2*time.Second, server.ReadHeaderTimeout)
+	assert.Equal(t, 4096, server.MaxHeaderBytes)
+}
+
+func TestNewHTTPServer_ReadHeaderTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/_health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	node := &CoordinationNode{
+		cfg: &config.CoordinationConfig{
+			BindAddr:          "127.0.0.1",
+			ReadHeaderTimeout: 100 * time.Millisecond,
+		},
+		logger:    zap.NewNop(),
+		ginRouter: router,
+	}
+	server := node.newHTTPServer()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(listener)
+	defer server.Close()
+
+	t.Run("CompleteRequest", func(t *testing.T) {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/_health")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("SlowHeaders", func(t *testing.T) {
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		// Start a request but never finish the headers
+		_, err = conn.Write([]byte("GET /_health HTTP/1.1\r\nHost: localhost\r\n"))
+		require.NoError(t, err)
+
+		start := time.Now()
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		_, err = bufio.NewReader(conn).ReadByte()
+
+		// The server hangs up without answering once the header timeout expires
+		assert.Equal(t, io.EOF, err)
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+}
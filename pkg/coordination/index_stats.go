@@ -0,0 +1,161 @@
+package coordination
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+)
+
+// indexStatsTotals sums the stats of a set of shard copies
+type indexStatsTotals struct {
+	docs, deleted, storeSize int64
+	indexing, indexingMillis int64
+	queries, queryMillis     int64
+	refreshes, refreshMillis int64
+	merges, mergeMillis      int64
+}
+
+func (t *indexStatsTotals) add(stats *pb.ShardStats) {
+	t.docs += stats.DocsCount
+	t.deleted += stats.DocsDeleted
+	t.storeSize += stats.SizeBytes
+	t.indexing += stats.IndexingTotal
+	t.indexingMillis += stats.IndexingTimeMillis
+	t.queries += stats.SearchQueriesTotal
+	t.queryMillis += stats.SearchQueriesTimeMillis
+	t.refreshes += stats.RefreshTotal
+	t.refreshMillis += stats.RefreshTimeMillis
+	t.merges += stats.MergeTotal
+	t.mergeMillis += stats.MergeTimeMillis
+}
+
+func (t *indexStatsTotals) render() gin.H {
+	return gin.H{
+		"docs": gin.H{
+			"count":   t.docs,
+			"deleted": t.deleted,
+		},
+		"store": gin.H{
+			"size_in_bytes": t.storeSize,
+		},
+		"indexing": gin.H{
+			"index_total":          t.indexing,
+			"index_time_in_millis": t.indexingMillis,
+		},
+		"search": gin.H{
+			"query_total":          t.queries,
+			"query_time_in_millis": t.queryMillis,
+		},
+		"refresh": gin.H{
+			"total":                t.refreshes,
+			"total_time_in_millis": t.refreshMillis,
+		},
+		"merges": gin.H{
+			"total":                t.merges,
+			"total_time_in_millis": t.mergeMillis,
+		},
+	}
+}
+
+// handleIndexStats reports document counts, store size and indexing,
+// search, refresh and merge counters for one index or, without an index,
+// for all of them. Each is summed over the primaries and over every
+// started copy, as the owning data nodes report them. level=cluster
+// reports only the totals across indices and level=shards adds each
+// copy's own stats under its index.
+func (c *CoordinationNode) handleIndexStats(ctx *gin.Context) {
+	level := ctx.DefaultQuery("level", "indices")
+	if level != "cluster" && level != "indices" && level != "shards" {
+		respondError(ctx, http.StatusBadRequest, "illegal_argument_exception",
+			"level parameter must be one of [cluster] or [indices] or [shards] but was ["+level+"]")
+		return
+	}
+
+	state, err := c.masterClient.GetClusterState(ctx.Request.Context(), true, false, true)
+	if err != nil {
+		respondErrorFrom(ctx, err, "stats_exception", "Failed to get cluster state")
+		return
+	}
+
+	var indices []*pb.IndexMetadata
+	if indexName := ctx.Param("index"); indexName != "" {
+		for _, index := range state.Indices {
+			if index.IndexName == indexName {
+				indices = append(indices, index)
+			}
+		}
+		if len(indices) == 0 {
+			respondAPIError(ctx, indexNotFoundError(indexName))
+			return
+		}
+	} else {
+		indices = state.Indices
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i].IndexName < indices[j].IndexName })
+
+	var allPrimaries, allTotal indexStatsTotals
+	var total, failed int
+	indicesStats := gin.H{}
+	for _, index := range indices {
+		var primaries, indexTotal indexStatsTotals
+		shards := gin.H{}
+
+		copies := startedShardCopies(state.GetRoutingTable().GetIndices()[index.IndexName].GetShards())
+		total += len(copies)
+		for i, stats := range c.shardCopyStats(ctx.Request.Context(), index.IndexName, copies) {
+			if stats == nil {
+				failed++
+				continue
+			}
+			indexTotal.add(stats)
+			allTotal.add(stats)
+			if copies[i].IsPrimary {
+				primaries.add(stats)
+				allPrimaries.add(stats)
+			}
+
+			if level == "shards" {
+				var copyStats indexStatsTotals
+				copyStats.add(stats)
+				shardStats := copyStats.render()
+				shardStats["routing"] = gin.H{
+					"state":   "STARTED",
+					"primary": copies[i].IsPrimary,
+					"node":    copies[i].NodeID,
+				}
+				key := strconv.Itoa(int(copies[i].ShardID))
+				entries, _ := shards[key].([]gin.H)
+				shards[key] = append(entries, shardStats)
+			}
+		}
+
+		entry := gin.H{
+			"uuid":      index.IndexUuid,
+			"primaries": primaries.render(),
+			"total":     indexTotal.render(),
+		}
+		if level == "shards" {
+			entry["shards"] = shards
+		}
+		indicesStats[index.IndexName] = entry
+	}
+
+	resp := gin.H{
+		"_shards": gin.H{
+			"total":      total,
+			"successful": total - failed,
+			"failed":     failed,
+		},
+		"_all": gin.H{
+			"primaries": allPrimaries.render(),
+			"total":     allTotal.render(),
+		},
+	}
+	if level != "cluster" {
+		resp["indices"] = indicesStats
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,151 @@
+package coordination
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// statsDataServer is a data node reporting fixed stats for each shard
+type statsDataServer struct {
+	pb.UnimplementedDataServiceServer
+	stats map[int32]*pb.ShardStats
+}
+
+func (s *statsDataServer) GetShardStats(ctx context.Context, req *pb.GetShardStatsRequest) (*pb.ShardStats, error) {
+	stats, ok := s.stats[req.ShardId]
+	if req.IndexName != "logs" || !ok {
+		return nil, status.Errorf(codes.NotFound, "shard not found")
+	}
+	return proto.Clone(stats).(*pb.ShardStats), nil
+}
+
+func TestIndexStats(t *testing.T) {
+	// A master holding a 2-shard index on node1
+	masterLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &resizeMasterServer{indices: map[string]*pb.IndexMetadata{
+		"logs": {IndexName: "logs", IndexUuid: "logs-uuid", Settings: &pb.IndexSettings{NumberOfShards: 2}},
+	}}
+	masterServer := grpc.NewServer()
+	pb.RegisterMasterServiceServer(masterServer, master)
+	go masterServer.Serve(masterLis)
+	defer masterServer.Stop()
+
+	dataLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	data := &statsDataServer{stats: map[int32]*pb.ShardStats{
+		0: {
+			IndexName: "logs", ShardId: 0, IsPrimary: true,
+			DocsCount: 10, DocsDeleted: 2, SizeBytes: 1000,
+			IndexingTotal: 12, IndexingTimeMillis: 30,
+			SearchQueriesTotal: 5, SearchQueriesTimeMillis: 7,
+			RefreshTotal: 3, RefreshTimeMillis: 4,
+			MergeTotal: 1, MergeTimeMillis: 9,
+		},
+		1: {
+			IndexName: "logs", ShardId: 1, IsPrimary: true,
+			DocsCount: 20, DocsDeleted: 0, SizeBytes: 2500,
+			IndexingTotal: 20, IndexingTimeMillis: 50,
+			SearchQueriesTotal: 5, SearchQueriesTimeMillis: 3,
+			RefreshTotal: 2, RefreshTimeMillis: 6,
+		},
+	}}
+	dataServer := grpc.NewServer()
+	pb.RegisterDataServiceServer(dataServer, data)
+	go dataServer.Serve(dataLis)
+	defer dataServer.Stop()
+
+	node := newTestCoordinationNode(t)
+	node.masterClient = NewMasterClient(masterLis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	defer node.masterClient.Disconnect()
+
+	dataClient := NewDataNodeClient("node1", dataLis.Addr().String(), zap.NewNop())
+	require.NoError(t, dataClient.Connect(context.Background()))
+	defer dataClient.Disconnect()
+	node.dataClients["node1"] = dataClient
+
+	getStats := func(path string) map[string]interface{} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+	section := func(stats interface{}, name, field string) float64 {
+		return stats.(map[string]interface{})[name].(map[string]interface{})[field].(float64)
+	}
+
+	// Both shards are summed into the index's totals
+	resp := getStats("/logs/_stats")
+	assert.Equal(t, map[string]interface{}{"total": 2.0, "successful": 2.0, "failed": 0.0}, resp["_shards"])
+	index := resp["indices"].(map[string]interface{})["logs"].(map[string]interface{})
+	assert.Equal(t, "logs-uuid", index["uuid"])
+	assert.NotContains(t, index, "shards")
+	for _, total := range []interface{}{index["primaries"], index["total"]} {
+		assert.Equal(t, 30.0, section(total, "docs", "count"))
+		assert.Equal(t, 2.0, section(total, "docs", "deleted"))
+		assert.Equal(t, 3500.0, section(total, "store", "size_in_bytes"))
+		assert.Equal(t, 32.0, section(total, "indexing", "index_total"))
+		assert.Equal(t, 80.0, section(total, "indexing", "index_time_in_millis"))
+		assert.Equal(t, 10.0, section(total, "search", "query_total"))
+		assert.Equal(t, 10.0, section(total, "search", "query_time_in_millis"))
+		assert.Equal(t, 5.0, section(total, "refresh", "total"))
+		assert.Equal(t, 10.0, section(total, "refresh", "total_time_in_millis"))
+		assert.Equal(t, 1.0, section(total, "merges", "total"))
+		assert.Equal(t, 9.0, section(total, "merges", "total_time_in_millis"))
+	}
+	all := resp["_all"].(map[string]interface{})
+	assert.Equal(t, 30.0, section(all["total"], "docs", "count"))
+
+	// level=shards breaks the totals down by shard copy
+	resp = getStats("/logs/_stats?level=shards")
+	shards := resp["indices"].(map[string]interface{})["logs"].(map[string]interface{})["shards"].(map[string]interface{})
+	require.Len(t, shards, 2)
+	shard0 := shards["0"].([]interface{})
+	require.Len(t, shard0, 1)
+	assert.Equal(t, 10.0, section(shard0[0], "docs", "count"))
+	assert.Equal(t, 1000.0, section(shard0[0], "store", "size_in_bytes"))
+	assert.Equal(t, true, shard0[0].(map[string]interface{})["routing"].(map[string]interface{})["primary"])
+	shard1 := shards["1"].([]interface{})
+	require.Len(t, shard1, 1)
+	assert.Equal(t, 20.0, section(shard1[0], "docs", "count"))
+	assert.Equal(t, 0.0, section(shard1[0], "merges", "total"))
+
+	// /_stats covers every index; level=cluster only reports the totals
+	resp = getStats("/_stats")
+	assert.Contains(t, resp["indices"], "logs")
+	resp = getStats("/_stats?level=cluster")
+	assert.NotContains(t, resp, "indices")
+	assert.Equal(t, 3500.0, section(resp["_all"].(map[string]interface{})["primaries"], "store", "size_in_bytes"))
+
+	// A shard its node can't report on counts as failed
+	delete(data.stats, 1)
+	resp = getStats("/logs/_stats")
+	assert.Equal(t, map[string]interface{}{"total": 2.0, "successful": 1.0, "failed": 1.0}, resp["_shards"])
+
+	req := httptest.NewRequest(http.MethodGet, "/logs/_stats?level=bogus", nil)
+	w := httptest.NewRecorder()
+	node.ginRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/missing/_stats", nil)
+	w = httptest.NewRecorder()
+	node.ginRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
@@ -0,0 +1,219 @@
+package coordination
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// indexTemplateRequest is the body of a PUT /_index_template/:name request
+type indexTemplateRequest struct {
+	IndexPatterns []string               `json:"index_patterns"`
+	Priority      int64                  `json:"priority"`
+	Template      map[string]interface{} `json:"template"`
+}
+
+// resolveIndexTemplate returns the highest priority template with a pattern
+// matching indexName, or nil if none matches. Templates of equal priority
+// are ordered by name so the choice is stable.
+func resolveIndexTemplate(templates []*pb.IndexTemplate, indexName string) *pb.IndexTemplate {
+	var best *pb.IndexTemplate
+	for _, template := range templates {
+		matched := false
+		for _, pattern := range template.IndexPatterns {
+			if ok, _ := path.Match(pattern, indexName); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		if best == nil || template.Priority > best.Priority ||
+			(template.Priority == best.Priority && template.Name < best.Name) {
+			best = template
+		}
+	}
+	return best
+}
+
+// mergeIndexBody overlays an index creation body on a template body. Objects
+// are merged key by key and any other value in the body replaces the
+// template's.
+func mergeIndexBody(template, body map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(template)+len(body))
+	for k, v := range template {
+		merged[k] = v
+	}
+	for k, v := range body {
+		bodyObject, bodyIsObject := v.(map[string]interface{})
+		templateObject, templateIsObject := merged[k].(map[string]interface{})
+		if bodyIsObject && templateIsObject {
+			merged[k] = mergeIndexBody(templateObject, bodyObject)
+			continue
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+// applyIndexTemplate merges the settings and mappings of the index template
+// matching indexName into an index creation body. The body is returned
+// unchanged if no template matches.
+func (c *CoordinationNode) applyIndexTemplate(ctx context.Context, indexName string, body map[string]interface{}) (map[string]interface{}, error) {
+	templates, err := c.masterClient.GetIndexTemplates(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index templates: %w", err)
+	}
+
+	template := resolveIndexTemplate(templates, indexName)
+	if template == nil {
+		return body, nil
+	}
+
+	var templateBody map[string]interface{}
+	if template.Template != "" {
+		if err := json.Unmarshal([]byte(template.Template), &templateBody); err != nil {
+			return nil, fmt.Errorf("index template [%s] is invalid: %w", template.Name, err)
+		}
+	}
+
+	c.logger.Info("Applying index template",
+		zap.String("index", indexName),
+		zap.String("template", template.Name),
+		zap.Int64("priority", template.Priority))
+
+	return mergeIndexBody(templateBody, body), nil
+}
+
+// handlePutIndexTemplate stores an index template in the master metadata
+func (c *CoordinationNode) handlePutIndexTemplate(ctx *gin.Context) {
+	name := ctx.Param("name")
+
+	badRequest := func(reason string) {
+		respondError(ctx, http.StatusBadRequest, "illegal_argument_exception", reason)
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		badRequest(fmt.Sprintf("Failed to read request body: %v", err))
+		return
+	}
+
+	var req indexTemplateRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		badRequest(fmt.Sprintf("Failed to parse index template: %v", err))
+		return
+	}
+	if len(req.IndexPatterns) == 0 {
+		badRequest(fmt.Sprintf("index template [%s] must have at least one index pattern", name))
+		return
+	}
+	for _, pattern := range req.IndexPatterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			badRequest(fmt.Sprintf("index template [%s] has invalid index pattern [%s]", name, pattern))
+			return
+		}
+	}
+	if req.Priority < 0 {
+		badRequest(fmt.Sprintf("index template priority must be >= 0 but was [%d]", req.Priority))
+		return
+	}
+	for key := range req.Template {
+		if key != "settings" && key != "mappings" {
+			badRequest(fmt.Sprintf("unknown key [%s] in index template", key))
+			return
+		}
+	}
+
+	var template string
+	if req.Template != nil {
+		encoded, err := json.Marshal(req.Template)
+		if err != nil {
+			badRequest(fmt.Sprintf("Failed to encode index template: %v", err))
+			return
+		}
+		template = string(encoded)
+	}
+
+	if _, err := c.masterClient.PutIndexTemplate(ctx.Request.Context(), &pb.IndexTemplate{
+		Name:          name,
+		IndexPatterns: req.IndexPatterns,
+		Priority:      req.Priority,
+		Template:      template,
+	}); err != nil {
+		c.logger.Error("Failed to store index template", zap.String("name", name), zap.Error(err))
+		respondError(ctx, http.StatusInternalServerError, "index_template_exception", fmt.Sprintf("Failed to store index template: %v", err))
+		return
+	}
+
+	c.logger.Info("Stored index template",
+		zap.String("name", name),
+		zap.Strings("index_patterns", req.IndexPatterns),
+		zap.Int64("priority", req.Priority))
+
+	ctx.JSON(http.StatusOK, gin.H{"acknowledged": true})
+}
+
+// handleGetIndexTemplate returns one index template, or every template when
+// no name is given
+func (c *CoordinationNode) handleGetIndexTemplate(ctx *gin.Context) {
+	name := ctx.Param("name")
+
+	templates, err := c.masterClient.GetIndexTemplates(ctx.Request.Context(), name)
+	if status.Code(err) == codes.NotFound {
+		respondError(ctx, http.StatusNotFound, "resource_not_found_exception", fmt.Sprintf("index template matching [%s] not found", name))
+		return
+	}
+	if err != nil {
+		c.logger.Error("Failed to get index templates", zap.String("name", name), zap.Error(err))
+		respondError(ctx, http.StatusInternalServerError, "index_template_exception", fmt.Sprintf("Failed to get index templates: %v", err))
+		return
+	}
+
+	results := make([]gin.H, 0, len(templates))
+	for _, template := range templates {
+		indexTemplate := gin.H{
+			"index_patterns": template.IndexPatterns,
+			"priority":       template.Priority,
+		}
+		if template.Template != "" {
+			var body map[string]interface{}
+			if err := json.Unmarshal([]byte(template.Template), &body); err == nil {
+				indexTemplate["template"] = body
+			}
+		}
+		results = append(results, gin.H{
+			"name":           template.Name,
+			"index_template": indexTemplate,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"index_templates": results})
+}
+
+// handleDeleteIndexTemplate deletes an index template
+func (c *CoordinationNode) handleDeleteIndexTemplate(ctx *gin.Context) {
+	name := ctx.Param("name")
+
+	if _, err := c.masterClient.DeleteIndexTemplate(ctx.Request.Context(), name); err != nil {
+		if status.Code(err) == codes.NotFound {
+			respondError(ctx, http.StatusNotFound, "resource_not_found_exception", fmt.Sprintf("index template matching [%s] not found", name))
+			return
+		}
+		c.logger.Error("Failed to delete index template", zap.String("name", name), zap.Error(err))
+		respondError(ctx, http.StatusInternalServerError, "index_template_exception", fmt.Sprintf("Failed to delete index template: %v", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"acknowledged": true})
+}
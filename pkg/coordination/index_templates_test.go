@@ -0,0 +1,217 @@
+package coordination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/coordination/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// templateMasterServer is a master that stores index templates alongside
+// the indices it creates
+type templateMasterServer struct {
+	resizeMasterServer
+
+	templatesMu sync.Mutex
+	templates   map[string]*pb.IndexTemplate
+}
+
+func (m *templateMasterServer) PutIndexTemplate(ctx context.Context, req *pb.PutIndexTemplateRequest) (*pb.PutIndexTemplateResponse, error) {
+	m.templatesMu.Lock()
+	defer m.templatesMu.Unlock()
+	m.templates[req.Template.Name] = req.Template
+	return &pb.PutIndexTemplateResponse{Acknowledged: true}, nil
+}
+
+func (m *templateMasterServer) GetIndexTemplates(ctx context.Context, req *pb.GetIndexTemplatesRequest) (*pb.GetIndexTemplatesResponse, error) {
+	m.templatesMu.Lock()
+	defer m.templatesMu.Unlock()
+	resp := &pb.GetIndexTemplatesResponse{}
+	for name, template := range m.templates {
+		if req.Name == "" || req.Name == name {
+			resp.Templates = append(resp.Templates, template)
+		}
+	}
+	if req.Name != "" && len(resp.Templates) == 0 {
+		return nil, status.Errorf(codes.NotFound, "index template not found: %s", req.Name)
+	}
+	sort.Slice(resp.Templates, func(i, j int) bool { return resp.Templates[i].Name < resp.Templates[j].Name })
+	return resp, nil
+}
+
+func (m *templateMasterServer) DeleteIndexTemplate(ctx context.Context, req *pb.DeleteIndexTemplateRequest) (*pb.DeleteIndexTemplateResponse, error) {
+	m.templatesMu.Lock()
+	defer m.templatesMu.Unlock()
+	if _, exists := m.templates[req.Name]; !exists {
+		return nil, status.Errorf(codes.NotFound, "index template not found: %s", req.Name)
+	}
+	delete(m.templates, req.Name)
+	return &pb.DeleteIndexTemplateResponse{Acknowledged: true}, nil
+}
+
+func TestResolveIndexTemplate(t *testing.T) {
+	templates := []*pb.IndexTemplate{
+		{Name: "logs", IndexPatterns: []string{"logs-*"}, Priority: 1},
+		{Name: "logs-2024", IndexPatterns: []string{"logs-2024*"}, Priority: 10},
+		{Name: "b-metrics", IndexPatterns: []string{"metrics-*"}, Priority: 5},
+		{Name: "a-metrics", IndexPatterns: []string{"other-*", "metrics-*"}, Priority: 5},
+	}
+
+	assert.Equal(t, "logs", resolveIndexTemplate(templates, "logs-2023.01").GetName())
+	assert.Equal(t, "logs-2024", resolveIndexTemplate(templates, "logs-2024.01").GetName())
+	// Equal priorities resolve by name
+	assert.Equal(t, "a-metrics", resolveIndexTemplate(templates, "metrics-cpu").GetName())
+	assert.Nil(t, resolveIndexTemplate(templates, "products"))
+}
+
+func TestMergeIndexBody(t *testing.T) {
+	template := map[string]interface{}{
+		"settings": map[string]interface{}{
+			"index": map[string]interface{}{"number_of_shards": float64(3), "number_of_replicas": float64(1)},
+		},
+		"mappings": map[string]interface{}{"properties": map[string]interface{}{"message": "text"}},
+	}
+	body := map[string]interface{}{
+		"settings": map[string]interface{}{
+			"index": map[string]interface{}{"number_of_replicas": float64(0)},
+		},
+	}
+
+	merged := mergeIndexBody(template, body)
+	index := merged["settings"].(map[string]interface{})["index"].(map[string]interface{})
+	assert.Equal(t, float64(3), index["number_of_shards"])
+	assert.Equal(t, float64(0), index["number_of_replicas"])
+	assert.Equal(t, template["mappings"], merged["mappings"])
+
+	// The template itself is left untouched
+	templateIndex := template["settings"].(map[string]interface{})["index"].(map[string]interface{})
+	assert.Equal(t, float64(1), templateIndex["number_of_replicas"])
+}
+
+func TestIndexTemplateAppliedOnCreate(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &templateMasterServer{
+		resizeMasterServer: resizeMasterServer{indices: map[string]*pb.IndexMetadata{}},
+		templates:          map[string]*pb.IndexTemplate{},
+	}
+	server := grpc.NewServer()
+	pb.RegisterMasterServiceServer(server, master)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	node := newTestCoordinationNode(t)
+	node.masterClient = NewMasterClient(lis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	defer node.masterClient.Disconnect()
+	node.pipelineRegistry = pipeline.NewRegistry(zap.NewNop())
+	require.NoError(t, node.pipelineRegistry.Register(&pipeline.PipelineDefinition{
+		Name:    "enrich-logs",
+		Version: "1.0.0",
+		Type:    pipeline.PipelineTypeDocument,
+		Stages: []pipeline.StageDefinition{
+			{Name: "noop", Type: pipeline.StageTypeNative, Enabled: true, Config: map[string]interface{}{"function": "noop"}},
+		},
+		Enabled: true,
+	}))
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodPut, "/_index_template/logs", `{
+		"index_patterns": ["logs-*"],
+		"priority": 1,
+		"template": {
+			"settings": {"index": {"number_of_shards": 3, "number_of_replicas": 1, "document": {"default_pipeline": "enrich-logs"}}},
+			"mappings": {"properties": {"message": {"type": "text"}}}
+		}
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = serve(http.MethodPut, "/_index_template/logs-archive", `{
+		"index_patterns": ["logs-archive-*"],
+		"priority": 10,
+		"template": {"settings": {"index": {"number_of_shards": 1}}}
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// A new matching index inherits the template's settings and pipelines
+	w = serve(http.MethodPut, "/logs-2024", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	settings := master.indices["logs-2024"].Settings
+	assert.Equal(t, int32(3), settings.NumberOfShards)
+	assert.Equal(t, int32(1), settings.NumberOfReplicas)
+	pipe, err := node.pipelineRegistry.GetPipelineForIndex("logs-2024", pipeline.PipelineTypeDocument)
+	require.NoError(t, err)
+	assert.Equal(t, "enrich-logs", pipe.Name())
+
+	// Settings in the request override the template's
+	w = serve(http.MethodPut, "/logs-2025", `{"settings": {"index": {"number_of_replicas": 0}}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, int32(3), master.indices["logs-2025"].Settings.NumberOfShards)
+	assert.Equal(t, int32(0), master.indices["logs-2025"].Settings.NumberOfReplicas)
+
+	// The highest priority matching template wins
+	w = serve(http.MethodPut, "/logs-archive-2020", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, int32(1), master.indices["logs-archive-2020"].Settings.NumberOfShards)
+	assert.Equal(t, int32(0), master.indices["logs-archive-2020"].Settings.NumberOfReplicas)
+
+	// Indices matching no template get the defaults
+	w = serve(http.MethodPut, "/products", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, int32(1), master.indices["products"].Settings.NumberOfShards)
+
+	w = serve(http.MethodGet, "/_index_template/logs", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		IndexTemplates []struct {
+			Name          string `json:"name"`
+			IndexTemplate struct {
+				IndexPatterns []string               `json:"index_patterns"`
+				Priority      int64                  `json:"priority"`
+				Template      map[string]interface{} `json:"template"`
+			} `json:"index_template"`
+		} `json:"index_templates"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.IndexTemplates, 1)
+	assert.Equal(t, []string{"logs-*"}, resp.IndexTemplates[0].IndexTemplate.IndexPatterns)
+	assert.Contains(t, resp.IndexTemplates[0].IndexTemplate.Template, "mappings")
+
+	w = serve(http.MethodGet, "/_index_template", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.IndexTemplates, 2)
+
+	w = serve(http.MethodDelete, "/_index_template/logs", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = serve(http.MethodGet, "/_index_template/logs", "")
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+	w = serve(http.MethodDelete, "/_index_template/logs", "")
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+
+	w = serve(http.MethodPut, "/_index_template/bad", `{"index_patterns": []}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	w = serve(http.MethodPut, "/_index_template/bad", `{"index_patterns": ["a-*"], "template": {"aliases": {}}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
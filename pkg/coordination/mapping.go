@@ -0,0 +1,212 @@
+package coordination
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// handleGetMapping returns an index's field mappings
+func (c *CoordinationNode) handleGetMapping(ctx *gin.Context) {
+	indexName := ctx.Param("index")
+
+	metadata, err := c.masterClient.GetIndexMetadata(ctx.Request.Context(), indexName)
+	if status.Code(err) == codes.NotFound {
+		respondAPIError(ctx, indexNotFoundError(indexName))
+		return
+	}
+	if err != nil {
+		c.logger.Error("Failed to get index metadata", zap.String("index", indexName), zap.Error(err))
+		respondErrorFrom(ctx, err, "mapping_exception", "Failed to get index metadata")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		indexName: gin.H{"mappings": renderMappings(metadata.GetMetadata().GetMappings())},
+	})
+}
+
+// handlePutMapping merges the fields of a mappings object into an index's
+// mapping. New fields are added; a field already mapped keeps its type and
+// analyzer, and a mapping changing either is rejected as a whole. The
+// master has the index's open shards apply the analyzers and numeric types
+// of the merged mapping before the request is acknowledged.
+func (c *CoordinationNode) handlePutMapping(ctx *gin.Context) {
+	indexName := ctx.Param("index")
+
+	badRequest := func(reason string) {
+		respondError(ctx, http.StatusBadRequest, "illegal_argument_exception", reason)
+	}
+
+	var body map[string]interface{}
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		respondAPIError(ctx, parsingError(fmt.Errorf("failed to parse mapping: %w", err)))
+		return
+	}
+	update, err := parseMappings(body)
+	if err != nil {
+		respondAPIError(ctx, parsingError(err))
+		return
+	}
+
+	metadata, err := c.masterClient.GetIndexMetadata(ctx.Request.Context(), indexName)
+	if status.Code(err) == codes.NotFound {
+		respondAPIError(ctx, indexNotFoundError(indexName))
+		return
+	}
+	if err != nil {
+		c.logger.Error("Failed to get index metadata", zap.String("index", indexName), zap.Error(err))
+		respondErrorFrom(ctx, err, "mapping_exception", "Failed to get index metadata")
+		return
+	}
+
+	merged, err := mergeMappings(metadata.GetMetadata().GetMappings(), update, "")
+	if err != nil {
+		badRequest(err.Error())
+		return
+	}
+
+	// The analysis and typed field settings are derived from the mapping,
+	// so they are rebuilt from the merged one. Custom analyzers and filters
+	// are fixed at index creation.
+	var existing indexAnalysis
+	if value := metadata.GetMetadata().GetSettings().GetAnalysis(); value != "" {
+		if err := json.Unmarshal([]byte(value), &existing); err != nil {
+			respondErrorFrom(ctx, err, "mapping_exception", "Failed to read index analysis settings")
+			return
+		}
+	}
+	analysisSetting, err := indexAnalysisSetting(existing.CustomAnalyzers, existing.CustomFilters, merged)
+	if err != nil {
+		badRequest(err.Error())
+		return
+	}
+	numericFields, err := indexNumericFieldsSetting(merged)
+	if err != nil {
+		badRequest(err.Error())
+		return
+	}
+	dateFields, err := indexDateFieldsSetting(merged)
+	if err != nil {
+		badRequest(err.Error())
+		return
+	}
+	geoPointFields, err := indexGeoPointFieldsSetting(merged)
+	if err != nil {
+		badRequest(err.Error())
+		return
+	}
+
+	_, err = c.masterClient.PutMapping(ctx.Request.Context(), indexName, merged, &pb.IndexSettings{
+		Analysis:       analysisSetting,
+		NumericFields:  numericFields,
+		DateFields:     dateFields,
+		GeoPointFields: geoPointFields,
+	}, metadata.GetMetadata().GetVersion())
+	if err != nil {
+		c.logger.Error("Failed to put mapping", zap.String("index", indexName), zap.Error(err))
+		respondErrorFrom(ctx, err, "mapping_exception", "Failed to put mapping")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"acknowledged": true})
+}
+
+// mergeMappings returns existing with the fields of update merged in,
+// recursing into object fields. A field already mapped can't change its
+// type or analyzer, as its indexed values would no longer match its
+// mapping. existing itself is not modified.
+func mergeMappings(existing, update map[string]*pb.FieldMapping, prefix string) (map[string]*pb.FieldMapping, error) {
+	merged := make(map[string]*pb.FieldMapping, len(existing)+len(update))
+	for name, field := range existing {
+		merged[name] = field
+	}
+
+	names := make([]string, 0, len(update))
+	for name := range update {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := prefix + name
+		field := update[name]
+		current, ok := existing[name]
+		if !ok {
+			merged[name] = field
+			continue
+		}
+
+		if fieldType(current) != fieldType(field) {
+			return nil, fmt.Errorf("mapper [%s] cannot be changed from type [%s] to [%s]", path, fieldType(current), fieldType(field))
+		}
+		if current.Analyzer != field.Analyzer && field.Analyzer != "" {
+			return nil, fmt.Errorf("mapper [%s] cannot update parameter [analyzer] from [%s] to [%s]", path, current.Analyzer, field.Analyzer)
+		}
+		if current.Format != field.Format && field.Format != "" {
+			return nil, fmt.Errorf("mapper [%s] cannot update parameter [format] from [%s] to [%s]", path, current.Format, field.Format)
+		}
+
+		properties, err := mergeMappings(current.Properties, field.Properties, path+".")
+		if err != nil {
+			return nil, err
+		}
+		updated := proto.Clone(current).(*pb.FieldMapping)
+		updated.IgnoreMalformed = field.IgnoreMalformed
+		updated.Properties = properties
+		merged[name] = updated
+	}
+	return merged, nil
+}
+
+// fieldType returns a field's type. A field mapped with properties but no
+// type is an object.
+func fieldType(field *pb.FieldMapping) string {
+	if field.Type == "" && len(field.Properties) > 0 {
+		return "object"
+	}
+	return field.Type
+}
+
+// renderMappings renders field mappings as the properties of a mappings
+// object, the inverse of parseMappings
+func renderMappings(fields map[string]*pb.FieldMapping) gin.H {
+	if len(fields) == 0 {
+		return gin.H{}
+	}
+
+	properties := make(gin.H, len(fields))
+	for name, field := range fields {
+		body := gin.H{}
+		if field.Type != "" {
+			body["type"] = field.Type
+		}
+		if field.Analyzer != "" {
+			body["analyzer"] = field.Analyzer
+		}
+		if !field.Index {
+			body["index"] = false
+		}
+		if field.Store {
+			body["store"] = true
+		}
+		if field.IgnoreMalformed {
+			body["ignore_malformed"] = true
+		}
+		if field.Format != "" {
+			body["format"] = field.Format
+		}
+		if len(field.Properties) > 0 {
+			body["properties"] = renderMappings(field.Properties)["properties"]
+		}
+		properties[name] = body
+	}
+	return gin.H{"properties": properties}
+}
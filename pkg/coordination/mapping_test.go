@@ -0,0 +1,120 @@
+package coordination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+func TestMergeMappings(t *testing.T) {
+	existing := map[string]*pb.FieldMapping{
+		"title": {Type: "text", Analyzer: "standard", Index: true},
+		"author": {Index: true, Properties: map[string]*pb.FieldMapping{
+			"name": {Type: "keyword", Index: true},
+		}},
+	}
+
+	merged, err := mergeMappings(existing, map[string]*pb.FieldMapping{
+		"price": {Type: "double", Index: true},
+		"author": {Index: true, Properties: map[string]*pb.FieldMapping{
+			"email": {Type: "keyword", Index: true},
+		}},
+	}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "double", merged["price"].Type)
+	assert.Equal(t, "text", merged["title"].Type)
+	assert.Contains(t, merged["author"].Properties, "name")
+	assert.Contains(t, merged["author"].Properties, "email")
+	assert.NotContains(t, existing["author"].Properties, "email")
+
+	_, err = mergeMappings(existing, map[string]*pb.FieldMapping{"title": {Type: "keyword", Index: true}}, "")
+	assert.EqualError(t, err, "mapper [title] cannot be changed from type [text] to [keyword]")
+
+	_, err = mergeMappings(existing, map[string]*pb.FieldMapping{
+		"author": {Index: true, Properties: map[string]*pb.FieldMapping{"name": {Type: "long", Index: true}}},
+	}, "")
+	assert.EqualError(t, err, "mapper [author.name] cannot be changed from type [keyword] to [long]")
+
+	_, err = mergeMappings(existing, map[string]*pb.FieldMapping{"title": {Type: "text", Analyzer: "whitespace", Index: true}}, "")
+	assert.EqualError(t, err, "mapper [title] cannot update parameter [analyzer] from [standard] to [whitespace]")
+
+	existing["created_at"] = &pb.FieldMapping{Type: "date", Format: "yyyy-MM-dd", Index: true}
+	_, err = mergeMappings(existing, map[string]*pb.FieldMapping{"created_at": {Type: "date", Format: "epoch_millis", Index: true}}, "")
+	assert.EqualError(t, err, "mapper [created_at] cannot update parameter [format] from [yyyy-MM-dd] to [epoch_millis]")
+}
+
+func TestPutMapping(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &resizeMasterServer{indices: map[string]*pb.IndexMetadata{
+		"products": {
+			IndexName: "products",
+			Version:   1,
+			Settings:  &pb.IndexSettings{NumberOfShards: 1},
+			Mappings:  map[string]*pb.FieldMapping{"title": {Type: "text", Index: true}},
+		},
+	}}
+	server := grpc.NewServer()
+	pb.RegisterMasterServiceServer(server, master)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	node := newTestCoordinationNode(t)
+	node.masterClient = NewMasterClient(lis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	defer node.masterClient.Disconnect()
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	// A new field is added alongside the existing ones
+	w := serve(http.MethodPut, "/products/_mapping", `{"properties": {"price": {"type": "double", "ignore_malformed": true}}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	updated := master.indices["products"]
+	assert.Equal(t, "text", updated.Mappings["title"].Type)
+	assert.Equal(t, "double", updated.Mappings["price"].Type)
+	assert.JSONEq(t, `{"price": {"type": "double", "ignore_malformed": true}}`, updated.Settings.NumericFields)
+
+	w = serve(http.MethodGet, "/products/_mapping", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	properties := got["products"].(map[string]interface{})["mappings"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "double", "ignore_malformed": true}, properties["price"])
+	assert.Equal(t, map[string]interface{}{"type": "text"}, properties["title"])
+
+	// Date formats are carried to the data nodes and rendered back
+	w = serve(http.MethodPut, "/products/_mapping", `{"properties": {"created_at": {"type": "date", "format": "yyyy-MM-dd||epoch_millis"}}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"created_at": {"formats": ["yyyy-MM-dd", "epoch_millis"]}}`, master.indices["products"].Settings.DateFields)
+	w = serve(http.MethodGet, "/products/_mapping", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	properties = got["products"].(map[string]interface{})["mappings"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "date", "format": "yyyy-MM-dd||epoch_millis"}, properties["created_at"])
+
+	// Changing an existing field's type is rejected and leaves the mapping
+	w = serve(http.MethodPut, "/products/_mapping", `{"properties": {"title": {"type": "keyword"}}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "illegal_argument_exception")
+	assert.Contains(t, w.Body.String(), "mapper [title] cannot be changed from type [text] to [keyword]")
+	assert.Equal(t, "text", master.indices["products"].Mappings["title"].Type)
+
+	w = serve(http.MethodPut, "/missing/_mapping", `{"properties": {"title": {"type": "text"}}}`)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
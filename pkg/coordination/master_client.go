@@ -2,14 +2,18 @@ package coordination
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"sync"
 	"time"
 
 	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/common/requestid"
+	"github.com/quidditch/quidditch/pkg/common/tracing"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 )
@@ -20,6 +24,7 @@ type MasterClient struct {
 	logger     *zap.Logger
 	conn       *grpc.ClientConn
 	client     pb.MasterServiceClient
+	tlsConfig  *tls.Config
 	mu         sync.RWMutex
 	connected  bool
 }
@@ -32,6 +37,14 @@ func NewMasterClient(masterAddr string, logger *zap.Logger) *MasterClient {
 	}
 }
 
+// SetTLSConfig makes Connect dial the master over TLS
+func (mc *MasterClient) SetTLSConfig(tlsConfig *tls.Config) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.tlsConfig = tlsConfig
+}
+
 // Connect establishes connection to the master node
 func (mc *MasterClient) Connect(ctx context.Context) error {
 	mc.mu.Lock()
@@ -50,7 +63,9 @@ func (mc *MasterClient) Connect(ctx context.Context) error {
 	conn, err := grpc.DialContext(
 		dialCtx,
 		mc.masterAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(transportCredentials(mc.tlsConfig)),
+		tracing.ClientOption(),
+		requestid.ClientOption(),
 		grpc.WithBlock(),
 	)
 	if err != nil {
@@ -229,6 +244,29 @@ func (mc *MasterClient) GetClusterState(ctx context.Context, includeRouting, inc
 	return resp, nil
 }
 
+// WatchClusterState opens a stream of cluster membership events from the
+// master. The stream stays open until ctx is cancelled or the connection breaks.
+func (mc *MasterClient) WatchClusterState(ctx context.Context, fromVersion int64) (pb.MasterService_WatchClusterStateClient, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	mc.logger.Debug("Watching cluster state", zap.Int64("from_version", fromVersion))
+
+	stream, err := client.WatchClusterState(ctx, &pb.WatchClusterStateRequest{
+		FromVersion: fromVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch cluster state: %w", err)
+	}
+
+	return stream, nil
+}
+
 // GetShardRouting retrieves shard routing information for an index
 func (mc *MasterClient) GetShardRouting(ctx context.Context, indexName string) (map[int32]*pb.ShardRouting, error) {
 	// Get cluster state with routing information
@@ -291,6 +329,474 @@ func (mc *MasterClient) UpdateIndexSettings(ctx context.Context, indexName strin
 	return nil, fmt.Errorf("failed to update index settings after %d retries", maxRetries)
 }
 
+// PutMapping replaces an index's field mappings with a mapping merged from
+// its metadata at version, along with the analysis and typed field settings
+// of derived, which are rebuilt from the merged mapping. The master rejects
+// the mapping with Aborted if the index's metadata changed since.
+func (mc *MasterClient) PutMapping(ctx context.Context, indexName string, mappings map[string]*pb.FieldMapping, derived *pb.IndexSettings, version int64) (*pb.PutMappingResponse, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	mc.logger.Info("Putting index mapping", zap.String("index", indexName))
+
+	req := &pb.PutMappingRequest{
+		IndexName:      indexName,
+		Mappings:       mappings,
+		Analysis:       derived.GetAnalysis(),
+		NumericFields:  derived.GetNumericFields(),
+		DateFields:     derived.GetDateFields(),
+		GeoPointFields: derived.GetGeoPointFields(),
+		Version:        version,
+	}
+
+	// Try to put the mapping, handle leader redirection
+	maxRetries := 3
+	for i := 0; i < maxRetries; i++ {
+		resp, err := client.PutMapping(ctx, req)
+		if err != nil {
+			if st, ok := status.FromError(err); ok {
+				if st.Code() == codes.FailedPrecondition {
+					mc.logger.Info("Master not leader, retrying", zap.String("error", st.Message()))
+					time.Sleep(time.Second)
+					continue
+				}
+			}
+			return nil, fmt.Errorf("failed to put mapping: %w", err)
+		}
+
+		mc.logger.Info("Successfully put index mapping",
+			zap.String("index", indexName),
+			zap.Int64("version", resp.Version))
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("failed to put mapping after %d retries", maxRetries)
+}
+
+// PutStoredScript stores a script, such as a mustache search template, in
+// the master metadata
+func (mc *MasterClient) PutStoredScript(ctx context.Context, id, lang, source string) (*pb.PutStoredScriptResponse, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	mc.logger.Info("Storing script", zap.String("id", id))
+
+	req := &pb.PutStoredScriptRequest{
+		Id:     id,
+		Lang:   lang,
+		Source: source,
+	}
+
+	// Try to store the script, handle leader redirection
+	maxRetries := 3
+	for i := 0; i < maxRetries; i++ {
+		resp, err := client.PutStoredScript(ctx, req)
+		if err != nil {
+			if st, ok := status.FromError(err); ok {
+				if st.Code() == codes.FailedPrecondition {
+					mc.logger.Info("Master not leader, retrying", zap.String("error", st.Message()))
+					time.Sleep(time.Second)
+					continue
+				}
+			}
+			return nil, fmt.Errorf("failed to store script: %w", err)
+		}
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("failed to store script after %d retries", maxRetries)
+}
+
+// GetStoredScript retrieves a stored script from the master
+func (mc *MasterClient) GetStoredScript(ctx context.Context, id string) (*pb.GetStoredScriptResponse, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	mc.logger.Debug("Getting stored script", zap.String("id", id))
+
+	resp, err := client.GetStoredScript(ctx, &pb.GetStoredScriptRequest{Id: id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stored script: %w", err)
+	}
+
+	return resp, nil
+}
+
+// PutIndexTemplate stores an index template in the master metadata
+func (mc *MasterClient) PutIndexTemplate(ctx context.Context, template *pb.IndexTemplate) (*pb.PutIndexTemplateResponse, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	mc.logger.Info("Storing index template", zap.String("name", template.Name))
+
+	req := &pb.PutIndexTemplateRequest{Template: template}
+
+	// Try to store the template, handle leader redirection
+	maxRetries := 3
+	for i := 0; i < maxRetries; i++ {
+		resp, err := client.PutIndexTemplate(ctx, req)
+		if err != nil {
+			if st, ok := status.FromError(err); ok {
+				if st.Code() == codes.FailedPrecondition {
+					mc.logger.Info("Master not leader, retrying", zap.String("error", st.Message()))
+					time.Sleep(time.Second)
+					continue
+				}
+			}
+			return nil, fmt.Errorf("failed to store index template: %w", err)
+		}
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("failed to store index template after %d retries", maxRetries)
+}
+
+// GetIndexTemplates retrieves an index template by name from the master,
+// or every template when name is empty
+func (mc *MasterClient) GetIndexTemplates(ctx context.Context, name string) ([]*pb.IndexTemplate, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	mc.logger.Debug("Getting index templates", zap.String("name", name))
+
+	resp, err := client.GetIndexTemplates(ctx, &pb.GetIndexTemplatesRequest{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index templates: %w", err)
+	}
+
+	return resp.Templates, nil
+}
+
+// DeleteIndexTemplate deletes an index template from the master metadata
+func (mc *MasterClient) DeleteIndexTemplate(ctx context.Context, name string) (*pb.DeleteIndexTemplateResponse, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	mc.logger.Info("Deleting index template", zap.String("name", name))
+
+	req := &pb.DeleteIndexTemplateRequest{Name: name}
+
+	// Try to delete the template, handle leader redirection
+	maxRetries := 3
+	for i := 0; i < maxRetries; i++ {
+		resp, err := client.DeleteIndexTemplate(ctx, req)
+		if err != nil {
+			if st, ok := status.FromError(err); ok {
+				if st.Code() == codes.FailedPrecondition {
+					mc.logger.Info("Master not leader, retrying", zap.String("error", st.Message()))
+					time.Sleep(time.Second)
+					continue
+				}
+			}
+			return nil, fmt.Errorf("failed to delete index template: %w", err)
+		}
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("failed to delete index template after %d retries", maxRetries)
+}
+
+// PutSnapshotRepository registers a snapshot repository in the master
+// metadata
+func (mc *MasterClient) PutSnapshotRepository(ctx context.Context, repository *pb.SnapshotRepository) (*pb.PutSnapshotRepositoryResponse, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	mc.logger.Info("Registering snapshot repository", zap.String("name", repository.Name))
+
+	req := &pb.PutSnapshotRepositoryRequest{Repository: repository}
+
+	// Try to register the repository, handle leader redirection
+	maxRetries := 3
+	for i := 0; i < maxRetries; i++ {
+		resp, err := client.PutSnapshotRepository(ctx, req)
+		if err != nil {
+			if st, ok := status.FromError(err); ok {
+				if st.Code() == codes.FailedPrecondition {
+					mc.logger.Info("Master not leader, retrying", zap.String("error", st.Message()))
+					time.Sleep(time.Second)
+					continue
+				}
+			}
+			return nil, fmt.Errorf("failed to register snapshot repository: %w", err)
+		}
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("failed to register snapshot repository after %d retries", maxRetries)
+}
+
+// GetSnapshotRepositories retrieves a snapshot repository by name from the
+// master, or every repository when name is empty
+func (mc *MasterClient) GetSnapshotRepositories(ctx context.Context, name string) ([]*pb.SnapshotRepository, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	mc.logger.Debug("Getting snapshot repositories", zap.String("name", name))
+
+	resp, err := client.GetSnapshotRepositories(ctx, &pb.GetSnapshotRepositoriesRequest{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot repositories: %w", err)
+	}
+
+	return resp.Repositories, nil
+}
+
+// UpdateClusterSettings sets persistent cluster settings and resets others
+// to their defaults, returning the settings after the update
+func (mc *MasterClient) UpdateClusterSettings(ctx context.Context, settings map[string]string, reset []string) (map[string]string, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	mc.logger.Info("Updating cluster settings",
+		zap.Int("settings", len(settings)),
+		zap.Strings("reset", reset))
+
+	req := &pb.UpdateClusterSettingsRequest{
+		Settings:      settings,
+		ResetSettings: reset,
+	}
+
+	// Try to update the settings, handle leader redirection
+	maxRetries := 3
+	for i := 0; i < maxRetries; i++ {
+		resp, err := client.UpdateClusterSettings(ctx, req)
+		if err != nil {
+			if st, ok := status.FromError(err); ok {
+				if st.Code() == codes.FailedPrecondition {
+					mc.logger.Info("Master not leader, retrying", zap.String("error", st.Message()))
+					time.Sleep(time.Second)
+					continue
+				}
+			}
+			return nil, fmt.Errorf("failed to update cluster settings: %w", err)
+		}
+		return resp.Settings, nil
+	}
+
+	return nil, fmt.Errorf("failed to update cluster settings after %d retries", maxRetries)
+}
+
+// GetClusterSettings retrieves the persistent cluster settings
+func (mc *MasterClient) GetClusterSettings(ctx context.Context) (map[string]string, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	resp, err := client.GetClusterSettings(ctx, &pb.GetClusterSettingsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster settings: %w", err)
+	}
+
+	return resp.Settings, nil
+}
+
+// ExplainAllocation asks the master why a copy of a shard is allocated where
+// it is, or why it is unassigned. An empty index name explains the first
+// unassigned shard.
+func (mc *MasterClient) ExplainAllocation(ctx context.Context, indexName string, shardID int32, primary bool) (*pb.ExplainAllocationResponse, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	resp, err := client.ExplainAllocation(ctx, &pb.ExplainAllocationRequest{
+		IndexName: indexName,
+		ShardId:   shardID,
+		Primary:   primary,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain allocation: %w", err)
+	}
+
+	return resp, nil
+}
+
+// PutPipeline stores a pipeline definition in the master metadata
+func (mc *MasterClient) PutPipeline(ctx context.Context, name, definition string) (*pb.PutPipelineResponse, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	mc.logger.Info("Storing pipeline", zap.String("name", name))
+
+	req := &pb.PutPipelineRequest{
+		Pipeline: &pb.StoredPipeline{Name: name, Definition: definition},
+	}
+
+	// Try to store the pipeline, handle leader redirection
+	maxRetries := 3
+	for i := 0; i < maxRetries; i++ {
+		resp, err := client.PutPipeline(ctx, req)
+		if err != nil {
+			if st, ok := status.FromError(err); ok {
+				if st.Code() == codes.FailedPrecondition {
+					mc.logger.Info("Master not leader, retrying", zap.String("error", st.Message()))
+					time.Sleep(time.Second)
+					continue
+				}
+			}
+			return nil, fmt.Errorf("failed to store pipeline: %w", err)
+		}
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("failed to store pipeline after %d retries", maxRetries)
+}
+
+// DeletePipeline deletes a pipeline from the master metadata
+func (mc *MasterClient) DeletePipeline(ctx context.Context, name string) (*pb.DeletePipelineResponse, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	mc.logger.Info("Deleting pipeline", zap.String("name", name))
+
+	req := &pb.DeletePipelineRequest{Name: name}
+
+	// Try to delete the pipeline, handle leader redirection
+	maxRetries := 3
+	for i := 0; i < maxRetries; i++ {
+		resp, err := client.DeletePipeline(ctx, req)
+		if err != nil {
+			if st, ok := status.FromError(err); ok {
+				if st.Code() == codes.FailedPrecondition {
+					mc.logger.Info("Master not leader, retrying", zap.String("error", st.Message()))
+					time.Sleep(time.Second)
+					continue
+				}
+			}
+			return nil, fmt.Errorf("failed to delete pipeline: %w", err)
+		}
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("failed to delete pipeline after %d retries", maxRetries)
+}
+
+// GetPipelines retrieves every stored pipeline and index association from
+// the master
+func (mc *MasterClient) GetPipelines(ctx context.Context) (*pb.GetPipelinesResponse, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	resp, err := client.GetPipelines(ctx, &pb.GetPipelinesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pipelines: %w", err)
+	}
+
+	return resp, nil
+}
+
+// SetIndexPipeline associates a pipeline with an index in the master
+// metadata, or removes the association when pipelineName is empty
+func (mc *MasterClient) SetIndexPipeline(ctx context.Context, indexName, pipelineType, pipelineName string) (*pb.SetIndexPipelineResponse, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	mc.logger.Info("Setting index pipeline",
+		zap.String("index", indexName),
+		zap.String("type", pipelineType),
+		zap.String("pipeline", pipelineName))
+
+	req := &pb.SetIndexPipelineRequest{
+		Association: &pb.IndexPipeline{
+			IndexName: indexName,
+			Type:      pipelineType,
+			Pipeline:  pipelineName,
+		},
+	}
+
+	// Try to set the association, handle leader redirection
+	maxRetries := 3
+	for i := 0; i < maxRetries; i++ {
+		resp, err := client.SetIndexPipeline(ctx, req)
+		if err != nil {
+			if st, ok := status.FromError(err); ok {
+				if st.Code() == codes.FailedPrecondition {
+					mc.logger.Info("Master not leader, retrying", zap.String("error", st.Message()))
+					time.Sleep(time.Second)
+					continue
+				}
+			}
+			return nil, fmt.Errorf("failed to set index pipeline: %w", err)
+		}
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("failed to set index pipeline after %d retries", maxRetries)
+}
+
 // GetClusterHealth retrieves cluster health information
 func (mc *MasterClient) GetClusterHealth(ctx context.Context) (*pb.ClusterStateResponse, error) {
 	// Cluster health is derived from cluster state
@@ -317,3 +823,12 @@ func (mc *MasterClient) Reconnect(ctx context.Context) error {
 	mc.logger.Info("Successfully reconnected to master")
 	return nil
 }
+
+// transportCredentials returns TLS credentials for tlsConfig, or insecure
+// credentials when TLS isn't configured
+func transportCredentials(tlsConfig *tls.Config) credentials.TransportCredentials {
+	if tlsConfig == nil {
+		return insecure.NewCredentials()
+	}
+	return credentials.NewTLS(tlsConfig)
+}
@@ -0,0 +1,128 @@
+package coordination
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quidditch/quidditch/pkg/coordination/router"
+	"go.uber.org/zap"
+)
+
+// multiGetRequest is the body of a _mget request
+type multiGetRequest struct {
+	Docs []struct {
+		Index string `json:"_index"`
+		ID    string `json:"_id"`
+	} `json:"docs"`
+	IDs []string `json:"ids"`
+}
+
+// handleMultiGet fetches several documents in one request. Documents are
+// listed in "docs" (each with an optional _index defaulting to the URL
+// index) or, for a URL index, as plain "ids". Each document may be in a
+// different index, so read permission is checked per document and a
+// document the caller can't read gets a security error in its place.
+func (c *CoordinationNode) handleMultiGet(ctx *gin.Context) {
+	urlIndex := ctx.Param("index")
+
+	badRequest := func(reason string) {
+		respondError(ctx, http.StatusBadRequest, "action_request_validation_exception", reason)
+	}
+
+	var req multiGetRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, "parse_exception", fmt.Sprintf("Failed to parse request body: %v", err))
+		return
+	}
+
+	var items []router.MultiGetItem
+	for i, doc := range req.Docs {
+		indexName := doc.Index
+		if indexName == "" {
+			indexName = urlIndex
+		}
+		if indexName == "" {
+			badRequest(fmt.Sprintf("index is missing for doc %d", i))
+			return
+		}
+		if doc.ID == "" {
+			badRequest(fmt.Sprintf("id is missing for doc %d", i))
+			return
+		}
+		items = append(items, router.MultiGetItem{IndexName: indexName, DocID: doc.ID})
+	}
+	if len(req.IDs) > 0 {
+		if urlIndex == "" {
+			badRequest("ids can only be used with an index in the URL")
+			return
+		}
+		for _, id := range req.IDs {
+			items = append(items, router.MultiGetItem{IndexName: urlIndex, DocID: id})
+		}
+	}
+	if len(items) == 0 {
+		badRequest("no documents to get")
+		return
+	}
+
+	identity, _ := identityFromContext(ctx)
+	docs := make([]gin.H, len(items))
+	var allowed []router.MultiGetItem
+	var positions []int
+	for i, item := range items {
+		if c.authorizer != nil && !c.authorizer.Allowed(identity, ActionRead, item.IndexName) {
+			docs[i] = gin.H{
+				"_index": item.IndexName,
+				"_id":    item.DocID,
+				"error": gin.H{
+					"type":   "security_exception",
+					"reason": forbiddenReason(identity, ActionRead, item.IndexName),
+				},
+			}
+			continue
+		}
+		allowed = append(allowed, item)
+		positions = append(positions, i)
+	}
+
+	var results []router.MultiGetResult
+	if len(allowed) > 0 {
+		results = c.docRouter.RouteMultiGetDocuments(ctx.Request.Context(), allowed)
+	}
+
+	for j, result := range results {
+		i := positions[j]
+		item := items[i]
+		switch {
+		case result.Err != nil && strings.Contains(result.Err.Error(), "not found"):
+			docs[i] = gin.H{"_index": item.IndexName, "_id": item.DocID, "found": false}
+		case result.Err != nil:
+			c.logger.Warn("Failed to get document in multi get",
+				zap.String("index", item.IndexName),
+				zap.String("doc_id", item.DocID),
+				zap.Error(result.Err))
+			docs[i] = gin.H{
+				"_index": item.IndexName,
+				"_id":    item.DocID,
+				"error": gin.H{
+					"type":   "get_failed_exception",
+					"reason": fmt.Sprintf("Failed to get document: %v", result.Err),
+				},
+			}
+		case !result.Response.Found:
+			docs[i] = gin.H{"_index": item.IndexName, "_id": item.DocID, "found": false}
+		default:
+			docs[i] = gin.H{
+				"_index":   item.IndexName,
+				"_id":      item.DocID,
+				"_version": result.Response.Version,
+				"found":    true,
+				"_source":  result.Response.Document.AsMap(),
+			}
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"docs": docs})
+}
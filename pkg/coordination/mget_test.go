@@ -0,0 +1,94 @@
+package coordination
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiGet_ExistingAndMissingIDs(t *testing.T) {
+	node := setupByQueryNode(newMemoryIndex())
+
+	w, response := postJSON(node.ginRouter, "/products/_mget", `{"ids": ["3", "missing", "1"]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	docs := response["docs"].([]interface{})
+	require.Len(t, docs, 3)
+
+	// Results keep the request order
+	first := docs[0].(map[string]interface{})
+	assert.Equal(t, "products", first["_index"])
+	assert.Equal(t, "3", first["_id"])
+	assert.Equal(t, true, first["found"])
+	assert.Equal(t, "books", first["_source"].(map[string]interface{})["category"])
+
+	missing := docs[1].(map[string]interface{})
+	assert.Equal(t, "missing", missing["_id"])
+	assert.Equal(t, false, missing["found"])
+	assert.NotContains(t, missing, "_source")
+
+	last := docs[2].(map[string]interface{})
+	assert.Equal(t, "1", last["_id"])
+	assert.Equal(t, true, last["found"])
+}
+
+func TestMultiGet_Docs(t *testing.T) {
+	node := setupByQueryNode(newMemoryIndex())
+
+	w, response := postJSON(node.ginRouter, "/_mget",
+		`{"docs": [{"_index": "products", "_id": "2"}, {"_index": "products", "_id": "9"}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	docs := response["docs"].([]interface{})
+	require.Len(t, docs, 2)
+	assert.Equal(t, true, docs[0].(map[string]interface{})["found"])
+	assert.Equal(t, "music", docs[0].(map[string]interface{})["_source"].(map[string]interface{})["category"])
+	assert.Equal(t, false, docs[1].(map[string]interface{})["found"])
+}
+
+func TestMultiGet_InvalidRequest(t *testing.T) {
+	node := setupByQueryNode(newMemoryIndex())
+
+	// ids need an index in the URL
+	w, _ := postJSON(node.ginRouter, "/_mget", `{"ids": ["1"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// docs need an index when the URL has none
+	w, _ = postJSON(node.ginRouter, "/_mget", `{"docs": [{"_id": "1"}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, _ = postJSON(node.ginRouter, "/products/_mget", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestMultiGet_ItemsAuthorized(t *testing.T) {
+	authorizer, err := newAuthorizer(authzTestConfig())
+	require.NoError(t, err)
+
+	node := setupByQueryNode(newMemoryIndex())
+	node.authorizer = authorizer
+	engine := gin.New()
+	engine.Use(func(ctx *gin.Context) {
+		ctx.Set(identityContextKey, &Identity{Name: "analyst", Roles: []string{"reader"}})
+	})
+	engine.POST("/_mget", node.handleMultiGet)
+
+	// The readable document is fetched; the other gets a security error
+	w, response := postJSON(engine, "/_mget",
+		`{"docs": [{"_index": "orders", "_id": "1"}, {"_index": "products", "_id": "2"}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	docs := response["docs"].([]interface{})
+	require.Len(t, docs, 2)
+	denied := docs[0].(map[string]interface{})
+	assert.Equal(t, "orders", denied["_index"])
+	assert.Equal(t, "security_exception", denied["error"].(map[string]interface{})["type"])
+	assert.NotContains(t, denied, "found")
+
+	allowed := docs[1].(map[string]interface{})
+	assert.Equal(t, true, allowed["found"])
+	assert.Equal(t, "music", allowed["_source"].(map[string]interface{})["category"])
+}
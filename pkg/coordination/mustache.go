@@ -0,0 +1,216 @@
+package coordination
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// mustacheNode is a parsed element of a mustache template
+type mustacheNode struct {
+	kind     byte   // 0 literal text, 'v' escaped variable, '&' raw variable, '#' section, '^' inverted section
+	text     string // literal text or tag name
+	children []*mustacheNode
+}
+
+// renderMustache renders a search template with its params. It supports the
+// mustache subset used by search templates:
+//
+//   - {{name}} and {{a.b}} insert a value, strings JSON-escaped so they can
+//     sit inside a quoted JSON string; {{{name}}} and {{&name}} insert it raw
+//   - {{#name}}...{{/name}} renders its body when the value is truthy, once
+//     per element for lists; {{^name}}...{{/name}} when it is not
+//   - {{#toJson}}name{{/toJson}} inserts the value as JSON
+//   - {{! comment}} is dropped
+//
+// Missing values render as empty strings.
+func renderMustache(source string, params map[string]interface{}) (string, error) {
+	nodes, _, err := parseMustache(source, "")
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := renderMustacheNodes(&b, nodes, []interface{}{params}); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// parseMustache parses a template up to the closing tag of section (or the
+// end of input when section is empty) and returns the rest of the template
+func parseMustache(source, section string) ([]*mustacheNode, string, error) {
+	var nodes []*mustacheNode
+	for {
+		start := strings.Index(source, "{{")
+		if start < 0 {
+			if section != "" {
+				return nil, "", fmt.Errorf("unclosed section {{#%s}}", section)
+			}
+			if source != "" {
+				nodes = append(nodes, &mustacheNode{text: source})
+			}
+			return nodes, "", nil
+		}
+		if start > 0 {
+			nodes = append(nodes, &mustacheNode{text: source[:start]})
+		}
+		source = source[start:]
+
+		closing := "}}"
+		if strings.HasPrefix(source, "{{{") {
+			closing = "}}}"
+		}
+		end := strings.Index(source, closing)
+		if end < 0 {
+			return nil, "", fmt.Errorf("unclosed tag %q", source)
+		}
+		tag := source[2:end]
+		source = source[end+len(closing):]
+
+		if closing == "}}}" {
+			nodes = append(nodes, &mustacheNode{kind: '&', text: strings.TrimSpace(tag[1:])})
+			continue
+		}
+
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			return nil, "", fmt.Errorf("empty tag")
+		}
+		name := strings.TrimSpace(tag[1:])
+		switch tag[0] {
+		case '!':
+			// Comment
+		case '#', '^':
+			children, rest, err := parseMustache(source, name)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, &mustacheNode{kind: tag[0], text: name, children: children})
+			source = rest
+		case '/':
+			if name != section {
+				return nil, "", fmt.Errorf("unexpected closing tag {{/%s}}", name)
+			}
+			return nodes, source, nil
+		case '&':
+			nodes = append(nodes, &mustacheNode{kind: '&', text: name})
+		default:
+			nodes = append(nodes, &mustacheNode{kind: 'v', text: tag})
+		}
+	}
+}
+
+// renderMustacheNodes renders nodes against a context stack, innermost last
+func renderMustacheNodes(b *strings.Builder, nodes []*mustacheNode, stack []interface{}) error {
+	for _, node := range nodes {
+		switch node.kind {
+		case 0:
+			b.WriteString(node.text)
+		case 'v', '&':
+			value := lookupMustache(stack, node.text)
+			if value == nil {
+				continue
+			}
+			if s, ok := value.(string); ok {
+				if node.kind == '&' {
+					b.WriteString(s)
+					continue
+				}
+				encoded, _ := json.Marshal(s)
+				b.Write(encoded[1 : len(encoded)-1])
+				continue
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("failed to render {{%s}}: %w", node.text, err)
+			}
+			b.Write(encoded)
+		case '#':
+			if node.text == "toJson" {
+				var name strings.Builder
+				if err := renderMustacheNodes(&name, node.children, stack); err != nil {
+					return err
+				}
+				encoded, err := json.Marshal(lookupMustache(stack, strings.TrimSpace(name.String())))
+				if err != nil {
+					return fmt.Errorf("failed to render toJson: %w", err)
+				}
+				b.Write(encoded)
+				continue
+			}
+
+			value := lookupMustache(stack, node.text)
+			if !mustacheTruthy(value) {
+				continue
+			}
+			if list, ok := value.([]interface{}); ok {
+				for _, item := range list {
+					if err := renderMustacheNodes(b, node.children, append(stack, item)); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := renderMustacheNodes(b, node.children, append(stack, value)); err != nil {
+				return err
+			}
+		case '^':
+			if mustacheTruthy(lookupMustache(stack, node.text)) {
+				continue
+			}
+			if err := renderMustacheNodes(b, node.children, stack); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// lookupMustache resolves a dotted name against the innermost context that
+// has its first part; "." is the innermost context itself
+func lookupMustache(stack []interface{}, name string) interface{} {
+	if name == "." {
+		return stack[len(stack)-1]
+	}
+
+	parts := strings.Split(name, ".")
+	for i := len(stack) - 1; i >= 0; i-- {
+		ctxMap, ok := stack[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		value, ok := ctxMap[parts[0]]
+		if !ok {
+			continue
+		}
+		for _, part := range parts[1:] {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			value = m[part]
+		}
+		return value
+	}
+	return nil
+}
+
+// mustacheTruthy reports whether a section renders for a value: nil, false,
+// empty strings and empty lists or objects are falsy
+func mustacheTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map {
+		return rv.Len() > 0
+	}
+	return true
+}
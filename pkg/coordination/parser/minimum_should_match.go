@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A minimum_should_match given as a string takes one of these forms, where
+// n is the number of should clauses:
+//
+//	"3"          3 clauses
+//	"-2"         all but 2 clauses (n-2)
+//	"75%"        75% of the clauses, rounded down
+//	"-25%"       all but 25% of the clauses, rounded down
+//	"3<90%"      all clauses when n <= 3, else 90% of them
+//	"2<-25% 9<-3" combinations, checked in order: all clauses when n <= 2,
+//	             all but 25% when n <= 9, else all but 3
+//
+// A resolved count below zero is zero.
+
+// conditionSeparator matches the < of a combination condition with any
+// whitespace around it
+var conditionSeparator = regexp.MustCompile(`\s*<\s*`)
+
+// ResolveMinimumShouldMatch resolves a minimum_should_match spec against
+// the number of should clauses
+func ResolveMinimumShouldMatch(spec string, clauses int) (int, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return 0, fmt.Errorf("minimum_should_match is empty")
+	}
+
+	if strings.Contains(spec, "<") {
+		// Conditions are separated by whitespace; allow it around the <
+		conditions := strings.Fields(conditionSeparator.ReplaceAllString(spec, "<"))
+		bounds := make([]int, len(conditions))
+		values := make([]string, len(conditions))
+		for i, condition := range conditions {
+			boundText, value, ok := strings.Cut(condition, "<")
+			if !ok || strings.Contains(value, "<") {
+				return 0, fmt.Errorf("invalid minimum_should_match condition [%s]: expected n<value", condition)
+			}
+			bound, err := strconv.Atoi(boundText)
+			if err != nil {
+				return 0, fmt.Errorf("invalid minimum_should_match condition [%s]: [%s] is not an integer", condition, boundText)
+			}
+			if _, err := ResolveMinimumShouldMatch(value, clauses); err != nil {
+				return 0, err
+			}
+			bounds[i], values[i] = bound, value
+		}
+
+		// Each condition applies past its bound, up to the next one's;
+		// up to the first bound every clause is required
+		result := clauses
+		for i, bound := range bounds {
+			if clauses <= bound {
+				break
+			}
+			result, _ = ResolveMinimumShouldMatch(values[i], clauses)
+		}
+		return result, nil
+	}
+
+	var result int
+	if percentText, ok := strings.CutSuffix(spec, "%"); ok {
+		percent, err := strconv.Atoi(percentText)
+		if err != nil {
+			return 0, fmt.Errorf("invalid minimum_should_match [%s]: expected an integer percentage", spec)
+		}
+		// Rounded toward zero, so a negative percentage leaves at least
+		// the rounded-down share of clauses optional
+		result = clauses * percent / 100
+		if percent < 0 {
+			result += clauses
+		}
+	} else {
+		count, err := strconv.Atoi(spec)
+		if err != nil {
+			return 0, fmt.Errorf("invalid minimum_should_match [%s]: expected an integer, a percentage or a combination", spec)
+		}
+		result = count
+		if count < 0 {
+			result += clauses
+		}
+	}
+
+	if result < 0 {
+		return 0, nil
+	}
+	return result, nil
+}
+
+// RequiredShouldClauses returns how many of the should clauses a
+// document must match, resolving minimum_should_match against their
+// number. Zero leaves the default: one clause when the bool query has
+// nothing but should clauses, none otherwise.
+func (q *BoolQuery) RequiredShouldClauses() int {
+	if q.MinimumShouldMatchStr != "" {
+		// The spec was validated when the query was parsed
+		resolved, _ := ResolveMinimumShouldMatch(q.MinimumShouldMatchStr, len(q.Should))
+		return resolved
+	}
+	if q.MinimumShouldMatch < 0 {
+		return max(0, len(q.Should)+q.MinimumShouldMatch)
+	}
+	return q.MinimumShouldMatch
+}
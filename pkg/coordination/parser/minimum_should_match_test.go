@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestResolveMinimumShouldMatch(t *testing.T) {
+	tests := []struct {
+		spec string
+		want map[int]int // should clause count -> required clauses
+	}{
+		{"2", map[int]int{1: 2, 2: 2, 5: 2}},
+		{"-2", map[int]int{1: 0, 2: 0, 3: 1, 5: 3}},
+		{"75%", map[int]int{1: 0, 2: 1, 3: 2, 4: 3, 10: 7}},
+		{"-25%", map[int]int{1: 1, 3: 3, 4: 3, 10: 8}},
+		{"100%", map[int]int{0: 0, 3: 3}},
+		{"3<90%", map[int]int{1: 1, 3: 3, 4: 3, 10: 9}},
+		{"2<-25% 9<-3", map[int]int{1: 1, 2: 2, 3: 3, 4: 3, 8: 6, 9: 7, 10: 7, 20: 17}},
+		{" 2 < -25%  9 < -3 ", map[int]int{2: 2, 8: 6, 20: 17}},
+	}
+
+	for _, tt := range tests {
+		for clauses, want := range tt.want {
+			t.Run(fmt.Sprintf("%s/%d", tt.spec, clauses), func(t *testing.T) {
+				got, err := ResolveMinimumShouldMatch(tt.spec, clauses)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got != want {
+					t.Errorf("ResolveMinimumShouldMatch(%q, %d) = %d, want %d", tt.spec, clauses, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestResolveMinimumShouldMatchInvalid(t *testing.T) {
+	for _, spec := range []string{"", "abc", "75.5%", "%", "3<", "<50%", "x<50%", "3<50%<2", "2<50% 9<abc"} {
+		if _, err := ResolveMinimumShouldMatch(spec, 5); err == nil {
+			t.Errorf("expected an error for %q", spec)
+		}
+	}
+}
+
+func TestParseBoolQueryMinimumShouldMatch(t *testing.T) {
+	parseBool := func(minimumShouldMatch string, clauses int) (*BoolQuery, error) {
+		should := make([]string, clauses)
+		for i := range should {
+			should[i] = fmt.Sprintf(`{"term": {"tag": "t%d"}}`, i)
+		}
+		body := fmt.Sprintf(`{"query": {"bool": {"should": [%s], "minimum_should_match": %s}}}`,
+			strings.Join(should, ","), minimumShouldMatch)
+		req, err := NewQueryParser().ParseSearchRequest([]byte(body))
+		if err != nil {
+			return nil, err
+		}
+		return req.ParsedQuery.(*BoolQuery), nil
+	}
+
+	tests := []struct {
+		spec    string
+		clauses int
+		want    int
+	}{
+		{`2`, 4, 2},
+		{`-1`, 4, 3},
+		{`"75%"`, 4, 3},
+		{`"75%"`, 8, 6},
+		{`"2<-25% 9<-3"`, 2, 2},
+		{`"2<-25% 9<-3"`, 8, 6},
+		{`"2<-25% 9<-3"`, 12, 9},
+	}
+	for _, tt := range tests {
+		query, err := parseBool(tt.spec, tt.clauses)
+		if err != nil {
+			t.Fatalf("minimum_should_match %s: unexpected error: %v", tt.spec, err)
+		}
+		if got := query.RequiredShouldClauses(); got != tt.want {
+			t.Errorf("minimum_should_match %s over %d clauses: got %d, want %d", tt.spec, tt.clauses, got, tt.want)
+		}
+	}
+
+	// Malformed specs are rejected when the query is parsed
+	for _, spec := range []string{`"lots"`, `"2<x"`, `1.5`, `true`} {
+		if _, err := parseBool(spec, 3); err == nil {
+			t.Errorf("minimum_should_match %s: expected an error", spec)
+		}
+	}
+}
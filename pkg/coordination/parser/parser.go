@@ -3,18 +3,35 @@ package parser
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"strings"
 
+	"github.com/quidditch/quidditch/pkg/common/geo"
 	"github.com/quidditch/quidditch/pkg/coordination/expressions"
+	"github.com/quidditch/quidditch/pkg/data/suggest"
 )
 
+// MaxRegexpLength is the longest pattern a regexp query may use
+const MaxRegexpLength = 1000
+
 // QueryParser parses OpenSearch Query DSL
-type QueryParser struct{}
+type QueryParser struct {
+	// allowUnboundedRegexp permits regexp patterns starting with .* or .+,
+	// which have to be checked against every term in the field
+	allowUnboundedRegexp bool
+}
 
 // NewQueryParser creates a new query parser
 func NewQueryParser() *QueryParser {
 	return &QueryParser{}
 }
 
+// SetAllowUnboundedRegexp controls whether regexp queries may start with an
+// unbounded wildcard (.* or .+); they are rejected by default
+func (p *QueryParser) SetAllowUnboundedRegexp(allow bool) {
+	p.allowUnboundedRegexp = allow
+}
+
 // ParseSearchRequest parses a complete search request
 func (p *QueryParser) ParseSearchRequest(body []byte) (*SearchRequest, error) {
 	var req SearchRequest
@@ -22,6 +39,52 @@ func (p *QueryParser) ParseSearchRequest(body []byte) (*SearchRequest, error) {
 		return nil, fmt.Errorf("failed to parse search request: %w", err)
 	}
 
+	// A missing size defaults, but an explicit size of 0 asks for no hits
+	// and is kept
+	var present struct {
+		Size *int `json:"size"`
+	}
+	if err := json.Unmarshal(body, &present); err == nil && present.Size == nil {
+		req.Size = DefaultSize
+	}
+	if req.Size < 0 {
+		return nil, fmt.Errorf("failed to parse search request: size must be >= 0, got %d", req.Size)
+	}
+	if req.From < 0 {
+		return nil, fmt.Errorf("failed to parse search request: from must be >= 0, got %d", req.From)
+	}
+
+	if _, err := req.TimeoutDuration(); err != nil {
+		return nil, fmt.Errorf("failed to parse search request: %w", err)
+	}
+	if req.TerminateAfter < 0 {
+		return nil, fmt.Errorf("failed to parse search request: terminate_after must be >= 0, got %d", req.TerminateAfter)
+	}
+	if req.MinScore < 0 {
+		return nil, fmt.Errorf("failed to parse search request: min_score must be >= 0, got %g", req.MinScore)
+	}
+	for _, entry := range req.IndicesBoost {
+		for index, boost := range entry {
+			if boost < 0 {
+				return nil, fmt.Errorf("failed to parse search request: indices_boost for [%s] must be >= 0, got %g", index, boost)
+			}
+		}
+	}
+	if req.Collapse != nil {
+		if req.Collapse.Field == "" {
+			return nil, fmt.Errorf("failed to parse search request: collapse requires a field")
+		}
+		if inner := req.Collapse.InnerHits; inner != nil && inner.Size != nil && *inner.Size < 0 {
+			return nil, fmt.Errorf("failed to parse search request: inner_hits size must be >= 0, got %d", *inner.Size)
+		}
+	}
+
+	if req.Suggest != nil {
+		if _, err := suggest.Parse(req.Suggest); err != nil {
+			return nil, fmt.Errorf("failed to parse search request: %w", err)
+		}
+	}
+
 	// Parse the query if present
 	if req.Query != nil {
 		parsedQuery, err := p.ParseQuery(req.Query)
@@ -46,45 +109,86 @@ func (p *QueryParser) ParseQuery(queryMap map[string]interface{}) (Query, error)
 	}
 
 	for queryType, queryBody := range queryMap {
-		switch queryType {
-		case "match":
-			return p.parseMatchQuery(queryBody)
-		case "match_phrase":
-			return p.parseMatchPhraseQuery(queryBody)
-		case "multi_match":
-			return p.parseMultiMatchQuery(queryBody)
-		case "term":
-			return p.parseTermQuery(queryBody)
-		case "terms":
-			return p.parseTermsQuery(queryBody)
-		case "range":
-			return p.parseRangeQuery(queryBody)
-		case "bool":
-			return p.parseBoolQuery(queryBody)
-		case "match_all":
-			return p.parseMatchAllQuery(queryBody)
-		case "exists":
-			return p.parseExistsQuery(queryBody)
-		case "prefix":
-			return p.parsePrefixQuery(queryBody)
-		case "wildcard":
-			return p.parseWildcardQuery(queryBody)
-		case "fuzzy":
-			return p.parseFuzzyQuery(queryBody)
-		case "query_string":
-			return p.parseQueryStringQuery(queryBody)
-		case "expr":
-			return p.parseExpressionQuery(queryBody)
-		case "wasm_udf":
-			return p.parseWasmUDFQuery(queryBody)
-		default:
-			return nil, fmt.Errorf("unsupported query type: %s", queryType)
+		query, err := p.parseQueryBody(queryType, queryBody)
+		if err != nil {
+			return nil, err
+		}
+		if named, ok := query.(interface{ setQueryName(string) }); ok {
+			named.setQueryName(queryName(queryBody))
 		}
+		return query, nil
 	}
 
 	return nil, fmt.Errorf("failed to parse query")
 }
 
+// queryName returns the _name of a query clause, given either in the query
+// body ({"bool": {..., "_name": "q"}}) or in its field object
+// ({"term": {"status": {"value": "active", "_name": "q"}}})
+func queryName(body interface{}) string {
+	bodyMap, ok := body.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if name, ok := bodyMap["_name"].(string); ok {
+		return name
+	}
+	for _, value := range bodyMap {
+		if fieldMap, ok := value.(map[string]interface{}); ok {
+			if name, ok := fieldMap["_name"].(string); ok {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// parseQueryBody parses the body of a query of the given type
+func (p *QueryParser) parseQueryBody(queryType string, queryBody interface{}) (Query, error) {
+	switch queryType {
+	case "match":
+		return p.parseMatchQuery(queryBody)
+	case "match_phrase":
+		return p.parseMatchPhraseQuery(queryBody)
+	case "multi_match":
+		return p.parseMultiMatchQuery(queryBody)
+	case "term":
+		return p.parseTermQuery(queryBody)
+	case "terms":
+		return p.parseTermsQuery(queryBody)
+	case "ids":
+		return p.parseIDsQuery(queryBody)
+	case "range":
+		return p.parseRangeQuery(queryBody)
+	case "bool":
+		return p.parseBoolQuery(queryBody)
+	case "match_all":
+		return p.parseMatchAllQuery(queryBody)
+	case "exists":
+		return p.parseExistsQuery(queryBody)
+	case "prefix":
+		return p.parsePrefixQuery(queryBody)
+	case "wildcard":
+		return p.parseWildcardQuery(queryBody)
+	case "regexp":
+		return p.parseRegexpQuery(queryBody)
+	case "fuzzy":
+		return p.parseFuzzyQuery(queryBody)
+	case "geo_distance":
+		return p.parseGeoDistanceQuery(queryBody)
+	case "query_string":
+		return p.parseQueryStringQuery(queryBody)
+	case "simple_query_string":
+		return p.parseSimpleQueryStringQuery(queryBody)
+	case "expr":
+		return p.parseExpressionQuery(queryBody)
+	case "wasm_udf":
+		return p.parseWasmUDFQuery(queryBody)
+	default:
+		return nil, fmt.Errorf("unsupported query type: %s", queryType)
+	}
+}
+
 // parseMatchQuery parses a match query
 func (p *QueryParser) parseMatchQuery(body interface{}) (Query, error) {
 	bodyMap, ok := body.(map[string]interface{})
@@ -250,6 +354,33 @@ func (p *QueryParser) parseTermsQuery(body interface{}) (Query, error) {
 	return nil, fmt.Errorf("terms query must have a field")
 }
 
+// parseIDsQuery parses an ids query: {"ids": {"values": ["1", "2"]}}
+func (p *QueryParser) parseIDsQuery(body interface{}) (Query, error) {
+	bodyMap, ok := body.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("ids query body must be an object")
+	}
+
+	values, ok := bodyMap["values"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("ids query values must be an array")
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("ids query values must not be empty")
+	}
+
+	query := &IDsQuery{Values: make([]string, 0, len(values))}
+	for _, value := range values {
+		id, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("ids query values must be strings, got %T", value)
+		}
+		query.Values = append(query.Values, id)
+	}
+
+	return query, nil
+}
+
 // parseRangeQuery parses a range query
 func (p *QueryParser) parseRangeQuery(body interface{}) (Query, error) {
 	bodyMap, ok := body.(map[string]interface{})
@@ -282,6 +413,9 @@ func (p *QueryParser) parseRangeQuery(body interface{}) (Query, error) {
 		if boost, ok := rangeMap["boost"].(float64); ok {
 			query.Boost = boost
 		}
+		if format, ok := rangeMap["format"].(string); ok {
+			query.Format = format
+		}
 
 		return query, nil
 	}
@@ -338,9 +472,17 @@ func (p *QueryParser) parseBoolQuery(body interface{}) (Query, error) {
 	if minMatch, ok := bodyMap["minimum_should_match"]; ok {
 		switch v := minMatch.(type) {
 		case float64:
+			if v != math.Trunc(v) {
+				return nil, fmt.Errorf("invalid minimum_should_match [%v]: expected an integer", v)
+			}
 			query.MinimumShouldMatch = int(v)
 		case string:
+			if _, err := ResolveMinimumShouldMatch(v, len(query.Should)); err != nil {
+				return nil, err
+			}
 			query.MinimumShouldMatchStr = v
+		default:
+			return nil, fmt.Errorf("minimum_should_match must be an integer or a string")
 		}
 	}
 
@@ -465,6 +607,92 @@ func (p *QueryParser) parseWildcardQuery(body interface{}) (Query, error) {
 	return nil, fmt.Errorf("wildcard query must have a field")
 }
 
+// parseRegexpQuery parses a regexp query:
+// {"regexp": {"field": "pattern"}} or {"regexp": {"field": {"value": "pattern"}}}
+func (p *QueryParser) parseRegexpQuery(body interface{}) (Query, error) {
+	bodyMap, ok := body.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("regexp query body must be an object")
+	}
+
+	for field, value := range bodyMap {
+		query := &RegexpQuery{
+			Field: field,
+		}
+
+		switch v := value.(type) {
+		case string:
+			query.Value = v
+		case map[string]interface{}:
+			if val, ok := v["value"].(string); ok {
+				query.Value = val
+			}
+		default:
+			return nil, fmt.Errorf("invalid regexp query value type")
+		}
+
+		if query.Value == "" {
+			return nil, fmt.Errorf("regexp query pattern is empty")
+		}
+		if len(query.Value) > MaxRegexpLength {
+			return nil, fmt.Errorf("regexp query pattern length [%d] exceeds the maximum of [%d]", len(query.Value), MaxRegexpLength)
+		}
+		if !p.allowUnboundedRegexp && (strings.HasPrefix(query.Value, ".*") || strings.HasPrefix(query.Value, ".+")) {
+			return nil, fmt.Errorf("regexp query pattern [%s] starts with an unbounded wildcard, which is disabled", query.Value)
+		}
+
+		return query, nil
+	}
+
+	return nil, fmt.Errorf("regexp query must have a field")
+}
+
+// parseGeoDistanceQuery parses a geo_distance query:
+// {"geo_distance": {"distance": "10km", "location": {"lat": 40.7, "lon": -74.0}}}
+func (p *QueryParser) parseGeoDistanceQuery(body interface{}) (Query, error) {
+	bodyMap, ok := body.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("geo_distance query body must be an object")
+	}
+
+	query := &GeoDistanceQuery{}
+
+	distance, ok := bodyMap["distance"]
+	if !ok {
+		return nil, fmt.Errorf("geo_distance query must have a distance")
+	}
+	meters, err := geo.ParseDistance(distance)
+	if err != nil {
+		return nil, fmt.Errorf("geo_distance query: %w", err)
+	}
+	query.Distance = meters
+
+	for key, value := range bodyMap {
+		switch key {
+		case "distance", "distance_type", "validation_method", "_name", "boost":
+			continue
+		case "render_distance":
+			query.RenderDistance, _ = value.(bool)
+			continue
+		}
+		if query.Field != "" {
+			return nil, fmt.Errorf("geo_distance query supports a single field, got [%s] and [%s]", query.Field, key)
+		}
+		origin, err := geo.ParsePoint(value)
+		if err != nil {
+			return nil, fmt.Errorf("geo_distance query origin for [%s]: %w", key, err)
+		}
+		query.Field = key
+		query.Origin = origin
+	}
+
+	if query.Field == "" {
+		return nil, fmt.Errorf("geo_distance query must have a field")
+	}
+
+	return query, nil
+}
+
 // parseFuzzyQuery parses a fuzzy query
 func (p *QueryParser) parseFuzzyQuery(body interface{}) (Query, error) {
 	bodyMap, ok := body.(map[string]interface{})
@@ -525,6 +753,55 @@ func (p *QueryParser) parseQueryStringQuery(body interface{}) (Query, error) {
 		}
 	}
 
+	if operator, ok := bodyMap["default_operator"].(string); ok {
+		if !strings.EqualFold(operator, "and") && !strings.EqualFold(operator, "or") {
+			return nil, fmt.Errorf("query_string query default_operator must be AND or OR, got [%s]", operator)
+		}
+		query.DefaultOperator = strings.ToUpper(operator)
+	}
+
+	parsed, err := parseQueryString(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query_string [%s]: %w", query.Query, err)
+	}
+	query.Parsed = parsed
+
+	return query, nil
+}
+
+// parseSimpleQueryStringQuery parses a simple_query_string query. Only the
+// body's structure is checked: the query string itself always parses.
+func (p *QueryParser) parseSimpleQueryStringQuery(body interface{}) (Query, error) {
+	bodyMap, ok := body.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("simple_query_string query body must be an object")
+	}
+
+	query := &SimpleQueryStringQuery{}
+
+	if q, ok := bodyMap["query"].(string); ok {
+		query.Query = q
+	} else {
+		return nil, fmt.Errorf("simple_query_string query must have a query string")
+	}
+
+	if fields, ok := bodyMap["fields"].([]interface{}); ok {
+		for _, f := range fields {
+			if field, ok := f.(string); ok {
+				query.Fields = append(query.Fields, field)
+			}
+		}
+	}
+
+	if operator, ok := bodyMap["default_operator"].(string); ok {
+		if !strings.EqualFold(operator, "and") && !strings.EqualFold(operator, "or") {
+			return nil, fmt.Errorf("simple_query_string query default_operator must be AND or OR, got [%s]", operator)
+		}
+		query.DefaultOperator = strings.ToUpper(operator)
+	}
+
+	query.Parsed = parseSimpleQueryString(query)
+
 	return query, nil
 }
 
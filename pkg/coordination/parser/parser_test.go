@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseMatchQuery(t *testing.T) {
@@ -133,6 +136,37 @@ func TestParseTermQuery(t *testing.T) {
 	}
 }
 
+func TestParseIDsQuery(t *testing.T) {
+	parser := NewQueryParser()
+
+	query, err := parser.ParseQuery(map[string]interface{}{
+		"ids": map[string]interface{}{"values": []interface{}{"1", "4", "100"}},
+	})
+	if err != nil {
+		t.Fatalf("ParseQuery() error = %v", err)
+	}
+
+	idsQuery, ok := query.(*IDsQuery)
+	if !ok {
+		t.Fatalf("Expected IDsQuery, got %T", query)
+	}
+	if !reflect.DeepEqual(idsQuery.Values, []string{"1", "4", "100"}) {
+		t.Errorf("Expected values [1 4 100], got %v", idsQuery.Values)
+	}
+
+	invalid := []interface{}{
+		map[string]interface{}{"values": []interface{}{}},
+		map[string]interface{}{"values": "1"},
+		map[string]interface{}{"values": []interface{}{1.0}},
+		map[string]interface{}{},
+	}
+	for _, body := range invalid {
+		if _, err := parser.ParseQuery(map[string]interface{}{"ids": body}); err == nil {
+			t.Errorf("Expected error for ids query %v", body)
+		}
+	}
+}
+
 func TestParseRangeQuery(t *testing.T) {
 	query := `{
 		"query": {
@@ -169,6 +203,39 @@ func TestParseRangeQuery(t *testing.T) {
 	}
 }
 
+func TestParseDateRangeQuery(t *testing.T) {
+	query := `{
+		"query": {
+			"range": {
+				"created": {
+					"gte": "2026-01-01",
+					"lt": "2026-02-01",
+					"format": "yyyy-MM-dd||epoch_millis"
+				}
+			}
+		}
+	}`
+
+	parser := NewQueryParser()
+	req, err := parser.ParseSearchRequest([]byte(query))
+	if err != nil {
+		t.Fatalf("ParseSearchRequest() error = %v", err)
+	}
+
+	rangeQuery, ok := req.ParsedQuery.(*RangeQuery)
+	if !ok {
+		t.Fatalf("Expected RangeQuery, got %T", req.ParsedQuery)
+	}
+
+	if rangeQuery.Gte != "2026-01-01" {
+		t.Errorf("Expected gte=2026-01-01, got %v", rangeQuery.Gte)
+	}
+
+	if rangeQuery.Format != "yyyy-MM-dd||epoch_millis" {
+		t.Errorf("Expected format to be kept, got %q", rangeQuery.Format)
+	}
+}
+
 func TestParseBoolQuery(t *testing.T) {
 	query := `{
 		"query": {
@@ -359,6 +426,117 @@ func TestParseExistsQuery(t *testing.T) {
 	}
 }
 
+func TestParseRegexpQuery(t *testing.T) {
+	parser := NewQueryParser()
+
+	for _, body := range []interface{}{
+		"qu.*k",
+		map[string]interface{}{"value": "qu.*k"},
+	} {
+		query, err := parser.ParseQuery(map[string]interface{}{
+			"regexp": map[string]interface{}{"title": body},
+		})
+		if err != nil {
+			t.Fatalf("ParseQuery() error = %v", err)
+		}
+		regexpQuery, ok := query.(*RegexpQuery)
+		if !ok {
+			t.Fatalf("Expected RegexpQuery, got %T", query)
+		}
+		if regexpQuery.Field != "title" || regexpQuery.Value != "qu.*k" {
+			t.Errorf("Unexpected regexp query %+v", regexpQuery)
+		}
+	}
+
+	rejected := []string{
+		"",
+		".*error",
+		".+error",
+		strings.Repeat("a", MaxRegexpLength+1),
+	}
+	for _, pattern := range rejected {
+		_, err := parser.ParseQuery(map[string]interface{}{
+			"regexp": map[string]interface{}{"title": pattern},
+		})
+		if err == nil {
+			t.Errorf("Expected pattern %.20q to be rejected", pattern)
+		}
+	}
+
+	// Unbounded patterns are accepted once allowed, the length cap still applies
+	parser.SetAllowUnboundedRegexp(true)
+	if _, err := parser.ParseQuery(map[string]interface{}{
+		"regexp": map[string]interface{}{"title": ".*error"},
+	}); err != nil {
+		t.Errorf("Expected unbounded pattern to be allowed, got %v", err)
+	}
+	if _, err := parser.ParseQuery(map[string]interface{}{
+		"regexp": map[string]interface{}{"title": strings.Repeat("a", MaxRegexpLength+1)},
+	}); err == nil {
+		t.Errorf("Expected overlong pattern to be rejected")
+	}
+}
+
+func TestParseGeoDistanceQuery(t *testing.T) {
+	query := `{
+		"query": {
+			"geo_distance": {
+				"distance": "12km",
+				"location": {"lat": 40.71, "lon": -74.0}
+			}
+		}
+	}`
+
+	parser := NewQueryParser()
+	req, err := parser.ParseSearchRequest([]byte(query))
+	if err != nil {
+		t.Fatalf("ParseSearchRequest() error = %v", err)
+	}
+
+	geoQuery, ok := req.ParsedQuery.(*GeoDistanceQuery)
+	if !ok {
+		t.Fatalf("Expected GeoDistanceQuery, got %T", req.ParsedQuery)
+	}
+
+	if geoQuery.Field != "location" {
+		t.Errorf("Expected field 'location', got '%s'", geoQuery.Field)
+	}
+	if geoQuery.Origin.Lat != 40.71 || geoQuery.Origin.Lon != -74.0 {
+		t.Errorf("Expected origin 40.71,-74.0, got %v", geoQuery.Origin)
+	}
+	if geoQuery.Distance != 12000 {
+		t.Errorf("Expected distance 12000m, got %v", geoQuery.Distance)
+	}
+
+	// "lat,lon" origins and render_distance
+	geoQuery2, err := parser.ParseQuery(map[string]interface{}{
+		"geo_distance": map[string]interface{}{
+			"distance":        "500m",
+			"pin":             "51.5,-0.12",
+			"render_distance": true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("ParseQuery() error = %v", err)
+	}
+	if q := geoQuery2.(*GeoDistanceQuery); q.Field != "pin" || q.Origin.Lat != 51.5 || !q.RenderDistance {
+		t.Errorf("Unexpected geo_distance query %+v", q)
+	}
+
+	invalid := []map[string]interface{}{
+		{"location": "40.71,-74.0"},                    // no distance
+		{"distance": "far", "location": "40.71,-74.0"}, // bad distance
+		{"distance": "1km"},                            // no field
+		{"distance": "1km", "location": "somewhere"},   // bad origin
+		{"distance": "1km", "a": "1,1", "b": "2,2"},    // two fields
+	}
+	for _, body := range invalid {
+		if _, err := parser.ParseQuery(map[string]interface{}{"geo_distance": body}); err == nil {
+			t.Errorf("Expected error for geo_distance %v", body)
+		}
+	}
+}
+
 func TestParseMatchAllQuery(t *testing.T) {
 	query := `{
 		"query": {
@@ -580,7 +758,8 @@ func TestParseSearchRequestWithOptions(t *testing.T) {
 			{"date": "desc"}
 		],
 		"_source": ["title", "date"],
-		"timeout": "5s"
+		"timeout": "5s",
+		"terminate_after": 100
 	}`
 
 	parser := NewQueryParser()
@@ -601,11 +780,161 @@ func TestParseSearchRequestWithOptions(t *testing.T) {
 		t.Errorf("Expected timeout='5s', got '%s'", req.Timeout)
 	}
 
+	if req.TerminateAfter != 100 {
+		t.Errorf("Expected terminate_after=100, got %d", req.TerminateAfter)
+	}
+
 	if len(req.Sort) != 1 {
 		t.Errorf("Expected 1 sort clause, got %d", len(req.Sort))
 	}
 }
 
+func TestParseSearchRequestCollapse(t *testing.T) {
+	parser := NewQueryParser()
+	req, err := parser.ParseSearchRequest([]byte(`{
+		"query": {"match_all": {}},
+		"collapse": {"field": "user_id", "inner_hits": {"name": "recent", "size": 2}}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseSearchRequest() error = %v", err)
+	}
+	if req.Collapse == nil || req.Collapse.Field != "user_id" {
+		t.Fatalf("Expected collapse on user_id, got %+v", req.Collapse)
+	}
+	if inner := req.Collapse.InnerHits; inner == nil || inner.Name != "recent" || inner.Size == nil || *inner.Size != 2 {
+		t.Errorf("Expected inner_hits recent/2, got %+v", inner)
+	}
+
+	if _, err := parser.ParseSearchRequest([]byte(`{"collapse": {}}`)); err == nil {
+		t.Error("Expected collapse without a field to be rejected")
+	}
+	if _, err := parser.ParseSearchRequest([]byte(`{"collapse": {"field": "user_id", "inner_hits": {"size": -1}}}`)); err == nil {
+		t.Error("Expected negative inner_hits size to be rejected")
+	}
+}
+
+func TestParseSearchRequestIndicesBoost(t *testing.T) {
+	parser := NewQueryParser()
+	req, err := parser.ParseSearchRequest([]byte(`{"indices_boost": [{"news": 1.5}, {"archive": 0.5}, {"news": 3}]}`))
+	if err != nil {
+		t.Fatalf("ParseSearchRequest() error = %v", err)
+	}
+	// The first entry naming an index applies
+	for index, want := range map[string]float64{"news": 1.5, "archive": 0.5, "blog": 1} {
+		if got := req.IndexBoost(index); got != want {
+			t.Errorf("IndexBoost(%s) = %g, want %g", index, got, want)
+		}
+	}
+
+	if _, err := parser.ParseSearchRequest([]byte(`{"indices_boost": [{"news": -1}]}`)); err == nil {
+		t.Error("Expected negative indices_boost to be rejected")
+	}
+}
+
+func TestParseSearchRequestFromSize(t *testing.T) {
+	parser := NewQueryParser()
+	tests := []struct {
+		body     string
+		wantSize int
+		wantFrom int
+	}{
+		{`{"query": {"match_all": {}}}`, DefaultSize, 0},
+		{`{"size": null}`, DefaultSize, 0},
+		{`{"size": 0, "aggs": {"avg_price": {"avg": {"field": "price"}}}}`, 0, 0},
+		{`{"from": 20}`, DefaultSize, 20},
+		{`{"from": 5, "size": 25}`, 25, 5},
+	}
+	for _, tt := range tests {
+		req, err := parser.ParseSearchRequest([]byte(tt.body))
+		if err != nil {
+			t.Errorf("ParseSearchRequest(%s) error = %v", tt.body, err)
+			continue
+		}
+		if req.Size != tt.wantSize || req.From != tt.wantFrom {
+			t.Errorf("ParseSearchRequest(%s) from/size = %d/%d, want %d/%d", tt.body, req.From, req.Size, tt.wantFrom, tt.wantSize)
+		}
+	}
+
+	if _, err := parser.ParseSearchRequest([]byte(`{"size": -1}`)); err == nil {
+		t.Error("Expected negative size to be rejected")
+	}
+	if _, err := parser.ParseSearchRequest([]byte(`{"from": -10}`)); err == nil {
+		t.Error("Expected negative from to be rejected")
+	}
+}
+
+func TestParseQueryNamed(t *testing.T) {
+	parser := NewQueryParser()
+	query, err := parser.ParseQuery(map[string]interface{}{
+		"bool": map[string]interface{}{
+			"_name": "outer",
+			"should": []interface{}{
+				map[string]interface{}{"term": map[string]interface{}{
+					"status": map[string]interface{}{"value": "active", "_name": "is_active"},
+				}},
+				map[string]interface{}{"match": map[string]interface{}{
+					"title": map[string]interface{}{"query": "laptop", "_name": "mentions_laptop"},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ParseQuery() error = %v", err)
+	}
+
+	boolQuery, ok := query.(*BoolQuery)
+	if !ok {
+		t.Fatalf("Expected *BoolQuery, got %T", query)
+	}
+	if boolQuery.QueryName() != "outer" {
+		t.Errorf("Expected bool query name outer, got %q", boolQuery.QueryName())
+	}
+	if len(boolQuery.Should) != 2 {
+		t.Fatalf("Expected 2 should clauses, got %d", len(boolQuery.Should))
+	}
+	for i, want := range []string{"is_active", "mentions_laptop"} {
+		named, ok := boolQuery.Should[i].(NamedQuery)
+		if !ok || named.QueryName() != want {
+			t.Errorf("Expected should[%d] named %q, got %+v", i, want, boolQuery.Should[i])
+		}
+	}
+}
+
+func TestSearchRequestTimeoutDuration(t *testing.T) {
+	tests := []struct {
+		timeout string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"-1", 0, false},
+		{"500ms", 500 * time.Millisecond, false},
+		{"2s", 2 * time.Second, false},
+		{"0s", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		req := &SearchRequest{Timeout: tt.timeout}
+		got, err := req.TimeoutDuration()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("TimeoutDuration(%q) error = %v, wantErr %v", tt.timeout, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("TimeoutDuration(%q) = %v, want %v", tt.timeout, got, tt.want)
+		}
+	}
+
+	parser := NewQueryParser()
+	if _, err := parser.ParseSearchRequest([]byte(`{"timeout": "soon"}`)); err == nil {
+		t.Error("Expected invalid timeout to be rejected")
+	}
+	if _, err := parser.ParseSearchRequest([]byte(`{"terminate_after": -1}`)); err == nil {
+		t.Error("Expected negative terminate_after to be rejected")
+	}
+}
+
 // Benchmark tests
 func BenchmarkParseSimpleMatch(b *testing.B) {
 	query := `{"query": {"match": {"title": "search"}}}`
@@ -0,0 +1,684 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultQueryStringField is the field query_string terms without a field
+// prefix search when the query names no default_field or fields: a pattern
+// matching every field, resolved to the index's fields by
+// ExpandFieldPatterns
+const DefaultQueryStringField = "*"
+
+// The query_string mini-language is the Lucene classic query syntax:
+//
+//	title:foo AND (status:active OR -status:deleted) price:[100 TO 200}
+//
+// Clauses are joined by AND (&&), OR (||) and NOT (!), or marked required
+// (+) or prohibited (-). AND binds tighter than OR, and clauses without an
+// operator between them are joined by the default_operator. A field prefix
+// scopes a term, phrase, range or parenthesized group; terms without one
+// search the default fields.
+
+// qsTokenKind is the kind of a query string token
+type qsTokenKind int
+
+const (
+	qsEOF qsTokenKind = iota
+	qsTerm
+	qsPhrase
+	qsRange
+	qsField
+	qsAnd
+	qsOr
+	qsNot
+	qsRequired
+	qsProhibited
+	qsLParen
+	qsRParen
+	qsBoost
+	qsFuzzy
+)
+
+// qsToken is a token of a query string
+type qsToken struct {
+	kind qsTokenKind
+	text string
+	pos  int
+
+	// wildcard is set on terms containing an unescaped * or ?; raw then
+	// keeps the term with its escapes, as wildcard patterns expect
+	wildcard bool
+	raw      string
+}
+
+// describe names a token in error messages
+func (t qsToken) describe() string {
+	switch t.kind {
+	case qsEOF:
+		return "end of query"
+	case qsTerm, qsField:
+		return fmt.Sprintf("[%s]", t.text)
+	case qsPhrase:
+		return fmt.Sprintf("[\"%s\"]", t.text)
+	default:
+		return fmt.Sprintf("[%s]", t.raw)
+	}
+}
+
+// isQueryStringTermEnd reports whether r ends a term
+func isQueryStringTermEnd(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(`()[]{}":^~`, r)
+}
+
+// lexQueryString splits a query string into tokens
+func lexQueryString(query string) ([]qsToken, error) {
+	runes := []rune(query)
+	var tokens []qsToken
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		emit := func(kind qsTokenKind, end int) {
+			raw := string(runes[start:end])
+			tokens = append(tokens, qsToken{kind: kind, text: raw, raw: raw, pos: start})
+			i = end
+		}
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			emit(qsLParen, i+1)
+		case r == ')':
+			emit(qsRParen, i+1)
+		case r == '+':
+			emit(qsRequired, i+1)
+		case r == '-':
+			emit(qsProhibited, i+1)
+		case r == '!':
+			emit(qsNot, i+1)
+		case r == '&' && i+1 < len(runes) && runes[i+1] == '&':
+			emit(qsAnd, i+2)
+		case r == '|' && i+1 < len(runes) && runes[i+1] == '|':
+			emit(qsOr, i+2)
+		case r == '^' || r == '~':
+			// A boost or fuzziness modifier, with an optional number
+			end := i + 1
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			kind := qsBoost
+			if r == '~' {
+				kind = qsFuzzy
+			}
+			emit(kind, end)
+			tokens[len(tokens)-1].text = string(runes[start+1 : end])
+		case r == '"':
+			var phrase strings.Builder
+			end := i + 1
+			for ; end < len(runes) && runes[end] != '"'; end++ {
+				if runes[end] == '\\' && end+1 < len(runes) {
+					end++
+				}
+				phrase.WriteRune(runes[end])
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated phrase starting at position %d", start)
+			}
+			emit(qsPhrase, end+1)
+			tokens[len(tokens)-1].text = phrase.String()
+		case r == '[' || r == '{':
+			end := i + 1
+			inPhrase := false
+			for ; end < len(runes); end++ {
+				if runes[end] == '"' {
+					inPhrase = !inPhrase
+				} else if !inPhrase && (runes[end] == ']' || runes[end] == '}') {
+					break
+				}
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated range starting at position %d", start)
+			}
+			emit(qsRange, end+1)
+		case r == ']' || r == '}' || r == ':' || r == '"':
+			return nil, fmt.Errorf("unexpected [%c] at position %d", r, start)
+		default:
+			var term strings.Builder
+			wildcard := false
+			end := i
+			for ; end < len(runes) && !isQueryStringTermEnd(runes[end]); end++ {
+				if runes[end] == '\\' && end+1 < len(runes) {
+					end++
+				} else if runes[end] == '*' || runes[end] == '?' {
+					wildcard = true
+				}
+				term.WriteRune(runes[end])
+			}
+			raw := string(runes[start:end])
+
+			token := qsToken{kind: qsTerm, text: term.String(), raw: raw, pos: start, wildcard: wildcard}
+			switch {
+			case end < len(runes) && runes[end] == ':':
+				token.kind = qsField
+				end++
+			case raw == "AND":
+				token.kind = qsAnd
+			case raw == "OR":
+				token.kind = qsOr
+			case raw == "NOT":
+				token.kind = qsNot
+			}
+			tokens = append(tokens, token)
+			i = end
+		}
+	}
+
+	return append(tokens, qsToken{kind: qsEOF, pos: len(runes)}), nil
+}
+
+// qsOccur is how a clause takes part in the bool query joining it with its
+// siblings
+type qsOccur int
+
+const (
+	qsShould qsOccur = iota
+	qsMust
+	qsMustNot
+)
+
+// qsClause is a parsed clause of a query string
+type qsClause struct {
+	query Query
+	occur qsOccur
+}
+
+// queryStringParser parses a query string into the query AST
+type queryStringParser struct {
+	tokens        []qsToken
+	pos           int
+	defaultFields []string
+	defaultAnd    bool
+
+	// resolve returns the fields a field pattern stands for; patterns are
+	// searched as given when it is nil. expanded is set once one has been.
+	resolve  func(pattern string) ([]string, error)
+	expanded bool
+}
+
+// parseQueryString parses the query string of a query_string query into
+// the query AST: terms become match queries, phrases match_phrase queries,
+// ranges and comparisons range queries, and operators bool queries
+func parseQueryString(q *QueryStringQuery) (Query, error) {
+	query, _, err := parseQueryStringFields(q, nil)
+	return query, err
+}
+
+// parseQueryStringFields parses a query string like parseQueryString,
+// searching the fields resolve returns for a field pattern in place of the
+// pattern. It reports whether any pattern was resolved.
+func parseQueryStringFields(q *QueryStringQuery, resolve func(pattern string) ([]string, error)) (Query, bool, error) {
+	tokens, err := lexQueryString(q.Query)
+	if err != nil {
+		return nil, false, err
+	}
+
+	p := &queryStringParser{
+		tokens:        tokens,
+		defaultFields: q.defaultFields(),
+		defaultAnd:    strings.EqualFold(q.DefaultOperator, "and"),
+		resolve:       resolve,
+	}
+
+	if p.peek().kind == qsEOF {
+		return nil, false, fmt.Errorf("query string is empty")
+	}
+	query, err := p.parseOr(p.defaultFields)
+	if err != nil {
+		return nil, false, err
+	}
+	if token := p.peek(); token.kind != qsEOF {
+		return nil, false, fmt.Errorf("unexpected %s at position %d", token.describe(), token.pos)
+	}
+	return query, p.expanded, nil
+}
+
+// defaultFields returns the fields terms without a field prefix search
+func (q *QueryStringQuery) defaultFields() []string {
+	if len(q.Fields) > 0 {
+		return q.Fields
+	}
+	if q.DefaultField != "" {
+		return []string{q.DefaultField}
+	}
+	return []string{DefaultQueryStringField}
+}
+
+// ExpandFieldPatterns returns query with the field patterns of its
+// query_string and simple_query_string queries resolved against an index: each pattern, such as
+// the default * or title.*, is replaced by the fields resolve returns for
+// it, as the index's shards only search fields by name. query itself is not
+// modified; it is returned as is when it has no patterns.
+func ExpandFieldPatterns(query Query, resolve func(pattern string) ([]string, error)) (Query, error) {
+	switch q := query.(type) {
+	case *QueryStringQuery:
+		parsed, expanded, err := parseQueryStringFields(q, resolve)
+		if err != nil || !expanded {
+			return query, err
+		}
+		resolved := *q
+		resolved.Parsed = parsed
+		return &resolved, nil
+
+	case *SimpleQueryStringQuery:
+		parsed, expanded, err := parseSimpleQueryStringFields(q, resolve)
+		if err != nil || !expanded {
+			return query, err
+		}
+		resolved := *q
+		resolved.Parsed = parsed
+		return &resolved, nil
+
+	case *BoolQuery:
+		resolved := *q
+		changed := false
+		for _, clauses := range []*[]Query{&resolved.Must, &resolved.Should, &resolved.MustNot, &resolved.Filter} {
+			expanded := make([]Query, len(*clauses))
+			for i, clause := range *clauses {
+				var err error
+				if expanded[i], err = ExpandFieldPatterns(clause, resolve); err != nil {
+					return nil, err
+				}
+				changed = changed || expanded[i] != clause
+			}
+			*clauses = expanded
+		}
+		if !changed {
+			return query, nil
+		}
+		return &resolved, nil
+	}
+	return query, nil
+}
+
+// expandFields replaces the field patterns among fields by the fields they
+// resolve to. A pattern resolving to no field is an error, as the query
+// would silently match nothing.
+func (p *queryStringParser) expandFields(fields []string) ([]string, error) {
+	if p.resolve == nil {
+		return fields, nil
+	}
+
+	var expanded []string
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		matched := []string{field}
+		if strings.ContainsAny(field, "*?") {
+			var err error
+			if matched, err = p.resolve(field); err != nil {
+				return nil, err
+			}
+			if len(matched) == 0 {
+				return nil, fmt.Errorf("field pattern [%s] matches no fields", field)
+			}
+			p.expanded = true
+		}
+		for _, name := range matched {
+			if !seen[name] {
+				seen[name] = true
+				expanded = append(expanded, name)
+			}
+		}
+	}
+	return expanded, nil
+}
+
+func (p *queryStringParser) peek() qsToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryStringParser) next() qsToken {
+	token := p.tokens[p.pos]
+	if token.kind != qsEOF {
+		p.pos++
+	}
+	return token
+}
+
+// startsClause reports whether a token can start a clause
+func (t qsToken) startsClause() bool {
+	switch t.kind {
+	case qsTerm, qsPhrase, qsRange, qsField, qsNot, qsRequired, qsProhibited, qsLParen:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseOr parses clauses joined by OR, or by nothing when the default
+// operator is OR. Required and prohibited clauses become must and must_not
+// clauses of the bool query joining them, the others should clauses.
+func (p *queryStringParser) parseOr(fields []string) (Query, error) {
+	var clauses []qsClause
+	for {
+		clause, err := p.parseAnd(fields)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+
+		if p.peek().kind == qsOr {
+			p.next()
+			continue
+		}
+		if !p.defaultAnd && p.peek().startsClause() {
+			continue
+		}
+		break
+	}
+
+	if len(clauses) == 1 && clauses[0].occur != qsMustNot {
+		return clauses[0].query, nil
+	}
+	query := &BoolQuery{}
+	for _, clause := range clauses {
+		switch clause.occur {
+		case qsMust:
+			query.Must = append(query.Must, clause.query)
+		case qsMustNot:
+			query.MustNot = append(query.MustNot, clause.query)
+		default:
+			query.Should = append(query.Should, clause.query)
+		}
+	}
+	return query, nil
+}
+
+// parseAnd parses clauses joined by AND, or by nothing when the default
+// operator is AND
+func (p *queryStringParser) parseAnd(fields []string) (qsClause, error) {
+	var clauses []qsClause
+	for {
+		clause, err := p.parseUnary(fields)
+		if err != nil {
+			return qsClause{}, err
+		}
+		clauses = append(clauses, clause)
+
+		if p.peek().kind == qsAnd {
+			p.next()
+			continue
+		}
+		if p.defaultAnd && p.peek().startsClause() {
+			continue
+		}
+		break
+	}
+
+	if len(clauses) == 1 {
+		return clauses[0], nil
+	}
+	query := &BoolQuery{}
+	for _, clause := range clauses {
+		if clause.occur == qsMustNot {
+			query.MustNot = append(query.MustNot, clause.query)
+		} else {
+			query.Must = append(query.Must, clause.query)
+		}
+	}
+	return qsClause{query: query}, nil
+}
+
+// parseUnary parses a clause with its NOT, + or - prefix
+func (p *queryStringParser) parseUnary(fields []string) (qsClause, error) {
+	switch p.peek().kind {
+	case qsNot, qsProhibited:
+		p.next()
+		clause, err := p.parseUnary(fields)
+		clause.occur = qsMustNot
+		return clause, err
+	case qsRequired:
+		p.next()
+		clause, err := p.parseUnary(fields)
+		clause.occur = qsMust
+		return clause, err
+	}
+
+	query, err := p.parsePrimary(fields)
+	return qsClause{query: query}, err
+}
+
+// parsePrimary parses a parenthesized group, a field-scoped clause or a
+// value searching the given fields
+func (p *queryStringParser) parsePrimary(fields []string) (Query, error) {
+	token := p.peek()
+	switch token.kind {
+	case qsLParen:
+		p.next()
+		return p.parseGroup(fields, token)
+
+	case qsField:
+		p.next()
+		if token.text == "_exists_" {
+			value := p.next()
+			if value.kind != qsTerm {
+				return nil, fmt.Errorf("_exists_ at position %d must name a field", token.pos)
+			}
+			return &ExistsQuery{Field: value.text}, nil
+		}
+		if open := p.peek(); open.kind == qsLParen {
+			p.next()
+			return p.parseGroup([]string{token.text}, open)
+		}
+		if kind := p.peek().kind; kind != qsTerm && kind != qsPhrase && kind != qsRange {
+			return nil, fmt.Errorf("field [%s] at position %d has no value", token.text, token.pos)
+		}
+		return p.parseValue([]string{token.text})
+
+	case qsTerm, qsPhrase, qsRange:
+		return p.parseValue(fields)
+
+	default:
+		return nil, fmt.Errorf("unexpected %s at position %d", token.describe(), token.pos)
+	}
+}
+
+// parseGroup parses the clauses of a parenthesized group up to its closing
+// parenthesis
+func (p *queryStringParser) parseGroup(fields []string, open qsToken) (Query, error) {
+	query, err := p.parseOr(fields)
+	if err != nil {
+		return nil, err
+	}
+	if p.next().kind != qsRParen {
+		return nil, fmt.Errorf("missing closing parenthesis for the group at position %d", open.pos)
+	}
+	return p.parseBoost(query)
+}
+
+// parseValue parses a term, phrase or range with its modifiers into a query
+// on each of the fields
+func (p *queryStringParser) parseValue(fields []string) (Query, error) {
+	token := p.next()
+
+	var build func(field string) Query
+	switch token.kind {
+	case qsPhrase:
+		slop := 0
+		if p.peek().kind == qsFuzzy {
+			slop, _ = strconv.Atoi(p.next().text)
+		}
+		build = func(field string) Query {
+			return &MatchPhraseQuery{Field: field, Query: token.text, Slop: slop}
+		}
+
+	case qsRange:
+		lower, upper, includeLower, includeUpper, err := parseQueryStringRange(token.raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %s at position %d: %w", token.describe(), token.pos, err)
+		}
+		build = func(field string) Query {
+			if lower == nil && upper == nil {
+				return &ExistsQuery{Field: field}
+			}
+			query := &RangeQuery{Field: field}
+			if includeLower {
+				query.Gte = lower
+			} else {
+				query.Gt = lower
+			}
+			if includeUpper {
+				query.Lte = upper
+			} else {
+				query.Lt = upper
+			}
+			return query
+		}
+
+	default:
+		build = p.termQuery(token)
+	}
+
+	if len(fields) == 1 && fields[0] == DefaultQueryStringField && token.kind == qsTerm && token.text == "*" {
+		// A lone * matches every document
+		return p.parseBoost(&MatchAllQuery{})
+	}
+
+	fields, err := p.expandFields(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var query Query
+	if len(fields) == 1 {
+		query = build(fields[0])
+	} else {
+		perField := &BoolQuery{}
+		for _, field := range fields {
+			perField.Should = append(perField.Should, build(field))
+		}
+		query = perField
+	}
+	return p.parseBoost(query)
+}
+
+// termQuery returns the builder of the query for a term: a comparison
+// (>, >=, <, <=) is a range, * an exists query, a term with wildcards a
+// prefix or wildcard query, a term followed by ~ a fuzzy query, and any
+// other term a match query
+func (p *queryStringParser) termQuery(token qsToken) func(field string) Query {
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if bound, ok := strings.CutPrefix(token.text, op); ok && bound != "" {
+			value := queryStringValue(bound)
+			return func(field string) Query {
+				query := &RangeQuery{Field: field}
+				switch op {
+				case ">=":
+					query.Gte = value
+				case "<=":
+					query.Lte = value
+				case ">":
+					query.Gt = value
+				case "<":
+					query.Lt = value
+				}
+				return query
+			}
+		}
+	}
+
+	if p.peek().kind == qsFuzzy {
+		fuzziness := p.next().text
+		if fuzziness == "" {
+			fuzziness = "AUTO"
+		}
+		return func(field string) Query {
+			return &FuzzyQuery{Field: field, Value: token.text, Fuzziness: fuzziness}
+		}
+	}
+
+	if token.wildcard {
+		prefix, isPrefix := strings.CutSuffix(token.raw, "*")
+		isPrefix = isPrefix && !strings.ContainsAny(prefix, `*?\`)
+		return func(field string) Query {
+			switch {
+			case token.text == "*":
+				return &ExistsQuery{Field: field}
+			case isPrefix:
+				return &PrefixQuery{Field: field, Value: prefix}
+			default:
+				return &WildcardQuery{Field: field, Value: token.raw}
+			}
+		}
+	}
+
+	return func(field string) Query {
+		return &MatchQuery{Field: field, Query: token.text}
+	}
+}
+
+// parseBoost applies a ^boost following a clause to its query
+func (p *queryStringParser) parseBoost(query Query) (Query, error) {
+	if p.peek().kind != qsBoost {
+		return query, nil
+	}
+	token := p.next()
+	boost, err := strconv.ParseFloat(token.text, 64)
+	if err != nil || boost < 0 {
+		return nil, fmt.Errorf("invalid boost %s at position %d", token.describe(), token.pos)
+	}
+	applyBoost(query, boost)
+	return query, nil
+}
+
+// applyBoost sets the boost of the queries that score with one; it has no
+// effect on the others
+func applyBoost(query Query, boost float64) {
+	switch q := query.(type) {
+	case *MatchQuery:
+		q.Boost = boost
+	case *TermQuery:
+		q.Boost = boost
+	case *RangeQuery:
+		q.Boost = boost
+	case *MatchAllQuery:
+		q.Boost = boost
+	}
+}
+
+// parseQueryStringRange parses a range such as [100 TO 200} into its
+// bounds; a * bound is unbounded and parses as nil
+func parseQueryStringRange(raw string) (lower, upper interface{}, includeLower, includeUpper bool, err error) {
+	includeLower = raw[0] == '['
+	includeUpper = raw[len(raw)-1] == ']'
+
+	parts := strings.Fields(raw[1 : len(raw)-1])
+	if len(parts) != 3 || parts[1] != "TO" {
+		return nil, nil, false, false, fmt.Errorf("expected [from TO to]")
+	}
+
+	bound := func(text string) interface{} {
+		if text == "*" {
+			return nil
+		}
+		if unquoted, err := strconv.Unquote(text); err == nil {
+			return unquoted
+		}
+		return queryStringValue(text)
+	}
+	return bound(parts[0]), bound(parts[2]), includeLower, includeUpper, nil
+}
+
+// queryStringValue returns a range bound as a number when it is one, as
+// range queries parsed from JSON have it, and as a string (such as a date)
+// otherwise
+func queryStringValue(text string) interface{} {
+	if number, err := strconv.ParseFloat(text, 64); err == nil {
+		return number
+	}
+	return text
+}
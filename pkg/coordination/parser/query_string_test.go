@@ -0,0 +1,406 @@
+package parser
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// parseQueryStringBody parses {"query_string": body} and returns the query
+// its query string parsed into
+func parseQueryStringBody(t *testing.T, body map[string]interface{}) (Query, error) {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]interface{}{
+		"query": map[string]interface{}{"query_string": body},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := NewQueryParser().ParseSearchRequest(data)
+	if err != nil {
+		return nil, err
+	}
+	queryString, ok := req.ParsedQuery.(*QueryStringQuery)
+	if !ok {
+		t.Fatalf("expected *QueryStringQuery, got %T", req.ParsedQuery)
+	}
+	return queryString.Parsed, nil
+}
+
+func TestParseQueryStringBooleanOperators(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		operator string
+		want     Query
+	}{
+		{
+			name:  "field term",
+			query: "title:foo",
+			want:  &MatchQuery{Field: "title", Query: "foo"},
+		},
+		{
+			name:  "AND",
+			query: "title:foo AND status:active",
+			want: &BoolQuery{Must: []Query{
+				&MatchQuery{Field: "title", Query: "foo"},
+				&MatchQuery{Field: "status", Query: "active"},
+			}},
+		},
+		{
+			name:  "OR",
+			query: "title:foo || title:bar",
+			want: &BoolQuery{Should: []Query{
+				&MatchQuery{Field: "title", Query: "foo"},
+				&MatchQuery{Field: "title", Query: "bar"},
+			}},
+		},
+		{
+			name:  "AND binds tighter than OR",
+			query: "a:1 OR b:2 AND c:3",
+			want: &BoolQuery{Should: []Query{
+				&MatchQuery{Field: "a", Query: "1"},
+				&BoolQuery{Must: []Query{
+					&MatchQuery{Field: "b", Query: "2"},
+					&MatchQuery{Field: "c", Query: "3"},
+				}},
+			}},
+		},
+		{
+			name:  "NOT",
+			query: "title:foo AND NOT status:deleted",
+			want: &BoolQuery{
+				Must:    []Query{&MatchQuery{Field: "title", Query: "foo"}},
+				MustNot: []Query{&MatchQuery{Field: "status", Query: "deleted"}},
+			},
+		},
+		{
+			name:  "lone NOT",
+			query: "!status:deleted",
+			want:  &BoolQuery{MustNot: []Query{&MatchQuery{Field: "status", Query: "deleted"}}},
+		},
+		{
+			name:  "required and prohibited",
+			query: "+title:foo -status:deleted tag:new",
+			want: &BoolQuery{
+				Must:    []Query{&MatchQuery{Field: "title", Query: "foo"}},
+				MustNot: []Query{&MatchQuery{Field: "status", Query: "deleted"}},
+				Should:  []Query{&MatchQuery{Field: "tag", Query: "new"}},
+			},
+		},
+		{
+			name:  "groups",
+			query: "(a:1 OR a:2) AND b:3",
+			want: &BoolQuery{Must: []Query{
+				&BoolQuery{Should: []Query{
+					&MatchQuery{Field: "a", Query: "1"},
+					&MatchQuery{Field: "a", Query: "2"},
+				}},
+				&MatchQuery{Field: "b", Query: "3"},
+			}},
+		},
+		{
+			name:  "field-scoped group",
+			query: "title:(foo bar)",
+			want: &BoolQuery{Should: []Query{
+				&MatchQuery{Field: "title", Query: "foo"},
+				&MatchQuery{Field: "title", Query: "bar"},
+			}},
+		},
+		{
+			name:     "default operator AND",
+			query:    "title:foo status:active OR status:new",
+			operator: "and",
+			want: &BoolQuery{Should: []Query{
+				&BoolQuery{Must: []Query{
+					&MatchQuery{Field: "title", Query: "foo"},
+					&MatchQuery{Field: "status", Query: "active"},
+				}},
+				&MatchQuery{Field: "status", Query: "new"},
+			}},
+		},
+		{
+			name:  "phrase with slop and boost",
+			query: `title:"quick fox"~2 body:fox^3`,
+			want: &BoolQuery{Should: []Query{
+				&MatchPhraseQuery{Field: "title", Query: "quick fox", Slop: 2},
+				&MatchQuery{Field: "body", Query: "fox", Boost: 3},
+			}},
+		},
+		{
+			name:  "wildcards, fuzzy and exists",
+			query: "name:jo* name:j?hn name:jhon~ _exists_:email tag:*",
+			want: &BoolQuery{Should: []Query{
+				&PrefixQuery{Field: "name", Value: "jo"},
+				&WildcardQuery{Field: "name", Value: "j?hn"},
+				&FuzzyQuery{Field: "name", Value: "jhon", Fuzziness: "AUTO"},
+				&ExistsQuery{Field: "email"},
+				&ExistsQuery{Field: "tag"},
+			}},
+		},
+		{
+			name:  "escaped characters",
+			query: `path:a\:b\*`,
+			want:  &MatchQuery{Field: "path", Query: "a:b*"},
+		},
+		{
+			name:  "match all",
+			query: "*:*",
+			want:  &MatchAllQuery{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := map[string]interface{}{"query": tt.query}
+			if tt.operator != "" {
+				body["default_operator"] = tt.operator
+			}
+			got, err := parseQueryStringBody(t, body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(tt.want)
+				t.Errorf("parsed %s\nwant   %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestParseQueryStringRanges(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  Query
+	}{
+		{
+			name:  "inclusive range",
+			query: "price:[100 TO 200]",
+			want:  &RangeQuery{Field: "price", Gte: 100.0, Lte: 200.0},
+		},
+		{
+			name:  "exclusive and mixed bounds",
+			query: "price:{100 TO 200]",
+			want:  &RangeQuery{Field: "price", Gt: 100.0, Lte: 200.0},
+		},
+		{
+			name:  "open-ended date range",
+			query: "date:[2024-01-01 TO *}",
+			want:  &RangeQuery{Field: "date", Gte: "2024-01-01"},
+		},
+		{
+			name:  "quoted bounds",
+			query: `date:{"2024-01-01" TO "2024-02-01"}`,
+			want:  &RangeQuery{Field: "date", Gt: "2024-01-01", Lt: "2024-02-01"},
+		},
+		{
+			name:  "unbounded range is exists",
+			query: "price:[* TO *]",
+			want:  &ExistsQuery{Field: "price"},
+		},
+		{
+			name:  "comparisons",
+			query: "price:>=10 AND age:<65",
+			want: &BoolQuery{Must: []Query{
+				&RangeQuery{Field: "price", Gte: 10.0},
+				&RangeQuery{Field: "age", Lt: 65.0},
+			}},
+		},
+		{
+			name:  "range combined with terms",
+			query: "title:foo AND price:[100 TO 200]",
+			want: &BoolQuery{Must: []Query{
+				&MatchQuery{Field: "title", Query: "foo"},
+				&RangeQuery{Field: "price", Gte: 100.0, Lte: 200.0},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseQueryStringBody(t, map[string]interface{}{"query": tt.query})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(tt.want)
+				t.Errorf("parsed %s\nwant   %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestParseQueryStringDefaultFields(t *testing.T) {
+	// Without default_field or fields, terms search every field
+	got, err := parseQueryStringBody(t, map[string]interface{}{"query": "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&MatchQuery{Field: DefaultQueryStringField, Query: "foo"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+
+	got, err = parseQueryStringBody(t, map[string]interface{}{"query": "foo", "default_field": "body"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&MatchQuery{Field: "body", Query: "foo"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+
+	// Several fields are searched with a should clause per field, and a
+	// field prefix overrides them
+	got, err = parseQueryStringBody(t, map[string]interface{}{
+		"query":  "foo AND status:active",
+		"fields": []interface{}{"title", "body"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &BoolQuery{Must: []Query{
+		&BoolQuery{Should: []Query{
+			&MatchQuery{Field: "title", Query: "foo"},
+			&MatchQuery{Field: "body", Query: "foo"},
+		}},
+		&MatchQuery{Field: "status", Query: "active"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+
+	// A lone * matches every document
+	got, err = parseQueryStringBody(t, map[string]interface{}{"query": "*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got.(*MatchAllQuery); !ok {
+		t.Errorf("expected *MatchAllQuery, got %T", got)
+	}
+}
+
+func TestExpandFieldPatterns(t *testing.T) {
+	fields := []string{"author.name", "body", "title"}
+	resolve := func(pattern string) ([]string, error) {
+		var matched []string
+		for _, field := range fields {
+			if ok, _ := path.Match(pattern, field); ok {
+				matched = append(matched, field)
+			}
+		}
+		return matched, nil
+	}
+	parse := func(body map[string]interface{}) Query {
+		t.Helper()
+		data, err := json.Marshal(map[string]interface{}{"query": body})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := NewQueryParser().ParseSearchRequest(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req.ParsedQuery
+	}
+
+	// The default * searches every resolved field
+	query := parse(map[string]interface{}{"query_string": map[string]interface{}{"query": "foo status:active"}})
+	expanded, err := ExpandFieldPatterns(query, resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &BoolQuery{Should: []Query{
+		&BoolQuery{Should: []Query{
+			&MatchQuery{Field: "author.name", Query: "foo"},
+			&MatchQuery{Field: "body", Query: "foo"},
+			&MatchQuery{Field: "title", Query: "foo"},
+		}},
+		&MatchQuery{Field: "status", Query: "active"},
+	}}
+	if got := expanded.(*QueryStringQuery).Parsed; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+	if query.(*QueryStringQuery).Parsed.(*BoolQuery).Should[0].(*MatchQuery).Field != DefaultQueryStringField {
+		t.Errorf("the original query was modified")
+	}
+
+	// Patterns in fields and field prefixes resolve too, inside bool queries
+	query = parse(map[string]interface{}{"bool": map[string]interface{}{
+		"must": []interface{}{map[string]interface{}{"query_string": map[string]interface{}{
+			"query":  "foo AND t*:bar",
+			"fields": []interface{}{"author.*", "body"},
+		}}},
+	}})
+	expanded, err = ExpandFieldPatterns(query, resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = &BoolQuery{Must: []Query{
+		&BoolQuery{Should: []Query{
+			&MatchQuery{Field: "author.name", Query: "foo"},
+			&MatchQuery{Field: "body", Query: "foo"},
+		}},
+		&MatchQuery{Field: "title", Query: "bar"},
+	}}
+	if got := expanded.(*BoolQuery).Must[0].(*QueryStringQuery).Parsed; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+
+	// Queries without patterns are returned as they are, and a lone *
+	// still matches every document
+	for _, body := range []map[string]interface{}{
+		{"query_string": map[string]interface{}{"query": "title:foo"}},
+		{"query_string": map[string]interface{}{"query": "*"}},
+		{"match": map[string]interface{}{"title": "foo"}},
+	} {
+		query := parse(body)
+		if expanded, err := ExpandFieldPatterns(query, resolve); err != nil || expanded != query {
+			t.Errorf("ExpandFieldPatterns(%v) = %v, %v, want the query unchanged", body, expanded, err)
+		}
+	}
+
+	// A pattern matching no field is an error
+	query = parse(map[string]interface{}{"query_string": map[string]interface{}{"query": "foo", "default_field": "price*"}})
+	if _, err := ExpandFieldPatterns(query, resolve); err == nil || !strings.Contains(err.Error(), "[price*] matches no fields") {
+		t.Errorf("expected a no fields error, got %v", err)
+	}
+}
+
+func TestParseQueryStringErrors(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr string
+	}{
+		{"", "query string is empty"},
+		{"(title:foo", "missing closing parenthesis"},
+		{"title:foo)", "unexpected [)]"},
+		{`title:"foo`, "unterminated phrase"},
+		{"price:[100 TO 200", "unterminated range"},
+		{"price:[100 200]", "expected [from TO to]"},
+		{"title:", "field [title] at position 0 has no value"},
+		{"title:foo AND", "unexpected end of query"},
+		{"title:foo^x", "invalid boost"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := parseQueryStringBody(t, map[string]interface{}{"query": tt.query})
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %q does not contain %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	_, err := parseQueryStringBody(t, map[string]interface{}{"query": "foo", "default_operator": "xor"})
+	if err == nil || !strings.Contains(err.Error(), "default_operator") {
+		t.Errorf("expected a default_operator error, got %v", err)
+	}
+}
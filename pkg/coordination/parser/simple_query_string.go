@@ -0,0 +1,371 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The simple_query_string syntax is a forgiving subset of query_string for
+// search boxes:
+//
+//	"quick fox"~2 + (brown | red*) -slow~1
+//
+// + joins clauses with AND, | with OR and - negates the clause it
+// precedes; clauses without an operator between them are joined by the
+// default_operator, and + binds tighter than |. Operators are only
+// recognized at the start of a term, so c++ is a term. A trailing * makes
+// a term a prefix, ~N after a term its fuzziness and after a phrase its
+// slop. Input that doesn't parse is ignored rather than rejected: a quote
+// without its closing quote is dropped, unmatched parentheses are dropped
+// or closed at the end, and operators without an operand are skipped.
+
+// sqsToken is a token of a simple query string
+type sqsToken struct {
+	kind qsTokenKind
+	text string
+
+	// prefix is set on terms ending with an unescaped *, which text omits
+	prefix bool
+
+	// fuzziness is the fuzziness (~N) of a term, or the slop of a phrase
+	fuzziness string
+}
+
+// lexSimpleQueryString splits a simple query string into tokens
+func lexSimpleQueryString(query string) []sqsToken {
+	runes := []rune(query)
+	var tokens []sqsToken
+
+	// readFuzziness reads a ~N suffix at i, returning N ("" for a bare ~)
+	// and where the suffix ends
+	readFuzziness := func(i int) (string, int, bool) {
+		if i >= len(runes) || runes[i] != '~' {
+			return "", i, false
+		}
+		end := i + 1
+		for end < len(runes) && unicode.IsDigit(runes[end]) {
+			end++
+		}
+		return string(runes[i+1 : end]), end, true
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, sqsToken{kind: qsLParen})
+			i++
+		case r == ')':
+			tokens = append(tokens, sqsToken{kind: qsRParen})
+			i++
+		case r == '+':
+			tokens = append(tokens, sqsToken{kind: qsAnd})
+			i++
+		case r == '|':
+			tokens = append(tokens, sqsToken{kind: qsOr})
+			i++
+		case r == '-':
+			tokens = append(tokens, sqsToken{kind: qsNot})
+			i++
+		case r == '"':
+			var phrase strings.Builder
+			end := i + 1
+			for ; end < len(runes) && runes[end] != '"'; end++ {
+				if runes[end] == '\\' && end+1 < len(runes) {
+					end++
+				}
+				phrase.WriteRune(runes[end])
+			}
+			if end == len(runes) {
+				// No closing quote: drop the quote and read the rest as terms
+				i++
+				continue
+			}
+			token := sqsToken{kind: qsPhrase, text: phrase.String()}
+			token.fuzziness, i, _ = readFuzziness(end + 1)
+			tokens = append(tokens, token)
+		case r == '~':
+			// Fuzziness without a term
+			_, i, _ = readFuzziness(i)
+		default:
+			var term strings.Builder
+			prefix := false
+			end := i
+			for ; end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`()"~`, runes[end]); end++ {
+				prefix = false
+				if runes[end] == '\\' && end+1 < len(runes) {
+					end++
+				} else if runes[end] == '*' {
+					prefix = true
+					continue
+				}
+				term.WriteRune(runes[end])
+			}
+
+			token := sqsToken{kind: qsTerm, text: term.String(), prefix: prefix}
+			if fuzziness, next, ok := readFuzziness(end); ok {
+				token.fuzziness = fuzziness
+				if fuzziness == "" {
+					token.fuzziness = "AUTO"
+				}
+				end = next
+			}
+			tokens = append(tokens, token)
+			i = end
+		}
+	}
+
+	return append(tokens, sqsToken{kind: qsEOF})
+}
+
+// simpleQueryStringParser parses a simple query string into the query AST.
+// Its methods return a nil query for input with nothing to search.
+type simpleQueryStringParser struct {
+	tokens     []sqsToken
+	pos        int
+	depth      int
+	fields     []simpleQueryStringField
+	defaultAnd bool
+}
+
+// simpleQueryStringField is a field searched by a simple_query_string
+// query, with the boost given as field^boost
+type simpleQueryStringField struct {
+	name  string
+	boost float64
+}
+
+// parseSimpleQueryString parses the query string of a simple_query_string
+// query into the query AST. It never fails: a query string with nothing to
+// search matches no documents.
+func parseSimpleQueryString(q *SimpleQueryStringQuery) Query {
+	query, _, _ := parseSimpleQueryStringFields(q, nil)
+	return query
+}
+
+// parseSimpleQueryStringFields parses a simple query string like
+// parseSimpleQueryString, searching the fields resolve returns for a field
+// pattern, with the pattern's boost, in place of the pattern. It reports
+// whether any pattern was resolved; only resolving can fail.
+func parseSimpleQueryStringFields(q *SimpleQueryStringQuery, resolve func(pattern string) ([]string, error)) (Query, bool, error) {
+	p := &simpleQueryStringParser{
+		tokens:     lexSimpleQueryString(q.Query),
+		defaultAnd: strings.EqualFold(q.DefaultOperator, "and"),
+	}
+	for _, field := range q.Fields {
+		name, boostText, hasBoost := strings.Cut(field, "^")
+		boost, err := strconv.ParseFloat(boostText, 64)
+		if !hasBoost || err != nil || boost < 0 {
+			boost = 0
+		}
+		p.fields = append(p.fields, simpleQueryStringField{name: name, boost: boost})
+	}
+	if len(p.fields) == 0 {
+		p.fields = []simpleQueryStringField{{name: DefaultQueryStringField}}
+	}
+
+	expanded := false
+	if resolve != nil {
+		var fields []simpleQueryStringField
+		seen := make(map[string]bool)
+		for _, field := range p.fields {
+			names := []string{field.name}
+			if strings.ContainsAny(field.name, "*?") {
+				var err error
+				if names, err = resolve(field.name); err != nil {
+					return nil, false, err
+				}
+				if len(names) == 0 {
+					return nil, false, fmt.Errorf("field pattern [%s] matches no fields", field.name)
+				}
+				expanded = true
+			}
+			for _, name := range names {
+				if !seen[name] {
+					seen[name] = true
+					fields = append(fields, simpleQueryStringField{name: name, boost: field.boost})
+				}
+			}
+		}
+		p.fields = fields
+	}
+
+	if query := p.parseOr(); query != nil {
+		return query, expanded, nil
+	}
+	return &BoolQuery{MustNot: []Query{&MatchAllQuery{}}}, expanded, nil
+}
+
+func (p *simpleQueryStringParser) peek() sqsToken {
+	return p.tokens[p.pos]
+}
+
+func (p *simpleQueryStringParser) next() sqsToken {
+	token := p.tokens[p.pos]
+	if token.kind != qsEOF {
+		p.pos++
+	}
+	return token
+}
+
+// startsClause reports whether a token can start a clause
+func (t sqsToken) startsClause() bool {
+	switch t.kind {
+	case qsTerm, qsPhrase, qsNot, qsLParen:
+		return true
+	default:
+		return false
+	}
+}
+
+// joinClauses joins clauses into a bool query, negated clauses as must_not
+// clauses and the others in occur. A single clause that isn't negated is
+// returned as it is.
+func joinClauses(clauses []qsClause, occur qsOccur) Query {
+	if len(clauses) == 0 {
+		return nil
+	}
+	if len(clauses) == 1 && clauses[0].occur != qsMustNot {
+		return clauses[0].query
+	}
+
+	query := &BoolQuery{}
+	for _, clause := range clauses {
+		switch {
+		case clause.occur == qsMustNot:
+			query.MustNot = append(query.MustNot, clause.query)
+		case occur == qsMust:
+			query.Must = append(query.Must, clause.query)
+		default:
+			query.Should = append(query.Should, clause.query)
+		}
+	}
+	return query
+}
+
+// parseOr parses clauses joined by |, or by nothing when the default
+// operator is OR. Outside any group, a closing parenthesis is skipped.
+func (p *simpleQueryStringParser) parseOr() Query {
+	var clauses []qsClause
+	for {
+		if clause := p.parseAnd(); clause.query != nil {
+			clauses = append(clauses, clause)
+		}
+
+		switch token := p.peek(); {
+		case token.kind == qsOr:
+			p.next()
+		case token.kind == qsRParen && p.depth == 0:
+			p.next()
+		case !p.defaultAnd && token.startsClause():
+		default:
+			return joinClauses(clauses, qsShould)
+		}
+	}
+}
+
+// parseAnd parses clauses joined by +, or by nothing when the default
+// operator is AND
+func (p *simpleQueryStringParser) parseAnd() qsClause {
+	var clauses []qsClause
+	for {
+		if clause := p.parseUnary(); clause.query != nil {
+			clauses = append(clauses, clause)
+		}
+
+		if p.peek().kind == qsAnd {
+			p.next()
+			continue
+		}
+		if p.defaultAnd && p.peek().startsClause() {
+			continue
+		}
+		break
+	}
+
+	if len(clauses) == 1 {
+		return clauses[0]
+	}
+	return qsClause{query: joinClauses(clauses, qsMust)}
+}
+
+// parseUnary parses a clause with its - prefix
+func (p *simpleQueryStringParser) parseUnary() qsClause {
+	if p.peek().kind == qsNot {
+		p.next()
+		clause := p.parseUnary()
+		clause.occur = qsMustNot
+		return clause
+	}
+	return qsClause{query: p.parsePrimary()}
+}
+
+// parsePrimary parses a group, term or phrase. An operator where a clause
+// should start is skipped.
+func (p *simpleQueryStringParser) parsePrimary() Query {
+	token := p.peek()
+	switch token.kind {
+	case qsLParen:
+		p.next()
+		p.depth++
+		query := p.parseOr()
+		p.depth--
+		// A group left open is closed at the end of the query
+		if p.peek().kind == qsRParen {
+			p.next()
+		}
+		return query
+
+	case qsTerm, qsPhrase:
+		p.next()
+		return p.valueQuery(token)
+
+	case qsAnd, qsOr:
+		p.next()
+		return nil
+
+	default:
+		return nil
+	}
+}
+
+// valueQuery returns the query for a term or phrase on each of the fields
+func (p *simpleQueryStringParser) valueQuery(token sqsToken) Query {
+	if token.kind == qsTerm && token.text == "" {
+		if token.prefix {
+			// A lone * matches every document
+			return &MatchAllQuery{}
+		}
+		return nil
+	}
+
+	build := func(field string) Query {
+		switch {
+		case token.kind == qsPhrase:
+			slop, _ := strconv.Atoi(token.fuzziness)
+			return &MatchPhraseQuery{Field: field, Query: token.text, Slop: slop}
+		case token.prefix:
+			return &PrefixQuery{Field: field, Value: token.text}
+		case token.fuzziness != "":
+			return &FuzzyQuery{Field: field, Value: token.text, Fuzziness: token.fuzziness}
+		default:
+			return &MatchQuery{Field: field, Query: token.text}
+		}
+	}
+
+	queries := make([]Query, len(p.fields))
+	for i, field := range p.fields {
+		queries[i] = build(field.name)
+		if field.boost > 0 {
+			applyBoost(queries[i], field.boost)
+		}
+	}
+	if len(queries) == 1 {
+		return queries[0]
+	}
+	return &BoolQuery{Should: queries}
+}
@@ -0,0 +1,244 @@
+package parser
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// parseSimpleQueryStringBody parses {"simple_query_string": body} and
+// returns the query its query string parsed into
+func parseSimpleQueryStringBody(t *testing.T, body map[string]interface{}) Query {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]interface{}{
+		"query": map[string]interface{}{"simple_query_string": body},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := NewQueryParser().ParseSearchRequest(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query, ok := req.ParsedQuery.(*SimpleQueryStringQuery)
+	if !ok {
+		t.Fatalf("expected *SimpleQueryStringQuery, got %T", req.ParsedQuery)
+	}
+	return query.Parsed
+}
+
+// matchNone is what a simple query string with nothing to search parses to
+var matchNone = &BoolQuery{MustNot: []Query{&MatchAllQuery{}}}
+
+func TestParseSimpleQueryString(t *testing.T) {
+	title := func(text string) Query { return &MatchQuery{Field: "title", Query: text} }
+
+	tests := []struct {
+		name     string
+		query    string
+		operator string
+		want     Query
+	}{
+		{
+			name:  "terms default to OR",
+			query: "quick fox",
+			want:  &BoolQuery{Should: []Query{title("quick"), title("fox")}},
+		},
+		{
+			name:     "default operator AND",
+			query:    "quick fox",
+			operator: "AND",
+			want:     &BoolQuery{Must: []Query{title("quick"), title("fox")}},
+		},
+		{
+			name:  "operators",
+			query: "quick + fox | dog -cat",
+			want: &BoolQuery{
+				Should: []Query{
+					&BoolQuery{Must: []Query{title("quick"), title("fox")}},
+					title("dog"),
+				},
+				MustNot: []Query{title("cat")},
+			},
+		},
+		{
+			name:  "groups",
+			query: "(quick | brown) + fox",
+			want: &BoolQuery{Must: []Query{
+				&BoolQuery{Should: []Query{title("quick"), title("brown")}},
+				title("fox"),
+			}},
+		},
+		{
+			name:  "phrase, prefix and fuzzy",
+			query: `"quick fox"~2 bro* fxo~1`,
+			want: &BoolQuery{Should: []Query{
+				&MatchPhraseQuery{Field: "title", Query: "quick fox", Slop: 2},
+				&PrefixQuery{Field: "title", Value: "bro"},
+				&FuzzyQuery{Field: "title", Value: "fxo", Fuzziness: "1"},
+			}},
+		},
+		{
+			name:  "operators inside terms",
+			query: "c++ e-mail",
+			want:  &BoolQuery{Should: []Query{title("c++"), title("e-mail")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := map[string]interface{}{"query": tt.query, "fields": []interface{}{"title"}}
+			if tt.operator != "" {
+				body["default_operator"] = tt.operator
+			}
+			got := parseSimpleQueryStringBody(t, body)
+			if !reflect.DeepEqual(got, tt.want) {
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(tt.want)
+				t.Errorf("parsed %s\nwant   %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestParseSimpleQueryStringFields(t *testing.T) {
+	got := parseSimpleQueryStringBody(t, map[string]interface{}{
+		"query":  "fox",
+		"fields": []interface{}{"title^3", "body"},
+	})
+	want := &BoolQuery{Should: []Query{
+		&MatchQuery{Field: "title", Query: "fox", Boost: 3},
+		&MatchQuery{Field: "body", Query: "fox"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+
+	// Without fields every field is searched
+	got = parseSimpleQueryStringBody(t, map[string]interface{}{"query": "fox"})
+	if want := (&MatchQuery{Field: DefaultQueryStringField, Query: "fox"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestExpandSimpleQueryStringFieldPatterns(t *testing.T) {
+	resolve := func(pattern string) ([]string, error) {
+		switch pattern {
+		case "*":
+			return []string{"body", "title"}, nil
+		case "title*":
+			return []string{"title", "title.raw"}, nil
+		}
+		return nil, nil
+	}
+
+	expand := func(body map[string]interface{}) (Query, error) {
+		t.Helper()
+		data, err := json.Marshal(map[string]interface{}{
+			"query": map[string]interface{}{"simple_query_string": body},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := NewQueryParser().ParseSearchRequest(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expanded, err := ExpandFieldPatterns(req.ParsedQuery, resolve)
+		if err != nil {
+			return nil, err
+		}
+		return expanded.(*SimpleQueryStringQuery).Parsed, nil
+	}
+
+	// The default * searches every resolved field
+	got, err := expand(map[string]interface{}{"query": "fox"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &BoolQuery{Should: []Query{
+		&MatchQuery{Field: "body", Query: "fox"},
+		&MatchQuery{Field: "title", Query: "fox"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+
+	// A pattern's boost applies to each field it resolves to
+	got, err = expand(map[string]interface{}{"query": "fox", "fields": []interface{}{"title*^2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = &BoolQuery{Should: []Query{
+		&MatchQuery{Field: "title", Query: "fox", Boost: 2},
+		&MatchQuery{Field: "title.raw", Query: "fox", Boost: 2},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+
+	// A pattern matching no field is an error
+	if _, err := expand(map[string]interface{}{"query": "fox", "fields": []interface{}{"price*"}}); err == nil {
+		t.Errorf("expected an error for a pattern matching no fields")
+	}
+}
+
+func TestParseSimpleQueryStringMalformed(t *testing.T) {
+	title := func(text string) Query { return &MatchQuery{Field: "title", Query: text} }
+
+	tests := []struct {
+		name  string
+		query string
+		want  Query
+	}{
+		{
+			name:  "unbalanced quote",
+			query: `"quick fox`,
+			want:  &BoolQuery{Should: []Query{title("quick"), title("fox")}},
+		},
+		{
+			name:  "unbalanced quote after a phrase",
+			query: `"quick fox" "brown`,
+			want: &BoolQuery{Should: []Query{
+				&MatchPhraseQuery{Field: "title", Query: "quick fox"},
+				title("brown"),
+			}},
+		},
+		{
+			name:  "unclosed group",
+			query: "(quick | fox",
+			want:  &BoolQuery{Should: []Query{title("quick"), title("fox")}},
+		},
+		{
+			name:  "unmatched closing parenthesis",
+			query: "quick) fox",
+			want:  &BoolQuery{Should: []Query{title("quick"), title("fox")}},
+		},
+		{
+			name:  "dangling operators",
+			query: "+quick | | fox -",
+			want:  &BoolQuery{Should: []Query{title("quick"), title("fox")}},
+		},
+		{name: "operators only", query: "+ - | +", want: matchNone},
+		{name: "empty group", query: "()", want: matchNone},
+		{name: "empty", query: "", want: matchNone},
+		{name: "whitespace", query: "   ", want: matchNone},
+		{name: "lone quote", query: `"`, want: matchNone},
+		{name: "lone tilde", query: "~2", want: matchNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseSimpleQueryStringBody(t, map[string]interface{}{
+				"query":  tt.query,
+				"fields": []interface{}{"title"},
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(tt.want)
+				t.Errorf("parsed %s\nwant   %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
@@ -1,35 +1,128 @@
 package parser
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/quidditch/quidditch/pkg/common/geo"
+)
+
 // SearchRequest represents a complete search request
 type SearchRequest struct {
-	Query       map[string]interface{}   `json:"query,omitempty"`
-	Size        int                      `json:"size,omitempty"`
-	From        int                      `json:"from,omitempty"`
-	Sort        []map[string]interface{} `json:"sort,omitempty"`
-	Source      interface{}              `json:"_source,omitempty"`
-	Aggregations map[string]interface{}  `json:"aggregations,omitempty"`
-	Aggs        map[string]interface{}   `json:"aggs,omitempty"` // Alias for aggregations
-	Highlight   map[string]interface{}   `json:"highlight,omitempty"`
-	Timeout     string                   `json:"timeout,omitempty"`
+	Query        map[string]interface{}   `json:"query,omitempty"`
+	Size         int                      `json:"size,omitempty"`
+	From         int                      `json:"from,omitempty"`
+	Sort         []map[string]interface{} `json:"sort,omitempty"`
+	Source       interface{}              `json:"_source,omitempty"`
+	Aggregations map[string]interface{}   `json:"aggregations,omitempty"`
+	Aggs         map[string]interface{}   `json:"aggs,omitempty"` // Alias for aggregations
+	Highlight    map[string]interface{}   `json:"highlight,omitempty"`
+	Timeout      string                   `json:"timeout,omitempty"`
+
+	// Stop collecting on each shard after this many matching documents (0 = no limit)
+	TerminateAfter int `json:"terminate_after,omitempty"`
+
+	// Drop hits scoring below this threshold from the hits and the total
+	MinScore float64 `json:"min_score,omitempty"`
+
+	// Return only the best-scoring hit per distinct value of a field
+	Collapse *Collapse `json:"collapse,omitempty"`
+
+	// Score multipliers per index for multi-index searches, as a list of
+	// {"index": boost} objects; the first entry naming an index applies
+	IndicesBoost []map[string]float64 `json:"indices_boost,omitempty"`
+
+	// Return hits from the successful shards when some shards fail (default true)
+	AllowPartialSearchResults *bool `json:"allow_partial_search_results,omitempty"`
+
+	// Named term and completion suggesters, run alongside the query
+	Suggest map[string]interface{} `json:"suggest,omitempty"`
 
 	// Parsed query (not from JSON)
 	ParsedQuery Query `json:"-"`
 }
 
+// DefaultSize is the number of hits returned when a search request has no
+// size
+const DefaultSize = 10
+
+// Collapse groups hits by a field. Each group is represented by its
+// best-scoring hit, optionally carrying the group's top hits as inner_hits.
+type Collapse struct {
+	Field     string     `json:"field"`
+	InnerHits *InnerHits `json:"inner_hits,omitempty"`
+}
+
+// DefaultInnerHitsSize is the number of inner hits returned per collapsed
+// group when inner_hits has no size
+const DefaultInnerHitsSize = 3
+
+// InnerHits requests the top hits of each collapsed group
+type InnerHits struct {
+	Name string `json:"name,omitempty"`
+	Size *int   `json:"size,omitempty"`
+}
+
+// IndexBoost returns the indices_boost of an index, 1 when it has none
+func (r *SearchRequest) IndexBoost(index string) float64 {
+	for _, entry := range r.IndicesBoost {
+		if boost, ok := entry[index]; ok {
+			return boost
+		}
+	}
+	return 1
+}
+
+// AllowPartialResults reports whether a search may succeed with failed shards
+func (r *SearchRequest) AllowPartialResults() bool {
+	return r.AllowPartialSearchResults == nil || *r.AllowPartialSearchResults
+}
+
+// TimeoutDuration returns the search timeout, or zero when the search may run
+// unbounded (no timeout or "-1")
+func (r *SearchRequest) TimeoutDuration() (time.Duration, error) {
+	if r.Timeout == "" || r.Timeout == "-1" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(r.Timeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid timeout [%s]: expected a positive duration such as \"500ms\"", r.Timeout)
+	}
+	return timeout, nil
+}
+
 // Query is the interface for all query types
 type Query interface {
 	QueryType() string
 }
 
+// Named carries the _name of a query clause. Hits report the names of the
+// clauses they matched in matched_queries.
+type Named struct {
+	Name string
+}
+
+// QueryName returns the _name of the query clause, or "" if it has none
+func (n *Named) QueryName() string { return n.Name }
+
+func (n *Named) setQueryName(name string) { n.Name = name }
+
+// NamedQuery is implemented by queries that can carry a _name
+type NamedQuery interface {
+	Query
+	QueryName() string
+}
+
 // ============================================================================
 // Full-Text Queries
 // ============================================================================
 
 // MatchQuery represents a match query
 type MatchQuery struct {
+	Named
 	Field    string
 	Query    string
-	Operator string  // "and" or "or"
+	Operator string // "and" or "or"
 	Boost    float64
 	Analyzer string
 }
@@ -38,6 +131,7 @@ func (q *MatchQuery) QueryType() string { return "match" }
 
 // MatchPhraseQuery represents a match_phrase query
 type MatchPhraseQuery struct {
+	Named
 	Field string
 	Query string
 	Slop  int // Maximum positions between matching terms
@@ -47,6 +141,7 @@ func (q *MatchPhraseQuery) QueryType() string { return "match_phrase" }
 
 // MultiMatchQuery represents a multi_match query
 type MultiMatchQuery struct {
+	Named
 	Query  string
 	Fields []string
 	Type   string // best_fields, most_fields, cross_fields, phrase, phrase_prefix
@@ -56,19 +151,40 @@ func (q *MultiMatchQuery) QueryType() string { return "multi_match" }
 
 // QueryStringQuery represents a query_string query (Lucene syntax)
 type QueryStringQuery struct {
-	Query        string
-	DefaultField string
-	Fields       []string
+	Named
+	Query           string
+	DefaultField    string
+	Fields          []string
+	DefaultOperator string // "OR" (default) or "AND", joining clauses without an operator
+
+	// Parsed is the query string parsed into term, phrase, range and bool
+	// queries
+	Parsed Query
 }
 
 func (q *QueryStringQuery) QueryType() string { return "query_string" }
 
+// SimpleQueryStringQuery represents a simple_query_string query, whose
+// forgiving syntax never fails to parse
+type SimpleQueryStringQuery struct {
+	Named
+	Query           string
+	Fields          []string // Field names, each optionally boosted as field^boost
+	DefaultOperator string   // "OR" (default) or "AND", joining clauses without an operator
+
+	// Parsed is the query string parsed into term, phrase and bool queries
+	Parsed Query
+}
+
+func (q *SimpleQueryStringQuery) QueryType() string { return "simple_query_string" }
+
 // ============================================================================
 // Term-Level Queries
 // ============================================================================
 
 // TermQuery represents a term query (exact match)
 type TermQuery struct {
+	Named
 	Field string
 	Value interface{}
 	Boost float64
@@ -78,26 +194,40 @@ func (q *TermQuery) QueryType() string { return "term" }
 
 // TermsQuery represents a terms query (multiple exact matches)
 type TermsQuery struct {
+	Named
 	Field  string
 	Values []interface{}
 }
 
 func (q *TermsQuery) QueryType() string { return "terms" }
 
+// IDsQuery represents an ids query (documents with any of the given _id values)
+type IDsQuery struct {
+	Named
+	Values []string
+}
+
+func (q *IDsQuery) QueryType() string { return "ids" }
+
 // RangeQuery represents a range query
 type RangeQuery struct {
+	Named
 	Field string
 	Gt    interface{} // Greater than
 	Gte   interface{} // Greater than or equal
 	Lt    interface{} // Less than
 	Lte   interface{} // Less than or equal
 	Boost float64
+	// Format lists the date formats used to parse date bounds, separated
+	// by "||" (e.g. "yyyy-MM-dd||epoch_millis")
+	Format string
 }
 
 func (q *RangeQuery) QueryType() string { return "range" }
 
 // ExistsQuery represents an exists query (field has a value)
 type ExistsQuery struct {
+	Named
 	Field string
 }
 
@@ -105,6 +235,7 @@ func (q *ExistsQuery) QueryType() string { return "exists" }
 
 // PrefixQuery represents a prefix query
 type PrefixQuery struct {
+	Named
 	Field string
 	Value string
 }
@@ -113,39 +244,64 @@ func (q *PrefixQuery) QueryType() string { return "prefix" }
 
 // WildcardQuery represents a wildcard query
 type WildcardQuery struct {
+	Named
 	Field string
 	Value string // Supports * and ?
 }
 
 func (q *WildcardQuery) QueryType() string { return "wildcard" }
 
+// RegexpQuery represents a regexp query
+type RegexpQuery struct {
+	Named
+	Field string
+	Value string // Regular expression matched against whole terms
+}
+
+func (q *RegexpQuery) QueryType() string { return "regexp" }
+
 // FuzzyQuery represents a fuzzy query
 type FuzzyQuery struct {
-	Field      string
-	Value      string
-	Fuzziness  string // "AUTO", "0", "1", "2"
+	Named
+	Field     string
+	Value     string
+	Fuzziness string // "AUTO", "0", "1", "2"
 }
 
 func (q *FuzzyQuery) QueryType() string { return "fuzzy" }
 
+// GeoDistanceQuery represents a geo_distance query (geo_point within a
+// radius of an origin)
+type GeoDistanceQuery struct {
+	Named
+	Field          string
+	Origin         geo.Point
+	Distance       float64 // Radius in meters
+	RenderDistance bool    // Add each hit's distance to its source as _geo_distance
+}
+
+func (q *GeoDistanceQuery) QueryType() string { return "geo_distance" }
+
 // ============================================================================
 // Compound Queries
 // ============================================================================
 
 // BoolQuery represents a bool query (boolean combinations)
 type BoolQuery struct {
-	Must                   []Query
-	Should                 []Query
-	MustNot                []Query
-	Filter                 []Query
-	MinimumShouldMatch     int
-	MinimumShouldMatchStr  string // Can be "75%" or "3<90%"
+	Named
+	Must                  []Query
+	Should                []Query
+	MustNot               []Query
+	Filter                []Query
+	MinimumShouldMatch    int
+	MinimumShouldMatchStr string // Can be "75%" or "3<90%"
 }
 
 func (q *BoolQuery) QueryType() string { return "bool" }
 
 // MatchAllQuery represents a match_all query
 type MatchAllQuery struct {
+	Named
 	Boost float64
 }
 
@@ -208,7 +364,7 @@ func IsTermLevelQuery(q Query) bool {
 // IsFullTextQuery checks if a query is a full-text query
 func IsFullTextQuery(q Query) bool {
 	switch q.(type) {
-	case *MatchQuery, *MatchPhraseQuery, *MultiMatchQuery, *QueryStringQuery:
+	case *MatchQuery, *MatchPhraseQuery, *MultiMatchQuery, *QueryStringQuery, *SimpleQueryStringQuery:
 		return true
 	default:
 		return false
@@ -240,6 +396,10 @@ func GetQueryFields(q Query) []string {
 		fields = append(fields, query.Field)
 	case *FuzzyQuery:
 		fields = append(fields, query.Field)
+	case *QueryStringQuery:
+		fields = append(fields, GetQueryFields(query.Parsed)...)
+	case *SimpleQueryStringQuery:
+		fields = append(fields, GetQueryFields(query.Parsed)...)
 	case *BoolQuery:
 		for _, subQuery := range query.Must {
 			fields = append(fields, GetQueryFields(subQuery)...)
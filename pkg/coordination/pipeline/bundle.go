@@ -0,0 +1,222 @@
+// Copyright 2026 Quidditch Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// ConflictPolicy decides what Import does with a pipeline or index
+// association that already exists
+type ConflictPolicy string
+
+const (
+	// ConflictSkip keeps the existing pipeline or association
+	ConflictSkip ConflictPolicy = "skip"
+
+	// ConflictOverwrite replaces the existing pipeline or association
+	ConflictOverwrite ConflictPolicy = "overwrite"
+)
+
+// Bundle is a portable set of pipeline definitions and index associations,
+// used to move pipelines between clusters
+type Bundle struct {
+	Pipelines    []*PipelineDefinition `json:"pipelines"`
+	Associations []IndexAssociation    `json:"associations"`
+}
+
+// ImportResult reports what Import changed
+type ImportResult struct {
+	Created             []string `json:"created"`
+	Updated             []string `json:"updated"`
+	Skipped             []string `json:"skipped"`
+	AssociationsApplied int      `json:"associations_applied"`
+	AssociationsSkipped int      `json:"associations_skipped"`
+}
+
+// Export returns every registered pipeline ordered by name, and every index
+// association ordered by index and type
+func (r *Registry) Export() *Bundle {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bundle := &Bundle{
+		Pipelines:    make([]*PipelineDefinition, 0, len(r.pipelines)),
+		Associations: []IndexAssociation{},
+	}
+	for _, impl := range r.pipelines {
+		defCopy := *impl.def
+		bundle.Pipelines = append(bundle.Pipelines, &defCopy)
+	}
+	sort.Slice(bundle.Pipelines, func(i, j int) bool {
+		return bundle.Pipelines[i].Name < bundle.Pipelines[j].Name
+	})
+
+	for indexName, pipelineMap := range r.indexPipelines {
+		for pipelineType, pipelineName := range pipelineMap {
+			bundle.Associations = append(bundle.Associations, IndexAssociation{
+				IndexName: indexName,
+				Type:      pipelineType,
+				Pipeline:  pipelineName,
+			})
+		}
+	}
+	sort.Slice(bundle.Associations, func(i, j int) bool {
+		a, b := bundle.Associations[i], bundle.Associations[j]
+		if a.IndexName != b.IndexName {
+			return a.IndexName < b.IndexName
+		}
+		return a.Type < b.Type
+	})
+
+	return bundle
+}
+
+// Import registers the pipelines of a bundle, then applies its index
+// associations. Pipelines and associations that already exist are kept or
+// replaced according to policy. The import is all-or-nothing: if anything
+// fails to apply, every change already made is undone and the error is
+// returned. Pipelines may reference each other in any order within the
+// bundle.
+func (r *Registry) Import(bundle *Bundle, policy ConflictPolicy) (*ImportResult, error) {
+	if policy != ConflictSkip && policy != ConflictOverwrite {
+		return nil, &ValidationError{
+			Field:   "on_conflict",
+			Message: fmt.Sprintf("unknown conflict policy '%s', expected '%s' or '%s'", policy, ConflictSkip, ConflictOverwrite),
+		}
+	}
+
+	seen := make(map[string]bool, len(bundle.Pipelines))
+	for _, def := range bundle.Pipelines {
+		if seen[def.Name] {
+			return nil, &ValidationError{
+				Field:   "pipelines",
+				Message: fmt.Sprintf("pipeline '%s' appears more than once", def.Name),
+			}
+		}
+		seen[def.Name] = true
+	}
+
+	result := &ImportResult{
+		Created: []string{},
+		Updated: []string{},
+		Skipped: []string{},
+	}
+
+	// undo holds the inverse of every change made so far
+	var undo []func() error
+	fail := func(err error) (*ImportResult, error) {
+		for i := len(undo) - 1; i >= 0; i-- {
+			if undoErr := undo[i](); undoErr != nil {
+				r.logger.Error("Failed to roll back pipeline import", zap.Error(undoErr))
+			}
+		}
+		return nil, err
+	}
+
+	var pending []*PipelineDefinition
+	for _, def := range bundle.Pipelines {
+		if _, exists := r.definition(def.Name); exists && policy == ConflictSkip {
+			result.Skipped = append(result.Skipped, def.Name)
+			continue
+		}
+		defCopy := *def
+		pending = append(pending, &defCopy)
+	}
+
+	// Register in passes until no pipeline's references become resolvable
+	for len(pending) > 0 {
+		var failed []*PipelineDefinition
+		var errs []error
+		for _, def := range pending {
+			name := def.Name
+			previous, exists := r.definition(name)
+			if exists {
+				if err := r.Update(def); err != nil {
+					failed = append(failed, def)
+					errs = append(errs, err)
+					continue
+				}
+				undo = append(undo, func() error { return r.Update(previous) })
+				result.Updated = append(result.Updated, name)
+				continue
+			}
+
+			if err := r.Register(def); err != nil {
+				failed = append(failed, def)
+				errs = append(errs, err)
+				continue
+			}
+			undo = append(undo, func() error { return r.Unregister(name) })
+			result.Created = append(result.Created, name)
+		}
+		if len(failed) == len(pending) {
+			return fail(fmt.Errorf("failed to import pipeline '%s': %w", failed[0].Name, errs[0]))
+		}
+		pending = failed
+	}
+
+	for _, association := range bundle.Associations {
+		indexName, pipelineType := association.IndexName, association.Type
+		current, exists := r.associated(indexName, pipelineType)
+		if exists && current == association.Pipeline {
+			continue
+		}
+		if exists && policy == ConflictSkip {
+			result.AssociationsSkipped++
+			continue
+		}
+
+		if _, registered := r.definition(association.Pipeline); !registered {
+			return fail(&ValidationError{
+				Field:   "associations",
+				Message: fmt.Sprintf("index '%s' is associated with unknown pipeline '%s'", indexName, association.Pipeline),
+			})
+		}
+		if err := r.AssociatePipeline(indexName, pipelineType, association.Pipeline); err != nil {
+			return fail(fmt.Errorf("failed to import association of index '%s': %w", indexName, err))
+		}
+		if exists {
+			undo = append(undo, func() error { return r.AssociatePipeline(indexName, pipelineType, current) })
+		} else {
+			undo = append(undo, func() error { return r.DisassociatePipeline(indexName, pipelineType) })
+		}
+		result.AssociationsApplied++
+	}
+
+	r.logger.Info("Pipelines imported",
+		zap.Int("created", len(result.Created)),
+		zap.Int("updated", len(result.Updated)),
+		zap.Int("skipped", len(result.Skipped)),
+		zap.Int("associations", result.AssociationsApplied))
+
+	return result, nil
+}
+
+// definition returns a copy of a registered pipeline's definition
+func (r *Registry) definition(name string) (*PipelineDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	impl, exists := r.pipelines[name]
+	if !exists {
+		return nil, false
+	}
+	defCopy := *impl.def
+	return &defCopy, true
+}
+
+// associated returns the pipeline associated with an index for a type
+func (r *Registry) associated(indexName string, pipelineType PipelineType) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pipelineName, exists := r.indexPipelines[indexName][pipelineType]
+	return pipelineName, exists
+}
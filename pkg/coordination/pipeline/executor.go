@@ -56,12 +56,34 @@ func (p *pipelineImpl) Metadata() map[string]interface{} {
 
 // Execute runs the pipeline on input data
 func (p *pipelineImpl) Execute(ctx context.Context, input interface{}) (interface{}, error) {
+	output, _, err := p.execute(ctx, input, nil)
+	return output, err
+}
+
+// execute runs the pipeline and returns the statistics of every stage that ran.
+// When observe is set it receives a snapshot of every stage, including skipped ones.
+func (p *pipelineImpl) execute(ctx context.Context, input interface{}, observe func(StageSnapshot)) (interface{}, []StageStats, error) {
 	startTime := time.Now()
 
 	// Check if pipeline is enabled
 	if !p.def.Enabled {
 		p.logger.Warn("Pipeline is disabled, skipping execution")
-		return input, nil
+		return input, nil, nil
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// Guard against pipelines that end up running themselves
+	ctx, err := enterPipeline(ctx, p.def.Name)
+	if err != nil {
+		return nil, nil, &PipelineError{
+			PipelineName: p.def.Name,
+			Message:      "pipeline cycle detected",
+			Cause:        err,
+			Timestamp:    time.Now(),
+		}
 	}
 
 	// Create parent context with timeout if configured
@@ -73,7 +95,7 @@ func (p *pipelineImpl) Execute(ctx context.Context, input interface{}) (interfac
 
 	// Validate input type matches pipeline type
 	if err := p.validateInput(input); err != nil {
-		return nil, &PipelineError{
+		return nil, nil, &PipelineError{
 			PipelineName: p.def.Name,
 			Message:      "invalid input type",
 			Cause:        err,
@@ -89,10 +111,39 @@ func (p *pipelineImpl) Execute(ctx context.Context, input interface{}) (interfac
 	for i, stage := range p.stages {
 		// Skip disabled stages
 		stageDef := &p.def.Stages[i]
+
+		// Snapshot the input before the stage can mutate it
+		var stageInput interface{}
+		if observe != nil {
+			stageInput = deepCopyValue(output)
+		}
+		record := func(status StageStatus, stageOutput interface{}, duration time.Duration, stageErr error) {
+			if observe == nil {
+				return
+			}
+			snapshot := StageSnapshot{
+				Name:     stageDef.Name,
+				Index:    i,
+				Type:     stage.Type(),
+				Status:   status,
+				Input:    stageInput,
+				Output:   deepCopyValue(stageOutput),
+				Duration: duration,
+			}
+			if pipelineErr, ok := stageErr.(*PipelineError); ok && pipelineErr.Cause != nil {
+				stageErr = pipelineErr.Cause
+			}
+			if stageErr != nil {
+				snapshot.Error = stageErr.Error()
+			}
+			observe(snapshot)
+		}
+
 		if !stageDef.Enabled {
 			p.logger.Debug("Stage disabled, skipping",
 				zap.String("stage", stageDef.Name),
 				zap.Int("index", i))
+			record(StageStatusSkipped, nil, 0, nil)
 			continue
 		}
 
@@ -111,6 +162,24 @@ func (p *pipelineImpl) Execute(ctx context.Context, input interface{}) (interfac
 		// Execute stage with error handling
 		stageOutput, stageStat, err := p.executeStage(stage, stageDef, stageCtx, output)
 
+		// Per-stage error policy takes precedence over OnFailure
+		if err != nil && stageDef.OnError != "" {
+			stageErr := err
+			stageOutput, err = p.applyErrorPolicy(stageDef, i, err, output, stageStat)
+			stageStats = append(stageStats, *stageStat)
+			if err != nil {
+				record(StageStatusFailed, nil, stageStat.AverageDuration, stageErr)
+				return nil, stageStats, err
+			}
+			if stageStat.DefaultedExecutions > 0 {
+				record(StageStatusDefaulted, stageOutput, stageStat.AverageDuration, stageErr)
+			} else {
+				record(StageStatusContinued, stageOutput, stageStat.AverageDuration, stageErr)
+			}
+			output = stageOutput
+			continue
+		}
+
 		// Record statistics
 		stageStats = append(stageStats, *stageStat)
 
@@ -134,6 +203,7 @@ func (p *pipelineImpl) Execute(ctx context.Context, input interface{}) (interfac
 					zap.String("policy", string(failurePolicy)),
 					zap.Error(err))
 				// Keep output unchanged, continue to next stage
+				record(StageStatusContinued, output, stageStat.AverageDuration, err)
 				continue
 
 			case FailurePolicyRetry:
@@ -143,7 +213,8 @@ func (p *pipelineImpl) Execute(ctx context.Context, input interface{}) (interfac
 					zap.Int("index", i),
 					zap.String("policy", string(failurePolicy)),
 					zap.Error(err))
-				return nil, err
+				record(StageStatusFailed, nil, stageStat.AverageDuration, err)
+				return nil, stageStats, err
 
 			case FailurePolicyAbort:
 				fallthrough
@@ -154,10 +225,13 @@ func (p *pipelineImpl) Execute(ctx context.Context, input interface{}) (interfac
 					zap.Int("index", i),
 					zap.String("policy", string(failurePolicy)),
 					zap.Error(err))
-				return nil, err
+				record(StageStatusFailed, nil, stageStat.AverageDuration, err)
+				return nil, stageStats, err
 			}
 		}
 
+		record(StageStatusSuccess, stageOutput, stageStat.AverageDuration, nil)
+
 		// Update output for next stage
 		output = stageOutput
 	}
@@ -170,7 +244,60 @@ func (p *pipelineImpl) Execute(ctx context.Context, input interface{}) (interfac
 		zap.Duration("duration", duration),
 		zap.Int("stages_executed", len(stageStats)))
 
-	return output, nil
+	return output, stageStats, nil
+}
+
+// applyErrorPolicy resolves a stage error according to the stage's OnError
+// policy, recording the outcome on the stage statistics
+func (p *pipelineImpl) applyErrorPolicy(stageDef *StageDefinition, stageIndex int,
+	err error, input interface{}, stageStat *StageStats) (interface{}, error) {
+
+	switch stageDef.OnError {
+	case ErrorPolicyContinue:
+		p.logger.Warn("Stage failed, passing input forward",
+			zap.String("stage", stageDef.Name),
+			zap.Int("index", stageIndex),
+			zap.Error(err))
+		stageStat.ContinuedExecutions = 1
+		return input, nil
+
+	case ErrorPolicyUseDefault:
+		p.logger.Warn("Stage failed, using default output",
+			zap.String("stage", stageDef.Name),
+			zap.Int("index", stageIndex),
+			zap.Error(err))
+		stageStat.DefaultedExecutions = 1
+		return deepCopyValue(stageDef.DefaultOutput), nil
+
+	default:
+		p.logger.Error("Stage failed, aborting pipeline",
+			zap.String("stage", stageDef.Name),
+			zap.Int("index", stageIndex),
+			zap.String("policy", string(stageDef.OnError)),
+			zap.Error(err))
+		return nil, err
+	}
+}
+
+// deepCopyValue copies nested maps and slices so later stages can't mutate
+// configured defaults or captured snapshots
+func deepCopyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			copied[k] = deepCopyValue(val)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, val := range v {
+			copied[i] = deepCopyValue(val)
+		}
+		return copied
+	default:
+		return v
+	}
 }
 
 // executeStage executes a single stage with timeout and error handling
@@ -359,38 +486,55 @@ func (e *Executor) ExecutePipeline(ctx context.Context, pipelineName string, inp
 		return nil, err
 	}
 
-	// Execute
+	// Execute, collecting per-stage outcomes when available
 	startTime := time.Now()
-	output, err := pipeline.Execute(ctx, input)
+	var output interface{}
+	var stageStats []StageStats
+	if impl, ok := pipeline.(*pipelineImpl); ok {
+		output, stageStats, err = impl.execute(ctx, input, nil)
+	} else {
+		output, err = pipeline.Execute(ctx, input)
+	}
 	duration := time.Since(startTime)
 
 	// Update metrics
 	e.updateMetrics(pipelineName, duration, err == nil, pipeline.Stages())
 
 	// Update registry statistics
-	success := err == nil
-	var stageStats []StageStats
-	if impl, ok := pipeline.(*pipelineImpl); ok {
-		stageStats = make([]StageStats, len(impl.stages))
-		for i, stage := range impl.stages {
-			stageStats[i] = StageStats{
-				Name:                 stage.Name(),
-				TotalExecutions:      1,
-				SuccessfulExecutions: 0,
-				FailedExecutions:     0,
-			}
-			if success {
-				stageStats[i].SuccessfulExecutions = 1
-			} else {
-				stageStats[i].FailedExecutions = 1
-			}
-		}
-	}
-	e.registry.UpdateStats(pipelineName, duration, success, stageStats)
+	e.registry.UpdateStats(pipelineName, duration, err == nil, stageStats)
 
 	return output, err
 }
 
+// SimulatePipeline runs a pipeline and captures the input and output of every
+// stage. Simulations are for debugging and don't update statistics or metrics.
+// When the pipeline fails, the result still holds the stages that ran.
+func (e *Executor) SimulatePipeline(ctx context.Context, pipelineName string, input interface{}) (*SimulationResult, error) {
+	pipeline, err := e.registry.Get(pipelineName)
+	if err != nil {
+		return nil, err
+	}
+
+	impl, ok := pipeline.(*pipelineImpl)
+	if !ok {
+		return nil, fmt.Errorf("pipeline '%s' does not support simulation", pipelineName)
+	}
+
+	result := &SimulationResult{
+		Pipeline: pipelineName,
+		Stages:   []StageSnapshot{},
+	}
+
+	startTime := time.Now()
+	output, _, err := impl.execute(ctx, input, func(snapshot StageSnapshot) {
+		result.Stages = append(result.Stages, snapshot)
+	})
+	result.Duration = time.Since(startTime)
+	result.Output = output
+
+	return result, err
+}
+
 // ExecutePipelineForIndex executes the pipeline associated with an index
 func (e *Executor) ExecutePipelineForIndex(ctx context.Context, indexName string,
 	pipelineType PipelineType, input interface{}) (interface{}, error) {
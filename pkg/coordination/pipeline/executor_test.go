@@ -581,3 +581,282 @@ func TestExecutor_Metrics(t *testing.T) {
 	assert.Greater(t, metrics.TotalDuration, time.Duration(0))
 	assert.False(t, metrics.LastExecutionTime.IsZero())
 }
+
+func TestExecutor_StageErrorPolicy(t *testing.T) {
+	logger := zap.NewNop()
+
+	failing := func() *mockStage {
+		return &mockStage{
+			name:      "enrich",
+			stageType: StageTypeNative,
+			executeFunc: func(ctx *StageContext, input interface{}) (interface{}, error) {
+				return nil, errors.New("enrichment service unavailable")
+			},
+		}
+	}
+	tagging := &mockStage{
+		name:      "tag",
+		stageType: StageTypeNative,
+		executeFunc: func(ctx *StageContext, input interface{}) (interface{}, error) {
+			doc := input.(map[string]interface{})
+			doc["tagged"] = true
+			return doc, nil
+		},
+	}
+
+	setup := func(t *testing.T, policy ErrorPolicy, defaultOutput interface{}) (*Executor, *Registry) {
+		registry := NewRegistry(logger)
+		executor := NewExecutor(registry, logger)
+
+		def := &PipelineDefinition{
+			Name:    "policy-pipeline",
+			Version: "1.0.0",
+			Type:    PipelineTypeDocument,
+			Stages: []StageDefinition{
+				{
+					Name:          "enrich",
+					Type:          StageTypeNative,
+					Enabled:       true,
+					Config:        map[string]interface{}{"function": "enrich"},
+					OnError:       policy,
+					DefaultOutput: defaultOutput,
+				},
+				{Name: "tag", Type: StageTypeNative, Enabled: true, Config: map[string]interface{}{"function": "tag"}},
+			},
+			Enabled: true,
+			// The stage policy must win over the pipeline-level policy
+			OnFailure: FailurePolicyContinue,
+		}
+		require.NoError(t, registry.Register(def))
+
+		pipeline, err := registry.Get("policy-pipeline")
+		require.NoError(t, err)
+		pipeline.(*pipelineImpl).SetStages([]Stage{failing(), tagging})
+
+		return executor, registry
+	}
+
+	t.Run("Fail", func(t *testing.T) {
+		executor, registry := setup(t, ErrorPolicyFail, nil)
+
+		output, err := executor.ExecutePipeline(context.Background(), "policy-pipeline", map[string]interface{}{"title": "doc"})
+		require.Error(t, err)
+		assert.Nil(t, output)
+		assert.Contains(t, err.Error(), "enrichment service unavailable")
+
+		stats, err := registry.GetStats("policy-pipeline")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), stats.FailedExecutions)
+		assert.Equal(t, int64(1), stats.StageStats[0].FailedExecutions)
+		assert.Contains(t, stats.StageStats[0].LastError, "enrichment service unavailable")
+		assert.Equal(t, int64(0), stats.StageStats[1].TotalExecutions, "stage after the failure must not run")
+	})
+
+	t.Run("Continue", func(t *testing.T) {
+		executor, registry := setup(t, ErrorPolicyContinue, nil)
+
+		output, err := executor.ExecutePipeline(context.Background(), "policy-pipeline", map[string]interface{}{"title": "doc"})
+		require.NoError(t, err)
+		result := output.(map[string]interface{})
+		assert.Equal(t, "doc", result["title"])
+		assert.Equal(t, true, result["tagged"])
+
+		stats, err := registry.GetStats("policy-pipeline")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), stats.SuccessfulExecutions)
+		assert.Equal(t, int64(1), stats.StageStats[0].FailedExecutions)
+		assert.Equal(t, int64(1), stats.StageStats[0].ContinuedExecutions)
+		assert.Equal(t, int64(0), stats.StageStats[0].DefaultedExecutions)
+		assert.Equal(t, int64(1), stats.StageStats[1].SuccessfulExecutions)
+	})
+
+	t.Run("UseDefault", func(t *testing.T) {
+		defaultDoc := map[string]interface{}{"title": "fallback"}
+		executor, registry := setup(t, ErrorPolicyUseDefault, defaultDoc)
+
+		for i := 0; i < 2; i++ {
+			output, err := executor.ExecutePipeline(context.Background(), "policy-pipeline", map[string]interface{}{"title": "doc"})
+			require.NoError(t, err)
+			result := output.(map[string]interface{})
+			assert.Equal(t, "fallback", result["title"])
+			assert.Equal(t, true, result["tagged"])
+		}
+
+		// Later stages must not mutate the configured default
+		assert.NotContains(t, defaultDoc, "tagged")
+
+		stats, err := registry.GetStats("policy-pipeline")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), stats.StageStats[0].DefaultedExecutions)
+		assert.Equal(t, int64(0), stats.StageStats[0].ContinuedExecutions)
+	})
+
+	t.Run("UseDefaultRequiresDefaultOutput", func(t *testing.T) {
+		registry := NewRegistry(logger)
+		err := registry.Register(&PipelineDefinition{
+			Name:    "missing-default",
+			Version: "1.0.0",
+			Type:    PipelineTypeDocument,
+			Stages: []StageDefinition{
+				{Name: "s", Type: StageTypeNative, Enabled: true, Config: map[string]interface{}{"function": "f"}, OnError: ErrorPolicyUseDefault},
+			},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "default_output")
+
+		err = registry.Register(&PipelineDefinition{
+			Name:    "bad-policy",
+			Version: "1.0.0",
+			Type:    PipelineTypeDocument,
+			Stages: []StageDefinition{
+				{Name: "s", Type: StageTypeNative, Enabled: true, Config: map[string]interface{}{"function": "f"}, OnError: "ignore"},
+			},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid on_error policy")
+	})
+}
+
+func TestExecutor_PipelineReferences(t *testing.T) {
+	logger := zap.NewNop()
+
+	refStage := func(name, target string) StageDefinition {
+		return StageDefinition{Name: name, Type: StageTypePipeline, Enabled: true, Config: map[string]interface{}{"pipeline": target}}
+	}
+	nativeStage := func(name string) StageDefinition {
+		return StageDefinition{Name: name, Type: StageTypeNative, Enabled: true, Config: map[string]interface{}{"function": name}}
+	}
+	setField := func(field string, value interface{}) *mockStage {
+		return &mockStage{
+			name:      field,
+			stageType: StageTypeNative,
+			executeFunc: func(ctx *StageContext, input interface{}) (interface{}, error) {
+				doc := input.(map[string]interface{})
+				doc[field] = value
+				return doc, nil
+			},
+		}
+	}
+
+	setup := func(t *testing.T) (*Executor, *Registry) {
+		registry := NewRegistry(logger)
+		executor := NewExecutor(registry, logger)
+
+		require.NoError(t, registry.Register(&PipelineDefinition{
+			Name:    "normalize",
+			Version: "1.0.0",
+			Type:    PipelineTypeDocument,
+			Stages:  []StageDefinition{nativeStage("normalized")},
+			Enabled: true,
+		}))
+		inner, err := registry.Get("normalize")
+		require.NoError(t, err)
+		inner.(*pipelineImpl).SetStages([]Stage{setField("normalized", true)})
+
+		require.NoError(t, registry.Register(&PipelineDefinition{
+			Name:    "ingest",
+			Version: "1.0.0",
+			Type:    PipelineTypeDocument,
+			Stages:  []StageDefinition{refStage("run-normalize", "normalize"), nativeStage("indexed")},
+			Enabled: true,
+		}))
+		outer, err := registry.Get("ingest")
+		require.NoError(t, err)
+		outer.(*pipelineImpl).SetStages([]Stage{registry.NewPipelineStage("run-normalize", "normalize"), setField("indexed", true)})
+
+		return executor, registry
+	}
+
+	t.Run("TwoLevelComposition", func(t *testing.T) {
+		executor, _ := setup(t)
+
+		output, err := executor.ExecutePipeline(context.Background(), "ingest", map[string]interface{}{"title": "doc"})
+		require.NoError(t, err)
+		result := output.(map[string]interface{})
+		assert.Equal(t, "doc", result["title"])
+		assert.Equal(t, true, result["normalized"])
+		assert.Equal(t, true, result["indexed"])
+	})
+
+	t.Run("RegistrationBuildsReferenceStages", func(t *testing.T) {
+		executor, registry := setup(t)
+
+		require.NoError(t, registry.Register(&PipelineDefinition{
+			Name:    "wrapper",
+			Version: "1.0.0",
+			Type:    PipelineTypeDocument,
+			Stages:  []StageDefinition{refStage("run-ingest", "ingest")},
+			Enabled: true,
+		}))
+
+		output, err := executor.ExecutePipeline(context.Background(), "wrapper", map[string]interface{}{"title": "doc"})
+		require.NoError(t, err)
+		assert.Equal(t, true, output.(map[string]interface{})["indexed"])
+	})
+
+	t.Run("RejectsInvalidReferences", func(t *testing.T) {
+		_, registry := setup(t)
+
+		err := registry.Register(&PipelineDefinition{
+			Name:    "dangling",
+			Version: "1.0.0",
+			Type:    PipelineTypeDocument,
+			Stages:  []StageDefinition{refStage("run-missing", "missing")},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "referenced pipeline 'missing' not found")
+
+		err = registry.Register(&PipelineDefinition{
+			Name:    "self",
+			Version: "1.0.0",
+			Type:    PipelineTypeDocument,
+			Stages:  []StageDefinition{refStage("run-self", "self")},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot reference itself")
+
+		err = registry.Register(&PipelineDefinition{
+			Name:    "query-wrapper",
+			Version: "1.0.0",
+			Type:    PipelineTypeQuery,
+			Stages:  []StageDefinition{refStage("run-ingest", "ingest")},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "expected 'query'")
+
+		err = registry.Unregister("normalize")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "still referenced by pipeline 'ingest'")
+	})
+
+	t.Run("RejectsCyclicUpdate", func(t *testing.T) {
+		_, registry := setup(t)
+
+		err := registry.Update(&PipelineDefinition{
+			Name:    "normalize",
+			Version: "2.0.0",
+			Type:    PipelineTypeDocument,
+			Stages:  []StageDefinition{refStage("run-ingest", "ingest")},
+		})
+		var validationErr *ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		assert.Equal(t, "pipeline reference cycle: normalize -> ingest -> normalize", validationErr.Message)
+
+		pipeline, err := registry.Get("normalize")
+		require.NoError(t, err)
+		assert.Equal(t, "1.0.0", pipeline.Version())
+	})
+
+	t.Run("DetectsCycleAtExecution", func(t *testing.T) {
+		executor, registry := setup(t)
+
+		// Bypass registration checks by wiring the cycle directly
+		inner, err := registry.Get("normalize")
+		require.NoError(t, err)
+		inner.(*pipelineImpl).SetStages([]Stage{registry.NewPipelineStage("normalized", "ingest")})
+
+		_, err = executor.ExecutePipeline(context.Background(), "ingest", map[string]interface{}{"title": "doc"})
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrPipelineCycle))
+	})
+}
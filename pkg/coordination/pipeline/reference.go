@@ -0,0 +1,92 @@
+// Copyright 2026 Quidditch Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPipelineCycle is returned when a pipeline ends up running itself
+// through pipeline reference stages
+var ErrPipelineCycle = errors.New("pipeline reference cycle")
+
+// callChainKey is the context key holding the names of the pipelines
+// currently executing, outermost first
+type callChainKey struct{}
+
+// callChain returns the pipelines already executing on ctx
+func callChain(ctx context.Context) []string {
+	chain, _ := ctx.Value(callChainKey{}).([]string)
+	return chain
+}
+
+// enterPipeline records name on the call chain, failing if it is already
+// executing further up the chain
+func enterPipeline(ctx context.Context, name string) (context.Context, error) {
+	chain := callChain(ctx)
+	for _, caller := range chain {
+		if caller == name {
+			return ctx, fmt.Errorf("%w: %s -> %s", ErrPipelineCycle, strings.Join(chain, " -> "), name)
+		}
+	}
+
+	next := make([]string, len(chain), len(chain)+1)
+	copy(next, chain)
+	return context.WithValue(ctx, callChainKey{}, append(next, name)), nil
+}
+
+// pipelineStage runs another registered pipeline inline
+type pipelineStage struct {
+	name     string
+	target   string
+	registry *Registry
+}
+
+// NewPipelineStage creates a stage that runs the registered pipeline target.
+// The target is resolved on every execution so it picks up updates.
+func (r *Registry) NewPipelineStage(name, target string) Stage {
+	return &pipelineStage{
+		name:     name,
+		target:   target,
+		registry: r,
+	}
+}
+
+// Name returns the stage identifier
+func (s *pipelineStage) Name() string {
+	return s.name
+}
+
+// Type returns the stage implementation type
+func (s *pipelineStage) Type() StageType {
+	return StageTypePipeline
+}
+
+// Config returns stage-specific configuration
+func (s *pipelineStage) Config() map[string]interface{} {
+	return map[string]interface{}{"pipeline": s.target}
+}
+
+// Execute runs the referenced pipeline on the input
+func (s *pipelineStage) Execute(ctx *StageContext, input interface{}) (interface{}, error) {
+	s.registry.mu.RLock()
+	impl, exists := s.registry.pipelines[s.target]
+	s.registry.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("referenced pipeline '%s' not found", s.target)
+	}
+
+	runCtx := ctx.Context
+	if runCtx == nil {
+		runCtx = context.Background()
+	}
+
+	output, _, err := impl.execute(runCtx, input, nil)
+	return output, err
+}
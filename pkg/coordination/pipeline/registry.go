@@ -6,13 +6,51 @@
 package pipeline
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.uber.org/zap"
 )
 
+// StageMetrics are the Prometheus metrics of pipeline stage execution
+type StageMetrics struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// NewStageMetrics creates the stage metrics and registers them with reg
+func NewStageMetrics(reg prometheus.Registerer) *StageMetrics {
+	factory := promauto.With(reg)
+	return &StageMetrics{
+		duration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "quidditch_pipeline_stage_duration_seconds",
+				Help:    "Time spent executing a pipeline stage",
+				Buckets: prometheus.ExponentialBuckets(0.0001, 2, 16), // 0.1ms to ~3s
+			},
+			[]string{"pipeline", "stage", "type"},
+		),
+		errors: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "quidditch_pipeline_stage_errors_total",
+				Help: "Total number of failed pipeline stage executions",
+			},
+			[]string{"pipeline", "stage", "type"},
+		),
+	}
+}
+
+// defaultStageMetrics are registered with the default Prometheus registry,
+// and shared by every registry that isn't given its own
+var defaultStageMetrics = NewStageMetrics(prometheus.DefaultRegisterer)
+
 // Registry manages pipeline registration and execution
 type Registry struct {
 	// pipelines maps pipeline name to implementation
@@ -27,45 +65,152 @@ type Registry struct {
 	// mu protects all maps
 	mu sync.RWMutex
 
+	// writeMu serializes changes, so the checks a change passed still hold
+	// once it is persisted, which happens without holding mu
+	writeMu sync.Mutex
+
+	// udfResolver verifies UDF references at registration (optional)
+	udfResolver UDFResolver
+
+	// stageFactory builds executable stages at registration (optional)
+	stageFactory StageFactory
+
+	// store persists pipelines and associations (optional)
+	store Store
+
+	// unrestored holds the stored pipelines Restore couldn't register
+	unrestored []UnrestoredPipeline
+
+	// stageMetrics exports stage executions to Prometheus
+	stageMetrics *StageMetrics
+
 	logger *zap.Logger
 }
 
+// UDFResolver resolves the UDFs referenced by pipeline stages
+type UDFResolver interface {
+	// ResolveVersion returns the concrete version for name@version, resolving
+	// an empty version or "latest" to the most recently registered version
+	ResolveVersion(name, version string) (string, error)
+}
+
+// StageFactory builds executable stages from their definitions
+type StageFactory interface {
+	BuildStages(defs []StageDefinition) ([]Stage, error)
+}
+
+// ErrNoStageBuilder is returned by a StageFactory for stage types it has no
+// builder for. Pipelines with such stages still register, and their stages
+// are attached later with PipelineImpl.SetStages.
+var ErrNoStageBuilder = errors.New("no builder for stage type")
+
 // NewRegistry creates a new pipeline registry
 func NewRegistry(logger *zap.Logger) *Registry {
 	return &Registry{
 		pipelines:      make(map[string]*pipelineImpl),
 		indexPipelines: make(map[string]map[PipelineType]string),
 		stats:          make(map[string]*PipelineStats),
+		stageMetrics:   defaultStageMetrics,
 		logger:         logger,
 	}
 }
 
+// SetStageMetrics exports stage executions to metrics instead of the
+// metrics registered with the default Prometheus registry
+func (r *Registry) SetStageMetrics(metrics *StageMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stageMetrics = metrics
+}
+
+// SetUDFResolver enables UDF dependency checks at registration time.
+// Without a resolver, stage UDF references are only checked at execution.
+func (r *Registry) SetUDFResolver(resolver UDFResolver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.udfResolver = resolver
+}
+
+// SetStageFactory makes Register build executable stages for each pipeline.
+// Without a factory, stages must be attached with PipelineImpl.SetStages.
+func (r *Registry) SetStageFactory(factory StageFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stageFactory = factory
+}
+
 // Register registers a new pipeline
 func (r *Registry) Register(def *PipelineDefinition) error {
+	return r.register(def, false)
+}
+
+// register registers a new pipeline. A restored pipeline keeps its revision
+// and timestamps and isn't saved to the store again.
+func (r *Registry) register(def *PipelineDefinition, restored bool) error {
 	if err := r.validatePipeline(def); err != nil {
 		return err
 	}
 
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	if err := r.resolveUDFDependencies(def); err != nil {
+		return err
+	}
+
+	stages, err := r.buildStages(def)
+	if err != nil {
+		return err
+	}
+
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	r.mu.RLock()
+	_, exists := r.pipelines[def.Name]
+	err = r.validatePipelineReferences(def)
+	store := r.store
+	r.mu.RUnlock()
 
 	// Check if pipeline already exists
-	if _, exists := r.pipelines[def.Name]; exists {
+	if exists {
 		return &ValidationError{
 			Field:   "name",
 			Message: fmt.Sprintf("pipeline '%s' already exists", def.Name),
 		}
 	}
+	if err != nil {
+		return err
+	}
+
+	if !restored {
+		now := time.Now()
+		def.Created = now
+		def.Updated = now
+		def.Revision = 1
+
+		if store != nil {
+			if err := store.SavePipeline(def); err != nil {
+				return fmt.Errorf("failed to persist pipeline '%s': %w", def.Name, err)
+			}
+		}
+	}
 
 	// Create pipeline implementation
 	impl := &pipelineImpl{
 		def:    def,
-		stages: []Stage{}, // Will be populated when stages are created
+		stages: stages,
 		logger: r.logger.With(zap.String("pipeline", def.Name)),
 	}
 
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	// Store pipeline
 	r.pipelines[def.Name] = impl
+	r.unrestored = slices.DeleteFunc(r.unrestored, func(u UnrestoredPipeline) bool {
+		return u.Name == def.Name
+	})
 
 	// Initialize statistics
 	r.stats[def.Name] = &PipelineStats{
@@ -78,11 +223,6 @@ func (r *Registry) Register(def *PipelineDefinition) error {
 		P99Duration:     0,
 	}
 
-	// Set timestamps
-	now := time.Now()
-	def.Created = now
-	def.Updated = now
-
 	r.logger.Info("Pipeline registered",
 		zap.String("name", def.Name),
 		zap.String("version", def.Version),
@@ -92,6 +232,108 @@ func (r *Registry) Register(def *PipelineDefinition) error {
 	return nil
 }
 
+// Update replaces the definition of an existing pipeline. The new definition
+// is validated and its stages are built before anything is swapped, so a
+// failed update leaves the current pipeline untouched. Index associations are
+// preserved, pipeline-level statistics are kept and stage statistics are
+// carried over for stages whose name didn't change.
+func (r *Registry) Update(def *PipelineDefinition) error {
+	if err := r.validatePipeline(def); err != nil {
+		return err
+	}
+
+	if err := r.resolveUDFDependencies(def); err != nil {
+		return err
+	}
+
+	stages, err := r.buildStages(def)
+	if err != nil {
+		return err
+	}
+
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	r.mu.RLock()
+	existing, err := r.checkUpdate(def)
+	store := r.store
+	r.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	def.Created = existing.def.Created
+	def.Updated = time.Now()
+	def.Revision = existing.def.Revision + 1
+
+	if store != nil {
+		if err := store.SavePipeline(def); err != nil {
+			return fmt.Errorf("failed to persist pipeline '%s': %w", def.Name, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pipelines[def.Name] = &pipelineImpl{
+		def:    def,
+		stages: stages,
+		logger: r.logger.With(zap.String("pipeline", def.Name)),
+	}
+
+	// Keep stage statistics for stages that survived the update
+	if stats, ok := r.stats[def.Name]; ok {
+		previous := make(map[string]StageStats, len(stats.StageStats))
+		for _, stageStat := range stats.StageStats {
+			previous[stageStat.Name] = stageStat
+		}
+		merged := make([]StageStats, len(def.Stages))
+		for i, stage := range def.Stages {
+			if stageStat, ok := previous[stage.Name]; ok {
+				merged[i] = stageStat
+			}
+		}
+		stats.StageStats = merged
+	}
+
+	r.logger.Info("Pipeline updated",
+		zap.String("name", def.Name),
+		zap.String("version", def.Version),
+		zap.Int64("revision", def.Revision),
+		zap.Int("stages", len(def.Stages)))
+
+	return nil
+}
+
+// checkUpdate returns the pipeline an update replaces, checking the new
+// definition against the registry. Caller must hold the registry lock.
+func (r *Registry) checkUpdate(def *PipelineDefinition) (*pipelineImpl, error) {
+	existing, exists := r.pipelines[def.Name]
+	if !exists {
+		return nil, fmt.Errorf("pipeline '%s' not found", def.Name)
+	}
+
+	if err := r.validatePipelineReferences(def); err != nil {
+		return nil, err
+	}
+
+	// Associations are type-checked, so the type can't change under them
+	if def.Type != existing.def.Type {
+		for indexName, pipelineMap := range r.indexPipelines {
+			for _, pipelineName := range pipelineMap {
+				if pipelineName == def.Name {
+					return nil, &ValidationError{
+						Field: "type",
+						Message: fmt.Sprintf("cannot change type of pipeline '%s' from '%s' to '%s': still associated with index '%s'",
+							def.Name, existing.def.Type, def.Type, indexName),
+					}
+				}
+			}
+		}
+	}
+	return existing, nil
+}
+
 // Get retrieves a pipeline by name
 func (r *Registry) Get(name string) (Pipeline, error) {
 	r.mu.RLock()
@@ -128,9 +370,37 @@ func (r *Registry) List(filterType PipelineType) []*PipelineDefinition {
 
 // Unregister removes a pipeline from the registry
 func (r *Registry) Unregister(name string) error {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	r.mu.RLock()
+	err := r.checkUnregister(name)
+	store := r.store
+	r.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if store != nil {
+		if err := store.DeletePipeline(name); err != nil {
+			return fmt.Errorf("failed to delete persisted pipeline '%s': %w", name, err)
+		}
+	}
+
 	r.mu.Lock()
-	defer r.mu.Unlock()
+	// Remove pipeline
+	delete(r.pipelines, name)
+	delete(r.stats, name)
+	r.mu.Unlock()
+
+	r.logger.Info("Pipeline unregistered", zap.String("name", name))
+
+	return nil
+}
 
+// checkUnregister checks that a pipeline exists and nothing uses it.
+// Caller must hold the registry lock.
+func (r *Registry) checkUnregister(name string) error {
 	// Check if pipeline exists
 	if _, exists := r.pipelines[name]; !exists {
 		return fmt.Errorf("pipeline '%s' not found", name)
@@ -146,22 +416,36 @@ func (r *Registry) Unregister(name string) error {
 		}
 	}
 
-	// Remove pipeline
-	delete(r.pipelines, name)
-	delete(r.stats, name)
-
-	r.logger.Info("Pipeline unregistered", zap.String("name", name))
+	// Check if pipeline is referenced by another pipeline
+	for _, impl := range r.pipelines {
+		for i := range impl.def.Stages {
+			if referencedPipeline(&impl.def.Stages[i]) == name {
+				return fmt.Errorf("cannot delete pipeline '%s': still referenced by pipeline '%s'",
+					name, impl.def.Name)
+			}
+		}
+	}
 
 	return nil
 }
 
 // AssociatePipeline associates a pipeline with an index for a specific type
 func (r *Registry) AssociatePipeline(indexName string, pipelineType PipelineType, pipelineName string) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	return r.associate(indexName, pipelineType, pipelineName, true)
+}
 
-	// Verify pipeline exists
+// associate associates a pipeline with an index, saving the association to
+// the store if persist is set
+func (r *Registry) associate(indexName string, pipelineType PipelineType, pipelineName string, persist bool) error {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	r.mu.RLock()
 	impl, exists := r.pipelines[pipelineName]
+	store := r.store
+	r.mu.RUnlock()
+
+	// Verify pipeline exists
 	if !exists {
 		return fmt.Errorf("pipeline '%s' not found", pipelineName)
 	}
@@ -175,6 +459,15 @@ func (r *Registry) AssociatePipeline(indexName string, pipelineType PipelineType
 		}
 	}
 
+	if persist && store != nil {
+		if err := store.SaveAssociation(indexName, pipelineType, pipelineName); err != nil {
+			return fmt.Errorf("failed to persist pipeline association: %w", err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	// Initialize index pipeline map if needed
 	if r.indexPipelines[indexName] == nil {
 		r.indexPipelines[indexName] = make(map[PipelineType]string)
@@ -273,6 +566,8 @@ func (r *Registry) UpdateStats(name string, duration time.Duration, success bool
 	stats.P95Duration = time.Duration(float64(stats.AverageDuration) * 1.5)
 	stats.P99Duration = time.Duration(float64(stats.AverageDuration) * 2.0)
 
+	r.observeStageMetrics(name, stageStats)
+
 	// Update stage statistics
 	for i, stageStat := range stageStats {
 		if i >= len(stats.StageStats) {
@@ -288,6 +583,8 @@ func (r *Registry) UpdateStats(name string, duration time.Duration, success bool
 				existing.FailedExecutions++
 				existing.LastError = stageStat.LastError
 			}
+			existing.ContinuedExecutions += stageStat.ContinuedExecutions
+			existing.DefaultedExecutions += stageStat.DefaultedExecutions
 
 			// Update average duration
 			if existing.TotalExecutions == 1 {
@@ -300,6 +597,134 @@ func (r *Registry) UpdateStats(name string, duration time.Duration, success bool
 	}
 }
 
+// observeStageMetrics exports the stages of one pipeline execution to
+// Prometheus. Caller must hold the registry lock.
+func (r *Registry) observeStageMetrics(name string, stageStats []StageStats) {
+	stageTypes := make(map[string]StageType)
+	if impl, ok := r.pipelines[name]; ok {
+		for _, stage := range impl.def.Stages {
+			stageTypes[stage.Name] = stage.Type
+		}
+	}
+
+	for _, stageStat := range stageStats {
+		labels := prometheus.Labels{
+			"pipeline": name,
+			"stage":    stageStat.Name,
+			"type":     string(stageTypes[stageStat.Name]),
+		}
+		r.stageMetrics.duration.With(labels).Observe(stageStat.AverageDuration.Seconds())
+		if stageStat.FailedExecutions > 0 {
+			r.stageMetrics.errors.With(labels).Inc()
+		}
+	}
+}
+
+// buildStages creates the pipeline's stages with the configured factory.
+// Pipeline reference stages are always built by the registry itself.
+func (r *Registry) buildStages(def *PipelineDefinition) ([]Stage, error) {
+	r.mu.RLock()
+	factory := r.stageFactory
+	r.mu.RUnlock()
+
+	var factoryDefs []StageDefinition
+	for _, stage := range def.Stages {
+		if stage.Type != StageTypePipeline {
+			factoryDefs = append(factoryDefs, stage)
+		}
+	}
+
+	var built []Stage
+	if len(factoryDefs) > 0 {
+		if factory == nil {
+			return []Stage{}, nil
+		}
+
+		var err error
+		built, err = factory.BuildStages(factoryDefs)
+		if errors.Is(err, ErrNoStageBuilder) {
+			return []Stage{}, nil
+		}
+		if err != nil {
+			return nil, &ValidationError{Field: "stages", Message: err.Error()}
+		}
+	}
+
+	stages := make([]Stage, 0, len(def.Stages))
+	for i := range def.Stages {
+		if target := referencedPipeline(&def.Stages[i]); target != "" {
+			stages = append(stages, r.NewPipelineStage(def.Stages[i].Name, target))
+			continue
+		}
+		stages = append(stages, built[0])
+		built = built[1:]
+	}
+
+	return stages, nil
+}
+
+// resolveUDFDependencies verifies that every UDF referenced by the pipeline's
+// stages is registered, and pins "latest" (or omitted) versions to the
+// concrete version resolved now so later UDF uploads don't change behavior.
+func (r *Registry) resolveUDFDependencies(def *PipelineDefinition) error {
+	r.mu.RLock()
+	resolver := r.udfResolver
+	r.mu.RUnlock()
+
+	if resolver == nil {
+		return nil
+	}
+
+	var missing []string
+	for _, stage := range def.Stages {
+		missing = append(missing, resolveStageUDF(resolver, stage.Config)...)
+
+		// Composite stages carry their nested stage definitions in config
+		if stage.Type == StageTypeComposite {
+			nested, _ := stage.Config["stages"].([]interface{})
+			for _, n := range nested {
+				nestedStage, ok := n.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				nestedConfig, _ := nestedStage["config"].(map[string]interface{})
+				missing = append(missing, resolveStageUDF(resolver, nestedConfig)...)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return &ValidationError{
+			Field:   "stages",
+			Message: fmt.Sprintf("referenced UDFs not found: %s", strings.Join(missing, ", ")),
+		}
+	}
+
+	return nil
+}
+
+// resolveStageUDF resolves the UDF referenced by a stage config, if any, and
+// returns the unresolvable reference as "name@version"
+func resolveStageUDF(resolver UDFResolver, config map[string]interface{}) []string {
+	udfName, ok := config["udf_name"].(string)
+	if !ok {
+		return nil
+	}
+
+	udfVersion, _ := config["udf_version"].(string)
+	resolved, err := resolver.ResolveVersion(udfName, udfVersion)
+	if err != nil {
+		if udfVersion == "" {
+			udfVersion = "latest"
+		}
+		return []string{fmt.Sprintf("%s@%s", udfName, udfVersion)}
+	}
+
+	config["udf_version"] = resolved
+	return nil
+}
+
 // validatePipeline validates a pipeline definition
 func (r *Registry) validatePipeline(def *PipelineDefinition) error {
 	// Validate required fields
@@ -376,15 +801,34 @@ func (r *Registry) validateStage(stage *StageDefinition, index int, stageNames m
 		StageTypePython:    true,
 		StageTypeNative:    true,
 		StageTypeComposite: true,
+		StageTypePipeline:  true,
 	}
 	if !validStageTypes[stage.Type] {
 		return &ValidationError{
 			Field: fmt.Sprintf("stages[%d].type", index),
-			Message: fmt.Sprintf("invalid stage type '%s', must be one of: python, native, composite",
+			Message: fmt.Sprintf("invalid stage type '%s', must be one of: python, native, composite, pipeline",
 				stage.Type),
 		}
 	}
 
+	// Validate error policy
+	switch stage.OnError {
+	case "", ErrorPolicyFail, ErrorPolicyContinue:
+	case ErrorPolicyUseDefault:
+		if stage.DefaultOutput == nil {
+			return &ValidationError{
+				Field:   fmt.Sprintf("stages[%d].default_output", index),
+				Message: "default_output is required when on_error is use_default",
+			}
+		}
+	default:
+		return &ValidationError{
+			Field: fmt.Sprintf("stages[%d].on_error", index),
+			Message: fmt.Sprintf("invalid on_error policy '%s', must be one of: fail, continue, use_default",
+				stage.OnError),
+		}
+	}
+
 	// Validate config
 	if stage.Config == nil {
 		return &ValidationError{
@@ -407,6 +851,10 @@ func (r *Registry) validateStage(stage *StageDefinition, index int, stageNames m
 		if err := r.validateCompositeStageConfig(stage.Config, index); err != nil {
 			return err
 		}
+	case StageTypePipeline:
+		if err := r.validatePipelineStageConfig(stage.Config, index); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -414,12 +862,31 @@ func (r *Registry) validateStage(stage *StageDefinition, index int, stageNames m
 
 // validatePythonStageConfig validates Python stage configuration
 func (r *Registry) validatePythonStageConfig(config map[string]interface{}, index int) error {
+	// Inline scripts run in the Python worker pool instead of a WASM UDF
+	if script, ok := config["script"]; ok {
+		if s, ok := script.(string); !ok || s == "" {
+			return &ValidationError{
+				Field:   fmt.Sprintf("stages[%d].config.script", index),
+				Message: "script must be a non-empty string",
+			}
+		}
+		if function, ok := config["function"]; ok {
+			if _, ok := function.(string); !ok {
+				return &ValidationError{
+					Field:   fmt.Sprintf("stages[%d].config.function", index),
+					Message: "function must be a string",
+				}
+			}
+		}
+		return nil
+	}
+
 	// udf_name is required
 	udfName, ok := config["udf_name"]
 	if !ok {
 		return &ValidationError{
 			Field:   fmt.Sprintf("stages[%d].config.udf_name", index),
-			Message: "udf_name is required for python stages",
+			Message: "udf_name is required for python stages without an inline script",
 		}
 	}
 
@@ -506,21 +973,138 @@ func (r *Registry) validateCompositeStageConfig(config map[string]interface{}, i
 	return nil
 }
 
+// validatePipelineStageConfig validates pipeline reference stage configuration
+func (r *Registry) validatePipelineStageConfig(config map[string]interface{}, index int) error {
+	// pipeline is required
+	target, ok := config["pipeline"]
+	if !ok {
+		return &ValidationError{
+			Field:   fmt.Sprintf("stages[%d].config.pipeline", index),
+			Message: "pipeline is required for pipeline stages",
+		}
+	}
+
+	// Verify it's a non-empty string
+	if name, ok := target.(string); !ok || name == "" {
+		return &ValidationError{
+			Field:   fmt.Sprintf("stages[%d].config.pipeline", index),
+			Message: "pipeline must be a non-empty string",
+		}
+	}
+
+	return nil
+}
+
+// validatePipelineReferences checks that every pipeline referenced by def
+// exists, has the same type and doesn't lead back to def. Caller must hold
+// the registry lock.
+func (r *Registry) validatePipelineReferences(def *PipelineDefinition) error {
+	for i, stage := range def.Stages {
+		target := referencedPipeline(&stage)
+		if target == "" {
+			continue
+		}
+
+		field := fmt.Sprintf("stages[%d].config.pipeline", i)
+
+		if target == def.Name {
+			return &ValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("pipeline '%s' cannot reference itself", def.Name),
+			}
+		}
+
+		impl, exists := r.pipelines[target]
+		if !exists {
+			return &ValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("referenced pipeline '%s' not found", target),
+			}
+		}
+
+		if impl.def.Type != def.Type {
+			return &ValidationError{
+				Field: field,
+				Message: fmt.Sprintf("referenced pipeline '%s' is type '%s', expected '%s'",
+					target, impl.def.Type, def.Type),
+			}
+		}
+
+		if path := r.findReferencePath(target, def.Name, []string{def.Name, target}); path != nil {
+			return &ValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("pipeline reference cycle: %s", strings.Join(path, " -> ")),
+			}
+		}
+	}
+
+	return nil
+}
+
+// findReferencePath returns the chain of references from pipeline from to
+// pipeline to, or nil if to isn't reachable. Caller must hold the registry lock.
+func (r *Registry) findReferencePath(from, to string, path []string) []string {
+	impl, exists := r.pipelines[from]
+	if !exists {
+		return nil
+	}
+
+	for i := range impl.def.Stages {
+		target := referencedPipeline(&impl.def.Stages[i])
+		if target == "" {
+			continue
+		}
+		if target == to {
+			return append(path, target)
+		}
+		// Existing references are acyclic, so this terminates
+		if found := r.findReferencePath(target, to, append(path, target)); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// referencedPipeline returns the pipeline a pipeline stage runs, or "" for
+// other stage types
+func referencedPipeline(stage *StageDefinition) string {
+	if stage.Type != StageTypePipeline {
+		return ""
+	}
+	name, _ := stage.Config["pipeline"].(string)
+	return name
+}
+
 // DisassociatePipeline removes a pipeline association from an index
 func (r *Registry) DisassociatePipeline(indexName string, pipelineType PipelineType) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
 
+	r.mu.RLock()
 	pipelineMap, exists := r.indexPipelines[indexName]
+	_, associated := pipelineMap[pipelineType]
+	store := r.store
+	r.mu.RUnlock()
+
 	if !exists {
 		return fmt.Errorf("no pipelines configured for index '%s'", indexName)
 	}
 
-	if _, exists := pipelineMap[pipelineType]; !exists {
+	if !associated {
 		return fmt.Errorf("no pipeline configured for index '%s' and type '%s'",
 			indexName, pipelineType)
 	}
 
+	if store != nil {
+		if err := store.SaveAssociation(indexName, pipelineType, ""); err != nil {
+			return fmt.Errorf("failed to persist pipeline association: %w", err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	delete(pipelineMap, pipelineType)
 
 	// Clean up empty map
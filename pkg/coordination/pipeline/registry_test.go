@@ -6,9 +6,13 @@
 package pipeline
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -532,6 +536,85 @@ func TestRegistry_AssociatePipeline(t *testing.T) {
 	})
 }
 
+func TestRegistry_Update(t *testing.T) {
+	logger := zap.NewNop()
+	registry := NewRegistry(logger)
+
+	newDef := func(version string, typ PipelineType, stageNames ...string) *PipelineDefinition {
+		def := &PipelineDefinition{Name: "update-pipeline", Version: version, Type: typ, Enabled: true}
+		for _, name := range stageNames {
+			def.Stages = append(def.Stages, StageDefinition{
+				Name:    name,
+				Type:    StageTypeNative,
+				Enabled: true,
+				Config:  map[string]interface{}{"function": name},
+			})
+		}
+		return def
+	}
+
+	original := newDef("1.0.0", PipelineTypeQuery, "synonyms", "boost")
+	require.NoError(t, registry.Register(original))
+	assert.Equal(t, int64(1), original.Revision)
+	require.NoError(t, registry.AssociatePipeline("products", PipelineTypeQuery, "update-pipeline"))
+
+	registry.UpdateStats("update-pipeline", 10*time.Millisecond, true, []StageStats{
+		{Name: "synonyms", TotalExecutions: 1, SuccessfulExecutions: 1},
+		{Name: "boost", TotalExecutions: 1, SuccessfulExecutions: 1},
+	})
+
+	t.Run("UpdateAssociatedPipeline", func(t *testing.T) {
+		updated := newDef("1.1.0", PipelineTypeQuery, "boost", "rewrite")
+		require.NoError(t, registry.Update(updated))
+
+		assert.Equal(t, int64(2), updated.Revision)
+		assert.Equal(t, original.Created, updated.Created)
+		assert.True(t, !updated.Updated.Before(original.Updated))
+
+		// Association now resolves to the new definition
+		pipeline, err := registry.GetPipelineForIndex("products", PipelineTypeQuery)
+		require.NoError(t, err)
+		assert.Equal(t, "1.1.0", pipeline.Version())
+
+		// Pipeline totals are kept; stage stats follow stage names
+		stats, err := registry.GetStats("update-pipeline")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), stats.TotalExecutions)
+		require.Len(t, stats.StageStats, 2)
+		assert.Equal(t, "boost", stats.StageStats[0].Name)
+		assert.Equal(t, int64(1), stats.StageStats[0].SuccessfulExecutions)
+		assert.Equal(t, int64(0), stats.StageStats[1].TotalExecutions)
+	})
+
+	t.Run("InvalidUpdateKeepsCurrentDefinition", func(t *testing.T) {
+		invalid := newDef("2.0.0", PipelineTypeQuery)
+		err := registry.Update(invalid)
+		require.Error(t, err)
+
+		pipeline, err := registry.Get("update-pipeline")
+		require.NoError(t, err)
+		assert.Equal(t, "1.1.0", pipeline.Version())
+	})
+
+	t.Run("TypeChangeWhileAssociated", func(t *testing.T) {
+		err := registry.Update(newDef("2.0.0", PipelineTypeDocument, "boost"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "still associated with index 'products'")
+
+		pipeline, err := registry.GetPipelineForIndex("products", PipelineTypeQuery)
+		require.NoError(t, err)
+		assert.Equal(t, PipelineTypeQuery, pipeline.Type())
+	})
+
+	t.Run("NonExistentPipeline", func(t *testing.T) {
+		def := newDef("1.0.0", PipelineTypeQuery, "boost")
+		def.Name = "does-not-exist"
+		err := registry.Update(def)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
+
 func TestRegistry_GetPipelineForIndex(t *testing.T) {
 	logger := zap.NewNop()
 	registry := NewRegistry(logger)
@@ -578,6 +661,120 @@ func TestRegistry_GetPipelineForIndex(t *testing.T) {
 	})
 }
 
+// fakeUDFResolver resolves against a fixed name -> versions table, where the
+// last listed version is the latest
+type fakeUDFResolver map[string][]string
+
+func (f fakeUDFResolver) ResolveVersion(name, version string) (string, error) {
+	versions := f[name]
+	if len(versions) == 0 {
+		return "", fmt.Errorf("UDF %s not found", name)
+	}
+	if version == "" || version == "latest" {
+		return versions[len(versions)-1], nil
+	}
+	for _, v := range versions {
+		if v == version {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("UDF %s@%s not found", name, version)
+}
+
+func TestRegistry_UDFDependencies(t *testing.T) {
+	registry := NewRegistry(zap.NewNop())
+	registry.SetUDFResolver(fakeUDFResolver{
+		"synonyms": {"1.0.0", "2.0.0"},
+	})
+
+	pythonStage := func(name string, config map[string]interface{}) StageDefinition {
+		return StageDefinition{Name: name, Type: StageTypePython, Enabled: true, Config: config}
+	}
+
+	t.Run("MissingUDF", func(t *testing.T) {
+		def := &PipelineDefinition{
+			Name:    "missing-udf",
+			Version: "1.0.0",
+			Type:    PipelineTypeQuery,
+			Stages: []StageDefinition{
+				pythonStage("ok", map[string]interface{}{"udf_name": "synonyms", "udf_version": "1.0.0"}),
+				pythonStage("unknown", map[string]interface{}{"udf_name": "spellcheck"}),
+				pythonStage("bad-version", map[string]interface{}{"udf_name": "synonyms", "udf_version": "3.0.0"}),
+			},
+		}
+
+		err := registry.Register(def)
+		require.Error(t, err)
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "stages", validationErr.Field)
+		assert.Contains(t, err.Error(), "spellcheck@latest")
+		assert.Contains(t, err.Error(), "synonyms@3.0.0")
+		assert.NotContains(t, err.Error(), "synonyms@1.0.0")
+
+		_, err = registry.Get("missing-udf")
+		assert.Error(t, err)
+	})
+
+	t.Run("PinnedVersion", func(t *testing.T) {
+		def := &PipelineDefinition{
+			Name:    "pinned",
+			Version: "1.0.0",
+			Type:    PipelineTypeQuery,
+			Stages: []StageDefinition{
+				pythonStage("stage1", map[string]interface{}{"udf_name": "synonyms", "udf_version": "1.0.0"}),
+			},
+		}
+
+		require.NoError(t, registry.Register(def))
+		assert.Equal(t, "1.0.0", def.Stages[0].Config["udf_version"])
+	})
+
+	t.Run("LatestAlias", func(t *testing.T) {
+		def := &PipelineDefinition{
+			Name:    "latest",
+			Version: "1.0.0",
+			Type:    PipelineTypeQuery,
+			Stages: []StageDefinition{
+				pythonStage("explicit", map[string]interface{}{"udf_name": "synonyms", "udf_version": "latest"}),
+				pythonStage("omitted", map[string]interface{}{"udf_name": "synonyms"}),
+			},
+		}
+
+		require.NoError(t, registry.Register(def))
+		assert.Equal(t, "2.0.0", def.Stages[0].Config["udf_version"])
+		assert.Equal(t, "2.0.0", def.Stages[1].Config["udf_version"])
+	})
+
+	t.Run("NestedCompositeStage", func(t *testing.T) {
+		def := &PipelineDefinition{
+			Name:    "composite",
+			Version: "1.0.0",
+			Type:    PipelineTypeQuery,
+			Stages: []StageDefinition{
+				{
+					Name:    "group",
+					Type:    StageTypeComposite,
+					Enabled: true,
+					Config: map[string]interface{}{
+						"stages": []interface{}{
+							map[string]interface{}{
+								"name":   "inner",
+								"type":   "python",
+								"config": map[string]interface{}{"udf_name": "missing_inner"},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		err := registry.Register(def)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing_inner@latest")
+	})
+}
+
 func TestRegistry_Stats(t *testing.T) {
 	logger := zap.NewNop()
 	registry := NewRegistry(logger)
@@ -673,6 +870,74 @@ func TestRegistry_Stats(t *testing.T) {
 	})
 }
 
+func TestRegistry_StageMetrics(t *testing.T) {
+	logger := zap.NewNop()
+	registry := NewRegistry(logger)
+	reg := prometheus.NewRegistry()
+	registry.SetStageMetrics(NewStageMetrics(reg))
+
+	def := &PipelineDefinition{
+		Name:    "metrics-pipeline",
+		Version: "1.0.0",
+		Type:    PipelineTypeDocument,
+		Stages: []StageDefinition{
+			{Name: "enrich", Type: StageTypeNative, Enabled: true, Config: map[string]interface{}{"function": "enrich"}},
+			{Name: "classify", Type: StageTypePython, Enabled: true, Config: map[string]interface{}{"udf_name": "classify"}},
+		},
+	}
+	require.NoError(t, registry.Register(def))
+
+	// Simulate three executions where the python stage fails once
+	for i := 0; i < 3; i++ {
+		classify := StageStats{Name: "classify", TotalExecutions: 1, SuccessfulExecutions: 1, AverageDuration: 20 * time.Millisecond}
+		if i == 0 {
+			classify = StageStats{Name: "classify", TotalExecutions: 1, FailedExecutions: 1, AverageDuration: 5 * time.Millisecond}
+		}
+		registry.UpdateStats("metrics-pipeline", 30*time.Millisecond, i != 0, []StageStats{
+			{Name: "enrich", TotalExecutions: 1, SuccessfulExecutions: 1, AverageDuration: 2 * time.Millisecond},
+			classify,
+		})
+	}
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	// findMetric returns the series of the named family for one stage
+	findMetric := func(name, stage, stageType string) *dto.Metric {
+		for _, family := range families {
+			if family.GetName() != name {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				labels := make(map[string]string)
+				for _, label := range metric.GetLabel() {
+					labels[label.GetName()] = label.GetValue()
+				}
+				if labels["pipeline"] == "metrics-pipeline" && labels["stage"] == stage && labels["type"] == stageType {
+					return metric
+				}
+			}
+		}
+		return nil
+	}
+
+	enrichDuration := findMetric("quidditch_pipeline_stage_duration_seconds", "enrich", "native")
+	require.NotNil(t, enrichDuration)
+	assert.Equal(t, uint64(3), enrichDuration.GetHistogram().GetSampleCount())
+	assert.InDelta(t, 0.006, enrichDuration.GetHistogram().GetSampleSum(), 1e-9)
+
+	classifyDuration := findMetric("quidditch_pipeline_stage_duration_seconds", "classify", "python")
+	require.NotNil(t, classifyDuration)
+	assert.Equal(t, uint64(3), classifyDuration.GetHistogram().GetSampleCount())
+
+	classifyErrors := findMetric("quidditch_pipeline_stage_errors_total", "classify", "python")
+	require.NotNil(t, classifyErrors)
+	assert.Equal(t, float64(1), classifyErrors.GetCounter().GetValue())
+
+	// Stages that never failed have no error series
+	assert.Nil(t, findMetric("quidditch_pipeline_stage_errors_total", "enrich", "native"))
+}
+
 func TestRegistry_DisassociatePipeline(t *testing.T) {
 	logger := zap.NewNop()
 	registry := NewRegistry(logger)
@@ -712,3 +977,213 @@ func TestRegistry_DisassociatePipeline(t *testing.T) {
 		assert.Contains(t, err.Error(), "no pipelines configured")
 	})
 }
+
+// memoryStore is a Store keeping serialized definitions, as a real store
+// would, so restored pipelines don't share memory with registered ones
+type memoryStore struct {
+	pipelines    map[string][]byte
+	associations map[string]IndexAssociation
+	order        []string
+	fail         error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		pipelines:    make(map[string][]byte),
+		associations: make(map[string]IndexAssociation),
+	}
+}
+
+func (s *memoryStore) SavePipeline(def *PipelineDefinition) error {
+	if s.fail != nil {
+		return s.fail
+	}
+	data, err := json.Marshal(def)
+	if err != nil {
+		return err
+	}
+	if _, exists := s.pipelines[def.Name]; !exists {
+		s.order = append(s.order, def.Name)
+	}
+	s.pipelines[def.Name] = data
+	return nil
+}
+
+func (s *memoryStore) DeletePipeline(name string) error {
+	if s.fail != nil {
+		return s.fail
+	}
+	delete(s.pipelines, name)
+	return nil
+}
+
+func (s *memoryStore) SaveAssociation(indexName string, pipelineType PipelineType, pipelineName string) error {
+	if s.fail != nil {
+		return s.fail
+	}
+	key := indexName + "/" + string(pipelineType)
+	if pipelineName == "" {
+		delete(s.associations, key)
+		return nil
+	}
+	s.associations[key] = IndexAssociation{IndexName: indexName, Type: pipelineType, Pipeline: pipelineName}
+	return nil
+}
+
+func (s *memoryStore) Load() (*StoredPipelines, error) {
+	stored := &StoredPipelines{}
+	// Newest first, so referencing pipelines load before their targets
+	for i := len(s.order) - 1; i >= 0; i-- {
+		data, exists := s.pipelines[s.order[i]]
+		if !exists {
+			continue
+		}
+		var def PipelineDefinition
+		if err := json.Unmarshal(data, &def); err != nil {
+			return nil, err
+		}
+		stored.Pipelines = append(stored.Pipelines, &def)
+	}
+	for _, association := range s.associations {
+		stored.Associations = append(stored.Associations, association)
+	}
+	return stored, nil
+}
+
+// blockingStore is a Store whose saves wait to be released
+type blockingStore struct {
+	Store
+	saving  chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStore) SavePipeline(def *PipelineDefinition) error {
+	close(s.saving)
+	<-s.release
+	return s.Store.SavePipeline(def)
+}
+
+func TestRegistry_Restore(t *testing.T) {
+	logger := zap.NewNop()
+	store := newMemoryStore()
+
+	registry := NewRegistry(logger)
+	registry.SetStore(store)
+
+	newDef := func(name string, stage StageDefinition) *PipelineDefinition {
+		return &PipelineDefinition{
+			Name:    name,
+			Version: "1.0.0",
+			Type:    PipelineTypeQuery,
+			Stages:  []StageDefinition{stage},
+			Enabled: true,
+		}
+	}
+	pythonStage := StageDefinition{
+		Name:    "rewrite",
+		Type:    StageTypePython,
+		Enabled: true,
+		Config:  map[string]interface{}{"udf_name": "rewrite_udf"},
+	}
+	referenceStage := StageDefinition{
+		Name:    "base",
+		Type:    StageTypePipeline,
+		Enabled: true,
+		Config:  map[string]interface{}{"pipeline": "base"},
+	}
+
+	require.NoError(t, registry.Register(newDef("base", pythonStage)))
+	require.NoError(t, registry.Register(newDef("wrapper", referenceStage)))
+	require.NoError(t, registry.Register(newDef("removed", pythonStage)))
+	require.NoError(t, registry.Update(newDef("base", pythonStage)))
+	require.NoError(t, registry.Unregister("removed"))
+	require.NoError(t, registry.AssociatePipeline("products", PipelineTypeQuery, "wrapper"))
+	require.NoError(t, registry.AssociatePipeline("orders", PipelineTypeQuery, "base"))
+	require.NoError(t, registry.DisassociatePipeline("orders", PipelineTypeQuery))
+
+	// Reconstruct the registry from the persisted state, as after a restart
+	restored := NewRegistry(logger)
+	restored.SetStore(store)
+	require.NoError(t, restored.Restore())
+
+	p, err := restored.GetPipelineForIndex("products", PipelineTypeQuery)
+	require.NoError(t, err)
+	assert.Equal(t, "wrapper", p.Name())
+
+	_, err = restored.GetPipelineForIndex("orders", PipelineTypeQuery)
+	assert.Error(t, err, "removed associations stay removed")
+	_, err = restored.Get("removed")
+	assert.Error(t, err, "unregistered pipelines stay unregistered")
+
+	base := restored.pipelines["base"].def
+	original := registry.pipelines["base"].def
+	assert.Equal(t, int64(2), base.Revision, "restoring keeps the revision")
+	assert.True(t, original.Created.Equal(base.Created))
+
+	t.Run("RejectedChangesAreNotApplied", func(t *testing.T) {
+		store.fail = fmt.Errorf("store unavailable")
+		defer func() { store.fail = nil }()
+
+		assert.Error(t, restored.Register(newDef("new", pythonStage)))
+		_, err := restored.Get("new")
+		assert.Error(t, err)
+
+		assert.Error(t, restored.AssociatePipeline("orders", PipelineTypeQuery, "base"))
+		_, err = restored.GetPipelineForIndex("orders", PipelineTypeQuery)
+		assert.Error(t, err)
+
+		assert.Error(t, restored.DisassociatePipeline("products", PipelineTypeQuery))
+		_, err = restored.GetPipelineForIndex("products", PipelineTypeQuery)
+		assert.NoError(t, err)
+	})
+
+	t.Run("LookupsDontWaitForTheStore", func(t *testing.T) {
+		slow := &blockingStore{Store: store, saving: make(chan struct{}), release: make(chan struct{})}
+		restored.SetStore(slow)
+		defer restored.SetStore(store)
+
+		done := make(chan error, 1)
+		go func() { done <- restored.Register(newDef("slow", pythonStage)) }()
+		<-slow.saving
+
+		// Searches look up pipelines while the store is still saving, and
+		// the new pipeline only appears once saved
+		_, err := restored.GetPipelineForIndex("products", PipelineTypeQuery)
+		assert.NoError(t, err)
+		_, err = restored.Get("slow")
+		assert.Error(t, err)
+
+		close(slow.release)
+		require.NoError(t, <-done)
+		_, err = restored.Get("slow")
+		assert.NoError(t, err)
+	})
+
+	t.Run("MissingUDFsAreReported", func(t *testing.T) {
+		udfs := fakeUDFResolver{}
+		missing := NewRegistry(logger)
+		missing.SetUDFResolver(udfs)
+		missing.SetStore(store)
+		require.NoError(t, missing.Restore())
+
+		unrestoredNames := func() []string {
+			var names []string
+			for _, u := range missing.Unrestored() {
+				names = append(names, u.Name)
+			}
+			return names
+		}
+
+		// Pipelines whose UDFs this node doesn't have are skipped along with
+		// the pipelines referencing them, and reported with the reason
+		assert.Equal(t, []string{"base", "slow", "wrapper"}, unrestoredNames())
+		assert.Contains(t, missing.Unrestored()[0].Reason, "rewrite_udf")
+		_, err := missing.Get("base")
+		assert.Error(t, err)
+
+		// Registering a skipped pipeline again clears its report
+		udfs["rewrite_udf"] = []string{"1.0.0"}
+		require.NoError(t, missing.Register(newDef("base", pythonStage)))
+		assert.Equal(t, []string{"slow", "wrapper"}, unrestoredNames())
+	})
+}
@@ -0,0 +1,294 @@
+// Copyright 2026 Quidditch Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+
+package stages
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/quidditch/quidditch/pkg/coordination/pipeline"
+	"go.uber.org/zap"
+)
+
+// pythonHarness is executed by every worker subprocess. It reads a single JSON
+// request from stdin, runs the stage function and writes the JSON result to
+// stdout. Anything the user script prints goes to stderr so it can't corrupt
+// the result.
+const pythonHarness = `
+import json, sys
+out = sys.stdout
+sys.stdout = sys.stderr
+req = json.load(sys.stdin)
+ns = {"__name__": "__quidditch_stage__"}
+exec(compile(req["source"], "<stage>", "exec"), ns)
+fn = ns.get(req["function"])
+if fn is None:
+    raise NameError("function %r is not defined by the stage script" % req["function"])
+result = fn(req["input"], req["parameters"])
+json.dump({"output": result}, out)
+out.flush()
+`
+
+// maxStderrBytes bounds how much of a worker's stderr is kept for error reporting
+const maxStderrBytes = 4096
+
+// PythonRunnerConfig configures the Python subprocess worker pool
+type PythonRunnerConfig struct {
+	Interpreter string        // Python executable (default "python3")
+	MaxWorkers  int           // Max concurrent worker processes (default 4)
+	Timeout     time.Duration // Per-invocation timeout (default 5s)
+}
+
+// PythonRunner executes Python stage scripts in short-lived subprocesses,
+// exchanging the stage input and output as JSON. Concurrency is bounded by
+// a fixed number of worker slots.
+type PythonRunner struct {
+	interpreter string
+	timeout     time.Duration
+	workers     chan struct{}
+	logger      *zap.Logger
+}
+
+// PythonError describes a failed Python stage invocation
+type PythonError struct {
+	Message  string
+	ExitCode int
+	Stderr   string
+	Cause    error
+}
+
+// Error implements error interface
+func (e *PythonError) Error() string {
+	msg := e.Message
+	if e.ExitCode != 0 {
+		msg = fmt.Sprintf("%s (exit code %d)", msg, e.ExitCode)
+	}
+	if e.Stderr != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.Stderr)
+	} else if e.Cause != nil {
+		msg = fmt.Sprintf("%s: %v", msg, e.Cause)
+	}
+	return msg
+}
+
+// Unwrap returns the underlying cause
+func (e *PythonError) Unwrap() error {
+	return e.Cause
+}
+
+// NewPythonRunner creates a new Python worker pool
+func NewPythonRunner(cfg *PythonRunnerConfig, logger *zap.Logger) *PythonRunner {
+	if cfg == nil {
+		cfg = &PythonRunnerConfig{}
+	}
+
+	interpreter := cfg.Interpreter
+	if interpreter == "" {
+		interpreter = "python3"
+	}
+
+	maxWorkers := cfg.MaxWorkers
+	if maxWorkers <= 0 {
+		maxWorkers = 4
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &PythonRunner{
+		interpreter: interpreter,
+		timeout:     timeout,
+		workers:     make(chan struct{}, maxWorkers),
+		logger:      logger.With(zap.String("component", "python_runner")),
+	}
+}
+
+// Run executes function(input, parameters) from the given Python source and
+// returns its JSON-decoded result. The call is bounded by the runner timeout
+// and by any deadline already set on ctx.
+func (r *PythonRunner) Run(ctx context.Context, source, function string,
+	input interface{}, parameters map[string]interface{}) (interface{}, error) {
+
+	if parameters == nil {
+		parameters = map[string]interface{}{}
+	}
+
+	request, err := json.Marshal(map[string]interface{}{
+		"source":     source,
+		"function":   function,
+		"input":      input,
+		"parameters": parameters,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode python stage input: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// Wait for a free worker slot
+	select {
+	case r.workers <- struct{}{}:
+		defer func() { <-r.workers }()
+	case <-ctx.Done():
+		return nil, &PythonError{Message: "timed out waiting for a python worker", Cause: ctx.Err()}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, r.interpreter, "-c", pythonHarness)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	startTime := time.Now()
+	runErr := cmd.Run()
+	duration := time.Since(startTime)
+
+	if ctx.Err() != nil {
+		return nil, &PythonError{
+			Message: fmt.Sprintf("python stage timed out after %s", duration.Round(time.Millisecond)),
+			Stderr:  tailStderr(stderr.String()),
+			Cause:   ctx.Err(),
+		}
+	}
+
+	if runErr != nil {
+		pyErr := &PythonError{
+			Message: "python stage failed",
+			Stderr:  tailStderr(stderr.String()),
+			Cause:   runErr,
+		}
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			pyErr.ExitCode = exitErr.ExitCode()
+		}
+		return nil, pyErr
+	}
+
+	var response struct {
+		Output interface{} `json:"output"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, &PythonError{
+			Message: "python stage returned invalid JSON",
+			Stderr:  tailStderr(stderr.String()),
+			Cause:   err,
+		}
+	}
+
+	r.logger.Debug("Python stage completed",
+		zap.String("function", function),
+		zap.Duration("duration", duration))
+
+	return response.Output, nil
+}
+
+// tailStderr keeps the end of stderr, where Python puts the exception
+func tailStderr(stderr string) string {
+	stderr = strings.TrimSpace(stderr)
+	if len(stderr) > maxStderrBytes {
+		stderr = "..." + stderr[len(stderr)-maxStderrBytes:]
+	}
+	return stderr
+}
+
+// PythonScriptStage runs inline Python source through a PythonRunner
+type PythonScriptStage struct {
+	name       string
+	source     string
+	function   string
+	parameters map[string]interface{}
+	runner     *PythonRunner
+	logger     *zap.Logger
+}
+
+// NewPythonScriptStage creates a stage from a python stage config carrying a
+// "script" (source) and optional "function" entry point (default "transform")
+func NewPythonScriptStage(name string, config map[string]interface{}, runner *PythonRunner, logger *zap.Logger) (*PythonScriptStage, error) {
+	if runner == nil {
+		return nil, fmt.Errorf("inline python scripts are disabled (set python_enabled to run them)")
+	}
+
+	source, ok := config["script"].(string)
+	if !ok || source == "" {
+		return nil, fmt.Errorf("script is required and must be a string")
+	}
+
+	function := "transform"
+	if v, ok := config["function"]; ok {
+		fnStr, ok := v.(string)
+		if !ok || fnStr == "" {
+			return nil, fmt.Errorf("function must be a non-empty string")
+		}
+		function = fnStr
+	}
+
+	parameters := make(map[string]interface{})
+	if params, ok := config["parameters"]; ok {
+		paramMap, ok := params.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("parameters must be an object")
+		}
+		parameters = paramMap
+	}
+
+	return &PythonScriptStage{
+		name:       name,
+		source:     source,
+		function:   function,
+		parameters: parameters,
+		runner:     runner,
+		logger:     logger.With(zap.String("stage", name), zap.String("function", function)),
+	}, nil
+}
+
+// Name returns the stage identifier
+func (s *PythonScriptStage) Name() string {
+	return s.name
+}
+
+// Type returns the stage implementation type
+func (s *PythonScriptStage) Type() pipeline.StageType {
+	return pipeline.StageTypePython
+}
+
+// Config returns stage-specific configuration
+func (s *PythonScriptStage) Config() map[string]interface{} {
+	return map[string]interface{}{
+		"script":     s.source,
+		"function":   s.function,
+		"parameters": s.parameters,
+	}
+}
+
+// Execute passes the input to the Python function as JSON and returns its
+// JSON output
+func (s *PythonScriptStage) Execute(ctx *pipeline.StageContext, input interface{}) (interface{}, error) {
+	s.logger.Debug("Executing Python script stage",
+		zap.String("pipeline", ctx.PipelineName),
+		zap.Int("stage_index", ctx.StageIndex))
+
+	runCtx := ctx.Context
+	if runCtx == nil {
+		runCtx = context.Background()
+	}
+
+	output, err := s.runner.Run(runCtx, s.source, s.function, input, s.parameters)
+	if err != nil {
+		s.logger.Error("Python script stage failed", zap.Error(err))
+		return nil, fmt.Errorf("python stage '%s' failed: %w", s.name, err)
+	}
+
+	return output, nil
+}
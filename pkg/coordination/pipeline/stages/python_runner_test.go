@@ -0,0 +1,173 @@
+// Copyright 2026 Quidditch Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+
+package stages
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/quidditch/quidditch/pkg/coordination/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func requirePython(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not available, skipping Python stage test")
+	}
+}
+
+func TestPythonRunner_Run(t *testing.T) {
+	requirePython(t)
+	runner := NewPythonRunner(&PythonRunnerConfig{Timeout: 2 * time.Second}, zap.NewNop())
+
+	t.Run("Transform", func(t *testing.T) {
+		source := `
+def transform(doc, params):
+    print("debug output must not corrupt the result")
+    doc["title"] = doc["title"].upper()
+    doc["boost"] = params["boost"]
+    return doc
+`
+		output, err := runner.Run(context.Background(), source, "transform",
+			map[string]interface{}{"title": "hello"},
+			map[string]interface{}{"boost": 2.5})
+		require.NoError(t, err)
+
+		doc, ok := output.(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "HELLO", doc["title"])
+		assert.Equal(t, 2.5, doc["boost"])
+	})
+
+	t.Run("ExceptionCapturesStderr", func(t *testing.T) {
+		source := `
+def transform(doc, params):
+    raise ValueError("bad document")
+`
+		_, err := runner.Run(context.Background(), source, "transform", map[string]interface{}{}, nil)
+		require.Error(t, err)
+
+		var pyErr *PythonError
+		require.True(t, errors.As(err, &pyErr))
+		assert.NotZero(t, pyErr.ExitCode)
+		assert.Contains(t, pyErr.Stderr, "ValueError: bad document")
+	})
+
+	t.Run("MissingFunction", func(t *testing.T) {
+		_, err := runner.Run(context.Background(), "x = 1", "transform", map[string]interface{}{}, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not defined by the stage script")
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		fast := NewPythonRunner(&PythonRunnerConfig{Timeout: 200 * time.Millisecond}, zap.NewNop())
+		source := `
+import time
+def transform(doc, params):
+    time.sleep(5)
+    return doc
+`
+		start := time.Now()
+		_, err := fast.Run(context.Background(), source, "transform", map[string]interface{}{}, nil)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Less(t, time.Since(start), 3*time.Second)
+	})
+}
+
+func TestPythonScriptStage_Pipeline(t *testing.T) {
+	requirePython(t)
+	logger := zap.NewNop()
+
+	builder := NewStageBuilder(nil, logger)
+	builder.SetPythonRunner(NewPythonRunner(nil, logger))
+
+	registry := pipeline.NewRegistry(logger)
+	registry.SetStageFactory(builder)
+	executor := pipeline.NewExecutor(registry, logger)
+
+	def := &pipeline.PipelineDefinition{
+		Name:    "python-transform",
+		Version: "1.0.0",
+		Type:    pipeline.PipelineTypeDocument,
+		Stages: []pipeline.StageDefinition{
+			{
+				Name:    "normalize",
+				Type:    pipeline.StageTypePython,
+				Enabled: true,
+				Config: map[string]interface{}{
+					"script": `
+def normalize(doc, params):
+    doc["tags"] = [t.strip().lower() for t in doc.get("tags", [])]
+    doc[params["flag"]] = True
+    return doc
+`,
+					"function":   "normalize",
+					"parameters": map[string]interface{}{"flag": "normalized"},
+				},
+			},
+		},
+		Enabled: true,
+	}
+	require.NoError(t, registry.Register(def))
+
+	output, err := executor.ExecutePipeline(context.Background(), "python-transform", map[string]interface{}{
+		"title": "Doc",
+		"tags":  []interface{}{" Go ", "SEARCH"},
+	})
+	require.NoError(t, err)
+
+	doc, ok := output.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "Doc", doc["title"])
+	assert.Equal(t, []interface{}{"go", "search"}, doc["tags"])
+	assert.Equal(t, true, doc["normalized"])
+}
+
+func TestStageBuilder_Registration(t *testing.T) {
+	logger := zap.NewNop()
+
+	registry := pipeline.NewRegistry(logger)
+	registry.SetStageFactory(NewStageBuilder(nil, logger))
+
+	// Without a python runner, inline scripts are rejected at registration
+	err := registry.Register(&pipeline.PipelineDefinition{
+		Name:    "python-disabled",
+		Version: "1.0.0",
+		Type:    pipeline.PipelineTypeDocument,
+		Stages: []pipeline.StageDefinition{{
+			Name:    "normalize",
+			Type:    pipeline.StageTypePython,
+			Enabled: true,
+			Config:  map[string]interface{}{"script": "def transform(doc, params):\n    return doc\n"},
+		}},
+		Enabled: true,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "python_enabled")
+
+	// Stage types without a builder register, their stages attached later
+	require.NoError(t, registry.Register(&pipeline.PipelineDefinition{
+		Name:    "native-deferred",
+		Version: "1.0.0",
+		Type:    pipeline.PipelineTypeDocument,
+		Stages: []pipeline.StageDefinition{{
+			Name:    "enrich",
+			Type:    pipeline.StageTypeNative,
+			Enabled: true,
+			Config:  map[string]interface{}{"function": "enrich"},
+		}},
+		Enabled: true,
+	}))
+	_, err = registry.Get("native-deferred")
+	assert.NoError(t, err)
+}
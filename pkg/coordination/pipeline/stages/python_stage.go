@@ -93,6 +93,10 @@ func (s *PythonStage) Execute(ctx *pipeline.StageContext, input interface{}) (in
 		zap.String("pipeline", ctx.PipelineName),
 		zap.Int("stage_index", ctx.StageIndex))
 
+	if s.udfCaller == nil {
+		return nil, fmt.Errorf("UDF '%s' cannot be executed: no UDF runtime available", s.udfName)
+	}
+
 	// Convert input to DocumentContext
 	docCtx, err := s.inputToDocumentContext(input)
 	if err != nil {
@@ -100,7 +104,7 @@ func (s *PythonStage) Execute(ctx *pipeline.StageContext, input interface{}) (in
 	}
 
 	// Convert parameters to WASM values
-	wasmParams := s.parametersToWasmValues()
+	wasmParams := parametersToWasmValues(s.parameters)
 
 	// Execute UDF via caller
 	results, err := s.udfCaller.Call(ctx.Context, s.udfName, s.udfVersion, docCtx, wasmParams)
@@ -174,12 +178,12 @@ func (s *PythonStage) inputToDocumentContext(input interface{}) (*wasm.DocumentC
 	return wasm.NewDocumentContextFromMap(documentID, score, dataMap), nil
 }
 
-// parametersToWasmValues converts parameters to WASM Value map
-func (s *PythonStage) parametersToWasmValues() map[string]wasm.Value {
+// parametersToWasmValues converts stage parameters to WASM Value map
+func parametersToWasmValues(parameters map[string]interface{}) map[string]wasm.Value {
 	wasmParams := make(map[string]wasm.Value)
 
-	for name, value := range s.parameters {
-		wasmValue := s.convertToWasmValue(value)
+	for name, value := range parameters {
+		wasmValue := convertToWasmValue(value)
 		wasmParams[name] = wasmValue
 	}
 
@@ -187,7 +191,7 @@ func (s *PythonStage) parametersToWasmValues() map[string]wasm.Value {
 }
 
 // convertToWasmValue converts a Go value to WASM Value
-func (s *PythonStage) convertToWasmValue(value interface{}) wasm.Value {
+func convertToWasmValue(value interface{}) wasm.Value {
 	switch v := value.(type) {
 	case int:
 		return wasm.NewI64Value(int64(v))
@@ -303,8 +307,9 @@ func (s *PythonStage) wasmResultToOutput(results []wasm.Value, originalInput int
 
 // StageBuilder is a helper for creating stages from definitions
 type StageBuilder struct {
-	udfCaller UDFCaller
-	logger    *zap.Logger
+	udfCaller    UDFCaller
+	pythonRunner *PythonRunner
+	logger       *zap.Logger
 }
 
 // NewStageBuilder creates a new stage builder
@@ -315,19 +320,34 @@ func NewStageBuilder(udfCaller UDFCaller, logger *zap.Logger) *StageBuilder {
 	}
 }
 
+// SetPythonRunner enables python stages that carry inline "script" source.
+// Without a runner such stages are rejected.
+func (b *StageBuilder) SetPythonRunner(runner *PythonRunner) {
+	b.pythonRunner = runner
+}
+
 // BuildStage creates a stage from a stage definition
 func (b *StageBuilder) BuildStage(def *pipeline.StageDefinition) (pipeline.Stage, error) {
 	switch def.Type {
 	case pipeline.StageTypePython:
+		if _, ok := def.Config["script"]; ok {
+			return NewPythonScriptStage(def.Name, def.Config, b.pythonRunner, b.logger)
+		}
 		return NewPythonStage(def.Name, def.Config, b.udfCaller, b.logger)
 
 	case pipeline.StageTypeNative:
-		// TODO: Implement native stages
-		return nil, fmt.Errorf("native stages not yet implemented")
+		function, _ := def.Config["function"].(string)
+		switch function {
+		case RerankFunction:
+			return NewRerankStage(def.Name, def.Config, b.udfCaller, b.logger)
+		default:
+			// TODO: Implement remaining native stages
+			return nil, fmt.Errorf("native function '%s' not yet implemented: %w", function, pipeline.ErrNoStageBuilder)
+		}
 
 	case pipeline.StageTypeComposite:
 		// TODO: Implement composite stages
-		return nil, fmt.Errorf("composite stages not yet implemented")
+		return nil, fmt.Errorf("composite stages not yet implemented: %w", pipeline.ErrNoStageBuilder)
 
 	default:
 		return nil, fmt.Errorf("unknown stage type: %s", def.Type)
@@ -0,0 +1,220 @@
+// Copyright 2026 Quidditch Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+
+package stages
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/quidditch/quidditch/pkg/coordination/pipeline"
+	"github.com/quidditch/quidditch/pkg/wasm"
+	"go.uber.org/zap"
+)
+
+// RerankFunction is the native function name of the UDF re-ranking stage
+const RerankFunction = "udf_rerank"
+
+// RerankStage re-scores every hit of a result pipeline with a scoring UDF and
+// re-sorts the hits by the new score. Each hit is exposed to the UDF as a
+// document context, so it can read fields and the original score through the
+// regular host functions.
+type RerankStage struct {
+	name       string
+	udfName    string
+	udfVersion string
+	parameters map[string]interface{}
+	udfCaller  UDFCaller
+	logger     *zap.Logger
+}
+
+// NewRerankStage creates a re-ranking stage from a native stage config with
+// "udf_name", optional "udf_version" and optional "parameters"
+func NewRerankStage(name string, config map[string]interface{}, udfCaller UDFCaller, logger *zap.Logger) (*RerankStage, error) {
+	udfName, ok := config["udf_name"].(string)
+	if !ok || udfName == "" {
+		return nil, fmt.Errorf("udf_name is required and must be a string")
+	}
+
+	udfVersion := ""
+	if v, ok := config["udf_version"]; ok {
+		vStr, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("udf_version must be a string")
+		}
+		udfVersion = vStr
+	}
+
+	parameters := make(map[string]interface{})
+	if params, ok := config["parameters"]; ok {
+		paramMap, ok := params.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("parameters must be an object")
+		}
+		parameters = paramMap
+	}
+
+	return &RerankStage{
+		name:       name,
+		udfName:    udfName,
+		udfVersion: udfVersion,
+		parameters: parameters,
+		udfCaller:  udfCaller,
+		logger:     logger.With(zap.String("stage", name), zap.String("udf", udfName)),
+	}, nil
+}
+
+// Name returns the stage identifier
+func (s *RerankStage) Name() string {
+	return s.name
+}
+
+// Type returns the stage implementation type
+func (s *RerankStage) Type() pipeline.StageType {
+	return pipeline.StageTypeNative
+}
+
+// Config returns stage-specific configuration
+func (s *RerankStage) Config() map[string]interface{} {
+	return map[string]interface{}{
+		"function":    RerankFunction,
+		"udf_name":    s.udfName,
+		"udf_version": s.udfVersion,
+		"parameters":  s.parameters,
+	}
+}
+
+// Execute scores each hit with the UDF and re-sorts the hits in place. The
+// input is either the result pipeline input ({"results": {...}, "request":
+// {...}}) or the results map itself. Everything except the hit order,
+// "_score" and "max_score" is left untouched.
+func (s *RerankStage) Execute(ctx *pipeline.StageContext, input interface{}) (interface{}, error) {
+	if s.udfCaller == nil {
+		return nil, fmt.Errorf("UDF '%s' cannot be executed: no UDF runtime available", s.udfName)
+	}
+
+	inputMap, ok := input.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("rerank stage expects map[string]interface{}, got %T", input)
+	}
+
+	results := inputMap
+	if nested, ok := inputMap["results"].(map[string]interface{}); ok {
+		results = nested
+	}
+
+	rawHits, ok := results["hits"]
+	if !ok {
+		return input, nil
+	}
+	hits, ok := rawHits.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("hits must be an array, got %T", rawHits)
+	}
+
+	wasmParams := parametersToWasmValues(s.parameters)
+	scores := make([]float64, len(hits))
+
+	for i, rawHit := range hits {
+		hit, ok := rawHit.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("hit %d must be an object, got %T", i, rawHit)
+		}
+
+		score, err := s.scoreHit(ctx, hit, wasmParams)
+		if err != nil {
+			return nil, fmt.Errorf("failed to score hit %d: %w", i, err)
+		}
+		hit["_score"] = score
+		scores[i] = score
+	}
+
+	// Stable sort keeps the original order for equal scores
+	order := make([]int, len(hits))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+
+	reranked := make([]interface{}, len(hits))
+	for i, idx := range order {
+		reranked[i] = hits[idx]
+	}
+	results["hits"] = reranked
+
+	if len(reranked) > 0 {
+		results["max_score"] = scores[order[0]]
+	}
+
+	s.logger.Debug("Re-ranked hits",
+		zap.String("pipeline", ctx.PipelineName),
+		zap.Int("hits", len(reranked)))
+
+	return input, nil
+}
+
+// scoreHit calls the scoring UDF for a single hit
+func (s *RerankStage) scoreHit(ctx *pipeline.StageContext, hit map[string]interface{}, params map[string]wasm.Value) (float64, error) {
+	documentID, _ := hit["_id"].(string)
+	score, _ := toFloat64(hit["_score"])
+
+	source, ok := hit["_source"].(map[string]interface{})
+	if !ok {
+		source = hit
+	}
+
+	docCtx := wasm.NewDocumentContextFromMap(documentID, score, source)
+
+	results, err := s.udfCaller.Call(ctx.Context, s.udfName, s.udfVersion, docCtx, params)
+	if err != nil {
+		s.logger.Error("Scoring UDF failed",
+			zap.String("document_id", documentID),
+			zap.Error(err))
+		return 0, fmt.Errorf("UDF '%s' execution failed: %w", s.udfName, err)
+	}
+
+	if len(results) == 0 {
+		return 0, fmt.Errorf("UDF '%s' returned no score", s.udfName)
+	}
+
+	return wasmValueToFloat64(results[0])
+}
+
+// wasmValueToFloat64 converts a numeric UDF result to a score
+func wasmValueToFloat64(value wasm.Value) (float64, error) {
+	switch value.Type {
+	case wasm.ValueTypeF64:
+		return value.AsFloat64()
+	case wasm.ValueTypeF32:
+		v, err := value.AsFloat32()
+		return float64(v), err
+	case wasm.ValueTypeI64:
+		v, err := value.AsInt64()
+		return float64(v), err
+	case wasm.ValueTypeI32:
+		v, err := value.AsInt32()
+		return float64(v), err
+	default:
+		return 0, fmt.Errorf("score must be numeric, got %v", value.Type)
+	}
+}
+
+// toFloat64 converts a numeric JSON or Go value to float64
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
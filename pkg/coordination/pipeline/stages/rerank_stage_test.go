@@ -0,0 +1,79 @@
+// Copyright 2026 Quidditch Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+
+package stages
+
+import (
+	"context"
+	"testing"
+
+	"github.com/quidditch/quidditch/pkg/coordination/pipeline"
+	"github.com/quidditch/quidditch/pkg/wasm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRerankStage_Execute(t *testing.T) {
+	logger := zap.NewNop()
+	stageCtx := &pipeline.StageContext{PipelineName: "results", Context: context.Background()}
+
+	// Score by the "rating" field
+	registry := &mockUDFRegistry{
+		callFunc: func(ctx context.Context, name, version string, docCtx *wasm.DocumentContext, params map[string]wasm.Value) ([]wasm.Value, error) {
+			rating, _ := docCtx.GetFieldInt64("rating")
+			return []wasm.Value{wasm.NewI64Value(rating)}, nil
+		},
+	}
+
+	t.Run("ReordersHits", func(t *testing.T) {
+		stage, err := NewRerankStage("rerank", map[string]interface{}{"udf_name": "by_rating"}, registry, logger)
+		require.NoError(t, err)
+
+		input := map[string]interface{}{
+			"total_hits": int64(42),
+			"hits": []interface{}{
+				map[string]interface{}{"_id": "a", "_score": 3.0, "_source": map[string]interface{}{"rating": float64(1)}},
+				map[string]interface{}{"_id": "b", "_score": 2.0, "_source": map[string]interface{}{"rating": float64(5)}},
+				map[string]interface{}{"_id": "c", "_score": 1.0, "_source": map[string]interface{}{"rating": float64(5)}},
+			},
+		}
+
+		output, err := stage.Execute(stageCtx, input)
+		require.NoError(t, err)
+
+		results := output.(map[string]interface{})
+		hits := results["hits"].([]interface{})
+		require.Len(t, hits, 3)
+		// Ties keep their original order
+		assert.Equal(t, "b", hits[0].(map[string]interface{})["_id"])
+		assert.Equal(t, "c", hits[1].(map[string]interface{})["_id"])
+		assert.Equal(t, "a", hits[2].(map[string]interface{})["_id"])
+		assert.Equal(t, 5.0, results["max_score"])
+		assert.Equal(t, int64(42), results["total_hits"])
+	})
+
+	t.Run("NonNumericScore", func(t *testing.T) {
+		badRegistry := &mockUDFRegistry{
+			callFunc: func(ctx context.Context, name, version string, docCtx *wasm.DocumentContext, params map[string]wasm.Value) ([]wasm.Value, error) {
+				return []wasm.Value{wasm.NewBoolValue(true)}, nil
+			},
+		}
+		stage, err := NewRerankStage("rerank", map[string]interface{}{"udf_name": "bad"}, badRegistry, logger)
+		require.NoError(t, err)
+
+		_, err = stage.Execute(stageCtx, map[string]interface{}{
+			"hits": []interface{}{map[string]interface{}{"_id": "a"}},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "score must be numeric")
+	})
+
+	t.Run("MissingUDFName", func(t *testing.T) {
+		_, err := NewRerankStage("rerank", map[string]interface{}{}, registry, logger)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "udf_name is required")
+	})
+}
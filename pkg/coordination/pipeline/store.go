@@ -0,0 +1,146 @@
+// Copyright 2026 Quidditch Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+
+package pipeline
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Store persists pipeline definitions and their index associations so a
+// registry can reload them after a restart
+type Store interface {
+	// SavePipeline records a pipeline definition, replacing any earlier
+	// definition of the same name
+	SavePipeline(def *PipelineDefinition) error
+
+	// DeletePipeline removes the record of a pipeline
+	DeletePipeline(name string) error
+
+	// SaveAssociation records the pipeline associated with an index for a
+	// pipeline type. An empty pipeline name removes the association.
+	SaveAssociation(indexName string, pipelineType PipelineType, pipelineName string) error
+
+	// Load returns every recorded pipeline and association
+	Load() (*StoredPipelines, error)
+}
+
+// StoredPipelines is the persisted state of a registry
+type StoredPipelines struct {
+	Pipelines    []*PipelineDefinition
+	Associations []IndexAssociation
+}
+
+// UnrestoredPipeline is a stored pipeline that Restore couldn't register,
+// such as one referencing a UDF this node doesn't have. Its definition stays
+// in the store, so it loads on a later restore once the cause is fixed, and
+// registering a pipeline of the same name replaces it.
+type UnrestoredPipeline struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// IndexAssociation is a pipeline associated with an index for one type
+type IndexAssociation struct {
+	IndexName string       `json:"index"`
+	Type      PipelineType `json:"type"`
+	Pipeline  string       `json:"pipeline"`
+}
+
+// SetStore makes the registry persist every change to its pipelines and
+// associations. A change the store rejects is not applied. Changes are
+// persisted one at a time, so the store sees them in the order they are
+// applied, but without holding the lock lookups take, so a slow store
+// doesn't stall searches.
+func (r *Registry) SetStore(store Store) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.store = store
+}
+
+// Restore registers the pipelines and associations recorded in the store,
+// keeping their revisions and timestamps. Pipelines are registered after the
+// pipelines they reference. Entries that no longer register, such as
+// pipelines whose UDFs are gone, are logged and skipped so the rest still
+// load; the skipped pipelines are reported by Unrestored.
+func (r *Registry) Restore() error {
+	r.mu.RLock()
+	store := r.store
+	r.mu.RUnlock()
+
+	if store == nil {
+		return nil
+	}
+
+	stored, err := store.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load pipelines: %w", err)
+	}
+
+	// Register in passes until no pipeline's references become resolvable
+	pending := stored.Pipelines
+	restored := 0
+	for len(pending) > 0 {
+		var failed []*PipelineDefinition
+		var errs []error
+		for _, def := range pending {
+			if err := r.register(def, true); err != nil {
+				failed = append(failed, def)
+				errs = append(errs, err)
+				continue
+			}
+			restored++
+		}
+		if len(failed) == len(pending) {
+			unrestored := make([]UnrestoredPipeline, 0, len(failed))
+			for i, def := range failed {
+				r.logger.Error("Failed to restore pipeline, skipping it until it registers again",
+					zap.String("name", def.Name),
+					zap.Error(errs[i]))
+				unrestored = append(unrestored, UnrestoredPipeline{Name: def.Name, Reason: errs[i].Error()})
+			}
+			r.mu.Lock()
+			r.unrestored = unrestored
+			r.mu.Unlock()
+			break
+		}
+		pending = failed
+	}
+
+	for _, association := range stored.Associations {
+		if err := r.associate(association.IndexName, association.Type, association.Pipeline, false); err != nil {
+			r.logger.Error("Failed to restore pipeline association",
+				zap.String("index", association.IndexName),
+				zap.String("type", string(association.Type)),
+				zap.String("pipeline", association.Pipeline),
+				zap.Error(err))
+		}
+	}
+
+	r.logger.Info("Restored pipelines",
+		zap.Int("pipelines", restored),
+		zap.Int("skipped", len(stored.Pipelines)-restored),
+		zap.Int("associations", len(stored.Associations)))
+
+	return nil
+}
+
+// Unrestored returns the stored pipelines the last Restore skipped and that
+// haven't been registered since, ordered by name
+func (r *Registry) Unrestored() []UnrestoredPipeline {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	unrestored := slices.Clone(r.unrestored)
+	slices.SortFunc(unrestored, func(a, b UnrestoredPipeline) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return unrestored
+}
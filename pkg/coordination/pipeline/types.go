@@ -39,6 +39,9 @@ const (
 
 	// StageTypeComposite chains multiple stages
 	StageTypeComposite StageType = "composite"
+
+	// StageTypePipeline runs another registered pipeline
+	StageTypePipeline StageType = "pipeline"
 )
 
 // Pipeline represents a sequence of processing stages
@@ -140,6 +143,9 @@ type PipelineDefinition struct {
 	// Timeout for the entire pipeline execution
 	Timeout *time.Duration `json:"timeout,omitempty"`
 
+	// Revision is set to 1 at registration and incremented by every update
+	Revision int64 `json:"revision"`
+
 	// Created timestamp when pipeline was registered
 	Created time.Time `json:"created,omitempty"`
 
@@ -152,8 +158,8 @@ type StageDefinition struct {
 	// Name is the stage identifier within the pipeline
 	Name string `json:"name" binding:"required"`
 
-	// Type defines the stage implementation (python, native, composite)
-	Type StageType `json:"type" binding:"required,oneof=python native composite"`
+	// Type defines the stage implementation (python, native, composite, pipeline)
+	Type StageType `json:"type" binding:"required,oneof=python native composite pipeline"`
 
 	// Config contains stage-specific configuration
 	//
@@ -173,6 +179,13 @@ type StageDefinition struct {
 	// OnFailure defines behavior when this stage fails
 	OnFailure FailurePolicy `json:"on_failure,omitempty"`
 
+	// OnError is the per-stage error policy. When set it takes precedence
+	// over OnFailure and the pipeline-level policy.
+	OnError ErrorPolicy `json:"on_error,omitempty"`
+
+	// DefaultOutput replaces the stage output when OnError is use_default
+	DefaultOutput interface{} `json:"default_output,omitempty"`
+
 	// Timeout for this stage execution
 	Timeout *time.Duration `json:"timeout,omitempty"`
 
@@ -194,6 +207,20 @@ const (
 	FailurePolicyRetry FailurePolicy = "retry"
 )
 
+// ErrorPolicy defines how the executor handles an error from a single stage
+type ErrorPolicy string
+
+const (
+	// ErrorPolicyFail aborts the pipeline and returns the stage error
+	ErrorPolicyFail ErrorPolicy = "fail"
+
+	// ErrorPolicyContinue passes the stage's input forward as its output
+	ErrorPolicyContinue ErrorPolicy = "continue"
+
+	// ErrorPolicyUseDefault substitutes the stage's configured DefaultOutput
+	ErrorPolicyUseDefault ErrorPolicy = "use_default"
+)
+
 // PipelineStats tracks pipeline execution metrics
 type PipelineStats struct {
 	// Name of the pipeline
@@ -241,6 +268,14 @@ type StageStats struct {
 	// FailedExecutions is the number of failed runs
 	FailedExecutions int64 `json:"failed_executions"`
 
+	// ContinuedExecutions is the number of failed runs whose input was
+	// passed forward by the continue error policy
+	ContinuedExecutions int64 `json:"continued_executions"`
+
+	// DefaultedExecutions is the number of failed runs replaced by the
+	// stage's default output
+	DefaultedExecutions int64 `json:"defaulted_executions"`
+
 	// AverageDuration is the mean execution time
 	AverageDuration time.Duration `json:"average_duration"`
 
@@ -248,6 +283,59 @@ type StageStats struct {
 	LastError string `json:"last_error,omitempty"`
 }
 
+// StageStatus describes how a stage finished during a single run
+type StageStatus string
+
+const (
+	StageStatusSuccess   StageStatus = "success"
+	StageStatusFailed    StageStatus = "failed"
+	StageStatusContinued StageStatus = "continued"
+	StageStatusDefaulted StageStatus = "defaulted"
+	StageStatusSkipped   StageStatus = "skipped"
+)
+
+// StageSnapshot captures a single stage's input and output during a simulation
+type StageSnapshot struct {
+	// Name of the stage
+	Name string `json:"name"`
+
+	// Index is the position in the pipeline (0-based)
+	Index int `json:"index"`
+
+	// Type is the stage implementation type
+	Type StageType `json:"type"`
+
+	// Status is the stage outcome
+	Status StageStatus `json:"status"`
+
+	// Input is the data the stage received
+	Input interface{} `json:"input,omitempty"`
+
+	// Output is the data passed to the next stage
+	Output interface{} `json:"output,omitempty"`
+
+	// Error is the stage error, if any
+	Error string `json:"error,omitempty"`
+
+	// Duration is the stage execution time
+	Duration time.Duration `json:"duration"`
+}
+
+// SimulationResult is the outcome of a pipeline simulation
+type SimulationResult struct {
+	// Pipeline is the simulated pipeline name
+	Pipeline string `json:"pipeline"`
+
+	// Output is the final pipeline output (nil if the pipeline failed)
+	Output interface{} `json:"output"`
+
+	// Stages contains one snapshot per stage, in execution order
+	Stages []StageSnapshot `json:"stages"`
+
+	// Duration is the total simulation time
+	Duration time.Duration `json:"duration"`
+}
+
 // PipelineError represents a pipeline execution error
 type PipelineError struct {
 	// PipelineName is the name of the failed pipeline
@@ -6,6 +6,8 @@
 package coordination
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -35,10 +37,14 @@ func (h *PipelineHandlers) RegisterRoutes(r *gin.RouterGroup) {
 	pipelines := r.Group("/pipelines")
 	{
 		pipelines.POST("/:name", h.createPipeline)
+		pipelines.PUT("/:name", h.updatePipeline)
 		pipelines.GET("/:name", h.getPipeline)
 		pipelines.DELETE("/:name", h.deletePipeline)
 		pipelines.GET("", h.listPipelines)
+		pipelines.GET("/_export", h.exportPipelines)
+		pipelines.POST("/_import", h.importPipelines)
 		pipelines.POST("/:name/_execute", h.executePipeline)
+		pipelines.POST("/:name/_simulate", h.simulatePipeline)
 		pipelines.GET("/:name/_stats", h.getStats)
 	}
 }
@@ -67,6 +73,7 @@ type PipelineResponse struct {
 	Enabled     bool                        `json:"enabled"`
 	OnFailure   pipeline.FailurePolicy      `json:"on_failure,omitempty"`
 	Timeout     *time.Duration              `json:"timeout,omitempty"`
+	Revision    int64                       `json:"revision"`
 	Created     time.Time                   `json:"created"`
 	Updated     time.Time                   `json:"updated"`
 }
@@ -84,6 +91,15 @@ type PipelineExecuteResponse struct {
 	Error    string         `json:"error,omitempty"`
 }
 
+// PipelineSimulateResponse represents a pipeline simulation response
+type PipelineSimulateResponse struct {
+	Output   interface{}              `json:"output"`
+	Stages   []pipeline.StageSnapshot `json:"stages"`
+	Duration time.Duration            `json:"duration_ms"`
+	Success  bool                     `json:"success"`
+	Error    string                   `json:"error,omitempty"`
+}
+
 // createPipeline handles POST /_pipelines/{name}
 func (h *PipelineHandlers) createPipeline(c *gin.Context) {
 	pipelineName := c.Param("name")
@@ -93,19 +109,13 @@ func (h *PipelineHandlers) createPipeline(c *gin.Context) {
 		h.logger.Warn("Invalid pipeline creation request",
 			zap.String("name", pipelineName),
 			zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"details": err.Error(),
-		})
+		respondAPIError(c, parsingError(err))
 		return
 	}
 
 	// Validate that name in path matches name in body
 	if req.Name != pipelineName {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Name mismatch",
-			"details": "Pipeline name in URL must match name in request body",
-		})
+		respondError(c, http.StatusBadRequest, "illegal_argument_exception", "Pipeline name in URL must match name in request body")
 		return
 	}
 
@@ -131,17 +141,11 @@ func (h *PipelineHandlers) createPipeline(c *gin.Context) {
 
 		// Check if it's a validation error
 		if _, ok := err.(*pipeline.ValidationError); ok {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Validation failed",
-				"details": err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, "validation_exception", err.Error())
 			return
 		}
 
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to register pipeline",
-			"details": err.Error(),
-		})
+		respondError(c, http.StatusInternalServerError, "pipeline_exception", fmt.Sprintf("Failed to register pipeline: %v", err))
 		return
 	}
 
@@ -158,6 +162,71 @@ func (h *PipelineHandlers) createPipeline(c *gin.Context) {
 	})
 }
 
+// updatePipeline handles PUT /_pipelines/{name}
+func (h *PipelineHandlers) updatePipeline(c *gin.Context) {
+	pipelineName := c.Param("name")
+
+	var req PipelineCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid pipeline update request",
+			zap.String("name", pipelineName),
+			zap.Error(err))
+		respondAPIError(c, parsingError(err))
+		return
+	}
+
+	// Validate that name in path matches name in body
+	if req.Name != pipelineName {
+		respondError(c, http.StatusBadRequest, "illegal_argument_exception", "Pipeline name in URL must match name in request body")
+		return
+	}
+
+	if _, err := h.registry.Get(pipelineName); err != nil {
+		respondError(c, http.StatusNotFound, "resource_not_found_exception", err.Error())
+		return
+	}
+
+	def := &pipeline.PipelineDefinition{
+		Name:        req.Name,
+		Version:     req.Version,
+		Type:        req.Type,
+		Description: req.Description,
+		Stages:      req.Stages,
+		Metadata:    req.Metadata,
+		Enabled:     req.Enabled,
+		OnFailure:   req.OnFailure,
+		Timeout:     req.Timeout,
+	}
+
+	if err := h.registry.Update(def); err != nil {
+		h.logger.Error("Failed to update pipeline",
+			zap.String("name", req.Name),
+			zap.String("version", req.Version),
+			zap.Error(err))
+
+		if _, ok := err.(*pipeline.ValidationError); ok {
+			respondError(c, http.StatusBadRequest, "validation_exception", err.Error())
+			return
+		}
+
+		respondError(c, http.StatusInternalServerError, "pipeline_exception", fmt.Sprintf("Failed to update pipeline: %v", err))
+		return
+	}
+
+	h.logger.Info("Pipeline updated successfully",
+		zap.String("name", req.Name),
+		zap.String("version", req.Version),
+		zap.Int64("revision", def.Revision))
+
+	c.JSON(http.StatusOK, gin.H{
+		"acknowledged": true,
+		"name":         req.Name,
+		"version":      req.Version,
+		"type":         req.Type,
+		"revision":     def.Revision,
+	})
+}
+
 // getPipeline handles GET /_pipelines/{name}
 func (h *PipelineHandlers) getPipeline(c *gin.Context) {
 	pipelineName := c.Param("name")
@@ -168,10 +237,7 @@ func (h *PipelineHandlers) getPipeline(c *gin.Context) {
 		h.logger.Debug("Pipeline not found",
 			zap.String("name", pipelineName),
 			zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Pipeline not found",
-			"details": err.Error(),
-		})
+		respondError(c, http.StatusNotFound, "resource_not_found_exception", err.Error())
 		return
 	}
 
@@ -192,6 +258,7 @@ func (h *PipelineHandlers) getPipeline(c *gin.Context) {
 			response.Enabled = def.Enabled
 			response.OnFailure = def.OnFailure
 			response.Timeout = def.Timeout
+			response.Revision = def.Revision
 			response.Created = def.Created
 			response.Updated = def.Updated
 			break
@@ -213,26 +280,17 @@ func (h *PipelineHandlers) deletePipeline(c *gin.Context) {
 
 		// Check if it's a not found error
 		if err.Error() == "pipeline '"+pipelineName+"' not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error":   "Pipeline not found",
-				"details": err.Error(),
-			})
+			respondError(c, http.StatusNotFound, "resource_not_found_exception", err.Error())
 			return
 		}
 
 		// Check if it's still associated with indexes
 		if err.Error() != "" {
-			c.JSON(http.StatusConflict, gin.H{
-				"error":   "Cannot delete pipeline",
-				"details": err.Error(),
-			})
+			respondError(c, http.StatusConflict, "illegal_state_exception", err.Error())
 			return
 		}
 
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to delete pipeline",
-			"details": err.Error(),
-		})
+		respondError(c, http.StatusInternalServerError, "pipeline_exception", fmt.Sprintf("Failed to delete pipeline: %v", err))
 		return
 	}
 
@@ -270,14 +328,61 @@ func (h *PipelineHandlers) listPipelines(c *gin.Context) {
 			Enabled:     def.Enabled,
 			OnFailure:   def.OnFailure,
 			Timeout:     def.Timeout,
+			Revision:    def.Revision,
 			Created:     def.Created,
 			Updated:     def.Updated,
 		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"total":     len(responses),
 		"pipelines": responses,
+	}
+	// Stored pipelines this node couldn't restore, such as ones whose UDFs
+	// it doesn't have, are listed so they aren't silently missing
+	if unrestored := h.registry.Unrestored(); len(unrestored) > 0 {
+		response["unrestored"] = unrestored
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// exportPipelines handles GET /_pipelines/_export
+func (h *PipelineHandlers) exportPipelines(c *gin.Context) {
+	c.JSON(http.StatusOK, h.registry.Export())
+}
+
+// importPipelines handles POST /_pipelines/_import. Pipelines and
+// associations that already exist are skipped, or replaced with
+// ?on_conflict=overwrite.
+func (h *PipelineHandlers) importPipelines(c *gin.Context) {
+	var bundle pipeline.Bundle
+	if err := c.ShouldBindJSON(&bundle); err != nil {
+		respondAPIError(c, parsingError(err))
+		return
+	}
+
+	policy := pipeline.ConflictPolicy(c.DefaultQuery("on_conflict", string(pipeline.ConflictSkip)))
+	result, err := h.registry.Import(&bundle, policy)
+	if err != nil {
+		h.logger.Warn("Failed to import pipelines", zap.Error(err))
+
+		var validationErr *pipeline.ValidationError
+		if errors.As(err, &validationErr) {
+			respondError(c, http.StatusBadRequest, "validation_exception", err.Error())
+			return
+		}
+
+		respondError(c, http.StatusInternalServerError, "pipeline_exception", fmt.Sprintf("Failed to import pipelines: %v", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"acknowledged":         true,
+		"created":              result.Created,
+		"updated":              result.Updated,
+		"skipped":              result.Skipped,
+		"associations_applied": result.AssociationsApplied,
+		"associations_skipped": result.AssociationsSkipped,
 	})
 }
 
@@ -290,10 +395,7 @@ func (h *PipelineHandlers) executePipeline(c *gin.Context) {
 		h.logger.Warn("Invalid pipeline execution request",
 			zap.String("name", pipelineName),
 			zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"details": err.Error(),
-		})
+		respondAPIError(c, parsingError(err))
 		return
 	}
 
@@ -329,6 +431,49 @@ func (h *PipelineHandlers) executePipeline(c *gin.Context) {
 	})
 }
 
+// simulatePipeline handles POST /_pipelines/{name}/_simulate
+func (h *PipelineHandlers) simulatePipeline(c *gin.Context) {
+	pipelineName := c.Param("name")
+
+	var req PipelineExecuteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid pipeline simulation request",
+			zap.String("name", pipelineName),
+			zap.Error(err))
+		respondAPIError(c, parsingError(err))
+		return
+	}
+
+	if _, err := h.registry.Get(pipelineName); err != nil {
+		respondError(c, http.StatusNotFound, "resource_not_found_exception", err.Error())
+		return
+	}
+
+	result, err := h.executor.SimulatePipeline(c.Request.Context(), pipelineName, req.Input)
+	if result == nil {
+		respondError(c, http.StatusInternalServerError, "pipeline_exception", fmt.Sprintf("Failed to simulate pipeline: %v", err))
+		return
+	}
+
+	response := PipelineSimulateResponse{
+		Output:   result.Output,
+		Stages:   result.Stages,
+		Duration: result.Duration,
+		Success:  err == nil,
+	}
+	if err != nil {
+		// Like _execute, a failing pipeline is still a successful simulation
+		response.Error = err.Error()
+	}
+
+	h.logger.Debug("Pipeline simulated",
+		zap.String("name", pipelineName),
+		zap.Int("stages", len(result.Stages)),
+		zap.Bool("success", response.Success))
+
+	c.JSON(http.StatusOK, response)
+}
+
 // getStats handles GET /_pipelines/{name}/_stats
 func (h *PipelineHandlers) getStats(c *gin.Context) {
 	pipelineName := c.Param("name")
@@ -339,10 +484,7 @@ func (h *PipelineHandlers) getStats(c *gin.Context) {
 		h.logger.Debug("Pipeline statistics not found",
 			zap.String("name", pipelineName),
 			zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Pipeline not found",
-			"details": err.Error(),
-		})
+		respondError(c, http.StatusNotFound, "resource_not_found_exception", err.Error())
 		return
 	}
 
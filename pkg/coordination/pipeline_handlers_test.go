@@ -8,6 +8,7 @@ package coordination
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -110,7 +111,7 @@ func TestPipelineHandlers_CreatePipeline(t *testing.T) {
 
 		var response map[string]interface{}
 		json.Unmarshal(w.Body.Bytes(), &response)
-		assert.Contains(t, response["error"], "Name mismatch")
+		assert.Contains(t, response["error"].(map[string]interface{})["reason"], "must match")
 	})
 
 	t.Run("InvalidType", func(t *testing.T) {
@@ -316,6 +317,70 @@ func TestPipelineHandlers_ListPipelines(t *testing.T) {
 	})
 }
 
+// storedPipelines is a pipeline store holding pipelines saved before a
+// restart
+type storedPipelines struct {
+	pipeline.Store
+	pipelines []*pipeline.PipelineDefinition
+}
+
+func (s *storedPipelines) Load() (*pipeline.StoredPipelines, error) {
+	return &pipeline.StoredPipelines{Pipelines: s.pipelines}, nil
+}
+
+// udfsOf resolves only the UDFs of the names it holds
+type udfsOf map[string]bool
+
+func (u udfsOf) ResolveVersion(name, version string) (string, error) {
+	if !u[name] {
+		return "", fmt.Errorf("UDF %s not found", name)
+	}
+	return "1.0.0", nil
+}
+
+func TestPipelineHandlers_ListUnrestoredPipelines(t *testing.T) {
+	router, registry, _ := setupPipelineTestRouter()
+
+	newDef := func(name, udf string) *pipeline.PipelineDefinition {
+		return &pipeline.PipelineDefinition{
+			Name:    name,
+			Version: "1.0.0",
+			Type:    pipeline.PipelineTypeQuery,
+			Stages: []pipeline.StageDefinition{{
+				Name:    "filter",
+				Type:    pipeline.StageTypePython,
+				Enabled: true,
+				Config:  map[string]interface{}{"udf_name": udf},
+			}},
+			Enabled: true,
+		}
+	}
+
+	// The node restarts without one of the UDFs its stored pipelines use
+	registry.SetUDFResolver(udfsOf{"local_udf": true})
+	registry.SetStore(&storedPipelines{pipelines: []*pipeline.PipelineDefinition{
+		newDef("local", "local_udf"),
+		newDef("remote", "remote_udf"),
+	}})
+	require.NoError(t, registry.Restore())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pipelines", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(1), response["total"])
+
+	// The skipped pipeline is listed with the UDF it is missing
+	unrestored := response["unrestored"].([]interface{})
+	require.Len(t, unrestored, 1)
+	skipped := unrestored[0].(map[string]interface{})
+	assert.Equal(t, "remote", skipped["name"])
+	assert.Contains(t, skipped["reason"], "remote_udf")
+}
+
 func TestPipelineHandlers_DeletePipeline(t *testing.T) {
 	router, registry, _ := setupPipelineTestRouter()
 
@@ -395,6 +460,76 @@ func TestPipelineHandlers_DeletePipeline(t *testing.T) {
 	})
 }
 
+func TestPipelineHandlers_UpdatePipeline(t *testing.T) {
+	router, registry, _ := setupPipelineTestRouter()
+
+	createReq := func(version string) PipelineCreateRequest {
+		return PipelineCreateRequest{
+			Name:    "update-test",
+			Version: version,
+			Type:    pipeline.PipelineTypeQuery,
+			Stages: []pipeline.StageDefinition{
+				{
+					Name:    "stage1",
+					Type:    pipeline.StageTypeNative,
+					Enabled: true,
+					Config:  map[string]interface{}{"function": "test"},
+				},
+			},
+			Enabled: true,
+		}
+	}
+
+	put := func(name string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/pipelines/"+name, bytes.NewBuffer(data))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	body, _ := json.Marshal(createReq("1.0.0"))
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pipelines/update-test", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NoError(t, registry.AssociatePipeline("products", pipeline.PipelineTypeQuery, "update-test"))
+
+	t.Run("UpdateAssociatedPipeline", func(t *testing.T) {
+		w := put("update-test", createReq("2.0.0"))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "2.0.0", response["version"])
+		assert.Equal(t, float64(2), response["revision"])
+
+		pipe, err := registry.GetPipelineForIndex("products", pipeline.PipelineTypeQuery)
+		require.NoError(t, err)
+		assert.Equal(t, "2.0.0", pipe.Version())
+	})
+
+	t.Run("InvalidUpdate", func(t *testing.T) {
+		invalid := createReq("3.0.0")
+		invalid.Stages[0].Type = "unknown"
+		w := put("update-test", invalid)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		pipe, err := registry.Get("update-test")
+		require.NoError(t, err)
+		assert.Equal(t, "2.0.0", pipe.Version())
+	})
+
+	t.Run("NonExistentPipeline", func(t *testing.T) {
+		missing := createReq("1.0.0")
+		missing.Name = "missing"
+		w := put("missing", missing)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
 func TestPipelineHandlers_ExecutePipeline(t *testing.T) {
 	router, registry, _ := setupPipelineTestRouter()
 
@@ -528,6 +663,115 @@ func TestPipelineHandlers_ExecutePipeline(t *testing.T) {
 	})
 }
 
+func TestPipelineHandlers_SimulatePipeline(t *testing.T) {
+	router, registry, _ := setupPipelineTestRouter()
+
+	def := &pipeline.PipelineDefinition{
+		Name:    "simulate-test",
+		Version: "1.0.0",
+		Type:    pipeline.PipelineTypeDocument,
+		Stages: []pipeline.StageDefinition{
+			{Name: "lowercase", Type: pipeline.StageTypeNative, Enabled: true, Config: map[string]interface{}{"function": "lowercase"}},
+			{Name: "tag", Type: pipeline.StageTypeNative, Enabled: true, Config: map[string]interface{}{"function": "tag"}},
+		},
+		Enabled: true,
+	}
+	require.NoError(t, registry.Register(def))
+
+	pipe, err := registry.Get("simulate-test")
+	require.NoError(t, err)
+	impl := pipe.(*pipeline.PipelineImpl)
+
+	// Both stages mutate the document in place, so snapshots must be copies
+	impl.SetStages([]pipeline.Stage{
+		&mockExecuteStage{
+			name:      "lowercase",
+			stageType: pipeline.StageTypeNative,
+			executeFunc: func(ctx *pipeline.StageContext, input interface{}) (interface{}, error) {
+				doc := input.(map[string]interface{})
+				doc["title"] = "hello"
+				return doc, nil
+			},
+		},
+		&mockExecuteStage{
+			name:      "tag",
+			stageType: pipeline.StageTypeNative,
+			executeFunc: func(ctx *pipeline.StageContext, input interface{}) (interface{}, error) {
+				doc := input.(map[string]interface{})
+				doc["tagged"] = true
+				return doc, nil
+			},
+		},
+	})
+
+	simulate := func(t *testing.T, name string) (int, PipelineSimulateResponse) {
+		body, _ := json.Marshal(PipelineExecuteRequest{
+			Input: map[string]interface{}{"title": "HELLO"},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/pipelines/"+name+"/_simulate", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response PipelineSimulateResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	t.Run("IntermediateOutputs", func(t *testing.T) {
+		code, response := simulate(t, "simulate-test")
+		require.Equal(t, http.StatusOK, code)
+		assert.True(t, response.Success)
+		require.Len(t, response.Stages, 2)
+
+		first := response.Stages[0]
+		assert.Equal(t, "lowercase", first.Name)
+		assert.Equal(t, pipeline.StageStatusSuccess, first.Status)
+		assert.Equal(t, map[string]interface{}{"title": "HELLO"}, first.Input)
+		assert.Equal(t, map[string]interface{}{"title": "hello"}, first.Output)
+
+		second := response.Stages[1]
+		assert.Equal(t, "tag", second.Name)
+		assert.Equal(t, map[string]interface{}{"title": "hello"}, second.Input)
+		assert.Equal(t, map[string]interface{}{"title": "hello", "tagged": true}, second.Output)
+
+		assert.Equal(t, map[string]interface{}{"title": "hello", "tagged": true}, response.Output)
+	})
+
+	t.Run("SimulationDoesNotUpdateStats", func(t *testing.T) {
+		stats, err := registry.GetStats("simulate-test")
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), stats.TotalExecutions)
+	})
+
+	t.Run("FailingStage", func(t *testing.T) {
+		impl.SetStages([]pipeline.Stage{
+			&mockExecuteStage{name: "lowercase", stageType: pipeline.StageTypeNative},
+			&mockExecuteStage{
+				name:      "tag",
+				stageType: pipeline.StageTypeNative,
+				executeFunc: func(ctx *pipeline.StageContext, input interface{}) (interface{}, error) {
+					return nil, assert.AnError
+				},
+			},
+		})
+
+		code, response := simulate(t, "simulate-test")
+		require.Equal(t, http.StatusOK, code)
+		assert.False(t, response.Success)
+		assert.NotEmpty(t, response.Error)
+		require.Len(t, response.Stages, 2)
+		assert.Equal(t, pipeline.StageStatusSuccess, response.Stages[0].Status)
+		assert.Equal(t, pipeline.StageStatusFailed, response.Stages[1].Status)
+		assert.Equal(t, assert.AnError.Error(), response.Stages[1].Error)
+	})
+
+	t.Run("NonExistentPipeline", func(t *testing.T) {
+		code, _ := simulate(t, "nonexistent")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
+
 func TestPipelineHandlers_GetStats(t *testing.T) {
 	router, registry, executor := setupPipelineTestRouter()
 
@@ -595,6 +839,121 @@ func TestPipelineHandlers_GetStats(t *testing.T) {
 	})
 }
 
+func TestPipelineHandlers_ExportImport(t *testing.T) {
+	sourceRouter, source, _ := setupPipelineTestRouter()
+	targetRouter, target, _ := setupPipelineTestRouter()
+
+	newDef := func(name, description string, stage pipeline.StageDefinition) *pipeline.PipelineDefinition {
+		return &pipeline.PipelineDefinition{
+			Name:        name,
+			Version:     "1.0.0",
+			Type:        pipeline.PipelineTypeQuery,
+			Description: description,
+			Stages:      []pipeline.StageDefinition{stage},
+			Enabled:     true,
+		}
+	}
+	nativeStage := pipeline.StageDefinition{
+		Name:    "rewrite",
+		Type:    pipeline.StageTypeNative,
+		Enabled: true,
+		Config:  map[string]interface{}{"function": "rewrite"},
+	}
+	referenceStage := pipeline.StageDefinition{
+		Name:    "base",
+		Type:    pipeline.StageTypePipeline,
+		Enabled: true,
+		Config:  map[string]interface{}{"pipeline": "base"},
+	}
+
+	require.NoError(t, source.Register(newDef("base", "exported", nativeStage)))
+	require.NoError(t, source.Register(newDef("wrapper", "exported", referenceStage)))
+	require.NoError(t, source.AssociatePipeline("products", pipeline.PipelineTypeQuery, "wrapper"))
+
+	w := httptest.NewRecorder()
+	sourceRouter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/pipelines/_export", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	exported := w.Body.Bytes()
+
+	var bundle pipeline.Bundle
+	require.NoError(t, json.Unmarshal(exported, &bundle))
+	require.Len(t, bundle.Pipelines, 2)
+	assert.Equal(t, "base", bundle.Pipelines[0].Name)
+	assert.Equal(t, []pipeline.IndexAssociation{
+		{IndexName: "products", Type: pipeline.PipelineTypeQuery, Pipeline: "wrapper"},
+	}, bundle.Associations)
+
+	importBundle := func(body []byte, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/pipelines/_import"+query, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		targetRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("SkipExisting", func(t *testing.T) {
+		// The target already has a pipeline of its own that must survive
+		require.NoError(t, target.Register(newDef("base", "local", nativeStage)))
+
+		w := importBundle(exported, "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, []interface{}{"wrapper"}, response["created"])
+		assert.Equal(t, []interface{}{"base"}, response["skipped"])
+
+		p, err := target.GetPipelineForIndex("products", pipeline.PipelineTypeQuery)
+		require.NoError(t, err)
+		assert.Equal(t, "wrapper", p.Name())
+
+		for _, def := range target.List("") {
+			if def.Name == "base" {
+				assert.Equal(t, "local", def.Description, "skip keeps the existing pipeline")
+			}
+		}
+	})
+
+	t.Run("Overwrite", func(t *testing.T) {
+		w := importBundle(exported, "?on_conflict=overwrite")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		for _, def := range target.List("") {
+			assert.Equal(t, "exported", def.Description, def.Name)
+		}
+	})
+
+	t.Run("AllOrNothing", func(t *testing.T) {
+		broken := pipeline.Bundle{
+			Pipelines: []*pipeline.PipelineDefinition{
+				newDef("fresh", "exported", nativeStage),
+				newDef("base", "replaced", nativeStage),
+				newDef("dangling", "exported", pipeline.StageDefinition{
+					Name:    "missing",
+					Type:    pipeline.StageTypePipeline,
+					Enabled: true,
+					Config:  map[string]interface{}{"pipeline": "missing"},
+				}),
+			},
+		}
+		body, _ := json.Marshal(broken)
+
+		w := importBundle(body, "?on_conflict=overwrite")
+		assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+		_, err := target.Get("fresh")
+		assert.Error(t, err, "pipelines created before the failure are removed")
+		for _, def := range target.List("") {
+			assert.Equal(t, "exported", def.Description, "updates before the failure are undone")
+		}
+	})
+
+	t.Run("UnknownConflictPolicy", func(t *testing.T) {
+		w := importBundle(exported, "?on_conflict=merge")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
 // mockExecuteStage is a mock stage for testing
 type mockExecuteStage struct {
 	name        string
@@ -0,0 +1,75 @@
+package coordination
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/quidditch/quidditch/pkg/coordination/pipeline"
+)
+
+// pipelineStoreTimeout bounds each call the pipeline store makes to the master
+const pipelineStoreTimeout = 10 * time.Second
+
+// masterPipelineStore persists pipelines and their index associations in the
+// master's cluster state, so every coordination node restores the same ones
+type masterPipelineStore struct {
+	client *MasterClient
+}
+
+// SavePipeline implements pipeline.Store
+func (s *masterPipelineStore) SavePipeline(def *pipeline.PipelineDefinition) error {
+	definition, err := json.Marshal(def)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pipeline: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pipelineStoreTimeout)
+	defer cancel()
+	_, err = s.client.PutPipeline(ctx, def.Name, string(definition))
+	return err
+}
+
+// DeletePipeline implements pipeline.Store
+func (s *masterPipelineStore) DeletePipeline(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pipelineStoreTimeout)
+	defer cancel()
+	_, err := s.client.DeletePipeline(ctx, name)
+	return err
+}
+
+// SaveAssociation implements pipeline.Store
+func (s *masterPipelineStore) SaveAssociation(indexName string, pipelineType pipeline.PipelineType, pipelineName string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pipelineStoreTimeout)
+	defer cancel()
+	_, err := s.client.SetIndexPipeline(ctx, indexName, string(pipelineType), pipelineName)
+	return err
+}
+
+// Load implements pipeline.Store
+func (s *masterPipelineStore) Load() (*pipeline.StoredPipelines, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pipelineStoreTimeout)
+	defer cancel()
+	resp, err := s.client.GetPipelines(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := &pipeline.StoredPipelines{}
+	for _, p := range resp.Pipelines {
+		var def pipeline.PipelineDefinition
+		if err := json.Unmarshal([]byte(p.Definition), &def); err != nil {
+			return nil, fmt.Errorf("invalid definition of pipeline %s: %w", p.Name, err)
+		}
+		stored.Pipelines = append(stored.Pipelines, &def)
+	}
+	for _, association := range resp.IndexPipelines {
+		stored.Associations = append(stored.Associations, pipeline.IndexAssociation{
+			IndexName: association.IndexName,
+			Type:      pipeline.PipelineType(association.Type),
+			Pipeline:  association.Pipeline,
+		})
+	}
+	return stored, nil
+}
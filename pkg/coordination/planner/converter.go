@@ -38,10 +38,13 @@ func (c *Converter) ConvertSearchRequest(req *parser.SearchRequest, indexName st
 
 	// Create scan with filter pushed down
 	scan := &LogicalScan{
-		IndexName:     indexName,
-		Shards:        shards,
-		Filter:        filterExpr, // Push filter into scan!
-		EstimatedRows: estimatedRows,
+		IndexName:      indexName,
+		Shards:         shards,
+		Filter:         filterExpr, // Push filter into scan!
+		EstimatedRows:  estimatedRows,
+		TerminateAfter: int64(req.TerminateAfter),
+		MinScore:       req.MinScore,
+		Collapse:       convertCollapse(req.Collapse),
 	}
 
 	var plan LogicalPlan = scan
@@ -79,15 +82,13 @@ func (c *Converter) ConvertSearchRequest(req *parser.SearchRequest, indexName st
 		plan = sort
 	}
 
-	// Add limit/pagination (from/size)
-	if req.Size > 0 || req.From > 0 {
-		size := req.Size
-		if size == 0 {
-			size = 10 // Default size
-		}
+	// Add limit/pagination (from/size). The parser has already defaulted a
+	// missing size, so a size of 0 asks for no hits: aggregations are
+	// returned on their own, and a plain search limits to nothing.
+	if req.Size > 0 || (len(aggregations) == 0 && (req.Size == 0 || req.From > 0)) {
 		plan = &LogicalLimit{
 			Offset: int64(req.From),
-			Limit:  int64(size),
+			Limit:  int64(req.Size),
 			Child:  plan,
 		}
 	}
@@ -95,8 +96,32 @@ func (c *Converter) ConvertSearchRequest(req *parser.SearchRequest, indexName st
 	return plan, nil
 }
 
-// ConvertQuery converts a parser.Query to an Expression
+// ConvertQuery converts a parser.Query to an Expression, carrying the
+// query's _name over to it
 func (c *Converter) ConvertQuery(q parser.Query) (*Expression, error) {
+	expr, err := c.convertQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	named, ok := q.(parser.NamedQuery)
+	if !ok || named.QueryName() == "" {
+		return expr, nil
+	}
+	// A single-clause bool converts to its clause, which may have a name
+	// of its own
+	if expr.Name != "" && expr.Name != named.QueryName() {
+		expr = &Expression{
+			Type:     ExprTypeBool,
+			Children: []*Expression{expr},
+		}
+	}
+	expr.Name = named.QueryName()
+	return expr, nil
+}
+
+// convertQuery converts a parser.Query to an Expression
+func (c *Converter) convertQuery(q parser.Query) (*Expression, error) {
 	switch query := q.(type) {
 	case *parser.MatchAllQuery:
 		return &Expression{
@@ -125,6 +150,24 @@ func (c *Converter) ConvertQuery(q parser.Query) (*Expression, error) {
 			Children: children,
 		}, nil
 
+	case *parser.IDsQuery:
+		// IDs query is a terms query over _id
+		children := make([]*Expression, len(query.Values))
+		for i, id := range query.Values {
+			children[i] = &Expression{
+				Type:  ExprTypeTerm,
+				Field: "_id",
+				Value: id,
+			}
+		}
+		if len(children) == 1 {
+			return children[0], nil
+		}
+		return &Expression{
+			Type:     ExprTypeBool,
+			Children: children,
+		}, nil
+
 	case *parser.RangeQuery:
 		// Only include non-nil range parameters
 		rangeParams := make(map[string]interface{})
@@ -140,6 +183,9 @@ func (c *Converter) ConvertQuery(q parser.Query) (*Expression, error) {
 		if query.Lte != nil {
 			rangeParams["lte"] = query.Lte
 		}
+		if query.Format != "" {
+			rangeParams["format"] = query.Format
+		}
 		return &Expression{
 			Type:  ExprTypeRange,
 			Field: query.Field,
@@ -166,6 +212,13 @@ func (c *Converter) ConvertQuery(q parser.Query) (*Expression, error) {
 			Value: query.Value,
 		}, nil
 
+	case *parser.RegexpQuery:
+		return &Expression{
+			Type:  ExprTypeRegexp,
+			Field: query.Field,
+			Value: query.Value,
+		}, nil
+
 	case *parser.MatchQuery:
 		return &Expression{
 			Type:  ExprTypeMatch,
@@ -206,21 +259,24 @@ func (c *Converter) ConvertQuery(q parser.Query) (*Expression, error) {
 			Value: query.Value,
 		}, nil
 
-	case *parser.QueryStringQuery:
-		// Query string is complex - for now treat as match on default field
-		field := query.DefaultField
-		if field == "" && len(query.Fields) > 0 {
-			field = query.Fields[0]
-		}
-		if field == "" {
-			field = "_all"
-		}
+	case *parser.GeoDistanceQuery:
 		return &Expression{
-			Type:  ExprTypeMatch,
-			Field: field,
-			Value: query.Query,
+			Type:  ExprTypeGeoDistance,
+			Field: query.Field,
+			Value: map[string]interface{}{
+				"origin":          query.Origin,
+				"distance":        query.Distance,
+				"render_distance": query.RenderDistance,
+			},
 		}, nil
 
+	case *parser.QueryStringQuery:
+		// The parser has already parsed the query string into the AST
+		return c.ConvertQuery(query.Parsed)
+
+	case *parser.SimpleQueryStringQuery:
+		return c.ConvertQuery(query.Parsed)
+
 	default:
 		return nil, fmt.Errorf("unsupported query type: %T", query)
 	}
@@ -260,8 +316,9 @@ func (c *Converter) convertBoolQuery(q *parser.BoolQuery) (*Expression, error) {
 			shouldChildren[i] = expr
 		}
 		shouldExpr := &Expression{
-			Type:     ExprTypeBool,
-			Children: shouldChildren,
+			Type:               ExprTypeBool,
+			Children:           shouldChildren,
+			MinimumShouldMatch: q.RequiredShouldClauses(),
 		}
 		allClauses = append(allClauses, shouldExpr)
 	}
@@ -450,6 +507,28 @@ func (c *Converter) convertSource(source interface{}, child LogicalPlan) (*Logic
 	}, nil
 }
 
+// convertCollapse converts the collapse option of a search request. Inner
+// hits are named after the field and hold DefaultInnerHitsSize hits unless
+// the request says otherwise.
+func convertCollapse(collapse *parser.Collapse) *Collapse {
+	if collapse == nil {
+		return nil
+	}
+
+	result := &Collapse{Field: collapse.Field}
+	if inner := collapse.InnerHits; inner != nil {
+		result.InnerHitsName = inner.Name
+		if result.InnerHitsName == "" {
+			result.InnerHitsName = collapse.Field
+		}
+		result.InnerHitsSize = parser.DefaultInnerHitsSize
+		if inner.Size != nil {
+			result.InnerHitsSize = *inner.Size
+		}
+	}
+	return result
+}
+
 // convertSort converts sort specification to LogicalSort
 func (c *Converter) convertSort(sort []map[string]interface{}, child LogicalPlan) (*LogicalSort, error) {
 	sortFields := make([]*SortField, 0, len(sort))
@@ -461,6 +540,20 @@ func (c *Converter) convertSort(sort []map[string]interface{}, child LogicalPlan
 				Descending: false,
 			}
 
+			if field == SortFieldScript {
+				script, ok := order.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("_script sort must be an object")
+				}
+				udf, _ := script["udf"].(string)
+				if udf == "" {
+					return nil, fmt.Errorf("_script sort requires a [udf] name")
+				}
+				sf.Script = &ScriptSort{UDF: udf}
+				sf.Script.Version, _ = script["version"].(string)
+				sf.Script.Params, _ = script["params"].(map[string]interface{})
+			}
+
 			// Parse order
 			switch orderValue := order.(type) {
 			case string:
@@ -474,6 +567,17 @@ func (c *Converter) convertSort(sort []map[string]interface{}, child LogicalPlan
 						sf.Descending = true
 					}
 				}
+				if missing, ok := orderValue["missing"]; ok && missing != nil {
+					sf.Missing = missing
+				}
+				if mode, ok := orderValue["mode"].(string); ok {
+					switch mode = strings.ToLower(mode); mode {
+					case SortModeMin, SortModeMax, SortModeSum, SortModeAvg, SortModeMedian:
+						sf.Mode = mode
+					default:
+						return nil, fmt.Errorf("unknown sort mode [%s] for field [%s]", mode, field)
+					}
+				}
 			}
 
 			sortFields = append(sortFields, sf)
@@ -503,14 +607,17 @@ func (c *Converter) estimateSelectivity(q parser.Query) float64 {
 		// Multiple terms, higher selectivity
 		return float64(len(query.Values)) * 0.1
 
+	case *parser.IDsQuery:
+		return min(1.0, 0.01*float64(len(query.Values))) // Each id matches at most one document
+
 	case *parser.RangeQuery:
 		return 0.3 // Assume range matches 30% of documents
 
 	case *parser.ExistsQuery:
 		return 0.8 // Assume field exists in 80% of documents
 
-	case *parser.PrefixQuery, *parser.WildcardQuery:
-		return 0.2 // Prefix/wildcard more selective
+	case *parser.PrefixQuery, *parser.WildcardQuery, *parser.RegexpQuery:
+		return 0.2 // Prefix/wildcard/regexp more selective
 
 	case *parser.MatchQuery, *parser.MatchPhraseQuery:
 		return 0.15 // Text queries moderately selective
@@ -1,8 +1,10 @@
 package planner
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/quidditch/quidditch/pkg/common/geo"
 	"github.com/quidditch/quidditch/pkg/coordination/parser"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -56,6 +58,28 @@ func TestConvertTermsQuery(t *testing.T) {
 	}
 }
 
+func TestConvertIDsQuery(t *testing.T) {
+	converter := NewConverter()
+
+	expr, err := converter.ConvertQuery(&parser.IDsQuery{Values: []string{"1", "2"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, ExprTypeBool, expr.Type)
+	require.Len(t, expr.Children, 2)
+	for i, child := range expr.Children {
+		assert.Equal(t, ExprTypeTerm, child.Type)
+		assert.Equal(t, "_id", child.Field)
+		assert.Equal(t, []string{"1", "2"}[i], child.Value)
+	}
+
+	// A single id is a plain term query
+	expr, err = converter.ConvertQuery(&parser.IDsQuery{Values: []string{"7"}})
+	require.NoError(t, err)
+	assert.Equal(t, ExprTypeTerm, expr.Type)
+	assert.Equal(t, "_id", expr.Field)
+	assert.Equal(t, "7", expr.Value)
+}
+
 func TestConvertRangeQuery(t *testing.T) {
 	converter := NewConverter()
 
@@ -91,6 +115,25 @@ func TestConvertExistsQuery(t *testing.T) {
 	assert.Equal(t, "email", expr.Field)
 }
 
+func TestConvertGeoDistanceQuery(t *testing.T) {
+	converter := NewConverter()
+
+	query := &parser.GeoDistanceQuery{
+		Field:    "location",
+		Origin:   geo.Point{Lat: 40.7, Lon: -74},
+		Distance: 1000,
+	}
+
+	expr, err := converter.ConvertQuery(query)
+
+	require.NoError(t, err)
+	assert.Equal(t, ExprTypeGeoDistance, expr.Type)
+	assert.Equal(t, "location", expr.Field)
+	params := expr.Value.(map[string]interface{})
+	assert.Equal(t, geo.Point{Lat: 40.7, Lon: -74}, params["origin"])
+	assert.Equal(t, 1000.0, params["distance"])
+}
+
 func TestConvertPrefixQuery(t *testing.T) {
 	converter := NewConverter()
 
@@ -123,6 +166,26 @@ func TestConvertWildcardQuery(t *testing.T) {
 	assert.Equal(t, "jo*n", expr.Value)
 }
 
+func TestConvertRegexpQuery(t *testing.T) {
+	converter := NewConverter()
+
+	query := &parser.RegexpQuery{
+		Field: "title",
+		Value: "qu[a-z]+k",
+	}
+
+	expr, err := converter.ConvertQuery(query)
+
+	require.NoError(t, err)
+	assert.Equal(t, ExprTypeRegexp, expr.Type)
+	assert.Equal(t, "title", expr.Field)
+	assert.Equal(t, "qu[a-z]+k", expr.Value)
+
+	jsonBytes, err := expressionToJSON(expr)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"regexp":{"title":"qu[a-z]+k"}}`, string(jsonBytes))
+}
+
 func TestConvertMatchQuery(t *testing.T) {
 	converter := NewConverter()
 
@@ -624,6 +687,61 @@ func TestConvertSortComplex(t *testing.T) {
 	assert.True(t, sort.SortFields[2].Descending)
 }
 
+func TestConvertSortMissingAndMode(t *testing.T) {
+	converter := NewConverter()
+	scan := &LogicalScan{IndexName: "products", EstimatedRows: 1000}
+
+	sortSpec := []map[string]interface{}{
+		{"price": map[string]interface{}{"order": "asc", "missing": "_first", "mode": "AVG"}},
+		{"rating": map[string]interface{}{"order": "desc", "missing": 0.0}},
+		{"name": "asc"},
+	}
+
+	sort, err := converter.convertSort(sortSpec, scan)
+	require.NoError(t, err)
+	require.Len(t, sort.SortFields, 3)
+
+	assert.Equal(t, SortMissingFirst, sort.SortFields[0].Missing)
+	assert.Equal(t, SortModeAvg, sort.SortFields[0].Mode)
+	assert.Equal(t, 0.0, sort.SortFields[1].Missing)
+	assert.Empty(t, sort.SortFields[1].Mode)
+	assert.Nil(t, sort.SortFields[2].Missing)
+
+	_, err = converter.convertSort([]map[string]interface{}{
+		{"price": map[string]interface{}{"mode": "mean"}},
+	}, scan)
+	assert.Error(t, err)
+}
+
+func TestConvertScriptSort(t *testing.T) {
+	converter := NewConverter()
+	scan := &LogicalScan{IndexName: "products", EstimatedRows: 1000}
+
+	sort, err := converter.convertSort([]map[string]interface{}{
+		{"_script": map[string]interface{}{
+			"udf":     "discounted_price",
+			"version": "1.0.0",
+			"params":  map[string]interface{}{"rate": 0.2},
+			"order":   "desc",
+		}},
+	}, scan)
+	require.NoError(t, err)
+	require.Len(t, sort.SortFields, 1)
+
+	sf := sort.SortFields[0]
+	assert.Equal(t, SortFieldScript, sf.Field)
+	assert.True(t, sf.Descending)
+	require.NotNil(t, sf.Script)
+	assert.Equal(t, "discounted_price", sf.Script.UDF)
+	assert.Equal(t, "1.0.0", sf.Script.Version)
+	assert.Equal(t, 0.2, sf.Script.Params["rate"])
+
+	_, err = converter.convertSort([]map[string]interface{}{
+		{"_script": map[string]interface{}{"order": "desc"}},
+	}, scan)
+	assert.Error(t, err)
+}
+
 func TestConvertWithOptimization(t *testing.T) {
 	converter := NewConverter()
 
@@ -703,6 +821,205 @@ func TestConvertToPhysicalPlan(t *testing.T) {
 	assert.NotNil(t, scan.EstimatedCost)
 }
 
+func TestConvertSearchRequestSize(t *testing.T) {
+	converter := NewConverter()
+	p := parser.NewQueryParser()
+
+	convert := func(body string) LogicalPlan {
+		req, err := p.ParseSearchRequest([]byte(body))
+		require.NoError(t, err)
+		plan, err := converter.ConvertSearchRequest(req, "products", []int32{0})
+		require.NoError(t, err)
+		return plan
+	}
+
+	// A missing size limits to the default
+	limit, ok := convert(`{"query": {"match_all": {}}}`).(*LogicalLimit)
+	require.True(t, ok)
+	assert.Equal(t, int64(parser.DefaultSize), limit.Limit)
+	assert.Equal(t, int64(0), limit.Offset)
+
+	// Size 0 with aggregations returns the aggregations alone
+	_, ok = convert(`{"size": 0, "aggs": {"avg_price": {"avg": {"field": "price"}}}}`).(*LogicalAggregate)
+	assert.True(t, ok)
+
+	// Size 0 without aggregations returns no hits rather than the default
+	limit, ok = convert(`{"query": {"match_all": {}}, "size": 0}`).(*LogicalLimit)
+	require.True(t, ok)
+	assert.Equal(t, int64(0), limit.Limit)
+
+	limit, ok = convert(`{"from": 30, "size": 15}`).(*LogicalLimit)
+	require.True(t, ok)
+	assert.Equal(t, int64(30), limit.Offset)
+	assert.Equal(t, int64(15), limit.Limit)
+}
+
+func TestConvertScanCollectionOptions(t *testing.T) {
+	converter := NewConverter()
+
+	reqJSON := `{
+		"query": {
+			"match": {
+				"title": "laptop"
+			}
+		},
+		"min_score": 1.5,
+		"terminate_after": 50
+	}`
+
+	p := parser.NewQueryParser()
+	req, err := p.ParseSearchRequest([]byte(reqJSON))
+	require.NoError(t, err)
+
+	logicalPlan, err := converter.ConvertSearchRequest(req, "products", []int32{0, 1})
+	require.NoError(t, err)
+
+	optimizer := NewOptimizer()
+	optimizer.RuleSet = NewRuleSet(GetDefaultRules()...)
+	optimized, err := optimizer.Optimize(logicalPlan)
+	require.NoError(t, err)
+
+	planner := NewPlanner(NewDefaultCostModel())
+	physicalPlan, err := planner.Plan(optimized)
+	require.NoError(t, err)
+
+	// The options reach the scan that fans out to the shards, under the
+	// default size
+	limit, ok := physicalPlan.(*PhysicalLimit)
+	require.True(t, ok)
+	assert.Equal(t, int64(parser.DefaultSize), limit.Limit)
+	scan, ok := limit.Child.(*PhysicalScan)
+	require.True(t, ok)
+	assert.Equal(t, 1.5, scan.MinScore)
+	assert.Equal(t, int64(50), scan.TerminateAfter)
+}
+
+func TestConvertCollapse(t *testing.T) {
+	converter := NewConverter()
+
+	p := parser.NewQueryParser()
+	req, err := p.ParseSearchRequest([]byte(`{
+		"query": {"match": {"title": "laptop"}},
+		"collapse": {"field": "user_id", "inner_hits": {}}
+	}`))
+	require.NoError(t, err)
+
+	logicalPlan, err := converter.ConvertSearchRequest(req, "products", []int32{0, 1})
+	require.NoError(t, err)
+
+	optimizer := NewOptimizer()
+	optimizer.RuleSet = NewRuleSet(GetDefaultRules()...)
+	optimized, err := optimizer.Optimize(logicalPlan)
+	require.NoError(t, err)
+
+	physicalPlan, err := NewPlanner(NewDefaultCostModel()).Plan(optimized)
+	require.NoError(t, err)
+
+	// Inner hits default to the field name and three hits per group
+	limit, ok := physicalPlan.(*PhysicalLimit)
+	require.True(t, ok)
+	scan, ok := limit.Child.(*PhysicalScan)
+	require.True(t, ok)
+	assert.Equal(t, &Collapse{Field: "user_id", InnerHitsName: "user_id", InnerHitsSize: 3}, scan.Collapse)
+}
+
+func TestConvertNamedQueries(t *testing.T) {
+	converter := NewConverter()
+
+	query, err := parser.NewQueryParser().ParseQuery(map[string]interface{}{
+		"bool": map[string]interface{}{
+			"should": []interface{}{
+				map[string]interface{}{"term": map[string]interface{}{
+					"status": map[string]interface{}{"value": "active", "_name": "is_active"},
+				}},
+				map[string]interface{}{"match": map[string]interface{}{
+					"title": map[string]interface{}{"query": "laptop", "_name": "mentions_laptop"},
+				}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	expr, err := converter.ConvertQuery(query)
+	require.NoError(t, err)
+	require.Len(t, expr.Children, 2)
+	assert.Equal(t, "is_active", expr.Children[0].Name)
+	assert.Equal(t, "mentions_laptop", expr.Children[1].Name)
+
+	// Names travel to the shards in the field objects of the clauses
+	jsonBytes, err := expressionToJSON(expr)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"bool": {"should": [
+		{"term": {"status": {"value": "active", "_name": "is_active"}}},
+		{"match": {"title": {"query": "laptop", "_name": "mentions_laptop"}}}
+	]}}`, string(jsonBytes))
+}
+
+func TestConvertMinimumShouldMatch(t *testing.T) {
+	converter := NewConverter()
+
+	shouldClauses := func(n int) []parser.Query {
+		clauses := make([]parser.Query, n)
+		for i := range clauses {
+			clauses[i] = &parser.TermQuery{Field: "tag", Value: fmt.Sprintf("t%d", i)}
+		}
+		return clauses
+	}
+
+	tests := []struct {
+		spec    string
+		clauses int
+		want    int
+	}{
+		{"75%", 4, 3},
+		{"75%", 10, 7},
+		{"-25%", 10, 8},
+		{"2<-25% 9<-3", 2, 2},
+		{"2<-25% 9<-3", 6, 5},
+		{"2<-25% 9<-3", 12, 9},
+	}
+	for _, tt := range tests {
+		expr, err := converter.ConvertQuery(&parser.BoolQuery{
+			Should:                shouldClauses(tt.clauses),
+			MinimumShouldMatchStr: tt.spec,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, expr.MinimumShouldMatch, "%q over %d clauses", tt.spec, tt.clauses)
+	}
+
+	// The shards get the resolved count
+	expr, err := converter.ConvertQuery(&parser.BoolQuery{
+		Must:                  []parser.Query{&parser.TermQuery{Field: "status", Value: "active"}},
+		Should:                shouldClauses(4),
+		MinimumShouldMatchStr: "50%",
+	})
+	require.NoError(t, err)
+	jsonBytes, err := expressionToJSON(expr.Children[1])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"bool": {"minimum_should_match": 2, "should": [
+		{"term": {"tag": "t0"}}, {"term": {"tag": "t1"}}, {"term": {"tag": "t2"}}, {"term": {"tag": "t3"}}
+	]}}`, string(jsonBytes))
+
+	// Without minimum_should_match the shards apply their default
+	expr, err = converter.ConvertQuery(&parser.BoolQuery{Should: shouldClauses(2)})
+	require.NoError(t, err)
+	jsonBytes, err = expressionToJSON(expr)
+	require.NoError(t, err)
+	assert.NotContains(t, string(jsonBytes), "minimum_should_match")
+
+	// Rows filtered on the coordinator need as many matching clauses
+	assert.True(t, evaluateExpression(&Expression{
+		Type:               ExprTypeBool,
+		Children:           []*Expression{{Type: ExprTypeExists, Field: "a"}, {Type: ExprTypeExists, Field: "b"}},
+		MinimumShouldMatch: 2,
+	}, map[string]interface{}{"a": 1, "b": 2}))
+	assert.False(t, evaluateExpression(&Expression{
+		Type:               ExprTypeBool,
+		Children:           []*Expression{{Type: ExprTypeExists, Field: "a"}, {Type: ExprTypeExists, Field: "b"}},
+		MinimumShouldMatch: 2,
+	}, map[string]interface{}{"a": 1}))
+}
+
 func TestFullPipelineEndToEnd(t *testing.T) {
 	// This test demonstrates the complete pipeline:
 	// JSON → Parser → Converter → Logical Plan → Optimizer → Physical Plan
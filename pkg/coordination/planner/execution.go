@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/quidditch/quidditch/pkg/coordination/executor"
 	"go.uber.org/zap"
@@ -43,11 +44,14 @@ func GetExecutionContext(ctx context.Context) (*ExecutionContext, error) {
 // convertExecutorResultToExecution converts executor.SearchResult to ExecutionResult
 func convertExecutorResultToExecution(result *executor.SearchResult) *ExecutionResult {
 	execResult := &ExecutionResult{
-		Rows:         make([]map[string]interface{}, len(result.Hits)),
-		TotalHits:    result.TotalHits,
-		MaxScore:     result.MaxScore,
-		Aggregations: make(map[string]*AggregationResult),
-		TookMillis:   result.TookMillis,
+		Rows:            make([]map[string]interface{}, len(result.Hits)),
+		TotalHits:       result.TotalHits,
+		MaxScore:        result.MaxScore,
+		Aggregations:    make(map[string]*AggregationResult),
+		TookMillis:      result.TookMillis,
+		Shards:          result.Shards,
+		TimedOut:        result.TimedOut,
+		TerminatedEarly: result.TerminatedEarly,
 	}
 
 	// Convert hits to rows
@@ -58,6 +62,12 @@ func convertExecutorResultToExecution(result *executor.SearchResult) *ExecutionR
 		}
 		row["_id"] = hit.ID
 		row["_score"] = hit.Score
+		if len(hit.Sort) > 0 {
+			row[SortValuesField] = hit.Sort
+		}
+		if len(hit.MatchedQueries) > 0 {
+			row[MatchedQueriesField] = hit.MatchedQueries
+		}
 		execResult.Rows[i] = row
 	}
 
@@ -131,6 +141,48 @@ func expressionToJSON(expr *Expression) ([]byte, error) {
 
 // expressionToMap converts an Expression to a map for JSON serialization
 func expressionToMap(expr *Expression) map[string]interface{} {
+	query := expressionQueryMap(expr)
+	if expr.Name != "" {
+		nameQuery(query, expr.Name)
+	}
+	return query
+}
+
+// nameQuery adds _name to a query map where the query DSL puts it: in the
+// field object of single-field queries, otherwise in the query body
+func nameQuery(query map[string]interface{}, name string) {
+	for queryType, body := range query {
+		bodyMap, ok := body.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		switch queryType {
+		case "term", "match", "range", "prefix", "wildcard", "regexp":
+			valueKey := "value"
+			if queryType == "match" {
+				valueKey = "query"
+			}
+			for field, value := range bodyMap {
+				// Copy rather than modify the expression's own value map
+				fieldMap := map[string]interface{}{"_name": name}
+				if m, ok := value.(map[string]interface{}); ok {
+					for k, v := range m {
+						fieldMap[k] = v
+					}
+				} else {
+					fieldMap[valueKey] = value
+				}
+				bodyMap[field] = fieldMap
+			}
+		default:
+			bodyMap["_name"] = name
+		}
+	}
+}
+
+// expressionQueryMap converts an Expression to a query DSL map
+func expressionQueryMap(expr *Expression) map[string]interface{} {
 	switch expr.Type {
 	case ExprTypeMatchAll:
 		return map[string]interface{}{
@@ -179,6 +231,26 @@ func expressionToMap(expr *Expression) map[string]interface{} {
 			},
 		}
 
+	case ExprTypeRegexp:
+		return map[string]interface{}{
+			"regexp": map[string]interface{}{
+				expr.Field: expr.Value,
+			},
+		}
+
+	case ExprTypeGeoDistance:
+		params, _ := expr.Value.(map[string]interface{})
+		query := map[string]interface{}{
+			expr.Field: params["origin"],
+			"distance": fmt.Sprintf("%gm", params["distance"]),
+		}
+		if render, _ := params["render_distance"].(bool); render {
+			query["render_distance"] = true
+		}
+		return map[string]interface{}{
+			"geo_distance": query,
+		}
+
 	case ExprTypeBool:
 		boolQuery := make(map[string]interface{})
 
@@ -200,6 +272,9 @@ func expressionToMap(expr *Expression) map[string]interface{} {
 			}
 			boolQuery["should"] = should
 		}
+		if expr.MinimumShouldMatch > 0 {
+			boolQuery["minimum_should_match"] = expr.MinimumShouldMatch
+		}
 
 		return map[string]interface{}{
 			"bool": boolQuery,
@@ -251,10 +326,15 @@ func evaluateExpression(expr *Expression, doc map[string]interface{}) bool {
 			return !evaluateExpression(expr.Children[0], doc)
 		}
 
-		// For OR (should)
+		// For OR (should), of which at least minimum_should_match match
+		required := max(1, expr.MinimumShouldMatch)
+		matched := 0
 		for _, child := range expr.Children {
 			if evaluateExpression(child, doc) {
-				return true
+				matched++
+				if matched >= required {
+					return true
+				}
 			}
 		}
 		return false
@@ -303,10 +383,27 @@ func sortRows(rows []map[string]interface{}, sortFields []*SortField) []map[stri
 	sorted := make([]map[string]interface{}, len(rows))
 	copy(sorted, rows)
 
+	// Script sort keys arrive from the shards in the order of the script
+	// sort fields
+	scriptIndex := make(map[*SortField]int)
+	for _, sf := range sortFields {
+		if sf.Script != nil {
+			scriptIndex[sf] = len(scriptIndex)
+		}
+	}
+
 	sort.SliceStable(sorted, func(i, j int) bool {
 		for _, sf := range sortFields {
-			vi := getFieldValue(sorted[i], sf.Field)
-			vj := getFieldValue(sorted[j], sf.Field)
+			vi, iok := sortValue(sorted[i], sf, scriptIndex)
+			vj, jok := sortValue(sorted[j], sf, scriptIndex)
+
+			// Rows missing the field go first or last regardless of order
+			if !iok || !jok {
+				if iok == jok {
+					continue
+				}
+				return !iok == (sf.Missing == SortMissingFirst)
+			}
 
 			cmp := compareValues(vi, vj)
 			if cmp != 0 {
@@ -322,11 +419,171 @@ func sortRows(rows []map[string]interface{}, sortFields []*SortField) []map[stri
 	return sorted
 }
 
-// getFieldValue gets a field value from a document, handling special fields
+// sortValue returns the value a row is sorted on for sf, reducing
+// multi-valued fields with the sort mode. ok is false when the row has no
+// value and sf.Missing is a placement rather than a substitute value.
+func sortValue(row map[string]interface{}, sf *SortField, scriptIndex map[*SortField]int) (interface{}, bool) {
+	var value interface{}
+	if sf.Script != nil {
+		values, _ := row[SortValuesField].([]float64)
+		if idx := scriptIndex[sf]; idx < len(values) {
+			value = values[idx]
+		}
+	} else {
+		value = getFieldValue(row, sf.Field)
+	}
+	if values, isArray := value.([]interface{}); isArray {
+		value = reduceSortValues(values, sf)
+	}
+	if value != nil {
+		return value, true
+	}
+
+	switch sf.Missing {
+	case nil, SortMissingFirst, SortMissingLast:
+		return nil, false
+	default:
+		return sf.Missing, true
+	}
+}
+
+// collapseRows keeps the best-scoring row per distinct value of the collapse
+// field. Rows must be in score order, as merged from the shards. With inner
+// hits requested, each kept row gets the top rows of its group (itself
+// included) under InnerHitsField.
+func collapseRows(rows []map[string]interface{}, collapse *Collapse) []map[string]interface{} {
+	collapsed := make([]map[string]interface{}, 0, len(rows))
+	groups := make(map[string]int) // collapse key -> index in collapsed
+	innerHits := make(map[int][]map[string]interface{})
+
+	for _, row := range rows {
+		key, err := json.Marshal(getFieldValue(row, collapse.Field))
+		if err != nil {
+			key = []byte("null")
+		}
+
+		idx, seen := groups[string(key)]
+		if !seen {
+			idx = len(collapsed)
+			groups[string(key)] = idx
+			collapsed = append(collapsed, row)
+		}
+		if len(innerHits[idx]) < collapse.InnerHitsSize {
+			inner := make(map[string]interface{}, len(row))
+			for k, v := range row {
+				inner[k] = v
+			}
+			innerHits[idx] = append(innerHits[idx], inner)
+		}
+	}
+
+	for idx, hits := range innerHits {
+		collapsed[idx][InnerHitsField] = map[string][]map[string]interface{}{
+			collapse.InnerHitsName: hits,
+		}
+	}
+	return collapsed
+}
+
+// withScriptSorts attaches the script sort fields to the shard search
+// options so the data nodes compute their sort keys
+func withScriptSorts(ctx context.Context, sortFields []*SortField) (context.Context, error) {
+	var scripts []string
+	for _, sf := range sortFields {
+		if sf.Script == nil {
+			continue
+		}
+		data, err := json.Marshal(map[string]interface{}{SortFieldScript: sf.Script})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode _script sort: %w", err)
+		}
+		scripts = append(scripts, string(data))
+	}
+	if len(scripts) == 0 {
+		return ctx, nil
+	}
+
+	opts := executor.ShardSearchOptionsFromContext(ctx)
+	opts.Sort = scripts
+	return executor.WithShardSearchOptions(ctx, opts), nil
+}
+
+// reduceSortValues picks the sort value of a multi-valued field. sum, avg
+// and median apply to numbers only; other values fall back to min/max.
+func reduceSortValues(values []interface{}, sf *SortField) interface{} {
+	present := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		if v != nil {
+			present = append(present, v)
+		}
+	}
+	if len(present) == 0 {
+		return nil
+	}
+
+	mode := sf.Mode
+	if mode == "" {
+		mode = SortModeMin
+		if sf.Descending {
+			mode = SortModeMax
+		}
+	}
+
+	if mode == SortModeSum || mode == SortModeAvg || mode == SortModeMedian {
+		nums := make([]float64, 0, len(present))
+		for _, v := range present {
+			if f, ok := toFloat64(v); ok {
+				nums = append(nums, f)
+			}
+		}
+		if len(nums) == len(present) {
+			switch mode {
+			case SortModeSum, SortModeAvg:
+				sum := 0.0
+				for _, f := range nums {
+					sum += f
+				}
+				if mode == SortModeAvg {
+					return sum / float64(len(nums))
+				}
+				return sum
+			case SortModeMedian:
+				sort.Float64s(nums)
+				mid := len(nums) / 2
+				if len(nums)%2 == 0 {
+					return (nums[mid-1] + nums[mid]) / 2
+				}
+				return nums[mid]
+			}
+		}
+		mode = SortModeMin
+		if sf.Descending {
+			mode = SortModeMax
+		}
+	}
+
+	best := present[0]
+	for _, v := range present[1:] {
+		cmp := compareValues(v, best)
+		if (mode == SortModeMin && cmp < 0) || (mode == SortModeMax && cmp > 0) {
+			best = v
+		}
+	}
+	return best
+}
+
+// getFieldValue gets a field value from a document, handling special fields.
+// Dot-path names (author.age) are resolved through nested objects when the
+// document doesn't carry the flattened key itself.
 func getFieldValue(doc map[string]interface{}, field string) interface{} {
 	if value, exists := doc[field]; exists {
 		return value
 	}
+	if idx := strings.Index(field, "."); idx > 0 {
+		if nested, ok := doc[field[:idx]].(map[string]interface{}); ok {
+			return getFieldValue(nested, field[idx+1:])
+		}
+	}
 	return nil
 }
 
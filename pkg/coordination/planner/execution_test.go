@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/quidditch/quidditch/pkg/common/geo"
 	"github.com/quidditch/quidditch/pkg/coordination/executor"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -72,6 +73,7 @@ func TestConvertExecutorResultToExecution(t *testing.T) {
 					"title": "Test Document",
 					"count": 10,
 				},
+				MatchedQueries: []string{"is_active"},
 			},
 			{
 				ID:    "doc2",
@@ -113,6 +115,8 @@ func TestConvertExecutorResultToExecution(t *testing.T) {
 	assert.Equal(t, 2.5, execResult.Rows[0]["_score"])
 	assert.Equal(t, "Test Document", execResult.Rows[0]["title"])
 	assert.Equal(t, 10, execResult.Rows[0]["count"])
+	assert.Equal(t, []string{"is_active"}, execResult.Rows[0][MatchedQueriesField])
+	assert.NotContains(t, execResult.Rows[1], MatchedQueriesField)
 
 	// Check aggregations
 	assert.Len(t, execResult.Aggregations, 2)
@@ -173,6 +177,19 @@ func TestExpressionToJSON(t *testing.T) {
 			},
 			expected: `{"exists":{"field":"email"}}`,
 		},
+		{
+			name: "geo_distance",
+			expr: &Expression{
+				Type:  ExprTypeGeoDistance,
+				Field: "location",
+				Value: map[string]interface{}{
+					"origin":          geo.Point{Lat: 40.7, Lon: -74},
+					"distance":        2500.0,
+					"render_distance": false,
+				},
+			},
+			expected: `{"geo_distance":{"location":{"lat":40.7,"lon":-74},"distance":"2500m"}}`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -303,12 +320,165 @@ func TestSortRows(t *testing.T) {
 		assert.Equal(t, "3", sorted[2]["_id"]) // Age 20
 	})
 
+	t.Run("sort_by_nested_field", func(t *testing.T) {
+		nested := []map[string]interface{}{
+			{"_id": "1", "author": map[string]interface{}{"age": 41}},
+			{"_id": "2", "author": map[string]interface{}{"age": 29}},
+			{"_id": "3", "author": map[string]interface{}{"age": 35}},
+		}
+
+		sorted := sortRows(nested, []*SortField{{Field: "author.age"}})
+		assert.Equal(t, "2", sorted[0]["_id"])
+		assert.Equal(t, "3", sorted[1]["_id"])
+		assert.Equal(t, "1", sorted[2]["_id"])
+	})
+
+	t.Run("sort_with_missing_field", func(t *testing.T) {
+		sparse := []map[string]interface{}{
+			{"_id": "1", "price": 30},
+			{"_id": "2"},
+			{"_id": "3", "price": 10},
+		}
+
+		// Missing docs go last by default, whichever the order
+		sorted := sortRows(sparse, []*SortField{{Field: "price"}})
+		assert.Equal(t, []interface{}{"3", "1", "2"}, sortedIDs(sorted))
+		sorted = sortRows(sparse, []*SortField{{Field: "price", Descending: true}})
+		assert.Equal(t, []interface{}{"1", "3", "2"}, sortedIDs(sorted))
+
+		sorted = sortRows(sparse, []*SortField{{Field: "price", Descending: true, Missing: SortMissingFirst}})
+		assert.Equal(t, []interface{}{"2", "1", "3"}, sortedIDs(sorted))
+
+		// A custom missing value sorts as if the doc had it
+		sorted = sortRows(sparse, []*SortField{{Field: "price", Missing: 20}})
+		assert.Equal(t, []interface{}{"3", "2", "1"}, sortedIDs(sorted))
+	})
+
+	t.Run("sort_multi_valued_field", func(t *testing.T) {
+		multi := []map[string]interface{}{
+			{"_id": "1", "prices": []interface{}{1, 50}},
+			{"_id": "2", "prices": []interface{}{10, 20}},
+			{"_id": "3", "prices": []interface{}{5}},
+		}
+
+		// Ascending defaults to min, descending to max
+		sorted := sortRows(multi, []*SortField{{Field: "prices"}})
+		assert.Equal(t, []interface{}{"1", "3", "2"}, sortedIDs(sorted))
+		sorted = sortRows(multi, []*SortField{{Field: "prices", Descending: true}})
+		assert.Equal(t, []interface{}{"1", "2", "3"}, sortedIDs(sorted))
+
+		sorted = sortRows(multi, []*SortField{{Field: "prices", Mode: SortModeMax}})
+		assert.Equal(t, []interface{}{"3", "2", "1"}, sortedIDs(sorted))
+		sorted = sortRows(multi, []*SortField{{Field: "prices", Mode: SortModeAvg}})
+		assert.Equal(t, []interface{}{"3", "2", "1"}, sortedIDs(sorted))
+		sorted = sortRows(multi, []*SortField{{Field: "prices", Mode: SortModeSum, Descending: true}})
+		assert.Equal(t, []interface{}{"1", "2", "3"}, sortedIDs(sorted))
+
+		words := []map[string]interface{}{
+			{"_id": "1", "tags": []interface{}{"pear", "apple"}},
+			{"_id": "2", "tags": []interface{}{"banana"}},
+		}
+		sorted = sortRows(words, []*SortField{{Field: "tags"}})
+		assert.Equal(t, []interface{}{"1", "2"}, sortedIDs(sorted))
+		sorted = sortRows(words, []*SortField{{Field: "tags", Mode: SortModeMax}})
+		assert.Equal(t, []interface{}{"2", "1"}, sortedIDs(sorted))
+	})
+
 	t.Run("empty_sort_fields", func(t *testing.T) {
 		sorted := sortRows(rows, []*SortField{})
 		assert.Equal(t, rows, sorted) // No sorting applied
 	})
 }
 
+func TestPhysicalSortByScript(t *testing.T) {
+	var shardOpts executor.ShardSearchOptions
+	mockExec := &mockQueryExecutor{
+		searchFunc: func(ctx context.Context, indexName string, query []byte, filterExpr []byte, from, size int) (*executor.SearchResult, error) {
+			shardOpts = executor.ShardSearchOptionsFromContext(ctx)
+			// Hits merged from two shards, each with its UDF-computed key
+			return &executor.SearchResult{
+				TotalHits: 3,
+				Hits: []*executor.SearchHit{
+					{ID: "1", Score: 1, Source: map[string]interface{}{"price": 100.0}, Sort: []float64{40}},
+					{ID: "2", Score: 1, Source: map[string]interface{}{"price": 80.0}, Sort: []float64{75}},
+					{ID: "3", Score: 1, Source: map[string]interface{}{"price": 50.0}, Sort: []float64{50}},
+				},
+			}, nil
+		},
+	}
+	ctx := WithExecutionContext(context.Background(), &ExecutionContext{
+		QueryExecutor: mockExec,
+		Logger:        zap.NewNop(),
+	})
+
+	plan := &PhysicalSort{
+		SortFields: []*SortField{
+			{Field: SortFieldScript, Descending: true, Script: &ScriptSort{UDF: "discounted_price"}},
+		},
+		Child: &PhysicalScan{IndexName: "products", TerminateAfter: 10},
+	}
+
+	result, err := plan.Execute(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"2", "3", "1"}, sortedIDs(result.Rows))
+
+	// The script reaches the shards alongside the scan's own options
+	assert.Equal(t, []string{`{"_script":{"udf":"discounted_price"}}`}, shardOpts.Sort)
+	assert.Equal(t, int64(10), shardOpts.TerminateAfter)
+}
+
+func TestPhysicalScanCollapse(t *testing.T) {
+	var shardOpts executor.ShardSearchOptions
+	mockExec := &mockQueryExecutor{
+		searchFunc: func(ctx context.Context, indexName string, query []byte, filterExpr []byte, from, size int) (*executor.SearchResult, error) {
+			shardOpts = executor.ShardSearchOptionsFromContext(ctx)
+			// Two shards each collapsed their own hits, merged in score order
+			return &executor.SearchResult{
+				TotalHits: 9,
+				Hits: []*executor.SearchHit{
+					{ID: "1", Score: 5, Source: map[string]interface{}{"user_id": "alice"}},
+					{ID: "2", Score: 4, Source: map[string]interface{}{"user_id": "bob"}},
+					{ID: "3", Score: 3, Source: map[string]interface{}{"user_id": "alice"}},
+					{ID: "4", Score: 2, Source: map[string]interface{}{"user_id": "carol"}},
+					{ID: "5", Score: 1, Source: map[string]interface{}{"user_id": "bob"}},
+				},
+			}, nil
+		},
+	}
+	ctx := WithExecutionContext(context.Background(), &ExecutionContext{
+		QueryExecutor: mockExec,
+		Logger:        zap.NewNop(),
+	})
+
+	scan := &PhysicalScan{
+		IndexName: "posts",
+		Collapse:  &Collapse{Field: "user_id", InnerHitsName: "recent", InnerHitsSize: 2},
+	}
+	result, err := scan.Execute(ctx)
+	require.NoError(t, err)
+
+	// One hit per collapse key, the best-scoring one; the total is unchanged
+	assert.Equal(t, []interface{}{"1", "2", "4"}, sortedIDs(result.Rows))
+	assert.Equal(t, int64(9), result.TotalHits)
+	assert.Equal(t, "user_id", shardOpts.CollapseField)
+	assert.Equal(t, int32(2), shardOpts.CollapseSize)
+
+	inner, ok := result.Rows[0][InnerHitsField].(map[string][]map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{"1", "3"}, sortedIDs(inner["recent"]))
+	inner = result.Rows[2][InnerHitsField].(map[string][]map[string]interface{})
+	assert.Equal(t, []interface{}{"4"}, sortedIDs(inner["recent"]))
+}
+
+// sortedIDs returns the _id of each row in order
+func sortedIDs(rows []map[string]interface{}) []interface{} {
+	ids := make([]interface{}, len(rows))
+	for i, row := range rows {
+		ids[i] = row["_id"]
+	}
+	return ids
+}
+
 func TestApplyLimitToRows(t *testing.T) {
 	rows := []map[string]interface{}{
 		{"_id": "1"},
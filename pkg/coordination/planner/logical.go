@@ -65,10 +65,14 @@ const (
 
 // LogicalScan represents a scan operation on an index
 type LogicalScan struct {
-	IndexName        string
-	Shards           []int32
-	Filter           *Expression // Optional filter expression (pushdown)
-	EstimatedRows    int64       // Estimated number of rows
+	IndexName      string
+	Shards         []int32
+	Filter         *Expression // Optional filter expression (pushdown)
+	EstimatedRows  int64       // Estimated number of rows
+	Limit          int64       // Top hits fetched from each shard (0 = the shard default)
+	TerminateAfter int64       // Per-shard cap on collected matches (0 = no limit)
+	MinScore       float64     // Drop matches scoring below this (0 = keep all)
+	Collapse       *Collapse   // Keep the best hit per distinct field value (nil = no collapsing)
 }
 
 func (s *LogicalScan) Type() PlanType               { return PlanTypeScan }
@@ -82,7 +86,17 @@ func (s *LogicalScan) Schema() *Schema {
 }
 func (s *LogicalScan) Cardinality() int64 { return s.EstimatedRows }
 func (s *LogicalScan) String() string {
-	return fmt.Sprintf("Scan(index=%s, shards=%v, filter=%v)", s.IndexName, s.Shards, s.Filter)
+	desc := fmt.Sprintf("Scan(index=%s, shards=%v, filter=%v", s.IndexName, s.Shards, s.Filter)
+	if s.TerminateAfter > 0 {
+		desc += fmt.Sprintf(", terminate_after=%d", s.TerminateAfter)
+	}
+	if s.MinScore > 0 {
+		desc += fmt.Sprintf(", min_score=%g", s.MinScore)
+	}
+	if s.Collapse != nil {
+		desc += ", collapse=" + s.Collapse.Field
+	}
+	return desc + ")"
 }
 
 // LogicalFilter represents a filter operation
@@ -192,8 +206,60 @@ type LogicalSort struct {
 type SortField struct {
 	Field      string
 	Descending bool
+	// Missing places rows without the field: "_last" (default), "_first",
+	// or a value used in place of the missing one
+	Missing interface{}
+	// Mode picks the value of multi-valued fields: min, max, sum, avg or
+	// median (default min ascending, max descending)
+	Mode string
+	// Script computes the sort key per document with a UDF (Field is _script)
+	Script *ScriptSort
+}
+
+// ScriptSort is a sort key computed on the data nodes by calling a
+// registered UDF for each hit
+type ScriptSort struct {
+	UDF     string                 `json:"udf"`
+	Version string                 `json:"version,omitempty"`
+	Params  map[string]interface{} `json:"params,omitempty"`
 }
 
+// Collapse keeps the best-scoring row per distinct value of Field. With
+// InnerHitsSize set, each kept row carries the top rows of its group as
+// inner hits named InnerHitsName.
+type Collapse struct {
+	Field         string
+	InnerHitsName string
+	InnerHitsSize int
+}
+
+// InnerHitsField is the row field holding the inner hits of a collapsed
+// row, keyed by inner hits name
+const InnerHitsField = "_inner_hits"
+
+// SortFieldScript is the sort field name of UDF-computed sort keys
+const SortFieldScript = "_script"
+
+// SortValuesField is the row field holding the script sort values returned
+// by the shards, in the order of the script sort fields
+const SortValuesField = "_sort"
+
+// MatchedQueriesField is the row field holding the names of the named
+// query clauses a hit matched
+const MatchedQueriesField = "_matched_queries"
+
+// Sort missing-value placements and multi-value modes
+const (
+	SortMissingFirst = "_first"
+	SortMissingLast  = "_last"
+
+	SortModeMin    = "min"
+	SortModeMax    = "max"
+	SortModeSum    = "sum"
+	SortModeAvg    = "avg"
+	SortModeMedian = "median"
+)
+
 func (s *LogicalSort) Type() PlanType          { return PlanTypeSort }
 func (s *LogicalSort) Children() []LogicalPlan { return []LogicalPlan{s.Child} }
 func (s *LogicalSort) SetChild(index int, child LogicalPlan) error {
@@ -282,25 +348,42 @@ type Expression struct {
 	Field    string
 	Value    interface{}
 	Children []*Expression
+	Name     string // _name of the clause, reported per hit in matched_queries
+
+	// MinimumShouldMatch is how many children of a should bool must match,
+	// resolved at plan time; zero leaves the default of one
+	MinimumShouldMatch int
 }
 
 // ExpressionType represents the type of expression
 type ExpressionType string
 
 const (
-	ExprTypeTerm       ExpressionType = "term"
-	ExprTypeMatch      ExpressionType = "match"
-	ExprTypeRange      ExpressionType = "range"
-	ExprTypeBool       ExpressionType = "bool"
-	ExprTypeWildcard   ExpressionType = "wildcard"
-	ExprTypePrefix     ExpressionType = "prefix"
-	ExprTypeExists     ExpressionType = "exists"
-	ExprTypeMatchAll   ExpressionType = "match_all"
+	ExprTypeTerm        ExpressionType = "term"
+	ExprTypeMatch       ExpressionType = "match"
+	ExprTypeRange       ExpressionType = "range"
+	ExprTypeBool        ExpressionType = "bool"
+	ExprTypeWildcard    ExpressionType = "wildcard"
+	ExprTypePrefix      ExpressionType = "prefix"
+	ExprTypeRegexp      ExpressionType = "regexp"
+	ExprTypeExists      ExpressionType = "exists"
+	ExprTypeMatchAll    ExpressionType = "match_all"
+	ExprTypeGeoDistance ExpressionType = "geo_distance"
 )
 
 func (e *Expression) String() string {
 	if e == nil {
 		return "nil"
 	}
-	return fmt.Sprintf("%s(%s=%v)", e.Type, e.Field, e.Value)
+	desc := fmt.Sprintf("%s(%s=%v", e.Type, e.Field, e.Value)
+	if e.Name != "" {
+		desc += ", _name=" + e.Name
+	}
+	if e.MinimumShouldMatch > 0 {
+		desc += fmt.Sprintf(", minimum_should_match=%d", e.MinimumShouldMatch)
+	}
+	if len(e.Children) > 0 {
+		desc += fmt.Sprintf(", children=%v", e.Children)
+	}
+	return desc + ")"
 }
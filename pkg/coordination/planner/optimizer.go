@@ -153,10 +153,14 @@ func (r *FilterPushdownRule) Apply(plan LogicalPlan) (LogicalPlan, bool) {
 
 	// Push filter into scan
 	newScan := &LogicalScan{
-		IndexName:     scan.IndexName,
-		Shards:        scan.Shards,
-		Filter:        r.combineFilters(scan.Filter, filter.Condition),
-		EstimatedRows: filter.EstimatedRows,
+		IndexName:      scan.IndexName,
+		Shards:         scan.Shards,
+		Filter:         r.combineFilters(scan.Filter, filter.Condition),
+		EstimatedRows:  filter.EstimatedRows,
+		Limit:          scan.Limit,
+		TerminateAfter: scan.TerminateAfter,
+		MinScore:       scan.MinScore,
+		Collapse:       scan.Collapse,
 	}
 
 	return newScan, true
@@ -220,8 +224,38 @@ func NewLimitPushdownRule() *LimitPushdownRule {
 }
 
 func (r *LimitPushdownRule) Apply(plan LogicalPlan) (LogicalPlan, bool) {
-	// TODO: Implement limit pushdown
-	return nil, false
+	// A limit directly over a scan, or over a projection of one, only needs
+	// the top offset+limit hits of each shard. The limit itself stays, to
+	// pick the page from the merged hits.
+	limit, ok := plan.(*LogicalLimit)
+	if !ok || limit.Limit <= 0 {
+		return nil, false
+	}
+
+	child := limit.Child
+	project, isProject := child.(*LogicalProject)
+	if isProject {
+		child = project.Child
+	}
+	scan, ok := child.(*LogicalScan)
+	if !ok || scan.Limit == limit.Offset+limit.Limit {
+		return nil, false
+	}
+
+	newScan := *scan
+	newScan.Limit = limit.Offset + limit.Limit
+
+	var newChild LogicalPlan = &newScan
+	if isProject {
+		newProject := *project
+		newProject.Child = &newScan
+		newChild = &newProject
+	}
+	return &LogicalLimit{
+		Offset: limit.Offset,
+		Limit:  limit.Limit,
+		Child:  newChild,
+	}, true
 }
 
 // RedundantFilterEliminationRule removes redundant filters
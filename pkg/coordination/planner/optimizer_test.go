@@ -298,6 +298,42 @@ func TestComplexOptimization(t *testing.T) {
 	assert.NotNil(t, optimizedScan.Filter)
 }
 
+func TestLimitPushdownRule(t *testing.T) {
+	scan := &LogicalScan{
+		IndexName:     "products",
+		Shards:        []int32{0, 1},
+		EstimatedRows: 10000,
+	}
+	limit := &LogicalLimit{
+		Offset: 20,
+		Limit:  10,
+		Child:  &LogicalProject{Fields: []string{"title"}, Child: scan},
+	}
+
+	rule := NewLimitPushdownRule()
+	newPlan, applied := rule.Apply(limit)
+	require.True(t, applied)
+
+	// Each shard returns the hits up to the end of the page; the limit
+	// still picks the page
+	newLimit, ok := newPlan.(*LogicalLimit)
+	require.True(t, ok)
+	assert.Equal(t, int64(20), newLimit.Offset)
+	assert.Equal(t, int64(10), newLimit.Limit)
+	project, ok := newLimit.Child.(*LogicalProject)
+	require.True(t, ok)
+	assert.Equal(t, int64(30), project.Child.(*LogicalScan).Limit)
+	assert.Zero(t, scan.Limit)
+
+	// Applying it again changes nothing
+	_, applied = rule.Apply(newPlan)
+	assert.False(t, applied)
+
+	// A filter between the limit and the scan keeps every hit
+	_, applied = rule.Apply(&LogicalLimit{Limit: 10, Child: &LogicalFilter{Child: scan}})
+	assert.False(t, applied)
+}
+
 func TestTopNOptimizationRule(t *testing.T) {
 	// Create a plan: Limit -> Sort -> Scan
 	scan := &LogicalScan{
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/quidditch/quidditch/pkg/coordination/executor"
 	"go.uber.org/zap"
 )
 
@@ -45,11 +46,14 @@ type PhysicalPlan interface {
 
 // ExecutionResult represents the result of executing a physical plan
 type ExecutionResult struct {
-	Rows         []map[string]interface{} // Result rows
-	TotalHits    int64                    // Total number of matching documents
-	MaxScore     float64                  // Maximum relevance score
-	Aggregations map[string]*AggregationResult // Aggregation results
-	TookMillis   int64                    // Execution time in milliseconds
+	Rows            []map[string]interface{}      // Result rows
+	TotalHits       int64                         // Total number of matching documents
+	MaxScore        float64                       // Maximum relevance score
+	Aggregations    map[string]*AggregationResult // Aggregation results
+	TookMillis      int64                         // Execution time in milliseconds
+	Shards          executor.ShardStats           // Shards searched, zero if unknown
+	TimedOut        bool                          // The search deadline expired on some shard
+	TerminatedEarly bool                          // Some shard stopped at terminate_after
 }
 
 // AggregationResult represents the result of an aggregation
@@ -78,12 +82,16 @@ type Stats struct {
 
 // PhysicalScan represents a physical scan operation
 type PhysicalScan struct {
-	IndexName   string
-	Shards      []int32
-	Filter      *Expression
-	Fields      []string // Fields to retrieve (projection)
-	OutputSchema *Schema
-	EstimatedCost *Cost
+	IndexName      string
+	Shards         []int32
+	Filter         *Expression
+	Fields         []string  // Fields to retrieve (projection)
+	Limit          int64     // Top hits fetched from each shard (0 = the shard default)
+	TerminateAfter int64     // Per-shard cap on collected matches (0 = no limit)
+	MinScore       float64   // Drop matches scoring below this (0 = keep all)
+	Collapse       *Collapse // Keep the best hit per distinct field value (nil = no collapsing)
+	OutputSchema   *Schema
+	EstimatedCost  *Cost
 }
 
 func (s *PhysicalScan) Type() PhysicalPlanType      { return PhysicalPlanTypeScan }
@@ -98,7 +106,7 @@ func (s *PhysicalScan) Execute(ctx context.Context) (*ExecutionResult, error) {
 	}
 
 	if execCtx.Logger != nil {
-		execCtx.Logger.Info("==> PhysicalScan.Execute ENTRY",
+		execCtx.Logger.Debug("==> PhysicalScan.Execute ENTRY",
 			zap.String("index", s.IndexName),
 			zap.Int("num_shards", len(s.Shards)),
 			zap.Bool("has_filter", s.Filter != nil))
@@ -118,11 +126,31 @@ func (s *PhysicalScan) Execute(ctx context.Context) (*ExecutionResult, error) {
 	}
 
 	if execCtx.Logger != nil {
-		execCtx.Logger.Info("Calling QueryExecutor.ExecuteSearch",
+		execCtx.Logger.Debug("Calling QueryExecutor.ExecuteSearch",
 			zap.String("index", s.IndexName),
 			zap.String("query", string(queryBytes)))
 	}
 
+	// Shards return the top hits of a pushed down limit, drop matches
+	// below min_score, stop collecting once terminate_after matches are
+	// found and collapse their hits, keeping enough per group for the inner
+	// hits
+	size := 10000 // large enough to get all results for this node
+	if s.Limit > 0 || s.TerminateAfter > 0 || s.MinScore > 0 || s.Collapse != nil {
+		opts := executor.ShardSearchOptionsFromContext(ctx)
+		if s.Limit > 0 {
+			size = int(s.Limit)
+			opts.Size = int32(s.Limit)
+		}
+		opts.TerminateAfter = s.TerminateAfter
+		opts.MinScore = s.MinScore
+		if s.Collapse != nil {
+			opts.CollapseField = s.Collapse.Field
+			opts.CollapseSize = int32(max(1, s.Collapse.InnerHitsSize))
+		}
+		ctx = executor.WithShardSearchOptions(ctx, opts)
+	}
+
 	// Execute distributed search via QueryExecutor
 	// Note: QueryExecutor handles pagination internally, but for scan we want all results
 	executorResult, err := execCtx.QueryExecutor.ExecuteSearch(
@@ -131,7 +159,7 @@ func (s *PhysicalScan) Execute(ctx context.Context) (*ExecutionResult, error) {
 		queryBytes,
 		nil, // filterExpression (separate from query)
 		0,   // from
-		10000, // size (large enough to get all results for this node)
+		size,
 	)
 	if err != nil {
 		if execCtx.Logger != nil {
@@ -141,13 +169,19 @@ func (s *PhysicalScan) Execute(ctx context.Context) (*ExecutionResult, error) {
 	}
 
 	if execCtx.Logger != nil {
-		execCtx.Logger.Info("QueryExecutor.ExecuteSearch SUCCESS",
+		execCtx.Logger.Debug("QueryExecutor.ExecuteSearch SUCCESS",
 			zap.Int64("total_hits", executorResult.TotalHits),
 			zap.Int("hits_returned", len(executorResult.Hits)))
 	}
 
 	// Convert executor result to execution result
-	return convertExecutorResultToExecution(executorResult), nil
+	result := convertExecutorResultToExecution(executorResult)
+
+	// Each shard collapsed its own hits; keep the best across shards
+	if s.Collapse != nil {
+		result.Rows = collapseRows(result.Rows, s.Collapse)
+	}
+	return result, nil
 }
 func (s *PhysicalScan) String() string {
 	return fmt.Sprintf("PhysicalScan(index=%s, shards=%v, filter=%v)", s.IndexName, s.Shards, s.Filter)
@@ -284,6 +318,11 @@ func (s *PhysicalSort) Children() []PhysicalPlan { return []PhysicalPlan{s.Child
 func (s *PhysicalSort) Schema() *Schema          { return s.OutputSchema }
 func (s *PhysicalSort) Cost() *Cost              { return s.EstimatedCost }
 func (s *PhysicalSort) Execute(ctx context.Context) (*ExecutionResult, error) {
+	ctx, err := withScriptSorts(ctx, s.SortFields)
+	if err != nil {
+		return nil, err
+	}
+
 	// Execute child and sort results
 	childResult, err := s.Child.Execute(ctx)
 	if err != nil {
@@ -344,6 +383,11 @@ func (t *PhysicalTopN) Children() []PhysicalPlan { return []PhysicalPlan{t.Child
 func (t *PhysicalTopN) Schema() *Schema          { return t.OutputSchema }
 func (t *PhysicalTopN) Cost() *Cost              { return t.EstimatedCost }
 func (t *PhysicalTopN) Execute(ctx context.Context) (*ExecutionResult, error) {
+	ctx, err := withScriptSorts(ctx, t.SortFields)
+	if err != nil {
+		return nil, err
+	}
+
 	// Execute child
 	childResult, err := t.Child.Execute(ctx)
 	if err != nil {
@@ -399,12 +443,16 @@ func (p *Planner) Plan(logical LogicalPlan) (PhysicalPlan, error) {
 func (p *Planner) planScan(logical *LogicalScan) (PhysicalPlan, error) {
 	cost := p.CostModel.EstimateScanCost(logical)
 	return &PhysicalScan{
-		IndexName:     logical.IndexName,
-		Shards:        logical.Shards,
-		Filter:        logical.Filter,
-		Fields:        []string{}, // TODO: Get from projection
-		OutputSchema:  logical.Schema(),
-		EstimatedCost: cost,
+		IndexName:      logical.IndexName,
+		Shards:         logical.Shards,
+		Filter:         logical.Filter,
+		Fields:         []string{}, // TODO: Get from projection
+		Limit:          logical.Limit,
+		TerminateAfter: logical.TerminateAfter,
+		MinScore:       logical.MinScore,
+		Collapse:       logical.Collapse,
+		OutputSchema:   logical.Schema(),
+		EstimatedCost:  cost,
 	}, nil
 }
 
@@ -145,6 +145,9 @@ func (qp *QueryPlanner) analyzeComplexity(query parser.Query) int {
 			complexity = 10 + len(q.Values)
 		}
 
+	case *parser.IDsQuery:
+		complexity = 5 + len(q.Values)
+
 	case *parser.RangeQuery:
 		complexity = 15
 
@@ -164,7 +167,7 @@ func (qp *QueryPlanner) analyzeComplexity(query parser.Query) int {
 			complexity += qp.analyzeComplexity(filter)
 		}
 
-	case *parser.WildcardQuery, *parser.QueryStringQuery:
+	case *parser.WildcardQuery, *parser.RegexpQuery, *parser.QueryStringQuery:
 		complexity = 30 // Expensive operations
 
 	case *parser.FuzzyQuery:
@@ -173,6 +176,9 @@ func (qp *QueryPlanner) analyzeComplexity(query parser.Query) int {
 	case *parser.PrefixQuery:
 		complexity = 20
 
+	case *parser.GeoDistanceQuery:
+		complexity = 20 // Bounding box plus a distance check per candidate
+
 	case *parser.MatchPhraseQuery:
 		complexity = 25
 
@@ -268,7 +274,8 @@ func (qp *QueryPlanner) selectShards(routing map[int32]*pb.ShardRouting, searchR
 	// - Shard statistics
 	// - Time-based partitioning
 	for shardID, shard := range routing {
-		if shard.Allocation != nil && shard.Allocation.State == pb.ShardAllocation_SHARD_STATE_STARTED {
+		state := shard.GetAllocation().GetState()
+		if state == pb.ShardAllocation_SHARD_STATE_STARTED || state == pb.ShardAllocation_SHARD_STATE_RELOCATING {
 			targetShards = append(targetShards, shardID)
 		}
 	}
@@ -351,7 +358,7 @@ func (qp *QueryPlanner) AnalyzeQuery(query parser.Query) []*OptimizationHint {
 
 	// Use type switch to check for different query types
 	switch q := query.(type) {
-	case *parser.WildcardQuery, *parser.QueryStringQuery:
+	case *parser.WildcardQuery, *parser.RegexpQuery, *parser.QueryStringQuery:
 		hints = append(hints, &OptimizationHint{
 			Type:        "expensive_query",
 			Description: "Wildcard and regexp queries are expensive. Consider using prefix or term queries.",
@@ -0,0 +1,74 @@
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/coordination/parser"
+)
+
+// expandFieldPatterns resolves the field patterns of the query_string and
+// simple_query_string queries in a search, such as the default *, to the
+// text and keyword fields the index maps, since shards only search fields
+// by name. The index's mapping is only fetched when the query has a
+// pattern.
+func (qs *QueryService) expandFieldPatterns(ctx context.Context, indexName string, searchReq *parser.SearchRequest) (*parser.SearchRequest, error) {
+	var fields []string
+	var metadataErr error
+	loaded := false
+	resolve := func(pattern string) ([]string, error) {
+		if !loaded {
+			loaded = true
+			metadata, err := qs.masterClient.GetIndexMetadata(ctx, indexName)
+			if err != nil {
+				metadataErr = fmt.Errorf("failed to get index metadata: %w", err)
+				return nil, metadataErr
+			}
+			fields = searchableFields(metadata.GetMetadata().GetMappings(), "")
+		}
+
+		var matched []string
+		for _, field := range fields {
+			if ok, _ := path.Match(pattern, field); ok {
+				matched = append(matched, field)
+			}
+		}
+		if len(matched) == 0 {
+			return nil, fmt.Errorf("index [%s] maps no text or keyword fields matching [%s]; set default_field or fields", indexName, pattern)
+		}
+		return matched, nil
+	}
+
+	query, err := parser.ExpandFieldPatterns(searchReq.ParsedQuery, resolve)
+	if metadataErr != nil {
+		return nil, metadataErr
+	}
+	if err != nil {
+		return nil, parsingError(fmt.Errorf("failed to parse query: %w", err))
+	}
+	if query == searchReq.ParsedQuery {
+		return searchReq, nil
+	}
+	expanded := *searchReq
+	expanded.ParsedQuery = query
+	return &expanded, nil
+}
+
+// searchableFields returns the dot paths of the text and keyword fields of
+// a mapping, in order
+func searchableFields(mappings map[string]*pb.FieldMapping, prefix string) []string {
+	var fields []string
+	for name, field := range mappings {
+		switch fieldType(field) {
+		case "text", "keyword":
+			fields = append(fields, prefix+name)
+		case "object", "nested":
+			fields = append(fields, searchableFields(field.Properties, prefix+name+".")...)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -7,11 +7,17 @@ package coordination
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 
 	pb "github.com/quidditch/quidditch/pkg/common/proto"
 	"github.com/quidditch/quidditch/pkg/coordination/executor"
+	"github.com/quidditch/quidditch/pkg/coordination/parser"
 	"github.com/quidditch/quidditch/pkg/coordination/pipeline"
+	"github.com/quidditch/quidditch/pkg/coordination/pipeline/stages"
+	"github.com/quidditch/quidditch/pkg/data/suggest"
+	"github.com/quidditch/quidditch/pkg/wasm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -37,6 +43,10 @@ func (m *mockPipelineQueryExecutor) ExecuteSearch(ctx context.Context, indexName
 	}, nil
 }
 
+func (m *mockPipelineQueryExecutor) ExecuteSuggest(ctx context.Context, indexName string, block map[string]interface{}) (map[string][]suggest.Entry, error) {
+	return map[string][]suggest.Entry{}, nil
+}
+
 // Mock master client for pipeline testing
 type mockPipelineMasterClient struct {
 	getShardRoutingFunc  func(ctx context.Context, indexName string) (map[int32]*pb.ShardRouting, error)
@@ -214,6 +224,86 @@ func TestQueryPipeline_FailureGracefulDegradation(t *testing.T) {
 	assert.Len(t, result.Hits, 3)
 }
 
+func TestQueryPipeline_InvalidOutput(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform func(req map[string]interface{})
+		errSubstr string
+	}{
+		{
+			name: "UnknownField",
+			transform: func(req map[string]interface{}) {
+				req["querry"] = req["query"]
+			},
+			errSubstr: "unknown field",
+		},
+		{
+			name: "UnparseableQuery",
+			transform: func(req map[string]interface{}) {
+				req["query"] = map[string]interface{}{"no_such_query": map[string]interface{}{}}
+			},
+			errSubstr: "failed to parse query",
+		},
+		{
+			name: "InvalidQuery",
+			transform: func(req map[string]interface{}) {
+				req["query"] = map[string]interface{}{"bool": map[string]interface{}{}}
+			},
+			errSubstr: "query validation failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zap.NewNop()
+			searched := false
+			mockExec := &mockPipelineQueryExecutor{
+				executeFunc: func(ctx context.Context, indexName string, query []byte, filterExpr []byte, from, size int) (*executor.SearchResult, error) {
+					searched = true
+					return &executor.SearchResult{}, nil
+				},
+			}
+			qs := NewQueryService(mockExec, &mockPipelineMasterClient{}, logger)
+			registry := pipeline.NewRegistry(logger)
+			qs.SetPipelineComponents(registry, pipeline.NewExecutor(registry, logger))
+
+			require.NoError(t, registry.Register(&pipeline.PipelineDefinition{
+				Name:    "broken-rewrite",
+				Version: "1.0.0",
+				Type:    pipeline.PipelineTypeQuery,
+				Stages: []pipeline.StageDefinition{
+					{Name: "rewrite", Type: pipeline.StageTypeNative, Enabled: true, Config: map[string]interface{}{"function": "test"}},
+				},
+				Enabled: true,
+			}))
+			pipe, err := registry.Get("broken-rewrite")
+			require.NoError(t, err)
+			pipe.(*pipeline.PipelineImpl).SetStages([]pipeline.Stage{&mockPipelineStage{
+				name:      "rewrite",
+				stageType: pipeline.StageTypeNative,
+				executeFunc: func(ctx *pipeline.StageContext, input interface{}) (interface{}, error) {
+					req := input.(map[string]interface{})
+					tt.transform(req)
+					return req, nil
+				},
+			}})
+			require.NoError(t, registry.AssociatePipeline("test-index", pipeline.PipelineTypeQuery, "broken-rewrite"))
+
+			requestBody := []byte(`{"query": {"match": {"title": "laptop"}}, "size": 10}`)
+			result, err := qs.ExecuteSearch(context.Background(), "test-index", requestBody)
+			require.Error(t, err)
+			assert.Nil(t, result)
+			assert.False(t, searched, "original query must not run after an invalid rewrite")
+
+			var outputErr *QueryPipelineOutputError
+			require.True(t, errors.As(err, &outputErr))
+			assert.Equal(t, "broken-rewrite", outputErr.Pipeline)
+			assert.Equal(t, "test-index", outputErr.Index)
+			assert.Contains(t, outputErr.Cause.Error(), tt.errSubstr)
+		})
+	}
+}
+
 func TestResultPipeline_NoConfigured(t *testing.T) {
 	qs, _, _ := setupQueryPipelineTest()
 
@@ -294,6 +384,65 @@ func TestResultPipeline_ReRanking(t *testing.T) {
 	assert.Equal(t, "doc1", result.Hits[2].ID) // Was first, now last
 }
 
+// scoringUDFCaller scores a hit by the length of its title plus a boosted original score
+type scoringUDFCaller struct {
+	calls int
+}
+
+func (c *scoringUDFCaller) Call(ctx context.Context, name, version string, docCtx *wasm.DocumentContext, params map[string]wasm.Value) ([]wasm.Value, error) {
+	c.calls++
+	title, _ := docCtx.GetFieldString("title")
+	boost, err := params["boost"].AsFloat64()
+	if err != nil {
+		return nil, err
+	}
+	return []wasm.Value{wasm.NewF64Value(float64(len(title)) + docCtx.GetScore()*boost)}, nil
+}
+
+func TestResultPipeline_UDFRerankStage(t *testing.T) {
+	qs, registry, _ := setupQueryPipelineTest()
+
+	caller := &scoringUDFCaller{}
+	registry.SetStageFactory(stages.NewStageBuilder(caller, zap.NewNop()))
+
+	resultPipelineDef := &pipeline.PipelineDefinition{
+		Name:    "udf-reranker",
+		Version: "1.0.0",
+		Type:    pipeline.PipelineTypeResult,
+		Stages: []pipeline.StageDefinition{
+			{
+				Name:    "rerank",
+				Type:    pipeline.StageTypeNative,
+				Enabled: true,
+				Config: map[string]interface{}{
+					"function":   stages.RerankFunction,
+					"udf_name":   "title_score",
+					"parameters": map[string]interface{}{"boost": 1.0},
+				},
+			},
+		},
+		Enabled: true,
+	}
+	require.NoError(t, registry.Register(resultPipelineDef))
+	require.NoError(t, registry.AssociatePipeline("test-index", pipeline.PipelineTypeResult, "udf-reranker"))
+
+	requestBody := []byte(`{"query": {"match": {"title": "laptop"}}, "size": 10}`)
+
+	result, err := qs.ExecuteSearch(context.Background(), "test-index", requestBody)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+
+	// laptop: 6+1.0, notebook: 8+0.9, computer: 8+0.8
+	assert.Equal(t, 3, caller.calls)
+	assert.Equal(t, int64(3), result.TotalHits)
+	require.Len(t, result.Hits, 3)
+	assert.Equal(t, "doc2", result.Hits[0].ID)
+	assert.Equal(t, "doc3", result.Hits[1].ID)
+	assert.Equal(t, "doc1", result.Hits[2].ID)
+	assert.InDelta(t, 8.9, result.Hits[0].Score, 1e-9)
+	assert.InDelta(t, 8.9, result.MaxScore, 1e-9)
+}
+
 func TestResultPipeline_ScoreModification(t *testing.T) {
 	qs, registry, _ := setupQueryPipelineTest()
 
@@ -414,6 +563,110 @@ func TestResultPipeline_FailureGracefulDegradation(t *testing.T) {
 	assert.Equal(t, "doc1", result.Hits[0].ID)
 }
 
+func TestResultPipeline_PreservesAggregations(t *testing.T) {
+	original := &SearchResult{
+		TookMillis: 12,
+		TotalHits:  2,
+		MaxScore:   1.5,
+		Hits: []*SearchHit{
+			{
+				ID: "doc1", Score: 1.5, Source: map[string]interface{}{"category": "books"},
+				Sort:           []float64{3, 1.5},
+				MatchedQueries: []string{"cheap", "in_stock"},
+				InnerHits: map[string][]*SearchHit{
+					"same_category": {{ID: "doc3", Score: 1.2, Source: map[string]interface{}{"category": "books"}}},
+				},
+			},
+			{ID: "doc2", Score: 0.5, Source: map[string]interface{}{"category": "music"}},
+		},
+		Aggregations: map[string]*AggregationResult{
+			"categories": {
+				Type: "terms",
+				Buckets: []*AggregationBucket{
+					{
+						Key:      "books",
+						DocCount: 7,
+						SubAggs: map[string]*AggregationResult{
+							"avg_price": {Type: "avg", Value: 12.5},
+						},
+					},
+					{Key: "music", DocCount: 3, SubAggs: map[string]*AggregationResult{}},
+				},
+			},
+			"price_stats": {Type: "stats", Count: 10, Min: 1, Max: 40, Avg: 15, Sum: 150},
+		},
+		Shards: &ShardInfo{Total: 4, Successful: 3, Skipped: 0, Failed: 1},
+	}
+
+	stages := map[string]func(input interface{}) (interface{}, error){
+		"NoOp": func(input interface{}) (interface{}, error) {
+			return input, nil
+		},
+		// Script stages hand back JSON-decoded maps, where every number is a float64
+		"JSONRoundTrip": func(input interface{}) (interface{}, error) {
+			data, err := json.Marshal(input)
+			if err != nil {
+				return nil, err
+			}
+			var output map[string]interface{}
+			err = json.Unmarshal(data, &output)
+			return output, err
+		},
+	}
+
+	for name, execute := range stages {
+		t.Run(name, func(t *testing.T) {
+			qs, registry, _ := setupQueryPipelineTest()
+
+			require.NoError(t, registry.Register(&pipeline.PipelineDefinition{
+				Name:    "passthrough",
+				Version: "1.0.0",
+				Type:    pipeline.PipelineTypeResult,
+				Stages: []pipeline.StageDefinition{
+					{Name: "passthrough", Type: pipeline.StageTypeNative, Enabled: true, Config: map[string]interface{}{"function": "test"}},
+				},
+				Enabled: true,
+			}))
+			pipe, err := registry.Get("passthrough")
+			require.NoError(t, err)
+			execute := execute
+			pipe.(*pipeline.PipelineImpl).SetStages([]pipeline.Stage{&mockPipelineStage{
+				name:      "passthrough",
+				stageType: pipeline.StageTypeNative,
+				executeFunc: func(ctx *pipeline.StageContext, input interface{}) (interface{}, error) {
+					return execute(input)
+				},
+			}})
+			require.NoError(t, registry.AssociatePipeline("test-index", pipeline.PipelineTypeResult, "passthrough"))
+
+			result, err := qs.executeResultPipeline(context.Background(), "test-index", original, &parser.SearchRequest{Size: 10})
+			require.NoError(t, err)
+
+			assert.Equal(t, original.TotalHits, result.TotalHits)
+			assert.Equal(t, original.Shards, result.Shards)
+
+			// Hits keep their sort keys, matched queries and inner hits
+			assert.Equal(t, original.Hits, result.Hits)
+
+			require.Len(t, result.Aggregations, 2)
+
+			categories := result.Aggregations["categories"]
+			require.NotNil(t, categories)
+			assert.Equal(t, "terms", categories.Type)
+			require.Len(t, categories.Buckets, 2)
+			assert.Equal(t, "books", categories.Buckets[0].Key)
+			assert.Equal(t, int64(7), categories.Buckets[0].DocCount)
+			require.Contains(t, categories.Buckets[0].SubAggs, "avg_price")
+			assert.Equal(t, "avg", categories.Buckets[0].SubAggs["avg_price"].Type)
+			assert.Equal(t, 12.5, categories.Buckets[0].SubAggs["avg_price"].Value)
+			assert.Equal(t, "music", categories.Buckets[1].Key)
+			assert.Empty(t, categories.Buckets[1].SubAggs)
+
+			assert.Equal(t, original.Aggregations["price_stats"], result.Aggregations["price_stats"])
+		})
+	}
+}
+
 func TestBothPipelines_QueryAndResult(t *testing.T) {
 	qs, registry, _ := setupQueryPipelineTest()
 
@@ -1,19 +1,25 @@
 package coordination
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/common/requestid"
+	"github.com/quidditch/quidditch/pkg/common/tracing"
 	"github.com/quidditch/quidditch/pkg/coordination/cache"
 	"github.com/quidditch/quidditch/pkg/coordination/executor"
 	"github.com/quidditch/quidditch/pkg/coordination/parser"
 	"github.com/quidditch/quidditch/pkg/coordination/pipeline"
 	"github.com/quidditch/quidditch/pkg/coordination/planner"
-	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/quidditch/quidditch/pkg/data/suggest"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -83,6 +89,7 @@ type QueryService struct {
 // queryExecutorInterface defines the methods needed from query executor
 type queryExecutorInterface interface {
 	ExecuteSearch(ctx context.Context, indexName string, query []byte, filterExpr []byte, from, size int) (*executor.SearchResult, error)
+	ExecuteSuggest(ctx context.Context, indexName string, block map[string]interface{}) (map[string][]suggest.Entry, error)
 }
 
 // masterClientInterface defines the methods needed from master client
@@ -140,14 +147,60 @@ func (qs *QueryService) SetPipelineComponents(registry *pipeline.Registry, execu
 	qs.pipelineExecutor = executor
 }
 
+// SetAllowUnboundedRegexp controls whether regexp queries may start with an
+// unbounded wildcard such as .*
+func (qs *QueryService) SetAllowUnboundedRegexp(allow bool) {
+	qs.queryParser.SetAllowUnboundedRegexp(allow)
+}
+
+// QueryPipelineOutputError is returned when the output of a query pipeline
+// can't be parsed back into a valid SearchRequest
+type QueryPipelineOutputError struct {
+	Pipeline string
+	Index    string
+	Cause    error
+}
+
+// Error implements error interface
+func (e *QueryPipelineOutputError) Error() string {
+	return fmt.Sprintf("query pipeline '%s' for index '%s' produced an invalid search request: %v",
+		e.Pipeline, e.Index, e.Cause)
+}
+
+// Unwrap returns the underlying cause
+func (e *QueryPipelineOutputError) Unwrap() error {
+	return e.Cause
+}
+
+// PartialSearchResultsError is returned when some shards failed and the
+// request set allow_partial_search_results to false
+type PartialSearchResultsError struct {
+	Index    string
+	Total    int
+	Failures []ShardFailure
+}
+
+// Error implements error interface
+func (e *PartialSearchResultsError) Error() string {
+	msg := fmt.Sprintf("%d of %d shards failed for index '%s' and partial search results are not allowed",
+		len(e.Failures), e.Total, e.Index)
+	if len(e.Failures) > 0 {
+		msg += ": " + e.Failures[0].Reason
+	}
+	return msg
+}
+
 // SearchResult represents a search result with all metadata
 type SearchResult struct {
-	TookMillis   int64
-	TotalHits    int64
-	MaxScore     float64
-	Hits         []*SearchHit
-	Aggregations map[string]*AggregationResult
-	Shards       *ShardInfo
+	TookMillis      int64
+	TimedOut        bool
+	TerminatedEarly bool
+	TotalHits       int64
+	MaxScore        float64
+	Hits            []*SearchHit
+	Aggregations    map[string]*AggregationResult
+	Suggest         map[string][]suggest.Entry // Suggestions by suggester name
+	Shards          *ShardInfo
 }
 
 // SearchHit represents a single hit
@@ -155,6 +208,13 @@ type SearchHit struct {
 	ID     string
 	Score  float64
 	Source map[string]interface{}
+	Sort   []float64 // Script sort keys, when sorted by _script
+
+	// Names of the named query clauses the hit matched
+	MatchedQueries []string
+
+	// Top hits of the collapsed group, by inner hits name
+	InnerHits map[string][]*SearchHit
 }
 
 // AggregationResult represents an aggregation result
@@ -186,45 +246,43 @@ type ShardInfo struct {
 	Successful int
 	Skipped    int
 	Failed     int
+	Failures   []ShardFailure
+}
+
+// ShardFailure describes a shard that failed to return results
+type ShardFailure struct {
+	Index  string
+	Shard  int32
+	Reason string
 }
 
-// ExecuteSearch executes a search query using the complete planner pipeline
+// ExecuteSearch executes a search query using the complete planner pipeline.
+// Searches asking for hits past the index's max_result_window are rejected
+// with a ResultWindowTooLargeError.
 func (qs *QueryService) ExecuteSearch(ctx context.Context, indexName string, requestBody []byte) (*SearchResult, error) {
+	return qs.executeSearch(ctx, indexName, requestBody, true)
+}
+
+// executeSearch executes a search query, checking it against the result
+// window when checkWindow is set. Internal scrolls page past the window the
+// way a client's scroll would.
+func (qs *QueryService) executeSearch(ctx context.Context, indexName string, requestBody []byte, checkWindow bool) (*SearchResult, error) {
+	logger := requestid.Logger(ctx, qs.logger)
 	startTime := time.Now()
 
-	qs.logger.Info("==> QueryService.ExecuteSearch ENTRY",
-		zap.String("index", indexName),
-		zap.Int("body_len", len(requestBody)),
-		zap.String("body", string(requestBody)))
+	ctx, span := tracing.StartSpan(ctx, "search", attribute.String("index", indexName))
+	defer span.End()
 
 	// Step 1: Parse query
 	parseStart := time.Now()
-	var searchReq *parser.SearchRequest
-	var err error
-
-	if len(requestBody) > 0 {
-		searchReq, err = qs.queryParser.ParseSearchRequest(requestBody)
-		if err != nil {
-			qs.logger.Error("Failed to parse query", zap.Error(err))
-			return nil, fmt.Errorf("failed to parse query: %w", err)
-		}
-
-		// Validate parsed query
-		if searchReq.ParsedQuery != nil {
-			if err := qs.queryParser.Validate(searchReq.ParsedQuery); err != nil {
-				qs.logger.Error("Query validation failed", zap.Error(err))
-				return nil, fmt.Errorf("query validation failed: %w", err)
-			}
-		}
-	} else {
-		// Empty body - match all query
-		searchReq = &parser.SearchRequest{
-			ParsedQuery: &parser.MatchAllQuery{},
-			Size:        10,
-		}
+	_, parseSpan := tracing.StartSpan(ctx, "search.parse")
+	searchReq, err := qs.parseSearchRequest(requestBody)
+	tracing.EndSpan(parseSpan, err)
+	if err != nil {
+		return nil, err
 	}
 
-	qs.logger.Info("Query parsed successfully",
+	logger.Debug("Query parsed successfully",
 		zap.String("index", indexName),
 		zap.Int("size", searchReq.Size))
 
@@ -234,38 +292,155 @@ func (qs *QueryService) ExecuteSearch(ctx context.Context, indexName string, req
 	if qs.pipelineRegistry != nil && qs.pipelineExecutor != nil {
 		queryPipelineStart := time.Now()
 		modifiedReq, err := qs.executeQueryPipeline(ctx, indexName, searchReq)
-		if err != nil {
+		var outputErr *QueryPipelineOutputError
+		if errors.As(err, &outputErr) {
+			// A broken rewrite must not silently run the original query
+			logger.Error("Query pipeline produced an invalid search request",
+				zap.String("index", indexName),
+				zap.String("pipeline", outputErr.Pipeline),
+				zap.Error(outputErr.Cause))
+			return nil, err
+		} else if err != nil {
 			// Log warning but continue with original request (graceful degradation)
-			qs.logger.Warn("Query pipeline failed, continuing with original request",
+			logger.Warn("Query pipeline failed, continuing with original request",
 				zap.String("index", indexName),
 				zap.Error(err))
 		} else if modifiedReq != nil {
 			searchReq = modifiedReq
-			qs.logger.Info("Query pipeline executed successfully",
+			logger.Debug("Query pipeline executed successfully",
 				zap.String("index", indexName),
 				zap.Duration("duration", time.Since(queryPipelineStart)))
 		}
 		queryPlanningTime.WithLabelValues(indexName, "query_pipeline").Observe(time.Since(queryPipelineStart).Seconds())
 	}
 
+	// The result window and slow log threshold come from the settings of
+	// the searched indices on the master, read once per search. The window
+	// is checked after the query pipeline, which may change from and size.
+	indices := splitIndices(indexName)
+	settings := qs.indexSettings(ctx, indices)
+	if checkWindow {
+		if err := checkResultWindow(indices, settings, searchReq.From, searchReq.Size); err != nil {
+			return nil, err
+		}
+	}
+
+	// Steps 2-6 run per index; a comma-separated index list is searched
+	// index by index and merged
+	var result *SearchResult
+	var plan string
+	if len(indices) > 1 {
+		result, plan, err = qs.searchIndices(ctx, indices, searchReq, startTime)
+	} else {
+		result, plan, err = qs.searchIndex(ctx, indexName, searchReq, startTime)
+	}
+	if err != nil {
+		return nil, err
+	}
+	totalTime := time.Since(startTime)
+
+	// Step 7: Execute result pipeline if configured
+	if qs.pipelineRegistry != nil && qs.pipelineExecutor != nil {
+		resultPipelineStart := time.Now()
+		modifiedResult, err := qs.executeResultPipeline(ctx, indexName, result, searchReq)
+		if err != nil {
+			// Log warning but continue with original results (graceful degradation)
+			logger.Warn("Result pipeline failed, continuing with original results",
+				zap.String("index", indexName),
+				zap.Error(err))
+		} else if modifiedResult != nil {
+			result = modifiedResult
+			logger.Debug("Result pipeline executed successfully",
+				zap.String("index", indexName),
+				zap.Duration("duration", time.Since(resultPipelineStart)))
+		}
+		queryPlanningTime.WithLabelValues(indexName, "result_pipeline").Observe(time.Since(resultPipelineStart).Seconds())
+	}
+
+	// Step 8: Run the suggesters, which draw from the whole index rather
+	// than the query's hits
+	if len(searchReq.Suggest) > 0 {
+		suggestions, err := qs.executeSuggest(ctx, splitIndices(indexName), searchReq.Suggest)
+		if err != nil {
+			return nil, err
+		}
+		result.Suggest = suggestions
+	}
+
+	logger.Debug("Query executed successfully",
+		zap.String("index", indexName),
+		zap.Int64("total_hits", result.TotalHits),
+		zap.Int("hits_returned", len(result.Hits)),
+		zap.Duration("total_time", totalTime))
+
+	logSlowQuery(logger, indexName, requestBody, time.Since(startTime), searchSlowlogThreshold(indices, settings), result, plan)
+
+	return result, nil
+}
+
+// executeSuggest runs the suggesters of a suggest block on each index and merges
+// their suggestions
+func (qs *QueryService) executeSuggest(ctx context.Context, indices []string, block map[string]interface{}) (map[string][]suggest.Entry, error) {
+	if len(indices) == 1 {
+		return qs.queryExecutor.ExecuteSuggest(ctx, indices[0], block)
+	}
+
+	suggesters, err := suggest.Parse(block)
+	if err != nil {
+		return nil, err
+	}
+	perIndex := make(map[string][][]suggest.Entry, len(suggesters))
+	for _, index := range indices {
+		suggestions, err := qs.queryExecutor.ExecuteSuggest(ctx, index, block)
+		if err != nil {
+			return nil, fmt.Errorf("suggest failed on index %s: %w", index, err)
+		}
+		for name, entries := range suggestions {
+			perIndex[name] = append(perIndex[name], entries)
+		}
+	}
+
+	merged := make(map[string][]suggest.Entry, len(suggesters))
+	for _, suggester := range suggesters {
+		merged[suggester.Name] = suggest.Merge(suggester, perIndex[suggester.Name])
+	}
+	return merged, nil
+}
+
+// searchIndex plans and executes a search against a single index and
+// returns the result with the description of the physical plan
+func (qs *QueryService) searchIndex(ctx context.Context, indexName string, searchReq *parser.SearchRequest, startTime time.Time) (*SearchResult, string, error) {
+	logger := requestid.Logger(ctx, qs.logger)
+
 	// Step 2: Get shard routing for this index
 	routing, err := qs.masterClient.GetShardRouting(ctx, indexName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get shard routing: %w", err)
+		return nil, "", fmt.Errorf("failed to get shard routing: %w", err)
 	}
 
 	// Extract shard IDs
 	shardIDs := make([]int32, 0, len(routing))
 	for shardID, shard := range routing {
-		if shard.Allocation != nil && shard.Allocation.State == pb.ShardAllocation_SHARD_STATE_STARTED {
+		state := shard.GetAllocation().GetState()
+		if state == pb.ShardAllocation_SHARD_STATE_STARTED || state == pb.ShardAllocation_SHARD_STATE_RELOCATING {
 			shardIDs = append(shardIDs, shardID)
 		}
 	}
 
 	if len(shardIDs) == 0 {
-		return nil, fmt.Errorf("no active shards found for index %s", indexName)
+		return nil, "", fmt.Errorf("no active shards found for index %s", indexName)
 	}
 
+	// query_string and simple_query_string field patterns are resolved
+	// against this index's mapping
+	searchReq, err = qs.expandFieldPatterns(ctx, indexName, searchReq)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Steps 3-5 build the plan
+	_, planSpan := tracing.StartSpan(ctx, "search.plan", attribute.Int("shards", len(shardIDs)))
+
 	// Step 3: Check logical plan cache or convert AST to Logical Plan
 	convertStart := time.Now()
 	var logicalPlan planner.LogicalPlan
@@ -274,16 +449,17 @@ func (qs *QueryService) ExecuteSearch(ctx context.Context, indexName string, req
 	cachedLogicalPlan, found := qs.queryCache.GetLogicalPlan(indexName, searchReq, shardIDs)
 	if found {
 		logicalPlan = cachedLogicalPlan
-		qs.logger.Debug("Logical plan retrieved from cache",
+		logger.Debug("Logical plan retrieved from cache",
 			zap.String("index", indexName),
 			zap.String("plan", logicalPlan.String()))
 	} else {
 		// Convert AST to Logical Plan
 		logicalPlan, err = qs.converter.ConvertSearchRequest(searchReq, indexName, shardIDs)
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert query to logical plan: %w", err)
+			tracing.EndSpan(planSpan, err)
+			return nil, "", fmt.Errorf("failed to convert query to logical plan: %w", err)
 		}
-		qs.logger.Debug("Logical plan created",
+		logger.Debug("Logical plan created",
 			zap.String("index", indexName),
 			zap.String("plan", logicalPlan.String()))
 	}
@@ -299,7 +475,7 @@ func (qs *QueryService) ExecuteSearch(ctx context.Context, indexName string, req
 		optimizeStart := time.Now()
 		optimizedPlan, err = qs.optimizer.Optimize(logicalPlan)
 		if err != nil {
-			qs.logger.Warn("Optimization failed, using unoptimized plan",
+			logger.Warn("Optimization failed, using unoptimized plan",
 				zap.String("index", indexName),
 				zap.Error(err))
 			optimizedPlan = logicalPlan
@@ -314,7 +490,7 @@ func (qs *QueryService) ExecuteSearch(ctx context.Context, indexName string, req
 			optimizationPassCount.WithLabelValues(indexName).Observe(0)
 		}
 
-		qs.logger.Debug("Logical plan optimized",
+		logger.Debug("Logical plan optimized",
 			zap.String("index", indexName),
 			zap.String("optimized_plan", optimizedPlan.String()),
 			zap.Duration("optimization_time", optimizeTime))
@@ -334,17 +510,18 @@ func (qs *QueryService) ExecuteSearch(ctx context.Context, indexName string, req
 	cachedPhysicalPlan, foundPhysical := qs.queryCache.GetPhysicalPlan(indexName, optimizedPlan)
 	if foundPhysical {
 		physicalPlan = cachedPhysicalPlan
-		qs.logger.Debug("Physical plan retrieved from cache",
+		logger.Debug("Physical plan retrieved from cache",
 			zap.String("index", indexName),
 			zap.String("plan", physicalPlan.String()))
 	} else {
 		// Convert to Physical Plan
 		physicalPlan, err = qs.physicalPlanner.Plan(optimizedPlan)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create physical plan: %w", err)
+			tracing.EndSpan(planSpan, err)
+			return nil, "", fmt.Errorf("failed to create physical plan: %w", err)
 		}
 
-		qs.logger.Debug("Physical plan created",
+		logger.Debug("Physical plan created",
 			zap.String("index", indexName),
 			zap.String("plan", physicalPlan.String()),
 			zap.Float64("estimated_cost", physicalPlan.Cost().TotalCost))
@@ -353,68 +530,103 @@ func (qs *QueryService) ExecuteSearch(ctx context.Context, indexName string, req
 		qs.queryCache.PutPhysicalPlan(indexName, optimizedPlan, physicalPlan)
 	}
 	queryPlanningTime.WithLabelValues(indexName, "physical").Observe(time.Since(physicalStart).Seconds())
+	planSpan.SetAttributes(attribute.Bool("cached", found && foundPhysical))
+	planSpan.End()
 
 	// Step 6: Execute Physical Plan
 	executeStart := time.Now()
 
+	// The request timeout bounds execution; the deadline travels with the
+	// context down to the data nodes, which return what they found in time
+	execBaseCtx := ctx
+	if timeout, _ := searchReq.TimeoutDuration(); timeout > 0 {
+		var cancel context.CancelFunc
+		execBaseCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	// Create execution context
 	execCtx := &planner.ExecutionContext{
 		QueryExecutor: qs.queryExecutor,
-		Logger:        qs.logger,
+		Logger:        logger,
 	}
-	ctxWithExec := planner.WithExecutionContext(ctx, execCtx)
+	execSpanCtx, execSpan := tracing.StartSpan(execBaseCtx, "search.execute")
+	ctxWithExec := planner.WithExecutionContext(execSpanCtx, execCtx)
 
 	// Execute plan
 	executionResult, err := physicalPlan.Execute(ctxWithExec)
 	executeTime := time.Since(executeStart)
+	tracing.EndSpan(execSpan, err)
 
 	if err != nil {
 		queryExecutionTime.WithLabelValues(indexName, "error").Observe(executeTime.Seconds())
-		return nil, fmt.Errorf("query execution failed: %w", err)
+		return nil, "", fmt.Errorf("query execution failed: %w", err)
 	}
 
-	queryExecutionTime.WithLabelValues(indexName, "success").Observe(executeTime.Seconds())
-
 	// Convert ExecutionResult to SearchResult
 	totalTime := time.Since(startTime)
 	result := qs.convertToSearchResult(executionResult, totalTime, len(shardIDs))
 
-	// Step 7: Execute result pipeline if configured
-	if qs.pipelineRegistry != nil && qs.pipelineExecutor != nil {
-		resultPipelineStart := time.Now()
-		modifiedResult, err := qs.executeResultPipeline(ctx, indexName, result, searchReq)
-		if err != nil {
-			// Log warning but continue with original results (graceful degradation)
-			qs.logger.Warn("Result pipeline failed, continuing with original results",
-				zap.String("index", indexName),
-				zap.Error(err))
-		} else if modifiedResult != nil {
-			result = modifiedResult
-			qs.logger.Info("Result pipeline executed successfully",
-				zap.String("index", indexName),
-				zap.Duration("duration", time.Since(resultPipelineStart)))
+	if result.Shards.Failed > 0 {
+		if !searchReq.AllowPartialResults() {
+			queryExecutionTime.WithLabelValues(indexName, "error").Observe(executeTime.Seconds())
+			return nil, "", &PartialSearchResultsError{
+				Index:    indexName,
+				Total:    result.Shards.Total,
+				Failures: result.Shards.Failures,
+			}
 		}
-		queryPlanningTime.WithLabelValues(indexName, "result_pipeline").Observe(time.Since(resultPipelineStart).Seconds())
+		logger.Warn("Returning partial search results",
+			zap.String("index", indexName),
+			zap.Int("failed_shards", result.Shards.Failed),
+			zap.Int("total_shards", result.Shards.Total))
 	}
 
-	qs.logger.Info("Query executed successfully",
+	queryExecutionTime.WithLabelValues(indexName, "success").Observe(executeTime.Seconds())
+	logger.Debug("Index search executed",
 		zap.String("index", indexName),
-		zap.Int64("total_hits", result.TotalHits),
-		zap.Int("hits_returned", len(result.Hits)),
-		zap.Duration("total_time", totalTime),
 		zap.Duration("execute_time", executeTime))
 
-	return result, nil
+	return result, physicalPlan.String(), nil
+}
+
+// parseSearchRequest parses and validates a search request body; an empty
+// body is a match_all query
+func (qs *QueryService) parseSearchRequest(requestBody []byte) (*parser.SearchRequest, error) {
+	if len(requestBody) == 0 {
+		return &parser.SearchRequest{
+			ParsedQuery: &parser.MatchAllQuery{},
+			Size:        parser.DefaultSize,
+		}, nil
+	}
+
+	searchReq, err := qs.queryParser.ParseSearchRequest(requestBody)
+	if err != nil {
+		qs.logger.Error("Failed to parse query", zap.Error(err))
+		return nil, parsingError(fmt.Errorf("failed to parse query: %w", err))
+	}
+
+	// Validate parsed query
+	if searchReq.ParsedQuery != nil {
+		if err := qs.queryParser.Validate(searchReq.ParsedQuery); err != nil {
+			qs.logger.Error("Query validation failed", zap.Error(err))
+			return nil, parsingError(fmt.Errorf("query validation failed: %w", err))
+		}
+	}
+
+	return searchReq, nil
 }
 
 // convertToSearchResult converts ExecutionResult to SearchResult
 func (qs *QueryService) convertToSearchResult(execResult *planner.ExecutionResult, totalTime time.Duration, totalShards int) *SearchResult {
 	result := &SearchResult{
-		TookMillis:   totalTime.Milliseconds(),
-		TotalHits:    execResult.TotalHits,
-		MaxScore:     execResult.MaxScore,
-		Hits:         make([]*SearchHit, len(execResult.Rows)),
-		Aggregations: make(map[string]*AggregationResult),
+		TookMillis:      totalTime.Milliseconds(),
+		TimedOut:        execResult.TimedOut,
+		TerminatedEarly: execResult.TerminatedEarly,
+		TotalHits:       execResult.TotalHits,
+		MaxScore:        execResult.MaxScore,
+		Hits:            make([]*SearchHit, len(execResult.Rows)),
+		Aggregations:    make(map[string]*AggregationResult),
 		Shards: &ShardInfo{
 			Total:      totalShards,
 			Successful: totalShards,
@@ -423,28 +635,23 @@ func (qs *QueryService) convertToSearchResult(execResult *planner.ExecutionResul
 		},
 	}
 
-	// Convert hits
-	for i, row := range execResult.Rows {
-		hit := &SearchHit{
-			Source: make(map[string]interface{}),
-		}
-
-		// Extract _id and _score
-		if id, ok := row["_id"].(string); ok {
-			hit.ID = id
-			delete(row, "_id")
-		}
-		if score, ok := row["_score"].(float64); ok {
-			hit.Score = score
-			delete(row, "_score")
-		}
-
-		// Copy remaining fields to source
-		for k, v := range row {
-			hit.Source[k] = v
+	// Prefer the executor's shard counts, which include failed shards
+	if execResult.Shards.Total > 0 {
+		result.Shards.Total = execResult.Shards.Total
+		result.Shards.Successful = execResult.Shards.Successful
+		result.Shards.Failed = execResult.Shards.Failed
+		for _, failure := range execResult.Shards.Failures {
+			result.Shards.Failures = append(result.Shards.Failures, ShardFailure{
+				Index:  failure.Index,
+				Shard:  failure.Shard,
+				Reason: failure.Reason,
+			})
 		}
+	}
 
-		result.Hits[i] = hit
+	// Convert hits
+	for i, row := range execResult.Rows {
+		result.Hits[i] = rowToSearchHit(row)
 	}
 
 	// Convert aggregations
@@ -455,6 +662,48 @@ func (qs *QueryService) convertToSearchResult(execResult *planner.ExecutionResul
 	return result
 }
 
+// rowToSearchHit converts a result row to a search hit, moving the _id,
+// _score, sort values and inner hits out of the source
+func rowToSearchHit(row map[string]interface{}) *SearchHit {
+	hit := &SearchHit{
+		Source: make(map[string]interface{}),
+	}
+
+	// Extract _id and _score
+	if id, ok := row["_id"].(string); ok {
+		hit.ID = id
+		delete(row, "_id")
+	}
+	if score, ok := row["_score"].(float64); ok {
+		hit.Score = score
+		delete(row, "_score")
+	}
+	if sortValues, ok := row[planner.SortValuesField].([]float64); ok {
+		hit.Sort = sortValues
+		delete(row, planner.SortValuesField)
+	}
+	if matched, ok := row[planner.MatchedQueriesField].([]string); ok {
+		hit.MatchedQueries = matched
+		delete(row, planner.MatchedQueriesField)
+	}
+	if innerHits, ok := row[planner.InnerHitsField].(map[string][]map[string]interface{}); ok {
+		hit.InnerHits = make(map[string][]*SearchHit, len(innerHits))
+		for name, rows := range innerHits {
+			for _, innerRow := range rows {
+				hit.InnerHits[name] = append(hit.InnerHits[name], rowToSearchHit(innerRow))
+			}
+		}
+		delete(row, planner.InnerHitsField)
+	}
+
+	// Copy remaining fields to source
+	for k, v := range row {
+		hit.Source[k] = v
+	}
+
+	return hit
+}
+
 // convertAggregation converts planner.AggregationResult to SearchResult.AggregationResult
 func (qs *QueryService) convertAggregation(agg *planner.AggregationResult) *AggregationResult {
 	result := &AggregationResult{
@@ -517,12 +766,20 @@ func (qs *QueryService) executeQueryPipeline(ctx context.Context, indexName stri
 	// Convert back to SearchRequest
 	outputMap, ok := output.(map[string]interface{})
 	if !ok {
-		return nil, fmt.Errorf("pipeline output is not a map, got %T", output)
+		return nil, &QueryPipelineOutputError{
+			Pipeline: pipe.Name(),
+			Index:    indexName,
+			Cause:    fmt.Errorf("pipeline output is not a map, got %T", output),
+		}
 	}
 
 	modifiedReq, err := qs.mapToSearchRequest(outputMap)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert pipeline output: %w", err)
+		return nil, &QueryPipelineOutputError{
+			Pipeline: pipe.Name(),
+			Index:    indexName,
+			Cause:    err,
+		}
 	}
 
 	return modifiedReq, nil
@@ -587,6 +844,9 @@ func (qs *QueryService) searchRequestToMap(req *parser.SearchRequest) (map[strin
 		return nil, err
 	}
 
+	// Size is omitted when 0, which would parse back as the default size
+	result["size"] = req.Size
+
 	return result, nil
 }
 
@@ -599,7 +859,9 @@ func (qs *QueryService) searchRequestToMapSimple(req *parser.SearchRequest) map[
 	}
 }
 
-// mapToSearchRequest converts map back to SearchRequest
+// mapToSearchRequest converts map back to SearchRequest. Unlike client
+// requests, fields the parser doesn't know are rejected rather than dropped,
+// and the rewritten query must pass validation.
 func (qs *QueryService) mapToSearchRequest(m map[string]interface{}) (*parser.SearchRequest, error) {
 	// Marshal to JSON then unmarshal to SearchRequest
 	data, err := json.Marshal(m)
@@ -607,27 +869,270 @@ func (qs *QueryService) mapToSearchRequest(m map[string]interface{}) (*parser.Se
 		return nil, err
 	}
 
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var strict parser.SearchRequest
+	if err := decoder.Decode(&strict); err != nil {
+		return nil, fmt.Errorf("failed to parse search request: %w", err)
+	}
+
 	// Parse the modified JSON
-	return qs.queryParser.ParseSearchRequest(data)
+	req, err := qs.queryParser.ParseSearchRequest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ParsedQuery != nil {
+		if err := qs.queryParser.Validate(req.ParsedQuery); err != nil {
+			return nil, fmt.Errorf("query validation failed: %w", err)
+		}
+	}
+
+	return req, nil
 }
 
 // searchResultToMap converts SearchResult to map for pipeline
 func (qs *QueryService) searchResultToMap(result *SearchResult) map[string]interface{} {
-	// Convert hits
-	hits := make([]interface{}, len(result.Hits))
-	for i, hit := range result.Hits {
-		hits[i] = map[string]interface{}{
+	m := map[string]interface{}{
+		"took":             result.TookMillis,
+		"timed_out":        result.TimedOut,
+		"terminated_early": result.TerminatedEarly,
+		"total_hits":       result.TotalHits,
+		"max_score":        result.MaxScore,
+		"hits":             hitsToMaps(result.Hits),
+	}
+
+	if len(result.Aggregations) > 0 {
+		m["aggregations"] = aggregationsToMap(result.Aggregations)
+	}
+
+	if result.Shards != nil {
+		shards := map[string]interface{}{
+			"total":      result.Shards.Total,
+			"successful": result.Shards.Successful,
+			"skipped":    result.Shards.Skipped,
+			"failed":     result.Shards.Failed,
+		}
+		if len(result.Shards.Failures) > 0 {
+			failures := make([]interface{}, len(result.Shards.Failures))
+			for i, failure := range result.Shards.Failures {
+				failures[i] = map[string]interface{}{
+					"index":  failure.Index,
+					"shard":  failure.Shard,
+					"reason": failure.Reason,
+				}
+			}
+			shards["failures"] = failures
+		}
+		m["_shards"] = shards
+	}
+
+	return m
+}
+
+// hitsToMaps converts hits to maps for pipeline, with their sort keys,
+// matched queries and inner hits when they have them
+func hitsToMaps(hits []*SearchHit) []interface{} {
+	maps := make([]interface{}, len(hits))
+	for i, hit := range hits {
+		hitMap := map[string]interface{}{
 			"_id":     hit.ID,
 			"_score":  hit.Score,
 			"_source": hit.Source,
 		}
+		if len(hit.Sort) > 0 {
+			sortKeys := make([]interface{}, len(hit.Sort))
+			for j, key := range hit.Sort {
+				sortKeys[j] = key
+			}
+			hitMap["sort"] = sortKeys
+		}
+		if len(hit.MatchedQueries) > 0 {
+			names := make([]interface{}, len(hit.MatchedQueries))
+			for j, name := range hit.MatchedQueries {
+				names[j] = name
+			}
+			hitMap["matched_queries"] = names
+		}
+		if len(hit.InnerHits) > 0 {
+			innerHits := make(map[string]interface{}, len(hit.InnerHits))
+			for name, inner := range hit.InnerHits {
+				innerHits[name] = hitsToMaps(inner)
+			}
+			hitMap["inner_hits"] = innerHits
+		}
+		maps[i] = hitMap
 	}
+	return maps
+}
 
-	return map[string]interface{}{
-		"took":       result.TookMillis,
-		"total_hits": result.TotalHits,
-		"max_score":  result.MaxScore,
-		"hits":       hits,
+// mapsToHits converts pipeline hit maps back to hits, skipping entries that
+// aren't objects
+func mapsToHits(maps []interface{}) []*SearchHit {
+	hits := make([]*SearchHit, 0, len(maps))
+	for _, hitData := range maps {
+		hitMap, ok := hitData.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		hit := &SearchHit{
+			Source: make(map[string]interface{}),
+		}
+		if id, ok := hitMap["_id"].(string); ok {
+			hit.ID = id
+		}
+		if score, ok := hitMap["_score"].(float64); ok {
+			hit.Score = score
+		}
+		if source, ok := hitMap["_source"].(map[string]interface{}); ok {
+			hit.Source = source
+		}
+		switch sortKeys := hitMap["sort"].(type) {
+		case []float64:
+			hit.Sort = sortKeys
+		case []interface{}:
+			for _, key := range sortKeys {
+				if value, ok := numberToFloat64(key); ok {
+					hit.Sort = append(hit.Sort, value)
+				}
+			}
+		}
+		switch names := hitMap["matched_queries"].(type) {
+		case []string:
+			hit.MatchedQueries = names
+		case []interface{}:
+			for _, name := range names {
+				if s, ok := name.(string); ok {
+					hit.MatchedQueries = append(hit.MatchedQueries, s)
+				}
+			}
+		}
+		if innerHits, ok := hitMap["inner_hits"].(map[string]interface{}); ok {
+			hit.InnerHits = make(map[string][]*SearchHit, len(innerHits))
+			for name, inner := range innerHits {
+				if innerMaps, ok := inner.([]interface{}); ok {
+					hit.InnerHits[name] = mapsToHits(innerMaps)
+				}
+			}
+		}
+
+		hits = append(hits, hit)
+	}
+	return hits
+}
+
+// aggregationsToMap converts aggregation results to maps for pipeline
+func aggregationsToMap(aggs map[string]*AggregationResult) map[string]interface{} {
+	m := make(map[string]interface{}, len(aggs))
+	for name, agg := range aggs {
+		if agg == nil {
+			continue
+		}
+
+		aggMap := map[string]interface{}{
+			"type":  agg.Type,
+			"value": agg.Value,
+			"count": agg.Count,
+			"min":   agg.Min,
+			"max":   agg.Max,
+			"avg":   agg.Avg,
+			"sum":   agg.Sum,
+		}
+
+		if agg.Buckets != nil {
+			buckets := make([]interface{}, len(agg.Buckets))
+			for i, bucket := range agg.Buckets {
+				bucketMap := map[string]interface{}{
+					"key":       bucket.Key,
+					"doc_count": bucket.DocCount,
+				}
+				if len(bucket.SubAggs) > 0 {
+					bucketMap["aggregations"] = aggregationsToMap(bucket.SubAggs)
+				}
+				buckets[i] = bucketMap
+			}
+			aggMap["buckets"] = buckets
+		}
+
+		m[name] = aggMap
+	}
+	return m
+}
+
+// mapToAggregations converts pipeline aggregation maps back to aggregation
+// results, skipping entries that aren't objects
+func mapToAggregations(m map[string]interface{}) map[string]*AggregationResult {
+	aggs := make(map[string]*AggregationResult, len(m))
+	for name, raw := range m {
+		aggMap, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		agg := &AggregationResult{}
+		agg.Type, _ = aggMap["type"].(string)
+		agg.Value, _ = numberToFloat64(aggMap["value"])
+		agg.Count, _ = numberToInt64(aggMap["count"])
+		agg.Min, _ = numberToFloat64(aggMap["min"])
+		agg.Max, _ = numberToFloat64(aggMap["max"])
+		agg.Avg, _ = numberToFloat64(aggMap["avg"])
+		agg.Sum, _ = numberToFloat64(aggMap["sum"])
+
+		if buckets, ok := aggMap["buckets"].([]interface{}); ok {
+			agg.Buckets = make([]*AggregationBucket, 0, len(buckets))
+			for _, rawBucket := range buckets {
+				bucketMap, ok := rawBucket.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				bucket := &AggregationBucket{
+					Key:     bucketMap["key"],
+					SubAggs: make(map[string]*AggregationResult),
+				}
+				bucket.DocCount, _ = numberToInt64(bucketMap["doc_count"])
+				if subAggs, ok := bucketMap["aggregations"].(map[string]interface{}); ok {
+					bucket.SubAggs = mapToAggregations(subAggs)
+				}
+				agg.Buckets = append(agg.Buckets, bucket)
+			}
+		}
+
+		aggs[name] = agg
+	}
+	return aggs
+}
+
+// numberToInt64 converts a numeric value from a pipeline map, which may have
+// been through JSON, to int64
+func numberToInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// numberToFloat64 converts a numeric value from a pipeline map to float64
+func numberToFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
 	}
 }
 
@@ -660,30 +1165,51 @@ func (qs *QueryService) mapToSearchResult(m map[string]interface{}) (*SearchResu
 	if maxScore, ok := m["max_score"].(float64); ok {
 		result.MaxScore = maxScore
 	}
+	if timedOut, ok := m["timed_out"].(bool); ok {
+		result.TimedOut = timedOut
+	}
+	if terminatedEarly, ok := m["terminated_early"].(bool); ok {
+		result.TerminatedEarly = terminatedEarly
+	}
 
 	// Extract hits
 	if hitsData, ok := m["hits"].([]interface{}); ok {
-		result.Hits = make([]*SearchHit, 0, len(hitsData))
-		for _, hitData := range hitsData {
-			hitMap, ok := hitData.(map[string]interface{})
-			if !ok {
-				continue
-			}
+		result.Hits = mapsToHits(hitsData)
+	}
 
-			hit := &SearchHit{
-				Source: make(map[string]interface{}),
-			}
-			if id, ok := hitMap["_id"].(string); ok {
-				hit.ID = id
-			}
-			if score, ok := hitMap["_score"].(float64); ok {
-				hit.Score = score
-			}
-			if source, ok := hitMap["_source"].(map[string]interface{}); ok {
-				hit.Source = source
-			}
+	// Extract aggregations
+	if aggs, ok := m["aggregations"].(map[string]interface{}); ok {
+		result.Aggregations = mapToAggregations(aggs)
+	}
 
-			result.Hits = append(result.Hits, hit)
+	// Extract shard info
+	if shards, ok := m["_shards"].(map[string]interface{}); ok {
+		if total, ok := numberToInt64(shards["total"]); ok {
+			result.Shards.Total = int(total)
+		}
+		if successful, ok := numberToInt64(shards["successful"]); ok {
+			result.Shards.Successful = int(successful)
+		}
+		if skipped, ok := numberToInt64(shards["skipped"]); ok {
+			result.Shards.Skipped = int(skipped)
+		}
+		if failed, ok := numberToInt64(shards["failed"]); ok {
+			result.Shards.Failed = int(failed)
+		}
+		if failures, ok := shards["failures"].([]interface{}); ok {
+			for _, f := range failures {
+				failureMap, ok := f.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				failure := ShardFailure{}
+				failure.Index, _ = failureMap["index"].(string)
+				failure.Reason, _ = failureMap["reason"].(string)
+				if shard, ok := numberToInt64(failureMap["shard"]); ok {
+					failure.Shard = int32(shard)
+				}
+				result.Shards.Failures = append(result.Shards.Failures, failure)
+			}
 		}
 	}
 
@@ -2,12 +2,15 @@ package coordination
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
 	pb "github.com/quidditch/quidditch/pkg/common/proto"
 	"github.com/quidditch/quidditch/pkg/coordination/executor"
 	"github.com/quidditch/quidditch/pkg/coordination/planner"
+	"github.com/quidditch/quidditch/pkg/data/suggest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -66,6 +69,10 @@ func (m *mockQueryExecutor) ExecuteSearch(ctx context.Context, indexName string,
 	}, nil
 }
 
+func (m *mockQueryExecutor) ExecuteSuggest(ctx context.Context, indexName string, block map[string]interface{}) (map[string][]suggest.Entry, error) {
+	return map[string][]suggest.Entry{}, nil
+}
+
 func TestNewQueryService(t *testing.T) {
 	logger := zap.NewNop()
 	mockExec := &mockQueryExecutor{}
@@ -253,6 +260,159 @@ func TestExecuteSearchMultipleShards(t *testing.T) {
 	assert.Equal(t, 3, result.Shards.Successful)
 }
 
+func TestExecuteSearchIndicesBoost(t *testing.T) {
+	logger := zap.NewNop()
+
+	mockExec := &mockQueryExecutor{
+		searchFunc: func(ctx context.Context, indexName string, query []byte, filterExpr []byte, from, size int) (*executor.SearchResult, error) {
+			scores := map[string][]float64{
+				"news":    {2.0, 1.0},
+				"archive": {1.5, 0.8},
+			}[indexName]
+			hits := make([]*executor.SearchHit, len(scores))
+			for i, score := range scores {
+				hits[i] = &executor.SearchHit{
+					ID:     fmt.Sprintf("%s-%d", indexName, i),
+					Score:  score,
+					Source: map[string]interface{}{"index": indexName},
+				}
+			}
+			return &executor.SearchResult{TotalHits: int64(len(hits)), MaxScore: scores[0], Hits: hits}, nil
+		},
+	}
+
+	service := NewQueryService(mockExec, &mockMasterClient{}, logger)
+
+	hitIDs := func(result *SearchResult) []string {
+		ids := make([]string, len(result.Hits))
+		for i, hit := range result.Hits {
+			ids[i] = hit.ID
+		}
+		return ids
+	}
+
+	// Without a boost the merged hits are ordered by raw score
+	result, err := service.ExecuteSearch(context.Background(), "news,archive", []byte(`{"query": {"match_all": {}}}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), result.TotalHits)
+	assert.Equal(t, []string{"news-0", "archive-0", "news-1", "archive-1"}, hitIDs(result))
+
+	// Boosting the archive moves its hits ahead
+	result, err = service.ExecuteSearch(context.Background(), "news,archive", []byte(`{
+		"query": {"match_all": {}},
+		"indices_boost": [{"archive": 2.0}],
+		"size": 3
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), result.TotalHits)
+	assert.Equal(t, []string{"archive-0", "news-0", "archive-1"}, hitIDs(result))
+	assert.Equal(t, 3.0, result.MaxScore)
+	assert.Equal(t, 3.0, result.Hits[0].Score)
+
+	// Sorted results cannot be merged by score
+	_, err = service.ExecuteSearch(context.Background(), "news,archive", []byte(`{"sort": [{"date": "desc"}]}`))
+	assert.Error(t, err)
+}
+
+func TestExecuteSearchPartialShardFailure(t *testing.T) {
+	logger := zap.NewNop()
+
+	// Shard 1 of 3 fails
+	mockExec := &mockQueryExecutor{
+		searchFunc: func(ctx context.Context, indexName string, query []byte, filterExpr []byte, from, size int) (*executor.SearchResult, error) {
+			return &executor.SearchResult{
+				TotalHits:  20,
+				MaxScore:   1.0,
+				TookMillis: 5,
+				Hits: []*executor.SearchHit{
+					{ID: "1", Score: 1.0, Source: map[string]interface{}{"title": "Doc 1"}},
+				},
+				Shards: executor.ShardStats{
+					Total:      3,
+					Successful: 2,
+					Failed:     1,
+					Failures: []executor.ShardFailure{
+						{Index: indexName, Shard: 1, Reason: "connection timeout"},
+					},
+				},
+			}, nil
+		},
+	}
+
+	mockMaster := &mockMasterClient{
+		shardRouting: map[int32]*pb.ShardRouting{
+			0: {ShardId: 0, IsPrimary: true, Allocation: &pb.ShardAllocation{NodeId: "node1", State: pb.ShardAllocation_SHARD_STATE_STARTED}},
+			1: {ShardId: 1, IsPrimary: true, Allocation: &pb.ShardAllocation{NodeId: "node2", State: pb.ShardAllocation_SHARD_STATE_STARTED}},
+			2: {ShardId: 2, IsPrimary: true, Allocation: &pb.ShardAllocation{NodeId: "node3", State: pb.ShardAllocation_SHARD_STATE_STARTED}},
+		},
+	}
+
+	service := NewQueryService(mockExec, mockMaster, logger)
+
+	t.Run("AllowedByDefault", func(t *testing.T) {
+		result, err := service.ExecuteSearch(context.Background(), "products", []byte(`{"query": {"match_all": {}}}`))
+
+		require.NoError(t, err)
+		assert.Len(t, result.Hits, 1)
+		assert.Equal(t, 3, result.Shards.Total)
+		assert.Equal(t, 2, result.Shards.Successful)
+		assert.Equal(t, 1, result.Shards.Failed)
+		require.Len(t, result.Shards.Failures, 1)
+		assert.Equal(t, ShardFailure{Index: "products", Shard: 1, Reason: "connection timeout"}, result.Shards.Failures[0])
+	})
+
+	t.Run("Disallowed", func(t *testing.T) {
+		body := `{"query": {"match_all": {}}, "allow_partial_search_results": false}`
+		result, err := service.ExecuteSearch(context.Background(), "products", []byte(body))
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		var partialErr *PartialSearchResultsError
+		require.ErrorAs(t, err, &partialErr)
+		assert.Equal(t, 3, partialErr.Total)
+		assert.Len(t, partialErr.Failures, 1)
+		assert.Contains(t, err.Error(), "connection timeout")
+	})
+}
+
+func TestExecuteSearchTimeout(t *testing.T) {
+	logger := zap.NewNop()
+
+	// The slow shard returns nothing once the deadline passes
+	mockExec := &mockQueryExecutor{
+		searchFunc: func(ctx context.Context, indexName string, query []byte, filterExpr []byte, from, size int) (*executor.SearchResult, error) {
+			_, hasDeadline := ctx.Deadline()
+			if !hasDeadline {
+				return &executor.SearchResult{TotalHits: 2, Hits: []*executor.SearchHit{}}, nil
+			}
+			<-ctx.Done()
+			return &executor.SearchResult{
+				TotalHits: 1,
+				Hits: []*executor.SearchHit{
+					{ID: "1", Score: 1.0, Source: map[string]interface{}{"title": "Fast shard"}},
+				},
+				TimedOut: true,
+			}, nil
+		},
+	}
+
+	service := NewQueryService(mockExec, &mockMasterClient{}, logger)
+
+	result, err := service.ExecuteSearch(context.Background(), "products", []byte(`{"query": {"match_all": {}}, "timeout": "20ms"}`))
+	require.NoError(t, err)
+	assert.True(t, result.TimedOut)
+	assert.Len(t, result.Hits, 1)
+
+	// Without a timeout the search runs unbounded
+	result, err = service.ExecuteSearch(context.Background(), "products", []byte(`{"query": {"match_all": {}}}`))
+	require.NoError(t, err)
+	assert.False(t, result.TimedOut)
+
+	_, err = service.ExecuteSearch(context.Background(), "products", []byte(`{"query": {"match_all": {}}, "timeout": "soon"}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid timeout")
+}
+
 func TestConvertSearchResultToConvert(t *testing.T) {
 	logger := zap.NewNop()
 	mockExec := &mockQueryExecutor{}
@@ -290,3 +450,49 @@ func TestConvertSearchResultToConvert(t *testing.T) {
 	assert.Equal(t, "Doc 1", result.Hits[0].Source["title"])
 	assert.Contains(t, result.Aggregations, "categories")
 }
+
+func TestExecuteSearchQueryStringDefaultFields(t *testing.T) {
+	var shardQuery []byte
+	mockExec := &mockQueryExecutor{
+		searchFunc: func(ctx context.Context, indexName string, query []byte, filterExpr []byte, from, size int) (*executor.SearchResult, error) {
+			shardQuery = query
+			return &executor.SearchResult{Hits: []*executor.SearchHit{}}, nil
+		},
+	}
+	mockMaster := &mockMasterClient{metadata: &pb.IndexMetadataResponse{
+		Metadata: &pb.IndexMetadata{
+			IndexName: "products",
+			Settings:  &pb.IndexSettings{NumberOfShards: 1},
+			Mappings: map[string]*pb.FieldMapping{
+				"title":  {Type: "text"},
+				"status": {Type: "keyword"},
+				"price":  {Type: "double"},
+				"author": {Properties: map[string]*pb.FieldMapping{"name": {Type: "text"}}},
+			},
+		},
+	}}
+	service := NewQueryService(mockExec, mockMaster, zap.NewNop())
+
+	// Terms without a field search the index's text and keyword fields
+	_, err := service.ExecuteSearch(context.Background(), "products",
+		[]byte(`{"query": {"query_string": {"query": "laptop"}}}`))
+	require.NoError(t, err)
+	for _, field := range []string{"title", "status", "author.name"} {
+		assert.Contains(t, string(shardQuery), `"`+field+`":"laptop"`)
+	}
+	assert.NotContains(t, string(shardQuery), `"price"`)
+	assert.NotContains(t, string(shardQuery), `"*"`)
+
+	_, err = service.ExecuteSearch(context.Background(), "products",
+		[]byte(`{"query": {"simple_query_string": {"query": "desk", "fields": ["author.*"]}}}`))
+	require.NoError(t, err)
+	assert.Contains(t, string(shardQuery), `"author.name":"desk"`)
+	assert.NotContains(t, string(shardQuery), `"title"`)
+
+	// A pattern no mapped field matches is rejected
+	_, err = service.ExecuteSearch(context.Background(), "products",
+		[]byte(`{"query": {"query_string": {"query": "laptop", "default_field": "missing*"}}}`))
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.Status)
+}
@@ -0,0 +1,274 @@
+package coordination
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quidditch/quidditch/pkg/common/config"
+	"github.com/quidditch/quidditch/pkg/common/metrics"
+	"go.uber.org/zap"
+)
+
+// clientIdleTimeout is how long an idle client's limiter state is kept
+const clientIdleTimeout = 10 * time.Minute
+
+// searchRoutes are the routes subject to the in-flight search limit
+var searchRoutes = map[string]bool{
+	"/_search":         true,
+	"/:index/_search":  true,
+	"/_msearch":        true,
+	"/:index/_msearch": true,
+	"/:index/_count":   true,
+}
+
+// clientLimit is the throttling state of one client
+type clientLimit struct {
+	tokens     float64
+	lastRefill time.Time
+	inFlight   int
+	lastSeen   time.Time
+}
+
+// RateLimiter throttles requests per client with a token bucket and bounds
+// the number of concurrent searches per client
+type RateLimiter struct {
+	rate        float64
+	burst       float64
+	maxInFlight int
+	keyByAPIKey bool
+
+	mu        sync.Mutex
+	clients   map[string]*clientLimit
+	lastSweep time.Time
+	now       func() time.Time
+
+	// metrics records the limiter's state and rejections (nil = not
+	// recorded)
+	metrics *metrics.MetricsCollector
+}
+
+// NewRateLimiter creates a rate limiter from config
+func NewRateLimiter(cfg config.RateLimitConfig) (*RateLimiter, error) {
+	if cfg.RequestsPerSecond < 0 {
+		return nil, fmt.Errorf("requests_per_second must not be negative")
+	}
+	if cfg.MaxInFlightSearches < 0 {
+		return nil, fmt.Errorf("max_in_flight_searches must not be negative")
+	}
+
+	var keyByAPIKey bool
+	switch cfg.KeyBy {
+	case "", "ip":
+	case "api_key":
+		keyByAPIKey = true
+	default:
+		return nil, fmt.Errorf("unknown key_by '%s' (expected ip or api_key)", cfg.KeyBy)
+	}
+
+	burst := float64(cfg.Burst)
+	if burst < 1 {
+		burst = math.Max(1, math.Ceil(cfg.RequestsPerSecond))
+	}
+
+	return &RateLimiter{
+		rate:        cfg.RequestsPerSecond,
+		burst:       burst,
+		maxInFlight: cfg.MaxInFlightSearches,
+		keyByAPIKey: keyByAPIKey,
+		clients:     make(map[string]*clientLimit),
+		now:         time.Now,
+	}, nil
+}
+
+// newRateLimiter builds the configured rate limiter, or nil when disabled
+func newRateLimiter(cfg config.RateLimitConfig) (*RateLimiter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	return NewRateLimiter(cfg)
+}
+
+// SetMetrics makes the limiter record its state and rejections in m. It
+// must be called before the limiter is used.
+func (l *RateLimiter) SetMetrics(m *metrics.MetricsCollector) {
+	l.metrics = m
+}
+
+// recordTrackedClients records how many clients are tracked. Must be called
+// with mu held.
+func (l *RateLimiter) recordTrackedClients() {
+	if l.metrics != nil {
+		l.metrics.RateLimitTrackedClients.Set(float64(len(l.clients)))
+	}
+}
+
+// recordInFlightSearches adds delta to the recorded searches in flight
+func (l *RateLimiter) recordInFlightSearches(delta float64) {
+	if l.metrics != nil {
+		l.metrics.RateLimitInFlightSearches.Add(delta)
+	}
+}
+
+// recordRejection records a request rejected for reason, rate or
+// concurrency
+func (l *RateLimiter) recordRejection(reason string) {
+	if l.metrics != nil {
+		l.metrics.RateLimitRejections.WithLabelValues(reason).Inc()
+	}
+}
+
+// client returns the state for key, creating it with a full bucket. Must be
+// called with mu held.
+func (l *RateLimiter) client(key string, now time.Time) *clientLimit {
+	if now.Sub(l.lastSweep) > clientIdleTimeout {
+		l.sweep(now)
+	}
+
+	state, ok := l.clients[key]
+	if !ok {
+		state = &clientLimit{tokens: l.burst, lastRefill: now, lastSeen: now}
+		l.clients[key] = state
+		l.recordTrackedClients()
+	}
+	return state
+}
+
+// sweep drops idle clients without searches in flight. Must be called with
+// mu held.
+func (l *RateLimiter) sweep(now time.Time) {
+	for key, state := range l.clients {
+		if state.inFlight == 0 && now.Sub(state.lastSeen) > clientIdleTimeout {
+			delete(l.clients, key)
+		}
+	}
+	l.lastSweep = now
+	l.recordTrackedClients()
+}
+
+// Allow takes a token from the client's bucket. If none is left, it returns
+// false and how long until the next token is available.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	if l.rate <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	state := l.client(key, now)
+
+	// Refill for the time since the last request
+	elapsed := now.Sub(state.lastRefill).Seconds()
+	state.tokens = math.Min(l.burst, state.tokens+elapsed*l.rate)
+	state.lastRefill = now
+	state.lastSeen = now
+
+	if state.tokens < 1 {
+		wait := time.Duration((1 - state.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+
+	state.tokens--
+	return true, 0
+}
+
+// AcquireSearch reserves an in-flight search slot for the client. Every
+// successful call must be paired with ReleaseSearch.
+func (l *RateLimiter) AcquireSearch(key string) bool {
+	if l.maxInFlight <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	state := l.client(key, now)
+	state.lastSeen = now
+	if state.inFlight >= l.maxInFlight {
+		return false
+	}
+
+	state.inFlight++
+	l.recordInFlightSearches(1)
+	return true
+}
+
+// ReleaseSearch frees a slot reserved by AcquireSearch
+func (l *RateLimiter) ReleaseSearch(key string) {
+	if l.maxInFlight <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if state, ok := l.clients[key]; ok && state.inFlight > 0 {
+		state.inFlight--
+		l.recordInFlightSearches(-1)
+	}
+}
+
+// clientKey identifies the caller of a request
+func (l *RateLimiter) clientKey(c *gin.Context) string {
+	if l.keyByAPIKey {
+		if identity, ok := identityFromContext(c); ok {
+			return "key:" + identity.Name
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// rateLimitMiddleware rejects requests over the client's rate or search
+// concurrency limit with 429. It must run after authentication so clients
+// can be keyed by API key.
+func rateLimitMiddleware(limiter *RateLimiter, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Health checks and metric scrapes are never throttled
+		if authAllowlist[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		key := limiter.clientKey(c)
+
+		if ok, wait := limiter.Allow(key); !ok {
+			limiter.recordRejection("rate")
+			logger.Debug("Request rate limit exceeded", zap.String("client", key))
+			abortTooManyRequests(c, wait, "request rate limit exceeded")
+			return
+		}
+
+		if !searchRoutes[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		if !limiter.AcquireSearch(key) {
+			limiter.recordRejection("concurrency")
+			logger.Debug("Concurrent search limit exceeded", zap.String("client", key))
+			abortTooManyRequests(c, time.Second, "too many concurrent search requests")
+			return
+		}
+		defer limiter.ReleaseSearch(key)
+
+		c.Next()
+	}
+}
+
+// abortTooManyRequests responds with 429 and a Retry-After hint in seconds
+func abortTooManyRequests(c *gin.Context, retryAfter time.Duration, reason string) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	respondError(c, http.StatusTooManyRequests, "rejected_execution_exception", reason)
+}
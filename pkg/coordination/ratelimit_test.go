@@ -0,0 +1,207 @@
+package coordination
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/quidditch/quidditch/pkg/common/config"
+	"github.com/quidditch/quidditch/pkg/common/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newRateLimitTestRouter(limiter *RateLimiter, search gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	// Stand-in for the auth middleware: identify callers by a test header
+	router.Use(func(c *gin.Context) {
+		if name := c.GetHeader("X-Test-User"); name != "" {
+			c.Set(identityContextKey, &Identity{Name: name})
+		}
+	})
+	router.Use(rateLimitMiddleware(limiter, zap.NewNop()))
+
+	ok := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+	if search == nil {
+		search = ok
+	}
+	router.GET("/_health", ok)
+	router.GET("/:index/_doc/:id", ok)
+	router.POST("/:index/_search", search)
+	return router
+}
+
+func serveFrom(router *gin.Engine, method, path, remoteAddr, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = remoteAddr
+	if user != "" {
+		req.Header.Set("X-Test-User", user)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimiter_TokenBucket(t *testing.T) {
+	limiter, err := NewRateLimiter(config.RateLimitConfig{RequestsPerSecond: 2, Burst: 3})
+	require.NoError(t, err)
+
+	now := time.Unix(1000, 0)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		ok, _ := limiter.Allow("client")
+		assert.True(t, ok, "request %d within burst", i)
+	}
+
+	ok, wait := limiter.Allow("client")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// Other clients have their own bucket
+	ok, _ = limiter.Allow("other")
+	assert.True(t, ok)
+
+	// Half a second refills one token at 2 req/s
+	now = now.Add(500 * time.Millisecond)
+	ok, _ = limiter.Allow("client")
+	assert.True(t, ok)
+	ok, _ = limiter.Allow("client")
+	assert.False(t, ok)
+
+	// The bucket never holds more than the burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		ok, _ := limiter.Allow("client")
+		assert.True(t, ok)
+	}
+	ok, _ = limiter.Allow("client")
+	assert.False(t, ok)
+}
+
+func TestRateLimiter_InvalidConfig(t *testing.T) {
+	_, err := NewRateLimiter(config.RateLimitConfig{RequestsPerSecond: -1})
+	assert.Error(t, err)
+
+	_, err = NewRateLimiter(config.RateLimitConfig{MaxInFlightSearches: -1})
+	assert.Error(t, err)
+
+	_, err = NewRateLimiter(config.RateLimitConfig{KeyBy: "cookie"})
+	assert.Error(t, err)
+
+	limiter, err := newRateLimiter(config.RateLimitConfig{RequestsPerSecond: 10})
+	require.NoError(t, err)
+	assert.Nil(t, limiter, "disabled rate limiting should not build a limiter")
+}
+
+func TestRateLimitMiddleware_Flood(t *testing.T) {
+	limiter, err := NewRateLimiter(config.RateLimitConfig{RequestsPerSecond: 1, Burst: 5})
+	require.NoError(t, err)
+	m := metrics.NewMetricsCollectorWithRegistry("coordination", prometheus.NewRegistry())
+	limiter.SetMetrics(m)
+	router := newRateLimitTestRouter(limiter, nil)
+
+	var accepted, rejected int
+	for i := 0; i < 20; i++ {
+		w := serveFrom(router, http.MethodGet, "/products/_doc/1", "10.0.0.1:1234", "")
+		switch w.Code {
+		case http.StatusOK:
+			accepted++
+		case http.StatusTooManyRequests:
+			rejected++
+			assert.Equal(t, "1", w.Header().Get("Retry-After"))
+			assert.Contains(t, w.Body.String(), "rejected_execution_exception")
+		default:
+			t.Fatalf("unexpected status %d", w.Code)
+		}
+	}
+	assert.Equal(t, 5, accepted)
+	assert.Equal(t, 15, rejected)
+	assert.Equal(t, float64(15), testutil.ToFloat64(m.RateLimitRejections.WithLabelValues("rate")))
+
+	// Another client IP is unaffected
+	w := serveFrom(router, http.MethodGet, "/products/_doc/1", "10.0.0.2:1234", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.RateLimitTrackedClients))
+
+	// Health checks are never throttled
+	w = serveFrom(router, http.MethodGet, "/_health", "10.0.0.1:1234", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRateLimitMiddleware_KeyByAPIKey(t *testing.T) {
+	limiter, err := NewRateLimiter(config.RateLimitConfig{
+		RequestsPerSecond: 1,
+		Burst:             2,
+		KeyBy:             "api_key",
+	})
+	require.NoError(t, err)
+	router := newRateLimitTestRouter(limiter, nil)
+
+	// Two callers behind the same IP get separate budgets
+	for _, user := range []string{"app-a", "app-b"} {
+		for i := 0; i < 2; i++ {
+			w := serveFrom(router, http.MethodGet, "/products/_doc/1", "10.0.0.1:1234", user)
+			assert.Equal(t, http.StatusOK, w.Code, "%s request %d", user, i)
+		}
+		w := serveFrom(router, http.MethodGet, "/products/_doc/1", "10.0.0.1:1234", user)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	}
+}
+
+func TestRateLimitMiddleware_ConcurrentSearches(t *testing.T) {
+	limiter, err := NewRateLimiter(config.RateLimitConfig{MaxInFlightSearches: 2})
+	require.NoError(t, err)
+	m := metrics.NewMetricsCollectorWithRegistry("coordination", prometheus.NewRegistry())
+	limiter.SetMetrics(m)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	router := newRateLimitTestRouter(limiter, func(c *gin.Context) {
+		started <- struct{}{}
+		<-release
+		c.JSON(http.StatusOK, gin.H{"hits": gin.H{}})
+	})
+
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = serveFrom(router, http.MethodPost, "/products/_search", "10.0.0.1:1234", "").Code
+		}(i)
+	}
+	<-started
+	<-started
+
+	// Both slots are taken: further searches are rejected, other routes aren't
+	w := serveFrom(router, http.MethodPost, "/products/_search", "10.0.0.1:1234", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "too many concurrent search requests")
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.RateLimitInFlightSearches))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.RateLimitRejections.WithLabelValues("concurrency")))
+
+	w = serveFrom(router, http.MethodGet, "/products/_doc/1", "10.0.0.1:1234", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK}, codes)
+
+	// Slots are freed once the searches finish
+	w = serveFrom(router, http.MethodPost, "/products/_search", "10.0.0.1:1234", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.RateLimitInFlightSearches))
+}
@@ -0,0 +1,74 @@
+package coordination
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// cloneIndexSettings returns a copy of index settings to change and send
+// back to the master, which replaces all of an index's settings on update
+func cloneIndexSettings(settings *pb.IndexSettings) *pb.IndexSettings {
+	if settings == nil {
+		return &pb.IndexSettings{}
+	}
+	return proto.Clone(settings).(*pb.IndexSettings)
+}
+
+// parseNumberOfReplicas parses an index.number_of_replicas setting value,
+// given as a JSON number or a numeric string
+func parseNumberOfReplicas(value interface{}) (int32, error) {
+	var replicas int64
+	switch v := value.(type) {
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, fmt.Errorf("number_of_replicas must be an integer but was [%v]", v)
+		}
+		replicas = int64(v)
+	case string:
+		parsed, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse number_of_replicas [%s]", v)
+		}
+		replicas = parsed
+	default:
+		return 0, fmt.Errorf("number_of_replicas must be a number but was [%v]", value)
+	}
+	if replicas < 0 {
+		return 0, fmt.Errorf("number_of_replicas must be >= 0 but was [%d]", replicas)
+	}
+	return int32(replicas), nil
+}
+
+// updateNumberOfReplicas asks the master to change the replica count of a
+// live index, which adds or removes replica shards. It writes the error
+// response and returns false if the update failed.
+func (c *CoordinationNode) updateNumberOfReplicas(ctx *gin.Context, indexName string, replicas int32) bool {
+	current, err := c.masterClient.GetIndexMetadata(ctx.Request.Context(), indexName)
+	if err != nil {
+		respondAPIError(ctx, indexNotFoundError(indexName))
+		return false
+	}
+
+	currentSettings := current.GetMetadata().GetSettings()
+	settings := cloneIndexSettings(currentSettings)
+	settings.NumberOfReplicas = replicas
+	if _, err := c.masterClient.UpdateIndexSettings(ctx.Request.Context(), indexName, settings); err != nil {
+		c.logger.Error("Failed to update number of replicas",
+			zap.String("index", indexName),
+			zap.Int32("replicas", replicas),
+			zap.Error(err))
+		respondErrorFrom(ctx, err, "settings_update_exception", "Failed to update number_of_replicas")
+		return false
+	}
+
+	c.logger.Info("Updated number of replicas",
+		zap.String("index", indexName),
+		zap.Int32("from", currentSettings.GetNumberOfReplicas()),
+		zap.Int32("to", replicas))
+	return true
+}
@@ -0,0 +1,113 @@
+package coordination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/coordination/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// replicasMasterServer is a master holding a single index whose settings
+// can be updated
+type replicasMasterServer struct {
+	pb.UnimplementedMasterServiceServer
+
+	settings *pb.IndexSettings
+}
+
+func (m *replicasMasterServer) GetIndexMetadata(ctx context.Context, req *pb.GetIndexMetadataRequest) (*pb.IndexMetadataResponse, error) {
+	if req.IndexName != "products" {
+		return nil, status.Errorf(codes.NotFound, "index not found: %s", req.IndexName)
+	}
+	return &pb.IndexMetadataResponse{Metadata: &pb.IndexMetadata{IndexName: "products", Settings: m.settings}}, nil
+}
+
+func (m *replicasMasterServer) UpdateIndexSettings(ctx context.Context, req *pb.UpdateIndexSettingsRequest) (*pb.UpdateIndexSettingsResponse, error) {
+	if req.Settings.NumberOfShards != m.settings.NumberOfShards {
+		return nil, status.Error(codes.InvalidArgument, "can't change the number of shards")
+	}
+	m.settings = req.Settings
+	return &pb.UpdateIndexSettingsResponse{Acknowledged: true}, nil
+}
+
+func TestParseNumberOfReplicas(t *testing.T) {
+	replicas, err := parseNumberOfReplicas(float64(2))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), replicas)
+
+	replicas, err = parseNumberOfReplicas("1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), replicas)
+
+	for _, value := range []interface{}{float64(-1), 1.5, "one", true, nil} {
+		_, err := parseNumberOfReplicas(value)
+		assert.Error(t, err, "value %v", value)
+	}
+}
+
+func TestPutSettingsNumberOfReplicas(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &replicasMasterServer{settings: &pb.IndexSettings{
+		NumberOfShards:   2,
+		NumberOfReplicas: 0,
+		StoreType:        "mmapfs",
+		Analysis:         `{"analyzer":{"folded":{"tokenizer":"standard"}}}`,
+		DateFields:       `{"created":{"format":"epoch_millis"}}`,
+	}}
+	server := grpc.NewServer()
+	pb.RegisterMasterServiceServer(server, master)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	node := newTestCoordinationNode(t)
+	node.masterClient = NewMasterClient(lis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	defer node.masterClient.Disconnect()
+	node.pipelineRegistry = pipeline.NewRegistry(zap.NewNop())
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodPut, "/products/_settings", `{"index":{"number_of_replicas":1}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, int32(1), master.settings.NumberOfReplicas)
+	assert.Equal(t, int32(2), master.settings.NumberOfShards)
+
+	// The master replaces all the settings, so the rest are sent back as they were
+	assert.Equal(t, "mmapfs", master.settings.StoreType)
+	assert.Equal(t, `{"analyzer":{"folded":{"tokenizer":"standard"}}}`, master.settings.Analysis)
+	assert.Equal(t, `{"created":{"format":"epoch_millis"}}`, master.settings.DateFields)
+
+	// The new count shows up in the index settings
+	w = serve(http.MethodGet, "/products/_settings", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp map[string]map[string]map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "1", resp["products"]["settings"]["index"]["number_of_replicas"])
+	assert.Equal(t, "2", resp["products"]["settings"]["index"]["number_of_shards"])
+
+	w = serve(http.MethodPut, "/products/_settings", `{"index":{"number_of_replicas":-1}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	assert.Equal(t, int32(1), master.settings.NumberOfReplicas)
+
+	w = serve(http.MethodPut, "/missing/_settings", `{"index":{"number_of_replicas":1}}`)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
@@ -0,0 +1,105 @@
+package coordination
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/common/requestid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+)
+
+// loggingDataService logs searches the way the data node does
+type loggingDataService struct {
+	pb.UnimplementedDataServiceServer
+	logger *zap.Logger
+}
+
+func (s *loggingDataService) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	requestid.Logger(ctx, s.logger).Info("Shard search", zap.String("index", req.IndexName))
+	return &pb.SearchResponse{Hits: &pb.SearchHits{}}, nil
+}
+
+// requestIDs returns the request_id field of every logged entry
+func requestIDs(logs *observer.ObservedLogs) []interface{} {
+	var ids []interface{}
+	for _, entry := range logs.AllUntimed() {
+		ids = append(ids, entry.ContextMap()["request_id"])
+	}
+	return ids
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	dataCore, dataLogs := observer.New(zapcore.InfoLevel)
+	coordCore, coordLogs := observer.New(zapcore.InfoLevel)
+
+	// Data node
+	address := net.JoinHostPort("127.0.0.1", strconv.Itoa(freeTestPort(t)))
+	listener, err := net.Listen("tcp", address)
+	require.NoError(t, err)
+	server := grpc.NewServer(requestid.ServerOption())
+	pb.RegisterDataServiceServer(server, &loggingDataService{logger: zap.New(dataCore)})
+	go server.Serve(listener)
+	defer server.Stop()
+
+	client := NewDataNodeClient("data-1", address, zap.NewNop())
+	require.NoError(t, client.Connect(context.Background()))
+	defer client.Disconnect()
+
+	// Coordination node
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestid.Middleware())
+	router.Use(ginLogger(zap.New(coordCore)))
+	router.GET("/:index/_search", func(c *gin.Context) {
+		_, err := client.Search(c.Request.Context(), c.Param("index"), 0, []byte(`{}`), nil)
+		require.NoError(t, err)
+		c.Status(http.StatusOK)
+	})
+
+	// An incoming id is kept and appears in both nodes' logs
+	req := httptest.NewRequest(http.MethodGet, "/products/_search", nil)
+	req.Header.Set(requestid.Header, "req-42")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "req-42", w.Header().Get(requestid.Header))
+	assert.Equal(t, []interface{}{"req-42"}, requestIDs(coordLogs))
+	assert.Equal(t, []interface{}{"req-42"}, requestIDs(dataLogs))
+
+	// Without an incoming id one is generated and propagated
+	req = httptest.NewRequest(http.MethodGet, "/products/_search", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	generated := w.Header().Get(requestid.Header)
+	require.NotEmpty(t, generated)
+	assert.Equal(t, generated, requestIDs(coordLogs)[1])
+	assert.Equal(t, generated, requestIDs(dataLogs)[1])
+
+	// An id too long or with characters outside [A-Za-z0-9-_.:] is replaced
+	for i, invalid := range []string{strings.Repeat("a", 129), "req 42", `req"<42>`} {
+		req = httptest.NewRequest(http.MethodGet, "/products/_search", nil)
+		req.Header.Set(requestid.Header, invalid)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		replaced := w.Header().Get(requestid.Header)
+		require.NotEmpty(t, replaced)
+		assert.NotEqual(t, invalid, replaced)
+		assert.Equal(t, replaced, requestIDs(coordLogs)[2+i])
+		assert.Equal(t, replaced, requestIDs(dataLogs)[2+i])
+	}
+}
@@ -0,0 +1,290 @@
+package coordination
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"go.uber.org/zap"
+)
+
+// resizeShardTimeout bounds how long a split or shrink waits for the shards
+// of the target index to start before copying documents
+const resizeShardTimeout = 30 * time.Second
+
+// resizeRequest is the body of a _split or _shrink request
+type resizeRequest struct {
+	Settings struct {
+		Index struct {
+			NumberOfShards   *int32 `json:"number_of_shards"`
+			NumberOfReplicas *int32 `json:"number_of_replicas"`
+		} `json:"index"`
+	} `json:"settings"`
+}
+
+// validateResize checks the shard count of a split or shrink target. A
+// split target must have a multiple of the source shards and a shrink
+// target a factor of them, so every source shard maps onto target shards
+// under the hash-modulo document routing.
+func validateResize(kind string, sourceShards, targetShards int32) error {
+	if targetShards <= 0 {
+		return fmt.Errorf("the number of target shards must be positive but was [%d]", targetShards)
+	}
+
+	switch kind {
+	case "split":
+		if targetShards <= sourceShards {
+			return fmt.Errorf("the number of source shards [%d] must be less than the number of target shards [%d]", sourceShards, targetShards)
+		}
+		if targetShards%sourceShards != 0 {
+			return fmt.Errorf("the number of source shards [%d] must be a factor of [%d]", sourceShards, targetShards)
+		}
+	case "shrink":
+		if targetShards >= sourceShards {
+			return fmt.Errorf("the number of target shards [%d] must be less than the number of source shards [%d]", targetShards, sourceShards)
+		}
+		if sourceShards%targetShards != 0 {
+			return fmt.Errorf("the number of source shards [%d] must be a multiple of [%d]", sourceShards, targetShards)
+		}
+	}
+	return nil
+}
+
+// handleSplitIndex splits an index into a new index with more shards
+func (c *CoordinationNode) handleSplitIndex(ctx *gin.Context) {
+	c.resizeIndex(ctx, "split")
+}
+
+// handleShrinkIndex shrinks an index into a new index with fewer shards
+func (c *CoordinationNode) handleShrinkIndex(ctx *gin.Context) {
+	c.resizeIndex(ctx, "shrink")
+}
+
+// resizeIndex creates the target index of a split or shrink with more or
+// fewer shards and copies every document of the source index into it.
+// Documents keep their ids, so the target routes each one by hashing its id
+// over the new shard count. Writes to the source are rejected until the
+// copy is done.
+func (c *CoordinationNode) resizeIndex(ctx *gin.Context, kind string) {
+	sourceIndex := ctx.Param("index")
+	targetIndex := ctx.Param("target")
+
+	badRequest := func(errorType, reason string) {
+		respondError(ctx, http.StatusBadRequest, errorType, reason)
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		badRequest("parse_exception", fmt.Sprintf("Failed to read request body: %v", err))
+		return
+	}
+	var req resizeRequest
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			badRequest("parsing_exception", fmt.Sprintf("Failed to parse %s request: %v", kind, err))
+			return
+		}
+	}
+
+	source, err := c.masterClient.GetIndexMetadata(ctx.Request.Context(), sourceIndex)
+	if err != nil {
+		respondAPIError(ctx, indexNotFoundError(sourceIndex))
+		return
+	}
+	if _, err := c.masterClient.GetIndexMetadata(ctx.Request.Context(), targetIndex); err == nil {
+		badRequest("resource_already_exists_exception", fmt.Sprintf("index [%s] already exists", targetIndex))
+		return
+	}
+
+	// The target keeps the source settings and mappings apart from the
+	// shard count. A shrink defaults to a single shard. The source's write
+	// block is not kept, as the documents are copied in with writes.
+	sourceSettings := source.GetMetadata().GetSettings()
+	settings := cloneIndexSettings(sourceSettings)
+	settings.BlocksWrite = false
+	sourceShards := sourceSettings.GetNumberOfShards()
+	switch {
+	case req.Settings.Index.NumberOfShards != nil:
+		settings.NumberOfShards = *req.Settings.Index.NumberOfShards
+	case kind == "shrink":
+		settings.NumberOfShards = 1
+	default:
+		badRequest("illegal_argument_exception", "index.number_of_shards is required for split operations")
+		return
+	}
+	if req.Settings.Index.NumberOfReplicas != nil {
+		settings.NumberOfReplicas = *req.Settings.Index.NumberOfReplicas
+	}
+	if err := validateResize(kind, sourceShards, settings.NumberOfShards); err != nil {
+		badRequest("illegal_argument_exception", err.Error())
+		return
+	}
+
+	c.logger.Info("Resizing index",
+		zap.String("kind", kind),
+		zap.String("source", sourceIndex),
+		zap.String("target", targetIndex),
+		zap.Int32("source_shards", sourceShards),
+		zap.Int32("target_shards", settings.NumberOfShards))
+
+	// Block writes to the source while it is copied, so every document
+	// written before the resize is in the target and none written during it
+	// is missed. A block set here is lifted again once the copy ends.
+	if !sourceSettings.GetBlocksWrite() {
+		if err := c.setIndexWriteBlock(ctx.Request.Context(), sourceIndex, true); err != nil {
+			c.logger.Error("Failed to block writes to resize source", zap.String("index", sourceIndex), zap.Error(err))
+			respondErrorFrom(ctx, err, "resize_exception", fmt.Sprintf("Failed to block writes to index [%s]", sourceIndex))
+			return
+		}
+		defer func() {
+			if err := c.setIndexWriteBlock(context.Background(), sourceIndex, false); err != nil {
+				c.logger.Error("Failed to unblock writes to resize source", zap.String("index", sourceIndex), zap.Error(err))
+			}
+		}()
+	}
+
+	if _, err := c.masterClient.CreateIndex(ctx.Request.Context(), targetIndex, settings, source.GetMetadata().GetMappings()); err != nil {
+		c.logger.Error("Failed to create resize target", zap.String("index", targetIndex), zap.Error(err))
+		respondError(ctx, http.StatusInternalServerError, "create_index_exception", fmt.Sprintf("Failed to create index [%s]: %v", targetIndex, err))
+		return
+	}
+
+	if err := c.waitForPrimaries(ctx.Request.Context(), targetIndex, settings.NumberOfShards, resizeShardTimeout); err != nil {
+		c.logger.Error("Resize target shards did not start", zap.String("index", targetIndex), zap.Error(err))
+		respondError(ctx, http.StatusInternalServerError, "resize_exception", fmt.Sprintf("Shards of index [%s] did not start: %v", targetIndex, err))
+		return
+	}
+
+	copied, err := c.copyIndexDocuments(ctx.Request.Context(), sourceIndex, targetIndex)
+	if err != nil {
+		c.logger.Error("Failed to copy documents to resize target",
+			zap.String("source", sourceIndex),
+			zap.String("target", targetIndex),
+			zap.Int("copied", copied),
+			zap.Error(err))
+		respondError(ctx, http.StatusInternalServerError, "resize_exception", fmt.Sprintf("Failed to %s index [%s] into [%s]: %v", kind, sourceIndex, targetIndex, err))
+		return
+	}
+
+	c.logger.Info("Resized index",
+		zap.String("kind", kind),
+		zap.String("source", sourceIndex),
+		zap.String("target", targetIndex),
+		zap.Int("documents", copied))
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"acknowledged":        true,
+		"shards_acknowledged": true,
+		"index":               targetIndex,
+	})
+}
+
+// copyIndexDocuments copies every document of a write-blocked source index
+// into a target index, keeping their ids, and returns how many it copied.
+// Each document is read back whole from its shard rather than taken from
+// the search hits, and the copy fails unless the target ends up holding as
+// many documents as the source.
+func (c *CoordinationNode) copyIndexDocuments(ctx context.Context, sourceIndex, targetIndex string) (int, error) {
+	expected, err := c.countIndexDocuments(ctx, sourceIndex)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count documents of [%s]: %w", sourceIndex, err)
+	}
+
+	var ids []string
+	err = c.scrollMatches(ctx, sourceIndex, json.RawMessage(`{"match_all": {}}`), defaultScrollSize, func(hits []*SearchHit) bool {
+		for _, hit := range hits {
+			ids = append(ids, hit.ID)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	copied := 0
+	for _, id := range ids {
+		doc, err := c.docRouter.RouteGetDocument(ctx, sourceIndex, id)
+		if err != nil {
+			return copied, fmt.Errorf("failed to read document [%s]: %w", id, err)
+		}
+		if !doc.Found {
+			return copied, fmt.Errorf("document [%s] disappeared from [%s] during the copy", id, sourceIndex)
+		}
+		if _, err := c.docRouter.RouteIndexDocument(ctx, targetIndex, id, doc.Document.AsMap()); err != nil {
+			return copied, fmt.Errorf("failed to copy document [%s]: %w", id, err)
+		}
+		copied++
+	}
+
+	actual, err := c.countIndexDocuments(ctx, targetIndex)
+	if err != nil {
+		return copied, fmt.Errorf("failed to count documents of [%s]: %w", targetIndex, err)
+	}
+	if actual != expected {
+		return copied, fmt.Errorf("index [%s] holds %d documents after the copy but [%s] holds %d", targetIndex, actual, sourceIndex, expected)
+	}
+	return copied, nil
+}
+
+// countIndexDocuments returns the number of documents in an index
+func (c *CoordinationNode) countIndexDocuments(ctx context.Context, indexName string) (int64, error) {
+	result, err := c.queryService.executeSearch(ctx, indexName, []byte(`{"query": {"match_all": {}}, "size": 0}`), false)
+	if err != nil {
+		return 0, err
+	}
+	return result.TotalHits, nil
+}
+
+// setIndexWriteBlock sets or lifts index.blocks.write on an index, keeping
+// its other settings
+func (c *CoordinationNode) setIndexWriteBlock(ctx context.Context, indexName string, blocked bool) error {
+	metadata, err := c.masterClient.GetIndexMetadata(ctx, indexName)
+	if err != nil {
+		return err
+	}
+
+	settings := cloneIndexSettings(metadata.GetMetadata().GetSettings())
+	settings.BlocksWrite = blocked
+	_, err = c.masterClient.UpdateIndexSettings(ctx, indexName, settings)
+	return err
+}
+
+// waitForPrimaries waits until every primary shard of an index is active
+func (c *CoordinationNode) waitForPrimaries(ctx context.Context, indexName string, numShards int32, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		routing, err := c.masterClient.GetShardRouting(ctx, indexName)
+		if err == nil {
+			active := int32(0)
+			for _, shard := range routing {
+				state := shard.GetAllocation().GetState()
+				if state == pb.ShardAllocation_SHARD_STATE_STARTED || state == pb.ShardAllocation_SHARD_STATE_RELOCATING {
+					active++
+				}
+			}
+			if active >= numShards {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("timed out waiting for %d primary shards", numShards)
+		case <-ticker.C:
+		}
+	}
+}
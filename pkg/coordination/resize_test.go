@@ -0,0 +1,375 @@
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/coordination/executor"
+	"github.com/quidditch/quidditch/pkg/coordination/router"
+	"github.com/quidditch/quidditch/pkg/data/suggest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// resizeMasterServer is a master that starts every shard of a created index
+// on node1
+type resizeMasterServer struct {
+	pb.UnimplementedMasterServiceServer
+
+	mu      sync.Mutex
+	indices map[string]*pb.IndexMetadata
+	blocks  []string // index.blocks.write updates, as "index=true|false"
+}
+
+func (m *resizeMasterServer) CreateIndex(ctx context.Context, req *pb.CreateIndexRequest) (*pb.CreateIndexResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.indices[req.IndexName]; exists {
+		return nil, status.Errorf(codes.AlreadyExists, "index [%s] already exists", req.IndexName)
+	}
+	m.indices[req.IndexName] = &pb.IndexMetadata{IndexName: req.IndexName, Settings: req.Settings, Mappings: req.Mappings}
+	return &pb.CreateIndexResponse{Acknowledged: true, IndexName: req.IndexName}, nil
+}
+
+func (m *resizeMasterServer) GetIndexMetadata(ctx context.Context, req *pb.GetIndexMetadataRequest) (*pb.IndexMetadataResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	metadata, exists := m.indices[req.IndexName]
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "index [%s] not found", req.IndexName)
+	}
+	return &pb.IndexMetadataResponse{Metadata: metadata}, nil
+}
+
+func (m *resizeMasterServer) UpdateIndexSettings(ctx context.Context, req *pb.UpdateIndexSettingsRequest) (*pb.UpdateIndexSettingsResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	metadata, exists := m.indices[req.IndexName]
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "index [%s] not found", req.IndexName)
+	}
+	// Like the master, the update replaces all the settings of the index
+	m.indices[req.IndexName] = &pb.IndexMetadata{
+		IndexName: req.IndexName,
+		Version:   metadata.Version + 1,
+		Settings:  proto.Clone(req.Settings).(*pb.IndexSettings),
+		Mappings:  metadata.Mappings,
+	}
+	m.blocks = append(m.blocks, fmt.Sprintf("%s=%t", req.IndexName, req.Settings.BlocksWrite))
+	return &pb.UpdateIndexSettingsResponse{Acknowledged: true}, nil
+}
+
+func (m *resizeMasterServer) PutMapping(ctx context.Context, req *pb.PutMappingRequest) (*pb.PutMappingResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	metadata, exists := m.indices[req.IndexName]
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "index [%s] not found", req.IndexName)
+	}
+	if req.Version != metadata.Version {
+		return nil, status.Errorf(codes.Aborted, "index [%s] is at version [%d]", req.IndexName, metadata.Version)
+	}
+	settings := proto.Clone(metadata.Settings).(*pb.IndexSettings)
+	settings.Analysis, settings.NumericFields = req.Analysis, req.NumericFields
+	settings.DateFields, settings.GeoPointFields = req.DateFields, req.GeoPointFields
+	m.indices[req.IndexName] = &pb.IndexMetadata{
+		IndexName: req.IndexName,
+		Version:   metadata.Version + 1,
+		Settings:  settings,
+		Mappings:  req.Mappings,
+	}
+	return &pb.PutMappingResponse{Acknowledged: true, Version: metadata.Version + 1}, nil
+}
+
+func (m *resizeMasterServer) GetClusterState(ctx context.Context, req *pb.GetClusterStateRequest) (*pb.ClusterStateResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	routing := &pb.RoutingTable{Indices: make(map[string]*pb.IndexRoutingTable)}
+	var indices []*pb.IndexMetadata
+	for name, metadata := range m.indices {
+		indices = append(indices, metadata)
+		shards := make(map[int32]*pb.ShardRouting)
+		for shardID := int32(0); shardID < metadata.Settings.GetNumberOfShards(); shardID++ {
+			shards[shardID] = &pb.ShardRouting{
+				ShardId:   shardID,
+				IsPrimary: true,
+				Allocation: &pb.ShardAllocation{
+					NodeId: "node1",
+					State:  pb.ShardAllocation_SHARD_STATE_STARTED,
+				},
+			}
+		}
+		routing.Indices[name] = &pb.IndexRoutingTable{IndexName: name, Shards: shards}
+	}
+	return &pb.ClusterStateResponse{Indices: indices, RoutingTable: routing}, nil
+}
+
+// shardedStore is an in-memory data node that keeps each index's documents
+// by shard, serving both searches and document writes
+type shardedStore struct {
+	mu     sync.Mutex
+	shards map[string]map[int32]map[string]map[string]interface{} // index -> shard -> id -> doc
+}
+
+func (s *shardedStore) ExecuteSearch(ctx context.Context, indexName string, query []byte, filterExpr []byte, from, size int) (*executor.SearchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hits := []*executor.SearchHit{}
+	for _, docs := range s.shards[indexName] {
+		for id, doc := range docs {
+			hits = append(hits, &executor.SearchHit{ID: id, Score: 1.0, Source: doc})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].ID < hits[j].ID })
+
+	total := int64(len(hits))
+	from = min(from, len(hits))
+	hits = hits[from:min(from+size, len(hits))]
+	return &executor.SearchResult{TotalHits: total, MaxScore: 1.0, Hits: hits}, nil
+}
+
+func (s *shardedStore) ExecuteSuggest(ctx context.Context, indexName string, block map[string]interface{}) (map[string][]suggest.Entry, error) {
+	return nil, status.Error(codes.Unimplemented, "suggesters are not supported")
+}
+
+func (s *shardedStore) IndexDocument(ctx context.Context, indexName string, shardID int32, docID string, document map[string]interface{}) (*pb.IndexDocumentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shards[indexName] == nil {
+		s.shards[indexName] = make(map[int32]map[string]map[string]interface{})
+	}
+	if s.shards[indexName][shardID] == nil {
+		s.shards[indexName][shardID] = make(map[string]map[string]interface{})
+	}
+	s.shards[indexName][shardID][docID] = document
+	return &pb.IndexDocumentResponse{Acknowledged: true, Version: 1}, nil
+}
+
+func (s *shardedStore) GetDocument(ctx context.Context, indexName string, shardID int32, docID string) (*pb.GetDocumentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc, found := s.shards[indexName][shardID][docID]
+	if !found {
+		return &pb.GetDocumentResponse{Found: false}, nil
+	}
+	source, err := structpb.NewStruct(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetDocumentResponse{Found: true, Version: 1, Document: source}, nil
+}
+
+func (s *shardedStore) TermVectors(ctx context.Context, indexName string, shardID int32, docID string, fields []string) (*pb.TermVectorsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "term vectors are not supported")
+}
+
+func (s *shardedStore) DeleteDocument(ctx context.Context, indexName string, shardID int32, docID string) (*pb.DeleteDocumentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, found := s.shards[indexName][shardID][docID]
+	delete(s.shards[indexName][shardID], docID)
+	return &pb.DeleteDocumentResponse{Acknowledged: true, Found: found}, nil
+}
+
+func (s *shardedStore) IsConnected() bool                 { return true }
+func (s *shardedStore) Connect(ctx context.Context) error { return nil }
+func (s *shardedStore) NodeID() string                    { return "node1" }
+
+// shardCounts returns the number of documents in each shard of an index
+func (s *shardedStore) shardCounts(indexName string) map[int32]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make(map[int32]int)
+	for shardID, docs := range s.shards[indexName] {
+		counts[shardID] = len(docs)
+	}
+	return counts
+}
+
+func TestValidateResize(t *testing.T) {
+	assert.NoError(t, validateResize("split", 2, 4))
+	assert.NoError(t, validateResize("split", 1, 3))
+	assert.Error(t, validateResize("split", 2, 3))
+	assert.Error(t, validateResize("split", 2, 2))
+	assert.NoError(t, validateResize("shrink", 4, 2))
+	assert.NoError(t, validateResize("shrink", 3, 1))
+	assert.Error(t, validateResize("shrink", 4, 3))
+	assert.Error(t, validateResize("shrink", 2, 4))
+	assert.Error(t, validateResize("shrink", 2, 0))
+}
+
+func TestShrinkIndexKeepsDocuments(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &resizeMasterServer{indices: make(map[string]*pb.IndexMetadata)}
+	server := grpc.NewServer()
+	pb.RegisterMasterServiceServer(server, master)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	store := &shardedStore{shards: make(map[string]map[int32]map[string]map[string]interface{})}
+	node := newTestCoordinationNode(t)
+	node.masterClient = NewMasterClient(lis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	defer node.masterClient.Disconnect()
+	node.queryService = NewQueryService(store, node.masterClient, zap.NewNop())
+	node.docRouter = router.NewDocumentRouter(node.masterClient, map[string]router.DataNodeClient{"node1": store}, zap.NewNop())
+
+	// Seed a 2-shard index through the document router
+	ctx := context.Background()
+	_, err = node.masterClient.CreateIndex(ctx, "products", &pb.IndexSettings{
+		NumberOfShards:   2,
+		NumberOfReplicas: 0,
+		StoreType:        "niofs",
+		Analysis:         `{"analyzer":{"folded":{"tokenizer":"standard"}}}`,
+	}, nil)
+	require.NoError(t, err)
+	const numDocs = 25
+	for i := 0; i < numDocs; i++ {
+		_, err := node.docRouter.RouteIndexDocument(ctx, "products", fmt.Sprintf("doc-%d", i), map[string]interface{}{"n": float64(i)})
+		require.NoError(t, err)
+	}
+	require.Len(t, store.shardCounts("products"), 2, "documents should span both source shards")
+
+	w, resp := postJSON(node.ginRouter, "/products/_shrink/products-small", `{"settings":{"index":{"number_of_shards":1}}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, true, resp["acknowledged"])
+	assert.Equal(t, "products-small", resp["index"])
+
+	target, err := node.masterClient.GetIndexMetadata(ctx, "products-small")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), target.Metadata.Settings.NumberOfShards)
+	assert.Equal(t, "niofs", target.Metadata.Settings.StoreType)
+	assert.Equal(t, `{"analyzer":{"folded":{"tokenizer":"standard"}}}`, target.Metadata.Settings.Analysis)
+
+	// The source was write-blocked for the copy and is writable again, with
+	// its other settings unchanged
+	assert.Equal(t, []string{"products=true", "products=false"}, master.blocks)
+	source, err := node.masterClient.GetIndexMetadata(ctx, "products")
+	require.NoError(t, err)
+	assert.False(t, source.Metadata.Settings.BlocksWrite)
+	assert.Equal(t, int32(2), source.Metadata.Settings.NumberOfShards)
+	assert.Equal(t, "niofs", source.Metadata.Settings.StoreType)
+	assert.Equal(t, `{"analyzer":{"folded":{"tokenizer":"standard"}}}`, source.Metadata.Settings.Analysis)
+	_, err = node.docRouter.RouteIndexDocument(ctx, "products", "doc-0", map[string]interface{}{"n": float64(0)})
+	require.NoError(t, err)
+
+	// Every document survives, routed to the single target shard
+	assert.Equal(t, map[int32]int{0: numDocs}, store.shardCounts("products-small"))
+	for i := 0; i < numDocs; i++ {
+		id := fmt.Sprintf("doc-%d", i)
+		doc, err := node.docRouter.RouteGetDocument(ctx, "products-small", id)
+		require.NoError(t, err)
+		require.True(t, doc.Found, "document %s missing from target", id)
+		assert.Equal(t, float64(i), doc.Document.AsMap()["n"])
+	}
+
+	// Shard counts must divide evenly
+	w, _ = postJSON(node.ginRouter, "/products/_split/products-three", `{"settings":{"index":{"number_of_shards":3}}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	w, _ = postJSON(node.ginRouter, "/products/_split/products-large", `{"settings":{"index":{"number_of_shards":4}}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	counts := store.shardCounts("products-large")
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	assert.Equal(t, numDocs, total)
+
+	// The target must not exist and the source must
+	w, resp = postJSON(node.ginRouter, "/products/_shrink/products-small", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	assert.Equal(t, "resource_already_exists_exception", resp["error"].(map[string]interface{})["type"])
+
+	w, _ = postJSON(node.ginRouter, "/missing/_shrink/missing-small", "")
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
+
+// lossyStore is a data node that drops writes of one document to one index
+type lossyStore struct {
+	*shardedStore
+	index, docID string
+}
+
+func (s *lossyStore) IndexDocument(ctx context.Context, indexName string, shardID int32, docID string, document map[string]interface{}) (*pb.IndexDocumentResponse, error) {
+	if indexName == s.index && docID == s.docID {
+		return &pb.IndexDocumentResponse{Acknowledged: true, Version: 1}, nil
+	}
+	return s.shardedStore.IndexDocument(ctx, indexName, shardID, docID, document)
+}
+
+func TestResizeIndexBlocksWritesAndVerifiesCount(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &resizeMasterServer{indices: make(map[string]*pb.IndexMetadata)}
+	server := grpc.NewServer()
+	pb.RegisterMasterServiceServer(server, master)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	store := &lossyStore{
+		shardedStore: &shardedStore{shards: make(map[string]map[int32]map[string]map[string]interface{})},
+		index:        "logs-small",
+		docID:        "doc-3",
+	}
+	node := newTestCoordinationNode(t)
+	node.masterClient = NewMasterClient(lis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	defer node.masterClient.Disconnect()
+	node.queryService = NewQueryService(store, node.masterClient, zap.NewNop())
+	node.docRouter = router.NewDocumentRouter(node.masterClient, map[string]router.DataNodeClient{"node1": store}, zap.NewNop())
+
+	ctx := context.Background()
+	_, err = node.masterClient.CreateIndex(ctx, "logs", &pb.IndexSettings{NumberOfShards: 2}, nil)
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err := node.docRouter.RouteIndexDocument(ctx, "logs", fmt.Sprintf("doc-%d", i), map[string]interface{}{"n": float64(i)})
+		require.NoError(t, err)
+	}
+
+	// A target missing a document fails the resize
+	w, resp := postJSON(node.ginRouter, "/logs/_shrink/logs-small", "")
+	require.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+	assert.Contains(t, resp["error"].(map[string]interface{})["reason"], "holds 4 documents")
+
+	// The block is lifted even when the copy fails
+	assert.Equal(t, []string{"logs=true", "logs=false"}, master.blocks)
+
+	// A blocked index rejects writes and deletes but still serves reads
+	_, err = master.UpdateIndexSettings(ctx, &pb.UpdateIndexSettingsRequest{IndexName: "logs", Settings: &pb.IndexSettings{NumberOfShards: 2, BlocksWrite: true}})
+	require.NoError(t, err)
+	_, err = node.docRouter.RouteIndexDocument(ctx, "logs", "doc-9", map[string]interface{}{"n": float64(9)})
+	assert.ErrorIs(t, err, router.ErrIndexWriteBlocked)
+	assert.Equal(t, http.StatusForbidden, classifyError(err).Status)
+	_, err = node.docRouter.RouteDeleteDocument(ctx, "logs", "doc-1")
+	assert.ErrorIs(t, err, router.ErrIndexWriteBlocked)
+	doc, err := node.docRouter.RouteGetDocument(ctx, "logs", "doc-1")
+	require.NoError(t, err)
+	assert.True(t, doc.Found)
+
+	// A resize leaves a block it didn't set in place
+	w, _ = postJSON(node.ginRouter, "/logs/_shrink/logs-one", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	metadata, err := node.masterClient.GetIndexMetadata(ctx, "logs")
+	require.NoError(t, err)
+	assert.True(t, metadata.Metadata.Settings.BlocksWrite)
+
+	// The target doesn't take on the source's write block
+	metadata, err = node.masterClient.GetIndexMetadata(ctx, "logs-one")
+	require.NoError(t, err)
+	assert.False(t, metadata.Metadata.Settings.BlocksWrite)
+}
@@ -0,0 +1,127 @@
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"go.uber.org/zap"
+)
+
+// defaultMaxResultWindow is the largest from+size a search may request on
+// an index that doesn't set index.max_result_window
+const defaultMaxResultWindow = 10000
+
+// settingsMaxResultWindow returns the result window index settings set,
+// or the default
+func settingsMaxResultWindow(settings *pb.IndexSettings) int {
+	if window := settings.GetMaxResultWindow(); window > 0 {
+		return int(window)
+	}
+	return defaultMaxResultWindow
+}
+
+// parseMaxResultWindow parses an index.max_result_window setting value,
+// given as a number or a numeric string. Null restores the default and
+// parses as zero.
+func parseMaxResultWindow(value interface{}) (int, error) {
+	var window float64
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		window = v
+	case string:
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("invalid max_result_window [%s]: expected a positive integer", v)
+		}
+		window = float64(parsed)
+	default:
+		return 0, fmt.Errorf("max_result_window must be a positive integer")
+	}
+	if window < 1 || window != math.Trunc(window) || window > math.MaxInt32 {
+		return 0, fmt.Errorf("invalid max_result_window [%v]: expected a positive integer", value)
+	}
+	return int(window), nil
+}
+
+// ResultWindowTooLargeError is returned when a search asks for hits past
+// the result window of an index
+type ResultWindowTooLargeError struct {
+	Index           string
+	Window          int // from+size of the request
+	MaxResultWindow int
+}
+
+// Error implements error interface
+func (e *ResultWindowTooLargeError) Error() string {
+	return fmt.Sprintf("Result window is too large, from + size must be less than or equal to: [%d] but was [%d]. "+
+		"Page through large result sets with search_after or a scroll instead, or raise the "+
+		"[index.max_result_window] setting of index [%s].", e.MaxResultWindow, e.Window, e.Index)
+}
+
+// MaxResultWindow returns the largest from+size a search may request on an
+// index, as its settings on the master set it. An index the master doesn't
+// know has the default window; its search fails on its own.
+func (qs *QueryService) MaxResultWindow(ctx context.Context, indexName string) int {
+	metadata, err := qs.masterClient.GetIndexMetadata(ctx, indexName)
+	if err != nil {
+		return defaultMaxResultWindow
+	}
+	return settingsMaxResultWindow(metadata.GetMetadata().GetSettings())
+}
+
+// indexSettings returns the settings on the master of each of indices. An
+// index the master doesn't know has no entry; its search fails on its own.
+func (qs *QueryService) indexSettings(ctx context.Context, indices []string) map[string]*pb.IndexSettings {
+	settings := make(map[string]*pb.IndexSettings, len(indices))
+	for _, index := range indices {
+		if metadata, err := qs.masterClient.GetIndexMetadata(ctx, index); err == nil {
+			settings[index] = metadata.GetMetadata().GetSettings()
+		}
+	}
+	return settings
+}
+
+// checkResultWindow rejects a search whose from+size goes past the result
+// window of any of the indices it searches
+func checkResultWindow(indices []string, settings map[string]*pb.IndexSettings, from, size int) error {
+	window := from + size
+	for _, index := range indices {
+		if max := settingsMaxResultWindow(settings[index]); window > max {
+			return &ResultWindowTooLargeError{Index: index, Window: window, MaxResultWindow: max}
+		}
+	}
+	return nil
+}
+
+// updateMaxResultWindow records an index's result window in its settings on
+// the master, keeping its other settings. Zero restores the default. It
+// responds with the error and returns false when the update fails.
+func (c *CoordinationNode) updateMaxResultWindow(ctx *gin.Context, indexName string, window int) bool {
+	current, err := c.masterClient.GetIndexMetadata(ctx.Request.Context(), indexName)
+	if err != nil {
+		respondAPIError(ctx, indexNotFoundError(indexName))
+		return false
+	}
+
+	settings := cloneIndexSettings(current.GetMetadata().GetSettings())
+	settings.MaxResultWindow = int32(window)
+	if _, err := c.masterClient.UpdateIndexSettings(ctx.Request.Context(), indexName, settings); err != nil {
+		c.logger.Error("Failed to update max result window",
+			zap.String("index", indexName),
+			zap.Int("max_result_window", window),
+			zap.Error(err))
+		respondErrorFrom(ctx, err, "settings_update_exception", "Failed to update max_result_window")
+		return false
+	}
+
+	c.logger.Info("Updated max result window",
+		zap.String("index", indexName),
+		zap.Int("max_result_window", window))
+	return true
+}
@@ -0,0 +1,177 @@
+package coordination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/coordination/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+func TestExecuteSearchResultWindow(t *testing.T) {
+	// The master holds each index's window
+	windows := map[string]int32{}
+	master := &mockPipelineMasterClient{
+		getIndexMetadataFunc: func(ctx context.Context, indexName string) (*pb.IndexMetadataResponse, error) {
+			if indexName == "missing" {
+				return nil, fmt.Errorf("index [%s] not found", indexName)
+			}
+			settings := &pb.IndexSettings{NumberOfShards: 1, MaxResultWindow: windows[indexName]}
+			return &pb.IndexMetadataResponse{Metadata: &pb.IndexMetadata{IndexName: indexName, Settings: settings}}, nil
+		},
+	}
+	service := NewQueryService(&mockQueryExecutor{}, master, zap.NewNop())
+
+	search := func(indexName string, from, size int) error {
+		body := []byte(fmt.Sprintf(`{"query": {"match_all": {}}, "from": %d, "size": %d}`, from, size))
+		_, err := service.ExecuteSearch(context.Background(), indexName, body)
+		return err
+	}
+
+	// Below and at the default window
+	require.NoError(t, search("products", 9980, 10))
+	require.NoError(t, search("products", 9990, 10))
+
+	// Above it
+	err := search("products", 9991, 10)
+	var windowErr *ResultWindowTooLargeError
+	require.True(t, errors.As(err, &windowErr), "expected a result window error, got %v", err)
+	assert.Equal(t, 10001, windowErr.Window)
+	assert.Equal(t, defaultMaxResultWindow, windowErr.MaxResultWindow)
+	assert.Contains(t, err.Error(), "search_after")
+	assert.Contains(t, err.Error(), "index.max_result_window")
+
+	// The window is per index
+	windows["products"] = 20000
+	require.NoError(t, search("products", 19990, 10))
+	assert.Error(t, search("products", 19991, 10))
+	assert.Error(t, search("logs", 9991, 10))
+
+	// Searching several indices is bounded by the smallest window
+	err = search("products,logs", 9991, 10)
+	require.True(t, errors.As(err, &windowErr))
+	assert.Equal(t, "logs", windowErr.Index)
+
+	// Zero is the default, as is the window of an index the master doesn't know
+	windows["products"] = 0
+	assert.Equal(t, defaultMaxResultWindow, service.MaxResultWindow(context.Background(), "products"))
+	assert.Equal(t, defaultMaxResultWindow, service.MaxResultWindow(context.Background(), "missing"))
+
+	// Internal scrolls page past the window
+	_, err = service.executeSearch(context.Background(), "products",
+		[]byte(`{"query": {"match_all": {}}, "from": 20000, "size": 10}`), false)
+	assert.NoError(t, err)
+}
+
+func TestParseMaxResultWindow(t *testing.T) {
+	window, err := parseMaxResultWindow(float64(50000))
+	require.NoError(t, err)
+	assert.Equal(t, 50000, window)
+
+	window, err = parseMaxResultWindow("50000")
+	require.NoError(t, err)
+	assert.Equal(t, 50000, window)
+
+	window, err = parseMaxResultWindow(nil)
+	require.NoError(t, err)
+	assert.Zero(t, window)
+
+	for _, value := range []interface{}{float64(0), float64(-5), 1.5, "many", true} {
+		_, err = parseMaxResultWindow(value)
+		assert.Error(t, err, "value %v", value)
+	}
+}
+
+func TestIndexSettingsHTTP_MaxResultWindow(t *testing.T) {
+	masterLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &resizeMasterServer{indices: map[string]*pb.IndexMetadata{
+		"products": {IndexName: "products", Settings: &pb.IndexSettings{NumberOfShards: 1, NumberOfReplicas: 1}},
+	}}
+	masterServer := grpc.NewServer()
+	pb.RegisterMasterServiceServer(masterServer, master)
+	go masterServer.Serve(masterLis)
+	defer masterServer.Stop()
+
+	// Two coordination nodes of the same cluster
+	newNode := func() *CoordinationNode {
+		node := newTestCoordinationNode(t)
+		node.pipelineRegistry = pipeline.NewRegistry(zap.NewNop())
+		node.masterClient = NewMasterClient(masterLis.Addr().String(), zap.NewNop())
+		require.NoError(t, node.masterClient.Connect(context.Background()))
+		t.Cleanup(func() { node.masterClient.Disconnect() })
+		node.queryService = NewQueryService(&mockPipelineQueryExecutor{}, &mockPipelineMasterClient{
+			getIndexMetadataFunc: node.masterClient.GetIndexMetadata,
+		}, zap.NewNop())
+		return node
+	}
+	node, other := newNode(), newNode()
+
+	doOn := func(node *CoordinationNode, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		return w
+	}
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		return doOn(node, method, path, body)
+	}
+	getWindow := func() interface{} {
+		w := do(http.MethodGet, "/products/_settings", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		indexSettings := response["products"].(map[string]interface{})["settings"].(map[string]interface{})["index"].(map[string]interface{})
+		return indexSettings["max_result_window"]
+	}
+
+	// Past the default window the search is rejected with a client error
+	w := do(http.MethodPost, "/products/_search", `{"from": 10000, "size": 10}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "illegal_argument_exception")
+	assert.Nil(t, getWindow())
+
+	w = do(http.MethodPut, "/products/_settings", `{"index": {"max_result_window": 20000}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "20000", getWindow())
+
+	w = do(http.MethodPost, "/products/_search", `{"from": 10000, "size": 10}`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// The window is kept on the master, so the other node enforces it too,
+	// and updating it keeps the index's other settings
+	w = doOn(other, http.MethodPost, "/products/_search", `{"from": 19990, "size": 10}`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doOn(other, http.MethodPost, "/products/_search", `{"from": 19991, "size": 10}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	assert.Equal(t, int32(20000), master.indices["products"].Settings.MaxResultWindow)
+	assert.Equal(t, int32(1), master.indices["products"].Settings.NumberOfReplicas)
+
+	// Invalid windows are rejected and leave the setting unchanged
+	w = do(http.MethodPut, "/products/_settings", `{"index": {"max_result_window": -1}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "20000", getWindow())
+
+	// Null restores the default
+	w = do(http.MethodPut, "/products/_settings", `{"index": {"max_result_window": null}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, getWindow())
+	w = doOn(other, http.MethodPost, "/products/_search", `{"from": 10000, "size": 10}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	w = do(http.MethodPut, "/missing/_settings", `{"index": {"max_result_window": 20000}}`)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
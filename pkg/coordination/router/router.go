@@ -2,17 +2,35 @@ package router
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"hash/fnv"
+	"sync"
 
 	pb "github.com/quidditch/quidditch/pkg/common/proto"
 	"go.uber.org/zap"
 )
 
+// ErrShardRelocating is returned for writes to a shard being relocated.
+// The copy to the target node is taken from a snapshot of the source, so
+// writes are rejected until the relocation completes rather than lost.
+var ErrShardRelocating = errors.New("shard is relocating")
+
+// ErrReplicaRecovering is returned for writes to a shard one of whose
+// replicas is recovering from the primary. The recovery copies a snapshot
+// of the primary, so writes are rejected until the replica has started and
+// takes them itself rather than missing them.
+var ErrReplicaRecovering = errors.New("shard replica is recovering")
+
+// ErrIndexWriteBlocked is returned for writes to an index whose
+// index.blocks.write setting is set
+var ErrIndexWriteBlocked = errors.New("index is blocked for writes")
+
 // DataNodeClient interface for communication with data nodes
 type DataNodeClient interface {
 	IndexDocument(ctx context.Context, indexName string, shardID int32, docID string, document map[string]interface{}) (*pb.IndexDocumentResponse, error)
 	GetDocument(ctx context.Context, indexName string, shardID int32, docID string) (*pb.GetDocumentResponse, error)
+	TermVectors(ctx context.Context, indexName string, shardID int32, docID string, fields []string) (*pb.TermVectorsResponse, error)
 	DeleteDocument(ctx context.Context, indexName string, shardID int32, docID string) (*pb.DeleteDocumentResponse, error)
 	IsConnected() bool
 	Connect(ctx context.Context) error
@@ -49,6 +67,10 @@ func (dr *DocumentRouter) RouteIndexDocument(ctx context.Context, indexName, doc
 		return nil, fmt.Errorf("failed to get index metadata: %w", err)
 	}
 
+	if metadata.Metadata.Settings.GetBlocksWrite() {
+		return nil, fmt.Errorf("index [%s]: %w", indexName, ErrIndexWriteBlocked)
+	}
+
 	numShards := metadata.Metadata.Settings.NumberOfShards
 	if numShards == 0 {
 		return nil, fmt.Errorf("index has no shards configured")
@@ -69,8 +91,8 @@ func (dr *DocumentRouter) RouteIndexDocument(ctx context.Context, indexName, doc
 	}
 
 	// Find primary shard for writes
-	if shard.Allocation == nil || shard.Allocation.State != pb.ShardAllocation_SHARD_STATE_STARTED {
-		return nil, fmt.Errorf("shard %d is not available (state: %v)", shardID, shard.Allocation.State)
+	if err := shardWritable(shard); err != nil {
+		return nil, fmt.Errorf("shard %d of index %s: %w", shardID, indexName, err)
 	}
 
 	// Only write to primary shard
@@ -109,6 +131,15 @@ func (dr *DocumentRouter) RouteIndexDocument(ctx context.Context, indexName, doc
 		return nil, err
 	}
 
+	// Replicas serve reads, so each takes the write too
+	err = dr.replicate(ctx, shard, func(replica DataNodeClient) error {
+		_, err := replica.IndexDocument(ctx, indexName, shardID, docID, document)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("document [%s] was indexed on the primary of shard %d but not on a replica: %w", docID, shardID, err)
+	}
+
 	dr.logger.Info("IndexDocument succeeded",
 		zap.String("doc_id", docID),
 		zap.Int64("version", resp.Version))
@@ -118,15 +149,50 @@ func (dr *DocumentRouter) RouteIndexDocument(ctx context.Context, indexName, doc
 
 // RouteGetDocument routes a get document operation to the correct shard
 func (dr *DocumentRouter) RouteGetDocument(ctx context.Context, indexName, docID string) (*pb.GetDocumentResponse, error) {
+	client, shardID, err := dr.routeRead(ctx, indexName, docID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Route to data node
+	dr.logger.Debug("Routing get document",
+		zap.String("index", indexName),
+		zap.String("doc_id", docID),
+		zap.Int32("shard_id", shardID),
+		zap.String("node_id", client.NodeID()))
+
+	return client.GetDocument(ctx, indexName, shardID, docID)
+}
+
+// RouteTermVectors routes a term vectors request to the shard holding the
+// document
+func (dr *DocumentRouter) RouteTermVectors(ctx context.Context, indexName, docID string, fields []string) (*pb.TermVectorsResponse, error) {
+	client, shardID, err := dr.routeRead(ctx, indexName, docID)
+	if err != nil {
+		return nil, err
+	}
+
+	dr.logger.Debug("Routing term vectors",
+		zap.String("index", indexName),
+		zap.String("doc_id", docID),
+		zap.Int32("shard_id", shardID),
+		zap.String("node_id", client.NodeID()))
+
+	return client.TermVectors(ctx, indexName, shardID, docID, fields)
+}
+
+// routeRead returns the connected client of the node serving reads of a
+// document, and the shard holding it
+func (dr *DocumentRouter) routeRead(ctx context.Context, indexName, docID string) (DataNodeClient, int32, error) {
 	// Get index metadata to determine number of shards
 	metadata, err := dr.masterClient.GetIndexMetadata(ctx, indexName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get index metadata: %w", err)
+		return nil, 0, fmt.Errorf("failed to get index metadata: %w", err)
 	}
 
 	numShards := metadata.Metadata.Settings.NumberOfShards
 	if numShards == 0 {
-		return nil, fmt.Errorf("index has no shards configured")
+		return nil, 0, fmt.Errorf("index has no shards configured")
 	}
 
 	// Calculate which shard this document belongs to
@@ -135,45 +201,29 @@ func (dr *DocumentRouter) RouteGetDocument(ctx context.Context, indexName, docID
 	// Get shard routing information
 	routing, err := dr.masterClient.GetShardRouting(ctx, indexName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get shard routing: %w", err)
+		return nil, 0, fmt.Errorf("failed to get shard routing: %w", err)
 	}
 
 	shard, exists := routing[shardID]
 	if !exists {
-		return nil, fmt.Errorf("shard %d not found for index %s", shardID, indexName)
+		return nil, 0, fmt.Errorf("shard %d not found for index %s", shardID, indexName)
 	}
 
 	// For reads, we can use primary or replica
-	if shard.Allocation == nil || shard.Allocation.State != pb.ShardAllocation_SHARD_STATE_STARTED {
-		return nil, fmt.Errorf("shard %d is not available", shardID)
+	if !shardActive(shard.Allocation) {
+		return nil, 0, fmt.Errorf("shard %d is not available", shardID)
 	}
 
 	nodeID := shard.Allocation.NodeId
 	if nodeID == "" {
-		return nil, fmt.Errorf("shard %d has no node assignment", shardID)
-	}
-
-	// Get data node client
-	client, exists := dr.dataClients[nodeID]
-	if !exists {
-		return nil, fmt.Errorf("data node %s not found", nodeID)
+		return nil, 0, fmt.Errorf("shard %d has no node assignment", shardID)
 	}
 
-	// Ensure client is connected
-	if !client.IsConnected() {
-		if err := client.Connect(ctx); err != nil {
-			return nil, fmt.Errorf("failed to connect to node %s: %w", nodeID, err)
-		}
+	client, err := dr.connectedClient(ctx, nodeID)
+	if err != nil {
+		return nil, 0, err
 	}
-
-	// Route to data node
-	dr.logger.Debug("Routing get document",
-		zap.String("index", indexName),
-		zap.String("doc_id", docID),
-		zap.Int32("shard_id", shardID),
-		zap.String("node_id", nodeID))
-
-	return client.GetDocument(ctx, indexName, shardID, docID)
+	return client, shardID, nil
 }
 
 // RouteDeleteDocument routes a delete document operation to the correct shard
@@ -184,6 +234,10 @@ func (dr *DocumentRouter) RouteDeleteDocument(ctx context.Context, indexName, do
 		return nil, fmt.Errorf("failed to get index metadata: %w", err)
 	}
 
+	if metadata.Metadata.Settings.GetBlocksWrite() {
+		return nil, fmt.Errorf("index [%s]: %w", indexName, ErrIndexWriteBlocked)
+	}
+
 	numShards := metadata.Metadata.Settings.NumberOfShards
 	if numShards == 0 {
 		return nil, fmt.Errorf("index has no shards configured")
@@ -204,8 +258,8 @@ func (dr *DocumentRouter) RouteDeleteDocument(ctx context.Context, indexName, do
 	}
 
 	// Only delete from primary shard
-	if shard.Allocation == nil || shard.Allocation.State != pb.ShardAllocation_SHARD_STATE_STARTED {
-		return nil, fmt.Errorf("shard %d is not available", shardID)
+	if err := shardWritable(shard); err != nil {
+		return nil, fmt.Errorf("shard %d of index %s: %w", shardID, indexName, err)
 	}
 
 	if !shard.IsPrimary {
@@ -237,7 +291,128 @@ func (dr *DocumentRouter) RouteDeleteDocument(ctx context.Context, indexName, do
 		zap.Int32("shard_id", shardID),
 		zap.String("node_id", nodeID))
 
-	return client.DeleteDocument(ctx, indexName, shardID, docID)
+	resp, err := client.DeleteDocument(ctx, indexName, shardID, docID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dr.replicate(ctx, shard, func(replica DataNodeClient) error {
+		_, err := replica.DeleteDocument(ctx, indexName, shardID, docID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("document [%s] was deleted on the primary of shard %d but not on a replica: %w", docID, shardID, err)
+	}
+	return resp, nil
+}
+
+// MultiGetItem identifies a document fetched by RouteMultiGetDocuments
+type MultiGetItem struct {
+	IndexName string
+	DocID     string
+}
+
+// MultiGetResult is the outcome of fetching one MultiGetItem
+type MultiGetResult struct {
+	Response *pb.GetDocumentResponse
+	Err      error
+}
+
+// RouteMultiGetDocuments fetches several documents, returning one result per
+// item in the same order. Index metadata and routing are looked up once per
+// index, and the documents held by each data node are fetched by one worker
+// per node, concurrently across nodes.
+func (dr *DocumentRouter) RouteMultiGetDocuments(ctx context.Context, items []MultiGetItem) []MultiGetResult {
+	results := make([]MultiGetResult, len(items))
+
+	type shardRouting struct {
+		numShards int32
+		routing   map[int32]*pb.ShardRouting
+		err       error
+	}
+	indexRouting := make(map[string]*shardRouting)
+
+	type nodeItem struct {
+		pos     int
+		shardID int32
+	}
+	nodeItems := make(map[string][]nodeItem)
+
+	for i, item := range items {
+		r, ok := indexRouting[item.IndexName]
+		if !ok {
+			r = &shardRouting{}
+			metadata, err := dr.masterClient.GetIndexMetadata(ctx, item.IndexName)
+			if err != nil {
+				r.err = fmt.Errorf("failed to get index metadata: %w", err)
+			} else if r.numShards = metadata.Metadata.Settings.NumberOfShards; r.numShards == 0 {
+				r.err = fmt.Errorf("index has no shards configured")
+			} else if r.routing, err = dr.masterClient.GetShardRouting(ctx, item.IndexName); err != nil {
+				r.err = fmt.Errorf("failed to get shard routing: %w", err)
+			}
+			indexRouting[item.IndexName] = r
+		}
+		if r.err != nil {
+			results[i].Err = r.err
+			continue
+		}
+
+		shardID := dr.calculateShardID(item.DocID, r.numShards)
+		shard, exists := r.routing[shardID]
+		if !exists {
+			results[i].Err = fmt.Errorf("shard %d not found for index %s", shardID, item.IndexName)
+			continue
+		}
+		if !shardActive(shard.Allocation) {
+			results[i].Err = fmt.Errorf("shard %d is not available", shardID)
+			continue
+		}
+		nodeID := shard.Allocation.NodeId
+		if nodeID == "" {
+			results[i].Err = fmt.Errorf("shard %d has no node assignment", shardID)
+			continue
+		}
+		nodeItems[nodeID] = append(nodeItems[nodeID], nodeItem{pos: i, shardID: shardID})
+	}
+
+	var wg sync.WaitGroup
+	for nodeID, batch := range nodeItems {
+		wg.Add(1)
+		go func(nodeID string, batch []nodeItem) {
+			defer wg.Done()
+
+			client, err := dr.connectedClient(ctx, nodeID)
+			for _, ni := range batch {
+				if err != nil {
+					results[ni.pos].Err = err
+					continue
+				}
+				item := items[ni.pos]
+				results[ni.pos].Response, results[ni.pos].Err = client.GetDocument(ctx, item.IndexName, ni.shardID, item.DocID)
+			}
+		}(nodeID, batch)
+	}
+	wg.Wait()
+
+	dr.logger.Debug("Routed multi get",
+		zap.Int("docs", len(items)),
+		zap.Int("nodes", len(nodeItems)))
+
+	return results
+}
+
+// connectedClient returns the client of a data node, connecting it if needed
+func (dr *DocumentRouter) connectedClient(ctx context.Context, nodeID string) (DataNodeClient, error) {
+	client, exists := dr.dataClients[nodeID]
+	if !exists {
+		return nil, fmt.Errorf("data node %s not found", nodeID)
+	}
+	if !client.IsConnected() {
+		if err := client.Connect(ctx); err != nil {
+			return nil, fmt.Errorf("failed to connect to node %s: %w", nodeID, err)
+		}
+	}
+	return client, nil
 }
 
 // calculateShardID uses consistent hashing to determine which shard a document belongs to
@@ -255,3 +430,53 @@ func (dr *DocumentRouter) calculateShardID(docID string, numShards int32) int32
 func (dr *DocumentRouter) SetDataClients(clients map[string]DataNodeClient) {
 	dr.dataClients = clients
 }
+
+// shardActive reports whether a shard copy serves requests. A relocating
+// shard keeps serving from its source node until the relocation completes.
+func shardActive(allocation *pb.ShardAllocation) bool {
+	state := allocation.GetState()
+	return state == pb.ShardAllocation_SHARD_STATE_STARTED || state == pb.ShardAllocation_SHARD_STATE_RELOCATING
+}
+
+// shardWritable reports why a shard can't take writes, or nil if it can.
+// Only a started primary takes writes; a relocating one would lose them
+// once its source is removed. Its replicas must not be relocating or
+// recovering either, as they would miss the writes their copy was taken
+// before.
+func shardWritable(shard *pb.ShardRouting) error {
+	switch state := shard.GetAllocation().GetState(); state {
+	case pb.ShardAllocation_SHARD_STATE_STARTED:
+	case pb.ShardAllocation_SHARD_STATE_RELOCATING:
+		return ErrShardRelocating
+	default:
+		return fmt.Errorf("shard is not available (state: %v)", state)
+	}
+
+	for _, replica := range shard.GetReplicas() {
+		switch replica.GetState() {
+		case pb.ShardAllocation_SHARD_STATE_RELOCATING:
+			return ErrShardRelocating
+		case pb.ShardAllocation_SHARD_STATE_INITIALIZING:
+			return ErrReplicaRecovering
+		}
+	}
+	return nil
+}
+
+// replicate runs a write already applied to a shard's primary against each
+// of its started replicas
+func (dr *DocumentRouter) replicate(ctx context.Context, shard *pb.ShardRouting, write func(replica DataNodeClient) error) error {
+	for _, replica := range shard.GetReplicas() {
+		if replica.GetState() != pb.ShardAllocation_SHARD_STATE_STARTED || replica.GetNodeId() == "" {
+			continue
+		}
+		client, err := dr.connectedClient(ctx, replica.GetNodeId())
+		if err == nil {
+			err = write(client)
+		}
+		if err != nil {
+			return fmt.Errorf("replica on node %s: %w", replica.GetNodeId(), err)
+		}
+	}
+	return nil
+}
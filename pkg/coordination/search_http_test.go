@@ -0,0 +1,47 @@
+package coordination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/quidditch/quidditch/pkg/coordination/executor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHandleSearch_CircuitBreakingException(t *testing.T) {
+	node := newTestCoordinationNode(t)
+
+	// The data node rejects the aggregation; the error comes back wrapped by
+	// the data client and executor
+	tripped := status.Error(codes.ResourceExhausted,
+		"circuit_breaking_exception: [fielddata] Data too large, data for [terms[by_user] on field [user_id]] would be [960000000/915.5mb], which is larger than the limit of [536870912/512.0mb]")
+	node.queryService = NewQueryService(&mockPipelineQueryExecutor{
+		executeFunc: func(ctx context.Context, indexName string, query []byte, filterExpr []byte, from, size int) (*executor.SearchResult, error) {
+			shardErr := fmt.Errorf("search failed on node data-1 shard 0: %w", tripped)
+			return nil, fmt.Errorf("all shard searches failed: %w", shardErr)
+		},
+	}, &mockPipelineMasterClient{}, zap.NewNop())
+
+	body := []byte(`{"query":{"match_all":{}},"aggs":{"by_user":{"terms":{"field":"user_id"}}}}`)
+	req := httptest.NewRequest(http.MethodPost, "/events/_search", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	node.ginRouter.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusTooManyRequests, w.Code, w.Body.String())
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	errObj := response["error"].(map[string]interface{})
+	assert.Equal(t, "circuit_breaking_exception", errObj["type"])
+	assert.Contains(t, errObj["reason"], "Data too large")
+}
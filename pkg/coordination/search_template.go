@@ -0,0 +1,178 @@
+package coordination
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// storedScriptRequest is the body of a PUT /_scripts/:id request
+type storedScriptRequest struct {
+	Script *struct {
+		Lang   string          `json:"lang"`
+		Source json.RawMessage `json:"source"`
+	} `json:"script"`
+}
+
+// searchTemplateRequest is the body of a _search/template request: a stored
+// template id or an inline source, rendered with params
+type searchTemplateRequest struct {
+	ID     string                 `json:"id"`
+	Source json.RawMessage        `json:"source"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// templateSource returns a template source given either as a JSON string or
+// as a JSON object, which is used verbatim
+func templateSource(raw json.RawMessage) (string, error) {
+	var source string
+	if err := json.Unmarshal(raw, &source); err == nil {
+		return source, nil
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return "", fmt.Errorf("template source must be a string or an object")
+	}
+	return string(raw), nil
+}
+
+// handlePutStoredScript stores a mustache search template in the master
+// metadata
+func (c *CoordinationNode) handlePutStoredScript(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	badRequest := func(reason string) {
+		respondError(ctx, http.StatusBadRequest, "illegal_argument_exception", reason)
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		badRequest(fmt.Sprintf("Failed to read request body: %v", err))
+		return
+	}
+
+	var req storedScriptRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		badRequest(fmt.Sprintf("Failed to parse stored script: %v", err))
+		return
+	}
+	if req.Script == nil || len(req.Script.Source) == 0 {
+		badRequest("stored script must have a [script.source]")
+		return
+	}
+	if req.Script.Lang != "" && req.Script.Lang != "mustache" {
+		badRequest(fmt.Sprintf("unsupported script lang [%s], only mustache is supported", req.Script.Lang))
+		return
+	}
+
+	source, err := templateSource(req.Script.Source)
+	if err != nil {
+		badRequest(err.Error())
+		return
+	}
+	if _, _, err := parseMustache(source, ""); err != nil {
+		badRequest(fmt.Sprintf("invalid mustache template: %v", err))
+		return
+	}
+
+	if _, err := c.masterClient.PutStoredScript(ctx.Request.Context(), id, "mustache", source); err != nil {
+		c.logger.Error("Failed to store script", zap.String("id", id), zap.Error(err))
+		respondError(ctx, http.StatusInternalServerError, "script_exception", fmt.Sprintf("Failed to store script: %v", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"acknowledged": true})
+}
+
+// handleGetStoredScript returns a stored script
+func (c *CoordinationNode) handleGetStoredScript(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	script, err := c.masterClient.GetStoredScript(ctx.Request.Context(), id)
+	if status.Code(err) == codes.NotFound {
+		ctx.JSON(http.StatusNotFound, gin.H{"_id": id, "found": false})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Failed to get stored script", zap.String("id", id), zap.Error(err))
+		respondError(ctx, http.StatusInternalServerError, "script_exception", fmt.Sprintf("Failed to get stored script: %v", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"_id":   id,
+		"found": true,
+		"script": gin.H{
+			"lang":   script.Lang,
+			"source": script.Source,
+		},
+	})
+}
+
+// handleSearchTemplate renders a stored or inline search template with the
+// request params and executes the resulting search request
+func (c *CoordinationNode) handleSearchTemplate(ctx *gin.Context) {
+	startTime := time.Now()
+	indexName := ctx.Param("index")
+	if indexName == "" {
+		indexName = "_all"
+	}
+
+	badRequest := func(reason string) {
+		respondError(ctx, http.StatusBadRequest, "parsing_exception", reason)
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		badRequest(fmt.Sprintf("Failed to read request body: %v", err))
+		return
+	}
+
+	var req searchTemplateRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		badRequest(fmt.Sprintf("Failed to parse search template request: %v", err))
+		return
+	}
+
+	var source string
+	switch {
+	case req.ID != "" && len(req.Source) > 0:
+		badRequest("search template request must have either [id] or [source], not both")
+		return
+	case req.ID != "":
+		script, err := c.masterClient.GetStoredScript(ctx.Request.Context(), req.ID)
+		if status.Code(err) == codes.NotFound {
+			respondError(ctx, http.StatusNotFound, "resource_not_found_exception", fmt.Sprintf("unable to find script [%s]", req.ID))
+			return
+		}
+		if err != nil {
+			c.logger.Error("Failed to get stored script", zap.String("id", req.ID), zap.Error(err))
+			respondError(ctx, http.StatusInternalServerError, "script_exception", fmt.Sprintf("Failed to get stored script: %v", err))
+			return
+		}
+		source = script.Source
+	case len(req.Source) > 0:
+		if source, err = templateSource(req.Source); err != nil {
+			badRequest(err.Error())
+			return
+		}
+	default:
+		badRequest("search template request must have an [id] or a [source]")
+		return
+	}
+
+	rendered, err := renderMustache(source, req.Params)
+	if err != nil {
+		badRequest(fmt.Sprintf("Failed to render search template: %v", err))
+		return
+	}
+
+	c.search(ctx, indexName, []byte(rendered), startTime)
+}
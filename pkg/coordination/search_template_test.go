@@ -0,0 +1,155 @@
+package coordination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/coordination/executor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// scriptMasterServer is a master that keeps stored scripts in memory
+type scriptMasterServer struct {
+	pb.UnimplementedMasterServiceServer
+
+	mu      sync.Mutex
+	scripts map[string]*pb.PutStoredScriptRequest
+}
+
+func (m *scriptMasterServer) PutStoredScript(ctx context.Context, req *pb.PutStoredScriptRequest) (*pb.PutStoredScriptResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scripts[req.Id] = req
+	return &pb.PutStoredScriptResponse{Acknowledged: true}, nil
+}
+
+func (m *scriptMasterServer) GetStoredScript(ctx context.Context, req *pb.GetStoredScriptRequest) (*pb.GetStoredScriptResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	script, ok := m.scripts[req.Id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "stored script not found: %s", req.Id)
+	}
+	return &pb.GetStoredScriptResponse{Id: script.Id, Lang: script.Lang, Source: script.Source}, nil
+}
+
+func TestSearchTemplate_StoreAndExecute(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	pb.RegisterMasterServiceServer(server, &scriptMasterServer{scripts: make(map[string]*pb.PutStoredScriptRequest)})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	node := newTestCoordinationNode(t)
+	node.masterClient = NewMasterClient(lis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	defer node.masterClient.Disconnect()
+
+	var shardQuery []byte
+	node.queryService = NewQueryService(&mockPipelineQueryExecutor{
+		executeFunc: func(ctx context.Context, indexName string, query []byte, filterExpr []byte, from, size int) (*executor.SearchResult, error) {
+			shardQuery = query
+			return &executor.SearchResult{
+				TotalHits: 1,
+				MaxScore:  1.0,
+				Hits: []*executor.SearchHit{
+					{ID: "doc1", Score: 1.0, Source: map[string]interface{}{"title": "gaming laptop"}},
+				},
+			}, nil
+		},
+	}, &mockPipelineMasterClient{}, zap.NewNop())
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodPost, "/_scripts/title_search", `{
+		"script": {
+			"lang": "mustache",
+			"source": "{\"query\": {\"match\": {\"title\": \"{{query_string}}\"}}, \"size\": {{size}}}"
+		}
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = serve(http.MethodGet, "/_scripts/title_search", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "{{query_string}}")
+
+	w = serve(http.MethodPost, "/products/_search/template", `{
+		"id": "title_search",
+		"params": {"query_string": "gaming \"pro\" laptop", "size": 5}
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// The rendered query reached the shards with the params filled in
+	var query map[string]interface{}
+	require.NoError(t, json.Unmarshal(shardQuery, &query))
+	match := query["match"].(map[string]interface{})
+	assert.Equal(t, `gaming "pro" laptop`, match["title"])
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	hits := response["hits"].(map[string]interface{})["hits"].([]interface{})
+	require.Len(t, hits, 1)
+	assert.Equal(t, "doc1", hits[0].(map[string]interface{})["_id"])
+
+	// Unknown templates are not found
+	w = serve(http.MethodPost, "/products/_search/template", `{"id": "missing"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
+
+func TestRenderMustache(t *testing.T) {
+	params := map[string]interface{}{
+		"text":   `say "hi"`,
+		"size":   5,
+		"user":   map[string]interface{}{"id": "u1"},
+		"tags":   []interface{}{"a", "b"},
+		"strict": false,
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"escaped string", `{"q": "{{text}}"}`, `{"q": "say \"hi\""}`},
+		{"number", `{"size": {{size}}}`, `{"size": 5}`},
+		{"dotted name", `{{user.id}}`, `u1`},
+		{"missing value", `[{{nope}}]`, `[]`},
+		{"toJson", `{"terms": {{#toJson}}tags{{/toJson}}}`, `{"terms": ["a","b"]}`},
+		{"list section", `{{#tags}}<{{.}}>{{/tags}}`, `<a><b>`},
+		{"false section", `{{#strict}}strict{{/strict}}{{^strict}}lenient{{/strict}}`, `lenient`},
+		{"raw", `{{{text}}}`, `say "hi"`},
+		{"comment", `a{{! note }}b`, `ab`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderMustache(tt.template, params)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := renderMustache(`{{#tags}}unclosed`, params)
+	assert.Error(t, err)
+	_, err = renderMustache(`{{/tags}}`, params)
+	assert.Error(t, err)
+}
@@ -0,0 +1,116 @@
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"go.uber.org/zap"
+)
+
+// handleGetSegments reports the segments of every started copy of an
+// index's shards: their document, deleted document and byte counts, so
+// operators can judge how much merging is pending
+func (c *CoordinationNode) handleGetSegments(ctx *gin.Context) {
+	indexName := ctx.Param("index")
+
+	routing, err := c.masterClient.GetShardRouting(ctx.Request.Context(), indexName)
+	if err != nil {
+		respondAPIError(ctx, indexNotFoundError(indexName))
+		return
+	}
+
+	copies := startedShardCopies(routing)
+	results := make([]*pb.GetSegmentStatsResponse, len(copies))
+	errs := make([]error, len(copies))
+	var wg sync.WaitGroup
+	for i, shard := range copies {
+		wg.Add(1)
+		go func(i int, shard shardCopy) {
+			defer wg.Done()
+			results[i], errs[i] = c.shardCopySegments(ctx.Request.Context(), indexName, shard)
+		}(i, shard)
+	}
+	wg.Wait()
+
+	shards := gin.H{}
+	failures := make([]gin.H, 0)
+	for i, shard := range copies {
+		if errs[i] != nil {
+			failures = append(failures, gin.H{
+				"index":   indexName,
+				"shard":   shard.ShardID,
+				"node":    shard.NodeID,
+				"primary": shard.IsPrimary,
+				"reason": gin.H{
+					"type":   "segments_exception",
+					"reason": errs[i].Error(),
+				},
+			})
+			continue
+		}
+
+		segments := gin.H{}
+		for _, segment := range results[i].Segments {
+			segments[segment.Name] = gin.H{
+				"num_docs":      segment.NumDocs,
+				"deleted_docs":  segment.DeletedDocs,
+				"size_in_bytes": segment.SizeBytes,
+				"committed":     true,
+				"search":        true,
+			}
+		}
+		key := strconv.Itoa(int(shard.ShardID))
+		entries, _ := shards[key].([]gin.H)
+		shards[key] = append(entries, gin.H{
+			"routing": gin.H{
+				"state":   "STARTED",
+				"primary": shard.IsPrimary,
+				"node":    shard.NodeID,
+			},
+			"num_committed_segments": len(results[i].Segments),
+			"num_search_segments":    len(results[i].Segments),
+			"segments":               segments,
+		})
+	}
+
+	summary := gin.H{
+		"total":      len(copies),
+		"successful": len(copies) - len(failures),
+		"failed":     len(failures),
+	}
+	if len(failures) > 0 {
+		summary["failures"] = failures
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"_shards": summary,
+		"indices": gin.H{
+			indexName: gin.H{"shards": shards},
+		},
+	})
+}
+
+// shardCopySegments lists the segments of one shard copy on its data node
+func (c *CoordinationNode) shardCopySegments(ctx context.Context, indexName string, shard shardCopy) (*pb.GetSegmentStatsResponse, error) {
+	c.dataClientsMu.RLock()
+	client, exists := c.dataClients[shard.NodeID]
+	c.dataClientsMu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("data node %s not found", shard.NodeID)
+	}
+
+	resp, err := client.GetSegmentStats(ctx, indexName, shard.ShardID)
+	if err != nil {
+		c.logger.Warn("Failed to get shard segments",
+			zap.String("index", indexName),
+			zap.Int32("shard_id", shard.ShardID),
+			zap.String("node_id", shard.NodeID),
+			zap.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
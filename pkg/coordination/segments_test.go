@@ -0,0 +1,95 @@
+package coordination
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+func TestGetSegments(t *testing.T) {
+	// A master holding a 2-shard index on node1
+	masterLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &resizeMasterServer{indices: map[string]*pb.IndexMetadata{
+		"logs": {IndexName: "logs", Settings: &pb.IndexSettings{NumberOfShards: 2}},
+	}}
+	masterServer := grpc.NewServer()
+	pb.RegisterMasterServiceServer(masterServer, master)
+	go masterServer.Serve(masterLis)
+	defer masterServer.Stop()
+
+	dataLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	data := &segmentDataServer{segments: map[int32][]*pb.SegmentStats{}}
+	dataServer := grpc.NewServer()
+	pb.RegisterDataServiceServer(dataServer, data)
+	go dataServer.Serve(dataLis)
+	defer dataServer.Stop()
+
+	node := newTestCoordinationNode(t)
+	node.masterClient = NewMasterClient(masterLis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	defer node.masterClient.Disconnect()
+
+	dataClient := NewDataNodeClient("node1", dataLis.Addr().String(), zap.NewNop())
+	require.NoError(t, dataClient.Connect(context.Background()))
+	defer dataClient.Disconnect()
+	node.dataClients["node1"] = dataClient
+
+	// Each commit writes a segment to shard 0
+	for commit := 0; commit < 3; commit++ {
+		data.index(0, 10)
+	}
+	data.index(1, 5)
+
+	shardSegments := func(resp map[string]interface{}, shardID string) map[string]interface{} {
+		shards := resp["indices"].(map[string]interface{})["logs"].(map[string]interface{})["shards"].(map[string]interface{})
+		copies := shards[shardID].([]interface{})
+		require.Len(t, copies, 1)
+		return copies[0].(map[string]interface{})
+	}
+
+	w, resp := getJSON(node.ginRouter, "/logs/_segments")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, float64(2), resp["_shards"].(map[string]interface{})["successful"])
+
+	shard := shardSegments(resp, "0")
+	assert.Equal(t, float64(3), shard["num_committed_segments"])
+	assert.Equal(t, true, shard["routing"].(map[string]interface{})["primary"])
+	segments := shard["segments"].(map[string]interface{})
+	require.Len(t, segments, 3)
+	first := segments["_0"].(map[string]interface{})
+	assert.Equal(t, float64(10), first["num_docs"])
+	assert.Equal(t, float64(0), first["deleted_docs"])
+	assert.Equal(t, float64(segmentOverheadBytes+10*segmentDocBytes), first["size_in_bytes"])
+	assert.Equal(t, float64(1), shardSegments(resp, "1")["num_committed_segments"])
+
+	// Force merging collapses the shard's segments into one
+	w, _ = postJSON(node.ginRouter, "/logs/_forcemerge?max_num_segments=1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w, resp = getJSON(node.ginRouter, "/logs/_segments")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	shard = shardSegments(resp, "0")
+	assert.Equal(t, float64(1), shard["num_committed_segments"])
+	for _, segment := range shard["segments"].(map[string]interface{}) {
+		assert.Equal(t, float64(30), segment.(map[string]interface{})["num_docs"])
+		assert.Equal(t, float64(0), segment.(map[string]interface{})["deleted_docs"])
+	}
+
+	// A shard whose node is gone is reported as failed
+	delete(node.dataClients, "node1")
+	w, resp = getJSON(node.ginRouter, "/logs/_segments")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, float64(2), resp["_shards"].(map[string]interface{})["failed"])
+
+	w, _ = getJSON(node.ginRouter, "/missing/_segments")
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
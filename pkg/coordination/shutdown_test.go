@@ -0,0 +1,150 @@
+package coordination
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quidditch/quidditch/pkg/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// freeTestPort returns a local TCP port that is currently unused
+func freeTestPort(t *testing.T) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+	return port
+}
+
+// startTestHTTPServer starts the node's REST server and waits until it
+// accepts connections, returning its address
+func startTestHTTPServer(t *testing.T, node *CoordinationNode) string {
+	t.Helper()
+
+	require.NoError(t, node.startHTTPServer())
+
+	addr := net.JoinHostPort(node.cfg.BindAddr, strconv.Itoa(node.cfg.RESTPort))
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, 2*time.Second, 10*time.Millisecond)
+
+	return addr
+}
+
+// newShutdownTestNode serves router on a free local port
+func newShutdownTestNode(t *testing.T, router *gin.Engine, shutdownTimeout time.Duration) (*CoordinationNode, string) {
+	t.Helper()
+
+	node := &CoordinationNode{
+		cfg: &config.CoordinationConfig{
+			BindAddr:        "127.0.0.1",
+			RESTPort:        freeTestPort(t),
+			ShutdownTimeout: shutdownTimeout,
+		},
+		logger:      zap.NewNop(),
+		ginRouter:   router,
+		dataClients: make(map[string]*DataNodeClient),
+	}
+
+	return node, "http://" + startTestHTTPServer(t, node)
+}
+
+func TestStop_DrainsInFlightRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	started := make(chan struct{})
+	router := gin.New()
+	router.GET("/slow", func(c *gin.Context) {
+		close(started)
+		time.Sleep(300 * time.Millisecond)
+		c.String(http.StatusOK, "finished")
+	})
+
+	node, baseURL := newShutdownTestNode(t, router, 5*time.Second)
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(baseURL + "/slow")
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		done <- result{status: resp.StatusCode, body: string(body), err: err}
+	}()
+
+	<-started
+	require.NoError(t, node.Stop(context.Background()))
+
+	// Stop only returns once the request has been answered
+	select {
+	case res := <-done:
+		require.NoError(t, res.err)
+		assert.Equal(t, http.StatusOK, res.status)
+		assert.Equal(t, "finished", res.body)
+	case <-time.After(time.Second):
+		t.Fatal("in-flight request did not complete")
+	}
+
+	// New requests are refused after Stop
+	_, err := http.Get(baseURL + "/slow")
+	assert.Error(t, err)
+}
+
+func TestStop_WaitsForBackgroundWork(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	node, _ := newShutdownTestNode(t, gin.New(), 5*time.Second)
+
+	finished := make(chan struct{})
+	node.goWorker(func() {
+		time.Sleep(200 * time.Millisecond)
+		close(finished)
+	})
+
+	// Discovery loops stop when Stop cancels them
+	discoveryCtx, stopDiscovery := context.WithCancel(context.Background())
+	node.stopDiscovery = stopDiscovery
+	node.goWorker(func() { <-discoveryCtx.Done() })
+
+	require.NoError(t, node.Stop(context.Background()))
+
+	select {
+	case <-finished:
+	default:
+		t.Fatal("Stop returned before background work finished")
+	}
+}
+
+func TestStop_DrainTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	node, _ := newShutdownTestNode(t, gin.New(), 100*time.Millisecond)
+
+	release := make(chan struct{})
+	defer close(release)
+	node.goWorker(func() { <-release })
+
+	start := time.Now()
+	require.NoError(t, node.Stop(context.Background()))
+	assert.Less(t, time.Since(start), 2*time.Second, "Stop should give up after the drain timeout")
+}
@@ -0,0 +1,118 @@
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"go.uber.org/zap"
+)
+
+// settingsSlowlogThreshold returns the slow log threshold index settings
+// set, or zero if slow logging is off
+func settingsSlowlogThreshold(settings *pb.IndexSettings) time.Duration {
+	threshold, err := time.ParseDuration(settings.GetSlowlogThreshold())
+	if err != nil || threshold < 0 {
+		return 0
+	}
+	return threshold
+}
+
+// parseSlowlogThreshold parses an index.slowlog.threshold setting value.
+// "-1" or null turn slow logging off.
+func parseSlowlogThreshold(value interface{}) (time.Duration, error) {
+	if value == nil {
+		return 0, nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return 0, fmt.Errorf("slowlog.threshold must be a duration string such as \"1s\"")
+	}
+	if s == "-1" {
+		return 0, nil
+	}
+	threshold, err := time.ParseDuration(s)
+	if err != nil || threshold < 0 {
+		return 0, fmt.Errorf("invalid slowlog.threshold [%s]: expected a duration such as \"1s\"", s)
+	}
+	return threshold, nil
+}
+
+// SlowlogThreshold returns the slow log threshold of an index, as its
+// settings on the master set it, or zero if slow logging is off
+func (qs *QueryService) SlowlogThreshold(ctx context.Context, indexName string) time.Duration {
+	metadata, err := qs.masterClient.GetIndexMetadata(ctx, indexName)
+	if err != nil {
+		return 0
+	}
+	return settingsSlowlogThreshold(metadata.GetMetadata().GetSettings())
+}
+
+// searchSlowlogThreshold returns the threshold a search of indices is slow
+// logged past: the smallest any of them sets, or zero if none does
+func searchSlowlogThreshold(indices []string, settings map[string]*pb.IndexSettings) time.Duration {
+	var threshold time.Duration
+	for _, index := range indices {
+		if t := settingsSlowlogThreshold(settings[index]); t > 0 && (threshold == 0 || t < threshold) {
+			threshold = t
+		}
+	}
+	return threshold
+}
+
+// updateSlowlogThreshold records an index's slow log threshold in its
+// settings on the master, keeping its other settings. Zero turns slow
+// logging off. It responds with the error and returns false when the
+// update fails.
+func (c *CoordinationNode) updateSlowlogThreshold(ctx *gin.Context, indexName string, threshold time.Duration) bool {
+	current, err := c.masterClient.GetIndexMetadata(ctx.Request.Context(), indexName)
+	if err != nil {
+		respondAPIError(ctx, indexNotFoundError(indexName))
+		return false
+	}
+
+	settings := cloneIndexSettings(current.GetMetadata().GetSettings())
+	settings.SlowlogThreshold = ""
+	if threshold > 0 {
+		settings.SlowlogThreshold = threshold.String()
+	}
+	if _, err := c.masterClient.UpdateIndexSettings(ctx.Request.Context(), indexName, settings); err != nil {
+		c.logger.Error("Failed to update slow log threshold",
+			zap.String("index", indexName),
+			zap.Duration("threshold", threshold),
+			zap.Error(err))
+		respondErrorFrom(ctx, err, "settings_update_exception", "Failed to update slowlog.threshold")
+		return false
+	}
+
+	c.logger.Info("Updated slow log threshold",
+		zap.String("index", indexName),
+		zap.Duration("threshold", threshold))
+	return true
+}
+
+// logSlowQuery writes a slow log entry if the search took longer than
+// threshold; zero turns slow logging off
+func logSlowQuery(logger *zap.Logger, indexName string, requestBody []byte, took, threshold time.Duration, result *SearchResult, plan string) {
+	if threshold <= 0 || took < threshold {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("index", indexName),
+		zap.ByteString("query", requestBody),
+		zap.Duration("took", took),
+		zap.Duration("threshold", threshold),
+		zap.Int64("total_hits", result.TotalHits),
+		zap.String("plan", plan),
+	}
+	if result.Shards != nil {
+		fields = append(fields,
+			zap.Int("shards_total", result.Shards.Total),
+			zap.Int("shards_successful", result.Shards.Successful),
+			zap.Int("shards_failed", result.Shards.Failed))
+	}
+	logger.Named("slowlog").Warn("Slow search", fields...)
+}
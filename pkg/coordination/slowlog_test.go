@@ -0,0 +1,179 @@
+package coordination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/coordination/executor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+)
+
+func TestExecuteSearchSlowlog(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+
+	// Every shard takes a little while to answer
+	mockExec := &mockQueryExecutor{
+		searchFunc: func(ctx context.Context, indexName string, query []byte, filterExpr []byte, from, size int) (*executor.SearchResult, error) {
+			time.Sleep(20 * time.Millisecond)
+			return &executor.SearchResult{TotalHits: 0, Hits: []*executor.SearchHit{}}, nil
+		},
+	}
+	// The master holds each index's threshold
+	thresholds := map[string]string{}
+	master := &mockPipelineMasterClient{
+		getIndexMetadataFunc: func(ctx context.Context, indexName string) (*pb.IndexMetadataResponse, error) {
+			settings := &pb.IndexSettings{NumberOfShards: 1, SlowlogThreshold: thresholds[indexName]}
+			return &pb.IndexMetadataResponse{Metadata: &pb.IndexMetadata{IndexName: indexName, Settings: settings}}, nil
+		},
+	}
+	service := NewQueryService(mockExec, master, zap.New(core))
+	query := []byte(`{"query": {"match_all": {}}}`)
+
+	slowEntries := func() []observer.LoggedEntry {
+		var entries []observer.LoggedEntry
+		for _, entry := range logs.AllUntimed() {
+			if entry.LoggerName == "slowlog" {
+				entries = append(entries, entry)
+			}
+		}
+		return entries
+	}
+
+	// No threshold: nothing is slow logged
+	_, err := service.ExecuteSearch(context.Background(), "products", query)
+	require.NoError(t, err)
+	assert.Empty(t, slowEntries())
+
+	// Below the threshold: only the normal info log
+	thresholds["products"] = "1h"
+	_, err = service.ExecuteSearch(context.Background(), "products", query)
+	require.NoError(t, err)
+	assert.Empty(t, slowEntries())
+
+	// Past the threshold: a structured warn entry
+	thresholds["products"] = "1ms"
+	_, err = service.ExecuteSearch(context.Background(), "products", query)
+	require.NoError(t, err)
+
+	entries := slowEntries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "products", fields["index"])
+	assert.Equal(t, string(query), fields["query"])
+	assert.GreaterOrEqual(t, fields["took"], 20*time.Millisecond)
+	assert.Equal(t, int64(1), fields["shards_total"])
+	assert.Equal(t, int64(1), fields["shards_successful"])
+	assert.NotEmpty(t, fields["plan"])
+
+	// Thresholds are per index
+	_, err = service.ExecuteSearch(context.Background(), "logs", query)
+	require.NoError(t, err)
+	assert.Len(t, slowEntries(), 1)
+}
+
+func TestParseSlowlogThreshold(t *testing.T) {
+	threshold, err := parseSlowlogThreshold("1s")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, threshold)
+
+	threshold, err = parseSlowlogThreshold("-1")
+	require.NoError(t, err)
+	assert.Zero(t, threshold)
+
+	threshold, err = parseSlowlogThreshold(nil)
+	require.NoError(t, err)
+	assert.Zero(t, threshold)
+
+	_, err = parseSlowlogThreshold("soon")
+	assert.Error(t, err)
+
+	_, err = parseSlowlogThreshold(5)
+	assert.Error(t, err)
+}
+
+func TestIndexSettingsHTTP_SlowlogThreshold(t *testing.T) {
+	masterLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &resizeMasterServer{indices: map[string]*pb.IndexMetadata{
+		"products": {IndexName: "products", Settings: &pb.IndexSettings{NumberOfShards: 1, NumberOfReplicas: 1, MaxResultWindow: 20000}},
+	}}
+	masterServer := grpc.NewServer()
+	pb.RegisterMasterServiceServer(masterServer, master)
+	go masterServer.Serve(masterLis)
+	defer masterServer.Stop()
+
+	// Two coordination nodes of the same cluster
+	newNode := func() *CoordinationNode {
+		node := newTestCoordinationNode(t)
+		node.masterClient = NewMasterClient(masterLis.Addr().String(), zap.NewNop())
+		require.NoError(t, node.masterClient.Connect(context.Background()))
+		t.Cleanup(func() { node.masterClient.Disconnect() })
+		node.queryService = NewQueryService(&mockPipelineQueryExecutor{}, &mockPipelineMasterClient{
+			getIndexMetadataFunc: node.masterClient.GetIndexMetadata,
+		}, zap.NewNop())
+		return node
+	}
+	node, other := newNode(), newNode()
+
+	putSettings := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/products/_settings", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		return w
+	}
+	getSlowlog := func(node *CoordinationNode) (map[string]interface{}, bool) {
+		req := httptest.NewRequest(http.MethodGet, "/products/_settings", nil)
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		indexSettings := response["products"].(map[string]interface{})["settings"].(map[string]interface{})["index"].(map[string]interface{})
+		slowlog, ok := indexSettings["slowlog"].(map[string]interface{})
+		return slowlog, ok
+	}
+
+	w := putSettings(`{"index": {"slowlog": {"threshold": "1s"}}}`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, time.Second, node.queryService.SlowlogThreshold(context.Background(), "products"))
+
+	// The threshold is kept on the master, so every node reports it, and
+	// updating it keeps the index's other settings
+	for _, n := range []*CoordinationNode{node, other} {
+		slowlog, ok := getSlowlog(n)
+		require.True(t, ok)
+		assert.Equal(t, "1s", slowlog["threshold"])
+	}
+	assert.Equal(t, time.Second, other.queryService.SlowlogThreshold(context.Background(), "products"))
+	assert.Equal(t, int32(20000), master.indices["products"].Settings.MaxResultWindow)
+	assert.Equal(t, int32(1), master.indices["products"].Settings.NumberOfReplicas)
+
+	// Invalid thresholds are rejected and leave the setting unchanged
+	w = putSettings(`{"index": {"slowlog": {"threshold": "soon"}}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "illegal_argument_exception")
+	assert.Equal(t, time.Second, node.queryService.SlowlogThreshold(context.Background(), "products"))
+
+	// "-1" turns the slow log off
+	w = putSettings(`{"index": {"slowlog": {"threshold": "-1"}}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	_, ok := getSlowlog(other)
+	assert.False(t, ok)
+	assert.Empty(t, master.indices["products"].Settings.SlowlogThreshold)
+}
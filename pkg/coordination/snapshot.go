@@ -0,0 +1,692 @@
+package coordination
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// snapshotsDir is the directory of a snapshot repository holding one
+// manifest per snapshot. Segment files are stored by the data nodes under
+// the repository's blobs directory.
+const snapshotsDir = "snapshots"
+
+// restoreTimeout bounds how long a restore waits for the primaries of an
+// index it created to start
+const restoreTimeout = 30 * time.Second
+
+// snapshotManifest describes a snapshot: the metadata of each index and the
+// segment files of each of its shards
+type snapshotManifest struct {
+	Snapshot  string                    `json:"snapshot"`
+	StartTime int64                     `json:"start_time_in_millis"`
+	EndTime   int64                     `json:"end_time_in_millis"`
+	Indices   map[string]*snapshotIndex `json:"indices"`
+}
+
+// snapshotIndex is an index in a snapshot
+type snapshotIndex struct {
+	Metadata json.RawMessage                  `json:"metadata"` // pb.IndexMetadata as protobuf JSON
+	Shards   map[int32][]snapshotManifestFile `json:"shards"`
+}
+
+// snapshotManifestFile is a segment file of a snapshotted shard
+type snapshotManifestFile struct {
+	Name      string `json:"name"`
+	Blob      string `json:"blob"`
+	SizeBytes int64  `json:"size_in_bytes"`
+}
+
+// putSnapshotRepositoryRequest is the body of a PUT /_snapshot/:repo request
+type putSnapshotRepositoryRequest struct {
+	Type     string `json:"type"`
+	Settings struct {
+		Location string `json:"location"`
+	} `json:"settings"`
+}
+
+// createSnapshotRequest is the body of a PUT /_snapshot/:repo/:snapshot
+// request
+type createSnapshotRequest struct {
+	Indices interface{} `json:"indices"`
+}
+
+// restoreSnapshotRequest is the body of a _restore request
+type restoreSnapshotRequest struct {
+	Indices           interface{} `json:"indices"`
+	RenamePattern     string      `json:"rename_pattern"`
+	RenameReplacement string      `json:"rename_replacement"`
+}
+
+// indexPatterns returns the index names or wildcard patterns of an indices
+// field, given as a comma-separated string or an array. No patterns means
+// every index.
+func indexPatterns(indices interface{}) ([]string, error) {
+	var patterns []string
+	switch v := indices.(type) {
+	case nil:
+	case string:
+		patterns = strings.Split(v, ",")
+	case []interface{}:
+		for _, item := range v {
+			name, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("indices must be strings but found [%v]", item)
+			}
+			patterns = append(patterns, name)
+		}
+	default:
+		return nil, fmt.Errorf("indices must be a string or an array")
+	}
+
+	var result []string
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "_all" {
+			return nil, nil
+		}
+		if pattern != "" {
+			result = append(result, pattern)
+		}
+	}
+	return result, nil
+}
+
+// matchIndices returns the names matching any of patterns, sorted. An exact
+// name that matches nothing is an error; no patterns match every name.
+func matchIndices(names []string, patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		matched := append([]string(nil), names...)
+		sort.Strings(matched)
+		return matched, nil
+	}
+
+	selected := make(map[string]bool)
+	for _, pattern := range patterns {
+		found := false
+		for _, name := range names {
+			if ok, _ := path.Match(pattern, name); ok {
+				selected[name] = true
+				found = true
+			}
+		}
+		if !found && !strings.ContainsAny(pattern, "*?[") {
+			return nil, fmt.Errorf("no such index [%s]", pattern)
+		}
+	}
+
+	matched := make([]string, 0, len(selected))
+	for name := range selected {
+		matched = append(matched, name)
+	}
+	sort.Strings(matched)
+	return matched, nil
+}
+
+// validSnapshotName reports whether a snapshot name can name its manifest
+func validSnapshotName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && !strings.HasPrefix(name, "_") &&
+		!strings.ContainsAny(name, `/\ ,"*?<>|#`) && name == strings.ToLower(name)
+}
+
+// snapshotManifestPath returns where a snapshot's manifest is stored
+func snapshotManifestPath(repository *pb.SnapshotRepository, snapshot string) string {
+	return filepath.Join(repository.Location, snapshotsDir, snapshot+".json")
+}
+
+// readSnapshotManifest reads a snapshot's manifest from its repository
+func readSnapshotManifest(repository *pb.SnapshotRepository, snapshot string) (*snapshotManifest, error) {
+	data, err := os.ReadFile(snapshotManifestPath(repository, snapshot))
+	if err != nil {
+		return nil, err
+	}
+	var manifest snapshotManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("corrupt snapshot manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// writeSnapshotManifest writes a snapshot's manifest to its repository,
+// through a temporary file so a partial manifest is never visible
+func writeSnapshotManifest(repository *pb.SnapshotRepository, manifest *snapshotManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	manifestPath := snapshotManifestPath(repository, manifest.Snapshot)
+	if err := os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(manifestPath+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(manifestPath+".tmp", manifestPath)
+}
+
+// snapshotRepository looks up a registered repository, writing a 404 and
+// returning nil if it is missing
+func (c *CoordinationNode) snapshotRepository(ctx *gin.Context, name string) *pb.SnapshotRepository {
+	repositories, err := c.masterClient.GetSnapshotRepositories(ctx.Request.Context(), name)
+	if err != nil || len(repositories) == 0 {
+		if err != nil && status.Code(err) != codes.NotFound {
+			c.logger.Error("Failed to get snapshot repository", zap.String("repository", name), zap.Error(err))
+		}
+		respondError(ctx, http.StatusNotFound, "repository_missing_exception", fmt.Sprintf("[%s] missing", name))
+		return nil
+	}
+	return repositories[0]
+}
+
+// handlePutSnapshotRepository registers a filesystem snapshot repository
+func (c *CoordinationNode) handlePutSnapshotRepository(ctx *gin.Context) {
+	name := ctx.Param("repository")
+
+	badRequest := func(reason string) {
+		respondError(ctx, http.StatusBadRequest, "repository_exception", fmt.Sprintf("[%s] %s", name, reason))
+	}
+
+	var req putSnapshotRepositoryRequest
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+		badRequest(fmt.Sprintf("failed to parse repository: %v", err))
+		return
+	}
+	if req.Type != "fs" {
+		badRequest(fmt.Sprintf("repository type [%s] does not exist, only fs is supported", req.Type))
+		return
+	}
+	if req.Settings.Location == "" {
+		badRequest("missing location")
+		return
+	}
+
+	// The location must lie under path.repo; the nodes writing to it create
+	// it when a snapshot is taken
+	location, ok := repositoryLocation(req.Settings.Location, c.cfg.PathRepo)
+	if !ok {
+		badRequest(fmt.Sprintf("location [%s] doesn't match any of the locations specified by path.repo", req.Settings.Location))
+		return
+	}
+
+	if _, err := c.masterClient.PutSnapshotRepository(ctx.Request.Context(), &pb.SnapshotRepository{
+		Name:     name,
+		Type:     req.Type,
+		Location: location,
+	}); err != nil {
+		c.logger.Error("Failed to register snapshot repository", zap.String("repository", name), zap.Error(err))
+		respondError(ctx, http.StatusInternalServerError, "repository_exception", fmt.Sprintf("Failed to register repository: %v", err))
+		return
+	}
+
+	c.logger.Info("Registered snapshot repository",
+		zap.String("repository", name),
+		zap.String("location", location))
+
+	ctx.JSON(http.StatusOK, gin.H{"acknowledged": true})
+}
+
+// repositoryLocation resolves an fs repository location, relative to the
+// first path.repo directory if it isn't absolute, and reports whether it lies
+// under one of the path.repo directories
+func repositoryLocation(location string, pathRepo []string) (string, bool) {
+	if len(pathRepo) == 0 {
+		return "", false
+	}
+	if !filepath.IsAbs(location) {
+		location = filepath.Join(pathRepo[0], location)
+	}
+	location, err := filepath.Abs(location)
+	if err != nil {
+		return "", false
+	}
+	for _, dir := range pathRepo {
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(dir, location)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return location, true
+		}
+	}
+	return "", false
+}
+
+// handleGetSnapshotRepository returns a registered snapshot repository
+func (c *CoordinationNode) handleGetSnapshotRepository(ctx *gin.Context) {
+	repository := c.snapshotRepository(ctx, ctx.Param("repository"))
+	if repository == nil {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		repository.Name: gin.H{
+			"type": repository.Type,
+			"settings": gin.H{
+				"location": repository.Location,
+			},
+		},
+	})
+}
+
+// handleCreateSnapshot snapshots indices into a repository. Each primary
+// shard's data node copies the shard's committed segment files, skipping
+// those already in the repository from earlier snapshots, and the index
+// metadata and file lists are recorded in the snapshot's manifest.
+func (c *CoordinationNode) handleCreateSnapshot(ctx *gin.Context) {
+	snapshot := ctx.Param("snapshot")
+	startTime := time.Now()
+
+	badRequest := func(errorType, reason string) {
+		respondError(ctx, http.StatusBadRequest, errorType, reason)
+	}
+
+	repository := c.snapshotRepository(ctx, ctx.Param("repository"))
+	if repository == nil {
+		return
+	}
+	if !validSnapshotName(snapshot) {
+		badRequest("invalid_snapshot_name_exception", fmt.Sprintf("[%s:%s] Invalid snapshot name", repository.Name, snapshot))
+		return
+	}
+	if _, err := os.Stat(snapshotManifestPath(repository, snapshot)); err == nil {
+		badRequest("invalid_snapshot_name_exception", fmt.Sprintf("[%s:%s] snapshot with the same name already exists", repository.Name, snapshot))
+		return
+	}
+
+	var req createSnapshotRequest
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		badRequest("parse_exception", fmt.Sprintf("Failed to read request body: %v", err))
+		return
+	}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			badRequest("parse_exception", fmt.Sprintf("Failed to parse snapshot request: %v", err))
+			return
+		}
+	}
+	patterns, err := indexPatterns(req.Indices)
+	if err != nil {
+		badRequest("illegal_argument_exception", err.Error())
+		return
+	}
+
+	state, err := c.masterClient.GetClusterState(ctx.Request.Context(), false, false, true)
+	if err != nil {
+		c.logger.Error("Failed to get cluster state", zap.Error(err))
+		respondError(ctx, http.StatusInternalServerError, "snapshot_exception", fmt.Sprintf("Failed to get cluster state: %v", err))
+		return
+	}
+	names := make([]string, 0, len(state.Indices))
+	for _, index := range state.Indices {
+		names = append(names, index.IndexName)
+	}
+	indices, err := matchIndices(names, patterns)
+	if err != nil {
+		respondError(ctx, http.StatusNotFound, "index_not_found_exception", err.Error())
+		return
+	}
+
+	manifest, stats, err := c.snapshotIndices(ctx.Request.Context(), repository, snapshot, indices)
+	if err == nil {
+		manifest.StartTime = startTime.UnixMilli()
+		manifest.EndTime = time.Now().UnixMilli()
+		err = writeSnapshotManifest(repository, manifest)
+	}
+	if err != nil {
+		c.logger.Error("Snapshot failed",
+			zap.String("repository", repository.Name),
+			zap.String("snapshot", snapshot),
+			zap.Error(err))
+		respondError(ctx, http.StatusInternalServerError, "snapshot_exception", fmt.Sprintf("[%s:%s] %v", repository.Name, snapshot, err))
+		return
+	}
+
+	c.logger.Info("Created snapshot",
+		zap.String("repository", repository.Name),
+		zap.String("snapshot", snapshot),
+		zap.Strings("indices", indices),
+		zap.Int("files", stats.totalFiles),
+		zap.Int("new_files", stats.newFiles))
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"snapshot": gin.H{
+			"snapshot":             snapshot,
+			"repository":           repository.Name,
+			"indices":              indices,
+			"state":                "SUCCESS",
+			"start_time_in_millis": manifest.StartTime,
+			"end_time_in_millis":   manifest.EndTime,
+			"duration_in_millis":   manifest.EndTime - manifest.StartTime,
+			"shards": gin.H{
+				"total":      stats.shards,
+				"failed":     0,
+				"successful": stats.shards,
+			},
+			"stats": gin.H{
+				"incremental": gin.H{
+					"file_count":    stats.newFiles,
+					"size_in_bytes": stats.newBytes,
+				},
+				"total": gin.H{
+					"file_count":    stats.totalFiles,
+					"size_in_bytes": stats.totalBytes,
+				},
+			},
+		},
+	})
+}
+
+// snapshotStats counts the shards and files of a snapshot and the files it
+// added to the repository
+type snapshotStats struct {
+	shards     int
+	totalFiles int
+	totalBytes int64
+	newFiles   int
+	newBytes   int64
+}
+
+// snapshotIndices snapshots the primary shards of indices in parallel and
+// returns the snapshot's manifest. Any shard failing fails the snapshot.
+func (c *CoordinationNode) snapshotIndices(ctx context.Context, repository *pb.SnapshotRepository, snapshot string, indices []string) (*snapshotManifest, *snapshotStats, error) {
+	manifest := &snapshotManifest{Snapshot: snapshot, Indices: make(map[string]*snapshotIndex)}
+	stats := &snapshotStats{}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var errs []error
+
+	for _, indexName := range indices {
+		metadata, err := c.masterClient.GetIndexMetadata(ctx, indexName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get metadata of index [%s]: %w", indexName, err)
+		}
+		encoded, err := protojson.Marshal(metadata.GetMetadata())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode metadata of index [%s]: %w", indexName, err)
+		}
+		routing, err := c.masterClient.GetShardRouting(ctx, indexName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get shard routing of index [%s]: %w", indexName, err)
+		}
+
+		index := &snapshotIndex{Metadata: encoded, Shards: make(map[int32][]snapshotManifestFile)}
+		manifest.Indices[indexName] = index
+
+		numShards := metadata.GetMetadata().GetSettings().GetNumberOfShards()
+		for shardID := int32(0); shardID < numShards; shardID++ {
+			stats.shards++
+			primary := routing[shardID].GetAllocation()
+			if !shardCopyActive(primary) {
+				errs = append(errs, fmt.Errorf("primary shard [%s][%d] is not active", indexName, shardID))
+				continue
+			}
+
+			wg.Add(1)
+			go func(indexName string, shardID int32, nodeID string) {
+				defer wg.Done()
+
+				files, err := c.snapshotShard(ctx, repository, indexName, shardID, nodeID)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					errs = append(errs, err)
+					return
+				}
+				for _, file := range files {
+					manifest.Indices[indexName].Shards[shardID] = append(manifest.Indices[indexName].Shards[shardID], snapshotManifestFile{
+						Name:      file.Name,
+						Blob:      file.Blob,
+						SizeBytes: file.SizeBytes,
+					})
+					stats.totalFiles++
+					stats.totalBytes += file.SizeBytes
+					if !file.Reused {
+						stats.newFiles++
+						stats.newBytes += file.SizeBytes
+					}
+				}
+			}(indexName, shardID, primary.GetNodeId())
+		}
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+	return manifest, stats, nil
+}
+
+// snapshotShard has a shard's data node copy its files into the repository
+func (c *CoordinationNode) snapshotShard(ctx context.Context, repository *pb.SnapshotRepository, indexName string, shardID int32, nodeID string) ([]*pb.SnapshotFile, error) {
+	c.dataClientsMu.RLock()
+	client, exists := c.dataClients[nodeID]
+	c.dataClientsMu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("data node %s of shard [%s][%d] not found", nodeID, indexName, shardID)
+	}
+
+	resp, err := client.SnapshotShard(ctx, indexName, shardID, repository.Location)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Files, nil
+}
+
+// handleGetSnapshot describes a snapshot in a repository
+func (c *CoordinationNode) handleGetSnapshot(ctx *gin.Context) {
+	snapshot := ctx.Param("snapshot")
+
+	repository := c.snapshotRepository(ctx, ctx.Param("repository"))
+	if repository == nil {
+		return
+	}
+
+	manifest, err := readSnapshotManifest(repository, snapshot)
+	if err != nil {
+		respondError(ctx, http.StatusNotFound, "snapshot_missing_exception", fmt.Sprintf("[%s:%s] is missing", repository.Name, snapshot))
+		return
+	}
+
+	indices := make([]string, 0, len(manifest.Indices))
+	shards := 0
+	for name, index := range manifest.Indices {
+		indices = append(indices, name)
+		shards += len(index.Shards)
+	}
+	sort.Strings(indices)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"snapshots": []gin.H{{
+			"snapshot":             manifest.Snapshot,
+			"indices":              indices,
+			"state":                "SUCCESS",
+			"start_time_in_millis": manifest.StartTime,
+			"end_time_in_millis":   manifest.EndTime,
+			"duration_in_millis":   manifest.EndTime - manifest.StartTime,
+			"shards": gin.H{
+				"total":      shards,
+				"failed":     0,
+				"successful": shards,
+			},
+		}},
+	})
+}
+
+// handleRestoreSnapshot recreates indices from a snapshot. Each index is
+// created with the snapshotted settings and mappings, its primaries are
+// restored from the snapshot's files, and its replicas then recover from
+// the primaries.
+func (c *CoordinationNode) handleRestoreSnapshot(ctx *gin.Context) {
+	snapshot := ctx.Param("snapshot")
+
+	badRequest := func(errorType, reason string) {
+		respondError(ctx, http.StatusBadRequest, errorType, reason)
+	}
+
+	repository := c.snapshotRepository(ctx, ctx.Param("repository"))
+	if repository == nil {
+		return
+	}
+	manifest, err := readSnapshotManifest(repository, snapshot)
+	if err != nil {
+		respondError(ctx, http.StatusNotFound, "snapshot_missing_exception", fmt.Sprintf("[%s:%s] is missing", repository.Name, snapshot))
+		return
+	}
+
+	var req restoreSnapshotRequest
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		badRequest("parse_exception", fmt.Sprintf("Failed to read request body: %v", err))
+		return
+	}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			badRequest("parse_exception", fmt.Sprintf("Failed to parse restore request: %v", err))
+			return
+		}
+	}
+	patterns, err := indexPatterns(req.Indices)
+	if err != nil {
+		badRequest("illegal_argument_exception", err.Error())
+		return
+	}
+	var rename *regexp.Regexp
+	if req.RenamePattern != "" {
+		if rename, err = regexp.Compile(req.RenamePattern); err != nil {
+			badRequest("illegal_argument_exception", fmt.Sprintf("invalid rename_pattern [%s]: %v", req.RenamePattern, err))
+			return
+		}
+	}
+
+	names := make([]string, 0, len(manifest.Indices))
+	for name := range manifest.Indices {
+		names = append(names, name)
+	}
+	indices, err := matchIndices(names, patterns)
+	if err != nil {
+		badRequest("snapshot_restore_exception", fmt.Sprintf("[%s:%s] %v in snapshot", repository.Name, snapshot, err))
+		return
+	}
+
+	// Every target must be free before anything is restored
+	targets := make(map[string]string, len(indices))
+	for _, indexName := range indices {
+		target := indexName
+		if rename != nil {
+			target = rename.ReplaceAllString(indexName, req.RenameReplacement)
+		}
+		if _, err := c.masterClient.GetIndexMetadata(ctx.Request.Context(), target); err == nil {
+			badRequest("snapshot_restore_exception", fmt.Sprintf(
+				"[%s:%s] cannot restore index [%s] because an index with the same name already exists in the cluster",
+				repository.Name, snapshot, target))
+			return
+		}
+		targets[indexName] = target
+	}
+
+	restored := make([]string, 0, len(indices))
+	shards := 0
+	for _, indexName := range indices {
+		n, err := c.restoreIndex(ctx.Request.Context(), repository, manifest.Indices[indexName], targets[indexName])
+		if err != nil {
+			c.logger.Error("Restore failed",
+				zap.String("repository", repository.Name),
+				zap.String("snapshot", snapshot),
+				zap.String("index", indexName),
+				zap.Error(err))
+			respondError(ctx, http.StatusInternalServerError, "snapshot_restore_exception", fmt.Sprintf("[%s:%s] failed to restore index [%s]: %v", repository.Name, snapshot, indexName, err))
+			return
+		}
+		restored = append(restored, targets[indexName])
+		shards += n
+	}
+
+	c.logger.Info("Restored snapshot",
+		zap.String("repository", repository.Name),
+		zap.String("snapshot", snapshot),
+		zap.Strings("indices", restored))
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"snapshot": gin.H{
+			"snapshot": snapshot,
+			"indices":  restored,
+			"shards": gin.H{
+				"total":      shards,
+				"failed":     0,
+				"successful": shards,
+			},
+		},
+	})
+}
+
+// restoreIndex creates target from a snapshotted index and restores its
+// primary shards, returning the number of shards restored
+func (c *CoordinationNode) restoreIndex(ctx context.Context, repository *pb.SnapshotRepository, index *snapshotIndex, target string) (int, error) {
+	var metadata pb.IndexMetadata
+	if err := protojson.Unmarshal(index.Metadata, &metadata); err != nil {
+		return 0, fmt.Errorf("corrupt index metadata: %w", err)
+	}
+
+	// Replicas are added once the primaries hold the snapshot's documents,
+	// so they recover from the restored primaries
+	settings := cloneIndexSettings(metadata.GetSettings())
+	settings.NumberOfReplicas = 0
+	if _, err := c.masterClient.CreateIndex(ctx, target, settings, metadata.GetMappings()); err != nil {
+		return 0, fmt.Errorf("failed to create index: %w", err)
+	}
+	if err := c.waitForPrimaries(ctx, target, settings.NumberOfShards, restoreTimeout); err != nil {
+		return 0, fmt.Errorf("primaries did not start: %w", err)
+	}
+
+	routing, err := c.masterClient.GetShardRouting(ctx, target)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get shard routing: %w", err)
+	}
+	for shardID := int32(0); shardID < settings.NumberOfShards; shardID++ {
+		nodeID := routing[shardID].GetAllocation().GetNodeId()
+		c.dataClientsMu.RLock()
+		client, exists := c.dataClients[nodeID]
+		c.dataClientsMu.RUnlock()
+		if !exists {
+			return 0, fmt.Errorf("data node %s of shard %d not found", nodeID, shardID)
+		}
+
+		files := make([]*pb.SnapshotFile, 0, len(index.Shards[shardID]))
+		for _, file := range index.Shards[shardID] {
+			files = append(files, &pb.SnapshotFile{Name: file.Name, Blob: file.Blob, SizeBytes: file.SizeBytes})
+		}
+		if _, err := client.RestoreShard(ctx, target, shardID, repository.Location, files); err != nil {
+			return 0, err
+		}
+	}
+
+	if replicas := metadata.GetSettings().GetNumberOfReplicas(); replicas > 0 {
+		settings.NumberOfReplicas = replicas
+		if _, err := c.masterClient.UpdateIndexSettings(ctx, target, settings); err != nil {
+			return 0, fmt.Errorf("failed to add replicas: %w", err)
+		}
+	}
+
+	return int(settings.NumberOfShards), nil
+}
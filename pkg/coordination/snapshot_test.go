@@ -0,0 +1,307 @@
+package coordination
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// snapshotMasterServer is a master with snapshot repositories whose index
+// settings can be updated
+type snapshotMasterServer struct {
+	resizeMasterServer
+
+	repositoriesMu sync.Mutex
+	repositories   map[string]*pb.SnapshotRepository
+}
+
+func (m *snapshotMasterServer) PutSnapshotRepository(ctx context.Context, req *pb.PutSnapshotRepositoryRequest) (*pb.PutSnapshotRepositoryResponse, error) {
+	m.repositoriesMu.Lock()
+	defer m.repositoriesMu.Unlock()
+	m.repositories[req.Repository.Name] = req.Repository
+	return &pb.PutSnapshotRepositoryResponse{Acknowledged: true}, nil
+}
+
+func (m *snapshotMasterServer) GetSnapshotRepositories(ctx context.Context, req *pb.GetSnapshotRepositoriesRequest) (*pb.GetSnapshotRepositoriesResponse, error) {
+	m.repositoriesMu.Lock()
+	defer m.repositoriesMu.Unlock()
+	repository, exists := m.repositories[req.Name]
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "snapshot repository not found: %s", req.Name)
+	}
+	return &pb.GetSnapshotRepositoriesResponse{Repositories: []*pb.SnapshotRepository{repository}}, nil
+}
+
+func (m *snapshotMasterServer) UpdateIndexSettings(ctx context.Context, req *pb.UpdateIndexSettingsRequest) (*pb.UpdateIndexSettingsResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.indices[req.IndexName].Settings = req.Settings
+	return &pb.UpdateIndexSettingsResponse{Acknowledged: true}, nil
+}
+
+// fileDataServer is a data node whose shards are sets of in-memory segment
+// files, snapshotted into blobs named by their content hash
+type fileDataServer struct {
+	pb.UnimplementedDataServiceServer
+
+	mu     sync.Mutex
+	shards map[string]map[int32]map[string][]byte // index -> shard -> file -> contents
+}
+
+func (s *fileDataServer) write(index string, shardID int32, name, contents string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shards[index] == nil {
+		s.shards[index] = make(map[int32]map[string][]byte)
+	}
+	if s.shards[index][shardID] == nil {
+		s.shards[index][shardID] = make(map[string][]byte)
+	}
+	s.shards[index][shardID][name] = []byte(contents)
+}
+
+func (s *fileDataServer) files(index string, shardID int32) map[string][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.shards[index][shardID]
+}
+
+func (s *fileDataServer) SnapshotShard(ctx context.Context, req *pb.SnapshotShardRequest) (*pb.SnapshotShardResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	shard, exists := s.shards[req.IndexName][req.ShardId]
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "shard not found")
+	}
+
+	blobsDir := filepath.Join(req.RepositoryLocation, "blobs")
+	if err := os.MkdirAll(blobsDir, 0755); err != nil {
+		return nil, err
+	}
+	resp := &pb.SnapshotShardResponse{}
+	for name, contents := range shard {
+		sum := sha256.Sum256(contents)
+		file := &pb.SnapshotFile{Name: name, Blob: hex.EncodeToString(sum[:]), SizeBytes: int64(len(contents))}
+		blobPath := filepath.Join(blobsDir, file.Blob)
+		if _, err := os.Stat(blobPath); err == nil {
+			file.Reused = true
+		} else if err := os.WriteFile(blobPath, contents, 0644); err != nil {
+			return nil, err
+		}
+		resp.Files = append(resp.Files, file)
+	}
+	return resp, nil
+}
+
+func (s *fileDataServer) RestoreShard(ctx context.Context, req *pb.RestoreShardRequest) (*pb.RestoreShardResponse, error) {
+	shard := make(map[string][]byte)
+	for _, file := range req.Files {
+		contents, err := os.ReadFile(filepath.Join(req.RepositoryLocation, "blobs", file.Blob))
+		if err != nil {
+			return nil, err
+		}
+		shard[file.Name] = contents
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shards[req.IndexName] == nil {
+		s.shards[req.IndexName] = make(map[int32]map[string][]byte)
+	}
+	s.shards[req.IndexName][req.ShardId] = shard
+	return &pb.RestoreShardResponse{Acknowledged: true}, nil
+}
+
+func TestIndexPatterns(t *testing.T) {
+	patterns, err := indexPatterns("logs-*, products")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"logs-*", "products"}, patterns)
+
+	patterns, err = indexPatterns([]interface{}{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, patterns)
+
+	patterns, err = indexPatterns("_all")
+	require.NoError(t, err)
+	assert.Empty(t, patterns)
+
+	_, err = indexPatterns(float64(1))
+	assert.Error(t, err)
+
+	names := []string{"products", "logs-2", "logs-1"}
+	matched, err := matchIndices(names, []string{"logs-*"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"logs-1", "logs-2"}, matched)
+
+	matched, err = matchIndices(names, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"logs-1", "logs-2", "products"}, matched)
+
+	matched, err = matchIndices(names, []string{"metrics-*"})
+	require.NoError(t, err)
+	assert.Empty(t, matched)
+
+	_, err = matchIndices(names, []string{"missing"})
+	assert.Error(t, err)
+}
+
+func TestSnapshotAndRestore(t *testing.T) {
+	// A master holding a 2-shard index with a replica, and a data node
+	// holding its primaries
+	masterLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	master := &snapshotMasterServer{
+		resizeMasterServer: resizeMasterServer{indices: map[string]*pb.IndexMetadata{
+			"logs": {
+				IndexName: "logs",
+				Settings:  &pb.IndexSettings{NumberOfShards: 2, NumberOfReplicas: 1, StoreType: "niofs", MaxResultWindow: 500},
+				Mappings:  map[string]*pb.FieldMapping{"message": {Type: "text"}},
+			},
+			"products": {IndexName: "products", Settings: &pb.IndexSettings{NumberOfShards: 1}},
+		}},
+		repositories: map[string]*pb.SnapshotRepository{},
+	}
+	masterServer := grpc.NewServer()
+	pb.RegisterMasterServiceServer(masterServer, master)
+	go masterServer.Serve(masterLis)
+	defer masterServer.Stop()
+
+	dataLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	data := &fileDataServer{shards: map[string]map[int32]map[string][]byte{}}
+	dataServer := grpc.NewServer()
+	pb.RegisterDataServiceServer(dataServer, data)
+	go dataServer.Serve(dataLis)
+	defer dataServer.Stop()
+
+	node := newTestCoordinationNode(t)
+	node.masterClient = NewMasterClient(masterLis.Addr().String(), zap.NewNop())
+	require.NoError(t, node.masterClient.Connect(context.Background()))
+	defer node.masterClient.Disconnect()
+
+	dataClient := NewDataNodeClient("node1", dataLis.Addr().String(), zap.NewNop())
+	require.NoError(t, dataClient.Connect(context.Background()))
+	defer dataClient.Disconnect()
+	node.dataClients["node1"] = dataClient
+
+	serve := func(method, path, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp
+	}
+
+	data.write("logs", 0, "segments_1", "commit 1")
+	data.write("logs", 0, "_0.seg", "docs 0-9")
+	data.write("logs", 1, "segments_1", "commit 1 of shard 1")
+	data.write("products", 0, "segments_1", "products")
+
+	pathRepo := t.TempDir()
+	node.cfg.PathRepo = []string{pathRepo}
+	location := filepath.Join(pathRepo, "backups")
+
+	// Snapshots need a registered repository
+	w, _ := serve(http.MethodPut, "/_snapshot/backups/snap-1", `{"indices": "logs"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+	w, _ = postJSON(node.ginRouter, "/_snapshot/backups", `{"type": "s3", "settings": {"bucket": "b"}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	// Locations outside path.repo are rejected without being created
+	outside := filepath.Join(t.TempDir(), "elsewhere")
+	w, _ = postJSON(node.ginRouter, "/_snapshot/backups", `{"type": "fs", "settings": {"location": "`+outside+`"}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	w, _ = postJSON(node.ginRouter, "/_snapshot/backups", `{"type": "fs", "settings": {"location": "`+pathRepo+`/../escape"}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	assert.NoDirExists(t, outside)
+
+	// Registering doesn't create the location; relative ones resolve under path.repo
+	w, _ = postJSON(node.ginRouter, "/_snapshot/backups", `{"type": "fs", "settings": {"location": "backups"}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NoDirExists(t, location)
+	w, _ = postJSON(node.ginRouter, "/_snapshot/backups", `{"type": "fs", "settings": {"location": "`+location+`"}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w, resp := serve(http.MethodGet, "/_snapshot/backups", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, location, resp["backups"].(map[string]interface{})["settings"].(map[string]interface{})["location"])
+
+	// The first snapshot copies every file
+	w, resp = serve(http.MethodPut, "/_snapshot/backups/snap-1", `{"indices": "logs"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	snapshot := resp["snapshot"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"logs"}, snapshot["indices"])
+	assert.Equal(t, "SUCCESS", snapshot["state"])
+	assert.Equal(t, float64(2), snapshot["shards"].(map[string]interface{})["successful"])
+	stats := snapshot["stats"].(map[string]interface{})
+	assert.Equal(t, float64(3), stats["incremental"].(map[string]interface{})["file_count"])
+	assert.Equal(t, float64(3), stats["total"].(map[string]interface{})["file_count"])
+
+	w, _ = serve(http.MethodPut, "/_snapshot/backups/snap-1", `{"indices": "logs"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	// The second snapshot only copies files that changed since the first
+	data.write("logs", 0, "segments_2", "commit 2")
+	data.write("logs", 0, "_1.seg", "docs 10-19")
+	w, resp = serve(http.MethodPut, "/_snapshot/backups/snap-2", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	snapshot = resp["snapshot"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"logs", "products"}, snapshot["indices"])
+	stats = snapshot["stats"].(map[string]interface{})
+	assert.Equal(t, float64(3), stats["incremental"].(map[string]interface{})["file_count"])
+	assert.Equal(t, float64(6), stats["total"].(map[string]interface{})["file_count"])
+
+	w, resp = serve(http.MethodGet, "/_snapshot/backups/snap-1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Len(t, resp["snapshots"], 1)
+	w, _ = serve(http.MethodGet, "/_snapshot/backups/missing", "")
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+
+	// Restoring over an existing index is refused
+	w, _ = postJSON(node.ginRouter, "/_snapshot/backups/snap-1/_restore", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	// Restoring under a new name recreates the index as it was in the
+	// first snapshot
+	data.write("logs", 0, "segments_3", "commit 3")
+	w, resp = postJSON(node.ginRouter, "/_snapshot/backups/snap-1/_restore",
+		`{"indices": "logs", "rename_pattern": "(.+)", "rename_replacement": "restored-$1"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []interface{}{"restored-logs"}, resp["snapshot"].(map[string]interface{})["indices"])
+
+	master.mu.Lock()
+	restored := master.indices["restored-logs"]
+	master.mu.Unlock()
+	require.NotNil(t, restored)
+	assert.Equal(t, int32(2), restored.Settings.NumberOfShards)
+	assert.Equal(t, int32(1), restored.Settings.NumberOfReplicas)
+	assert.Equal(t, "niofs", restored.Settings.StoreType)
+	assert.Equal(t, int32(500), restored.Settings.MaxResultWindow)
+	assert.Equal(t, "text", restored.Mappings["message"].Type)
+
+	assert.Equal(t, map[string][]byte{
+		"segments_1": []byte("commit 1"),
+		"_0.seg":     []byte("docs 0-9"),
+	}, data.files("restored-logs", 0))
+	assert.Equal(t, map[string][]byte{
+		"segments_1": []byte("commit 1 of shard 1"),
+	}, data.files("restored-logs", 1))
+}
@@ -0,0 +1,80 @@
+package coordination
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHandleSearch_Suggest(t *testing.T) {
+	node := newTestCoordinationNode(t)
+	index := &memoryIndex{docs: map[string]map[string]interface{}{
+		"1": {"title": "The quick brown fox", "suggest": map[string]interface{}{"input": []interface{}{"Nirvana", "Nevermind"}, "weight": float64(34)}},
+		"2": {"title": "A quick brown dog", "suggest": "Nine Inch Nails"},
+		"3": {"title": "Slow and steady", "suggest": "Pearl Jam"},
+	}}
+	node.queryService = NewQueryService(index, &mockPipelineMasterClient{}, zap.NewNop())
+
+	t.Run("misspelled term", func(t *testing.T) {
+		w, response := postJSON(node.ginRouter, "/music/_search", `{
+			"size": 0,
+			"suggest": {
+				"text": "quikc fox",
+				"spelling": {"term": {"field": "title"}}
+			}
+		}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		entries := response["suggest"].(map[string]interface{})["spelling"].([]interface{})
+		require.Len(t, entries, 2)
+
+		entry := entries[0].(map[string]interface{})
+		assert.Equal(t, "quikc", entry["text"])
+		assert.Equal(t, float64(0), entry["offset"])
+		assert.Equal(t, float64(5), entry["length"])
+		options := entry["options"].([]interface{})
+		require.NotEmpty(t, options)
+		best := options[0].(map[string]interface{})
+		assert.Equal(t, "quick", best["text"])
+		assert.Equal(t, float64(2), best["freq"])
+		assert.InDelta(t, 0.6, best["score"], 1e-9)
+
+		// Terms in the index get no corrections
+		assert.Empty(t, entries[1].(map[string]interface{})["options"])
+	})
+
+	t.Run("prefix completion", func(t *testing.T) {
+		w, response := postJSON(node.ginRouter, "/music/_search", `{
+			"size": 0,
+			"suggest": {"bands": {"prefix": "ni", "completion": {"field": "suggest"}}}
+		}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		entries := response["suggest"].(map[string]interface{})["bands"].([]interface{})
+		require.Len(t, entries, 1)
+		options := entries[0].(map[string]interface{})["options"].([]interface{})
+		require.Len(t, options, 2)
+
+		first := options[0].(map[string]interface{})
+		assert.Equal(t, "Nirvana", first["text"])
+		assert.Equal(t, "1", first["_id"])
+		assert.Equal(t, float64(34), first["_score"])
+		assert.NotNil(t, first["_source"])
+		assert.Equal(t, "Nine Inch Nails", options[1].(map[string]interface{})["text"])
+	})
+
+	t.Run("no suggest section without a suggest block", func(t *testing.T) {
+		w, response := postJSON(node.ginRouter, "/music/_search", `{"query": {"match_all": {}}}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.NotContains(t, response, "suggest")
+	})
+
+	t.Run("invalid suggester", func(t *testing.T) {
+		w, _ := postJSON(node.ginRouter, "/music/_search", `{"suggest": {"s": {"text": "x", "phrase": {"field": "title"}}}}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "must have one of [term, completion]")
+	})
+}
@@ -0,0 +1,340 @@
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxCompletedTasks is the number of finished background tasks whose results
+// are kept for GET /_tasks/:id; older results are dropped first
+const maxCompletedTasks = 1000
+
+// taskFunc runs a long operation under a task. It reports progress through
+// the task and returns the HTTP status and body of the operation's response.
+// It stops early when ctx is cancelled.
+type taskFunc func(ctx context.Context, t *task) (int, gin.H)
+
+// task is a long-running operation tracked by the task manager
+type task struct {
+	id          string
+	node        string
+	number      int64
+	action      string
+	description string
+	startTime   time.Time
+	cancel      context.CancelFunc
+
+	mu        sync.Mutex
+	status    gin.H // Progress reported by the operation
+	cancelled bool
+	completed bool
+	code      int   // HTTP status of the operation's response
+	response  gin.H // The operation's response, once completed
+}
+
+// setStatus records the progress of the task's operation
+func (t *task) setStatus(status gin.H) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status = status
+}
+
+// info renders the task as listed by the tasks API
+func (t *task) info() gin.H {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	info := gin.H{
+		"node":                  t.node,
+		"id":                    t.number,
+		"type":                  "transport",
+		"action":                t.action,
+		"description":           t.description,
+		"start_time_in_millis":  t.startTime.UnixMilli(),
+		"running_time_in_nanos": time.Since(t.startTime).Nanoseconds(),
+		"cancellable":           true,
+		"cancelled":             t.cancelled,
+	}
+	if t.status != nil {
+		info["status"] = t.status
+	}
+	return info
+}
+
+// taskManager assigns ids to long-running operations and tracks them, so
+// they can be listed, polled and cancelled. Operations started without
+// waiting for completion keep their result after finishing until it is
+// read back or evicted.
+type taskManager struct {
+	nodeID string
+
+	mu        sync.Mutex
+	nextID    int64
+	tasks     map[string]*task
+	completed []string // Ids of finished background tasks, oldest first
+}
+
+// newTaskManager creates a task manager for the node with the given id
+func newTaskManager(nodeID string) *taskManager {
+	return &taskManager{
+		nodeID: nodeID,
+		tasks:  make(map[string]*task),
+	}
+}
+
+// register creates a task for an operation running under ctx. The task's
+// context is cancelled by cancelTask or when ctx is.
+func (m *taskManager) register(ctx context.Context, action, description string) (*task, context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	taskCtx, cancel := context.WithCancel(ctx)
+	t := &task{
+		id:          fmt.Sprintf("%s:%d", m.nodeID, m.nextID),
+		node:        m.nodeID,
+		number:      m.nextID,
+		action:      action,
+		description: description,
+		startTime:   time.Now(),
+		cancel:      cancel,
+	}
+	m.tasks[t.id] = t
+	return t, taskCtx
+}
+
+// run runs op under the task and records its response. A task whose
+// result is kept stays listed by get once it finishes; otherwise it is
+// forgotten.
+func (m *taskManager) run(ctx context.Context, t *task, op taskFunc, keepResult bool) (int, gin.H) {
+	code, response := op(ctx, t)
+	t.cancel()
+
+	t.mu.Lock()
+	t.completed = true
+	t.code = code
+	t.response = response
+	t.mu.Unlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !keepResult {
+		delete(m.tasks, t.id)
+		return code, response
+	}
+	m.completed = append(m.completed, t.id)
+	if len(m.completed) > maxCompletedTasks {
+		delete(m.tasks, m.completed[0])
+		m.completed = m.completed[1:]
+	}
+	return code, response
+}
+
+// get returns a running or finished task
+func (m *taskManager) get(id string) (*task, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tasks[id]
+	return t, ok
+}
+
+// running returns the running tasks whose action matches one of the
+// patterns, ordered by id. No patterns matches every action.
+func (m *taskManager) running(actions []string) []*task {
+	m.mu.Lock()
+	tasks := make([]*task, 0, len(m.tasks))
+	for _, t := range m.tasks {
+		tasks = append(tasks, t)
+	}
+	m.mu.Unlock()
+
+	var running []*task
+	for _, t := range tasks {
+		t.mu.Lock()
+		completed := t.completed
+		t.mu.Unlock()
+		if completed || !matchesAnyAction(actions, t.action) {
+			continue
+		}
+		running = append(running, t)
+	}
+	sort.Slice(running, func(i, j int) bool { return running[i].number < running[j].number })
+	return running
+}
+
+// cancelTask cancels a running task. It reports false if there is no such
+// running task.
+func (m *taskManager) cancelTask(id string) (*task, bool) {
+	t, ok := m.get(id)
+	if !ok {
+		return nil, false
+	}
+	t.mu.Lock()
+	if t.completed {
+		t.mu.Unlock()
+		return nil, false
+	}
+	t.cancelled = true
+	t.mu.Unlock()
+
+	t.cancel()
+	return t, true
+}
+
+// matchesAnyAction reports whether an action matches one of the patterns,
+// in which * matches any run of characters
+func matchesAnyAction(patterns []string, action string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matchesWildcard(pattern, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWildcard reports whether s matches pattern, in which * matches
+// any run of characters, / and : included
+func matchesWildcard(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
+
+// runTask runs a long operation as a task. With wait_for_completion=false
+// the operation runs in the background and the response carries the id of
+// the task to poll; otherwise the operation's own response is written once
+// it completes.
+func (c *CoordinationNode) runTask(ctx *gin.Context, action, description string, op taskFunc) {
+	waitForCompletion := true
+	if value := ctx.Query("wait_for_completion"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			respondError(ctx, http.StatusBadRequest, "illegal_argument_exception",
+				fmt.Sprintf("Failed to parse [wait_for_completion] value [%s]", value))
+			return
+		}
+		waitForCompletion = parsed
+	}
+
+	if waitForCompletion {
+		t, taskCtx := c.tasks.register(ctx.Request.Context(), action, description)
+		code, response := c.tasks.run(taskCtx, t, op, false)
+		ctx.JSON(code, response)
+		return
+	}
+
+	// The background operation outlives the request, keeping its values
+	// such as the request id
+	t, taskCtx := c.tasks.register(context.WithoutCancel(ctx.Request.Context()), action, description)
+	c.workers.Add(1)
+	go func() {
+		defer c.workers.Done()
+		code, _ := c.tasks.run(taskCtx, t, op, true)
+		c.logger.Info("Background task completed",
+			zap.String("task", t.id),
+			zap.String("action", action),
+			zap.Int("status", code))
+	}()
+
+	ctx.JSON(http.StatusOK, gin.H{"task": t.id})
+}
+
+// tasksResponse renders tasks grouped by node, as listed by the tasks API
+func (c *CoordinationNode) tasksResponse(tasks []*task) gin.H {
+	infos := make(gin.H, len(tasks))
+	for _, t := range tasks {
+		infos[t.id] = t.info()
+	}
+	return gin.H{
+		"nodes": gin.H{
+			c.tasks.nodeID: gin.H{
+				"name":  c.tasks.nodeID,
+				"tasks": infos,
+			},
+		},
+	}
+}
+
+// taskNotFoundError reports a task id that is neither running nor has a
+// stored result
+func taskNotFoundError(id string) *APIError {
+	return &APIError{
+		Status: http.StatusNotFound,
+		Type:   "resource_not_found_exception",
+		Reason: fmt.Sprintf("task [%s] isn't running and hasn't stored its results", id),
+	}
+}
+
+// handleListTasks lists the running tasks, optionally only those whose
+// action matches the comma-separated actions patterns
+func (c *CoordinationNode) handleListTasks(ctx *gin.Context) {
+	var actions []string
+	if value := ctx.Query("actions"); value != "" {
+		actions = strings.Split(value, ",")
+	}
+	ctx.JSON(http.StatusOK, c.tasksResponse(c.tasks.running(actions)))
+}
+
+// handleGetTask returns a task's progress, and its response once completed
+func (c *CoordinationNode) handleGetTask(ctx *gin.Context) {
+	id := ctx.Param("id")
+	t, ok := c.tasks.get(id)
+	if !ok {
+		respondAPIError(ctx, taskNotFoundError(id))
+		return
+	}
+
+	info := t.info()
+	t.mu.Lock()
+	completed, code, response := t.completed, t.code, t.response
+	t.mu.Unlock()
+
+	body := gin.H{"completed": completed, "task": info}
+	if completed {
+		if code >= http.StatusBadRequest {
+			body["error"] = response["error"]
+		} else {
+			body["response"] = response
+		}
+	}
+	ctx.JSON(http.StatusOK, body)
+}
+
+// handleCancelTask cancels a running task. The operation stops at its next
+// check and reports what it did until then.
+func (c *CoordinationNode) handleCancelTask(ctx *gin.Context) {
+	id := ctx.Param("id")
+	t, ok := c.tasks.cancelTask(id)
+	if !ok {
+		respondAPIError(ctx, taskNotFoundError(id))
+		return
+	}
+
+	c.logger.Info("Cancelled task", zap.String("task", id), zap.String("action", t.action))
+	ctx.JSON(http.StatusOK, c.tasksResponse([]*task{t}))
+}
@@ -0,0 +1,172 @@
+package coordination
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getJSON(r *gin.Engine, path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w, response
+}
+
+// waitForTask polls a task until it completes and returns its final state
+func waitForTask(t *testing.T, r *gin.Engine, taskID string) map[string]interface{} {
+	t.Helper()
+
+	var task map[string]interface{}
+	require.Eventually(t, func() bool {
+		w, response := getJSON(r, "/_tasks/"+taskID)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		task = response
+		return response["completed"] == true
+	}, 5*time.Second, 10*time.Millisecond)
+	return task
+}
+
+func TestTasks_BackgroundTaskLifecycle(t *testing.T) {
+	node := newTestCoordinationNode(t)
+
+	// A mock long operation that reports progress until it is released
+	release := make(chan struct{})
+	progressed := make(chan struct{})
+	node.ginRouter.POST("/_test/long", func(ctx *gin.Context) {
+		node.runTask(ctx, "indices:test/long", "long test operation", func(ctx context.Context, task *task) (int, gin.H) {
+			task.setStatus(gin.H{"done": 1, "total": 2})
+			close(progressed)
+			<-release
+			task.setStatus(gin.H{"done": 2, "total": 2})
+			return http.StatusOK, gin.H{"result": "finished"}
+		})
+	})
+
+	w, response := postJSON(node.ginRouter, "/_test/long?wait_for_completion=false", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	taskID, _ := response["task"].(string)
+	require.Equal(t, "coord-test:1", taskID)
+	<-progressed
+
+	// While running, the task is listed and reports its progress
+	w, response = getJSON(node.ginRouter, "/_tasks/"+taskID)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, false, response["completed"])
+	info := response["task"].(map[string]interface{})
+	assert.Equal(t, "indices:test/long", info["action"])
+	assert.Equal(t, "long test operation", info["description"])
+	assert.Equal(t, map[string]interface{}{"done": float64(1), "total": float64(2)}, info["status"])
+	assert.NotContains(t, response, "response")
+
+	_, response = getJSON(node.ginRouter, "/_tasks?actions=indices:test/*")
+	tasks := response["nodes"].(map[string]interface{})["coord-test"].(map[string]interface{})["tasks"].(map[string]interface{})
+	assert.Contains(t, tasks, taskID)
+
+	_, response = getJSON(node.ginRouter, "/_tasks?actions=*byquery")
+	tasks = response["nodes"].(map[string]interface{})["coord-test"].(map[string]interface{})["tasks"].(map[string]interface{})
+	assert.Empty(t, tasks)
+
+	// Once released, the task completes with the operation's response and
+	// is no longer listed as running
+	close(release)
+	final := waitForTask(t, node.ginRouter, taskID)
+	assert.Equal(t, map[string]interface{}{"result": "finished"}, final["response"])
+	assert.Equal(t, float64(2), final["task"].(map[string]interface{})["status"].(map[string]interface{})["done"])
+
+	_, response = getJSON(node.ginRouter, "/_tasks")
+	tasks = response["nodes"].(map[string]interface{})["coord-test"].(map[string]interface{})["tasks"].(map[string]interface{})
+	assert.Empty(t, tasks)
+
+	// Completed tasks can't be cancelled
+	w, _ = postJSON(node.ginRouter, "/_tasks/"+taskID+"/_cancel", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTasks_CancelRunningTask(t *testing.T) {
+	node := newTestCoordinationNode(t)
+
+	started := make(chan struct{})
+	node.ginRouter.POST("/_test/blocking", func(ctx *gin.Context) {
+		node.runTask(ctx, "indices:test/blocking", "blocking test operation", func(ctx context.Context, task *task) (int, gin.H) {
+			close(started)
+			<-ctx.Done()
+			return http.StatusOK, gin.H{"canceled": "by user request"}
+		})
+	})
+
+	_, response := postJSON(node.ginRouter, "/_test/blocking?wait_for_completion=false", "")
+	taskID := response["task"].(string)
+	<-started
+
+	w, response := postJSON(node.ginRouter, "/_tasks/"+taskID+"/_cancel", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	info := response["nodes"].(map[string]interface{})["coord-test"].(map[string]interface{})["tasks"].(map[string]interface{})[taskID].(map[string]interface{})
+	assert.Equal(t, true, info["cancelled"])
+
+	final := waitForTask(t, node.ginRouter, taskID)
+	assert.Equal(t, "by user request", final["response"].(map[string]interface{})["canceled"])
+	assert.Equal(t, true, final["task"].(map[string]interface{})["cancelled"])
+}
+
+func TestTasks_Errors(t *testing.T) {
+	node := newTestCoordinationNode(t)
+
+	w, response := getJSON(node.ginRouter, "/_tasks/coord-test:42")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "resource_not_found_exception", response["error"].(map[string]interface{})["type"])
+
+	w, _ = postJSON(node.ginRouter, "/_tasks/coord-test:42/_cancel", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, _ = postJSON(node.ginRouter, "/logs/_delete_by_query?wait_for_completion=maybe", `{"query": {"match_all": {}}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTasks_DeleteByQueryInBackground(t *testing.T) {
+	index := newMemoryIndex()
+	node := setupByQueryNode(index)
+	node.ginRouter.GET("/_tasks/:id", node.handleGetTask)
+
+	w, response := postJSON(node.ginRouter, "/products/_delete_by_query?wait_for_completion=false", `{"query": {"term": {"category": "books"}}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	taskID := response["task"].(string)
+	require.NotEmpty(t, taskID)
+
+	final := waitForTask(t, node.ginRouter, taskID)
+	result := final["response"].(map[string]interface{})
+	assert.Equal(t, float64(2), result["deleted"])
+	assert.Equal(t, float64(2), result["total"])
+	assert.Equal(t, "indices:data/write/delete/byquery", final["task"].(map[string]interface{})["action"])
+
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	assert.Len(t, index.docs, 2)
+}
+
+func TestMatchesWildcard(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"indices:data/write/delete/byquery", "indices:data/write/delete/byquery", true},
+		{"*byquery", "indices:data/write/update/byquery", true},
+		{"indices:*", "indices:admin/forcemerge", true},
+		{"indices:*/write/*", "indices:data/write/update/byquery", true},
+		{"indices:admin/*", "indices:data/write/update/byquery", false},
+		{"*merge", "indices:admin/forcemerge/x", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchesWildcard(tt.pattern, tt.s), "%s ~ %s", tt.pattern, tt.s)
+	}
+}
@@ -0,0 +1,114 @@
+package coordination
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// termVectorsRequest is the optional body of a _termvectors request. Query
+// parameters of the same names take precedence.
+type termVectorsRequest struct {
+	Fields    []string `json:"fields"`
+	Positions *bool    `json:"positions"`
+	Offsets   *bool    `json:"offsets"`
+}
+
+// handleTermVectors returns the terms of a document's fields with their
+// frequencies, positions and offsets, for relevance debugging and "more
+// like this" style queries
+func (c *CoordinationNode) handleTermVectors(ctx *gin.Context) {
+	indexName := ctx.Param("index")
+	docID := ctx.Param("id")
+
+	badRequest := func(reason string) {
+		respondError(ctx, http.StatusBadRequest, "illegal_argument_exception", reason)
+	}
+
+	var req termVectorsRequest
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		badRequest(fmt.Sprintf("Failed to read request body: %v", err))
+		return
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			badRequest(fmt.Sprintf("Failed to parse term vectors request: %v", err))
+			return
+		}
+	}
+
+	fields := req.Fields
+	if value := ctx.Query("fields"); value != "" {
+		fields = strings.Split(value, ",")
+	}
+	flag := func(name string, fromBody *bool) (bool, bool) {
+		if value := ctx.Query(name); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				badRequest(fmt.Sprintf("Failed to parse [%s]: %v", name, err))
+				return false, false
+			}
+			return parsed, true
+		}
+		if fromBody != nil {
+			return *fromBody, true
+		}
+		return true, true
+	}
+	positions, ok := flag("positions", req.Positions)
+	if !ok {
+		return
+	}
+	offsets, ok := flag("offsets", req.Offsets)
+	if !ok {
+		return
+	}
+
+	resp, err := c.docRouter.RouteTermVectors(ctx.Request.Context(), indexName, docID, fields)
+	if err != nil {
+		c.logger.Error("Failed to get term vectors",
+			zap.String("index", indexName),
+			zap.String("doc_id", docID),
+			zap.Error(err))
+		respondErrorFrom(ctx, err, "exception", "Failed to get term vectors")
+		return
+	}
+
+	termVectors := gin.H{}
+	for field, vector := range resp.TermVectors {
+		terms := gin.H{}
+		for term, stats := range vector.Terms {
+			entry := gin.H{"term_freq": stats.TermFreq}
+			if positions || offsets {
+				tokens := make([]gin.H, len(stats.Tokens))
+				for i, token := range stats.Tokens {
+					tokens[i] = gin.H{}
+					if positions {
+						tokens[i]["position"] = token.Position
+					}
+					if offsets {
+						tokens[i]["start_offset"] = token.StartOffset
+						tokens[i]["end_offset"] = token.EndOffset
+					}
+				}
+				entry["tokens"] = tokens
+			}
+			terms[term] = entry
+		}
+		termVectors[field] = gin.H{"terms": terms}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"_index":       indexName,
+		"_id":          docID,
+		"found":        resp.Found,
+		"term_vectors": termVectors,
+	})
+}
@@ -0,0 +1,59 @@
+package coordination
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTermVectors_KnownDocument(t *testing.T) {
+	index := newMemoryIndex()
+	index.docs["5"] = map[string]interface{}{"title": "To be, or not to be", "category": "plays", "year": float64(1603)}
+	node := setupByQueryNode(index)
+
+	get := func(path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		node.ginRouter.ServeHTTP(w, req)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w, response
+	}
+
+	w, response := get("/products/_termvectors/5")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, true, response["found"])
+	assert.Equal(t, "5", response["_id"])
+
+	vectors := response["term_vectors"].(map[string]interface{})
+	assert.Len(t, vectors, 2)
+	terms := vectors["title"].(map[string]interface{})["terms"].(map[string]interface{})
+	assert.Len(t, terms, 4)
+
+	be := terms["be"].(map[string]interface{})
+	assert.Equal(t, float64(2), be["term_freq"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"position": float64(1), "start_offset": float64(3), "end_offset": float64(5)},
+		map[string]interface{}{"position": float64(5), "start_offset": float64(17), "end_offset": float64(19)},
+	}, be["tokens"])
+	assert.Equal(t, float64(1), terms["not"].(map[string]interface{})["term_freq"])
+
+	// Fields can be limited, and positions and offsets left out
+	w, response = get("/products/_termvectors/5?fields=category&positions=false&offsets=false")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	vectors = response["term_vectors"].(map[string]interface{})
+	require.Len(t, vectors, 1)
+	plays := vectors["category"].(map[string]interface{})["terms"].(map[string]interface{})["plays"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"term_freq": float64(1)}, plays)
+
+	w, response = get("/products/_termvectors/missing")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, false, response["found"])
+
+	w, _ = get("/products/_termvectors/5?offsets=maybe")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,187 @@
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"go.uber.org/zap"
+)
+
+// defaultTierMigrationTimeout bounds how long a tier migration that force
+// merges waits for the index's shards to reach their new tier
+const defaultTierMigrationTimeout = 5 * time.Minute
+
+// handleMigrateTier moves an index to a storage tier. The master relocates
+// the index's shards to data nodes of that tier. With force_merge set, the
+// request waits for the relocations to finish and then force merges every
+// copy, as indices moving off the hot tier are no longer written to.
+func (c *CoordinationNode) handleMigrateTier(ctx *gin.Context) {
+	indexName := ctx.Param("index")
+	tier := ctx.Param("tier")
+
+	badRequest := func(reason string) {
+		respondError(ctx, http.StatusBadRequest, "illegal_argument_exception", reason)
+	}
+
+	forceMerge := false
+	if value := ctx.Query("force_merge"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			badRequest(fmt.Sprintf("force_merge must be a boolean but was [%s]", value))
+			return
+		}
+		forceMerge = parsed
+	}
+	maxNumSegments := int32(1)
+	if value := ctx.Query("max_num_segments"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || parsed < 0 {
+			badRequest(fmt.Sprintf("max_num_segments must be a non-negative integer but was [%s]", value))
+			return
+		}
+		maxNumSegments = int32(parsed)
+	}
+	timeout := defaultTierMigrationTimeout
+	if value := ctx.Query("timeout"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			badRequest(fmt.Sprintf("failed to parse timeout [%s]", value))
+			return
+		}
+		timeout = parsed
+	}
+
+	current, err := c.masterClient.GetIndexMetadata(ctx.Request.Context(), indexName)
+	if err != nil {
+		respondAPIError(ctx, indexNotFoundError(indexName))
+		return
+	}
+
+	currentSettings := current.GetMetadata().GetSettings()
+	settings := cloneIndexSettings(currentSettings)
+	if settings.Tiering == nil {
+		settings.Tiering = &pb.TieringSettings{}
+	}
+	settings.Tiering.DefaultTier = tier
+	if _, err := c.masterClient.UpdateIndexSettings(ctx.Request.Context(), indexName, settings); err != nil {
+		c.logger.Error("Failed to update index storage tier",
+			zap.String("index", indexName),
+			zap.String("tier", tier),
+			zap.Error(err))
+		respondErrorFrom(ctx, err, "settings_update_exception", "Failed to update storage tier")
+		return
+	}
+
+	c.logger.Info("Migrating index to storage tier",
+		zap.String("index", indexName),
+		zap.String("from", currentSettings.GetTiering().GetDefaultTier()),
+		zap.String("to", tier))
+
+	response := gin.H{
+		"acknowledged": true,
+		"index":        indexName,
+		"tier":         tier,
+	}
+	if !forceMerge {
+		ctx.JSON(http.StatusOK, response)
+		return
+	}
+
+	routing, err := c.waitForTier(ctx.Request.Context(), indexName, tier, timeout)
+	if err != nil {
+		response["timed_out"] = true
+		response["reason"] = err.Error()
+		ctx.JSON(http.StatusRequestTimeout, response)
+		return
+	}
+
+	copies := startedShardCopies(routing)
+	results := make([]gin.H, len(copies))
+	var wg sync.WaitGroup
+	for i, shard := range copies {
+		wg.Add(1)
+		go func(i int, shard shardCopy) {
+			defer wg.Done()
+			results[i] = c.forceMergeShardCopy(ctx.Request.Context(), indexName, shard, maxNumSegments)
+		}(i, shard)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result["status"] != "success" {
+			failed++
+		}
+	}
+	response["forcemerge"] = gin.H{
+		"_shards": gin.H{
+			"total":      len(copies),
+			"successful": len(copies) - failed,
+			"failed":     failed,
+		},
+		"shards": results,
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// waitForTier waits until every copy of an index's shards is started on a
+// data node of tier and returns the index's shard routing
+func (c *CoordinationNode) waitForTier(ctx context.Context, indexName, tier string, timeout time.Duration) (map[int32]*pb.ShardRouting, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		state, err := c.masterClient.GetClusterState(ctx, true, true, false)
+		if err == nil {
+			if routing, done := indexOnTier(state, indexName, tier); done {
+				return routing, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("timed out waiting for index [%s] to move to the [%s] tier", indexName, tier)
+		case <-ticker.C:
+		}
+	}
+}
+
+// indexOnTier returns an index's shard routing and whether every copy of
+// its shards is started on a data node of tier. Nodes that do not set a
+// tier are hot.
+func indexOnTier(state *pb.ClusterStateResponse, indexName, tier string) (map[int32]*pb.ShardRouting, bool) {
+	nodeTiers := make(map[string]string, len(state.Nodes))
+	for _, node := range state.Nodes {
+		nodeTier := node.GetAttributes().GetStorageTier()
+		if nodeTier == "" {
+			nodeTier = "hot"
+		}
+		nodeTiers[node.NodeId] = nodeTier
+	}
+
+	index := state.GetRoutingTable().GetIndices()[indexName]
+	if index == nil {
+		return nil, false
+	}
+	for _, shard := range index.Shards {
+		allocations := append([]*pb.ShardAllocation{shard.GetAllocation()}, shard.GetReplicas()...)
+		for _, allocation := range allocations {
+			if allocation.GetState() != pb.ShardAllocation_SHARD_STATE_STARTED || nodeTiers[allocation.GetNodeId()] != tier {
+				return nil, false
+			}
+		}
+	}
+	return index.Shards, true
+}
@@ -0,0 +1,50 @@
+package coordination
+
+import (
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexOnTier(t *testing.T) {
+	started := pb.ShardAllocation_SHARD_STATE_STARTED
+	state := &pb.ClusterStateResponse{
+		Nodes: []*pb.NodeInfo{
+			{NodeId: "hot-1"},
+			{NodeId: "warm-1", Attributes: &pb.NodeAttributes{StorageTier: "warm"}},
+			{NodeId: "warm-2", Attributes: &pb.NodeAttributes{StorageTier: "warm"}},
+		},
+		RoutingTable: &pb.RoutingTable{Indices: map[string]*pb.IndexRoutingTable{
+			"logs": {IndexName: "logs", Shards: map[int32]*pb.ShardRouting{
+				0: {
+					ShardId:    0,
+					IsPrimary:  true,
+					Allocation: &pb.ShardAllocation{NodeId: "warm-1", State: started},
+					Replicas:   []*pb.ShardAllocation{{NodeId: "hot-1", State: started}},
+				},
+			}},
+		}},
+	}
+
+	// A replica is still on the hot tier
+	_, done := indexOnTier(state, "logs", "warm")
+	assert.False(t, done)
+
+	// Moving it is not enough until it has started
+	shard := state.RoutingTable.Indices["logs"].Shards[0]
+	shard.Replicas[0] = &pb.ShardAllocation{NodeId: "warm-2", State: pb.ShardAllocation_SHARD_STATE_INITIALIZING}
+	_, done = indexOnTier(state, "logs", "warm")
+	assert.False(t, done)
+
+	shard.Replicas[0].State = started
+	routing, done := indexOnTier(state, "logs", "warm")
+	assert.True(t, done)
+	assert.Len(t, routing, 1)
+
+	// Nodes without a tier are hot
+	_, done = indexOnTier(state, "logs", "hot")
+	assert.False(t, done)
+	_, done = indexOnTier(state, "missing", "warm")
+	assert.False(t, done)
+}
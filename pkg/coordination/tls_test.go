@@ -0,0 +1,196 @@
+package coordination
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quidditch/quidditch/pkg/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testPKI is a throwaway CA with one server and one client certificate
+type testPKI struct {
+	caFile     string
+	serverCert string
+	serverKey  string
+	clientCert string
+	clientKey  string
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	dir := t.TempDir()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "quidditch-test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	pki := &testPKI{caFile: filepath.Join(dir, "ca.pem")}
+	writePEM(t, pki.caFile, "CERTIFICATE", caDER)
+
+	issue := func(name string, serial int64, usage x509.ExtKeyUsage) (string, string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+
+		certFile := filepath.Join(dir, name+".pem")
+		keyFile := filepath.Join(dir, name+"-key.pem")
+		writePEM(t, certFile, "CERTIFICATE", der)
+		writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+		return certFile, keyFile
+	}
+
+	pki.serverCert, pki.serverKey = issue("server", 2, x509.ExtKeyUsageServerAuth)
+	pki.clientCert, pki.clientKey = issue("client", 3, x509.ExtKeyUsageClientAuth)
+	return pki
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(path, data, 0600))
+}
+
+// startTLSTestNode starts the REST server of a minimal node on a free port
+func startTLSTestNode(t *testing.T, tlsCfg config.TLSConfig) string {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/_health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	node := &CoordinationNode{
+		cfg: &config.CoordinationConfig{
+			BindAddr: "127.0.0.1",
+			RESTPort: freeTestPort(t),
+			TLS:      tlsCfg,
+		},
+		logger:    zap.NewNop(),
+		ginRouter: router,
+	}
+	addr := startTestHTTPServer(t, node)
+	t.Cleanup(func() { node.httpServer.Close() })
+
+	return "https://" + addr + "/_health"
+}
+
+// tlsTestClient builds an HTTPS client trusting the test CA
+func tlsTestClient(t *testing.T, pki *testPKI, withCert bool) *http.Client {
+	t.Helper()
+
+	tlsCfg := config.TLSConfig{CAFile: pki.caFile}
+	if withCert {
+		tlsCfg.CertFile = pki.clientCert
+		tlsCfg.KeyFile = pki.clientKey
+	}
+	clientTLS, err := tlsCfg.ClientConfig()
+	require.NoError(t, err)
+
+	return &http.Client{
+		Transport: &http.Transport{TLSClientConfig: clientTLS},
+		Timeout:   5 * time.Second,
+	}
+}
+
+func TestStartHTTPServer_TLS(t *testing.T) {
+	pki := newTestPKI(t)
+	url := startTLSTestNode(t, config.TLSConfig{
+		CertFile: pki.serverCert,
+		KeyFile:  pki.serverKey,
+	})
+
+	resp, err := tlsTestClient(t, pki, false).Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// A client that doesn't trust the CA must fail the handshake
+	_, err = (&http.Client{Timeout: 5 * time.Second}).Get(url)
+	assert.Error(t, err)
+}
+
+func TestStartHTTPServer_MutualTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	url := startTLSTestNode(t, config.TLSConfig{
+		CertFile:   pki.serverCert,
+		KeyFile:    pki.serverKey,
+		CAFile:     pki.caFile,
+		ClientAuth: true,
+	})
+
+	t.Run("WithClientCert", func(t *testing.T) {
+		resp, err := tlsTestClient(t, pki, true).Get(url)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("WithoutClientCert", func(t *testing.T) {
+		_, err := tlsTestClient(t, pki, false).Get(url)
+		assert.Error(t, err)
+	})
+}
+
+func TestStartHTTPServer_InvalidTLSConfig(t *testing.T) {
+	node := &CoordinationNode{
+		cfg: &config.CoordinationConfig{
+			BindAddr: "127.0.0.1",
+			TLS: config.TLSConfig{
+				CertFile: "/nonexistent/cert.pem",
+				KeyFile:  "/nonexistent/key.pem",
+			},
+		},
+		logger:    zap.NewNop(),
+		ginRouter: gin.New(),
+	}
+
+	err := node.startHTTPServer()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid tls config")
+}
+
+func TestTransportCredentials(t *testing.T) {
+	assert.Equal(t, "insecure", transportCredentials(nil).Info().SecurityProtocol)
+	assert.Equal(t, "tls", transportCredentials(&tls.Config{}).Info().SecurityProtocol)
+}
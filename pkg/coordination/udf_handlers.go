@@ -1,8 +1,10 @@
 package coordination
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -34,24 +36,39 @@ func (h *UDFHandlers) RegisterRoutes(r *gin.RouterGroup) {
 		udfs.GET("/:name/versions", h.listVersions)
 		udfs.DELETE("/:name/:version", h.deleteUDF)
 		udfs.POST("/:name/test", h.testUDF)
+		udfs.POST("/:name/_validate", h.validateUDF)
 		udfs.GET("/:name/stats", h.getStats)
 	}
 }
 
 // UDFUploadRequest represents a UDF upload request
 type UDFUploadRequest struct {
-	Name        string                 `json:"name" binding:"required"`
-	Version     string                 `json:"version" binding:"required"`
-	Description string                 `json:"description"`
-	Category    string                 `json:"category"`
-	Author      string                 `json:"author"`
-	Language    string                 `json:"language" binding:"required,oneof=wasm rust c wat python"`
-	FunctionName string                `json:"function_name" binding:"required"`
-	WASMBase64  string                 `json:"wasm_base64" binding:"required"`
-	Parameters  []wasm.UDFParameter    `json:"parameters"`
-	Returns     []wasm.UDFReturnType   `json:"returns" binding:"required"`
-	Tags        []string               `json:"tags"`
-	Metadata    map[string]interface{} `json:"metadata"`
+	Name         string                 `json:"name" binding:"required"`
+	Version      string                 `json:"version" binding:"required"`
+	Description  string                 `json:"description"`
+	Category     string                 `json:"category"`
+	Author       string                 `json:"author"`
+	Language     string                 `json:"language" binding:"required,oneof=wasm rust c wat python"`
+	FunctionName string                 `json:"function_name" binding:"required"`
+	WASMBase64   string                 `json:"wasm_base64" binding:"required"`
+	WASMHash     string                 `json:"wasm_hash"` // Optional SHA-256 of the WASM bytes, verified on upload
+	Parameters   []wasm.UDFParameter    `json:"parameters"`
+	Returns      []wasm.UDFReturnType   `json:"returns" binding:"required"`
+	Tags         []string               `json:"tags"`
+	Metadata     map[string]interface{} `json:"metadata"`
+
+	// Deterministic declares that the UDF's result depends only on its
+	// parameters and the document fields it reads, so results are cached
+	Deterministic bool `json:"deterministic"`
+
+	// SupportsBatch declares that the module exports a batch entrypoint
+	// the data nodes call with many documents at once
+	SupportsBatch bool `json:"supports_batch"`
+
+	// Pool settings (optional, registry defaults apply when omitted)
+	PoolSize             int   `json:"pool_size"`
+	PoolAcquireTimeoutMs int64 `json:"pool_acquire_timeout_ms"`
+	PoolFailFast         bool  `json:"pool_fail_fast"`
 }
 
 // uploadUDF handles POST /api/v1/udfs
@@ -59,10 +76,7 @@ func (h *UDFHandlers) uploadUDF(c *gin.Context) {
 	var req UDFUploadRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Warn("Invalid UDF upload request", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"details": err.Error(),
-		})
+		respondAPIError(c, parsingError(err))
 		return
 	}
 
@@ -70,39 +84,54 @@ func (h *UDFHandlers) uploadUDF(c *gin.Context) {
 	wasmBytes, err := decodeBase64(req.WASMBase64)
 	if err != nil {
 		h.logger.Warn("Invalid base64 WASM data", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid WASM data",
-			"details": err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, "illegal_argument_exception", fmt.Sprintf("Invalid WASM data: %v", err))
 		return
 	}
 
 	// Create UDF metadata
 	metadata := &wasm.UDFMetadata{
-		Name:         req.Name,
-		Version:      req.Version,
-		Description:  req.Description,
-		Category:     req.Category,
-		Author:       req.Author,
-		FunctionName: req.FunctionName,
-		WASMBytes:    wasmBytes,
-		Parameters:   req.Parameters,
-		Returns:      req.Returns,
-		Tags:         req.Tags,
-		RegisteredAt: time.Now(),
-		UpdatedAt:    time.Now(),
+		Name:          req.Name,
+		Version:       req.Version,
+		Description:   req.Description,
+		Category:      req.Category,
+		Author:        req.Author,
+		FunctionName:  req.FunctionName,
+		WASMBytes:     wasmBytes,
+		WASMHash:      strings.ToLower(req.WASMHash),
+		Parameters:    req.Parameters,
+		Returns:       req.Returns,
+		Tags:          req.Tags,
+		Deterministic: req.Deterministic,
+		SupportsBatch: req.SupportsBatch,
+		RegisteredAt:  time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
+	// Apply per-UDF pool overrides on top of registry defaults
+	poolCfg := h.registry.DefaultPoolConfig()
+	if req.PoolSize > 0 {
+		poolCfg.Size = req.PoolSize
+	}
+	if req.PoolAcquireTimeoutMs > 0 {
+		poolCfg.AcquireTimeout = time.Duration(req.PoolAcquireTimeoutMs) * time.Millisecond
+	}
+	poolCfg.FailFast = req.PoolFailFast
+
 	// Register UDF
-	if err := h.registry.Register(metadata); err != nil {
+	if err := h.registry.RegisterWithPoolConfig(metadata, poolCfg); err != nil {
 		h.logger.Error("Failed to register UDF",
 			zap.String("name", req.Name),
 			zap.String("version", req.Version),
 			zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to register UDF",
-			"details": err.Error(),
-		})
+		if errors.Is(err, wasm.ErrChecksumMismatch) {
+			respondError(c, http.StatusBadRequest, "illegal_argument_exception", err.Error())
+			return
+		}
+		if errors.Is(err, wasm.ErrSignatureMismatch) {
+			respondError(c, http.StatusBadRequest, "illegal_argument_exception", err.Error())
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "udf_exception", fmt.Sprintf("Failed to register UDF: %v", err))
 		return
 	}
 
@@ -112,11 +141,12 @@ func (h *UDFHandlers) uploadUDF(c *gin.Context) {
 		zap.Int("wasm_size", len(wasmBytes)))
 
 	c.JSON(http.StatusCreated, gin.H{
-		"message":    "UDF registered successfully",
-		"name":       req.Name,
-		"version":    req.Version,
-		"wasm_size":  len(wasmBytes),
-		"parameters": len(req.Parameters),
+		"message":       "UDF registered successfully",
+		"name":          req.Name,
+		"version":       req.Version,
+		"wasm_size":     len(wasmBytes),
+		"wasm_hash":     metadata.WASMHash,
+		"parameters":    len(req.Parameters),
 		"registered_at": metadata.RegisteredAt,
 	})
 }
@@ -186,9 +216,7 @@ func (h *UDFHandlers) getUDF(c *gin.Context) {
 		h.logger.Debug("UDF not found",
 			zap.String("name", name),
 			zap.String("version", version))
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": fmt.Sprintf("UDF %s not found", name),
-		})
+		respondError(c, http.StatusNotFound, "resource_not_found_exception", fmt.Sprintf("UDF %s not found", name))
 		return
 	}
 
@@ -221,9 +249,7 @@ func (h *UDFHandlers) listVersions(c *gin.Context) {
 	udfs := h.registry.Query(query)
 
 	if len(udfs) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": fmt.Sprintf("No versions found for UDF %s", name),
-		})
+		respondError(c, http.StatusNotFound, "resource_not_found_exception", fmt.Sprintf("No versions found for UDF %s", name))
 		return
 	}
 
@@ -254,10 +280,7 @@ func (h *UDFHandlers) deleteUDF(c *gin.Context) {
 			zap.String("name", name),
 			zap.String("version", version),
 			zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   fmt.Sprintf("UDF %s@%s not found", name, version),
-			"details": err.Error(),
-		})
+		respondError(c, http.StatusNotFound, "resource_not_found_exception", fmt.Sprintf("UDF %s@%s not found", name, version))
 		return
 	}
 
@@ -285,10 +308,7 @@ func (h *UDFHandlers) testUDF(c *gin.Context) {
 
 	var req UDFTestRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"details": err.Error(),
-		})
+		respondAPIError(c, parsingError(err))
 		return
 	}
 
@@ -298,9 +318,7 @@ func (h *UDFHandlers) testUDF(c *gin.Context) {
 		// Use latest version
 		registered, err := h.registry.GetLatest(name)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": fmt.Sprintf("UDF %s not found", name),
-			})
+			respondError(c, http.StatusNotFound, "resource_not_found_exception", fmt.Sprintf("UDF %s not found", name))
 			return
 		}
 		version = registered.Metadata.Version
@@ -328,10 +346,11 @@ func (h *UDFHandlers) testUDF(c *gin.Context) {
 			zap.String("name", name),
 			zap.String("version", version),
 			zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "UDF execution failed",
-			"details": err.Error(),
-		})
+		if errors.Is(err, wasm.ErrPoolExhausted) {
+			respondError(c, http.StatusTooManyRequests, "rejected_execution_exception", err.Error())
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "udf_exception", fmt.Sprintf("UDF execution failed: %v", err))
 		return
 	}
 
@@ -350,6 +369,66 @@ func (h *UDFHandlers) testUDF(c *gin.Context) {
 	})
 }
 
+// UDFValidateRequest represents a UDF dry run validation request. It takes
+// the module and declared signature of an upload; the name comes from the
+// path.
+type UDFValidateRequest struct {
+	Version       string               `json:"version" binding:"required"`
+	FunctionName  string               `json:"function_name" binding:"required"`
+	WASMBase64    string               `json:"wasm_base64" binding:"required"`
+	WASMHash      string               `json:"wasm_hash"`
+	Parameters    []wasm.UDFParameter  `json:"parameters"`
+	Returns       []wasm.UDFReturnType `json:"returns" binding:"required"`
+	SupportsBatch bool                 `json:"supports_batch"`
+}
+
+// validateUDF handles POST /api/v1/udfs/:name/_validate. It checks a UDF
+// would register and could be called without registering or running it,
+// so CI can validate modules without sample documents.
+func (h *UDFHandlers) validateUDF(c *gin.Context) {
+	name := c.Param("name")
+
+	var req UDFValidateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondAPIError(c, parsingError(err))
+		return
+	}
+
+	wasmBytes, err := decodeBase64(req.WASMBase64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "illegal_argument_exception", fmt.Sprintf("Invalid WASM data: %v", err))
+		return
+	}
+
+	validation := h.registry.ValidateUDF(&wasm.UDFMetadata{
+		Name:          name,
+		Version:       req.Version,
+		FunctionName:  req.FunctionName,
+		WASMBytes:     wasmBytes,
+		WASMHash:      strings.ToLower(req.WASMHash),
+		Parameters:    req.Parameters,
+		Returns:       req.Returns,
+		SupportsBatch: req.SupportsBatch,
+	})
+
+	h.logger.Debug("UDF validated",
+		zap.String("name", name),
+		zap.String("version", req.Version),
+		zap.Bool("valid", validation.Valid))
+
+	status := http.StatusOK
+	if !validation.Valid {
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, gin.H{
+		"name":        name,
+		"version":     req.Version,
+		"valid":       validation.Valid,
+		"wasm_hash":   validation.WASMHash,
+		"diagnostics": validation.Diagnostics,
+	})
+}
+
 // getStats handles GET /api/v1/udfs/:name/stats
 func (h *UDFHandlers) getStats(c *gin.Context) {
 	name := c.Param("name")
@@ -359,9 +438,7 @@ func (h *UDFHandlers) getStats(c *gin.Context) {
 		// Get latest version
 		registered, err := h.registry.GetLatest(name)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": fmt.Sprintf("UDF %s not found", name),
-			})
+			respondError(c, http.StatusNotFound, "resource_not_found_exception", fmt.Sprintf("UDF %s not found", name))
 			return
 		}
 		version = registered.Metadata.Version
@@ -369,15 +446,14 @@ func (h *UDFHandlers) getStats(c *gin.Context) {
 
 	stats, err := h.registry.GetStats(name, version)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": fmt.Sprintf("Stats for UDF %s@%s not found", name, version),
-		})
+		respondError(c, http.StatusNotFound, "resource_not_found_exception", fmt.Sprintf("Stats for UDF %s@%s not found", name, version))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"name":              name,
 		"version":           version,
+		"wasm_hash":         stats.WASMHash,
 		"call_count":        stats.CallCount,
 		"error_count":       stats.ErrorCount,
 		"total_duration_ms": stats.TotalDuration.Milliseconds(),
@@ -387,7 +463,14 @@ func (h *UDFHandlers) getStats(c *gin.Context) {
 		"last_called":       stats.LastCalled,
 		"last_error":        stats.LastError,
 		"last_error_time":   stats.LastErrorTime,
-	})
+	}
+
+	// Include pool utilization when the UDF is pooled
+	if poolStats, err := h.registry.GetPoolStats(name, version); err == nil {
+		response["pool"] = poolStats
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // Helper functions
@@ -105,6 +105,344 @@ func TestUDFHandlers_UploadUDF(t *testing.T) {
 	})
 }
 
+func TestUDFHandlers_UploadUDFSignatureMismatch(t *testing.T) {
+	logger := zap.NewNop()
+	rt, err := wasm.NewRuntime(&wasm.Config{
+		EnableJIT:   false,
+		EnableDebug: false,
+		Logger:      logger,
+	})
+	require.NoError(t, err)
+	defer rt.Close()
+
+	registry, err := wasm.NewUDFRegistry(&wasm.UDFRegistryConfig{
+		Runtime:          rt,
+		DefaultPoolSize:  1,
+		StrictSignatures: true,
+		Logger:           logger,
+	})
+	require.NoError(t, err)
+
+	handlers := NewUDFHandlers(registry, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api := router.Group("/api/v1")
+	handlers.RegisterRoutes(api)
+
+	// Exports add(i32, i32) -> i32, which lacks the leading ctx_id (i64)
+	wasmBytes := []byte{
+		0x00, 0x61, 0x73, 0x6d,
+		0x01, 0x00, 0x00, 0x00,
+		0x01, 0x07, 0x01, 0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7f,
+		0x03, 0x02, 0x01, 0x00,
+		0x07, 0x07, 0x01, 0x03, 0x61, 0x64, 0x64, 0x00, 0x00,
+		0x0a, 0x09, 0x01, 0x07, 0x00, 0x20, 0x00, 0x20, 0x01, 0x6a, 0x0b,
+	}
+
+	req := UDFUploadRequest{
+		Name:         "mismatched",
+		Version:      "1.0.0",
+		Language:     "wasm",
+		FunctionName: "add",
+		WASMBase64:   string(wasmBytes),
+		Parameters: []wasm.UDFParameter{
+			{Name: "x", Type: wasm.ValueTypeI32, Required: true},
+		},
+		Returns: []wasm.UDFReturnType{
+			{Type: wasm.ValueTypeI32},
+		},
+	}
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/api/v1/udfs", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "udf_signature_mismatch")
+
+	_, err = registry.Get("mismatched", "1.0.0")
+	assert.Error(t, err)
+}
+
+func TestUDFHandlers_UploadUDFDeduplication(t *testing.T) {
+	logger := zap.NewNop()
+	rt, err := wasm.NewRuntime(&wasm.Config{
+		EnableJIT:   false,
+		EnableDebug: false,
+		Logger:      logger,
+	})
+	require.NoError(t, err)
+	defer rt.Close()
+
+	registry, err := wasm.NewUDFRegistry(&wasm.UDFRegistryConfig{
+		Runtime:         rt,
+		DefaultPoolSize: 1,
+		EnableStats:     true,
+		Logger:          logger,
+	})
+	require.NoError(t, err)
+
+	handlers := NewUDFHandlers(registry, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api := router.Group("/api/v1")
+	handlers.RegisterRoutes(api)
+
+	wasmBytes := []byte{
+		0x00, 0x61, 0x73, 0x6d, // WASM magic
+		0x01, 0x00, 0x00, 0x00, // Version
+	}
+	hash := wasm.WASMHash(wasmBytes)
+
+	upload := func(name, version, declaredHash string) *httptest.ResponseRecorder {
+		req := UDFUploadRequest{
+			Name:         name,
+			Version:      version,
+			Language:     "wasm",
+			FunctionName: "filter",
+			WASMBase64:   string(wasmBytes),
+			WASMHash:     declaredHash,
+			Returns: []wasm.UDFReturnType{
+				{Type: wasm.ValueTypeI32},
+			},
+		}
+
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/udfs", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	// Identical bytes under another name and version share one module
+	for _, w := range []*httptest.ResponseRecorder{
+		upload("first", "1.0.0", ""),
+		upload("second", "2.0.0", hash),
+	} {
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, hash, response["wasm_hash"])
+	}
+
+	first, err := registry.Get("first", "1.0.0")
+	require.NoError(t, err)
+	second, err := registry.Get("second", "2.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, first.ModuleName, second.ModuleName)
+	assert.Same(t, &first.Metadata.WASMBytes[0], &second.Metadata.WASMBytes[0])
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/udfs/second/stats?version=2.0.0", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var stats map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, hash, stats["wasm_hash"])
+
+	// The shared module outlives any one of the UDFs using it
+	require.NoError(t, registry.Unregister("first", "1.0.0"))
+	instance, err := rt.NewModuleInstance(second.ModuleName)
+	require.NoError(t, err)
+	instance.Close()
+	require.Equal(t, http.StatusCreated, upload("third", "1.0.0", "").Code)
+
+	// A declared hash that doesn't match the bytes is rejected
+	w = upload("corrupted", "1.0.0", wasm.WASMHash([]byte("other bytes")))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "udf_checksum_mismatch")
+	_, err = registry.Get("corrupted", "1.0.0")
+	assert.Error(t, err)
+}
+
+// filterWasmBytes imports env.get_field_int64 and exports
+// filter(ctx_id i64) -> i32
+var filterWasmBytes = []byte{
+	0x00, 0x61, 0x73, 0x6d,
+	0x01, 0x00, 0x00, 0x00,
+	0x01, 0x0d, 0x02,
+	0x60, 0x03, 0x7e, 0x7f, 0x7f, 0x01, 0x7e,
+	0x60, 0x01, 0x7e, 0x01, 0x7f,
+	0x02, 0x17, 0x01, 0x03, 0x65, 0x6e, 0x76,
+	0x0f, 0x67, 0x65, 0x74, 0x5f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x5f, 0x69, 0x6e, 0x74, 0x36, 0x34,
+	0x00, 0x00,
+	0x03, 0x02, 0x01, 0x01,
+	0x07, 0x0a, 0x01, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x00, 0x01,
+	0x0a, 0x06, 0x01, 0x04, 0x00, 0x41, 0x01, 0x0b,
+}
+
+func TestUDFHandlers_ValidateUDF(t *testing.T) {
+	logger := zap.NewNop()
+	rt, err := wasm.NewRuntime(&wasm.Config{
+		EnableJIT:   false,
+		EnableDebug: false,
+		Logger:      logger,
+	})
+	require.NoError(t, err)
+	defer rt.Close()
+
+	registry, err := wasm.NewUDFRegistry(&wasm.UDFRegistryConfig{
+		Runtime:         rt,
+		DefaultPoolSize: 1,
+		Logger:          logger,
+	})
+	require.NoError(t, err)
+
+	handlers := NewUDFHandlers(registry, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api := router.Group("/api/v1")
+	handlers.RegisterRoutes(api)
+
+	wasmBytes := filterWasmBytes
+
+	validate := func(functionName string) (int, map[string]interface{}) {
+		req := UDFValidateRequest{
+			Version:      "1.0.0",
+			FunctionName: functionName,
+			WASMBase64:   string(wasmBytes),
+			Returns: []wasm.UDFReturnType{
+				{Type: wasm.ValueTypeI32},
+			},
+		}
+
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/udfs/checked/_validate", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, r)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+	diagnostics := func(response map[string]interface{}) map[string]map[string]interface{} {
+		byCheck := make(map[string]map[string]interface{})
+		for _, d := range response["diagnostics"].([]interface{}) {
+			diagnostic := d.(map[string]interface{})
+			byCheck[diagnostic["check"].(string)] = diagnostic
+		}
+		return byCheck
+	}
+
+	t.Run("ValidModule", func(t *testing.T) {
+		code, response := validate("filter")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, true, response["valid"])
+		assert.Equal(t, wasm.WASMHash(wasmBytes), response["wasm_hash"])
+
+		checks := diagnostics(response)
+		for _, check := range []string{
+			wasm.CheckMetadata, wasm.CheckChecksum, wasm.CheckCompile, wasm.CheckSignature, wasm.CheckHostImports,
+		} {
+			require.Contains(t, checks, check)
+			assert.Equal(t, true, checks[check]["passed"], check)
+		}
+	})
+
+	t.Run("MissingExport", func(t *testing.T) {
+		code, response := validate("score")
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, false, response["valid"])
+
+		checks := diagnostics(response)
+		assert.Equal(t, true, checks[wasm.CheckCompile]["passed"])
+		assert.Equal(t, false, checks[wasm.CheckSignature]["passed"])
+		assert.Contains(t, checks[wasm.CheckSignature]["message"], "function score is not exported")
+	})
+
+	// Validation never registers the UDF
+	_, err = registry.Get("checked", "1.0.0")
+	assert.Error(t, err)
+}
+
+func TestUDFHandlers_PersistAcrossRestart(t *testing.T) {
+	logger := zap.NewNop()
+	dataDir := t.TempDir()
+
+	// start brings up the UDF side of a coordination node over dataDir
+	start := func() (*wasm.UDFRegistry, *gin.Engine, func()) {
+		rt, err := wasm.NewRuntime(&wasm.Config{
+			EnableJIT:   false,
+			EnableDebug: false,
+			Logger:      logger,
+		})
+		require.NoError(t, err)
+
+		store, err := wasm.NewFileUDFStore(dataDir)
+		require.NoError(t, err)
+
+		registry, err := wasm.NewUDFRegistry(&wasm.UDFRegistryConfig{
+			Runtime:          rt,
+			DefaultPoolSize:  1,
+			StrictSignatures: true,
+			Store:            store,
+			Logger:           logger,
+		})
+		require.NoError(t, err)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		api := router.Group("/api/v1")
+		NewUDFHandlers(registry, logger).RegisterRoutes(api)
+
+		return registry, router, func() {
+			registry.Close()
+			rt.Close()
+		}
+	}
+
+	_, router, stop := start()
+	for _, version := range []string{"1.0.0", "1.1.0", "2.0.0"} {
+		body, _ := json.Marshal(UDFUploadRequest{
+			Name:         "persisted",
+			Version:      version,
+			Language:     "wasm",
+			FunctionName: "filter",
+			WASMBase64:   string(filterWasmBytes),
+			Returns:      []wasm.UDFReturnType{{Type: wasm.ValueTypeI32}},
+			PoolSize:     2,
+		})
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/udfs", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, r)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/v1/udfs/persisted/1.1.0", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	stop()
+
+	// A restarted node over the same data directory has the UDFs back
+	registry, router, stop := start()
+	defer stop()
+
+	for _, version := range []string{"1.0.0", "2.0.0"} {
+		registered, err := registry.Get("persisted", version)
+		require.NoError(t, err, version)
+		assert.Equal(t, filterWasmBytes, registered.Metadata.WASMBytes)
+		assert.Equal(t, 2, registered.PoolConfig.Size)
+	}
+	_, err := registry.Get("persisted", "1.1.0")
+	assert.Error(t, err, "deleted versions stay deleted")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/udfs/persisted", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "2.0.0", response["version"])
+}
+
 func TestUDFHandlers_ListUDFs(t *testing.T) {
 	// Create test setup
 	logger := zap.NewNop()
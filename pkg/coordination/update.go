@@ -0,0 +1,83 @@
+package coordination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// errDocumentMissing is returned by updateDocument when the document does not
+// exist and the update has no upsert
+var errDocumentMissing = errors.New("document missing")
+
+// documentUpdate is a partial update of a document, shared by the _update
+// API and bulk update operations
+type documentUpdate struct {
+	// Doc is merged into the existing source
+	Doc map[string]interface{}
+
+	// Upsert is indexed when the document does not exist, and DocAsUpsert
+	// indexes Doc instead
+	Upsert      map[string]interface{}
+	DocAsUpsert bool
+}
+
+// updateDocument applies an update to a document. It returns "created" when
+// a missing document was upserted, "noop" when merging the partial document
+// leaves the source unchanged and "updated" otherwise, with the resulting
+// document version.
+func (c *CoordinationNode) updateDocument(ctx context.Context, indexName, docID string, update documentUpdate) (string, int64, error) {
+	existing, err := c.docRouter.RouteGetDocument(ctx, indexName, docID)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	if !existing.Found {
+		upsert := update.Upsert
+		if update.DocAsUpsert {
+			upsert = update.Doc
+		}
+		if upsert == nil {
+			return "", 0, fmt.Errorf("[%s]: %w", docID, errDocumentMissing)
+		}
+
+		resp, err := c.docRouter.RouteIndexDocument(ctx, indexName, docID, upsert)
+		if err != nil {
+			return "", 0, err
+		}
+		return "created", resp.Version, nil
+	}
+
+	source := existing.Document.AsMap()
+	merged := mergeDocument(source, update.Doc)
+	if reflect.DeepEqual(merged, source) {
+		return "noop", existing.Version, nil
+	}
+
+	resp, err := c.docRouter.RouteIndexDocument(ctx, indexName, docID, merged)
+	if err != nil {
+		return "", 0, err
+	}
+	return "updated", resp.Version, nil
+}
+
+// mergeDocument returns a copy of source with partial merged into it. Objects
+// present in both are merged recursively; any other value in partial
+// replaces the one in source.
+func mergeDocument(source, partial map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(source)+len(partial))
+	for key, value := range source {
+		merged[key] = value
+	}
+	for key, value := range partial {
+		if object, ok := value.(map[string]interface{}); ok {
+			if existing, ok := merged[key].(map[string]interface{}); ok {
+				merged[key] = mergeDocument(existing, object)
+				continue
+			}
+		}
+		merged[key] = value
+	}
+	return merged
+}
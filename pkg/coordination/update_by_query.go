@@ -0,0 +1,147 @@
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quidditch/quidditch/pkg/coordination/pipeline"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// handleUpdateByQuery reindexes every document matching the query in the
+// request body, found one scroll_size batch at a time. Each document runs through
+// the document pipeline named by the pipeline parameter, or the index default
+// document pipeline, which makes it possible to backfill computed fields
+// after a pipeline change. It runs as a task, in the background with
+// wait_for_completion=false.
+func (c *CoordinationNode) handleUpdateByQuery(ctx *gin.Context) {
+	indexName := ctx.Param("index")
+
+	req, ok := parseByQueryRequest(ctx, false)
+	if !ok {
+		return
+	}
+
+	var pipe pipeline.Pipeline
+	if pipelineName := ctx.Query("pipeline"); pipelineName != "" {
+		var err error
+		if c.pipelineRegistry != nil {
+			pipe, err = c.pipelineRegistry.Get(pipelineName)
+		} else {
+			err = fmt.Errorf("pipeline not found: %s", pipelineName)
+		}
+		if err == nil && pipe.Type() != pipeline.PipelineTypeDocument {
+			err = fmt.Errorf("pipeline %s is a %s pipeline, not a document pipeline", pipelineName, pipe.Type())
+		}
+		if err != nil {
+			respondError(ctx, http.StatusBadRequest, "illegal_argument_exception", err.Error())
+			return
+		}
+	} else if c.pipelineRegistry != nil {
+		pipe, _ = c.pipelineRegistry.GetPipelineForIndex(indexName, pipeline.PipelineTypeDocument)
+	}
+
+	c.runTask(ctx, "indices:data/write/update/byquery", fmt.Sprintf("update-by-query [%s]", indexName), func(ctx context.Context, t *task) (int, gin.H) {
+		return c.updateByQuery(ctx, t, indexName, req, pipe)
+	})
+}
+
+// updateByQuery runs an update by query request under its task, running
+// each document through pipe when set. Each scroll_size batch of matches is
+// fetched whole and reindexed before the next is searched for; reindexed
+// documents that show up again on later pages are skipped. Data nodes
+// whose storage engine can't replace documents fail the request with a 501.
+func (c *CoordinationNode) updateByQuery(ctx context.Context, t *task, indexName string, req *byQueryRequest, pipe pipeline.Pipeline) (int, gin.H) {
+	startTime := time.Now()
+
+	result := &byQueryResult{}
+	updated := 0
+	var unsupported error
+	err := c.scrollMatches(ctx, indexName, req.Query, req.ScrollSize, func(hits []*SearchHit) bool {
+		result.Batches++
+		result.Total += len(hits)
+		for _, hit := range hits {
+			if ctx.Err() != nil {
+				return false
+			}
+			docID := hit.ID
+
+			// Search hits may not carry every field, so the whole document
+			// is fetched; one deleted since it matched is skipped
+			existing, err := c.docRouter.RouteGetDocument(ctx, indexName, docID)
+			if err == nil && !existing.Found {
+				continue
+			}
+			if err != nil {
+				if ctx.Err() != nil || !result.recordFailure(req, docID, "get_failed_exception", err) {
+					return false
+				}
+				continue
+			}
+
+			document := existing.Document.AsMap()
+			if pipe != nil {
+				modifiedDoc, err := c.runDocumentPipeline(ctx, pipe, indexName, docID, document)
+				if err != nil {
+					if !result.recordFailure(req, docID, "pipeline_exception", err) {
+						return false
+					}
+					continue
+				}
+				document = modifiedDoc
+			}
+
+			if _, err := c.docRouter.RouteIndexDocument(ctx, indexName, docID, document); err != nil {
+				if ctx.Err() != nil {
+					return false
+				}
+				// Every write fails the same way on a storage engine that
+				// can't replace documents, so the request fails as a whole
+				if status.Code(err) == codes.Unimplemented {
+					unsupported = err
+					return false
+				}
+				if !result.recordFailure(req, docID, "index_failed_exception", err) {
+					return false
+				}
+				continue
+			}
+			updated++
+			t.setStatus(result.progress("updated", updated))
+		}
+		return true
+	})
+	if unsupported != nil {
+		c.logger.Warn("Update by query is not supported by the data nodes",
+			zap.String("index", indexName),
+			zap.Error(unsupported))
+		apiErr := classifyError(unsupported)
+		return apiErr.Status, apiErr.envelope()
+	}
+	if err != nil && ctx.Err() == nil {
+		c.logger.Error("Update by query search failed",
+			zap.String("index", indexName),
+			zap.Error(err))
+		return byQuerySearchError(err)
+	}
+	result.Cancelled = ctx.Err() != nil
+
+	c.logger.Info("Update by query completed",
+		zap.String("index", indexName),
+		zap.Int("total", result.Total),
+		zap.Int("updated", updated),
+		zap.Int("batches", result.Batches),
+		zap.Int("version_conflicts", result.VersionConflicts),
+		zap.Int("failures", len(result.Failures)),
+		zap.Bool("cancelled", result.Cancelled))
+
+	response := result.response(indexName, time.Since(startTime).Milliseconds())
+	response["updated"] = updated
+	return result.statusCode(), response
+}
+
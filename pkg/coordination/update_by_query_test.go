@@ -0,0 +1,189 @@
+package coordination
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/coordination/executor"
+	"github.com/quidditch/quidditch/pkg/coordination/pipeline"
+	"github.com/quidditch/quidditch/pkg/coordination/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// registerDiscountPipeline registers a document pipeline that adds a
+// computed on_sale field to each document
+func registerDiscountPipeline(t *testing.T, registry *pipeline.Registry) {
+	t.Helper()
+
+	require.NoError(t, registry.Register(&pipeline.PipelineDefinition{
+		Name:    "add-on-sale",
+		Version: "1.0.0",
+		Type:    pipeline.PipelineTypeDocument,
+		Stages: []pipeline.StageDefinition{
+			{
+				Name:    "on_sale",
+				Type:    pipeline.StageTypeNative,
+				Enabled: true,
+				Config:  map[string]interface{}{"function": "test"},
+			},
+		},
+		Enabled: true,
+	}))
+
+	pipe, err := registry.Get("add-on-sale")
+	require.NoError(t, err)
+	pipe.(*pipeline.PipelineImpl).SetStages([]pipeline.Stage{
+		&mockDocPipelineStage{
+			name:      "on_sale",
+			stageType: pipeline.StageTypeNative,
+			executeFunc: func(ctx *pipeline.StageContext, input interface{}) (interface{}, error) {
+				inputMap := input.(map[string]interface{})
+				doc := inputMap["document"].(map[string]interface{})
+				doc["on_sale"] = doc["category"] == "books"
+				return inputMap, nil
+			},
+		},
+	})
+}
+
+func TestUpdateByQuery_PipelineAddsField(t *testing.T) {
+	index := newMemoryIndex()
+	node := setupByQueryNode(index)
+	registerDiscountPipeline(t, node.pipelineRegistry)
+
+	w, response := postJSON(node.ginRouter, "/products/_update_by_query?pipeline=add-on-sale&scroll_size=1",
+		`{"query": {"term": {"category": "books"}}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, float64(2), response["total"])
+	assert.Equal(t, float64(2), response["updated"])
+	assert.Equal(t, float64(2), response["batches"])
+	assert.Empty(t, response["failures"])
+
+	// Only the matching documents gained the field
+	assert.Equal(t, true, index.docs["1"]["on_sale"])
+	assert.Equal(t, true, index.docs["3"]["on_sale"])
+	assert.NotContains(t, index.docs["2"], "on_sale")
+	assert.NotContains(t, index.docs["4"], "on_sale")
+
+	// The update is what GET returns
+	req := httptest.NewRequest(http.MethodGet, "/products/_doc/1", nil)
+	rec := httptest.NewRecorder()
+	node.ginRouter.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, map[string]interface{}{"category": "books", "on_sale": true}, got["_source"])
+}
+
+// eventIndex is a memoryIndex that records the order of searches and
+// writes made against it
+type eventIndex struct {
+	*memoryIndex
+	events []string
+}
+
+func (e *eventIndex) ExecuteSearch(ctx context.Context, indexName string, query []byte, filterExpr []byte, from, size int) (*executor.SearchResult, error) {
+	e.events = append(e.events, "search")
+	return e.memoryIndex.ExecuteSearch(ctx, indexName, query, filterExpr, from, size)
+}
+
+func (e *eventIndex) IndexDocument(ctx context.Context, indexName string, shardID int32, docID string, document map[string]interface{}) (*pb.IndexDocumentResponse, error) {
+	e.events = append(e.events, "index "+docID)
+	return e.memoryIndex.IndexDocument(ctx, indexName, shardID, docID, document)
+}
+
+func TestUpdateByQuery_WritesEachBatch(t *testing.T) {
+	index := &eventIndex{memoryIndex: newMemoryIndex()}
+	node := setupByQueryNode(index.memoryIndex)
+	node.queryService = NewQueryService(index, &mockMasterClient{}, zap.NewNop())
+	node.docRouter = router.NewDocumentRouter(&mockMasterClient{}, map[string]router.DataNodeClient{"node1": index}, zap.NewNop())
+
+	w, response := postJSON(node.ginRouter, "/products/_update_by_query?scroll_size=1",
+		`{"query": {"term": {"category": "books"}}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, float64(2), response["updated"])
+
+	// A batch is written before the next one is searched for
+	assert.Equal(t, []string{"search", "index 1", "search", "index 3"}, index.events)
+}
+
+func TestUpdateByQuery_ReplaceUnsupported(t *testing.T) {
+	index := newMemoryIndex()
+	index.replaceUnsupported = true
+	node := setupByQueryNode(index)
+	registerDiscountPipeline(t, node.pipelineRegistry)
+
+	// Nothing is reported updated when the data nodes can't replace
+	// documents
+	w, response := postJSON(node.ginRouter, "/products/_update_by_query?pipeline=add-on-sale",
+		`{"query": {"term": {"category": "books"}}}`)
+	assert.Equal(t, http.StatusNotImplemented, w.Code, w.Body.String())
+	assert.NotContains(t, response, "updated")
+	assert.Equal(t, "unsupported_operation_exception", response["error"].(map[string]interface{})["type"])
+	assert.NotContains(t, index.docs["1"], "on_sale")
+}
+
+func TestUpdateByQuery_DefaultPipelineAndAllDocuments(t *testing.T) {
+	index := newMemoryIndex()
+	node := setupByQueryNode(index)
+	registerDiscountPipeline(t, node.pipelineRegistry)
+	require.NoError(t, node.pipelineRegistry.AssociatePipeline("products", pipeline.PipelineTypeDocument, "add-on-sale"))
+
+	// Without a body every document is updated through the index pipeline
+	w, response := postJSON(node.ginRouter, "/products/_update_by_query", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, float64(4), response["updated"])
+	assert.Equal(t, float64(1), response["batches"])
+
+	assert.Equal(t, true, index.docs["1"]["on_sale"])
+	assert.Equal(t, false, index.docs["2"]["on_sale"])
+}
+
+// partialSourceIndex is a memoryIndex whose search hits only carry the
+// category field, as hits read from stored fields may miss some
+type partialSourceIndex struct {
+	*memoryIndex
+}
+
+func (p *partialSourceIndex) ExecuteSearch(ctx context.Context, indexName string, query []byte, filterExpr []byte, from, size int) (*executor.SearchResult, error) {
+	result, err := p.memoryIndex.ExecuteSearch(ctx, indexName, query, filterExpr, from, size)
+	if err != nil {
+		return nil, err
+	}
+	for _, hit := range result.Hits {
+		hit.Source = map[string]interface{}{"category": hit.Source["category"]}
+	}
+	return result, nil
+}
+
+func TestUpdateByQuery_KeepsFullSource(t *testing.T) {
+	index := newMemoryIndex()
+	index.docs["1"]["title"] = "Dune"
+	node := setupByQueryNode(index)
+	node.queryService = NewQueryService(&partialSourceIndex{index}, &mockMasterClient{}, zap.NewNop())
+	registerDiscountPipeline(t, node.pipelineRegistry)
+
+	w, response := postJSON(node.ginRouter, "/products/_update_by_query?pipeline=add-on-sale",
+		`{"query": {"term": {"category": "books"}}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, float64(2), response["updated"])
+
+	// The reindexed document keeps the fields its search hit lacked
+	assert.Equal(t, map[string]interface{}{"category": "books", "title": "Dune", "on_sale": true}, index.docs["1"])
+}
+
+func TestUpdateByQuery_InvalidRequest(t *testing.T) {
+	node := setupByQueryNode(newMemoryIndex())
+
+	w, _ := postJSON(node.ginRouter, "/products/_update_by_query?pipeline=missing", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, _ = postJSON(node.ginRouter, "/products/_update_by_query?scroll_size=0", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
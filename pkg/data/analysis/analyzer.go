@@ -0,0 +1,88 @@
+// Package analysis turns text into the terms it is indexed and searched by.
+// An analyzer is a tokenizer followed by a chain of token filters; the
+// registry holds the built-in analyzers and those an index defines in its
+// settings.
+package analysis
+
+// Token is a term produced by analysis, with its position in the token
+// stream and its character offsets in the analyzed text
+type Token struct {
+	Text        string `json:"token"`
+	Position    int    `json:"position"`
+	StartOffset int    `json:"start_offset"`
+	EndOffset   int    `json:"end_offset"`
+	Type        string `json:"type"`
+}
+
+// Token types set by the tokenizers
+const (
+	TokenTypeAlphanum  = "<ALPHANUM>"
+	TokenTypeNum       = "<NUM>"
+	TokenTypeIdeograph = "<IDEOGRAPHIC>"
+	TokenTypeWord      = "word"
+)
+
+// Tokenizer splits text into tokens
+type Tokenizer func(text string) []Token
+
+// TokenFilter transforms a token stream. Filters that drop tokens leave the
+// positions of the remaining tokens unchanged, so phrases keep their gaps.
+type TokenFilter func(tokens []Token) []Token
+
+// Analyzer is a tokenizer followed by a chain of token filters
+type Analyzer struct {
+	name      string
+	tokenizer Tokenizer
+	filters   []TokenFilter
+}
+
+// NewAnalyzer creates an analyzer from a tokenizer and token filters,
+// applied in order
+func NewAnalyzer(name string, tokenizer Tokenizer, filters ...TokenFilter) *Analyzer {
+	return &Analyzer{name: name, tokenizer: tokenizer, filters: filters}
+}
+
+// Name returns the analyzer name
+func (a *Analyzer) Name() string {
+	return a.name
+}
+
+// Analyze analyzes text into tokens
+func (a *Analyzer) Analyze(text string) []Token {
+	tokens := a.tokenizer(text)
+	for _, filter := range a.filters {
+		if len(tokens) == 0 {
+			break
+		}
+		tokens = filter(tokens)
+	}
+	return tokens
+}
+
+// AnalyzeToStrings analyzes text and returns the token text alone. It never
+// fails; the error matches the analyzers Diagon provides.
+func (a *Analyzer) AnalyzeToStrings(text string) ([]string, error) {
+	tokens := a.Analyze(text)
+	terms := make([]string, len(tokens))
+	for i, token := range tokens {
+		terms[i] = token.Text
+	}
+	return terms, nil
+}
+
+// AnalyzeByPosition analyzes text and groups the token text by position.
+// Tokens sharing a position, like synonyms, are alternatives for it.
+func (a *Analyzer) AnalyzeByPosition(text string) ([][]string, error) {
+	var groups [][]string
+	index := make(map[int]int)
+	for _, token := range a.Analyze(text) {
+		i, ok := index[token.Position]
+		if !ok {
+			i = len(groups)
+			index[token.Position] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], token.Text)
+	}
+	return groups, nil
+}
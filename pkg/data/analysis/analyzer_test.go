@@ -0,0 +1,171 @@
+package analysis
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// terms analyzes text with the named analyzer of a registry
+func terms(t *testing.T, registry *Registry, analyzer, text string) []string {
+	t.Helper()
+
+	a, ok := registry.Get(analyzer)
+	if !ok {
+		t.Fatalf("analyzer [%s] not found", analyzer)
+	}
+	terms, err := a.AnalyzeToStrings(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return terms
+}
+
+func TestBuiltinAnalyzers(t *testing.T) {
+	registry, err := NewRegistry(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const text = "The Quick-Brown fox isn't at the Café, it's 3.5 m away!"
+	tests := []struct {
+		analyzer string
+		want     []string
+	}{
+		{"standard", []string{"the", "quick", "brown", "fox", "isn't", "at", "the", "café", "it's", "3.5", "m", "away"}},
+		{"simple", []string{"the", "quick", "brown", "fox", "isn", "t", "at", "the", "café", "it", "s", "m", "away"}},
+		{"whitespace", []string{"The", "Quick-Brown", "fox", "isn't", "at", "the", "Café,", "it's", "3.5", "m", "away!"}},
+		{"keyword", []string{text}},
+		{"lowercase", []string{"the", "quick-brown", "fox", "isn't", "at", "the", "café,", "it's", "3.5", "m", "away!"}},
+		{"stop", []string{"quick", "brown", "fox", "isn", "t", "café", "s", "m", "away"}},
+		{"english", []string{"quick", "brown", "fox", "isn't", "cafe", "it's", "3.5", "m", "away"}},
+		{"multilingual", []string{"the", "quick", "brown", "fox", "isn't", "at", "the", "cafe", "it's", "3.5", "m", "away"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.analyzer, func(t *testing.T) {
+			if got := terms(t, registry, tt.analyzer, text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStandardTokenizer(t *testing.T) {
+	tests := []struct {
+		text string
+		want []Token
+	}{
+		{
+			text: "Hello, world",
+			want: []Token{
+				{Text: "Hello", Position: 0, StartOffset: 0, EndOffset: 5, Type: TokenTypeAlphanum},
+				{Text: "world", Position: 1, StartOffset: 7, EndOffset: 12, Type: TokenTypeAlphanum},
+			},
+		},
+		{
+			text: "1,000 u.s.a. snake_case",
+			want: []Token{
+				{Text: "1,000", Position: 0, StartOffset: 0, EndOffset: 5, Type: TokenTypeNum},
+				{Text: "u.s.a", Position: 1, StartOffset: 6, EndOffset: 11, Type: TokenTypeAlphanum},
+				{Text: "snake_case", Position: 2, StartOffset: 13, EndOffset: 23, Type: TokenTypeAlphanum},
+			},
+		},
+		{
+			// Offsets count characters, not bytes; ideographs are words
+			text: "café 北京",
+			want: []Token{
+				{Text: "café", Position: 0, StartOffset: 0, EndOffset: 4, Type: TokenTypeAlphanum},
+				{Text: "北", Position: 1, StartOffset: 5, EndOffset: 6, Type: TokenTypeIdeograph},
+				{Text: "京", Position: 2, StartOffset: 6, EndOffset: 7, Type: TokenTypeIdeograph},
+			},
+		},
+		{text: " -- ", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := StandardTokenizer(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStopFilterKeepsPositions(t *testing.T) {
+	registry, err := NewRegistry(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	analyzer, _ := registry.Get("stop")
+
+	var positions []int
+	for _, token := range analyzer.Analyze("the end of the road") {
+		positions = append(positions, token.Position)
+	}
+	if want := []int{1, 4}; !reflect.DeepEqual(positions, want) {
+		t.Errorf("positions %v, want %v", positions, want)
+	}
+}
+
+func TestASCIIFoldingFilter(t *testing.T) {
+	tokens := ASCIIFoldingFilter(WhitespaceTokenizer("Crème brûlée Straße Øresund हिन्दी"))
+	var got []string
+	for _, token := range tokens {
+		got = append(got, token.Text)
+	}
+	if want := []string{"Creme", "brulee", "Strasse", "Oresund", "हिन्दी"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCustomAnalyzer(t *testing.T) {
+	registry, err := NewRegistry(map[string]Definition{
+		"folded_keyword": {Tokenizer: "keyword", Filters: []string{"trim", "lowercase", "asciifolding"}},
+		"shouting":       {Tokenizer: "whitespace", Filters: []string{"uppercase"}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := terms(t, registry, "folded_keyword", "  Île de France "), []string{"ile de france"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := terms(t, registry, "shouting", "stop that"), []string{"STOP", "THAT"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Built-in analyzers are still there
+	if _, ok := registry.Get("standard"); !ok {
+		t.Error("expected the standard analyzer")
+	}
+	names := registry.Names()
+	if len(names) != len(builtinAnalyzers)+2 {
+		t.Errorf("expected %d analyzers, got %v", len(builtinAnalyzers)+2, names)
+	}
+}
+
+func TestCustomAnalyzerErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition Definition
+		wantErr    string
+	}{
+		{"missing", Definition{Filters: []string{"lowercase"}}, "must set a tokenizer"},
+		{"missing", Definition{Tokenizer: "ngram"}, "unknown tokenizer [ngram]"},
+		{"missing", Definition{Tokenizer: "standard", Filters: []string{"lowercase", "shingle"}}, "unknown token filter [shingle]"},
+		{"keyword", Definition{Tokenizer: "whitespace"}, "cannot replace the built-in analyzer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantErr, func(t *testing.T) {
+			_, err := NewRegistry(map[string]Definition{tt.name: tt.definition}, nil)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %q does not contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
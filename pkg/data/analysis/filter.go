@@ -0,0 +1,97 @@
+package analysis
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// tokenFilters are the token filters custom analyzers can chain
+var tokenFilters = map[string]TokenFilter{
+	"lowercase":    LowercaseFilter,
+	"uppercase":    UppercaseFilter,
+	"stop":         StopFilter,
+	"asciifolding": ASCIIFoldingFilter,
+	"trim":         TrimFilter,
+	"porter_stem":  PorterStemFilter,
+}
+
+// EnglishStopWords are the words the stop filter removes, Lucene's English
+// stop word set
+var EnglishStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "if": true, "in": true,
+	"into": true, "is": true, "it": true, "no": true, "not": true, "of": true,
+	"on": true, "or": true, "such": true, "that": true, "the": true,
+	"their": true, "then": true, "there": true, "these": true, "they": true,
+	"this": true, "to": true, "was": true, "will": true, "with": true,
+}
+
+// mapTokens replaces the text of each token, dropping tokens left empty
+func mapTokens(tokens []Token, mapping func(string) string) []Token {
+	filtered := tokens[:0]
+	for _, token := range tokens {
+		token.Text = mapping(token.Text)
+		if token.Text != "" {
+			filtered = append(filtered, token)
+		}
+	}
+	return filtered
+}
+
+// LowercaseFilter lowercases tokens
+func LowercaseFilter(tokens []Token) []Token {
+	return mapTokens(tokens, strings.ToLower)
+}
+
+// UppercaseFilter uppercases tokens
+func UppercaseFilter(tokens []Token) []Token {
+	return mapTokens(tokens, strings.ToUpper)
+}
+
+// TrimFilter trims whitespace around tokens
+func TrimFilter(tokens []Token) []Token {
+	return mapTokens(tokens, strings.TrimSpace)
+}
+
+// StopFilter removes English stop words. Matching is case-sensitive, so it
+// belongs after a lowercase filter.
+func StopFilter(tokens []Token) []Token {
+	filtered := tokens[:0]
+	for _, token := range tokens {
+		if !EnglishStopWords[token.Text] {
+			filtered = append(filtered, token)
+		}
+	}
+	return filtered
+}
+
+// foldedLetters are the letters that don't decompose into an ASCII letter
+// and a diacritic
+var foldedLetters = strings.NewReplacer(
+	"ß", "ss", "æ", "ae", "Æ", "AE", "œ", "oe", "Œ", "OE",
+	"ø", "o", "Ø", "O", "đ", "d", "Đ", "D", "ł", "l", "Ł", "L",
+	"þ", "th", "Þ", "TH", "ð", "d", "Ð", "D", "ı", "i",
+)
+
+// ASCIIFoldingFilter folds Latin letters with diacritics to their ASCII
+// equivalents, so café matches cafe. Marks on letters of other scripts,
+// such as Devanagari vowel signs, are kept.
+func ASCIIFoldingFilter(tokens []Token) []Token {
+	return mapTokens(tokens, func(text string) string {
+		var folded strings.Builder
+		latin := false
+		for _, r := range norm.NFD.String(foldedLetters.Replace(text)) {
+			if unicode.Is(unicode.Mn, r) {
+				if latin {
+					continue
+				}
+			} else {
+				latin = unicode.Is(unicode.Latin, r)
+			}
+			folded.WriteRune(r)
+		}
+		return norm.NFC.String(folded.String())
+	})
+}
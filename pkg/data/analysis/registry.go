@@ -0,0 +1,173 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultAnalyzer analyzes text fields that don't map an analyzer
+const DefaultAnalyzer = "standard"
+
+// builtinAnalyzers are the analyzers every index can use
+var builtinAnalyzers = map[string]*Analyzer{
+	// Words, lowercased
+	"standard": NewAnalyzer("standard", StandardTokenizer, LowercaseFilter),
+	// Runs of letters, lowercased
+	"simple": NewAnalyzer("simple", LowercaseTokenizer),
+	// Whitespace-separated tokens as they are
+	"whitespace": NewAnalyzer("whitespace", WhitespaceTokenizer),
+	// The whole value as a single token
+	"keyword": NewAnalyzer("keyword", KeywordTokenizer),
+	// Whitespace-separated tokens, lowercased
+	"lowercase": NewAnalyzer("lowercase", WhitespaceTokenizer, LowercaseFilter),
+	// Runs of letters, lowercased, without English stop words
+	"stop": NewAnalyzer("stop", LowercaseTokenizer, StopFilter),
+	// Words, lowercased and folded to ASCII, without English stop words
+	"english": NewAnalyzer("english", StandardTokenizer, LowercaseFilter, ASCIIFoldingFilter, StopFilter),
+	// Words, lowercased and folded to ASCII
+	"multilingual": NewAnalyzer("multilingual", StandardTokenizer, LowercaseFilter, ASCIIFoldingFilter),
+}
+
+// Definition defines a custom analyzer as a tokenizer followed by a chain
+// of token filters, each named by a built-in filter or a custom one
+type Definition struct {
+	Tokenizer string   `json:"tokenizer"`
+	Filters   []string `json:"filters,omitempty"`
+}
+
+// FilterDefinition defines a custom token filter: a synonym filter with its
+// rules, a stemmer for a language, or a built-in filter under another name
+type FilterDefinition struct {
+	Type     string   `json:"type"`
+	Synonyms []string `json:"synonyms,omitempty"`
+	Language string   `json:"language,omitempty"`
+}
+
+// Registry resolves analyzer names to the built-in analyzers and the
+// custom analyzers an index defines
+type Registry struct {
+	analyzers map[string]*Analyzer
+	filters   map[string]FilterDefinition
+}
+
+// NewRegistry creates a registry of the built-in analyzers and the given
+// custom ones, which can chain the given custom token filters. Custom
+// analyzers and filters cannot replace built-in ones.
+func NewRegistry(custom map[string]Definition, filters map[string]FilterDefinition) (*Registry, error) {
+	for name, definition := range filters {
+		if _, exists := tokenFilters[name]; exists {
+			return nil, fmt.Errorf("custom token filter [%s] cannot replace the built-in filter", name)
+		}
+		// Check the definition on its own; analyzers build it again with
+		// the analysis chain preceding it
+		if _, err := definition.build(name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	analyzers := make(map[string]*Analyzer, len(builtinAnalyzers)+len(custom))
+	for name, analyzer := range builtinAnalyzers {
+		analyzers[name] = analyzer
+	}
+
+	for name, definition := range custom {
+		if _, exists := builtinAnalyzers[name]; exists {
+			return nil, fmt.Errorf("custom analyzer [%s] cannot replace the built-in analyzer", name)
+		}
+		analyzer, err := definition.build(name, filters)
+		if err != nil {
+			return nil, err
+		}
+		analyzers[name] = analyzer
+	}
+
+	return &Registry{analyzers: analyzers, filters: filters}, nil
+}
+
+// Build creates an analyzer from a definition without adding it to the
+// registry. Its filters can name the registry's custom filters.
+func (r *Registry) Build(name string, definition Definition) (*Analyzer, error) {
+	return definition.build(name, r.filters)
+}
+
+// build creates the analyzer a definition defines
+func (d Definition) build(name string, customFilters map[string]FilterDefinition) (*Analyzer, error) {
+	if d.Tokenizer == "" {
+		return nil, fmt.Errorf("custom analyzer [%s] must set a tokenizer", name)
+	}
+	tokenizer, ok := tokenizers[d.Tokenizer]
+	if !ok {
+		return nil, fmt.Errorf("custom analyzer [%s] has unknown tokenizer [%s]", name, d.Tokenizer)
+	}
+
+	filters := make([]TokenFilter, len(d.Filters))
+	for i, filterName := range d.Filters {
+		if definition, ok := customFilters[filterName]; ok {
+			// A custom filter sees its rules as the chain before it sees text
+			preceding := NewAnalyzer(name, tokenizer, filters[:i]...)
+			filter, err := definition.build(filterName, func(text string) []string {
+				terms, _ := preceding.AnalyzeToStrings(text)
+				return terms
+			})
+			if err != nil {
+				return nil, err
+			}
+			filters[i] = filter
+			continue
+		}
+		filter, ok := tokenFilters[filterName]
+		if !ok {
+			return nil, fmt.Errorf("custom analyzer [%s] has unknown token filter [%s]", name, filterName)
+		}
+		filters[i] = filter
+	}
+
+	return NewAnalyzer(name, tokenizer, filters...), nil
+}
+
+// build creates the token filter a definition defines. analyze splits the
+// terms of synonym rules into tokens.
+func (d FilterDefinition) build(name string, analyze func(string) []string) (TokenFilter, error) {
+	switch d.Type {
+	case "synonym", "synonym_graph":
+		filter, err := NewSynonymFilter(d.Synonyms, analyze)
+		if err != nil {
+			return nil, fmt.Errorf("custom token filter [%s]: %w", name, err)
+		}
+		return filter, nil
+	case "stemmer":
+		language := d.Language
+		if language == "" {
+			language = "english"
+		}
+		filter, err := NewStemFilter(language)
+		if err != nil {
+			return nil, fmt.Errorf("custom token filter [%s]: %w", name, err)
+		}
+		return filter, nil
+	case "":
+		return nil, fmt.Errorf("custom token filter [%s] must set a type", name)
+	}
+
+	filter, ok := tokenFilters[d.Type]
+	if !ok {
+		return nil, fmt.Errorf("custom token filter [%s] has unknown type [%s]", name, d.Type)
+	}
+	return filter, nil
+}
+
+// Get returns the analyzer with the given name
+func (r *Registry) Get(name string) (*Analyzer, bool) {
+	analyzer, ok := r.analyzers[name]
+	return analyzer, ok
+}
+
+// Names returns the names of the analyzers in the registry, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.analyzers))
+	for name := range r.analyzers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
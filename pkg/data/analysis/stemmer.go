@@ -0,0 +1,294 @@
+package analysis
+
+import "fmt"
+
+// stemmers are the stemmers the stemmer filter type supports, by language
+var stemmers = map[string]func(string) string{
+	"english":         PorterStem,
+	"porter":          PorterStem,
+	"minimal_english": MinimalEnglishStem,
+}
+
+// NewStemFilter creates a filter stemming tokens in a language. It stems
+// lowercase ASCII words only, so it belongs after a lowercase filter.
+func NewStemFilter(language string) (TokenFilter, error) {
+	stem, ok := stemmers[language]
+	if !ok {
+		return nil, fmt.Errorf("unknown stemmer language [%s]", language)
+	}
+	return func(tokens []Token) []Token {
+		return mapTokens(tokens, func(text string) string {
+			if !isLowerASCII(text) {
+				return text
+			}
+			return stem(text)
+		})
+	}, nil
+}
+
+// PorterStemFilter stems English tokens with the Porter stemmer, so
+// "running" matches "run"
+func PorterStemFilter(tokens []Token) []Token {
+	filter, _ := NewStemFilter("english")
+	return filter(tokens)
+}
+
+// isLowerASCII reports whether text consists of lowercase ASCII letters
+func isLowerASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] < 'a' || text[i] > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// MinimalEnglishStem removes English plural endings, Lucene's minimal
+// English stemmer: "queries" becomes "query", "cats" becomes "cat"
+func MinimalEnglishStem(word string) string {
+	n := len(word)
+	if n < 3 || word[n-1] != 's' {
+		return word
+	}
+	switch word[n-2] {
+	case 'u', 's':
+		return word
+	case 'e':
+		if n > 3 && word[n-3] == 'i' && word[n-4] != 'a' && word[n-4] != 'e' {
+			return word[:n-3] + "y"
+		}
+		switch word[n-3] {
+		case 'i', 'a', 'o', 'e':
+			return word
+		}
+	}
+	return word[:n-1]
+}
+
+// PorterStem stems a lowercase English word with the Porter algorithm
+// (M.F. Porter, "An algorithm for suffix stripping", 1980). Words of two
+// letters or fewer are kept.
+func PorterStem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	s := &porterStemmer{b: []byte(word), k: len(word) - 1}
+	s.step1ab()
+	if s.k > 0 {
+		s.step1c()
+		s.step2()
+		s.step3()
+		s.step4()
+		s.step5()
+	}
+	return string(s.b[:s.k+1])
+}
+
+// porterStemmer holds a word being stemmed: b[:k+1] is the current word
+// and b[:j+1] the stem before the suffix last matched by ends
+type porterStemmer struct {
+	b    []byte
+	k, j int
+}
+
+// cons reports whether b[i] is a consonant. y is a consonant at the start
+// of a word or after a vowel.
+func (s *porterStemmer) cons(i int) bool {
+	switch s.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !s.cons(i-1)
+	}
+	return true
+}
+
+// m measures the number of vowel-consonant sequences in b[:j+1]
+func (s *porterStemmer) m() int {
+	n, i := 0, 0
+	for ; i <= s.j && s.cons(i); i++ {
+	}
+	for {
+		for ; i <= s.j && !s.cons(i); i++ {
+		}
+		if i > s.j {
+			return n
+		}
+		for ; i <= s.j && s.cons(i); i++ {
+		}
+		n++
+	}
+}
+
+// vowelInStem reports whether b[:j+1] contains a vowel
+func (s *porterStemmer) vowelInStem() bool {
+	for i := 0; i <= s.j; i++ {
+		if !s.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doubleCons reports whether b[i-1:i+1] is a double consonant
+func (s *porterStemmer) doubleCons(i int) bool {
+	return i >= 1 && s.b[i] == s.b[i-1] && s.cons(i)
+}
+
+// cvc reports whether b[i-2:i+1] is consonant-vowel-consonant and the last
+// consonant isn't w, x or y, as in hop but not in snow
+func (s *porterStemmer) cvc(i int) bool {
+	if i < 2 || !s.cons(i) || s.cons(i-1) || !s.cons(i-2) {
+		return false
+	}
+	switch s.b[i] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+// ends reports whether the word ends with suffix, setting j to the end of
+// the stem before it
+func (s *porterStemmer) ends(suffix string) bool {
+	start := s.k - len(suffix) + 1
+	if start < 0 || string(s.b[start:s.k+1]) != suffix {
+		return false
+	}
+	s.j = start - 1
+	return true
+}
+
+// setTo replaces the suffix after the stem with replacement
+func (s *porterStemmer) setTo(replacement string) {
+	s.b = append(s.b[:s.j+1], replacement...)
+	s.k = s.j + len(replacement)
+}
+
+// replace replaces the suffix after the stem if the stem measure is positive
+func (s *porterStemmer) replace(replacement string) {
+	if s.m() > 0 {
+		s.setTo(replacement)
+	}
+}
+
+// step1ab removes plurals and -ed or -ing:
+// caresses → caress, ponies → poni, meeting → meet, hopping → hop
+func (s *porterStemmer) step1ab() {
+	if s.b[s.k] == 's' {
+		switch {
+		case s.ends("sses"):
+			s.k -= 2
+		case s.ends("ies"):
+			s.setTo("i")
+		case s.b[s.k-1] != 's':
+			s.k--
+		}
+	}
+	if s.ends("eed") {
+		if s.m() > 0 {
+			s.k--
+		}
+		return
+	}
+	if (s.ends("ed") || s.ends("ing")) && s.vowelInStem() {
+		s.k = s.j
+		switch {
+		case s.ends("at"):
+			s.setTo("ate")
+		case s.ends("bl"):
+			s.setTo("ble")
+		case s.ends("iz"):
+			s.setTo("ize")
+		case s.doubleCons(s.k):
+			switch s.b[s.k] {
+			case 'l', 's', 'z':
+			default:
+				s.k--
+			}
+		default:
+			s.j = s.k
+			if s.m() == 1 && s.cvc(s.k) {
+				s.setTo("e")
+			}
+		}
+	}
+}
+
+// step1c turns a final y into i when the stem has a vowel: happy → happi
+func (s *porterStemmer) step1c() {
+	if s.ends("y") && s.vowelInStem() {
+		s.b[s.k] = 'i'
+	}
+}
+
+// step2 maps double suffixes to single ones: relational → relate
+func (s *porterStemmer) step2() {
+	s.replaceFirst([][2]string{
+		{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"},
+		{"izer", "ize"}, {"bli", "ble"}, {"alli", "al"}, {"entli", "ent"},
+		{"eli", "e"}, {"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"},
+		{"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"},
+		{"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+		{"logi", "log"},
+	})
+}
+
+// step3 handles -ic-, -full, -ness and the like: hopeful → hope
+func (s *porterStemmer) step3() {
+	s.replaceFirst([][2]string{
+		{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+		{"ical", "ic"}, {"ful", ""}, {"ness", ""},
+	})
+}
+
+// replaceFirst replaces the first of the suffixes the word ends with. The
+// suffix is replaced only if the stem measure is positive, but a matching
+// suffix ends the search either way.
+func (s *porterStemmer) replaceFirst(suffixes [][2]string) {
+	for _, suffix := range suffixes {
+		if s.ends(suffix[0]) {
+			s.replace(suffix[1])
+			return
+		}
+	}
+}
+
+// step4Suffixes are the suffixes step4 removes, longest first where one
+// ends another
+var step4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+	"ent", "ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+}
+
+// step4 removes -ant, -ence and the like when the stem measure is above
+// one: adjustable → adjust
+func (s *porterStemmer) step4() {
+	for _, suffix := range step4Suffixes {
+		if !s.ends(suffix) {
+			continue
+		}
+		// -ion is removed only after s or t
+		if suffix == "ion" && (s.j < 0 || (s.b[s.j] != 's' && s.b[s.j] != 't')) {
+			return
+		}
+		if s.m() > 1 {
+			s.k = s.j
+		}
+		return
+	}
+}
+
+// step5 removes a final -e and reduces a final -ll when the stem measure is
+// above one: probate → probat, controll → control
+func (s *porterStemmer) step5() {
+	s.j = s.k
+	if s.b[s.k] == 'e' {
+		if a := s.m(); a > 1 || (a == 1 && !s.cvc(s.k-1)) {
+			s.k--
+		}
+	}
+	if s.b[s.k] == 'l' && s.doubleCons(s.k) && s.m() > 1 {
+		s.k--
+	}
+}
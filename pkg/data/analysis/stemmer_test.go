@@ -0,0 +1,88 @@
+package analysis
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPorterStem(t *testing.T) {
+	// Examples from Porter's paper and the reference vocabulary
+	tests := map[string]string{
+		"caresses": "caress", "ponies": "poni", "ties": "ti", "caress": "caress", "cats": "cat",
+		"feed": "feed", "agreed": "agre", "plastered": "plaster", "bled": "bled", "motoring": "motor",
+		"sing": "sing", "conflated": "conflat", "troubled": "troubl", "sized": "size", "hopping": "hop",
+		"tanned": "tan", "falling": "fall", "hissing": "hiss", "fizzed": "fizz", "failing": "fail",
+		"filing": "file", "happy": "happi", "sky": "sky", "relational": "relat", "conditional": "condit",
+		"rational": "ration", "valenci": "valenc", "hesitanci": "hesit", "digitizer": "digit",
+		"conformabli": "conform", "radicalli": "radic", "differentli": "differ", "vileli": "vile",
+		"analogousli": "analog", "vietnamization": "vietnam", "predication": "predic", "operator": "oper",
+		"feudalism": "feudal", "decisiveness": "decis", "hopefulness": "hope", "callousness": "callous",
+		"formaliti": "formal", "sensitiviti": "sensit", "sensibiliti": "sensibl", "triplicate": "triplic",
+		"formative": "form", "formalize": "formal", "electriciti": "electr", "electrical": "electr",
+		"hopeful": "hope", "goodness": "good", "revival": "reviv", "allowance": "allow",
+		"inference": "infer", "airliner": "airlin", "gyroscopic": "gyroscop", "adjustable": "adjust",
+		"defensible": "defens", "irritant": "irrit", "replacement": "replac", "adjustment": "adjust",
+		"dependent": "depend", "adoption": "adopt", "homologou": "homolog", "communism": "commun",
+		"activate": "activ", "angulariti": "angular", "homologous": "homolog", "effective": "effect",
+		"bowdlerize": "bowdler", "probate": "probat", "rate": "rate", "cease": "ceas",
+		"controll": "control", "roll": "roll", "running": "run", "runs": "run", "ran": "ran",
+		"generalizations": "gener", "is": "is",
+	}
+
+	for word, want := range tests {
+		if got := PorterStem(word); got != want {
+			t.Errorf("PorterStem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestMinimalEnglishStem(t *testing.T) {
+	tests := map[string]string{
+		"queries": "query", "cats": "cat", "boxes": "boxe", "status": "status",
+		"glass": "glass", "toes": "toes", "series": "sery", "running": "running",
+	}
+
+	for word, want := range tests {
+		if got := MinimalEnglishStem(word); got != want {
+			t.Errorf("MinimalEnglishStem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestStemFilter(t *testing.T) {
+	registry, err := NewRegistry(map[string]Definition{
+		"stemmed":   {Tokenizer: "standard", Filters: []string{"lowercase", "porter_stem"}},
+		"plurals":   {Tokenizer: "standard", Filters: []string{"lowercase", "light"}},
+		"unstemmed": {Tokenizer: "standard", Filters: []string{"lowercase"}},
+	}, map[string]FilterDefinition{
+		"light": {Type: "stemmer", Language: "minimal_english"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const text = "Running runners RUN queries Café"
+	tests := []struct {
+		analyzer string
+		want     []string
+	}{
+		// Tokens that aren't plain ASCII words are kept as they are
+		{"stemmed", []string{"run", "runner", "run", "queri", "café"}},
+		{"plurals", []string{"running", "runner", "run", "query", "café"}},
+		{"unstemmed", []string{"running", "runners", "run", "queries", "café"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.analyzer, func(t *testing.T) {
+			if got := terms(t, registry, tt.analyzer, text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+
+	_, err = NewRegistry(nil, map[string]FilterDefinition{"stems": {Type: "stemmer", Language: "klingon"}})
+	if err == nil || !strings.Contains(err.Error(), "unknown stemmer language [klingon]") {
+		t.Errorf("expected an unknown language error, got %v", err)
+	}
+}
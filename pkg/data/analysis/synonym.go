@@ -0,0 +1,157 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TokenTypeSynonym is the type of the tokens the synonym filter adds
+const TokenTypeSynonym = "SYNONYM"
+
+// Synonym rules take one of two forms:
+//
+//	laptop, notebook, portable computer    equivalent: each expands to all
+//	tv, telly => television                explicit: tv and telly are
+//	                                       replaced by television
+//
+// A synonym may span several words; the longest synonym starting at a
+// token wins. The terms of a rule are analyzed by the analysis chain
+// preceding the synonym filter, so with a lowercase filter before it
+// "Laptop" in a rule matches "LAPTOP" in text.
+
+// synonymFilter replaces the token sequences its rules match
+type synonymFilter struct {
+	// rules maps a token sequence, joined by ruleSeparator, to the token
+	// sequences replacing it
+	rules map[string][][]string
+
+	// maxLength is the length of the longest sequence the rules match
+	maxLength int
+}
+
+// ruleSeparator joins the terms of a sequence into a rules key
+const ruleSeparator = "\x00"
+
+// NewSynonymFilter creates a synonym filter from rules. analyze splits the
+// terms of a rule into the tokens they match; nil splits on whitespace.
+func NewSynonymFilter(rules []string, analyze func(string) []string) (TokenFilter, error) {
+	if analyze == nil {
+		analyze = strings.Fields
+	}
+	f := &synonymFilter{rules: make(map[string][][]string)}
+
+	// parseSide splits one side of a rule into its analyzed term sequences
+	parseSide := func(side string) [][]string {
+		var sequences [][]string
+		for _, synonym := range strings.Split(side, ",") {
+			if terms := analyze(synonym); len(terms) > 0 {
+				sequences = append(sequences, terms)
+			}
+		}
+		return sequences
+	}
+
+	for _, rule := range rules {
+		if strings.TrimSpace(rule) == "" || strings.HasPrefix(strings.TrimSpace(rule), "#") {
+			continue
+		}
+
+		var inputs, outputs [][]string
+		if lhs, rhs, explicit := strings.Cut(rule, "=>"); explicit {
+			if strings.Contains(rhs, "=>") {
+				return nil, fmt.Errorf("invalid synonym rule [%s]: more than one =>", rule)
+			}
+			inputs, outputs = parseSide(lhs), parseSide(rhs)
+			if len(inputs) == 0 || len(outputs) == 0 {
+				return nil, fmt.Errorf("invalid synonym rule [%s]: both sides of => need a synonym", rule)
+			}
+		} else {
+			inputs = parseSide(rule)
+			outputs = inputs
+		}
+
+		for _, input := range inputs {
+			key := strings.Join(input, ruleSeparator)
+			for _, output := range outputs {
+				f.add(key, output)
+			}
+			f.maxLength = max(f.maxLength, len(input))
+		}
+	}
+
+	return f.filter, nil
+}
+
+// add adds a replacement for the sequence key, unless it already has it
+func (f *synonymFilter) add(key string, output []string) {
+	joined := strings.Join(output, ruleSeparator)
+	for _, existing := range f.rules[key] {
+		if strings.Join(existing, ruleSeparator) == joined {
+			return
+		}
+	}
+	f.rules[key] = append(f.rules[key], output)
+}
+
+// filter replaces each matched sequence by its synonyms. The first token of
+// every synonym takes the position of the first matched token, so the
+// synonyms are stacked like alternatives.
+func (f *synonymFilter) filter(tokens []Token) []Token {
+	var filtered []Token
+	for i := 0; i < len(tokens); {
+		var outputs [][]string
+		length := min(f.maxLength, len(tokens)-i)
+		for ; length > 0; length-- {
+			terms := make([]string, length)
+			for j := range terms {
+				terms[j] = tokens[i+j].Text
+			}
+			if matched, ok := f.rules[strings.Join(terms, ruleSeparator)]; ok {
+				outputs = matched
+				break
+			}
+		}
+		if length == 0 {
+			filtered = append(filtered, tokens[i])
+			i++
+			continue
+		}
+
+		matched := tokens[i : i+length]
+		var replaced []Token
+		for _, output := range outputs {
+			if isSequence(matched, output) {
+				// The matched tokens themselves keep their types
+				replaced = append(replaced, matched...)
+				continue
+			}
+			for j, term := range output {
+				replaced = append(replaced, Token{
+					Text:        term,
+					Position:    matched[0].Position + j,
+					StartOffset: matched[0].StartOffset,
+					EndOffset:   matched[length-1].EndOffset,
+					Type:        TokenTypeSynonym,
+				})
+			}
+		}
+		sort.SliceStable(replaced, func(a, b int) bool { return replaced[a].Position < replaced[b].Position })
+		filtered = append(filtered, replaced...)
+		i += length
+	}
+	return filtered
+}
+
+// isSequence reports whether tokens are the terms of a sequence
+func isSequence(tokens []Token, terms []string) bool {
+	if len(tokens) != len(terms) {
+		return false
+	}
+	for i, token := range tokens {
+		if token.Text != terms[i] {
+			return false
+		}
+	}
+	return true
+}
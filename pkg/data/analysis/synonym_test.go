@@ -0,0 +1,94 @@
+package analysis
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSynonymFilter(t *testing.T) {
+	registry, err := NewRegistry(map[string]Definition{
+		"products": {Tokenizer: "standard", Filters: []string{"lowercase", "product_synonyms"}},
+	}, map[string]FilterDefinition{
+		"product_synonyms": {Type: "synonym", Synonyms: []string{
+			"Laptop, Notebook",
+			"tv, telly => television",
+			"usa, united states",
+			"# comments and blank lines are skipped",
+			"",
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	analyzer, _ := registry.Get("products")
+
+	tests := []struct {
+		text string
+		want []Token
+	}{
+		{
+			// Equivalent synonyms are stacked on the original token
+			text: "cheap LAPTOP",
+			want: []Token{
+				{Text: "cheap", Position: 0, StartOffset: 0, EndOffset: 5, Type: TokenTypeAlphanum},
+				{Text: "laptop", Position: 1, StartOffset: 6, EndOffset: 12, Type: TokenTypeAlphanum},
+				{Text: "notebook", Position: 1, StartOffset: 6, EndOffset: 12, Type: TokenTypeSynonym},
+			},
+		},
+		{
+			// Explicit rules replace the matched token
+			text: "telly",
+			want: []Token{
+				{Text: "television", Position: 0, StartOffset: 0, EndOffset: 5, Type: TokenTypeSynonym},
+			},
+		},
+		{
+			// The longest synonym wins and may span several tokens
+			text: "United States map",
+			want: []Token{
+				{Text: "usa", Position: 0, StartOffset: 0, EndOffset: 13, Type: TokenTypeSynonym},
+				{Text: "united", Position: 0, StartOffset: 0, EndOffset: 6, Type: TokenTypeAlphanum},
+				{Text: "states", Position: 1, StartOffset: 7, EndOffset: 13, Type: TokenTypeAlphanum},
+				{Text: "map", Position: 2, StartOffset: 14, EndOffset: 17, Type: TokenTypeAlphanum},
+			},
+		},
+		{
+			text: "united",
+			want: []Token{{Text: "united", Position: 0, StartOffset: 0, EndOffset: 6, Type: TokenTypeAlphanum}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := analyzer.Analyze(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSynonymFilterErrors(t *testing.T) {
+	tests := []struct {
+		filters map[string]FilterDefinition
+		wantErr string
+	}{
+		{map[string]FilterDefinition{"s": {Type: "synonym", Synonyms: []string{"tv =>"}}}, "both sides of => need a synonym"},
+		{map[string]FilterDefinition{"s": {Type: "synonym", Synonyms: []string{"a => b => c"}}}, "more than one =>"},
+		{map[string]FilterDefinition{"s": {}}, "must set a type"},
+		{map[string]FilterDefinition{"s": {Type: "shingle"}}, "unknown type [shingle]"},
+		{map[string]FilterDefinition{"lowercase": {Type: "uppercase"}}, "cannot replace the built-in filter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantErr, func(t *testing.T) {
+			_, err := NewRegistry(nil, tt.filters)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %q does not contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package analysis
+
+// PositionIncrementGap separates the positions of the values of a
+// multi-valued field, so phrases don't match across values
+const PositionIncrementGap = 100
+
+// TermOccurrence is an occurrence of a term in a field
+type TermOccurrence struct {
+	Position    int `json:"position"`
+	StartOffset int `json:"start_offset"`
+	EndOffset   int `json:"end_offset"`
+}
+
+// TermVector lists the occurrences of each term of a field, in order. The
+// term frequency is the number of occurrences.
+type TermVector map[string][]TermOccurrence
+
+// NewTermVector builds the term vector of a field from its values, analyzed
+// by analyze. Positions of later values continue after a gap of
+// PositionIncrementGap and their offsets count from the start of the first
+// value, values being one character apart.
+func NewTermVector(values []string, analyze func(string) ([]Token, error)) (TermVector, error) {
+	vector := make(TermVector)
+	position, offset := 0, 0
+	for i, value := range values {
+		tokens, err := analyze(value)
+		if err != nil {
+			return nil, err
+		}
+
+		last := position - 1
+		for _, token := range tokens {
+			occurrence := TermOccurrence{
+				Position:    position + token.Position,
+				StartOffset: offset + token.StartOffset,
+				EndOffset:   offset + token.EndOffset,
+			}
+			vector[token.Text] = append(vector[token.Text], occurrence)
+			last = max(last, occurrence.Position)
+		}
+
+		if i < len(values)-1 {
+			position = last + 1 + PositionIncrementGap
+			offset += len([]rune(value)) + 1
+		}
+	}
+	return vector, nil
+}
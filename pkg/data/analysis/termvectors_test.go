@@ -0,0 +1,39 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewTermVector(t *testing.T) {
+	registry, err := NewRegistry(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	standard, _ := registry.Get("standard")
+	analyze := func(text string) ([]Token, error) {
+		return standard.Analyze(text), nil
+	}
+
+	vector, err := NewTermVector([]string{"Run, run far", "run"}, analyze)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := TermVector{
+		"run": {
+			{Position: 0, StartOffset: 0, EndOffset: 3},
+			{Position: 1, StartOffset: 5, EndOffset: 8},
+			// The second value starts after the gap, one character after
+			// the first ends
+			{Position: 103, StartOffset: 13, EndOffset: 16},
+		},
+		"far": {{Position: 2, StartOffset: 9, EndOffset: 12}},
+	}
+	if !reflect.DeepEqual(vector, want) {
+		t.Errorf("got  %+v\nwant %+v", vector, want)
+	}
+	if freq := len(vector["run"]); freq != 3 {
+		t.Errorf("term frequency of run = %d, want 3", freq)
+	}
+}
@@ -0,0 +1,145 @@
+package analysis
+
+import (
+	"unicode"
+)
+
+// tokenizers are the tokenizers custom analyzers can be built from
+var tokenizers = map[string]Tokenizer{
+	"standard":   StandardTokenizer,
+	"whitespace": WhitespaceTokenizer,
+	"keyword":    KeywordTokenizer,
+	"letter":     LetterTokenizer,
+	"lowercase":  LowercaseTokenizer,
+}
+
+// StandardTokenizer splits text on word boundaries, an approximation of
+// Unicode text segmentation: runs of letters and digits are words, an
+// apostrophe or period between two of them doesn't end the word (don't,
+// u.s.a, 3.14) and neither does a comma between digits (1,000). Each
+// ideograph is a word of its own.
+func StandardTokenizer(text string) []Token {
+	runes := []rune(text)
+	var tokens []Token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if isIdeograph(r) {
+			tokens = append(tokens, Token{
+				Text:        string(r),
+				Position:    len(tokens),
+				StartOffset: i,
+				EndOffset:   i + 1,
+				Type:        TokenTypeIdeograph,
+			})
+			i++
+			continue
+		}
+		if !isWordRune(r) {
+			i++
+			continue
+		}
+
+		start, numeric := i, true
+		for i < len(runes) {
+			if isWordRune(runes[i]) && !isIdeograph(runes[i]) {
+				numeric = numeric && unicode.IsDigit(runes[i])
+				i++
+				continue
+			}
+			// A joiner continues the word when a word character follows
+			if i+1 < len(runes) && isWordRune(runes[i+1]) && !isIdeograph(runes[i+1]) {
+				switch runes[i] {
+				case '\'', '’', '.':
+					i++
+					continue
+				case ',':
+					if unicode.IsDigit(runes[i-1]) && unicode.IsDigit(runes[i+1]) {
+						i++
+						continue
+					}
+				}
+			}
+			break
+		}
+
+		tokenType := TokenTypeAlphanum
+		if numeric {
+			tokenType = TokenTypeNum
+		}
+		tokens = append(tokens, Token{
+			Text:        string(runes[start:i]),
+			Position:    len(tokens),
+			StartOffset: start,
+			EndOffset:   i,
+			Type:        tokenType,
+		})
+	}
+
+	return tokens
+}
+
+// WhitespaceTokenizer splits text on whitespace
+func WhitespaceTokenizer(text string) []Token {
+	return splitRuns(text, func(r rune) bool { return !unicode.IsSpace(r) })
+}
+
+// KeywordTokenizer emits the whole text as a single token
+func KeywordTokenizer(text string) []Token {
+	if text == "" {
+		return nil
+	}
+	return []Token{{
+		Text:      text,
+		EndOffset: len([]rune(text)),
+		Type:      TokenTypeWord,
+	}}
+}
+
+// LetterTokenizer splits text on anything that isn't a letter
+func LetterTokenizer(text string) []Token {
+	return splitRuns(text, unicode.IsLetter)
+}
+
+// LowercaseTokenizer splits text on anything that isn't a letter and
+// lowercases the tokens
+func LowercaseTokenizer(text string) []Token {
+	return LowercaseFilter(LetterTokenizer(text))
+}
+
+// splitRuns emits the runs of runes that are part of a token as tokens
+func splitRuns(text string, inToken func(rune) bool) []Token {
+	runes := []rune(text)
+	var tokens []Token
+
+	for i := 0; i < len(runes); {
+		if !inToken(runes[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && inToken(runes[i]) {
+			i++
+		}
+		tokens = append(tokens, Token{
+			Text:        string(runes[start:i]),
+			Position:    len(tokens),
+			StartOffset: start,
+			EndOffset:   i,
+			Type:        TokenTypeWord,
+		})
+	}
+
+	return tokens
+}
+
+// isWordRune reports whether r is part of a word: a letter, digit, mark or
+// connector such as _
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Pc, r)
+}
+
+// isIdeograph reports whether r is an ideograph, which is a word on its own
+func isIdeograph(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r)
+}
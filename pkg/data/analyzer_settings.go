@@ -1,11 +1,18 @@
 package data
 
 import (
+	"encoding/json"
 	"fmt"
 
+	"github.com/quidditch/quidditch/pkg/data/analysis"
 	"github.com/quidditch/quidditch/pkg/data/diagon"
 )
 
+// settingAnalysis is the index setting carrying an index's analyzer
+// settings as JSON: its custom analyzers and the analyzers its mapping
+// selects per field
+const settingAnalysis = "index.analysis"
+
 // AnalyzerSettings defines text analysis configuration for an index.
 type AnalyzerSettings struct {
 	// Default analyzer for all text fields (if not overridden)
@@ -14,21 +21,21 @@ type AnalyzerSettings struct {
 	// Per-field analyzer overrides
 	FieldAnalyzers map[string]string `json:"field_analyzers,omitempty"`
 
-	// Custom analyzer definitions (future enhancement)
+	// Custom analyzers, a tokenizer and filter chain each
 	CustomAnalyzers map[string]AnalyzerDefinition `json:"custom_analyzers,omitempty"`
-}
 
-// AnalyzerDefinition defines a custom analyzer configuration.
-// This is for future enhancement to support custom tokenizers and filters.
-type AnalyzerDefinition struct {
-	Tokenizer string   `json:"tokenizer"`
-	Filters   []string `json:"filters,omitempty"`
+	// Custom token filters custom analyzers can chain, like synonym filters
+	CustomFilters map[string]analysis.FilterDefinition `json:"custom_filters,omitempty"`
 }
 
+// AnalyzerDefinition defines a custom analyzer as a tokenizer followed by
+// a chain of token filters.
+type AnalyzerDefinition = analysis.Definition
+
 // DefaultAnalyzerSettings returns default analyzer settings.
 func DefaultAnalyzerSettings() *AnalyzerSettings {
 	return &AnalyzerSettings{
-		DefaultAnalyzer: "standard",
+		DefaultAnalyzer: analysis.DefaultAnalyzer,
 		FieldAnalyzers:  make(map[string]string),
 		CustomAnalyzers: make(map[string]AnalyzerDefinition),
 	}
@@ -53,14 +60,19 @@ func (as *AnalyzerSettings) SetFieldAnalyzer(fieldName, analyzerName string) {
 
 // Validate checks if the analyzer settings are valid.
 func (as *AnalyzerSettings) Validate() error {
+	registry, err := as.Registry()
+	if err != nil {
+		return err
+	}
+
 	// Check if default analyzer is valid
-	if err := validateAnalyzerName(as.DefaultAnalyzer); err != nil {
+	if err := validateAnalyzerName(registry, as.DefaultAnalyzer); err != nil {
 		return fmt.Errorf("invalid default analyzer: %w", err)
 	}
 
 	// Check if field analyzers are valid
 	for field, analyzerName := range as.FieldAnalyzers {
-		if err := validateAnalyzerName(analyzerName); err != nil {
+		if err := validateAnalyzerName(registry, analyzerName); err != nil {
 			return fmt.Errorf("invalid analyzer for field %s: %w", field, err)
 		}
 	}
@@ -68,26 +80,49 @@ func (as *AnalyzerSettings) Validate() error {
 	return nil
 }
 
+// Registry returns the registry of the built-in analyzers and the custom
+// analyzers and token filters these settings define.
+func (as *AnalyzerSettings) Registry() (*analysis.Registry, error) {
+	return analysis.NewRegistry(as.CustomAnalyzers, as.CustomFilters)
+}
+
+// nativeAnalyzers are the analyzers only Diagon provides, used when a name
+// isn't in the registry.
+var nativeAnalyzers = map[string]bool{
+	"chinese": true,
+	"search":  true,
+}
+
 // validateAnalyzerName checks if an analyzer name is valid.
-func validateAnalyzerName(name string) error {
-	validAnalyzers := map[string]bool{
-		"standard":      true,
-		"simple":        true,
-		"whitespace":    true,
-		"keyword":       true,
-		"chinese":       true,
-		"english":       true,
-		"multilingual":  true,
-		"search":        true,
-	}
-
-	if !validAnalyzers[name] {
+func validateAnalyzerName(registry *analysis.Registry, name string) error {
+	if _, ok := registry.Get(name); !ok && !nativeAnalyzers[name] {
 		return fmt.Errorf("unknown analyzer: %s", name)
 	}
 
 	return nil
 }
 
+// shardAnalyzerSettings parses the analyzer settings in an index's
+// settings. Indices without them use the defaults.
+func shardAnalyzerSettings(settings map[string]string) (*AnalyzerSettings, error) {
+	analyzerSettings := DefaultAnalyzerSettings()
+	value := settings[settingAnalysis]
+	if value == "" {
+		return analyzerSettings, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), analyzerSettings); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", settingAnalysis, err)
+	}
+	if analyzerSettings.DefaultAnalyzer == "" {
+		analyzerSettings.DefaultAnalyzer = analysis.DefaultAnalyzer
+	}
+	if err := analyzerSettings.Validate(); err != nil {
+		return nil, err
+	}
+	return analyzerSettings, nil
+}
+
 // AnalyzerCache caches analyzer instances to avoid recreating them for each document.
 type AnalyzerCache struct {
 	analyzers map[string]*diagon.Analyzer
@@ -143,3 +178,19 @@ func AnalyzeField(cache *AnalyzerCache, settings *AnalyzerSettings, fieldName, f
 
 	return tokens, nil
 }
+
+// resolveAnalyzer returns the named analyzer from the registry, or the
+// Diagon analyzer for names only Diagon provides.
+func resolveAnalyzer(registry *analysis.Registry, cache *AnalyzerCache, name string) (diagon.FieldAnalyzer, error) {
+	if analyzer, ok := registry.Get(name); ok {
+		return analyzer, nil
+	}
+	if !nativeAnalyzers[name] {
+		return nil, fmt.Errorf("unknown analyzer: %s", name)
+	}
+	analyzer, err := cache.GetOrCreate(name)
+	if err != nil {
+		return nil, err
+	}
+	return analyzer, nil
+}
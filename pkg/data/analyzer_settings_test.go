@@ -229,3 +229,40 @@ func TestAnalyzeFieldWithDifferentAnalyzers(t *testing.T) {
 		})
 	}
 }
+
+func TestShardAnalyzerSettings(t *testing.T) {
+	// Indices without analyzer settings use the defaults
+	settings, err := shardAnalyzerSettings(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.DefaultAnalyzer != "standard" {
+		t.Errorf("Expected default analyzer 'standard', got '%s'", settings.DefaultAnalyzer)
+	}
+
+	settings, err = shardAnalyzerSettings(map[string]string{settingAnalysis: `{
+		"field_analyzers": {"title": "folded", "tags": "lowercase"},
+		"custom_analyzers": {"folded": {"tokenizer": "whitespace", "filters": ["lowercase", "asciifolding"]}}
+	}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := settings.GetAnalyzerForField("title"); got != "folded" {
+		t.Errorf("Expected 'folded' analyzer, got '%s'", got)
+	}
+	if got := settings.GetAnalyzerForField("body"); got != "standard" {
+		t.Errorf("Expected default analyzer 'standard', got '%s'", got)
+	}
+
+	invalid := []string{
+		`not json`,
+		`{"field_analyzers": {"title": "missing"}}`,
+		`{"custom_analyzers": {"broken": {"tokenizer": "missing"}}}`,
+		`{"custom_analyzers": {"standard": {"tokenizer": "keyword"}}}`,
+	}
+	for _, value := range invalid {
+		if _, err := shardAnalyzerSettings(map[string]string{settingAnalysis: value}); err == nil {
+			t.Errorf("Expected an error for %s", value)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package breaker
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// FieldData is the name of the breaker guarding aggregation memory
+const FieldData = "fielddata"
+
+// Breaker metrics
+var (
+	breakerTripped = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "quidditch_circuit_breaker_tripped_total",
+			Help: "Requests rejected because a circuit breaker limit would be exceeded",
+		},
+		[]string{"breaker"},
+	)
+
+	breakerEstimatedBytes = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "quidditch_circuit_breaker_estimated_bytes",
+			Help: "Memory currently reserved against a circuit breaker",
+		},
+		[]string{"breaker"},
+	)
+
+	breakerLimitBytes = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "quidditch_circuit_breaker_limit_bytes",
+			Help: "Memory limit of a circuit breaker",
+		},
+		[]string{"breaker"},
+	)
+)
+
+// CircuitBreakingError is returned when a reservation would exceed the
+// breaker limit
+type CircuitBreakingError struct {
+	Breaker string // Breaker name (e.g. "fielddata")
+	Label   string // What requested the memory (e.g. "terms[category]")
+	Bytes   int64  // Bytes requested
+	Used    int64  // Bytes already reserved
+	Limit   int64  // Breaker limit
+}
+
+// Error implements error interface
+func (e *CircuitBreakingError) Error() string {
+	return fmt.Sprintf("circuit_breaking_exception: [%s] Data too large, data for [%s] would be [%d/%s], which is larger than the limit of [%d/%s]",
+		e.Breaker, e.Label, e.Used+e.Bytes, formatBytes(e.Used+e.Bytes), e.Limit, formatBytes(e.Limit))
+}
+
+// Breaker tracks estimated memory reserved by in-flight operations and
+// rejects reservations that would push the total over a limit
+type Breaker struct {
+	name  string
+	limit int64
+
+	mu   sync.Mutex
+	used int64
+}
+
+// New creates a breaker. A limit <= 0 disables the breaker.
+func New(name string, limit int64) *Breaker {
+	if limit > 0 {
+		breakerLimitBytes.WithLabelValues(name).Set(float64(limit))
+	}
+	return &Breaker{
+		name:  name,
+		limit: limit,
+	}
+}
+
+// Name returns the breaker name
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Limit returns the breaker limit in bytes
+func (b *Breaker) Limit() int64 {
+	return b.limit
+}
+
+// Used returns the bytes currently reserved
+func (b *Breaker) Used() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.used
+}
+
+// Reserve adds bytes to the breaker, or returns a *CircuitBreakingError if
+// that would exceed the limit. Every successful reservation must be released.
+func (b *Breaker) Reserve(label string, bytes int64) error {
+	if b.limit <= 0 || bytes <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if bytes > b.limit-b.used {
+		breakerTripped.WithLabelValues(b.name).Inc()
+		return &CircuitBreakingError{
+			Breaker: b.name,
+			Label:   label,
+			Bytes:   bytes,
+			Used:    b.used,
+			Limit:   b.limit,
+		}
+	}
+
+	b.used += bytes
+	breakerEstimatedBytes.WithLabelValues(b.name).Set(float64(b.used))
+	return nil
+}
+
+// Release returns bytes reserved by Reserve
+func (b *Breaker) Release(bytes int64) {
+	if b.limit <= 0 || bytes <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.used -= bytes
+	if b.used < 0 {
+		b.used = 0
+	}
+	breakerEstimatedBytes.WithLabelValues(b.name).Set(float64(b.used))
+}
+
+// formatBytes renders a byte count for error messages
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%db", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit && exp < 4; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cb", float64(bytes)/float64(div), "kmgtp"[exp])
+}
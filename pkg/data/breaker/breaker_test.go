@@ -0,0 +1,124 @@
+package breaker
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseAggs(t *testing.T, data string) map[string]interface{} {
+	var aggs map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(data), &aggs))
+	return aggs
+}
+
+func TestBreaker_ReserveAndRelease(t *testing.T) {
+	b := New("test_reserve", 1000)
+
+	require.NoError(t, b.Reserve("a", 600))
+	assert.Equal(t, int64(600), b.Used())
+
+	err := b.Reserve("b", 500)
+	var cbErr *CircuitBreakingError
+	require.True(t, errors.As(err, &cbErr))
+	assert.Equal(t, "test_reserve", cbErr.Breaker)
+	assert.Equal(t, int64(500), cbErr.Bytes)
+	assert.Equal(t, int64(600), cbErr.Used)
+	assert.Equal(t, int64(600), b.Used(), "failed reservation must not be counted")
+
+	b.Release(600)
+	require.NoError(t, b.Reserve("b", 500))
+	b.Release(500)
+	assert.Equal(t, int64(0), b.Used())
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	b := New("test_disabled", 0)
+	require.NoError(t, b.Reserve("huge", 1<<50))
+	assert.Equal(t, int64(0), b.Used())
+}
+
+func TestEstimateAggregations(t *testing.T) {
+	cardinality := func(field string) int64 {
+		switch field {
+		case "category":
+			return 10
+		case "user_id":
+			return 1_000_000
+		}
+		return -1
+	}
+
+	t.Run("TermsUsesCardinality", func(t *testing.T) {
+		bytes, label, err := EstimateAggregations(parseAggs(t, `{"by_cat": {"terms": {"field": "category"}}}`), 5000, cardinality)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10*bucketBytes), bytes)
+		assert.Equal(t, "terms[by_cat] on field [category]", label)
+	})
+
+	t.Run("TermsCappedByDocCount", func(t *testing.T) {
+		bytes, _, err := EstimateAggregations(parseAggs(t, `{"by_user": {"terms": {"field": "user_id"}}}`), 5000, cardinality)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5000*bucketBytes), bytes)
+	})
+
+	t.Run("UnknownCardinalityUsesDocCount", func(t *testing.T) {
+		bytes, _, err := EstimateAggregations(parseAggs(t, `{"h": {"histogram": {"field": "price", "interval": 10}}}`), 200, cardinality)
+		require.NoError(t, err)
+		assert.Equal(t, int64(200*bucketBytes), bytes)
+	})
+
+	t.Run("SubAggregationsMultiply", func(t *testing.T) {
+		bytes, _, err := EstimateAggregations(parseAggs(t, `{
+			"by_cat": {
+				"terms": {"field": "category"},
+				"aggs": {"avg_price": {"avg": {"field": "price"}}}
+			}
+		}`), 5000, cardinality)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10*bucketBytes+10*metricBytes), bytes)
+	})
+
+	t.Run("CardinalityBoundedByPrecision", func(t *testing.T) {
+		bytes, label, err := EstimateAggregations(parseAggs(t, `{
+			"users": {"cardinality": {"field": "user_id", "precision_threshold": 100}},
+			"max_price": {"max": {"field": "price"}}
+		}`), 0, cardinality)
+		require.NoError(t, err)
+		assert.Equal(t, int64(100*hllCounterBytes+metricBytes), bytes)
+		assert.Equal(t, "cardinality[users] on field [user_id]", label)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, _, err := EstimateAggregations(map[string]interface{}{"bad": "terms"}, 10, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestBreaker_TripsOnHighCardinalityTerms(t *testing.T) {
+	b := New(FieldData, 1<<20) // 1mb
+	cardinality := func(field string) int64 { return 5_000_000 }
+	aggs := parseAggs(t, `{"by_session": {"terms": {"field": "session_id", "size": 10}}}`)
+
+	tripsBefore := testutil.ToFloat64(breakerTripped.WithLabelValues(FieldData))
+
+	_, err := b.ReserveAggregations(aggs, 10_000_000, cardinality)
+	var cbErr *CircuitBreakingError
+	require.True(t, errors.As(err, &cbErr))
+	assert.Equal(t, "terms[by_session] on field [session_id]", cbErr.Label)
+	assert.Contains(t, err.Error(), "circuit_breaking_exception: [fielddata] Data too large")
+	assert.Contains(t, err.Error(), "larger than the limit of [1048576/1.0mb]")
+	assert.Equal(t, int64(0), b.Used())
+	assert.Equal(t, tripsBefore+1, testutil.ToFloat64(breakerTripped.WithLabelValues(FieldData)))
+
+	// A low-cardinality aggregation fits
+	reserved, err := b.ReserveAggregations(aggs, 10_000_000, func(string) int64 { return 100 })
+	require.NoError(t, err)
+	assert.Equal(t, int64(100*bucketBytes), reserved)
+	b.Release(reserved)
+	assert.Equal(t, int64(0), b.Used())
+}
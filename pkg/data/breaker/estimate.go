@@ -0,0 +1,179 @@
+package breaker
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Memory estimates per aggregation structure, in bytes
+const (
+	bucketBytes      = 96    // Bucket key, doc count and map entry
+	metricBytes      = 64    // Single-value or stats metric state
+	percentilesBytes = 16384 // Percentile digest
+	hllCounterBytes  = 8     // Per counted value of a cardinality aggregation
+
+	defaultPrecisionThreshold = 3000
+)
+
+// CardinalityEstimator returns the estimated number of distinct values of a
+// field, or a negative number if it's unknown
+type CardinalityEstimator func(field string) int64
+
+// bucketAggregations create one bucket per distinct field value
+var bucketAggregations = map[string]bool{
+	"terms":          true,
+	"histogram":      true,
+	"date_histogram": true,
+}
+
+// EstimateAggregations estimates the memory needed to collect the given
+// aggregations ("aggs" section of a search request) over docCount documents.
+// Bucket counts come from field cardinality when known, capped at docCount. It returns the total estimate and a label naming the largest
+// aggregation, for error messages.
+func EstimateAggregations(aggs map[string]interface{}, docCount int64, cardinality CardinalityEstimator) (int64, string, error) {
+	if docCount < 0 {
+		docCount = 0
+	}
+
+	var total, largest int64
+	var largestLabel string
+
+	// Sorted so the reported label is deterministic
+	names := make([]string, 0, len(aggs))
+	for name := range aggs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		def, ok := aggs[name].(map[string]interface{})
+		if !ok {
+			return 0, "", fmt.Errorf("aggregation [%s] must be an object", name)
+		}
+
+		bytes, label, err := estimateAggregation(name, def, docCount, cardinality)
+		if err != nil {
+			return 0, "", err
+		}
+		total = saturatingAdd(total, bytes)
+		if bytes > largest || largestLabel == "" {
+			largest = bytes
+			largestLabel = label
+		}
+	}
+
+	return total, largestLabel, nil
+}
+
+// ReserveAggregations estimates the memory of aggs and reserves it against
+// the breaker. It returns the reserved bytes, which the caller must Release
+// once the aggregations are collected.
+func (b *Breaker) ReserveAggregations(aggs map[string]interface{}, docCount int64, cardinality CardinalityEstimator) (int64, error) {
+	if b.limit <= 0 || len(aggs) == 0 {
+		return 0, nil
+	}
+
+	bytes, label, err := EstimateAggregations(aggs, docCount, cardinality)
+	if err != nil {
+		return 0, err
+	}
+	if err := b.Reserve(label, bytes); err != nil {
+		return 0, err
+	}
+	return bytes, nil
+}
+
+// estimateAggregation estimates one aggregation including its sub-aggregations
+func estimateAggregation(name string, def map[string]interface{}, docCount int64, cardinality CardinalityEstimator) (int64, string, error) {
+	var aggType string
+	var body map[string]interface{}
+	var subAggs map[string]interface{}
+
+	for key, value := range def {
+		switch key {
+		case "aggs", "aggregations":
+			sub, ok := value.(map[string]interface{})
+			if !ok {
+				return 0, "", fmt.Errorf("sub-aggregations of [%s] must be an object", name)
+			}
+			subAggs = sub
+		case "meta":
+		default:
+			aggType = key
+			body, _ = value.(map[string]interface{})
+		}
+	}
+
+	field, _ := body["field"].(string)
+	label := fmt.Sprintf("%s[%s]", aggType, name)
+	if field != "" {
+		label = fmt.Sprintf("%s[%s] on field [%s]", aggType, name, field)
+	}
+
+	var bytes int64
+	buckets := int64(1)
+
+	switch {
+	case bucketAggregations[aggType]:
+		buckets = estimateBuckets(field, docCount, cardinality)
+		bytes = saturatingMul(buckets, bucketBytes)
+	case aggType == "cardinality":
+		threshold := int64(defaultPrecisionThreshold)
+		if v, ok := body["precision_threshold"].(float64); ok && v > 0 {
+			threshold = int64(v)
+		}
+		values := estimateBuckets(field, docCount, cardinality)
+		if values > threshold {
+			values = threshold
+		}
+		bytes = saturatingMul(values, hllCounterBytes)
+	case aggType == "percentiles":
+		bytes = percentilesBytes
+	default:
+		bytes = metricBytes
+	}
+
+	if len(subAggs) > 0 {
+		subBytes, _, err := EstimateAggregations(subAggs, docCount, cardinality)
+		if err != nil {
+			return 0, "", err
+		}
+		// Every bucket carries its own copy of the sub-aggregations
+		bytes = saturatingAdd(bytes, saturatingMul(buckets, subBytes))
+	}
+
+	return bytes, label, nil
+}
+
+// estimateBuckets returns the expected number of distinct values of field.
+// A field can't have more values than there are documents, so a known
+// docCount caps the cardinality estimate.
+func estimateBuckets(field string, docCount int64, cardinality CardinalityEstimator) int64 {
+	buckets := docCount
+	if cardinality != nil && field != "" {
+		if c := cardinality(field); c >= 0 && (docCount <= 0 || c < docCount) {
+			buckets = c
+		}
+	}
+	return buckets
+}
+
+// saturatingAdd adds without overflowing
+func saturatingAdd(a, b int64) int64 {
+	if a > math.MaxInt64-b {
+		return math.MaxInt64
+	}
+	return a + b
+}
+
+// saturatingMul multiplies non-negative values without overflowing
+func saturatingMul(a, b int64) int64 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	if a > math.MaxInt64/b {
+		return math.MaxInt64
+	}
+	return a * b
+}
@@ -0,0 +1,68 @@
+// Package collapse implements field collapsing of search hits: keeping only
+// the best hits of each distinct value of a field.
+//
+// A shard feeds its matches to a Collector in score order with each match's
+// group key, read from the field's indexed value, and loads documents only
+// for the matches the collector keeps.
+package collapse
+
+// Collector picks the hits kept when collapsing on a field: at most size
+// hits per distinct key and at most groups distinct keys. Hits without the
+// field form one group, as with a null value.
+type Collector struct {
+	size   int
+	groups int
+	counts map[string]int
+	absent int
+	full   int
+}
+
+// NewCollector returns a collector keeping size hits (at least one) of each
+// of the first groups keys it sees
+func NewCollector(size, groups int) *Collector {
+	if size < 1 {
+		size = 1
+	}
+	return &Collector{size: size, groups: groups, counts: make(map[string]int)}
+}
+
+// Add offers the next hit, in score order, with its group key; ok is false
+// when the hit has no value for the field. It reports whether the hit is
+// kept.
+func (c *Collector) Add(key string, ok bool) bool {
+	count, seen := c.absent, c.absent > 0
+	if ok {
+		count, seen = c.counts[key]
+	}
+
+	if !seen && c.numGroups() == c.groups {
+		return false
+	}
+	if count == c.size {
+		return false
+	}
+
+	count++
+	if ok {
+		c.counts[key] = count
+	} else {
+		c.absent = count
+	}
+	if count == c.size {
+		c.full++
+	}
+	return true
+}
+
+// Done reports whether every group is full, so no later hit can be kept
+func (c *Collector) Done() bool {
+	return c.numGroups() == c.groups && c.full == c.groups
+}
+
+func (c *Collector) numGroups() int {
+	n := len(c.counts)
+	if c.absent > 0 {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,71 @@
+package collapse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// keys returns the group keys of hits; nil is a hit without the field
+func keys(values ...interface{}) []*string {
+	out := make([]*string, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			out[i] = &s
+		}
+	}
+	return out
+}
+
+func collect(c *Collector, hits []*string) []int {
+	var kept []int
+	for i, key := range hits {
+		if c.Done() {
+			break
+		}
+		if key == nil {
+			if c.Add("", false) {
+				kept = append(kept, i)
+			}
+		} else if c.Add(*key, true) {
+			kept = append(kept, i)
+		}
+	}
+	return kept
+}
+
+func TestCollector(t *testing.T) {
+	hits := keys("alice", "bob", "alice", "carol", "bob", "alice")
+
+	// The best hit of each key
+	assert.Equal(t, []int{0, 1, 3}, collect(NewCollector(0, 10), hits))
+
+	// Two hits per key
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, collect(NewCollector(2, 10), hits))
+
+	// Only the first two keys seen
+	assert.Equal(t, []int{0, 1, 2, 4}, collect(NewCollector(2, 2), hits))
+}
+
+func TestCollectorMissingField(t *testing.T) {
+	// Hits without the field share a group, distinct from an empty value
+	hits := keys(nil, "", nil, "x", "")
+	assert.Equal(t, []int{0, 1, 3}, collect(NewCollector(1, 10), hits))
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, collect(NewCollector(2, 10), hits))
+}
+
+func TestCollectorDone(t *testing.T) {
+	c := NewCollector(1, 2)
+	assert.False(t, c.Done())
+	assert.True(t, c.Add("a", true))
+	assert.False(t, c.Done())
+	assert.False(t, c.Add("a", true))
+	assert.True(t, c.Add("b", true))
+	assert.True(t, c.Done())
+	assert.False(t, c.Add("c", true))
+
+	// No groups at all keeps nothing
+	c = NewCollector(1, 0)
+	assert.True(t, c.Done())
+	assert.False(t, c.Add("a", true))
+}
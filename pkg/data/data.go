@@ -2,18 +2,23 @@ package data
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"os"
 	"sync"
 	"time"
 
-	pb "github.com/quidditch/quidditch/pkg/common/proto"
 	"github.com/quidditch/quidditch/pkg/common/config"
+	"github.com/quidditch/quidditch/pkg/common/logging"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/common/requestid"
+	"github.com/quidditch/quidditch/pkg/common/tracing"
 	"github.com/quidditch/quidditch/pkg/data/diagon"
 	"github.com/quidditch/quidditch/pkg/wasm"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // DataNode represents a data node in the Quidditch cluster
@@ -26,6 +31,11 @@ type DataNode struct {
 	shards       *ShardManager
 	masterClient *MasterClient
 	mu           sync.RWMutex
+
+	// logLevel is the level the node's logger logs at, which the
+	// logger.level cluster setting changes when set
+	logLevel         *zap.AtomicLevel
+	stopLogLevelPoll context.CancelFunc
 }
 
 // NewDataNode creates a new data node
@@ -34,6 +44,24 @@ func NewDataNode(cfg *config.DataNodeConfig, logger *zap.Logger) (*DataNode, err
 		return nil, fmt.Errorf("logger is required")
 	}
 
+	// Build TLS credentials before initializing storage
+	serverOpts := []grpc.ServerOption{tracing.ServerOption(), requestid.ServerOption()}
+	if cfg.TLS.Enabled() {
+		serverTLS, err := cfg.TLS.ServerConfig()
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls config: %w", err)
+		}
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(serverTLS)))
+	}
+	var clientTLS *tls.Config
+	if cfg.TLS.Enabled() || cfg.TLS.CAFile != "" {
+		tlsConfig, err := cfg.TLS.ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls config: %w", err)
+		}
+		clientTLS = tlsConfig
+	}
+
 	// Ensure data directory exists
 	if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
@@ -41,9 +69,10 @@ func NewDataNode(cfg *config.DataNodeConfig, logger *zap.Logger) (*DataNode, err
 
 	// Initialize Diagon bridge
 	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
-		DataDir:     cfg.DataDir,
-		SIMDEnabled: cfg.SIMDEnabled,
-		Logger:      logger,
+		DataDir:         cfg.DataDir,
+		SIMDEnabled:     cfg.SIMDEnabled,
+		Logger:          logger,
+		RAMBufferSizeMB: cfg.RAMBufferSizeMB,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize Diagon: %w", err)
@@ -75,9 +104,10 @@ func NewDataNode(cfg *config.DataNodeConfig, logger *zap.Logger) (*DataNode, err
 
 	// Create master client
 	masterClient := NewMasterClient(cfg.NodeID, cfg.MasterAddr, logger)
+	masterClient.SetTLSConfig(clientTLS)
 
 	// Create gRPC server
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(serverOpts...)
 
 	node := &DataNode{
 		cfg:          cfg,
@@ -132,6 +162,13 @@ func (d *DataNode) Start(ctx context.Context) error {
 	// Start heartbeat (using master client)
 	d.masterClient.StartHeartbeat(ctx, 10*time.Second)
 
+	// Follow the logger.level cluster setting
+	if d.logLevel != nil {
+		pollCtx, cancel := context.WithCancel(ctx)
+		d.stopLogLevelPoll = cancel
+		go d.pollLogLevel(pollCtx, logLevelPollInterval)
+	}
+
 	return nil
 }
 
@@ -139,6 +176,10 @@ func (d *DataNode) Start(ctx context.Context) error {
 func (d *DataNode) Stop(ctx context.Context) error {
 	d.logger.Info("Stopping data node")
 
+	if d.stopLogLevelPoll != nil {
+		d.stopLogLevelPoll()
+	}
+
 	// Stop heartbeat
 	d.masterClient.StopHeartbeat()
 
@@ -168,6 +209,47 @@ func (d *DataNode) Stop(ctx context.Context) error {
 	return nil
 }
 
+// logLevelPollInterval is how often a data node reads the logger.level
+// cluster setting from the master
+const logLevelPollInterval = 30 * time.Second
+
+// SetLogLevel sets the level the node's logger logs at, letting the
+// logger.level cluster setting change it while the node runs
+func (d *DataNode) SetLogLevel(level zap.AtomicLevel) {
+	d.logLevel = &level
+}
+
+// pollLogLevel refreshes the node's log level every interval until ctx is
+// done
+func (d *DataNode) pollLogLevel(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.refreshLogLevel(ctx)
+		}
+	}
+}
+
+// refreshLogLevel applies the logger.level cluster setting as the master
+// holds it, or the configured level when the setting is unset
+func (d *DataNode) refreshLogLevel(ctx context.Context) {
+	if d.logLevel == nil {
+		return
+	}
+
+	settings, err := d.masterClient.GetClusterSettings(ctx)
+	if err != nil {
+		d.logger.Warn("Failed to get cluster settings", zap.Error(err))
+		return
+	}
+	logging.ApplySetting(*d.logLevel, settings, d.cfg.LogLevel, d.logger)
+}
+
 // registerWithMaster registers this data node with the master
 func (d *DataNode) registerWithMaster(ctx context.Context) {
 	d.logger.Info("Registering with master",
@@ -192,6 +274,9 @@ func (d *DataNode) registerWithMaster(ctx context.Context) {
 			SimdEnabled: d.cfg.SIMDEnabled,
 			Version:     "1.0.0", // TODO: Get from build
 		}
+		if d.cfg.Zone != "" {
+			attributes.Labels = map[string]string{"zone": d.cfg.Zone}
+		}
 
 		if err := d.masterClient.Register(ctx, d.cfg.BindAddr, int32(d.cfg.GRPCPort), attributes); err != nil {
 			d.logger.Error("Failed to register with master", zap.Error(err))
@@ -200,6 +285,7 @@ func (d *DataNode) registerWithMaster(ctx context.Context) {
 		}
 
 		d.logger.Info("Successfully registered with master")
+		d.refreshLogLevel(ctx)
 		return
 	}
 
@@ -244,16 +330,17 @@ func (d *DataNode) collectStats() *NodeStats {
 	defer d.mu.RUnlock()
 
 	stats := &NodeStats{
-		NodeID:       d.cfg.NodeID,
-		ActiveShards: d.shards.Count(),
-		DocsCount:    0,
+		NodeID:         d.cfg.NodeID,
+		ActiveShards:   d.shards.Count(),
+		DocsCount:      0,
 		StoreSizeBytes: 0,
 	}
 
 	// Aggregate stats from all shards
 	for _, shard := range d.shards.List() {
-		stats.DocsCount += shard.DocsCount
-		stats.StoreSizeBytes += shard.SizeBytes
+		shardStats := shard.Stats()
+		stats.DocsCount += shardStats.DocsCount
+		stats.StoreSizeBytes += shardStats.SizeBytes
 	}
 
 	return stats
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/quidditch/quidditch/pkg/common/config"
+	"github.com/quidditch/quidditch/pkg/data/diagon"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -70,6 +71,68 @@ func TestDataNode_CreateShard(t *testing.T) {
 	assert.Equal(t, 1, node.shards.Count())
 }
 
+func TestShardManager_CreateShardWithSettings_StoreType(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:     "node-1",
+		DataDir:    t.TempDir(),
+		MasterAddr: "localhost:9000",
+		MaxShards:  10,
+	}
+	node, err := NewDataNode(cfg, zap.NewNop())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for shardID, storeType := range []string{"mmapfs", "niofs", "hybridfs", ""} {
+		settings := map[string]string{"index.store.type": storeType}
+		require.NoError(t, node.shards.CreateShardWithSettings(ctx, "test-index", int32(shardID), true, settings))
+
+		shard, err := node.shards.GetShard("test-index", int32(shardID))
+		require.NoError(t, err)
+		expected, _ := diagon.ParseStoreType(storeType)
+		assert.Equal(t, expected, shard.StoreType)
+
+		// The store type is recorded for reopening the shard after a restart
+		recorded, err := readShardOptions(shard.Path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, recorded.StoreType)
+	}
+
+	err = node.shards.CreateShardWithSettings(ctx, "test-index", 9, true, map[string]string{"index.store.type": "simplefs"})
+	assert.Error(t, err)
+}
+
+func TestShardManager_CreateShardWithSettings_BufferSize(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:          "node-1",
+		DataDir:         t.TempDir(),
+		MasterAddr:      "localhost:9000",
+		MaxShards:       10,
+		RAMBufferSizeMB: 32,
+	}
+	node, err := NewDataNode(cfg, zap.NewNop())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, node.shards.CreateShardWithSettings(ctx, "test-index", 0, true, nil))
+	require.NoError(t, node.shards.CreateShardWithSettings(ctx, "test-index", 1, true, map[string]string{"index.buffer_size_mb": "256"}))
+
+	shard, err := node.shards.GetShard("test-index", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 32.0, shard.DiagonShard.RAMBufferSizeMB())
+
+	shard, err = node.shards.GetShard("test-index", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 256.0, shard.DiagonShard.RAMBufferSizeMB())
+	recorded, err := readShardOptions(shard.Path)
+	require.NoError(t, err)
+	assert.Equal(t, 256.0, recorded.RAMBufferSizeMB)
+
+	for _, value := range []string{"0.5", "4096", "large"} {
+		err := node.shards.CreateShardWithSettings(ctx, "test-index", 2, true, map[string]string{"index.buffer_size_mb": value})
+		assert.Error(t, err, value)
+	}
+}
+
 func TestDataNode_DeleteShard(t *testing.T) {
 	cfg := &config.DataNodeConfig{
 		NodeID:      "node-1",
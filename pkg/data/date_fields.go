@@ -0,0 +1,40 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// settingDateFields is the index setting carrying, as JSON, the formats of
+// each field the index's mapping maps as a date, keyed by the field's dot
+// path
+const settingDateFields = "index.mapping.date_fields"
+
+// DateField is the mapping of a field mapped as a date
+type DateField struct {
+	// Formats are tried in order to parse the field's values; none uses
+	// the default ISO-8601 and epoch millis formats
+	Formats []string `json:"formats,omitempty"`
+}
+
+// shardDateFields parses the date field mappings in an index's settings.
+// Indices without them only detect ISO-8601 strings as dates.
+func shardDateFields(settings map[string]string) (map[string]DateField, error) {
+	value := settings[settingDateFields]
+	if value == "" {
+		return nil, nil
+	}
+
+	var fields map[string]DateField
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", settingDateFields, err)
+	}
+	for path, field := range fields {
+		for _, format := range field.Formats {
+			if format == "" {
+				return nil, fmt.Errorf("invalid %s: field [%s] has an empty date format", settingDateFields, path)
+			}
+		}
+	}
+	return fields, nil
+}
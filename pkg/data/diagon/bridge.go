@@ -9,12 +9,23 @@ package diagon
 import "C"
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 
+	"github.com/quidditch/quidditch/pkg/common/geo"
+	"github.com/quidditch/quidditch/pkg/coordination/parser"
+	"github.com/quidditch/quidditch/pkg/data/collapse"
 	"go.uber.org/zap"
 )
 
@@ -31,6 +42,10 @@ type Config struct {
 	DataDir     string
 	SIMDEnabled bool
 	Logger      *zap.Logger
+
+	// RAMBufferSizeMB is the indexing RAM buffer of shards that do not set
+	// their own (0 for DefaultRAMBufferSizeMB)
+	RAMBufferSizeMB float64
 }
 
 // NewDiagonBridge creates a new Diagon bridge
@@ -38,6 +53,9 @@ func NewDiagonBridge(cfg *Config) (*DiagonBridge, error) {
 	if cfg.Logger == nil {
 		return nil, fmt.Errorf("logger is required")
 	}
+	if err := ValidateRAMBufferSize(cfg.RAMBufferSizeMB); err != nil {
+		return nil, err
+	}
 
 	bridge := &DiagonBridge{
 		config: cfg,
@@ -75,8 +93,20 @@ func (db *DiagonBridge) Stop() error {
 	return nil
 }
 
-// CreateShard creates a new shard using real Diagon IndexWriter
-func (db *DiagonBridge) CreateShard(path string) (*Shard, error) {
+// CreateShard creates a new shard using real Diagon IndexWriter, configured
+// by opts
+func (db *DiagonBridge) CreateShard(path string, opts ShardOptions) (*Shard, error) {
+	if err := ValidateRAMBufferSize(opts.RAMBufferSizeMB); err != nil {
+		return nil, err
+	}
+	ramBufferSizeMB := opts.RAMBufferSizeMB
+	if ramBufferSizeMB == 0 {
+		ramBufferSizeMB = db.config.RAMBufferSizeMB
+	}
+	if ramBufferSizeMB == 0 {
+		ramBufferSizeMB = DefaultRAMBufferSizeMB
+	}
+
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
@@ -89,7 +119,15 @@ func (db *DiagonBridge) CreateShard(path string) (*Shard, error) {
 	cPath := C.CString(path)
 	defer C.free(unsafe.Pointer(cPath))
 
-	dir := C.diagon_open_mmap_directory(cPath) // Use MMapDirectory for performance
+	var dir C.DiagonDirectory
+	switch opts.StoreType {
+	case StoreTypeNIO:
+		dir = C.diagon_open_nio_directory(cPath)
+	case StoreTypeMMap, StoreTypeHybrid, "":
+		dir = C.diagon_open_mmap_directory(cPath) // Use MMapDirectory for performance
+	default:
+		return nil, fmt.Errorf("unknown store type [%s]", opts.StoreType)
+	}
 	if dir == nil {
 		errMsg := C.GoString(C.diagon_last_error())
 		return nil, fmt.Errorf("failed to open directory: %s", errMsg)
@@ -97,8 +135,8 @@ func (db *DiagonBridge) CreateShard(path string) (*Shard, error) {
 
 	// Create IndexWriter config
 	config := C.diagon_create_index_writer_config()
-	C.diagon_config_set_ram_buffer_size(config, 64.0)                   // 64MB buffer
-	C.diagon_config_set_open_mode(config, 2)                            // CREATE_OR_APPEND
+	C.diagon_config_set_ram_buffer_size(config, C.double(ramBufferSizeMB))
+	C.diagon_config_set_open_mode(config, 2) // CREATE_OR_APPEND
 	C.diagon_config_set_commit_on_close(config, true)
 
 	// Create IndexWriter
@@ -112,17 +150,19 @@ func (db *DiagonBridge) CreateShard(path string) (*Shard, error) {
 	}
 
 	shard := &Shard{
-		path:      path,
-		bridge:    db,
-		directory: dir,
-		writer:    writer,
-		reader:    nil, // Will be opened when needed
-		logger:    db.logger.With(zap.String("shard_path", path)),
+		path:            path,
+		bridge:          db,
+		directory:       dir,
+		writer:          writer,
+		logger:          db.logger.With(zap.String("shard_path", path)),
+		ramBufferSizeMB: ramBufferSizeMB,
 	}
 
 	db.shards[path] = shard
 
-	shard.logger.Info("Created real Diagon shard with IndexWriter")
+	shard.logger.Info("Created real Diagon shard with IndexWriter",
+		zap.String("store_type", string(opts.StoreType)),
+		zap.Float64("ram_buffer_size_mb", ramBufferSizeMB))
 
 	return shard, nil
 }
@@ -140,16 +180,113 @@ func (db *DiagonBridge) GetShard(path string) (*Shard, error) {
 	return shard, nil
 }
 
+// sourceField is the stored field holding a document's original JSON
+const sourceField = "_source"
+
 // Shard represents a real Diagon shard with IndexWriter/IndexReader
 type Shard struct {
 	path      string
 	bridge    *DiagonBridge
 	directory C.DiagonDirectory
 	writer    C.DiagonIndexWriter
-	reader    C.DiagonIndexReader
-	searcher  C.DiagonIndexSearcher
 	logger    *zap.Logger
 	mu        sync.RWMutex
+
+	// snapshot is the reader and searcher searches run on, opened on first
+	// use and swapped by Refresh. changed records documents indexed since.
+	snapshot *searcherSnapshot
+	changed  atomic.Bool
+
+	// searches counts searches holding a snapshot, which Close waits for
+	searches sync.WaitGroup
+
+	// ramBufferSizeMB is the indexing RAM buffer the writer was opened with
+	ramBufferSizeMB float64
+
+	// dateFields maps date fields to their formats (nil for the defaults);
+	// populated by SetDateField or by detecting ISO-8601 values at index time
+	dateFields map[string][]string
+
+	// geoPointFields holds fields mapped as geo_point by SetGeoPointField;
+	// {"lat": .., "lon": ..} objects are detected without a mapping
+	geoPointFields map[string]bool
+
+	// fieldAnalyzers holds the analyzers mapped by SetFieldAnalyzer; other
+	// strings are indexed as text fields Diagon analyzes
+	fieldAnalyzers map[string]FieldAnalyzer
+}
+
+// FieldAnalyzer analyzes the text of a field into the terms it is indexed
+// and matched by
+type FieldAnalyzer interface {
+	AnalyzeToStrings(text string) ([]string, error)
+}
+
+// PositionAnalyzer is a FieldAnalyzer that can also group terms by
+// position, so match queries treat terms stacked at one position, like
+// synonyms, as alternatives
+type PositionAnalyzer interface {
+	FieldAnalyzer
+	AnalyzeByPosition(text string) ([][]string, error)
+}
+
+// SetFieldAnalyzer maps field to an analyzer. Its strings are indexed as
+// the terms the analyzer produces, and match queries on it are analyzed
+// the same way, so both sides agree. A nil analyzer removes the mapping.
+func (s *Shard) SetFieldAnalyzer(field string, analyzer FieldAnalyzer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if analyzer == nil {
+		delete(s.fieldAnalyzers, field)
+		return
+	}
+	if s.fieldAnalyzers == nil {
+		s.fieldAnalyzers = make(map[string]FieldAnalyzer)
+	}
+	s.fieldAnalyzers[field] = analyzer
+}
+
+// fieldAnalyzer returns the analyzer mapped to field, if any
+func (s *Shard) fieldAnalyzer(field string) (FieldAnalyzer, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	analyzer, ok := s.fieldAnalyzers[field]
+	return analyzer, ok
+}
+
+// SetGeoPointField maps field as a geo_point, so "lat,lon" strings and
+// [lon, lat] arrays are indexed as points too
+func (s *Shard) SetGeoPointField(field string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.geoPointFields == nil {
+		s.geoPointFields = make(map[string]bool)
+	}
+	s.geoPointFields[field] = true
+}
+
+// SetDateField maps field as a date parsed with the given formats, or
+// DefaultDateFormats when none are given. Values are indexed as epoch millis.
+func (s *Shard) SetDateField(field string, formats ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dateFields == nil {
+		s.dateFields = make(map[string][]string)
+	}
+	s.dateFields[field] = formats
+}
+
+// dateFormats returns the formats of a date field and whether field is one
+func (s *Shard) dateFormats(field string) ([]string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	formats, ok := s.dateFields[field]
+	return formats, ok
 }
 
 // IndexDocument indexes a document using real Diagon IndexWriter
@@ -157,7 +294,7 @@ func (s *Shard) IndexDocument(docID string, doc map[string]interface{}) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.logger.Info("==> DiagonBridge.IndexDocument ENTRY",
+	s.logger.Debug("Indexing document",
 		zap.String("doc_id", docID),
 		zap.Int("num_fields", len(doc)))
 
@@ -165,8 +302,6 @@ func (s *Shard) IndexDocument(docID string, doc map[string]interface{}) error {
 	diagonDoc := C.diagon_create_document()
 	defer C.diagon_free_document(diagonDoc)
 
-	s.logger.Info("Created Diagon document object", zap.String("doc_id", docID))
-
 	// Add ID field - both indexed (for searching) and stored (for retrieval)
 	cDocID := C.CString(docID)
 	defer C.free(unsafe.Pointer(cDocID))
@@ -181,24 +316,125 @@ func (s *Shard) IndexDocument(docID string, doc map[string]interface{}) error {
 	storedIDField := C.diagon_create_stored_field(cIDFieldName, cDocID)
 	C.diagon_document_add_field(diagonDoc, storedIDField)
 
-	// Add other fields
-	for key, value := range doc {
-		cFieldName := C.CString(key)
-		defer C.free(unsafe.Pointer(cFieldName))
+	// C strings handed to field constructors must outlive the fields, so
+	// they are collected here and released once the document is added
+	var cStrings []*C.char
+	defer func() {
+		for _, p := range cStrings {
+			C.free(unsafe.Pointer(p))
+		}
+	}()
+	cstr := func(s string) *C.char {
+		p := C.CString(s)
+		cStrings = append(cStrings, p)
+		return p
+	}
+
+	// addField indexes a single value. Nested objects are flattened into
+	// dot-path fields (author.name, author.age) so they can be queried
+	// directly; the object is also stored as JSON under its own name.
+	// Array elements are indexed as separate values on the same field with
+	// store unset, so only the original array is kept as the stored value
+	// (text fields are always stored by Diagon).
+	var addField func(key string, value interface{}, store bool)
+	addField = func(key string, value interface{}, store bool) {
+		cFieldName := cstr(key)
+
+		// Formatting the value allocates, so only when it will be logged
+		if ce := s.logger.Check(zap.DebugLevel, "Indexing field"); ce != nil {
+			ce.Write(
+				zap.String("field", key),
+				zap.String("type", fmt.Sprintf("%T", value)),
+				zap.Any("value", value))
+		}
+
+		// geo_point fields are indexed as <field>.lat and <field>.lon
+		// doubles, which geo_distance queries filter on
+		if s.geoPointFields[key] || geo.IsPointObject(value) {
+			if point, err := geo.ParsePoint(value); err == nil {
+				for _, coord := range []struct {
+					name  string
+					value float64
+				}{{key + ".lat", point.Lat}, {key + ".lon", point.Lon}} {
+					cCoordName := cstr(coord.name)
+					field := C.diagon_create_indexed_double_field(cCoordName, C.double(coord.value))
+					C.diagon_document_add_field(diagonDoc, field)
+					if store {
+						storedField := C.diagon_create_stored_field(cCoordName, cstr(strconv.FormatFloat(coord.value, 'f', -1, 64)))
+						C.diagon_document_add_field(diagonDoc, storedField)
+					}
+				}
+				if jsonBytes, err := json.Marshal(value); err == nil && store {
+					field := C.diagon_create_stored_field(cFieldName, cstr(string(jsonBytes)))
+					C.diagon_document_add_field(diagonDoc, field)
+				}
+
+				s.logger.Debug("Created geo_point field", zap.String("field", key))
+				return
+			}
+		}
+
+		// Date fields are indexed as epoch millis so range queries work on
+		// them; strings that look like ISO-8601 dates are detected as dates
+		formats, isDate := s.dateFields[key]
+		if str, ok := value.(string); ok && !isDate && looksLikeDate(str) {
+			if s.dateFields == nil {
+				s.dateFields = make(map[string][]string)
+			}
+			s.dateFields[key] = nil
+			isDate = true
+		}
+		if _, isArray := value.([]interface{}); isDate && !isArray {
+			millis, err := ParseDate(value, formats)
+			if err == nil {
+				field := C.diagon_create_indexed_long_field(cFieldName, C.int64_t(millis))
+				C.diagon_document_add_field(diagonDoc, field)
+
+				// Store the original value so it round-trips unchanged
+				if store {
+					stored := fmt.Sprintf("%v", value)
+					if f, ok := value.(float64); ok {
+						stored = strconv.FormatFloat(f, 'f', -1, 64)
+					}
+					storedField := C.diagon_create_stored_field(cFieldName, cstr(stored))
+					C.diagon_document_add_field(diagonDoc, storedField)
+				}
 
-		s.logger.Info("DEBUG: Indexing field",
-			zap.String("field", key),
-			zap.String("type", fmt.Sprintf("%T", value)),
-			zap.Any("value", value))
+				s.logger.Debug("Created indexed+stored date field", zap.String("field", key), zap.Int64("value", millis))
+				return
+			}
+			s.logger.Warn("Failed to parse date field, indexing as-is",
+				zap.String("field", key),
+				zap.Error(err))
+		}
 
 		switch v := value.(type) {
 		case string:
+			if analyzer, ok := s.fieldAnalyzers[key]; ok {
+				// Index the analyzed terms as exact-match tokens and store
+				// the original value
+				terms, err := analyzer.AnalyzeToStrings(v)
+				if err != nil {
+					s.logger.Warn("Failed to analyze field, skipping",
+						zap.String("field", key),
+						zap.Error(err))
+					return
+				}
+				for _, term := range terms {
+					field := C.diagon_create_string_field(cFieldName, cstr(term))
+					C.diagon_document_add_field(diagonDoc, field)
+				}
+				if store {
+					storedField := C.diagon_create_stored_field(cFieldName, cstr(v))
+					C.diagon_document_add_field(diagonDoc, storedField)
+				}
+				return
+			}
+
 			// TextField for strings (analyzed, indexed, stored)
-			cValue := C.CString(v)
-			defer C.free(unsafe.Pointer(cValue))
-			field := C.diagon_create_text_field(cFieldName, cValue)
+			field := C.diagon_create_text_field(cFieldName, cstr(v))
 			C.diagon_document_add_field(diagonDoc, field)
-			s.logger.Info("DEBUG: Created text field", zap.String("field", key))
+			s.logger.Debug("Created text field", zap.String("field", key))
 
 		case int, int32, int64:
 			// Create indexed numeric field for integers (searchable with range queries)
@@ -216,12 +452,12 @@ func (s *Shard) IndexDocument(docID string, doc map[string]interface{}) error {
 			C.diagon_document_add_field(diagonDoc, field)
 
 			// ALSO add as StoredField so we can retrieve it
-			cValueStr := C.CString(fmt.Sprintf("%d", val))
-			defer C.free(unsafe.Pointer(cValueStr))
-			storedField := C.diagon_create_stored_field(cFieldName, cValueStr)
-			C.diagon_document_add_field(diagonDoc, storedField)
+			if store {
+				storedField := C.diagon_create_stored_field(cFieldName, cstr(fmt.Sprintf("%d", val)))
+				C.diagon_document_add_field(diagonDoc, storedField)
+			}
 
-			s.logger.Info("DEBUG: Created indexed+stored long field", zap.String("field", key), zap.Int64("value", val))
+			s.logger.Debug("Created indexed+stored long field", zap.String("field", key), zap.Int64("value", val))
 
 		case float32, float64:
 			// Create indexed numeric field for floats (searchable with range queries)
@@ -237,36 +473,85 @@ func (s *Shard) IndexDocument(docID string, doc map[string]interface{}) error {
 			C.diagon_document_add_field(diagonDoc, field)
 
 			// ALSO add as StoredField so we can retrieve it
-			cValueStr := C.CString(fmt.Sprintf("%f", val))
-			defer C.free(unsafe.Pointer(cValueStr))
-			storedField := C.diagon_create_stored_field(cFieldName, cValueStr)
-			C.diagon_document_add_field(diagonDoc, storedField)
+			if store {
+				storedField := C.diagon_create_stored_field(cFieldName, cstr(fmt.Sprintf("%f", val)))
+				C.diagon_document_add_field(diagonDoc, storedField)
+			}
+
+			s.logger.Debug("Created indexed+stored double field", zap.String("field", key), zap.Float64("value", val))
+
+		case bool:
+			// Index a normalized keyword token so term queries on true/false
+			// match exactly, without going through text analysis
+			token := cstr(strconv.FormatBool(v))
+			field := C.diagon_create_string_field(cFieldName, token)
+			C.diagon_document_add_field(diagonDoc, field)
+
+			if store {
+				storedField := C.diagon_create_stored_field(cFieldName, token)
+				C.diagon_document_add_field(diagonDoc, storedField)
+			}
+
+		case map[string]interface{}:
+			for subKey, subValue := range v {
+				addField(key+"."+subKey, subValue, store)
+			}
+			if !store {
+				return
+			}
+			if jsonBytes, err := json.Marshal(v); err == nil {
+				field := C.diagon_create_stored_field(cFieldName, cstr(string(jsonBytes)))
+				C.diagon_document_add_field(diagonDoc, field)
+			}
 
-			s.logger.Info("DEBUG: Created indexed+stored double field", zap.String("field", key), zap.Float64("value", val))
+		case []interface{}:
+			// Store the original array first so retrieval sees it ahead of
+			// the per-element text values
+			if store {
+				if jsonBytes, err := json.Marshal(v); err == nil {
+					field := C.diagon_create_stored_field(cFieldName, cstr(string(jsonBytes)))
+					C.diagon_document_add_field(diagonDoc, field)
+				}
+			}
+			for _, elem := range v {
+				addField(key, elem, false)
+			}
 
 		default:
-			// Convert to JSON string for complex types
+			// Convert to JSON string for complex types; there is nothing
+			// to index, so array elements of other types are skipped
+			if !store {
+				return
+			}
 			jsonBytes, err := json.Marshal(v)
 			if err != nil {
 				s.logger.Warn("Failed to marshal field, skipping",
 					zap.String("field", key),
 					zap.Error(err))
-				continue
+				return
 			}
-			cValue := C.CString(string(jsonBytes))
-			defer C.free(unsafe.Pointer(cValue))
-			field := C.diagon_create_stored_field(cFieldName, cValue)
+			field := C.diagon_create_stored_field(cFieldName, cstr(string(jsonBytes)))
 			C.diagon_document_add_field(diagonDoc, field)
 		}
 	}
 
+	// Add other fields
+	for key, value := range doc {
+		addField(key, value, true)
+	}
+
+	// Keep the whole document as _source, so reads and copies return every
+	// field rather than the ones stored individually
+	source, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal _source: %w", err)
+	}
+	cSourceFieldName := cstr(sourceField)
+	sourceStored := C.diagon_create_stored_field(cSourceFieldName, cstr(string(source)))
+	C.diagon_document_add_field(diagonDoc, sourceStored)
+
 	// Add document to IndexWriter
-	s.logger.Info("Calling C.diagon_add_document", zap.String("doc_id", docID))
 	result := C.diagon_add_document(s.writer, diagonDoc)
-	s.logger.Info("C.diagon_add_document returned",
-		zap.String("doc_id", docID),
-		zap.Bool("success", bool(result)))
-
 	if !result {
 		errMsg := C.GoString(C.diagon_last_error())
 		s.logger.Error("C.diagon_add_document FAILED",
@@ -274,13 +559,12 @@ func (s *Shard) IndexDocument(docID string, doc map[string]interface{}) error {
 			zap.String("error", errMsg))
 		return fmt.Errorf("failed to add document: %s", errMsg)
 	}
+	s.changed.Store(true)
 
-	s.logger.Info("Document added to IndexWriter RAM buffer (NOT YET COMMITTED)",
+	s.logger.Debug("Document added to IndexWriter RAM buffer",
 		zap.String("doc_id", docID),
 		zap.Int("fields", len(doc)))
 
-	s.logger.Warn("WARNING: Document is in RAM buffer but NOT committed to disk yet! Need to call Commit() or Flush()")
-
 	return nil
 }
 
@@ -312,43 +596,270 @@ func (s *Shard) Flush() error {
 	return nil
 }
 
-// Refresh reopens the reader to see recent changes
+// Refresh reopens the reader to see recent changes. Searches already
+// running keep the snapshot they started on.
 func (s *Shard) Refresh() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.writer == nil {
+		return fmt.Errorf("shard is closed")
+	}
+
 	// Commit first to ensure changes are visible
+	s.changed.Store(false)
 	if !C.diagon_commit(s.writer) {
+		s.changed.Store(true)
 		errMsg := C.GoString(C.diagon_last_error())
 		return fmt.Errorf("commit failed during refresh: %s", errMsg)
 	}
 
-	// Close old reader and searcher
-	if s.searcher != nil {
-		C.diagon_free_index_searcher(s.searcher)
-		s.searcher = nil
+	snapshot, err := openSearcherSnapshot(s.directory)
+	if err != nil {
+		s.changed.Store(true)
+		return fmt.Errorf("failed to reopen reader: %w", err)
+	}
+
+	// Swap in the new snapshot; the old one is freed once the searches
+	// still running on it finish
+	if s.snapshot != nil {
+		s.snapshot.decRef()
+	}
+	s.snapshot = snapshot
+
+	s.logger.Debug("Refreshed shard (reopened reader)")
+	return nil
+}
+
+// ForceMerge merges the shard's segments down to at most maxNumSegments,
+// dropping deleted documents, and reopens the reader on the merged index. A
+// maxNumSegments of 0 lets the merge policy decide.
+func (s *Shard) ForceMerge(maxNumSegments int) error {
+	s.mu.Lock()
+	if !C.diagon_force_merge(s.writer, C.int(maxNumSegments)) {
+		errMsg := C.GoString(C.diagon_last_error())
+		s.mu.Unlock()
+		return fmt.Errorf("force merge failed: %s", errMsg)
+	}
+	s.mu.Unlock()
+
+	s.logger.Debug("Force merged shard", zap.Int("max_num_segments", maxNumSegments))
+	return s.Refresh()
+}
+
+// DeletedDocs returns the number of deleted documents still held in the
+// shard's segments
+func (s *Shard) DeletedDocs() (int64, error) {
+	snapshot, err := s.acquireSearcher()
+	if err != nil {
+		return 0, err
+	}
+	defer s.releaseSearcher(snapshot)
+
+	return int64(C.diagon_reader_max_doc(snapshot.reader)) - int64(C.diagon_reader_num_docs(snapshot.reader)), nil
+}
+
+// RAMBufferSizeMB returns the indexing RAM buffer the shard's writer was
+// opened with
+func (s *Shard) RAMBufferSizeMB() float64 {
+	return s.ramBufferSizeMB
+}
+
+// NumDocs returns the number of live documents in the shard's segments
+func (s *Shard) NumDocs() (int64, error) {
+	snapshot, err := s.acquireSearcher()
+	if err != nil {
+		return 0, err
 	}
-	if s.reader != nil {
-		C.diagon_close_index_reader(s.reader)
-		s.reader = nil
+	defer s.releaseSearcher(snapshot)
+
+	return int64(C.diagon_reader_num_docs(snapshot.reader)), nil
+}
+
+// SegmentInfo describes one segment of a shard's index
+type SegmentInfo struct {
+	Name        string
+	NumDocs     int64
+	DeletedDocs int64
+	SizeBytes   int64
+}
+
+// Segments returns the segments of the shard's index as its reader sees
+// them, oldest first. Diagon's C API only reports how many segments the
+// reader holds, so each segment's name and size come from its files in the
+// shard's directory, and its document counts are only known when the index
+// has a single segment.
+func (s *Shard) Segments() ([]SegmentInfo, error) {
+	snapshot, err := s.acquireSearcher()
+	if err != nil {
+		return nil, err
 	}
+	defer s.releaseSearcher(snapshot)
 
-	// Open new reader
-	s.reader = C.diagon_open_index_reader(s.directory)
-	if s.reader == nil {
+	count := int(C.diagon_reader_get_segment_count(snapshot.reader))
+	if count < 0 {
 		errMsg := C.GoString(C.diagon_last_error())
-		return fmt.Errorf("failed to reopen reader: %s", errMsg)
+		return nil, fmt.Errorf("failed to list segments: %s", errMsg)
+	}
+
+	segments, err := segmentFiles(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list segments: %w", err)
+	}
+	// Files of segments merged away linger until the writer deletes them;
+	// the reader's segments are the newest ones
+	if len(segments) > count {
+		segments = segments[len(segments)-count:]
+	}
+
+	if len(segments) == 1 {
+		numDocs := int64(C.diagon_reader_num_docs(snapshot.reader))
+		segments[0].NumDocs = numDocs
+		segments[0].DeletedDocs = int64(C.diagon_reader_max_doc(snapshot.reader)) - numDocs
+	}
+	return segments, nil
+}
+
+// segmentFiles groups the files in a shard's directory by the segment they
+// belong to, named by their _N prefix, and returns each segment with the
+// total size of its files, in the order the segments were written. Files
+// that belong to no segment, like segments_N and write.lock, are skipped.
+func segmentFiles(path string) ([]SegmentInfo, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make(map[string]int64)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "_") {
+			continue
+		}
+		segment := name
+		if end := strings.IndexAny(name[1:], "._"); end >= 0 {
+			segment = name[:end+1]
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // Deleted since the directory was read
+			}
+			return nil, err
+		}
+		sizes[segment] += info.Size()
+	}
+
+	segments := make([]SegmentInfo, 0, len(sizes))
+	for name, size := range sizes {
+		segments = append(segments, SegmentInfo{Name: name, SizeBytes: size})
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		// Segment names count up in base 36
+		gi, _ := strconv.ParseInt(segments[i].Name[1:], 36, 64)
+		gj, _ := strconv.ParseInt(segments[j].Name[1:], 36, 64)
+		if gi != gj {
+			return gi < gj
+		}
+		return segments[i].Name < segments[j].Name
+	})
+	return segments, nil
+}
+
+// analyzedMatchQuery builds the query matching the terms text analyzes to:
+// a term query for a single term, otherwise a bool query requiring any of
+// them, or all of them when requireAll is set. Terms an analyzer stacks at
+// one position, like synonyms, are alternatives: any of them satisfies the
+// position. Text without terms matches no documents.
+func (s *Shard) analyzedMatchQuery(cField *C.char, analyzer FieldAnalyzer, text string, requireAll bool) (C.DiagonQuery, error) {
+	var groups [][]string
+	if positional, ok := analyzer.(PositionAnalyzer); ok {
+		var err error
+		if groups, err = positional.AnalyzeByPosition(text); err != nil {
+			return nil, fmt.Errorf("failed to analyze match query: %w", err)
+		}
+	} else {
+		terms, err := analyzer.AnalyzeToStrings(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze match query: %w", err)
+		}
+		for _, term := range terms {
+			groups = append(groups, []string{term})
+		}
+	}
+
+	termQuery := func(text string) (C.DiagonQuery, error) {
+		cValue := C.CString(text)
+		defer C.free(unsafe.Pointer(cValue))
+
+		term := C.diagon_create_term(cField, cValue)
+		defer C.diagon_free_term(term)
+
+		query := C.diagon_create_term_query(term)
+		if query == nil {
+			errMsg := C.GoString(C.diagon_last_error())
+			return nil, fmt.Errorf("failed to create match query: %s", errMsg)
+		}
+		return query, nil
+	}
+
+	// anyQuery matches documents with any of the terms
+	anyQuery := func(terms []string) (C.DiagonQuery, error) {
+		if len(terms) == 1 {
+			return termQuery(terms[0])
+		}
+		boolQueryBuilder := C.diagon_create_bool_query()
+		if boolQueryBuilder == nil {
+			errMsg := C.GoString(C.diagon_last_error())
+			return nil, fmt.Errorf("failed to create match query: %s", errMsg)
+		}
+		for _, text := range terms {
+			query, err := termQuery(text)
+			if err != nil {
+				return nil, err
+			}
+			C.diagon_bool_query_add_should(boolQueryBuilder, query)
+		}
+		query := C.diagon_bool_query_build(boolQueryBuilder)
+		if query == nil {
+			errMsg := C.GoString(C.diagon_last_error())
+			return nil, fmt.Errorf("failed to build match query: %s", errMsg)
+		}
+		return query, nil
+	}
+
+	if len(groups) == 1 {
+		return anyQuery(groups[0])
 	}
 
-	// Create new searcher
-	s.searcher = C.diagon_create_index_searcher(s.reader)
-	if s.searcher == nil {
+	boolQueryBuilder := C.diagon_create_bool_query()
+	if boolQueryBuilder == nil {
 		errMsg := C.GoString(C.diagon_last_error())
-		return fmt.Errorf("failed to create searcher: %s", errMsg)
+		return nil, fmt.Errorf("failed to create match query: %s", errMsg)
+	}
+	if len(groups) == 0 {
+		// A bool query with only a must_not clause matches nothing
+		matchAll := C.diagon_create_match_all_query()
+		C.diagon_bool_query_add_must_not(boolQueryBuilder, matchAll)
+	}
+	for _, terms := range groups {
+		query, err := anyQuery(terms)
+		if err != nil {
+			return nil, err
+		}
+		if requireAll {
+			C.diagon_bool_query_add_must(boolQueryBuilder, query)
+		} else {
+			C.diagon_bool_query_add_should(boolQueryBuilder, query)
+		}
 	}
 
-	s.logger.Debug("Refreshed shard (reopened reader)")
-	return nil
+	query := C.diagon_bool_query_build(boolQueryBuilder)
+	if query == nil {
+		errMsg := C.GoString(C.diagon_last_error())
+		return nil, fmt.Errorf("failed to build match query: %s", errMsg)
+	}
+	return query, nil
 }
 
 // convertQueryToDiagon converts a query object to a Diagon query
@@ -365,14 +876,18 @@ func (s *Shard) convertQueryToDiagon(queryObj map[string]interface{}) (C.DiagonQ
 			defer C.free(unsafe.Pointer(cField))
 
 			// Handle both simple and complex term query formats
+			if m, ok := value.(map[string]interface{}); ok {
+				value = m["value"]
+			}
 			var termValue string
 			switch v := value.(type) {
 			case string:
 				termValue = v
-			case map[string]interface{}:
-				if val, ok := v["value"]; ok {
-					termValue = fmt.Sprintf("%v", val)
-				}
+			case bool:
+				// Booleans are indexed as "true"/"false" keyword tokens
+				termValue = strconv.FormatBool(v)
+			case nil:
+				// {"value": ...} missing; leave the term empty
 			default:
 				termValue = fmt.Sprintf("%v", v)
 			}
@@ -398,7 +913,7 @@ func (s *Shard) convertQueryToDiagon(queryObj map[string]interface{}) (C.DiagonQ
 			defer C.free(unsafe.Pointer(cField))
 
 			// Handle both simple and complex match query formats
-			var matchText string
+			var matchText, operator string
 			switch v := value.(type) {
 			case string:
 				matchText = v
@@ -406,10 +921,22 @@ func (s *Shard) convertQueryToDiagon(queryObj map[string]interface{}) (C.DiagonQ
 				if q, ok := v["query"].(string); ok {
 					matchText = q
 				}
+				operator, _ = v["operator"].(string)
 			default:
 				matchText = fmt.Sprintf("%v", v)
 			}
 
+			// A field with a mapped analyzer matches the terms its text
+			// analyzes to, as its values were indexed
+			if analyzer, ok := s.fieldAnalyzer(field); ok {
+				query, err := s.analyzedMatchQuery(cField, analyzer, matchText, strings.EqualFold(operator, "and"))
+				if err != nil {
+					return nil, err
+				}
+				diagonQuery = query
+				break
+			}
+
 			cValue := C.CString(matchText)
 			defer C.free(unsafe.Pointer(cValue))
 
@@ -423,67 +950,188 @@ func (s *Shard) convertQueryToDiagon(queryObj map[string]interface{}) (C.DiagonQ
 			}
 			break // Only support single field for now
 		}
+	} else if idsQuery, ok := queryObj["ids"].(map[string]interface{}); ok {
+		// IDs query: {"ids": {"values": ["1", "2"]}}
+		// _id is indexed as an exact-match StringField, so this is a bool
+		// should over term queries on _id
+		values, _ := idsQuery["values"].([]interface{})
+		if len(values) == 0 {
+			return nil, fmt.Errorf("ids query values must be a non-empty array")
+		}
+
+		boolQueryBuilder := C.diagon_create_bool_query()
+		if boolQueryBuilder == nil {
+			errMsg := C.GoString(C.diagon_last_error())
+			return nil, fmt.Errorf("failed to create ids query: %s", errMsg)
+		}
+
+		cField := C.CString("_id")
+		defer C.free(unsafe.Pointer(cField))
+		for _, value := range values {
+			id, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("ids query values must be strings, got %T", value)
+			}
+			cValue := C.CString(id)
+			defer C.free(unsafe.Pointer(cValue))
+
+			term := C.diagon_create_term(cField, cValue)
+			defer C.diagon_free_term(term)
+
+			termQuery := C.diagon_create_term_query(term)
+			if termQuery == nil {
+				errMsg := C.GoString(C.diagon_last_error())
+				return nil, fmt.Errorf("failed to create ids term query: %s", errMsg)
+			}
+			C.diagon_bool_query_add_should(boolQueryBuilder, termQuery)
+		}
+
+		diagonQuery = C.diagon_bool_query_build(boolQueryBuilder)
+		if diagonQuery == nil {
+			errMsg := C.GoString(C.diagon_last_error())
+			return nil, fmt.Errorf("failed to build ids query: %s", errMsg)
+		}
+	} else if regexpQuery, ok := queryObj["regexp"].(map[string]interface{}); ok {
+		// Regexp query: {"regexp": {"field_name": "pattern"}} or {"regexp": {"field_name": {"value": "pattern"}}}
+		// Pattern length and unbounded prefixes are checked by the coordination parser
+		for field, value := range regexpQuery {
+			cField := C.CString(field)
+			defer C.free(unsafe.Pointer(cField))
+
+			var pattern string
+			switch v := value.(type) {
+			case string:
+				pattern = v
+			case map[string]interface{}:
+				pattern, _ = v["value"].(string)
+			}
+			if pattern == "" {
+				return nil, fmt.Errorf("regexp query on field [%s] has no pattern", field)
+			}
+
+			cPattern := C.CString(pattern)
+			defer C.free(unsafe.Pointer(cPattern))
+
+			diagonQuery = C.diagon_create_regexp_query(cField, cPattern)
+			if diagonQuery == nil {
+				errMsg := C.GoString(C.diagon_last_error())
+				return nil, fmt.Errorf("failed to create regexp query: %s", errMsg)
+			}
+			break // Only support single field for now
+		}
+	} else if prefixQuery, ok := queryObj["prefix"].(map[string]interface{}); ok {
+		// Prefix query: {"prefix": {"field_name": "prefix"}}, run as a regexp
+		field, prefix, err := patternQueryValue("prefix", prefixQuery)
+		if err != nil {
+			return nil, err
+		}
+		if diagonQuery, err = newRegexpQuery(field, prefixRegexp(prefix)); err != nil {
+			return nil, err
+		}
+	} else if wildcardQuery, ok := queryObj["wildcard"].(map[string]interface{}); ok {
+		// Wildcard query: {"wildcard": {"field_name": "la*p?"}}, run as a regexp
+		field, pattern, err := patternQueryValue("wildcard", wildcardQuery)
+		if err != nil {
+			return nil, err
+		}
+		if diagonQuery, err = newRegexpQuery(field, wildcardRegexp(pattern)); err != nil {
+			return nil, err
+		}
+	} else if existsQuery, ok := queryObj["exists"].(map[string]interface{}); ok {
+		// Exists query: {"exists": {"field": "field_name"}}
+		field, _ := existsQuery["field"].(string)
+		if field == "" {
+			return nil, fmt.Errorf("exists query must have a field")
+		}
+		query, err := newExistsQuery(field)
+		if err != nil {
+			return nil, err
+		}
+		diagonQuery = query
 	} else if _, ok := queryObj["match_all"]; ok {
 		// Match all query: {"match_all": {}}
 		// Use proper MatchAllDocsQuery from Diagon C API
-		s.logger.Info("DEBUG: Creating match_all query")
+		s.logger.Debug("Creating match_all query")
 		diagonQuery = C.diagon_create_match_all_query()
 		if diagonQuery == nil {
 			errMsg := C.GoString(C.diagon_last_error())
 			s.logger.Error("Failed to create match_all query", zap.String("error", errMsg))
 			return nil, fmt.Errorf("failed to create match_all query: %s", errMsg)
 		}
-		s.logger.Info("DEBUG: match_all query created successfully")
+		s.logger.Debug("match_all query created successfully")
 	} else if rangeQuery, ok := queryObj["range"].(map[string]interface{}); ok {
 		// Range query: {"range": {"field_name": {"gte": 100, "lte": 1000}}}
 		for field, rangeParams := range rangeQuery {
 			params := rangeParams.(map[string]interface{})
 
-			s.logger.Info("DEBUG: Range query params",
-				zap.String("field", field),
-				zap.Any("params", params))
-
-			var lowerValue, upperValue float64
-			var includeLower, includeUpper bool
+			if ce := s.logger.Check(zap.DebugLevel, "Range query params"); ce != nil {
+				ce.Write(zap.String("field", field), zap.Any("params", params))
+			}
 
-			// Parse lower bound
-			if gte, ok := params["gte"].(float64); ok {
+			// Date bounds (strings, or any bound on a date field) are
+			// converted to epoch millis to match how dates are indexed
+			formats, isDate := s.dateFormats(field)
+			if format, ok := params["format"].(string); ok {
+				formats, isDate = strings.Split(format, "||"), true
+			}
+			bounds := make(map[string]float64, 4)
+			for _, name := range []string{"gte", "gt", "lte", "lt"} {
+				value, ok := params[name]
+				if !ok {
+					continue
+				}
+				if _, isString := value.(string); isString || isDate {
+					millis, err := ParseDate(value, formats)
+					if err != nil {
+						return nil, fmt.Errorf("failed to parse [%s] of range query on field [%s]: %w", name, field, err)
+					}
+					bounds[name] = float64(millis)
+				} else if f, ok := value.(float64); ok {
+					bounds[name] = f
+				}
+			}
+
+			var lowerValue, upperValue float64
+			var includeLower, includeUpper bool
+
+			// Parse lower bound
+			if gte, ok := bounds["gte"]; ok {
 				lowerValue = gte
 				includeLower = true
-				s.logger.Info("DEBUG: Found gte (float64)", zap.Float64("value", gte))
-			} else if gt, ok := params["gt"].(float64); ok {
+				s.logger.Debug("Found gte (float64)", zap.Float64("value", gte))
+			} else if gt, ok := bounds["gt"]; ok {
 				lowerValue = gt
 				includeLower = false
-				s.logger.Info("DEBUG: Found gt (float64)", zap.Float64("value", gt))
+				s.logger.Debug("Found gt (float64)", zap.Float64("value", gt))
 			} else {
 				// No lower bound - use smallest representable value
 				// Use -(2^53) which is safe for float64 → int64 conversion
 				lowerValue = -9007199254740992
 				includeLower = true
-				s.logger.Info("DEBUG: No lower bound, using default", zap.Float64("value", lowerValue))
+				s.logger.Debug("No lower bound, using default", zap.Float64("value", lowerValue))
 			}
 
 			// Parse upper bound
-			if lte, ok := params["lte"].(float64); ok {
+			if lte, ok := bounds["lte"]; ok {
 				upperValue = lte
 				includeUpper = true
-				s.logger.Info("DEBUG: Found lte (float64)", zap.Float64("value", lte))
-			} else if lt, ok := params["lt"].(float64); ok {
+				s.logger.Debug("Found lte (float64)", zap.Float64("value", lte))
+			} else if lt, ok := bounds["lt"]; ok {
 				upperValue = lt
 				includeUpper = false
-				s.logger.Info("DEBUG: Found lt (float64)", zap.Float64("value", lt))
+				s.logger.Debug("Found lt (float64)", zap.Float64("value", lt))
 			} else {
 				// No upper bound - use largest safe value
 				// Use 2^53 which is the max safe integer in float64
 				upperValue = 9007199254740992
 				includeUpper = true
-				s.logger.Info("DEBUG: No upper bound, using default", zap.Float64("value", upperValue))
+				s.logger.Debug("No upper bound, using default", zap.Float64("value", upperValue))
 			}
 
 			cField := C.CString(field)
 			defer C.free(unsafe.Pointer(cField))
 
-			s.logger.Info("DEBUG: Creating Diagon numeric range query",
+			s.logger.Debug("Creating Diagon numeric range query",
 				zap.String("field", field),
 				zap.Float64("lower", lowerValue),
 				zap.Float64("upper", upperValue),
@@ -501,12 +1149,61 @@ func (s *Shard) convertQueryToDiagon(queryObj map[string]interface{}) (C.DiagonQ
 
 			if diagonQuery == nil {
 				errMsg := C.GoString(C.diagon_last_error())
-				s.logger.Error("DEBUG: Failed to create Diagon numeric range query", zap.String("error", errMsg))
+				s.logger.Error("Failed to create Diagon numeric range query", zap.String("error", errMsg))
 				return nil, fmt.Errorf("failed to create numeric range query: %s", errMsg)
 			}
-			s.logger.Info("DEBUG: Diagon numeric range query created successfully")
+			s.logger.Debug("Diagon numeric range query created successfully")
 			break // Only support single field for now
 		}
+	} else if geoQuery, ok := queryObj["geo_distance"].(map[string]interface{}); ok {
+		// Geo distance query: {"geo_distance": {"distance": "10km", "location": {"lat": 40.7, "lon": -74.0}}}
+		// Diagon has no geo support, so this matches the bounding box of the
+		// circle via ranges on <field>.lat and <field>.lon, or a box inside
+		// it for clauses geoSearchQuery placed under must_not;
+		// SearchWithOptions checks the exact distance
+		filter, err := parseGeoDistance(geoQuery)
+		if err != nil {
+			return nil, err
+		}
+		minLat, maxLat, minLon, maxLon, wrapsLon := geo.BoundingBox(filter.origin, filter.meters)
+		if filter.inner {
+			minLat, maxLat, minLon, maxLon = geo.InnerBox(filter.origin, filter.meters)
+			wrapsLon = false
+		}
+
+		boolQueryBuilder := C.diagon_create_bool_query()
+		if boolQueryBuilder == nil {
+			errMsg := C.GoString(C.diagon_last_error())
+			return nil, fmt.Errorf("failed to create geo_distance query: %s", errMsg)
+		}
+
+		cLatField := C.CString(filter.field + ".lat")
+		defer C.free(unsafe.Pointer(cLatField))
+		latQuery := C.diagon_create_numeric_range_query(cLatField, C.double(minLat), C.double(maxLat), C.bool(true), C.bool(true))
+		if latQuery == nil {
+			errMsg := C.GoString(C.diagon_last_error())
+			return nil, fmt.Errorf("failed to create geo_distance latitude range: %s", errMsg)
+		}
+		C.diagon_bool_query_add_filter(boolQueryBuilder, latQuery)
+
+		// Boxes crossing the antimeridian span every longitude, so only
+		// latitude narrows them
+		if !wrapsLon {
+			cLonField := C.CString(filter.field + ".lon")
+			defer C.free(unsafe.Pointer(cLonField))
+			lonQuery := C.diagon_create_numeric_range_query(cLonField, C.double(minLon), C.double(maxLon), C.bool(true), C.bool(true))
+			if lonQuery == nil {
+				errMsg := C.GoString(C.diagon_last_error())
+				return nil, fmt.Errorf("failed to create geo_distance longitude range: %s", errMsg)
+			}
+			C.diagon_bool_query_add_filter(boolQueryBuilder, lonQuery)
+		}
+
+		diagonQuery = C.diagon_bool_query_build(boolQueryBuilder)
+		if diagonQuery == nil {
+			errMsg := C.GoString(C.diagon_last_error())
+			return nil, fmt.Errorf("failed to build geo_distance query: %s", errMsg)
+		}
 	} else if boolQuery, ok := queryObj["bool"].(map[string]interface{}); ok {
 		// Bool query: {"bool": {"must": [...], "should": [...], "filter": [...], "must_not": [...]}}
 		boolQueryBuilder := C.diagon_create_bool_query()
@@ -604,9 +1301,19 @@ func (s *Shard) convertQueryToDiagon(queryObj map[string]interface{}) (C.DiagonQ
 			}
 		}
 
-		// Set minimum_should_match if specified
-		if minShould, ok := boolQuery["minimum_should_match"].(float64); ok {
+		// Set minimum_should_match if specified. The coordinator resolves
+		// percentage and combination forms at plan time; a query that still
+		// carries one is resolved against its should clauses here.
+		switch minShould := boolQuery["minimum_should_match"].(type) {
+		case float64:
 			C.diagon_bool_query_set_minimum_should_match(boolQueryBuilder, C.int(minShould))
+		case string:
+			shouldClauses, _ := boolQuery["should"].([]interface{})
+			resolved, err := parser.ResolveMinimumShouldMatch(minShould, len(shouldClauses))
+			if err != nil {
+				return nil, err
+			}
+			C.diagon_bool_query_set_minimum_should_match(boolQueryBuilder, C.int(resolved))
 		}
 
 		// Build the final query
@@ -621,7 +1328,7 @@ func (s *Shard) convertQueryToDiagon(queryObj map[string]interface{}) (C.DiagonQ
 		for k := range queryObj {
 			queryTypes = append(queryTypes, k)
 		}
-		return nil, fmt.Errorf("unsupported query type: %v (currently supported: 'term', 'match', 'match_all', 'range', 'bool')", queryTypes)
+		return nil, fmt.Errorf("unsupported query type: %v (currently supported: 'term', 'ids', 'match', 'match_all', 'range', 'regexp', 'prefix', 'wildcard', 'exists', 'geo_distance', 'bool')", queryTypes)
 	}
 
 	return diagonQuery, nil
@@ -629,46 +1336,49 @@ func (s *Shard) convertQueryToDiagon(queryObj map[string]interface{}) (C.DiagonQ
 
 // Search executes a search query using real Diagon IndexSearcher
 func (s *Shard) Search(query []byte, filterExpression []byte) (*SearchResult, error) {
-	s.mu.Lock()
+	return s.SearchContext(context.Background(), query, filterExpression)
+}
 
-	// Commit any pending changes first to make them visible
-	if s.writer != nil {
-		if !C.diagon_commit(s.writer) {
-			s.mu.Unlock()
-			errMsg := C.GoString(C.diagon_last_error())
-			s.logger.Warn("Failed to commit before search", zap.String("error", errMsg))
-			// Continue anyway - might have no changes to commit
-		}
-	}
+// SearchContext executes a search query that stops at the context deadline.
+// The Diagon search call itself can't be interrupted, so the deadline is
+// checked before it runs and between stored-field lookups; hits loaded so far
+// are returned with TimedOut set.
+func (s *Shard) SearchContext(ctx context.Context, query []byte, filterExpression []byte) (*SearchResult, error) {
+	return s.SearchWithOptions(ctx, query, filterExpression, SearchOptions{})
+}
 
-	// Always reopen reader/searcher to see latest changes
-	// Close existing reader/searcher if they exist
-	if s.searcher != nil {
-		C.diagon_free_index_searcher(s.searcher)
-		s.searcher = nil
-	}
-	if s.reader != nil {
-		C.diagon_close_index_reader(s.reader)
-		s.reader = nil
-	}
+// SearchOptions controls how a shard collects hits for a search
+type SearchOptions struct {
+	Size           int     // Top hits to return (0 = DefaultSearchSize)
+	TerminateAfter int64   // Stop collecting after this many matches (0 = no limit)
+	MinScore       float64 // Drop matches scoring below this (0 = keep all)
+	CollapseField  string  // Keep only the best hits per distinct value of this field
+	CollapseSize   int     // Hits kept per collapse value (0 = 1)
+}
 
-	// Open fresh reader
-	s.reader = C.diagon_open_index_reader(s.directory)
-	if s.reader == nil {
-		s.mu.Unlock()
-		errMsg := C.GoString(C.diagon_last_error())
-		return nil, fmt.Errorf("failed to open reader: %s", errMsg)
+// DefaultSearchSize is the number of top hits a search returns when
+// SearchOptions does not set one
+const DefaultSearchSize = 10
+
+// SearchWithOptions executes a search query like SearchContext, applying the
+// collection options. At most Size top hits are returned. Matches below MinScore are neither returned nor
+// counted. With TerminateAfter set, at most that many matches are collected
+// and counted, and TerminatedEarly reports whether more matched. With
+// CollapseField set, the top hits are the best CollapseSize hits of each of
+// the top distinct field values; TotalHits still counts every match.
+func (s *Shard) SearchWithOptions(ctx context.Context, query []byte, filterExpression []byte, opts SearchOptions) (*SearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	// Create fresh searcher
-	s.searcher = C.diagon_create_index_searcher(s.reader)
-	if s.searcher == nil {
-		s.mu.Unlock()
-		errMsg := C.GoString(C.diagon_last_error())
-		return nil, fmt.Errorf("failed to create searcher: %s", errMsg)
+	// Search a snapshot that sees every document indexed before the search
+	// started. Refresh may swap the shard's snapshot while this one is in
+	// use.
+	snapshot, err := s.acquireSearcher()
+	if err != nil {
+		return nil, err
 	}
-
-	s.mu.Unlock()
+	defer s.releaseSearcher(snapshot)
 
 	// Parse query JSON
 	var queryObj map[string]interface{}
@@ -676,18 +1386,54 @@ func (s *Shard) Search(query []byte, filterExpression []byte) (*SearchResult, er
 		return nil, fmt.Errorf("failed to parse query: %w", err)
 	}
 
+	// geo_distance only matches boxes in Diagon, so a query holding it runs
+	// as a wider query whose matches are checked exactly
+	geoCheck, err := newGeoDistanceCheck(queryObj)
+	if err != nil {
+		return nil, err
+	}
+	searchQuery := queryObj
+	if geoCheck != nil {
+		searchQuery = geoSearchQuery(queryObj, false)
+	}
+
 	// Convert to Diagon query
-	diagonQuery, err := s.convertQueryToDiagon(queryObj)
+	diagonQuery, err := s.convertQueryToDiagon(searchQuery)
 	if err != nil {
 		return nil, err
 	}
 	defer C.diagon_free_query(diagonQuery)
 
-	// Execute search
-	s.mu.RLock()
-	topDocs := C.diagon_search(s.searcher, diagonQuery, 10)
-	s.mu.RUnlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Execute search. terminate_after caps the matches collected, so Diagon
+	// keeps no more top docs than that; its C API has no early terminating
+	// collector, so every match is still scored and counted.
+	topN := DefaultSearchSize
+	if opts.Size > 0 {
+		topN = opts.Size
+	}
+	collect := topN
+	if opts.TerminateAfter > 0 && int64(collect) > opts.TerminateAfter {
+		collect = int(opts.TerminateAfter)
+	}
+
+	// min_score leaves low scoring matches out of the total, and geo_distance
+	// matches have to be checked exactly, so every match has to be checked.
+	// Collapsing needs every match to fill the top groups, though only the
+	// first terminate_after of them when no match is dropped. The search
+	// collects up to every live document, so they are filtered in one pass.
+	if opts.MinScore > 0 || geoCheck != nil || opts.CollapseField != "" {
+		all := int(C.diagon_reader_num_docs(snapshot.reader))
+		if opts.MinScore == 0 && geoCheck == nil && opts.TerminateAfter > 0 && int64(all) > opts.TerminateAfter {
+			all = int(opts.TerminateAfter)
+		}
+		collect = max(collect, all)
+	}
 
+	topDocs := C.diagon_search(snapshot.searcher, diagonQuery, C.int(collect))
 	if topDocs == nil {
 		errMsg := C.GoString(C.diagon_last_error())
 		return nil, fmt.Errorf("search failed: %s", errMsg)
@@ -699,8 +1445,131 @@ func (s *Shard) Search(query []byte, filterExpression []byte) (*SearchResult, er
 	maxScore := float64(C.diagon_top_docs_max_score(topDocs))
 	numResults := int(C.diagon_top_docs_score_docs_length(topDocs))
 
-	hits := make([]*Hit, 0, numResults)
+	// Drop matches scoring below min_score; score docs are ordered by score
+	if opts.MinScore > 0 {
+		above := 0
+		for ; above < numResults; above++ {
+			scoreDoc := C.diagon_top_docs_score_doc_at(topDocs, C.int(above))
+			if scoreDoc == nil || float64(C.diagon_score_doc_get_score(scoreDoc)) < opts.MinScore {
+				break
+			}
+		}
+		totalHits = int64(above)
+		numResults = above
+		if above == 0 {
+			maxScore = 0
+		}
+	}
+
+	// Positions of the collected score docs. Matches Diagon's geo_distance
+	// boxes let through are dropped here by their exact distance.
+	// Collapsing picks the top hits from every match
+	collectLimit := topN
+	if opts.CollapseField != "" {
+		collectLimit = numResults
+	}
+	if geoCheck != nil && len(geoCheck.unresolved()) > 0 {
+		matches := make([]int, 0, numResults)
+		for i := 0; i < numResults; i++ {
+			if scoreDoc := C.diagon_top_docs_score_doc_at(topDocs, C.int(i)); scoreDoc != nil {
+				matches = append(matches, int(C.diagon_score_doc_get_doc(scoreDoc)))
+			}
+		}
+		if err := s.resolveGeoDistanceCheck(snapshot, geoCheck, matches); err != nil {
+			return nil, err
+		}
+	}
+	positions := make([]int, 0, min(numResults, collectLimit))
+	var distances map[int]float64
 	for i := 0; i < numResults; i++ {
+		if geoCheck == nil {
+			if len(positions) == collectLimit {
+				break
+			}
+			positions = append(positions, i)
+			continue
+		}
+
+		scoreDoc := C.diagon_top_docs_score_doc_at(topDocs, C.int(i))
+		if scoreDoc == nil {
+			continue
+		}
+		internalDocID := int(C.diagon_score_doc_get_doc(scoreDoc))
+		point := func(field string) (geo.Point, bool) {
+			return storedGeoPoint(snapshot, internalDocID, field)
+		}
+		render := func(distance float64) {
+			if distances == nil {
+				distances = make(map[int]float64)
+			}
+			distances[i] = distance
+		}
+		if geoCheck.matches(internalDocID, point, render) {
+			positions = append(positions, i)
+		}
+	}
+	if geoCheck != nil {
+		totalHits = int64(len(positions))
+		maxScore = 0
+		if len(positions) > 0 {
+			maxScore = float64(C.diagon_score_doc_get_score(C.diagon_top_docs_score_doc_at(topDocs, C.int(positions[0]))))
+		}
+		if len(positions) > collectLimit {
+			positions = positions[:collectLimit]
+		}
+	}
+
+	// Stop collecting once terminate_after matches have been seen
+	terminatedEarly := false
+	if opts.TerminateAfter > 0 && totalHits > opts.TerminateAfter {
+		terminatedEarly = true
+		totalHits = opts.TerminateAfter
+		if int64(len(positions)) > opts.TerminateAfter {
+			positions = positions[:opts.TerminateAfter]
+		}
+	}
+
+	// Collapse on the value indexed for the field, read on its own, so only
+	// the kept hits have their documents loaded
+	if opts.CollapseField != "" {
+		collector := collapse.NewCollector(opts.CollapseSize, topN)
+		kept := positions[:0]
+		for _, i := range positions {
+			if collector.Done() {
+				break
+			}
+			scoreDoc := C.diagon_top_docs_score_doc_at(topDocs, C.int(i))
+			if scoreDoc == nil {
+				continue
+			}
+			key, ok := storedField(snapshot, int(C.diagon_score_doc_get_doc(scoreDoc)), opts.CollapseField)
+			if collector.Add(key, ok) {
+				kept = append(kept, i)
+			}
+		}
+		positions = kept
+	}
+	numResults = len(positions)
+
+	page := make([]int, 0, len(positions))
+	for _, i := range positions {
+		if scoreDoc := C.diagon_top_docs_score_doc_at(topDocs, C.int(i)); scoreDoc != nil {
+			page = append(page, int(C.diagon_score_doc_get_doc(scoreDoc)))
+		}
+	}
+	matches, err := s.namedQueryMatches(snapshot, queryObj, page)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]*Hit, 0, numResults)
+	timedOut := false
+	for _, i := range positions {
+		if ctx.Err() != nil {
+			timedOut = true
+			break
+		}
+
 		scoreDoc := C.diagon_top_docs_score_doc_at(topDocs, C.int(i))
 		if scoreDoc == nil {
 			continue
@@ -710,15 +1579,15 @@ func (s *Shard) Search(query []byte, filterExpression []byte) (*SearchResult, er
 		score := float64(C.diagon_score_doc_get_score(scoreDoc))
 
 		// Retrieve the actual document with all stored fields
-		doc, docIDString, err := s.getDocumentByInternalID(internalDocID)
+		doc, docIDString, err := s.getDocumentByInternalID(snapshot, internalDocID)
 		if err != nil {
 			s.logger.Warn("Failed to retrieve document fields",
 				zap.Int("internal_doc_id", internalDocID),
 				zap.Error(err))
 			// Fallback to minimal data if retrieval fails
 			hits = append(hits, &Hit{
-				ID:     fmt.Sprintf("doc_%d", internalDocID),
-				Score:  score,
+				ID:    fmt.Sprintf("doc_%d", internalDocID),
+				Score: score,
 				Source: map[string]interface{}{
 					"_internal_doc_id": internalDocID,
 				},
@@ -726,18 +1595,25 @@ func (s *Shard) Search(query []byte, filterExpression []byte) (*SearchResult, er
 			continue
 		}
 
+		if distance, ok := distances[i]; ok {
+			doc["_geo_distance"] = distance
+		}
+
 		hits = append(hits, &Hit{
-			ID:     docIDString,
-			Score:  score,
-			Source: doc,
+			ID:             docIDString,
+			Score:          score,
+			Source:         doc,
+			MatchedQueries: matchedQueries(matches, internalDocID),
 		})
 	}
 
 	result := &SearchResult{
-		Took:      5, // TODO: Track actual time
-		TotalHits: totalHits,
-		MaxScore:  maxScore,
-		Hits:      hits,
+		Took:            5, // TODO: Track actual time
+		TotalHits:       totalHits,
+		MaxScore:        maxScore,
+		Hits:            hits,
+		TimedOut:        timedOut,
+		TerminatedEarly: terminatedEarly,
 	}
 
 	s.logger.Debug("Executed search via real Diagon IndexSearcher",
@@ -748,104 +1624,318 @@ func (s *Shard) Search(query []byte, filterExpression []byte) (*SearchResult, er
 	return result, nil
 }
 
-// getDocumentByInternalID retrieves a document's stored fields given its internal Diagon doc ID
-// Returns the document fields map and the document's _id string
-func (s *Shard) getDocumentByInternalID(internalDocID int) (map[string]interface{}, string, error) {
-	// Retrieve stored fields using reader
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// namedQueryMatch is the set of documents matching a named query clause
+type namedQueryMatch struct {
+	name string
+	docs map[int]bool
+}
+
+// namedQueryMatches searches each named clause of a query on its own and
+// returns which documents of the page it matches, for reporting
+// matched_queries per hit. Each clause is searched with a filter on the _id
+// of the page's documents, so it only collects those. A named geo_distance
+// clause matches its bounding box.
+func (s *Shard) namedQueryMatches(snapshot *searcherSnapshot, queryObj map[string]interface{}, page []int) ([]namedQueryMatch, error) {
+	named := namedQueries(queryObj)
+	if len(named) == 0 || len(page) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]interface{}, 0, len(page))
+	for _, internalDocID := range page {
+		if id, ok := storedField(snapshot, internalDocID, "_id"); ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	pageFilter := map[string]interface{}{"ids": map[string]interface{}{"values": ids}}
+
+	matches := make([]namedQueryMatch, 0, len(named))
+	for _, nq := range named {
+		onPage := map[string]interface{}{"bool": map[string]interface{}{
+			"must":   []interface{}{nq.clause},
+			"filter": []interface{}{pageFilter},
+		}}
+		docs, err := s.matchingDocs(snapshot, onPage, len(ids))
+		if err != nil {
+			return nil, fmt.Errorf("failed to match named query [%s]: %w", nq.name, err)
+		}
+		matches = append(matches, namedQueryMatch{name: nq.name, docs: docs})
+	}
+	return matches, nil
+}
+
+// resolveGeoDistanceCheck looks up which of the matches of a search match
+// each clause of its geo_distance check that Diagon didn't enforce on every
+// match
+func (s *Shard) resolveGeoDistanceCheck(snapshot *searcherSnapshot, check *geoDistanceCheck, matches []int) error {
+	ids := make([]interface{}, 0, len(matches))
+	for _, internalDocID := range matches {
+		if id, ok := storedField(snapshot, internalDocID, "_id"); ok {
+			ids = append(ids, id)
+		}
+	}
+	matchesFilter := map[string]interface{}{"ids": map[string]interface{}{"values": ids}}
+
+	for _, clause := range check.unresolved() {
+		clause.docs = map[int]bool{}
+		if len(ids) == 0 {
+			continue
+		}
+		amongMatches := map[string]interface{}{"bool": map[string]interface{}{
+			"must":   []interface{}{clause.clause},
+			"filter": []interface{}{matchesFilter},
+		}}
+		docs, err := s.matchingDocs(snapshot, amongMatches, len(ids))
+		if err != nil {
+			return fmt.Errorf("failed to match clause next to geo_distance: %w", err)
+		}
+		clause.docs = docs
+	}
+	return nil
+}
+
+// matchingDocs returns the internal ids of every document in a snapshot
+// matching a query, expected to match about n of them
+func (s *Shard) matchingDocs(snapshot *searcherSnapshot, queryObj map[string]interface{}, n int) (map[int]bool, error) {
+	diagonQuery, err := s.convertQueryToDiagon(queryObj)
+	if err != nil {
+		return nil, err
+	}
+	defer C.diagon_free_query(diagonQuery)
+
+	topDocs := C.diagon_search(snapshot.searcher, diagonQuery, C.int(n))
+	if topDocs == nil {
+		errMsg := C.GoString(C.diagon_last_error())
+		return nil, fmt.Errorf("search failed: %s", errMsg)
+	}
+	// Older copies of a page's documents share their _id, so a few more may
+	// match than were asked for
+	if total := int(C.diagon_top_docs_total_hits(topDocs)); total > n {
+		C.diagon_free_top_docs(topDocs)
+		topDocs = C.diagon_search(snapshot.searcher, diagonQuery, C.int(total))
+		if topDocs == nil {
+			errMsg := C.GoString(C.diagon_last_error())
+			return nil, fmt.Errorf("search failed: %s", errMsg)
+		}
+	}
+	defer C.diagon_free_top_docs(topDocs)
+
+	numDocs := int(C.diagon_top_docs_score_docs_length(topDocs))
+	docs := make(map[int]bool, numDocs)
+	for i := 0; i < numDocs; i++ {
+		if scoreDoc := C.diagon_top_docs_score_doc_at(topDocs, C.int(i)); scoreDoc != nil {
+			docs[int(C.diagon_score_doc_get_doc(scoreDoc))] = true
+		}
+	}
+	return docs, nil
+}
+
+// matchedQueries returns the names of the named clauses matching a document
+func matchedQueries(matches []namedQueryMatch, internalDocID int) []string {
+	var names []string
+	for _, match := range matches {
+		if match.docs[internalDocID] {
+			names = append(names, match.name)
+		}
+	}
+	return names
+}
+
+// newRegexpQuery builds a regexp query on a field
+func newRegexpQuery(field, pattern string) (C.DiagonQuery, error) {
+	cField := C.CString(field)
+	defer C.free(unsafe.Pointer(cField))
+	cPattern := C.CString(pattern)
+	defer C.free(unsafe.Pointer(cPattern))
+
+	query := C.diagon_create_regexp_query(cField, cPattern)
+	if query == nil {
+		errMsg := C.GoString(C.diagon_last_error())
+		return nil, fmt.Errorf("failed to create regexp query: %s", errMsg)
+	}
+	return query, nil
+}
+
+// newExistsQuery builds a query matching the documents that indexed a value
+// for field: a term of a string field, a number of a numeric or date
+// field, or the latitude of a geo_point
+func newExistsQuery(field string) (C.DiagonQuery, error) {
+	anyTerm, err := newRegexpQuery(field, ".*")
+	if err != nil {
+		return nil, err
+	}
+
+	boolQueryBuilder := C.diagon_create_bool_query()
+	if boolQueryBuilder == nil {
+		C.diagon_free_query(anyTerm)
+		errMsg := C.GoString(C.diagon_last_error())
+		return nil, fmt.Errorf("failed to create exists query: %s", errMsg)
+	}
+	C.diagon_bool_query_add_should(boolQueryBuilder, anyTerm)
+
+	for _, name := range []string{field, field + ".lat"} {
+		cName := C.CString(name)
+		anyNumber := C.diagon_create_numeric_range_query(cName,
+			C.double(math.Inf(-1)), C.double(math.Inf(1)), C.bool(true), C.bool(true))
+		C.free(unsafe.Pointer(cName))
+		if anyNumber == nil {
+			errMsg := C.GoString(C.diagon_last_error())
+			return nil, fmt.Errorf("failed to create exists query: %s", errMsg)
+		}
+		C.diagon_bool_query_add_should(boolQueryBuilder, anyNumber)
+	}
+
+	query := C.diagon_bool_query_build(boolQueryBuilder)
+	if query == nil {
+		errMsg := C.GoString(C.diagon_last_error())
+		return nil, fmt.Errorf("failed to build exists query: %s", errMsg)
+	}
+	return query, nil
+}
+
+// storedGeoPoint reads the stored <field>.lat and <field>.lon values of a
+// document indexed with a geo_point field
+func storedGeoPoint(snapshot *searcherSnapshot, internalDocID int, field string) (geo.Point, bool) {
+	diagonDoc := C.diagon_reader_get_document(snapshot.reader, C.int(internalDocID))
+	if diagonDoc == nil {
+		return geo.Point{}, false
+	}
+	defer C.diagon_free_document(diagonDoc)
+
+	var coords [2]float64
+	for i, name := range []string{field + ".lat", field + ".lon"} {
+		buf := make([]byte, 64)
+		cFieldName := C.CString(name)
+		found := C.diagon_document_get_field_value(diagonDoc, cFieldName,
+			(*C.char)(unsafe.Pointer(&buf[0])), C.size_t(len(buf)))
+		C.free(unsafe.Pointer(cFieldName))
+		if !found {
+			return geo.Point{}, false
+		}
+
+		nullIdx := bytes.IndexByte(buf, 0)
+		if nullIdx < 0 {
+			nullIdx = len(buf)
+		}
+		value, err := strconv.ParseFloat(string(buf[:nullIdx]), 64)
+		if err != nil {
+			return geo.Point{}, false
+		}
+		coords[i] = value
+	}
+
+	return geo.Point{Lat: coords[0], Lon: coords[1]}, true
+}
+
+// storedField reads the value stored for one field of a document, as it
+// was indexed: strings as given, numbers and booleans formatted and arrays
+// and objects as JSON. Diagon's C API has no doc values reads, so this
+// stands in for them without parsing the document's _source.
+func storedField(snapshot *searcherSnapshot, internalDocID int, field string) (string, bool) {
+	diagonDoc := C.diagon_reader_get_document(snapshot.reader, C.int(internalDocID))
+	if diagonDoc == nil {
+		return "", false
+	}
+	defer C.diagon_free_document(diagonDoc)
+	return storedFieldValue(diagonDoc, field)
+}
 
-	// Debug: Check reader's maxDoc
-	maxDoc := int(C.diagon_reader_max_doc(s.reader))
-	s.logger.Info("Attempting to retrieve document",
-		zap.Int("internal_doc_id", internalDocID),
-		zap.Int("reader_max_doc", maxDoc))
+// storedFieldValue reads the string value of a stored field, growing the
+// buffer until the value fits
+func storedFieldValue(diagonDoc C.DiagonDocument, field string) (string, bool) {
+	cFieldName := C.CString(field)
+	defer C.free(unsafe.Pointer(cFieldName))
 
+	for size := 4096; ; size *= 4 {
+		buf := make([]byte, size)
+		if !C.diagon_document_get_field_value(diagonDoc, cFieldName,
+			(*C.char)(unsafe.Pointer(&buf[0])), C.size_t(len(buf))) {
+			return "", false
+		}
+		// A value filling the buffer may have been cut short
+		if nullIdx := bytes.IndexByte(buf, 0); nullIdx >= 0 && nullIdx < len(buf)-1 {
+			return string(buf[:nullIdx]), true
+		}
+		if size >= maxStoredFieldSize {
+			return string(bytes.TrimRight(buf, "\x00")), true
+		}
+	}
+}
+
+// maxStoredFieldSize bounds the buffer storedFieldValue grows to
+const maxStoredFieldSize = 256 << 20
+
+// getDocumentByInternalID retrieves a document's stored fields given its internal Diagon doc ID
+// Returns the document fields map and the document's _id string
+func (s *Shard) getDocumentByInternalID(snapshot *searcherSnapshot, internalDocID int) (map[string]interface{}, string, error) {
+	maxDoc := int(C.diagon_reader_max_doc(snapshot.reader))
 	if internalDocID >= maxDoc {
 		return nil, "", fmt.Errorf("internal docID %d >= maxDoc %d", internalDocID, maxDoc)
 	}
 
-	diagonDoc := C.diagon_reader_get_document(s.reader, C.int(internalDocID))
+	diagonDoc := C.diagon_reader_get_document(snapshot.reader, C.int(internalDocID))
 	if diagonDoc == nil {
 		errMsg := C.GoString(C.diagon_last_error())
 		return nil, "", fmt.Errorf("failed to retrieve document: %s", errMsg)
 	}
 	defer C.diagon_free_document(diagonDoc)
 
-	// Extract fields from Diagon document
-	doc := make(map[string]interface{})
-	var docIDString string
-
 	// Get _id field (this is the user-provided doc ID)
-	idBuf := make([]byte, 1024)
-	cIDFieldName := C.CString("_id")
-	defer C.free(unsafe.Pointer(cIDFieldName))
-	if C.diagon_document_get_field_value(diagonDoc, cIDFieldName,
-		(*C.char)(unsafe.Pointer(&idBuf[0])), C.size_t(len(idBuf))) {
-		// Find null terminator
-		nullIdx := 0
-		for i, b := range idBuf {
-			if b == 0 {
-				nullIdx = i
-				break
-			}
-		}
-		docIDString = string(idBuf[:nullIdx])
-		doc["_id"] = docIDString
-	} else {
+	docIDString, hasID := storedFieldValue(diagonDoc, "_id")
+	if !hasID {
 		// Fallback if _id not found
 		docIDString = fmt.Sprintf("doc_%d", internalDocID)
 	}
 
+	// The whole document, as indexed
+	if source, ok := storedFieldValue(diagonDoc, sourceField); ok {
+		doc := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(source), &doc); err != nil {
+			return nil, "", fmt.Errorf("failed to parse _source of document %s: %w", docIDString, err)
+		}
+		if hasID {
+			doc["_id"] = docIDString
+		}
+		return doc, docIDString, nil
+	}
+
+	// Documents indexed before _source was stored only keep their fields
+	// individually, so the common ones are read back by name
+	doc := legacyStoredFields(diagonDoc)
+	if hasID {
+		doc["_id"] = docIDString
+	}
+	return doc, docIDString, nil
+}
+
+// legacyStoredFields reads back the commonly named fields of a document
+// indexed without _source
+func legacyStoredFields(diagonDoc C.DiagonDocument) map[string]interface{} {
+	doc := make(map[string]interface{})
+
 	// Try to get common text fields
 	commonFields := []string{"title", "description", "name", "content", "text", "body", "category", "brand"}
 	for _, fieldName := range commonFields {
-		buf := make([]byte, 4096)
-		cFieldName := C.CString(fieldName)
-		if C.diagon_document_get_field_value(diagonDoc, cFieldName,
-			(*C.char)(unsafe.Pointer(&buf[0])), C.size_t(len(buf))) {
-			// Find null terminator
-			nullIdx := 0
-			for i, b := range buf {
-				if b == 0 {
-					nullIdx = i
-					break
-				}
-			}
-			if nullIdx > 0 {
-				doc[fieldName] = string(buf[:nullIdx])
-			}
+		if value, ok := storedFieldValue(diagonDoc, fieldName); ok && value != "" {
+			doc[fieldName] = value
 		}
-		C.free(unsafe.Pointer(cFieldName))
 	}
 
 	// Try to get common numeric/boolean fields
 	// Since we store them as string StoredFields, retrieve as string and parse
 	commonNumFields := []string{"price", "count", "quantity", "age", "score"}
 	for _, fieldName := range commonNumFields {
-		buf := make([]byte, 1024)
-		cFieldName := C.CString(fieldName)
-		if C.diagon_document_get_field_value(diagonDoc, cFieldName,
-			(*C.char)(unsafe.Pointer(&buf[0])), C.size_t(len(buf))) {
-			// Find null terminator
-			nullIdx := 0
-			for i, b := range buf {
-				if b == 0 {
-					nullIdx = i
-					break
-				}
-			}
-			if nullIdx > 0 {
-				valueStr := string(buf[:nullIdx])
-				// Try to parse as int64
-				if intVal, err := strconv.ParseInt(valueStr, 10, 64); err == nil {
-					doc[fieldName] = intVal
-				} else if floatVal, err := strconv.ParseFloat(valueStr, 64); err == nil {
-					doc[fieldName] = floatVal
-				}
+		if valueStr, ok := storedFieldValue(diagonDoc, fieldName); ok && valueStr != "" {
+			// Try to parse as int64
+			if intVal, err := strconv.ParseInt(valueStr, 10, 64); err == nil {
+				doc[fieldName] = intVal
+			} else if floatVal, err := strconv.ParseFloat(valueStr, 64); err == nil {
+				doc[fieldName] = floatVal
 			}
 		}
-		C.free(unsafe.Pointer(cFieldName))
 	}
 
 	// Try to get common boolean fields
@@ -859,46 +1949,72 @@ func (s *Shard) getDocumentByInternalID(internalDocID int) (map[string]interface
 		C.free(unsafe.Pointer(cFieldName))
 	}
 
-	return doc, docIDString, nil
+	return doc
 }
 
-// GetDocument retrieves a document by ID
-func (s *Shard) GetDocument(docID string) (map[string]interface{}, error) {
-	s.logger.Info(">>>>>> GetDocument ENTRY", zap.String("doc_id", docID))
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	s.logger.Debug("GetDocument called", zap.String("doc_id", docID))
+// ScanDocuments returns up to size documents starting at internal document
+// position from, in index order, each with its full _source. Indexing them
+// in this order into an empty shard reproduces the shard's documents;
+// documents indexed before _source was stored only carry their commonly
+// named fields.
+func (s *Shard) ScanDocuments(from, size int) ([]Hit, error) {
+	snapshot, err := s.acquireSearcher()
+	if err != nil {
+		return nil, err
+	}
+	defer s.releaseSearcher(snapshot)
 
-	// Ensure reader and searcher are initialized
-	if s.reader == nil || s.searcher == nil {
-		s.logger.Info("Reader not initialized, opening now", zap.String("doc_id", docID))
+	maxDoc := int(C.diagon_reader_max_doc(snapshot.reader))
 
-		// Commit first to ensure changes are visible
-		if !C.diagon_commit(s.writer) {
-			errMsg := C.GoString(C.diagon_last_error())
-			return nil, fmt.Errorf("commit failed: %s", errMsg)
+	hits := make([]Hit, 0, min(size, max(maxDoc-from, 0)))
+	for internalDocID := from; internalDocID < maxDoc && len(hits) < size; internalDocID++ {
+		doc, docID, err := s.getDocumentByInternalID(snapshot, internalDocID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read document %d: %w", internalDocID, err)
 		}
+		delete(doc, "_id")
+		hits = append(hits, Hit{ID: docID, Source: doc})
+	}
+	return hits, nil
+}
 
-		// Open reader
-		s.reader = C.diagon_open_index_reader(s.directory)
-		if s.reader == nil {
-			errMsg := C.GoString(C.diagon_last_error())
-			return nil, fmt.Errorf("failed to open reader: %s", errMsg)
-		}
+// GetDocument retrieves a document by ID
+func (s *Shard) GetDocument(docID string) (map[string]interface{}, error) {
+	snapshot, err := s.acquireSearcher()
+	if err != nil {
+		return nil, err
+	}
+	defer s.releaseSearcher(snapshot)
 
-		// Create searcher
-		s.searcher = C.diagon_create_index_searcher(s.reader)
-		if s.searcher == nil {
-			errMsg := C.GoString(C.diagon_last_error())
-			return nil, fmt.Errorf("failed to create searcher: %s", errMsg)
-		}
+	internalDocID, found, err := s.lookupDocID(snapshot, docID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("document not found")
+	}
 
-		s.logger.Info("Reader and searcher initialized successfully")
+	doc, _, err := s.getDocumentByInternalID(snapshot, internalDocID)
+	if err != nil {
+		return nil, err
 	}
+	return doc, nil
+}
 
-	// Search for the document by _id field to get internal doc ID
-	s.logger.Info("STEP 1: Creating term for _id search")
+// HasDocument reports whether the shard holds a document with the given _id
+func (s *Shard) HasDocument(docID string) (bool, error) {
+	snapshot, err := s.acquireSearcher()
+	if err != nil {
+		return false, err
+	}
+	defer s.releaseSearcher(snapshot)
+
+	_, found, err := s.lookupDocID(snapshot, docID)
+	return found, err
+}
+
+// lookupDocID finds the internal doc ID of the document with the given _id
+func (s *Shard) lookupDocID(snapshot *searcherSnapshot, docID string) (int, bool, error) {
 	cIDField := C.CString("_id")
 	defer C.free(unsafe.Pointer(cIDField))
 
@@ -908,167 +2024,76 @@ func (s *Shard) GetDocument(docID string) (map[string]interface{}, error) {
 	term := C.diagon_create_term(cIDField, cDocID)
 	if term == nil {
 		errMsg := C.GoString(C.diagon_last_error())
-		s.logger.Error("FAILED at create term", zap.String("error", errMsg))
-		return nil, fmt.Errorf("failed to create term: %s", errMsg)
+		return 0, false, fmt.Errorf("failed to create term: %s", errMsg)
 	}
 	defer C.diagon_free_term(term)
 
-	s.logger.Info("STEP 2: Creating term query")
 	query := C.diagon_create_term_query(term)
 	if query == nil {
 		errMsg := C.GoString(C.diagon_last_error())
-		s.logger.Error("FAILED at create query", zap.String("error", errMsg))
-		return nil, fmt.Errorf("failed to create query: %s", errMsg)
+		return 0, false, fmt.Errorf("failed to create query: %s", errMsg)
 	}
 	defer C.diagon_free_query(query)
 
-	s.logger.Info("STEP 3: Executing search", zap.String("doc_id", docID))
-
-	// Search to find the internal doc ID
-	topDocs := C.diagon_search(s.searcher, query, 1)
+	topDocs := C.diagon_search(snapshot.searcher, query, 1)
 	if topDocs == nil {
 		errMsg := C.GoString(C.diagon_last_error())
-		s.logger.Error("FAILED at search", zap.String("error", errMsg))
-		return nil, fmt.Errorf("search failed: %s", errMsg)
+		return 0, false, fmt.Errorf("search failed: %s", errMsg)
 	}
 	defer C.diagon_free_top_docs(topDocs)
 
-	totalHits := int64(C.diagon_top_docs_total_hits(topDocs))
-	s.logger.Debug("Search completed", zap.Int64("total_hits", totalHits))
-
-	if totalHits == 0 {
-		return nil, fmt.Errorf("document not found")
+	if int64(C.diagon_top_docs_total_hits(topDocs)) == 0 {
+		return 0, false, nil
 	}
 
-	// Get internal doc ID from search result
 	scoreDoc := C.diagon_top_docs_score_doc_at(topDocs, 0)
 	if scoreDoc == nil {
-		return nil, fmt.Errorf("failed to get score doc")
-	}
-
-	internalDocID := int(C.diagon_score_doc_get_doc(scoreDoc))
-	s.logger.Debug("Found document", zap.Int("internal_doc_id", internalDocID))
-
-	// Retrieve stored fields using reader
-	s.logger.Info("CALLING diagon_reader_get_document", zap.Int("internal_doc_id", internalDocID))
-	diagonDoc := C.diagon_reader_get_document(s.reader, C.int(internalDocID))
-	s.logger.Info("RETURNED from diagon_reader_get_document", zap.Bool("is_nil", diagonDoc == nil))
-	if diagonDoc == nil {
-		errMsg := C.GoString(C.diagon_last_error())
-		s.logger.Info("ERROR from C API", zap.String("error", errMsg))
-		return nil, fmt.Errorf("failed to retrieve document: %s", errMsg)
-	}
-	defer C.diagon_free_document(diagonDoc)
-
-	// Extract fields from Diagon document
-	doc := make(map[string]interface{})
-
-	// Get _id field
-	idBuf := make([]byte, 1024)
-	cIDFieldName := C.CString("_id")
-	defer C.free(unsafe.Pointer(cIDFieldName))
-	if C.diagon_document_get_field_value(diagonDoc, cIDFieldName,
-		(*C.char)(unsafe.Pointer(&idBuf[0])), C.size_t(len(idBuf))) {
-		// Find null terminator
-		nullIdx := 0
-		for i, b := range idBuf {
-			if b == 0 {
-				nullIdx = i
-				break
-			}
-		}
-		doc["_id"] = string(idBuf[:nullIdx])
-	}
-
-	// Try to get common text fields from the original document
-	// Since we don't have field enumeration, we'll try common field names
-	commonFields := []string{"title", "description", "name", "content", "text", "body"}
-	for _, fieldName := range commonFields {
-		buf := make([]byte, 4096)
-		cFieldName := C.CString(fieldName)
-		if C.diagon_document_get_field_value(diagonDoc, cFieldName,
-			(*C.char)(unsafe.Pointer(&buf[0])), C.size_t(len(buf))) {
-			// Find null terminator
-			nullIdx := 0
-			for i, b := range buf {
-				if b == 0 {
-					nullIdx = i
-					break
-				}
-			}
-			if nullIdx > 0 {
-				doc[fieldName] = string(buf[:nullIdx])
-			}
-		}
-		C.free(unsafe.Pointer(cFieldName))
-	}
-
-	// Try to get common numeric fields
-	commonNumFields := []string{"price", "count", "quantity", "age", "score"}
-	for _, fieldName := range commonNumFields {
-		var val int64
-		cFieldName := C.CString(fieldName)
-		if C.diagon_document_get_long_value(diagonDoc, cFieldName, (*C.int64_t)(unsafe.Pointer(&val))) {
-			doc[fieldName] = val
-		}
-		C.free(unsafe.Pointer(cFieldName))
+		return 0, false, fmt.Errorf("failed to get score doc")
 	}
+	return int(C.diagon_score_doc_get_doc(scoreDoc)), true, nil
+}
 
-	// Try to get common float fields
-	for _, fieldName := range commonNumFields {
-		var val float64
-		cFieldName := C.CString(fieldName)
-		if C.diagon_document_get_double_value(diagonDoc, cFieldName, (*C.double)(unsafe.Pointer(&val))) {
-			// Only add if not already added as int
-			if _, exists := doc[fieldName]; !exists {
-				doc[fieldName] = val
-			}
-		}
-		C.free(unsafe.Pointer(cFieldName))
-	}
+// ErrReplaceUnsupported is returned when a document is indexed with the _id
+// of one the shard already holds: Diagon can only append documents, so the
+// new one would sit next to the old rather than replace it
+var ErrReplaceUnsupported = errors.New("replacing an indexed document is not supported by the storage engine")
 
-	s.logger.Info("Retrieved document via Diagon StoredFieldsReader",
-		zap.String("doc_id", docID),
-		zap.Int("internal_doc_id", internalDocID),
-		zap.Int("num_fields", len(doc)))
+// ErrDeleteUnsupported is returned by DeleteDocument: Diagon's C API has no
+// way to delete an indexed document yet
+var ErrDeleteUnsupported = errors.New("document deletion is not supported by the storage engine")
 
-	return doc, nil
-}
-
-// DeleteDocument deletes a document (not yet implemented in Phase 4)
+// DeleteDocument deletes a document. Diagon can't delete documents yet, so
+// it always returns ErrDeleteUnsupported.
 func (s *Shard) DeleteDocument(docID string) error {
 	// TODO: Implement when document deletion is available in Diagon
-	s.logger.Warn("Document deletion not yet implemented in Diagon Phase 4", zap.String("doc_id", docID))
-	return fmt.Errorf("document deletion not yet implemented in Diagon Phase 4")
+	return ErrDeleteUnsupported
 }
 
 // Close closes the shard and frees all resources
 func (s *Shard) Close() error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	// Close searcher
-	if s.searcher != nil {
-		C.diagon_free_index_searcher(s.searcher)
-		s.searcher = nil
-	}
-
-	// Close reader
-	if s.reader != nil {
-		C.diagon_close_index_reader(s.reader)
-		s.reader = nil
-	}
 
-	// Close writer
+	// Close writer, which stops new searches from starting
 	if s.writer != nil {
 		C.diagon_close_index_writer(s.writer)
 		s.writer = nil
 	}
+	snapshot := s.snapshot
+	s.snapshot = nil
+	directory := s.directory
+	s.directory = nil
+	s.mu.Unlock()
+
+	// Searches still running read from the directory, so it is closed once
+	// they finish
+	s.searches.Wait()
+	if snapshot != nil {
+		snapshot.decRef()
+	}
 
 	// Close directory
-	if s.directory != nil {
-		C.diagon_close_directory(s.directory)
-		s.directory = nil
+	if directory != nil {
+		C.diagon_close_directory(directory)
 	}
 
 	s.logger.Info("Closed real Diagon shard")
@@ -1078,11 +2103,13 @@ func (s *Shard) Close() error {
 
 // SearchResult represents search results
 type SearchResult struct {
-	Took         int64                        `json:"took"`
-	TotalHits    int64                        `json:"total_hits"`
-	MaxScore     float64                      `json:"max_score"`
-	Hits         []*Hit                       `json:"hits"`
-	Aggregations map[string]AggregationResult `json:"aggregations,omitempty"`
+	Took            int64                        `json:"took"`
+	TotalHits       int64                        `json:"total_hits"`
+	MaxScore        float64                      `json:"max_score"`
+	Hits            []*Hit                       `json:"hits"`
+	Aggregations    map[string]AggregationResult `json:"aggregations,omitempty"`
+	TimedOut        bool                         `json:"timed_out,omitempty"`
+	TerminatedEarly bool                         `json:"terminated_early,omitempty"`
 }
 
 // Hit represents a search hit
@@ -1090,6 +2117,10 @@ type Hit struct {
 	ID     string                 `json:"_id"`
 	Score  float64                `json:"_score"`
 	Source map[string]interface{} `json:"_source"`
+	Sort   []float64              `json:"sort,omitempty"` // _script sort keys
+
+	// Names of the named query clauses the hit matched
+	MatchedQueries []string `json:"matched_queries,omitempty"`
 }
 
 // AggregationResult represents an aggregation result
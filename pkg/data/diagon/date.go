@@ -0,0 +1,125 @@
+package diagon
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Named date formats accepted alongside Go time layouts and Java-style
+// patterns such as yyyy-MM-dd
+const (
+	DateFormatEpochMillis            = "epoch_millis"
+	DateFormatEpochSecond            = "epoch_second"
+	DateFormatStrictDateOptionalTime = "strict_date_optional_time"
+)
+
+// DefaultDateFormats are used for date fields without explicit formats:
+// ISO-8601 dates with an optional time part, or epoch milliseconds
+var DefaultDateFormats = []string{DateFormatStrictDateOptionalTime, DateFormatEpochMillis}
+
+// isoLayouts are the layouts tried for strict_date_optional_time
+var isoLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// javaDateLayout translates the common Java-style pattern letters used in
+// Elasticsearch mappings (yyyy-MM-dd HH:mm:ss) into a Go time layout
+var javaDateLayout = strings.NewReplacer(
+	"yyyy", "2006",
+	"MM", "01",
+	"dd", "02",
+	"HH", "15",
+	"mm", "04",
+	"ss", "05",
+	"SSS", "000",
+	"'T'", "T",
+)
+
+// ParseDate converts a date value to epoch milliseconds using the given
+// formats, tried in order. Strings are matched against the formats; numbers
+// are taken as epoch millis (or seconds when only epoch_second is allowed).
+// Times without a zone are treated as UTC.
+func ParseDate(value interface{}, formats []string) (int64, error) {
+	if len(formats) == 0 {
+		formats = DefaultDateFormats
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return epochFromNumber(v, formats), nil
+	case int64:
+		return epochFromNumber(float64(v), formats), nil
+	case int:
+		return epochFromNumber(float64(v), formats), nil
+	case string:
+		for _, format := range formats {
+			if millis, ok := parseDateString(v, format); ok {
+				return millis, nil
+			}
+		}
+		return 0, fmt.Errorf("failed to parse date [%s] with formats [%s]", v, strings.Join(formats, "||"))
+	default:
+		return 0, fmt.Errorf("unsupported date value type %T", value)
+	}
+}
+
+// epochFromNumber interprets a numeric date, which is epoch millis unless
+// epoch_second is the only epoch format allowed
+func epochFromNumber(v float64, formats []string) int64 {
+	for _, format := range formats {
+		if format == DateFormatEpochMillis {
+			return int64(v)
+		}
+	}
+	for _, format := range formats {
+		if format == DateFormatEpochSecond {
+			return int64(math.Round(v * 1000))
+		}
+	}
+	return int64(v)
+}
+
+// parseDateString parses s with a single named format or Go layout
+func parseDateString(s string, format string) (int64, bool) {
+	switch format {
+	case DateFormatEpochMillis:
+		n, err := strconv.ParseInt(s, 10, 64)
+		return n, err == nil
+	case DateFormatEpochSecond:
+		n, err := strconv.ParseFloat(s, 64)
+		return int64(math.Round(n * 1000)), err == nil
+	case DateFormatStrictDateOptionalTime:
+		for _, layout := range isoLayouts {
+			if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+				return t.UnixMilli(), true
+			}
+		}
+		return 0, false
+	default:
+		if strings.Contains(format, "yyyy") {
+			format = javaDateLayout.Replace(format)
+		}
+		t, err := time.ParseInLocation(format, s, time.UTC)
+		if err != nil {
+			return 0, false
+		}
+		return t.UnixMilli(), true
+	}
+}
+
+// looksLikeDate reports whether s is an ISO-8601 date, which is how string
+// fields are detected as dates when they have no explicit date mapping
+func looksLikeDate(s string) bool {
+	// Cheap shape check before trying the layouts: YYYY-MM-DD prefix
+	if len(s) < 10 || s[4] != '-' || s[7] != '-' {
+		return false
+	}
+	_, ok := parseDateString(s, DateFormatStrictDateOptionalTime)
+	return ok
+}
@@ -0,0 +1,58 @@
+package diagon
+
+import (
+	"testing"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		formats []string
+		want    int64
+		wantErr bool
+	}{
+		{name: "iso_date", value: "2026-01-01", want: 1767225600000},
+		{name: "iso_datetime", value: "2026-01-01T00:00:01Z", want: 1767225601000},
+		{name: "iso_datetime_offset", value: "2026-01-01T02:00:00+02:00", want: 1767225600000},
+		{name: "iso_datetime_no_zone", value: "2026-01-01T00:00:00.500", want: 1767225600500},
+		{name: "epoch_millis_number", value: float64(1767225600000), want: 1767225600000},
+		{name: "epoch_millis_string", value: "1767225600000", want: 1767225600000},
+		{name: "epoch_second", value: float64(1767225600), formats: []string{DateFormatEpochSecond}, want: 1767225600000},
+		{name: "java_pattern", value: "01/01/2026", formats: []string{"MM/dd/yyyy"}, want: 1767225600000},
+		{name: "go_layout", value: "2026-01-01 00:00", formats: []string{"2006-01-02 15:04"}, want: 1767225600000},
+		{name: "invalid", value: "yesterday", wantErr: true},
+		{name: "unsupported_type", value: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDate(tt.value, tt.formats)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseDate(%v) expected error, got %d", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDate(%v) error = %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDate(%v) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLooksLikeDate(t *testing.T) {
+	for _, s := range []string{"2026-01-01", "2026-01-15T10:30:00Z"} {
+		if !looksLikeDate(s) {
+			t.Errorf("looksLikeDate(%q) = false, want true", s)
+		}
+	}
+	for _, s := range []string{"hello world", "2026", "1767225600000", "2026-13-45"} {
+		if looksLikeDate(s) {
+			t.Errorf("looksLikeDate(%q) = true, want false", s)
+		}
+	}
+}
@@ -38,7 +38,7 @@ func TestDoubleRangeQuery(t *testing.T) {
 	defer bridge.Stop()
 
 	// Create shard
-	shard, err := bridge.CreateShard(indexPath)
+	shard, err := bridge.CreateShard(indexPath, ShardOptions{})
 	if err != nil {
 		t.Fatalf("Failed to create shard: %v", err)
 	}
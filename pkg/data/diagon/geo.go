@@ -0,0 +1,266 @@
+package diagon
+
+import (
+	"fmt"
+
+	"github.com/quidditch/quidditch/pkg/common/geo"
+	"github.com/quidditch/quidditch/pkg/coordination/parser"
+)
+
+// innerBoxParam marks a geo_distance clause geoSearchQuery placed under
+// must_not, which Diagon matches by a box inside its circle
+const innerBoxParam = "_inner_box"
+
+// geoDistanceFilter is a parsed geo_distance query
+type geoDistanceFilter struct {
+	field  string
+	origin geo.Point
+	meters float64
+	render bool // add the distance to each hit as _geo_distance
+	inner  bool // match a box inside the circle rather than around it
+}
+
+// parseGeoDistance parses the body of a geo_distance query:
+// {"distance": "10km", "location": {"lat": 40.7, "lon": -74.0}}
+func parseGeoDistance(params map[string]interface{}) (*geoDistanceFilter, error) {
+	distance, ok := params["distance"]
+	if !ok {
+		return nil, fmt.Errorf("geo_distance query must have a distance")
+	}
+	meters, err := geo.ParseDistance(distance)
+	if err != nil {
+		return nil, fmt.Errorf("geo_distance query: %w", err)
+	}
+
+	filter := &geoDistanceFilter{meters: meters}
+	for key, value := range params {
+		switch key {
+		case "distance", "distance_type", "validation_method", "_name", "boost":
+			continue
+		case "render_distance":
+			filter.render, _ = value.(bool)
+			continue
+		case innerBoxParam:
+			filter.inner, _ = value.(bool)
+			continue
+		}
+		if filter.field != "" {
+			return nil, fmt.Errorf("geo_distance query supports a single field, got [%s] and [%s]", filter.field, key)
+		}
+		origin, err := geo.ParsePoint(value)
+		if err != nil {
+			return nil, fmt.Errorf("geo_distance query origin for [%s]: %w", key, err)
+		}
+		filter.field = key
+		filter.origin = origin
+	}
+
+	if filter.field == "" {
+		return nil, fmt.Errorf("geo_distance query must have a field")
+	}
+	return filter, nil
+}
+
+// hasGeoDistance reports whether a query is a geo_distance query or a bool
+// query holding one in any of its clauses
+func hasGeoDistance(queryObj map[string]interface{}) bool {
+	if _, ok := queryObj["geo_distance"]; ok {
+		return true
+	}
+	boolQuery, ok := queryObj["bool"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, occur := range []string{"must", "filter", "should", "must_not"} {
+		clauses, _ := boolQuery[occur].([]interface{})
+		for _, clause := range clauses {
+			if clauseMap, ok := clause.(map[string]interface{}); ok && hasGeoDistance(clauseMap) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// geoSearchQuery returns the query Diagon runs in place of one holding
+// geo_distance clauses. Diagon matches a geo_distance clause by the box
+// around its circle, which matches too much; under must_not that would
+// exclude too much, so there the clause matches a box inside the circle
+// instead. Either way Diagon matches every document the query matches, and
+// its geoDistanceCheck drops the others.
+func geoSearchQuery(queryObj map[string]interface{}, negated bool) map[string]interface{} {
+	if params, ok := queryObj["geo_distance"].(map[string]interface{}); ok {
+		if !negated {
+			return queryObj
+		}
+		inner := make(map[string]interface{}, len(params)+1)
+		for key, value := range params {
+			inner[key] = value
+		}
+		inner[innerBoxParam] = true
+		return map[string]interface{}{"geo_distance": inner}
+	}
+
+	boolQuery, ok := queryObj["bool"].(map[string]interface{})
+	if !ok {
+		return queryObj
+	}
+	rewritten := make(map[string]interface{}, len(boolQuery))
+	for key, value := range boolQuery {
+		clauses, isArray := value.([]interface{})
+		if !isArray || (key != "must" && key != "filter" && key != "should" && key != "must_not") {
+			rewritten[key] = value
+			continue
+		}
+		rewrittenClauses := make([]interface{}, len(clauses))
+		for i, clause := range clauses {
+			rewrittenClauses[i] = clause
+			if clauseMap, ok := clause.(map[string]interface{}); ok {
+				rewrittenClauses[i] = geoSearchQuery(clauseMap, negated != (key == "must_not"))
+			}
+		}
+		rewritten[key] = rewrittenClauses
+	}
+	return map[string]interface{}{"bool": rewritten}
+}
+
+// geoDistanceCheck is the exact form of a query's geo_distance clauses and
+// of the bool clauses combining them, which each document Diagon matches
+// for geoSearchQuery is checked against
+type geoDistanceCheck struct {
+	filter *geoDistanceFilter // A geo_distance clause
+
+	// A clause without geo_distance. It holds on every match when Diagon
+	// enforced it; otherwise docs holds the matches it holds on.
+	clause map[string]interface{}
+	always bool
+	docs   map[int]bool
+
+	// A bool clause; filter clauses count as must
+	must, should, mustNot []*geoDistanceCheck
+	minShould             int
+}
+
+// newGeoDistanceCheck builds the check of a query, or returns nil when it
+// holds no geo_distance clause
+func newGeoDistanceCheck(queryObj map[string]interface{}) (*geoDistanceCheck, error) {
+	if !hasGeoDistance(queryObj) {
+		return nil, nil
+	}
+	return buildGeoDistanceCheck(queryObj, true)
+}
+
+// buildGeoDistanceCheck builds the check of a clause. required marks a
+// clause reached only through must and filter clauses, which Diagon
+// enforces on every match unless it involves geo_distance.
+func buildGeoDistanceCheck(queryObj map[string]interface{}, required bool) (*geoDistanceCheck, error) {
+	if !hasGeoDistance(queryObj) {
+		return &geoDistanceCheck{clause: queryObj, always: required}, nil
+	}
+	if params, ok := queryObj["geo_distance"].(map[string]interface{}); ok {
+		filter, err := parseGeoDistance(params)
+		if err != nil {
+			return nil, err
+		}
+		return &geoDistanceCheck{filter: filter}, nil
+	}
+
+	boolQuery := queryObj["bool"].(map[string]interface{})
+	check := &geoDistanceCheck{}
+	for _, occur := range []string{"must", "filter", "should", "must_not"} {
+		clauses, _ := boolQuery[occur].([]interface{})
+		for _, clause := range clauses {
+			clauseMap, ok := clause.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			clauseCheck, err := buildGeoDistanceCheck(clauseMap, required && (occur == "must" || occur == "filter"))
+			if err != nil {
+				return nil, err
+			}
+			switch occur {
+			case "must", "filter":
+				check.must = append(check.must, clauseCheck)
+			case "should":
+				check.should = append(check.should, clauseCheck)
+			case "must_not":
+				check.mustNot = append(check.mustNot, clauseCheck)
+			}
+		}
+	}
+
+	// Should clauses are optional next to must or filter clauses
+	if len(check.must) == 0 && len(check.should) > 0 {
+		check.minShould = 1
+	}
+	switch minShould := boolQuery["minimum_should_match"].(type) {
+	case float64:
+		check.minShould = int(minShould)
+	case string:
+		resolved, err := parser.ResolveMinimumShouldMatch(minShould, len(check.should))
+		if err != nil {
+			return nil, err
+		}
+		check.minShould = resolved
+	}
+	return check, nil
+}
+
+// unresolved returns the clauses of the check without geo_distance whose
+// matches have to be looked up
+func (c *geoDistanceCheck) unresolved() []*geoDistanceCheck {
+	if c.clause != nil {
+		if c.always {
+			return nil
+		}
+		return []*geoDistanceCheck{c}
+	}
+	var clauses []*geoDistanceCheck
+	for _, group := range [][]*geoDistanceCheck{c.must, c.should, c.mustNot} {
+		for _, clause := range group {
+			clauses = append(clauses, clause.unresolved()...)
+		}
+	}
+	return clauses
+}
+
+// matches reports whether a document satisfies the check. point reads the
+// document's geo_point of a field, and render receives its distance from
+// the origin of each geo_distance clause it is within that renders it.
+func (c *geoDistanceCheck) matches(internalDocID int, point func(field string) (geo.Point, bool), render func(distance float64)) bool {
+	switch {
+	case c.filter != nil:
+		p, ok := point(c.filter.field)
+		if !ok {
+			return false
+		}
+		distance := geo.Distance(c.filter.origin, p)
+		if distance > c.filter.meters {
+			return false
+		}
+		if c.filter.render {
+			render(distance)
+		}
+		return true
+	case c.clause != nil:
+		return c.always || c.docs[internalDocID]
+	}
+
+	for _, clause := range c.must {
+		if !clause.matches(internalDocID, point, render) {
+			return false
+		}
+	}
+	for _, clause := range c.mustNot {
+		if clause.matches(internalDocID, point, render) {
+			return false
+		}
+	}
+	matched := 0
+	for _, clause := range c.should {
+		if clause.matches(internalDocID, point, render) {
+			matched++
+		}
+	}
+	return matched >= c.minShould
+}
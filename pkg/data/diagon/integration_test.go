@@ -2,11 +2,14 @@ package diagon
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // TestRealDiagonIntegration tests the full integration with real Diagon C++ engine
@@ -45,7 +48,7 @@ func TestRealDiagonIntegration(t *testing.T) {
 	defer bridge.Stop()
 
 	// Create shard
-	shard, err := bridge.CreateShard(indexPath)
+	shard, err := bridge.CreateShard(indexPath, ShardOptions{})
 	if err != nil {
 		t.Fatalf("Failed to create shard: %v", err)
 	}
@@ -221,7 +224,7 @@ func TestMultipleShards(t *testing.T) {
 			t.Fatalf("Failed to create shard directory: %v", err)
 		}
 
-		shard, err := bridge.CreateShard(shardPath)
+		shard, err := bridge.CreateShard(shardPath, ShardOptions{})
 		if err != nil {
 			t.Fatalf("Failed to create shard %d: %v", i, err)
 		}
@@ -257,6 +260,316 @@ func TestMultipleShards(t *testing.T) {
 	}
 }
 
+// TestCreateShardStoreTypes creates, fills and reopens a shard through each
+// directory type
+func TestCreateShardStoreTypes(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	bridge, err := NewDiagonBridge(&Config{
+		DataDir:     tmpDir,
+		SIMDEnabled: true,
+		Logger:      zap.NewNop(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create bridge: %v", err)
+	}
+	if err := bridge.Start(); err != nil {
+		t.Fatalf("Failed to start bridge: %v", err)
+	}
+	defer bridge.Stop()
+
+	for _, storeType := range []StoreType{StoreTypeMMap, StoreTypeNIO, StoreTypeHybrid} {
+		t.Run(string(storeType), func(t *testing.T) {
+			shardPath := filepath.Join(tmpDir, string(storeType))
+			if err := os.MkdirAll(shardPath, 0755); err != nil {
+				t.Fatalf("Failed to create shard directory: %v", err)
+			}
+
+			shard, err := bridge.CreateShard(shardPath, ShardOptions{StoreType: storeType})
+			if err != nil {
+				t.Fatalf("Failed to create shard: %v", err)
+			}
+			doc := map[string]interface{}{"content": "stored through " + string(storeType)}
+			if err := shard.IndexDocument("doc_1", doc); err != nil {
+				t.Fatalf("Failed to index document: %v", err)
+			}
+			if err := shard.Commit(); err != nil {
+				t.Fatalf("Failed to commit: %v", err)
+			}
+			shard.Close()
+
+			// The committed document is readable through the same directory type
+			reopened, err := bridge.CreateShard(shardPath, ShardOptions{StoreType: storeType})
+			if err != nil {
+				t.Fatalf("Failed to reopen shard: %v", err)
+			}
+			defer reopened.Close()
+
+			result, err := reopened.Search([]byte(`{"term": {"content": "stored"}}`), nil)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if result.TotalHits != 1 {
+				t.Errorf("Expected 1 hit, got %d", result.TotalHits)
+			}
+		})
+	}
+
+	if _, err := bridge.CreateShard(filepath.Join(tmpDir, "unknown"), ShardOptions{StoreType: "simplefs"}); err == nil {
+		t.Error("Expected an unknown store type to be rejected")
+	}
+}
+
+// TestCreateShardRAMBufferSize checks the indexing RAM buffer a shard is
+// opened with comes from its options or the bridge's default
+func TestCreateShardRAMBufferSize(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if _, err := NewDiagonBridge(&Config{DataDir: tmpDir, Logger: zap.NewNop(), RAMBufferSizeMB: 4096}); err == nil {
+		t.Error("Expected an oversized default RAM buffer to be rejected")
+	}
+
+	bridge, err := NewDiagonBridge(&Config{
+		DataDir:         tmpDir,
+		Logger:          zap.NewNop(),
+		RAMBufferSizeMB: 16,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create bridge: %v", err)
+	}
+	if err := bridge.Start(); err != nil {
+		t.Fatalf("Failed to start bridge: %v", err)
+	}
+	defer bridge.Stop()
+
+	for _, tc := range []struct {
+		name     string
+		sizeMB   float64
+		expected float64
+	}{
+		{"node_default", 0, 16},
+		{"large_ingest", 512, 512},
+		{"small", 1, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			shardPath := filepath.Join(tmpDir, tc.name)
+			if err := os.MkdirAll(shardPath, 0755); err != nil {
+				t.Fatalf("Failed to create shard directory: %v", err)
+			}
+
+			shard, err := bridge.CreateShard(shardPath, ShardOptions{RAMBufferSizeMB: tc.sizeMB})
+			if err != nil {
+				t.Fatalf("Failed to create shard: %v", err)
+			}
+			defer shard.Close()
+
+			if shard.RAMBufferSizeMB() != tc.expected {
+				t.Errorf("Expected a %gMB RAM buffer, got %gMB", tc.expected, shard.RAMBufferSizeMB())
+			}
+		})
+	}
+
+	for _, sizeMB := range []float64{0.5, 2048, -1} {
+		if _, err := bridge.CreateShard(filepath.Join(tmpDir, "invalid"), ShardOptions{RAMBufferSizeMB: sizeMB}); err == nil {
+			t.Errorf("Expected a %gMB RAM buffer to be rejected", sizeMB)
+		}
+	}
+}
+
+// TestSegments indexes across several commits, each writing a segment, and
+// checks the segments collapse into one when force merged
+func TestSegments(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	bridge, err := NewDiagonBridge(&Config{DataDir: tmpDir, Logger: zap.NewNop()})
+	if err != nil {
+		t.Fatalf("Failed to create bridge: %v", err)
+	}
+	if err := bridge.Start(); err != nil {
+		t.Fatalf("Failed to start bridge: %v", err)
+	}
+	defer bridge.Stop()
+
+	shardPath := filepath.Join(tmpDir, "segments")
+	if err := os.MkdirAll(shardPath, 0755); err != nil {
+		t.Fatalf("Failed to create shard directory: %v", err)
+	}
+	shard, err := bridge.CreateShard(shardPath, ShardOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create shard: %v", err)
+	}
+	defer shard.Close()
+
+	const commits, docsPerCommit = 3, 5
+	for c := 0; c < commits; c++ {
+		for i := 0; i < docsPerCommit; i++ {
+			docID := fmt.Sprintf("doc_%d_%d", c, i)
+			if err := shard.IndexDocument(docID, map[string]interface{}{"content": "segment " + docID}); err != nil {
+				t.Fatalf("Failed to index %s: %v", docID, err)
+			}
+		}
+		if err := shard.Refresh(); err != nil {
+			t.Fatalf("Failed to refresh: %v", err)
+		}
+	}
+
+	segments, err := shard.Segments()
+	if err != nil {
+		t.Fatalf("Failed to list segments: %v", err)
+	}
+	if len(segments) < 2 {
+		t.Fatalf("Expected a segment per commit, got %d", len(segments))
+	}
+	for _, segment := range segments {
+		if segment.Name == "" || segment.SizeBytes <= 0 {
+			t.Errorf("Expected a named, non-empty segment, got %+v", segment)
+		}
+	}
+
+	if err := shard.ForceMerge(1); err != nil {
+		t.Fatalf("Failed to force merge: %v", err)
+	}
+	segments, err = shard.Segments()
+	if err != nil {
+		t.Fatalf("Failed to list segments: %v", err)
+	}
+	if len(segments) != 1 {
+		t.Fatalf("Expected one segment after force merge, got %d", len(segments))
+	}
+	if segments[0].NumDocs != commits*docsPerCommit || segments[0].DeletedDocs != 0 {
+		t.Errorf("Expected the merged segment to hold every document, got %+v", segments[0])
+	}
+}
+
+// TestSegmentFiles groups a shard directory's files by segment
+func TestSegmentFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]int{
+		"_0.cfs":        100,
+		"_0.si":         10,
+		"_0_1.liv":      5,
+		"_a.cfs":        300,
+		"_2.cfs":        200,
+		"segments_3":    50,
+		"write.lock":    0,
+		"_2_Lucene.doc": 20,
+	}
+	for name, size := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	segments, err := segmentFiles(dir)
+	if err != nil {
+		t.Fatalf("Failed to list segments: %v", err)
+	}
+	want := []SegmentInfo{
+		{Name: "_0", SizeBytes: 115},
+		{Name: "_2", SizeBytes: 220},
+		{Name: "_a", SizeBytes: 300},
+	}
+	if len(segments) != len(want) {
+		t.Fatalf("Expected %d segments, got %+v", len(want), segments)
+	}
+	for i := range want {
+		if segments[i] != want[i] {
+			t.Errorf("Expected segment %d to be %+v, got %+v", i, want[i], segments[i])
+		}
+	}
+}
+
+// TestConcurrentSearchAndRefresh runs searches while documents are indexed
+// and the shard refreshed, each search seeing a consistent snapshot
+func TestConcurrentSearchAndRefresh(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	bridge, err := NewDiagonBridge(&Config{DataDir: tmpDir, Logger: zap.NewNop()})
+	if err != nil {
+		t.Fatalf("Failed to create bridge: %v", err)
+	}
+	if err := bridge.Start(); err != nil {
+		t.Fatalf("Failed to start bridge: %v", err)
+	}
+	defer bridge.Stop()
+
+	shardPath := filepath.Join(tmpDir, "shard")
+	if err := os.MkdirAll(shardPath, 0755); err != nil {
+		t.Fatalf("Failed to create shard directory: %v", err)
+	}
+	shard, err := bridge.CreateShard(shardPath, ShardOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create shard: %v", err)
+	}
+	defer shard.Close()
+
+	const initialDocs = 10
+	const addedDocs = 50
+	for i := 0; i < initialDocs; i++ {
+		if err := shard.IndexDocument(fmt.Sprintf("doc_%d", i), map[string]interface{}{"content": "searchable"}); err != nil {
+			t.Fatalf("Failed to index document %d: %v", i, err)
+		}
+	}
+	if err := shard.Refresh(); err != nil {
+		t.Fatalf("Failed to refresh: %v", err)
+	}
+
+	query := []byte(`{"term": {"content": "searchable"}}`)
+	done := make(chan struct{})
+	errs := make(chan error, 16)
+	var wg sync.WaitGroup
+
+	// Searchers never see fewer documents than an earlier search did
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			seen := int64(initialDocs)
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				result, err := shard.Search(query, nil)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if result.TotalHits < seen {
+					errs <- fmt.Errorf("search saw %d hits after seeing %d", result.TotalHits, seen)
+					return
+				}
+				seen = result.TotalHits
+			}
+		}()
+	}
+
+	// Meanwhile index more documents, refreshing after each
+	for i := initialDocs; i < initialDocs+addedDocs; i++ {
+		if err := shard.IndexDocument(fmt.Sprintf("doc_%d", i), map[string]interface{}{"content": "searchable"}); err != nil {
+			t.Fatalf("Failed to index document %d: %v", i, err)
+		}
+		if err := shard.Refresh(); err != nil {
+			t.Fatalf("Failed to refresh: %v", err)
+		}
+	}
+	close(done)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	result, err := shard.Search(query, nil)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if result.TotalHits != initialDocs+addedDocs {
+		t.Errorf("Expected %d hits, got %d", initialDocs+addedDocs, result.TotalHits)
+	}
+}
+
 // TestDiagonPerformance benchmarks indexing and search performance
 func TestDiagonPerformance(t *testing.T) {
 	if testing.Short() {
@@ -292,7 +605,7 @@ func TestDiagonPerformance(t *testing.T) {
 		t.Fatalf("Failed to create index directory: %v", err)
 	}
 
-	shard, err := bridge.CreateShard(indexPath)
+	shard, err := bridge.CreateShard(indexPath, ShardOptions{})
 	if err != nil {
 		t.Fatalf("Failed to create shard: %v", err)
 	}
@@ -304,9 +617,9 @@ func TestDiagonPerformance(t *testing.T) {
 
 	for i := 0; i < numDocs; i++ {
 		doc := map[string]interface{}{
-			"id":      i,
-			"title":   fmt.Sprintf("Document %d", i),
-			"content": fmt.Sprintf("This is the content of document %d with some searchable terms", i),
+			"id":       i,
+			"title":    fmt.Sprintf("Document %d", i),
+			"content":  fmt.Sprintf("This is the content of document %d with some searchable terms", i),
 			"category": []string{"tech", "science", "programming"}[i%3],
 		}
 
@@ -350,3 +663,54 @@ func TestDiagonPerformance(t *testing.T) {
 			term, result.TotalHits, result.MaxScore)
 	}
 }
+
+// BenchmarkIndexDocument indexes documents through a production logger. At
+// info the indexing path writes no entries and allocates nothing for
+// logging; debug, which logs every document and field, is the baseline.
+func BenchmarkIndexDocument(b *testing.B) {
+	doc := map[string]interface{}{
+		"title":       "Wireless noise cancelling headphones",
+		"description": "Over-ear headphones with thirty hours of battery life",
+		"price":       199.99,
+		"quantity":    float64(12),
+		"tags":        []interface{}{"audio", "wireless"},
+		"brand":       map[string]interface{}{"name": "Acme", "country": "SE"},
+	}
+
+	for _, level := range []zapcore.Level{zapcore.InfoLevel, zapcore.DebugLevel} {
+		b.Run(level.String(), func(b *testing.B) {
+			tmpDir := b.TempDir()
+			core := zapcore.NewCore(
+				zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+				zapcore.AddSync(io.Discard),
+				level)
+
+			bridge, err := NewDiagonBridge(&Config{DataDir: tmpDir, Logger: zap.New(core)})
+			if err != nil {
+				b.Fatalf("Failed to create bridge: %v", err)
+			}
+			if err := bridge.Start(); err != nil {
+				b.Fatalf("Failed to start bridge: %v", err)
+			}
+			defer bridge.Stop()
+
+			shardPath := filepath.Join(tmpDir, "bench")
+			if err := os.MkdirAll(shardPath, 0755); err != nil {
+				b.Fatalf("Failed to create shard directory: %v", err)
+			}
+			shard, err := bridge.CreateShard(shardPath, ShardOptions{})
+			if err != nil {
+				b.Fatalf("Failed to create shard: %v", err)
+			}
+			defer shard.Close()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := shard.IndexDocument(fmt.Sprintf("doc_%d", i), doc); err != nil {
+					b.Fatalf("Failed to index document: %v", err)
+				}
+			}
+		})
+	}
+}
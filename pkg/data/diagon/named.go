@@ -0,0 +1,50 @@
+package diagon
+
+// namedQuery is a query clause carrying a _name
+type namedQuery struct {
+	name   string
+	clause map[string]interface{}
+}
+
+// namedQueries returns the clauses of a query that carry a _name, the query
+// itself included, in depth-first order. _name sits in the query body
+// ({"bool": {..., "_name": "q"}}) or in the field object of single-field
+// queries ({"term": {"status": {"value": "active", "_name": "q"}}}).
+func namedQueries(queryObj map[string]interface{}) []namedQuery {
+	var named []namedQuery
+	for _, body := range queryObj {
+		bodyMap, ok := body.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if name := clauseName(bodyMap); name != "" {
+			named = append(named, namedQuery{name: name, clause: queryObj})
+		}
+
+		for _, occur := range []string{"must", "should", "filter", "must_not"} {
+			clauses, _ := bodyMap[occur].([]interface{})
+			for _, clause := range clauses {
+				if clauseMap, ok := clause.(map[string]interface{}); ok {
+					named = append(named, namedQueries(clauseMap)...)
+				}
+			}
+		}
+	}
+	return named
+}
+
+// clauseName returns the _name in a query body or its field object
+func clauseName(body map[string]interface{}) string {
+	if name, ok := body["_name"].(string); ok {
+		return name
+	}
+	for _, value := range body {
+		if fieldMap, ok := value.(map[string]interface{}); ok {
+			if name, ok := fieldMap["_name"].(string); ok {
+				return name
+			}
+		}
+	}
+	return ""
+}
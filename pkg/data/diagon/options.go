@@ -0,0 +1,49 @@
+package diagon
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Bounds of a shard's indexing RAM buffer. Diagon, like Lucene, cannot
+// address a buffer of 2GB or more.
+const (
+	DefaultRAMBufferSizeMB = 64.0
+	MinRAMBufferSizeMB     = 1.0
+	MaxRAMBufferSizeMB     = 2047.0
+)
+
+// ShardOptions configure how a shard's index is opened
+type ShardOptions struct {
+	// StoreType selects the directory the shard's files are read through
+	StoreType StoreType
+
+	// RAMBufferSizeMB is how much the index writer buffers before flushing
+	// a segment. Zero uses the bridge's configured default.
+	RAMBufferSizeMB float64
+}
+
+// ValidateRAMBufferSize checks an indexing RAM buffer size in megabytes.
+// Zero is the default.
+func ValidateRAMBufferSize(sizeMB float64) error {
+	if sizeMB != 0 && (sizeMB < MinRAMBufferSizeMB || sizeMB > MaxRAMBufferSizeMB) {
+		return fmt.Errorf("RAM buffer size must be between %gMB and %gMB but was %gMB", MinRAMBufferSizeMB, MaxRAMBufferSizeMB, sizeMB)
+	}
+	return nil
+}
+
+// ParseRAMBufferSize parses an index.buffer_size_mb setting value. Empty is
+// the default, zero.
+func ParseRAMBufferSize(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	sizeMB, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid index.buffer_size_mb [%s]: %w", value, err)
+	}
+	if err := ValidateRAMBufferSize(sizeMB); err != nil {
+		return 0, err
+	}
+	return sizeMB, nil
+}
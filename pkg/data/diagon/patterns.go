@@ -0,0 +1,70 @@
+package diagon
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Prefix and wildcard queries run as regexp queries, the only pattern query
+// Diagon has. Their patterns are translated to the regexp syntax, escaping
+// every other character so it matches literally.
+
+// regexpLiteral escapes text so a regexp matches it literally
+func regexpLiteral(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// prefixRegexp returns the regexp matching the terms starting with prefix
+func prefixRegexp(prefix string) string {
+	return regexpLiteral(prefix) + ".*"
+}
+
+// wildcardRegexp translates a wildcard pattern, where * matches any
+// characters, ? any one character and \ escapes the next character, to
+// the equivalent regexp
+func wildcardRegexp(pattern string) string {
+	var b strings.Builder
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '\\' && i+1 < len(runes):
+			i++
+			b.WriteString(regexpLiteral(string(runes[i])))
+		case r == '*':
+			b.WriteString(".*")
+		case r == '?':
+			b.WriteByte('.')
+		default:
+			b.WriteString(regexpLiteral(string(r)))
+		}
+	}
+	return b.String()
+}
+
+// patternQueryValue returns the field and value of a single-field pattern
+// query, {"prefix": {"field": "value"}} or {"prefix": {"field": {"value":
+// "value"}}}
+func patternQueryValue(queryType string, body map[string]interface{}) (string, string, error) {
+	for field, value := range body {
+		var text string
+		switch v := value.(type) {
+		case string:
+			text = v
+		case map[string]interface{}:
+			text, _ = v["value"].(string)
+		}
+		if text == "" {
+			return "", "", fmt.Errorf("%s query on field [%s] has no value", queryType, field)
+		}
+		return field, text, nil
+	}
+	return "", "", fmt.Errorf("%s query must have a field", queryType)
+}
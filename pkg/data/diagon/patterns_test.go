@@ -0,0 +1,47 @@
+package diagon
+
+import (
+	"testing"
+)
+
+func TestPatternRegexps(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "prefix", got: prefixRegexp("lap"), want: "lap.*"},
+		{name: "prefix_escaped", got: prefixRegexp("c++"), want: `c\+\+.*`},
+		{name: "prefix_dot", got: prefixRegexp("v1.2"), want: `v1\.2.*`},
+		{name: "wildcard_star", got: wildcardRegexp("lap*p"), want: "lap.*p"},
+		{name: "wildcard_question", got: wildcardRegexp("te?t"), want: "te.t"},
+		{name: "wildcard_escaped_star", got: wildcardRegexp(`a\*b*`), want: `a\*b.*`},
+		{name: "wildcard_special", got: wildcardRegexp("(x)*"), want: `\(x\).*`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatternQueryValue(t *testing.T) {
+	field, value, err := patternQueryValue("prefix", map[string]interface{}{"title": "lap"})
+	if err != nil || field != "title" || value != "lap" {
+		t.Errorf("patternQueryValue = %q, %q, %v, want title, lap", field, value, err)
+	}
+
+	_, value, err = patternQueryValue("wildcard", map[string]interface{}{"title": map[string]interface{}{"value": "l*p"}})
+	if err != nil || value != "l*p" {
+		t.Errorf("patternQueryValue = %q, %v, want l*p", value, err)
+	}
+
+	for _, body := range []map[string]interface{}{{"title": ""}, {}} {
+		if _, _, err := patternQueryValue("prefix", body); err == nil {
+			t.Errorf("patternQueryValue(%v) expected error", body)
+		}
+	}
+}
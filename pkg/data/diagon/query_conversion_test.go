@@ -211,6 +211,8 @@ func TestQueryTypeSupport(t *testing.T) {
 		// `{"match_all": {}}`,
 		`{"range": {"price": {"gte": 100}}}`,
 		`{"bool": {"must": [{"term": {"field": "value"}}]}}`,
+		`{"ids": {"values": ["1", "2"]}}`,
+		`{"regexp": {"field": "val.*"}}`,
 	}
 
 	for _, queryJSON := range supportedQueries {
@@ -229,6 +231,9 @@ func TestQueryTypeSupport(t *testing.T) {
 		`{"wildcard": {"field": "val*"}}`,
 		`{"fuzzy": {"field": "value"}}`,
 		`{"prefix": {"field": "pre"}}`,
+		`{"ids": {"values": []}}`,
+		`{"ids": {"values": [1, 2]}}`,
+		`{"regexp": {"field": ""}}`,
 	}
 
 	for _, queryJSON := range unsupportedQueries {
@@ -0,0 +1,90 @@
+package diagon
+
+/*
+#include "diagon/c_api/diagon_c_api.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// searcherSnapshot is a point-in-time view of a shard's index: a reader and
+// the searcher over it. The shard holds a reference to its current snapshot
+// and every search holds one for as long as it runs, so Refresh can swap in
+// a new snapshot without waiting for searches on the old one. The last
+// reference released frees it.
+type searcherSnapshot struct {
+	reader   C.DiagonIndexReader
+	searcher C.DiagonIndexSearcher
+	refs     atomic.Int32
+}
+
+// openSearcherSnapshot opens a reader and searcher over the committed state
+// of a directory, holding one reference for the caller
+func openSearcherSnapshot(directory C.DiagonDirectory) (*searcherSnapshot, error) {
+	reader := C.diagon_open_index_reader(directory)
+	if reader == nil {
+		errMsg := C.GoString(C.diagon_last_error())
+		return nil, fmt.Errorf("failed to open reader: %s", errMsg)
+	}
+
+	searcher := C.diagon_create_index_searcher(reader)
+	if searcher == nil {
+		errMsg := C.GoString(C.diagon_last_error())
+		C.diagon_close_index_reader(reader)
+		return nil, fmt.Errorf("failed to create searcher: %s", errMsg)
+	}
+
+	snapshot := &searcherSnapshot{reader: reader, searcher: searcher}
+	snapshot.refs.Store(1)
+	return snapshot, nil
+}
+
+// incRef takes a reference to the snapshot
+func (ss *searcherSnapshot) incRef() {
+	ss.refs.Add(1)
+}
+
+// decRef releases a reference to the snapshot, freeing it with the last one
+func (ss *searcherSnapshot) decRef() {
+	if ss.refs.Add(-1) == 0 {
+		C.diagon_free_index_searcher(ss.searcher)
+		C.diagon_close_index_reader(ss.reader)
+	}
+}
+
+// acquireSearcher returns a reference to the shard's current snapshot,
+// refreshing it first if documents were indexed since it was opened. The
+// caller must pass it to releaseSearcher once done.
+func (s *Shard) acquireSearcher() (*searcherSnapshot, error) {
+	// Documents indexed while refreshing are left to the next search, so a
+	// steady stream of writes can't keep a search refreshing
+	refreshed := false
+	for {
+		s.mu.RLock()
+		if s.writer == nil {
+			s.mu.RUnlock()
+			return nil, fmt.Errorf("shard is closed")
+		}
+		if snapshot := s.snapshot; snapshot != nil && (refreshed || !s.changed.Load()) {
+			snapshot.incRef()
+			s.searches.Add(1)
+			s.mu.RUnlock()
+			return snapshot, nil
+		}
+		s.mu.RUnlock()
+
+		if err := s.Refresh(); err != nil {
+			return nil, err
+		}
+		refreshed = true
+	}
+}
+
+// releaseSearcher releases a snapshot returned by acquireSearcher
+func (s *Shard) releaseSearcher(snapshot *searcherSnapshot) {
+	snapshot.decRef()
+	s.searches.Done()
+}
@@ -0,0 +1,33 @@
+package diagon
+
+import "fmt"
+
+// StoreType selects the Diagon directory a shard's files are read through
+type StoreType string
+
+const (
+	// StoreTypeMMap memory-maps the shard's files, which is fastest when
+	// the host has memory to spare for the page cache
+	StoreTypeMMap StoreType = "mmapfs"
+
+	// StoreTypeNIO reads the shard's files with positional reads, for
+	// memory-constrained hosts and network filesystems where mapping
+	// files is a poor fit
+	StoreTypeNIO StoreType = "niofs"
+
+	// StoreTypeHybrid is the default. Diagon has no per-file directory
+	// switching, so it opens the same MMapDirectory as mmapfs.
+	StoreTypeHybrid StoreType = "hybridfs"
+)
+
+// ParseStoreType parses an index.store.type setting value. Empty is the
+// default, hybridfs.
+func ParseStoreType(value string) (StoreType, error) {
+	switch StoreType(value) {
+	case "":
+		return StoreTypeHybrid, nil
+	case StoreTypeMMap, StoreTypeNIO, StoreTypeHybrid:
+		return StoreType(value), nil
+	}
+	return "", fmt.Errorf("unknown index.store.type [%s], expected one of mmapfs, niofs or hybridfs", value)
+}
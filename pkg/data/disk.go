@@ -0,0 +1,43 @@
+package data
+
+import (
+	"io/fs"
+	"path/filepath"
+	"syscall"
+)
+
+// diskUsage returns the share of the filesystem holding path that is in
+// use, counting space reserved for root as used, and the filesystem's size
+func diskUsage(path string) (float64, int64, error) {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(path, &fs); err != nil {
+		return 0, 0, err
+	}
+
+	total := float64(fs.Blocks) * float64(fs.Bsize)
+	if total == 0 {
+		return 0, 0, nil
+	}
+	available := float64(fs.Bavail) * float64(fs.Bsize)
+	return (total - available) / total * 100, int64(total), nil
+}
+
+// dirSize returns the total size of the files under path
+func dirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,123 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Index settings bounding the documents a shard indexes
+const (
+	settingMaxFieldValueLength = "index.mapping.max_field_value_length"
+	settingMaxDocumentSize     = "index.mapping.max_document_size_bytes"
+)
+
+// DocumentLimits bound the documents a shard indexes. Zero is no limit.
+type DocumentLimits struct {
+	// MaxFieldValueLength is the longest string value, in bytes, a field
+	// may hold
+	MaxFieldValueLength int64
+
+	// MaxDocumentSize is the largest document, in bytes, counting its field
+	// names and values
+	MaxDocumentSize int64
+}
+
+// shardDocumentLimits parses the document limits in an index's settings.
+// Settings left unset don't limit documents, so indices created before the
+// limits existed keep accepting what they did.
+func shardDocumentLimits(settings map[string]string) (DocumentLimits, error) {
+	maxFieldValueLength, err := parseDocumentLimit(settings, settingMaxFieldValueLength)
+	if err != nil {
+		return DocumentLimits{}, err
+	}
+	maxDocumentSize, err := parseDocumentLimit(settings, settingMaxDocumentSize)
+	if err != nil {
+		return DocumentLimits{}, err
+	}
+	return DocumentLimits{MaxFieldValueLength: maxFieldValueLength, MaxDocumentSize: maxDocumentSize}, nil
+}
+
+// parseDocumentLimit parses one document limit setting. Unset is zero, no
+// limit.
+func parseDocumentLimit(settings map[string]string, key string) (int64, error) {
+	value := settings[key]
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("invalid %s [%s]: expected a non-negative number of bytes", key, value)
+	}
+	return limit, nil
+}
+
+// FieldValueTooLongError is returned when a document holds a string value
+// longer than its index's index.mapping.max_field_value_length
+type FieldValueTooLongError struct {
+	DocID  string
+	Field  string
+	Length int64
+	Limit  int64
+}
+
+func (e *FieldValueTooLongError) Error() string {
+	return fmt.Sprintf("document [%s] has a value of %d bytes in field [%s], longer than the limit of [%d] set by [%s]",
+		e.DocID, e.Length, e.Field, e.Limit, settingMaxFieldValueLength)
+}
+
+// DocumentTooLargeError is returned when a document is larger than its
+// index's index.mapping.max_document_size_bytes
+type DocumentTooLargeError struct {
+	DocID string
+	Limit int64
+}
+
+func (e *DocumentTooLargeError) Error() string {
+	return fmt.Sprintf("document [%s] is larger than the limit of [%d] bytes set by [%s]",
+		e.DocID, e.Limit, settingMaxDocumentSize)
+}
+
+// Check rejects a document exceeding the limits. The document is measured
+// by its field names and values, numbers and booleans counting 8 bytes,
+// stopping as soon as it is over the size limit.
+func (l DocumentLimits) Check(docID string, doc map[string]interface{}) error {
+	var size int64
+	var check func(field string, value interface{}) error
+	check = func(field string, value interface{}) error {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for name, child := range v {
+				size += int64(len(name))
+				path := name
+				if field != "" {
+					path = field + "." + name
+				}
+				if err := check(path, child); err != nil {
+					return err
+				}
+			}
+			return nil
+		case []interface{}:
+			for _, element := range v {
+				if err := check(field, element); err != nil {
+					return err
+				}
+			}
+			return nil
+		case string:
+			length := int64(len(v))
+			if l.MaxFieldValueLength > 0 && length > l.MaxFieldValueLength {
+				return &FieldValueTooLongError{DocID: docID, Field: field, Length: length, Limit: l.MaxFieldValueLength}
+			}
+			size += length
+		case nil:
+		default:
+			size += 8
+		}
+		if l.MaxDocumentSize > 0 && size > l.MaxDocumentSize {
+			return &DocumentTooLargeError{DocID: docID, Limit: l.MaxDocumentSize}
+		}
+		return nil
+	}
+	return check("", doc)
+}
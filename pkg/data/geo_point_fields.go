@@ -0,0 +1,31 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// settingGeoPointFields is the index setting carrying, as a JSON list, the
+// dot paths of the fields the index's mapping maps as geo_point
+const settingGeoPointFields = "index.mapping.geo_point_fields"
+
+// shardGeoPointFields parses the geo_point field mappings in an index's
+// settings. Indices without them only detect {"lat": .., "lon": ..}
+// objects as points.
+func shardGeoPointFields(settings map[string]string) ([]string, error) {
+	value := settings[settingGeoPointFields]
+	if value == "" {
+		return nil, nil
+	}
+
+	var fields []string
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", settingGeoPointFields, err)
+	}
+	for _, field := range fields {
+		if field == "" {
+			return nil, fmt.Errorf("invalid %s: empty field name", settingGeoPointFields)
+		}
+	}
+	return fields, nil
+}
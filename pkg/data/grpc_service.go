@@ -3,10 +3,14 @@ package data
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
 	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/common/requestid"
+	"github.com/quidditch/quidditch/pkg/data/breaker"
 	"github.com/quidditch/quidditch/pkg/data/diagon"
+	"github.com/quidditch/quidditch/pkg/data/suggest"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -44,8 +48,27 @@ func (s *DataService) CreateShard(ctx context.Context, req *pb.CreateShardReques
 		return nil, status.Error(codes.InvalidArgument, "shard_id must be non-negative")
 	}
 
+	if _, err := shardOptions(req.Settings); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := shardAnalyzerSettings(req.Settings); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := shardDocumentLimits(req.Settings); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := shardNumericFields(req.Settings); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := shardDateFields(req.Settings); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := shardGeoPointFields(req.Settings); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	// Create shard
-	if err := s.node.shards.CreateShard(ctx, req.IndexName, req.ShardId, req.IsPrimary); err != nil {
+	if err := s.node.shards.CreateShardWithSettings(ctx, req.IndexName, req.ShardId, req.IsPrimary, req.Settings); err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create shard: %v", err)
 	}
 
@@ -57,6 +80,38 @@ func (s *DataService) CreateShard(ctx context.Context, req *pb.CreateShardReques
 	}, nil
 }
 
+// UpdateShardSettings applies an index's updated mapping settings to the
+// shards of the index on this data node
+func (s *DataService) UpdateShardSettings(ctx context.Context, req *pb.UpdateShardSettingsRequest) (*pb.UpdateShardSettingsResponse, error) {
+	s.logger.Info("UpdateShardSettings request", zap.String("index", req.IndexName))
+
+	if req.IndexName == "" {
+		return nil, status.Error(codes.InvalidArgument, "index name is required")
+	}
+	if _, err := shardAnalyzerSettings(req.Settings); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := shardNumericFields(req.Settings); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := shardDateFields(req.Settings); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := shardGeoPointFields(req.Settings); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	updated, err := s.node.shards.UpdateIndexSettings(ctx, req.IndexName, req.Settings)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update shard settings: %v", err)
+	}
+
+	return &pb.UpdateShardSettingsResponse{
+		Acknowledged:  true,
+		ShardsUpdated: int32(updated),
+	}, nil
+}
+
 // DeleteShard deletes a shard from this data node
 func (s *DataService) DeleteShard(ctx context.Context, req *pb.DeleteShardRequest) (*pb.DeleteShardResponse, error) {
 	s.logger.Info("DeleteShard request",
@@ -78,6 +133,46 @@ func (s *DataService) DeleteShard(ctx context.Context, req *pb.DeleteShardReques
 	}, nil
 }
 
+// ScanShard returns a page of the documents of a shard, used by the master to
+// copy a relocating shard
+func (s *DataService) ScanShard(ctx context.Context, req *pb.ScanShardRequest) (*pb.ScanShardResponse, error) {
+	s.logger.Debug("ScanShard request",
+		zap.String("index", req.IndexName),
+		zap.Int32("shard_id", req.ShardId),
+		zap.Int32("from", req.From),
+		zap.Int32("size", req.Size))
+
+	// Validate request
+	if req.IndexName == "" {
+		return nil, status.Error(codes.InvalidArgument, "index name is required")
+	}
+	if req.From < 0 || req.Size <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "from must be non-negative and size positive")
+	}
+
+	// Get shard
+	shard, err := s.node.shards.GetShard(req.IndexName, req.ShardId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "shard not found: %v", err)
+	}
+
+	hits, err := shard.ScanDocuments(ctx, int(req.From), int(req.Size))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to scan shard: %v", err)
+	}
+
+	documents := make([]*pb.BulkIndexItem, 0, len(hits))
+	for _, hit := range hits {
+		source, err := structpb.NewStruct(hit.Source)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to convert document %s: %v", hit.ID, err)
+		}
+		documents = append(documents, &pb.BulkIndexItem{DocId: hit.ID, Document: source})
+	}
+
+	return &pb.ScanShardResponse{Documents: documents}, nil
+}
+
 // GetShardInfo returns information about a shard
 func (s *DataService) GetShardInfo(ctx context.Context, req *pb.GetShardInfoRequest) (*pb.ShardInfo, error) {
 	s.logger.Debug("GetShardInfo request",
@@ -147,9 +242,107 @@ func (s *DataService) FlushShard(ctx context.Context, req *pb.FlushShardRequest)
 	}, nil
 }
 
+// ForceMerge merges the segments of a shard
+func (s *DataService) ForceMerge(ctx context.Context, req *pb.ForceMergeRequest) (*pb.ForceMergeResponse, error) {
+	s.logger.Info("ForceMerge request",
+		zap.String("index", req.IndexName),
+		zap.Int32("shard_id", req.ShardId),
+		zap.Int32("max_num_segments", req.MaxNumSegments))
+
+	if req.MaxNumSegments < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "max_num_segments must be >= 0 but was [%d]", req.MaxNumSegments)
+	}
+
+	// Get shard
+	shard, err := s.node.shards.GetShard(req.IndexName, req.ShardId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "shard not found: %v", err)
+	}
+
+	result, err := shard.ForceMerge(ctx, int(req.MaxNumSegments))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to force merge shard: %v", err)
+	}
+
+	return &pb.ForceMergeResponse{
+		Acknowledged:      true,
+		DocsDeletedBefore: result.DocsDeletedBefore,
+		DocsDeletedAfter:  result.DocsDeletedAfter,
+		SizeBytesBefore:   result.SizeBytesBefore,
+		SizeBytesAfter:    result.SizeBytesAfter,
+	}, nil
+}
+
+// SnapshotShard copies a shard's committed segment files into a snapshot
+// repository
+func (s *DataService) SnapshotShard(ctx context.Context, req *pb.SnapshotShardRequest) (*pb.SnapshotShardResponse, error) {
+	s.logger.Info("SnapshotShard request",
+		zap.String("index", req.IndexName),
+		zap.Int32("shard_id", req.ShardId),
+		zap.String("repository", req.RepositoryLocation))
+
+	if req.RepositoryLocation == "" {
+		return nil, status.Error(codes.InvalidArgument, "repository location is required")
+	}
+
+	// Get shard
+	shard, err := s.node.shards.GetShard(req.IndexName, req.ShardId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "shard not found: %v", err)
+	}
+
+	files, err := shard.Snapshot(ctx, req.RepositoryLocation)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to snapshot shard: %v", err)
+	}
+
+	resp := &pb.SnapshotShardResponse{}
+	for _, file := range files {
+		resp.Files = append(resp.Files, &pb.SnapshotFile{
+			Name:      file.Name,
+			Blob:      file.Blob,
+			SizeBytes: file.SizeBytes,
+			Reused:    file.Reused,
+		})
+	}
+	return resp, nil
+}
+
+// RestoreShard replaces a shard's contents with segment files from a
+// snapshot repository
+func (s *DataService) RestoreShard(ctx context.Context, req *pb.RestoreShardRequest) (*pb.RestoreShardResponse, error) {
+	s.logger.Info("RestoreShard request",
+		zap.String("index", req.IndexName),
+		zap.Int32("shard_id", req.ShardId),
+		zap.String("repository", req.RepositoryLocation),
+		zap.Int("files", len(req.Files)))
+
+	if req.IndexName == "" {
+		return nil, status.Error(codes.InvalidArgument, "index name is required")
+	}
+	if req.RepositoryLocation == "" {
+		return nil, status.Error(codes.InvalidArgument, "repository location is required")
+	}
+
+	files := make([]SnapshotFile, 0, len(req.Files))
+	for _, file := range req.Files {
+		files = append(files, SnapshotFile{Name: file.Name, Blob: file.Blob, SizeBytes: file.SizeBytes})
+	}
+
+	shard, err := s.node.shards.RestoreShard(ctx, req.IndexName, req.ShardId, req.RepositoryLocation, files)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to restore shard: %v", err)
+	}
+
+	return &pb.RestoreShardResponse{
+		Acknowledged: true,
+		DocCount:     shard.DocsCount,
+	}, nil
+}
+
 // IndexDocument indexes a document into a shard
 func (s *DataService) IndexDocument(ctx context.Context, req *pb.IndexDocumentRequest) (*pb.IndexDocumentResponse, error) {
-	s.logger.Info("==> DataService.IndexDocument ENTRY",
+	s.logger.Debug("IndexDocument request",
 		zap.String("index", req.IndexName),
 		zap.Int32("shard_id", req.ShardId),
 		zap.String("doc_id", req.DocId))
@@ -168,12 +361,7 @@ func (s *DataService) IndexDocument(ctx context.Context, req *pb.IndexDocumentRe
 		return nil, status.Error(codes.InvalidArgument, "document is required")
 	}
 
-	s.logger.Info("IndexDocument validation passed", zap.String("doc_id", req.DocId))
-
 	// Get shard
-	s.logger.Info("Getting shard",
-		zap.String("index", req.IndexName),
-		zap.Int32("shard_id", req.ShardId))
 	shard, err := s.node.shards.GetShard(req.IndexName, req.ShardId)
 	if err != nil {
 		s.logger.Error("Failed to get shard",
@@ -183,36 +371,60 @@ func (s *DataService) IndexDocument(ctx context.Context, req *pb.IndexDocumentRe
 		return nil, status.Errorf(codes.NotFound, "shard not found: %v", err)
 	}
 
-	s.logger.Info("Got shard successfully", zap.String("doc_id", req.DocId))
-
 	// Convert protobuf Struct to map
 	doc := req.Document.AsMap()
-	s.logger.Info("Converted document to map",
-		zap.String("doc_id", req.DocId),
-		zap.Int("num_fields", len(doc)))
 
 	// Index document
-	s.logger.Info("Calling shard.IndexDocument", zap.String("doc_id", req.DocId))
 	if err := shard.IndexDocument(ctx, req.DocId, doc); err != nil {
-		s.logger.Error("shard.IndexDocument FAILED",
+		// A document the mapping rejects is the client's error, not the node's
+		if rejected := rejectedDocumentStatus(err); rejected != nil {
+			s.logger.Debug("Rejected document",
+				zap.String("doc_id", req.DocId),
+				zap.Error(err))
+			return nil, rejected
+		}
+		if errors.Is(err, diagon.ErrReplaceUnsupported) {
+			return nil, status.Error(codes.Unimplemented, err.Error())
+		}
+		s.logger.Error("Failed to index document",
 			zap.String("doc_id", req.DocId),
 			zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "failed to index document: %v", err)
 	}
 
-	s.logger.Info("shard.IndexDocument SUCCESS", zap.String("doc_id", req.DocId))
-
-	s.logger.Info("Returning IndexDocumentResponse",
-		zap.String("doc_id", req.DocId),
-		zap.Int64("version", 1))
+	s.logger.Debug("Indexed document",
+		zap.String("doc_id", req.DocId))
 
+	// Existing documents are rejected rather than replaced, so an indexed
+	// document is always a new one
 	return &pb.IndexDocumentResponse{
 		Acknowledged: true,
 		DocId:        req.DocId,
 		Version:      1, // TODO: Implement versioning
+		Found:        false,
 	}, nil
 }
 
+// rejectedDocumentStatus returns the status a document its index's
+// mapping rejects is reported with, or nil for any other error. A value
+// too long or malformed is invalid; a whole document too large is out of
+// range, which the coordinator reports as 413.
+func rejectedDocumentStatus(err error) error {
+	var fieldErr *FieldValueTooLongError
+	if errors.As(err, &fieldErr) {
+		return status.Error(codes.InvalidArgument, fieldErr.Error())
+	}
+	var malformedErr *MalformedFieldError
+	if errors.As(err, &malformedErr) {
+		return status.Error(codes.InvalidArgument, malformedErr.Error())
+	}
+	var sizeErr *DocumentTooLargeError
+	if errors.As(err, &sizeErr) {
+		return status.Error(codes.OutOfRange, sizeErr.Error())
+	}
+	return nil
+}
+
 // GetDocument retrieves a document by ID
 func (s *DataService) GetDocument(ctx context.Context, req *pb.GetDocumentRequest) (*pb.GetDocumentResponse, error) {
 	s.logger.Debug("GetDocument request",
@@ -263,6 +475,66 @@ func (s *DataService) GetDocument(ctx context.Context, req *pb.GetDocumentReques
 	}, nil
 }
 
+// TermVectors returns the term vectors of a document's fields
+func (s *DataService) TermVectors(ctx context.Context, req *pb.TermVectorsRequest) (*pb.TermVectorsResponse, error) {
+	s.logger.Debug("TermVectors request",
+		zap.String("index", req.IndexName),
+		zap.Int32("shard_id", req.ShardId),
+		zap.String("doc_id", req.DocId))
+
+	// Validate request
+	if req.IndexName == "" {
+		return nil, status.Error(codes.InvalidArgument, "index name is required")
+	}
+	if req.DocId == "" {
+		return nil, status.Error(codes.InvalidArgument, "doc_id is required")
+	}
+
+	// Get shard
+	shard, err := s.node.shards.GetShard(req.IndexName, req.ShardId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "shard not found: %v", err)
+	}
+
+	// A document that can't be read is reported as not found, as by
+	// GetDocument
+	doc, err := shard.GetDocument(ctx, req.DocId)
+	if err != nil {
+		return &pb.TermVectorsResponse{Found: false, DocId: req.DocId}, nil
+	}
+
+	vectors, err := shard.TermVectors(doc, req.Fields)
+	if err != nil {
+		var unavailableErr *TermVectorsUnavailableError
+		if errors.As(err, &unavailableErr) {
+			return nil, status.Error(codes.InvalidArgument, unavailableErr.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to compute term vectors: %v", err)
+	}
+
+	resp := &pb.TermVectorsResponse{
+		Found:       true,
+		DocId:       req.DocId,
+		TermVectors: make(map[string]*pb.FieldTermVector, len(vectors)),
+	}
+	for field, vector := range vectors {
+		terms := make(map[string]*pb.TermVectorTerm, len(vector))
+		for term, occurrences := range vector {
+			tokens := make([]*pb.TermVectorToken, len(occurrences))
+			for i, occurrence := range occurrences {
+				tokens[i] = &pb.TermVectorToken{
+					Position:    int32(occurrence.Position),
+					StartOffset: int32(occurrence.StartOffset),
+					EndOffset:   int32(occurrence.EndOffset),
+				}
+			}
+			terms[term] = &pb.TermVectorTerm{TermFreq: int32(len(occurrences)), Tokens: tokens}
+		}
+		resp.TermVectors[field] = &pb.FieldTermVector{Terms: terms}
+	}
+	return resp, nil
+}
+
 // DeleteDocument deletes a document by ID
 func (s *DataService) DeleteDocument(ctx context.Context, req *pb.DeleteDocumentRequest) (*pb.DeleteDocumentResponse, error) {
 	s.logger.Debug("DeleteDocument request",
@@ -286,6 +558,9 @@ func (s *DataService) DeleteDocument(ctx context.Context, req *pb.DeleteDocument
 
 	// Delete document
 	if err := shard.DeleteDocument(ctx, req.DocId); err != nil {
+		if errors.Is(err, diagon.ErrDeleteUnsupported) {
+			return nil, status.Error(codes.Unimplemented, diagon.ErrDeleteUnsupported.Error())
+		}
 		return nil, status.Errorf(codes.Internal, "failed to delete document: %v", err)
 	}
 
@@ -351,18 +626,19 @@ func (s *DataService) BulkIndex(ctx context.Context, req *pb.BulkIndexRequest) (
 
 // Search executes a search query on a shard
 func (s *DataService) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
-	s.logger.Info("==> DataService.Search ENTRY",
+	logger := requestid.Logger(ctx, s.logger)
+	logger.Debug("Search request",
 		zap.String("index", req.IndexName),
 		zap.Int32("shard_id", req.ShardId),
-		zap.String("query", string(req.Query)))
+		zap.ByteString("query", req.Query))
 
 	// Validate request
 	if req.IndexName == "" {
-		s.logger.Error("Search failed: index name is required")
+		logger.Error("Search failed: index name is required")
 		return nil, status.Error(codes.InvalidArgument, "index name is required")
 	}
 	if req.Query == nil {
-		s.logger.Error("Search failed: query is required")
+		logger.Error("Search failed: query is required")
 		return nil, status.Error(codes.InvalidArgument, "query is required")
 	}
 
@@ -374,25 +650,28 @@ func (s *DataService) Search(ctx context.Context, req *pb.SearchRequest) (*pb.Se
 
 	startTime := time.Now()
 
-	s.logger.Info("DEBUG: About to call shard.Search",
-		zap.String("index", req.IndexName),
-		zap.Int32("shard_id", req.ShardId))
-
 	// Execute search (UDF queries are embedded in req.Query JSON)
-	result, err := shard.Search(ctx, req.Query)
-
-	s.logger.Info("DEBUG: shard.Search returned",
-		zap.Bool("has_result", result != nil),
-		zap.Bool("has_error", err != nil))
-
-	if result != nil {
-		s.logger.Info("DEBUG: Search result details",
-			zap.Int64("total_hits", result.TotalHits),
-			zap.Int("num_hits", len(result.Hits)))
+	result, err := shard.SearchWithOptions(ctx, req.Query, diagon.SearchOptions{
+		Size:           int(req.Size),
+		TerminateAfter: req.TerminateAfter,
+		MinScore:       req.MinScore,
+		CollapseField:  req.CollapseField,
+		CollapseSize:   int(req.CollapseSize),
+	})
+	if err == nil {
+		// _script sort keys are computed per hit; the coordinator merges on them
+		err = shard.SortResults(ctx, req.Sort, result)
 	}
 
 	if err != nil {
-		s.logger.Error("DEBUG: Search error", zap.Error(err))
+		logger.Error("Search failed", zap.Error(err))
+		var cbErr *breaker.CircuitBreakingError
+		if errors.As(err, &cbErr) {
+			return nil, status.Error(codes.ResourceExhausted, cbErr.Error())
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, status.Error(codes.DeadlineExceeded, "search timed out")
+		}
 		return nil, status.Errorf(codes.Internal, "search failed: %v", err)
 	}
 
@@ -404,7 +683,7 @@ func (s *DataService) Search(ctx context.Context, req *pb.SearchRequest) (*pb.Se
 		// Convert document to protobuf Struct
 		docStruct, err := structpb.NewStruct(hit.Source)
 		if err != nil {
-			s.logger.Error("Failed to convert document", zap.Error(err))
+			logger.Error("Failed to convert document", zap.Error(err))
 			continue
 		}
 
@@ -412,6 +691,9 @@ func (s *DataService) Search(ctx context.Context, req *pb.SearchRequest) (*pb.Se
 			Id:     hit.ID,
 			Score:  hit.Score,
 			Source: docStruct,
+			Sort:   hit.Sort,
+
+			MatchedQueries: hit.MatchedQueries,
 		})
 	}
 
@@ -419,8 +701,9 @@ func (s *DataService) Search(ctx context.Context, req *pb.SearchRequest) (*pb.Se
 	aggregations := convertAggregations(result.Aggregations)
 
 	return &pb.SearchResponse{
-		TookMillis: tookMillis,
-		TimedOut:   false,
+		TookMillis:      tookMillis,
+		TimedOut:        result.TimedOut,
+		TerminatedEarly: result.TerminatedEarly,
 		Shards: &pb.ShardSearchStats{
 			Total:      1,
 			Successful: 1,
@@ -466,6 +749,68 @@ func (s *DataService) Count(ctx context.Context, req *pb.CountRequest) (*pb.Coun
 	}, nil
 }
 
+// Suggest runs the suggesters of a search request's suggest block on a shard
+func (s *DataService) Suggest(ctx context.Context, req *pb.SuggestRequest) (*pb.SuggestResponse, error) {
+	s.logger.Debug("Suggest request",
+		zap.String("index", req.IndexName),
+		zap.Int32("shard_id", req.ShardId))
+
+	// Validate request
+	if req.IndexName == "" {
+		return nil, status.Error(codes.InvalidArgument, "index name is required")
+	}
+	var block map[string]interface{}
+	if err := json.Unmarshal(req.Suggest, &block); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid suggest block: %v", err)
+	}
+	suggesters, err := suggest.Parse(block)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Get shard
+	shard, err := s.node.shards.GetShard(req.IndexName, req.ShardId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "shard not found: %v", err)
+	}
+
+	results, err := shard.Suggest(ctx, suggesters)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "suggest failed: %v", err)
+	}
+
+	resp := &pb.SuggestResponse{Suggestions: make(map[string]*pb.SuggestEntries, len(results))}
+	for name, entries := range results {
+		pbEntries := make([]*pb.SuggestEntry, len(entries))
+		for i, entry := range entries {
+			options := make([]*pb.SuggestOption, len(entry.Options))
+			for j, option := range entry.Options {
+				options[j] = &pb.SuggestOption{
+					Text:  option.Text,
+					Score: option.Score,
+					Freq:  option.Freq,
+					DocId: option.ID,
+				}
+				if option.Source != nil {
+					source, err := structpb.NewStruct(option.Source)
+					if err != nil {
+						return nil, status.Errorf(codes.Internal, "failed to convert source of %s: %v", option.ID, err)
+					}
+					options[j].Source = source
+				}
+			}
+			pbEntries[i] = &pb.SuggestEntry{
+				Text:    entry.Text,
+				Offset:  int32(entry.Offset),
+				Length:  int32(entry.Length),
+				Options: options,
+			}
+		}
+		resp.Suggestions[name] = &pb.SuggestEntries{Entries: pbEntries}
+	}
+	return resp, nil
+}
+
 // GetShardStats returns statistics for a specific shard
 func (s *DataService) GetShardStats(ctx context.Context, req *pb.GetShardStatsRequest) (*pb.ShardStats, error) {
 	s.logger.Debug("GetShardStats request",
@@ -481,18 +826,61 @@ func (s *DataService) GetShardStats(ctx context.Context, req *pb.GetShardStatsRe
 	// Get stats
 	stats := shard.Stats()
 
+	return shardStatsToProto(stats), nil
+}
+
+// shardStatsToProto converts shard statistics to their proto form
+func shardStatsToProto(stats *ShardStats) *pb.ShardStats {
 	return &pb.ShardStats{
 		IndexName:               stats.IndexName,
 		ShardId:                 stats.ShardID,
 		IsPrimary:               stats.IsPrimary,
 		DocsCount:               stats.DocsCount,
-		DocsDeleted:             0, // TODO: Track deleted docs
+		DocsDeleted:             stats.DocsDeleted,
 		SizeBytes:               stats.SizeBytes,
-		SearchQueriesTotal:      0, // TODO: Track query metrics
-		SearchQueriesTimeMillis: 0,
-		IndexingTotal:           0, // TODO: Track indexing metrics
-		IndexingTimeMillis:      0,
-	}, nil
+		SearchQueriesTotal:      stats.Search.Total,
+		SearchQueriesTimeMillis: stats.Search.Time.Milliseconds(),
+		IndexingTotal:           stats.Indexing.Total,
+		IndexingTimeMillis:      stats.Indexing.Time.Milliseconds(),
+		RefreshTotal:            stats.Refresh.Total,
+		RefreshTimeMillis:       stats.Refresh.Time.Milliseconds(),
+		MergeTotal:              stats.Merge.Total,
+		MergeTimeMillis:         stats.Merge.Time.Milliseconds(),
+	}
+}
+
+// GetSegmentStats returns the segments of a shard's index
+func (s *DataService) GetSegmentStats(ctx context.Context, req *pb.GetSegmentStatsRequest) (*pb.GetSegmentStatsResponse, error) {
+	s.logger.Debug("GetSegmentStats request",
+		zap.String("index", req.IndexName),
+		zap.Int32("shard_id", req.ShardId))
+
+	// Get shard
+	shard, err := s.node.shards.GetShard(req.IndexName, req.ShardId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "shard not found: %v", err)
+	}
+
+	segments, err := shard.Segments()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list segments: %v", err)
+	}
+
+	resp := &pb.GetSegmentStatsResponse{
+		IndexName: req.IndexName,
+		ShardId:   req.ShardId,
+		IsPrimary: shard.IsPrimary,
+		Segments:  make([]*pb.SegmentStats, len(segments)),
+	}
+	for i, segment := range segments {
+		resp.Segments[i] = &pb.SegmentStats{
+			Name:        segment.Name,
+			NumDocs:     segment.NumDocs,
+			DeletedDocs: segment.DeletedDocs,
+			SizeBytes:   segment.SizeBytes,
+		}
+	}
+	return resp, nil
 }
 
 // GetNodeStats returns statistics for the entire node
@@ -513,32 +901,27 @@ func (s *DataService) GetNodeStats(ctx context.Context, req *pb.GetNodeStatsRequ
 		totalSize += stats.SizeBytes
 
 		if req.IncludeShards {
-			shardStats = append(shardStats, &pb.ShardStats{
-				IndexName:               stats.IndexName,
-				ShardId:                 stats.ShardID,
-				IsPrimary:               stats.IsPrimary,
-				DocsCount:               stats.DocsCount,
-				DocsDeleted:             0, // TODO: Track deleted docs
-				SizeBytes:               stats.SizeBytes,
-				SearchQueriesTotal:      0, // TODO: Track query metrics
-				SearchQueriesTimeMillis: 0,
-				IndexingTotal:           0, // TODO: Track indexing metrics
-				IndexingTimeMillis:      0,
-			})
+			shardStats = append(shardStats, shardStatsToProto(stats))
 		}
 	}
 
-	// TODO: Get actual CPU, memory, disk usage
+	diskUsagePercent, diskTotal, err := diskUsage(s.node.cfg.DataDir)
+	if err != nil {
+		s.logger.Warn("Failed to get disk usage", zap.String("data_dir", s.node.cfg.DataDir), zap.Error(err))
+	}
+
+	// TODO: Get actual CPU, memory usage
 	nodeStats := &pb.DataNodeStats{
-		NodeId:              s.node.cfg.NodeID,
-		TotalShards:         int32(len(shards)),
-		TotalDocs:           totalDocs,
-		TotalSizeBytes:      totalSize,
-		CpuUsagePercent:     0.0,  // TODO: Implement
-		MemoryUsagePercent:  0.0,  // TODO: Implement
-		DiskUsagePercent:    0.0,  // TODO: Implement
-		UptimeSeconds:       0,    // TODO: Track uptime
-		Shards:              shardStats,
+		NodeId:             s.node.cfg.NodeID,
+		TotalShards:        int32(len(shards)),
+		TotalDocs:          totalDocs,
+		TotalSizeBytes:     totalSize,
+		CpuUsagePercent:    0.0, // TODO: Implement
+		MemoryUsagePercent: 0.0, // TODO: Implement
+		DiskUsagePercent:   diskUsagePercent,
+		DiskTotalBytes:     diskTotal,
+		UptimeSeconds:      0, // TODO: Track uptime
+		Shards:             shardStats,
 	}
 
 	return nodeStats, nil
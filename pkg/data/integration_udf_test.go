@@ -38,6 +38,34 @@ var alwaysFalseUDFWasm = []byte{
 	0x01, 0x04, 0x00, 0x41, 0x00, 0x0b,
 }
 
+// UDF computing a sort key: price - discount - BINARY FORMAT
+// (module
+//   (import "env" "get_field_float64" (func $get (param i64 i32 i32) (result f64)))
+//   (memory (export "memory") 1)
+//   (data (i32.const 0) "pricediscount")
+//   (func (export "discounted_price") (param i64) (result f64)
+//     (f64.sub (call $get (local.get 0) (i32.const 0) (i32.const 5))
+//              (call $get (local.get 0) (i32.const 5) (i32.const 8)))))
+var discountedPriceUDFWasm = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	0x01, 0x0d, 0x02, 0x60, 0x03, 0x7e, 0x7f, 0x7f,
+	0x01, 0x7c, 0x60, 0x01, 0x7e, 0x01, 0x7c, 0x02,
+	0x19, 0x01, 0x03, 0x65, 0x6e, 0x76, 0x11, 0x67,
+	0x65, 0x74, 0x5f, 0x66, 0x69, 0x65, 0x6c, 0x64,
+	0x5f, 0x66, 0x6c, 0x6f, 0x61, 0x74, 0x36, 0x34,
+	0x00, 0x00, 0x03, 0x02, 0x01, 0x01, 0x05, 0x03,
+	0x01, 0x00, 0x01, 0x07, 0x1d, 0x02, 0x06, 0x6d,
+	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x02, 0x00, 0x10,
+	0x64, 0x69, 0x73, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x65, 0x64, 0x5f, 0x70, 0x72, 0x69, 0x63, 0x65,
+	0x00, 0x01, 0x0a, 0x15, 0x01, 0x13, 0x00, 0x20,
+	0x00, 0x41, 0x00, 0x41, 0x05, 0x10, 0x00, 0x20,
+	0x00, 0x41, 0x05, 0x41, 0x08, 0x10, 0x00, 0xa1,
+	0x0b, 0x0b, 0x13, 0x01, 0x00, 0x41, 0x00, 0x0b,
+	0x0d, 0x70, 0x72, 0x69, 0x63, 0x65, 0x64, 0x69,
+	0x73, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+}
+
 // setupIntegrationTest creates a test environment with data node, UDF registry, and sample data
 func setupIntegrationTest(t *testing.T) (*ShardManager, *wasm.UDFRegistry, func()) {
 	// Create temp directory for test data
@@ -591,6 +619,56 @@ func TestIntegration_UDFStatistics(t *testing.T) {
 }
 
 // Benchmark UDF query execution
+func TestIntegration_UDFScriptSort(t *testing.T) {
+	shardManager, registry, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	err := shardManager.CreateShard(ctx, "test-index", 0, true)
+	require.NoError(t, err)
+
+	shard, err := shardManager.GetShard("test-index", 0)
+	require.NoError(t, err)
+
+	docs := map[string]map[string]interface{}{
+		"doc1": {"price": 100.0, "discount": 60.0},
+		"doc2": {"price": 80.0, "discount": 5.0},
+		"doc3": {"price": 50.0, "discount": 0.0},
+	}
+	for id, doc := range docs {
+		require.NoError(t, shard.IndexDocument(ctx, id, doc))
+	}
+
+	err = registry.Register(&wasm.UDFMetadata{
+		Name:         "discounted_price",
+		Version:      "1.0.0",
+		FunctionName: "discounted_price",
+		WASMBytes:    discountedPriceUDFWasm,
+		Returns:      []wasm.UDFReturnType{{Type: wasm.ValueTypeF64}},
+	})
+	require.NoError(t, err)
+
+	result, err := shard.Search(ctx, []byte(`{"match_all": {}}`))
+	require.NoError(t, err)
+	require.Len(t, result.Hits, 3)
+
+	// The version is resolved to the latest registered one
+	err = shard.SortResults(ctx, []string{`{"_script": {"udf": "discounted_price"}}`}, result)
+	require.NoError(t, err)
+
+	keys := make(map[string][]float64)
+	for _, hit := range result.Hits {
+		keys[hit.ID] = hit.Sort
+	}
+	assert.Equal(t, []float64{40}, keys["doc1"])
+	assert.Equal(t, []float64{75}, keys["doc2"])
+	assert.Equal(t, []float64{50}, keys["doc3"])
+
+	err = shard.SortResults(ctx, []string{`{"_script": {"udf": "missing_udf"}}`}, result)
+	assert.Error(t, err)
+}
+
 func BenchmarkIntegration_UDFQuery(b *testing.B) {
 	logger := zap.NewNop()
 	tmpDir, _ := os.MkdirTemp("", "bench-*")
@@ -2,14 +2,18 @@ package data
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"sync"
 	"time"
 
 	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/common/requestid"
+	"github.com/quidditch/quidditch/pkg/common/tracing"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 )
@@ -21,6 +25,7 @@ type MasterClient struct {
 	logger         *zap.Logger
 	conn           *grpc.ClientConn
 	client         pb.MasterServiceClient
+	tlsConfig      *tls.Config
 	mu             sync.RWMutex
 	connected      bool
 	heartbeatStop  chan struct{}
@@ -38,6 +43,14 @@ func NewMasterClient(nodeID, masterAddr string, logger *zap.Logger) *MasterClien
 	}
 }
 
+// SetTLSConfig makes Connect dial the master over TLS
+func (mc *MasterClient) SetTLSConfig(tlsConfig *tls.Config) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.tlsConfig = tlsConfig
+}
+
 // Connect establishes connection to the master node
 func (mc *MasterClient) Connect(ctx context.Context) error {
 	mc.mu.Lock()
@@ -53,7 +66,9 @@ func (mc *MasterClient) Connect(ctx context.Context) error {
 	conn, err := grpc.DialContext(
 		ctx,
 		mc.masterAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(transportCredentials(mc.tlsConfig)),
+		tracing.ClientOption(),
+		requestid.ClientOption(),
 		grpc.WithBlock(),
 		grpc.WithTimeout(10*time.Second),
 	)
@@ -69,6 +84,15 @@ func (mc *MasterClient) Connect(ctx context.Context) error {
 	return nil
 }
 
+// transportCredentials returns TLS credentials for tlsConfig, or insecure
+// credentials when TLS isn't configured
+func transportCredentials(tlsConfig *tls.Config) credentials.TransportCredentials {
+	if tlsConfig == nil {
+		return insecure.NewCredentials()
+	}
+	return credentials.NewTLS(tlsConfig)
+}
+
 // Disconnect closes the connection to the master
 func (mc *MasterClient) Disconnect() error {
 	mc.mu.Lock()
@@ -239,6 +263,24 @@ func (mc *MasterClient) GetClusterState(ctx context.Context) (*pb.ClusterStateRe
 	return resp, nil
 }
 
+// GetClusterSettings retrieves the persistent cluster settings from master
+func (mc *MasterClient) GetClusterSettings(ctx context.Context) (map[string]string, error) {
+	mc.mu.RLock()
+	if !mc.connected {
+		mc.mu.RUnlock()
+		return nil, fmt.Errorf("not connected to master")
+	}
+	client := mc.client
+	mc.mu.RUnlock()
+
+	resp, err := client.GetClusterSettings(ctx, &pb.GetClusterSettingsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster settings: %w", err)
+	}
+
+	return resp.Settings, nil
+}
+
 // GetIndexMetadata retrieves metadata for a specific index
 func (mc *MasterClient) GetIndexMetadata(ctx context.Context, indexName string) (*pb.IndexMetadataResponse, error) {
 	mc.mu.RLock()
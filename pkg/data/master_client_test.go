@@ -6,10 +6,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/quidditch/quidditch/pkg/common/config"
 	pb "github.com/quidditch/quidditch/pkg/common/proto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -24,6 +26,9 @@ type mockMasterServer struct {
 	unregisterFunc  func(*pb.UnregisterNodeRequest) (*pb.UnregisterNodeResponse, error)
 	getStateFunc    func(*pb.GetClusterStateRequest) (*pb.ClusterStateResponse, error)
 	getIndexFunc    func(*pb.GetIndexMetadataRequest) (*pb.IndexMetadataResponse, error)
+
+	// settings are the cluster settings the master holds
+	settings map[string]string
 }
 
 func (m *mockMasterServer) RegisterNode(ctx context.Context, req *pb.RegisterNodeRequest) (*pb.RegisterNodeResponse, error) {
@@ -80,6 +85,10 @@ func (m *mockMasterServer) GetIndexMetadata(ctx context.Context, req *pb.GetInde
 	}, nil
 }
 
+func (m *mockMasterServer) GetClusterSettings(ctx context.Context, req *pb.GetClusterSettingsRequest) (*pb.GetClusterSettingsResponse, error) {
+	return &pb.GetClusterSettingsResponse{Settings: m.settings}, nil
+}
+
 // setupMockMasterServer creates a mock master server for testing
 func setupMockMasterServer(t *testing.T, mock *mockMasterServer) (*grpc.Server, *bufconn.Listener) {
 	buffer := 1024 * 1024
@@ -395,6 +404,47 @@ func TestMasterClient_GetIndexMetadata(t *testing.T) {
 	assert.Equal(t, "test-index", resp.Metadata.IndexName)
 }
 
+func TestDataNodeRefreshLogLevel(t *testing.T) {
+	mock := &mockMasterServer{settings: map[string]string{"logger.level": "debug"}}
+
+	server, listener := setupMockMasterServer(t, mock)
+	defer server.Stop()
+
+	logger := zap.NewNop()
+	client := NewMasterClient("node-1", "bufconn", logger)
+
+	// Setup connection
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(bufDialer(listener)),
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithTimeout(5*time.Second))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client.conn = conn
+	client.client = pb.NewMasterServiceClient(conn)
+	client.connected = true
+
+	node := &DataNode{
+		cfg:          &config.DataNodeConfig{LogLevel: "info"},
+		logger:       logger,
+		masterClient: client,
+	}
+	level := zap.NewAtomicLevelAt(zapcore.WarnLevel)
+	node.SetLogLevel(level)
+
+	// The cluster setting overrides the configured level
+	node.refreshLogLevel(ctx)
+	assert.Equal(t, zapcore.DebugLevel, level.Level())
+
+	// Unset, the node returns to its configured level
+	mock.settings = nil
+	node.refreshLogLevel(ctx)
+	assert.Equal(t, zapcore.InfoLevel, level.Level())
+}
+
 func TestMasterClient_Unregister(t *testing.T) {
 	unregisterCalled := false
 	mock := &mockMasterServer{
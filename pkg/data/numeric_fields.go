@@ -0,0 +1,220 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// settingNumericFields is the index setting carrying, as JSON, the type
+// and ignore_malformed policy of each field the index's mapping maps as
+// numeric, keyed by the field's dot path
+const settingNumericFields = "index.mapping.numeric_fields"
+
+// integerRanges bounds the values of the integer field types
+var integerRanges = map[string]struct{ min, max float64 }{
+	"long":    {math.MinInt64, math.MaxInt64},
+	"integer": {math.MinInt32, math.MaxInt32},
+	"short":   {math.MinInt16, math.MaxInt16},
+	"byte":    {math.MinInt8, math.MaxInt8},
+}
+
+// floatTypes are the floating point field types
+var floatTypes = map[string]bool{"double": true, "float": true, "half_float": true}
+
+// NumericField is the mapping of a field mapped as numeric
+type NumericField struct {
+	Type string `json:"type"`
+
+	// IgnoreMalformed leaves out values that aren't numbers instead of
+	// rejecting the document
+	IgnoreMalformed bool `json:"ignore_malformed,omitempty"`
+}
+
+// shardNumericFields parses the numeric field mappings in an index's
+// settings. Indices without them coerce no field.
+func shardNumericFields(settings map[string]string) (map[string]NumericField, error) {
+	value := settings[settingNumericFields]
+	if value == "" {
+		return nil, nil
+	}
+
+	var fields map[string]NumericField
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", settingNumericFields, err)
+	}
+	for path, field := range fields {
+		if _, ok := integerRanges[field.Type]; !ok && !floatTypes[field.Type] {
+			return nil, fmt.Errorf("invalid %s: field [%s] has unknown numeric type [%s]", settingNumericFields, path, field.Type)
+		}
+	}
+	return fields, nil
+}
+
+// MalformedFieldError is returned when a document holds a value that
+// doesn't parse as the numeric type its field is mapped as
+type MalformedFieldError struct {
+	DocID string
+	Field string
+	Type  string
+	Value interface{}
+}
+
+func (e *MalformedFieldError) Error() string {
+	return fmt.Sprintf("failed to parse field [%s] of type [%s] in document with id [%s]: value [%v] is not a valid %s",
+		e.Field, e.Type, e.DocID, e.Value, e.Type)
+}
+
+// coerce parses a single value as the field's type. Numeric strings are
+// accepted, and integer types drop fractions, as a numeric field coerces
+// by default. It reports false for a value that is no number or is out of
+// the type's range.
+func (f NumericField) coerce(value interface{}) (interface{}, bool) {
+	var n float64
+	switch v := value.(type) {
+	case float64:
+		n = v
+	case float32:
+		n = float64(v)
+	case int:
+		n = float64(v)
+	case int32:
+		n = float64(v)
+	case int64:
+		if f.Type == "long" {
+			return v, true
+		}
+		n = float64(v)
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, false
+		}
+		n = parsed
+	default:
+		return nil, false
+	}
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return nil, false
+	}
+
+	bounds, isInteger := integerRanges[f.Type]
+	if !isInteger {
+		return n, true
+	}
+	n = math.Trunc(n)
+	if n < bounds.min || n > bounds.max {
+		return nil, false
+	}
+	return int64(n), true
+}
+
+// coerceNumericFields returns doc with the values of its numeric fields
+// coerced to their mapped type, so they are indexed as numbers rather
+// than text. A malformed value rejects the document unless its field
+// ignores malformed values, in which case the value is left out and its
+// field reported as ignored. doc itself is not modified.
+func coerceNumericFields(docID string, doc map[string]interface{}, fields map[string]NumericField) (map[string]interface{}, []string, error) {
+	if len(fields) == 0 {
+		return doc, nil, nil
+	}
+	var ignored []string
+	coerced, err := coerceObject(docID, "", doc, fields, &ignored)
+	if err != nil {
+		return nil, nil, err
+	}
+	return coerced, ignored, nil
+}
+
+// coerceObject coerces the numeric fields of an object whose fields' dot
+// paths start with prefix
+func coerceObject(docID, prefix string, obj map[string]interface{}, fields map[string]NumericField, ignored *[]string) (map[string]interface{}, error) {
+	coerced := make(map[string]interface{}, len(obj))
+	for name, value := range obj {
+		path := prefix + name
+		if field, ok := fields[path]; ok {
+			value, malformed, err := coerceFieldValue(docID, path, field, value)
+			if err != nil {
+				return nil, err
+			}
+			if malformed {
+				*ignored = append(*ignored, path)
+			}
+			if value != nil || !malformed {
+				coerced[name] = value
+			}
+			continue
+		}
+
+		nested, err := coerceNested(docID, path, value, fields, ignored)
+		if err != nil {
+			return nil, err
+		}
+		coerced[name] = nested
+	}
+	return coerced, nil
+}
+
+// coerceNested coerces the numeric fields of the objects a value that is
+// not itself numeric holds, directly or as array elements
+func coerceNested(docID, path string, value interface{}, fields map[string]NumericField, ignored *[]string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return coerceObject(docID, path+".", v, fields, ignored)
+	case []interface{}:
+		elements := make([]interface{}, len(v))
+		for i, element := range v {
+			coerced, err := coerceNested(docID, path, element, fields, ignored)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = coerced
+		}
+		return elements, nil
+	default:
+		return value, nil
+	}
+}
+
+// coerceFieldValue coerces the value of a numeric field, each element of
+// an array separately. It reports whether a malformed value was ignored;
+// the value is nil when nothing is left of it.
+func coerceFieldValue(docID, path string, field NumericField, value interface{}) (interface{}, bool, error) {
+	if value == nil {
+		return nil, false, nil
+	}
+
+	values, isArray := value.([]interface{})
+	if !isArray {
+		values = []interface{}{value}
+	} else if len(values) == 0 {
+		return value, false, nil
+	}
+	coerced := make([]interface{}, 0, len(values))
+	malformed := false
+	for _, element := range values {
+		if element == nil {
+			coerced = append(coerced, nil)
+			continue
+		}
+		n, ok := field.coerce(element)
+		if !ok {
+			if field.IgnoreMalformed {
+				malformed = true
+				continue
+			}
+			return nil, false, &MalformedFieldError{DocID: docID, Field: path, Type: field.Type, Value: element}
+		}
+		coerced = append(coerced, n)
+	}
+
+	if len(coerced) == 0 {
+		return nil, malformed, nil
+	}
+	if isArray {
+		return coerced, malformed, nil
+	}
+	return coerced[0], malformed, nil
+}
@@ -2,15 +2,21 @@ package data
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/quidditch/quidditch/pkg/common/config"
+	"github.com/quidditch/quidditch/pkg/data/analysis"
+	"github.com/quidditch/quidditch/pkg/data/breaker"
 	"github.com/quidditch/quidditch/pkg/data/diagon"
+	"github.com/quidditch/quidditch/pkg/data/suggest"
 	"github.com/quidditch/quidditch/pkg/wasm"
 	"go.uber.org/zap"
 )
@@ -21,6 +27,7 @@ type ShardManager struct {
 	logger    *zap.Logger
 	diagon    *diagon.DiagonBridge
 	udfFilter *UDFFilter
+	breaker   *breaker.Breaker  // Shared fielddata breaker for aggregations
 	shards    map[string]*Shard // key: "index:shardID"
 	mu        sync.RWMutex
 }
@@ -35,6 +42,7 @@ func NewShardManager(cfg *config.DataNodeConfig, logger *zap.Logger, diagon *dia
 		logger:    logger,
 		diagon:    diagon,
 		udfFilter: udfFilter,
+		breaker:   breaker.New(breaker.FieldData, cfg.FieldDataBreakerLimit),
 		shards:    make(map[string]*Shard),
 	}
 }
@@ -69,8 +77,49 @@ func (sm *ShardManager) Stop(ctx context.Context) error {
 	return nil
 }
 
+// Index settings configuring a shard's Diagon index
+const (
+	settingStoreType  = "index.store.type"
+	settingBufferSize = "index.buffer_size_mb"
+)
+
+// shardSettingsFile records the index settings a shard was created with in
+// its directory, so the shard is reopened the same way after a restart
+const shardSettingsFile = "shard_settings.json"
+
 // CreateShard creates a new shard
 func (sm *ShardManager) CreateShard(ctx context.Context, indexName string, shardID int32, isPrimary bool) error {
+	return sm.CreateShardWithSettings(ctx, indexName, shardID, isPrimary, nil)
+}
+
+// CreateShardWithSettings creates a new shard configured by its index's
+// settings, such as index.store.type and index.buffer_size_mb
+func (sm *ShardManager) CreateShardWithSettings(ctx context.Context, indexName string, shardID int32, isPrimary bool, settings map[string]string) error {
+	opts, err := shardOptions(settings)
+	if err != nil {
+		return err
+	}
+	analyzerSettings, err := shardAnalyzerSettings(settings)
+	if err != nil {
+		return err
+	}
+	limits, err := shardDocumentLimits(settings)
+	if err != nil {
+		return err
+	}
+	numericFields, err := shardNumericFields(settings)
+	if err != nil {
+		return err
+	}
+	dateFields, err := shardDateFields(settings)
+	if err != nil {
+		return err
+	}
+	geoPointFields, err := shardGeoPointFields(settings)
+	if err != nil {
+		return err
+	}
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
@@ -92,27 +141,40 @@ func (sm *ShardManager) CreateShard(ctx context.Context, indexName string, shard
 		return fmt.Errorf("failed to create shard directory: %w", err)
 	}
 
+	if err := writeShardSettings(shardPath, settings); err != nil {
+		return fmt.Errorf("failed to record shard settings: %w", err)
+	}
+
 	// Create shard using Diagon
-	diagonShard, err := sm.diagon.CreateShard(shardPath)
+	diagonShard, err := sm.diagon.CreateShard(shardPath, opts)
 	if err != nil {
 		return fmt.Errorf("failed to create Diagon shard: %w", err)
 	}
 
-	// Create shard wrapper with default analyzer settings
+	// Create shard wrapper with the index's analyzer settings
 	shard := &Shard{
-		IndexName:        indexName,
-		ShardID:          shardID,
-		IsPrimary:        isPrimary,
-		Path:             shardPath,
-		State:            ShardStateInitializing,
-		DiagonShard:      diagonShard,
-		udfFilter:        sm.udfFilter,
-		DocsCount:        0,
-		SizeBytes:        0,
-		logger:           sm.logger.With(zap.String("shard", key)),
-		analyzerSettings: DefaultAnalyzerSettings(), // Use default analyzer settings
-		analyzerCache:    NewAnalyzerCache(),        // Create analyzer cache
+		IndexName:     indexName,
+		ShardID:       shardID,
+		IsPrimary:     isPrimary,
+		Path:          shardPath,
+		State:         ShardStateInitializing,
+		StoreType:     opts.StoreType,
+		DiagonShard:   diagonShard,
+		Limits:        limits,
+		NumericFields: numericFields,
+		udfFilter:     sm.udfFilter,
+		breaker:       sm.breaker,
+		DocsCount:     0,
+		SizeBytes:     0,
+		logger:        sm.logger.With(zap.String("shard", key)),
+		analyzerCache: NewAnalyzerCache(), // Create analyzer cache
 	}
+	if err := shard.SetAnalyzerSettings(analyzerSettings); err != nil {
+		shard.Close()
+		return fmt.Errorf("failed to set up analyzers: %w", err)
+	}
+	shard.SetDateFields(dateFields)
+	shard.SetGeoPointFields(geoPointFields)
 
 	sm.shards[key] = shard
 
@@ -123,11 +185,133 @@ func (sm *ShardManager) CreateShard(ctx context.Context, indexName string, shard
 		zap.String("index", indexName),
 		zap.Int32("shard_id", shardID),
 		zap.Bool("is_primary", isPrimary),
+		zap.String("store_type", string(opts.StoreType)),
+		zap.Float64("ram_buffer_size_mb", diagonShard.RAMBufferSizeMB()),
 		zap.String("path", shardPath))
 
 	return nil
 }
 
+// shardOptions parses the index settings configuring a shard's Diagon
+// index. Settings left unset use the node's defaults.
+func shardOptions(settings map[string]string) (diagon.ShardOptions, error) {
+	storeType, err := diagon.ParseStoreType(settings[settingStoreType])
+	if err != nil {
+		return diagon.ShardOptions{}, err
+	}
+	bufferSizeMB, err := diagon.ParseRAMBufferSize(settings[settingBufferSize])
+	if err != nil {
+		return diagon.ShardOptions{}, err
+	}
+	return diagon.ShardOptions{StoreType: storeType, RAMBufferSizeMB: bufferSizeMB}, nil
+}
+
+// writeShardSettings records the settings configuring a shard's Diagon
+// index and its analyzers in its directory
+func writeShardSettings(shardPath string, settings map[string]string) error {
+	recorded := make(map[string]string)
+	for _, key := range []string{settingStoreType, settingBufferSize, settingAnalysis, settingMaxFieldValueLength, settingMaxDocumentSize, settingNumericFields, settingDateFields, settingGeoPointFields} {
+		if value, ok := settings[key]; ok && value != "" {
+			recorded[key] = value
+		}
+	}
+	data, err := json.Marshal(recorded)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(shardPath, shardSettingsFile), data, 0644)
+}
+
+// readShardSettings returns the settings recorded in a shard's directory,
+// or none for shards created before settings were recorded
+func readShardSettings(shardPath string) (map[string]string, error) {
+	var settings map[string]string
+	data, err := os.ReadFile(filepath.Join(shardPath, shardSettingsFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return nil, fmt.Errorf("corrupt shard settings: %w", err)
+		}
+	}
+	return settings, nil
+}
+
+// readShardOptions returns the options recorded in a shard's directory, or
+// the defaults for shards created before settings were recorded
+func readShardOptions(shardPath string) (diagon.ShardOptions, error) {
+	settings, err := readShardSettings(shardPath)
+	if err != nil {
+		return diagon.ShardOptions{}, err
+	}
+	return shardOptions(settings)
+}
+
+// mappingSettings are the index settings derived from the index's mapping,
+// which a PUT _mapping can change while its shards are open
+var mappingSettings = []string{settingAnalysis, settingNumericFields, settingDateFields, settingGeoPointFields}
+
+// UpdateIndexSettings applies an index's mapping-derived settings, its
+// analyzers and numeric, date and geo_point fields, to each of its open
+// shards and records them in the shard directories, so the shards index new
+// documents the way the updated mapping says. It returns the number of shards updated.
+func (sm *ShardManager) UpdateIndexSettings(ctx context.Context, indexName string, settings map[string]string) (int, error) {
+	analyzerSettings, err := shardAnalyzerSettings(settings)
+	if err != nil {
+		return 0, err
+	}
+	numericFields, err := shardNumericFields(settings)
+	if err != nil {
+		return 0, err
+	}
+	dateFields, err := shardDateFields(settings)
+	if err != nil {
+		return 0, err
+	}
+	geoPointFields, err := shardGeoPointFields(settings)
+	if err != nil {
+		return 0, err
+	}
+
+	sm.mu.RLock()
+	var shards []*Shard
+	for _, shard := range sm.shards {
+		if shard.IndexName == indexName {
+			shards = append(shards, shard)
+		}
+	}
+	sm.mu.RUnlock()
+
+	for _, shard := range shards {
+		recorded, err := readShardSettings(shard.Path)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read settings of shard %d: %w", shard.ShardID, err)
+		}
+		if recorded == nil {
+			recorded = make(map[string]string)
+		}
+		for _, key := range mappingSettings {
+			recorded[key] = settings[key]
+		}
+		if err := writeShardSettings(shard.Path, recorded); err != nil {
+			return 0, fmt.Errorf("failed to record settings of shard %d: %w", shard.ShardID, err)
+		}
+
+		if err := shard.SetAnalyzerSettings(analyzerSettings); err != nil {
+			return 0, fmt.Errorf("failed to set up analyzers of shard %d: %w", shard.ShardID, err)
+		}
+		shard.SetNumericFields(numericFields)
+		shard.SetDateFields(dateFields)
+		shard.SetGeoPointFields(geoPointFields)
+	}
+
+	sm.logger.Info("Updated index settings of shards",
+		zap.String("index", indexName),
+		zap.Int("shards", len(shards)))
+	return len(shards), nil
+}
+
 // DeleteShard deletes a shard
 func (sm *ShardManager) DeleteShard(ctx context.Context, indexName string, shardID int32) error {
 	sm.mu.Lock()
@@ -263,8 +447,42 @@ func (sm *ShardManager) loadShards() error {
 				continue
 			}
 
+			var opts diagon.ShardOptions
+			var analyzerSettings *AnalyzerSettings
+			var limits DocumentLimits
+			var numericFields map[string]NumericField
+			var dateFields map[string]DateField
+			var geoPointFields []string
+			settings, err := readShardSettings(shardPath)
+			if err == nil {
+				opts, err = shardOptions(settings)
+			}
+			if err == nil {
+				analyzerSettings, err = shardAnalyzerSettings(settings)
+			}
+			if err == nil {
+				limits, err = shardDocumentLimits(settings)
+			}
+			if err == nil {
+				numericFields, err = shardNumericFields(settings)
+			}
+			if err == nil {
+				dateFields, err = shardDateFields(settings)
+			}
+			if err == nil {
+				geoPointFields, err = shardGeoPointFields(settings)
+			}
+			if err != nil {
+				sm.logger.Error("Failed to read shard settings",
+					zap.String("index", indexName),
+					zap.Int64("shard_id", shardID),
+					zap.String("path", shardPath),
+					zap.Error(err))
+				continue
+			}
+
 			// Create/open the Diagon shard
-			diagonShard, err := sm.diagon.CreateShard(shardPath)
+			diagonShard, err := sm.diagon.CreateShard(shardPath, opts)
 			if err != nil {
 				sm.logger.Error("Failed to load shard from disk",
 					zap.String("index", indexName),
@@ -273,22 +491,36 @@ func (sm *ShardManager) loadShards() error {
 					zap.Error(err))
 				continue
 			}
+			sizeBytes, _ := dirSize(shardPath)
 
 			// Create shard wrapper
 			shard := &Shard{
-				IndexName:        indexName,
-				ShardID:          int32(shardID),
-				IsPrimary:        false, // Will be set by master during registration
-				Path:             shardPath,
-				State:            ShardStateStarted,
-				DiagonShard:      diagonShard,
-				udfFilter:        sm.udfFilter,
-				DocsCount:        0, // TODO: Could load actual count from Diagon
-				SizeBytes:        0, // TODO: Could calculate from disk
-				logger:           sm.logger.With(zap.String("shard", key)),
-				analyzerSettings: DefaultAnalyzerSettings(), // Use default analyzer settings
-				analyzerCache:    NewAnalyzerCache(),        // Create analyzer cache
+				IndexName:     indexName,
+				ShardID:       int32(shardID),
+				IsPrimary:     false, // Will be set by master during registration
+				Path:          shardPath,
+				State:         ShardStateStarted,
+				StoreType:     opts.StoreType,
+				DiagonShard:   diagonShard,
+				Limits:        limits,
+				NumericFields: numericFields,
+				udfFilter:     sm.udfFilter,
+				breaker:       sm.breaker,
+				DocsCount:     0, // TODO: Could load actual count from Diagon
+				SizeBytes:     sizeBytes,
+				logger:        sm.logger.With(zap.String("shard", key)),
+				analyzerCache: NewAnalyzerCache(), // Create analyzer cache
 			}
+			if err := shard.SetAnalyzerSettings(analyzerSettings); err != nil {
+				sm.logger.Error("Failed to set up shard analyzers",
+					zap.String("index", indexName),
+					zap.Int64("shard_id", shardID),
+					zap.Error(err))
+				shard.Close()
+				continue
+			}
+			shard.SetDateFields(dateFields)
+			shard.SetGeoPointFields(geoPointFields)
 
 			sm.mu.Lock()
 			sm.shards[key] = shard
@@ -320,14 +552,24 @@ type Shard struct {
 	IsPrimary        bool
 	Path             string
 	State            ShardState
+	StoreType        diagon.StoreType // Directory type the shard's files are read through
 	DiagonShard      *diagon.Shard
+	Limits           DocumentLimits          // Bounds of the documents the shard indexes
+	NumericFields    map[string]NumericField // Fields mapped as numeric, by dot path
 	udfFilter        *UDFFilter
+	breaker          *breaker.Breaker
 	DocsCount        int64
 	SizeBytes        int64
+	indexing         operationCounter // Documents indexed
+	searches         operationCounter // Queries executed
+	refreshes        operationCounter // Refreshes requested
+	merges           operationCounter // Force merges
 	logger           *zap.Logger
 	mu               sync.RWMutex
-	analyzerSettings *AnalyzerSettings // Analyzer configuration for this shard
-	analyzerCache    *AnalyzerCache    // Cached analyzer instances
+	analyzerSettings *AnalyzerSettings  // Analyzer configuration for this shard
+	analyzerRegistry *analysis.Registry // Built-in and custom analyzers of the settings
+	analyzerCache    *AnalyzerCache     // Cached analyzer instances
+	suggestions      suggest.Index      // Terms and completion inputs of suggested fields
 }
 
 // ShardState represents the state of a shard
@@ -340,11 +582,72 @@ const (
 	ShardStateClosed       ShardState = "closed"
 )
 
-// SetAnalyzerSettings updates the analyzer settings for this shard
-func (s *Shard) SetAnalyzerSettings(settings *AnalyzerSettings) {
+// SetAnalyzerSettings updates the analyzer settings for this shard and maps
+// the fields they select an analyzer for, so those fields are analyzed the
+// same way at index and query time
+func (s *Shard) SetAnalyzerSettings(settings *AnalyzerSettings) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+
+	registry, err := settings.Registry()
+	if err != nil {
+		return err
+	}
+	analyzers := make(map[string]diagon.FieldAnalyzer, len(settings.FieldAnalyzers))
+	for field, name := range settings.FieldAnalyzers {
+		analyzer, err := resolveAnalyzer(registry, s.analyzerCache, name)
+		if err != nil {
+			return fmt.Errorf("invalid analyzer for field %s: %w", field, err)
+		}
+		analyzers[field] = analyzer
+	}
+
+	// Fields the previous settings mapped go back to Diagon's analysis
+	if s.analyzerSettings != nil {
+		for field := range s.analyzerSettings.FieldAnalyzers {
+			if _, ok := analyzers[field]; !ok {
+				s.DiagonShard.SetFieldAnalyzer(field, nil)
+			}
+		}
+	}
+	for field, analyzer := range analyzers {
+		s.DiagonShard.SetFieldAnalyzer(field, analyzer)
+	}
+
 	s.analyzerSettings = settings
+	s.analyzerRegistry = registry
+	s.suggestions.Reset()
+	return nil
+}
+
+// SetNumericFields replaces the fields the shard coerces to numbers
+func (s *Shard) SetNumericFields(fields map[string]NumericField) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.NumericFields = fields
+}
+
+// SetDateField maps a field as a date parsed with the given formats (ISO-8601
+// or epoch millis when none are given); dates are indexed as epoch millis
+func (s *Shard) SetDateField(field string, formats ...string) {
+	s.DiagonShard.SetDateField(field, formats...)
+}
+
+// SetDateFields maps the fields an index's mapping maps as dates, so their
+// values are parsed with the mapped formats
+func (s *Shard) SetDateFields(fields map[string]DateField) {
+	for field, date := range fields {
+		s.DiagonShard.SetDateField(field, date.Formats...)
+	}
+}
+
+// SetGeoPointFields maps the fields an index's mapping maps as geo_point,
+// so "lat,lon" strings and [lon, lat] arrays are indexed as points for
+// geo_distance queries
+func (s *Shard) SetGeoPointFields(fields []string) {
+	for _, field := range fields {
+		s.DiagonShard.SetGeoPointField(field)
+	}
 }
 
 // GetAnalyzerSettings returns the current analyzer settings
@@ -359,57 +662,81 @@ func (s *Shard) AnalyzeText(fieldName, text string) ([]string, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if s.analyzerSettings == nil || s.analyzerCache == nil {
+	if s.analyzerSettings == nil || s.analyzerRegistry == nil || s.analyzerCache == nil {
 		return nil, fmt.Errorf("analyzer settings not initialized")
 	}
 
-	return AnalyzeField(s.analyzerCache, s.analyzerSettings, fieldName, text)
+	analyzerName := s.analyzerSettings.GetAnalyzerForField(fieldName)
+	analyzer, err := resolveAnalyzer(s.analyzerRegistry, s.analyzerCache, analyzerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get analyzer %s: %w", analyzerName, err)
+	}
+	return analyzer.AnalyzeToStrings(text)
 }
 
-// IndexDocument indexes a document in the shard
+// IndexDocument indexes a new document in the shard. Diagon can't replace
+// a document, so one with the _id of an existing document is rejected with
+// diagon.ErrReplaceUnsupported; the check is made under the shard's write
+// lock, so a concurrent write of the same document can't slip past it.
 func (s *Shard) IndexDocument(ctx context.Context, docID string, doc map[string]interface{}) error {
+	start := time.Now()
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.logger.Info("==> Shard.IndexDocument ENTRY",
-		zap.String("index", s.IndexName),
-		zap.Int32("shard_id", s.ShardID),
-		zap.String("doc_id", docID))
-
 	if s.State != ShardStateStarted {
 		return fmt.Errorf("shard is not ready")
 	}
 
+	// Reject oversized documents before Diagon builds them in memory
+	if err := s.Limits.Check(docID, doc); err != nil {
+		return err
+	}
+
+	// Coerce mapped numeric fields to their type, so they are indexed as
+	// numbers whatever JSON type they were sent as
+	doc, ignored, err := coerceNumericFields(docID, doc, s.NumericFields)
+	if err != nil {
+		return err
+	}
+	if len(ignored) > 0 {
+		s.logger.Debug("Ignored malformed field values",
+			zap.String("doc_id", docID),
+			zap.Strings("fields", ignored))
+	}
+
+	// Every write refreshes the reader, so it sees all the shard's
+	// documents
+	exists, err := s.DiagonShard.HasDocument(docID)
+	if err != nil {
+		return fmt.Errorf("failed to look up document: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("[%s]: %w", docID, diagon.ErrReplaceUnsupported)
+	}
+
 	// Index document using Diagon
-	s.logger.Info("Calling DiagonShard.IndexDocument", zap.String("doc_id", docID))
 	if err := s.DiagonShard.IndexDocument(docID, doc); err != nil {
-		s.logger.Error("DiagonShard.IndexDocument FAILED", zap.Error(err))
 		return fmt.Errorf("failed to index document: %w", err)
 	}
 
-	s.logger.Info("DiagonShard.IndexDocument SUCCESS", zap.String("doc_id", docID))
-
-	// CRITICAL FIX: Commit the document to disk so it's searchable
-	s.logger.Info("Calling DiagonShard.Commit to flush to disk", zap.String("doc_id", docID))
+	// Commit the document to disk, since there is no translog to replay
+	// it from, and refresh the reader so searches see it
 	if err := s.DiagonShard.Commit(); err != nil {
-		s.logger.Error("DiagonShard.Commit FAILED", zap.Error(err))
 		return fmt.Errorf("failed to commit document: %w", err)
 	}
-
-	s.logger.Info("DiagonShard.Commit SUCCESS - document now on disk", zap.String("doc_id", docID))
-
-	// CRITICAL FIX: Refresh the reader so searches can see the new document
-	s.logger.Info("Calling DiagonShard.Refresh to reopen reader", zap.String("doc_id", docID))
 	if err := s.DiagonShard.Refresh(); err != nil {
-		s.logger.Error("DiagonShard.Refresh FAILED", zap.Error(err))
 		return fmt.Errorf("failed to refresh reader: %w", err)
 	}
 
-	s.logger.Info("DiagonShard.Refresh SUCCESS - document now searchable", zap.String("doc_id", docID))
+	// Keep the fields suggesters have run on current
+	if analyze, err := s.suggestAnalyzer(); err == nil {
+		s.suggestions.Update(docID, doc, analyze)
+	}
 
 	s.DocsCount++
+	s.indexing.observe(start)
 
-	s.logger.Info("Indexed document successfully",
+	s.logger.Debug("Indexed document",
 		zap.String("doc_id", docID),
 		zap.Int64("docs_count", s.DocsCount))
 
@@ -418,6 +745,13 @@ func (s *Shard) IndexDocument(ctx context.Context, docID string, doc map[string]
 
 // Search executes a search query on the shard
 func (s *Shard) Search(ctx context.Context, query []byte) (*diagon.SearchResult, error) {
+	return s.SearchWithOptions(ctx, query, diagon.SearchOptions{})
+}
+
+// SearchWithOptions executes a search query on the shard, applying the
+// collection options such as terminate_after
+func (s *Shard) SearchWithOptions(ctx context.Context, query []byte, opts diagon.SearchOptions) (*diagon.SearchResult, error) {
+	start := time.Now()
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -425,11 +759,34 @@ func (s *Shard) Search(ctx context.Context, query []byte) (*diagon.SearchResult,
 		return nil, fmt.Errorf("shard is not ready")
 	}
 
-	// Execute search using Diagon (pass empty filterExpression)
-	result, err := s.DiagonShard.Search(query, nil)
+	// Buckets are built for every matching document, so reserve the
+	// estimated aggregation memory against the fielddata breaker before the
+	// search collects them. Matches aren't counted until then, so the
+	// shard's live documents bound them, and with no per-field statistics
+	// in Diagon they bound each field's distinct values too.
+	if aggs := requestAggregations(query); len(aggs) > 0 && s.breaker != nil {
+		docCount, err := s.DiagonShard.NumDocs()
+		if err != nil {
+			return nil, fmt.Errorf("failed to count documents: %w", err)
+		}
+		reserved, err := s.breaker.ReserveAggregations(aggs, docCount, nil)
+		if err != nil {
+			s.logger.Warn("Aggregation rejected by circuit breaker",
+				zap.String("index", s.IndexName),
+				zap.Int32("shard_id", s.ShardID),
+				zap.Error(err))
+			return nil, err
+		}
+		defer s.breaker.Release(reserved)
+	}
+
+	// Execute search using Diagon (pass empty filterExpression); the
+	// request deadline cuts hit collection short
+	result, err := s.DiagonShard.SearchWithOptions(ctx, query, nil, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute search: %w", err)
 	}
+	s.searches.observe(start)
 
 	s.logger.Debug("Executed search",
 		zap.Int64("total_hits", result.TotalHits),
@@ -455,6 +812,34 @@ func (s *Shard) Search(ctx context.Context, query []byte) (*diagon.SearchResult,
 	return result, nil
 }
 
+// SortResults computes the sort keys of the _script sort clauses sent with
+// a search by calling their UDFs on each hit
+func (s *Shard) SortResults(ctx context.Context, sortClauses []string, result *diagon.SearchResult) error {
+	if len(sortClauses) == 0 {
+		return nil
+	}
+	if s.udfFilter == nil || s.udfFilter.registry == nil {
+		return fmt.Errorf("_script sort requires a UDF registry")
+	}
+	return s.udfFilter.SortResults(ctx, sortClauses, result)
+}
+
+// requestAggregations returns the "aggs" (or "aggregations") section sent
+// with a shard query, if any
+func requestAggregations(query []byte) map[string]interface{} {
+	var request struct {
+		Aggs         map[string]interface{} `json:"aggs"`
+		Aggregations map[string]interface{} `json:"aggregations"`
+	}
+	if err := json.Unmarshal(query, &request); err != nil {
+		return nil
+	}
+	if len(request.Aggs) > 0 {
+		return request.Aggs
+	}
+	return request.Aggregations
+}
+
 // GetDocument retrieves a document by ID
 func (s *Shard) GetDocument(ctx context.Context, docID string) (map[string]interface{}, error) {
 	s.mu.RLock()
@@ -473,6 +858,19 @@ func (s *Shard) GetDocument(ctx context.Context, docID string) (map[string]inter
 	return doc, nil
 }
 
+// ScanDocuments returns up to size documents of the shard starting at
+// position from, in index order
+func (s *Shard) ScanDocuments(ctx context.Context, from, size int) ([]diagon.Hit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.State != ShardStateStarted {
+		return nil, fmt.Errorf("shard is not ready")
+	}
+
+	return s.DiagonShard.ScanDocuments(from, size)
+}
+
 // DeleteDocument deletes a document by ID
 func (s *Shard) DeleteDocument(ctx context.Context, docID string) error {
 	s.mu.Lock()
@@ -487,6 +885,7 @@ func (s *Shard) DeleteDocument(ctx context.Context, docID string) error {
 		return fmt.Errorf("failed to delete document: %w", err)
 	}
 
+	s.suggestions.Delete(docID)
 	s.DocsCount--
 
 	s.logger.Debug("Deleted document", zap.String("doc_id", docID))
@@ -496,6 +895,7 @@ func (s *Shard) DeleteDocument(ctx context.Context, docID string) error {
 
 // Refresh refreshes the shard (makes recent changes visible)
 func (s *Shard) Refresh() error {
+	start := time.Now()
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -507,6 +907,7 @@ func (s *Shard) Refresh() error {
 	if err := s.DiagonShard.Refresh(); err != nil {
 		return fmt.Errorf("failed to refresh shard: %w", err)
 	}
+	s.refreshes.observe(start)
 
 	s.logger.Debug("Refreshed shard")
 
@@ -532,6 +933,71 @@ func (s *Shard) Flush() error {
 	return nil
 }
 
+// ForceMergeResult reports the deleted documents and disk space of a shard
+// before and after a force merge
+type ForceMergeResult struct {
+	DocsDeletedBefore int64
+	DocsDeletedAfter  int64
+	SizeBytesBefore   int64
+	SizeBytesAfter    int64
+}
+
+// ForceMerge merges the shard's segments down to at most maxNumSegments,
+// reclaiming the space held by deleted documents
+func (s *Shard) ForceMerge(ctx context.Context, maxNumSegments int) (*ForceMergeResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.State != ShardStateStarted {
+		return nil, fmt.Errorf("shard is not ready")
+	}
+
+	result := &ForceMergeResult{}
+	var err error
+	if result.DocsDeletedBefore, err = s.DiagonShard.DeletedDocs(); err != nil {
+		return nil, fmt.Errorf("failed to count deleted documents: %w", err)
+	}
+	if result.SizeBytesBefore, err = dirSize(s.Path); err != nil {
+		return nil, fmt.Errorf("failed to measure shard size: %w", err)
+	}
+
+	start := time.Now()
+	if err := s.DiagonShard.ForceMerge(maxNumSegments); err != nil {
+		return nil, fmt.Errorf("failed to force merge shard: %w", err)
+	}
+	s.merges.observe(start)
+
+	if result.DocsDeletedAfter, err = s.DiagonShard.DeletedDocs(); err != nil {
+		return nil, fmt.Errorf("failed to count deleted documents: %w", err)
+	}
+	if result.SizeBytesAfter, err = dirSize(s.Path); err != nil {
+		return nil, fmt.Errorf("failed to measure shard size: %w", err)
+	}
+	s.SizeBytes = result.SizeBytesAfter
+
+	s.logger.Info("Force merged shard",
+		zap.Int("max_num_segments", maxNumSegments),
+		zap.Int64("docs_deleted_before", result.DocsDeletedBefore),
+		zap.Int64("docs_deleted_after", result.DocsDeletedAfter),
+		zap.Int64("size_bytes_before", result.SizeBytesBefore),
+		zap.Int64("size_bytes_after", result.SizeBytesAfter))
+
+	return result, nil
+}
+
+// Segments returns the segments of the shard's index, so operators can see
+// how much merging is pending
+func (s *Shard) Segments() ([]diagon.SegmentInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.State != ShardStateStarted {
+		return nil, fmt.Errorf("shard is not ready")
+	}
+
+	return s.DiagonShard.Segments()
+}
+
 // Close closes the shard
 func (s *Shard) Close() error {
 	s.mu.Lock()
@@ -558,27 +1024,78 @@ func (s *Shard) Close() error {
 	return nil
 }
 
-// Stats returns shard statistics
+// Stats returns shard statistics. The store size is measured from the
+// shard's files each time, so it reflects flushes, merges and the space
+// deleted documents hold until they are merged away. The operation
+// counters cover the shard's lifetime on this node.
 func (s *Shard) Stats() *ShardStats {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	size, err := dirSize(s.Path)
+	if err != nil {
+		s.logger.Warn("Failed to measure shard size", zap.Error(err))
+	}
+	var deletedDocs int64
+	if s.DiagonShard != nil {
+		deletedDocs, _ = s.DiagonShard.DeletedDocs()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		s.SizeBytes = size
+	}
 
 	return &ShardStats{
-		IndexName: s.IndexName,
-		ShardID:   s.ShardID,
-		IsPrimary: s.IsPrimary,
-		State:     s.State,
-		DocsCount: s.DocsCount,
-		SizeBytes: s.SizeBytes,
+		IndexName:   s.IndexName,
+		ShardID:     s.ShardID,
+		IsPrimary:   s.IsPrimary,
+		State:       s.State,
+		DocsCount:   s.DocsCount,
+		DocsDeleted: deletedDocs,
+		SizeBytes:   s.SizeBytes,
+		Indexing:    s.indexing.stats(),
+		Search:      s.searches.stats(),
+		Refresh:     s.refreshes.stats(),
+		Merge:       s.merges.stats(),
 	}
 }
 
 // ShardStats represents shard statistics
 type ShardStats struct {
-	IndexName string
-	ShardID   int32
-	IsPrimary bool
-	State     ShardState
-	DocsCount int64
-	SizeBytes int64
+	IndexName   string
+	ShardID     int32
+	IsPrimary   bool
+	State       ShardState
+	DocsCount   int64
+	DocsDeleted int64
+	SizeBytes   int64
+	Indexing    OperationStats
+	Search      OperationStats
+	Refresh     OperationStats
+	Merge       OperationStats
+}
+
+// OperationStats counts the executions of an operation on a shard and the
+// time they took
+type OperationStats struct {
+	Total int64
+	Time  time.Duration
+}
+
+// operationCounter counts the executions of an operation, which may run
+// concurrently, and the time they took
+type operationCounter struct {
+	total atomic.Int64
+	nanos atomic.Int64
+}
+
+// observe counts an execution that started at start and just finished
+func (c *operationCounter) observe(start time.Time) {
+	c.total.Add(1)
+	c.nanos.Add(int64(time.Since(start)))
+}
+
+// stats returns the counts so far
+func (c *operationCounter) stats() OperationStats {
+	return OperationStats{Total: c.total.Load(), Time: time.Duration(c.nanos.Load())}
 }
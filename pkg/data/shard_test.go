@@ -3,10 +3,15 @@ package data
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/quidditch/quidditch/pkg/common/config"
+	"github.com/quidditch/quidditch/pkg/data/analysis"
 	"github.com/quidditch/quidditch/pkg/data/diagon"
+	"github.com/quidditch/quidditch/pkg/data/suggest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -245,9 +250,1021 @@ func TestShard_IndexDocument(t *testing.T) {
 	err = shard.IndexDocument(ctx, "doc-2", doc)
 	assert.NoError(t, err)
 	assert.Equal(t, int64(2), shard.DocsCount)
+
+	// Indexing an existing id is rejected rather than appending a second
+	// copy, so the original stays visible and counted once
+	err = shard.IndexDocument(ctx, "doc-1", map[string]interface{}{"title": "Replacement"})
+	assert.ErrorIs(t, err, diagon.ErrReplaceUnsupported)
+	assert.Equal(t, int64(2), shard.DocsCount)
+	numDocs, err := shard.DiagonShard.NumDocs()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), numDocs)
+
+	retrieved, err := shard.GetDocument(ctx, "doc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Test Document", retrieved["title"])
+}
+
+func TestShard_GetDocument(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   "/tmp/test-data",
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	// Create a shard
+	sm.CreateShard(ctx, "test-index", 0, true)
+	shard, err := sm.GetShard("test-index", 0)
+	require.NoError(t, err)
+
+	// Index a document
+	doc := map[string]interface{}{
+		"title": "Test Document",
+		"body":  "This is a test",
+	}
+	err = shard.IndexDocument(ctx, "doc-1", doc)
+	require.NoError(t, err)
+
+	// Get the document
+	retrievedDoc, err := shard.GetDocument(ctx, "doc-1")
+	assert.NoError(t, err)
+	assert.NotNil(t, retrievedDoc)
+	assert.Equal(t, "Test Document", retrievedDoc["title"])
+
+	// Try to get non-existent document
+	_, err = shard.GetDocument(ctx, "non-existent")
+	assert.Error(t, err)
+}
+
+func TestShard_DeleteDocument(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   "/tmp/test-data",
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	// Create a shard
+	sm.CreateShard(ctx, "test-index", 0, true)
+	shard, err := sm.GetShard("test-index", 0)
+	require.NoError(t, err)
+
+	// Index a document
+	doc := map[string]interface{}{
+		"title": "Test Document",
+	}
+	err = shard.IndexDocument(ctx, "doc-1", doc)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), shard.DocsCount)
+
+	// Delete the document
+	err = shard.DeleteDocument(ctx, "doc-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), shard.DocsCount)
+}
+
+func TestShard_Search(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   "/tmp/test-data",
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	// Create a shard
+	sm.CreateShard(ctx, "test-index", 0, true)
+	shard, err := sm.GetShard("test-index", 0)
+	require.NoError(t, err)
+
+	// Index some documents
+	docs := []map[string]interface{}{
+		{"title": "First Document", "body": "Test content"},
+		{"title": "Second Document", "body": "More test content"},
+	}
+	for i, doc := range docs {
+		err = shard.IndexDocument(ctx, fmt.Sprintf("doc-%d", i), doc)
+		require.NoError(t, err)
+	}
+
+	// Execute search (empty query for now)
+	query := []byte("{}")
+	result, err := shard.Search(ctx, query)
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+}
+
+func TestShard_SearchAggregationBreaker(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   t.TempDir(),
+		MaxShards: 10,
+		// Room for a terms aggregation's buckets over two documents only
+		FieldDataBreakerLimit: 200,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	require.NoError(t, sm.CreateShard(ctx, "breaker-index", 0, true))
+	shard, err := sm.GetShard("breaker-index", 0)
+	require.NoError(t, err)
+
+	search := func() error {
+		_, err := shard.Search(ctx, []byte(`{"term": {"category": "none"}, "aggs": {"categories": {"terms": {"field": "category"}}}}`))
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		require.NoError(t, shard.IndexDocument(ctx, fmt.Sprintf("doc-%d", i), map[string]interface{}{"category": "books"}))
+	}
+	require.NoError(t, search())
+
+	// The reservation is checked before the search runs, against every
+	// document the aggregation could collect, even when none match
+	require.NoError(t, shard.IndexDocument(ctx, "doc-2", map[string]interface{}{"category": "books"}))
+	assert.Error(t, search())
+	assert.Zero(t, sm.breaker.Used())
+}
+
+func TestShard_SearchTerminateAfter(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   "/tmp/test-data",
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	sm.CreateShard(ctx, "terminate-index", 0, true)
+	shard, err := sm.GetShard("terminate-index", 0)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		err = shard.IndexDocument(ctx, fmt.Sprintf("doc-%d", i), map[string]interface{}{"status": "active"})
+		require.NoError(t, err)
+	}
+
+	query := []byte(`{"term": {"status": "active"}}`)
+
+	// Collection stops after two matches
+	result, err := shard.SearchWithOptions(ctx, query, diagon.SearchOptions{TerminateAfter: 2})
+	require.NoError(t, err)
+	assert.True(t, result.TerminatedEarly)
+	assert.Equal(t, int64(2), result.TotalHits)
+	assert.LessOrEqual(t, len(result.Hits), 2)
+
+	// A limit above the match count doesn't terminate early
+	result, err = shard.SearchWithOptions(ctx, query, diagon.SearchOptions{TerminateAfter: 10})
+	require.NoError(t, err)
+	assert.False(t, result.TerminatedEarly)
+	assert.Equal(t, int64(5), result.TotalHits)
+}
+
+func TestShard_SearchMinScore(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   "/tmp/test-data",
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	sm.CreateShard(ctx, "min-score-index", 0, true)
+	shard, err := sm.GetShard("min-score-index", 0)
+	require.NoError(t, err)
+
+	for i := 0; i < 12; i++ {
+		err = shard.IndexDocument(ctx, fmt.Sprintf("doc-%d", i), map[string]interface{}{"status": "active"})
+		require.NoError(t, err)
+	}
+
+	query := []byte(`{"term": {"status": "active"}}`)
+
+	all, err := shard.Search(ctx, query)
+	require.NoError(t, err)
+	require.Equal(t, int64(12), all.TotalHits)
+	require.NotEmpty(t, all.Hits)
+	lowest := all.Hits[len(all.Hits)-1].Score
+
+	// A threshold at or below every score keeps all matches
+	result, err := shard.SearchWithOptions(ctx, query, diagon.SearchOptions{MinScore: lowest})
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), result.TotalHits)
+
+	// Raising the threshold above the best score drops every hit and the total
+	result, err = shard.SearchWithOptions(ctx, query, diagon.SearchOptions{MinScore: all.MaxScore + 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), result.TotalHits)
+	assert.Empty(t, result.Hits)
+}
+
+func TestShard_SearchNestedField(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   "/tmp/test-data",
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	sm.CreateShard(ctx, "nested-index", 0, true)
+	shard, err := sm.GetShard("nested-index", 0)
+	require.NoError(t, err)
+
+	err = shard.IndexDocument(ctx, "doc-1", map[string]interface{}{
+		"title":  "Go in Action",
+		"author": map[string]interface{}{"name": "alice", "age": float64(42)},
+	})
+	require.NoError(t, err)
+	err = shard.IndexDocument(ctx, "doc-2", map[string]interface{}{
+		"title":  "Learning Go",
+		"author": map[string]interface{}{"name": "bob", "age": float64(25)},
+	})
+	require.NoError(t, err)
+
+	// Nested numeric fields are queryable by their dot path
+	result, err := shard.Search(ctx, []byte(`{"range": {"author.age": {"gte": 30}}}`))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.TotalHits)
+	assert.Equal(t, "doc-1", result.Hits[0].ID)
+
+	// Nested strings are indexed as text under their dot path too
+	result, err = shard.Search(ctx, []byte(`{"term": {"author.name": "bob"}}`))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.TotalHits)
+	assert.Equal(t, "doc-2", result.Hits[0].ID)
+}
+
+func TestShard_SearchArrayField(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   "/tmp/test-data",
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	sm.CreateShard(ctx, "array-index", 0, true)
+	shard, err := sm.GetShard("array-index", 0)
+	require.NoError(t, err)
+
+	err = shard.IndexDocument(ctx, "doc-1", map[string]interface{}{
+		"tags":   []interface{}{"go", "rust"},
+		"scores": []interface{}{float64(10), float64(75)},
+	})
+	require.NoError(t, err)
+	err = shard.IndexDocument(ctx, "doc-2", map[string]interface{}{
+		"tags":   []interface{}{"python"},
+		"scores": []interface{}{float64(20), float64(30)},
+	})
+	require.NoError(t, err)
+
+	t.Run("string array", func(t *testing.T) {
+		for _, tag := range []string{"go", "rust"} {
+			result, err := shard.Search(ctx, []byte(fmt.Sprintf(`{"term": {"tags": %q}}`, tag)))
+			require.NoError(t, err)
+			require.Equal(t, int64(1), result.TotalHits, tag)
+			assert.Equal(t, "doc-1", result.Hits[0].ID)
+		}
+	})
+
+	t.Run("numeric array", func(t *testing.T) {
+		// Matches when any element falls in the range
+		result, err := shard.Search(ctx, []byte(`{"range": {"scores": {"gte": 50}}}`))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.TotalHits)
+		assert.Equal(t, "doc-1", result.Hits[0].ID)
+
+		result, err = shard.Search(ctx, []byte(`{"range": {"scores": {"gte": 15, "lte": 25}}}`))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.TotalHits)
+		assert.Equal(t, "doc-2", result.Hits[0].ID)
+	})
+}
+
+func TestShard_SearchBooleanField(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   "/tmp/test-data",
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	sm.CreateShard(ctx, "bool-index", 0, true)
+	shard, err := sm.GetShard("bool-index", 0)
+	require.NoError(t, err)
+
+	docs := map[string]bool{"doc-1": true, "doc-2": false, "doc-3": true}
+	for id, active := range docs {
+		err = shard.IndexDocument(ctx, id, map[string]interface{}{
+			"category": "books",
+			"active":   active,
+		})
+		require.NoError(t, err)
+	}
+
+	result, err := shard.Search(ctx, []byte(`{"term": {"active": false}}`))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.TotalHits)
+	assert.Equal(t, "doc-2", result.Hits[0].ID)
+
+	result, err = shard.Search(ctx, []byte(`{"bool": {
+		"must": [{"term": {"category": "books"}}],
+		"filter": [{"term": {"active": true}}]
+	}}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.TotalHits)
+	for _, hit := range result.Hits {
+		assert.True(t, docs[hit.ID], hit.ID)
+	}
+}
+
+func TestShard_SearchAnalyzedField(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   t.TempDir(),
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	// title uses a custom analyzer, sku the keyword analyzer
+	settings := map[string]string{settingAnalysis: `{
+		"field_analyzers": {"title": "folding", "sku": "keyword"},
+		"custom_analyzers": {"folding": {"tokenizer": "standard", "filters": ["lowercase", "asciifolding"]}}
+	}`}
+	require.NoError(t, sm.CreateShardWithSettings(ctx, "analyzed-index", 0, true, settings))
+	shard, err := sm.GetShard("analyzed-index", 0)
+	require.NoError(t, err)
+
+	require.NoError(t, shard.IndexDocument(ctx, "doc-1", map[string]interface{}{"title": "Crème Brûlée", "sku": "AB-12 x"}))
+	require.NoError(t, shard.IndexDocument(ctx, "doc-2", map[string]interface{}{"title": "Apple Pie", "sku": "ab-12"}))
+
+	// Match queries are analyzed as the values were indexed
+	result, err := shard.Search(ctx, []byte(`{"match": {"title": "CREME pudding"}}`))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.TotalHits)
+	assert.Equal(t, "doc-1", result.Hits[0].ID)
+
+	result, err = shard.Search(ctx, []byte(`{"match": {"title": {"query": "creme pie", "operator": "and"}}}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), result.TotalHits)
+
+	result, err = shard.Search(ctx, []byte(`{"match": {"sku": "ab-12"}}`))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.TotalHits)
+	assert.Equal(t, "doc-2", result.Hits[0].ID)
+
+	// The original value is what is stored
+	doc, err := shard.GetDocument(ctx, "doc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Crème Brûlée", doc["title"])
+
+	tokens, err := shard.AnalyzeText("title", "Crème Brûlée")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"creme", "brulee"}, tokens)
+
+	// Unknown analyzers are rejected when the shard is created
+	err = sm.CreateShardWithSettings(ctx, "analyzed-index", 1, true, map[string]string{
+		settingAnalysis: `{"field_analyzers": {"title": "missing"}}`,
+	})
+	assert.Error(t, err)
+}
+
+func TestShard_SearchSynonyms(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   t.TempDir(),
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	// title expands synonyms; name holds the same text without them
+	settings := map[string]string{settingAnalysis: `{
+		"field_analyzers": {"title": "products", "name": "standard"},
+		"custom_analyzers": {"products": {"tokenizer": "standard", "filters": ["lowercase", "product_synonyms"]}},
+		"custom_filters": {"product_synonyms": {"type": "synonym", "synonyms": ["laptop, notebook", "tv => television"]}}
+	}`}
+	require.NoError(t, sm.CreateShardWithSettings(ctx, "synonym-index", 0, true, settings))
+	shard, err := sm.GetShard("synonym-index", 0)
+	require.NoError(t, err)
+
+	for id, text := range map[string]string{
+		"doc-1": "Notebook sleeve",
+		"doc-2": "Laptop stand",
+		"doc-3": "Television remote",
+		"doc-4": "Paper notebook",
+	} {
+		require.NoError(t, shard.IndexDocument(ctx, id, map[string]interface{}{"title": text, "name": text}))
+	}
+
+	search := func(query string) []string {
+		t.Helper()
+		result, err := shard.Search(ctx, []byte(query))
+		require.NoError(t, err)
+		var ids []string
+		for _, hit := range result.Hits {
+			ids = append(ids, hit.ID)
+		}
+		return ids
+	}
+
+	// Without synonyms only the literal term matches
+	assert.ElementsMatch(t, []string{"doc-2"}, search(`{"match": {"name": "LAPTOP"}}`))
+	assert.Empty(t, search(`{"match": {"name": "tv"}}`))
+
+	// With them the equivalent terms match too
+	assert.ElementsMatch(t, []string{"doc-1", "doc-2", "doc-4"}, search(`{"match": {"title": "LAPTOP"}}`))
+	assert.ElementsMatch(t, []string{"doc-3"}, search(`{"match": {"title": "tv"}}`))
+
+	// A synonym satisfies its position when all terms are required
+	assert.ElementsMatch(t, []string{"doc-1"}, search(`{"match": {"title": {"query": "laptop sleeve", "operator": "and"}}}`))
+
+	tokens, err := shard.AnalyzeText("title", "laptop")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"laptop", "notebook"}, tokens)
+
+	// Invalid rules are rejected when the shard is created
+	err = sm.CreateShardWithSettings(ctx, "synonym-index", 1, true, map[string]string{settingAnalysis: `{
+		"custom_analyzers": {"products": {"tokenizer": "standard", "filters": ["product_synonyms"]}},
+		"custom_filters": {"product_synonyms": {"type": "synonym", "synonyms": ["tv =>"]}}
+	}`})
+	assert.ErrorContains(t, err, "both sides of => need a synonym")
+}
+
+func TestShard_SearchStemmedField(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   t.TempDir(),
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	// body is stemmed, title holds the same text unstemmed
+	settings := map[string]string{settingAnalysis: `{
+		"field_analyzers": {"body": "stemmed", "title": "standard"},
+		"custom_analyzers": {"stemmed": {"tokenizer": "standard", "filters": ["lowercase", "porter_stem"]}}
+	}`}
+	require.NoError(t, sm.CreateShardWithSettings(ctx, "stemmed-index", 0, true, settings))
+	shard, err := sm.GetShard("stemmed-index", 0)
+	require.NoError(t, err)
+
+	require.NoError(t, shard.IndexDocument(ctx, "doc-1", map[string]interface{}{"body": "Running shoes", "title": "Running shoes"}))
+	require.NoError(t, shard.IndexDocument(ctx, "doc-2", map[string]interface{}{"body": "Trail runs", "title": "Trail runs"}))
+
+	count := func(query string) int64 {
+		t.Helper()
+		result, err := shard.Search(ctx, []byte(query))
+		require.NoError(t, err)
+		return result.TotalHits
+	}
+
+	// Stemmed and unstemmed forms match when the field is stemmed, since
+	// queries are stemmed like the indexed values
+	assert.Equal(t, int64(2), count(`{"match": {"body": "run"}}`))
+	assert.Equal(t, int64(1), count(`{"match": {"body": {"query": "runs shoe", "operator": "and"}}}`))
+
+	// and only literally when it isn't
+	assert.Equal(t, int64(0), count(`{"match": {"title": "run"}}`))
+	assert.Equal(t, int64(1), count(`{"match": {"title": "running"}}`))
+}
+
+func TestShard_TermVectors(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   t.TempDir(),
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	settings := map[string]string{settingAnalysis: `{"field_analyzers": {"sku": "keyword", "title": "standard", "tags": "standard"}}`}
+	require.NoError(t, sm.CreateShardWithSettings(ctx, "vectors-index", 0, true, settings))
+	shard, err := sm.GetShard("vectors-index", 0)
+	require.NoError(t, err)
+
+	require.NoError(t, shard.IndexDocument(ctx, "doc-1", map[string]interface{}{
+		"title":  "To be, or not to be",
+		"sku":    "AB-12",
+		"tags":   []interface{}{"Drama", "drama"},
+		"author": map[string]interface{}{"name": "William Shakespeare"},
+		"year":   float64(1603),
+	}))
+
+	doc, err := shard.GetDocument(ctx, "doc-1")
+	require.NoError(t, err)
+
+	// Fields Diagon analyzed itself can't be rebuilt
+	_, err = shard.TermVectors(doc, nil)
+	var unavailableErr *TermVectorsUnavailableError
+	require.ErrorAs(t, err, &unavailableErr)
+	assert.Equal(t, "author.name", unavailableErr.Field)
+	_, err = shard.TermVectors(doc, []string{"sku", "author.name"})
+	require.ErrorAs(t, err, &unavailableErr)
+	assert.Equal(t, "author.name", unavailableErr.Field)
+
+	// Mapped fields are rebuilt with their analyzer; numbers have no terms
+	vectors, err := shard.TermVectors(doc, []string{"title", "sku", "tags", "year"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sku", "tags", "title"}, slices.Sorted(maps.Keys(vectors)))
+
+	title := vectors["title"]
+	assert.Len(t, title["to"], 2)
+	assert.Len(t, title["be"], 2)
+	assert.Len(t, title["or"], 1)
+	assert.Equal(t, []analysis.TermOccurrence{{Position: 1, StartOffset: 3, EndOffset: 5}, {Position: 5, StartOffset: 17, EndOffset: 19}}, title["be"])
+
+	// Keyword fields are a single term, and array values add up
+	assert.Equal(t, []analysis.TermOccurrence{{Position: 0, StartOffset: 0, EndOffset: 5}}, vectors["sku"]["AB-12"])
+	assert.Len(t, vectors["tags"]["drama"], 2)
+
+	// Fields can be limited; missing ones have no vectors
+	vectors, err = shard.TermVectors(doc, []string{"sku", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sku"}, slices.Sorted(maps.Keys(vectors)))
+}
+
+func TestShard_Suggest(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   t.TempDir(),
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	require.NoError(t, sm.CreateShard(ctx, "suggest-index", 0, true))
+	shard, err := sm.GetShard("suggest-index", 0)
+	require.NoError(t, err)
+
+	docs := map[string]map[string]interface{}{
+		"doc-1": {"title": "Quick brown fox", "suggest": map[string]interface{}{"input": []interface{}{"Nirvana"}, "weight": float64(20)}},
+		"doc-2": {"title": "Quick thinking", "suggest": "Nine Inch Nails"},
+	}
+	for id, doc := range docs {
+		require.NoError(t, shard.IndexDocument(ctx, id, doc))
+	}
+	require.NoError(t, shard.Refresh())
+
+	results, err := shard.Suggest(ctx, []suggest.Suggester{
+		{Name: "spelling", Type: suggest.TypeTerm, Field: "title", Text: "quikc", Size: 5,
+			SuggestMode: suggest.ModeMissing, MaxEdits: 2, PrefixLength: 1, MinWordLength: 4},
+		{Name: "autocomplete", Type: suggest.TypeCompletion, Field: "suggest", Text: "NI", Size: 5},
+	})
+	require.NoError(t, err)
+
+	// The misspelled term is corrected from the analyzed title terms
+	require.Len(t, results["spelling"], 1)
+	spelling := results["spelling"][0]
+	require.NotEmpty(t, spelling.Options)
+	assert.Equal(t, "quick", spelling.Options[0].Text)
+	assert.Equal(t, int64(2), spelling.Options[0].Freq)
+
+	// Completions match the prefix case-insensitively, heaviest first
+	require.Len(t, results["autocomplete"], 1)
+	completions := results["autocomplete"][0].Options
+	require.Len(t, completions, 2)
+	assert.Equal(t, "Nirvana", completions[0].Text)
+	assert.Equal(t, "doc-1", completions[0].ID)
+	assert.Equal(t, "Nine Inch Nails", completions[1].Text)
+	assert.NotNil(t, completions[0].Source)
+
+	// Documents indexed afterwards update the suggested fields
+	require.NoError(t, shard.IndexDocument(ctx, "doc-3", map[string]interface{}{"title": "Quick quirk", "suggest": "Nickelback"}))
+	results, err = shard.Suggest(ctx, []suggest.Suggester{
+		{Name: "spelling", Type: suggest.TypeTerm, Field: "title", Text: "quikc", Size: 5,
+			SuggestMode: suggest.ModeMissing, Sort: "score", MaxEdits: 2, PrefixLength: 1, MinWordLength: 4},
+		{Name: "autocomplete", Type: suggest.TypeCompletion, Field: "suggest", Text: "NI", Size: 5},
+	})
+	require.NoError(t, err)
+	spelling = results["spelling"][0]
+	require.Len(t, spelling.Options, 2)
+	assert.Equal(t, int64(3), spelling.Options[0].Freq)
+	assert.Equal(t, "quirk", spelling.Options[1].Text)
+	assert.Len(t, results["autocomplete"][0].Options, 3)
+}
+
+func TestShard_IndexDocumentLimits(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   t.TempDir(),
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	require.NoError(t, sm.CreateShardWithSettings(ctx, "limits-index", 0, true, map[string]string{
+		settingMaxFieldValueLength: "16",
+		settingMaxDocumentSize:     "64",
+	}))
+	shard, err := sm.GetShard("limits-index", 0)
+	require.NoError(t, err)
+	assert.Equal(t, DocumentLimits{MaxFieldValueLength: 16, MaxDocumentSize: 64}, shard.Limits)
+
+	require.NoError(t, shard.IndexDocument(ctx, "small", map[string]interface{}{"title": "within limits"}))
+
+	t.Run("field value too long", func(t *testing.T) {
+		err := shard.IndexDocument(ctx, "long-value", map[string]interface{}{
+			"user": map[string]interface{}{"tags": []interface{}{"short", strings.Repeat("x", 17)}},
+		})
+		var fieldErr *FieldValueTooLongError
+		require.ErrorAs(t, err, &fieldErr)
+		assert.Equal(t, "user.tags", fieldErr.Field)
+		assert.Equal(t, int64(17), fieldErr.Length)
+		assert.Contains(t, err.Error(), settingMaxFieldValueLength)
+	})
+
+	t.Run("document too large", func(t *testing.T) {
+		doc := make(map[string]interface{})
+		for i := 0; i < 8; i++ {
+			doc[fmt.Sprintf("field_%d", i)] = "0123456789"
+		}
+		err := shard.IndexDocument(ctx, "large", doc)
+		var sizeErr *DocumentTooLargeError
+		require.ErrorAs(t, err, &sizeErr)
+		assert.Equal(t, int64(64), sizeErr.Limit)
+	})
+
+	// Rejected documents never reach the index
+	assert.Equal(t, int64(1), shard.DocsCount)
+
+	// Invalid limits are rejected when the shard is created
+	err = sm.CreateShardWithSettings(ctx, "limits-index", 1, true, map[string]string{settingMaxDocumentSize: "-1"})
+	assert.ErrorContains(t, err, settingMaxDocumentSize)
+
+	// An index that doesn't set the limits accepts documents of any size
+	require.NoError(t, sm.CreateShard(ctx, "unlimited-index", 0, true))
+	unlimited, err := sm.GetShard("unlimited-index", 0)
+	require.NoError(t, err)
+	assert.Equal(t, DocumentLimits{}, unlimited.Limits)
+	require.NoError(t, unlimited.IndexDocument(ctx, "large", map[string]interface{}{"body": strings.Repeat("x", 2<<20)}))
+}
+
+func TestShard_IndexDocumentNumericFields(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   t.TempDir(),
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	require.NoError(t, sm.CreateShardWithSettings(ctx, "numeric-index", 0, true, map[string]string{
+		settingNumericFields: `{"stock": {"type": "integer"}, "price": {"type": "double", "ignore_malformed": true}}`,
+	}))
+	shard, err := sm.GetShard("numeric-index", 0)
+	require.NoError(t, err)
+
+	t.Run("numeric strings are coerced", func(t *testing.T) {
+		require.NoError(t, shard.IndexDocument(ctx, "coerced", map[string]interface{}{
+			"title": "coerced", "stock": "12", "price": "9.5",
+		}))
+		require.NoError(t, shard.Refresh())
+
+		result, err := shard.Search(ctx, []byte(`{"range": {"stock": {"gte": 10}}}`))
+		require.NoError(t, err)
+		require.Len(t, result.Hits, 1)
+		assert.Equal(t, "coerced", result.Hits[0].ID)
+	})
+
+	t.Run("malformed values reject the document", func(t *testing.T) {
+		err := shard.IndexDocument(ctx, "rejected", map[string]interface{}{"title": "rejected", "stock": "plenty"})
+		var malformedErr *MalformedFieldError
+		require.ErrorAs(t, err, &malformedErr)
+		assert.Equal(t, "stock", malformedErr.Field)
+		assert.Equal(t, "integer", malformedErr.Type)
+
+		_, err = shard.GetDocument(ctx, "rejected")
+		assert.Error(t, err)
+	})
+
+	t.Run("ignore_malformed skips the field", func(t *testing.T) {
+		require.NoError(t, shard.IndexDocument(ctx, "ignored", map[string]interface{}{
+			"title": "ignored", "stock": float64(3), "price": "unknown",
+		}))
+
+		doc, err := shard.GetDocument(ctx, "ignored")
+		require.NoError(t, err)
+		assert.Equal(t, "ignored", doc["title"])
+		assert.NotContains(t, doc, "price")
+	})
+}
+
+func TestCoerceNumericFields(t *testing.T) {
+	fields := map[string]NumericField{
+		"count":        {Type: "byte"},
+		"stats.views":  {Type: "long"},
+		"scores":       {Type: "float", IgnoreMalformed: true},
+		"nested.ratio": {Type: "half_float"},
+	}
+	doc := map[string]interface{}{
+		"count":  float64(7.9),
+		"stats":  map[string]interface{}{"views": " 42 "},
+		"scores": []interface{}{float64(1.5), "n/a", "2"},
+		"nested": []interface{}{map[string]interface{}{"ratio": "0.25"}},
+		"title":  "untouched",
+	}
+	coerced, ignored, err := coerceNumericFields("1", doc, fields)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), coerced["count"])
+	assert.Equal(t, map[string]interface{}{"views": int64(42)}, coerced["stats"])
+	assert.Equal(t, []interface{}{1.5, 2.0}, coerced["scores"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"ratio": 0.25}}, coerced["nested"])
+	assert.Equal(t, "untouched", coerced["title"])
+	assert.Equal(t, []string{"scores"}, ignored)
+
+	// The original document is left as it was
+	assert.Equal(t, " 42 ", doc["stats"].(map[string]interface{})["views"])
+
+	// Values out of the type's range are malformed
+	_, _, err = coerceNumericFields("2", map[string]interface{}{"count": float64(300)}, fields)
+	assert.ErrorContains(t, err, "failed to parse field [count] of type [byte]")
+	_, _, err = coerceNumericFields("3", map[string]interface{}{"count": true}, fields)
+	assert.ErrorContains(t, err, "failed to parse field [count]")
+}
+
+func TestShard_SearchDateField(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   "/tmp/test-data",
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	sm.CreateShard(ctx, "date-index", 0, true)
+	shard, err := sm.GetShard("date-index", 0)
+	require.NoError(t, err)
+
+	// "created" is detected as a date from its ISO-8601 values
+	err = shard.IndexDocument(ctx, "doc-1", map[string]interface{}{"created": "2025-12-31"})
+	require.NoError(t, err)
+	err = shard.IndexDocument(ctx, "doc-2", map[string]interface{}{"created": "2026-01-15T10:30:00Z"})
+	require.NoError(t, err)
+
+	// "updated" is mapped explicitly, so epoch millis numbers are dates too
+	shard.SetDateField("updated")
+	err = shard.IndexDocument(ctx, "doc-3", map[string]interface{}{"updated": float64(1767225600000)}) // 2026-01-01
+	require.NoError(t, err)
+	err = shard.IndexDocument(ctx, "doc-4", map[string]interface{}{"updated": float64(1735689600000)}) // 2025-01-01
+	require.NoError(t, err)
+
+	t.Run("iso input", func(t *testing.T) {
+		result, err := shard.Search(ctx, []byte(`{"range": {"created": {"gte": "2026-01-01"}}}`))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.TotalHits)
+		assert.Equal(t, "doc-2", result.Hits[0].ID)
+	})
+
+	t.Run("epoch input", func(t *testing.T) {
+		result, err := shard.Search(ctx, []byte(`{"range": {"updated": {"gte": "2026-01-01"}}}`))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.TotalHits)
+		assert.Equal(t, "doc-3", result.Hits[0].ID)
+
+		result, err = shard.Search(ctx, []byte(`{"range": {"updated": {"lt": 1767225600000}}}`))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.TotalHits)
+		assert.Equal(t, "doc-4", result.Hits[0].ID)
+	})
+
+	t.Run("custom format", func(t *testing.T) {
+		result, err := shard.Search(ctx, []byte(`{"range": {"created": {"lt": "01/01/2026", "format": "MM/dd/yyyy"}}}`))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.TotalHits)
+		assert.Equal(t, "doc-1", result.Hits[0].ID)
+	})
+
+	t.Run("invalid bound", func(t *testing.T) {
+		_, err := shard.Search(ctx, []byte(`{"range": {"created": {"gte": "not a date"}}}`))
+		assert.Error(t, err)
+	})
+}
+
+func TestShard_DateFieldsFromSettings(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   t.TempDir(),
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	// The mapping's formats are applied when the shard is created
+	settings := map[string]string{settingDateFields: `{"published": {"formats": ["yyyy/MM/dd"]}}`}
+	require.NoError(t, sm.CreateShardWithSettings(ctx, "dated-index", 0, true, settings))
+	shard, err := sm.GetShard("dated-index", 0)
+	require.NoError(t, err)
+	require.NoError(t, shard.IndexDocument(ctx, "doc-1", map[string]interface{}{"published": "2026/01/15"}))
+	require.NoError(t, shard.IndexDocument(ctx, "doc-2", map[string]interface{}{"published": "2025/06/01"}))
+
+	result, err := shard.Search(ctx, []byte(`{"range": {"published": {"gte": "2026-01-01"}}}`))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.TotalHits)
+	assert.Equal(t, "doc-1", result.Hits[0].ID)
+
+	// A date field added to the mapping reaches the open shard
+	settings[settingDateFields] = `{"published": {"formats": ["yyyy/MM/dd"]}, "updated": {"formats": ["epoch_second"]}}`
+	updated, err := sm.UpdateIndexSettings(ctx, "dated-index", settings)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+	require.NoError(t, shard.IndexDocument(ctx, "doc-3", map[string]interface{}{"updated": float64(1767225600)})) // 2026-01-01
+
+	result, err = shard.Search(ctx, []byte(`{"range": {"updated": {"gte": "2025-12-31"}}}`))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.TotalHits)
+	assert.Equal(t, "doc-3", result.Hits[0].ID)
+
+	_, err = shardDateFields(map[string]string{settingDateFields: `{"published": {"formats": [""]}}`})
+	assert.ErrorContains(t, err, "field [published] has an empty date format")
 }
 
-func TestShard_GetDocument(t *testing.T) {
+func TestShard_SearchGeoDistance(t *testing.T) {
 	cfg := &config.DataNodeConfig{
 		NodeID:    "node-1",
 		DataDir:   "/tmp/test-data",
@@ -266,31 +1283,95 @@ func TestShard_GetDocument(t *testing.T) {
 	sm.Start(ctx)
 	defer sm.Stop(ctx)
 
-	// Create a shard
-	sm.CreateShard(ctx, "test-index", 0, true)
-	shard, err := sm.GetShard("test-index", 0)
+	// "location" is mapped so "lat,lon" strings are points too
+	settings := map[string]string{settingGeoPointFields: `["location"]`}
+	require.NoError(t, sm.CreateShardWithSettings(ctx, "geo-index", 0, true, settings))
+	shard, err := sm.GetShard("geo-index", 0)
 	require.NoError(t, err)
 
-	// Index a document
-	doc := map[string]interface{}{
-		"title": "Test Document",
-		"body":  "This is a test",
+	places := map[string]interface{}{
+		"louvre":     map[string]interface{}{"lat": 48.8606, "lon": 2.3376}, // ~1.3km from origin
+		"eiffel":     "48.8584,2.2945",                                      // ~4.2km
+		"versailles": map[string]interface{}{"lat": 48.8049, "lon": 2.1204}, // ~17km
+		"london":     "51.5074,-0.1278",                                     // ~340km
+		"notre-dame": map[string]interface{}{"lat": 48.8530, "lon": 2.3499}, // ~0.4km
+		"corner":     "48.8866,2.3972",                                      // ~4.7km, in the corner of a 4km box
 	}
-	err = shard.IndexDocument(ctx, "doc-1", doc)
+	for id, location := range places {
+		err = shard.IndexDocument(ctx, id, map[string]interface{}{"location": location})
+		require.NoError(t, err)
+	}
+
+	hitIDs := func(result *diagon.SearchResult) []string {
+		ids := make([]string, 0, len(result.Hits))
+		for _, hit := range result.Hits {
+			ids = append(ids, hit.ID)
+		}
+		return ids
+	}
+
+	origin := `{"lat": 48.8566, "lon": 2.3522}`
+
+	result, err := shard.Search(ctx, []byte(`{"geo_distance": {"distance": "5km", "location": `+origin+`}}`))
 	require.NoError(t, err)
+	assert.Equal(t, int64(4), result.TotalHits)
+	assert.ElementsMatch(t, []string{"louvre", "eiffel", "notre-dame", "corner"}, hitIDs(result))
 
-	// Get the document
-	retrievedDoc, err := shard.GetDocument(ctx, "doc-1")
-	assert.NoError(t, err)
-	assert.NotNil(t, retrievedDoc)
-	assert.Equal(t, "Test Document", retrievedDoc["title"])
+	result, err = shard.Search(ctx, []byte(`{"geo_distance": {"distance": "2km", "location": `+origin+`}}`))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"louvre", "notre-dame"}, hitIDs(result))
 
-	// Try to get non-existent document
-	_, err = shard.GetDocument(ctx, "non-existent")
-	assert.Error(t, err)
+	result, err = shard.Search(ctx, []byte(`{"geo_distance": {"distance": "1000km", "location": `+origin+`}}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), result.TotalHits)
+
+	t.Run("bool filter", func(t *testing.T) {
+		result, err := shard.Search(ctx, []byte(`{"bool": {
+			"must": [{"match_all": {}}],
+			"filter": [{"geo_distance": {"distance": "20km", "location": `+origin+`}}]
+		}}`))
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), result.TotalHits)
+		assert.NotContains(t, hitIDs(result), "london")
+	})
+
+	t.Run("bool must_not", func(t *testing.T) {
+		result, err := shard.Search(ctx, []byte(`{"bool": {
+			"must": [{"match_all": {}}],
+			"must_not": [{"geo_distance": {"distance": "4km", "location": `+origin+`}}]
+		}}`))
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"eiffel", "corner", "versailles", "london"}, hitIDs(result))
+	})
+
+	t.Run("bool should", func(t *testing.T) {
+		result, err := shard.Search(ctx, []byte(`{"bool": {
+			"should": [
+				{"geo_distance": {"distance": "4km", "location": `+origin+`}},
+				{"term": {"_id": "london"}}
+			]
+		}}`))
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"louvre", "notre-dame", "london"}, hitIDs(result))
+	})
+
+	t.Run("render distance", func(t *testing.T) {
+		result, err := shard.Search(ctx, []byte(`{"geo_distance": {"distance": "1km", "location": `+origin+`, "render_distance": true}}`))
+		require.NoError(t, err)
+		require.Len(t, result.Hits, 1)
+		assert.Equal(t, "notre-dame", result.Hits[0].ID)
+		distance, ok := result.Hits[0].Source["_geo_distance"].(float64)
+		require.True(t, ok)
+		assert.InDelta(t, 400, distance, 100)
+	})
+
+	t.Run("invalid origin", func(t *testing.T) {
+		_, err := shard.Search(ctx, []byte(`{"geo_distance": {"distance": "1km", "location": "nowhere"}}`))
+		assert.Error(t, err)
+	})
 }
 
-func TestShard_DeleteDocument(t *testing.T) {
+func TestShard_SearchIDs(t *testing.T) {
 	cfg := &config.DataNodeConfig{
 		NodeID:    "node-1",
 		DataDir:   "/tmp/test-data",
@@ -309,26 +1390,26 @@ func TestShard_DeleteDocument(t *testing.T) {
 	sm.Start(ctx)
 	defer sm.Stop(ctx)
 
-	// Create a shard
-	sm.CreateShard(ctx, "test-index", 0, true)
-	shard, err := sm.GetShard("test-index", 0)
+	sm.CreateShard(ctx, "ids-index", 0, true)
+	shard, err := sm.GetShard("ids-index", 0)
 	require.NoError(t, err)
 
-	// Index a document
-	doc := map[string]interface{}{
-		"title": "Test Document",
+	for i := 1; i <= 5; i++ {
+		err = shard.IndexDocument(ctx, fmt.Sprintf("doc-%d", i), map[string]interface{}{"title": "book"})
+		require.NoError(t, err)
 	}
-	err = shard.IndexDocument(ctx, "doc-1", doc)
-	require.NoError(t, err)
-	assert.Equal(t, int64(1), shard.DocsCount)
 
-	// Delete the document
-	err = shard.DeleteDocument(ctx, "doc-1")
-	assert.NoError(t, err)
-	assert.Equal(t, int64(0), shard.DocsCount)
+	result, err := shard.Search(ctx, []byte(`{"ids": {"values": ["doc-2", "doc-4", "missing"]}}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.TotalHits)
+	ids := []string{}
+	for _, hit := range result.Hits {
+		ids = append(ids, hit.ID)
+	}
+	assert.ElementsMatch(t, []string{"doc-2", "doc-4"}, ids)
 }
 
-func TestShard_Search(t *testing.T) {
+func TestShard_SearchRegexp(t *testing.T) {
 	cfg := &config.DataNodeConfig{
 		NodeID:    "node-1",
 		DataDir:   "/tmp/test-data",
@@ -347,26 +1428,28 @@ func TestShard_Search(t *testing.T) {
 	sm.Start(ctx)
 	defer sm.Stop(ctx)
 
-	// Create a shard
-	sm.CreateShard(ctx, "test-index", 0, true)
-	shard, err := sm.GetShard("test-index", 0)
+	sm.CreateShard(ctx, "regexp-index", 0, true)
+	shard, err := sm.GetShard("regexp-index", 0)
 	require.NoError(t, err)
 
-	// Index some documents
-	docs := []map[string]interface{}{
-		{"title": "First Document", "body": "Test content"},
-		{"title": "Second Document", "body": "More test content"},
-	}
-	for i, doc := range docs {
-		err = shard.IndexDocument(ctx, fmt.Sprintf("doc-%d", i), doc)
+	for id, status := range map[string]string{"doc-1": "shipped", "doc-2": "shipping", "doc-3": "pending"} {
+		err = shard.IndexDocument(ctx, id, map[string]interface{}{"status": status})
 		require.NoError(t, err)
 	}
 
-	// Execute search (empty query for now)
-	query := []byte("{}")
-	result, err := shard.Search(ctx, query)
-	assert.NoError(t, err)
-	assert.NotNil(t, result)
+	result, err := shard.Search(ctx, []byte(`{"regexp": {"status": "ship.*"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.TotalHits)
+
+	result, err = shard.Search(ctx, []byte(`{"regexp": {"status": {"value": "pend[a-z]ng"}}}`))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.TotalHits)
+	assert.Equal(t, "doc-3", result.Hits[0].ID)
+
+	// Patterns must match the whole term
+	result, err = shard.Search(ctx, []byte(`{"regexp": {"status": "ship"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), result.TotalHits)
 }
 
 func TestShard_RefreshAndFlush(t *testing.T) {
@@ -436,6 +1519,150 @@ func TestShard_Stats(t *testing.T) {
 	assert.Equal(t, int64(0), stats.DocsCount)
 }
 
+func TestShard_StatsStoreSize(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   t.TempDir(),
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	require.NoError(t, sm.CreateShard(ctx, "size-index", 0, true))
+	shard, err := sm.GetShard("size-index", 0)
+	require.NoError(t, err)
+	empty := shard.Stats().SizeBytes
+
+	// Each refresh commits a segment, growing the shard's files
+	for commit := 0; commit < 3; commit++ {
+		for i := 0; i < 20; i++ {
+			docID := fmt.Sprintf("doc-%d-%d", commit, i)
+			require.NoError(t, shard.IndexDocument(ctx, docID, map[string]interface{}{
+				"title": strings.Repeat("store size "+docID+" ", 10),
+			}))
+		}
+		require.NoError(t, shard.Refresh())
+	}
+	indexed := shard.Stats().SizeBytes
+	assert.Greater(t, indexed, empty)
+
+	// Diagon can't delete documents yet, so the shard only shrinks by
+	// merging its segments into one
+	result, err := shard.ForceMerge(ctx, 1)
+	require.NoError(t, err)
+	merged := shard.Stats().SizeBytes
+	assert.Less(t, merged, indexed)
+	assert.Equal(t, result.SizeBytesAfter, merged)
+}
+
+func TestShard_StatsOperationCounters(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   t.TempDir(),
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	require.NoError(t, sm.CreateShard(ctx, "counters-index", 0, true))
+	shard, err := sm.GetShard("counters-index", 0)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, shard.IndexDocument(ctx, fmt.Sprintf("doc-%d", i), map[string]interface{}{"title": "counter"}))
+	}
+	require.NoError(t, shard.Refresh())
+	_, err = shard.Search(ctx, []byte(`{"match_all":{}}`))
+	require.NoError(t, err)
+	_, err = shard.ForceMerge(ctx, 1)
+	require.NoError(t, err)
+
+	stats := shard.Stats()
+	assert.Equal(t, int64(5), stats.Indexing.Total)
+	assert.Equal(t, int64(1), stats.Refresh.Total)
+	assert.Equal(t, int64(1), stats.Search.Total)
+	assert.Equal(t, int64(1), stats.Merge.Total)
+	assert.Equal(t, int64(0), stats.DocsDeleted)
+}
+
+func TestShard_ForceMerge(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   t.TempDir(),
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	require.NoError(t, sm.CreateShard(ctx, "merge-index", 0, true))
+	shard, err := sm.GetShard("merge-index", 0)
+	require.NoError(t, err)
+
+	// Each refresh commits a segment
+	for commit := 0; commit < 3; commit++ {
+		for i := 0; i < 10; i++ {
+			require.NoError(t, shard.IndexDocument(ctx, fmt.Sprintf("doc-%d-%d", commit, i), map[string]interface{}{
+				"title": fmt.Sprintf("merge %d", commit),
+			}))
+		}
+		require.NoError(t, shard.Refresh())
+	}
+	segments, err := shard.Segments()
+	require.NoError(t, err)
+	assert.Greater(t, len(segments), 1)
+
+	result, err := shard.ForceMerge(ctx, 1)
+	require.NoError(t, err)
+	segments, err = shard.Segments()
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(segments), 1)
+
+	// Diagon can't delete documents, so there are none to reclaim, and
+	// every document survives the merge
+	assert.Equal(t, int64(0), result.DocsDeletedBefore)
+	assert.Equal(t, int64(0), result.DocsDeletedAfter)
+	assert.Equal(t, result.SizeBytesAfter, shard.Stats().SizeBytes)
+	search, err := shard.Search(ctx, []byte(`{"match_all":{}}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(30), search.TotalHits)
+
+	// A closed shard can't be merged
+	require.NoError(t, shard.Close())
+	_, err = shard.ForceMerge(ctx, 1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not ready")
+}
+
 func TestShard_Close(t *testing.T) {
 	cfg := &config.DataNodeConfig{
 		NodeID:    "node-1",
@@ -522,3 +1749,155 @@ func TestShard_OperationsOnClosedShard(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not ready")
 }
+
+func TestShard_SearchCollapse(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   "/tmp/test-data",
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	sm.CreateShard(ctx, "collapse-index", 0, true)
+	shard, err := sm.GetShard("collapse-index", 0)
+	require.NoError(t, err)
+
+	users := []string{"alice", "bob", "alice", "carol", "bob", "alice"}
+	for i, user := range users {
+		err = shard.IndexDocument(ctx, fmt.Sprintf("post-%d", i), map[string]interface{}{
+			"title":   "post",
+			"user_id": user,
+		})
+		require.NoError(t, err)
+	}
+
+	query := []byte(`{"term": {"title": "post"}}`)
+	result, err := shard.SearchWithOptions(ctx, query, diagon.SearchOptions{CollapseField: "user_id"})
+	require.NoError(t, err)
+
+	// One hit per user; the total still counts every matching post
+	assert.Equal(t, int64(len(users)), result.TotalHits)
+	seen := map[interface{}]bool{}
+	for _, hit := range result.Hits {
+		assert.False(t, seen[hit.Source["user_id"]], "duplicate collapse key %v", hit.Source["user_id"])
+		seen[hit.Source["user_id"]] = true
+	}
+	assert.Len(t, seen, 3)
+
+	// Keeping two hits per user leaves room for inner hits
+	result, err = shard.SearchWithOptions(ctx, query, diagon.SearchOptions{CollapseField: "user_id", CollapseSize: 2})
+	require.NoError(t, err)
+	assert.Len(t, result.Hits, 5)
+}
+
+func TestShard_SearchPatternQueries(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   "/tmp/test-data",
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	sm.CreateShard(ctx, "pattern-index", 0, true)
+	shard, err := sm.GetShard("pattern-index", 0)
+	require.NoError(t, err)
+
+	docs := []map[string]interface{}{
+		{"title": "laptop", "price": 999.0},
+		{"title": "lamp"},
+		{"title": "desk", "price": 150.0},
+	}
+	for i, doc := range docs {
+		require.NoError(t, shard.IndexDocument(ctx, fmt.Sprintf("doc-%d", i), doc))
+	}
+
+	tests := []struct {
+		query string
+		want  int64
+	}{
+		{`{"prefix": {"title": "la"}}`, 2},
+		{`{"wildcard": {"title": "l*p"}}`, 1},
+		{`{"wildcard": {"title": "d?sk"}}`, 1},
+		{`{"exists": {"field": "price"}}`, 2},
+		{`{"exists": {"field": "title"}}`, 3},
+		{`{"exists": {"field": "color"}}`, 0},
+	}
+	for _, tt := range tests {
+		result, err := shard.Search(ctx, []byte(tt.query))
+		require.NoError(t, err, tt.query)
+		assert.Equal(t, tt.want, result.TotalHits, tt.query)
+	}
+}
+
+func TestShard_SearchNamedQueries(t *testing.T) {
+	cfg := &config.DataNodeConfig{
+		NodeID:    "node-1",
+		DataDir:   "/tmp/test-data",
+		MaxShards: 10,
+	}
+	logger := zap.NewNop()
+	diagonBridge, err := diagon.NewDiagonBridge(&diagon.Config{
+		DataDir: cfg.DataDir,
+		Logger:  logger,
+	})
+	require.NoError(t, err)
+
+	sm := NewShardManager(cfg, logger, diagonBridge, nil)
+
+	ctx := context.Background()
+	sm.Start(ctx)
+	defer sm.Stop(ctx)
+
+	sm.CreateShard(ctx, "named-index", 0, true)
+	shard, err := sm.GetShard("named-index", 0)
+	require.NoError(t, err)
+
+	docs := map[string]map[string]interface{}{
+		"both":     {"status": "active", "category": "laptop"},
+		"active":   {"status": "active", "category": "phone"},
+		"laptop":   {"status": "retired", "category": "laptop"},
+		"no-match": {"status": "retired", "category": "phone"},
+	}
+	for id, doc := range docs {
+		require.NoError(t, shard.IndexDocument(ctx, id, doc))
+	}
+
+	query := []byte(`{"bool": {"should": [
+		{"term": {"status": {"value": "active", "_name": "is_active"}}},
+		{"term": {"category": {"value": "laptop", "_name": "is_laptop"}}}
+	]}}`)
+	result, err := shard.SearchWithOptions(ctx, query, diagon.SearchOptions{})
+	require.NoError(t, err)
+
+	matched := make(map[string][]string, len(result.Hits))
+	for _, hit := range result.Hits {
+		matched[hit.ID] = hit.MatchedQueries
+	}
+	assert.Equal(t, map[string][]string{
+		"both":   {"is_active", "is_laptop"},
+		"active": {"is_active"},
+		"laptop": {"is_laptop"},
+	}, matched)
+}
@@ -0,0 +1,263 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+)
+
+// snapshotBlobsDir is the directory of a snapshot repository holding segment
+// files, each named by the SHA-256 of its contents so that files unchanged
+// since an earlier snapshot are stored once
+const snapshotBlobsDir = "blobs"
+
+// diagonLockFile is held by the shard's index writer and never snapshotted
+const diagonLockFile = "write.lock"
+
+// SnapshotFile is a committed segment file of a shard stored in a snapshot
+// repository
+type SnapshotFile struct {
+	Name      string
+	Blob      string
+	SizeBytes int64
+	Reused    bool
+}
+
+// Snapshot commits the shard and copies its segment files into the snapshot
+// repository at repositoryLocation. Files the repository already holds are
+// not copied again. Writes to the shard wait until the copy is done.
+func (s *Shard) Snapshot(ctx context.Context, repositoryLocation string) ([]SnapshotFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.State != ShardStateStarted {
+		return nil, fmt.Errorf("shard is not ready")
+	}
+
+	if err := s.DiagonShard.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit shard: %w", err)
+	}
+
+	blobsDir := filepath.Join(repositoryLocation, snapshotBlobsDir)
+	if err := os.MkdirAll(blobsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create repository blobs directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shard files: %w", err)
+	}
+
+	var files []SnapshotFile
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == diagonLockFile {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		file, err := snapshotFile(filepath.Join(s.Path, entry.Name()), blobsDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot %s: %w", entry.Name(), err)
+		}
+		files = append(files, file)
+	}
+
+	reused := 0
+	for _, file := range files {
+		if file.Reused {
+			reused++
+		}
+	}
+	s.logger.Info("Snapshotted shard",
+		zap.String("repository", repositoryLocation),
+		zap.Int("files", len(files)),
+		zap.Int("reused", reused))
+
+	return files, nil
+}
+
+// snapshotFile copies a segment file into blobsDir under its content hash,
+// unless a blob with that hash is already there
+func snapshotFile(path, blobsDir string) (SnapshotFile, error) {
+	hash, size, err := fileSHA256(path)
+	if err != nil {
+		return SnapshotFile{}, err
+	}
+	file := SnapshotFile{Name: filepath.Base(path), Blob: hash, SizeBytes: size}
+
+	blobPath := filepath.Join(blobsDir, hash)
+	if info, err := os.Stat(blobPath); err == nil && info.Size() == size {
+		file.Reused = true
+		return file, nil
+	}
+
+	// Copy to a temporary name first so a failed copy never leaves a
+	// partial blob behind under its final name
+	if err := copyFile(path, blobPath+".tmp"); err != nil {
+		return SnapshotFile{}, err
+	}
+	if err := os.Rename(blobPath+".tmp", blobPath); err != nil {
+		return SnapshotFile{}, err
+	}
+	return file, nil
+}
+
+// RestoreShard replaces the contents of a shard with segment files from the
+// snapshot repository at repositoryLocation, creating the shard if this node
+// does not hold it, and returns the restored shard
+func (sm *ShardManager) RestoreShard(ctx context.Context, indexName string, shardID int32, repositoryLocation string, files []SnapshotFile) (*Shard, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	key := shardKey(indexName, shardID)
+	isPrimary := true
+	if existing, exists := sm.shards[key]; exists {
+		isPrimary = existing.IsPrimary
+		if err := existing.Close(); err != nil {
+			return nil, fmt.Errorf("failed to close shard: %w", err)
+		}
+		delete(sm.shards, key)
+	}
+
+	// Replace the shard directory with the snapshot's files
+	shardPath := filepath.Join(sm.cfg.DataDir, indexName, fmt.Sprintf("shard_%d", shardID))
+	if err := os.RemoveAll(shardPath); err != nil {
+		return nil, fmt.Errorf("failed to clear shard directory: %w", err)
+	}
+	if err := os.MkdirAll(shardPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create shard directory: %w", err)
+	}
+	blobsDir := filepath.Join(repositoryLocation, snapshotBlobsDir)
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if file.Name != filepath.Base(file.Name) || file.Blob != filepath.Base(file.Blob) {
+			return nil, fmt.Errorf("invalid snapshot file %s", file.Name)
+		}
+		if err := copyFile(filepath.Join(blobsDir, file.Blob), filepath.Join(shardPath, file.Name)); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", file.Name, err)
+		}
+	}
+
+	// Open the restored files with the settings they were snapshotted with
+	settings, err := readShardSettings(shardPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read restored shard settings: %w", err)
+	}
+	opts, err := shardOptions(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read restored shard settings: %w", err)
+	}
+	analyzerSettings, err := shardAnalyzerSettings(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read restored shard settings: %w", err)
+	}
+	limits, err := shardDocumentLimits(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read restored shard settings: %w", err)
+	}
+	numericFields, err := shardNumericFields(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read restored shard settings: %w", err)
+	}
+	dateFields, err := shardDateFields(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read restored shard settings: %w", err)
+	}
+	geoPointFields, err := shardGeoPointFields(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read restored shard settings: %w", err)
+	}
+	diagonShard, err := sm.diagon.CreateShard(shardPath, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open restored Diagon shard: %w", err)
+	}
+	docsCount, err := diagonShard.NumDocs()
+	if err != nil {
+		diagonShard.Close()
+		return nil, fmt.Errorf("failed to count restored documents: %w", err)
+	}
+	sizeBytes, _ := dirSize(shardPath)
+
+	shard := &Shard{
+		IndexName:     indexName,
+		ShardID:       shardID,
+		IsPrimary:     isPrimary,
+		Path:          shardPath,
+		State:         ShardStateStarted,
+		StoreType:     opts.StoreType,
+		DiagonShard:   diagonShard,
+		Limits:        limits,
+		NumericFields: numericFields,
+		udfFilter:     sm.udfFilter,
+		breaker:       sm.breaker,
+		DocsCount:     docsCount,
+		SizeBytes:     sizeBytes,
+		logger:        sm.logger.With(zap.String("shard", key)),
+		analyzerCache: NewAnalyzerCache(),
+	}
+	if err := shard.SetAnalyzerSettings(analyzerSettings); err != nil {
+		shard.Close()
+		return nil, fmt.Errorf("failed to set up restored shard analyzers: %w", err)
+	}
+	shard.SetDateFields(dateFields)
+	shard.SetGeoPointFields(geoPointFields)
+	sm.shards[key] = shard
+
+	sm.logger.Info("Restored shard from snapshot",
+		zap.String("index", indexName),
+		zap.Int32("shard_id", shardID),
+		zap.String("repository", repositoryLocation),
+		zap.Int("files", len(files)),
+		zap.Int64("docs", docsCount))
+
+	return shard, nil
+}
+
+// fileSHA256 returns the hex SHA-256 and size of a file
+func fileSHA256(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// copyFile copies src to dst, syncing dst to disk
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
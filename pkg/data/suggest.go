@@ -0,0 +1,93 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/quidditch/quidditch/pkg/data/analysis"
+	"github.com/quidditch/quidditch/pkg/data/diagon"
+	"github.com/quidditch/quidditch/pkg/data/suggest"
+)
+
+// Suggest runs suggesters over the shard's suggest index, keyed by
+// suggester name. Diagon offers no access to its term dictionary, so the
+// first suggester on a field builds the field's terms or completion inputs
+// from the stored documents, analyzed with the field's analyzer the way its
+// terms are indexed; indexing keeps them current from then on. The shard is
+// only locked while each batch of documents is read.
+func (s *Shard) Suggest(ctx context.Context, suggesters []suggest.Suggester) (map[string][]suggest.Entry, error) {
+	s.mu.RLock()
+	if s.State != ShardStateStarted {
+		s.mu.RUnlock()
+		return nil, fmt.Errorf("shard is not ready")
+	}
+	analyze, err := s.suggestAnalyzer()
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	scan := func(from, size int) ([]suggest.Document, error) {
+		hits, err := s.ScanDocuments(ctx, from, size)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read documents: %w", err)
+		}
+		docs := make([]suggest.Document, len(hits))
+		for i, hit := range hits {
+			docs[i] = suggest.Document{ID: hit.ID, Source: hit.Source}
+		}
+		return docs, nil
+	}
+
+	results := make(map[string][]suggest.Entry, len(suggesters))
+	for _, suggester := range suggesters {
+		entries, err := s.suggestions.Suggest(ctx, suggester, analyze, scan)
+		if err != nil {
+			return nil, fmt.Errorf("suggester [%s]: %w", suggester.Name, err)
+		}
+
+		// Completions return the source of the documents they came from
+		if suggester.Type == suggest.TypeCompletion {
+			for _, entry := range entries {
+				for i := range entry.Options {
+					source, err := s.GetDocument(ctx, entry.Options[i].ID)
+					if err != nil {
+						return nil, fmt.Errorf("suggester [%s]: %w", suggester.Name, err)
+					}
+					entry.Options[i].Source = source
+				}
+			}
+		}
+		results[suggester.Name] = entries
+	}
+	return results, nil
+}
+
+// suggestAnalyzer returns a function analyzing text with the analyzer the
+// current settings give each field. The caller holds s.mu.
+func (s *Shard) suggestAnalyzer() (suggest.AnalyzeFunc, error) {
+	if s.analyzerSettings == nil || s.analyzerRegistry == nil || s.analyzerCache == nil {
+		return nil, fmt.Errorf("analyzer settings not initialized")
+	}
+	settings, registry, cache := s.analyzerSettings, s.analyzerRegistry, s.analyzerCache
+
+	var mu sync.Mutex
+	analyzers := make(map[string]diagon.FieldAnalyzer)
+	return func(field, text string) ([]analysis.Token, error) {
+		mu.Lock()
+		analyzer, ok := analyzers[field]
+		if !ok {
+			analyzerName := settings.GetAnalyzerForField(field)
+			var err error
+			analyzer, err = resolveAnalyzer(registry, cache, analyzerName)
+			if err != nil {
+				mu.Unlock()
+				return nil, fmt.Errorf("failed to get analyzer %s: %w", analyzerName, err)
+			}
+			analyzers[field] = analyzer
+		}
+		mu.Unlock()
+		return analyzeTokens(analyzer, text)
+	}, nil
+}
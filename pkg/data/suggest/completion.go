@@ -0,0 +1,98 @@
+package suggest
+
+import (
+	"sort"
+	"strings"
+)
+
+// completionInput is one completion input of a document with its weight. A
+// completion field holds a string, a list of strings, or objects of the
+// form {"input": "..." or [...], "weight": n}.
+type completionInput struct {
+	Text   string
+	Weight float64
+}
+
+// completionInputs returns the completion inputs of a completion field value
+func completionInputs(value interface{}) []completionInput {
+	var inputs []completionInput
+	for _, item := range flatten(value) {
+		switch v := item.(type) {
+		case string:
+			inputs = append(inputs, completionInput{Text: v, Weight: 1})
+		case map[string]interface{}:
+			weight := 1.0
+			if w, ok := v["weight"].(float64); ok {
+				weight = w
+			}
+			for _, text := range flatten(v["input"]) {
+				if s, ok := text.(string); ok {
+					inputs = append(inputs, completionInput{Text: s, Weight: weight})
+				}
+			}
+		}
+	}
+	return inputs
+}
+
+// runCompletion completes the suggested prefix from the completion inputs
+// of each document. Prefixes match case-insensitively and each document
+// contributes its heaviest matching input.
+func runCompletion(s Suggester, inputs map[string][]completionInput) Entry {
+	prefix := strings.ToLower(s.Text)
+	entry := Entry{Text: s.Text, Length: len([]rune(s.Text)), Options: []Option{}}
+
+	for id, docInputs := range inputs {
+		var best *completionInput
+		for _, input := range docInputs {
+			if !strings.HasPrefix(strings.ToLower(input.Text), prefix) {
+				continue
+			}
+			if best == nil || input.Weight > best.Weight {
+				best = &input
+			}
+		}
+		if best != nil {
+			entry.Options = append(entry.Options, Option{
+				Text:  best.Text,
+				Score: best.Weight,
+				ID:    id,
+			})
+		}
+	}
+
+	entry.Options = topCompletions(s, entry.Options)
+	return entry
+}
+
+// topCompletions sorts completions by weight, drops repeated texts when
+// skip_duplicates is set, and keeps the first size of them
+func topCompletions(s Suggester, options []Option) []Option {
+	sort.SliceStable(options, func(i, j int) bool {
+		a, b := options[i], options[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Text != b.Text {
+			return a.Text < b.Text
+		}
+		return a.ID < b.ID
+	})
+
+	if s.SkipDuplicates {
+		seen := make(map[string]bool, len(options))
+		unique := options[:0]
+		for _, option := range options {
+			if !seen[option.Text] {
+				seen[option.Text] = true
+				unique = append(unique, option)
+			}
+		}
+		options = unique
+	}
+
+	if len(options) > s.Size {
+		options = options[:s.Size]
+	}
+	return options
+}
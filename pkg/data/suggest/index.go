@@ -0,0 +1,283 @@
+package suggest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// scanBatch is the number of stored documents read at a time while building
+// the index of a field
+const scanBatch = 1000
+
+// ScanFunc returns up to size stored documents of a shard starting at
+// position from, in index order
+type ScanFunc func(from, size int) ([]Document, error)
+
+// Index holds, for each field a suggester has run on, the field's terms or
+// completion inputs by document. A field is built from the stored documents
+// the first time it is suggested on, then kept current as documents are
+// indexed and deleted, so later suggesters read the index rather than every
+// document. The zero Index is empty and ready to use.
+type Index struct {
+	mu     sync.RWMutex
+	fields map[fieldKey]*fieldIndex
+}
+
+// fieldKey identifies the index of a field for one suggester type
+type fieldKey struct {
+	typ, field string
+}
+
+// fieldIndex is the index of one field. Term fields hold the analyzed terms
+// of each document with their document frequencies; completion fields hold
+// each document's inputs.
+type fieldIndex struct {
+	ready chan struct{} // Closed once built
+	err   error         // Why the build failed, once ready
+
+	// Documents indexed or deleted while building, which the documents read
+	// by the build don't override
+	touched map[string]bool
+
+	terms  map[string][]string
+	freqs  map[string]int64
+	inputs map[string][]completionInput
+}
+
+// Suggest runs a suggester over the index of its field, building the field
+// from the documents scan returns if it isn't indexed yet. Completion
+// options carry their document's ID but not its source.
+func (ix *Index) Suggest(ctx context.Context, s Suggester, analyze AnalyzeFunc, scan ScanFunc) ([]Entry, error) {
+	if s.Type != TypeTerm && s.Type != TypeCompletion {
+		return nil, fmt.Errorf("unknown suggester type [%s]", s.Type)
+	}
+	f, err := ix.field(ctx, s, analyze, scan)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Type == TypeCompletion {
+		ix.mu.RLock()
+		defer ix.mu.RUnlock()
+		return []Entry{runCompletion(s, f.inputs)}, nil
+	}
+
+	tokens, err := analyze(s.Field, s.Text)
+	if err != nil {
+		return nil, err
+	}
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return termEntries(s, tokens, f.freqs), nil
+}
+
+// Update indexes a document's values of every indexed field, replacing
+// those of an earlier version of it. A field whose value can't be analyzed
+// is dropped, to be built again by the next suggester on it.
+func (ix *Index) Update(id string, source map[string]interface{}, analyze AnalyzeFunc) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	for key, f := range ix.fields {
+		values := fieldValues(source, key.field)
+		if key.typ == TypeCompletion {
+			f.setInputs(id, documentInputs(values))
+			continue
+		}
+		terms, err := documentTerms(key.field, values, analyze)
+		if err != nil {
+			delete(ix.fields, key)
+			continue
+		}
+		f.setTerms(id, terms)
+	}
+}
+
+// Delete removes a document from every indexed field
+func (ix *Index) Delete(id string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	for key, f := range ix.fields {
+		if key.typ == TypeCompletion {
+			f.setInputs(id, nil)
+		} else {
+			f.setTerms(id, nil)
+		}
+	}
+}
+
+// Reset drops every indexed field, so each is built again the next time it
+// is suggested on. Term fields depend on the field's analyzer, so the index
+// is reset when analyzers change.
+func (ix *Index) Reset() {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.fields = nil
+}
+
+// field returns the index of a suggester's field, building it if needed or
+// waiting for a build already under way
+func (ix *Index) field(ctx context.Context, s Suggester, analyze AnalyzeFunc, scan ScanFunc) (*fieldIndex, error) {
+	key := fieldKey{typ: s.Type, field: s.Field}
+
+	ix.mu.Lock()
+	f, ok := ix.fields[key]
+	if !ok {
+		f = &fieldIndex{
+			ready:   make(chan struct{}),
+			touched: make(map[string]bool),
+			terms:   make(map[string][]string),
+			freqs:   make(map[string]int64),
+			inputs:  make(map[string][]completionInput),
+		}
+		if ix.fields == nil {
+			ix.fields = make(map[fieldKey]*fieldIndex)
+		}
+		ix.fields[key] = f
+	}
+	ix.mu.Unlock()
+
+	if !ok {
+		ix.build(ctx, key, f, analyze, scan)
+	}
+
+	select {
+	case <-f.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f, nil
+}
+
+// build indexes the field from the stored documents. Documents are read and
+// analyzed without holding the index, which is only locked to add them.
+func (ix *Index) build(ctx context.Context, key fieldKey, f *fieldIndex, analyze AnalyzeFunc, scan ScanFunc) {
+	defer close(f.ready)
+
+	fail := func(err error) {
+		ix.mu.Lock()
+		defer ix.mu.Unlock()
+		f.err = err
+		if ix.fields[key] == f {
+			delete(ix.fields, key)
+		}
+	}
+
+	for from := 0; ; from += scanBatch {
+		if err := ctx.Err(); err != nil {
+			fail(err)
+			return
+		}
+		docs, err := scan(from, scanBatch)
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		terms := make([][]string, len(docs))
+		inputs := make([][]completionInput, len(docs))
+		for i, doc := range docs {
+			values := fieldValues(doc.Source, key.field)
+			if key.typ == TypeCompletion {
+				inputs[i] = documentInputs(values)
+				continue
+			}
+			if terms[i], err = documentTerms(key.field, values, analyze); err != nil {
+				fail(err)
+				return
+			}
+		}
+
+		ix.mu.Lock()
+		for i, doc := range docs {
+			if f.touched[doc.ID] {
+				continue
+			}
+			if key.typ == TypeCompletion {
+				f.setInputs(doc.ID, inputs[i])
+			} else {
+				f.setTerms(doc.ID, terms[i])
+			}
+		}
+		if len(docs) < scanBatch {
+			f.touched = nil
+		}
+		ix.mu.Unlock()
+
+		if len(docs) < scanBatch {
+			return
+		}
+	}
+}
+
+// setTerms replaces the terms of a document, or removes them when nil
+func (f *fieldIndex) setTerms(id string, terms []string) {
+	if f.touched != nil {
+		f.touched[id] = true
+	}
+	for _, term := range f.terms[id] {
+		if f.freqs[term]--; f.freqs[term] <= 0 {
+			delete(f.freqs, term)
+		}
+	}
+	delete(f.terms, id)
+	if len(terms) == 0 {
+		return
+	}
+	f.terms[id] = terms
+	for _, term := range terms {
+		f.freqs[term]++
+	}
+}
+
+// setInputs replaces the completion inputs of a document, or removes them
+// when nil
+func (f *fieldIndex) setInputs(id string, inputs []completionInput) {
+	if f.touched != nil {
+		f.touched[id] = true
+	}
+	if len(inputs) == 0 {
+		delete(f.inputs, id)
+		return
+	}
+	f.inputs[id] = inputs
+}
+
+// documentTerms returns the distinct analyzed terms of a document's values
+// of a field
+func documentTerms(field string, values []interface{}, analyze AnalyzeFunc) ([]string, error) {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, value := range values {
+		text, ok := value.(string)
+		if !ok {
+			continue
+		}
+		tokens, err := analyze(field, text)
+		if err != nil {
+			return nil, err
+		}
+		for _, token := range tokens {
+			if !seen[token.Text] {
+				seen[token.Text] = true
+				terms = append(terms, token.Text)
+			}
+		}
+	}
+	return terms, nil
+}
+
+// documentInputs returns the completion inputs of a document's values of a
+// completion field
+func documentInputs(values []interface{}) []completionInput {
+	var inputs []completionInput
+	for _, value := range values {
+		inputs = append(inputs, completionInputs(value)...)
+	}
+	return inputs
+}
@@ -0,0 +1,336 @@
+// Package suggest implements the term and completion suggesters of the
+// search API's suggest block.
+//
+// Diagon exposes neither its term dictionary nor an FST over field values,
+// so each shard keeps an Index of the fields suggesters run on, built from
+// its stored documents and updated as documents are indexed: the term
+// suggester corrects against the field's terms and their document
+// frequencies by edit distance, and the completion suggester matches the
+// inputs of a completion field by prefix. Each shard runs the suggesters
+// over its own index and the coordinator merges the results.
+package suggest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/quidditch/quidditch/pkg/data/analysis"
+)
+
+// Suggester types
+const (
+	TypeTerm       = "term"
+	TypeCompletion = "completion"
+)
+
+// Term suggester modes, deciding which input terms get suggestions
+const (
+	ModeMissing = "missing" // Only terms not in the shard
+	ModePopular = "popular" // Only more frequent terms than the input term
+	ModeAlways  = "always"  // Any matching term
+)
+
+// Defaults of the term and completion suggesters
+const (
+	DefaultSize          = 5
+	DefaultMaxEdits      = 2
+	DefaultPrefixLength  = 1
+	DefaultMinWordLength = 4
+)
+
+// Suggester is one named suggester of a suggest block
+type Suggester struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Field string `json:"field"`
+	Text  string `json:"text"` // Text to correct, or the prefix to complete
+	Size  int    `json:"size"`
+
+	// Term suggester
+	SuggestMode   string `json:"suggest_mode,omitempty"`
+	Sort          string `json:"sort,omitempty"` // "score" (default) or "frequency"
+	MaxEdits      int    `json:"max_edits,omitempty"`
+	PrefixLength  int    `json:"prefix_length,omitempty"`
+	MinWordLength int    `json:"min_word_length,omitempty"`
+
+	// Completion suggester
+	SkipDuplicates bool `json:"skip_duplicates,omitempty"`
+}
+
+// Entry is the suggestions for one term of the suggested text. A completion
+// suggester has a single entry for its prefix.
+type Entry struct {
+	Text    string   `json:"text"`
+	Offset  int      `json:"offset"`
+	Length  int      `json:"length"`
+	Options []Option `json:"options"`
+}
+
+// Option is one suggestion. Term suggestions carry the term's document
+// frequency; completions carry the document the input came from and score
+// by the input's weight.
+type Option struct {
+	Text   string                 `json:"text"`
+	Score  float64                `json:"score"`
+	Freq   int64                  `json:"freq,omitempty"`
+	ID     string                 `json:"_id,omitempty"`
+	Source map[string]interface{} `json:"_source,omitempty"`
+}
+
+// Document is a stored document suggestions are drawn from
+type Document struct {
+	ID     string
+	Source map[string]interface{}
+}
+
+// AnalyzeFunc analyzes text with the analyzer of a field
+type AnalyzeFunc func(field, text string) ([]analysis.Token, error)
+
+// suggesterJSON is one suggester of a suggest block as sent by clients
+type suggesterJSON struct {
+	Text       *string         `json:"text"`
+	Prefix     *string         `json:"prefix"`
+	Term       *termJSON       `json:"term"`
+	Completion *completionJSON `json:"completion"`
+}
+
+type termJSON struct {
+	Field         string `json:"field"`
+	Size          *int   `json:"size"`
+	SuggestMode   string `json:"suggest_mode"`
+	Sort          string `json:"sort"`
+	MaxEdits      *int   `json:"max_edits"`
+	PrefixLength  *int   `json:"prefix_length"`
+	MinWordLength *int   `json:"min_word_length"`
+}
+
+type completionJSON struct {
+	Field          string `json:"field"`
+	Size           *int   `json:"size"`
+	SkipDuplicates bool   `json:"skip_duplicates"`
+}
+
+// Parse parses the suggest block of a search request. A "text" entry of the
+// block is the text of the suggesters that don't set their own. Suggesters
+// are returned sorted by name.
+func Parse(block map[string]interface{}) ([]Suggester, error) {
+	var globalText *string
+	if value, ok := block["text"]; ok {
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("suggest [text] must be a string")
+		}
+		globalText = &text
+	}
+
+	names := make([]string, 0, len(block))
+	for name := range block {
+		if name != "text" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	suggesters := make([]Suggester, 0, len(names))
+	for _, name := range names {
+		s, err := parseSuggester(name, block[name], globalText)
+		if err != nil {
+			return nil, err
+		}
+		suggesters = append(suggesters, s)
+	}
+	return suggesters, nil
+}
+
+// parseSuggester parses one named suggester, applying the defaults
+func parseSuggester(name string, value interface{}, globalText *string) (Suggester, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return Suggester{}, fmt.Errorf("suggester [%s]: %w", name, err)
+	}
+	var raw suggesterJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Suggester{}, fmt.Errorf("suggester [%s] must be an object: %w", name, err)
+	}
+
+	s := Suggester{Name: name, Size: DefaultSize}
+	text := globalText
+	if raw.Text != nil {
+		text = raw.Text
+	}
+
+	size := func(value *int) error {
+		if value == nil {
+			return nil
+		}
+		if *value <= 0 {
+			return fmt.Errorf("suggester [%s]: size must be > 0, got %d", name, *value)
+		}
+		s.Size = *value
+		return nil
+	}
+
+	switch {
+	case raw.Term != nil && raw.Completion != nil:
+		return Suggester{}, fmt.Errorf("suggester [%s] must have exactly one of [term, completion]", name)
+	case raw.Term != nil:
+		t := raw.Term
+		s.Type = TypeTerm
+		s.Field = t.Field
+		s.SuggestMode = ModeMissing
+		s.Sort = "score"
+		s.MaxEdits = DefaultMaxEdits
+		s.PrefixLength = DefaultPrefixLength
+		s.MinWordLength = DefaultMinWordLength
+		if err := size(t.Size); err != nil {
+			return Suggester{}, err
+		}
+		switch t.SuggestMode {
+		case "":
+		case ModeMissing, ModePopular, ModeAlways:
+			s.SuggestMode = t.SuggestMode
+		default:
+			return Suggester{}, fmt.Errorf("suggester [%s]: unknown suggest_mode [%s]", name, t.SuggestMode)
+		}
+		switch t.Sort {
+		case "":
+		case "score", "frequency":
+			s.Sort = t.Sort
+		default:
+			return Suggester{}, fmt.Errorf("suggester [%s]: unknown sort [%s]", name, t.Sort)
+		}
+		if t.MaxEdits != nil {
+			if *t.MaxEdits < 1 || *t.MaxEdits > 2 {
+				return Suggester{}, fmt.Errorf("suggester [%s]: max_edits must be 1 or 2, got %d", name, *t.MaxEdits)
+			}
+			s.MaxEdits = *t.MaxEdits
+		}
+		if t.PrefixLength != nil {
+			if *t.PrefixLength < 0 {
+				return Suggester{}, fmt.Errorf("suggester [%s]: prefix_length must be >= 0, got %d", name, *t.PrefixLength)
+			}
+			s.PrefixLength = *t.PrefixLength
+		}
+		if t.MinWordLength != nil {
+			if *t.MinWordLength < 1 {
+				return Suggester{}, fmt.Errorf("suggester [%s]: min_word_length must be >= 1, got %d", name, *t.MinWordLength)
+			}
+			s.MinWordLength = *t.MinWordLength
+		}
+	case raw.Completion != nil:
+		c := raw.Completion
+		s.Type = TypeCompletion
+		s.Field = c.Field
+		s.SkipDuplicates = c.SkipDuplicates
+		if err := size(c.Size); err != nil {
+			return Suggester{}, err
+		}
+		if raw.Prefix != nil {
+			text = raw.Prefix
+		}
+	default:
+		return Suggester{}, fmt.Errorf("suggester [%s] must have one of [term, completion]", name)
+	}
+
+	if s.Field == "" {
+		return Suggester{}, fmt.Errorf("suggester [%s] requires a field", name)
+	}
+	if text == nil {
+		return Suggester{}, fmt.Errorf("suggester [%s] requires a text", name)
+	}
+	s.Text = *text
+	return s, nil
+}
+
+// Run runs a suggester over a set of documents
+func Run(s Suggester, docs []Document, analyze AnalyzeFunc) ([]Entry, error) {
+	var ix Index
+	scan := func(from, size int) ([]Document, error) {
+		from = min(from, len(docs))
+		return docs[from:min(from+size, len(docs))], nil
+	}
+	entries, err := ix.Suggest(context.Background(), s, analyze, scan)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Type == TypeCompletion {
+		sources := make(map[string]map[string]interface{}, len(docs))
+		for _, doc := range docs {
+			sources[doc.ID] = doc.Source
+		}
+		for i := range entries[0].Options {
+			entries[0].Options[i].Source = sources[entries[0].Options[i].ID]
+		}
+	}
+	return entries, nil
+}
+
+// Merge merges the entries a suggester returned on each shard. Every shard
+// analyzes the suggested text the same way, so entries line up by position.
+func Merge(s Suggester, shards [][]Entry) []Entry {
+	var merged []Entry
+	for _, entries := range shards {
+		if merged == nil && entries != nil {
+			merged = make([]Entry, len(entries))
+			for i, entry := range entries {
+				merged[i] = Entry{Text: entry.Text, Offset: entry.Offset, Length: entry.Length}
+			}
+		}
+		for i, entry := range entries {
+			if i < len(merged) {
+				merged[i].Options = append(merged[i].Options, entry.Options...)
+			}
+		}
+	}
+
+	for i := range merged {
+		switch s.Type {
+		case TypeTerm:
+			merged[i].Options = mergeTermOptions(s, merged[i].Options)
+		case TypeCompletion:
+			merged[i].Options = topCompletions(s, merged[i].Options)
+		}
+	}
+	return merged
+}
+
+// fieldValues returns the values of a dotted field path of a document,
+// flattening arrays along the way
+func fieldValues(source map[string]interface{}, path string) []interface{} {
+	if value, ok := source[path]; ok {
+		return flatten(value)
+	}
+	for i := 0; i < len(path); i++ {
+		if path[i] != '.' {
+			continue
+		}
+		var values []interface{}
+		for _, value := range flatten(source[path[:i]]) {
+			if object, ok := value.(map[string]interface{}); ok {
+				values = append(values, fieldValues(object, path[i+1:])...)
+			}
+		}
+		if len(values) > 0 {
+			return values
+		}
+	}
+	return nil
+}
+
+// flatten returns the items of an array value, or the value itself
+func flatten(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		var values []interface{}
+		for _, item := range v {
+			values = append(values, flatten(item)...)
+		}
+		return values
+	}
+	return []interface{}{value}
+}
@@ -0,0 +1,296 @@
+package suggest
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quidditch/quidditch/pkg/data/analysis"
+)
+
+// standardAnalyze analyzes every field with the standard analyzer
+func standardAnalyze(t *testing.T) AnalyzeFunc {
+	t.Helper()
+
+	registry, err := analysis.NewRegistry(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	analyzer, _ := registry.Get(analysis.DefaultAnalyzer)
+	return func(field, text string) ([]analysis.Token, error) {
+		return analyzer.Analyze(text), nil
+	}
+}
+
+// optionTexts returns the texts of the options of an entry
+func optionTexts(entry Entry) []string {
+	var texts []string
+	for _, option := range entry.Options {
+		texts = append(texts, option.Text)
+	}
+	return texts
+}
+
+func TestParse(t *testing.T) {
+	suggesters, err := Parse(map[string]interface{}{
+		"text": "global text",
+		"spelling": map[string]interface{}{
+			"term": map[string]interface{}{"field": "title", "max_edits": float64(1), "suggest_mode": "popular"},
+		},
+		"autocomplete": map[string]interface{}{
+			"prefix":     "nir",
+			"completion": map[string]interface{}{"field": "suggest", "size": float64(3), "skip_duplicates": true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Suggester{
+		{Name: "autocomplete", Type: TypeCompletion, Field: "suggest", Text: "nir", Size: 3, SkipDuplicates: true},
+		{
+			Name: "spelling", Type: TypeTerm, Field: "title", Text: "global text", Size: DefaultSize,
+			SuggestMode: ModePopular, Sort: "score", MaxEdits: 1, PrefixLength: DefaultPrefixLength, MinWordLength: DefaultMinWordLength,
+		},
+	}
+	if !reflect.DeepEqual(suggesters, want) {
+		t.Errorf("got  %+v\nwant %+v", suggesters, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		block   map[string]interface{}
+		wantErr string
+	}{
+		{map[string]interface{}{"s": map[string]interface{}{"text": "x"}}, "must have one of [term, completion]"},
+		{map[string]interface{}{"s": map[string]interface{}{"text": "x", "term": map[string]interface{}{}}}, "requires a field"},
+		{map[string]interface{}{"s": map[string]interface{}{"term": map[string]interface{}{"field": "f"}}}, "requires a text"},
+		{map[string]interface{}{"s": map[string]interface{}{"text": "x", "term": map[string]interface{}{"field": "f", "max_edits": float64(3)}}}, "max_edits must be 1 or 2"},
+		{map[string]interface{}{"s": map[string]interface{}{"text": "x", "term": map[string]interface{}{"field": "f", "suggest_mode": "sometimes"}}}, "unknown suggest_mode [sometimes]"},
+		{map[string]interface{}{"s": map[string]interface{}{"prefix": "x", "completion": map[string]interface{}{"field": "f", "size": float64(0)}}}, "size must be > 0"},
+		{map[string]interface{}{"text": float64(1)}, "[text] must be a string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantErr, func(t *testing.T) {
+			_, err := Parse(tt.block)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %q does not contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTermSuggester(t *testing.T) {
+	docs := []Document{
+		{ID: "1", Source: map[string]interface{}{"title": "The quick brown fox"}},
+		{ID: "2", Source: map[string]interface{}{"title": "A quick brown dog"}},
+		{ID: "3", Source: map[string]interface{}{"title": "Quack and quick"}},
+		{ID: "4", Source: map[string]interface{}{"title": "Brawn over brain"}},
+	}
+	s := Suggester{
+		Name: "spelling", Type: TypeTerm, Field: "title", Text: "qiuck brwon fox", Size: DefaultSize,
+		SuggestMode: ModeMissing, Sort: "score", MaxEdits: DefaultMaxEdits, PrefixLength: DefaultPrefixLength, MinWordLength: DefaultMinWordLength,
+	}
+
+	entries, err := Run(s, docs, standardAnalyze(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected an entry per term, got %+v", entries)
+	}
+
+	// A transposition is two edits, as far as quack, which is rarer
+	if got := entries[0]; got.Text != "qiuck" || got.Offset != 0 || got.Length != 5 {
+		t.Errorf("unexpected entry %+v", got)
+	}
+	if got, want := optionTexts(entries[0]), []string{"quick", "quack"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := entries[0].Options[0]; got.Freq != 3 || got.Score != 0.6 {
+		t.Errorf("unexpected option %+v", got)
+	}
+
+	// Equal scores go to the more frequent term
+	if got, want := optionTexts(entries[1]), []string{"brown", "brain", "brawn"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// fox is in the index, and too short anyway
+	if got := entries[2]; got.Text != "fox" || len(got.Options) != 0 || got.Offset != 12 {
+		t.Errorf("unexpected entry %+v", got)
+	}
+
+	t.Run("always mode", func(t *testing.T) {
+		s := s
+		s.Text, s.SuggestMode, s.MinWordLength = "quick", ModeAlways, 1
+		entries, err := Run(s, docs, standardAnalyze(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := optionTexts(entries[0]), []string{"quack"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		left, _ := Run(s, docs[:2], standardAnalyze(t))
+		right, _ := Run(s, docs[2:], standardAnalyze(t))
+		merged := Merge(s, [][]Entry{left, right})
+		if got, want := optionTexts(merged[0]), []string{"quick", "quack"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %q, want %q", got, want)
+		}
+		if merged[0].Options[0].Freq != 3 {
+			t.Errorf("expected frequencies summed across shards, got %+v", merged[0].Options[0])
+		}
+	})
+}
+
+func TestCompletionSuggester(t *testing.T) {
+	docs := []Document{
+		{ID: "1", Source: map[string]interface{}{"suggest": map[string]interface{}{"input": []interface{}{"Nirvana", "Nevermind"}, "weight": float64(34)}}},
+		{ID: "2", Source: map[string]interface{}{"suggest": []interface{}{"Nine Inch Nails", "NIN"}}},
+		{ID: "3", Source: map[string]interface{}{"suggest": map[string]interface{}{"input": "Nirvana", "weight": float64(10)}}},
+		{ID: "4", Source: map[string]interface{}{"suggest": "Pearl Jam"}},
+	}
+	s := Suggester{Name: "autocomplete", Type: TypeCompletion, Field: "suggest", Text: "ni", Size: DefaultSize}
+
+	entries, err := Run(s, docs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Text != "ni" || entries[0].Length != 2 {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if got, want := optionTexts(entries[0]), []string{"Nirvana", "Nirvana", "Nine Inch Nails"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := entries[0].Options[0]; got.ID != "1" || got.Score != 34 || got.Source == nil {
+		t.Errorf("unexpected option %+v", got)
+	}
+
+	t.Run("skip duplicates and size", func(t *testing.T) {
+		s := s
+		s.SkipDuplicates, s.Size = true, 1
+		left, _ := Run(s, docs[:2], nil)
+		right, _ := Run(s, docs[2:], nil)
+		merged := Merge(s, [][]Entry{right, left})
+		if got, want := optionTexts(merged[0]), []string{"Nirvana"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %q, want %q", got, want)
+		}
+		if merged[0].Options[0].ID != "1" {
+			t.Errorf("expected the heaviest completion, got %+v", merged[0].Options[0])
+		}
+	})
+}
+
+func TestIndex(t *testing.T) {
+	analyze := standardAnalyze(t)
+	stored := []Document{
+		{ID: "1", Source: map[string]interface{}{"title": "quick fox", "suggest": "Nirvana"}},
+		{ID: "2", Source: map[string]interface{}{"title": "quick dog"}},
+	}
+	scans := 0
+	scan := func(from, size int) ([]Document, error) {
+		scans++
+		from = min(from, len(stored))
+		return stored[from:min(from+size, len(stored))], nil
+	}
+
+	var ix Index
+	spelling := Suggester{
+		Name: "spelling", Type: TypeTerm, Field: "title", Text: "quikc", Size: DefaultSize,
+		SuggestMode: ModeMissing, Sort: "score", MaxEdits: DefaultMaxEdits, PrefixLength: DefaultPrefixLength, MinWordLength: DefaultMinWordLength,
+	}
+	autocomplete := Suggester{Name: "autocomplete", Type: TypeCompletion, Field: "suggest", Text: "n", Size: DefaultSize}
+	run := func(s Suggester) Entry {
+		t.Helper()
+		entries, err := ix.Suggest(context.Background(), s, analyze, scan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return entries[0]
+	}
+
+	// The first suggester on a field builds it from the stored documents
+	if got := run(spelling).Options; len(got) != 1 || got[0].Text != "quick" || got[0].Freq != 2 {
+		t.Errorf("unexpected options %+v", got)
+	}
+	if got, want := optionTexts(run(autocomplete)), []string{"Nirvana"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if scans != 2 {
+		t.Errorf("expected a scan per field, got %d", scans)
+	}
+
+	// Indexed and deleted documents update the built fields without a scan
+	ix.Update("3", map[string]interface{}{"title": "quick quirk", "suggest": "Nine Inch Nails"}, analyze)
+	ix.Update("1", map[string]interface{}{"title": "slow fox"}, analyze)
+	ix.Delete("2")
+	if got := run(spelling).Options; len(got) != 2 || got[0].Text != "quick" || got[0].Freq != 1 || got[1].Text != "quirk" {
+		t.Errorf("unexpected options %+v", got)
+	}
+	if got, want := optionTexts(run(autocomplete)), []string{"Nine Inch Nails"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if scans != 2 {
+		t.Errorf("expected no more scans, got %d", scans)
+	}
+
+	// Resetting the index builds fields again
+	ix.Reset()
+	run(spelling)
+	if scans != 3 {
+		t.Errorf("expected the field to be built again, got %d scans", scans)
+	}
+}
+
+func TestIndexUpdatedWhileBuilding(t *testing.T) {
+	analyze := standardAnalyze(t)
+	var ix Index
+	s := Suggester{Name: "autocomplete", Type: TypeCompletion, Field: "suggest", Text: "n", Size: DefaultSize}
+
+	// A document indexed or deleted while the field is read keeps its new
+	// version over the stale one the scan returns
+	scan := func(from, size int) ([]Document, error) {
+		ix.Update("1", map[string]interface{}{"suggest": "Nine Inch Nails"}, analyze)
+		ix.Delete("2")
+		return []Document{
+			{ID: "1", Source: map[string]interface{}{"suggest": "Nirvana"}},
+			{ID: "2", Source: map[string]interface{}{"suggest": "Nickelback"}},
+		}, nil
+	}
+	entries, err := ix.Suggest(context.Background(), s, analyze, scan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := optionTexts(entries[0]), []string{"Nine Inch Nails"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"kitten", "sitting", 3},
+		{"quick", "qiuck", 2},
+		{"", "abc", 3},
+		{"same", "same", 0},
+	}
+	for _, tt := range tests {
+		if got := levenshtein([]rune(tt.a), []rune(tt.b), 3); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+	if got := levenshtein([]rune("abcdef"), []rune("uvwxyz"), 2); got != 3 {
+		t.Errorf("expected the limit to cut the distance short, got %d", got)
+	}
+}
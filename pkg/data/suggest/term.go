@@ -0,0 +1,141 @@
+package suggest
+
+import (
+	"sort"
+
+	"github.com/quidditch/quidditch/pkg/data/analysis"
+)
+
+// termEntries suggests corrections for each term of the suggested text
+// from the terms of the field, within max_edits edits
+func termEntries(s Suggester, tokens []analysis.Token, freqs map[string]int64) []Entry {
+	entries := make([]Entry, 0, len(tokens))
+	for _, token := range tokens {
+		entries = append(entries, Entry{
+			Text:    token.Text,
+			Offset:  token.StartOffset,
+			Length:  token.EndOffset - token.StartOffset,
+			Options: termOptions(s, token.Text, freqs),
+		})
+	}
+	return entries
+}
+
+// termOptions returns the best corrections of a term among the field's
+// terms
+func termOptions(s Suggester, text string, freqs map[string]int64) []Option {
+	input := []rune(text)
+	if len(input) < s.MinWordLength {
+		return []Option{}
+	}
+	inputFreq := freqs[text]
+	if s.SuggestMode == ModeMissing && inputFreq > 0 {
+		return []Option{}
+	}
+
+	options := []Option{}
+	for term, freq := range freqs {
+		if term == text {
+			continue
+		}
+		if s.SuggestMode == ModePopular && freq <= inputFreq {
+			continue
+		}
+		candidate := []rune(term)
+		if !sharesPrefix(input, candidate, s.PrefixLength) {
+			continue
+		}
+		distance := levenshtein(input, candidate, s.MaxEdits)
+		if distance > s.MaxEdits {
+			continue
+		}
+		options = append(options, Option{
+			Text:  term,
+			Score: 1 - float64(distance)/float64(max(len(input), len(candidate))),
+			Freq:  freq,
+		})
+	}
+	return topTermOptions(s, options)
+}
+
+// mergeTermOptions merges the options of one term from several shards,
+// summing the frequencies of a term suggested by more than one
+func mergeTermOptions(s Suggester, options []Option) []Option {
+	byText := make(map[string]int, len(options))
+	merged := []Option{}
+	for _, option := range options {
+		if i, ok := byText[option.Text]; ok {
+			merged[i].Freq += option.Freq
+			continue
+		}
+		byText[option.Text] = len(merged)
+		merged = append(merged, option)
+	}
+	return topTermOptions(s, merged)
+}
+
+// topTermOptions sorts term options by score then frequency, or frequency
+// then score, and keeps the first size of them
+func topTermOptions(s Suggester, options []Option) []Option {
+	sort.Slice(options, func(i, j int) bool {
+		a, b := options[i], options[j]
+		if s.Sort == "frequency" && a.Freq != b.Freq {
+			return a.Freq > b.Freq
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Freq != b.Freq {
+			return a.Freq > b.Freq
+		}
+		return a.Text < b.Text
+	})
+	if len(options) > s.Size {
+		options = options[:s.Size]
+	}
+	return options
+}
+
+// sharesPrefix reports whether two terms start with the same n characters
+func sharesPrefix(a, b []rune, n int) bool {
+	if len(a) < n || len(b) < n {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// levenshtein returns the edit distance between two terms, or limit+1 once
+// the distance is known to exceed limit
+func levenshtein(a, b []rune, limit int) int {
+	if diff := len(a) - len(b); diff > limit || -diff > limit {
+		return limit + 1
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
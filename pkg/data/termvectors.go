@@ -0,0 +1,117 @@
+package data
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/quidditch/quidditch/pkg/data/analysis"
+	"github.com/quidditch/quidditch/pkg/data/diagon"
+)
+
+// TermVectorsUnavailableError reports a field whose term vectors can't be
+// rebuilt because Diagon analyzed it rather than an analyzer of the index
+type TermVectorsUnavailableError struct {
+	Field string
+}
+
+func (e *TermVectorsUnavailableError) Error() string {
+	return fmt.Sprintf("term vectors of field [%s] are not available: it has no analyzer mapped, "+
+		"so its terms were produced by the storage engine", e.Field)
+}
+
+// TermVectors returns the term vectors of the string fields of a document
+// of the shard, keyed by field path. Diagon keeps no per-document term
+// vectors, so they are rebuilt from the stored source with the analyzer the
+// index maps the field to, which produced the terms it was indexed with.
+// A string field without one was analyzed by Diagon itself and reports a
+// TermVectorsUnavailableError instead of terms that may differ. fields
+// limits the fields; empty returns every string field.
+func (s *Shard) TermVectors(doc map[string]interface{}, fields []string) (map[string]analysis.TermVector, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.analyzerSettings == nil || s.analyzerRegistry == nil || s.analyzerCache == nil {
+		return nil, fmt.Errorf("analyzer settings not initialized")
+	}
+
+	values := make(map[string][]string)
+	collectStringFields(doc, "", values)
+	if len(fields) > 0 {
+		requested := make(map[string][]string, len(fields))
+		for _, field := range fields {
+			if fieldValues, ok := values[field]; ok {
+				requested[field] = fieldValues
+			}
+		}
+		values = requested
+	}
+
+	vectors := make(map[string]analysis.TermVector, len(values))
+	for _, field := range slices.Sorted(maps.Keys(values)) {
+		analyzerName, ok := s.analyzerSettings.FieldAnalyzers[field]
+		if !ok {
+			return nil, &TermVectorsUnavailableError{Field: field}
+		}
+		analyzer, err := resolveAnalyzer(s.analyzerRegistry, s.analyzerCache, analyzerName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get analyzer %s: %w", analyzerName, err)
+		}
+		vector, err := analysis.NewTermVector(values[field], func(text string) ([]analysis.Token, error) {
+			return analyzeTokens(analyzer, text)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze field %s: %w", field, err)
+		}
+		vectors[field] = vector
+	}
+	return vectors, nil
+}
+
+// analyzeTokens analyzes text into tokens with their positions and offsets
+func analyzeTokens(analyzer diagon.FieldAnalyzer, text string) ([]analysis.Token, error) {
+	switch a := analyzer.(type) {
+	case *analysis.Analyzer:
+		return a.Analyze(text), nil
+	case *diagon.Analyzer:
+		tokens, err := a.Analyze(text)
+		if err != nil {
+			return nil, err
+		}
+		converted := make([]analysis.Token, len(tokens))
+		for i, token := range tokens {
+			converted[i] = analysis.Token(token)
+		}
+		return converted, nil
+	}
+	return nil, fmt.Errorf("analyzer %T does not produce tokens", analyzer)
+}
+
+// collectStringFields adds the string values of a document to values, keyed
+// by dot path. Arrays hold several values of one field.
+func collectStringFields(doc map[string]interface{}, prefix string, values map[string][]string) {
+	keys := make([]string, 0, len(doc))
+	for key := range doc {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		collectStringValues(doc[key], prefix+key, values)
+	}
+}
+
+// collectStringValues adds the string values of a field value to values
+func collectStringValues(value interface{}, path string, values map[string][]string) {
+	switch v := value.(type) {
+	case string:
+		values[path] = append(values[path], v)
+	case []interface{}:
+		for _, item := range v {
+			collectStringValues(item, path, values)
+		}
+	case map[string]interface{}:
+		collectStringFields(v, path+".", values)
+	}
+}
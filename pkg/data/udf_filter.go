@@ -11,6 +11,10 @@ import (
 	"go.uber.org/zap"
 )
 
+// udfBatchSize is the number of hits passed to a UDF that supports batching
+// in one call
+const udfBatchSize = 256
+
 // UDFFilter handles WASM UDF query filtering
 type UDFFilter struct {
 	registry *wasm.UDFRegistry
@@ -62,10 +66,12 @@ func (uf *UDFFilter) FilterResults(
 
 	// Return filtered results
 	return &diagon.SearchResult{
-		Took:      results.Took,
-		TotalHits: int64(len(filteredHits)),
-		MaxScore:  results.MaxScore,
-		Hits:      filteredHits,
+		Took:            results.Took,
+		TotalHits:       int64(len(filteredHits)),
+		MaxScore:        results.MaxScore,
+		Hits:            filteredHits,
+		TimedOut:        results.TimedOut,
+		TerminatedEarly: results.TerminatedEarly,
 	}, nil
 }
 
@@ -124,28 +130,19 @@ func (uf *UDFFilter) filterHits(
 		return nil, fmt.Errorf("failed to convert parameters: %w", err)
 	}
 
-	// Process each hit
-	for _, hit := range hits {
-		// Create document context from map
-		docCtx := wasm.NewDocumentContextFromMap(hit.ID, hit.Score, hit.Source)
-
-		// Call UDF
-		results, err := uf.registry.Call(
-			ctx,
-			udfQuery.Name,
-			udfQuery.Version,
-			docCtx,
-			params,
-		)
-
-		if err != nil {
-			// Log error but continue processing other documents
-			uf.logger.Warn("UDF execution failed for document",
-				zap.String("doc_id", hit.ID),
-				zap.String("udf_name", udfQuery.Name),
-				zap.Error(err))
-			continue
+	// Call the UDF on every hit, in batches when it supports them
+	hitResults := make([][]wasm.Value, 0, len(hits))
+	for start := 0; start < len(hits); start += udfBatchSize {
+		end := start + udfBatchSize
+		if end > len(hits) {
+			end = len(hits)
 		}
+		hitResults = append(hitResults, uf.callUDF(ctx, udfQuery.Name, udfQuery.Version, hits[start:end], params)...)
+	}
+
+	// Process each hit
+	for i, hit := range hits {
+		results := hitResults[i]
 
 		// Check if UDF returned true (include document)
 		if len(results) > 0 {
@@ -191,6 +188,47 @@ func (uf *UDFFilter) filterHits(
 	return filteredHits, nil
 }
 
+// callUDF calls a UDF on each hit and returns their results, nil for hits it
+// failed on. UDFs that support batching are called once for all the hits,
+// others once per hit.
+func (uf *UDFFilter) callUDF(
+	ctx context.Context,
+	name, version string,
+	hits []*diagon.Hit,
+	params map[string]wasm.Value,
+) [][]wasm.Value {
+	docCtxs := make([]*wasm.DocumentContext, len(hits))
+	for i, hit := range hits {
+		docCtxs[i] = wasm.NewDocumentContextFromMap(hit.ID, hit.Score, hit.Source)
+	}
+
+	if registered, err := uf.registry.Get(name, version); err == nil && registered.Metadata.SupportsBatch {
+		results, err := uf.registry.CallBatch(ctx, name, version, docCtxs, params)
+		if err == nil {
+			return results
+		}
+		uf.logger.Warn("Batch UDF execution failed, falling back to per-document calls",
+			zap.String("udf_name", name),
+			zap.Int("batch_size", len(hits)),
+			zap.Error(err))
+	}
+
+	results := make([][]wasm.Value, len(hits))
+	for i, docCtx := range docCtxs {
+		values, err := uf.registry.Call(ctx, name, version, docCtx, params)
+		if err != nil {
+			// Log error but continue processing other documents
+			uf.logger.Warn("UDF execution failed for document",
+				zap.String("doc_id", hits[i].ID),
+				zap.String("udf_name", name),
+				zap.Error(err))
+			continue
+		}
+		results[i] = values
+	}
+	return results
+}
+
 // convertParameters converts query parameters to WASM values
 func (uf *UDFFilter) convertParameters(params map[string]interface{}) (map[string]wasm.Value, error) {
 	values := make(map[string]wasm.Value, len(params))
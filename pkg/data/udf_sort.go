@@ -0,0 +1,103 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/quidditch/quidditch/pkg/data/diagon"
+	"github.com/quidditch/quidditch/pkg/wasm"
+	"go.uber.org/zap"
+)
+
+// scriptSort is a _script sort clause whose key is computed by a UDF
+type scriptSort struct {
+	UDF     string                 `json:"udf"`
+	Version string                 `json:"version"`
+	Params  map[string]interface{} `json:"params"`
+}
+
+// parseScriptSorts parses the _script sort clauses sent with a shard search,
+// each a JSON object such as {"_script": {"udf": "price_per_unit"}}
+func parseScriptSorts(clauses []string) ([]*scriptSort, error) {
+	sorts := make([]*scriptSort, 0, len(clauses))
+	for _, clause := range clauses {
+		var spec struct {
+			Script *scriptSort `json:"_script"`
+		}
+		if err := json.Unmarshal([]byte(clause), &spec); err != nil {
+			return nil, fmt.Errorf("invalid sort clause: %w", err)
+		}
+		if spec.Script == nil || spec.Script.UDF == "" {
+			return nil, fmt.Errorf("sort clause must be a _script sort with a udf name")
+		}
+		sorts = append(sorts, spec.Script)
+	}
+	return sorts, nil
+}
+
+// SortResults computes the sort keys of _script sort clauses: the UDF of
+// each clause is called with every hit's DocumentContext and the numeric
+// result appended to hit.Sort. The coordinator merges shard hits on these
+// keys, so a hit the UDF fails on fails the search.
+func (uf *UDFFilter) SortResults(ctx context.Context, clauses []string, results *diagon.SearchResult) error {
+	sorts, err := parseScriptSorts(clauses)
+	if err != nil {
+		return err
+	}
+
+	for _, sort := range sorts {
+		version, err := uf.registry.ResolveVersion(sort.UDF, sort.Version)
+		if err != nil {
+			return fmt.Errorf("_script sort: %w", err)
+		}
+
+		params, err := uf.convertParameters(sort.Params)
+		if err != nil {
+			return fmt.Errorf("failed to convert parameters: %w", err)
+		}
+
+		for _, hit := range results.Hits {
+			docCtx := wasm.NewDocumentContextFromMap(hit.ID, hit.Score, hit.Source)
+			values, err := uf.registry.Call(ctx, sort.UDF, version, docCtx, params)
+			if err != nil {
+				return fmt.Errorf("_script sort UDF %s failed for document %s: %w", sort.UDF, hit.ID, err)
+			}
+			if len(values) == 0 {
+				return fmt.Errorf("_script sort UDF %s returned no value for document %s", sort.UDF, hit.ID)
+			}
+
+			key, err := sortKey(values[0])
+			if err != nil {
+				return fmt.Errorf("_script sort UDF %s: %w", sort.UDF, err)
+			}
+			hit.Sort = append(hit.Sort, key)
+		}
+
+		uf.logger.Debug("Computed _script sort keys",
+			zap.String("udf_name", sort.UDF),
+			zap.String("udf_version", version),
+			zap.Int("hits", len(results.Hits)))
+	}
+
+	return nil
+}
+
+// sortKey converts a numeric UDF result to a sort key
+func sortKey(value wasm.Value) (float64, error) {
+	switch value.Type {
+	case wasm.ValueTypeI32:
+		v, err := value.AsInt32()
+		return float64(v), err
+	case wasm.ValueTypeI64:
+		v, err := value.AsInt64()
+		return float64(v), err
+	case wasm.ValueTypeF32:
+		v, err := value.AsFloat32()
+		return float64(v), err
+	case wasm.ValueTypeF64:
+		return value.AsFloat64()
+	default:
+		return 0, fmt.Errorf("sort key must be numeric, got %v", value.Type)
+	}
+}
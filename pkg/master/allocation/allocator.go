@@ -38,10 +38,15 @@ func (a *Allocator) AllocateShards(state *raft.ClusterState, indexName string, n
 	}
 
 	decisions := make([]AllocationDecision, 0)
+	shardCounts := a.countShards(dataNodes, state)
+
+	// copies holds the nodes each shard has been allocated to, so that no
+	// two copies share a node or a zone
+	copies := make(map[int32][]*raft.NodeMeta)
 
 	// Allocate primary shards
 	for shardID := int32(0); shardID < numShards; shardID++ {
-		node := a.selectNodeForShard(dataNodes, state, indexName, shardID, true)
+		node := a.selectNodeForShard(state, indexName, dataNodes, shardCounts)
 		if node == nil {
 			return nil, fmt.Errorf("failed to allocate primary shard %d", shardID)
 		}
@@ -53,6 +58,8 @@ func (a *Allocator) AllocateShards(state *raft.ClusterState, indexName string, n
 			NodeID:    node.NodeID,
 			Reason:    "primary_allocation",
 		})
+		shardCounts[node.NodeID]++
+		copies[shardID] = append(copies[shardID], node)
 
 		a.logger.Debug("Allocated primary shard",
 			zap.String("index", indexName),
@@ -60,26 +67,113 @@ func (a *Allocator) AllocateShards(state *raft.ClusterState, indexName string, n
 			zap.String("node", node.NodeID))
 	}
 
-	// Allocate replica shards
+	// Allocate replica shards. A replica with no valid node is left
+	// unassigned rather than placed next to another copy.
 	for replica := int32(0); replica < numReplicas; replica++ {
 		for shardID := int32(0); shardID < numShards; shardID++ {
-			// Find primary shard allocation
-			var primaryNode string
-			for _, decision := range decisions {
-				if decision.ShardID == shardID && decision.IsPrimary {
-					primaryNode = decision.NodeID
-					break
+			node := a.selectNodeForReplica(state, indexName, dataNodes, shardCounts, copies[shardID])
+			if node == nil {
+				a.logger.Warn("Failed to allocate replica shard, no node without a copy of the shard",
+					zap.String("index", indexName),
+					zap.Int32("shard_id", shardID),
+					zap.Int32("replica", replica))
+				continue
+			}
+
+			decisions = append(decisions, AllocationDecision{
+				IndexName: indexName,
+				ShardID:   shardID,
+				IsPrimary: false,
+				NodeID:    node.NodeID,
+				Reason:    fmt.Sprintf("replica_%d_allocation", replica),
+			})
+			shardCounts[node.NodeID]++
+			copies[shardID] = append(copies[shardID], node)
+
+			a.logger.Debug("Allocated replica shard",
+				zap.String("index", indexName),
+				zap.Int32("shard_id", shardID),
+				zap.Int32("replica", replica),
+				zap.String("node", node.NodeID))
+		}
+	}
+
+	return decisions, nil
+}
+
+// AdjustReplicas plans the replica changes that bring each shard of an
+// existing index to numReplicas assigned replicas. It returns replicas to
+// allocate, placed like those of a new index, and replicas to remove,
+// preferring copies that have not started and then those on the fullest
+// nodes. Replicas with no valid node are left out of the allocations.
+func (a *Allocator) AdjustReplicas(state *raft.ClusterState, indexName string, numReplicas int32) ([]AllocationDecision, []*raft.ShardRouting, error) {
+	index, exists := state.Indices[indexName]
+	if !exists {
+		return nil, nil, fmt.Errorf("%w [%s]", ErrIndexNotFound, indexName)
+	}
+
+	dataNodes := a.getHealthyDataNodes(state)
+	shardCounts := a.countShards(dataNodes, state)
+
+	keys := make([]string, 0, len(state.ShardRouting))
+	for key := range state.ShardRouting {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	decisions := make([]AllocationDecision, 0)
+	removals := make([]*raft.ShardRouting, 0)
+
+	for shardID := int32(0); shardID < index.NumShards; shardID++ {
+		var replicas []*raft.ShardRouting
+		copies := make([]*raft.NodeMeta, 0)
+		for _, key := range keys {
+			shard := state.ShardRouting[key]
+			if shard.IndexName != indexName || shard.ShardID != shardID || shard.State == "unassigned" {
+				continue
+			}
+			if !shard.IsPrimary {
+				replicas = append(replicas, shard)
+			}
+			if node, exists := state.Nodes[shard.NodeID]; exists {
+				copies = append(copies, node)
+			}
+			if node, exists := state.Nodes[shard.RelocatingNodeID]; exists {
+				copies = append(copies, node)
+			}
+		}
+
+		// Remove the replicas beyond the new count
+		if excess := len(replicas) - int(numReplicas); excess > 0 {
+			sort.SliceStable(replicas, func(i, j int) bool {
+				iStarted := replicas[i].State == "" || replicas[i].State == "started"
+				jStarted := replicas[j].State == "" || replicas[j].State == "started"
+				if iStarted != jStarted {
+					return !iStarted
 				}
+				return shardCounts[replicas[i].NodeID] > shardCounts[replicas[j].NodeID]
+			})
+			for _, shard := range replicas[:excess] {
+				removals = append(removals, shard)
+				shardCounts[shard.NodeID]--
+
+				a.logger.Debug("Removing replica shard",
+					zap.String("index", indexName),
+					zap.Int32("shard_id", shardID),
+					zap.String("node", shard.NodeID))
 			}
+			continue
+		}
 
-			// Select different node for replica
-			node := a.selectNodeForReplica(dataNodes, state, indexName, shardID, primaryNode)
+		// Allocate the missing replicas
+		for replica := int32(len(replicas)); replica < numReplicas; replica++ {
+			node := a.selectNodeForReplica(state, indexName, dataNodes, shardCounts, copies)
 			if node == nil {
-				a.logger.Warn("Failed to allocate replica shard",
+				a.logger.Warn("Failed to allocate replica shard, no node without a copy of the shard",
 					zap.String("index", indexName),
 					zap.Int32("shard_id", shardID),
 					zap.Int32("replica", replica))
-				continue
+				break
 			}
 
 			decisions = append(decisions, AllocationDecision{
@@ -89,6 +183,8 @@ func (a *Allocator) AllocateShards(state *raft.ClusterState, indexName string, n
 				NodeID:    node.NodeID,
 				Reason:    fmt.Sprintf("replica_%d_allocation", replica),
 			})
+			shardCounts[node.NodeID]++
+			copies = append(copies, node)
 
 			a.logger.Debug("Allocated replica shard",
 				zap.String("index", indexName),
@@ -98,72 +194,95 @@ func (a *Allocator) AllocateShards(state *raft.ClusterState, indexName string, n
 		}
 	}
 
-	return decisions, nil
+	return decisions, removals, nil
 }
 
-// RebalanceShards rebalances shards across nodes
+// RebalanceShards plans relocations that even out the number of shards per
+// healthy data node, moving shards from the fullest node to the emptiest one
+// until they differ by at most one. A node above the high disk watermark
+// first has a shard moved off it each round. Shards relocating to a node
+// already count towards it, and no more than the cluster_concurrent_rebalance
+// limit of relocations are in flight at once. Shards on nodes outside their
+// index's storage tier move to that tier before anything else. The
+// allocation.enable setting
+// limits relocations to primaries, or disables them.
 func (a *Allocator) RebalanceShards(state *raft.ClusterState) ([]RebalanceDecision, error) {
+	enable := allocationEnable(state)
+	if enable == "none" || enable == "new_primaries" {
+		return nil, nil // Rebalancing disabled
+	}
+
 	dataNodes := a.getHealthyDataNodes(state)
 	if len(dataNodes) < 2 {
 		return nil, nil // No rebalancing needed
 	}
 
-	// Calculate current shard distribution
+	// Relocations already in flight count against the limit
+	maxRelocations := concurrentRebalance(state)
+	inFlight := 0
+	for _, shard := range state.ShardRouting {
+		if shard.State == "relocating" {
+			inFlight++
+		}
+	}
+	if maxRelocations >= 0 && inFlight >= maxRelocations {
+		return nil, nil
+	}
+
+	// Calculate current shard distribution, and which nodes hold a copy of
+	// each shard
 	nodeShardCounts := make(map[string]int)
 	for _, node := range dataNodes {
 		nodeShardCounts[node.NodeID] = 0
 	}
-
+	copies := make(map[string]bool)
 	for _, shard := range state.ShardRouting {
-		if count, exists := nodeShardCounts[shard.NodeID]; exists {
-			nodeShardCounts[shard.NodeID] = count + 1
+		nodeID := shard.NodeID
+		if shard.State == "relocating" && shard.RelocatingNodeID != "" {
+			nodeID = shard.RelocatingNodeID
 		}
-	}
-
-	// Find imbalance
-	avgShards := 0
-	for _, count := range nodeShardCounts {
-		avgShards += count
-	}
-	if len(nodeShardCounts) > 0 {
-		avgShards = avgShards / len(nodeShardCounts)
+		if count, exists := nodeShardCounts[nodeID]; exists {
+			nodeShardCounts[nodeID] = count + 1
+		}
+		copies[shardCopyKey(shard.IndexName, shard.ShardID, shard.NodeID)] = true
+		copies[shardCopyKey(shard.IndexName, shard.ShardID, shard.RelocatingNodeID)] = true
 	}
 
 	decisions := make([]RebalanceDecision, 0)
+	moved := make(map[string]bool)
+	drained := make(map[string]bool)
 
-	// Move shards from overloaded nodes to underloaded nodes
-	for {
-		overloadedNode := a.findOverloadedNode(nodeShardCounts, avgShards)
-		underloadedNode := a.findUnderloadedNode(nodeShardCounts, avgShards)
-
-		if overloadedNode == "" || underloadedNode == "" {
-			break // No more rebalancing needed
-		}
-
-		// Find a shard to move
-		shardToMove := a.findShardToMove(state, overloadedNode)
+	for maxRelocations < 0 || inFlight+len(decisions) < maxRelocations {
+		nodeIDs := a.sortNodesByShardCount(nodeShardCounts)
+		fromNode, toNode, shardToMove, reason := a.findRelocation(state, nodeIDs, nodeShardCounts, enable == "primaries", copies, moved, drained)
 		if shardToMove == nil {
-			break
+			break // Balanced, or no shard may move
 		}
 
 		decisions = append(decisions, RebalanceDecision{
 			IndexName: shardToMove.IndexName,
 			ShardID:   shardToMove.ShardID,
 			IsPrimary: shardToMove.IsPrimary,
-			FromNode:  overloadedNode,
-			ToNode:    underloadedNode,
-			Reason:    "rebalance",
+			FromNode:  fromNode,
+			ToNode:    toNode,
+			Reason:    reason,
 		})
+		if reason == "disk_watermark" {
+			drained[fromNode] = true
+		}
 
 		// Update counts
-		nodeShardCounts[overloadedNode]--
-		nodeShardCounts[underloadedNode]++
+		moved[fmt.Sprintf("%s:%d", shardToMove.IndexName, shardToMove.ShardID)] = true
+		copies[shardCopyKey(shardToMove.IndexName, shardToMove.ShardID, toNode)] = true
+		nodeShardCounts[fromNode]--
+		nodeShardCounts[toNode]++
 
 		a.logger.Info("Rebalancing shard",
 			zap.String("index", shardToMove.IndexName),
 			zap.Int32("shard_id", shardToMove.ShardID),
-			zap.String("from", overloadedNode),
-			zap.String("to", underloadedNode))
+			zap.String("from", fromNode),
+			zap.String("to", toNode),
+			zap.String("reason", reason))
 	}
 
 	return decisions, nil
@@ -191,8 +310,8 @@ func (a *Allocator) getHealthyDataNodes(state *raft.ClusterState) []*raft.NodeMe
 	return nodes
 }
 
-func (a *Allocator) selectNodeForShard(nodes []*raft.NodeMeta, state *raft.ClusterState, indexName string, shardID int32, isPrimary bool) *raft.NodeMeta {
-	// Count shards per node
+// countShards returns the number of shards allocated to each node
+func (a *Allocator) countShards(nodes []*raft.NodeMeta, state *raft.ClusterState) map[string]int {
 	shardCounts := make(map[string]int)
 	for _, node := range nodes {
 		shardCounts[node.NodeID] = 0
@@ -203,95 +322,211 @@ func (a *Allocator) selectNodeForShard(nodes []*raft.NodeMeta, state *raft.Clust
 			shardCounts[shard.NodeID] = count + 1
 		}
 	}
+	return shardCounts
+}
 
-	// Sort nodes by shard count (ascending)
-	sort.Slice(nodes, func(i, j int) bool {
-		return shardCounts[nodes[i].NodeID] < shardCounts[nodes[j].NodeID]
-	})
-
-	// Return node with fewest shards
-	if len(nodes) > 0 {
-		return nodes[0]
+// selectNodeForShard returns the node with the fewest shards that may take
+// a new primary of an index, or nil if there is none
+func (a *Allocator) selectNodeForShard(state *raft.ClusterState, indexName string, nodes []*raft.NodeMeta, shardCounts map[string]int) *raft.NodeMeta {
+	candidateNodes := make([]*raft.NodeMeta, 0, len(nodes))
+	for _, node := range nodes {
+		if canAllocate(state, indexName, node, nil, true, true) {
+			candidateNodes = append(candidateNodes, node)
+		}
 	}
 
-	return nil
+	return leastLoadedNode(candidateNodes, shardCounts)
 }
 
-func (a *Allocator) selectNodeForReplica(nodes []*raft.NodeMeta, state *raft.ClusterState, indexName string, shardID int32, primaryNode string) *raft.NodeMeta {
-	// Count shards per node
-	shardCounts := make(map[string]int)
+// selectNodeForReplica returns the node with the fewest shards among those
+// that may hold another copy of a shard of an index, or nil if there is none
+func (a *Allocator) selectNodeForReplica(state *raft.ClusterState, indexName string, nodes []*raft.NodeMeta, shardCounts map[string]int, copies []*raft.NodeMeta) *raft.NodeMeta {
+	candidateNodes := make([]*raft.NodeMeta, 0, len(nodes))
 	for _, node := range nodes {
-		shardCounts[node.NodeID] = 0
-	}
-
-	for _, shard := range state.ShardRouting {
-		if count, exists := shardCounts[shard.NodeID]; exists {
-			shardCounts[shard.NodeID] = count + 1
+		if canAllocate(state, indexName, node, copies, false, false) {
+			candidateNodes = append(candidateNodes, node)
 		}
 	}
 
-	// Filter out primary node
-	candidateNodes := make([]*raft.NodeMeta, 0)
+	return leastLoadedNode(candidateNodes, shardCounts)
+}
+
+// leastLoadedNode returns the node with the fewest shards, breaking ties by
+// node ID
+func leastLoadedNode(nodes []*raft.NodeMeta, shardCounts map[string]int) *raft.NodeMeta {
+	var selected *raft.NodeMeta
 	for _, node := range nodes {
-		if node.NodeID != primaryNode {
-			candidateNodes = append(candidateNodes, node)
+		if selected == nil || shardCounts[node.NodeID] < shardCounts[selected.NodeID] ||
+			(shardCounts[node.NodeID] == shardCounts[selected.NodeID] && node.NodeID < selected.NodeID) {
+			selected = node
 		}
 	}
+	return selected
+}
 
-	if len(candidateNodes) == 0 {
-		return nil
+// sortNodesByShardCount returns the node IDs from the most shards to the
+// fewest, breaking ties by node ID so decisions are deterministic
+func (a *Allocator) sortNodesByShardCount(shardCounts map[string]int) []string {
+	nodeIDs := make([]string, 0, len(shardCounts))
+	for nodeID := range shardCounts {
+		nodeIDs = append(nodeIDs, nodeID)
 	}
-
-	// Sort candidates by shard count
-	sort.Slice(candidateNodes, func(i, j int) bool {
-		return shardCounts[candidateNodes[i].NodeID] < shardCounts[candidateNodes[j].NodeID]
+	sort.Slice(nodeIDs, func(i, j int) bool {
+		if shardCounts[nodeIDs[i]] != shardCounts[nodeIDs[j]] {
+			return shardCounts[nodeIDs[i]] > shardCounts[nodeIDs[j]]
+		}
+		return nodeIDs[i] < nodeIDs[j]
 	})
-
-	return candidateNodes[0]
+	return nodeIDs
 }
 
-func (a *Allocator) findOverloadedNode(shardCounts map[string]int, avgShards int) string {
-	var overloadedNode string
-	maxShards := avgShards
+// findRelocation picks the next shard to relocate, given the node IDs from
+// the most shards to the fewest. A shard on a node outside its index's
+// storage tier moves first, then a shard on a node above the high disk
+// watermark that has not been drained this round, each to the emptiest node
+// that may take it; otherwise a shard moves from a fuller node to one with
+// at least two fewer shards. It returns the source and target nodes, the
+// shard and the reason for the move, or a nil shard.
+func (a *Allocator) findRelocation(state *raft.ClusterState, nodeIDs []string, shardCounts map[string]int, primariesOnly bool, copies, moved, drained map[string]bool) (string, string, *raft.ShardRouting, string) {
+	for _, fromNode := range nodeIDs {
+		for i := len(nodeIDs) - 1; i >= 0; i-- {
+			toNode := nodeIDs[i]
+			if toNode == fromNode {
+				continue
+			}
+			if shard := a.findMisplacedShard(state, fromNode, toNode, primariesOnly, copies, moved); shard != nil {
+				return fromNode, toNode, shard, "tier"
+			}
+		}
+	}
+
+	for _, fromNode := range nodeIDs {
+		if drained[fromNode] || !aboveHighWatermark(state, state.Nodes[fromNode]) {
+			continue
+		}
+		for i := len(nodeIDs) - 1; i >= 0; i-- {
+			toNode := nodeIDs[i]
+			if toNode == fromNode {
+				continue
+			}
+			if shard := a.findShardToMove(state, fromNode, toNode, primariesOnly, copies, moved); shard != nil {
+				return fromNode, toNode, shard, "disk_watermark"
+			}
+		}
+	}
 
-	for nodeID, count := range shardCounts {
-		if count > maxShards+1 { // Allow 1 shard difference
-			overloadedNode = nodeID
-			maxShards = count
+	for i := len(nodeIDs) - 1; i > 0; i-- {
+		toNode := nodeIDs[i]
+		for _, fromNode := range nodeIDs[:i] {
+			if shardCounts[fromNode]-shardCounts[toNode] <= 1 {
+				break
+			}
+			if shard := a.findShardToMove(state, fromNode, toNode, primariesOnly, copies, moved); shard != nil {
+				return fromNode, toNode, shard, "rebalance"
+			}
 		}
 	}
 
-	return overloadedNode
+	return "", "", nil, ""
 }
 
-func (a *Allocator) findUnderloadedNode(shardCounts map[string]int, avgShards int) string {
-	var underloadedNode string
-	minShards := avgShards
+// findShardToMove picks a started shard on fromNode that may be placed on
+// toNode, preferring replicas, which are safer to move. At most one copy of
+// each shard is moved per round.
+func (a *Allocator) findShardToMove(state *raft.ClusterState, fromNode, toNode string, primariesOnly bool, copies map[string]bool, moved map[string]bool) *raft.ShardRouting {
+	keys := make([]string, 0, len(state.ShardRouting))
+	for key := range state.ShardRouting {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-	for nodeID, count := range shardCounts {
-		if count < minShards-1 { // Allow 1 shard difference
-			underloadedNode = nodeID
-			minShards = count
+	var primary *raft.ShardRouting
+	for _, key := range keys {
+		shard := state.ShardRouting[key]
+		if shard.NodeID != fromNode || moved[fmt.Sprintf("%s:%d", shard.IndexName, shard.ShardID)] {
+			continue
+		}
+		if shard.State != "" && shard.State != "started" {
+			continue // Initializing or already relocating
+		}
+		if copies[shardCopyKey(shard.IndexName, shard.ShardID, toNode)] {
+			continue // toNode already holds a copy of this shard
+		}
+		if target, exists := state.Nodes[toNode]; exists && !canAllocate(state, shard.IndexName, target, otherCopies(state, shard), shard.IsPrimary, false) {
+			continue // A decider keeps the shard off toNode
+		}
+		if target, exists := state.Nodes[toNode]; exists && !fitsBelowLowWatermark(state, target, shardSize(state, shard)) {
+			continue // toNode would pass the low watermark holding the shard
+		}
+		if !shard.IsPrimary && !primariesOnly {
+			return shard
+		}
+		if shard.IsPrimary && primary == nil {
+			primary = shard
 		}
 	}
 
-	return underloadedNode
+	return primary
 }
 
-func (a *Allocator) findShardToMove(state *raft.ClusterState, fromNode string) *raft.ShardRouting {
-	// Find a non-primary shard to move (replicas are safer to move)
-	for _, shard := range state.ShardRouting {
-		if shard.NodeID == fromNode && !shard.IsPrimary {
+// findMisplacedShard picks a started shard on fromNode that its index's
+// storage tier keeps off fromNode and that may be placed on toNode
+func (a *Allocator) findMisplacedShard(state *raft.ClusterState, fromNode, toNode string, primariesOnly bool, copies map[string]bool, moved map[string]bool) *raft.ShardRouting {
+	source, exists := state.Nodes[fromNode]
+	if !exists {
+		return nil
+	}
+
+	keys := make([]string, 0, len(state.ShardRouting))
+	for key := range state.ShardRouting {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		shard := state.ShardRouting[key]
+		if shard.NodeID != fromNode || moved[fmt.Sprintf("%s:%d", shard.IndexName, shard.ShardID)] {
+			continue
+		}
+		if shard.State != "" && shard.State != "started" {
+			continue
+		}
+		if primariesOnly && !shard.IsPrimary {
+			continue
+		}
+		if decideTier(state, shard.IndexName, source).Allowed {
+			continue
+		}
+		if copies[shardCopyKey(shard.IndexName, shard.ShardID, toNode)] {
+			continue
+		}
+		if target, exists := state.Nodes[toNode]; exists && canAllocate(state, shard.IndexName, target, otherCopies(state, shard), shard.IsPrimary, false) &&
+			fitsBelowLowWatermark(state, target, shardSize(state, shard)) {
 			return shard
 		}
 	}
+	return nil
+}
 
-	// If no replicas, move a primary
-	for _, shard := range state.ShardRouting {
-		if shard.NodeID == fromNode {
-			return shard
+// otherCopies returns the nodes holding the copies of a shard other than the
+// given one, including the targets of relocating copies
+func otherCopies(state *raft.ClusterState, shard *raft.ShardRouting) []*raft.NodeMeta {
+	nodes := make([]*raft.NodeMeta, 0)
+	for _, other := range state.ShardRouting {
+		if other == shard || other.IndexName != shard.IndexName || other.ShardID != shard.ShardID {
+			continue
+		}
+		if node, exists := state.Nodes[other.NodeID]; exists {
+			nodes = append(nodes, node)
+		}
+		if node, exists := state.Nodes[other.RelocatingNodeID]; exists {
+			nodes = append(nodes, node)
 		}
 	}
+	return nodes
+}
 
-	return nil
+// shardCopyKey identifies the copy of a shard held by a node
+func shardCopyKey(indexName string, shardID int32, nodeID string) string {
+	return fmt.Sprintf("%s:%d@%s", indexName, shardID, nodeID)
 }
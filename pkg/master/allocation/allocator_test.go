@@ -1,6 +1,8 @@
 package allocation
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/quidditch/quidditch/pkg/master/raft"
@@ -451,5 +453,517 @@ func TestAllocateShardsLargeNumberOfShards(t *testing.T) {
 	}
 }
 
-// Add fmt import at the top
-import "fmt"
+func TestAllocateShardsReplicaAntiAffinity(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	allocator := NewAllocator(logger)
+
+	// node-2 is emptier, so without anti-affinity it would take every copy
+	state := &raft.ClusterState{
+		Version:     1,
+		ClusterUUID: "test-cluster",
+		Indices:     make(map[string]*raft.IndexMeta),
+		Nodes: map[string]*raft.NodeMeta{
+			"node-1": {NodeID: "node-1", NodeType: "data", Status: "healthy"},
+			"node-2": {NodeID: "node-2", NodeType: "data", Status: "healthy"},
+		},
+		ShardRouting: map[string]*raft.ShardRouting{
+			"other:0": {IndexName: "other", ShardID: 0, IsPrimary: true, NodeID: "node-1"},
+			"other:1": {IndexName: "other", ShardID: 1, IsPrimary: true, NodeID: "node-1"},
+			"other:2": {IndexName: "other", ShardID: 2, IsPrimary: true, NodeID: "node-1"},
+			"other:3": {IndexName: "other", ShardID: 3, IsPrimary: true, NodeID: "node-1"},
+		},
+	}
+
+	// Two replicas per shard, but only one can be placed apart from the primary
+	decisions, err := allocator.AllocateShards(state, "test-index", 2, 2)
+	if err != nil {
+		t.Fatalf("AllocateShards failed: %v", err)
+	}
+
+	primaries := make(map[int32]string)
+	replicas := make(map[int32][]string)
+	for _, decision := range decisions {
+		if decision.IsPrimary {
+			primaries[decision.ShardID] = decision.NodeID
+		} else {
+			replicas[decision.ShardID] = append(replicas[decision.ShardID], decision.NodeID)
+		}
+	}
+
+	for shardID := int32(0); shardID < 2; shardID++ {
+		if len(replicas[shardID]) != 1 {
+			t.Fatalf("Shard %d: expected 1 assigned replica, got %v", shardID, replicas[shardID])
+		}
+		if replicas[shardID][0] == primaries[shardID] {
+			t.Errorf("Shard %d: replica placed on primary node %s", shardID, primaries[shardID])
+		}
+	}
+}
+
+func TestAllocateShardsZoneAwareness(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	allocator := NewAllocator(logger)
+
+	state := &raft.ClusterState{
+		Version:     1,
+		ClusterUUID: "test-cluster",
+		Indices:     make(map[string]*raft.IndexMeta),
+		Nodes: map[string]*raft.NodeMeta{
+			"node-1": {NodeID: "node-1", NodeType: "data", Status: "healthy", Zone: "zone-a"},
+			"node-2": {NodeID: "node-2", NodeType: "data", Status: "healthy", Zone: "zone-a"},
+			"node-3": {NodeID: "node-3", NodeType: "data", Status: "healthy", Zone: "zone-b"},
+		},
+		ShardRouting: make(map[string]*raft.ShardRouting),
+	}
+
+	decisions, err := allocator.AllocateShards(state, "test-index", 4, 1)
+	if err != nil {
+		t.Fatalf("AllocateShards failed: %v", err)
+	}
+
+	shardZones := make(map[int32][]string)
+	for _, decision := range decisions {
+		shardZones[decision.ShardID] = append(shardZones[decision.ShardID], state.Nodes[decision.NodeID].Zone)
+	}
+
+	for shardID, zones := range shardZones {
+		if len(zones) == 2 && zones[0] == zones[1] {
+			t.Errorf("Shard %d has both copies in %s", shardID, zones[0])
+		}
+	}
+
+	// A second replica has no zone left
+	decisions, err = allocator.AllocateShards(state, "test-index-2", 1, 2)
+	if err != nil {
+		t.Fatalf("AllocateShards failed: %v", err)
+	}
+	if len(decisions) != 2 {
+		t.Errorf("Expected a primary and 1 replica, got %d decisions", len(decisions))
+	}
+}
+
+func TestRebalanceShardsRespectsAntiAffinity(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	allocator := NewAllocator(logger)
+
+	// Every shard on node-1 has its other copy in node-3's zone
+	state := &raft.ClusterState{
+		Version:     1,
+		ClusterUUID: "test-cluster",
+		Indices:     make(map[string]*raft.IndexMeta),
+		Nodes: map[string]*raft.NodeMeta{
+			"node-1": {NodeID: "node-1", NodeType: "data", Status: "healthy", Zone: "zone-a"},
+			"node-2": {NodeID: "node-2", NodeType: "data", Status: "healthy", Zone: "zone-b"},
+			"node-3": {NodeID: "node-3", NodeType: "data", Status: "healthy", Zone: "zone-b"},
+		},
+		ShardRouting: map[string]*raft.ShardRouting{
+			"index-1:0":                {IndexName: "index-1", ShardID: 0, IsPrimary: true, NodeID: "node-1", State: "started"},
+			"index-1:1":                {IndexName: "index-1", ShardID: 1, IsPrimary: true, NodeID: "node-1", State: "started"},
+			"index-1:2":                {IndexName: "index-1", ShardID: 2, IsPrimary: true, NodeID: "node-1", State: "started"},
+			"index-1:0:replica:node-2": {IndexName: "index-1", ShardID: 0, NodeID: "node-2", State: "started"},
+			"index-1:1:replica:node-2": {IndexName: "index-1", ShardID: 1, NodeID: "node-2", State: "started"},
+			"index-1:2:replica:node-2": {IndexName: "index-1", ShardID: 2, NodeID: "node-2", State: "started"},
+		},
+	}
+
+	decisions, err := allocator.RebalanceShards(state)
+	if err != nil {
+		t.Fatalf("RebalanceShards failed: %v", err)
+	}
+
+	// node-3 is empty, but it may only take the replicas from node-2
+	for _, decision := range decisions {
+		if decision.FromNode == "node-1" {
+			t.Errorf("Shard %d moved from node-1 into the zone of its replica", decision.ShardID)
+		}
+	}
+	if len(decisions) == 0 {
+		t.Error("Expected replicas to move from node-2 to node-3")
+	}
+}
+
+func TestAllocateShardsAvoidsHighDiskUsage(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	allocator := NewAllocator(logger)
+
+	// node-1 is above the high watermark, node-2 only above the low one
+	state := &raft.ClusterState{
+		Version:     1,
+		ClusterUUID: "test-cluster",
+		Indices:     make(map[string]*raft.IndexMeta),
+		Nodes: map[string]*raft.NodeMeta{
+			"node-1": {NodeID: "node-1", NodeType: "data", Status: "healthy", DiskUsagePercent: 95},
+			"node-2": {NodeID: "node-2", NodeType: "data", Status: "healthy", DiskUsagePercent: 87},
+			"node-3": {NodeID: "node-3", NodeType: "data", Status: "healthy", DiskUsagePercent: 40},
+		},
+		ShardRouting: make(map[string]*raft.ShardRouting),
+		Settings:     make(map[string]string),
+	}
+
+	decisions, err := allocator.AllocateShards(state, "test-index", 4, 1)
+	if err != nil {
+		t.Fatalf("AllocateShards failed: %v", err)
+	}
+
+	for _, decision := range decisions {
+		if decision.NodeID == "node-1" {
+			t.Errorf("Shard %d (primary=%v) allocated above the high watermark", decision.ShardID, decision.IsPrimary)
+		}
+		if decision.NodeID == "node-2" && !decision.IsPrimary {
+			t.Errorf("Replica of shard %d allocated above the low watermark", decision.ShardID)
+		}
+	}
+
+	// Raising the watermarks makes node-1 eligible again
+	state.Settings[SettingDiskWatermarkLow] = "0.96"
+	state.Settings[SettingDiskWatermarkHigh] = "97%"
+	decisions, err = allocator.AllocateShards(state, "test-index-2", 3, 0)
+	if err != nil {
+		t.Fatalf("AllocateShards failed: %v", err)
+	}
+	nodes := make(map[string]bool)
+	for _, decision := range decisions {
+		nodes[decision.NodeID] = true
+	}
+	if !nodes["node-1"] {
+		t.Errorf("Expected node-1 to get a shard below the raised watermarks, got %v", decisions)
+	}
+
+	// No node below the high watermark
+	state.Settings[SettingDiskWatermarkHigh] = "30%"
+	if _, err := allocator.AllocateShards(state, "test-index-3", 1, 0); err == nil {
+		t.Error("Expected an error when every node is above the high watermark")
+	}
+}
+
+func TestRebalanceShardsDrainsHighDiskUsage(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	allocator := NewAllocator(logger)
+
+	// Counts are balanced, but node-1 is above the high watermark
+	state := &raft.ClusterState{
+		Version:     1,
+		ClusterUUID: "test-cluster",
+		Indices:     make(map[string]*raft.IndexMeta),
+		Nodes: map[string]*raft.NodeMeta{
+			"node-1": {NodeID: "node-1", NodeType: "data", Status: "healthy", DiskUsagePercent: 92},
+			"node-2": {NodeID: "node-2", NodeType: "data", Status: "healthy", DiskUsagePercent: 88},
+			"node-3": {NodeID: "node-3", NodeType: "data", Status: "healthy", DiskUsagePercent: 20},
+		},
+		ShardRouting: map[string]*raft.ShardRouting{
+			"index-1:0": {IndexName: "index-1", ShardID: 0, IsPrimary: true, NodeID: "node-1", State: "started"},
+			"index-1:1": {IndexName: "index-1", ShardID: 1, IsPrimary: true, NodeID: "node-1", State: "started"},
+			"index-1:2": {IndexName: "index-1", ShardID: 2, IsPrimary: true, NodeID: "node-2", State: "started"},
+			"index-1:3": {IndexName: "index-1", ShardID: 3, IsPrimary: true, NodeID: "node-2", State: "started"},
+			"index-1:4": {IndexName: "index-1", ShardID: 4, IsPrimary: true, NodeID: "node-3", State: "started"},
+			"index-1:5": {IndexName: "index-1", ShardID: 5, IsPrimary: true, NodeID: "node-3", State: "started"},
+		},
+	}
+
+	decisions, err := allocator.RebalanceShards(state)
+	if err != nil {
+		t.Fatalf("RebalanceShards failed: %v", err)
+	}
+
+	if len(decisions) != 1 {
+		t.Fatalf("Expected 1 relocation off node-1, got %v", decisions)
+	}
+	if decisions[0].FromNode != "node-1" || decisions[0].ToNode != "node-3" || decisions[0].Reason != "disk_watermark" {
+		t.Errorf("Expected a disk_watermark move from node-1 to node-3, got %+v", decisions[0])
+	}
+}
+
+func TestRebalanceShardsWeighsShardSizes(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	allocator := NewAllocator(logger)
+
+	// node-1 is above the high watermark and holds a large and a small
+	// shard; node-2 has room below the low watermark for the small one only
+	const gb = int64(1) << 30
+	state := &raft.ClusterState{
+		Version:     1,
+		ClusterUUID: "test-cluster",
+		Indices:     make(map[string]*raft.IndexMeta),
+		Nodes: map[string]*raft.NodeMeta{
+			"node-1": {NodeID: "node-1", NodeType: "data", Status: "healthy", DiskUsagePercent: 92, DiskTotalBytes: 100 * gb,
+				ShardSizes: map[string]int64{"index-1:0": 20 * gb, "index-1:1": 2 * gb}},
+			"node-2": {NodeID: "node-2", NodeType: "data", Status: "healthy", DiskUsagePercent: 75, DiskTotalBytes: 100 * gb,
+				ShardSizes: map[string]int64{"index-1:2": 1 * gb}},
+		},
+		ShardRouting: map[string]*raft.ShardRouting{
+			"index-1:0": {IndexName: "index-1", ShardID: 0, IsPrimary: true, NodeID: "node-1", State: "started"},
+			"index-1:1": {IndexName: "index-1", ShardID: 1, IsPrimary: true, NodeID: "node-1", State: "started"},
+			"index-1:2": {IndexName: "index-1", ShardID: 2, IsPrimary: true, NodeID: "node-2", State: "started"},
+		},
+	}
+
+	decisions, err := allocator.RebalanceShards(state)
+	if err != nil {
+		t.Fatalf("RebalanceShards failed: %v", err)
+	}
+	if len(decisions) != 1 {
+		t.Fatalf("Expected 1 relocation off node-1, got %v", decisions)
+	}
+	if decisions[0].ShardID != 1 || decisions[0].ToNode != "node-2" || decisions[0].Reason != "disk_watermark" {
+		t.Errorf("Expected the small shard to move to node-2, got %+v", decisions[0])
+	}
+
+	// With no room for either shard, nothing moves
+	state.Nodes["node-2"].DiskUsagePercent = 84
+	decisions, err = allocator.RebalanceShards(state)
+	if err != nil {
+		t.Fatalf("RebalanceShards failed: %v", err)
+	}
+	if len(decisions) != 0 {
+		t.Errorf("Expected no relocation, got %v", decisions)
+	}
+}
+
+// tieredState returns a cluster state with two hot and two warm data nodes
+func tieredState() *raft.ClusterState {
+	return &raft.ClusterState{
+		Version:     1,
+		ClusterUUID: "test-cluster",
+		Indices: map[string]*raft.IndexMeta{
+			"logs": {Name: "logs", NumShards: 2, NumReplicas: 1, Tier: "warm"},
+		},
+		Nodes: map[string]*raft.NodeMeta{
+			"hot-1":  {NodeID: "hot-1", NodeType: "data", Status: "healthy", StorageTier: "hot"},
+			"hot-2":  {NodeID: "hot-2", NodeType: "data", Status: "healthy"},
+			"warm-1": {NodeID: "warm-1", NodeType: "data", Status: "healthy", StorageTier: "warm"},
+			"warm-2": {NodeID: "warm-2", NodeType: "data", Status: "healthy", StorageTier: "warm"},
+		},
+		ShardRouting: make(map[string]*raft.ShardRouting),
+	}
+}
+
+func TestAllocateShardsRespectsTier(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	allocator := NewAllocator(logger)
+	state := tieredState()
+
+	decisions, err := allocator.AllocateShards(state, "logs", 2, 1)
+	if err != nil {
+		t.Fatalf("AllocateShards failed: %v", err)
+	}
+	if len(decisions) != 4 {
+		t.Fatalf("Expected 4 allocation decisions, got %v", decisions)
+	}
+	for _, decision := range decisions {
+		if state.Nodes[decision.NodeID].StorageTier != "warm" {
+			t.Errorf("Shard %d (primary=%v) of a warm index allocated to %s", decision.ShardID, decision.IsPrimary, decision.NodeID)
+		}
+	}
+
+	// An index without a tier may use every node, with a node without a
+	// tier counting as hot
+	decisions, err = allocator.AllocateShards(state, "products", 4, 0)
+	if err != nil {
+		t.Fatalf("AllocateShards failed: %v", err)
+	}
+	nodes := make(map[string]bool)
+	for _, decision := range decisions {
+		nodes[decision.NodeID] = true
+	}
+	if len(nodes) != 4 {
+		t.Errorf("Expected an untiered index spread over all 4 nodes, got %v", decisions)
+	}
+	if decision := decideTier(state, "logs", state.Nodes["hot-2"]); decision.Allowed {
+		t.Errorf("Expected a node without a tier to be hot, got %+v", decision)
+	}
+
+	// With no node on the tier, the warm index's shards stay unallocated
+	state.Indices["archive"] = &raft.IndexMeta{Name: "archive", NumShards: 1, Tier: "cold"}
+	if _, err := allocator.AllocateShards(state, "archive", 1, 0); err == nil {
+		t.Error("Expected an error allocating an index with no node on its tier")
+	}
+}
+
+func TestRebalanceShardsMigratesTier(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	allocator := NewAllocator(logger)
+
+	// logs has just been marked warm, but its shards are on the hot nodes
+	state := tieredState()
+	for _, shard := range []*raft.ShardRouting{
+		{IndexName: "logs", ShardID: 0, IsPrimary: true, NodeID: "hot-1", State: "started"},
+		{IndexName: "logs", ShardID: 0, NodeID: "hot-2", State: "started"},
+		{IndexName: "logs", ShardID: 1, IsPrimary: true, NodeID: "hot-2", State: "started"},
+		{IndexName: "logs", ShardID: 1, NodeID: "hot-1", State: "started"},
+		{IndexName: "products", ShardID: 0, IsPrimary: true, NodeID: "warm-1", State: "started"},
+	} {
+		state.ShardRouting[raft.ShardRoutingKey(shard)] = shard
+	}
+	state.Indices["products"] = &raft.IndexMeta{Name: "products", NumShards: 1}
+
+	decisions, err := allocator.RebalanceShards(state)
+	if err != nil {
+		t.Fatalf("RebalanceShards failed: %v", err)
+	}
+
+	// One copy of each shard moves per round, and only to the warm nodes
+	if len(decisions) != 2 {
+		t.Fatalf("Expected 2 tier relocations, got %+v", decisions)
+	}
+	for _, decision := range decisions {
+		if decision.IndexName != "logs" || decision.Reason != "tier" {
+			t.Errorf("Expected a tier move of a logs shard, got %+v", decision)
+		}
+		if state.Nodes[decision.ToNode].StorageTier != "warm" {
+			t.Errorf("Expected shard %d to move to a warm node, got %+v", decision.ShardID, decision)
+		}
+	}
+
+	// A copy outside its tier can't remain where it is
+	explanation, err := allocator.ExplainShard(state, "logs", 0, true)
+	if err != nil {
+		t.Fatalf("ExplainShard failed: %v", err)
+	}
+	if explanation.CanRemain != "no" {
+		t.Errorf("Expected a warm shard on a hot node to be unable to remain, got %q", explanation.CanRemain)
+	}
+}
+
+func TestExplainShardUnassignedReplica(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	allocator := NewAllocator(logger)
+
+	// One data node holds the primary, so the replica is unassigned
+	state := &raft.ClusterState{
+		Version:     1,
+		ClusterUUID: "test-cluster",
+		Indices: map[string]*raft.IndexMeta{
+			"test-index": {Name: "test-index", NumShards: 1, NumReplicas: 1},
+		},
+		Nodes: map[string]*raft.NodeMeta{
+			"node-1": {NodeID: "node-1", NodeType: "data", Status: "healthy"},
+		},
+		ShardRouting: map[string]*raft.ShardRouting{
+			"test-index:0":          {IndexName: "test-index", ShardID: 0, IsPrimary: true, NodeID: "node-1", State: "started"},
+			"test-index:0:replica:": {IndexName: "test-index", ShardID: 0, State: "unassigned", UnassignedReason: "INDEX_CREATED"},
+		},
+		Settings: make(map[string]string),
+	}
+
+	// With no shard given, the first unassigned shard is explained
+	explanation, err := allocator.ExplainShard(state, "", 0, false)
+	if err != nil {
+		t.Fatalf("ExplainShard failed: %v", err)
+	}
+	if explanation.IndexName != "test-index" || explanation.IsPrimary || explanation.CurrentState != "unassigned" {
+		t.Errorf("Expected the unassigned replica, got %+v", explanation)
+	}
+	if explanation.CanAllocate != "no" || explanation.UnassignedReason != "INDEX_CREATED" {
+		t.Errorf("Expected can_allocate no for INDEX_CREATED, got %q for %q", explanation.CanAllocate, explanation.UnassignedReason)
+	}
+
+	if len(explanation.NodeDecisions) != 1 || explanation.NodeDecisions[0].NodeID != "node-1" {
+		t.Fatalf("Expected a decision for node-1, got %+v", explanation.NodeDecisions)
+	}
+	blocking := make([]string, 0)
+	for _, decision := range explanation.NodeDecisions[0].Decisions {
+		if !decision.Allowed {
+			blocking = append(blocking, decision.Decider)
+		}
+	}
+	if len(blocking) != 1 || blocking[0] != "same_shard" {
+		t.Errorf("Expected same_shard to block node-1, got %v", blocking)
+	}
+
+	// Disabling allocation blocks a second node too
+	state.Nodes["node-2"] = &raft.NodeMeta{NodeID: "node-2", NodeType: "data", Status: "healthy"}
+	state.Settings[SettingAllocationEnable] = "primaries"
+	explanation, err = allocator.ExplainShard(state, "test-index", 0, false)
+	if err != nil {
+		t.Fatalf("ExplainShard failed: %v", err)
+	}
+	if explanation.CanAllocate != "no" {
+		t.Errorf("Expected can_allocate no with replica allocation disabled, got %q", explanation.CanAllocate)
+	}
+	for _, node := range explanation.NodeDecisions {
+		if node.NodeID != "node-2" {
+			continue
+		}
+		if node.Allowed || node.Decisions[0].Decider != "enable" || node.Decisions[0].Allowed {
+			t.Errorf("Expected the enable decider to block node-2, got %+v", node)
+		}
+	}
+
+	// The assigned primary can remain on its node
+	explanation, err = allocator.ExplainShard(state, "test-index", 0, true)
+	if err != nil {
+		t.Fatalf("ExplainShard failed: %v", err)
+	}
+	if explanation.CurrentNode != "node-1" || explanation.CanRemain != "yes" {
+		t.Errorf("Expected the primary to remain on node-1, got %+v", explanation)
+	}
+
+	if _, err := allocator.ExplainShard(state, "missing", 0, true); err == nil {
+		t.Error("Expected an error for a missing index")
+	}
+}
+
+func TestAdjustReplicas(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	allocator := NewAllocator(logger)
+
+	state := &raft.ClusterState{
+		Version:     1,
+		ClusterUUID: "test-cluster",
+		Indices: map[string]*raft.IndexMeta{
+			"test-index": {Name: "test-index", NumShards: 2, NumReplicas: 0},
+		},
+		Nodes: map[string]*raft.NodeMeta{
+			"node-1": {NodeID: "node-1", NodeType: "data", Status: "healthy"},
+			"node-2": {NodeID: "node-2", NodeType: "data", Status: "healthy"},
+		},
+		ShardRouting: map[string]*raft.ShardRouting{
+			"test-index:0": {IndexName: "test-index", ShardID: 0, IsPrimary: true, NodeID: "node-1", State: "started"},
+			"test-index:1": {IndexName: "test-index", ShardID: 1, IsPrimary: true, NodeID: "node-2", State: "started"},
+		},
+	}
+
+	// Each shard gains a replica on the node without its primary, and a
+	// second replica has nowhere to go
+	decisions, removals, err := allocator.AdjustReplicas(state, "test-index", 2)
+	if err != nil {
+		t.Fatalf("AdjustReplicas failed: %v", err)
+	}
+	if len(removals) != 0 {
+		t.Errorf("Expected no removals, got %d", len(removals))
+	}
+	if len(decisions) != 2 {
+		t.Fatalf("Expected 2 replica allocations, got %d", len(decisions))
+	}
+	for _, decision := range decisions {
+		if decision.IsPrimary {
+			t.Errorf("Shard %d: expected a replica allocation", decision.ShardID)
+		}
+		primary := state.ShardRouting[fmt.Sprintf("test-index:%d", decision.ShardID)]
+		if decision.NodeID == primary.NodeID {
+			t.Errorf("Shard %d: replica placed on primary node %s", decision.ShardID, primary.NodeID)
+		}
+	}
+
+	// Dropping to zero replicas removes them again
+	for _, decision := range decisions {
+		replica := &raft.ShardRouting{IndexName: "test-index", ShardID: decision.ShardID, NodeID: decision.NodeID, State: "started"}
+		state.ShardRouting[raft.ShardRoutingKey(replica)] = replica
+	}
+	decisions, removals, err = allocator.AdjustReplicas(state, "test-index", 0)
+	if err != nil {
+		t.Fatalf("AdjustReplicas failed: %v", err)
+	}
+	if len(decisions) != 0 || len(removals) != 2 {
+		t.Fatalf("Expected 2 removals and no allocations, got %d removals and %d allocations", len(removals), len(decisions))
+	}
+	for _, shard := range removals {
+		if shard.IsPrimary {
+			t.Errorf("Shard %d: primary removed", shard.ShardID)
+		}
+	}
+
+	if _, _, err := allocator.AdjustReplicas(state, "missing", 1); !errors.Is(err, ErrIndexNotFound) {
+		t.Errorf("Expected ErrIndexNotFound, got %v", err)
+	}
+}
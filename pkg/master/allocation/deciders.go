@@ -0,0 +1,197 @@
+package allocation
+
+import (
+	"fmt"
+
+	"github.com/quidditch/quidditch/pkg/master/raft"
+)
+
+// Decision is a decider's verdict on placing a copy of a shard on a node
+type Decision struct {
+	Decider     string
+	Allowed     bool
+	Explanation string
+}
+
+// decide runs every allocation decider for placing a copy of a shard of an
+// index on a node, given the nodes holding its other copies. newPrimary is
+// set when the copy is the primary of a new shard, which only the high disk
+// watermark keeps off a node.
+func decide(state *raft.ClusterState, indexName string, node *raft.NodeMeta, copies []*raft.NodeMeta, primary, newPrimary bool) []Decision {
+	return []Decision{
+		decideEnable(state, primary, newPrimary),
+		decideSameShard(node, copies),
+		decideAwareness(node, copies),
+		decideTier(state, indexName, node),
+		decideDiskThreshold(state, node, newPrimary),
+	}
+}
+
+// canAllocate reports whether every decider allows placing a copy of a
+// shard of an index on a node
+func canAllocate(state *raft.ClusterState, indexName string, node *raft.NodeMeta, copies []*raft.NodeMeta, primary, newPrimary bool) bool {
+	for _, decision := range decide(state, indexName, node, copies, primary, newPrimary) {
+		if !decision.Allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// decideEnable applies the cluster.routing.allocation.enable setting
+func decideEnable(state *raft.ClusterState, primary, newPrimary bool) Decision {
+	enable := allocationEnable(state)
+
+	allowed := false
+	switch enable {
+	case "all":
+		allowed = true
+	case "primaries":
+		allowed = primary
+	case "new_primaries":
+		allowed = newPrimary
+	}
+
+	if !allowed {
+		return Decision{
+			Decider:     "enable",
+			Explanation: fmt.Sprintf("this copy of the shard may not be allocated due to cluster setting [%s=%s]", SettingAllocationEnable, enable),
+		}
+	}
+	return Decision{
+		Decider:     "enable",
+		Allowed:     true,
+		Explanation: fmt.Sprintf("allocation is allowed by cluster setting [%s=%s]", SettingAllocationEnable, enable),
+	}
+}
+
+// decideSameShard keeps two copies of a shard off the same node
+func decideSameShard(node *raft.NodeMeta, copies []*raft.NodeMeta) Decision {
+	for _, other := range copies {
+		if other.NodeID == node.NodeID {
+			return Decision{
+				Decider:     "same_shard",
+				Explanation: "a copy of this shard is already allocated to this node",
+			}
+		}
+	}
+	return Decision{
+		Decider:     "same_shard",
+		Allowed:     true,
+		Explanation: "this node does not hold a copy of this shard",
+	}
+}
+
+// decideAwareness keeps two copies of a shard out of the same zone
+func decideAwareness(node *raft.NodeMeta, copies []*raft.NodeMeta) Decision {
+	if node.Zone == "" {
+		return Decision{
+			Decider:     "awareness",
+			Allowed:     true,
+			Explanation: "this node has no zone attribute",
+		}
+	}
+	for _, other := range copies {
+		if other.NodeID != node.NodeID && other.Zone == node.Zone {
+			return Decision{
+				Decider:     "awareness",
+				Explanation: fmt.Sprintf("a copy of this shard is already allocated to node [%s] in zone [%s]", other.NodeID, node.Zone),
+			}
+		}
+	}
+	return Decision{
+		Decider:     "awareness",
+		Allowed:     true,
+		Explanation: fmt.Sprintf("no other copy of this shard is in zone [%s]", node.Zone),
+	}
+}
+
+// decideTier keeps the shards of an index with a storage tier on data nodes
+// of that tier. Nodes that do not set a tier are hot.
+func decideTier(state *raft.ClusterState, indexName string, node *raft.NodeMeta) Decision {
+	tier := ""
+	if index, exists := state.Indices[indexName]; exists {
+		tier = index.Tier
+	}
+	if tier == "" {
+		return Decision{
+			Decider:     "tier",
+			Allowed:     true,
+			Explanation: "the index has no storage tier",
+		}
+	}
+
+	if nodeTier(node) != tier {
+		return Decision{
+			Decider:     "tier",
+			Explanation: fmt.Sprintf("the index is on the [%s] tier and the node is on the [%s] tier", tier, nodeTier(node)),
+		}
+	}
+	return Decision{
+		Decider:     "tier",
+		Allowed:     true,
+		Explanation: fmt.Sprintf("the index and the node are on the [%s] tier", tier),
+	}
+}
+
+// nodeTier returns the storage tier of a data node
+func nodeTier(node *raft.NodeMeta) string {
+	if node.StorageTier == "" {
+		return "hot"
+	}
+	return node.StorageTier
+}
+
+// decideDiskThreshold keeps shards off nodes above the disk watermarks: new
+// primaries off nodes above the high watermark and every other copy off
+// nodes above the low watermark
+func decideDiskThreshold(state *raft.ClusterState, node *raft.NodeMeta, newPrimary bool) Decision {
+	low, high := diskWatermarks(state)
+
+	key, watermark := SettingDiskWatermarkLow, low
+	if newPrimary {
+		key, watermark = SettingDiskWatermarkHigh, high
+	}
+
+	if node.DiskUsagePercent > watermark {
+		return Decision{
+			Decider: "disk_threshold",
+			Explanation: fmt.Sprintf("the node is above the watermark cluster setting [%s=%.1f%%], having used [%.1f%%] of its disk",
+				key, watermark, node.DiskUsagePercent),
+		}
+	}
+	return Decision{
+		Decider: "disk_threshold",
+		Allowed: true,
+		Explanation: fmt.Sprintf("the node is below the watermark cluster setting [%s=%.1f%%], having used [%.1f%%] of its disk",
+			key, watermark, node.DiskUsagePercent),
+	}
+}
+
+// aboveHighWatermark reports whether a node uses more disk than the high
+// watermark allows, so its shards should move elsewhere
+func aboveHighWatermark(state *raft.ClusterState, node *raft.NodeMeta) bool {
+	_, high := diskWatermarks(state)
+	return node.DiskUsagePercent > high
+}
+
+// fitsBelowLowWatermark reports whether a node stays below the low disk
+// watermark once it takes a shard of sizeBytes. A node that hasn't reported
+// its disk size, or a shard of unknown size, is assumed to fit.
+func fitsBelowLowWatermark(state *raft.ClusterState, node *raft.NodeMeta, sizeBytes int64) bool {
+	if node.DiskTotalBytes <= 0 || sizeBytes <= 0 {
+		return true
+	}
+	low, _ := diskWatermarks(state)
+	return node.DiskUsagePercent+float64(sizeBytes)/float64(node.DiskTotalBytes)*100 <= low
+}
+
+// shardSize returns the on-disk size of a shard copy as its node last
+// reported it, or 0 if unknown
+func shardSize(state *raft.ClusterState, shard *raft.ShardRouting) int64 {
+	node, exists := state.Nodes[shard.NodeID]
+	if !exists {
+		return 0
+	}
+	return node.ShardSizes[fmt.Sprintf("%s:%d", shard.IndexName, shard.ShardID)]
+}
@@ -0,0 +1,188 @@
+package allocation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/quidditch/quidditch/pkg/master/raft"
+)
+
+// ErrNoUnassignedShards is returned by ExplainShard when asked to explain
+// the first unassigned shard and every shard is assigned
+var ErrNoUnassignedShards = errors.New("unable to find any unassigned shards to explain")
+
+// ErrIndexNotFound is returned by ExplainShard for an unknown index
+var ErrIndexNotFound = errors.New("no such index")
+
+// Explanation describes where a copy of a shard is allocated and, for an
+// unassigned copy, which deciders keep it off each data node
+type Explanation struct {
+	IndexName string
+	ShardID   int32
+	IsPrimary bool
+
+	// CurrentState is the copy's routing state, and CurrentNode the node
+	// holding it when it is assigned
+	CurrentState string
+	CurrentNode  string
+
+	// UnassignedReason and UnassignedAt record why and when an unassigned
+	// copy was left unassigned
+	UnassignedReason string
+	UnassignedAt     int64
+
+	// CanAllocate is "yes" when some node may take an unassigned copy and
+	// "no" otherwise; CanRemain is "yes" or "no" for an assigned copy
+	CanAllocate         string
+	AllocateExplanation string
+	CanRemain           string
+
+	// NodeDecisions holds the deciders' verdicts for each data node
+	NodeDecisions []NodeDecision
+}
+
+// NodeDecision is the outcome of running the deciders for one node
+type NodeDecision struct {
+	NodeID    string
+	Zone      string
+	Allowed   bool
+	Decisions []Decision
+}
+
+// ExplainShard explains the allocation of a copy of a shard. With an empty
+// index name it explains the first unassigned copy in the cluster.
+func (a *Allocator) ExplainShard(state *raft.ClusterState, indexName string, shardID int32, isPrimary bool) (*Explanation, error) {
+	var shard *raft.ShardRouting
+	if indexName == "" {
+		shard = firstUnassignedShard(state)
+		if shard == nil {
+			return nil, ErrNoUnassignedShards
+		}
+		indexName, shardID, isPrimary = shard.IndexName, shard.ShardID, shard.IsPrimary
+	} else {
+		index, exists := state.Indices[indexName]
+		if !exists {
+			return nil, fmt.Errorf("%w [%s]", ErrIndexNotFound, indexName)
+		}
+		if shardID < 0 || shardID >= index.NumShards {
+			return nil, fmt.Errorf("shard [%d] does not exist in index [%s]", shardID, indexName)
+		}
+		if !isPrimary && index.NumReplicas == 0 {
+			return nil, fmt.Errorf("index [%s] has no replicas to explain", indexName)
+		}
+		shard = findShardCopy(state, indexName, shardID, isPrimary)
+	}
+
+	explanation := &Explanation{
+		IndexName:    indexName,
+		ShardID:      shardID,
+		IsPrimary:    isPrimary,
+		CurrentState: "unassigned",
+	}
+
+	// A primary that was never allocated is a new primary
+	newPrimary := isPrimary && shard == nil
+	if shard == nil {
+		explanation.UnassignedReason = "INDEX_CREATED"
+	} else {
+		explanation.UnassignedReason = shard.UnassignedReason
+		explanation.UnassignedAt = shard.UnassignedAt
+		if shard.State != "unassigned" && shard.NodeID != "" {
+			explanation.CurrentState = shard.State
+			if explanation.CurrentState == "" {
+				explanation.CurrentState = "started"
+			}
+			explanation.CurrentNode = shard.NodeID
+		}
+	}
+
+	// Run the deciders against every data node, in node ID order
+	target := shard
+	if target == nil {
+		target = &raft.ShardRouting{IndexName: indexName, ShardID: shardID, IsPrimary: isPrimary}
+	}
+	copies := otherCopies(state, target)
+
+	nodes := a.getHealthyDataNodes(state)
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].NodeID < nodes[j].NodeID })
+
+	allowedNodes := 0
+	for _, node := range nodes {
+		if node.NodeID == explanation.CurrentNode {
+			continue
+		}
+		decisions := decide(state, indexName, node, copies, isPrimary, newPrimary)
+		nodeDecision := NodeDecision{NodeID: node.NodeID, Zone: node.Zone, Allowed: true, Decisions: decisions}
+		for _, decision := range decisions {
+			if !decision.Allowed {
+				nodeDecision.Allowed = false
+			}
+		}
+		if nodeDecision.Allowed {
+			allowedNodes++
+		}
+		explanation.NodeDecisions = append(explanation.NodeDecisions, nodeDecision)
+	}
+
+	if explanation.CurrentNode != "" {
+		explanation.CanRemain = "yes"
+		if node, exists := state.Nodes[explanation.CurrentNode]; exists &&
+			(aboveHighWatermark(state, node) || !decideTier(state, indexName, node).Allowed) {
+			explanation.CanRemain = "no"
+		}
+		return explanation, nil
+	}
+
+	switch {
+	case len(nodes) == 0:
+		explanation.CanAllocate = "no"
+		explanation.AllocateExplanation = "cannot allocate because there are no healthy data nodes"
+	case allowedNodes == 0:
+		explanation.CanAllocate = "no"
+		explanation.AllocateExplanation = "cannot allocate because allocation is not permitted to any of the nodes"
+	default:
+		explanation.CanAllocate = "yes"
+		explanation.AllocateExplanation = fmt.Sprintf("can allocate the shard to %d of %d nodes", allowedNodes, len(nodes))
+	}
+
+	return explanation, nil
+}
+
+// firstUnassignedShard returns the unassigned shard copy that sorts first
+// by routing key
+func firstUnassignedShard(state *raft.ClusterState) *raft.ShardRouting {
+	keys := make([]string, 0, len(state.ShardRouting))
+	for key, shard := range state.ShardRouting {
+		if shard.State == "unassigned" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+	return state.ShardRouting[keys[0]]
+}
+
+// findShardCopy returns the routing of a copy of a shard, preferring an
+// unassigned replica when asked for a replica, or nil if there is none
+func findShardCopy(state *raft.ClusterState, indexName string, shardID int32, isPrimary bool) *raft.ShardRouting {
+	keys := make([]string, 0)
+	for key, shard := range state.ShardRouting {
+		if shard.IndexName == indexName && shard.ShardID == shardID && shard.IsPrimary == isPrimary {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if state.ShardRouting[key].State == "unassigned" {
+			return state.ShardRouting[key]
+		}
+	}
+	if len(keys) > 0 {
+		return state.ShardRouting[keys[0]]
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package allocation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/quidditch/quidditch/pkg/master/raft"
+)
+
+// Cluster settings that control shard allocation
+const (
+	// SettingAllocationEnable limits which shards may be relocated: all,
+	// primaries, new_primaries or none
+	SettingAllocationEnable = "cluster.routing.allocation.enable"
+
+	// SettingConcurrentRebalance caps the relocations in flight at once
+	SettingConcurrentRebalance = "cluster.routing.allocation.cluster_concurrent_rebalance"
+
+	// SettingDiskWatermarkLow is the disk usage above which a node gets no
+	// more replicas or relocated shards
+	SettingDiskWatermarkLow = "cluster.routing.allocation.disk.watermark.low"
+
+	// SettingDiskWatermarkHigh is the disk usage above which a node gets no
+	// new shards at all and its shards are relocated to other nodes
+	SettingDiskWatermarkHigh = "cluster.routing.allocation.disk.watermark.high"
+)
+
+// Default allocation settings
+const (
+	DefaultConcurrentRebalance = 2
+	DefaultDiskWatermarkLow    = "85%"
+	DefaultDiskWatermarkHigh   = "90%"
+)
+
+// ValidateSetting checks the value of a cluster setting, rejecting settings
+// that are not recognized
+func ValidateSetting(key, value string) error {
+	switch key {
+	case SettingAllocationEnable:
+		switch value {
+		case "all", "primaries", "new_primaries", "none":
+			return nil
+		}
+		return fmt.Errorf("illegal value [%s] for [%s], expected one of [all, primaries, new_primaries, none]", value, key)
+	case SettingConcurrentRebalance:
+		if n, err := strconv.Atoi(value); err != nil || n < -1 {
+			return fmt.Errorf("illegal value [%s] for [%s], expected an integer >= -1", value, key)
+		}
+		return nil
+	case SettingDiskWatermarkLow, SettingDiskWatermarkHigh:
+		if _, err := parseWatermark(value); err != nil {
+			return fmt.Errorf("illegal value [%s] for [%s], %v", value, key, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("persistent setting [%s], not recognized", key)
+}
+
+// allocationEnable returns the cluster.routing.allocation.enable setting
+func allocationEnable(state *raft.ClusterState) string {
+	if value, ok := state.Settings[SettingAllocationEnable]; ok {
+		return value
+	}
+	return "all"
+}
+
+// concurrentRebalance returns the relocation limit; -1 means unlimited
+func concurrentRebalance(state *raft.ClusterState) int {
+	if value, ok := state.Settings[SettingConcurrentRebalance]; ok {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return DefaultConcurrentRebalance
+}
+
+// diskWatermarks returns the low and high disk watermarks as percentages
+func diskWatermarks(state *raft.ClusterState) (float64, float64) {
+	watermark := func(key, defaultValue string) float64 {
+		value, ok := state.Settings[key]
+		if !ok {
+			value = defaultValue
+		}
+		percent, err := parseWatermark(value)
+		if err != nil {
+			percent, _ = parseWatermark(defaultValue)
+		}
+		return percent
+	}
+	return watermark(SettingDiskWatermarkLow, DefaultDiskWatermarkLow),
+		watermark(SettingDiskWatermarkHigh, DefaultDiskWatermarkHigh)
+}
+
+// parseWatermark parses a disk watermark given as a percentage, such as
+// "85%", or a ratio, such as "0.85", into a percentage
+func parseWatermark(value string) (float64, error) {
+	if percent, ok := strings.CutSuffix(value, "%"); ok {
+		n, err := strconv.ParseFloat(percent, 64)
+		if err != nil || n < 0 || n > 100 {
+			return 0, fmt.Errorf("expected a percentage between 0%% and 100%%")
+		}
+		return n, nil
+	}
+
+	ratio, err := strconv.ParseFloat(value, 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		return 0, fmt.Errorf("expected a percentage such as 85%% or a ratio between 0 and 1")
+	}
+	return ratio * 100, nil
+}
@@ -1,15 +1,21 @@
 package master
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
 	"time"
 
 	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/master/allocation"
 	"github.com/quidditch/quidditch/pkg/master/raft"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -86,14 +92,69 @@ func (s *MasterService) CreateIndex(ctx context.Context, req *pb.CreateIndexRequ
 	if req.Settings == nil {
 		return nil, status.Error(codes.InvalidArgument, "index settings are required")
 	}
+	if err := validateIndexStoreType(req.Settings.StoreType); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := validateIndexBufferSize(req.Settings.BufferSizeMb); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := validateIndexDocumentLimit(SettingIndexMaxFieldValueLength, req.Settings.MaxFieldValueLength); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := validateIndexDocumentLimit(SettingIndexMaxDocumentSize, req.Settings.MaxDocumentSizeBytes); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := validateIndexMaxResultWindow(req.Settings.MaxResultWindow); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := validateIndexSlowlogThreshold(req.Settings.SlowlogThreshold); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 
 	// Check if not leader
 	if !s.node.IsLeader() {
 		return nil, status.Errorf(codes.FailedPrecondition, "not the leader, redirect to %s", s.node.Leader())
 	}
 
-	// Use MasterNode.CreateIndex which includes shard allocation
-	if err := s.node.CreateIndex(ctx, req.IndexName, req.Settings.NumberOfShards, req.Settings.NumberOfReplicas); err != nil {
+	settings := make(map[string]string)
+	if req.Settings.StoreType != "" {
+		settings[SettingIndexStoreType] = req.Settings.StoreType
+	}
+	if req.Settings.BufferSizeMb != 0 {
+		settings[SettingIndexBufferSize] = strconv.FormatFloat(req.Settings.BufferSizeMb, 'f', -1, 64)
+	}
+	if req.Settings.MaxFieldValueLength != 0 {
+		settings[SettingIndexMaxFieldValueLength] = strconv.FormatInt(req.Settings.MaxFieldValueLength, 10)
+	}
+	if req.Settings.MaxDocumentSizeBytes != 0 {
+		settings[SettingIndexMaxDocumentSize] = strconv.FormatInt(req.Settings.MaxDocumentSizeBytes, 10)
+	}
+	if req.Settings.MaxResultWindow != 0 {
+		settings[SettingIndexMaxResultWindow] = strconv.Itoa(int(req.Settings.MaxResultWindow))
+	}
+	if req.Settings.SlowlogThreshold != "" {
+		settings[SettingIndexSlowlogThreshold] = req.Settings.SlowlogThreshold
+	}
+	if req.Settings.Analysis != "" {
+		settings[SettingIndexAnalysis] = req.Settings.Analysis
+	}
+	if req.Settings.NumericFields != "" {
+		settings[SettingIndexNumericFields] = req.Settings.NumericFields
+	}
+	if req.Settings.DateFields != "" {
+		settings[SettingIndexDateFields] = req.Settings.DateFields
+	}
+	if req.Settings.GeoPointFields != "" {
+		settings[SettingIndexGeoPointFields] = req.Settings.GeoPointFields
+	}
+
+	mappings, err := marshalMappings(req.Mappings)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid mappings: %v", err)
+	}
+
+	// Use MasterNode.CreateIndexWithSettings which includes shard allocation
+	if err := s.node.CreateIndexWithSettings(ctx, req.IndexName, req.Settings.NumberOfShards, req.Settings.NumberOfReplicas, settings, mappings); err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create index: %v", err)
 	}
 
@@ -157,8 +218,69 @@ func (s *MasterService) UpdateIndexSettings(ctx context.Context, req *pb.UpdateI
 		return nil, status.Errorf(codes.FailedPrecondition, "not the leader, redirect to %s", s.node.Leader())
 	}
 
-	// TODO: Implement update index settings
-	return nil, status.Error(codes.Unimplemented, "UpdateIndexSettings not yet implemented")
+	if req.Settings == nil {
+		return nil, status.Error(codes.InvalidArgument, "index settings are required")
+	}
+
+	state, err := s.node.GetClusterState(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get cluster state: %v", err)
+	}
+	index, exists := state.Indices[req.IndexName]
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "index not found: %s", req.IndexName)
+	}
+
+	// The shard count is fixed at creation; _split and _shrink copy an
+	// index into a new one with a different count
+	if req.Settings.NumberOfShards != 0 && req.Settings.NumberOfShards != index.NumShards {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"can't change the number of shards for an index [%s] from [%d] to [%d]",
+			req.IndexName, index.NumShards, req.Settings.NumberOfShards)
+	}
+	if req.Settings.NumberOfReplicas < 0 {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"number_of_replicas must be non-negative but was [%d]", req.Settings.NumberOfReplicas)
+	}
+	// Settings without tiering leave the index's storage tier unchanged
+	if req.Settings.Tiering != nil {
+		if err := validateStorageTier(req.Settings.Tiering.DefaultTier); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if err := validateIndexMaxResultWindow(req.Settings.MaxResultWindow); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := validateIndexSlowlogThreshold(req.Settings.SlowlogThreshold); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.node.UpdateIndexReplicas(ctx, req.IndexName, req.Settings.NumberOfReplicas); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update index settings: %v", err)
+	}
+	if req.Settings.Tiering != nil {
+		if err := s.node.UpdateIndexTier(ctx, req.IndexName, req.Settings.Tiering.DefaultTier); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to update index settings: %v", err)
+		}
+	}
+	values := map[string]string{
+		SettingIndexBlocksWrite:      "",
+		SettingIndexMaxResultWindow:  "",
+		SettingIndexSlowlogThreshold: req.Settings.SlowlogThreshold,
+	}
+	if req.Settings.BlocksWrite {
+		values[SettingIndexBlocksWrite] = "true"
+	}
+	if req.Settings.MaxResultWindow != 0 {
+		values[SettingIndexMaxResultWindow] = strconv.Itoa(int(req.Settings.MaxResultWindow))
+	}
+	if err := s.node.UpdateIndexSettings(ctx, req.IndexName, values); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update index settings: %v", err)
+	}
+
+	return &pb.UpdateIndexSettingsResponse{
+		Acknowledged: true,
+	}, nil
 }
 
 // GetIndexMetadata returns metadata for an index
@@ -188,10 +310,8 @@ func (s *MasterService) GetIndexMetadata(ctx context.Context, req *pb.GetIndexMe
 		IndexName: indexMeta.Name,
 		IndexUuid: indexMeta.UUID,
 		Version:   indexMeta.Version,
-		Settings: &pb.IndexSettings{
-			NumberOfShards:   indexMeta.NumShards,
-			NumberOfReplicas: indexMeta.NumReplicas,
-		},
+		Settings:  indexSettingsToProto(indexMeta),
+		Mappings:  indexMappingsToProto(indexMeta),
 		State:     s.convertIndexStateToProto(indexMeta.State),
 		CreatedAt: timestamppb.New(time.Unix(indexMeta.CreatedAt, 0)),
 	}
@@ -201,6 +321,47 @@ func (s *MasterService) GetIndexMetadata(ctx context.Context, req *pb.GetIndexMe
 	}, nil
 }
 
+// PutMapping replaces an index's field mappings with a mapping the caller
+// merged from the index's metadata at req.Version, along with the analysis
+// and typed field settings derived from it
+func (s *MasterService) PutMapping(ctx context.Context, req *pb.PutMappingRequest) (*pb.PutMappingResponse, error) {
+	s.logger.Info("PutMapping request", zap.String("index", req.IndexName))
+
+	// Check if not leader
+	if !s.node.IsLeader() {
+		return nil, status.Errorf(codes.FailedPrecondition, "not the leader, redirect to %s", s.node.Leader())
+	}
+
+	if req.IndexName == "" {
+		return nil, status.Error(codes.InvalidArgument, "index name is required")
+	}
+	mappings, err := marshalMappings(req.Mappings)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid mappings: %v", err)
+	}
+
+	version, err := s.node.PutIndexMapping(ctx, req.IndexName, mappings, map[string]string{
+		SettingIndexAnalysis:       req.Analysis,
+		SettingIndexNumericFields:  req.NumericFields,
+		SettingIndexDateFields:     req.DateFields,
+		SettingIndexGeoPointFields: req.GeoPointFields,
+	}, req.Version)
+	if errors.Is(err, allocation.ErrIndexNotFound) {
+		return nil, status.Errorf(codes.NotFound, "index not found: %s", req.IndexName)
+	}
+	if errors.Is(err, ErrMappingVersionConflict) {
+		return nil, status.Error(codes.Aborted, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to put mapping: %v", err)
+	}
+
+	return &pb.PutMappingResponse{
+		Acknowledged: true,
+		Version:      version,
+	}, nil
+}
+
 // AllocateShard allocates a shard to a node
 func (s *MasterService) AllocateShard(ctx context.Context, req *pb.AllocateShardRequest) (*pb.AllocateShardResponse, error) {
 	s.logger.Info("AllocateShard request",
@@ -234,17 +395,25 @@ func (s *MasterService) RebalanceShards(ctx context.Context, req *pb.RebalanceSh
 		return nil, status.Errorf(codes.FailedPrecondition, "not the leader, redirect to %s", s.node.Leader())
 	}
 
-	// Get current state
-	state, err := s.node.GetClusterState(ctx)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get cluster state: %v", err)
+	if len(req.IndexNames) > 0 {
+		return nil, status.Error(codes.InvalidArgument, "rebalancing specific indices is not supported")
 	}
 
-	// TODO: Implement rebalancing logic
-	_ = state
+	decisions, err := s.node.RebalanceShards(ctx, req.DryRun)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to rebalance shards: %v", err)
+	}
 
 	// Convert to proto
-	relocations := make([]*pb.ShardRelocation, 0)
+	relocations := make([]*pb.ShardRelocation, 0, len(decisions))
+	for _, decision := range decisions {
+		relocations = append(relocations, &pb.ShardRelocation{
+			IndexName: decision.IndexName,
+			ShardId:   decision.ShardID,
+			FromNode:  decision.FromNode,
+			ToNode:    decision.ToNode,
+		})
+	}
 
 	return &pb.RebalanceShardsResponse{
 		Relocations: relocations,
@@ -276,6 +445,10 @@ func (s *MasterService) RegisterNode(ctx context.Context, req *pb.RegisterNodeRe
 		JoinedAt: time.Now().Unix(),
 		LastSeen: time.Now().Unix(),
 	}
+	if req.Attributes != nil {
+		node.Zone = req.Attributes.Labels["zone"]
+		node.StorageTier = req.Attributes.StorageTier
+	}
 
 	payload, err := json.Marshal(node)
 	if err != nil {
@@ -375,21 +548,716 @@ func (s *MasterService) NodeHeartbeat(ctx context.Context, req *pb.NodeHeartbeat
 	}, nil
 }
 
-// WatchClusterState streams cluster state changes
+// PutStoredScript stores a script, such as a mustache search template,
+// replacing any script with the same id
+func (s *MasterService) PutStoredScript(ctx context.Context, req *pb.PutStoredScriptRequest) (*pb.PutStoredScriptResponse, error) {
+	s.logger.Info("PutStoredScript request", zap.String("id", req.Id))
+
+	// Validate request
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "script id is required")
+	}
+	if req.Source == "" {
+		return nil, status.Error(codes.InvalidArgument, "script source is required")
+	}
+	lang := req.Lang
+	if lang == "" {
+		lang = "mustache"
+	}
+	if lang != "mustache" {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported script lang [%s], only mustache is supported", lang)
+	}
+
+	// Check if not leader
+	if !s.node.IsLeader() {
+		return nil, status.Errorf(codes.FailedPrecondition, "not the leader, redirect to %s", s.node.Leader())
+	}
+
+	payload, err := json.Marshal(&raft.StoredScript{
+		ID:     req.Id,
+		Lang:   lang,
+		Source: req.Source,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal stored script: %v", err)
+	}
+
+	cmd := raft.Command{
+		Type:    raft.CommandPutStoredScript,
+		Payload: payload,
+	}
+
+	if err := s.node.raftNode.Apply(cmd, 5*time.Second); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to store script: %v", err)
+	}
+
+	return &pb.PutStoredScriptResponse{
+		Acknowledged: true,
+	}, nil
+}
+
+// GetStoredScript returns a stored script
+func (s *MasterService) GetStoredScript(ctx context.Context, req *pb.GetStoredScriptRequest) (*pb.GetStoredScriptResponse, error) {
+	s.logger.Debug("GetStoredScript request", zap.String("id", req.Id))
+
+	state, err := s.node.GetClusterState(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get cluster state: %v", err)
+	}
+
+	script, ok := state.StoredScripts[req.Id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "stored script not found: %s", req.Id)
+	}
+
+	return &pb.GetStoredScriptResponse{
+		Id:     script.ID,
+		Lang:   script.Lang,
+		Source: script.Source,
+	}, nil
+}
+
+// PutIndexTemplate stores an index template, replacing any template with the
+// same name
+func (s *MasterService) PutIndexTemplate(ctx context.Context, req *pb.PutIndexTemplateRequest) (*pb.PutIndexTemplateResponse, error) {
+	template := req.GetTemplate()
+	s.logger.Info("PutIndexTemplate request", zap.String("name", template.GetName()))
+
+	// Validate request
+	if template.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "index template name is required")
+	}
+	if len(template.GetIndexPatterns()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "index template requires at least one index pattern")
+	}
+
+	// Check if not leader
+	if !s.node.IsLeader() {
+		return nil, status.Errorf(codes.FailedPrecondition, "not the leader, redirect to %s", s.node.Leader())
+	}
+
+	payload, err := json.Marshal(&raft.IndexTemplate{
+		Name:          template.Name,
+		IndexPatterns: template.IndexPatterns,
+		Priority:      template.Priority,
+		Template:      template.Template,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal index template: %v", err)
+	}
+
+	cmd := raft.Command{
+		Type:    raft.CommandPutIndexTemplate,
+		Payload: payload,
+	}
+
+	if err := s.node.raftNode.Apply(cmd, 5*time.Second); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to store index template: %v", err)
+	}
+
+	return &pb.PutIndexTemplateResponse{
+		Acknowledged: true,
+	}, nil
+}
+
+// GetIndexTemplates returns one index template by name, or every template
+// ordered by name when no name is given
+func (s *MasterService) GetIndexTemplates(ctx context.Context, req *pb.GetIndexTemplatesRequest) (*pb.GetIndexTemplatesResponse, error) {
+	s.logger.Debug("GetIndexTemplates request", zap.String("name", req.Name))
+
+	state, err := s.node.GetClusterState(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get cluster state: %v", err)
+	}
+
+	var templates []*raft.IndexTemplate
+	if req.Name != "" {
+		template, ok := state.IndexTemplates[req.Name]
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "index template not found: %s", req.Name)
+		}
+		templates = append(templates, template)
+	} else {
+		for _, template := range state.IndexTemplates {
+			templates = append(templates, template)
+		}
+		sort.Slice(templates, func(i, j int) bool {
+			return templates[i].Name < templates[j].Name
+		})
+	}
+
+	resp := &pb.GetIndexTemplatesResponse{}
+	for _, template := range templates {
+		resp.Templates = append(resp.Templates, &pb.IndexTemplate{
+			Name:          template.Name,
+			IndexPatterns: template.IndexPatterns,
+			Priority:      template.Priority,
+			Template:      template.Template,
+		})
+	}
+	return resp, nil
+}
+
+// DeleteIndexTemplate deletes an index template. Indices already created
+// from it keep their settings.
+func (s *MasterService) DeleteIndexTemplate(ctx context.Context, req *pb.DeleteIndexTemplateRequest) (*pb.DeleteIndexTemplateResponse, error) {
+	s.logger.Info("DeleteIndexTemplate request", zap.String("name", req.Name))
+
+	state, err := s.node.GetClusterState(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get cluster state: %v", err)
+	}
+	if _, ok := state.IndexTemplates[req.Name]; !ok {
+		return nil, status.Errorf(codes.NotFound, "index template not found: %s", req.Name)
+	}
+
+	// Check if not leader
+	if !s.node.IsLeader() {
+		return nil, status.Errorf(codes.FailedPrecondition, "not the leader, redirect to %s", s.node.Leader())
+	}
+
+	payload, err := json.Marshal(&raft.IndexTemplate{Name: req.Name})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal index template: %v", err)
+	}
+
+	cmd := raft.Command{
+		Type:    raft.CommandDeleteIndexTemplate,
+		Payload: payload,
+	}
+
+	if err := s.node.raftNode.Apply(cmd, 5*time.Second); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete index template: %v", err)
+	}
+
+	return &pb.DeleteIndexTemplateResponse{
+		Acknowledged: true,
+	}, nil
+}
+
+// PutSnapshotRepository registers a snapshot repository, replacing any
+// repository with the same name
+func (s *MasterService) PutSnapshotRepository(ctx context.Context, req *pb.PutSnapshotRepositoryRequest) (*pb.PutSnapshotRepositoryResponse, error) {
+	repository := req.GetRepository()
+	s.logger.Info("PutSnapshotRepository request",
+		zap.String("name", repository.GetName()),
+		zap.String("type", repository.GetType()))
+
+	// Validate request
+	if repository.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "repository name is required")
+	}
+	if repository.GetType() != "fs" {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported repository type [%s], only fs is supported", repository.GetType())
+	}
+	if repository.GetLocation() == "" {
+		return nil, status.Error(codes.InvalidArgument, "fs repository requires a location")
+	}
+
+	// Check if not leader
+	if !s.node.IsLeader() {
+		return nil, status.Errorf(codes.FailedPrecondition, "not the leader, redirect to %s", s.node.Leader())
+	}
+
+	payload, err := json.Marshal(&raft.SnapshotRepository{
+		Name:     repository.Name,
+		Type:     repository.Type,
+		Location: repository.Location,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal snapshot repository: %v", err)
+	}
+
+	cmd := raft.Command{
+		Type:    raft.CommandPutSnapshotRepository,
+		Payload: payload,
+	}
+
+	if err := s.node.raftNode.Apply(cmd, 5*time.Second); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to register snapshot repository: %v", err)
+	}
+
+	return &pb.PutSnapshotRepositoryResponse{
+		Acknowledged: true,
+	}, nil
+}
+
+// GetSnapshotRepositories returns one snapshot repository by name, or every
+// repository ordered by name when no name is given
+func (s *MasterService) GetSnapshotRepositories(ctx context.Context, req *pb.GetSnapshotRepositoriesRequest) (*pb.GetSnapshotRepositoriesResponse, error) {
+	s.logger.Debug("GetSnapshotRepositories request", zap.String("name", req.Name))
+
+	state, err := s.node.GetClusterState(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get cluster state: %v", err)
+	}
+
+	var repositories []*raft.SnapshotRepository
+	if req.Name != "" {
+		repository, ok := state.SnapshotRepositories[req.Name]
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "snapshot repository not found: %s", req.Name)
+		}
+		repositories = append(repositories, repository)
+	} else {
+		for _, repository := range state.SnapshotRepositories {
+			repositories = append(repositories, repository)
+		}
+		sort.Slice(repositories, func(i, j int) bool {
+			return repositories[i].Name < repositories[j].Name
+		})
+	}
+
+	resp := &pb.GetSnapshotRepositoriesResponse{}
+	for _, repository := range repositories {
+		resp.Repositories = append(resp.Repositories, &pb.SnapshotRepository{
+			Name:     repository.Name,
+			Type:     repository.Type,
+			Location: repository.Location,
+		})
+	}
+	return resp, nil
+}
+
+// UpdateClusterSettings sets or resets persistent cluster settings
+func (s *MasterService) UpdateClusterSettings(ctx context.Context, req *pb.UpdateClusterSettingsRequest) (*pb.UpdateClusterSettingsResponse, error) {
+	s.logger.Info("UpdateClusterSettings request",
+		zap.Int("settings", len(req.Settings)),
+		zap.Strings("reset", req.ResetSettings))
+
+	// Validate request
+	update := make(map[string]*string, len(req.Settings)+len(req.ResetSettings))
+	for key, value := range req.Settings {
+		if err := validateClusterSetting(key, value); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		update[key] = &value
+	}
+	for _, key := range req.ResetSettings {
+		update[key] = nil
+	}
+
+	// Check if not leader
+	if !s.node.IsLeader() {
+		return nil, status.Errorf(codes.FailedPrecondition, "not the leader, redirect to %s", s.node.Leader())
+	}
+
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal cluster settings: %v", err)
+	}
+
+	cmd := raft.Command{
+		Type:    raft.CommandUpdateClusterSettings,
+		Payload: payload,
+	}
+
+	if err := s.node.raftNode.Apply(cmd, 5*time.Second); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update cluster settings: %v", err)
+	}
+
+	state, err := s.node.GetClusterState(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get cluster state: %v", err)
+	}
+
+	return &pb.UpdateClusterSettingsResponse{
+		Acknowledged: true,
+		Settings:     state.Settings,
+	}, nil
+}
+
+// GetClusterSettings returns the persistent cluster settings
+func (s *MasterService) GetClusterSettings(ctx context.Context, req *pb.GetClusterSettingsRequest) (*pb.GetClusterSettingsResponse, error) {
+	state, err := s.node.GetClusterState(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get cluster state: %v", err)
+	}
+
+	return &pb.GetClusterSettingsResponse{
+		Settings: state.Settings,
+	}, nil
+}
+
+// ExplainAllocation explains where a copy of a shard is allocated, or which
+// allocation deciders keep an unassigned copy off each data node
+func (s *MasterService) ExplainAllocation(ctx context.Context, req *pb.ExplainAllocationRequest) (*pb.ExplainAllocationResponse, error) {
+	state, err := s.node.GetClusterState(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get cluster state: %v", err)
+	}
+
+	explanation, err := allocation.NewAllocator(s.logger).ExplainShard(state, req.IndexName, req.ShardId, req.Primary)
+	if errors.Is(err, allocation.ErrIndexNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	resp := &pb.ExplainAllocationResponse{
+		IndexName:           explanation.IndexName,
+		ShardId:             explanation.ShardID,
+		Primary:             explanation.IsPrimary,
+		CurrentState:        explanation.CurrentState,
+		CurrentNode:         explanation.CurrentNode,
+		UnassignedReason:    explanation.UnassignedReason,
+		CanAllocate:         explanation.CanAllocate,
+		AllocateExplanation: explanation.AllocateExplanation,
+		CanRemain:           explanation.CanRemain,
+	}
+	if explanation.UnassignedAt > 0 {
+		resp.UnassignedAt = timestamppb.New(time.Unix(explanation.UnassignedAt, 0))
+	}
+	for _, node := range explanation.NodeDecisions {
+		nodeDecision := &pb.NodeAllocationDecision{
+			NodeId:  node.NodeID,
+			Zone:    node.Zone,
+			Allowed: node.Allowed,
+		}
+		for _, decision := range node.Decisions {
+			nodeDecision.Deciders = append(nodeDecision.Deciders, &pb.AllocationDeciderResult{
+				Decider:     decision.Decider,
+				Allowed:     decision.Allowed,
+				Explanation: decision.Explanation,
+			})
+		}
+		resp.NodeAllocationDecisions = append(resp.NodeAllocationDecisions, nodeDecision)
+	}
+
+	return resp, nil
+}
+
+// PutPipeline stores a pipeline definition, replacing any pipeline with the
+// same name. The definition is kept as given; coordination nodes validate it.
+func (s *MasterService) PutPipeline(ctx context.Context, req *pb.PutPipelineRequest) (*pb.PutPipelineResponse, error) {
+	pipeline := req.GetPipeline()
+	s.logger.Info("PutPipeline request", zap.String("name", pipeline.GetName()))
+
+	// Validate request
+	if pipeline.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "pipeline name is required")
+	}
+	if !json.Valid([]byte(pipeline.GetDefinition())) {
+		return nil, status.Error(codes.InvalidArgument, "pipeline definition must be valid JSON")
+	}
+
+	// Check if not leader
+	if !s.node.IsLeader() {
+		return nil, status.Errorf(codes.FailedPrecondition, "not the leader, redirect to %s", s.node.Leader())
+	}
+
+	payload, err := json.Marshal(&raft.StoredPipeline{
+		Name:       pipeline.Name,
+		Definition: pipeline.Definition,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal pipeline: %v", err)
+	}
+
+	cmd := raft.Command{
+		Type:    raft.CommandPutPipeline,
+		Payload: payload,
+	}
+
+	if err := s.node.raftNode.Apply(cmd, 5*time.Second); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to store pipeline: %v", err)
+	}
+
+	return &pb.PutPipelineResponse{
+		Acknowledged: true,
+	}, nil
+}
+
+// DeletePipeline deletes a pipeline that is no longer associated with any
+// index
+func (s *MasterService) DeletePipeline(ctx context.Context, req *pb.DeletePipelineRequest) (*pb.DeletePipelineResponse, error) {
+	s.logger.Info("DeletePipeline request", zap.String("name", req.Name))
+
+	state, err := s.node.GetClusterState(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get cluster state: %v", err)
+	}
+	if _, ok := state.Pipelines[req.Name]; !ok {
+		return nil, status.Errorf(codes.NotFound, "pipeline not found: %s", req.Name)
+	}
+	for index, pipelines := range state.IndexPipelines {
+		for pipelineType, name := range pipelines {
+			if name == req.Name {
+				return nil, status.Errorf(codes.FailedPrecondition,
+					"pipeline %s is still associated with index %s for type %s", req.Name, index, pipelineType)
+			}
+		}
+	}
+
+	// Check if not leader
+	if !s.node.IsLeader() {
+		return nil, status.Errorf(codes.FailedPrecondition, "not the leader, redirect to %s", s.node.Leader())
+	}
+
+	payload, err := json.Marshal(&raft.StoredPipeline{Name: req.Name})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal pipeline: %v", err)
+	}
+
+	cmd := raft.Command{
+		Type:    raft.CommandDeletePipeline,
+		Payload: payload,
+	}
+
+	if err := s.node.raftNode.Apply(cmd, 5*time.Second); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete pipeline: %v", err)
+	}
+
+	return &pb.DeletePipelineResponse{
+		Acknowledged: true,
+	}, nil
+}
+
+// GetPipelines returns every stored pipeline ordered by name, and every
+// index association ordered by index and type
+func (s *MasterService) GetPipelines(ctx context.Context, req *pb.GetPipelinesRequest) (*pb.GetPipelinesResponse, error) {
+	s.logger.Debug("GetPipelines request")
+
+	state, err := s.node.GetClusterState(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get cluster state: %v", err)
+	}
+
+	resp := &pb.GetPipelinesResponse{}
+	for _, pipeline := range state.Pipelines {
+		resp.Pipelines = append(resp.Pipelines, &pb.StoredPipeline{
+			Name:       pipeline.Name,
+			Definition: pipeline.Definition,
+		})
+	}
+	sort.Slice(resp.Pipelines, func(i, j int) bool {
+		return resp.Pipelines[i].Name < resp.Pipelines[j].Name
+	})
+
+	for index, pipelines := range state.IndexPipelines {
+		for pipelineType, name := range pipelines {
+			resp.IndexPipelines = append(resp.IndexPipelines, &pb.IndexPipeline{
+				IndexName: index,
+				Type:      pipelineType,
+				Pipeline:  name,
+			})
+		}
+	}
+	sort.Slice(resp.IndexPipelines, func(i, j int) bool {
+		a, b := resp.IndexPipelines[i], resp.IndexPipelines[j]
+		if a.IndexName != b.IndexName {
+			return a.IndexName < b.IndexName
+		}
+		return a.Type < b.Type
+	})
+
+	return resp, nil
+}
+
+// SetIndexPipeline associates a stored pipeline with an index for one
+// pipeline type, or removes the association when no pipeline is given
+func (s *MasterService) SetIndexPipeline(ctx context.Context, req *pb.SetIndexPipelineRequest) (*pb.SetIndexPipelineResponse, error) {
+	association := req.GetAssociation()
+	s.logger.Info("SetIndexPipeline request",
+		zap.String("index", association.GetIndexName()),
+		zap.String("type", association.GetType()),
+		zap.String("pipeline", association.GetPipeline()))
+
+	// Validate request
+	if association.GetIndexName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "index name is required")
+	}
+	if association.GetType() == "" {
+		return nil, status.Error(codes.InvalidArgument, "pipeline type is required")
+	}
+	if association.GetPipeline() != "" {
+		state, err := s.node.GetClusterState(ctx)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get cluster state: %v", err)
+		}
+		if _, ok := state.Pipelines[association.Pipeline]; !ok {
+			return nil, status.Errorf(codes.NotFound, "pipeline not found: %s", association.Pipeline)
+		}
+	}
+
+	// Check if not leader
+	if !s.node.IsLeader() {
+		return nil, status.Errorf(codes.FailedPrecondition, "not the leader, redirect to %s", s.node.Leader())
+	}
+
+	payload, err := json.Marshal(&raft.IndexPipeline{
+		IndexName: association.IndexName,
+		Type:      association.Type,
+		Pipeline:  association.Pipeline,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal index pipeline: %v", err)
+	}
+
+	cmd := raft.Command{
+		Type:    raft.CommandSetIndexPipeline,
+		Payload: payload,
+	}
+
+	if err := s.node.raftNode.Apply(cmd, 5*time.Second); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to set index pipeline: %v", err)
+	}
+
+	return &pb.SetIndexPipelineResponse{
+		Acknowledged: true,
+	}, nil
+}
+
+// WatchClusterState streams node membership and shard routing changes. The
+// stream opens with a NODE_JOINED event for every current member and a
+// SHARD_ALLOCATED event for every index with shards, so watchers converge
+// without a separate GetClusterState call, then reports joins, departures
+// and shard changes as the cluster state changes. Membership events carry a
+// serialized pb.NodeInfo, shard events the serialized pb.IndexRoutingTable
+// of the index whose shards changed, with no shards once it is deleted.
 func (s *MasterService) WatchClusterState(req *pb.WatchClusterStateRequest, stream pb.MasterService_WatchClusterStateServer) error {
 	s.logger.Info("WatchClusterState request", zap.Int64("from_version", req.FromVersion))
 
-	// TODO: Implement cluster state watching
-	// This would involve subscribing to FSM updates and streaming changes
-	return status.Error(codes.Unimplemented, "WatchClusterState not yet implemented")
+	updates, cancel := s.node.SubscribeClusterState()
+	defer cancel()
+
+	known := make(map[string]*raft.NodeMeta)
+	knownRouting := make(map[string][]byte)
+	for {
+		state, err := s.node.GetClusterState(stream.Context())
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to get cluster state: %v", err)
+		}
+
+		events, err := s.membershipEvents(known, state)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to build cluster state event: %v", err)
+		}
+		routingEvents, err := s.routingEvents(knownRouting, state)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to build cluster state event: %v", err)
+		}
+		events = append(events, routingEvents...)
+		for _, event := range events {
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-updates:
+		}
+	}
+}
+
+// membershipEvents diffs the nodes in state against known, returning join
+// and leave events and updating known to match state. A node whose address
+// changed is reported as joined again.
+func (s *MasterService) membershipEvents(known map[string]*raft.NodeMeta, state *raft.ClusterState) ([]*pb.ClusterStateEvent, error) {
+	var events []*pb.ClusterStateEvent
+
+	add := func(eventType pb.ClusterStateEvent_EventType, node *raft.NodeMeta) error {
+		payload, err := proto.Marshal(s.convertNodeToProto(node))
+		if err != nil {
+			return err
+		}
+		events = append(events, &pb.ClusterStateEvent{
+			Version: state.Version,
+			Type:    eventType,
+			Payload: payload,
+		})
+		return nil
+	}
+
+	for id, node := range known {
+		if _, exists := state.Nodes[id]; !exists {
+			if err := add(pb.ClusterStateEvent_EVENT_TYPE_NODE_LEFT, node); err != nil {
+				return nil, err
+			}
+			delete(known, id)
+		}
+	}
+
+	for id, node := range state.Nodes {
+		prev, exists := known[id]
+		if exists && prev.BindAddr == node.BindAddr && prev.GRPCPort == node.GRPCPort {
+			continue
+		}
+		if err := add(pb.ClusterStateEvent_EVENT_TYPE_NODE_JOINED, node); err != nil {
+			return nil, err
+		}
+		known[id] = node
+	}
+
+	return events, nil
+}
+
+// routingEvents diffs the shard routing of each index in state against
+// known, which holds the serialized routing last reported per index,
+// returning a SHARD_ALLOCATED event for every index whose shards changed and
+// updating known to match state
+func (s *MasterService) routingEvents(known map[string][]byte, state *raft.ClusterState) ([]*pb.ClusterStateEvent, error) {
+	var events []*pb.ClusterStateEvent
+
+	add := func(index *pb.IndexRoutingTable) ([]byte, error) {
+		// Deterministic so unchanged routing serializes to the same bytes
+		payload, err := proto.MarshalOptions{Deterministic: true}.Marshal(index)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(payload, known[index.IndexName]) {
+			return payload, nil
+		}
+		events = append(events, &pb.ClusterStateEvent{
+			Version: state.Version,
+			Type:    pb.ClusterStateEvent_EVENT_TYPE_SHARD_ALLOCATED,
+			Payload: payload,
+		})
+		return payload, nil
+	}
+
+	routing := s.convertRoutingTableToProto(state.ShardRouting).Indices
+	for name := range known {
+		if _, exists := routing[name]; exists {
+			continue
+		}
+		if _, err := add(&pb.IndexRoutingTable{IndexName: name}); err != nil {
+			return nil, err
+		}
+		delete(known, name)
+	}
+
+	for name, index := range routing {
+		payload, err := add(index)
+		if err != nil {
+			return nil, err
+		}
+		known[name] = payload
+	}
+
+	return events, nil
 }
 
 // Helper functions for conversions
 
 func (s *MasterService) calculateClusterStatus(state *raft.ClusterState) pb.ClusterStatus {
-	// Simple logic: if we have indices and nodes, cluster is green
+	// Simple logic: if we have indices and nodes, cluster is green unless
+	// a shard is unassigned
 	if len(state.Indices) > 0 && len(state.Nodes) > 0 {
-		return pb.ClusterStatus_CLUSTER_STATUS_GREEN
+		clusterStatus := pb.ClusterStatus_CLUSTER_STATUS_GREEN
+		for _, shard := range state.ShardRouting {
+			if shard.State != "unassigned" {
+				continue
+			}
+			if shard.IsPrimary {
+				return pb.ClusterStatus_CLUSTER_STATUS_RED
+			}
+			clusterStatus = pb.ClusterStatus_CLUSTER_STATUS_YELLOW
+		}
+		return clusterStatus
 	}
 	if len(state.Indices) > 0 {
 		return pb.ClusterStatus_CLUSTER_STATUS_YELLOW
@@ -404,10 +1272,8 @@ func (s *MasterService) convertIndicesToProto(indices map[string]*raft.IndexMeta
 			IndexName: idx.Name,
 			IndexUuid: idx.UUID,
 			Version:   idx.Version,
-			Settings: &pb.IndexSettings{
-				NumberOfShards:   idx.NumShards,
-				NumberOfReplicas: idx.NumReplicas,
-			},
+			Settings:  indexSettingsToProto(idx),
+			Mappings:  indexMappingsToProto(idx),
 			State:     s.convertIndexStateToProto(idx.State),
 			CreatedAt: timestamppb.New(time.Unix(idx.CreatedAt, 0)),
 		})
@@ -415,13 +1281,59 @@ func (s *MasterService) convertIndicesToProto(indices map[string]*raft.IndexMeta
 	return result
 }
 
+// indexSettingsToProto converts the settings recorded in an index's
+// metadata
+func indexSettingsToProto(index *raft.IndexMeta) *pb.IndexSettings {
+	settings := &pb.IndexSettings{
+		NumberOfShards:   index.NumShards,
+		NumberOfReplicas: index.NumReplicas,
+		StoreType:        index.Settings[SettingIndexStoreType],
+		Analysis:         index.Settings[SettingIndexAnalysis],
+		NumericFields:    index.Settings[SettingIndexNumericFields],
+		DateFields:       index.Settings[SettingIndexDateFields],
+		GeoPointFields:   index.Settings[SettingIndexGeoPointFields],
+		BlocksWrite:      index.Settings[SettingIndexBlocksWrite] == "true",
+	}
+	if value, ok := index.Settings[SettingIndexBufferSize]; ok {
+		settings.BufferSizeMb, _ = strconv.ParseFloat(value, 64)
+	}
+	if value, ok := index.Settings[SettingIndexMaxFieldValueLength]; ok {
+		settings.MaxFieldValueLength, _ = strconv.ParseInt(value, 10, 64)
+	}
+	if value, ok := index.Settings[SettingIndexMaxDocumentSize]; ok {
+		settings.MaxDocumentSizeBytes, _ = strconv.ParseInt(value, 10, 64)
+	}
+	if value, ok := index.Settings[SettingIndexMaxResultWindow]; ok {
+		window, _ := strconv.ParseInt(value, 10, 32)
+		settings.MaxResultWindow = int32(window)
+	}
+	settings.SlowlogThreshold = index.Settings[SettingIndexSlowlogThreshold]
+	if index.Tier != "" {
+		settings.Tiering = &pb.TieringSettings{DefaultTier: index.Tier}
+	}
+	return settings
+}
+
+// shardStateToProto converts a routing table shard state. Shards without a
+// state predate shard state tracking and are started.
+func shardStateToProto(state string) pb.ShardAllocation_ShardState {
+	switch state {
+	case "initializing":
+		return pb.ShardAllocation_SHARD_STATE_INITIALIZING
+	case "relocating":
+		return pb.ShardAllocation_SHARD_STATE_RELOCATING
+	case "unassigned":
+		return pb.ShardAllocation_SHARD_STATE_UNASSIGNED
+	default:
+		return pb.ShardAllocation_SHARD_STATE_STARTED
+	}
+}
+
 func (s *MasterService) convertRoutingTableToProto(routing map[string]*raft.ShardRouting) *pb.RoutingTable {
 	indices := make(map[string]*pb.IndexRoutingTable)
 
-	// Group shards by index name
-	for _, shard := range routing {
-		indexName := shard.IndexName
-
+	// shardEntry returns the routing entry of a shard, creating it if needed
+	shardEntry := func(indexName string, shardID int32) *pb.ShardRouting {
 		// Create index routing table if it doesn't exist
 		if _, exists := indices[indexName]; !exists {
 			indices[indexName] = &pb.IndexRoutingTable{
@@ -430,14 +1342,38 @@ func (s *MasterService) convertRoutingTableToProto(routing map[string]*raft.Shar
 			}
 		}
 
-		// Add shard routing
-		indices[indexName].Shards[shard.ShardID] = &pb.ShardRouting{
-			ShardId:   shard.ShardID,
-			IsPrimary: shard.IsPrimary,
-			Allocation: &pb.ShardAllocation{
-				NodeId: shard.NodeID,
-				State:  pb.ShardAllocation_SHARD_STATE_STARTED, // Assume started for now
-			},
+		entry, exists := indices[indexName].Shards[shardID]
+		if !exists {
+			entry = &pb.ShardRouting{ShardId: shardID}
+			indices[indexName].Shards[shardID] = entry
+		}
+		return entry
+	}
+
+	// Group shards by index name. Each shard has one entry holding the
+	// primary allocation, with replica copies listed alongside it.
+	for _, shard := range routing {
+		allocation := &pb.ShardAllocation{
+			NodeId:           shard.NodeID,
+			State:            shardStateToProto(shard.State),
+			RelocatingNodeId: shard.RelocatingNodeID,
+		}
+
+		entry := shardEntry(shard.IndexName, shard.ShardID)
+		if shard.IsPrimary {
+			entry.IsPrimary = true
+			entry.Allocation = allocation
+		} else {
+			entry.Replicas = append(entry.Replicas, allocation)
+		}
+	}
+
+	// Map iteration order is random; keep replica order stable for callers
+	for _, index := range indices {
+		for _, shard := range index.Shards {
+			sort.Slice(shard.Replicas, func(i, j int) bool {
+				return shard.Replicas[i].NodeId < shard.Replicas[j].NodeId
+			})
 		}
 	}
 
@@ -450,20 +1386,31 @@ func (s *MasterService) convertRoutingTableToProto(routing map[string]*raft.Shar
 func (s *MasterService) convertNodesToProto(nodes map[string]*raft.NodeMeta) []*pb.NodeInfo {
 	result := make([]*pb.NodeInfo, 0, len(nodes))
 	for _, node := range nodes {
-		result = append(result, &pb.NodeInfo{
-			NodeId:    node.NodeID,
-			NodeName:  node.NodeID,
-			NodeType:  s.convertNodeTypeToProto(node.NodeType),
-			BindAddr:  node.BindAddr,
-			GrpcPort:  node.GRPCPort,
-			Status:    s.convertNodeStatusToProto(node.Status),
-			JoinedAt:  timestamppb.New(time.Unix(node.JoinedAt, 0)),
-			LastSeen:  timestamppb.New(time.Unix(node.LastSeen, 0)),
-		})
+		result = append(result, s.convertNodeToProto(node))
 	}
 	return result
 }
 
+func (s *MasterService) convertNodeToProto(node *raft.NodeMeta) *pb.NodeInfo {
+	info := &pb.NodeInfo{
+		NodeId:   node.NodeID,
+		NodeName: node.NodeID,
+		NodeType: s.convertNodeTypeToProto(node.NodeType),
+		BindAddr: node.BindAddr,
+		GrpcPort: node.GRPCPort,
+		Status:   s.convertNodeStatusToProto(node.Status),
+		JoinedAt: timestamppb.New(time.Unix(node.JoinedAt, 0)),
+		LastSeen: timestamppb.New(time.Unix(node.LastSeen, 0)),
+	}
+	if node.Zone != "" || node.StorageTier != "" {
+		info.Attributes = &pb.NodeAttributes{StorageTier: node.StorageTier}
+		if node.Zone != "" {
+			info.Attributes.Labels = map[string]string{"zone": node.Zone}
+		}
+	}
+	return info
+}
+
 func (s *MasterService) convertIndexStateToProto(state string) pb.IndexMetadata_IndexState {
 	switch state {
 	case "creating":
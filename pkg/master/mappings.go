@@ -0,0 +1,130 @@
+package master
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/master/allocation"
+	"github.com/quidditch/quidditch/pkg/master/raft"
+	"go.uber.org/zap"
+)
+
+// ErrMappingVersionConflict is returned when an index's metadata changed
+// between reading its mapping and putting the merged mapping back
+var ErrMappingVersionConflict = errors.New("index metadata changed concurrently")
+
+// marshalMappings encodes field mappings as recorded in an index's
+// metadata. No mappings are recorded as empty.
+func marshalMappings(mappings map[string]*pb.FieldMapping) (string, error) {
+	if len(mappings) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(mappings)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// indexMappingsToProto converts the field mappings recorded in an index's
+// metadata
+func indexMappingsToProto(index *raft.IndexMeta) map[string]*pb.FieldMapping {
+	if index.Mappings == "" {
+		return nil
+	}
+	var mappings map[string]*pb.FieldMapping
+	if err := json.Unmarshal([]byte(index.Mappings), &mappings); err != nil {
+		return nil
+	}
+	return mappings
+}
+
+// PutIndexMapping replaces an index's field mappings, and the settings
+// derived from them, with a mapping merged from its metadata at the given
+// version. A version of 0 skips the check.
+func (m *MasterNode) PutIndexMapping(ctx context.Context, indexName, mappings string, settings map[string]string, version int64) (int64, error) {
+	if !m.raftNode.IsLeader() {
+		return 0, fmt.Errorf("not the leader, redirect to %s", m.raftNode.Leader())
+	}
+
+	index, exists := m.fsm.GetState().Indices[indexName]
+	if !exists {
+		return 0, fmt.Errorf("%w [%s]", allocation.ErrIndexNotFound, indexName)
+	}
+	if version != 0 && version != index.Version {
+		return 0, fmt.Errorf("%w: mapping of [%s] was merged from version [%d] but the index is at [%d]",
+			ErrMappingVersionConflict, indexName, version, index.Version)
+	}
+
+	updated := *index
+	updated.Mappings = mappings
+	updated.Version = index.Version + 1
+	updated.Settings = make(map[string]string, len(index.Settings)+len(settings))
+	for key, value := range index.Settings {
+		updated.Settings[key] = value
+	}
+	for key, value := range settings {
+		if value == "" {
+			delete(updated.Settings, key)
+			continue
+		}
+		updated.Settings[key] = value
+	}
+
+	payload, err := json.Marshal(&updated)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal index: %w", err)
+	}
+	if err := m.raftNode.Apply(raft.Command{Type: raft.CommandUpdateIndex, Payload: payload}, 5*time.Second); err != nil {
+		return 0, fmt.Errorf("failed to apply update index command: %w", err)
+	}
+
+	m.logger.Info("Updated index mapping",
+		zap.String("index", indexName),
+		zap.Int64("version", updated.Version))
+
+	// Shards received the index settings when they were created, so the
+	// open ones are sent the updated settings before the mapping is
+	// acknowledged
+	if err := m.updateShardSettings(ctx, indexName, updated.Settings); err != nil {
+		return 0, err
+	}
+	return updated.Version, nil
+}
+
+// updateShardSettings sends an index's settings to every data node holding
+// a copy of one of its shards, including relocation targets
+func (m *MasterNode) updateShardSettings(ctx context.Context, indexName string, settings map[string]string) error {
+	nodes := make(map[string]bool)
+	for _, shard := range m.fsm.GetState().ShardRouting {
+		if shard.IndexName != indexName {
+			continue
+		}
+		if shard.NodeID != "" {
+			nodes[shard.NodeID] = true
+		}
+		if shard.RelocatingNodeID != "" {
+			nodes[shard.RelocatingNodeID] = true
+		}
+	}
+
+	for nodeID := range nodes {
+		conn, err := m.dialDataNode(nodeID)
+		if err != nil {
+			return err
+		}
+		_, err = pb.NewDataServiceClient(conn).UpdateShardSettings(ctx, &pb.UpdateShardSettingsRequest{
+			IndexName: indexName,
+			Settings:  settings,
+		})
+		conn.Close()
+		if err != nil {
+			return fmt.Errorf("failed to update shard settings on data node %s: %w", nodeID, err)
+		}
+	}
+	return nil
+}
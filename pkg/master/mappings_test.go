@@ -0,0 +1,70 @@
+package master
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/quidditch/quidditch/pkg/common/config"
+	"go.uber.org/zap"
+)
+
+func TestMasterNodePutIndexMappingUpdatesShards(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	logger, _ := zap.NewDevelopment()
+	cfg := &config.MasterConfig{
+		NodeID:   "test-master",
+		BindAddr: "127.0.0.1",
+		RaftPort: 19314,
+		GRPCPort: 19315,
+		DataDir:  t.TempDir(),
+		Peers:    []string{},
+	}
+
+	node, err := NewMasterNode(cfg, logger)
+	if err != nil {
+		t.Fatalf("Failed to create master node: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := node.Start(ctx); err != nil {
+		t.Fatalf("Failed to start master node: %v", err)
+	}
+	defer node.Stop(ctx)
+
+	// Wait for leader election
+	time.Sleep(3 * time.Second)
+
+	if !node.IsLeader() {
+		t.Skip("Node did not become leader, skipping test")
+	}
+
+	dataNode := startFakeDataNode(t)
+	if err := node.RegisterNode(ctx, "data-1", "data", "127.0.0.1", dataNode.port); err != nil {
+		t.Fatalf("Failed to register node: %v", err)
+	}
+	if err := node.CreateIndex(ctx, "products", 1, 0); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+
+	// The mapping is acknowledged only once the open shard has the
+	// settings derived from it
+	analysis := `{"field_analyzers":{"title":"english"}}`
+	numericFields := `{"price":{"type":"double"}}`
+	if _, err := node.PutIndexMapping(ctx, "products", `{"title":{"type":"text","analyzer":"english"}}`, map[string]string{
+		SettingIndexAnalysis:      analysis,
+		SettingIndexNumericFields: numericFields,
+	}, 0); err != nil {
+		t.Fatalf("Failed to put mapping: %v", err)
+	}
+
+	dataNode.mu.Lock()
+	settings := dataNode.settings["products"]
+	dataNode.mu.Unlock()
+	if settings[SettingIndexAnalysis] != analysis || settings[SettingIndexNumericFields] != numericFields {
+		t.Errorf("Expected the shard to receive the mapping settings, got %v", settings)
+	}
+}
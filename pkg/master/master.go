@@ -2,18 +2,24 @@ package master
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net"
 	"time"
 
 	"github.com/google/uuid"
-	pb "github.com/quidditch/quidditch/pkg/common/proto"
 	"github.com/quidditch/quidditch/pkg/common/config"
+	"github.com/quidditch/quidditch/pkg/common/logging"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/common/requestid"
+	"github.com/quidditch/quidditch/pkg/common/tracing"
 	"github.com/quidditch/quidditch/pkg/master/allocation"
 	"github.com/quidditch/quidditch/pkg/master/raft"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 // MasterNode represents a master node in the Quidditch cluster
@@ -23,6 +29,14 @@ type MasterNode struct {
 	raftNode   *raft.RaftNode
 	grpcServer *grpc.Server
 	fsm        *raft.FSM
+	clientTLS  *tls.Config // TLS for connections to data nodes (nil = plaintext)
+
+	stopRebalancer context.CancelFunc
+
+	// logLevel is the level the node's logger logs at, which the
+	// logger.level cluster setting changes when set
+	logLevel          *zap.AtomicLevel
+	stopLogLevelWatch context.CancelFunc
 }
 
 // NewMasterNode creates a new master node
@@ -31,6 +45,24 @@ func NewMasterNode(cfg *config.MasterConfig, logger *zap.Logger) (*MasterNode, e
 		return nil, fmt.Errorf("logger is required")
 	}
 
+	// Build TLS credentials before creating anything that needs cleanup
+	serverOpts := []grpc.ServerOption{tracing.ServerOption(), requestid.ServerOption()}
+	var clientTLS *tls.Config
+	if cfg.TLS.Enabled() {
+		serverTLS, err := cfg.TLS.ServerConfig()
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls config: %w", err)
+		}
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(serverTLS)))
+	}
+	if cfg.TLS.Enabled() || cfg.TLS.CAFile != "" {
+		tlsConfig, err := cfg.TLS.ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls config: %w", err)
+		}
+		clientTLS = tlsConfig
+	}
+
 	// Create FSM
 	fsm := raft.NewFSM(logger)
 
@@ -50,7 +82,7 @@ func NewMasterNode(cfg *config.MasterConfig, logger *zap.Logger) (*MasterNode, e
 	}
 
 	// Create gRPC server
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(serverOpts...)
 
 	node := &MasterNode{
 		cfg:        cfg,
@@ -58,6 +90,7 @@ func NewMasterNode(cfg *config.MasterConfig, logger *zap.Logger) (*MasterNode, e
 		raftNode:   raftNode,
 		grpcServer: grpcServer,
 		fsm:        fsm,
+		clientTLS:  clientTLS,
 	}
 
 	// Register gRPC service
@@ -102,6 +135,22 @@ func (m *MasterNode) Start(ctx context.Context) error {
 		}
 	}()
 
+	// Rebalance shards in the background when configured; only the leader
+	// relocates them
+	if interval := m.cfg.RebalanceInterval; interval > 0 {
+		rebalanceCtx, cancel := context.WithCancel(context.Background())
+		m.stopRebalancer = cancel
+		go m.runRebalancer(rebalanceCtx, interval)
+	}
+
+	// Follow the logger.level cluster setting on followers as well as the
+	// leader
+	if m.logLevel != nil {
+		watchCtx, cancel := context.WithCancel(context.Background())
+		m.stopLogLevelWatch = cancel
+		go m.watchLogLevel(watchCtx)
+	}
+
 	return nil
 }
 
@@ -109,6 +158,13 @@ func (m *MasterNode) Start(ctx context.Context) error {
 func (m *MasterNode) Stop(ctx context.Context) error {
 	m.logger.Info("Stopping master node")
 
+	if m.stopRebalancer != nil {
+		m.stopRebalancer()
+	}
+	if m.stopLogLevelWatch != nil {
+		m.stopLogLevelWatch()
+	}
+
 	// Stop gRPC server
 	m.grpcServer.GracefulStop()
 
@@ -120,6 +176,30 @@ func (m *MasterNode) Stop(ctx context.Context) error {
 	return nil
 }
 
+// SetLogLevel sets the level the node's logger logs at, letting the
+// logger.level cluster setting change it while the node runs
+func (m *MasterNode) SetLogLevel(level zap.AtomicLevel) {
+	m.logLevel = &level
+}
+
+// watchLogLevel applies the logger.level cluster setting as this node's
+// copy of the cluster state holds it, and again whenever the state changes,
+// until ctx is done
+func (m *MasterNode) watchLogLevel(ctx context.Context) {
+	changes, cancel := m.fsm.Subscribe()
+	defer cancel()
+
+	for {
+		logging.ApplySetting(*m.logLevel, m.fsm.GetState().Settings, m.cfg.LogLevel, m.logger)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-changes:
+		}
+	}
+}
+
 // initializeCluster initializes a new cluster with a UUID
 func (m *MasterNode) initializeCluster() error {
 	state := m.fsm.GetState()
@@ -145,6 +225,13 @@ func (m *MasterNode) initializeCluster() error {
 
 // CreateIndex creates a new index in the cluster
 func (m *MasterNode) CreateIndex(ctx context.Context, indexName string, numShards, numReplicas int32) error {
+	return m.CreateIndexWithSettings(ctx, indexName, numShards, numReplicas, nil, "")
+}
+
+// CreateIndexWithSettings creates a new index whose metadata records the
+// given index settings, such as index.store.type, which are passed on to
+// the data nodes creating its shards, and its field mappings as JSON
+func (m *MasterNode) CreateIndexWithSettings(ctx context.Context, indexName string, numShards, numReplicas int32, settings map[string]string, mappings string) error {
 	if !m.raftNode.IsLeader() {
 		return fmt.Errorf("not the leader, redirect to %s", m.raftNode.Leader())
 	}
@@ -156,9 +243,13 @@ func (m *MasterNode) CreateIndex(ctx context.Context, indexName string, numShard
 		Version:     1,
 		NumShards:   numShards,
 		NumReplicas: numReplicas,
-		Settings:    make(map[string]string),
+		Settings:    make(map[string]string, len(settings)),
 		State:       "open",
 		CreatedAt:   time.Now().Unix(),
+		Mappings:    mappings,
+	}
+	for key, value := range settings {
+		index.Settings[key] = value
 	}
 
 	// Marshal payload
@@ -302,7 +393,43 @@ func (m *MasterNode) allocateShards(ctx context.Context, indexName string, numSh
 			zap.String("node", decision.NodeID))
 
 		// After allocation in Raft, tell the data node to actually create the shard
-		go m.createShardOnDataNode(ctx, decision.NodeID, indexName, decision.ShardID)
+		go m.createShardOnDataNode(ctx, decision.NodeID, indexName, decision.ShardID, decision.IsPrimary)
+	}
+
+	// Replicas the allocator could not place apart from the other copies of
+	// their shard stay unassigned, which leaves the cluster yellow
+	replicas := make(map[int32]int32)
+	for _, decision := range decisions {
+		if !decision.IsPrimary {
+			replicas[decision.ShardID]++
+		}
+	}
+	for shardID := int32(0); shardID < numShards; shardID++ {
+		if replicas[shardID] >= numReplicas {
+			continue
+		}
+
+		unassigned := raft.ShardRouting{
+			IndexName:        indexName,
+			ShardID:          shardID,
+			State:            "unassigned",
+			Version:          1,
+			UnassignedReason: "INDEX_CREATED",
+			UnassignedAt:     time.Now().Unix(),
+		}
+		if err := m.applyShardCommand(raft.CommandAllocateShard, &unassigned); err != nil {
+			m.logger.Error("Failed to record unassigned replica",
+				zap.String("index", indexName),
+				zap.Int32("shard_id", shardID),
+				zap.Error(err))
+			continue
+		}
+
+		m.logger.Warn("Replica shard left unassigned",
+			zap.String("index", indexName),
+			zap.Int32("shard_id", shardID),
+			zap.Int32("assigned_replicas", replicas[shardID]),
+			zap.Int32("num_replicas", numReplicas))
 	}
 
 	return nil
@@ -348,8 +475,14 @@ func (m *MasterNode) GetClusterState(ctx context.Context) (*raft.ClusterState, e
 	return m.fsm.GetState(), nil
 }
 
+// SubscribeClusterState returns a channel signalled on every cluster state
+// change and a function that cancels the subscription
+func (m *MasterNode) SubscribeClusterState() (<-chan struct{}, func()) {
+	return m.fsm.Subscribe()
+}
+
 // createShardOnDataNode creates a shard on the specified data node
-func (m *MasterNode) createShardOnDataNode(ctx context.Context, nodeID, indexName string, shardID int32) {
+func (m *MasterNode) createShardOnDataNode(ctx context.Context, nodeID, indexName string, shardID int32, isPrimary bool) {
 	// Get node information from cluster state
 	state := m.fsm.GetState()
 	node, exists := state.Nodes[nodeID]
@@ -361,7 +494,7 @@ func (m *MasterNode) createShardOnDataNode(ctx context.Context, nodeID, indexNam
 
 	// Connect to data node
 	addr := fmt.Sprintf("%s:%d", node.BindAddr, node.GRPCPort)
-	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(m.transportCredentials()), tracing.ClientOption(), requestid.ClientOption())
 	if err != nil {
 		m.logger.Error("Failed to connect to data node",
 			zap.String("node_id", nodeID),
@@ -377,6 +510,10 @@ func (m *MasterNode) createShardOnDataNode(ctx context.Context, nodeID, indexNam
 	req := &pb.CreateShardRequest{
 		IndexName: indexName,
 		ShardId:   shardID,
+		IsPrimary: isPrimary,
+	}
+	if index, exists := state.Indices[indexName]; exists {
+		req.Settings = index.Settings
 	}
 
 	m.logger.Info("Creating shard on data node",
@@ -395,6 +532,11 @@ func (m *MasterNode) createShardOnDataNode(ctx context.Context, nodeID, indexNam
 			zap.String("index", indexName),
 			zap.Int32("shard_id", shardID),
 			zap.Error(err))
+
+		// A replica left initializing would hold back writes to its shard
+		if !isPrimary {
+			m.failReplica(raft.ShardRouting{IndexName: indexName, ShardID: shardID, NodeID: nodeID})
+		}
 		return
 	}
 
@@ -413,7 +555,12 @@ func (m *MasterNode) createShardOnDataNode(ctx context.Context, nodeID, indexNam
 
 		// Get current shard routing to preserve IsPrimary field
 		state := m.fsm.GetState()
-		key := fmt.Sprintf("%s:%d", indexName, shardID)
+		key := raft.ShardRoutingKey(&raft.ShardRouting{
+			IndexName: indexName,
+			ShardID:   shardID,
+			IsPrimary: isPrimary,
+			NodeID:    nodeID,
+		})
 		currentShard, exists := state.ShardRouting[key]
 		if !exists {
 			m.logger.Error("Shard not found in routing table during state update",
@@ -476,3 +623,11 @@ func (m *MasterNode) IsLeader() bool {
 func (m *MasterNode) Leader() string {
 	return m.raftNode.Leader()
 }
+
+// transportCredentials returns the credentials used to dial data nodes
+func (m *MasterNode) transportCredentials() credentials.TransportCredentials {
+	if m.clientTLS == nil {
+		return insecure.NewCredentials()
+	}
+	return credentials.NewTLS(m.clientTLS)
+}
@@ -2,13 +2,19 @@ package master
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	hraft "github.com/hashicorp/raft"
 	"github.com/quidditch/quidditch/pkg/common/config"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/master/raft"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestNewMasterNode(t *testing.T) {
@@ -473,6 +479,199 @@ func TestMasterNodeRegisterNodeAsLeader(t *testing.T) {
 	}
 }
 
+func TestMasterNodeWatchLogLevel(t *testing.T) {
+	fsm := raft.NewFSM(zap.NewNop())
+	node := &MasterNode{
+		cfg:    &config.MasterConfig{LogLevel: "info"},
+		logger: zap.NewNop(),
+		fsm:    fsm,
+	}
+	level := zap.NewAtomicLevelAt(zapcore.WarnLevel)
+	node.SetLogLevel(level)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go node.watchLogLevel(ctx)
+
+	waitForLevel := func(want zapcore.Level) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for level.Level() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected log level %v, got %v", want, level.Level())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	applySettings := func(payload string) {
+		t.Helper()
+		cmdData, err := json.Marshal(raft.Command{Type: raft.CommandUpdateClusterSettings, Payload: json.RawMessage(payload)})
+		if err != nil {
+			t.Fatalf("Failed to marshal command: %v", err)
+		}
+		if err, ok := fsm.Apply(&hraft.Log{Type: hraft.LogCommand, Data: cmdData}).(error); ok {
+			t.Fatalf("Apply returned error: %v", err)
+		}
+	}
+
+	// Unset, the node logs at its configured level
+	waitForLevel(zapcore.InfoLevel)
+
+	// Setting logger.level changes it as the state is applied
+	applySettings(`{"logger.level": "debug"}`)
+	waitForLevel(zapcore.DebugLevel)
+
+	// Resetting it returns to the configured level
+	applySettings(`{"logger.level": null}`)
+	waitForLevel(zapcore.InfoLevel)
+}
+
+func TestMasterServiceMembershipEvents(t *testing.T) {
+	service := NewMasterService(nil, zap.NewNop())
+	known := make(map[string]*raft.NodeMeta)
+
+	decode := func(event *pb.ClusterStateEvent) *pb.NodeInfo {
+		var node pb.NodeInfo
+		if err := proto.Unmarshal(event.Payload, &node); err != nil {
+			t.Fatalf("Failed to decode event payload: %v", err)
+		}
+		return &node
+	}
+
+	// Initial state reports every member as joined
+	state := &raft.ClusterState{
+		Version: 1,
+		Nodes: map[string]*raft.NodeMeta{
+			"data-1": {NodeID: "data-1", NodeType: "data", BindAddr: "10.0.0.1", GRPCPort: 9303},
+		},
+	}
+	events, err := service.membershipEvents(known, state)
+	if err != nil {
+		t.Fatalf("membershipEvents failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != pb.ClusterStateEvent_EVENT_TYPE_NODE_JOINED {
+		t.Fatalf("Expected one join event, got %v", events)
+	}
+	if node := decode(events[0]); node.NodeId != "data-1" || node.NodeType != pb.NodeType_NODE_TYPE_DATA {
+		t.Errorf("Unexpected node in join event: %v", node)
+	}
+
+	// Unchanged membership produces no events
+	state.Version = 2
+	events, _ = service.membershipEvents(known, state)
+	if len(events) != 0 {
+		t.Errorf("Expected no events, got %d", len(events))
+	}
+
+	// data-1 leaves and data-2 joins
+	state = &raft.ClusterState{
+		Version: 3,
+		Nodes: map[string]*raft.NodeMeta{
+			"data-2": {NodeID: "data-2", NodeType: "data", BindAddr: "10.0.0.2", GRPCPort: 9303},
+		},
+	}
+	events, _ = service.membershipEvents(known, state)
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	if events[0].Type != pb.ClusterStateEvent_EVENT_TYPE_NODE_LEFT || decode(events[0]).NodeId != "data-1" {
+		t.Errorf("Expected data-1 to leave, got %v", events[0])
+	}
+	if events[1].Type != pb.ClusterStateEvent_EVENT_TYPE_NODE_JOINED || decode(events[1]).NodeId != "data-2" {
+		t.Errorf("Expected data-2 to join, got %v", events[1])
+	}
+	if events[1].Version != 3 {
+		t.Errorf("Expected event version 3, got %d", events[1].Version)
+	}
+}
+
+func TestMasterServiceRoutingEvents(t *testing.T) {
+	service := NewMasterService(nil, zap.NewNop())
+	known := make(map[string][]byte)
+
+	decode := func(event *pb.ClusterStateEvent) *pb.IndexRoutingTable {
+		var index pb.IndexRoutingTable
+		if err := proto.Unmarshal(event.Payload, &index); err != nil {
+			t.Fatalf("Failed to decode event payload: %v", err)
+		}
+		return &index
+	}
+
+	state := &raft.ClusterState{
+		Version: 1,
+		ShardRouting: map[string]*raft.ShardRouting{
+			"products:0:primary": {IndexName: "products", ShardID: 0, IsPrimary: true, NodeID: "data-1", State: "initializing"},
+		},
+	}
+	events, err := service.routingEvents(known, state)
+	if err != nil {
+		t.Fatalf("routingEvents failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != pb.ClusterStateEvent_EVENT_TYPE_SHARD_ALLOCATED {
+		t.Fatalf("Expected one shard event, got %v", events)
+	}
+	if index := decode(events[0]); index.IndexName != "products" || len(index.Shards) != 1 {
+		t.Errorf("Unexpected routing in shard event: %v", index)
+	}
+
+	// Unchanged routing produces no events
+	state.Version = 2
+	events, _ = service.routingEvents(known, state)
+	if len(events) != 0 {
+		t.Errorf("Expected no events, got %d", len(events))
+	}
+
+	// The shard starts
+	state.Version = 3
+	state.ShardRouting["products:0:primary"].State = "started"
+	events, _ = service.routingEvents(known, state)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	if shard := decode(events[0]).Shards[0]; shard.Allocation.State != pb.ShardAllocation_SHARD_STATE_STARTED {
+		t.Errorf("Expected a started shard, got %v", shard)
+	}
+
+	// The index is deleted
+	state.Version = 4
+	state.ShardRouting = map[string]*raft.ShardRouting{}
+	events, _ = service.routingEvents(known, state)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	if index := decode(events[0]); index.IndexName != "products" || len(index.Shards) != 0 {
+		t.Errorf("Expected products with no shards, got %v", index)
+	}
+}
+
+func TestMasterServiceRoutingTableReplicas(t *testing.T) {
+	service := NewMasterService(nil, zap.NewNop())
+
+	routing := map[string]*raft.ShardRouting{
+		"products:0":                {IndexName: "products", ShardID: 0, IsPrimary: true, NodeID: "node-1"},
+		"products:0:replica:node-3": {IndexName: "products", ShardID: 0, NodeID: "node-3"},
+		"products:0:replica:node-2": {IndexName: "products", ShardID: 0, NodeID: "node-2"},
+		"products:1":                {IndexName: "products", ShardID: 1, IsPrimary: true, NodeID: "node-2"},
+	}
+
+	table := service.convertRoutingTableToProto(routing)
+	shards := table.Indices["products"].Shards
+	if len(shards) != 2 {
+		t.Fatalf("Expected 2 shards, got %d", len(shards))
+	}
+
+	shard0 := shards[0]
+	if !shard0.IsPrimary || shard0.Allocation.GetNodeId() != "node-1" {
+		t.Errorf("Expected shard 0 primary on node-1, got %v", shard0)
+	}
+	if len(shard0.Replicas) != 2 || shard0.Replicas[0].NodeId != "node-2" || shard0.Replicas[1].NodeId != "node-3" {
+		t.Errorf("Expected replicas on node-2 and node-3, got %v", shard0.Replicas)
+	}
+	if len(shards[1].Replicas) != 0 {
+		t.Errorf("Expected no replicas for shard 1, got %v", shards[1].Replicas)
+	}
+}
+
 func BenchmarkGetClusterState(b *testing.B) {
 	logger, _ := zap.NewDevelopment()
 	tmpDir := b.TempDir()
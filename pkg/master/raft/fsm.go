@@ -15,9 +15,9 @@ type CommandType string
 
 const (
 	// Index commands
-	CommandCreateIndex  CommandType = "create_index"
-	CommandDeleteIndex  CommandType = "delete_index"
-	CommandUpdateIndex  CommandType = "update_index"
+	CommandCreateIndex CommandType = "create_index"
+	CommandDeleteIndex CommandType = "delete_index"
+	CommandUpdateIndex CommandType = "update_index"
 
 	// Node commands
 	CommandRegisterNode   CommandType = "register_node"
@@ -29,6 +29,26 @@ const (
 	CommandAllocateShard   CommandType = "allocate_shard"
 	CommandDeallocateShard CommandType = "deallocate_shard"
 	CommandUpdateShard     CommandType = "update_shard"
+	CommandRelocateShard   CommandType = "relocate_shard"
+	CommandRemoveShard     CommandType = "remove_shard"
+
+	// Stored script commands
+	CommandPutStoredScript CommandType = "put_stored_script"
+
+	// Index template commands
+	CommandPutIndexTemplate    CommandType = "put_index_template"
+	CommandDeleteIndexTemplate CommandType = "delete_index_template"
+
+	// Snapshot repository commands
+	CommandPutSnapshotRepository CommandType = "put_snapshot_repository"
+
+	// Cluster settings commands
+	CommandUpdateClusterSettings CommandType = "update_cluster_settings"
+
+	// Pipeline commands
+	CommandPutPipeline      CommandType = "put_pipeline"
+	CommandDeletePipeline   CommandType = "delete_pipeline"
+	CommandSetIndexPipeline CommandType = "set_index_pipeline"
 )
 
 // Command represents a state change command
@@ -39,36 +59,78 @@ type Command struct {
 
 // ClusterState represents the entire cluster state
 type ClusterState struct {
-	Version      int64                   `json:"version"`
-	ClusterUUID  string                  `json:"cluster_uuid"`
-	Indices      map[string]*IndexMeta   `json:"indices"`       // index_name -> metadata
-	Nodes        map[string]*NodeMeta    `json:"nodes"`         // node_id -> metadata
+	Version      int64                    `json:"version"`
+	ClusterUUID  string                   `json:"cluster_uuid"`
+	Indices      map[string]*IndexMeta    `json:"indices"`       // index_name -> metadata
+	Nodes        map[string]*NodeMeta     `json:"nodes"`         // node_id -> metadata
 	ShardRouting map[string]*ShardRouting `json:"shard_routing"` // "index:shard_id" -> routing
+
+	// Stored scripts such as search templates, by script id
+	StoredScripts map[string]*StoredScript `json:"stored_scripts,omitempty"`
+
+	// Index templates applied to new indices, by template name
+	IndexTemplates map[string]*IndexTemplate `json:"index_templates,omitempty"`
+
+	// Snapshot repositories, by repository name
+	SnapshotRepositories map[string]*SnapshotRepository `json:"snapshot_repositories,omitempty"`
+
+	// Persistent cluster settings, by dotted setting name
+	Settings map[string]string `json:"settings,omitempty"`
+
+	// Pipelines, by pipeline name
+	Pipelines map[string]*StoredPipeline `json:"pipelines,omitempty"`
+
+	// Pipelines associated with indices: index name -> pipeline type ->
+	// pipeline name
+	IndexPipelines map[string]map[string]string `json:"index_pipelines,omitempty"`
 }
 
 // IndexMeta stores index metadata
 type IndexMeta struct {
-	Name             string            `json:"name"`
-	UUID             string            `json:"uuid"`
-	Version          int64             `json:"version"`
-	NumShards        int32             `json:"num_shards"`
-	NumReplicas      int32             `json:"num_replicas"`
-	Settings         map[string]string `json:"settings"`
-	State            string            `json:"state"` // open, closed, deleting
-	CreatedAt        int64             `json:"created_at"`
+	Name        string            `json:"name"`
+	UUID        string            `json:"uuid"`
+	Version     int64             `json:"version"`
+	NumShards   int32             `json:"num_shards"`
+	NumReplicas int32             `json:"num_replicas"`
+	Settings    map[string]string `json:"settings"`
+	State       string            `json:"state"` // open, closed, deleting
+	CreatedAt   int64             `json:"created_at"`
+
+	// Tier is the storage tier whose data nodes hold the index's shards;
+	// empty places them on any data node
+	Tier string `json:"tier,omitempty"`
+
+	// Mappings is the index's field mappings as JSON, keyed by field name
+	Mappings string `json:"mappings,omitempty"`
 }
 
 // NodeMeta stores node metadata
 type NodeMeta struct {
-	NodeID      string            `json:"node_id"`
-	NodeType    string            `json:"node_type"` // master, coordination, data
-	BindAddr    string            `json:"bind_addr"`
-	GRPCPort    int32             `json:"grpc_port"`
-	StorageTier string            `json:"storage_tier"`
-	MaxShards   int32             `json:"max_shards"`
-	Status      string            `json:"status"` // healthy, degraded, offline
-	JoinedAt    int64             `json:"joined_at"`
-	LastSeen    int64             `json:"last_seen"`
+	NodeID      string `json:"node_id"`
+	NodeType    string `json:"node_type"` // master, coordination, data
+	BindAddr    string `json:"bind_addr"`
+	GRPCPort    int32  `json:"grpc_port"`
+	StorageTier string `json:"storage_tier"`
+	MaxShards   int32  `json:"max_shards"`
+	Status      string `json:"status"` // healthy, degraded, offline
+	JoinedAt    int64  `json:"joined_at"`
+	LastSeen    int64  `json:"last_seen"`
+
+	// Zone is the node's rack or availability zone, used to spread the
+	// copies of a shard (optional)
+	Zone string `json:"zone,omitempty"`
+
+	// DiskUsagePercent is the share of the node's data disk in use, as last
+	// reported by the node
+	DiskUsagePercent float64 `json:"disk_usage_percent,omitempty"`
+
+	// DiskTotalBytes is the size of the node's data disk, as last reported
+	// by the node
+	DiskTotalBytes int64 `json:"disk_total_bytes,omitempty"`
+
+	// ShardSizes are the on-disk sizes of the shards the node holds, keyed
+	// by index:shard, as last reported by the node
+	ShardSizes map[string]int64 `json:"shard_sizes,omitempty"`
 }
 
 // ShardRouting stores shard allocation information
@@ -79,6 +141,61 @@ type ShardRouting struct {
 	NodeID    string `json:"node_id"`
 	State     string `json:"state"` // initializing, started, relocating, unassigned
 	Version   int64  `json:"version"`
+
+	// RelocatingNodeID is the node a relocating shard copy is moving to
+	RelocatingNodeID string `json:"relocating_node_id,omitempty"`
+
+	// UnassignedReason records why an unassigned shard copy could not be
+	// allocated, and UnassignedAt when (Unix seconds)
+	UnassignedReason string `json:"unassigned_reason,omitempty"`
+	UnassignedAt     int64  `json:"unassigned_at,omitempty"`
+}
+
+// StoredScript is a stored script, such as a mustache search template
+type StoredScript struct {
+	ID     string `json:"id"`
+	Lang   string `json:"lang"`
+	Source string `json:"source"`
+}
+
+// IndexTemplate holds the settings and mappings given to new indices whose
+// names match one of its patterns
+type IndexTemplate struct {
+	Name          string   `json:"name"`
+	IndexPatterns []string `json:"index_patterns"`
+	Priority      int64    `json:"priority"`
+	Template      string   `json:"template"` // JSON {"settings": ..., "mappings": ...}
+}
+
+// SnapshotRepository is a registered location that snapshots are written to
+type SnapshotRepository struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"` // fs
+	Location string `json:"location"`
+}
+
+// StoredPipeline is a pipeline definition kept for the coordination nodes,
+// which own its format
+type StoredPipeline struct {
+	Name       string `json:"name"`
+	Definition string `json:"definition"` // JSON pipeline definition
+}
+
+// IndexPipeline associates a pipeline with an index for one pipeline type
+type IndexPipeline struct {
+	IndexName string `json:"index_name"`
+	Type      string `json:"type"`     // query, document, result
+	Pipeline  string `json:"pipeline"` // Empty removes the association
+}
+
+// ShardRoutingKey returns the ShardRouting map key of a shard copy. Primaries
+// are keyed "index:shard"; replicas also carry the node holding them, so
+// every copy of a shard has its own entry.
+func ShardRoutingKey(shard *ShardRouting) string {
+	if shard.IsPrimary {
+		return fmt.Sprintf("%s:%d", shard.IndexName, shard.ShardID)
+	}
+	return fmt.Sprintf("%s:%d:replica:%s", shard.IndexName, shard.ShardID, shard.NodeID)
 }
 
 // FSM (Finite State Machine) implements raft.FSM interface
@@ -86,6 +203,9 @@ type FSM struct {
 	mu     sync.RWMutex
 	state  *ClusterState
 	logger *zap.Logger
+
+	watchMu  sync.Mutex
+	watchers map[chan struct{}]struct{}
 }
 
 // NewFSM creates a new FSM
@@ -96,13 +216,24 @@ func NewFSM(logger *zap.Logger) *FSM {
 			Indices:      make(map[string]*IndexMeta),
 			Nodes:        make(map[string]*NodeMeta),
 			ShardRouting: make(map[string]*ShardRouting),
+
+			StoredScripts:        make(map[string]*StoredScript),
+			IndexTemplates:       make(map[string]*IndexTemplate),
+			SnapshotRepositories: make(map[string]*SnapshotRepository),
+			Settings:             make(map[string]string),
+			Pipelines:            make(map[string]*StoredPipeline),
+			IndexPipelines:       make(map[string]map[string]string),
 		},
-		logger: logger,
+		logger:   logger,
+		watchers: make(map[chan struct{}]struct{}),
 	}
 }
 
 // Apply applies a Raft log entry to the FSM
 func (f *FSM) Apply(log *raft.Log) interface{} {
+	// Deferred first so watchers are notified after the lock is released
+	defer f.notifyWatchers()
+
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
@@ -135,6 +266,26 @@ func (f *FSM) Apply(log *raft.Log) interface{} {
 		return f.applyDeallocateShard(cmd.Payload)
 	case CommandUpdateShard:
 		return f.applyUpdateShard(cmd.Payload)
+	case CommandRelocateShard:
+		return f.applyRelocateShard(cmd.Payload)
+	case CommandRemoveShard:
+		return f.applyRemoveShard(cmd.Payload)
+	case CommandPutStoredScript:
+		return f.applyPutStoredScript(cmd.Payload)
+	case CommandPutIndexTemplate:
+		return f.applyPutIndexTemplate(cmd.Payload)
+	case CommandDeleteIndexTemplate:
+		return f.applyDeleteIndexTemplate(cmd.Payload)
+	case CommandPutSnapshotRepository:
+		return f.applyPutSnapshotRepository(cmd.Payload)
+	case CommandUpdateClusterSettings:
+		return f.applyUpdateClusterSettings(cmd.Payload)
+	case CommandPutPipeline:
+		return f.applyPutPipeline(cmd.Payload)
+	case CommandDeletePipeline:
+		return f.applyDeletePipeline(cmd.Payload)
+	case CommandSetIndexPipeline:
+		return f.applySetIndexPipeline(cmd.Payload)
 	default:
 		return fmt.Errorf("unknown command type: %s", cmd.Type)
 	}
@@ -152,6 +303,13 @@ func (f *FSM) Snapshot() (raft.FSMSnapshot, error) {
 		Indices:      make(map[string]*IndexMeta),
 		Nodes:        make(map[string]*NodeMeta),
 		ShardRouting: make(map[string]*ShardRouting),
+
+		StoredScripts:        make(map[string]*StoredScript),
+		IndexTemplates:       make(map[string]*IndexTemplate),
+		SnapshotRepositories: make(map[string]*SnapshotRepository),
+		Settings:             make(map[string]string),
+		Pipelines:            make(map[string]*StoredPipeline),
+		IndexPipelines:       make(map[string]map[string]string),
 	}
 
 	for k, v := range f.state.Indices {
@@ -163,6 +321,29 @@ func (f *FSM) Snapshot() (raft.FSMSnapshot, error) {
 	for k, v := range f.state.ShardRouting {
 		stateCopy.ShardRouting[k] = v
 	}
+	for k, v := range f.state.StoredScripts {
+		stateCopy.StoredScripts[k] = v
+	}
+	for k, v := range f.state.IndexTemplates {
+		stateCopy.IndexTemplates[k] = v
+	}
+	for k, v := range f.state.SnapshotRepositories {
+		stateCopy.SnapshotRepositories[k] = v
+	}
+	for k, v := range f.state.Settings {
+		stateCopy.Settings[k] = v
+	}
+	for k, v := range f.state.Pipelines {
+		stateCopy.Pipelines[k] = v
+	}
+	for index, pipelines := range f.state.IndexPipelines {
+		// Associations are updated in place, so the inner maps are copied
+		pipelinesCopy := make(map[string]string, len(pipelines))
+		for k, v := range pipelines {
+			pipelinesCopy[k] = v
+		}
+		stateCopy.IndexPipelines[index] = pipelinesCopy
+	}
 
 	return &fsmSnapshot{state: stateCopy}, nil
 }
@@ -177,11 +358,11 @@ func (f *FSM) Restore(rc io.ReadCloser) error {
 	}
 
 	f.mu.Lock()
-	defer f.mu.Unlock()
-
 	f.state = &state
 	f.logger.Info("Restored FSM from snapshot", zap.Int64("version", state.Version))
+	f.mu.Unlock()
 
+	f.notifyWatchers()
 	return nil
 }
 
@@ -197,6 +378,13 @@ func (f *FSM) GetState() *ClusterState {
 		Indices:      make(map[string]*IndexMeta),
 		Nodes:        make(map[string]*NodeMeta),
 		ShardRouting: make(map[string]*ShardRouting),
+
+		StoredScripts:        make(map[string]*StoredScript),
+		IndexTemplates:       make(map[string]*IndexTemplate),
+		SnapshotRepositories: make(map[string]*SnapshotRepository),
+		Settings:             make(map[string]string),
+		Pipelines:            make(map[string]*StoredPipeline),
+		IndexPipelines:       make(map[string]map[string]string),
 	}
 
 	for k, v := range f.state.Indices {
@@ -208,10 +396,65 @@ func (f *FSM) GetState() *ClusterState {
 	for k, v := range f.state.ShardRouting {
 		stateCopy.ShardRouting[k] = v
 	}
+	for k, v := range f.state.StoredScripts {
+		stateCopy.StoredScripts[k] = v
+	}
+	for k, v := range f.state.IndexTemplates {
+		stateCopy.IndexTemplates[k] = v
+	}
+	for k, v := range f.state.SnapshotRepositories {
+		stateCopy.SnapshotRepositories[k] = v
+	}
+	for k, v := range f.state.Settings {
+		stateCopy.Settings[k] = v
+	}
+	for k, v := range f.state.Pipelines {
+		stateCopy.Pipelines[k] = v
+	}
+	for index, pipelines := range f.state.IndexPipelines {
+		// Associations are updated in place, so the inner maps are copied
+		pipelinesCopy := make(map[string]string, len(pipelines))
+		for k, v := range pipelines {
+			pipelinesCopy[k] = v
+		}
+		stateCopy.IndexPipelines[index] = pipelinesCopy
+	}
 
 	return stateCopy
 }
 
+// Subscribe returns a channel that is signalled whenever the state changes,
+// and a function that cancels the subscription. Notifications are coalesced,
+// so subscribers should re-read the state with GetState when signalled.
+func (f *FSM) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	f.watchMu.Lock()
+	f.watchers[ch] = struct{}{}
+	f.watchMu.Unlock()
+
+	cancel := func() {
+		f.watchMu.Lock()
+		delete(f.watchers, ch)
+		f.watchMu.Unlock()
+	}
+	return ch, cancel
+}
+
+// notifyWatchers signals every subscriber without blocking
+func (f *FSM) notifyWatchers() {
+	f.watchMu.Lock()
+	defer f.watchMu.Unlock()
+
+	for ch := range f.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// A notification is already pending
+		}
+	}
+}
+
 // Command application methods
 
 func (f *FSM) applyCreateIndex(payload json.RawMessage) error {
@@ -239,6 +482,7 @@ func (f *FSM) applyDeleteIndex(payload json.RawMessage) error {
 	}
 
 	delete(f.state.Indices, req.IndexName)
+	delete(f.state.IndexPipelines, req.IndexName)
 	f.logger.Info("Deleted index", zap.String("index", req.IndexName))
 
 	return nil
@@ -327,8 +571,7 @@ func (f *FSM) applyAllocateShard(payload json.RawMessage) error {
 		return fmt.Errorf("failed to unmarshal shard: %w", err)
 	}
 
-	key := fmt.Sprintf("%s:%d", shard.IndexName, shard.ShardID)
-	f.state.ShardRouting[key] = &shard
+	f.state.ShardRouting[ShardRoutingKey(&shard)] = &shard
 	f.logger.Info("Allocated shard",
 		zap.String("index", shard.IndexName),
 		zap.Int32("shard_id", shard.ShardID),
@@ -346,8 +589,12 @@ func (f *FSM) applyDeallocateShard(payload json.RawMessage) error {
 		return fmt.Errorf("failed to unmarshal request: %w", err)
 	}
 
-	key := fmt.Sprintf("%s:%d", req.IndexName, req.ShardID)
-	delete(f.state.ShardRouting, key)
+	// Remove the primary and every replica of the shard
+	for key, shard := range f.state.ShardRouting {
+		if shard.IndexName == req.IndexName && shard.ShardID == req.ShardID {
+			delete(f.state.ShardRouting, key)
+		}
+	}
 	f.logger.Info("Deallocated shard",
 		zap.String("index", req.IndexName),
 		zap.Int32("shard_id", req.ShardID))
@@ -361,8 +608,7 @@ func (f *FSM) applyUpdateShard(payload json.RawMessage) error {
 		return fmt.Errorf("failed to unmarshal shard: %w", err)
 	}
 
-	key := fmt.Sprintf("%s:%d", shard.IndexName, shard.ShardID)
-	f.state.ShardRouting[key] = &shard
+	f.state.ShardRouting[ShardRoutingKey(&shard)] = &shard
 	f.logger.Info("Updated shard",
 		zap.String("index", shard.IndexName),
 		zap.Int32("shard_id", shard.ShardID))
@@ -370,6 +616,231 @@ func (f *FSM) applyUpdateShard(payload json.RawMessage) error {
 	return nil
 }
 
+func (f *FSM) applyRelocateShard(payload json.RawMessage) error {
+	var shard ShardRouting
+	if err := json.Unmarshal(payload, &shard); err != nil {
+		return fmt.Errorf("failed to unmarshal shard: %w", err)
+	}
+
+	key := ShardRoutingKey(&shard)
+	current, exists := f.state.ShardRouting[key]
+	if !exists || current.State != "relocating" || current.RelocatingNodeID == "" {
+		return fmt.Errorf("shard %s is not relocating", key)
+	}
+
+	// The copy on the target node replaces the relocating one
+	target := *current
+	target.NodeID = current.RelocatingNodeID
+	target.RelocatingNodeID = ""
+	target.State = "started"
+	target.Version = current.Version + 1
+
+	delete(f.state.ShardRouting, key)
+	f.state.ShardRouting[ShardRoutingKey(&target)] = &target
+	f.logger.Info("Relocated shard",
+		zap.String("index", target.IndexName),
+		zap.Int32("shard_id", target.ShardID),
+		zap.String("from", current.NodeID),
+		zap.String("to", target.NodeID))
+
+	return nil
+}
+
+// applyRemoveShard removes a single copy of a shard, unlike deallocate_shard
+// which removes every copy
+func (f *FSM) applyRemoveShard(payload json.RawMessage) error {
+	var shard ShardRouting
+	if err := json.Unmarshal(payload, &shard); err != nil {
+		return fmt.Errorf("failed to unmarshal shard: %w", err)
+	}
+
+	delete(f.state.ShardRouting, ShardRoutingKey(&shard))
+	f.logger.Info("Removed shard copy",
+		zap.String("index", shard.IndexName),
+		zap.Int32("shard_id", shard.ShardID),
+		zap.Bool("is_primary", shard.IsPrimary),
+		zap.String("node", shard.NodeID))
+
+	return nil
+}
+
+func (f *FSM) applyPutStoredScript(payload json.RawMessage) error {
+	var script StoredScript
+	if err := json.Unmarshal(payload, &script); err != nil {
+		return fmt.Errorf("failed to unmarshal stored script: %w", err)
+	}
+
+	// Snapshots taken before stored scripts existed restore without the map
+	if f.state.StoredScripts == nil {
+		f.state.StoredScripts = make(map[string]*StoredScript)
+	}
+
+	f.state.StoredScripts[script.ID] = &script
+	f.logger.Info("Stored script", zap.String("id", script.ID))
+
+	return nil
+}
+
+func (f *FSM) applyPutIndexTemplate(payload json.RawMessage) error {
+	var template IndexTemplate
+	if err := json.Unmarshal(payload, &template); err != nil {
+		return fmt.Errorf("failed to unmarshal index template: %w", err)
+	}
+
+	// Snapshots taken before index templates existed restore without the map
+	if f.state.IndexTemplates == nil {
+		f.state.IndexTemplates = make(map[string]*IndexTemplate)
+	}
+
+	f.state.IndexTemplates[template.Name] = &template
+	f.logger.Info("Stored index template",
+		zap.String("name", template.Name),
+		zap.Strings("index_patterns", template.IndexPatterns),
+		zap.Int64("priority", template.Priority))
+
+	return nil
+}
+
+func (f *FSM) applyDeleteIndexTemplate(payload json.RawMessage) error {
+	var template IndexTemplate
+	if err := json.Unmarshal(payload, &template); err != nil {
+		return fmt.Errorf("failed to unmarshal index template: %w", err)
+	}
+
+	if _, exists := f.state.IndexTemplates[template.Name]; !exists {
+		return fmt.Errorf("index template %s does not exist", template.Name)
+	}
+
+	delete(f.state.IndexTemplates, template.Name)
+	f.logger.Info("Deleted index template", zap.String("name", template.Name))
+
+	return nil
+}
+
+func (f *FSM) applyPutSnapshotRepository(payload json.RawMessage) error {
+	var repository SnapshotRepository
+	if err := json.Unmarshal(payload, &repository); err != nil {
+		return fmt.Errorf("failed to unmarshal snapshot repository: %w", err)
+	}
+
+	// Snapshots taken before snapshot repositories existed restore without
+	// the map
+	if f.state.SnapshotRepositories == nil {
+		f.state.SnapshotRepositories = make(map[string]*SnapshotRepository)
+	}
+
+	f.state.SnapshotRepositories[repository.Name] = &repository
+	f.logger.Info("Registered snapshot repository",
+		zap.String("name", repository.Name),
+		zap.String("type", repository.Type),
+		zap.String("location", repository.Location))
+
+	return nil
+}
+
+func (f *FSM) applyUpdateClusterSettings(payload json.RawMessage) error {
+	// A null value resets the setting to its default
+	var settings map[string]*string
+	if err := json.Unmarshal(payload, &settings); err != nil {
+		return fmt.Errorf("failed to unmarshal cluster settings: %w", err)
+	}
+
+	// Snapshots taken before cluster settings existed restore without the map
+	if f.state.Settings == nil {
+		f.state.Settings = make(map[string]string)
+	}
+
+	for key, value := range settings {
+		if value == nil {
+			delete(f.state.Settings, key)
+			continue
+		}
+		f.state.Settings[key] = *value
+	}
+	f.logger.Info("Updated cluster settings", zap.Int("count", len(settings)))
+
+	return nil
+}
+
+func (f *FSM) applyPutPipeline(payload json.RawMessage) error {
+	var pipeline StoredPipeline
+	if err := json.Unmarshal(payload, &pipeline); err != nil {
+		return fmt.Errorf("failed to unmarshal pipeline: %w", err)
+	}
+
+	// Snapshots taken before pipelines were stored restore without the map
+	if f.state.Pipelines == nil {
+		f.state.Pipelines = make(map[string]*StoredPipeline)
+	}
+
+	f.state.Pipelines[pipeline.Name] = &pipeline
+	f.logger.Info("Stored pipeline", zap.String("name", pipeline.Name))
+
+	return nil
+}
+
+func (f *FSM) applyDeletePipeline(payload json.RawMessage) error {
+	var pipeline StoredPipeline
+	if err := json.Unmarshal(payload, &pipeline); err != nil {
+		return fmt.Errorf("failed to unmarshal pipeline: %w", err)
+	}
+
+	if _, exists := f.state.Pipelines[pipeline.Name]; !exists {
+		return fmt.Errorf("pipeline %s does not exist", pipeline.Name)
+	}
+	for index, pipelines := range f.state.IndexPipelines {
+		for pipelineType, name := range pipelines {
+			if name == pipeline.Name {
+				return fmt.Errorf("pipeline %s is still associated with index %s for type %s",
+					pipeline.Name, index, pipelineType)
+			}
+		}
+	}
+
+	delete(f.state.Pipelines, pipeline.Name)
+	f.logger.Info("Deleted pipeline", zap.String("name", pipeline.Name))
+
+	return nil
+}
+
+func (f *FSM) applySetIndexPipeline(payload json.RawMessage) error {
+	var association IndexPipeline
+	if err := json.Unmarshal(payload, &association); err != nil {
+		return fmt.Errorf("failed to unmarshal index pipeline: %w", err)
+	}
+
+	if association.Pipeline == "" {
+		delete(f.state.IndexPipelines[association.IndexName], association.Type)
+		if len(f.state.IndexPipelines[association.IndexName]) == 0 {
+			delete(f.state.IndexPipelines, association.IndexName)
+		}
+		f.logger.Info("Removed index pipeline",
+			zap.String("index", association.IndexName),
+			zap.String("type", association.Type))
+		return nil
+	}
+
+	if _, exists := f.state.Pipelines[association.Pipeline]; !exists {
+		return fmt.Errorf("pipeline %s does not exist", association.Pipeline)
+	}
+
+	// Snapshots taken before pipelines were stored restore without the map
+	if f.state.IndexPipelines == nil {
+		f.state.IndexPipelines = make(map[string]map[string]string)
+	}
+	if f.state.IndexPipelines[association.IndexName] == nil {
+		f.state.IndexPipelines[association.IndexName] = make(map[string]string)
+	}
+
+	f.state.IndexPipelines[association.IndexName][association.Type] = association.Pipeline
+	f.logger.Info("Set index pipeline",
+		zap.String("index", association.IndexName),
+		zap.String("type", association.Type),
+		zap.String("pipeline", association.Pipeline))
+
+	return nil
+}
+
 // fsmSnapshot implements raft.FSMSnapshot
 type fsmSnapshot struct {
 	state *ClusterState
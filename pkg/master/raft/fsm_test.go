@@ -237,6 +237,134 @@ func TestFSMApplyAllocateShard(t *testing.T) {
 	}
 }
 
+func TestFSMApplyPutStoredScript(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	fsm := NewFSM(logger)
+
+	apply := func(script *StoredScript) {
+		payload, err := json.Marshal(script)
+		if err != nil {
+			t.Fatalf("Failed to marshal script: %v", err)
+		}
+		cmdData, err := json.Marshal(Command{Type: CommandPutStoredScript, Payload: payload})
+		if err != nil {
+			t.Fatalf("Failed to marshal command: %v", err)
+		}
+		if result := fsm.Apply(&raft.Log{Type: raft.LogCommand, Data: cmdData}); result != nil {
+			if err, ok := result.(error); ok {
+				t.Fatalf("Apply returned error: %v", err)
+			}
+		}
+	}
+
+	apply(&StoredScript{ID: "by_title", Lang: "mustache", Source: `{"query": {"match": {"title": "{{q}}"}}}`})
+	// Storing under the same id replaces the script
+	apply(&StoredScript{ID: "by_title", Lang: "mustache", Source: `{"query": {"term": {"title": "{{q}}"}}}`})
+
+	state := fsm.GetState()
+	script, ok := state.StoredScripts["by_title"]
+	if !ok {
+		t.Fatal("Stored script not found in state")
+	}
+	if script.Source != `{"query": {"term": {"title": "{{q}}"}}}` {
+		t.Errorf("Expected replaced script source, got %s", script.Source)
+	}
+
+	snapshot, err := fsm.Snapshot()
+	if err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+	if len(snapshot.(*fsmSnapshot).state.StoredScripts) != 1 {
+		t.Error("Expected the stored script in the snapshot")
+	}
+}
+
+func TestFSMApplyIndexTemplates(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	fsm := NewFSM(logger)
+
+	apply := func(cmdType CommandType, template *IndexTemplate) interface{} {
+		payload, err := json.Marshal(template)
+		if err != nil {
+			t.Fatalf("Failed to marshal template: %v", err)
+		}
+		cmdData, err := json.Marshal(Command{Type: cmdType, Payload: payload})
+		if err != nil {
+			t.Fatalf("Failed to marshal command: %v", err)
+		}
+		return fsm.Apply(&raft.Log{Type: raft.LogCommand, Data: cmdData})
+	}
+
+	if result := apply(CommandPutIndexTemplate, &IndexTemplate{
+		Name:          "logs",
+		IndexPatterns: []string{"logs-*"},
+		Priority:      5,
+		Template:      `{"settings":{"index":{"number_of_shards":3}}}`,
+	}); result != nil {
+		t.Fatalf("Apply returned error: %v", result)
+	}
+
+	state := fsm.GetState()
+	template, ok := state.IndexTemplates["logs"]
+	if !ok {
+		t.Fatal("Index template not found in state")
+	}
+	if template.Priority != 5 || len(template.IndexPatterns) != 1 || template.IndexPatterns[0] != "logs-*" {
+		t.Errorf("Unexpected index template: %+v", template)
+	}
+
+	snapshot, err := fsm.Snapshot()
+	if err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+	if len(snapshot.(*fsmSnapshot).state.IndexTemplates) != 1 {
+		t.Error("Expected the index template in the snapshot")
+	}
+
+	if result := apply(CommandDeleteIndexTemplate, &IndexTemplate{Name: "logs"}); result != nil {
+		t.Fatalf("Apply returned error: %v", result)
+	}
+	if _, ok := fsm.GetState().IndexTemplates["logs"]; ok {
+		t.Error("Index template should be deleted")
+	}
+	if result := apply(CommandDeleteIndexTemplate, &IndexTemplate{Name: "logs"}); result == nil {
+		t.Error("Deleting a missing index template should fail")
+	}
+}
+
+func TestFSMApplySnapshotRepository(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	fsm := NewFSM(logger)
+
+	payload, err := json.Marshal(&SnapshotRepository{Name: "backups", Type: "fs", Location: "/mnt/backups"})
+	if err != nil {
+		t.Fatalf("Failed to marshal repository: %v", err)
+	}
+	cmdData, err := json.Marshal(Command{Type: CommandPutSnapshotRepository, Payload: payload})
+	if err != nil {
+		t.Fatalf("Failed to marshal command: %v", err)
+	}
+	if result := fsm.Apply(&raft.Log{Type: raft.LogCommand, Data: cmdData}); result != nil {
+		t.Fatalf("Apply returned error: %v", result)
+	}
+
+	repository, ok := fsm.GetState().SnapshotRepositories["backups"]
+	if !ok {
+		t.Fatal("Snapshot repository not found in state")
+	}
+	if repository.Type != "fs" || repository.Location != "/mnt/backups" {
+		t.Errorf("Unexpected snapshot repository: %+v", repository)
+	}
+
+	snapshot, err := fsm.Snapshot()
+	if err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+	if len(snapshot.(*fsmSnapshot).state.SnapshotRepositories) != 1 {
+		t.Error("Expected the snapshot repository in the snapshot")
+	}
+}
+
 func TestFSMSnapshot(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	fsm := NewFSM(logger)
@@ -455,6 +583,75 @@ func TestFSMStateVersionIncrement(t *testing.T) {
 	}
 }
 
+func TestFSMReplicaShardRouting(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	fsm := NewFSM(logger)
+
+	apply := func(index uint64, cmdType CommandType, payload interface{}) {
+		data, _ := json.Marshal(payload)
+		cmdData, _ := json.Marshal(Command{Type: cmdType, Payload: data})
+		if result := fsm.Apply(&raft.Log{Index: index, Term: 1, Type: raft.LogCommand, Data: cmdData}); result != nil {
+			t.Fatalf("Apply returned error: %v", result)
+		}
+	}
+
+	apply(1, CommandAllocateShard, &ShardRouting{IndexName: "products", ShardID: 0, IsPrimary: true, NodeID: "node-1"})
+	apply(2, CommandAllocateShard, &ShardRouting{IndexName: "products", ShardID: 0, IsPrimary: false, NodeID: "node-2"})
+
+	// The replica must not overwrite the primary
+	state := fsm.GetState()
+	if len(state.ShardRouting) != 2 {
+		t.Fatalf("Expected 2 shard copies, got %d", len(state.ShardRouting))
+	}
+	if primary := state.ShardRouting["products:0"]; primary == nil || primary.NodeID != "node-1" {
+		t.Errorf("Expected primary on node-1, got %+v", primary)
+	}
+	if replica := state.ShardRouting["products:0:replica:node-2"]; replica == nil || replica.IsPrimary {
+		t.Errorf("Expected replica on node-2, got %+v", replica)
+	}
+
+	// Deallocating the shard removes every copy
+	apply(3, CommandDeallocateShard, map[string]interface{}{"index_name": "products", "shard_id": 0})
+	if n := len(fsm.GetState().ShardRouting); n != 0 {
+		t.Errorf("Expected no shard copies after deallocation, got %d", n)
+	}
+}
+
+func TestFSMSubscribe(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	fsm := NewFSM(logger)
+
+	updates, cancel := fsm.Subscribe()
+
+	node := &NodeMeta{NodeID: "node-1", NodeType: "data"}
+	payload, _ := json.Marshal(node)
+	cmdData, _ := json.Marshal(Command{Type: CommandRegisterNode, Payload: payload})
+
+	// Two changes before the subscriber reads coalesce into one notification
+	fsm.Apply(&raft.Log{Index: 1, Term: 1, Type: raft.LogCommand, Data: cmdData})
+	fsm.Apply(&raft.Log{Index: 2, Term: 1, Type: raft.LogCommand, Data: cmdData})
+
+	select {
+	case <-updates:
+	default:
+		t.Fatal("Expected a notification after Apply")
+	}
+	select {
+	case <-updates:
+		t.Fatal("Expected notifications to be coalesced")
+	default:
+	}
+
+	// No notifications after cancelling
+	cancel()
+	fsm.Apply(&raft.Log{Index: 3, Term: 1, Type: raft.LogCommand, Data: cmdData})
+	select {
+	case <-updates:
+		t.Fatal("Unexpected notification after cancel")
+	default:
+	}
+}
+
 // Mock ReadCloser for testing
 type mockReadCloser struct {
 	data []byte
@@ -473,3 +670,149 @@ func (m *mockReadCloser) Read(p []byte) (n int, err error) {
 func (m *mockReadCloser) Close() error {
 	return nil
 }
+
+func TestFSMApplyRelocateShard(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	fsm := NewFSM(logger)
+
+	apply := func(cmdType CommandType, shard *ShardRouting) {
+		payload, err := json.Marshal(shard)
+		if err != nil {
+			t.Fatalf("Failed to marshal shard: %v", err)
+		}
+		cmdData, err := json.Marshal(Command{Type: cmdType, Payload: payload})
+		if err != nil {
+			t.Fatalf("Failed to marshal command: %v", err)
+		}
+		if result := fsm.Apply(&raft.Log{Type: raft.LogCommand, Data: cmdData}); result != nil {
+			if err, ok := result.(error); ok {
+				t.Fatalf("Apply returned error: %v", err)
+			}
+		}
+	}
+
+	replica := &ShardRouting{IndexName: "products", ShardID: 0, NodeID: "node-1", State: "started", Version: 2}
+	apply(CommandAllocateShard, replica)
+
+	relocating := *replica
+	relocating.State = "relocating"
+	relocating.RelocatingNodeID = "node-2"
+	apply(CommandUpdateShard, &relocating)
+	apply(CommandRelocateShard, &relocating)
+
+	// The replica is re-keyed under its new node and started there
+	state := fsm.GetState()
+	if _, exists := state.ShardRouting["products:0:replica:node-1"]; exists {
+		t.Error("Expected the source copy to be removed")
+	}
+	moved, exists := state.ShardRouting["products:0:replica:node-2"]
+	if !exists {
+		t.Fatal("Expected the shard copy on node-2")
+	}
+	if moved.State != "started" || moved.RelocatingNodeID != "" || moved.Version != 3 {
+		t.Errorf("Unexpected relocated shard: %+v", moved)
+	}
+
+	// A shard that is not relocating cannot be moved
+	payload, _ := json.Marshal(moved)
+	cmdData, _ := json.Marshal(Command{Type: CommandRelocateShard, Payload: payload})
+	if _, ok := fsm.Apply(&raft.Log{Type: raft.LogCommand, Data: cmdData}).(error); !ok {
+		t.Error("Expected an error relocating a started shard")
+	}
+}
+
+func TestFSMApplyUpdateClusterSettings(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	fsm := NewFSM(logger)
+
+	apply := func(payload string) {
+		cmdData, err := json.Marshal(Command{Type: CommandUpdateClusterSettings, Payload: json.RawMessage(payload)})
+		if err != nil {
+			t.Fatalf("Failed to marshal command: %v", err)
+		}
+		if result := fsm.Apply(&raft.Log{Type: raft.LogCommand, Data: cmdData}); result != nil {
+			if err, ok := result.(error); ok {
+				t.Fatalf("Apply returned error: %v", err)
+			}
+		}
+	}
+
+	apply(`{"cluster.routing.allocation.enable": "none", "cluster.routing.allocation.cluster_concurrent_rebalance": "4"}`)
+	// A null value resets a setting
+	apply(`{"cluster.routing.allocation.enable": null}`)
+
+	settings := fsm.GetState().Settings
+	if _, exists := settings["cluster.routing.allocation.enable"]; exists {
+		t.Error("Expected the reset setting to be removed")
+	}
+	if settings["cluster.routing.allocation.cluster_concurrent_rebalance"] != "4" {
+		t.Errorf("Expected concurrent rebalance 4, got %v", settings)
+	}
+}
+
+func TestFSMApplyPipelines(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	fsm := NewFSM(logger)
+
+	apply := func(cmdType CommandType, payload interface{}) {
+		payloadData, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatalf("Failed to marshal payload: %v", err)
+		}
+		cmdData, err := json.Marshal(Command{Type: cmdType, Payload: payloadData})
+		if err != nil {
+			t.Fatalf("Failed to marshal command: %v", err)
+		}
+		if result := fsm.Apply(&raft.Log{Type: raft.LogCommand, Data: cmdData}); result != nil {
+			if err, ok := result.(error); ok {
+				t.Fatalf("Apply returned error: %v", err)
+			}
+		}
+	}
+
+	apply(CommandCreateIndex, &IndexMeta{Name: "products", NumShards: 1})
+	apply(CommandPutPipeline, &StoredPipeline{Name: "rewrite", Definition: `{"name":"rewrite"}`})
+	apply(CommandPutPipeline, &StoredPipeline{Name: "unused", Definition: `{"name":"unused"}`})
+	apply(CommandSetIndexPipeline, &IndexPipeline{IndexName: "products", Type: "query", Pipeline: "rewrite"})
+	apply(CommandDeletePipeline, &StoredPipeline{Name: "unused"})
+
+	state := fsm.GetState()
+	if _, exists := state.Pipelines["unused"]; exists {
+		t.Error("Expected the deleted pipeline to be removed")
+	}
+	if state.Pipelines["rewrite"].Definition != `{"name":"rewrite"}` {
+		t.Errorf("Expected the rewrite pipeline definition, got %v", state.Pipelines["rewrite"])
+	}
+	if state.IndexPipelines["products"]["query"] != "rewrite" {
+		t.Errorf("Expected products to use the rewrite query pipeline, got %v", state.IndexPipelines)
+	}
+
+	// Pipelines survive a snapshot and restore, as on a master restart
+	snapshot, err := fsm.Snapshot()
+	if err != nil {
+		t.Fatalf("Failed to snapshot: %v", err)
+	}
+	data, err := json.Marshal(snapshot.(*fsmSnapshot).state)
+	if err != nil {
+		t.Fatalf("Failed to marshal snapshot: %v", err)
+	}
+	restored := NewFSM(logger)
+	if err := restored.Restore(&mockReadCloser{data: data}); err != nil {
+		t.Fatalf("Failed to restore snapshot: %v", err)
+	}
+	if restored.GetState().IndexPipelines["products"]["query"] != "rewrite" {
+		t.Errorf("Expected the association to be restored, got %v", restored.GetState().IndexPipelines)
+	}
+
+	// Deleting the index drops its associations
+	apply(CommandDeleteIndex, map[string]string{"index_name": "products"})
+	if _, exists := fsm.GetState().IndexPipelines["products"]; exists {
+		t.Error("Expected the deleted index's associations to be removed")
+	}
+
+	apply(CommandSetIndexPipeline, &IndexPipeline{IndexName: "orders", Type: "query", Pipeline: "rewrite"})
+	apply(CommandSetIndexPipeline, &IndexPipeline{IndexName: "orders", Type: "query"})
+	if _, exists := fsm.GetState().IndexPipelines["orders"]; exists {
+		t.Error("Expected an empty pipeline to remove the association")
+	}
+}
@@ -0,0 +1,356 @@
+package master
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/common/requestid"
+	"github.com/quidditch/quidditch/pkg/common/tracing"
+	"github.com/quidditch/quidditch/pkg/master/allocation"
+	"github.com/quidditch/quidditch/pkg/master/raft"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// diskUsageTolerance is the change in a node's disk usage, in percentage
+// points, below which the master does not record the new value
+const diskUsageTolerance = 0.5
+
+// shardSizeTolerance is the relative change in a shard's size below which
+// the master does not record the new size
+const shardSizeTolerance = 0.05
+
+// relocationBatchSize is the number of documents copied per request when a
+// shard is relocated
+const relocationBatchSize = 500
+
+// runRebalancer periodically rebalances shards while this node is the
+// leader, until ctx is cancelled
+func (m *MasterNode) runRebalancer(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !m.raftNode.IsLeader() {
+				continue
+			}
+			m.refreshDiskUsage(ctx)
+			if _, err := m.RebalanceShards(ctx, false); err != nil {
+				m.logger.Warn("Shard rebalancing failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// refreshDiskUsage records the disk usage each healthy data node reports in
+// its node stats, along with the size of its disk and of each of its
+// shards, which the allocator weighs against the disk watermarks
+func (m *MasterNode) refreshDiskUsage(ctx context.Context) {
+	for _, node := range m.fsm.GetState().Nodes {
+		if node.NodeType != "data" || node.Status != "healthy" {
+			continue
+		}
+
+		stats, err := m.nodeDiskStats(ctx, node.NodeID)
+		if err != nil {
+			m.logger.Warn("Failed to get node disk usage", zap.String("node_id", node.NodeID), zap.Error(err))
+			continue
+		}
+		shardSizes := make(map[string]int64, len(stats.Shards))
+		for _, shard := range stats.Shards {
+			shardSizes[fmt.Sprintf("%s:%d", shard.IndexName, shard.ShardId)] = shard.SizeBytes
+		}
+		if math.Abs(stats.DiskUsagePercent-node.DiskUsagePercent) < diskUsageTolerance &&
+			stats.DiskTotalBytes == node.DiskTotalBytes && !shardSizesChanged(node.ShardSizes, shardSizes) {
+			continue
+		}
+
+		updated := *node
+		updated.DiskUsagePercent = stats.DiskUsagePercent
+		updated.DiskTotalBytes = stats.DiskTotalBytes
+		updated.ShardSizes = shardSizes
+		payload, err := json.Marshal(&updated)
+		if err != nil {
+			continue
+		}
+		if err := m.raftNode.Apply(raft.Command{Type: raft.CommandUpdateNode, Payload: payload}, 5*time.Second); err != nil {
+			m.logger.Warn("Failed to record node disk usage", zap.String("node_id", node.NodeID), zap.Error(err))
+		}
+	}
+}
+
+// shardSizesChanged reports whether a node's shards differ from those last
+// recorded, or any of them changed size by more than shardSizeTolerance
+func shardSizesChanged(recorded, reported map[string]int64) bool {
+	if len(recorded) != len(reported) {
+		return true
+	}
+	for key, size := range reported {
+		previous, ok := recorded[key]
+		if !ok {
+			return true
+		}
+		if math.Abs(float64(size-previous)) > shardSizeTolerance*float64(max(previous, 1)) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeDiskStats asks a data node for its disk usage and shard sizes
+func (m *MasterNode) nodeDiskStats(ctx context.Context, nodeID string) (*pb.DataNodeStats, error) {
+	conn, err := m.dialDataNode(nodeID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	statsCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return pb.NewDataServiceClient(conn).GetNodeStats(statsCtx, &pb.GetNodeStatsRequest{IncludeShards: true})
+}
+
+// RebalanceShards plans relocations that even out the shards per data node
+// and, unless dryRun is set, starts them. Each relocating shard is marked
+// relocating, copied to its target node in the background, and then moved
+// there in the routing table.
+func (m *MasterNode) RebalanceShards(ctx context.Context, dryRun bool) ([]allocation.RebalanceDecision, error) {
+	if !m.raftNode.IsLeader() {
+		return nil, fmt.Errorf("not the leader, redirect to %s", m.raftNode.Leader())
+	}
+
+	state := m.fsm.GetState()
+	decisions, err := allocation.NewAllocator(m.logger).RebalanceShards(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan rebalancing: %w", err)
+	}
+	if dryRun {
+		return decisions, nil
+	}
+
+	started := make([]allocation.RebalanceDecision, 0, len(decisions))
+	for _, decision := range decisions {
+		shard, exists := state.ShardRouting[raft.ShardRoutingKey(&raft.ShardRouting{
+			IndexName: decision.IndexName,
+			ShardID:   decision.ShardID,
+			IsPrimary: decision.IsPrimary,
+			NodeID:    decision.FromNode,
+		})]
+		if !exists {
+			continue
+		}
+
+		relocating := *shard
+		relocating.State = "relocating"
+		relocating.RelocatingNodeID = decision.ToNode
+		relocating.Version = shard.Version + 1
+		if err := m.applyShardCommand(raft.CommandUpdateShard, &relocating); err != nil {
+			m.logger.Error("Failed to start shard relocation",
+				zap.String("index", decision.IndexName),
+				zap.Int32("shard_id", decision.ShardID),
+				zap.Error(err))
+			continue
+		}
+
+		m.logger.Info("Relocating shard",
+			zap.String("index", decision.IndexName),
+			zap.Int32("shard_id", decision.ShardID),
+			zap.String("from", decision.FromNode),
+			zap.String("to", decision.ToNode))
+
+		go m.relocateShard(relocating)
+		started = append(started, decision)
+	}
+
+	return started, nil
+}
+
+// relocateShard copies a relocating shard to its target node and moves its
+// routing there, deleting the source copy. If the copy fails the shard goes
+// back to started on its source node. Writes to the shard are rejected
+// while it relocates; documents that reached the source anyway, routed by
+// a stale routing table, are copied once more before the source is deleted.
+func (m *MasterNode) relocateShard(shard raft.ShardRouting) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	copied, err := m.copyShard(ctx, shard)
+	if err != nil {
+		m.logger.Error("Shard relocation failed",
+			zap.String("index", shard.IndexName),
+			zap.Int32("shard_id", shard.ShardID),
+			zap.String("from", shard.NodeID),
+			zap.String("to", shard.RelocatingNodeID),
+			zap.Error(err))
+
+		current, exists := m.fsm.GetState().ShardRouting[raft.ShardRoutingKey(&shard)]
+		if !exists || current.State != "relocating" {
+			return
+		}
+		reverted := *current
+		reverted.State = "started"
+		reverted.RelocatingNodeID = ""
+		reverted.Version = current.Version + 1
+		if err := m.applyShardCommand(raft.CommandUpdateShard, &reverted); err != nil {
+			m.logger.Error("Failed to cancel shard relocation",
+				zap.String("index", shard.IndexName),
+				zap.Int32("shard_id", shard.ShardID),
+				zap.Error(err))
+		}
+		return
+	}
+
+	if err := m.applyShardCommand(raft.CommandRelocateShard, &shard); err != nil {
+		m.logger.Error("Failed to complete shard relocation",
+			zap.String("index", shard.IndexName),
+			zap.Int32("shard_id", shard.ShardID),
+			zap.Error(err))
+		return
+	}
+
+	m.logger.Info("Relocated shard",
+		zap.String("index", shard.IndexName),
+		zap.Int32("shard_id", shard.ShardID),
+		zap.String("from", shard.NodeID),
+		zap.String("to", shard.RelocatingNodeID))
+
+	// The source copy is no longer routed to
+	conn, err := m.dialDataNode(shard.NodeID)
+	if err != nil {
+		m.logger.Warn("Failed to connect to relocation source", zap.String("node_id", shard.NodeID), zap.Error(err))
+		return
+	}
+	defer conn.Close()
+	source := pb.NewDataServiceClient(conn)
+
+	targetConn, err := m.dialDataNode(shard.RelocatingNodeID)
+	if err != nil {
+		m.logger.Warn("Failed to connect to relocation target, keeping source copy",
+			zap.String("node_id", shard.RelocatingNodeID), zap.Error(err))
+		return
+	}
+	defer targetConn.Close()
+
+	if _, err := copyDocuments(ctx, source, pb.NewDataServiceClient(targetConn), shard, copied); err != nil {
+		m.logger.Warn("Failed to copy late writes to relocation target, keeping source copy",
+			zap.String("index", shard.IndexName),
+			zap.Int32("shard_id", shard.ShardID),
+			zap.Error(err))
+		return
+	}
+
+	if _, err := source.DeleteShard(ctx, &pb.DeleteShardRequest{
+		IndexName: shard.IndexName,
+		ShardId:   shard.ShardID,
+	}); err != nil {
+		m.logger.Warn("Failed to delete relocated shard from source",
+			zap.String("node_id", shard.NodeID),
+			zap.String("index", shard.IndexName),
+			zap.Int32("shard_id", shard.ShardID),
+			zap.Error(err))
+	}
+}
+
+// copyShard creates a relocating shard on its target node and copies the
+// source documents into it in index order, returning how many it copied
+func (m *MasterNode) copyShard(ctx context.Context, shard raft.ShardRouting) (int32, error) {
+	sourceConn, err := m.dialDataNode(shard.NodeID)
+	if err != nil {
+		return 0, err
+	}
+	defer sourceConn.Close()
+
+	targetConn, err := m.dialDataNode(shard.RelocatingNodeID)
+	if err != nil {
+		return 0, err
+	}
+	defer targetConn.Close()
+
+	source := pb.NewDataServiceClient(sourceConn)
+	target := pb.NewDataServiceClient(targetConn)
+
+	req := &pb.CreateShardRequest{
+		IndexName: shard.IndexName,
+		ShardId:   shard.ShardID,
+		IsPrimary: shard.IsPrimary,
+	}
+	if index, exists := m.fsm.GetState().Indices[shard.IndexName]; exists {
+		req.Settings = index.Settings
+	}
+	if _, err := target.CreateShard(ctx, req); err != nil {
+		return 0, fmt.Errorf("failed to create shard on %s: %w", shard.RelocatingNodeID, err)
+	}
+
+	return copyDocuments(ctx, source, target, shard, 0)
+}
+
+// copyDocuments copies the documents of a shard from its source to its
+// relocation target, starting at document position from, and returns the
+// position after the last one copied
+func copyDocuments(ctx context.Context, source, target pb.DataServiceClient, shard raft.ShardRouting, from int32) (int32, error) {
+	for {
+		page, err := source.ScanShard(ctx, &pb.ScanShardRequest{
+			IndexName: shard.IndexName,
+			ShardId:   shard.ShardID,
+			From:      from,
+			Size:      relocationBatchSize,
+		})
+		if err != nil {
+			return from, fmt.Errorf("failed to read shard from %s: %w", shard.NodeID, err)
+		}
+		if len(page.Documents) == 0 {
+			return from, nil
+		}
+
+		resp, err := target.BulkIndex(ctx, &pb.BulkIndexRequest{
+			IndexName: shard.IndexName,
+			ShardId:   shard.ShardID,
+			Items:     page.Documents,
+		})
+		if err != nil {
+			return from, fmt.Errorf("failed to copy documents to %s: %w", shard.RelocatingNodeID, err)
+		}
+		if resp.HasErrors {
+			return from, fmt.Errorf("failed to copy documents to %s", shard.RelocatingNodeID)
+		}
+		from += int32(len(page.Documents))
+
+		if len(page.Documents) < relocationBatchSize {
+			return from, nil
+		}
+	}
+}
+
+// applyShardCommand applies a shard routing command through Raft
+func (m *MasterNode) applyShardCommand(cmdType raft.CommandType, shard *raft.ShardRouting) error {
+	payload, err := json.Marshal(shard)
+	if err != nil {
+		return fmt.Errorf("failed to marshal shard routing: %w", err)
+	}
+
+	return m.raftNode.Apply(raft.Command{Type: cmdType, Payload: payload}, 5*time.Second)
+}
+
+// dialDataNode connects to a data node in the cluster state
+func (m *MasterNode) dialDataNode(nodeID string) (*grpc.ClientConn, error) {
+	node, exists := m.fsm.GetState().Nodes[nodeID]
+	if !exists {
+		return nil, fmt.Errorf("node %s not found in cluster state", nodeID)
+	}
+
+	addr := fmt.Sprintf("%s:%d", node.BindAddr, node.GRPCPort)
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(m.transportCredentials()), tracing.ClientOption(), requestid.ClientOption())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to data node %s at %s: %w", nodeID, addr, err)
+	}
+	return conn, nil
+}
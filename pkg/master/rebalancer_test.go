@@ -0,0 +1,345 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/quidditch/quidditch/pkg/common/config"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/master/raft"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// fakeDataNode is a data node that keeps its shards' documents in memory
+type fakeDataNode struct {
+	pb.UnimplementedDataServiceServer
+
+	mu        sync.Mutex
+	shards    map[string][]*pb.BulkIndexItem // "index:shard" -> documents
+	settings  map[string]map[string]string   // index -> settings last sent by UpdateShardSettings
+	diskUsage float64
+	port      int32
+}
+
+func startFakeDataNode(t *testing.T) *fakeDataNode {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	node := &fakeDataNode{
+		shards:   make(map[string][]*pb.BulkIndexItem),
+		settings: make(map[string]map[string]string),
+		port:     int32(lis.Addr().(*net.TCPAddr).Port),
+	}
+
+	server := grpc.NewServer()
+	pb.RegisterDataServiceServer(server, node)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	return node
+}
+
+func (n *fakeDataNode) CreateShard(ctx context.Context, req *pb.CreateShardRequest) (*pb.CreateShardResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	key := fmt.Sprintf("%s:%d", req.IndexName, req.ShardId)
+	n.shards[key] = nil
+	return &pb.CreateShardResponse{Acknowledged: true, ShardKey: key}, nil
+}
+
+func (n *fakeDataNode) DeleteShard(ctx context.Context, req *pb.DeleteShardRequest) (*pb.DeleteShardResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.shards, fmt.Sprintf("%s:%d", req.IndexName, req.ShardId))
+	return &pb.DeleteShardResponse{Acknowledged: true}, nil
+}
+
+func (n *fakeDataNode) UpdateShardSettings(ctx context.Context, req *pb.UpdateShardSettingsRequest) (*pb.UpdateShardSettingsResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.settings[req.IndexName] = req.Settings
+	return &pb.UpdateShardSettingsResponse{Acknowledged: true, ShardsUpdated: 1}, nil
+}
+
+func (n *fakeDataNode) ScanShard(ctx context.Context, req *pb.ScanShardRequest) (*pb.ScanShardResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	docs, exists := n.shards[fmt.Sprintf("%s:%d", req.IndexName, req.ShardId)]
+	if !exists {
+		return nil, status.Error(codes.NotFound, "shard not found")
+	}
+	from := min(int(req.From), len(docs))
+	to := min(from+int(req.Size), len(docs))
+	return &pb.ScanShardResponse{Documents: docs[from:to]}, nil
+}
+
+func (n *fakeDataNode) BulkIndex(ctx context.Context, req *pb.BulkIndexRequest) (*pb.BulkIndexResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	key := fmt.Sprintf("%s:%d", req.IndexName, req.ShardId)
+	if _, exists := n.shards[key]; !exists {
+		return nil, status.Error(codes.NotFound, "shard not found")
+	}
+	n.shards[key] = append(n.shards[key], req.Items...)
+	return &pb.BulkIndexResponse{}, nil
+}
+
+func (n *fakeDataNode) GetNodeStats(ctx context.Context, req *pb.GetNodeStatsRequest) (*pb.DataNodeStats, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return &pb.DataNodeStats{TotalShards: int32(len(n.shards)), DiskUsagePercent: n.diskUsage}, nil
+}
+
+// documents returns the documents of a shard, and whether the node holds it
+func (n *fakeDataNode) documents(indexName string, shardID int32) ([]*pb.BulkIndexItem, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	docs, exists := n.shards[fmt.Sprintf("%s:%d", indexName, shardID)]
+	return docs, exists
+}
+
+func TestMasterNodeRebalanceOnNodeJoin(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	logger, _ := zap.NewDevelopment()
+	cfg := &config.MasterConfig{
+		NodeID:            "test-master",
+		BindAddr:          "127.0.0.1",
+		RaftPort:          19308,
+		GRPCPort:          19309,
+		DataDir:           t.TempDir(),
+		Peers:             []string{},
+		RebalanceInterval: 100 * time.Millisecond,
+	}
+
+	node, err := NewMasterNode(cfg, logger)
+	if err != nil {
+		t.Fatalf("Failed to create master node: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := node.Start(ctx); err != nil {
+		t.Fatalf("Failed to start master node: %v", err)
+	}
+	defer node.Stop(ctx)
+
+	// Wait for leader election
+	time.Sleep(3 * time.Second)
+
+	if !node.IsLeader() {
+		t.Skip("Node did not become leader, skipping test")
+	}
+
+	// waitFor polls the cluster state until cond holds
+	waitFor := func(what string, cond func(nodeShards map[string]int, started int) bool) {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for {
+			state, _ := node.GetClusterState(ctx)
+			nodeShards := make(map[string]int)
+			started := 0
+			for _, shard := range state.ShardRouting {
+				nodeShards[shard.NodeID]++
+				if shard.State == "started" {
+					started++
+				}
+			}
+			if cond(nodeShards, started) {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for %s: %v", what, nodeShards)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+
+	// All shards start on the only data node
+	data1 := startFakeDataNode(t)
+	if err := node.RegisterNode(ctx, "data-1", "data", "127.0.0.1", data1.port); err != nil {
+		t.Fatalf("Failed to register node: %v", err)
+	}
+	if err := node.CreateIndex(ctx, "products", 4, 0); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	waitFor("shards to start", func(nodeShards map[string]int, started int) bool {
+		return nodeShards["data-1"] == 4 && started == 4
+	})
+
+	for shardID := int32(0); shardID < 4; shardID++ {
+		if _, err := data1.BulkIndex(ctx, &pb.BulkIndexRequest{
+			IndexName: "products",
+			ShardId:   shardID,
+			Items:     []*pb.BulkIndexItem{{DocId: fmt.Sprintf("doc-%d", shardID)}},
+		}); err != nil {
+			t.Fatalf("Failed to seed shard %d: %v", shardID, err)
+		}
+	}
+
+	// A second data node joins and takes half of the shards
+	data2 := startFakeDataNode(t)
+	if err := node.RegisterNode(ctx, "data-2", "data", "127.0.0.1", data2.port); err != nil {
+		t.Fatalf("Failed to register node: %v", err)
+	}
+	waitFor("shards to rebalance", func(nodeShards map[string]int, started int) bool {
+		return nodeShards["data-1"] == 2 && nodeShards["data-2"] == 2 && started == 4
+	})
+
+	// Relocated shards carry their documents and leave the source node
+	state, _ := node.GetClusterState(ctx)
+	for _, shard := range state.ShardRouting {
+		if shard.NodeID != "data-2" {
+			continue
+		}
+		docs, exists := data2.documents("products", shard.ShardID)
+		if !exists || len(docs) != 1 || docs[0].DocId != fmt.Sprintf("doc-%d", shard.ShardID) {
+			t.Errorf("Expected shard %d documents on data-2, got %v", shard.ShardID, docs)
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if _, exists := data1.documents("products", shard.ShardID); !exists {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Errorf("Expected shard %d to be deleted from data-1", shard.ShardID)
+				break
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+}
+
+func TestMasterNodeAllocationAvoidsHighDiskUsage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	logger, _ := zap.NewDevelopment()
+	cfg := &config.MasterConfig{
+		NodeID:            "test-master",
+		BindAddr:          "127.0.0.1",
+		RaftPort:          19310,
+		GRPCPort:          19311,
+		DataDir:           t.TempDir(),
+		Peers:             []string{},
+		RebalanceInterval: -1,
+	}
+
+	node, err := NewMasterNode(cfg, logger)
+	if err != nil {
+		t.Fatalf("Failed to create master node: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := node.Start(ctx); err != nil {
+		t.Fatalf("Failed to start master node: %v", err)
+	}
+	defer node.Stop(ctx)
+
+	// Wait for leader election
+	time.Sleep(3 * time.Second)
+
+	if !node.IsLeader() {
+		t.Skip("Node did not become leader, skipping test")
+	}
+
+	full := startFakeDataNode(t)
+	full.diskUsage = 95
+	if err := node.RegisterNode(ctx, "data-full", "data", "127.0.0.1", full.port); err != nil {
+		t.Fatalf("Failed to register node: %v", err)
+	}
+	spare := startFakeDataNode(t)
+	spare.diskUsage = 30
+	if err := node.RegisterNode(ctx, "data-spare", "data", "127.0.0.1", spare.port); err != nil {
+		t.Fatalf("Failed to register node: %v", err)
+	}
+
+	node.refreshDiskUsage(ctx)
+
+	state, _ := node.GetClusterState(ctx)
+	if usage := state.Nodes["data-full"].DiskUsagePercent; usage != 95 {
+		t.Fatalf("Expected data-full disk usage 95%%, got %v", usage)
+	}
+
+	if err := node.CreateIndex(ctx, "products", 4, 0); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+
+	state, _ = node.GetClusterState(ctx)
+	shards := 0
+	for _, shard := range state.ShardRouting {
+		if shard.IndexName != "products" {
+			continue
+		}
+		shards++
+		if shard.NodeID != "data-spare" {
+			t.Errorf("Shard %d allocated to %s, expected data-spare", shard.ShardID, shard.NodeID)
+		}
+	}
+	if shards != 4 {
+		t.Errorf("Expected 4 allocated shards, got %d", shards)
+	}
+}
+
+func TestCopyDocumentsResumesFromPosition(t *testing.T) {
+	dial := func(node *fakeDataNode) pb.DataServiceClient {
+		conn, err := grpc.Dial(fmt.Sprintf("127.0.0.1:%d", node.port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatalf("Failed to dial data node: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return pb.NewDataServiceClient(conn)
+	}
+
+	sourceNode := startFakeDataNode(t)
+	targetNode := startFakeDataNode(t)
+	source, target := dial(sourceNode), dial(targetNode)
+	ctx := context.Background()
+
+	shard := raft.ShardRouting{IndexName: "products", ShardID: 0, NodeID: "data-1", RelocatingNodeID: "data-2"}
+	for _, node := range []pb.DataServiceClient{source, target} {
+		if _, err := node.CreateShard(ctx, &pb.CreateShardRequest{IndexName: "products", ShardId: 0}); err != nil {
+			t.Fatalf("Failed to create shard: %v", err)
+		}
+	}
+	addDocs := func(ids ...string) {
+		items := make([]*pb.BulkIndexItem, len(ids))
+		for i, id := range ids {
+			items[i] = &pb.BulkIndexItem{DocId: id}
+		}
+		if _, err := source.BulkIndex(ctx, &pb.BulkIndexRequest{IndexName: "products", ShardId: 0, Items: items}); err != nil {
+			t.Fatalf("Failed to index documents: %v", err)
+		}
+	}
+
+	addDocs("doc-0", "doc-1")
+	copied, err := copyDocuments(ctx, source, target, shard, 0)
+	if err != nil || copied != 2 {
+		t.Fatalf("Expected 2 documents copied, got %d (%v)", copied, err)
+	}
+
+	// A write that reached the source late is copied by the next pass
+	// without copying the earlier documents again
+	addDocs("doc-2")
+	copied, err = copyDocuments(ctx, source, target, shard, copied)
+	if err != nil || copied != 3 {
+		t.Fatalf("Expected copying to end at 3, got %d (%v)", copied, err)
+	}
+	docs, _ := targetNode.documents("products", 0)
+	if len(docs) != 3 || docs[2].DocId != "doc-2" {
+		t.Errorf("Expected doc-0..doc-2 on target, got %v", docs)
+	}
+}
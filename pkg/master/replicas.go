@@ -0,0 +1,258 @@
+package master
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"github.com/quidditch/quidditch/pkg/master/allocation"
+	"github.com/quidditch/quidditch/pkg/master/raft"
+	"go.uber.org/zap"
+)
+
+// UpdateIndexReplicas changes the number of replicas of a live index. The
+// new count is recorded in the index metadata, and the allocator then adds
+// replicas, which recover their documents from the primary, or removes the
+// extra ones.
+func (m *MasterNode) UpdateIndexReplicas(ctx context.Context, indexName string, numReplicas int32) error {
+	if !m.raftNode.IsLeader() {
+		return fmt.Errorf("not the leader, redirect to %s", m.raftNode.Leader())
+	}
+	if numReplicas < 0 {
+		return fmt.Errorf("number_of_replicas must be non-negative but was [%d]", numReplicas)
+	}
+
+	index, exists := m.fsm.GetState().Indices[indexName]
+	if !exists {
+		return fmt.Errorf("%w [%s]", allocation.ErrIndexNotFound, indexName)
+	}
+
+	if index.NumReplicas != numReplicas {
+		updated := *index
+		updated.NumReplicas = numReplicas
+		updated.Version = index.Version + 1
+
+		payload, err := json.Marshal(&updated)
+		if err != nil {
+			return fmt.Errorf("failed to marshal index: %w", err)
+		}
+		if err := m.raftNode.Apply(raft.Command{Type: raft.CommandUpdateIndex, Payload: payload}, 5*time.Second); err != nil {
+			return fmt.Errorf("failed to apply update index command: %w", err)
+		}
+
+		m.logger.Info("Updated index replicas",
+			zap.String("index", indexName),
+			zap.Int32("from", index.NumReplicas),
+			zap.Int32("to", numReplicas))
+	}
+
+	return m.adjustReplicas(indexName, numReplicas)
+}
+
+// adjustReplicas allocates or removes replica shards until each shard of an
+// index has numReplicas replicas, recording a shard whose replicas cannot
+// all be placed as having an unassigned replica
+func (m *MasterNode) adjustReplicas(indexName string, numReplicas int32) error {
+	state := m.fsm.GetState()
+	decisions, removals, err := allocation.NewAllocator(m.logger).AdjustReplicas(state, indexName, numReplicas)
+	if err != nil {
+		return fmt.Errorf("failed to plan replica allocation: %w", err)
+	}
+
+	for _, shard := range removals {
+		if err := m.applyShardCommand(raft.CommandRemoveShard, shard); err != nil {
+			m.logger.Error("Failed to remove replica shard",
+				zap.String("index", indexName),
+				zap.Int32("shard_id", shard.ShardID),
+				zap.String("node", shard.NodeID),
+				zap.Error(err))
+			continue
+		}
+
+		m.logger.Info("Removed replica shard",
+			zap.String("index", indexName),
+			zap.Int32("shard_id", shard.ShardID),
+			zap.String("node", shard.NodeID))
+
+		go m.deleteShardOnDataNode(*shard)
+	}
+
+	for _, decision := range decisions {
+		replica := raft.ShardRouting{
+			IndexName: decision.IndexName,
+			ShardID:   decision.ShardID,
+			NodeID:    decision.NodeID,
+			State:     "initializing",
+			Version:   1,
+		}
+		if err := m.applyShardCommand(raft.CommandAllocateShard, &replica); err != nil {
+			m.logger.Error("Failed to apply replica allocation",
+				zap.String("index", indexName),
+				zap.Int32("shard_id", decision.ShardID),
+				zap.String("node", decision.NodeID),
+				zap.Error(err))
+			continue
+		}
+
+		m.logger.Info("Allocated replica shard",
+			zap.String("index", indexName),
+			zap.Int32("shard_id", decision.ShardID),
+			zap.String("node", decision.NodeID))
+
+		go m.recoverReplica(replica)
+	}
+
+	// Record which shards are still short of replicas
+	state = m.fsm.GetState()
+	index := state.Indices[indexName]
+	if index == nil {
+		return nil
+	}
+	assigned := make(map[int32]int32)
+	for _, shard := range state.ShardRouting {
+		if shard.IndexName == indexName && !shard.IsPrimary && shard.State != "unassigned" {
+			assigned[shard.ShardID]++
+		}
+	}
+	for shardID := int32(0); shardID < index.NumShards; shardID++ {
+		unassigned := raft.ShardRouting{IndexName: indexName, ShardID: shardID}
+		_, recorded := state.ShardRouting[raft.ShardRoutingKey(&unassigned)]
+
+		switch {
+		case assigned[shardID] < numReplicas && !recorded:
+			unassigned.State = "unassigned"
+			unassigned.Version = 1
+			unassigned.UnassignedReason = "REPLICA_ADDED"
+			unassigned.UnassignedAt = time.Now().Unix()
+			if err := m.applyShardCommand(raft.CommandAllocateShard, &unassigned); err != nil {
+				m.logger.Error("Failed to record unassigned replica",
+					zap.String("index", indexName),
+					zap.Int32("shard_id", shardID),
+					zap.Error(err))
+			}
+		case assigned[shardID] >= numReplicas && recorded:
+			if err := m.applyShardCommand(raft.CommandRemoveShard, &unassigned); err != nil {
+				m.logger.Error("Failed to clear unassigned replica",
+					zap.String("index", indexName),
+					zap.Int32("shard_id", shardID),
+					zap.Error(err))
+			}
+		}
+	}
+
+	return nil
+}
+
+// recoverReplica copies the primary's documents to a newly allocated
+// replica and marks the replica started. Coordination nodes reject writes
+// to the shard while the replica initializes, so the copy misses none, and
+// send each write to the replica as well as the primary once it starts.
+func (m *MasterNode) recoverReplica(replica raft.ShardRouting) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	primary, exists := m.fsm.GetState().ShardRouting[raft.ShardRoutingKey(&raft.ShardRouting{
+		IndexName: replica.IndexName,
+		ShardID:   replica.ShardID,
+		IsPrimary: true,
+	})]
+	if !exists || primary.NodeID == "" {
+		m.logger.Error("Replica recovery failed, primary shard is not assigned",
+			zap.String("index", replica.IndexName),
+			zap.Int32("shard_id", replica.ShardID))
+		m.failReplica(replica)
+		return
+	}
+
+	// copyShard creates the shard on the target node and copies the source
+	// documents into it, as it does for a relocation
+	recovery := replica
+	recovery.NodeID = primary.NodeID
+	recovery.RelocatingNodeID = replica.NodeID
+	if _, err := m.copyShard(ctx, recovery); err != nil {
+		m.logger.Error("Replica recovery failed",
+			zap.String("index", replica.IndexName),
+			zap.Int32("shard_id", replica.ShardID),
+			zap.String("from", primary.NodeID),
+			zap.String("to", replica.NodeID),
+			zap.Error(err))
+		m.failReplica(replica)
+		return
+	}
+
+	current, exists := m.fsm.GetState().ShardRouting[raft.ShardRoutingKey(&replica)]
+	if !exists {
+		return // Removed while recovering
+	}
+	started := *current
+	started.State = "started"
+	started.Version = current.Version + 1
+	if err := m.applyShardCommand(raft.CommandUpdateShard, &started); err != nil {
+		m.logger.Error("Failed to start recovered replica",
+			zap.String("index", replica.IndexName),
+			zap.Int32("shard_id", replica.ShardID),
+			zap.Error(err))
+		return
+	}
+
+	m.logger.Info("Recovered replica shard",
+		zap.String("index", replica.IndexName),
+		zap.Int32("shard_id", replica.ShardID),
+		zap.String("from", primary.NodeID),
+		zap.String("to", replica.NodeID))
+}
+
+// failReplica removes a replica whose recovery failed, so writes to its
+// shard aren't held back by it, and records the shard as short of a replica
+func (m *MasterNode) failReplica(replica raft.ShardRouting) {
+	if err := m.applyShardCommand(raft.CommandRemoveShard, &replica); err != nil {
+		m.logger.Error("Failed to remove replica shard",
+			zap.String("index", replica.IndexName),
+			zap.Int32("shard_id", replica.ShardID),
+			zap.String("node", replica.NodeID),
+			zap.Error(err))
+		return
+	}
+	go m.deleteShardOnDataNode(replica)
+
+	unassigned := raft.ShardRouting{IndexName: replica.IndexName, ShardID: replica.ShardID}
+	if _, recorded := m.fsm.GetState().ShardRouting[raft.ShardRoutingKey(&unassigned)]; recorded {
+		return
+	}
+	unassigned.State = "unassigned"
+	unassigned.Version = 1
+	unassigned.UnassignedReason = "ALLOCATION_FAILED"
+	unassigned.UnassignedAt = time.Now().Unix()
+	if err := m.applyShardCommand(raft.CommandAllocateShard, &unassigned); err != nil {
+		m.logger.Error("Failed to record unassigned replica",
+			zap.String("index", replica.IndexName),
+			zap.Int32("shard_id", replica.ShardID),
+			zap.Error(err))
+	}
+}
+
+// deleteShardOnDataNode deletes a removed shard copy from its data node
+func (m *MasterNode) deleteShardOnDataNode(shard raft.ShardRouting) {
+	conn, err := m.dialDataNode(shard.NodeID)
+	if err != nil {
+		m.logger.Warn("Failed to connect to data node", zap.String("node_id", shard.NodeID), zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := pb.NewDataServiceClient(conn).DeleteShard(ctx, &pb.DeleteShardRequest{
+		IndexName: shard.IndexName,
+		ShardId:   shard.ShardID,
+	}); err != nil {
+		m.logger.Warn("Failed to delete shard from data node",
+			zap.String("node_id", shard.NodeID),
+			zap.String("index", shard.IndexName),
+			zap.Int32("shard_id", shard.ShardID),
+			zap.Error(err))
+	}
+}
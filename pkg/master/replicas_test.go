@@ -0,0 +1,133 @@
+package master
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/quidditch/quidditch/pkg/common/config"
+	pb "github.com/quidditch/quidditch/pkg/common/proto"
+	"go.uber.org/zap"
+)
+
+func TestMasterNodeUpdateIndexReplicas(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	logger, _ := zap.NewDevelopment()
+	cfg := &config.MasterConfig{
+		NodeID:            "test-master",
+		BindAddr:          "127.0.0.1",
+		RaftPort:          19312,
+		GRPCPort:          19313,
+		DataDir:           t.TempDir(),
+		Peers:             []string{},
+		RebalanceInterval: -1,
+	}
+
+	node, err := NewMasterNode(cfg, logger)
+	if err != nil {
+		t.Fatalf("Failed to create master node: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := node.Start(ctx); err != nil {
+		t.Fatalf("Failed to start master node: %v", err)
+	}
+	defer node.Stop(ctx)
+
+	// Wait for leader election
+	time.Sleep(3 * time.Second)
+
+	if !node.IsLeader() {
+		t.Skip("Node did not become leader, skipping test")
+	}
+
+	dataNodes := map[string]*fakeDataNode{
+		"data-1": startFakeDataNode(t),
+		"data-2": startFakeDataNode(t),
+	}
+	for nodeID, dataNode := range dataNodes {
+		if err := node.RegisterNode(ctx, nodeID, "data", "127.0.0.1", dataNode.port); err != nil {
+			t.Fatalf("Failed to register node: %v", err)
+		}
+	}
+
+	if err := node.CreateIndex(ctx, "products", 1, 0); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+
+	// Wait for the primary to start
+	deadline := time.Now().Add(10 * time.Second)
+	var primaryNode string
+	for primaryNode == "" {
+		state, _ := node.GetClusterState(ctx)
+		if shard, exists := state.ShardRouting["products:0"]; exists && shard.State == "started" {
+			primaryNode = shard.NodeID
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the primary to start")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if _, err := dataNodes[primaryNode].BulkIndex(ctx, &pb.BulkIndexRequest{
+		IndexName: "products",
+		ShardId:   0,
+		Items:     []*pb.BulkIndexItem{{DocId: "doc-1"}},
+	}); err != nil {
+		t.Fatalf("Failed to seed primary: %v", err)
+	}
+
+	// Adding a replica allocates it on the other node, recovered from the
+	// primary
+	if err := node.UpdateIndexReplicas(ctx, "products", 1); err != nil {
+		t.Fatalf("Failed to update replicas: %v", err)
+	}
+
+	state, _ := node.GetClusterState(ctx)
+	if replicas := state.Indices["products"].NumReplicas; replicas != 1 {
+		t.Errorf("Expected 1 replica in index metadata, got %d", replicas)
+	}
+
+	replicaNode := "data-1"
+	if primaryNode == "data-1" {
+		replicaNode = "data-2"
+	}
+	replicaKey := "products:0:replica:" + replicaNode
+	deadline = time.Now().Add(10 * time.Second)
+	for {
+		state, _ := node.GetClusterState(ctx)
+		if shard, exists := state.ShardRouting[replicaKey]; exists && shard.State == "started" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for replica %s to start: %v", replicaKey, state.ShardRouting)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	docs, exists := dataNodes[replicaNode].documents("products", 0)
+	if !exists || len(docs) != 1 || docs[0].DocId != "doc-1" {
+		t.Errorf("Expected the replica to hold the primary's documents, got %v", docs)
+	}
+
+	// A second replica has no node apart from the other copies
+	if err := node.UpdateIndexReplicas(ctx, "products", 2); err != nil {
+		t.Fatalf("Failed to update replicas: %v", err)
+	}
+	state, _ = node.GetClusterState(ctx)
+	if shard, exists := state.ShardRouting["products:0:replica:"]; !exists || shard.State != "unassigned" {
+		t.Errorf("Expected an unassigned replica, got %v", state.ShardRouting)
+	}
+
+	// Dropping back to no replicas removes them
+	if err := node.UpdateIndexReplicas(ctx, "products", 0); err != nil {
+		t.Fatalf("Failed to update replicas: %v", err)
+	}
+	state, _ = node.GetClusterState(ctx)
+	for key, shard := range state.ShardRouting {
+		if shard.IndexName == "products" && !shard.IsPrimary {
+			t.Errorf("Expected replica %s to be removed", key)
+		}
+	}
+}
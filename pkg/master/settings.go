@@ -0,0 +1,218 @@
+package master
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quidditch/quidditch/pkg/common/logging"
+	"github.com/quidditch/quidditch/pkg/master/allocation"
+	"github.com/quidditch/quidditch/pkg/master/raft"
+	"go.uber.org/zap"
+)
+
+// SettingAutoCreateIndex controls whether a write to a missing index creates
+// it: true, false, or a comma-separated list of index patterns, each
+// prefixed with + to allow or - to deny, where the first matching pattern
+// decides
+const SettingAutoCreateIndex = "action.auto_create_index"
+
+// SettingLoggerLevel is the cluster setting for the level every node logs
+// at, overriding its configured level until it is reset
+const SettingLoggerLevel = logging.SettingLevel
+
+// SettingIndexStoreType is the index setting choosing how a shard's files
+// are read: mmapfs memory-maps them, niofs reads them with positional reads,
+// and hybridfs, the default, leaves the choice to the data node
+const SettingIndexStoreType = "index.store.type"
+
+// indexStoreTypes are the values of index.store.type
+var indexStoreTypes = map[string]bool{"mmapfs": true, "niofs": true, "hybridfs": true}
+
+// validateIndexStoreType checks an index.store.type value. Empty is the
+// default.
+func validateIndexStoreType(storeType string) error {
+	if storeType != "" && !indexStoreTypes[storeType] {
+		return fmt.Errorf("unknown [%s] value [%s], expected one of mmapfs, niofs or hybridfs", SettingIndexStoreType, storeType)
+	}
+	return nil
+}
+
+// SettingIndexBufferSize is the index setting sizing the RAM buffer, in
+// megabytes, each shard's index writer fills before flushing a segment.
+// Unset uses the data node's default.
+const SettingIndexBufferSize = "index.buffer_size_mb"
+
+// Bounds of index.buffer_size_mb. Diagon, like Lucene, cannot address a
+// buffer of 2GB or more.
+const (
+	minIndexBufferSizeMB = 1
+	maxIndexBufferSizeMB = 2047
+)
+
+// validateIndexBufferSize checks an index.buffer_size_mb value. Zero is the
+// default.
+func validateIndexBufferSize(sizeMB float64) error {
+	if sizeMB != 0 && (sizeMB < minIndexBufferSizeMB || sizeMB > maxIndexBufferSizeMB) {
+		return fmt.Errorf("[%s] must be between %d and %d but was [%s]", SettingIndexBufferSize,
+			minIndexBufferSizeMB, maxIndexBufferSizeMB, strconv.FormatFloat(sizeMB, 'f', -1, 64))
+	}
+	return nil
+}
+
+// SettingIndexMaxFieldValueLength is the index setting bounding, in bytes,
+// the string values a document's fields may hold. Unset, values aren't
+// limited.
+const SettingIndexMaxFieldValueLength = "index.mapping.max_field_value_length"
+
+// SettingIndexMaxDocumentSize is the index setting bounding, in bytes, the
+// documents the index accepts. Unset, documents aren't limited.
+const SettingIndexMaxDocumentSize = "index.mapping.max_document_size_bytes"
+
+// validateIndexDocumentLimit checks the value of a setting bounding the
+// documents an index accepts. Zero is no limit.
+func validateIndexDocumentLimit(setting string, limit int64) error {
+	if limit < 0 {
+		return fmt.Errorf("[%s] must be >= 0 but was [%d]", setting, limit)
+	}
+	return nil
+}
+
+// SettingIndexMaxResultWindow is the index setting bounding the from+size
+// a search of the index may request. Unset uses the coordination nodes'
+// default.
+const SettingIndexMaxResultWindow = "index.max_result_window"
+
+// validateIndexMaxResultWindow checks an index.max_result_window value.
+// Zero is the default.
+func validateIndexMaxResultWindow(window int32) error {
+	if window < 0 {
+		return fmt.Errorf("[%s] must be >= 1 but was [%d]", SettingIndexMaxResultWindow, window)
+	}
+	return nil
+}
+
+// SettingIndexSlowlogThreshold is the index setting holding the search
+// duration past which coordination nodes write a search of the index to
+// the slow log. Unset turns slow logging off.
+const SettingIndexSlowlogThreshold = "index.slowlog.threshold"
+
+// validateIndexSlowlogThreshold checks an index.slowlog.threshold value.
+// Empty turns slow logging off.
+func validateIndexSlowlogThreshold(threshold string) error {
+	if threshold == "" {
+		return nil
+	}
+	if d, err := time.ParseDuration(threshold); err != nil || d <= 0 {
+		return fmt.Errorf("[%s] must be a positive duration such as \"1s\" but was [%s]", SettingIndexSlowlogThreshold, threshold)
+	}
+	return nil
+}
+
+// SettingIndexAnalysis is the index setting carrying the index's analyzer
+// settings as JSON, the custom analyzers its settings define and the
+// analyzers its mapping selects per field. Data nodes build each shard's
+// analyzers from it.
+const SettingIndexAnalysis = "index.analysis"
+
+// SettingIndexNumericFields is the index setting carrying, as JSON, the
+// type and ignore_malformed policy of each field the index's mapping maps
+// as numeric. Data nodes coerce those fields' values to their type.
+const SettingIndexNumericFields = "index.mapping.numeric_fields"
+
+// SettingIndexDateFields is the index setting carrying, as JSON, the
+// formats of each field the index's mapping maps as a date. Data nodes
+// parse those fields' values with them.
+const SettingIndexDateFields = "index.mapping.date_fields"
+
+// SettingIndexGeoPointFields is the index setting carrying, as a JSON list,
+// the fields the index's mapping maps as geo_point. Data nodes index their
+// "lat,lon" string and [lon, lat] array values as points.
+const SettingIndexGeoPointFields = "index.mapping.geo_point_fields"
+
+// SettingIndexBlocksWrite is the index setting that, while "true", makes
+// coordination nodes reject document writes to the index. A split or
+// shrink sets it on the source index while copying it.
+const SettingIndexBlocksWrite = "index.blocks.write"
+
+// UpdateIndexSettings records settings of a live index in its metadata.
+// An empty value removes the setting.
+func (m *MasterNode) UpdateIndexSettings(ctx context.Context, indexName string, values map[string]string) error {
+	if !m.raftNode.IsLeader() {
+		return fmt.Errorf("not the leader, redirect to %s", m.raftNode.Leader())
+	}
+
+	index, exists := m.fsm.GetState().Indices[indexName]
+	if !exists {
+		return fmt.Errorf("%w [%s]", allocation.ErrIndexNotFound, indexName)
+	}
+
+	settings := make(map[string]string, len(index.Settings)+len(values))
+	for key, value := range index.Settings {
+		settings[key] = value
+	}
+	changed := false
+	for key, value := range values {
+		if settings[key] == value {
+			continue
+		}
+		if value == "" {
+			delete(settings, key)
+		} else {
+			settings[key] = value
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	updated := *index
+	updated.Settings = settings
+	updated.Version = index.Version + 1
+
+	payload, err := json.Marshal(&updated)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+	if err := m.raftNode.Apply(raft.Command{Type: raft.CommandUpdateIndex, Payload: payload}, 5*time.Second); err != nil {
+		return fmt.Errorf("failed to apply update index command: %w", err)
+	}
+
+	m.logger.Info("Updated index settings", zap.String("index", indexName), zap.Any("settings", values))
+	return nil
+}
+
+// validateClusterSetting checks the value of a cluster setting, rejecting
+// settings that are not recognized
+func validateClusterSetting(key, value string) error {
+	if key == SettingAutoCreateIndex {
+		if value == "true" || value == "false" {
+			return nil
+		}
+		for _, pattern := range strings.Split(value, ",") {
+			pattern = strings.TrimSpace(pattern)
+			if strings.HasPrefix(pattern, "+") || strings.HasPrefix(pattern, "-") {
+				pattern = pattern[1:]
+			}
+			if pattern == "" {
+				return fmt.Errorf("illegal value [%s] for [%s], expected true, false or a list of index patterns", value, key)
+			}
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("illegal value [%s] for [%s], invalid index pattern [%s]", value, key, pattern)
+			}
+		}
+		return nil
+	}
+	if key == SettingLoggerLevel {
+		if _, err := logging.ParseLevel(value); err != nil {
+			return fmt.Errorf("illegal value [%s] for [%s], %v", value, key, err)
+		}
+		return nil
+	}
+	return allocation.ValidateSetting(key, value)
+}
@@ -0,0 +1,97 @@
+package master
+
+import (
+	"testing"
+)
+
+func TestValidateClusterSetting(t *testing.T) {
+	for _, value := range []string{"true", "false", "logs-*", "+logs-*,-secret-*", "-secret-*, *"} {
+		if err := validateClusterSetting(SettingAutoCreateIndex, value); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", value, err)
+		}
+	}
+	for _, value := range []string{"", "logs-*,", "+", "logs-["} {
+		if err := validateClusterSetting(SettingAutoCreateIndex, value); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+
+	if err := validateClusterSetting(SettingLoggerLevel, "DEBUG"); err != nil {
+		t.Errorf("Expected log level to be valid, got %v", err)
+	}
+	if err := validateClusterSetting(SettingLoggerLevel, "loud"); err == nil {
+		t.Error("Expected unknown log level to be rejected")
+	}
+
+	// Allocation settings are still validated, and unknown settings rejected
+	if err := validateClusterSetting("cluster.routing.allocation.enable", "primaries"); err != nil {
+		t.Errorf("Expected allocation setting to be valid, got %v", err)
+	}
+	if err := validateClusterSetting("cluster.routing.allocation.enable", "some"); err == nil {
+		t.Error("Expected invalid allocation setting to be rejected")
+	}
+	if err := validateClusterSetting("action.unknown", "true"); err == nil {
+		t.Error("Expected unknown setting to be rejected")
+	}
+}
+
+func TestValidateIndexStoreType(t *testing.T) {
+	for _, value := range []string{"", "mmapfs", "niofs", "hybridfs"} {
+		if err := validateIndexStoreType(value); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", value, err)
+		}
+	}
+	for _, value := range []string{"simplefs", "fs", "MMAPFS"} {
+		if err := validateIndexStoreType(value); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestValidateIndexBufferSize(t *testing.T) {
+	for _, value := range []float64{0, 1, 64, 512.5, 2047} {
+		if err := validateIndexBufferSize(value); err != nil {
+			t.Errorf("Expected %g to be valid, got %v", value, err)
+		}
+	}
+	for _, value := range []float64{-1, 0.5, 2048, 100000} {
+		if err := validateIndexBufferSize(value); err == nil {
+			t.Errorf("Expected %g to be rejected", value)
+		}
+	}
+}
+
+func TestValidateIndexDocumentLimit(t *testing.T) {
+	for _, value := range []int64{0, 1, 32766, 100 << 20} {
+		if err := validateIndexDocumentLimit(SettingIndexMaxDocumentSize, value); err != nil {
+			t.Errorf("Expected %d to be valid, got %v", value, err)
+		}
+	}
+	if err := validateIndexDocumentLimit(SettingIndexMaxFieldValueLength, -1); err == nil {
+		t.Errorf("Expected -1 to be rejected")
+	}
+}
+
+func TestValidateIndexMaxResultWindow(t *testing.T) {
+	for _, value := range []int32{0, 1, 10000, 50000} {
+		if err := validateIndexMaxResultWindow(value); err != nil {
+			t.Errorf("Expected %d to be valid, got %v", value, err)
+		}
+	}
+	if err := validateIndexMaxResultWindow(-1); err == nil {
+		t.Errorf("Expected -1 to be rejected")
+	}
+}
+
+func TestValidateIndexSlowlogThreshold(t *testing.T) {
+	for _, value := range []string{"", "1s", "500ms", "2m"} {
+		if err := validateIndexSlowlogThreshold(value); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", value, err)
+		}
+	}
+	for _, value := range []string{"soon", "0s", "-1s"} {
+		if err := validateIndexSlowlogThreshold(value); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package master
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/quidditch/quidditch/pkg/master/allocation"
+	"github.com/quidditch/quidditch/pkg/master/raft"
+	"go.uber.org/zap"
+)
+
+// storageTiers are the storage tiers a data node or an index may be on
+var storageTiers = map[string]bool{"hot": true, "warm": true, "cold": true, "frozen": true}
+
+// validateStorageTier checks an index's storage tier. Empty clears it.
+func validateStorageTier(tier string) error {
+	if tier != "" && !storageTiers[tier] {
+		return fmt.Errorf("unknown storage tier [%s], expected one of hot, warm, cold or frozen", tier)
+	}
+	return nil
+}
+
+// UpdateIndexTier moves a live index to a storage tier. The tier is
+// recorded in the index metadata, which keeps new copies of its shards on
+// data nodes of that tier, and shards already elsewhere start relocating
+// there at once rather than on the next rebalancing round.
+func (m *MasterNode) UpdateIndexTier(ctx context.Context, indexName, tier string) error {
+	if !m.raftNode.IsLeader() {
+		return fmt.Errorf("not the leader, redirect to %s", m.raftNode.Leader())
+	}
+	if err := validateStorageTier(tier); err != nil {
+		return err
+	}
+
+	index, exists := m.fsm.GetState().Indices[indexName]
+	if !exists {
+		return fmt.Errorf("%w [%s]", allocation.ErrIndexNotFound, indexName)
+	}
+	if index.Tier == tier {
+		return nil
+	}
+
+	updated := *index
+	updated.Tier = tier
+	updated.Version = index.Version + 1
+
+	payload, err := json.Marshal(&updated)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+	if err := m.raftNode.Apply(raft.Command{Type: raft.CommandUpdateIndex, Payload: payload}, 5*time.Second); err != nil {
+		return fmt.Errorf("failed to apply update index command: %w", err)
+	}
+
+	m.logger.Info("Updated index storage tier",
+		zap.String("index", indexName),
+		zap.String("from", index.Tier),
+		zap.String("to", tier))
+
+	if _, err := m.RebalanceShards(ctx, false); err != nil {
+		m.logger.Warn("Failed to start tier migration", zap.String("index", indexName), zap.Error(err))
+	}
+	return nil
+}
@@ -22,8 +22,21 @@ type DocumentContext struct {
 
 	// For debugging
 	fieldAccesses int
+
+	// reads records the fields a UDF read while tracking is on, so the
+	// result of a deterministic UDF can be cached against their values
+	reads    map[string]bool
+	tracking int
+	readsMu  sync.Mutex
 }
 
+// Pseudo field names recording reads of a document's metadata. They can't
+// collide with document fields, which never start with a NUL byte.
+const (
+	readDocumentID = "\x00_id"
+	readScore      = "\x00_score"
+)
+
 // NewDocumentContext creates a context from JSON document data
 func NewDocumentContext(documentID string, score float64, jsonData []byte) (*DocumentContext, error) {
 	var data map[string]interface{}
@@ -52,6 +65,7 @@ func (dc *DocumentContext) GetFieldString(fieldPath string) (string, bool) {
 	dc.mu.RLock()
 	defer dc.mu.RUnlock()
 	dc.fieldAccesses++
+	dc.recordRead(fieldPath)
 
 	value, exists := dc.getNestedField(fieldPath)
 	if !exists {
@@ -78,6 +92,7 @@ func (dc *DocumentContext) GetFieldInt64(fieldPath string) (int64, bool) {
 	dc.mu.RLock()
 	defer dc.mu.RUnlock()
 	dc.fieldAccesses++
+	dc.recordRead(fieldPath)
 
 	value, exists := dc.getNestedField(fieldPath)
 	if !exists {
@@ -106,6 +121,7 @@ func (dc *DocumentContext) GetFieldFloat64(fieldPath string) (float64, bool) {
 	dc.mu.RLock()
 	defer dc.mu.RUnlock()
 	dc.fieldAccesses++
+	dc.recordRead(fieldPath)
 
 	value, exists := dc.getNestedField(fieldPath)
 	if !exists {
@@ -134,6 +150,7 @@ func (dc *DocumentContext) GetFieldBool(fieldPath string) (bool, bool) {
 	dc.mu.RLock()
 	defer dc.mu.RUnlock()
 	dc.fieldAccesses++
+	dc.recordRead(fieldPath)
 
 	value, exists := dc.getNestedField(fieldPath)
 	if !exists {
@@ -153,6 +170,7 @@ func (dc *DocumentContext) GetFieldBool(fieldPath string) (bool, bool) {
 func (dc *DocumentContext) HasField(fieldPath string) bool {
 	dc.mu.RLock()
 	defer dc.mu.RUnlock()
+	dc.recordRead(fieldPath)
 
 	_, exists := dc.getNestedField(fieldPath)
 	return exists
@@ -160,14 +178,68 @@ func (dc *DocumentContext) HasField(fieldPath string) bool {
 
 // GetDocumentID returns the document ID
 func (dc *DocumentContext) GetDocumentID() string {
+	dc.recordRead(readDocumentID)
 	return dc.documentID
 }
 
 // GetScore returns the document score
 func (dc *DocumentContext) GetScore() float64 {
+	dc.recordRead(readScore)
 	return dc.score
 }
 
+// trackReads starts recording the fields read from the document. Calls
+// sharing the context record into the same set, so each sees every field it
+// read, possibly with others.
+func (dc *DocumentContext) trackReads() {
+	dc.readsMu.Lock()
+	defer dc.readsMu.Unlock()
+	if dc.tracking == 0 {
+		dc.reads = make(map[string]bool)
+	}
+	dc.tracking++
+}
+
+// stopTrackingReads returns the fields read since trackReads
+func (dc *DocumentContext) stopTrackingReads() []string {
+	dc.readsMu.Lock()
+	defer dc.readsMu.Unlock()
+
+	fields := make([]string, 0, len(dc.reads))
+	for field := range dc.reads {
+		fields = append(fields, field)
+	}
+	dc.tracking--
+	if dc.tracking == 0 {
+		dc.reads = nil
+	}
+	return fields
+}
+
+// recordRead records a read of field while tracking is on
+func (dc *DocumentContext) recordRead(field string) {
+	dc.readsMu.Lock()
+	defer dc.readsMu.Unlock()
+	if dc.tracking > 0 {
+		dc.reads[field] = true
+	}
+}
+
+// readValue returns what a read of field observes, for comparing documents:
+// the field's value and whether the document has it
+func (dc *DocumentContext) readValue(field string) (interface{}, bool) {
+	switch field {
+	case readDocumentID:
+		return dc.documentID, true
+	case readScore:
+		return dc.score, true
+	}
+
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+	return dc.getNestedField(field)
+}
+
 // GetFieldAccessCount returns the number of field accesses (for debugging)
 func (dc *DocumentContext) GetFieldAccessCount() int {
 	dc.mu.RLock()
@@ -292,6 +364,8 @@ func (cp *ContextPool) Get(documentID string, score float64, jsonData []byte) (*
 		ctx.documentID = documentID
 		ctx.score = score
 		ctx.fieldAccesses = 0
+		ctx.reads = nil
+		ctx.tracking = 0
 		return ctx, nil
 	default:
 		// Pool empty, create new context
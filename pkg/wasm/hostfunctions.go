@@ -6,13 +6,15 @@ import (
 	"fmt"
 	"math"
 	"sync"
-	"unsafe"
 
 	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/api"
 	"go.uber.org/zap"
 )
 
+// hostModuleName is the module UDFs import host functions from
+const hostModuleName = "env"
+
 // HostFunctions manages the host functions available to WASM modules
 type HostFunctions struct {
 	logger   *zap.Logger
@@ -91,9 +93,11 @@ func (hf *HostFunctions) GetParameter(name string) (interface{}, bool) {
 // This must be called before instantiating modules that use these functions
 func (hf *HostFunctions) RegisterHostFunctions(ctx context.Context, runtime wazero.Runtime) error {
 	// Create host module builder
-	hostBuilder := runtime.NewHostModuleBuilder("env")
+	hostBuilder := runtime.NewHostModuleBuilder(hostModuleName)
 
 	// Register field access functions using GoModuleFunction
+	// get_field_string(ctx_id: i64, field_ptr: i32, field_len: i32, result_ptr: i32, result_len_ptr: i32) -> i32
+	// Returns: 0=success, 1=not found, 3=buffer too small
 	hostBuilder.NewFunctionBuilder().
 		WithGoModuleFunction(api.GoModuleFunc(hf.getFieldString), []api.ValueType{
 			api.ValueTypeI64, // ctx_id
@@ -137,6 +141,8 @@ func (hf *HostFunctions) RegisterHostFunctions(ctx context.Context, runtime waze
 		Export("has_field")
 
 	// Register document metadata functions
+	// get_document_id(ctx_id: i64, result_ptr: i32, result_len_ptr: i32) -> i32
+	// Returns: 0=success, 1=not found, 3=buffer too small
 	hostBuilder.NewFunctionBuilder().
 		WithGoModuleFunction(api.GoModuleFunc(hf.getDocumentID), []api.ValueType{
 			api.ValueTypeI64, // ctx_id
@@ -171,6 +177,16 @@ func (hf *HostFunctions) RegisterHostFunctions(ctx context.Context, runtime waze
 		}, []api.ValueType{api.ValueTypeI32}).
 		Export("get_param_string")
 
+	// get_param_i32(name_ptr: i32, name_len: i32, out_ptr: i32) -> i32
+	// Returns: 0=success, 1=not found, 2=not numeric, 3=write error
+	hostBuilder.NewFunctionBuilder().
+		WithGoModuleFunction(api.GoModuleFunc(hf.getParamInt32), []api.ValueType{
+			api.ValueTypeI32, // name_ptr
+			api.ValueTypeI32, // name_len
+			api.ValueTypeI32, // out_ptr
+		}, []api.ValueType{api.ValueTypeI32}).
+		Export("get_param_i32")
+
 	// get_param_i64(name_ptr: i32, name_len: i32, out_ptr: i32) -> i32
 	// Returns: 0=success, 1=not found, 2=not numeric, 3=write error
 	hostBuilder.NewFunctionBuilder().
@@ -212,7 +228,9 @@ func (hf *HostFunctions) RegisterHostFunctions(ctx context.Context, runtime waze
 
 // getFieldString retrieves a string field value
 // Parameters: ctx_id, field_ptr, field_len, result_ptr, result_len_ptr
-// Returns: 1 if field exists, 0 if not
+// Returns: 0=success, 1=not found, 3=buffer too small
+// The caller stores its buffer size at result_len_ptr; on success it holds
+// the value's length, and when the buffer is too small the required size.
 func (hf *HostFunctions) getFieldString(ctx context.Context, mod api.Module, stack []uint64) {
 	ctxID := stack[0]
 	fieldPtr := uint32(stack[1])
@@ -223,7 +241,7 @@ func (hf *HostFunctions) getFieldString(ctx context.Context, mod api.Module, sta
 	// Get document context
 	docCtx, exists := hf.GetContext(ctxID)
 	if !exists {
-		stack[0] = 0 // Field not found
+		stack[0] = 1 // Field not found
 		return
 	}
 
@@ -231,34 +249,23 @@ func (hf *HostFunctions) getFieldString(ctx context.Context, mod api.Module, sta
 	fieldPath, ok := mod.Memory().Read(fieldPtr, fieldLen)
 	if !ok {
 		hf.logger.Warn("Failed to read field path from WASM memory")
-		stack[0] = 0
+		stack[0] = 1
 		return
 	}
 
 	// Get field value
 	value, exists := docCtx.GetFieldString(string(fieldPath))
 	if !exists {
-		stack[0] = 0 // Field not found
+		stack[0] = 1 // Field not found
 		return
 	}
 
-	// Write result to WASM memory
-	valueBytes := []byte(value)
-	if !mod.Memory().Write(resultPtr, valueBytes) {
-		hf.logger.Warn("Failed to write result to WASM memory")
-		stack[0] = 0
-		return
-	}
-
-	// Write result length
-	lengthBytes := uint32ToBytes(uint32(len(valueBytes)))
-	if !mod.Memory().Write(resultLenPtr, lengthBytes) {
-		hf.logger.Warn("Failed to write result length to WASM memory")
-		stack[0] = 0
+	if !writeSizedResult(mod.Memory(), resultPtr, resultLenPtr, []byte(value)) {
+		stack[0] = 3 // Buffer too small
 		return
 	}
 
-	stack[0] = 1 // Success
+	stack[0] = 0 // Success
 }
 
 // getFieldInt64 retrieves an int64 field value
@@ -393,7 +400,8 @@ func (hf *HostFunctions) hasField(ctx context.Context, mod api.Module, stack []u
 
 // getDocumentID retrieves the document ID
 // Parameters: ctx_id, result_ptr, result_len_ptr
-// Returns: 1 on success, 0 on failure
+// Returns: 0=success, 1=not found, 3=buffer too small
+// The buffer size is negotiated through result_len_ptr as in getFieldString.
 func (hf *HostFunctions) getDocumentID(ctx context.Context, mod api.Module, stack []uint64) {
 	ctxID := stack[0]
 	resultPtr := uint32(stack[1])
@@ -402,30 +410,16 @@ func (hf *HostFunctions) getDocumentID(ctx context.Context, mod api.Module, stac
 	// Get document context
 	docCtx, exists := hf.GetContext(ctxID)
 	if !exists {
-		stack[0] = 0
-		return
-	}
-
-	// Get document ID
-	docID := docCtx.GetDocumentID()
-	docIDBytes := []byte(docID)
-
-	// Write to WASM memory
-	if !mod.Memory().Write(resultPtr, docIDBytes) {
-		hf.logger.Warn("Failed to write document ID to WASM memory")
-		stack[0] = 0
+		stack[0] = 1
 		return
 	}
 
-	// Write length
-	lengthBytes := uint32ToBytes(uint32(len(docIDBytes)))
-	if !mod.Memory().Write(resultLenPtr, lengthBytes) {
-		hf.logger.Warn("Failed to write document ID length to WASM memory")
-		stack[0] = 0
+	if !writeSizedResult(mod.Memory(), resultPtr, resultLenPtr, []byte(docCtx.GetDocumentID())) {
+		stack[0] = 3 // Buffer too small
 		return
 	}
 
-	stack[0] = 1
+	stack[0] = 0
 }
 
 // getScore retrieves the document score
@@ -497,33 +491,88 @@ func (hf *HostFunctions) getParamString(ctx context.Context, mod api.Module, sta
 		return
 	}
 
-	// Read buffer size from WASM memory
-	valueLenBytes, ok := mod.Memory().Read(valueLenPtr, 4)
-	if !ok {
-		stack[0] = 3 // Buffer error
+	if !writeSizedResult(mod.Memory(), valuePtr, valueLenPtr, []byte(strValue)) {
+		stack[0] = 3 // Buffer too small
 		return
 	}
-	bufferSize := binary.LittleEndian.Uint32(valueLenBytes)
 
-	// Check if buffer is large enough
-	valueBytes := []byte(strValue)
-	if uint32(len(valueBytes)) > bufferSize {
+	stack[0] = 0 // Success
+}
+
+// writeSizedResult writes value into a WASM buffer whose size the caller
+// stored at lenPtr, then stores the value's length there. If the value does
+// not fit, nothing is written to the buffer, the required size is stored at
+// lenPtr and it returns false.
+func writeSizedResult(mem api.Memory, ptr, lenPtr uint32, value []byte) bool {
+	bufferSizeBytes, ok := mem.Read(lenPtr, 4)
+	if !ok {
+		return false
+	}
+	lengthBytes := uint32ToBytes(uint32(len(value)))
+
+	if uint32(len(value)) > bytesToUint32(bufferSizeBytes) {
 		// Write required size
-		binary.LittleEndian.PutUint32(valueLenBytes, uint32(len(valueBytes)))
-		mod.Memory().Write(valueLenPtr, valueLenBytes)
-		stack[0] = 3 // Buffer too small
+		mem.Write(lenPtr, lengthBytes)
+		return false
+	}
+
+	if !mem.Write(ptr, value) {
+		return false
+	}
+	return mem.Write(lenPtr, lengthBytes)
+}
+
+// getParamInt32 retrieves an int32 parameter from the current UDF execution
+// Parameters: name_ptr, name_len, out_ptr
+// Returns: 0=success, 1=not found, 2=not numeric, 3=write error
+func (hf *HostFunctions) getParamInt32(ctx context.Context, mod api.Module, stack []uint64) {
+	namePtr := uint32(stack[0])
+	nameLen := uint32(stack[1])
+	outPtr := uint32(stack[2])
+
+	// Read parameter name
+	nameBytes, ok := mod.Memory().Read(namePtr, nameLen)
+	if !ok {
+		stack[0] = 1
+		return
+	}
+	paramName := string(nameBytes)
+
+	// Look up parameter
+	paramValue, exists := hf.GetParameter(paramName)
+	if !exists {
+		stack[0] = 1
 		return
 	}
 
-	// Write string value to WASM memory
-	if !mod.Memory().Write(valuePtr, valueBytes) {
-		stack[0] = 3 // Write error
+	// Convert to int32 (handle multiple numeric types)
+	var i32Value int32
+	switch v := paramValue.(type) {
+	case int32:
+		i32Value = v
+	case int64:
+		i32Value = int32(v)
+	case int:
+		i32Value = int32(v)
+	case float64:
+		i32Value = int32(v)
+	case float32:
+		i32Value = int32(v)
+	default:
+		hf.logger.Warn("Parameter is not numeric",
+			zap.String("name", paramName),
+			zap.String("type", fmt.Sprintf("%T", paramValue)))
+		stack[0] = 2
 		return
 	}
 
-	// Write actual length
-	binary.LittleEndian.PutUint32(valueLenBytes, uint32(len(valueBytes)))
-	mod.Memory().Write(valueLenPtr, valueLenBytes)
+	// Write to WASM memory
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, uint32(i32Value))
+	if !mod.Memory().Write(outPtr, buf) {
+		stack[0] = 3
+		return
+	}
 
 	stack[0] = 0 // Success
 }
@@ -681,17 +730,18 @@ func (hf *HostFunctions) getParamBool(ctx context.Context, mod api.Module, stack
 	stack[0] = 0 // Success
 }
 
-// uint32ToBytes converts uint32 to byte slice
+// uint32ToBytes converts uint32 to a little-endian byte slice, the byte
+// order of WASM linear memory
 func uint32ToBytes(v uint32) []byte {
 	bytes := make([]byte, 4)
-	*(*uint32)(unsafe.Pointer(&bytes[0])) = v
+	binary.LittleEndian.PutUint32(bytes, v)
 	return bytes
 }
 
-// bytesToUint32 converts byte slice to uint32
+// bytesToUint32 converts a little-endian byte slice to uint32
 func bytesToUint32(bytes []byte) uint32 {
 	if len(bytes) < 4 {
 		return 0
 	}
-	return *(*uint32)(unsafe.Pointer(&bytes[0]))
+	return binary.LittleEndian.Uint32(bytes)
 }
@@ -0,0 +1,125 @@
+package wasm
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestUint32BytesLittleEndian(t *testing.T) {
+	// WASM memory is little-endian whatever the host's byte order
+	assert.Equal(t, []byte{0x78, 0x56, 0x34, 0x12}, uint32ToBytes(0x12345678))
+	assert.Equal(t, uint32(0x12345678), bytesToUint32([]byte{0x78, 0x56, 0x34, 0x12}))
+
+	for _, v := range []uint32{0, 1, 255, 256, 65536, math.MaxUint32} {
+		assert.Equal(t, v, bytesToUint32(uint32ToBytes(v)))
+	}
+
+	// Extra bytes are ignored and short slices read as zero
+	assert.Equal(t, uint32(1), bytesToUint32([]byte{1, 0, 0, 0, 0xff}))
+	assert.Equal(t, uint32(0), bytesToUint32([]byte{1, 2, 3}))
+}
+
+// memoryOnlyWasm is a module exporting one page of memory, for calling host
+// functions directly
+var memoryOnlyWasm = []byte{
+	0x00, 0x61, 0x73, 0x6d, // WASM magic
+	0x01, 0x00, 0x00, 0x00, // Version
+
+	// Memory section: 1 page
+	0x05, 0x03, 0x01, 0x00, 0x01,
+
+	// Export section: "memory"
+	0x07, 0x0a, 0x01, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x02, 0x00,
+}
+
+func TestStringHostFunctionsBufferSize(t *testing.T) {
+	rt, err := NewRuntime(&Config{Logger: zap.NewNop()})
+	require.NoError(t, err)
+	defer rt.Close()
+
+	ctx := context.Background()
+	mod, err := rt.GetWazeroRuntime().Instantiate(ctx, memoryOnlyWasm)
+	require.NoError(t, err)
+	mem := mod.Memory()
+
+	hf := NewHostFunctions(rt)
+	ctxID := hf.RegisterContext(NewDocumentContextFromMap("doc-42", 1.0, map[string]interface{}{
+		"title": "hello world",
+	}))
+
+	const (
+		fieldPtr     = 0
+		resultPtr    = 64
+		resultLenPtr = 128
+		canaryPtr    = resultPtr + 4
+	)
+	require.True(t, mem.Write(fieldPtr, []byte("title")))
+
+	callGetFieldString := func(bufferSize uint32) uint64 {
+		require.True(t, mem.WriteUint32Le(resultLenPtr, bufferSize))
+		stack := []uint64{ctxID, fieldPtr, 5, resultPtr, resultLenPtr}
+		hf.getFieldString(ctx, mod, stack)
+		return stack[0]
+	}
+	callGetDocumentID := func(bufferSize uint32) uint64 {
+		require.True(t, mem.WriteUint32Le(resultLenPtr, bufferSize))
+		stack := []uint64{ctxID, resultPtr, resultLenPtr}
+		hf.getDocumentID(ctx, mod, stack)
+		return stack[0]
+	}
+	resultLen := func() uint32 {
+		length, ok := mem.ReadUint32Le(resultLenPtr)
+		require.True(t, ok)
+		return length
+	}
+
+	t.Run("FieldBufferTooSmall", func(t *testing.T) {
+		require.True(t, mem.Write(resultPtr, []byte("....canary")))
+		assert.Equal(t, uint64(3), callGetFieldString(4))
+		assert.Equal(t, uint32(len("hello world")), resultLen(), "required size is reported")
+
+		// Nothing past the caller's buffer is overwritten
+		canary, _ := mem.Read(canaryPtr, 6)
+		assert.Equal(t, "canary", string(canary))
+	})
+
+	t.Run("FieldBufferSized", func(t *testing.T) {
+		assert.Equal(t, uint64(0), callGetFieldString(uint32(len("hello world"))))
+		assert.Equal(t, uint32(len("hello world")), resultLen())
+		value, _ := mem.Read(resultPtr, resultLen())
+		assert.Equal(t, "hello world", string(value))
+	})
+
+	t.Run("FieldNotFound", func(t *testing.T) {
+		stack := []uint64{ctxID, fieldPtr, 3, resultPtr, resultLenPtr} // "tit"
+		hf.getFieldString(ctx, mod, stack)
+		assert.Equal(t, uint64(1), stack[0])
+	})
+
+	t.Run("DocumentIDBufferTooSmall", func(t *testing.T) {
+		require.True(t, mem.Write(resultPtr, []byte("....canary")))
+		assert.Equal(t, uint64(3), callGetDocumentID(2))
+		assert.Equal(t, uint32(len("doc-42")), resultLen())
+
+		canary, _ := mem.Read(canaryPtr, 6)
+		assert.Equal(t, "canary", string(canary))
+	})
+
+	t.Run("DocumentIDBufferSized", func(t *testing.T) {
+		assert.Equal(t, uint64(0), callGetDocumentID(256))
+		assert.Equal(t, uint32(len("doc-42")), resultLen())
+		value, _ := mem.Read(resultPtr, resultLen())
+		assert.Equal(t, "doc-42", string(value))
+	})
+
+	t.Run("UnknownContext", func(t *testing.T) {
+		stack := []uint64{ctxID + 1, resultPtr, resultLenPtr}
+		hf.getDocumentID(ctx, mod, stack)
+		assert.Equal(t, uint64(1), stack[0])
+	})
+}
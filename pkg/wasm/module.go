@@ -2,8 +2,12 @@ package wasm
 
 import (
 	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/api"
@@ -12,13 +16,27 @@ import (
 
 // ModuleInstance represents an instantiated WASM module
 type ModuleInstance struct {
-	name     string
-	module   api.Module
-	runtime  *Runtime
-	logger   *zap.Logger
-	mu       sync.RWMutex
+	name    string
+	module  api.Module
+	runtime *Runtime
+	logger  *zap.Logger
+	mu      sync.RWMutex
+
+	// Host-owned scratch area for the string-return convention, allocated on
+	// first use and reused for the lifetime of the instance
+	retArea      uint32
+	retAreaReady bool
+
+	// Host-owned scratch area for batch calls, grown to fit the largest
+	// batch seen so far
+	batchArea     uint32
+	batchAreaSize uint32
 }
 
+// stringReturnAreaSize is the size of the (ptr u32, len u32) pair a UDF
+// writes when it returns a string
+const stringReturnAreaSize = 8
+
 // NewModuleInstance creates a new module instance
 func (r *Runtime) NewModuleInstance(moduleName string) (*ModuleInstance, error) {
 	compiledModule, err := r.GetModule(moduleName)
@@ -150,6 +168,75 @@ func (mi *ModuleInstance) WriteBytes(data []byte, offset uint32) error {
 	return nil
 }
 
+// StringReturnArea returns the offset of an 8-byte buffer the host passes to
+// string-returning UDFs. The buffer lives in a page the host grows onto the
+// instance memory, so it never overlaps data owned by the module.
+func (mi *ModuleInstance) StringReturnArea() (uint32, error) {
+	mi.mu.Lock()
+	defer mi.mu.Unlock()
+
+	if mi.retAreaReady {
+		return mi.retArea, nil
+	}
+
+	memory := mi.module.Memory()
+	if memory == nil {
+		return 0, fmt.Errorf("module has no memory")
+	}
+
+	prevPages, ok := memory.Grow(1)
+	if !ok {
+		return 0, fmt.Errorf("failed to grow memory for string return area")
+	}
+
+	mi.retArea = prevPages * 65536
+	mi.retAreaReady = true
+
+	return mi.retArea, nil
+}
+
+// BatchArea returns the offset of a host-owned buffer of at least size bytes
+// for passing batch call arguments and results. Like the string return area
+// it lives in pages the host grows onto the instance memory.
+func (mi *ModuleInstance) BatchArea(size uint32) (uint32, error) {
+	mi.mu.Lock()
+	defer mi.mu.Unlock()
+
+	if size <= mi.batchAreaSize {
+		return mi.batchArea, nil
+	}
+
+	memory := mi.module.Memory()
+	if memory == nil {
+		return 0, fmt.Errorf("module has no memory")
+	}
+
+	pages := (size + 65535) / 65536
+	prevPages, ok := memory.Grow(pages)
+	if !ok {
+		return 0, fmt.Errorf("failed to grow memory for %d byte batch area", size)
+	}
+
+	mi.batchArea = prevPages * 65536
+	mi.batchAreaSize = pages * 65536
+
+	return mi.batchArea, nil
+}
+
+// ReadStringReturn decodes the (ptr, len) pair a UDF wrote into its return
+// area and reads the referenced string from memory
+func (mi *ModuleInstance) ReadStringReturn(area uint32) (string, error) {
+	header, err := mi.ReadBytes(area, stringReturnAreaSize)
+	if err != nil {
+		return "", err
+	}
+
+	ptr := binary.LittleEndian.Uint32(header[0:4])
+	length := binary.LittleEndian.Uint32(header[4:8])
+
+	return mi.ReadString(ptr, length)
+}
+
 // GetMemorySize returns the current memory size in bytes
 func (mi *ModuleInstance) GetMemorySize() uint32 {
 	memory := mi.GetMemory()
@@ -198,6 +285,10 @@ func (mi *ModuleInstance) GetName() string {
 	return mi.name
 }
 
+// ErrPoolExhausted is returned by a bounded acquire when no pooled instance
+// becomes available in time
+var ErrPoolExhausted = errors.New("udf_pool_exhausted")
+
 // ModulePool manages a pool of reusable module instances
 type ModulePool struct {
 	runtime  *Runtime
@@ -206,6 +297,20 @@ type ModulePool struct {
 	size     int
 	logger   *zap.Logger
 	mu       sync.RWMutex
+
+	// Utilization tracking
+	inUse     int64
+	peakInUse int64
+	exhausted uint64
+}
+
+// PoolStats describes the current utilization of a module pool
+type PoolStats struct {
+	Size      int    `json:"size"`
+	Available int    `json:"available"`
+	InUse     int64  `json:"in_use"`
+	PeakInUse int64  `json:"peak_in_use"`
+	Exhausted uint64 `json:"exhausted"`
 }
 
 // NewModulePool creates a pool of module instances for reuse
@@ -243,16 +348,78 @@ func (r *Runtime) NewModulePool(moduleName string, poolSize int) (*ModulePool, e
 func (mp *ModulePool) Get() (*ModuleInstance, error) {
 	select {
 	case instance := <-mp.pool:
+		mp.markAcquired()
 		return instance, nil
 	default:
 		// Pool exhausted, create a new instance
 		mp.logger.Warn("Module pool exhausted, creating new instance")
-		return mp.runtime.NewModuleInstance(mp.module)
+		instance, err := mp.runtime.NewModuleInstance(mp.module)
+		if err != nil {
+			return nil, err
+		}
+		mp.markAcquired()
+		return instance, nil
+	}
+}
+
+// Acquire retrieves an instance from the pool without growing it.
+// If every instance is busy it waits up to timeout (or until ctx is done);
+// a non-positive timeout fails fast. ErrPoolExhausted is returned when no
+// instance became available.
+func (mp *ModulePool) Acquire(ctx context.Context, timeout time.Duration) (*ModuleInstance, error) {
+	select {
+	case instance := <-mp.pool:
+		mp.markAcquired()
+		return instance, nil
+	default:
+	}
+
+	if timeout <= 0 {
+		atomic.AddUint64(&mp.exhausted, 1)
+		return nil, ErrPoolExhausted
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case instance := <-mp.pool:
+		mp.markAcquired()
+		return instance, nil
+	case <-timer.C:
+		atomic.AddUint64(&mp.exhausted, 1)
+		return nil, ErrPoolExhausted
+	case <-ctx.Done():
+		atomic.AddUint64(&mp.exhausted, 1)
+		return nil, fmt.Errorf("%w: %v", ErrPoolExhausted, ctx.Err())
+	}
+}
+
+// markAcquired records an instance leaving the pool
+func (mp *ModulePool) markAcquired() {
+	inUse := atomic.AddInt64(&mp.inUse, 1)
+	for {
+		peak := atomic.LoadInt64(&mp.peakInUse)
+		if inUse <= peak || atomic.CompareAndSwapInt64(&mp.peakInUse, peak, inUse) {
+			return
+		}
+	}
+}
+
+// Stats returns the current pool utilization
+func (mp *ModulePool) Stats() PoolStats {
+	return PoolStats{
+		Size:      mp.size,
+		Available: len(mp.pool),
+		InUse:     atomic.LoadInt64(&mp.inUse),
+		PeakInUse: atomic.LoadInt64(&mp.peakInUse),
+		Exhausted: atomic.LoadUint64(&mp.exhausted),
 	}
 }
 
 // Put returns an instance to the pool
 func (mp *ModulePool) Put(instance *ModuleInstance) {
+	atomic.AddInt64(&mp.inUse, -1)
 	select {
 	case mp.pool <- instance:
 		// Instance returned to pool
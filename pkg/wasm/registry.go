@@ -2,29 +2,58 @@ package wasm
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
 	"go.uber.org/zap"
 )
 
+// ErrChecksumMismatch is returned when a UDF's declared WASM hash doesn't
+// match its bytes
+var ErrChecksumMismatch = errors.New("udf_checksum_mismatch")
+
+// ErrSignatureMismatch is returned when a UDF's declared parameters/returns
+// don't match the signature of the exported WASM function
+var ErrSignatureMismatch = errors.New("udf_signature_mismatch")
+
 // UDFRegistry manages User-Defined Functions (UDFs) compiled as WASM modules
 type UDFRegistry struct {
-	runtime      *Runtime
-	hostFuncs    *HostFunctions
-	logger       *zap.Logger
+	runtime   *Runtime
+	hostFuncs *HostFunctions
+	logger    *zap.Logger
 
 	// UDF storage
-	udfs         map[string]*RegisteredUDF // name@version → UDF
-	pools        map[string]*ModulePool    // name@version → pool
-	stats        map[string]*UDFStats      // name@version → stats
+	udfs  map[string]*RegisteredUDF // name@version → UDF
+	pools map[string]*ModulePool    // name@version → pool
+	stats map[string]*UDFStats      // name@version → stats
+
+	// Compiled modules shared by UDFs uploaded with identical bytes
+	modules map[string]*sharedModule // WASM hash → module
 
 	// Configuration
-	defaultPoolSize int
-	enableStats     bool
+	defaultPoolSize       int
+	defaultAcquireTimeout time.Duration
+	enableStats           bool
+	strictSignatures      bool
+	resultCacheSize       int
+	store                 UDFStore
+
+	mu sync.RWMutex
+}
 
-	mu               sync.RWMutex
+// sharedModule is a compiled module and the bytes it was compiled from,
+// shared by every UDF registered with the same bytes
+type sharedModule struct {
+	name  string
+	bytes []byte
+	refs  int
 }
 
 // RegisteredUDF represents a registered WASM UDF
@@ -32,15 +61,30 @@ type RegisteredUDF struct {
 	Metadata   *UDFMetadata
 	ModuleName string // Internal module name in runtime
 	Pool       *ModulePool
+	PoolConfig UDFPoolConfig
 	Stats      *UDFStats
+
+	// cache holds results of deterministic UDFs (nil = not cached)
+	cache *udfResultCache
+}
+
+// UDFPoolConfig controls instance pooling and backpressure for a single UDF
+type UDFPoolConfig struct {
+	Size           int           // Instances warmed up at registration (0 = no pooling)
+	AcquireTimeout time.Duration // Max wait for a free instance when the pool is saturated
+	FailFast       bool          // Return ErrPoolExhausted immediately when saturated
 }
 
 // UDFRegistryConfig configures the UDF registry
 type UDFRegistryConfig struct {
-	Runtime         *Runtime
-	DefaultPoolSize int  // Default module pool size (0 = no pooling)
-	EnableStats     bool // Enable call statistics
-	Logger          *zap.Logger
+	Runtime               *Runtime
+	DefaultPoolSize       int           // Default module pool size (0 = no pooling)
+	DefaultAcquireTimeout time.Duration // Default wait for a free pooled instance (0 = 5s)
+	EnableStats           bool          // Enable call statistics
+	StrictSignatures      bool          // Reject UDFs whose declared signature doesn't match the WASM export
+	ResultCacheSize       int           // Results cached per deterministic UDF (0 = DefaultResultCacheSize, <0 = no caching)
+	Store                 UDFStore      // Persists registered UDFs, which are restored on creation (nil = in memory only)
+	Logger                *zap.Logger
 }
 
 // NewUDFRegistry creates a new UDF registry
@@ -57,6 +101,14 @@ func NewUDFRegistry(cfg *UDFRegistryConfig) (*UDFRegistry, error) {
 		cfg.DefaultPoolSize = 10
 	}
 
+	if cfg.DefaultAcquireTimeout <= 0 {
+		cfg.DefaultAcquireTimeout = 5 * time.Second
+	}
+
+	if cfg.ResultCacheSize == 0 {
+		cfg.ResultCacheSize = DefaultResultCacheSize
+	}
+
 	// Create host functions
 	hostFuncs := NewHostFunctions(cfg.Runtime)
 	if err := hostFuncs.RegisterHostFunctions(cfg.Runtime.GetContext(), cfg.Runtime.GetWazeroRuntime()); err != nil {
@@ -64,23 +116,41 @@ func NewUDFRegistry(cfg *UDFRegistryConfig) (*UDFRegistry, error) {
 	}
 
 	registry := &UDFRegistry{
-		runtime:         cfg.Runtime,
-		hostFuncs:       hostFuncs,
-		logger:          cfg.Logger.With(zap.String("component", "udf_registry")),
-		udfs:            make(map[string]*RegisteredUDF),
-		pools:           make(map[string]*ModulePool),
-		stats:           make(map[string]*UDFStats),
-		defaultPoolSize: cfg.DefaultPoolSize,
-		enableStats:     cfg.EnableStats,
+		runtime:               cfg.Runtime,
+		hostFuncs:             hostFuncs,
+		logger:                cfg.Logger.With(zap.String("component", "udf_registry")),
+		udfs:                  make(map[string]*RegisteredUDF),
+		pools:                 make(map[string]*ModulePool),
+		stats:                 make(map[string]*UDFStats),
+		modules:               make(map[string]*sharedModule),
+		defaultPoolSize:       cfg.DefaultPoolSize,
+		defaultAcquireTimeout: cfg.DefaultAcquireTimeout,
+		enableStats:           cfg.EnableStats,
+		strictSignatures:      cfg.StrictSignatures,
+		resultCacheSize:       cfg.ResultCacheSize,
+		store:                 cfg.Store,
 	}
 
 	registry.logger.Info("UDF registry initialized",
 		zap.Int("default_pool_size", cfg.DefaultPoolSize),
+		zap.Duration("default_acquire_timeout", cfg.DefaultAcquireTimeout),
 		zap.Bool("stats_enabled", cfg.EnableStats))
 
+	if registry.store != nil {
+		registry.restore()
+	}
+
 	return registry, nil
 }
 
+// DefaultPoolConfig returns the pool settings applied by Register
+func (r *UDFRegistry) DefaultPoolConfig() UDFPoolConfig {
+	return UDFPoolConfig{
+		Size:           r.defaultPoolSize,
+		AcquireTimeout: r.defaultAcquireTimeout,
+	}
+}
+
 // Register registers a new UDF
 func (r *UDFRegistry) Register(metadata *UDFMetadata) error {
 	return r.RegisterWithPoolSize(metadata, r.defaultPoolSize)
@@ -88,6 +158,25 @@ func (r *UDFRegistry) Register(metadata *UDFMetadata) error {
 
 // RegisterWithPoolSize registers a new UDF with a specific pool size
 func (r *UDFRegistry) RegisterWithPoolSize(metadata *UDFMetadata, poolSize int) error {
+	return r.RegisterWithPoolConfig(metadata, UDFPoolConfig{
+		Size:           poolSize,
+		AcquireTimeout: r.defaultAcquireTimeout,
+	})
+}
+
+// RegisterWithPoolConfig registers a new UDF with explicit pool settings.
+// The pool is warmed up eagerly so the first calls don't pay instantiation cost.
+func (r *UDFRegistry) RegisterWithPoolConfig(metadata *UDFMetadata, poolCfg UDFPoolConfig) error {
+	return r.register(metadata, poolCfg, true)
+}
+
+// register registers a UDF, recording it in the store if persist is set
+func (r *UDFRegistry) register(metadata *UDFMetadata, poolCfg UDFPoolConfig, persist bool) error {
+	if poolCfg.AcquireTimeout <= 0 && !poolCfg.FailFast {
+		poolCfg.AcquireTimeout = r.defaultAcquireTimeout
+	}
+	poolSize := poolCfg.Size
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -108,27 +197,37 @@ func (r *UDFRegistry) RegisterWithPoolSize(metadata *UDFMetadata, poolSize int)
 		zap.String("version", metadata.Version),
 		zap.String("function", metadata.FunctionName))
 
-	// Compile module
-	moduleName := fmt.Sprintf("udf_%s_%s", metadata.Name, metadata.Version)
-	moduleMetadata := &ModuleMetadata{
-		Name:        moduleName,
-		Version:     metadata.Version,
-		Description: metadata.Description,
-		Author:      metadata.Author,
+	// Verify the declared checksum before anything is compiled
+	hash := WASMHash(metadata.WASMBytes)
+	if metadata.WASMHash != "" && metadata.WASMHash != hash {
+		return fmt.Errorf("%w: declared %s, WASM bytes hash to %s",
+			ErrChecksumMismatch, metadata.WASMHash, hash)
 	}
+	metadata.WASMHash = hash
 
-	if err := r.runtime.CompileModule(moduleName, metadata.WASMBytes, moduleMetadata); err != nil {
-		return fmt.Errorf("failed to compile UDF: %w", err)
+	// Compile module, or share the one compiled from identical bytes
+	module, err := r.acquireModule(metadata)
+	if err != nil {
+		return err
 	}
+	moduleName := module.name
+	metadata.WASMBytes = module.bytes
 
-	// Create module pool if requested
+	if r.strictSignatures {
+		if err := r.validateSignature(metadata, moduleName); err != nil {
+			r.releaseModule(hash)
+			return err
+		}
+	}
+
+	// Create and warm up module pool if requested
 	var pool *ModulePool
 	if poolSize > 0 {
 		var err error
 		pool, err = r.runtime.NewModulePool(moduleName, poolSize)
 		if err != nil {
 			// Cleanup compiled module
-			r.runtime.UnloadModule(moduleName)
+			r.releaseModule(hash)
 			return fmt.Errorf("failed to create module pool: %w", err)
 		}
 		r.pools[fullName] = pool
@@ -147,13 +246,19 @@ func (r *UDFRegistry) RegisterWithPoolSize(metadata *UDFMetadata, poolSize int)
 		Metadata:   metadata,
 		ModuleName: moduleName,
 		Pool:       pool,
+		PoolConfig: poolCfg,
+	}
+
+	if metadata.Deterministic && r.resultCacheSize > 0 {
+		registered.cache = newUDFResultCache(fullName, r.resultCacheSize)
 	}
 
 	// Initialize stats if enabled
 	if r.enableStats {
 		stats := &UDFStats{
-			Name:    metadata.Name,
-			Version: metadata.Version,
+			Name:     metadata.Name,
+			Version:  metadata.Version,
+			WASMHash: hash,
 		}
 		r.stats[fullName] = stats
 		registered.Stats = stats
@@ -161,14 +266,80 @@ func (r *UDFRegistry) RegisterWithPoolSize(metadata *UDFMetadata, poolSize int)
 
 	r.udfs[fullName] = registered
 
+	if persist && r.store != nil {
+		if err := r.store.Save(&StoredUDF{Metadata: metadata, PoolConfig: poolCfg}); err != nil {
+			r.drop(fullName, registered)
+			return fmt.Errorf("failed to persist UDF: %w", err)
+		}
+	}
+
 	r.logger.Info("UDF registered successfully",
 		zap.String("name", metadata.Name),
 		zap.String("version", metadata.Version),
-		zap.Int("pool_size", poolSize))
+		zap.Int("pool_size", poolSize),
+		zap.String("wasm_hash", hash),
+		zap.Int("module_refs", module.refs),
+		zap.Duration("acquire_timeout", poolCfg.AcquireTimeout),
+		zap.Bool("fail_fast", poolCfg.FailFast),
+		zap.Bool("deterministic", metadata.Deterministic))
 
 	return nil
 }
 
+// WASMHash returns the content hash identifying a UDF's WASM bytes: their
+// hex-encoded SHA-256
+func WASMHash(wasmBytes []byte) string {
+	sum := sha256.Sum256(wasmBytes)
+	return hex.EncodeToString(sum[:])
+}
+
+// acquireModule returns the compiled module for a UDF's bytes, compiling
+// them unless a module compiled from identical bytes is already loaded.
+// Must be called with r.mu held.
+func (r *UDFRegistry) acquireModule(metadata *UDFMetadata) (*sharedModule, error) {
+	if module, exists := r.modules[metadata.WASMHash]; exists {
+		module.refs++
+		return module, nil
+	}
+
+	moduleName := "udf_" + metadata.WASMHash
+	moduleMetadata := &ModuleMetadata{
+		Name:        moduleName,
+		Version:     metadata.Version,
+		Description: metadata.Description,
+		Author:      metadata.Author,
+	}
+
+	if err := r.runtime.CompileModule(moduleName, metadata.WASMBytes, moduleMetadata); err != nil {
+		return nil, fmt.Errorf("failed to compile UDF: %w", err)
+	}
+
+	module := &sharedModule{name: moduleName, bytes: metadata.WASMBytes, refs: 1}
+	r.modules[metadata.WASMHash] = module
+	return module, nil
+}
+
+// releaseModule drops a UDF's reference to its compiled module, unloading
+// it with the last reference. Must be called with r.mu held.
+func (r *UDFRegistry) releaseModule(hash string) {
+	module, exists := r.modules[hash]
+	if !exists {
+		return
+	}
+
+	module.refs--
+	if module.refs > 0 {
+		return
+	}
+	delete(r.modules, hash)
+
+	if err := r.runtime.UnloadModule(module.name); err != nil {
+		r.logger.Warn("Failed to unload module",
+			zap.String("module", module.name),
+			zap.Error(err))
+	}
+}
+
 // Unregister removes a UDF from the registry
 func (r *UDFRegistry) Unregister(name, version string) error {
 	r.mu.Lock()
@@ -185,28 +356,61 @@ func (r *UDFRegistry) Unregister(name, version string) error {
 		zap.String("name", name),
 		zap.String("version", version))
 
+	// Forget the UDF durably first, so a failure can't bring it back on
+	// the next restart
+	if r.store != nil {
+		if err := r.store.Delete(name, version); err != nil {
+			return fmt.Errorf("failed to delete persisted UDF %s: %w", fullName, err)
+		}
+	}
+
+	r.drop(fullName, registered)
+
+	r.logger.Info("UDF unregistered successfully",
+		zap.String("name", name),
+		zap.String("version", version))
+
+	return nil
+}
+
+// drop removes a UDF from the registry, closing its pool and releasing its
+// module. Must be called with r.mu held.
+func (r *UDFRegistry) drop(fullName string, registered *RegisteredUDF) {
 	// Close pool if exists
 	if pool, exists := r.pools[fullName]; exists {
 		pool.Close()
 		delete(r.pools, fullName)
 	}
 
-	// Unload module
-	if err := r.runtime.UnloadModule(registered.ModuleName); err != nil {
-		r.logger.Warn("Failed to unload module",
-			zap.String("module", registered.ModuleName),
-			zap.Error(err))
-	}
+	// Unload module once no other UDF shares it
+	r.releaseModule(registered.Metadata.WASMHash)
 
 	// Remove from registry
 	delete(r.udfs, fullName)
 	delete(r.stats, fullName)
+}
 
-	r.logger.Info("UDF unregistered successfully",
-		zap.String("name", name),
-		zap.String("version", version))
+// restore registers the UDFs recorded in the store. UDFs that no longer
+// load or register are logged and skipped rather than failing startup.
+func (r *UDFRegistry) restore() {
+	records, err := r.store.Load()
+	if err != nil {
+		r.logger.Warn("Failed to load some persisted UDFs", zap.Error(err))
+	}
 
-	return nil
+	restored := 0
+	for _, record := range records {
+		if err := r.register(record.Metadata, record.PoolConfig, false); err != nil {
+			r.logger.Warn("Failed to restore persisted UDF",
+				zap.String("name", record.Metadata.Name),
+				zap.String("version", record.Metadata.Version),
+				zap.Error(err))
+			continue
+		}
+		restored++
+	}
+
+	r.logger.Info("Restored persisted UDFs", zap.Int("count", restored))
 }
 
 // Get retrieves a registered UDF
@@ -248,6 +452,28 @@ func (r *UDFRegistry) GetLatest(name string) (*RegisteredUDF, error) {
 	return latest, nil
 }
 
+// LatestVersion is the version alias that resolves to the most recently
+// registered version of a UDF
+const LatestVersion = "latest"
+
+// ResolveVersion returns the concrete version for a UDF reference. An empty
+// version or LatestVersion resolves to the most recently registered version.
+func (r *UDFRegistry) ResolveVersion(name, version string) (string, error) {
+	if version == "" || version == LatestVersion {
+		latest, err := r.GetLatest(name)
+		if err != nil {
+			return "", err
+		}
+		return latest.Metadata.Version, nil
+	}
+
+	if _, err := r.Get(name, version); err != nil {
+		return "", err
+	}
+
+	return version, nil
+}
+
 // List returns all registered UDFs
 func (r *UDFRegistry) List() []*UDFMetadata {
 	r.mu.RLock()
@@ -295,38 +521,40 @@ func (r *UDFRegistry) Call(ctx context.Context, name, version string, docCtx *Do
 		return nil, fmt.Errorf("parameter validation failed: %w", err)
 	}
 
-	// Register parameters for host function access
-	paramMap := make(map[string]interface{})
-	for name, val := range params {
-		// Convert Value to native Go type
-		switch val.Type {
-		case ValueTypeI32:
-			if v, err := val.AsInt32(); err == nil {
-				paramMap[name] = v
-			}
-		case ValueTypeI64:
-			if v, err := val.AsInt64(); err == nil {
-				paramMap[name] = v
-			}
-		case ValueTypeF32:
-			if v, err := val.AsFloat32(); err == nil {
-				paramMap[name] = v
-			}
-		case ValueTypeF64:
-			if v, err := val.AsFloat64(); err == nil {
-				paramMap[name] = v
-			}
-		case ValueTypeString:
-			if v, err := val.AsString(); err == nil {
-				paramMap[name] = v
-			}
-		case ValueTypeBool:
-			if v, err := val.AsBool(); err == nil {
-				paramMap[name] = v
+	// Deterministic UDFs skip execution when a call with the same
+	// parameters has seen the same values for the fields it read
+	var paramsKey string
+	cached := false
+	if registered.cache != nil && docCtx != nil {
+		if key, err := cacheParamsKey(params); err == nil {
+			paramsKey = key
+			cached = true
+		}
+	}
+	if cached {
+		if values, ok := registered.cache.get(paramsKey, docCtx); ok {
+			if r.enableStats && registered.Stats != nil {
+				registered.Stats.CacheHits++
 			}
+			return values, nil
+		}
+		if r.enableStats && registered.Stats != nil {
+			registered.Stats.CacheMisses++
 		}
+		docCtx.trackReads()
 	}
-	r.hostFuncs.RegisterParameters(paramMap)
+	var values []Value
+	defer func() {
+		if cached {
+			fields := docCtx.stopTrackingReads()
+			if values != nil {
+				registered.cache.put(paramsKey, fields, docCtx, values)
+			}
+		}
+	}()
+
+	// Register parameters for host function access
+	r.hostFuncs.RegisterParameters(nativeParameters(params))
 	defer r.hostFuncs.UnregisterParameters()
 
 	// Convert parameters to uint64 array
@@ -336,19 +564,22 @@ func (r *UDFRegistry) Call(ctx context.Context, name, version string, docCtx *Do
 	}
 
 	// Get module instance
-	var instance *ModuleInstance
-	if registered.Pool != nil {
-		instance, err = registered.Pool.Get()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get instance from pool: %w", err)
-		}
-		defer registered.Pool.Put(instance)
-	} else {
-		instance, err = r.runtime.NewModuleInstance(registered.ModuleName)
+	instance, release, err := r.acquireInstance(ctx, registered, startTime)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// String-returning UDFs receive a trailing pointer to a host-owned
+	// (ptr, len) pair that they fill in instead of returning a value
+	var retArea uint32
+	stringReturn := returnsString(registered.Metadata)
+	if stringReturn {
+		retArea, err = instance.StringReturnArea()
 		if err != nil {
-			return nil, fmt.Errorf("failed to create instance: %w", err)
+			return nil, fmt.Errorf("failed to allocate string return area: %w", err)
 		}
-		defer instance.Close()
+		wasmParams = append(wasmParams, uint64(retArea))
 	}
 
 	// Call function
@@ -364,8 +595,17 @@ func (r *UDFRegistry) Call(ctx context.Context, name, version string, docCtx *Do
 		return nil, fmt.Errorf("UDF call failed: %w", err)
 	}
 
+	if stringReturn {
+		str, err := instance.ReadStringReturn(retArea)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read string result: %w", err)
+		}
+		values = []Value{NewStringValue(str)}
+		return values, nil
+	}
+
 	// Convert results
-	values, err := r.convertResults(registered.Metadata, results)
+	values, err = r.convertResults(registered.Metadata, results)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert results: %w", err)
 	}
@@ -373,6 +613,205 @@ func (r *UDFRegistry) Call(ctx context.Context, name, version string, docCtx *Do
 	return values, nil
 }
 
+// BatchFunctionSuffix is appended to a UDF's function name to find its batch
+// entrypoint
+const BatchFunctionSuffix = "_batch"
+
+// ErrBatchUnsupported is returned by CallBatch for UDFs that don't declare
+// supports_batch
+var ErrBatchUnsupported = errors.New("udf_batch_unsupported")
+
+// CallBatch executes a UDF that supports batching over many documents in a
+// single module call and returns each document's results.
+//
+// The batch entrypoint is the function name with BatchFunctionSuffix. It
+// receives a pointer to count little-endian u64 context IDs, the count, the
+// declared non-string parameters, and a pointer to count 8-byte result
+// slots, and writes each document's result into its slot encoded as it would
+// return it from a single call. Results of deterministic UDFs already cached
+// are not recomputed.
+func (r *UDFRegistry) CallBatch(ctx context.Context, name, version string, docCtxs []*DocumentContext, params map[string]Value) ([][]Value, error) {
+	startTime := time.Now()
+
+	registered, err := r.Get(name, version)
+	if err != nil {
+		return nil, err
+	}
+	if !registered.Metadata.SupportsBatch {
+		return nil, fmt.Errorf("%w: %s", ErrBatchUnsupported, registered.Metadata.GetFullName())
+	}
+
+	if err := r.validateParameters(registered.Metadata, params); err != nil {
+		return nil, fmt.Errorf("parameter validation failed: %w", err)
+	}
+
+	values := make([][]Value, len(docCtxs))
+
+	// Only documents missing from the cache go to the module
+	var paramsKey string
+	if registered.cache != nil {
+		if key, err := cacheParamsKey(params); err == nil {
+			paramsKey = key
+		}
+	}
+	pending := make([]int, 0, len(docCtxs))
+	for i, docCtx := range docCtxs {
+		if paramsKey != "" {
+			if cached, ok := registered.cache.get(paramsKey, docCtx); ok {
+				values[i] = cached
+				if r.enableStats && registered.Stats != nil {
+					registered.Stats.CacheHits++
+				}
+				continue
+			}
+			if r.enableStats && registered.Stats != nil {
+				registered.Stats.CacheMisses++
+			}
+		}
+		pending = append(pending, i)
+	}
+	if len(pending) == 0 {
+		return values, nil
+	}
+
+	ctxIDs := make([]byte, 8*len(pending))
+	for j, i := range pending {
+		ctxID := r.hostFuncs.RegisterContext(docCtxs[i])
+		defer r.hostFuncs.UnregisterContext(ctxID)
+		binary.LittleEndian.PutUint64(ctxIDs[8*j:], ctxID)
+
+		if paramsKey != "" {
+			docCtxs[i].trackReads()
+		}
+	}
+
+	r.hostFuncs.RegisterParameters(nativeParameters(params))
+	defer r.hostFuncs.UnregisterParameters()
+
+	wasmParams, err := r.prepareParameters(registered.Metadata, params, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare parameters: %w", err)
+	}
+
+	instance, release, err := r.acquireInstance(ctx, registered, startTime)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	count := uint32(len(pending))
+	area, err := instance.BatchArea(16 * count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate batch area: %w", err)
+	}
+	if err := instance.WriteBytes(ctxIDs, area); err != nil {
+		return nil, fmt.Errorf("failed to write context IDs: %w", err)
+	}
+	resultsPtr := area + 8*count
+
+	// Swap the leading context ID for the batch arguments
+	batchParams := append([]uint64{uint64(area), uint64(count)}, wasmParams[1:]...)
+	batchParams = append(batchParams, uint64(resultsPtr))
+
+	_, err = instance.CallFunction(ctx, registered.Metadata.FunctionName+BatchFunctionSuffix, batchParams...)
+
+	if r.enableStats && registered.Stats != nil {
+		registered.Stats.UpdateStats(time.Since(startTime), err)
+	}
+
+	var fields [][]string
+	if paramsKey != "" {
+		fields = make([][]string, len(pending))
+		for j, i := range pending {
+			fields[j] = docCtxs[i].stopTrackingReads()
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("UDF batch call failed: %w", err)
+	}
+
+	slots, err := instance.ReadBytes(resultsPtr, 8*count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch results: %w", err)
+	}
+	for j, i := range pending {
+		value, err := FromUint64(binary.LittleEndian.Uint64(slots[8*j:]), registered.Metadata.Returns[0].Type)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert results: %w", err)
+		}
+		values[i] = []Value{value}
+
+		if paramsKey != "" {
+			registered.cache.put(paramsKey, fields[j], docCtxs[i], values[i])
+		}
+	}
+
+	return values, nil
+}
+
+// acquireInstance returns a module instance to call a UDF on and a function
+// releasing it: one from the UDF's pool, or a fresh one without pooling
+func (r *UDFRegistry) acquireInstance(ctx context.Context, registered *RegisteredUDF, startTime time.Time) (*ModuleInstance, func(), error) {
+	if registered.Pool == nil {
+		instance, err := r.runtime.NewModuleInstance(registered.ModuleName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create instance: %w", err)
+		}
+		return instance, func() { instance.Close() }, nil
+	}
+
+	timeout := registered.PoolConfig.AcquireTimeout
+	if registered.PoolConfig.FailFast {
+		timeout = 0
+	}
+	instance, err := registered.Pool.Acquire(ctx, timeout)
+	if err != nil {
+		if r.enableStats && registered.Stats != nil {
+			registered.Stats.UpdateStats(time.Since(startTime), err)
+		}
+		return nil, nil, fmt.Errorf("failed to get instance from pool for UDF %s: %w",
+			registered.Metadata.GetFullName(), err)
+	}
+	return instance, func() { registered.Pool.Put(instance) }, nil
+}
+
+// nativeParameters converts parameters to the Go values host functions
+// expose to UDFs
+func nativeParameters(params map[string]Value) map[string]interface{} {
+	paramMap := make(map[string]interface{})
+	for name, val := range params {
+		// Convert Value to native Go type
+		switch val.Type {
+		case ValueTypeI32:
+			if v, err := val.AsInt32(); err == nil {
+				paramMap[name] = v
+			}
+		case ValueTypeI64:
+			if v, err := val.AsInt64(); err == nil {
+				paramMap[name] = v
+			}
+		case ValueTypeF32:
+			if v, err := val.AsFloat32(); err == nil {
+				paramMap[name] = v
+			}
+		case ValueTypeF64:
+			if v, err := val.AsFloat64(); err == nil {
+				paramMap[name] = v
+			}
+		case ValueTypeString:
+			if v, err := val.AsString(); err == nil {
+				paramMap[name] = v
+			}
+		case ValueTypeBool:
+			if v, err := val.AsBool(); err == nil {
+				paramMap[name] = v
+			}
+		}
+	}
+	return paramMap
+}
+
 // validateParameters validates parameters against UDF metadata
 func (r *UDFRegistry) validateParameters(metadata *UDFMetadata, params map[string]Value) error {
 	// Check required parameters
@@ -404,8 +843,13 @@ func (r *UDFRegistry) prepareParameters(metadata *UDFMetadata, params map[string
 	// First parameter is always context ID
 	wasmParams := []uint64{ctxID}
 
-	// Add parameters in order defined in metadata
+	// Add parameters in order defined in metadata. Strings have no WASM
+	// scalar representation and are only reachable via get_param_string.
 	for _, param := range metadata.Parameters {
+		if param.Type == ValueTypeString {
+			continue
+		}
+
 		value, exists := params[param.Name]
 		if !exists {
 			// Use default value if available
@@ -429,6 +873,135 @@ func (r *UDFRegistry) prepareParameters(metadata *UDFMetadata, params map[string
 	return wasmParams, nil
 }
 
+// returnsString reports whether the UDF uses the string-return convention
+func returnsString(metadata *UDFMetadata) bool {
+	return len(metadata.Returns) == 1 && metadata.Returns[0].Type == ValueTypeString
+}
+
+// wasmSignature returns the WASM-level params and results a UDF export must
+// have for the declared metadata: ctx_id first, declared non-string params in
+// order, and a trailing return-area pointer for string returns.
+func wasmSignature(metadata *UDFMetadata) ([]api.ValueType, []api.ValueType) {
+	params := []api.ValueType{api.ValueTypeI64}
+	for _, param := range metadata.Parameters {
+		if param.Type == ValueTypeString {
+			continue
+		}
+		params = append(params, toWASMValueType(param.Type))
+	}
+
+	if returnsString(metadata) {
+		return append(params, api.ValueTypeI32), []api.ValueType{}
+	}
+
+	results := make([]api.ValueType, 0, len(metadata.Returns))
+	for _, ret := range metadata.Returns {
+		results = append(results, toWASMValueType(ret.Type))
+	}
+
+	return params, results
+}
+
+// toWASMValueType maps a UDF value type to its WASM representation
+func toWASMValueType(typ ValueType) api.ValueType {
+	switch typ {
+	case ValueTypeI64:
+		return api.ValueTypeI64
+	case ValueTypeF32:
+		return api.ValueTypeF32
+	case ValueTypeF64:
+		return api.ValueTypeF64
+	default:
+		// i32, bool and string pointers are all i32 in WASM
+		return api.ValueTypeI32
+	}
+}
+
+// validateSignature checks the declared metadata against the compiled
+// module's exported function
+func (r *UDFRegistry) validateSignature(metadata *UDFMetadata, moduleName string) error {
+	compiled, err := r.runtime.GetModule(moduleName)
+	if err != nil {
+		return err
+	}
+
+	return checkSignature(compiled.CompiledModule, metadata)
+}
+
+// checkSignature checks a compiled module exports the functions the
+// metadata declares, with matching signatures
+func checkSignature(compiled wazero.CompiledModule, metadata *UDFMetadata) error {
+	def, ok := compiled.ExportedFunctions()[metadata.FunctionName]
+	if !ok {
+		return fmt.Errorf("%w: function %s is not exported by the module",
+			ErrSignatureMismatch, metadata.FunctionName)
+	}
+
+	wantParams, wantResults := wasmSignature(metadata)
+	if !equalValueTypes(def.ParamTypes(), wantParams) || !equalValueTypes(def.ResultTypes(), wantResults) {
+		return fmt.Errorf("%w: function %s has signature %s, declared metadata requires %s",
+			ErrSignatureMismatch, metadata.FunctionName,
+			formatSignature(def.ParamTypes(), def.ResultTypes()),
+			formatSignature(wantParams, wantResults))
+	}
+
+	if metadata.SupportsBatch {
+		batchName := metadata.FunctionName + BatchFunctionSuffix
+		def, ok := compiled.ExportedFunctions()[batchName]
+		if !ok {
+			return fmt.Errorf("%w: batch function %s is not exported by the module",
+				ErrSignatureMismatch, batchName)
+		}
+
+		wantParams, wantResults := batchSignature(metadata)
+		if !equalValueTypes(def.ParamTypes(), wantParams) || !equalValueTypes(def.ResultTypes(), wantResults) {
+			return fmt.Errorf("%w: function %s has signature %s, declared metadata requires %s",
+				ErrSignatureMismatch, batchName,
+				formatSignature(def.ParamTypes(), def.ResultTypes()),
+				formatSignature(wantParams, wantResults))
+		}
+	}
+
+	return nil
+}
+
+// batchSignature returns the WASM-level params and results of a UDF's batch
+// entrypoint: the context ID array and its length, declared non-string
+// params in order, and the result slot array
+func batchSignature(metadata *UDFMetadata) ([]api.ValueType, []api.ValueType) {
+	params, _ := wasmSignature(metadata)
+	batch := []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}
+	batch = append(batch, params[1:]...)
+	batch = append(batch, api.ValueTypeI32)
+	return batch, nil
+}
+
+func equalValueTypes(a, b []api.ValueType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func formatSignature(params, results []api.ValueType) string {
+	names := func(types []api.ValueType) string {
+		out := ""
+		for i, t := range types {
+			if i > 0 {
+				out += ", "
+			}
+			out += api.ValueTypeName(t)
+		}
+		return out
+	}
+	return fmt.Sprintf("(%s) -> (%s)", names(params), names(results))
+}
+
 // convertResults converts WASM results to Value array
 func (r *UDFRegistry) convertResults(metadata *UDFMetadata, results []uint64) ([]Value, error) {
 	if len(results) != len(metadata.Returns) {
@@ -542,6 +1115,22 @@ func (r *UDFRegistry) GetStats(name, version string) (*UDFStats, error) {
 	return &statsCopy, nil
 }
 
+// GetPoolStats returns pool utilization for a UDF
+func (r *UDFRegistry) GetPoolStats(name, version string) (*PoolStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	fullName := fmt.Sprintf("%s@%s", name, version)
+
+	pool, exists := r.pools[fullName]
+	if !exists {
+		return nil, fmt.Errorf("UDF %s not found or pooling not enabled", fullName)
+	}
+
+	stats := pool.Stats()
+	return &stats, nil
+}
+
 // GetAllStats returns statistics for all UDFs
 func (r *UDFRegistry) GetAllStats() map[string]*UDFStats {
 	r.mu.RLock()
@@ -572,6 +1161,7 @@ func (r *UDFRegistry) Close() error {
 	r.udfs = nil
 	r.pools = nil
 	r.stats = nil
+	r.modules = nil
 
 	r.logger.Info("UDF registry shut down successfully")
 
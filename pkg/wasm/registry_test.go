@@ -1,7 +1,10 @@
 package wasm
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -88,6 +91,20 @@ func TestUDFMetadataValidationErrors(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "string return with other returns",
+			metadata: &UDFMetadata{
+				Name:         "test",
+				Version:      "1.0.0",
+				FunctionName: "test",
+				WASMBytes:    addWasmBytes,
+				Returns: []UDFReturnType{
+					{Type: ValueTypeString},
+					{Type: ValueTypeI32},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -375,6 +392,21 @@ func TestUDFRegistryGetLatest(t *testing.T) {
 		t.Errorf("Expected latest version '2.0.0', got '%s'", latest.Metadata.Version)
 	}
 
+	// ResolveVersion should honor the latest alias and pinned versions
+	for version, want := range map[string]string{"": "2.0.0", LatestVersion: "2.0.0", "1.0.0": "1.0.0"} {
+		resolved, err := registry.ResolveVersion("test", version)
+		if err != nil {
+			t.Fatalf("Failed to resolve version %q: %v", version, err)
+		}
+		if resolved != want {
+			t.Errorf("ResolveVersion(%q) = %q, want %q", version, resolved, want)
+		}
+	}
+
+	if _, err := registry.ResolveVersion("test", "3.0.0"); err == nil {
+		t.Error("Expected error resolving unregistered version")
+	}
+
 	t.Log("✅ GetLatest working correctly")
 }
 
@@ -544,6 +576,633 @@ func TestUDFStatsUpdate(t *testing.T) {
 	t.Log("✅ Stats tracking working correctly")
 }
 
+func TestUDFRegistryPoolConcurrency(t *testing.T) {
+	logger := zap.NewNop()
+
+	runtime, err := NewRuntime(&Config{
+		EnableJIT: true,
+		Logger:    logger,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create runtime: %v", err)
+	}
+	defer runtime.Close()
+
+	registry, err := NewUDFRegistry(&UDFRegistryConfig{
+		Runtime: runtime,
+		Logger:  logger,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	defer registry.Close()
+
+	newMetadata := func(name string) *UDFMetadata {
+		return &UDFMetadata{
+			Name:         name,
+			Version:      "1.0.0",
+			FunctionName: "add",
+			WASMBytes:    addWasmBytes,
+			Parameters: []UDFParameter{
+				{Name: "b", Type: ValueTypeI32, Required: true},
+			},
+			Returns: []UDFReturnType{{Type: ValueTypeI32}},
+		}
+	}
+	params := map[string]Value{"b": NewI32Value(2)}
+
+	t.Run("BlockingAcquire", func(t *testing.T) {
+		poolSize := 2
+		err := registry.RegisterWithPoolConfig(newMetadata("blocking"), UDFPoolConfig{
+			Size:           poolSize,
+			AcquireTimeout: 5 * time.Second,
+		})
+		if err != nil {
+			t.Fatalf("Failed to register UDF: %v", err)
+		}
+
+		// Warmup instantiated the whole pool up front
+		poolStats, err := registry.GetPoolStats("blocking", "1.0.0")
+		if err != nil {
+			t.Fatalf("Failed to get pool stats: %v", err)
+		}
+		if poolStats.Available != poolSize {
+			t.Errorf("Expected %d warm instances, got %d", poolSize, poolStats.Available)
+		}
+
+		// Drive more parallel calls than the pool size
+		callers := poolSize * 8
+		var wg sync.WaitGroup
+		errs := make(chan error, callers)
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := registry.Call(context.Background(), "blocking", "1.0.0", NewDocumentContextFromMap("doc", 1.0, map[string]interface{}{}), params); err != nil {
+					errs <- err
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			t.Errorf("Call failed under contention: %v", err)
+		}
+
+		poolStats, _ = registry.GetPoolStats("blocking", "1.0.0")
+		if poolStats.InUse != 0 {
+			t.Errorf("Expected no instances in use, got %d", poolStats.InUse)
+		}
+		if poolStats.PeakInUse > int64(poolSize) {
+			t.Errorf("Pool grew beyond its size: peak %d > %d", poolStats.PeakInUse, poolSize)
+		}
+		if poolStats.Available != poolSize {
+			t.Errorf("Expected %d instances returned to pool, got %d", poolSize, poolStats.Available)
+		}
+	})
+
+	t.Run("FailFast", func(t *testing.T) {
+		err := registry.RegisterWithPoolConfig(newMetadata("failfast"), UDFPoolConfig{
+			Size:     1,
+			FailFast: true,
+		})
+		if err != nil {
+			t.Fatalf("Failed to register UDF: %v", err)
+		}
+
+		registered, err := registry.Get("failfast", "1.0.0")
+		if err != nil {
+			t.Fatalf("Failed to get UDF: %v", err)
+		}
+
+		// Saturate the pool
+		held, err := registered.Pool.Acquire(context.Background(), 0)
+		if err != nil {
+			t.Fatalf("Failed to acquire instance: %v", err)
+		}
+
+		_, err = registry.Call(context.Background(), "failfast", "1.0.0", NewDocumentContextFromMap("doc", 1.0, map[string]interface{}{}), params)
+		if !errors.Is(err, ErrPoolExhausted) {
+			t.Fatalf("Expected ErrPoolExhausted, got %v", err)
+		}
+
+		registered.Pool.Put(held)
+
+		if _, err := registry.Call(context.Background(), "failfast", "1.0.0", NewDocumentContextFromMap("doc", 1.0, map[string]interface{}{}), params); err != nil {
+			t.Errorf("Call failed after instance was released: %v", err)
+		}
+
+		poolStats, _ := registry.GetPoolStats("failfast", "1.0.0")
+		if poolStats.Exhausted != 1 {
+			t.Errorf("Expected 1 exhausted acquire, got %d", poolStats.Exhausted)
+		}
+	})
+}
+
+// typedWasmBytes exports one function per supported UDF signature, all taking
+// the ctx_id (i64) as their first parameter:
+//
+//	echo_i32(ctx i64, x i32) -> i32      returns x + 1
+//	echo_i64(ctx i64, x i64) -> i64      returns x + 1
+//	echo_f64(ctx i64, x f64) -> f64      returns x + x
+//	negate_bool(ctx i64, x i32) -> i32   returns !x
+//	greet(ctx i64, ret i32)              writes (16, 5) to ret; "hello" lives at offset 16
+var typedWasmBytes = []byte{
+	0x00, 0x61, 0x73, 0x6d, // WASM magic number
+	0x01, 0x00, 0x00, 0x00, // Version
+	// Type section
+	0x01, 0x18, 0x04,
+	0x60, 0x02, 0x7e, 0x7f, 0x01, 0x7f, // (i64, i32) -> i32
+	0x60, 0x02, 0x7e, 0x7e, 0x01, 0x7e, // (i64, i64) -> i64
+	0x60, 0x02, 0x7e, 0x7c, 0x01, 0x7c, // (i64, f64) -> f64
+	0x60, 0x02, 0x7e, 0x7f, 0x00, // (i64, i32) -> ()
+	// Function section
+	0x03, 0x06, 0x05, 0x00, 0x01, 0x02, 0x00, 0x03,
+	// Memory section (1 page)
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	// Export section
+	0x07, 0x41, 0x06,
+	0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x02, 0x00, // "memory"
+	0x08, 0x65, 0x63, 0x68, 0x6f, 0x5f, 0x69, 0x33, 0x32, 0x00, 0x00, // "echo_i32"
+	0x08, 0x65, 0x63, 0x68, 0x6f, 0x5f, 0x69, 0x36, 0x34, 0x00, 0x01, // "echo_i64"
+	0x08, 0x65, 0x63, 0x68, 0x6f, 0x5f, 0x66, 0x36, 0x34, 0x00, 0x02, // "echo_f64"
+	0x0b, 0x6e, 0x65, 0x67, 0x61, 0x74, 0x65, 0x5f, 0x62, 0x6f, 0x6f, 0x6c, 0x00, 0x03, // "negate_bool"
+	0x05, 0x67, 0x72, 0x65, 0x65, 0x74, 0x00, 0x04, // "greet"
+	// Code section
+	0x0a, 0x30, 0x05,
+	0x07, 0x00, 0x20, 0x01, 0x41, 0x01, 0x6a, 0x0b, // local.get 1; i32.const 1; i32.add
+	0x07, 0x00, 0x20, 0x01, 0x42, 0x01, 0x7c, 0x0b, // local.get 1; i64.const 1; i64.add
+	0x07, 0x00, 0x20, 0x01, 0x20, 0x01, 0xa0, 0x0b, // local.get 1; local.get 1; f64.add
+	0x05, 0x00, 0x20, 0x01, 0x45, 0x0b, // local.get 1; i32.eqz
+	0x10, 0x00,
+	0x20, 0x01, 0x41, 0x10, 0x36, 0x02, 0x00, // i32.store ret+0 = 16
+	0x20, 0x01, 0x41, 0x05, 0x36, 0x02, 0x04, // i32.store ret+4 = 5
+	0x0b,
+	// Data section: "hello" at offset 16
+	0x0b, 0x0b, 0x01, 0x00, 0x41, 0x10, 0x0b, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f,
+}
+
+func TestUDFRegistryTypedSignatures(t *testing.T) {
+	logger := zap.NewNop()
+
+	runtime, err := NewRuntime(&Config{
+		EnableJIT:   true,
+		EnableDebug: false,
+		Logger:      logger,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create runtime: %v", err)
+	}
+	defer runtime.Close()
+
+	registry, err := NewUDFRegistry(&UDFRegistryConfig{
+		Runtime:          runtime,
+		DefaultPoolSize:  1,
+		StrictSignatures: true,
+		Logger:           logger,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	defer registry.Close()
+
+	docCtx := NewDocumentContextFromMap("doc", 1.0, map[string]interface{}{})
+
+	tests := []struct {
+		name     string
+		function string
+		params   []UDFParameter
+		returns  []UDFReturnType
+		args     map[string]Value
+		want     Value
+	}{
+		{
+			name:     "i32",
+			function: "echo_i32",
+			params:   []UDFParameter{{Name: "x", Type: ValueTypeI32, Required: true}},
+			returns:  []UDFReturnType{{Type: ValueTypeI32}},
+			args:     map[string]Value{"x": NewI32Value(41)},
+			want:     NewI32Value(42),
+		},
+		{
+			name:     "i64",
+			function: "echo_i64",
+			params:   []UDFParameter{{Name: "x", Type: ValueTypeI64, Required: true}},
+			returns:  []UDFReturnType{{Type: ValueTypeI64}},
+			args:     map[string]Value{"x": NewI64Value(1 << 40)},
+			want:     NewI64Value(1<<40 + 1),
+		},
+		{
+			name:     "f64",
+			function: "echo_f64",
+			params:   []UDFParameter{{Name: "x", Type: ValueTypeF64, Required: true}},
+			returns:  []UDFReturnType{{Type: ValueTypeF64}},
+			args:     map[string]Value{"x": NewF64Value(1.25)},
+			want:     NewF64Value(2.5),
+		},
+		{
+			name:     "bool",
+			function: "negate_bool",
+			params:   []UDFParameter{{Name: "x", Type: ValueTypeBool, Required: true}},
+			returns:  []UDFReturnType{{Type: ValueTypeBool}},
+			args:     map[string]Value{"x": NewBoolValue(false)},
+			want:     NewBoolValue(true),
+		},
+		{
+			// String params are read through get_param_string, not passed positionally
+			name:     "string param",
+			function: "echo_i32",
+			params: []UDFParameter{
+				{Name: "label", Type: ValueTypeString, Required: true},
+				{Name: "x", Type: ValueTypeI32, Required: true},
+			},
+			returns: []UDFReturnType{{Type: ValueTypeI32}},
+			args:    map[string]Value{"label": NewStringValue("ignored"), "x": NewI32Value(1)},
+			want:    NewI32Value(2),
+		},
+		{
+			name:     "string return",
+			function: "greet",
+			returns:  []UDFReturnType{{Type: ValueTypeString}},
+			want:     NewStringValue("hello"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			udfName := "typed_" + tt.function + "_" + fmt.Sprint(len(tt.params))
+			err := registry.Register(&UDFMetadata{
+				Name:         udfName,
+				Version:      "1.0.0",
+				FunctionName: tt.function,
+				WASMBytes:    typedWasmBytes,
+				Parameters:   tt.params,
+				Returns:      tt.returns,
+			})
+			if err != nil {
+				t.Fatalf("Failed to register UDF: %v", err)
+			}
+
+			// Call twice so the pooled instance reuses its return area
+			for i := 0; i < 2; i++ {
+				results, err := registry.Call(context.Background(), udfName, "1.0.0", docCtx, tt.args)
+				if err != nil {
+					t.Fatalf("Call failed: %v", err)
+				}
+				if len(results) != 1 {
+					t.Fatalf("Expected 1 result, got %d", len(results))
+				}
+				if results[0] != tt.want {
+					t.Errorf("Expected %v, got %v", tt.want, results[0])
+				}
+			}
+		})
+	}
+
+	mismatches := []struct {
+		name     string
+		function string
+		params   []UDFParameter
+		returns  []UDFReturnType
+	}{
+		{
+			name:     "missing export",
+			function: "does_not_exist",
+			returns:  []UDFReturnType{{Type: ValueTypeI32}},
+		},
+		{
+			name:     "wrong return type",
+			function: "echo_i32",
+			params:   []UDFParameter{{Name: "x", Type: ValueTypeI32}},
+			returns:  []UDFReturnType{{Type: ValueTypeI64}},
+		},
+		{
+			name:     "wrong param type",
+			function: "echo_i64",
+			params:   []UDFParameter{{Name: "x", Type: ValueTypeF64}},
+			returns:  []UDFReturnType{{Type: ValueTypeI64}},
+		},
+		{
+			name:     "missing param",
+			function: "echo_i32",
+			returns:  []UDFReturnType{{Type: ValueTypeI32}},
+		},
+		{
+			name:     "string return without return area",
+			function: "echo_i32",
+			params:   []UDFParameter{{Name: "x", Type: ValueTypeI32}},
+			returns:  []UDFReturnType{{Type: ValueTypeString}},
+		},
+	}
+
+	for i, tt := range mismatches {
+		t.Run("reject "+tt.name, func(t *testing.T) {
+			err := registry.Register(&UDFMetadata{
+				Name:         fmt.Sprintf("mismatch_%d", i),
+				Version:      "1.0.0",
+				FunctionName: tt.function,
+				WASMBytes:    typedWasmBytes,
+				Parameters:   tt.params,
+				Returns:      tt.returns,
+			})
+			if !errors.Is(err, ErrSignatureMismatch) {
+				t.Fatalf("Expected ErrSignatureMismatch, got %v", err)
+			}
+			if _, err := registry.Get(fmt.Sprintf("mismatch_%d", i), "1.0.0"); err == nil {
+				t.Error("Rejected UDF should not be registered")
+			}
+		})
+	}
+}
+
+// priceAboveWasmBytes exports price_above(ctx, min i64) -> i32, which reads
+// the "price" field through get_field_int64 and compares it to min, and its
+// batch entrypoint price_above_batch(ctxs, count i32, min i64, out i32)
+var priceAboveWasmBytes = []byte{
+	0x00, 0x61, 0x73, 0x6d, // WASM magic number
+	0x01, 0x00, 0x00, 0x00, // Version
+	// Type section
+	0x01, 0x15, 0x03,
+	0x60, 0x03, 0x7e, 0x7f, 0x7f, 0x01, 0x7e, // (i64, i32, i32) -> i64
+	0x60, 0x02, 0x7e, 0x7e, 0x01, 0x7f, // (i64, i64) -> i32
+	0x60, 0x04, 0x7f, 0x7f, 0x7e, 0x7f, 0x00, // (i32, i32, i64, i32) -> ()
+	// Import section: env.get_field_int64
+	0x02, 0x17, 0x01,
+	0x03, 0x65, 0x6e, 0x76,
+	0x0f, 0x67, 0x65, 0x74, 0x5f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x5f, 0x69, 0x6e, 0x74, 0x36, 0x34,
+	0x00, 0x00,
+	// Function section
+	0x03, 0x03, 0x02, 0x01, 0x02,
+	// Memory section (1 page)
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	// Export section
+	0x07, 0x2c, 0x03,
+	0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x02, 0x00, // "memory"
+	0x0b, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x61, 0x62, 0x6f, 0x76, 0x65, 0x00, 0x01, // "price_above"
+	0x11, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x61, 0x62, 0x6f, 0x76, 0x65,
+	0x5f, 0x62, 0x61, 0x74, 0x63, 0x68, 0x00, 0x02, // "price_above_batch"
+	// Code section
+	0x0a, 0x45, 0x02,
+	0x0d, 0x00,
+	0x20, 0x00, 0x41, 0x00, 0x41, 0x05, 0x10, 0x00, // get_field_int64(ctx, "price")
+	0x20, 0x01, 0x55, // local.get 1; i64.gt_s
+	0x0b,
+	0x35, 0x01, 0x01, 0x7f, // one i32 local: i
+	0x02, 0x40, 0x03, 0x40, // block; loop
+	0x20, 0x04, 0x20, 0x01, 0x4f, 0x0d, 0x01, // br_if 1 (i >= count)
+	0x20, 0x03, 0x20, 0x04, 0x41, 0x03, 0x74, 0x6a, // out + i*8
+	0x20, 0x00, 0x20, 0x04, 0x41, 0x03, 0x74, 0x6a, 0x29, 0x03, 0x00, // i64.load ctxs + i*8
+	0x20, 0x02, 0x10, 0x01, 0xad, // price_above(ctx, min); i64.extend_i32_u
+	0x37, 0x03, 0x00, // i64.store
+	0x20, 0x04, 0x41, 0x01, 0x6a, 0x21, 0x04, // i++
+	0x0c, 0x00, 0x0b, 0x0b, // br 0; end loop; end block
+	0x0b,
+	// Data section: "price" at offset 0
+	0x0b, 0x0b, 0x01,
+	0x00, 0x41, 0x00, 0x0b,
+	0x05, 0x70, 0x72, 0x69, 0x63, 0x65,
+}
+
+func TestUDFRegistryResultCache(t *testing.T) {
+	logger := zap.NewNop()
+
+	runtime, err := NewRuntime(&Config{
+		EnableJIT:   true,
+		EnableDebug: false,
+		Logger:      logger,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create runtime: %v", err)
+	}
+	defer runtime.Close()
+
+	registry, err := NewUDFRegistry(&UDFRegistryConfig{
+		Runtime:         runtime,
+		DefaultPoolSize: 1,
+		EnableStats:     true,
+		Logger:          logger,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	defer registry.Close()
+
+	register := func(name string, deterministic bool) {
+		err := registry.Register(&UDFMetadata{
+			Name:          name,
+			Version:       "1.0.0",
+			FunctionName:  "price_above",
+			WASMBytes:     priceAboveWasmBytes,
+			Parameters:    []UDFParameter{{Name: "min", Type: ValueTypeI64, Required: true}},
+			Returns:       []UDFReturnType{{Type: ValueTypeI32}},
+			Deterministic: deterministic,
+		})
+		if err != nil {
+			t.Fatalf("Failed to register UDF: %v", err)
+		}
+	}
+	call := func(name string, doc map[string]interface{}, min int64, want int32) {
+		t.Helper()
+		docCtx := NewDocumentContextFromMap("doc", 1.0, doc)
+		results, err := registry.Call(context.Background(), name, "1.0.0", docCtx, map[string]Value{"min": NewI64Value(min)})
+		if err != nil {
+			t.Fatalf("Call failed: %v", err)
+		}
+		if len(results) != 1 || results[0] != NewI32Value(want) {
+			t.Fatalf("Expected [%v], got %v", NewI32Value(want), results)
+		}
+	}
+	executions := func(name string) uint64 {
+		stats, err := registry.GetStats(name, "1.0.0")
+		if err != nil {
+			t.Fatalf("Failed to get stats: %v", err)
+		}
+		return stats.CallCount
+	}
+
+	register("cached", true)
+
+	call("cached", map[string]interface{}{"price": 100, "title": "a"}, 50, 1)
+	if got := executions("cached"); got != 1 {
+		t.Fatalf("Expected 1 execution, got %d", got)
+	}
+
+	// Same params and price: served from the cache, even for another
+	// document whose unread fields differ
+	call("cached", map[string]interface{}{"price": 100, "title": "a"}, 50, 1)
+	call("cached", map[string]interface{}{"price": 100, "title": "b"}, 50, 1)
+	if got := executions("cached"); got != 1 {
+		t.Errorf("Expected cache hits to skip execution, got %d executions", got)
+	}
+
+	// A different param or a different value of a read field runs the UDF
+	call("cached", map[string]interface{}{"price": 100, "title": "a"}, 150, 0)
+	call("cached", map[string]interface{}{"price": 10, "title": "a"}, 50, 0)
+	if got := executions("cached"); got != 3 {
+		t.Errorf("Expected 3 executions, got %d", got)
+	}
+
+	stats, _ := registry.GetStats("cached", "1.0.0")
+	if stats.CacheHits != 2 || stats.CacheMisses != 3 {
+		t.Errorf("Expected 2 hits and 3 misses, got %d and %d", stats.CacheHits, stats.CacheMisses)
+	}
+
+	// UDFs not declared deterministic always run
+	register("uncached", false)
+	call("uncached", map[string]interface{}{"price": 100}, 50, 1)
+	call("uncached", map[string]interface{}{"price": 100}, 50, 1)
+	if got := executions("uncached"); got != 2 {
+		t.Errorf("Expected 2 executions, got %d", got)
+	}
+}
+
+func TestUDFRegistryCallBatch(t *testing.T) {
+	logger := zap.NewNop()
+
+	runtime, err := NewRuntime(&Config{
+		EnableJIT:   true,
+		EnableDebug: false,
+		Logger:      logger,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create runtime: %v", err)
+	}
+	defer runtime.Close()
+
+	registry, err := NewUDFRegistry(&UDFRegistryConfig{
+		Runtime:          runtime,
+		DefaultPoolSize:  1,
+		EnableStats:      true,
+		StrictSignatures: true,
+		Logger:           logger,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	defer registry.Close()
+
+	for _, name := range []string{"batched", "single"} {
+		err := registry.Register(&UDFMetadata{
+			Name:          name,
+			Version:       "1.0.0",
+			FunctionName:  "price_above",
+			WASMBytes:     priceAboveWasmBytes,
+			Parameters:    []UDFParameter{{Name: "min", Type: ValueTypeI64, Required: true}},
+			Returns:       []UDFReturnType{{Type: ValueTypeI32}},
+			SupportsBatch: name == "batched",
+		})
+		if err != nil {
+			t.Fatalf("Failed to register UDF: %v", err)
+		}
+	}
+
+	prices := []int{10, 60, 50, 100, 0}
+	docCtxs := make([]*DocumentContext, len(prices))
+	for i, price := range prices {
+		docCtxs[i] = NewDocumentContextFromMap(fmt.Sprintf("doc-%d", i), 1.0, map[string]interface{}{"price": price})
+	}
+	params := map[string]Value{"min": NewI64Value(50)}
+
+	results, err := registry.CallBatch(context.Background(), "batched", "1.0.0", docCtxs, params)
+	if err != nil {
+		t.Fatalf("CallBatch failed: %v", err)
+	}
+	if len(results) != len(docCtxs) {
+		t.Fatalf("Expected %d results, got %d", len(docCtxs), len(results))
+	}
+	for i, docCtx := range docCtxs {
+		want, err := registry.Call(context.Background(), "single", "1.0.0", docCtx, params)
+		if err != nil {
+			t.Fatalf("Call failed: %v", err)
+		}
+		if len(results[i]) != 1 || results[i][0] != want[0] {
+			t.Errorf("Document %d: batch returned %v, single call returned %v", i, results[i], want)
+		}
+	}
+
+	// One module call for the whole batch
+	stats, _ := registry.GetStats("batched", "1.0.0")
+	if stats.CallCount != 1 {
+		t.Errorf("Expected 1 batch call, got %d", stats.CallCount)
+	}
+
+	if _, err := registry.CallBatch(context.Background(), "single", "1.0.0", docCtxs, params); !errors.Is(err, ErrBatchUnsupported) {
+		t.Errorf("Expected ErrBatchUnsupported, got %v", err)
+	}
+
+	// Declaring batch support requires the batch export
+	err = registry.Register(&UDFMetadata{
+		Name:          "no_batch_export",
+		Version:       "1.0.0",
+		FunctionName:  "echo_i32",
+		WASMBytes:     typedWasmBytes,
+		Parameters:    []UDFParameter{{Name: "x", Type: ValueTypeI32}},
+		Returns:       []UDFReturnType{{Type: ValueTypeI32}},
+		SupportsBatch: true,
+	})
+	if !errors.Is(err, ErrSignatureMismatch) {
+		t.Errorf("Expected ErrSignatureMismatch, got %v", err)
+	}
+}
+
+// BenchmarkUDFRegistryCallBatch compares calling a UDF once per document to
+// passing all the documents to its batch entrypoint
+func BenchmarkUDFRegistryCallBatch(b *testing.B) {
+	logger := zap.NewNop()
+
+	runtime, _ := NewRuntime(&Config{
+		EnableJIT:   true,
+		EnableDebug: false,
+		Logger:      logger,
+	})
+	defer runtime.Close()
+
+	registry, _ := NewUDFRegistry(&UDFRegistryConfig{
+		Runtime:         runtime,
+		DefaultPoolSize: 1,
+		Logger:          logger,
+	})
+	defer registry.Close()
+
+	registry.Register(&UDFMetadata{
+		Name:          "price_above",
+		Version:       "1.0.0",
+		FunctionName:  "price_above",
+		WASMBytes:     priceAboveWasmBytes,
+		Parameters:    []UDFParameter{{Name: "min", Type: ValueTypeI64, Required: true}},
+		Returns:       []UDFReturnType{{Type: ValueTypeI32}},
+		SupportsBatch: true,
+	})
+
+	docCtxs := make([]*DocumentContext, 256)
+	for i := range docCtxs {
+		docCtxs[i] = NewDocumentContextFromMap(fmt.Sprintf("doc-%d", i), 1.0, map[string]interface{}{"price": i})
+	}
+	params := map[string]Value{"min": NewI64Value(128)}
+	ctx := context.Background()
+
+	b.Run("per-doc", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, docCtx := range docCtxs {
+				if _, err := registry.Call(ctx, "price_above", "1.0.0", docCtx, params); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+		b.ReportMetric(float64(b.N*len(docCtxs))/b.Elapsed().Seconds(), "docs/s")
+	})
+
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := registry.CallBatch(ctx, "price_above", "1.0.0", docCtxs, params); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(b.N*len(docCtxs))/b.Elapsed().Seconds(), "docs/s")
+	})
+}
+
 func BenchmarkUDFRegistryRegister(b *testing.B) {
 	logger, _ := zap.NewProduction()
 
@@ -0,0 +1,155 @@
+package wasm
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultResultCacheSize is the number of results cached per deterministic
+// UDF when the registry config doesn't set one
+const DefaultResultCacheSize = 10000
+
+// maxReadSetsPerParams bounds the distinct sets of fields remembered for one
+// set of parameters. A UDF reads different fields only along different
+// branches, so a handful covers all but pathological functions.
+const maxReadSetsPerParams = 16
+
+// udfResultCache caches the results of a deterministic UDF.
+//
+// A result is keyed by the UDF version, its parameters and the values of the
+// document fields the call read. A deterministic function handed the same
+// parameters and the same values for every field it reads takes the same
+// path, so any document agreeing on those fields gets the same result
+// without running the function. Which fields a call reads depends on the
+// values it sees, so the cache remembers every set of fields calls with the
+// same parameters have read and tries each in turn.
+type udfResultCache struct {
+	fullName   string
+	maxEntries int
+
+	mu       sync.Mutex
+	entries  map[string][]Value    // key → results
+	readSets map[string][][]string // params key → sorted field sets
+}
+
+// newUDFResultCache creates a result cache for a UDF holding up to
+// maxEntries results
+func newUDFResultCache(fullName string, maxEntries int) *udfResultCache {
+	return &udfResultCache{
+		fullName:   fullName,
+		maxEntries: maxEntries,
+		entries:    make(map[string][]Value),
+		readSets:   make(map[string][][]string),
+	}
+}
+
+// get returns the cached results for a call with params on docCtx
+func (c *udfResultCache) get(paramsKey string, docCtx *DocumentContext) ([]Value, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, fields := range c.readSets[paramsKey] {
+		key, err := c.key(paramsKey, fields, docCtx)
+		if err != nil {
+			continue
+		}
+		if results, ok := c.entries[key]; ok {
+			return append([]Value(nil), results...), true
+		}
+	}
+	return nil, false
+}
+
+// put caches the results of a call with params on docCtx that read fields
+func (c *udfResultCache) put(paramsKey string, fields []string, docCtx *DocumentContext, results []Value) {
+	sort.Strings(fields)
+	key, err := c.key(paramsKey, fields, docCtx)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.hasReadSet(paramsKey, fields) {
+		if len(c.readSets[paramsKey]) >= maxReadSetsPerParams {
+			return
+		}
+		c.readSets[paramsKey] = append(c.readSets[paramsKey], fields)
+	}
+
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.maxEntries {
+		// Evict an arbitrary entry; map iteration order is random enough
+		// to avoid favouring any document
+		for evict := range c.entries {
+			delete(c.entries, evict)
+			break
+		}
+	}
+	c.entries[key] = append([]Value(nil), results...)
+}
+
+// hasReadSet reports whether fields is already remembered for paramsKey.
+// Must be called with c.mu held.
+func (c *udfResultCache) hasReadSet(paramsKey string, fields []string) bool {
+	for _, known := range c.readSets[paramsKey] {
+		if strings.Join(known, "\x00") == strings.Join(fields, "\x00") {
+			return true
+		}
+	}
+	return false
+}
+
+// key hashes the UDF, its parameters and the values docCtx has for fields
+func (c *udfResultCache) key(paramsKey string, fields []string, docCtx *DocumentContext) (string, error) {
+	h := sha256.New()
+	h.Write([]byte(c.fullName))
+	h.Write([]byte{0})
+	h.Write([]byte(paramsKey))
+	for _, field := range fields {
+		h.Write([]byte{0})
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+
+		value, exists := docCtx.readValue(field)
+		if !exists {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("field %s: %w", field, err)
+		}
+		h.Write([]byte{1})
+		h.Write(encoded)
+	}
+	return string(h.Sum(nil)), nil
+}
+
+// cacheParamsKey returns a canonical encoding of call parameters
+func cacheParamsKey(params map[string]Value) (string, error) {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	type param struct {
+		Name string      `json:"n"`
+		Type ValueType   `json:"t"`
+		Data interface{} `json:"d"`
+	}
+	encoded := make([]param, len(names))
+	for i, name := range names {
+		encoded[i] = param{Name: name, Type: params[name].Type, Data: params[name].Data}
+	}
+
+	key, err := json.Marshal(encoded)
+	if err != nil {
+		return "", err
+	}
+	return string(key), nil
+}
@@ -14,24 +14,27 @@ type UDFMetadata struct {
 	Author      string `json:"author"`
 
 	// Function signature
-	FunctionName string               `json:"function_name"` // Entry point in WASM module
-	Parameters   []UDFParameter       `json:"parameters"`
-	Returns      []UDFReturnType      `json:"returns"`
+	FunctionName string          `json:"function_name"` // Entry point in WASM module
+	Parameters   []UDFParameter  `json:"parameters"`
+	Returns      []UDFReturnType `json:"returns"`
 
 	// WASM module
 	WASMBytes []byte `json:"-"` // Raw WASM bytes (not serialized)
 	WASMSize  int    `json:"wasm_size"`
+	WASMHash  string `json:"wasm_hash,omitempty"` // SHA-256 of WASMBytes, set at registration
 
 	// Performance hints
 	ExpectedLatency time.Duration `json:"expected_latency,omitempty"` // Expected execution time
 	MemoryRequired  uint32        `json:"memory_required,omitempty"`  // Memory pages required
+	Deterministic   bool          `json:"deterministic,omitempty"`    // Same params and fields always give the same result, so results may be cached
+	SupportsBatch   bool          `json:"supports_batch,omitempty"`   // Module exports FunctionName + "_batch" taking many documents per call
 
 	// Metadata
-	Tags        []string          `json:"tags,omitempty"`
-	Category    string            `json:"category,omitempty"`
-	License     string            `json:"license,omitempty"`
-	Repository  string            `json:"repository,omitempty"`
-	CustomMeta  map[string]string `json:"custom_meta,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	Category   string            `json:"category,omitempty"`
+	License    string            `json:"license,omitempty"`
+	Repository string            `json:"repository,omitempty"`
+	CustomMeta map[string]string `json:"custom_meta,omitempty"`
 
 	// Registration info
 	RegisteredAt time.Time `json:"registered_at"`
@@ -105,6 +108,17 @@ func (m *UDFMetadata) Validate() error {
 		if ret.Type < ValueTypeI32 || ret.Type > ValueTypeBool {
 			return fmt.Errorf("return %d: invalid type %d", i, ret.Type)
 		}
+
+		// Strings are returned through a host-provided buffer, which only
+		// works when the string is the sole result
+		if ret.Type == ValueTypeString && len(m.Returns) > 1 {
+			return fmt.Errorf("return %d: string return must be the only return value", i)
+		}
+	}
+
+	// Batch entrypoints write one scalar result slot per document
+	if m.SupportsBatch && (len(m.Returns) != 1 || m.Returns[0].Type == ValueTypeString) {
+		return fmt.Errorf("batch UDFs must return a single non-string value")
 	}
 
 	return nil
@@ -182,8 +196,11 @@ func (m *UDFMetadata) Clone() *UDFMetadata {
 		Author:          m.Author,
 		FunctionName:    m.FunctionName,
 		WASMSize:        m.WASMSize,
+		WASMHash:        m.WASMHash,
 		ExpectedLatency: m.ExpectedLatency,
 		MemoryRequired:  m.MemoryRequired,
+		Deterministic:   m.Deterministic,
+		SupportsBatch:   m.SupportsBatch,
 		Category:        m.Category,
 		License:         m.License,
 		Repository:      m.Repository,
@@ -218,17 +235,20 @@ func (m *UDFMetadata) Clone() *UDFMetadata {
 
 // UDFStats contains statistics about a UDF
 type UDFStats struct {
-	Name             string        `json:"name"`
-	Version          string        `json:"version"`
-	CallCount        uint64        `json:"call_count"`
-	ErrorCount       uint64        `json:"error_count"`
-	TotalDuration    time.Duration `json:"total_duration"`
-	AverageDuration  time.Duration `json:"average_duration"`
-	MinDuration      time.Duration `json:"min_duration"`
-	MaxDuration      time.Duration `json:"max_duration"`
-	LastCalled       time.Time     `json:"last_called"`
-	LastError        string        `json:"last_error,omitempty"`
-	LastErrorTime    time.Time     `json:"last_error_time,omitempty"`
+	Name            string        `json:"name"`
+	Version         string        `json:"version"`
+	WASMHash        string        `json:"wasm_hash,omitempty"`
+	CallCount       uint64        `json:"call_count"`
+	ErrorCount      uint64        `json:"error_count"`
+	TotalDuration   time.Duration `json:"total_duration"`
+	AverageDuration time.Duration `json:"average_duration"`
+	MinDuration     time.Duration `json:"min_duration"`
+	MaxDuration     time.Duration `json:"max_duration"`
+	LastCalled      time.Time     `json:"last_called"`
+	LastError       string        `json:"last_error,omitempty"`
+	LastErrorTime   time.Time     `json:"last_error_time,omitempty"`
+	CacheHits       uint64        `json:"cache_hits,omitempty"`
+	CacheMisses     uint64        `json:"cache_misses,omitempty"`
 }
 
 // UpdateStats updates statistics after a UDF call
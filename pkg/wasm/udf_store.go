@@ -0,0 +1,209 @@
+package wasm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// UDFStore persists registered UDFs so a registry can reload them after a
+// restart
+type UDFStore interface {
+	// Save records a registered UDF, replacing any earlier record of the
+	// same name and version
+	Save(udf *StoredUDF) error
+
+	// Delete removes the record of a UDF
+	Delete(name, version string) error
+
+	// Load returns every recorded UDF. Records that can't be read are
+	// reported in the error alongside the ones that could.
+	Load() ([]*StoredUDF, error)
+}
+
+// StoredUDF is a persisted UDF registration: its metadata, including the
+// WASM bytes, and the pool settings it was registered with
+type StoredUDF struct {
+	Metadata   *UDFMetadata  `json:"metadata"`
+	PoolConfig UDFPoolConfig `json:"pool"`
+}
+
+// FileUDFStore is a UDFStore keeping one JSON record per UDF version under
+// dir/udfs and the WASM modules they use under dir/modules, named by their
+// hash. Versions uploaded with identical bytes share one module file, which
+// is removed once no record uses it.
+type FileUDFStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileUDFStore creates a UDF store in dir, creating it if needed
+func NewFileUDFStore(dir string) (*FileUDFStore, error) {
+	for _, sub := range []string{"udfs", "modules"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create UDF store directory: %w", err)
+		}
+	}
+	return &FileUDFStore{dir: dir}, nil
+}
+
+// Save implements UDFStore
+func (s *FileUDFStore) Save(udf *StoredUDF) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash := udf.Metadata.WASMHash
+	if hash == "" {
+		hash = WASMHash(udf.Metadata.WASMBytes)
+	}
+
+	modulePath := s.modulePath(hash)
+	if _, err := os.Stat(modulePath); errors.Is(err, os.ErrNotExist) {
+		if err := writeFileAtomic(modulePath, udf.Metadata.WASMBytes); err != nil {
+			return fmt.Errorf("failed to write module: %w", err)
+		}
+	}
+
+	record := *udf
+	metadata := *udf.Metadata
+	metadata.WASMHash = hash
+	record.Metadata = &metadata
+
+	data, err := json.MarshalIndent(&record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal UDF record: %w", err)
+	}
+	if err := writeFileAtomic(s.recordPath(metadata.Name, metadata.Version), data); err != nil {
+		return fmt.Errorf("failed to write UDF record: %w", err)
+	}
+	return nil
+}
+
+// Delete implements UDFStore
+func (s *FileUDFStore) Delete(name, version string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.recordPath(name, version)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete UDF record: %w", err)
+	}
+
+	// A record that fails to load may still reference its module, so
+	// modules are only compacted when every record loads
+	records, err := s.loadRecords()
+	if err != nil {
+		return nil
+	}
+	return s.compact(records)
+}
+
+// Load implements UDFStore
+func (s *FileUDFStore) Load() ([]*StoredUDF, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.loadRecords()
+	if err != nil {
+		return records, err
+	}
+	return records, s.compact(records)
+}
+
+// loadRecords reads every UDF record along with its module bytes. Must be
+// called with s.mu held.
+func (s *FileUDFStore) loadRecords() ([]*StoredUDF, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, "udfs"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list UDF records: %w", err)
+	}
+
+	var records []*StoredUDF
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		record, err := s.loadRecord(filepath.Join(s.dir, "udfs", entry.Name()))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, errors.Join(errs...)
+}
+
+// loadRecord reads one UDF record and its module bytes, checking they still
+// match the recorded hash
+func (s *FileUDFStore) loadRecord(path string) (*StoredUDF, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var record StoredUDF
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("invalid UDF record: %w", err)
+	}
+	if record.Metadata == nil || record.Metadata.WASMHash == "" {
+		return nil, fmt.Errorf("UDF record has no module hash")
+	}
+
+	wasmBytes, err := os.ReadFile(s.modulePath(record.Metadata.WASMHash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module: %w", err)
+	}
+	if hash := WASMHash(wasmBytes); hash != record.Metadata.WASMHash {
+		return nil, fmt.Errorf("%w: module %s hashes to %s", ErrChecksumMismatch, record.Metadata.WASMHash, hash)
+	}
+	record.Metadata.WASMBytes = wasmBytes
+
+	return &record, nil
+}
+
+// compact removes module files none of records uses, left behind by deleted
+// versions. Must be called with s.mu held.
+func (s *FileUDFStore) compact(records []*StoredUDF) error {
+	used := make(map[string]bool, len(records))
+	for _, record := range records {
+		used[record.Metadata.WASMHash] = true
+	}
+
+	modules, err := os.ReadDir(filepath.Join(s.dir, "modules"))
+	if err != nil {
+		return fmt.Errorf("failed to list modules: %w", err)
+	}
+	for _, module := range modules {
+		hash, isModule := strings.CutSuffix(module.Name(), ".wasm")
+		if !isModule || used[hash] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, "modules", module.Name())); err != nil {
+			return fmt.Errorf("failed to remove unused module: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *FileUDFStore) recordPath(name, version string) string {
+	return filepath.Join(s.dir, "udfs", url.PathEscape(name+"@"+version)+".json")
+}
+
+func (s *FileUDFStore) modulePath(hash string) string {
+	return filepath.Join(s.dir, "modules", hash+".wasm")
+}
+
+// writeFileAtomic writes data to path through a temporary file, so a crash
+// never leaves a partially written file behind
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
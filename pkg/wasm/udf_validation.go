@@ -0,0 +1,97 @@
+package wasm
+
+import (
+	"fmt"
+)
+
+// Checks run by ValidateUDF, in order
+const (
+	CheckMetadata    = "metadata"
+	CheckChecksum    = "checksum"
+	CheckCompile     = "compile"
+	CheckSignature   = "signature"
+	CheckHostImports = "host_imports"
+)
+
+// UDFDiagnostic is the outcome of one validation check
+type UDFDiagnostic struct {
+	Check   string `json:"check"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// UDFValidation is the outcome of validating a UDF without running it
+type UDFValidation struct {
+	Valid       bool            `json:"valid"`
+	WASMHash    string          `json:"wasm_hash,omitempty"`
+	Diagnostics []UDFDiagnostic `json:"diagnostics"`
+}
+
+// ValidateUDF checks a UDF would register and could be called, without
+// registering or running it: the metadata is well formed, the bytes match a
+// declared hash, the module compiles, its exports match the declared
+// signature, and every function it imports is a host function of the same
+// signature. Checks that need the compiled module are skipped if it doesn't
+// compile. Signatures are checked even when the registry doesn't enforce
+// them.
+func (r *UDFRegistry) ValidateUDF(metadata *UDFMetadata) *UDFValidation {
+	validation := &UDFValidation{Valid: true}
+	report := func(check string, err error) {
+		diagnostic := UDFDiagnostic{Check: check, Passed: err == nil}
+		if err != nil {
+			diagnostic.Message = err.Error()
+			validation.Valid = false
+		}
+		validation.Diagnostics = append(validation.Diagnostics, diagnostic)
+	}
+
+	report(CheckMetadata, metadata.Validate())
+
+	validation.WASMHash = WASMHash(metadata.WASMBytes)
+	if metadata.WASMHash != "" && metadata.WASMHash != validation.WASMHash {
+		report(CheckChecksum, fmt.Errorf("%w: declared %s, WASM bytes hash to %s",
+			ErrChecksumMismatch, metadata.WASMHash, validation.WASMHash))
+	} else {
+		report(CheckChecksum, nil)
+	}
+
+	ctx := r.runtime.GetContext()
+	compiled, err := r.runtime.GetWazeroRuntime().CompileModule(ctx, metadata.WASMBytes)
+	if err != nil {
+		report(CheckCompile, fmt.Errorf("failed to compile module: %w", err))
+		return validation
+	}
+	defer compiled.Close(ctx)
+	report(CheckCompile, nil)
+
+	report(CheckSignature, checkSignature(compiled, metadata))
+
+	hostModule := r.runtime.GetWazeroRuntime().Module(hostModuleName)
+	importsOK := true
+	for _, imported := range compiled.ImportedFunctions() {
+		moduleName, name, _ := imported.Import()
+		if moduleName != hostModuleName || hostModule == nil {
+			report(CheckHostImports, fmt.Errorf("function %s.%s is not provided by the host", moduleName, name))
+			importsOK = false
+			continue
+		}
+
+		host, ok := hostModule.ExportedFunctionDefinitions()[name]
+		if !ok {
+			report(CheckHostImports, fmt.Errorf("host function %s does not exist", name))
+			importsOK = false
+			continue
+		}
+		if !equalValueTypes(imported.ParamTypes(), host.ParamTypes()) || !equalValueTypes(imported.ResultTypes(), host.ResultTypes()) {
+			report(CheckHostImports, fmt.Errorf("host function %s is imported as %s, the host provides %s", name,
+				formatSignature(imported.ParamTypes(), imported.ResultTypes()),
+				formatSignature(host.ParamTypes(), host.ResultTypes())))
+			importsOK = false
+		}
+	}
+	if importsOK {
+		report(CheckHostImports, nil)
+	}
+
+	return validation
+}
@@ -62,6 +62,10 @@ type ClusterConfig struct {
 	NumCoordination int
 	NumData         int
 	StartPorts      PortRange
+
+	// PathRepo lists the directories coordination nodes accept fs snapshot
+	// repositories under
+	PathRepo []string
 }
 
 // PortRange defines port allocation
@@ -183,6 +187,7 @@ func (tc *TestCluster) createCoordinationNodes(cfg *ClusterConfig) error {
 			RESTPort:   cfg.StartPorts.CoordRESTBase + i,
 			MasterAddr: masterAddr,
 			LogLevel:   "debug",
+			PathRepo:   cfg.PathRepo,
 		}
 
 		node, err := coordination.NewCoordinationNode(coordCfg, tc.logger)
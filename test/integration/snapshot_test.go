@@ -0,0 +1,146 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRestoreIntoFreshCluster(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	// The repository outlives both clusters
+	repositoryDir := t.TempDir()
+	repository := map[string]interface{}{
+		"type":     "fs",
+		"settings": map[string]interface{}{"location": repositoryDir},
+	}
+	const numDocs = 20
+
+	// Snapshot an index from the first cluster
+	sourceCfg := DefaultClusterConfig()
+	sourceCfg.PathRepo = []string{repositoryDir}
+	source, err := NewTestCluster(t, sourceCfg)
+	if err != nil {
+		t.Fatalf("Failed to create source cluster: %v", err)
+	}
+	ctx := context.Background()
+	if err := source.Start(ctx); err != nil {
+		t.Fatalf("Failed to start source cluster: %v", err)
+	}
+	if err := source.WaitForClusterReady(10 * time.Second); err != nil {
+		source.Stop()
+		t.Fatalf("Source cluster not ready: %v", err)
+	}
+
+	client := NewHTTPClient(t, GetCoordNodeURL(source, 0))
+	if err := CreateTestIndex(t, client, "products", 2, 0); err != nil {
+		source.Stop()
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	time.Sleep(2 * time.Second) // Wait for shards to start
+
+	for i := 0; i < numDocs; i++ {
+		doc := map[string]interface{}{"name": fmt.Sprintf("product %d", i), "price": i}
+		if err := IndexDocument(t, client, "products", fmt.Sprintf("%d", i), doc); err != nil {
+			source.Stop()
+			t.Fatalf("Failed to index document %d: %v", i, err)
+		}
+	}
+
+	expectStatus(t, client, http.MethodPut, "/_snapshot/backups", repository, http.StatusOK)
+	expectStatus(t, client, http.MethodPut, "/_snapshot/backups/snap-1", map[string]interface{}{"indices": "products"}, http.StatusOK)
+
+	// A second snapshot of the unchanged index copies no new files
+	resp, err := client.Put("/_snapshot/backups/snap-2", map[string]interface{}{"indices": "products"})
+	if err != nil {
+		source.Stop()
+		t.Fatalf("Failed to take second snapshot: %v", err)
+	}
+	var second struct {
+		Snapshot struct {
+			Stats struct {
+				Incremental struct {
+					FileCount int `json:"file_count"`
+				} `json:"incremental"`
+			} `json:"stats"`
+		} `json:"snapshot"`
+	}
+	if err := client.DecodeJSON(resp, &second); err != nil {
+		source.Stop()
+		t.Fatalf("Failed to decode snapshot response: %v", err)
+	}
+	if second.Snapshot.Stats.Incremental.FileCount != 0 {
+		t.Errorf("Expected the second snapshot to reuse every file, copied %d", second.Snapshot.Stats.Incremental.FileCount)
+	}
+
+	if err := source.Stop(); err != nil {
+		t.Fatalf("Failed to stop source cluster: %v", err)
+	}
+
+	// Restore it into a fresh cluster with its own data directories
+	cfg := DefaultClusterConfig()
+	cfg.StartPorts = PortRange{
+		MasterRaftBase: 21300,
+		MasterGRPCBase: 21400,
+		CoordRESTBase:  21500,
+		DataGRPCBase:   21600,
+	}
+	cfg.PathRepo = []string{repositoryDir}
+	target, err := NewTestCluster(t, cfg)
+	if err != nil {
+		t.Fatalf("Failed to create target cluster: %v", err)
+	}
+	defer target.Stop()
+	if err := target.Start(ctx); err != nil {
+		t.Fatalf("Failed to start target cluster: %v", err)
+	}
+	if err := target.WaitForClusterReady(10 * time.Second); err != nil {
+		t.Fatalf("Target cluster not ready: %v", err)
+	}
+
+	client = NewHTTPClient(t, GetCoordNodeURL(target, 0))
+	expectStatus(t, client, http.MethodPut, "/_snapshot/backups", repository, http.StatusOK)
+	expectStatus(t, client, http.MethodPost, "/_snapshot/backups/snap-1/_restore", map[string]interface{}{}, http.StatusOK)
+
+	result, err := SearchIndex(t, client, "products", map[string]interface{}{"match_all": map[string]interface{}{}})
+	if err != nil {
+		t.Fatalf("Failed to search restored index: %v", err)
+	}
+	if result.Hits.Total.Value != numDocs {
+		t.Errorf("Expected %d restored documents, got %d", numDocs, result.Hits.Total.Value)
+	}
+
+	// The restored index can't be restored over
+	expectStatus(t, client, http.MethodPost, "/_snapshot/backups/snap-1/_restore", map[string]interface{}{}, http.StatusBadRequest)
+}
+
+// expectStatus sends a request and fails the test unless it returns status
+func expectStatus(t *testing.T, client *HTTPClient, method, path string, body interface{}, status int) {
+	t.Helper()
+
+	var resp *http.Response
+	var err error
+	switch method {
+	case http.MethodPut:
+		resp, err = client.Put(path, body)
+	case http.MethodPost:
+		resp, err = client.Post(path, body)
+	default:
+		t.Fatalf("Unsupported method %s", method)
+	}
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != status {
+		respBody, _ := io.ReadAll(resp.Body)
+		t.Fatalf("%s %s returned %d, expected %d: %s", method, path, resp.StatusCode, status, respBody)
+	}
+}